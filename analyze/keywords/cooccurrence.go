@@ -0,0 +1,379 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keywords
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// CooccurrenceMatrix counts how often two keywords appear in the same record
+type CooccurrenceMatrix struct {
+	Keywords []string `json:"keywords"` // Vocabulary, ordered by cluster when clustered
+	Records  []int    `json:"records"`  // Number of records containing each keyword
+	Counts   [][]int  `json:"counts"`   // Counts[i][j]: records containing both i and j
+}
+
+// KeywordCluster is a group of keywords that are frequently reported together
+type KeywordCluster struct {
+	ID       int      `json:"id"`
+	Label    string   `json:"label"`    // Most frequent keyword of the cluster
+	Keywords []string `json:"keywords"` // Keywords ordered by frequency
+	Records  int      `json:"records"`  // Records assigned to this cluster
+	Cohesion float64  `json:"cohesion"` // Average Jaccard similarity inside the cluster
+}
+
+// ClusterReport is the JSON document written by WriteClusters
+type ClusterReport struct {
+	Threshold   float64            `json:"threshold"`
+	Clusters    []KeywordCluster   `json:"clusters"`
+	Assignments map[string]int     `json:"assignments"` // Keyword to cluster ID
+	Matrix      CooccurrenceMatrix `json:"matrix"`
+}
+
+// BuildCooccurrence builds the co-occurrence matrix of the topN most frequent
+// keywords. Keywords repeated inside a record are counted once.
+func BuildCooccurrence(records [][]string, topN int) CooccurrenceMatrix {
+	sets := make([]map[string]struct{}, 0, len(records))
+	frequency := make(map[string]int)
+	for _, record := range records {
+		set := make(map[string]struct{}, len(record))
+		for _, keyword := range record {
+			if keyword == "" {
+				continue
+			}
+			if _, seen := set[keyword]; !seen {
+				set[keyword] = struct{}{}
+				frequency[keyword]++
+			}
+		}
+		sets = append(sets, set)
+	}
+
+	vocabulary := make([]string, 0, len(frequency))
+	for keyword := range frequency {
+		vocabulary = append(vocabulary, keyword)
+	}
+	sort.Slice(vocabulary, func(i, j int) bool {
+		if frequency[vocabulary[i]] != frequency[vocabulary[j]] {
+			return frequency[vocabulary[i]] > frequency[vocabulary[j]]
+		}
+		return vocabulary[i] < vocabulary[j]
+	})
+	if len(vocabulary) > topN {
+		vocabulary = vocabulary[:topN]
+	}
+
+	index := make(map[string]int, len(vocabulary))
+	matrix := CooccurrenceMatrix{
+		Keywords: vocabulary,
+		Records:  make([]int, len(vocabulary)),
+		Counts:   make([][]int, len(vocabulary)),
+	}
+	for i, keyword := range vocabulary {
+		index[keyword] = i
+		matrix.Records[i] = frequency[keyword]
+		matrix.Counts[i] = make([]int, len(vocabulary))
+	}
+	for _, set := range sets {
+		present := make([]int, 0, len(set))
+		for keyword := range set {
+			if i, ok := index[keyword]; ok {
+				present = append(present, i)
+			}
+		}
+		for _, i := range present {
+			for _, j := range present {
+				matrix.Counts[i][j]++
+			}
+		}
+	}
+	return matrix
+}
+
+// Jaccard returns the share of records containing keyword i or j that contain both
+func (m CooccurrenceMatrix) Jaccard(i, j int) float64 {
+	union := m.Records[i] + m.Records[j] - m.Counts[i][j]
+	if union == 0 {
+		return 0
+	}
+	return float64(m.Counts[i][j]) / float64(union)
+}
+
+// ClusterKeywords groups the matrix keywords with average-linkage agglomerative
+// clustering on Jaccard similarity. Clusters are merged while their average
+// similarity is at least threshold, so unrelated keywords stay on their own.
+func ClusterKeywords(matrix CooccurrenceMatrix, threshold float64) [][]int {
+	clusters := make([][]int, len(matrix.Keywords))
+	for i := range clusters {
+		clusters[i] = []int{i}
+	}
+	for len(clusters) > 1 {
+		best, bestA, bestB := -1.0, -1, -1
+		for a := 0; a < len(clusters); a++ {
+			for b := a + 1; b < len(clusters); b++ {
+				if s := averageLinkage(matrix, clusters[a], clusters[b]); s > best {
+					best, bestA, bestB = s, a, b
+				}
+			}
+		}
+		if best < threshold {
+			break
+		}
+		clusters[bestA] = append(clusters[bestA], clusters[bestB]...)
+		clusters = append(clusters[:bestB], clusters[bestB+1:]...)
+	}
+	for _, cluster := range clusters {
+		sort.Ints(cluster) // Vocabulary order is frequency order
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i]) != len(clusters[j]) {
+			return len(clusters[i]) > len(clusters[j])
+		}
+		return clusters[i][0] < clusters[j][0]
+	})
+	return clusters
+}
+
+func averageLinkage(matrix CooccurrenceMatrix, a, b []int) float64 {
+	total := 0.0
+	for _, i := range a {
+		for _, j := range b {
+			total += matrix.Jaccard(i, j)
+		}
+	}
+	return total / float64(len(a)*len(b))
+}
+
+// BuildClusterReport clusters the matrix keywords, assigns every record to the
+// cluster sharing most of its keywords and reorders the matrix by cluster
+func BuildClusterReport(records [][]string, matrix CooccurrenceMatrix, threshold float64) ClusterReport {
+	groups := ClusterKeywords(matrix, threshold)
+	report := ClusterReport{
+		Threshold:   threshold,
+		Clusters:    make([]KeywordCluster, 0, len(groups)),
+		Assignments: make(map[string]int, len(matrix.Keywords)),
+	}
+	order := make([]int, 0, len(matrix.Keywords))
+	for id, group := range groups {
+		cluster := KeywordCluster{ID: id, Label: matrix.Keywords[group[0]]}
+		for _, i := range group {
+			cluster.Keywords = append(cluster.Keywords, matrix.Keywords[i])
+			report.Assignments[matrix.Keywords[i]] = id
+		}
+		if len(group) > 1 {
+			pairs := 0
+			for x := range group {
+				for y := x + 1; y < len(group); y++ {
+					cluster.Cohesion += matrix.Jaccard(group[x], group[y])
+					pairs++
+				}
+			}
+			cluster.Cohesion = math.Round(cluster.Cohesion/float64(pairs)*1000) / 1000
+		}
+		report.Clusters = append(report.Clusters, cluster)
+		order = append(order, group...)
+	}
+
+	for _, record := range records {
+		hits := make(map[int]int)
+		seen := make(map[string]struct{}, len(record))
+		for _, keyword := range record {
+			if _, dup := seen[keyword]; dup {
+				continue
+			}
+			seen[keyword] = struct{}{}
+			if id, ok := report.Assignments[keyword]; ok {
+				hits[id]++
+			}
+		}
+		best, bestHits := -1, 0
+		for id, n := range hits {
+			if n > bestHits || (n == bestHits && id < best) {
+				best, bestHits = id, n
+			}
+		}
+		if best >= 0 {
+			report.Clusters[best].Records++
+		}
+	}
+	report.Matrix = matrix.reorder(order)
+	return report
+}
+
+func (m CooccurrenceMatrix) reorder(order []int) CooccurrenceMatrix {
+	reordered := CooccurrenceMatrix{
+		Keywords: make([]string, len(order)),
+		Records:  make([]int, len(order)),
+		Counts:   make([][]int, len(order)),
+	}
+	for x, i := range order {
+		reordered.Keywords[x] = m.Keywords[i]
+		reordered.Records[x] = m.Records[i]
+		reordered.Counts[x] = make([]int, len(order))
+		for y, j := range order {
+			reordered.Counts[x][y] = m.Counts[i][j]
+		}
+	}
+	return reordered
+}
+
+// PrintClusters prints the clusters with more than one keyword
+func (report ClusterReport) PrintClusters() {
+	fmt.Printf("\nKeyword Clusters (Jaccard threshold %.2f):\n", report.Threshold)
+	fmt.Println("------------------------------------------------------------")
+	singletons := 0
+	for _, cluster := range report.Clusters {
+		if len(cluster.Keywords) == 1 {
+			singletons++
+			continue
+		}
+		fmt.Printf("#%-3d %-20s records: %-6d cohesion: %.2f\n",
+			cluster.ID, cluster.Label, cluster.Records, cluster.Cohesion)
+		fmt.Printf("     %v\n", cluster.Keywords)
+	}
+	fmt.Printf("%d keywords did not join any cluster\n", singletons)
+	fmt.Println("------------------------------------------------------------")
+}
+
+// WriteClusters saves the cluster assignments and the matrix as JSON
+func (report ClusterReport) WriteClusters(savePath string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal clusters: %v", err)
+	}
+	if err := os.WriteFile(savePath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write clusters: %v", err)
+	}
+	fmt.Printf("✓ Cluster assignments saved to: %s\n", savePath)
+	return nil
+}
+
+// PlotHeatmap renders the Jaccard similarity of the matrix keywords as a PNG
+// heatmap. Cluster boundaries are outlined so related keywords form blocks.
+func (report ClusterReport) PlotHeatmap(savePath string) error {
+	matrix := report.Matrix
+	n := len(matrix.Keywords)
+	if n == 0 {
+		return fmt.Errorf("no keywords to plot")
+	}
+
+	font, err := GetChineseFont()
+	if err != nil {
+		font, err = chart.GetDefaultFont()
+		if err != nil {
+			return fmt.Errorf("failed to load font: %v", err)
+		}
+	}
+
+	const cell, margin, titleHeight = 28, 220, 50
+	size := max(margin+n*cell+40, 720)
+	r, err := chart.PNG(size, size+titleHeight)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %v", err)
+	}
+	fillRect(r, 0, 0, size, size+titleHeight, drawing.ColorWhite)
+
+	setFont(r, font, 16)
+	r.Text("Keyword Co-occurrence Heatmap (Jaccard similarity)", 20, 32)
+
+	top := margin + titleHeight
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			value := 1.0
+			if i != j {
+				value = matrix.Jaccard(i, j)
+			}
+			fillRect(r, margin+j*cell, top+i*cell, margin+(j+1)*cell, top+(i+1)*cell, heatColor(value))
+		}
+	}
+
+	setFont(r, font, 10)
+	for i, keyword := range matrix.Keywords {
+		box := r.MeasureText(keyword)
+		r.Text(keyword, margin-box.Width()-8, top+i*cell+cell/2+box.Height()/2)
+	}
+	for j, keyword := range matrix.Keywords {
+		r.SetTextRotation(-math.Pi / 2)
+		r.Text(keyword, margin+j*cell+cell/2+4, top-8)
+		r.ClearTextRotation()
+	}
+
+	// Outline clusters along the diagonal
+	r.SetStrokeColor(drawing.ColorBlack)
+	r.SetStrokeWidth(2)
+	start := 0
+	for _, cluster := range report.Clusters {
+		end := start + len(cluster.Keywords)
+		if len(cluster.Keywords) > 1 {
+			x0, y0 := margin+start*cell, top+start*cell
+			x1, y1 := margin+end*cell, top+end*cell
+			r.MoveTo(x0, y0)
+			r.LineTo(x1, y0)
+			r.LineTo(x1, y1)
+			r.LineTo(x0, y1)
+			r.LineTo(x0, y0)
+			r.Stroke()
+		}
+		start = end
+	}
+
+	f, err := os.Create(savePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer f.Close()
+	if err := r.Save(f); err != nil {
+		return fmt.Errorf("failed to render heatmap: %v", err)
+	}
+	fmt.Printf("✓ Heatmap saved to: %s\n", savePath)
+	return nil
+}
+
+// heatColor maps a similarity in [0, 1] from white to dark red
+func heatColor(value float64) drawing.Color {
+	value = math.Max(0, math.Min(1, value))
+	return drawing.Color{
+		R: uint8(255 - 75*value),
+		G: uint8(255 - 235*value),
+		B: uint8(255 - 225*value),
+		A: 255,
+	}
+}
+
+func fillRect(r chart.Renderer, x0, y0, x1, y1 int, color drawing.Color) {
+	r.SetFillColor(color)
+	r.SetStrokeColor(color)
+	r.SetStrokeWidth(0)
+	r.MoveTo(x0, y0)
+	r.LineTo(x1, y0)
+	r.LineTo(x1, y1)
+	r.LineTo(x0, y1)
+	r.LineTo(x0, y0)
+	r.FillStroke()
+}
+
+func setFont(r chart.Renderer, font *truetype.Font, size float64) {
+	r.SetFont(font)
+	r.SetFontSize(size)
+	r.SetFontColor(drawing.ColorBlack)
+}
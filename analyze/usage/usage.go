@@ -0,0 +1,220 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage reports the model token usage and cost of the compliance
+// reviews per tenant, from the detector_token_usage table the Postgres
+// handler of CompliK fills. Finance attributes the review spend to namespaces
+// and regions with it, and a namespace whose daily usage jumps points at
+// runaway scanning.
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Groupings of the report rows
+const (
+	ByNamespace = "namespace"
+	ByRegion    = "region"
+	ByDay       = "day"
+)
+
+// groupColumns are the columns each grouping reports
+var groupColumns = map[string][]string{
+	ByNamespace: {"region", "namespace"},
+	ByRegion:    {"region"},
+	ByDay:       {"day", "region", "namespace"},
+}
+
+// Options selects the period and grouping of a report
+type Options struct {
+	From    time.Time // First day of the period
+	To      time.Time // Day after the period
+	GroupBy string    // ByNamespace, ByRegion or ByDay
+	CSVPath string    // Output path of the rows as CSV, empty to skip
+}
+
+// DefaultOptions reports the last 30 days per namespace
+func DefaultOptions() Options {
+	to := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	return Options{From: to.AddDate(0, 0, -30), To: to, GroupBy: ByNamespace}
+}
+
+// Row is the usage of a group over the period. Day, Region and Namespace are
+// empty when not part of the grouping.
+type Row struct {
+	Day              string
+	Region           string
+	Namespace        string
+	Reviews          int64
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+}
+
+// Reporter queries the token usage
+type Reporter struct {
+	db *sql.DB
+}
+
+// NewReporter connects to the CompliK database at dsn
+func NewReporter(dsn string) (*Reporter, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+	return &Reporter{db: db}, nil
+}
+
+// Close closes the database connection
+func (r *Reporter) Close() error {
+	return r.db.Close()
+}
+
+// Query returns the usage of the period per group, most expensive first
+func (r *Reporter) Query(opts Options) ([]Row, error) {
+	columns, ok := groupColumns[opts.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q, use %s, %s or %s", opts.GroupBy, ByNamespace, ByRegion, ByDay)
+	}
+	group := strings.Join(columns, ", ")
+	query := "SELECT " + group + ", SUM(reviews), SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd)" +
+		" FROM detector_token_usage WHERE day >= ? AND day < ?" +
+		" GROUP BY " + group + " ORDER BY SUM(cost_usd) DESC, SUM(prompt_tokens) DESC"
+	rows, err := r.db.Query(query, opts.From.Format(time.DateOnly), opts.To.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var result []Row
+	for rows.Next() {
+		var row Row
+		fields := map[string]*string{"day": &row.Day, "region": &row.Region, "namespace": &row.Namespace}
+		dest := make([]any, 0, len(columns)+4)
+		for _, column := range columns {
+			dest = append(dest, fields[column])
+		}
+		dest = append(dest, &row.Reviews, &row.PromptTokens, &row.CompletionTokens, &row.CostUSD)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// Print writes rows as a table with a total line
+func Print(w io.Writer, rows []Row, groupBy string) error {
+	columns := groupColumns[groupBy]
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := append(upper(columns), "REVIEWS", "PROMPT TOKENS", "COMPLETION TOKENS", "COST USD")
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	var total Row
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(append(row.groups(columns), row.values()...), "\t")+"\t")
+		total.Reviews += row.Reviews
+		total.PromptTokens += row.PromptTokens
+		total.CompletionTokens += row.CompletionTokens
+		total.CostUSD += row.CostUSD
+	}
+	groups := make([]string, len(columns))
+	groups[0] = "TOTAL"
+	fmt.Fprintln(tw, strings.Join(append(groups, total.values()...), "\t")+"\t")
+	return tw.Flush()
+}
+
+// WriteCSV writes rows to path
+func WriteCSV(path string, rows []Row, groupBy string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+	columns := groupColumns[groupBy]
+	w := csv.NewWriter(file)
+	if err := w.Write(append(append([]string{}, columns...), "reviews", "prompt_tokens", "completion_tokens", "cost_usd")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := w.Write(append(row.groups(columns), row.values()...)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Run queries the usage, prints it and writes the CSV when requested
+func (r *Reporter) Run(w io.Writer, opts Options) error {
+	rows, err := r.Query(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Model usage from %s to %s\n\n", opts.From.Format(time.DateOnly),
+		opts.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err := Print(w, rows, opts.GroupBy); err != nil {
+		return err
+	}
+	if opts.CSVPath != "" {
+		if err := WriteCSV(opts.CSVPath, rows, opts.GroupBy); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\nUsage written to %s\n", opts.CSVPath)
+	}
+	return nil
+}
+
+func (r Row) groups(columns []string) []string {
+	fields := map[string]string{"day": r.Day, "region": r.Region, "namespace": r.Namespace}
+	groups := make([]string, 0, len(columns))
+	for _, column := range columns {
+		groups = append(groups, fields[column])
+	}
+	return groups
+}
+
+func (r Row) values() []string {
+	return []string{
+		strconv.FormatInt(r.Reviews, 10),
+		strconv.FormatInt(r.PromptTokens, 10),
+		strconv.FormatInt(r.CompletionTokens, 10),
+		strconv.FormatFloat(r.CostUSD, 'f', 2, 64),
+	}
+}
+
+func upper(columns []string) []string {
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		result = append(result, strings.ToUpper(column))
+	}
+	return result
+}
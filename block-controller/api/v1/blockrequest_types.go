@@ -40,6 +40,35 @@ type BlockRequestSpec struct {
 	// Action defines the action to be performed: 'locked' or 'active'
 	// +kubebuilder:validation:Enum=locked;active
 	Action string `json:"action"`
+
+	// LockProfile selects what locking means for the target namespaces: 'all' applies the
+	// ResourceQuota, scales workloads to zero and quarantines the network, while 'quota-only',
+	// 'scale-only' and 'network-only' apply a single measure. Ignored when Action is 'active'.
+	// +kubebuilder:validation:Enum=all;quota-only;scale-only;network-only
+	// +kubebuilder:default=all
+	// +optional
+	LockProfile string `json:"lockProfile,omitempty"`
+
+	// ExpiryPolicy defines what happens when the lock of a target namespace expires: 'unlock'
+	// restores the namespace, 'keep-locked-and-alert' keeps it locked and emits a warning event,
+	// and 'archive-then-delete' archives its workloads and deletes it after ExpiryGracePeriod.
+	// Deletion additionally requires the namespace to carry the
+	// 'core.clawcloud.run/allow-deletion: "true"' annotation.
+	// +kubebuilder:validation:Enum=unlock;keep-locked-and-alert;archive-then-delete
+	// +kubebuilder:default=keep-locked-and-alert
+	// +optional
+	ExpiryPolicy string `json:"expiryPolicy,omitempty"`
+
+	// ExpiryGracePeriod is how long an expired namespace is kept after it has been archived
+	// before it is deleted. Only used by the 'archive-then-delete' expiry policy.
+	// +optional
+	ExpiryGracePeriod *metav1.Duration `json:"expiryGracePeriod,omitempty"`
+
+	// DryRun computes the changes for the target namespaces and sends them as server-side
+	// dry runs, so they are validated by the API server but not persisted. What would change
+	// is logged and summarized in the namespace statuses.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // NamespaceStatus represents the status of a single namespace operation
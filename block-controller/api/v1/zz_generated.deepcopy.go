@@ -97,6 +97,11 @@ func (in *BlockRequestSpec) DeepCopyInto(out *BlockRequestSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExpiryGracePeriod != nil {
+		in, out := &in.ExpiryGracePeriod, &out.ExpiryGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockRequestSpec.
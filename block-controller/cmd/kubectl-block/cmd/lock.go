@@ -221,6 +221,11 @@ func (o *CommandOptions) updateNamespaceForLock(namespace string) error {
 		unlockTime := time.Now().Add(o.duration)
 		ns.Annotations[constants.UnlockTimestampLabel] = unlockTime.Format(time.RFC3339)
 	}
+	// Request the default lock profile, namespaces with an unlock timestamp but no
+	// profile are treated as locked before lock profiles existed
+	if _, ok := ns.Annotations[constants.LockProfileAnnotation]; !ok {
+		ns.Annotations[constants.LockProfileAnnotation] = constants.LockProfileAll
+	}
 
 	// Add operation reason
 	if opts.reason != "" {
@@ -17,16 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	apiv1 "github.com/bearslyricattack/CompliK/block-controller/api/v1"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -42,8 +56,34 @@ var (
 	lockedOnly bool
 	allLocked  bool
 	details    bool
+	profile    string
+
+	// 批量操作参数
+	file             string
+	fromBlockRequest string
+	qps              float64
+	burst            int
+
+	// 输出格式
+	output string
+)
+
+// planListLimit is the number of namespaces listed in the plan of a bulk operation
+const planListLimit = 20
+
+// Exit codes of the status command. Errors of any command exit with exitError.
+const (
+	exitError  = 1
+	exitLocked = 2
 )
 
+// exitCode ends the CLI with the given code without printing an error
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "kubectl-block",
@@ -57,16 +97,24 @@ namespaces with ease.`,
 	rootCmd.AddCommand(lockCommand())
 	rootCmd.AddCommand(unlockCommand())
 	rootCmd.AddCommand(statusCommand())
+	rootCmd.AddCommand(previewCommand())
+	rootCmd.AddCommand(restorePreviewCommand())
 
 	// 全局参数
 	rootCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "", "", "Path to the kubeconfig file")
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "The namespace to operate in")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "If true, only print the object that would be sent")
+	rootCmd.PersistentFlags().Float64Var(&qps, "qps", 5, "Maximum API requests per second sent to the cluster")
+	rootCmd.PersistentFlags().IntVar(&burst, "burst", 10, "Maximum burst of API requests above --qps")
 
 	if err := rootCmd.Execute(); err != nil {
+		var code exitCode
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
 
@@ -79,6 +127,8 @@ func lockCommand() *cobra.Command {
   kubectl block lock my-namespace
   kubectl block lock my-namespace --duration=24h --reason="Maintenance"
   kubectl block lock --selector=environment=dev
+  kubectl block lock -f namespaces.txt --force
+  kubectl block lock --from-blockrequest blockrequest.yaml
   kubectl block lock --all`,
 		RunE: runLock,
 	}
@@ -89,6 +139,7 @@ func lockCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "The namespace to lock")
 	cmd.Flags().StringVar(&selector, "selector", "", "Label selector to identify namespaces to lock")
 	cmd.Flags().BoolVar(&all, "all", false, "Lock all namespaces (excluding system namespaces)")
+	addBulkFlags(cmd, "lock")
 
 	return cmd
 }
@@ -102,7 +153,8 @@ func unlockCommand() *cobra.Command {
   kubectl block unlock my-namespace
   kubectl block unlock my-namespace --reason="Maintenance completed"
   kubectl block unlock --all-locked
-  kubectl block unlock --selector=environment=dev`,
+  kubectl block unlock --selector=environment=dev
+  kubectl block unlock -f namespaces.txt --force`,
 		RunE: runUnlock,
 	}
 
@@ -111,19 +163,30 @@ func unlockCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "The namespace to unlock")
 	cmd.Flags().StringVar(&selector, "selector", "", "Label selector to identify namespaces to unlock")
 	cmd.Flags().BoolVar(&allLocked, "all-locked", false, "Unlock all currently locked namespaces")
+	addBulkFlags(cmd, "unlock")
 
 	return cmd
 }
 
+// addBulkFlags adds the flags reading the target namespaces of a bulk operation from files
+func addBulkFlags(cmd *cobra.Command, verb string) {
+	cmd.Flags().StringVarP(&file, "file", "f", "", "File with the namespaces to "+verb+", one per line; '-' reads standard input")
+	cmd.Flags().StringVar(&fromBlockRequest, "from-blockrequest", "", "BlockRequest manifest whose namespaceNames and namespaceSelector select the namespaces to "+verb)
+}
+
 func statusCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status [namespace]",
 		Short: "Show the status of namespaces",
-		Long:  `Display the current status of namespaces, including lock status and remaining lock time.`,
+		Long: `Display the current status of namespaces, including lock status and remaining lock time.
+
+The command exits with 0 if no namespace is locked, 2 if any namespace is locked and 1 if
+the status of any namespace could not be read, so scripts can check it without parsing the output.`,
 		Example: `
   kubectl block status my-namespace
   kubectl block status --all
-  kubectl block status --locked-only`,
+  kubectl block status --locked-only
+  kubectl block status --all -o json`,
 		RunE: runStatus,
 	}
 
@@ -131,12 +194,46 @@ func statusCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&all, "all", false, "Show status of all namespaces")
 	cmd.Flags().BoolVar(&lockedOnly, "locked-only", false, "Show only locked namespaces")
 	cmd.Flags().BoolVarP(&details, "details", "D", false, "Show detailed information")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or yaml")
+
+	return cmd
+}
+
+func previewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview <namespace>",
+		Short: "Preview what locking a namespace would change",
+		Long: `Show the workloads that would be scaled down, suspended or deleted and the objects
+that would be created if the namespace were locked. Nothing is changed.`,
+		Example: `
+  kubectl block preview my-namespace
+  kubectl block preview my-namespace --profile=scale-only`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPreview,
+	}
+
+	cmd.Flags().StringVar(&profile, "profile", "", "Lock profile to preview (all, quota-only, scale-only, network-only); defaults to the namespace's requested profile")
+
+	return cmd
+}
+
+func restorePreviewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore-preview <namespace>",
+		Short: "Preview what unlocking a namespace would restore",
+		Long: `Show the workloads that would be scaled up or resumed from their block-controller
+annotations and the objects that would be removed if the namespace were unlocked. Nothing is changed.`,
+		Example: `
+  kubectl block restore-preview my-namespace`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRestorePreview,
+	}
 
 	return cmd
 }
 
 func runLock(cmd *cobra.Command, args []string) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := restConfig()
 	if err != nil {
 		return err
 	}
@@ -154,16 +251,27 @@ func runLock(cmd *cobra.Command, args []string) error {
 		namespaces, err = getAllNamespaces(clientset)
 	case selector != "":
 		namespaces, err = getNamespacesBySelector(clientset, selector)
+	case file != "" || fromBlockRequest != "":
+		namespaces, err = getBulkNamespaces(clientset)
 	default:
-		return fmt.Errorf("you must specify a namespace name, or use --selector or --all")
+		return fmt.Errorf("you must specify a namespace name, or use --selector, --file, --from-blockrequest or --all")
 	}
 
 	if err != nil {
 		return err
 	}
 
+	if len(namespaces) == 0 {
+		fmt.Println("ℹ️  No namespaces found to lock")
+		return nil
+	}
+
 	fmt.Printf("🔒 Planning to lock %d namespace(s):\n", len(namespaces))
-	for _, ns := range namespaces {
+	for i, ns := range namespaces {
+		if i == planListLimit {
+			fmt.Printf("  ... and %d more\n", len(namespaces)-planListLimit)
+			break
+		}
 		status, _ := getNamespaceStatus(clientset, ns)
 		statusIcon := "🔓"
 		if status == "locked" {
@@ -187,18 +295,9 @@ func runLock(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("\n🚀 Starting lock operation...\n")
-	successCount := 0
-	failureCount := 0
-
-	for _, ns := range namespaces {
-		if err := lockNamespace(clientset, ns); err != nil {
-			fmt.Printf("❌ Failed to lock namespace %s: %v\n", ns, err)
-			failureCount++
-		} else {
-			fmt.Printf("✅ Successfully locked namespace %s\n", ns)
-			successCount++
-		}
-	}
+	successCount, failureCount := runBulk(namespaces, "lock", "locked", func(ns string) error {
+		return lockNamespace(clientset, ns)
+	})
 
 	fmt.Printf("\n📊 Lock operation completed:\n")
 	fmt.Printf("  ✅ Success: %d\n", successCount)
@@ -212,7 +311,7 @@ func runLock(cmd *cobra.Command, args []string) error {
 }
 
 func runUnlock(cmd *cobra.Command, args []string) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := restConfig()
 	if err != nil {
 		return err
 	}
@@ -228,16 +327,29 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 		namespaces = args
 	case allLocked:
 		namespaces, err = getLockedNamespaces(clientset)
+	case selector != "":
+		namespaces, err = getNamespacesBySelector(clientset, selector)
+	case file != "" || fromBlockRequest != "":
+		namespaces, err = getBulkNamespaces(clientset)
 	default:
-		return fmt.Errorf("you must specify a namespace name, or use --all-locked")
+		return fmt.Errorf("you must specify a namespace name, or use --selector, --file, --from-blockrequest or --all-locked")
 	}
 
 	if err != nil {
 		return err
 	}
 
+	if len(namespaces) == 0 {
+		fmt.Println("ℹ️  No namespaces found to unlock")
+		return nil
+	}
+
 	fmt.Printf("🔓 Planning to unlock %d namespace(s):\n", len(namespaces))
-	for _, ns := range namespaces {
+	for i, ns := range namespaces {
+		if i == planListLimit {
+			fmt.Printf("  ... and %d more\n", len(namespaces)-planListLimit)
+			break
+		}
 		fmt.Printf("  🔒 %s\n", ns)
 	}
 
@@ -253,18 +365,9 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("\n🚀 Starting unlock operation...\n")
-	successCount := 0
-	failureCount := 0
-
-	for _, ns := range namespaces {
-		if err := unlockNamespace(clientset, ns); err != nil {
-			fmt.Printf("❌ Failed to unlock namespace %s: %v\n", ns, err)
-			failureCount++
-		} else {
-			fmt.Printf("✅ Successfully unlocked namespace %s\n", ns)
-			successCount++
-		}
-	}
+	successCount, failureCount := runBulk(namespaces, "unlock", "unlocked", func(ns string) error {
+		return unlockNamespace(clientset, ns)
+	})
 
 	fmt.Printf("\n📊 Unlock operation completed:\n")
 	fmt.Printf("  ✅ Success: %d\n", successCount)
@@ -278,7 +381,11 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if output != "" && output != "json" && output != "yaml" {
+		return fmt.Errorf("unsupported output format %q, use json or yaml", output)
+	}
+
+	config, err := restConfig()
 	if err != nil {
 		return err
 	}
@@ -304,34 +411,440 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf("📊 Namespace Status Report\n\n")
-	fmt.Printf("NAMESPACE\tSTATUS\tREMAINING\tWORKLOADS\n")
-
+	report := statusReport{Namespaces: make([]namespaceStatus, 0, len(namespaces))}
 	for _, ns := range namespaces {
-		status, err := getNamespaceStatus(clientset, ns)
+		status := getNamespaceReport(clientset, ns)
+		switch {
+		case status.Error != "":
+			report.Failed++
+		case status.Locked:
+			report.Locked++
+		}
+		report.Namespaces = append(report.Namespaces, status)
+	}
+	report.Total = len(report.Namespaces)
+
+	if err := printStatusReport(report); err != nil {
+		return err
+	}
+
+	// 退出码供脚本判断，不再打印错误信息
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	switch {
+	case report.Failed > 0:
+		return exitCode(exitError)
+	case report.Locked > 0:
+		return exitCode(exitLocked)
+	}
+	return nil
+}
+
+// statusReport is the structured output of the status command. Its field names are part of
+// the CLI interface and must not change.
+type statusReport struct {
+	Namespaces []namespaceStatus `json:"namespaces"`
+	Total      int               `json:"total"`
+	Locked     int               `json:"locked"`
+	Failed     int               `json:"failed"`
+}
+
+// namespaceStatus is the status of a single namespace
+type namespaceStatus struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Locked     bool   `json:"locked"`
+	UnlockTime string `json:"unlockTime,omitempty"`
+	Remaining  string `json:"remaining,omitempty"`
+	Workloads  int    `json:"workloads"`
+	Error      string `json:"error,omitempty"`
+}
+
+func getNamespaceReport(clientset *kubernetes.Clientset, namespace string) namespaceStatus {
+	result := namespaceStatus{Name: namespace}
+	ns, err := clientset.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = ns.Labels["clawcloud.run/status"]
+	if result.Status == "" {
+		result.Status = "active"
+	}
+	result.Locked = result.Status == "locked"
+	if unlockTime, err := time.Parse(time.RFC3339, ns.Annotations["clawcloud.run/unlock-timestamp"]); err == nil {
+		result.UnlockTime = unlockTime.UTC().Format(time.RFC3339)
+		result.Remaining = formatRemaining(unlockTime)
+	}
+	result.Workloads = countWorkloads(clientset, namespace)
+	return result
+}
+
+func printStatusReport(report statusReport) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
-			fmt.Printf("%s\tError\t\t-\n", ns)
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	fmt.Printf("📊 Namespace Status Report\n\n")
+	fmt.Printf("NAMESPACE\tSTATUS\tREMAINING\tWORKLOADS\n")
+	for _, ns := range report.Namespaces {
+		if ns.Error != "" {
+			fmt.Printf("%s\tError\t\t-\n", ns.Name)
 			continue
 		}
 
 		statusIcon := "🔓"
-		if status == "locked" {
+		if ns.Locked {
 			statusIcon = "🔒"
 		}
 
-		remaining := getRemainingTime(clientset, ns)
+		remaining := ns.Remaining
 		if remaining == "" {
 			remaining = "-"
 		}
 
-		workloads := countWorkloads(clientset, ns)
+		fmt.Printf("%s\t%s %s\t%s\t%d\n", ns.Name, statusIcon, ns.Status, remaining, ns.Workloads)
+	}
+	return nil
+}
+
+// previewChange is a single change that a lock or unlock would apply
+type previewChange struct {
+	kind     string
+	name     string
+	replicas string
+	action   string
+}
+
+func runPreview(cmd *cobra.Command, args []string) error {
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, args[0], metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	lockProfile := profile
+	if lockProfile == "" {
+		lockProfile = ns.Annotations[constants.LockProfileAnnotation]
+	}
+	lockProfile = utils.NormalizeLockProfile(lockProfile)
+	components := utils.LockProfileComponents(lockProfile)
+
+	var changes []previewChange
+	if components.Quota {
+		changes = append(changes, previewChange{kind: "ResourceQuota", name: constants.ResourceQuotaName, replicas: "-", action: "create"})
+	}
+	if components.Network {
+		changes = append(changes, previewChange{kind: "NetworkPolicy", name: constants.NetworkPolicyName, replicas: "-", action: "create"})
+	}
+	if components.Scale {
+		scaleChanges, err := previewScaleDown(ctx, clientset, ns.Name)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, scaleChanges...)
+	}
+
+	fmt.Printf("🔍 Lock preview for namespace %s (profile: %s)\n\n", ns.Name, lockProfile)
+	printChanges(changes)
+	return nil
+}
+
+// previewScaleDown mirrors the scale down of the namespace scanner without applying it
+func previewScaleDown(ctx context.Context, clientset *kubernetes.Clientset, namespace string) ([]previewChange, error) {
+	var changes []previewChange
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	// exemptTargets holds the exempt workloads as "Kind/name", their autoscalers are not paused
+	exemptDeployments := make(map[string]bool)
+	exemptTargets := make(map[string]bool)
+	for _, d := range deployments.Items {
+		change, exempt := previewWorkload("Deployment", &d, d.Spec.Template.Spec, d.Spec.Replicas)
+		if exempt {
+			exemptDeployments[d.Name] = true
+			exemptTargets["Deployment/"+d.Name] = true
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, sts := range statefulSets.Items {
+		change, exempt := previewWorkload("StatefulSet", &sts, sts.Spec.Template.Spec, sts.Spec.Replicas)
+		if exempt {
+			exemptTargets["StatefulSet/"+sts.Name] = true
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for _, rs := range replicaSets.Items {
+		if owner := metav1.GetControllerOf(&rs); owner != nil && owner.Kind == "Deployment" && exemptDeployments[owner.Name] {
+			continue
+		}
+		if replicas := int32Value(rs.Spec.Replicas); replicas != 0 {
+			changes = append(changes, previewChange{kind: "ReplicaSet", name: rs.Name, replicas: strconv.Itoa(int(replicas)), action: "scale to 0"})
+		}
+	}
+
+	rcs, err := clientset.CoreV1().ReplicationControllers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicationcontrollers: %w", err)
+	}
+	for _, rc := range rcs.Items {
+		if replicas := int32Value(rc.Spec.Replicas); replicas != 0 {
+			changes = append(changes, previewChange{kind: "ReplicationController", name: rc.Name, replicas: strconv.Itoa(int(replicas)), action: "scale to 0"})
+		}
+	}
+
+	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontalpodautoscalers: %w", err)
+	}
+	for _, hpa := range hpas.Items {
+		if _, paused := hpa.Annotations[constants.OriginalHPAScaleUpAnnotation]; paused || utils.HPATargetsExempt(&hpa, exemptTargets) {
+			continue
+		}
+		changes = append(changes, previewChange{kind: "HorizontalPodAutoscaler", name: hpa.Name, replicas: "-", action: "pause"})
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for _, cj := range cronJobs.Items {
+		if cj.Spec.Suspend != nil && !*cj.Spec.Suspend {
+			changes = append(changes, previewChange{kind: "CronJob", name: cj.Name, replicas: "-", action: "suspend"})
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		isStandalone := true
+		for _, owner := range pod.OwnerReferences {
+			if owner.Kind == "ReplicaSet" || owner.Kind == "StatefulSet" || owner.Kind == "ReplicationController" || owner.Kind == "Job" {
+				isStandalone = false
+				break
+			}
+		}
+		if isStandalone {
+			changes = append(changes, previewChange{kind: "Pod", name: pod.Name, replicas: "-", action: "evict (PDB-aware)"})
+		}
+	}
+
+	return changes, nil
+}
+
+// previewWorkload returns the change a lock would apply to a Deployment or StatefulSet
+// and whether the workload is exempt from scale down
+func previewWorkload(kind string, obj metav1.Object, spec corev1.PodSpec, replicas *int32) (*previewChange, bool) {
+	current := strconv.Itoa(int(int32Value(replicas)))
+	action := "scale to 0"
+	if utils.IsLockExempt(obj) {
+		err := utils.ValidateLockExemption(spec)
+		if err == nil {
+			return &previewChange{kind: kind, name: obj.GetName(), replicas: current, action: "keep (lock exempt)"}, true
+		}
+		action = fmt.Sprintf("scale to 0 (exemption ignored: %v)", err)
+	}
+	if int32Value(replicas) == 0 {
+		return nil, false
+	}
+	return &previewChange{kind: kind, name: obj.GetName(), replicas: current, action: action}, false
+}
+
+func runRestorePreview(cmd *cobra.Command, args []string) error {
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, args[0], metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	appliedProfile := ns.Annotations[constants.AppliedLockProfileAnnotation]
+	components := utils.LockProfileComponents(appliedProfile)
+	if appliedProfile == "" {
+		appliedProfile = "not recorded, reverting all"
+	}
 
-		fmt.Printf("%s\t%s %s\t%s\t%d\n", ns, statusIcon, status, remaining, workloads)
+	var changes []previewChange
+	if components.Quota {
+		if _, err := clientset.CoreV1().ResourceQuotas(ns.Name).Get(ctx, constants.ResourceQuotaName, metav1.GetOptions{}); err == nil {
+			changes = append(changes, previewChange{kind: "ResourceQuota", name: constants.ResourceQuotaName, replicas: "-", action: "delete"})
+		}
+	}
+	if components.Network {
+		if _, err := clientset.NetworkingV1().NetworkPolicies(ns.Name).Get(ctx, constants.NetworkPolicyName, metav1.GetOptions{}); err == nil {
+			changes = append(changes, previewChange{kind: "NetworkPolicy", name: constants.NetworkPolicyName, replicas: "-", action: "delete"})
+		}
+	}
+	if components.Scale {
+		restoreChanges, err := previewRestore(ctx, clientset, ns.Name)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, restoreChanges...)
 	}
 
+	fmt.Printf("🔍 Restore preview for namespace %s (applied profile: %s)\n\n", ns.Name, appliedProfile)
+	printChanges(changes)
 	return nil
 }
 
+// previewRestore lists what unlock would restore from the block-controller annotations
+func previewRestore(ctx context.Context, clientset *kubernetes.Clientset, namespace string) ([]previewChange, error) {
+	var changes []previewChange
+	restore := func(kind, name string, current *int32, annotations map[string]string) {
+		original, ok := annotations[constants.OriginalReplicasAnnotation]
+		if !ok {
+			return
+		}
+		action := "scale to " + original
+		if _, err := strconv.Atoi(original); err != nil {
+			action = fmt.Sprintf("skip (invalid annotation %q)", original)
+		}
+		changes = append(changes, previewChange{kind: kind, name: name, replicas: strconv.Itoa(int(int32Value(current))), action: action})
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		restore("Deployment", d.Name, d.Spec.Replicas, d.Annotations)
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, sts := range statefulSets.Items {
+		restore("StatefulSet", sts.Name, sts.Spec.Replicas, sts.Annotations)
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for _, rs := range replicaSets.Items {
+		restore("ReplicaSet", rs.Name, rs.Spec.Replicas, rs.Annotations)
+	}
+
+	rcs, err := clientset.CoreV1().ReplicationControllers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicationcontrollers: %w", err)
+	}
+	for _, rc := range rcs.Items {
+		restore("ReplicationController", rc.Name, rc.Spec.Replicas, rc.Annotations)
+	}
+
+	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontalpodautoscalers: %w", err)
+	}
+	for _, hpa := range hpas.Items {
+		if _, paused := hpa.Annotations[constants.OriginalHPAScaleUpAnnotation]; paused {
+			changes = append(changes, previewChange{kind: "HorizontalPodAutoscaler", name: hpa.Name, replicas: "-", action: "resume"})
+		}
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for _, cj := range cronJobs.Items {
+		original, ok := cj.Annotations[constants.OriginalSuspendAnnotation]
+		if !ok {
+			continue
+		}
+		action := "set suspend=" + original
+		if _, err := strconv.ParseBool(original); err != nil {
+			action = fmt.Sprintf("skip (invalid annotation %q)", original)
+		}
+		changes = append(changes, previewChange{kind: "CronJob", name: cj.Name, replicas: "-", action: action})
+	}
+
+	return changes, nil
+}
+
+func printChanges(changes []previewChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tREPLICAS\tACTION")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.kind, c.name, c.replicas, c.action)
+	}
+	w.Flush()
+}
+
+// restConfig loads the kubeconfig; all API requests are rate limited client side by --qps and --burst
+func restConfig() (*rest.Config, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	config.QPS = float32(qps)
+	config.Burst = burst
+	return config, nil
+}
+
+func newClientset() (*kubernetes.Clientset, error) {
+	config, err := restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+func int32Value(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
 // Helper functions
 func lockNamespace(clientset *kubernetes.Clientset, namespace string) error {
 	if dryRun {
@@ -357,6 +870,9 @@ func lockNamespace(clientset *kubernetes.Clientset, namespace string) error {
 		unlockTime := time.Now().Add(duration)
 		ns.Annotations["clawcloud.run/unlock-timestamp"] = unlockTime.Format(time.RFC3339)
 	}
+	if _, ok := ns.Annotations[constants.LockProfileAnnotation]; !ok {
+		ns.Annotations[constants.LockProfileAnnotation] = constants.LockProfileAll
+	}
 	ns.Annotations["clawcloud.run/lock-reason"] = reason
 
 	_, err = clientset.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
@@ -404,27 +920,8 @@ func getNamespaceStatus(clientset *kubernetes.Clientset, namespace string) (stri
 	return status, nil
 }
 
-func getRemainingTime(clientset *kubernetes.Clientset, namespace string) string {
-	ctx := context.TODO()
-	ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
-	if err != nil {
-		return ""
-	}
-
-	if ns.Annotations == nil {
-		return ""
-	}
-
-	unlockTimeStr := ns.Annotations["clawcloud.run/unlock-timestamp"]
-	if unlockTimeStr == "" {
-		return ""
-	}
-
-	unlockTime, err := time.Parse(time.RFC3339, unlockTimeStr)
-	if err != nil {
-		return ""
-	}
-
+// formatRemaining formats the time left until unlockTime
+func formatRemaining(unlockTime time.Time) string {
 	if time.Now().After(unlockTime) {
 		return "expired"
 	}
@@ -502,6 +999,172 @@ func getNamespacesBySelector(clientset *kubernetes.Clientset, selector string) (
 	return result, nil
 }
 
+// getBulkNamespaces returns the namespaces of --file and --from-blockrequest without duplicates,
+// in the order they were read
+func getBulkNamespaces(clientset *kubernetes.Clientset) ([]string, error) {
+	var namespaces []string
+	if file != "" {
+		fromFile, err := readNamespaceFile(file)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, fromFile...)
+	}
+	if fromBlockRequest != "" {
+		fromRequest, err := readBlockRequestNamespaces(clientset, fromBlockRequest)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, fromRequest...)
+	}
+
+	seen := sets.New[string]()
+	result := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if !seen.Has(ns) {
+			seen.Insert(ns)
+			result = append(result, ns)
+		}
+	}
+	return result, nil
+}
+
+// readNamespaceFile reads one namespace per line; blank lines and '#' comments are skipped
+func readNamespaceFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var namespaces []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			namespaces = append(namespaces, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return namespaces, nil
+}
+
+// readBlockRequestNamespaces returns the namespaces targeted by the BlockRequests of a YAML or
+// JSON manifest, which may hold several documents. Selectors are resolved against the cluster.
+func readBlockRequestNamespaces(clientset *kubernetes.Clientset, path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var namespaces []string
+	decoder := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var request apiv1.BlockRequest
+		if err := decoder.Decode(&request); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if request.Kind != "" && request.Kind != "BlockRequest" {
+			return nil, fmt.Errorf("%s: expected a BlockRequest, got %s", path, request.Kind)
+		}
+		namespaces = append(namespaces, request.Spec.NamespaceNames...)
+		if request.Spec.NamespaceSelector != nil {
+			labelSelector, err := metav1.LabelSelectorAsSelector(request.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespaceSelector of BlockRequest %s: %w", request.Name, err)
+			}
+			selected, err := getNamespacesBySelector(clientset, labelSelector.String())
+			if err != nil {
+				return nil, err
+			}
+			namespaces = append(namespaces, selected...)
+		}
+	}
+	return namespaces, nil
+}
+
+// runBulk applies operation to each namespace. A single namespace is reported as before;
+// larger batches draw a progress bar and only print failures, so hundreds of namespaces
+// stay readable.
+func runBulk(namespaces []string, verb, done string, operation func(string) error) (int, int) {
+	successCount, failureCount := 0, 0
+	bar := newProgressBar(os.Stderr, len(namespaces))
+	for _, ns := range namespaces {
+		err := operation(ns)
+		if len(namespaces) == 1 || verbose || err != nil {
+			bar.clear()
+			if err != nil {
+				fmt.Printf("❌ Failed to %s namespace %s: %v\n", verb, ns, err)
+			} else {
+				fmt.Printf("✅ Successfully %s namespace %s\n", done, ns)
+			}
+		}
+		if err != nil {
+			failureCount++
+		} else {
+			successCount++
+		}
+		if len(namespaces) > 1 {
+			bar.set(successCount+failureCount, failureCount)
+		}
+	}
+	bar.finish()
+	return successCount, failureCount
+}
+
+// progressBar draws a single updating line like "[#####-----] 50/100 (2 failed)"
+type progressBar struct {
+	out     io.Writer
+	total   int
+	drawn   bool
+	current string
+}
+
+func newProgressBar(out io.Writer, total int) *progressBar {
+	return &progressBar{out: out, total: total}
+}
+
+func (p *progressBar) set(processed, failed int) {
+	const width = 30
+	filled := 0
+	if p.total > 0 {
+		filled = processed * width / p.total
+	}
+	p.current = fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat("-", width-filled), processed, p.total)
+	if failed > 0 {
+		p.current += fmt.Sprintf(" (%d failed)", failed)
+	}
+	fmt.Fprintf(p.out, "\r%s", p.current)
+	p.drawn = true
+}
+
+// clear erases the bar so a message can be printed; the next set draws it again
+func (p *progressBar) clear() {
+	if p.drawn {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", len(p.current)))
+		p.drawn = false
+	}
+}
+
+func (p *progressBar) finish() {
+	if p.drawn {
+		fmt.Fprintln(p.out)
+		p.drawn = false
+	}
+}
+
 func countWorkloads(clientset *kubernetes.Clientset, namespace string) int {
 	ctx := context.TODO()
 	count := 0
@@ -22,6 +22,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -41,6 +42,8 @@ import (
 
 	corev1 "github.com/bearslyricattack/CompliK/block-controller/api/v1"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/controller"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/debug"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/dryrun"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/scanner"
 	// +kubebuilder:scaffold:imports
 )
@@ -94,6 +97,26 @@ func main() {
 	flag.DurationVar(&slowScanInterval, "slow-scan-interval", 1*time.Hour, "The interval for the slow full scan (janitor).")
 	var scanBatchSize int
 	flag.IntVar(&scanBatchSize, "scan-batch-size", 100, "The batch size for scanning namespaces.")
+	var scanWorkers int
+	flag.IntVar(&scanWorkers, "scan-workers", 10, "The number of namespaces processed concurrently by the scanner.")
+	var scanJitter time.Duration
+	flag.DurationVar(&scanJitter, "scan-jitter", 50*time.Millisecond, "The maximum random delay before the scanner processes a namespace.")
+	var archiveNamespace string
+	flag.StringVar(&archiveNamespace, "archive-namespace", "block-system",
+		"The namespace where the archive-then-delete expiry policy stores namespace archives.")
+	var pdbEvictionTimeout time.Duration
+	flag.DurationVar(&pdbEvictionTimeout, "pdb-eviction-timeout", 10*time.Minute,
+		"How long a PodDisruptionBudget may block the eviction of a standalone pod in a locked namespace "+
+			"before the pod is deleted. Use 0 to always wait for the budget.")
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Send all namespace and workload mutations as server-side dry runs and log what would change "+
+			"instead of persisting it.")
+	var debugAddr, debugTokenFile string
+	flag.StringVar(&debugAddr, "debug-bind-address", "",
+		"The address the pprof and runtime debug endpoints bind to. Leave empty to disable them.")
+	flag.StringVar(&debugTokenFile, "debug-token-file", "",
+		"A file with the bearer token of the debug endpoints, required unless they bind to the loopback interface.")
 	var webhookEnable bool
 	flag.BoolVar(&webhookEnable, "web-hook-enable", true, "enable webhook server")
 
@@ -213,7 +236,44 @@ func main() {
 		os.Exit(1)
 	}
 
+	// In dry-run mode the lock and unlock mutations are validated by the API server but not persisted
+	workloadClient, scannerClient := mgr.GetClient(), client.Client(nonCachingClient)
+	if dryRun {
+		setupLog.Info("Dry-run mode enabled, mutations are not persisted")
+		dryRunLog := ctrl.Log.WithName("dry-run")
+		workloadClient = dryrun.NewClient(mgr.GetClient(), dryRunLog)
+		scannerClient = dryrun.NewClient(nonCachingClient, dryRunLog)
+	}
+
+	nsScanner := &scanner.NamespaceScanner{
+
+		Client: scannerClient, // Use non-caching client for all scanner operations
+
+		Log: ctrl.Log.WithName("namespace-scanner"),
+
+		Scheme: mgr.GetScheme(),
+
+		LockDuration: lockDuration,
+
+		FastScanInterval: fastScanInterval,
+
+		SlowScanInterval: slowScanInterval,
+
+		ScanBatchSize: scanBatchSize,
+
+		ScanWorkers: scanWorkers,
+
+		ScanJitter: scanJitter,
+
+		ArchiveNamespace: archiveNamespace,
+
+		Recorder: mgr.GetEventRecorderFor("namespace-scanner"),
+
+		PDBEvictionTimeout: pdbEvictionTimeout,
+	}
+
 	// Choose between optimized architecture or original architecture based on configuration
+	var optimizedController *controller.MemoryEfficientController
 	if enableOptimizedArchitecture {
 		// Use optimized architecture
 		setupLog.Info("Using optimized memory-efficient architecture",
@@ -221,11 +281,11 @@ func main() {
 			"workerCount", workerCount,
 			"maxConcurrentReconciles", maxConcurrentReconciles)
 
-		optimizedController := controller.NewMemoryEfficientController(
-			mgr.GetClient(),
+		optimizedController = controller.NewMemoryEfficientController(
+			workloadClient,
 			mgr.GetScheme(),
 			int64(maxMemoryMB),
-		)
+		).WithListReader(nonCachingClient) // The cache does not support paging with Continue
 
 		if err := optimizedController.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create optimized controller", "controller", "MemoryEfficientController")
@@ -239,33 +299,43 @@ func main() {
 			NonCachingClient:        nonCachingClient, // Non-caching client for List
 			Scheme:                  mgr.GetScheme(),
 			MaxConcurrentReconciles: maxConcurrentReconciles,
+			DryRun:                  dryRun,
+			Scanner:                 nsScanner, // Dry runs go through the lock code path of the scanner
 		}).SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "BlockRequest")
 			os.Exit(1)
 		}
 	}
 
-	nsScanner := &scanner.NamespaceScanner{
-
-		Client: nonCachingClient, // Use non-caching client for all scanner operations
-
-		Log: ctrl.Log.WithName("namespace-scanner"),
-
-		Scheme: mgr.GetScheme(),
-
-		LockDuration: lockDuration,
-
-		FastScanInterval: fastScanInterval,
-
-		SlowScanInterval: slowScanInterval,
-
-		ScanBatchSize: scanBatchSize,
-	}
 	if err := mgr.Add(nsScanner); err != nil {
 		setupLog.Error(err, "unable to add scanner to manager")
 		os.Exit(1)
 	}
 
+	if debugAddr != "" {
+		var debugToken string
+		if debugTokenFile != "" {
+			token, err := os.ReadFile(debugTokenFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read debug token")
+				os.Exit(1)
+			}
+			debugToken = strings.TrimSpace(string(token))
+		}
+		if err := debug.CheckExposure(debugAddr, debugToken); err != nil {
+			setupLog.Error(err, "refusing to expose debug endpoints")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&debug.Server{
+			Addr:  debugAddr,
+			Token: debugToken,
+			Log:   ctrl.Log.WithName("debug"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add debug server to manager")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -276,6 +346,24 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddHealthzCheck("namespace-scanner", nsScanner.Healthz); err != nil {
+		setupLog.Error(err, "unable to set up scanner health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("namespace-scanner", nsScanner.Readyz); err != nil {
+		setupLog.Error(err, "unable to set up scanner ready check")
+		os.Exit(1)
+	}
+	if optimizedController != nil {
+		if err := mgr.AddHealthzCheck("memory-efficient-controller", optimizedController.Healthz); err != nil {
+			setupLog.Error(err, "unable to set up controller health check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("memory-efficient-controller", optimizedController.Readyz); err != nil {
+			setupLog.Error(err, "unable to set up controller ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -29,13 +29,63 @@ const (
 
 	// StatusLabel is the label key used to mark namespace status (locked/active)
 	StatusLabel = "clawcloud.run/status"
+	// LockExemptLabel is the label key used to exempt Deployments and StatefulSets from scale down
+	LockExemptLabel = "clawcloud.run/lock-exempt"
 	// UnlockTimestampLabel is the label key used to store unlock timestamp
 	UnlockTimestampLabel = "clawcloud.run/unlock-timestamp"
 	// OriginalReplicasAnnotation is the annotation key used to store original replica count
 	OriginalReplicasAnnotation = "core.clawcloud.run/original-replicas"
 	// OriginalSuspendAnnotation is the annotation key used to store original suspend state
 	OriginalSuspendAnnotation = "core.clawcloud.run/original-suspend"
+	// OriginalHPAScaleUpAnnotation is the annotation key used to store the original scale up
+	// behavior of a HorizontalPodAutoscaler paused during a lock
+	OriginalHPAScaleUpAnnotation = "core.clawcloud.run/original-hpa-scale-up"
+	// EvictionBlockedAtAnnotation is the annotation key used to record when the eviction of a pod
+	// was first refused by a PodDisruptionBudget
+	EvictionBlockedAtAnnotation = "core.clawcloud.run/eviction-blocked-at"
+	// LockProfileAnnotation is the annotation key used to store the requested lock profile
+	LockProfileAnnotation = "core.clawcloud.run/lock-profile"
+	// AppliedLockProfileAnnotation is the annotation key used to store the lock profile that was
+	// actually applied, so that unlock only reverts what was locked
+	AppliedLockProfileAnnotation = "core.clawcloud.run/applied-lock-profile"
+	// ExpiryPolicyAnnotation is the annotation key used to store the lock expiry policy
+	ExpiryPolicyAnnotation = "core.clawcloud.run/expiry-policy"
+	// ExpiryGracePeriodAnnotation is the annotation key used to store the grace period before an
+	// expired namespace is deleted
+	ExpiryGracePeriodAnnotation = "core.clawcloud.run/expiry-grace-period"
+	// ExpiredAtAnnotation is the annotation key used to record when a lock expired
+	ExpiredAtAnnotation = "core.clawcloud.run/expired-at"
+	// DeletionScheduledAnnotation is the annotation key used to store when an expired namespace
+	// will be deleted
+	DeletionScheduledAnnotation = "core.clawcloud.run/deletion-scheduled-at"
+	// AllowDeletionAnnotation is the annotation key that must be set to "true" on a namespace
+	// before block-controller is allowed to delete it
+	AllowDeletionAnnotation = "core.clawcloud.run/allow-deletion"
+	// ArchivedNamespaceLabel is the label key used to mark the archive of a deleted namespace
+	ArchivedNamespaceLabel = "core.clawcloud.run/archived-namespace"
+
+	// LockProfileAll applies the ResourceQuota, scales workloads to zero and quarantines the network
+	LockProfileAll = "all"
+	// LockProfileQuotaOnly only applies the ResourceQuota
+	LockProfileQuotaOnly = "quota-only"
+	// LockProfileScaleOnly only scales workloads to zero
+	LockProfileScaleOnly = "scale-only"
+	// LockProfileNetworkOnly only applies the quarantine NetworkPolicy
+	LockProfileNetworkOnly = "network-only"
+	// LockProfileQuotaScale applies the ResourceQuota and scales workloads to zero, the lock of
+	// namespaces that were locked before lock profiles existed
+	LockProfileQuotaScale = "quota-scale"
+
+	// ExpiryPolicyUnlock unlocks the namespace when its lock expires
+	ExpiryPolicyUnlock = "unlock"
+	// ExpiryPolicyKeepLocked keeps the namespace locked and emits a warning event when its lock expires
+	ExpiryPolicyKeepLocked = "keep-locked-and-alert"
+	// ExpiryPolicyArchiveThenDelete archives the namespace workloads and deletes the namespace
+	// after a grace period when its lock expires
+	ExpiryPolicyArchiveThenDelete = "archive-then-delete"
 
 	// ResourceQuotaName is the name of the ResourceQuota object created by block-controller
 	ResourceQuotaName = "block-controller-quota"
+	// NetworkPolicyName is the name of the quarantine NetworkPolicy object created by block-controller
+	NetworkPolicyName = "block-controller-quarantine"
 )
@@ -20,9 +20,13 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	apiv1 "github.com/bearslyricattack/CompliK/block-controller/api/v1"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/dryrun"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/scanner"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +44,13 @@ type BlockRequestReconciler struct {
 	NonCachingClient        client.Client
 	Scheme                  *runtime.Scheme
 	MaxConcurrentReconciles int
+	// DryRun sends the namespace changes of every BlockRequest as server-side dry runs,
+	// as if all of them set spec.dryRun
+	DryRun bool
+	// Scanner is the namespace scanner that locks the namespaces. Dry runs go through its
+	// lock code path, including pod evictions and lock expiry; without it they simulate the
+	// stream processor.
+	Scanner *scanner.NamespaceScanner
 }
 
 // +kubebuilder:rbac:groups=core.clawcloud.run,resources=blockrequests,verbs=get;list;watch;update;patch
@@ -149,7 +160,23 @@ func (r *BlockRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				namespace.Labels = make(map[string]string)
 			}
 			namespace.Labels[constants.StatusLabel] = blockRequest.Spec.Action
-			if err := r.Update(ctx, &namespace); err != nil {
+			if blockRequest.Spec.Action == constants.LockedStatus {
+				// The scanner records the profile it applied separately, so unlock
+				// reverts the right measures even if this annotation changes later
+				if namespace.Annotations == nil {
+					namespace.Annotations = make(map[string]string)
+				}
+				namespace.Annotations[constants.LockProfileAnnotation] = utils.NormalizeLockProfile(blockRequest.Spec.LockProfile)
+				namespace.Annotations[constants.ExpiryPolicyAnnotation] = utils.NormalizeExpiryPolicy(blockRequest.Spec.ExpiryPolicy)
+				if blockRequest.Spec.ExpiryGracePeriod != nil {
+					namespace.Annotations[constants.ExpiryGracePeriodAnnotation] = blockRequest.Spec.ExpiryGracePeriod.Duration.String()
+				} else {
+					delete(namespace.Annotations, constants.ExpiryGracePeriodAnnotation)
+				}
+			}
+			if r.DryRun || blockRequest.Spec.DryRun {
+				msg = r.dryRunNamespace(ctx, &namespace, blockRequest.Spec.Action)
+			} else if err := r.Update(ctx, &namespace); err != nil {
 				msg = "Failed to update namespace label"
 				log.Error(err, msg, "namespace", nsName)
 			} else {
@@ -172,6 +199,37 @@ func (r *BlockRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// dryRunNamespace sends the label update of a namespace and the lock or unlock of its
+// workloads as server-side dry runs. The status and finalizer of the BlockRequest itself are
+// still written, so the batches progress as usual. It returns the namespace status message.
+func (r *BlockRequestReconciler) dryRunNamespace(ctx context.Context, namespace *corev1.Namespace, action string) string {
+	log := logf.FromContext(ctx).WithValues("namespace", namespace.Name)
+	// The workloads are listed in pages, which the cache of r.Client does not support
+	dryRunClient := dryrun.NewClient(r.NonCachingClient, log)
+
+	if err := dryRunClient.Update(ctx, namespace); err != nil {
+		log.Error(err, "Dry run of namespace label update failed")
+		return "Dry run: failed to update namespace label: " + err.Error()
+	}
+
+	var err error
+	if r.Scanner != nil {
+		err = r.Scanner.WithClient(dryRunClient).ProcessNamespace(ctx, *namespace)
+	} else {
+		// Locking applies the requested profile, unlocking reverts the applied one
+		components := utils.LockProfileComponents(namespace.Annotations[constants.LockProfileAnnotation])
+		if action != constants.LockedStatus {
+			components = utils.LockProfileComponents(namespace.Annotations[constants.AppliedLockProfileAnnotation])
+		}
+		err = NewStreamProcessor(dryRunClient).ProcessNamespaceWorkloads(ctx, namespace.Name, action, components)
+	}
+	if err != nil {
+		log.Error(err, "Dry run of namespace workloads failed")
+		return fmt.Sprintf("Dry run: %d changes accepted before failure: %v", dryRunClient.Changes(), err)
+	}
+	return fmt.Sprintf("Dry run: %d changes would be made", dryRunClient.Changes())
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BlockRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -0,0 +1,183 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apiv1 "github.com/bearslyricattack/CompliK/block-controller/api/v1"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/scanner"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// TestBlockRequestDryRun 测试 dry-run 请求只记录变更而不修改命名空间和工作负载
+func TestBlockRequestDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apiv1.AddToScheme(scheme)
+	request := &apiv1.BlockRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default"},
+		Spec: apiv1.BlockRequestSpec{
+			NamespaceNames: []string{"tenant"},
+			Action:         constants.LockedStatus,
+			LockProfile:    constants.LockProfileAll,
+			DryRun:         true,
+		},
+	}
+	// 工作负载超过一页，缓存客户端无法分页
+	objs := []client.Object{request, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}}
+	for i := 0; i <= workloadPageSize; i++ {
+		objs = append(objs, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%03d", i), Namespace: "tenant"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		})
+	}
+	fakeClient, apiServer := newPagingTestClients(fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&apiv1.BlockRequest{}).
+		WithObjects(objs...).Build())
+	reconciler := &BlockRequestReconciler{Client: fakeClient, NonCachingClient: apiServer, Scheme: scheme}
+	ctx := context.Background()
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(request)}); err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	var ns corev1.Namespace
+	_ = fakeClient.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns)
+	if _, ok := ns.Labels[constants.StatusLabel]; ok {
+		t.Error("Dry run should not label the namespace")
+	}
+	var web appsv1.Deployment
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web-000"}, &web)
+	if *web.Spec.Replicas != 3 || web.Annotations[constants.OriginalReplicasAnnotation] != "" {
+		t.Errorf("Dry run should not scale down the deployment, got %d %v", *web.Spec.Replicas, web.Annotations)
+	}
+	var rq corev1.ResourceQuota
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); err == nil {
+		t.Error("Dry run should not create the ResourceQuota")
+	}
+
+	// 命名空间标签、ResourceQuota、NetworkPolicy 各一次变更，每个 Deployment 一次变更
+	var updated apiv1.BlockRequest
+	_ = fakeClient.Get(ctx, client.ObjectKeyFromObject(request), &updated)
+	if len(updated.Status.NamespaceStatuses) != 1 ||
+		updated.Status.NamespaceStatuses[0].Message != fmt.Sprintf("Dry run: %d changes would be made", 4+workloadPageSize) {
+		t.Errorf("Unexpected namespace statuses: %+v", updated.Status.NamespaceStatuses)
+	}
+	if updated.Status.ProcessedNamespaceCount != 1 {
+		t.Error("Dry run should still record the progress of the BlockRequest")
+	}
+}
+
+// TestBlockRequestDryRunThroughScanner 测试 dry-run 走扫描器的锁定逻辑，包括驱逐独立 Pod 和锁定到期
+func TestBlockRequestDryRunThroughScanner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apiv1.AddToScheme(scheme)
+	request := func(name, action string) *apiv1.BlockRequest {
+		return &apiv1.BlockRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: apiv1.BlockRequestSpec{
+				NamespaceNames: []string{name},
+				Action:         action,
+				LockProfile:    constants.LockProfileScaleOnly,
+				DryRun:         true,
+			},
+		}
+	}
+	lock, expire := request("tenant", constants.LockedStatus), request("expired", constants.LockedStatus)
+	base := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&apiv1.BlockRequest{}).
+		WithObjects(
+			lock, expire,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "tenant"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: "expired",
+				Annotations: map[string]string{
+					constants.UnlockTimestampLabel:         time.Now().Add(-time.Hour).Format(time.RFC3339),
+					constants.AppliedLockProfileAnnotation: constants.LockProfileScaleOnly,
+				},
+			}},
+		).Build()
+	// 假客户端不支持 dry-run 驱逐，这里按 API Server 的行为只校验不删除
+	dryRunEvictions := 0
+	fakeClient, apiServer := newPagingTestClients(interceptor.NewClient(base, interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, sub client.Object, opts ...client.SubResourceCreateOption) error {
+			createOpts := &client.SubResourceCreateOptions{}
+			createOpts.ApplyOptions(opts)
+			if subResource == "eviction" && len(createOpts.DryRun) > 0 {
+				dryRunEvictions++
+				return nil
+			}
+			return c.SubResource(subResource).Create(ctx, obj, sub, opts...)
+		},
+	}))
+	reconciler := &BlockRequestReconciler{
+		Client:           fakeClient,
+		NonCachingClient: apiServer,
+		Scheme:           scheme,
+		Scanner:          &scanner.NamespaceScanner{Log: logr.Discard(), LockDuration: time.Hour},
+	}
+	ctx := context.Background()
+
+	messages := make(map[string]string)
+	for _, blockRequest := range []*apiv1.BlockRequest{lock, expire} {
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(blockRequest)}); err != nil {
+			t.Fatalf("Reconcile failed: %v", err)
+		}
+		var updated apiv1.BlockRequest
+		_ = fakeClient.Get(ctx, client.ObjectKeyFromObject(blockRequest), &updated)
+		if len(updated.Status.NamespaceStatuses) != 1 {
+			t.Fatalf("Unexpected namespace statuses: %+v", updated.Status.NamespaceStatuses)
+		}
+		messages[blockRequest.Name] = updated.Status.NamespaceStatuses[0].Message
+	}
+
+	// 命名空间标签、锁定注解和驱逐独立 Pod 各一次变更
+	if messages["tenant"] != "Dry run: 3 changes would be made" {
+		t.Errorf("Unexpected lock dry run: %s", messages["tenant"])
+	}
+	// 到期的命名空间被解锁：标签和移除锁定注解各一次变更
+	if messages["expired"] != "Dry run: 2 changes would be made" {
+		t.Errorf("Unexpected expiry dry run: %s", messages["expired"])
+	}
+
+	if dryRunEvictions != 1 {
+		t.Errorf("Standalone pod should be evicted once as a dry run, got %d", dryRunEvictions)
+	}
+	var pod corev1.Pod
+	if err := apiServer.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "standalone"}, &pod); err != nil {
+		t.Errorf("Dry run should not evict the pod: %v", err)
+	}
+	var expired corev1.Namespace
+	_ = apiServer.Get(ctx, client.ObjectKey{Name: "expired"}, &expired)
+	if expired.Annotations[constants.UnlockTimestampLabel] == "" {
+		t.Error("Dry run should not unlock the expired namespace")
+	}
+}
@@ -17,6 +17,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -24,8 +25,10 @@ import (
 
 	"github.com/bearslyricattack/CompliK/block-controller/api/v1"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
@@ -61,6 +64,10 @@ type MemoryEfficientController struct {
 	errorCount   int64 // Error count
 	lastGC       time.Time
 
+	// Health
+	lastMemoryCheck int64       // Unix nano of the last memory monitor tick
+	memoryExceeded  atomic.Bool // Memory usage above maxMemoryMB
+
 	// Performance monitoring
 	mu        sync.RWMutex
 	memStats  runtime.MemStats
@@ -78,7 +85,10 @@ type EventFilter struct {
 // StreamProcessor - Stream workload processor
 type StreamProcessor struct {
 	client client.Client
-	pool   sync.Pool
+	// reader 用于分页 List，informer 缓存不支持 Continue，需要使用直连 API Server 的 Reader
+	reader   client.Reader
+	pool     sync.Pool
+	pageSize int64 // 每次 List 返回的最大对象数
 }
 
 // NamespaceIndex - Lightweight namespace index
@@ -123,6 +133,12 @@ func NewMemoryEfficientController(client client.Client, scheme *k8sruntime.Schem
 	return controller
 }
 
+// WithListReader 设置分页列出工作负载使用的 Reader，client 为缓存客户端时必须设置
+func (r *MemoryEfficientController) WithListReader(reader client.Reader) *MemoryEfficientController {
+	r.processor.reader = reader
+	return r
+}
+
 // SetupWithManager Setup controller manager
 func (r *MemoryEfficientController) SetupWithManager(mgr ctrl.Manager) error {
 	// Create controller - listen to BlockRequest events (temporarily removed Namespace monitoring)
@@ -184,7 +200,10 @@ func (r *MemoryEfficientController) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 	}
 
-	return r.processNamespace(ctx, req.Name)
+	start := time.Now()
+	result, err := r.processNamespace(ctx, req.Name)
+	metrics.ReconcileDuration.WithLabelValues(metrics.ComponentController, metrics.Result(err)).Observe(time.Since(start).Seconds())
+	return result, err
 }
 
 // processNamespace Process namespace
@@ -248,21 +267,48 @@ func (r *MemoryEfficientController) handleNamespaceLocked(ctx context.Context, n
 		namespace.Annotations = make(map[string]string)
 	}
 
+	profile := utils.ResolveLockProfile(namespace.Annotations)
+	components := utils.LockProfileComponents(profile)
+
+	// 锁定配置变更时，先撤销不再需要的措施
+	if applied, ok := namespace.Annotations[constants.AppliedLockProfileAnnotation]; ok && applied != profile {
+		released := utils.LockProfileComponents(applied).Without(components)
+		if err := r.processor.ProcessNamespaceWorkloads(ctx, namespace.Name, constants.ActiveStatus, released); err != nil {
+			logger.Error(err, "Failed to release previous lock profile")
+			return ctrl.Result{}, err
+		}
+	}
+
+	needsUpdate := false
 	unlockTimeStr := namespace.Annotations[constants.UnlockTimestampLabel]
-	if unlockTimeStr == "" {
+	newlyLocked := unlockTimeStr == ""
+	if newlyLocked {
 		// 设置默认解锁时间 (7天后)
 		unlockTime := time.Now().Add(7 * 24 * time.Hour)
 		namespace.Annotations[constants.UnlockTimestampLabel] = unlockTime.Format(time.RFC3339)
-
+		needsUpdate = true
+	}
+	// 记录实际应用的锁定配置，解锁时据此撤销
+	if namespace.Annotations[constants.AppliedLockProfileAnnotation] != profile {
+		namespace.Annotations[constants.AppliedLockProfileAnnotation] = profile
+		needsUpdate = true
+	}
+	if needsUpdate {
 		if err := r.Update(ctx, namespace); err != nil {
-			logger.Error(err, "Failed to update namespace with unlock timestamp")
+			if errors.IsConflict(err) {
+				metrics.Conflicts.WithLabelValues(metrics.ComponentController).Inc()
+			}
+			logger.Error(err, "Failed to update namespace with lock annotations")
 			return ctrl.Result{}, err
 		}
 		atomic.AddInt64(&r.apiCallCount, 1)
+		if newlyLocked {
+			metrics.NamespacesLocked.WithLabelValues(metrics.ComponentController).Inc()
+		}
 	}
 
 	// 2. 流式处理工作负载 (不缓存)
-	if err := r.processor.ProcessNamespaceWorkloads(ctx, namespace.Name, constants.LockedStatus); err != nil {
+	if err := r.processor.ProcessNamespaceWorkloads(ctx, namespace.Name, constants.LockedStatus, components); err != nil {
 		logger.Error(err, "Failed to process namespace workloads")
 		return ctrl.Result{}, err
 	}
@@ -274,21 +320,32 @@ func (r *MemoryEfficientController) handleNamespaceLocked(ctx context.Context, n
 func (r *MemoryEfficientController) ensureNamespaceUnlocked(ctx context.Context, namespace *corev1.Namespace) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	// 流式处理工作负载恢复
-	if err := r.processor.ProcessNamespaceWorkloads(ctx, namespace.Name, constants.ActiveStatus); err != nil {
+	// 流式处理工作负载恢复，未记录锁定配置时全部撤销
+	components := utils.LockProfileComponents(namespace.Annotations[constants.AppliedLockProfileAnnotation])
+	if err := r.processor.ProcessNamespaceWorkloads(ctx, namespace.Name, constants.ActiveStatus, components); err != nil {
 		logger.Error(err, "Failed to restore namespace workloads")
 		return ctrl.Result{}, err
 	}
 
-	// 清理解锁时间戳
+	// 清理锁定相关注解
 	if namespace.Annotations != nil {
-		if _, exists := namespace.Annotations[constants.UnlockTimestampLabel]; exists {
-			delete(namespace.Annotations, constants.UnlockTimestampLabel)
+		removed := false
+		for _, key := range utils.LockAnnotations {
+			if _, exists := namespace.Annotations[key]; exists {
+				delete(namespace.Annotations, key)
+				removed = true
+			}
+		}
+		if removed {
 			if err := r.Update(ctx, namespace); err != nil {
+				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentController).Inc()
+				}
 				logger.Error(err, "Failed to clean namespace annotations")
 				return ctrl.Result{}, err
 			}
 			atomic.AddInt64(&r.apiCallCount, 1)
+			metrics.NamespacesUnlocked.WithLabelValues(metrics.ComponentController).Inc()
 		}
 	}
 
@@ -301,6 +358,7 @@ func (r *MemoryEfficientController) Start(ctx context.Context) error {
 	logger.Info("Starting memory efficient controller")
 
 	// 启动内存监控
+	atomic.StoreInt64(&r.lastMemoryCheck, time.Now().UnixNano())
 	go r.startMemoryMonitor(ctx)
 
 	// 启动事件过滤器更新
@@ -312,6 +370,27 @@ func (r *MemoryEfficientController) Start(ctx context.Context) error {
 	return nil
 }
 
+// Healthz 存活检查：内存监控协程超过 2 分钟未运行则认为控制器卡死
+func (r *MemoryEfficientController) Healthz(_ *http.Request) error {
+	last := atomic.LoadInt64(&r.lastMemoryCheck)
+	if last == 0 {
+		// 尚未启动 (例如未获得 leader)，无需检查
+		return nil
+	}
+	if since := time.Since(time.Unix(0, last)); since > 2*time.Minute {
+		return fmt.Errorf("memory monitor has not run for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+// Readyz 就绪检查：内存超过上限时暂不就绪
+func (r *MemoryEfficientController) Readyz(_ *http.Request) error {
+	if r.memoryExceeded.Load() {
+		return fmt.Errorf("memory usage above limit of %d MB", r.maxMemoryMB)
+	}
+	return nil
+}
+
 // isMemoryPressure Check memory pressure
 func (r *MemoryEfficientController) isMemoryPressure() bool {
 	r.mu.RLock()
@@ -338,6 +417,8 @@ func (r *MemoryEfficientController) startMemoryMonitor(ctx context.Context) {
 		case <-ticker.C:
 			runtime.ReadMemStats(&r.memStats)
 			allocMB := int64(r.memStats.Alloc) / 1024 / 1024
+			atomic.StoreInt64(&r.lastMemoryCheck, time.Now().UnixNano())
+			r.memoryExceeded.Store(allocMB > r.maxMemoryMB)
 
 			log.FromContext(ctx).V(1).Info("Memory usage",
 				"alloc_mb", allocMB,
@@ -468,8 +549,15 @@ func NewEventFilter() *EventFilter {
 }
 
 func NewStreamProcessor(client client.Client) *StreamProcessor {
+	return NewStreamProcessorWithReader(client, client)
+}
+
+// NewStreamProcessorWithReader 创建使用 reader 分页列出工作负载、使用 client 写入的流式处理器
+func NewStreamProcessorWithReader(client client.Client, reader client.Reader) *StreamProcessor {
 	return &StreamProcessor{
-		client: client,
+		client:   client,
+		reader:   reader,
+		pageSize: workloadPageSize,
 		pool: sync.Pool{
 			New: func() interface{} {
 				return make([]string, 0, 50)
@@ -554,54 +642,80 @@ func (ef *EventFilter) simpleHash(s string) uint32 {
 
 // StreamProcessor 方法
 
-func (sp *StreamProcessor) ProcessNamespaceWorkloads(ctx context.Context, namespace, action string) error {
-	// 这里实现工作负载的流式处理
-	// 为了简化，这里只是一个框架
-	// 实际实现需要处理 Deployments, StatefulSets 等
-
+// ProcessNamespaceWorkloads 按锁定配置锁定或恢复命名空间，工作负载分页处理以控制内存
+func (sp *StreamProcessor) ProcessNamespaceWorkloads(ctx context.Context, namespace, action string, components utils.LockComponents) error {
 	switch action {
 	case constants.LockedStatus:
-		return sp.processNamespaceLocked(ctx, namespace)
+		return sp.processNamespaceLocked(ctx, namespace, components)
 	case constants.ActiveStatus:
-		return sp.processNamespaceUnlocked(ctx, namespace)
+		return sp.processNamespaceUnlocked(ctx, namespace, components)
 	}
 
 	return nil
 }
 
-func (sp *StreamProcessor) processNamespaceLocked(ctx context.Context, namespace string) error {
-	// 实现锁定逻辑：创建 ResourceQuota，缩容工作负载等
-	// 这里只是框架，实际实现需要完整的逻辑
+func (sp *StreamProcessor) processNamespaceLocked(ctx context.Context, namespace string, components utils.LockComponents) error {
+	// 创建 ResourceQuota，同时缩容时为豁免的工作负载预留替换 Pod 的配额
+	if components.Quota {
+		var allowance corev1.ResourceList
+		if components.Scale {
+			var err error
+			if allowance, err = sp.lockExemptionAllowance(ctx, namespace); err != nil {
+				return err
+			}
+		}
+		if err := sp.applyResourceQuota(ctx, utils.CreateResourceQuota(namespace, false, allowance)); err != nil {
+			return err
+		}
+	}
 
-	// 创建 ResourceQuota
-	rq := utils.CreateResourceQuota(namespace, false)
-	if err := sp.client.Create(ctx, rq); err != nil && !errors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create ResourceQuota: %w", err)
+	// 创建隔离 NetworkPolicy
+	if components.Network {
+		np := utils.CreateQuarantineNetworkPolicy(namespace)
+		if err := sp.client.Create(ctx, np); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create quarantine NetworkPolicy: %w", err)
+		}
 	}
 
-	// TODO: 处理其他工作负载类型
-	// Deployments, StatefulSets, etc.
+	// 分页缩容工作负载
+	if components.Scale {
+		return sp.scaleDownWorkloads(ctx, namespace)
+	}
 
 	return nil
 }
 
-func (sp *StreamProcessor) processNamespaceUnlocked(ctx context.Context, namespace string) error {
-	// 实现解封逻辑：删除 ResourceQuota，恢复工作负载等
-	// 这里只是框架，实际实现需要完整的逻辑
-
+func (sp *StreamProcessor) processNamespaceUnlocked(ctx context.Context, namespace string, components utils.LockComponents) error {
 	// 删除 ResourceQuota
-	rq := &corev1.ResourceQuota{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      constants.ResourceQuotaName,
-			Namespace: namespace,
-		},
+	if components.Quota {
+		rq := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.ResourceQuotaName,
+				Namespace: namespace,
+			},
+		}
+		if err := sp.client.Delete(ctx, rq); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ResourceQuota: %w", err)
+		}
 	}
-	if err := sp.client.Delete(ctx, rq); err != nil && !errors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete ResourceQuota: %w", err)
+
+	// 删除隔离 NetworkPolicy
+	if components.Network {
+		np := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.NetworkPolicyName,
+				Namespace: namespace,
+			},
+		}
+		if err := sp.client.Delete(ctx, np); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete quarantine NetworkPolicy: %w", err)
+		}
 	}
 
-	// TODO: 处理其他工作负载类型
-	// Deployments, StatefulSets, etc.
+	// 分页恢复工作负载
+	if components.Scale {
+		return sp.restoreWorkloads(ctx, namespace)
+	}
 
 	return nil
 }
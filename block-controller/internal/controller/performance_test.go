@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -104,7 +105,7 @@ func BenchmarkNamespaceIndex(b *testing.B) {
 func BenchmarkMemoryUsage(b *testing.B) {
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 创建大量命名空间模拟
 	namespaces := make([]client.Object, 1000)
@@ -165,7 +166,7 @@ func BenchmarkMemoryUsage(b *testing.B) {
 func TestMemoryEfficiency(t *testing.T) {
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 模拟 5000 个命名空间
 	numNamespaces := 5000
@@ -282,7 +283,7 @@ func TestMemoryEfficiency(t *testing.T) {
 func TestConcurrencyPerformance(t *testing.T) {
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 创建测试命名空间
 	numNamespaces := 1000
@@ -374,7 +375,7 @@ func TestScalability(t *testing.T) {
 
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 测试不同规模的性能
 	testCases := []struct {
@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -38,7 +39,7 @@ import (
 func TestSimplePerformance(t *testing.T) {
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 创建 1000 个测试命名空间
 	numNamespaces := 1000
@@ -143,7 +144,7 @@ func TestSimplePerformance(t *testing.T) {
 func TestConcurrentPerformance(t *testing.T) {
 	scheme := k8sruntime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 创建 2000 个测试命名空间
 	numNamespaces := 2000
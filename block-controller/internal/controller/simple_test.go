@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -105,7 +106,7 @@ func TestSimpleStringPool(t *testing.T) {
 func TestSimpleMemoryEfficientController(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = v1.AddToScheme(scheme)
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	// 创建测试命名空间
 	namespace := &corev1.Namespace{
@@ -161,7 +162,7 @@ func TestSimpleMemoryEfficientController(t *testing.T) {
 // TestMemoryPressureHandling 测试内存压力处理
 func TestMemoryPressureHandling(t *testing.T) {
 	scheme := runtime.NewScheme()
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	controller := NewMemoryEfficientController(
 		fake.NewClientBuilder().WithScheme(scheme).Build(),
@@ -214,7 +215,7 @@ func TestHashFunction(t *testing.T) {
 // TestControllerInitialization 测试控制器初始化
 func TestControllerInitialization(t *testing.T) {
 	scheme := runtime.NewScheme()
-	_ = corev1.AddToScheme(scheme)
+	_ = clientgoscheme.AddToScheme(scheme)
 
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
 
@@ -0,0 +1,426 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// workloadPageSize 每页列出的工作负载数量，与控制器批大小一致
+const workloadPageSize = 50
+
+// 工作负载的注解约定与 NamespaceScanner 保持一致：
+// 缩容前把副本数写入 OriginalReplicasAnnotation，暂停 CronJob 前把 suspend 写入
+// OriginalSuspendAnnotation，暂停 HPA 前把扩容策略写入 OriginalHPAScaleUpAnnotation，
+// 恢复时据此还原并删除注解。
+
+// scaleDownWorkloads 分页缩容命名空间内的工作负载
+func (sp *StreamProcessor) scaleDownWorkloads(ctx context.Context, namespace string) error {
+	logger := log.FromContext(ctx).WithValues("namespace", namespace)
+
+	// 豁免的 Deployment 由其自身管理 ReplicaSet，只记录名称；
+	// exemptTargets 以 "Kind/name" 记录豁免的工作负载，其 HPA 不暂停
+	exemptDeployments := make(map[string]bool)
+	exemptTargets := make(map[string]bool)
+
+	var deployments appsv1.DeploymentList
+	if err := sp.forEachPage(ctx, namespace, &deployments, func() error {
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			if isLockExempt(logger, "deployment", deployment, deployment.Spec.Template.Spec) {
+				exemptDeployments[deployment.Name] = true
+				exemptTargets["Deployment/"+deployment.Name] = true
+				continue
+			}
+			if err := sp.scaleDown(ctx, "deployment", deployment, &deployment.Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var statefulsets appsv1.StatefulSetList
+	if err := sp.forEachPage(ctx, namespace, &statefulsets, func() error {
+		for i := range statefulsets.Items {
+			statefulset := &statefulsets.Items[i]
+			if isLockExempt(logger, "statefulset", statefulset, statefulset.Spec.Template.Spec) {
+				exemptTargets["StatefulSet/"+statefulset.Name] = true
+				continue
+			}
+			if err := sp.scaleDown(ctx, "statefulset", statefulset, &statefulset.Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var replicasets appsv1.ReplicaSetList
+	if err := sp.forEachPage(ctx, namespace, &replicasets, func() error {
+		for i := range replicasets.Items {
+			replicaset := &replicasets.Items[i]
+			if owner := metav1.GetControllerOf(replicaset); owner != nil && owner.Kind == "Deployment" && exemptDeployments[owner.Name] {
+				continue
+			}
+			if err := sp.scaleDown(ctx, "replicaset", replicaset, &replicaset.Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var rcs corev1.ReplicationControllerList
+	if err := sp.forEachPage(ctx, namespace, &rcs, func() error {
+		for i := range rcs.Items {
+			if err := sp.scaleDown(ctx, "replicationcontroller", &rcs.Items[i], &rcs.Items[i].Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := sp.forEachPage(ctx, namespace, &hpas, func() error {
+		for i := range hpas.Items {
+			hpa := &hpas.Items[i]
+			if utils.HPATargetsExempt(hpa, exemptTargets) {
+				continue
+			}
+			if err := sp.pauseHPA(ctx, hpa); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var cronjobs batchv1.CronJobList
+	return sp.forEachPage(ctx, namespace, &cronjobs, func() error {
+		for i := range cronjobs.Items {
+			if err := sp.suspend(ctx, &cronjobs.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockExemptionAllowance 分页汇总命名空间内豁免工作负载的配额
+func (sp *StreamProcessor) lockExemptionAllowance(ctx context.Context, namespace string) (corev1.ResourceList, error) {
+	allowance := corev1.ResourceList{}
+	var deployments appsv1.DeploymentList
+	if err := sp.forEachPage(ctx, namespace, &deployments, func() error {
+		for i := range deployments.Items {
+			spec := deployments.Items[i].Spec
+			if utils.IsLockExempt(&deployments.Items[i]) && utils.ValidateLockExemption(spec.Template.Spec) == nil {
+				utils.AddResources(allowance, utils.LockExemptionAllowance(spec.Replicas, spec.Template.Spec))
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var statefulsets appsv1.StatefulSetList
+	if err := sp.forEachPage(ctx, namespace, &statefulsets, func() error {
+		for i := range statefulsets.Items {
+			spec := statefulsets.Items[i].Spec
+			if utils.IsLockExempt(&statefulsets.Items[i]) && utils.ValidateLockExemption(spec.Template.Spec) == nil {
+				utils.AddResources(allowance, utils.LockExemptionAllowance(spec.Replicas, spec.Template.Spec))
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return allowance, nil
+}
+
+// applyResourceQuota 创建 ResourceQuota，已存在时按豁免工作负载的变化更新配额
+func (sp *StreamProcessor) applyResourceQuota(ctx context.Context, rq *corev1.ResourceQuota) error {
+	err := sp.client.Create(ctx, rq)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create ResourceQuota: %w", err)
+	}
+	var existing corev1.ResourceQuota
+	if err := sp.client.Get(ctx, client.ObjectKeyFromObject(rq), &existing); err != nil {
+		return fmt.Errorf("failed to get ResourceQuota: %w", err)
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Hard, rq.Spec.Hard) {
+		return nil
+	}
+	existing.Spec.Hard = rq.Spec.Hard
+	if err := sp.client.Update(ctx, &existing); err != nil {
+		return fmt.Errorf("failed to update ResourceQuota: %w", err)
+	}
+	return nil
+}
+
+// restoreWorkloads 分页恢复命名空间内被缩容的工作负载
+func (sp *StreamProcessor) restoreWorkloads(ctx context.Context, namespace string) error {
+	var deployments appsv1.DeploymentList
+	if err := sp.forEachPage(ctx, namespace, &deployments, func() error {
+		for i := range deployments.Items {
+			if err := sp.restore(ctx, "deployment", &deployments.Items[i], &deployments.Items[i].Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var statefulsets appsv1.StatefulSetList
+	if err := sp.forEachPage(ctx, namespace, &statefulsets, func() error {
+		for i := range statefulsets.Items {
+			if err := sp.restore(ctx, "statefulset", &statefulsets.Items[i], &statefulsets.Items[i].Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var replicasets appsv1.ReplicaSetList
+	if err := sp.forEachPage(ctx, namespace, &replicasets, func() error {
+		for i := range replicasets.Items {
+			if err := sp.restore(ctx, "replicaset", &replicasets.Items[i], &replicasets.Items[i].Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var rcs corev1.ReplicationControllerList
+	if err := sp.forEachPage(ctx, namespace, &rcs, func() error {
+		for i := range rcs.Items {
+			if err := sp.restore(ctx, "replicationcontroller", &rcs.Items[i], &rcs.Items[i].Spec.Replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// 工作负载恢复副本数后再恢复 HPA 的扩容策略
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := sp.forEachPage(ctx, namespace, &hpas, func() error {
+		for i := range hpas.Items {
+			if err := sp.resumeHPA(ctx, &hpas.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var cronjobs batchv1.CronJobList
+	return sp.forEachPage(ctx, namespace, &cronjobs, func() error {
+		for i := range cronjobs.Items {
+			if err := sp.resume(ctx, &cronjobs.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forEachPage 分页列出命名空间内的对象，每页处理完成后再请求下一页，
+// 同一时刻只有一页对象驻留内存
+func (sp *StreamProcessor) forEachPage(ctx context.Context, namespace string, list client.ObjectList, process func() error) error {
+	continueToken := ""
+	for {
+		opts := []client.ListOption{client.InNamespace(namespace), client.Limit(sp.pageSize)}
+		if continueToken != "" {
+			opts = append(opts, client.Continue(continueToken))
+		}
+		if err := sp.reader.List(ctx, list, opts...); err != nil {
+			return fmt.Errorf("failed to list %T: %w", list, err)
+		}
+		if err := process(); err != nil {
+			return err
+		}
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+// scaleDown 记录原副本数并缩容到 0，未设置副本数时按默认值 1 处理
+func (sp *StreamProcessor) scaleDown(ctx context.Context, kind string, obj client.Object, replicas **int32) error {
+	current := int32(1)
+	if *replicas != nil {
+		current = **replicas
+	}
+	if current == 0 {
+		return nil
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[constants.OriginalReplicasAnnotation] = strconv.Itoa(int(current))
+	obj.SetAnnotations(annotations)
+	zero := int32(0)
+	*replicas = &zero
+
+	log.FromContext(ctx).Info("Scaling down workload", "kind", kind, "name", obj.GetName())
+	return sp.update(ctx, kind, obj)
+}
+
+// restore 按注解恢复副本数，注解无法解析时跳过该工作负载
+func (sp *StreamProcessor) restore(ctx context.Context, kind string, obj client.Object, replicas **int32) error {
+	annotations := obj.GetAnnotations()
+	value, ok := annotations[constants.OriginalReplicasAnnotation]
+	if !ok {
+		return nil
+	}
+	original, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Unable to parse original replicas annotation", "kind", kind, "name", obj.GetName())
+		return nil
+	}
+
+	restored := int32(original)
+	*replicas = &restored
+	delete(annotations, constants.OriginalReplicasAnnotation)
+	obj.SetAnnotations(annotations)
+
+	log.FromContext(ctx).Info("Restoring workload", "kind", kind, "name", obj.GetName(), "replicas", restored)
+	return sp.update(ctx, kind, obj)
+}
+
+// suspend 记录原 suspend 状态并暂停 CronJob
+func (sp *StreamProcessor) suspend(ctx context.Context, cronjob *batchv1.CronJob) error {
+	if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+		return nil
+	}
+
+	if cronjob.Annotations == nil {
+		cronjob.Annotations = make(map[string]string)
+	}
+	cronjob.Annotations[constants.OriginalSuspendAnnotation] = strconv.FormatBool(false)
+	suspend := true
+	cronjob.Spec.Suspend = &suspend
+
+	log.FromContext(ctx).Info("Suspending cronjob", "name", cronjob.Name)
+	return sp.update(ctx, "cronjob", cronjob)
+}
+
+// resume 按注解恢复 CronJob 的 suspend 状态
+func (sp *StreamProcessor) resume(ctx context.Context, cronjob *batchv1.CronJob) error {
+	value, ok := cronjob.Annotations[constants.OriginalSuspendAnnotation]
+	if !ok {
+		return nil
+	}
+	suspend, err := strconv.ParseBool(value)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Unable to parse original suspend annotation", "name", cronjob.Name)
+		return nil
+	}
+
+	cronjob.Spec.Suspend = &suspend
+	delete(cronjob.Annotations, constants.OriginalSuspendAnnotation)
+
+	log.FromContext(ctx).Info("Resuming cronjob", "name", cronjob.Name)
+	return sp.update(ctx, "cronjob", cronjob)
+}
+
+// pauseHPA 禁止 HPA 扩容，避免锁定期间 HPA 把工作负载扩回去
+func (sp *StreamProcessor) pauseHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	paused, err := utils.PauseHPA(hpa)
+	if err != nil {
+		return fmt.Errorf("failed to pause hpa %s: %w", hpa.Name, err)
+	}
+	if !paused {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Pausing hpa", "name", hpa.Name)
+	return sp.update(ctx, "hpa", hpa)
+}
+
+// resumeHPA 按注解恢复 HPA 的扩容策略，注解无法解析时跳过该 HPA
+func (sp *StreamProcessor) resumeHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	resumed, err := utils.ResumeHPA(hpa)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Unable to parse original hpa scale up annotation", "name", hpa.Name)
+		return nil
+	}
+	if !resumed {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Resuming hpa", "name", hpa.Name)
+	return sp.update(ctx, "hpa", hpa)
+}
+
+// update 更新工作负载，冲突时返回错误由控制器重新入队
+func (sp *StreamProcessor) update(ctx context.Context, kind string, obj client.Object) error {
+	if err := sp.client.Update(ctx, obj); err != nil {
+		if errors.IsConflict(err) {
+			metrics.Conflicts.WithLabelValues(metrics.ComponentController).Inc()
+		}
+		return fmt.Errorf("failed to update %s %s: %w", kind, obj.GetName(), err)
+	}
+	return nil
+}
+
+// isLockExempt 判断工作负载是否豁免缩容，仅当容器都设置了 CPU 和内存上限时豁免才生效
+func isLockExempt(logger logr.Logger, kind string, obj metav1.Object, spec corev1.PodSpec) bool {
+	if !utils.IsLockExempt(obj) {
+		return false
+	}
+	if err := utils.ValidateLockExemption(spec); err != nil {
+		logger.Error(err, "Ignoring lock exemption of uncapped workload", "kind", kind, "name", obj.GetName())
+		return false
+	}
+	logger.Info("Skipping lock exempt workload", "kind", kind, "name", obj.GetName())
+	return true
+}
@@ -0,0 +1,402 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func cappedPodSpec() corev1.PodSpec {
+	return corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}},
+	}}}
+}
+
+func newStreamTestClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+// pageList 按 Limit 截断 list，offset 为本页起始位置，返回下一页的起始位置，没有下一页时返回 0
+func pageList(list client.ObjectList, limit int64, offset int) (int, error) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return 0, err
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	next := 0
+	if limit > 0 && int64(len(items)) > limit {
+		items = items[:limit]
+		next = offset + int(limit)
+	}
+	return next, meta.SetList(list, items)
+}
+
+// newPagingTestClients 基于 base 返回模拟 informer 缓存的客户端和模拟 API Server 分页的客户端：
+// 缓存截断结果后返回无法使用的 Continue，并拒绝带 Continue 的 List
+func newPagingTestClients(base client.WithWatch) (client.Client, client.Client) {
+	list := func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) (*client.ListOptions, error) {
+		listOpts := &client.ListOptions{}
+		listOpts.ApplyOptions(opts)
+		err := c.List(ctx, list, &client.ListOptions{Namespace: listOpts.Namespace, LabelSelector: listOpts.LabelSelector})
+		return listOpts, err
+	}
+
+	cached := interceptor.NewClient(base, interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, obj client.ObjectList, opts ...client.ListOption) error {
+			listOpts, err := list(ctx, c, obj, opts...)
+			if err != nil {
+				return err
+			}
+			if listOpts.Continue != "" {
+				return fmt.Errorf("continue list option is not supported by the cache")
+			}
+			next, err := pageList(obj, listOpts.Limit, 0)
+			if next > 0 {
+				obj.SetContinue("continue-not-supported")
+			}
+			return err
+		},
+	})
+	apiServer := interceptor.NewClient(base, interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, obj client.ObjectList, opts ...client.ListOption) error {
+			listOpts, err := list(ctx, c, obj, opts...)
+			if err != nil {
+				return err
+			}
+			offset := 0
+			if listOpts.Continue != "" {
+				if offset, err = strconv.Atoi(listOpts.Continue); err != nil {
+					return err
+				}
+			}
+			next, err := pageList(obj, listOpts.Limit, offset)
+			if next > 0 {
+				obj.SetContinue(strconv.Itoa(next))
+			}
+			return err
+		},
+	})
+	return cached, apiServer
+}
+
+// TestStreamProcessorPagesThroughReader 测试工作负载超过一页时通过 Reader 分页，缓存客户端只用于写入
+func TestStreamProcessorPagesThroughReader(t *testing.T) {
+	const ns = "tenant"
+	count := 2*workloadPageSize + 10
+	objs := make([]client.Object, 0, count)
+	for i := 0; i < int(count); i++ {
+		objs = append(objs, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%03d", i), Namespace: ns},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		})
+	}
+	cached, apiReader := newPagingTestClients(newStreamTestClient(objs...).(client.WithWatch))
+	ctx := context.Background()
+	scaleOnly := utils.LockProfileComponents(constants.LockProfileScaleOnly)
+
+	// 缓存客户端不支持 Continue，第二页必然失败
+	if err := NewStreamProcessor(cached).ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, scaleOnly); err == nil {
+		t.Fatal("Paging through the cache should fail")
+	}
+
+	sp := NewStreamProcessorWithReader(cached, apiReader)
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, scaleOnly); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	replicas := func(want int32) {
+		t.Helper()
+		for i := 0; i < int(count); i++ {
+			var deployment appsv1.Deployment
+			if err := apiReader.Get(ctx, client.ObjectKey{Namespace: ns, Name: fmt.Sprintf("web-%03d", i)}, &deployment); err != nil {
+				t.Fatalf("Failed to get deployment: %v", err)
+			}
+			if *deployment.Spec.Replicas != want {
+				t.Fatalf("Deployment %s should have %d replicas, got %d", deployment.Name, want, *deployment.Spec.Replicas)
+			}
+		}
+	}
+	replicas(0)
+
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.ActiveStatus, scaleOnly); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	replicas(2)
+}
+
+// TestStreamProcessorLockAndRestore 测试流式缩容与恢复工作负载
+func TestStreamProcessorLockAndRestore(t *testing.T) {
+	const ns = "tenant"
+	fakeClient := newStreamTestClient(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "exempt",
+				Namespace: ns,
+				Labels:    map[string]string{constants.LockExemptLabel: "true"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(1),
+				Template: corev1.PodTemplateSpec{Spec: cappedPodSpec()},
+			},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: ns},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(2)},
+		},
+		&corev1.ReplicationController{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: ns},
+			Spec:       corev1.ReplicationControllerSpec{Replicas: int32Ptr(1)},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: ns},
+			Spec:       batchv1.CronJobSpec{Schedule: "* * * * *"},
+		},
+	)
+	sp := NewStreamProcessor(fakeClient)
+	ctx := context.Background()
+	scaleOnly := utils.LockProfileComponents(constants.LockProfileScaleOnly)
+
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, scaleOnly); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	var web, exempt appsv1.Deployment
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "web"}, &web)
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "exempt"}, &exempt)
+	if *web.Spec.Replicas != 0 || web.Annotations[constants.OriginalReplicasAnnotation] != "3" {
+		t.Errorf("Deployment should be scaled down with original replicas recorded, got %d %v", *web.Spec.Replicas, web.Annotations)
+	}
+	if *exempt.Spec.Replicas != 1 {
+		t.Error("Lock exempt deployment should keep running")
+	}
+	var db appsv1.StatefulSet
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "db"}, &db)
+	if *db.Spec.Replicas != 0 {
+		t.Error("StatefulSet should be scaled down")
+	}
+	var rc corev1.ReplicationController
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "legacy"}, &rc)
+	if *rc.Spec.Replicas != 0 {
+		t.Error("ReplicationController should be scaled down")
+	}
+	var cronjob batchv1.CronJob
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "report"}, &cronjob)
+	if cronjob.Spec.Suspend == nil || !*cronjob.Spec.Suspend {
+		t.Error("CronJob should be suspended")
+	}
+
+	// scale-only 配置不应创建 ResourceQuota
+	var rq corev1.ResourceQuota
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: constants.ResourceQuotaName}, &rq); err == nil {
+		t.Error("ResourceQuota should not be created for scale-only profile")
+	}
+
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.ActiveStatus, scaleOnly); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "web"}, &web)
+	if *web.Spec.Replicas != 3 {
+		t.Errorf("Deployment should be restored to 3 replicas, got %d", *web.Spec.Replicas)
+	}
+	if _, ok := web.Annotations[constants.OriginalReplicasAnnotation]; ok {
+		t.Error("Original replicas annotation should be removed")
+	}
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "db"}, &db)
+	if *db.Spec.Replicas != 2 {
+		t.Errorf("StatefulSet should be restored to 2 replicas, got %d", *db.Spec.Replicas)
+	}
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "report"}, &cronjob)
+	if cronjob.Spec.Suspend == nil || *cronjob.Spec.Suspend {
+		t.Error("CronJob should be resumed")
+	}
+
+	t.Log("✅ Stream processor workload test passed")
+}
+
+// TestStreamProcessorExemptQuotaAllowance 测试锁定配置包含配额和缩容时为豁免工作负载预留配额，quota-only 时不预留
+func TestStreamProcessorExemptQuotaAllowance(t *testing.T) {
+	const ns = "tenant"
+	exempt := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup",
+			Namespace: ns,
+			Labels:    map[string]string{constants.LockExemptLabel: "true"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(1),
+			Template: corev1.PodTemplateSpec{Spec: cappedPodSpec()},
+		},
+	}
+	ctx := context.Background()
+	quota := func(c client.Client) corev1.ResourceList {
+		t.Helper()
+		var rq corev1.ResourceQuota
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: constants.ResourceQuotaName}, &rq); err != nil {
+			t.Fatalf("Failed to get ResourceQuota: %v", err)
+		}
+		return rq.Spec.Hard
+	}
+
+	fakeClient := newStreamTestClient(exempt.DeepCopy())
+	sp := NewStreamProcessor(fakeClient)
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, utils.LockProfileComponents(constants.LockProfileAll)); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	hard := quota(fakeClient)
+	if pods, memory := hard[corev1.ResourcePods], hard[corev1.ResourceLimitsMemory]; pods.Value() != 2 || memory.Cmp(resource.MustParse("256Mi")) != 0 {
+		t.Errorf("Exempt statefulset should get room for 2 pods and 256Mi, got %s pods and %s", pods.String(), memory.String())
+	}
+	var backup appsv1.StatefulSet
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "backup"}, &backup)
+	if *backup.Spec.Replicas != 1 {
+		t.Error("Lock exempt statefulset should keep running")
+	}
+
+	// 已存在的配额随豁免工作负载的副本数更新
+	backup.Spec.Replicas = int32Ptr(2)
+	if err := fakeClient.Update(ctx, &backup); err != nil {
+		t.Fatalf("Failed to scale statefulset: %v", err)
+	}
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, utils.LockProfileComponents(constants.LockProfileAll)); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if pods := quota(fakeClient)[corev1.ResourcePods]; pods.Value() != 3 {
+		t.Errorf("ResourceQuota should follow the exempt replicas, got %s pods", pods.String())
+	}
+
+	quotaOnlyClient := newStreamTestClient(exempt.DeepCopy())
+	if err := NewStreamProcessor(quotaOnlyClient).ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus,
+		utils.LockProfileComponents(constants.LockProfileQuotaOnly)); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if pods := quota(quotaOnlyClient)[corev1.ResourcePods]; !pods.IsZero() {
+		t.Errorf("quota-only profile should not grant exempt workloads pods, got %s", pods.String())
+	}
+}
+
+// TestStreamProcessorPausesHPAs 测试锁定时暂停 HPA 扩容，解锁时恢复原扩容策略
+func TestStreamProcessorPausesHPAs(t *testing.T) {
+	const ns = "tenant"
+	window := int32(60)
+	hpa := func(name, target string, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) *autoscalingv2.HorizontalPodAutoscaler {
+		return &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: target},
+				MinReplicas:    int32Ptr(2),
+				MaxReplicas:    10,
+				Behavior:       behavior,
+			},
+		}
+	}
+	fakeClient := newStreamTestClient(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "exempt",
+				Namespace: ns,
+				Labels:    map[string]string{constants.LockExemptLabel: "true"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(1),
+				Template: corev1.PodTemplateSpec{Spec: cappedPodSpec()},
+			},
+		},
+		hpa("web", "web", nil),
+		hpa("api", "api", &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleUp: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &window},
+		}),
+		hpa("exempt", "exempt", nil),
+	)
+	sp := NewStreamProcessor(fakeClient)
+	ctx := context.Background()
+	scaleOnly := utils.LockProfileComponents(constants.LockProfileScaleOnly)
+
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.LockedStatus, scaleOnly); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	get := func(name string) *autoscalingv2.HorizontalPodAutoscaler {
+		var got autoscalingv2.HorizontalPodAutoscaler
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, &got); err != nil {
+			t.Fatalf("Failed to get hpa %s: %v", name, err)
+		}
+		return &got
+	}
+	for _, name := range []string{"web", "api"} {
+		paused := get(name)
+		if paused.Spec.Behavior == nil || paused.Spec.Behavior.ScaleUp == nil ||
+			paused.Spec.Behavior.ScaleUp.SelectPolicy == nil ||
+			*paused.Spec.Behavior.ScaleUp.SelectPolicy != autoscalingv2.DisabledPolicySelect {
+			t.Errorf("HPA %s should have scale up disabled, got %+v", name, paused.Spec.Behavior)
+		}
+		if *paused.Spec.MinReplicas != 2 {
+			t.Errorf("HPA %s should keep its min replicas", name)
+		}
+	}
+	if exempt := get("exempt"); exempt.Spec.Behavior != nil {
+		t.Error("HPA of a lock exempt deployment should keep running")
+	}
+
+	if err := sp.ProcessNamespaceWorkloads(ctx, ns, constants.ActiveStatus, scaleOnly); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if web := get("web"); web.Spec.Behavior != nil {
+		t.Errorf("HPA web should have no behavior after restore, got %+v", web.Spec.Behavior)
+	}
+	api := get("api")
+	if api.Spec.Behavior == nil || api.Spec.Behavior.ScaleUp == nil || api.Spec.Behavior.ScaleUp.SelectPolicy != nil ||
+		*api.Spec.Behavior.ScaleUp.StabilizationWindowSeconds != window {
+		t.Errorf("HPA api should get its scale up behavior back, got %+v", api.Spec.Behavior)
+	}
+	if _, ok := api.Annotations[constants.OriginalHPAScaleUpAnnotation]; ok {
+		t.Error("Original scale up annotation should be removed")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debug serves the pprof profiles and runtime statistics of the
+// controller, e.g. to profile the memory of the informer caches in production.
+// It is disabled by default and requires a bearer token unless it only listens
+// on the loopback interface.
+package debug
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Runtime is the body of /debug/runtime
+type Runtime struct {
+	GoVersion  string `json:"go_version"`
+	Goroutines int    `json:"goroutines"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	// Memory statistics in bytes, see runtime.MemStats
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+	LastGC       string `json:"last_gc,omitempty"`
+}
+
+// Server serves the debug endpoints as a manager runnable
+type Server struct {
+	// Addr is the listen address, e.g. 127.0.0.1:6060
+	Addr string
+	// Token is the bearer token requests must carry, required when Addr is
+	// not on the loopback interface
+	Token string
+	Log   logr.Logger
+}
+
+// NeedLeaderElection returns false, so that every replica can be profiled.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the debug endpoints until ctx is done. Profiles and traces run
+// for the requested seconds, so the server has no write timeout.
+func (s *Server) Start(ctx context.Context) error {
+	if err := CheckExposure(s.Addr, s.Token); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on debug address %s: %w", s.Addr, err)
+	}
+	server := &http.Server{
+		Handler:           Handler(s.Token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	s.Log.Info("starting debug server", "addr", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler returns the debug endpoints, requiring token as bearer token when it
+// is not empty
+func Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReadRuntime())
+	})
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ReadRuntime returns the current runtime statistics
+func ReadRuntime() Runtime {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	info := Runtime{
+		GoVersion:    runtime.Version(),
+		Goroutines:   runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    stats.HeapAlloc,
+		HeapInuse:    stats.HeapInuse,
+		HeapObjects:  stats.HeapObjects,
+		StackInuse:   stats.StackInuse,
+		Sys:          stats.Sys,
+		NumGC:        stats.NumGC,
+		PauseTotalNs: stats.PauseTotalNs,
+	}
+	if stats.LastGC > 0 {
+		info.LastGC = time.Unix(0, int64(stats.LastGC)).UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// CheckExposure refuses a listen address other than the loopback interface
+// without a token, the profiles reveal the memory of the controller
+func CheckExposure(addr, token string) error {
+	if token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return errors.New("--debug-bind-address beyond the loopback interface requires --debug-token-file")
+}
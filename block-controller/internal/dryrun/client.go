@@ -0,0 +1,172 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun provides a client that sends every mutation as a server-side dry run.
+// The API server still validates and defaults each request, so admission errors show up
+// exactly as they would for a real change, but nothing is persisted. Each mutation is
+// logged together with the fields it would change.
+package dryrun
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Client wraps a client so that all writes are server-side dry runs. Reads go to the
+// wrapped client unchanged.
+type Client struct {
+	client.Client
+	log     logr.Logger
+	changes atomic.Int64
+}
+
+// NewClient returns a dry-run client writing through c.
+func NewClient(c client.Client, log logr.Logger) *Client {
+	return &Client{Client: client.NewDryRunClient(c), log: log}
+}
+
+// Changes returns the number of mutations that would have been made so far.
+func (c *Client) Changes() int64 {
+	return c.changes.Load()
+}
+
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record("create", "", obj, nil)
+	return nil
+}
+
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	// The live object is read before the dry run, which returns the would-be result in obj
+	diff := c.diff(ctx, obj)
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record("update", "", obj, diff)
+	return nil
+}
+
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, _ := patch.Data(obj)
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	c.record("patch", "", obj, data)
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record("delete", "", obj, nil)
+	return nil
+}
+
+func (c *Client) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.Client.DeleteAllOf(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record("deletecollection", "", obj, nil)
+	return nil
+}
+
+func (c *Client) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *Client) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{SubResourceClient: c.Client.SubResource(subResource), parent: c, name: subResource}
+}
+
+// diff returns the JSON merge patch from the live object to obj. It is empty if the live
+// object cannot be read, e.g. because obj does not exist yet.
+func (c *Client) diff(ctx context.Context, obj client.Object) []byte {
+	live, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return nil
+	}
+	data, err := client.MergeFrom(live).Data(obj)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// record logs and counts a mutation the dry run accepted. Subresource writes are
+// reported with the kind "Kind/subresource".
+func (c *Client) record(verb, subResource string, obj runtime.Object, changes []byte) {
+	c.changes.Add(1)
+	kind := "Unknown"
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		kind = gvk.Kind
+	}
+	if subResource != "" {
+		kind += "/" + subResource
+	}
+	metrics.DryRunMutations.WithLabelValues(verb, kind).Inc()
+
+	values := []any{"verb", verb, "kind", kind}
+	if o, ok := obj.(client.Object); ok {
+		values = append(values, "namespace", o.GetNamespace(), "name", o.GetName())
+	}
+	if len(changes) > 0 {
+		values = append(values, "changes", string(changes))
+	}
+	c.log.Info("Dry run: mutation not persisted", values...)
+}
+
+// subResourceClient records the writes of a subresource like status or eviction.
+type subResourceClient struct {
+	client.SubResourceClient
+	parent *Client
+	name   string
+}
+
+func (s *subResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if err := s.SubResourceClient.Create(ctx, obj, subResource, opts...); err != nil {
+		return err
+	}
+	s.parent.record("create", s.name, obj, nil)
+	return nil
+}
+
+func (s *subResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if err := s.SubResourceClient.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	s.parent.record("update", s.name, obj, nil)
+	return nil
+}
+
+func (s *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	data, _ := patch.Data(obj)
+	if err := s.SubResourceClient.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	s.parent.record("patch", s.name, obj, data)
+	return nil
+}
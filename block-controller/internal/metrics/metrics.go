@@ -0,0 +1,147 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics exported by the block-controller.
+// All collectors are registered with the controller-runtime registry and served
+// from the manager's metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "block_controller"
+
+// Component label values
+const (
+	ComponentController = "memory-efficient-controller"
+	ComponentScanner    = "namespace-scanner"
+)
+
+var (
+	// NamespacesLocked counts namespaces that have been locked
+	NamespacesLocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "namespaces_locked_total",
+		Help:      "Total number of namespaces locked.",
+	}, []string{"component"})
+
+	// NamespacesUnlocked counts namespaces that have been unlocked
+	NamespacesUnlocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "namespaces_unlocked_total",
+		Help:      "Total number of namespaces unlocked.",
+	}, []string{"component"})
+
+	// LockedNamespaces is the number of namespaces currently labeled as locked
+	LockedNamespaces = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "locked_namespaces",
+		Help:      "Number of namespaces currently labeled as locked.",
+	})
+
+	// ReconcileDuration observes the time spent processing a single namespace
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time spent processing a single namespace.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"component", "result"})
+
+	// Conflicts counts optimistic concurrency conflicts when updating objects
+	Conflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "update_conflicts_total",
+		Help:      "Total number of update conflicts while locking or unlocking workloads.",
+	}, []string{"component"})
+
+	// ScanDuration observes the duration of complete fast and slow scans
+	ScanDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scan_duration_seconds",
+		Help:      "Duration of complete namespace scans.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+	}, []string{"scan"})
+
+	// ScanProgress is the number of namespaces processed by the running scan
+	ScanProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scan_processed_namespaces",
+		Help:      "Number of namespaces processed by the current or last scan.",
+	}, []string{"scan"})
+
+	// ScanNamespaces counts namespaces processed by scans
+	ScanNamespaces = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scan_namespaces_total",
+		Help:      "Total number of namespaces processed by scans.",
+	}, []string{"scan", "result"})
+
+	// ExpiredLocks counts expired locks by the expiry policy that was applied
+	ExpiredLocks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "expired_locks_total",
+		Help:      "Total number of expired locks by applied expiry policy.",
+	}, []string{"policy"})
+
+	// ExpiredLocksDeleted counts namespaces deleted because their lock expired
+	ExpiredLocksDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "expired_locks_deleted_total",
+		Help:      "Total number of namespaces deleted after their lock expired.",
+	})
+
+	// PodEvictions counts evictions of standalone pods in locked namespaces by result:
+	// evicted, blocked by a PodDisruptionBudget, or deleted after the eviction timeout
+	PodEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pod_evictions_total",
+		Help:      "Total number of standalone pod evictions in locked namespaces by result.",
+	}, []string{"result"})
+
+	// DryRunMutations counts the mutations sent as server-side dry runs and not persisted
+	DryRunMutations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dry_run_mutations_total",
+		Help:      "Total number of mutations sent as server-side dry runs by verb and kind.",
+	}, []string{"verb", "kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		NamespacesLocked,
+		NamespacesUnlocked,
+		LockedNamespaces,
+		ReconcileDuration,
+		Conflicts,
+		ScanDuration,
+		ScanProgress,
+		ScanNamespaces,
+		ExpiredLocks,
+		ExpiredLocksDeleted,
+		PodEvictions,
+		DryRunMutations,
+	)
+}
+
+// Result returns the result label value for an error
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,302 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultExpiryGracePeriod is used when a namespace does not specify a grace period
+	defaultExpiryGracePeriod = 24 * time.Hour
+	// maxArchiveSize keeps archives below the ConfigMap size limit
+	maxArchiveSize = 900 * 1024
+)
+
+// namespaceArchive is the content stored in the archive ConfigMap of an expired namespace
+type namespaceArchive struct {
+	Namespace    string                         `json:"namespace"`
+	ArchivedAt   time.Time                      `json:"archivedAt"`
+	Deployments  []appsv1.Deployment            `json:"deployments,omitempty"`
+	StatefulSets []appsv1.StatefulSet           `json:"statefulSets,omitempty"`
+	CronJobs     []batchv1.CronJob              `json:"cronJobs,omitempty"`
+	Services     []corev1.Service               `json:"services,omitempty"`
+	ConfigMaps   []corev1.ConfigMap             `json:"configMaps,omitempty"`
+	PVCs         []corev1.PersistentVolumeClaim `json:"persistentVolumeClaims,omitempty"`
+}
+
+// handleLockExpiration applies the expiry policy of a namespace whose lock has expired.
+// Namespaces are only ever deleted by the archive-then-delete policy, after the grace
+// period and when the namespace explicitly allows deletion.
+func (s *NamespaceScanner) handleLockExpiration(ctx context.Context, namespace *corev1.Namespace) error {
+	policy := utils.NormalizeExpiryPolicy(namespace.Annotations[constants.ExpiryPolicyAnnotation])
+	switch policy {
+	case constants.ExpiryPolicyUnlock:
+		return s.expireUnlock(ctx, namespace)
+	case constants.ExpiryPolicyArchiveThenDelete:
+		if namespace.Annotations[constants.AllowDeletionAnnotation] != "true" {
+			return s.expireKeepLocked(ctx, namespace,
+				fmt.Sprintf("deletion requires the %s=true annotation", constants.AllowDeletionAnnotation))
+		}
+		return s.expireArchiveThenDelete(ctx, namespace)
+	default:
+		return s.expireKeepLocked(ctx, namespace, "")
+	}
+}
+
+func (s *NamespaceScanner) expireUnlock(ctx context.Context, namespace *corev1.Namespace) error {
+	log := s.Log.WithValues("namespace", namespace.Name)
+	log.Info("Lock expired, unlocking namespace")
+
+	namespace.Labels[constants.StatusLabel] = constants.ActiveStatus
+	if err := s.Update(ctx, namespace); err != nil {
+		log.Error(err, "unable to unlock expired namespace")
+		return err
+	}
+	metrics.ExpiredLocks.WithLabelValues(constants.ExpiryPolicyUnlock).Inc()
+	s.event(namespace, corev1.EventTypeNormal, "LockExpired", "Lock expired, namespace unlocked")
+
+	return s.handleUnlock(ctx, namespace)
+}
+
+// expireKeepLocked keeps an expired namespace locked and alerts once per expired lock.
+// note explains why a stricter policy was not applied.
+func (s *NamespaceScanner) expireKeepLocked(ctx context.Context, namespace *corev1.Namespace, note string) error {
+	log := s.Log.WithValues("namespace", namespace.Name)
+
+	unlockTimestamp := namespace.Annotations[constants.UnlockTimestampLabel]
+	if namespace.Annotations[constants.ExpiredAtAnnotation] != unlockTimestamp {
+		log.Info("Lock expired, keeping namespace locked", "note", note)
+		namespace.Annotations[constants.ExpiredAtAnnotation] = unlockTimestamp
+		if err := s.Update(ctx, namespace); err != nil {
+			log.Error(err, "unable to record lock expiration")
+			return err
+		}
+		metrics.ExpiredLocks.WithLabelValues(constants.ExpiryPolicyKeepLocked).Inc()
+		message := fmt.Sprintf("Lock expired at %s, namespace stays locked until it is unlocked manually", unlockTimestamp)
+		if note != "" {
+			message += ": " + note
+		}
+		s.event(namespace, corev1.EventTypeWarning, "LockExpired", message)
+	}
+
+	return s.handleLock(ctx, namespace)
+}
+
+func (s *NamespaceScanner) expireArchiveThenDelete(ctx context.Context, namespace *corev1.Namespace) error {
+	log := s.Log.WithValues("namespace", namespace.Name)
+
+	scheduled, ok := namespace.Annotations[constants.DeletionScheduledAnnotation]
+	if !ok {
+		if err := s.archiveNamespace(ctx, namespace.Name); err != nil {
+			log.Error(err, "unable to archive expired namespace")
+			s.event(namespace, corev1.EventTypeWarning, "ArchiveFailed",
+				fmt.Sprintf("Lock expired but the namespace could not be archived, deletion postponed: %v", err))
+			return s.handleLock(ctx, namespace)
+		}
+
+		deleteAt := time.Now().Add(s.expiryGracePeriod(namespace))
+		namespace.Annotations[constants.ExpiredAtAnnotation] = namespace.Annotations[constants.UnlockTimestampLabel]
+		namespace.Annotations[constants.DeletionScheduledAnnotation] = deleteAt.Format(time.RFC3339)
+		if err := s.Update(ctx, namespace); err != nil {
+			log.Error(err, "unable to schedule namespace deletion")
+			return err
+		}
+		log.Info("Lock expired, namespace archived and scheduled for deletion", "deleteAt", deleteAt)
+		metrics.ExpiredLocks.WithLabelValues(constants.ExpiryPolicyArchiveThenDelete).Inc()
+		s.event(namespace, corev1.EventTypeWarning, "DeletionScheduled",
+			fmt.Sprintf("Lock expired, namespace archived to %s/%s and will be deleted at %s",
+				s.ArchiveNamespace, archiveName(namespace.Name), deleteAt.Format(time.RFC3339)))
+		return s.handleLock(ctx, namespace)
+	}
+
+	deleteAt, err := time.Parse(time.RFC3339, scheduled)
+	if err != nil {
+		log.Error(err, "invalid deletion schedule, keeping namespace locked", "deletionScheduledAt", scheduled)
+		return s.handleLock(ctx, namespace)
+	}
+	if time.Now().Before(deleteAt) {
+		return s.handleLock(ctx, namespace)
+	}
+
+	// Never delete without an archive, e.g. when it was removed during the grace period
+	var archive corev1.ConfigMap
+	key := client.ObjectKey{Namespace: s.ArchiveNamespace, Name: archiveName(namespace.Name)}
+	if err := s.Get(ctx, key, &archive); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err := s.archiveNamespace(ctx, namespace.Name); err != nil {
+			log.Error(err, "unable to archive expired namespace before deletion")
+			return s.handleLock(ctx, namespace)
+		}
+	}
+
+	log.Info("Grace period over, deleting namespace")
+	if err := s.Delete(ctx, namespace); err != nil {
+		log.Error(err, "unable to delete namespace")
+		return err
+	}
+	metrics.ExpiredLocksDeleted.Inc()
+	s.event(namespace, corev1.EventTypeWarning, "NamespaceDeleted", "Grace period over, expired namespace deleted")
+
+	return nil
+}
+
+// expiryGracePeriod returns the grace period of a namespace, falling back to the default
+func (s *NamespaceScanner) expiryGracePeriod(namespace *corev1.Namespace) time.Duration {
+	if value, ok := namespace.Annotations[constants.ExpiryGracePeriodAnnotation]; ok {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+		s.Log.Info("invalid expiry grace period, using default", "namespace", namespace.Name, "value", value)
+	}
+	return defaultExpiryGracePeriod
+}
+
+// archiveNamespace stores the workload definitions of a namespace in a ConfigMap in
+// ArchiveNamespace. Secrets are never archived.
+func (s *NamespaceScanner) archiveNamespace(ctx context.Context, namespace string) error {
+	if s.ArchiveNamespace == "" {
+		return fmt.Errorf("no archive namespace configured")
+	}
+
+	archive := namespaceArchive{Namespace: namespace, ArchivedAt: time.Now().UTC()}
+	var deployments appsv1.DeploymentList
+	if err := s.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, item := range deployments.Items {
+		stripObjectMeta(&item.ObjectMeta)
+		item.Status = appsv1.DeploymentStatus{}
+		archive.Deployments = append(archive.Deployments, item)
+	}
+	var statefulsets appsv1.StatefulSetList
+	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, item := range statefulsets.Items {
+		stripObjectMeta(&item.ObjectMeta)
+		item.Status = appsv1.StatefulSetStatus{}
+		archive.StatefulSets = append(archive.StatefulSets, item)
+	}
+	var cronjobs batchv1.CronJobList
+	if err := s.List(ctx, &cronjobs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for _, item := range cronjobs.Items {
+		stripObjectMeta(&item.ObjectMeta)
+		item.Status = batchv1.CronJobStatus{}
+		archive.CronJobs = append(archive.CronJobs, item)
+	}
+	var services corev1.ServiceList
+	if err := s.List(ctx, &services, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, item := range services.Items {
+		stripObjectMeta(&item.ObjectMeta)
+		item.Status = corev1.ServiceStatus{}
+		archive.Services = append(archive.Services, item)
+	}
+	var configmaps corev1.ConfigMapList
+	if err := s.List(ctx, &configmaps, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list configmaps: %w", err)
+	}
+	for _, item := range configmaps.Items {
+		if item.Name == "kube-root-ca.crt" {
+			continue
+		}
+		stripObjectMeta(&item.ObjectMeta)
+		archive.ConfigMaps = append(archive.ConfigMaps, item)
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := s.List(ctx, &pvcs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list persistentvolumeclaims: %w", err)
+	}
+	for _, item := range pvcs.Items {
+		stripObjectMeta(&item.ObjectMeta)
+		item.Status = corev1.PersistentVolumeClaimStatus{}
+		archive.PVCs = append(archive.PVCs, item)
+	}
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	if len(data) > maxArchiveSize {
+		return fmt.Errorf("archive of %d bytes exceeds the limit of %d bytes", len(data), maxArchiveSize)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      archiveName(namespace),
+			Namespace: s.ArchiveNamespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by":   "block-controller",
+				constants.ArchivedNamespaceLabel: namespace,
+			},
+		},
+		Data: map[string]string{"archive.json": string(data)},
+	}
+	if err := s.Create(ctx, cm); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		var existing corev1.ConfigMap
+		if err := s.Get(ctx, client.ObjectKeyFromObject(cm), &existing); err != nil {
+			return fmt.Errorf("failed to get archive: %w", err)
+		}
+		existing.Labels = cm.Labels
+		existing.Data = cm.Data
+		if err := s.Update(ctx, &existing); err != nil {
+			return fmt.Errorf("failed to update archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// stripObjectMeta removes server populated metadata so that archived objects can be re-applied
+func stripObjectMeta(meta *metav1.ObjectMeta) {
+	meta.UID = ""
+	meta.ResourceVersion = ""
+	meta.Generation = 0
+	meta.CreationTimestamp = metav1.Time{}
+	meta.ManagedFields = nil
+	meta.OwnerReferences = nil
+}
+
+func archiveName(namespace string) string {
+	return "archive-" + namespace
+}
+
+// event records an event on a namespace when an event recorder is configured
+func (s *NamespaceScanner) event(namespace *corev1.Namespace, eventType, reason, message string) {
+	if s.Recorder != nil {
+		s.Recorder.Event(namespace, eventType, reason, message)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Healthz reports the scanner as unhealthy when its scan loop has stopped making
+// progress for three fast scan intervals. It is meant to be used as a liveness check.
+func (s *NamespaceScanner) Healthz(_ *http.Request) error {
+	s.healthMu.RLock()
+	defer s.healthMu.RUnlock()
+
+	if s.startedAt.IsZero() {
+		// Not elected leader yet, nothing to check
+		return nil
+	}
+	last := s.lastHeartbeat
+	if last.IsZero() {
+		last = s.startedAt
+	}
+	if staleAfter := 3 * s.FastScanInterval; time.Since(last) > staleAfter {
+		return fmt.Errorf("namespace scanner made no progress for %s", time.Since(last).Round(time.Second))
+	}
+	return nil
+}
+
+// Readyz reports the scanner as not ready when its last scan failed, which usually
+// means the API server cannot be reached.
+func (s *NamespaceScanner) Readyz(_ *http.Request) error {
+	s.healthMu.RLock()
+	defer s.healthMu.RUnlock()
+
+	if s.lastScanErr != nil {
+		return fmt.Errorf("last namespace scan failed: %w", s.lastScanErr)
+	}
+	return nil
+}
+
+func (s *NamespaceScanner) markStarted() {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	s.startedAt = time.Now()
+}
+
+func (s *NamespaceScanner) heartbeat() {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	s.lastHeartbeat = time.Now()
+}
+
+func (s *NamespaceScanner) recordScan(err error) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	s.lastHeartbeat = time.Now()
+	s.lastScanErr = err
+}
@@ -17,23 +17,28 @@ package scanner
 import (
 	"context"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
 	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // NamespaceScanner scans namespaces and applies blocking policies
-
-// TODO(user): Add unit tests for the scanner
-
 type NamespaceScanner struct {
 	client.Client
 	Log              logr.Logger
@@ -42,10 +47,53 @@ type NamespaceScanner struct {
 	FastScanInterval time.Duration
 	SlowScanInterval time.Duration
 	ScanBatchSize    int
+	// ScanWorkers is the number of namespaces processed concurrently during a scan
+	ScanWorkers int
+	// ScanJitter is the maximum random delay before a worker processes a namespace,
+	// spreading API calls over time instead of bursting
+	ScanJitter time.Duration
+	// ArchiveNamespace is where the archive-then-delete expiry policy stores namespace archives
+	ArchiveNamespace string
+	// Recorder emits events on namespaces, e.g. when a lock expires
+	Recorder record.EventRecorder
+	// PDBEvictionTimeout is how long the eviction of a standalone pod may be refused by a
+	// PodDisruptionBudget before the pod is deleted directly. Zero waits for the budget forever.
+	PDBEvictionTimeout time.Duration
+
+	healthMu      sync.RWMutex
+	startedAt     time.Time
+	lastHeartbeat time.Time
+	lastScanErr   error
+}
+
+// WithClient returns a scanner with the configuration of s that sends its requests
+// through c, e.g. a dry-run client to report what a scan would change.
+func (s *NamespaceScanner) WithClient(c client.Client) *NamespaceScanner {
+	return &NamespaceScanner{
+		Client:             c,
+		Log:                s.Log,
+		Scheme:             s.Scheme,
+		LockDuration:       s.LockDuration,
+		FastScanInterval:   s.FastScanInterval,
+		SlowScanInterval:   s.SlowScanInterval,
+		ScanBatchSize:      s.ScanBatchSize,
+		ScanWorkers:        s.ScanWorkers,
+		ScanJitter:         s.ScanJitter,
+		ArchiveNamespace:   s.ArchiveNamespace,
+		Recorder:           s.Recorder,
+		PDBEvictionTimeout: s.PDBEvictionTimeout,
+	}
+}
+
+// ProcessNamespace handles the lock, unlock or lock expiry of a namespace the way a scan does.
+func (s *NamespaceScanner) ProcessNamespace(ctx context.Context, namespace corev1.Namespace) error {
+	return s.processNamespace(ctx, namespace)
 }
 
 // Start starts the namespace scanner with two tickers for fast and slow scans.
 func (s *NamespaceScanner) Start(ctx context.Context) error {
+	s.markStarted()
+
 	fastTicker := time.NewTicker(s.FastScanInterval)
 	defer fastTicker.Stop()
 	slowTicker := time.NewTicker(s.SlowScanInterval)
@@ -57,20 +105,29 @@ func (s *NamespaceScanner) Start(ctx context.Context) error {
 			return nil
 		case <-fastTicker.C:
 			s.Log.Info("Starting fast scan")
-			if err := s.fastScan(ctx); err != nil {
+			start := time.Now()
+			err := s.fastScan(ctx)
+			metrics.ScanDuration.WithLabelValues(fastScanName).Observe(time.Since(start).Seconds())
+			if err != nil {
 				s.Log.Error(err, "Fast scan failed")
 			}
+			s.recordScan(err)
 		case <-slowTicker.C:
 			s.Log.Info("Starting slow scan (janitor)")
-			if err := s.slowScan(ctx); err != nil {
+			start := time.Now()
+			err := s.slowScan(ctx)
+			metrics.ScanDuration.WithLabelValues(slowScanName).Observe(time.Since(start).Seconds())
+			if err != nil {
 				s.Log.Error(err, "Slow scan failed")
 			}
+			s.recordScan(err)
 		}
 	}
 }
 
 func (s *NamespaceScanner) fastScan(ctx context.Context) error {
 	log := s.Log.WithName("fast-scan")
+	progress := newScanProgress(fastScanName)
 
 	// Process locked namespaces
 	var lockedNsList corev1.NamespaceList
@@ -79,11 +136,8 @@ func (s *NamespaceScanner) fastScan(ctx context.Context) error {
 		log.Error(err, "failed to list locked namespaces")
 		return err
 	}
-	for _, ns := range lockedNsList.Items {
-		if err := s.processNamespace(ctx, ns); err != nil {
-			log.Error(err, "failed to process locked namespace", "namespace", ns.Name)
-		}
-	}
+	metrics.LockedNamespaces.Set(float64(len(lockedNsList.Items)))
+	s.processNamespaces(ctx, log, progress, lockedNsList.Items)
 
 	// Process active namespaces
 	var activeNsList corev1.NamespaceList
@@ -92,16 +146,16 @@ func (s *NamespaceScanner) fastScan(ctx context.Context) error {
 		log.Error(err, "failed to list active namespaces")
 		return err
 	}
-	for _, ns := range activeNsList.Items {
-		if err := s.processNamespace(ctx, ns); err != nil {
-			log.Error(err, "failed to process active namespace", "namespace", ns.Name)
-		}
-	}
+	s.processNamespaces(ctx, log, progress, activeNsList.Items)
 
+	log.Info("fast scan finished", "processed", progress.processed.Load(), "failed", progress.failed.Load())
 	return nil
 }
 
 func (s *NamespaceScanner) slowScan(ctx context.Context) error {
+	log := s.Log.WithName("slow-scan")
+	progress := newScanProgress(slowScanName)
+
 	var continueToken string
 	for {
 		namespaceList := &corev1.NamespaceList{}
@@ -110,23 +164,27 @@ func (s *NamespaceScanner) slowScan(ctx context.Context) error {
 			return err
 		}
 
-		for _, namespace := range namespaceList.Items {
-			if err := s.processNamespace(ctx, namespace); err != nil {
-				s.Log.Error(err, "Failed to process namespace", "namespace", namespace.Name)
-			}
-		}
+		s.processNamespaces(ctx, log, progress, namespaceList.Items)
 
 		if namespaceList.Continue == "" {
 			break
 		}
 		continueToken = namespaceList.Continue
 	}
+
+	log.Info("slow scan finished", "processed", progress.processed.Load(), "failed", progress.failed.Load())
 	return nil
 }
 
-func (s *NamespaceScanner) processNamespace(ctx context.Context, namespace corev1.Namespace) error {
+func (s *NamespaceScanner) processNamespace(ctx context.Context, namespace corev1.Namespace) (err error) {
 	log := s.Log.WithValues("namespace", namespace.Name)
 
+	start := time.Now()
+	defer func() {
+		metrics.ReconcileDuration.WithLabelValues(metrics.ComponentScanner, metrics.Result(err)).Observe(time.Since(start).Seconds())
+		s.heartbeat()
+	}()
+
 	status, ok := namespace.Labels[constants.StatusLabel]
 	if !ok {
 		// If label doesn't exist, ensure no quota is present.
@@ -158,40 +216,162 @@ func (s *NamespaceScanner) processNamespace(ctx context.Context, namespace corev
 func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Namespace) error {
 	log := s.Log.WithValues("namespace", namespace.Name)
 
-	// Ensure unlock timestamp exists
 	if namespace.Annotations == nil {
 		namespace.Annotations = make(map[string]string)
 	}
-	_, ok := namespace.Annotations[constants.UnlockTimestampLabel]
-	if !ok {
+	profile := utils.ResolveLockProfile(namespace.Annotations)
+	components := utils.LockProfileComponents(profile)
+
+	// Revert measures that are no longer part of the lock profile
+	if applied, ok := namespace.Annotations[constants.AppliedLockProfileAnnotation]; ok && applied != profile {
+		log.Info("lock profile changed", "from", applied, "to", profile)
+		if err := s.releaseLock(ctx, namespace.Name, utils.LockProfileComponents(applied).Without(components)); err != nil {
+			return err
+		}
+	}
+
+	// Ensure unlock timestamp and applied profile exist
+	needsUpdate, newlyLocked := false, false
+	if _, ok := namespace.Annotations[constants.UnlockTimestampLabel]; !ok {
 		unlockTime := time.Now().Add(s.LockDuration)
 		namespace.Annotations[constants.UnlockTimestampLabel] = unlockTime.Format(time.RFC3339)
+		needsUpdate = true
+		newlyLocked = true
+	}
+	if namespace.Annotations[constants.AppliedLockProfileAnnotation] != profile {
+		namespace.Annotations[constants.AppliedLockProfileAnnotation] = profile
+		needsUpdate = true
+	}
+	if needsUpdate {
 		if err := s.Update(ctx, namespace); err != nil {
-			log.Error(err, "unable to update namespace with unlock timestamp")
+			log.Error(err, "unable to update namespace with lock annotations")
+			return err
+		}
+		if newlyLocked {
+			metrics.NamespacesLocked.WithLabelValues(metrics.ComponentScanner).Inc()
+		}
+	}
+
+	if components.Quota {
+		// Exempt workloads only keep running when the workloads are scaled down, the quota
+		// grants them room to replace their pods
+		var allowance corev1.ResourceList
+		if components.Scale {
+			var err error
+			if allowance, err = s.lockExemptionAllowance(ctx, namespace.Name); err != nil {
+				return err
+			}
+		}
+		if err := s.applyResourceQuota(ctx, namespace.Name, allowance); err != nil {
+			return err
+		}
+	}
+	if components.Network {
+		if err := s.applyNetworkQuarantine(ctx, namespace.Name); err != nil {
 			return err
 		}
 	}
+	if components.Scale {
+		return s.scaleDownWorkloads(ctx, namespace.Name)
+	}
+	return nil
+}
+
+func (s *NamespaceScanner) applyResourceQuota(ctx context.Context, namespace string, allowance corev1.ResourceList) error {
+	log := s.Log.WithValues("namespace", namespace)
 
 	// Create ResourceQuota if it doesn't exist
-	rq := utils.CreateResourceQuota(namespace.Name, false)
+	rq := utils.CreateResourceQuota(namespace, false, allowance)
 	log.Info("creating ResourceQuota")
 	if err := s.Create(ctx, rq); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			log.Error(err, "unable to create ResourceQuota")
+			return err
+		}
+		log.Info("ResourceQuota already exists")
+		// Follow the allowance of the exempt workloads as they change during the lock
+		var existing corev1.ResourceQuota
+		if err := s.Get(ctx, client.ObjectKeyFromObject(rq), &existing); err != nil {
+			log.Error(err, "unable to get ResourceQuota")
+			return err
+		}
+		if equality.Semantic.DeepEqual(existing.Spec.Hard, rq.Spec.Hard) {
+			return nil
+		}
+		log.Info("updating ResourceQuota allowance of lock exempt workloads")
+		existing.Spec.Hard = rq.Spec.Hard
+		if err := s.Update(ctx, &existing); err != nil {
+			log.Error(err, "unable to update ResourceQuota")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockExemptionAllowance sums the quota allowance of the exempt workloads of a namespace
+func (s *NamespaceScanner) lockExemptionAllowance(ctx context.Context, namespace string) (corev1.ResourceList, error) {
+	allowance := corev1.ResourceList{}
+	var deployments appsv1.DeploymentList
+	if err := s.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		s.Log.Error(err, "unable to list deployments", "namespace", namespace)
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		if utils.IsLockExempt(&deployment) && utils.ValidateLockExemption(deployment.Spec.Template.Spec) == nil {
+			utils.AddResources(allowance, utils.LockExemptionAllowance(deployment.Spec.Replicas, deployment.Spec.Template.Spec))
+		}
+	}
+	var statefulsets appsv1.StatefulSetList
+	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace)); err != nil {
+		s.Log.Error(err, "unable to list statefulsets", "namespace", namespace)
+		return nil, err
+	}
+	for _, statefulset := range statefulsets.Items {
+		if utils.IsLockExempt(&statefulset) && utils.ValidateLockExemption(statefulset.Spec.Template.Spec) == nil {
+			utils.AddResources(allowance, utils.LockExemptionAllowance(statefulset.Spec.Replicas, statefulset.Spec.Template.Spec))
+		}
+	}
+	return allowance, nil
+}
+
+func (s *NamespaceScanner) applyNetworkQuarantine(ctx context.Context, namespace string) error {
+	log := s.Log.WithValues("namespace", namespace)
+
+	// Create quarantine NetworkPolicy if it doesn't exist
+	np := utils.CreateQuarantineNetworkPolicy(namespace)
+	log.Info("creating quarantine NetworkPolicy")
+	if err := s.Create(ctx, np); err != nil {
 		if errors.IsAlreadyExists(err) {
-			log.Info("ResourceQuota already exists")
+			log.Info("quarantine NetworkPolicy already exists")
 		} else {
-			log.Error(err, "unable to create ResourceQuota")
+			log.Error(err, "unable to create quarantine NetworkPolicy")
 			return err
 		}
 	}
 
+	return nil
+}
+
+func (s *NamespaceScanner) scaleDownWorkloads(ctx context.Context, namespace string) error {
+	log := s.Log.WithValues("namespace", namespace)
+
 	// Scale down deployments
 	var deployments appsv1.DeploymentList
-	if err := s.List(ctx, &deployments, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list deployments")
 		return err
 	}
 
+	// exemptTargets holds the exempt workloads as "Kind/name", their autoscalers are not paused
+	exemptDeployments := make(map[string]bool)
+	exemptTargets := make(map[string]bool)
 	for _, deployment := range deployments.Items {
+		if s.isLockExempt(log, "deployment", &deployment, deployment.Spec.Template.Spec) {
+			exemptDeployments[deployment.Name] = true
+			exemptTargets["Deployment/"+deployment.Name] = true
+			continue
+		}
 		if deployment.Annotations == nil {
 			deployment.Annotations = make(map[string]string)
 		}
@@ -201,6 +381,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 			*deployment.Spec.Replicas = 0
 			if err := s.Update(ctx, &deployment); err != nil {
 				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 					log.Info("deployment has been modified, requeueing", "deployment", deployment.Name)
 					return nil
 				}
@@ -212,12 +393,16 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 
 	// Scale down statefulsets
 	var statefulsets appsv1.StatefulSetList
-	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list statefulsets")
 		return err
 	}
 
 	for _, statefulset := range statefulsets.Items {
+		if s.isLockExempt(log, "statefulset", &statefulset, statefulset.Spec.Template.Spec) {
+			exemptTargets["StatefulSet/"+statefulset.Name] = true
+			continue
+		}
 		if statefulset.Annotations == nil {
 			statefulset.Annotations = make(map[string]string)
 		}
@@ -227,6 +412,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 			*statefulset.Spec.Replicas = 0
 			if err := s.Update(ctx, &statefulset); err != nil {
 				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 					log.Info("statefulset has been modified, requeueing", "statefulset", statefulset.Name)
 					return nil
 				}
@@ -238,12 +424,16 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 
 	// Scale down replicasets
 	var replicasets appsv1.ReplicaSetList
-	if err := s.List(ctx, &replicasets, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &replicasets, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list replicasets")
 		return err
 	}
 
 	for _, replicaset := range replicasets.Items {
+		// ReplicaSets of exempt deployments are managed by the deployment
+		if owner := metav1.GetControllerOf(&replicaset); owner != nil && owner.Kind == "Deployment" && exemptDeployments[owner.Name] {
+			continue
+		}
 		if replicaset.Annotations == nil {
 			replicaset.Annotations = make(map[string]string)
 		}
@@ -253,6 +443,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 			*replicaset.Spec.Replicas = 0
 			if err := s.Update(ctx, &replicaset); err != nil {
 				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 					log.Info("replicaset has been modified, requeueing", "replicaset", replicaset.Name)
 					return nil
 				}
@@ -264,7 +455,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 
 	// Scale down replicationcontrollers
 	var rcs corev1.ReplicationControllerList
-	if err := s.List(ctx, &rcs, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &rcs, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list replicationcontrollers")
 		return err
 	}
@@ -279,6 +470,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 			*rc.Spec.Replicas = 0
 			if err := s.Update(ctx, &rc); err != nil {
 				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 					log.Info("replicationcontroller has been modified, requeueing", "rc", rc.Name)
 					return nil
 				}
@@ -288,9 +480,39 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 		}
 	}
 
+	// Pause horizontal pod autoscalers so they do not scale the workloads back up
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := s.List(ctx, &hpas, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "unable to list horizontalpodautoscalers")
+		return err
+	}
+
+	for _, hpa := range hpas.Items {
+		if utils.HPATargetsExempt(&hpa, exemptTargets) {
+			continue
+		}
+		paused, err := utils.PauseHPA(&hpa)
+		if err != nil {
+			log.Error(err, "unable to pause horizontalpodautoscaler", "hpa", hpa.Name)
+			return err
+		}
+		if paused {
+			log.Info("pausing horizontalpodautoscaler", "hpa", hpa.Name)
+			if err := s.Update(ctx, &hpa); err != nil {
+				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
+					log.Info("horizontalpodautoscaler has been modified, requeueing", "hpa", hpa.Name)
+					return nil
+				}
+				log.Error(err, "unable to pause horizontalpodautoscaler", "hpa", hpa.Name)
+				return err
+			}
+		}
+	}
+
 	// Suspend cronjobs
 	var cronjobs batchv1.CronJobList
-	if err := s.List(ctx, &cronjobs, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &cronjobs, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list cronjobs")
 		return err
 	}
@@ -305,6 +527,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 			*cronjob.Spec.Suspend = true
 			if err := s.Update(ctx, &cronjob); err != nil {
 				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 					log.Info("cronjob has been modified, requeueing", "cronjob", cronjob.Name)
 					return nil
 				}
@@ -314,9 +537,9 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 		}
 	}
 
-	// Delete pods
+	// Evict standalone pods
 	var pods corev1.PodList
-	if err := s.List(ctx, &pods, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &pods, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list pods")
 		return err
 	}
@@ -331,9 +554,7 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 		}
 
 		if isStandalone {
-			log.Info("deleting pod", "pod", pod.Name)
-			if err := s.Delete(ctx, &pod); err != nil {
-				log.Error(err, "unable to delete pod", "pod", pod.Name)
+			if err := s.evictPod(ctx, log, &pod); err != nil {
 				return err
 			}
 		}
@@ -342,12 +563,130 @@ func (s *NamespaceScanner) handleLock(ctx context.Context, namespace *corev1.Nam
 	return nil
 }
 
+// evictPod removes a standalone pod through the eviction API so that PodDisruptionBudgets are
+// respected. The first refusal is recorded on the pod and the eviction is retried on the next
+// scans; once PDBEvictionTimeout has passed the pod is deleted directly, since a budget must
+// not keep a locked namespace running.
+func (s *NamespaceScanner) evictPod(ctx context.Context, log logr.Logger, pod *corev1.Pod) error {
+	log.Info("evicting pod", "pod", pod.Name)
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	err := s.SubResource("eviction").Create(ctx, pod, eviction)
+	if err == nil || errors.IsNotFound(err) {
+		metrics.PodEvictions.WithLabelValues("evicted").Inc()
+		return nil
+	}
+	if !errors.IsTooManyRequests(err) {
+		log.Error(err, "unable to evict pod", "pod", pod.Name)
+		return err
+	}
+
+	// The eviction API answers 429 when a PodDisruptionBudget allows no disruption
+	metrics.PodEvictions.WithLabelValues("blocked").Inc()
+	now := time.Now()
+	blockedAt, parseErr := time.Parse(time.RFC3339, pod.Annotations[constants.EvictionBlockedAtAnnotation])
+	if parseErr != nil {
+		log.Info("pod eviction blocked by PodDisruptionBudget, retrying on next scan", "pod", pod.Name)
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[constants.EvictionBlockedAtAnnotation] = now.Format(time.RFC3339)
+		if err := s.Update(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			if errors.IsConflict(err) {
+				metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
+				return nil
+			}
+			log.Error(err, "unable to record blocked eviction", "pod", pod.Name)
+			return err
+		}
+		return nil
+	}
+	if s.PDBEvictionTimeout <= 0 || now.Sub(blockedAt) < s.PDBEvictionTimeout {
+		log.Info("pod eviction blocked by PodDisruptionBudget, retrying on next scan", "pod", pod.Name, "blockedAt", blockedAt)
+		return nil
+	}
+
+	log.Info("pod eviction blocked past timeout, deleting pod", "pod", pod.Name, "blockedAt", blockedAt)
+	if err := s.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "unable to delete pod", "pod", pod.Name)
+		return err
+	}
+	metrics.PodEvictions.WithLabelValues("deleted").Inc()
+	return nil
+}
+
+// isLockExempt reports whether a workload is exempt from scale down. The exemption is
+// only honored when the workload stays capped by CPU and memory limits.
+func (s *NamespaceScanner) isLockExempt(log logr.Logger, kind string, obj metav1.Object, spec corev1.PodSpec) bool {
+	if !utils.IsLockExempt(obj) {
+		return false
+	}
+	if err := utils.ValidateLockExemption(spec); err != nil {
+		log.Error(err, "ignoring lock exemption of uncapped workload", kind, obj.GetName())
+		return false
+	}
+	log.Info("skipping lock exempt workload", kind, obj.GetName())
+	return true
+}
+
 func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.Namespace) error {
 	log := s.Log.WithValues("namespace", namespace.Name)
 
+	// Revert the measures of the applied lock profile. Namespaces without a recorded
+	// profile are fully reverted.
+	components := utils.LockProfileComponents(namespace.Annotations[constants.AppliedLockProfileAnnotation])
+	if err := s.releaseLock(ctx, namespace.Name, components); err != nil {
+		return err
+	}
+
+	// Remove lock annotations
+	if namespace.Annotations != nil {
+		removed := false
+		for _, key := range utils.LockAnnotations {
+			if _, exists := namespace.Annotations[key]; exists {
+				delete(namespace.Annotations, key)
+				removed = true
+			}
+		}
+		if removed {
+			log.Info("removing lock annotations")
+			if err := s.Update(ctx, namespace); err != nil {
+				log.Error(err, "unable to remove lock annotations")
+				return err
+			}
+			log.Info("successfully removed lock annotations")
+			metrics.NamespacesUnlocked.WithLabelValues(metrics.ComponentScanner).Inc()
+		} else {
+			log.Info("no lock annotations found, nothing to clean")
+		}
+	}
+
+	return nil
+}
+
+// releaseLock reverts the blocking measures selected by components.
+func (s *NamespaceScanner) releaseLock(ctx context.Context, namespace string, components utils.LockComponents) error {
+	if components.Quota {
+		if err := s.removeResourceQuota(ctx, namespace); err != nil {
+			return err
+		}
+	}
+	if components.Network {
+		if err := s.removeNetworkQuarantine(ctx, namespace); err != nil {
+			return err
+		}
+	}
+	if components.Scale {
+		return s.restoreWorkloads(ctx, namespace)
+	}
+	return nil
+}
+
+func (s *NamespaceScanner) removeResourceQuota(ctx context.Context, namespace string) error {
+	log := s.Log.WithValues("namespace", namespace)
+
 	// Delete ResourceQuota if it exists
 	var resourceQuota corev1.ResourceQuota
-	if err := s.Get(ctx, client.ObjectKey{Name: constants.ResourceQuotaName, Namespace: namespace.Name}, &resourceQuota); err == nil {
+	if err := s.Get(ctx, client.ObjectKey{Name: constants.ResourceQuotaName, Namespace: namespace}, &resourceQuota); err == nil {
 		log.Info("deleting ResourceQuota")
 		if err := s.Delete(ctx, &resourceQuota); err != nil {
 			log.Error(err, "unable to delete ResourceQuota")
@@ -355,9 +694,31 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 		}
 	}
 
+	return nil
+}
+
+func (s *NamespaceScanner) removeNetworkQuarantine(ctx context.Context, namespace string) error {
+	log := s.Log.WithValues("namespace", namespace)
+
+	// Delete quarantine NetworkPolicy if it exists
+	var networkPolicy networkingv1.NetworkPolicy
+	if err := s.Get(ctx, client.ObjectKey{Name: constants.NetworkPolicyName, Namespace: namespace}, &networkPolicy); err == nil {
+		log.Info("deleting quarantine NetworkPolicy")
+		if err := s.Delete(ctx, &networkPolicy); err != nil {
+			log.Error(err, "unable to delete quarantine NetworkPolicy")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *NamespaceScanner) restoreWorkloads(ctx context.Context, namespace string) error {
+	log := s.Log.WithValues("namespace", namespace)
+
 	// Scale up deployments
 	var deployments appsv1.DeploymentList
-	if err := s.List(ctx, &deployments, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list deployments")
 		return err
 	}
@@ -375,6 +736,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 				delete(deployment.Annotations, constants.OriginalReplicasAnnotation)
 				if err := s.Update(ctx, &deployment); err != nil {
 					if errors.IsConflict(err) {
+						metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 						log.Info("deployment has been modified, requeueing", "deployment", deployment.Name)
 						return nil
 					}
@@ -387,7 +749,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 
 	// Scale up statefulsets
 	var statefulsets appsv1.StatefulSetList
-	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &statefulsets, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list statefulsets")
 		return err
 	}
@@ -405,6 +767,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 				delete(statefulset.Annotations, constants.OriginalReplicasAnnotation)
 				if err := s.Update(ctx, &statefulset); err != nil {
 					if errors.IsConflict(err) {
+						metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 						log.Info("statefulset has been modified, requeueing", "statefulset", statefulset.Name)
 						return nil
 					}
@@ -417,7 +780,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 
 	// Scale up replicasets
 	var replicasets appsv1.ReplicaSetList
-	if err := s.List(ctx, &replicasets, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &replicasets, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list replicasets")
 		return err
 	}
@@ -435,6 +798,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 				delete(replicaset.Annotations, constants.OriginalReplicasAnnotation)
 				if err := s.Update(ctx, &replicaset); err != nil {
 					if errors.IsConflict(err) {
+						metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 						log.Info("replicaset has been modified, requeueing", "replicaset", replicaset.Name)
 						return nil
 					}
@@ -447,7 +811,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 
 	// Scale up replicationcontrollers
 	var rcs corev1.ReplicationControllerList
-	if err := s.List(ctx, &rcs, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &rcs, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list replicationcontrollers")
 		return err
 	}
@@ -465,6 +829,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 				delete(rc.Annotations, constants.OriginalReplicasAnnotation)
 				if err := s.Update(ctx, &rc); err != nil {
 					if errors.IsConflict(err) {
+						metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 						log.Info("replicationcontroller has been modified, requeueing", "rc", rc.Name)
 						return nil
 					}
@@ -475,9 +840,36 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 		}
 	}
 
+	// Resume horizontal pod autoscalers once the workloads have their replicas back
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := s.List(ctx, &hpas, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "unable to list horizontalpodautoscalers")
+		return err
+	}
+
+	for _, hpa := range hpas.Items {
+		resumed, err := utils.ResumeHPA(&hpa)
+		if err != nil {
+			log.Error(err, "unable to parse original scale up annotation for horizontalpodautoscaler", "hpa", hpa.Name)
+			continue
+		}
+		if resumed {
+			log.Info("resuming horizontalpodautoscaler", "hpa", hpa.Name)
+			if err := s.Update(ctx, &hpa); err != nil {
+				if errors.IsConflict(err) {
+					metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
+					log.Info("horizontalpodautoscaler has been modified, requeueing", "hpa", hpa.Name)
+					return nil
+				}
+				log.Error(err, "unable to resume horizontalpodautoscaler", "hpa", hpa.Name)
+				return err
+			}
+		}
+	}
+
 	// Unsuspend cronjobs
 	var cronjobs batchv1.CronJobList
-	if err := s.List(ctx, &cronjobs, client.InNamespace(namespace.Name)); err != nil {
+	if err := s.List(ctx, &cronjobs, client.InNamespace(namespace)); err != nil {
 		log.Error(err, "unable to list cronjobs")
 		return err
 	}
@@ -495,6 +887,7 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 				delete(cronjob.Annotations, constants.OriginalSuspendAnnotation)
 				if err := s.Update(ctx, &cronjob); err != nil {
 					if errors.IsConflict(err) {
+						metrics.Conflicts.WithLabelValues(metrics.ComponentScanner).Inc()
 						log.Info("cronjob has been modified, requeueing", "cronjob", cronjob.Name)
 						return nil
 					}
@@ -505,32 +898,5 @@ func (s *NamespaceScanner) handleUnlock(ctx context.Context, namespace *corev1.N
 		}
 	}
 
-	// Remove timestamp annotation
-	if namespace.Annotations != nil {
-		if _, exists := namespace.Annotations[constants.UnlockTimestampLabel]; exists {
-			log.Info("removing unlock-timestamp annotation")
-			delete(namespace.Annotations, constants.UnlockTimestampLabel)
-			if err := s.Update(ctx, namespace); err != nil {
-				log.Error(err, "unable to remove timestamp annotation")
-				return err
-			}
-			log.Info("successfully removed unlock-timestamp annotation")
-		} else {
-			log.Info("no unlock-timestamp annotation found, nothing to clean")
-		}
-	}
-
-	return nil
-}
-
-func (s *NamespaceScanner) handleLockExpiration(ctx context.Context, namespace *corev1.Namespace) error {
-	log := s.Log.WithValues("namespace", namespace.Name)
-	log.Info("Lock expired, deleting namespace")
-
-	if err := s.Delete(ctx, namespace); err != nil {
-		log.Error(err, "unable to delete namespace")
-		return err
-	}
-
 	return nil
 }
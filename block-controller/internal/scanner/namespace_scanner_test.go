@@ -0,0 +1,658 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/dryrun"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newTestScanner(objs ...client.Object) *NamespaceScanner {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	return &NamespaceScanner{
+		Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Log:              logr.Discard(),
+		Scheme:           scheme,
+		LockDuration:     time.Hour,
+		FastScanInterval: time.Minute,
+		SlowScanInterval: time.Hour,
+		ScanBatchSize:    10,
+		ScanWorkers:      4,
+	}
+}
+
+func lockedNamespace(name, profile string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{constants.StatusLabel: constants.LockedStatus},
+	}}
+	if profile != "" {
+		ns.Annotations = map[string]string{constants.LockProfileAnnotation: profile}
+	}
+	return ns
+}
+
+func deployment(namespace, name string, replicas int32, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+// TestFastScanProcessesAllNamespaces 测试并发扫描处理所有命名空间
+func TestFastScanProcessesAllNamespaces(t *testing.T) {
+	var objs []client.Object
+	for i := range 25 {
+		objs = append(objs, lockedNamespace(fmt.Sprintf("ns-%d", i), ""))
+	}
+	s := newTestScanner(objs...)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	for i := range 25 {
+		var rq corev1.ResourceQuota
+		key := client.ObjectKey{Namespace: fmt.Sprintf("ns-%d", i), Name: constants.ResourceQuotaName}
+		if err := s.Get(ctx, key, &rq); err != nil {
+			t.Errorf("namespace ns-%d was not locked: %v", i, err)
+		}
+	}
+	if err := s.Healthz(nil); err != nil {
+		t.Errorf("scanner should be healthy after a scan: %v", err)
+	}
+}
+
+// TestLockProfileQuotaOnly 测试 quota-only 配置只创建 ResourceQuota
+func TestLockProfileQuotaOnly(t *testing.T) {
+	s := newTestScanner(
+		lockedNamespace("tenant", constants.LockProfileQuotaOnly),
+		deployment("tenant", "web", 3, nil),
+	)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	var rq corev1.ResourceQuota
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); err != nil {
+		t.Errorf("ResourceQuota should be created: %v", err)
+	}
+	var np networkingv1.NetworkPolicy
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); err == nil {
+		t.Error("NetworkPolicy should not be created for quota-only profile")
+	}
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *d.Spec.Replicas != 3 {
+		t.Errorf("deployment should keep 3 replicas, got %d", *d.Spec.Replicas)
+	}
+
+	var ns corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if ns.Annotations[constants.AppliedLockProfileAnnotation] != constants.LockProfileQuotaOnly {
+		t.Errorf("applied profile should be recorded, got %q", ns.Annotations[constants.AppliedLockProfileAnnotation])
+	}
+}
+
+// TestUnlockRevertsAppliedProfile 测试解锁按已应用的配置撤销
+func TestUnlockRevertsAppliedProfile(t *testing.T) {
+	s := newTestScanner(
+		lockedNamespace("tenant", constants.LockProfileAll),
+		deployment("tenant", "web", 2, nil),
+	)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	var ns corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	ns.Labels[constants.StatusLabel] = constants.ActiveStatus
+	if err := s.Update(ctx, &ns); err != nil {
+		t.Fatalf("failed to unlock namespace: %v", err)
+	}
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	var np networkingv1.NetworkPolicy
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); err == nil {
+		t.Error("NetworkPolicy should be deleted on unlock")
+	}
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *d.Spec.Replicas != 2 {
+		t.Errorf("deployment should be restored to 2 replicas, got %d", *d.Spec.Replicas)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if _, ok := ns.Annotations[constants.AppliedLockProfileAnnotation]; ok {
+		t.Error("applied profile annotation should be removed on unlock")
+	}
+}
+
+// TestLockProfileChangeReleasesMeasures 测试锁定配置变更时撤销不再需要的措施
+func TestLockProfileChangeReleasesMeasures(t *testing.T) {
+	s := newTestScanner(
+		lockedNamespace("tenant", constants.LockProfileAll),
+		deployment("tenant", "web", 2, nil),
+	)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var np networkingv1.NetworkPolicy
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); err != nil {
+		t.Fatalf("NetworkPolicy should be created for all profile: %v", err)
+	}
+
+	var ns corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	ns.Annotations[constants.LockProfileAnnotation] = constants.LockProfileScaleOnly
+	if err := s.Update(ctx, &ns); err != nil {
+		t.Fatalf("failed to change lock profile: %v", err)
+	}
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); !apierrors.IsNotFound(err) {
+		t.Errorf("NetworkPolicy should be released, got %v", err)
+	}
+	var rq corev1.ResourceQuota
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); !apierrors.IsNotFound(err) {
+		t.Errorf("ResourceQuota should be released, got %v", err)
+	}
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *d.Spec.Replicas != 0 {
+		t.Errorf("deployment should stay scaled down, got %d", *d.Spec.Replicas)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if ns.Annotations[constants.AppliedLockProfileAnnotation] != constants.LockProfileScaleOnly {
+		t.Errorf("applied profile should follow the change, got %q", ns.Annotations[constants.AppliedLockProfileAnnotation])
+	}
+}
+
+// TestLockBeforeProfilesKeepsMeasures 测试在锁定配置出现前锁定的命名空间不会被网络隔离
+func TestLockBeforeProfilesKeepsMeasures(t *testing.T) {
+	ns := lockedNamespace("tenant", "")
+	ns.Annotations = map[string]string{
+		constants.UnlockTimestampLabel: time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+	s := newTestScanner(ns, deployment("tenant", "web", 2, nil))
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	var rq corev1.ResourceQuota
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); err != nil {
+		t.Errorf("ResourceQuota should be kept: %v", err)
+	}
+	var np networkingv1.NetworkPolicy
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); err == nil {
+		t.Error("NetworkPolicy should not be created for a lock taken before lock profiles")
+	}
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *d.Spec.Replicas != 0 {
+		t.Errorf("deployment should be scaled down, got %d", *d.Spec.Replicas)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if ns.Annotations[constants.AppliedLockProfileAnnotation] != constants.LockProfileQuotaScale {
+		t.Errorf("applied profile should be %q, got %q", constants.LockProfileQuotaScale, ns.Annotations[constants.AppliedLockProfileAnnotation])
+	}
+
+	// 后续扫描保持原有措施
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.NetworkPolicyName}, &np); err == nil {
+		t.Error("NetworkPolicy should not be created on later scans")
+	}
+}
+
+// TestLockExemptWorkloads 测试豁免标签仅对设置了资源上限的工作负载生效
+func TestLockExemptWorkloads(t *testing.T) {
+	exempt := map[string]string{constants.LockExemptLabel: "true"}
+	capped := deployment("tenant", "billing", 1, exempt)
+	capped.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "exporter",
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		}},
+	}}
+	uncapped := deployment("tenant", "backup", 1, exempt)
+	uncapped.Spec.Template.Spec.Containers = []corev1.Container{{Name: "backup"}}
+
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileScaleOnly), capped, uncapped)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	for name, want := range map[string]int32{"billing": 1, "backup": 0} {
+		var d appsv1.Deployment
+		if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: name}, &d); err != nil {
+			t.Fatalf("failed to get deployment %s: %v", name, err)
+		}
+		if *d.Spec.Replicas != want {
+			t.Errorf("deployment %s: expected %d replicas, got %d", name, want, *d.Spec.Replicas)
+		}
+	}
+}
+
+// TestLockExemptWorkloadsQuotaAllowance 测试锁定配置包含配额和缩容时，豁免的工作负载获得替换 Pod 的配额
+func TestLockExemptWorkloadsQuotaAllowance(t *testing.T) {
+	exempt := map[string]string{constants.LockExemptLabel: "true"}
+	newCapped := func() *appsv1.Deployment {
+		capped := deployment("tenant", "billing", 2, exempt)
+		capped.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "exporter",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			}},
+		}}
+		return capped
+	}
+	uncapped := func() *appsv1.Deployment {
+		d := deployment("tenant", "backup", 1, exempt)
+		d.Spec.Template.Spec.Containers = []corev1.Container{{Name: "backup"}}
+		return d
+	}
+
+	tests := []struct {
+		profile string
+		// pods 为 ResourceQuota 允许的 Pod 数，-1 表示不创建 ResourceQuota
+		pods     int64
+		replicas int32
+	}{
+		{constants.LockProfileAll, 3, 2},
+		{constants.LockProfileQuotaScale, 3, 2},
+		// quota-only 不缩容，配额同时约束所有 Pod，豁免不生效
+		{constants.LockProfileQuotaOnly, 0, 2},
+		{constants.LockProfileScaleOnly, -1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			s := newTestScanner(lockedNamespace("tenant", tt.profile), newCapped(), uncapped())
+			ctx := context.Background()
+			if err := s.fastScan(ctx); err != nil {
+				t.Fatalf("fast scan failed: %v", err)
+			}
+
+			var d appsv1.Deployment
+			if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "billing"}, &d); err != nil {
+				t.Fatalf("failed to get deployment: %v", err)
+			}
+			if *d.Spec.Replicas != tt.replicas {
+				t.Errorf("expected %d replicas, got %d", tt.replicas, *d.Spec.Replicas)
+			}
+
+			var rq corev1.ResourceQuota
+			err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq)
+			if tt.pods < 0 {
+				if err == nil {
+					t.Error("ResourceQuota should not be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get ResourceQuota: %v", err)
+			}
+			pods := rq.Spec.Hard[corev1.ResourcePods]
+			if pods.Value() != tt.pods {
+				t.Errorf("expected %d pods in the quota, got %s", tt.pods, pods.String())
+			}
+			if tt.pods > 0 {
+				limitsCPU := rq.Spec.Hard[corev1.ResourceLimitsCPU]
+				if limitsCPU.Cmp(resource.MustParse("300m")) != 0 {
+					t.Errorf("expected 300m CPU limits in the quota, got %s", limitsCPU.String())
+				}
+			}
+		})
+	}
+}
+
+// TestLockQuotaFollowsExemptions 测试锁定期间豁免标签变化时更新 ResourceQuota
+func TestLockQuotaFollowsExemptions(t *testing.T) {
+	capped := deployment("tenant", "billing", 1, nil)
+	capped.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "exporter",
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		}},
+	}}
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileAll), capped)
+	ctx := context.Background()
+	quotaPods := func() int64 {
+		t.Helper()
+		var rq corev1.ResourceQuota
+		if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); err != nil {
+			t.Fatalf("failed to get ResourceQuota: %v", err)
+		}
+		pods := rq.Spec.Hard[corev1.ResourcePods]
+		return pods.Value()
+	}
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if pods := quotaPods(); pods != 0 {
+		t.Fatalf("expected no pods in the quota, got %d", pods)
+	}
+
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "billing"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	d.Labels = map[string]string{constants.LockExemptLabel: "true"}
+	*d.Spec.Replicas = 1
+	if err := s.Update(ctx, &d); err != nil {
+		t.Fatalf("failed to label deployment: %v", err)
+	}
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if pods := quotaPods(); pods != 2 {
+		t.Errorf("expected the quota to follow the exemption, got %d pods", pods)
+	}
+}
+
+// TestLockPausesAutoscalers 测试锁定暂停 HPA 扩容，解锁后恢复
+func TestLockPausesAutoscalers(t *testing.T) {
+	minReplicas := int32(2)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "tenant"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    5,
+		},
+	}
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileScaleOnly), deployment("tenant", "web", 2, nil), hpa)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var paused autoscalingv2.HorizontalPodAutoscaler
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &paused); err != nil {
+		t.Fatalf("failed to get hpa: %v", err)
+	}
+	if paused.Spec.Behavior == nil || paused.Spec.Behavior.ScaleUp == nil ||
+		*paused.Spec.Behavior.ScaleUp.SelectPolicy != autoscalingv2.DisabledPolicySelect {
+		t.Fatalf("hpa scale up should be disabled, got %+v", paused.Spec.Behavior)
+	}
+
+	var ns corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	ns.Labels[constants.StatusLabel] = constants.ActiveStatus
+	if err := s.Update(ctx, &ns); err != nil {
+		t.Fatalf("failed to unlock namespace: %v", err)
+	}
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var resumed autoscalingv2.HorizontalPodAutoscaler
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &resumed); err != nil {
+		t.Fatalf("failed to get hpa: %v", err)
+	}
+	if resumed.Spec.Behavior != nil || resumed.Annotations[constants.OriginalHPAScaleUpAnnotation] != "" {
+		t.Errorf("hpa should be restored, got %+v %v", resumed.Spec.Behavior, resumed.Annotations)
+	}
+}
+
+// TestEvictionRespectsPodDisruptionBudget 测试 PDB 拒绝驱逐时先等待，超时后才直接删除
+func TestEvictionRespectsPodDisruptionBudget(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "tenant"}}
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileScaleOnly), pod)
+	s.PDBEvictionTimeout = time.Minute
+	evictions := 0
+	s.Client = interceptor.NewClient(s.Client.(client.WithWatch), interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, sub client.Object, opts ...client.SubResourceCreateOption) error {
+			evictions++
+			return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		},
+	})
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var blocked corev1.Pod
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "standalone"}, &blocked); err != nil {
+		t.Fatalf("pod blocked by a disruption budget should not be deleted: %v", err)
+	}
+	if blocked.Annotations[constants.EvictionBlockedAtAnnotation] == "" {
+		t.Fatal("blocked eviction should be recorded on the pod")
+	}
+
+	// 超时前继续等待
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "standalone"}, &blocked); err != nil {
+		t.Fatalf("pod should survive until the eviction timeout: %v", err)
+	}
+
+	blocked.Annotations[constants.EvictionBlockedAtAnnotation] = time.Now().Add(-2 * time.Minute).Format(time.RFC3339)
+	if err := s.Update(ctx, &blocked); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "standalone"}, &blocked); !apierrors.IsNotFound(err) {
+		t.Errorf("pod should be deleted after the eviction timeout, got %v", err)
+	}
+	if evictions != 3 {
+		t.Errorf("expected 3 eviction attempts, got %d", evictions)
+	}
+}
+
+// TestEvictionRemovesStandalonePods 测试无 PDB 限制时通过驱逐删除独立 Pod
+func TestEvictionRemovesStandalonePods(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "tenant"}}
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileScaleOnly), pod)
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var evicted corev1.Pod
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "standalone"}, &evicted); !apierrors.IsNotFound(err) {
+		t.Errorf("standalone pod should be evicted, got %v", err)
+	}
+}
+
+// TestDryRunScanLeavesNamespace 测试 dry-run 模式下扫描不持久化任何变更
+func TestDryRunScanLeavesNamespace(t *testing.T) {
+	s := newTestScanner(lockedNamespace("tenant", constants.LockProfileAll), deployment("tenant", "web", 2, nil))
+	dryRunClient := dryrun.NewClient(s.Client, logr.Discard())
+	s.Client = dryRunClient
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if dryRunClient.Changes() == 0 {
+		t.Error("dry run should record the changes of the lock")
+	}
+	var ns corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &ns); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if _, ok := ns.Annotations[constants.UnlockTimestampLabel]; ok {
+		t.Error("dry run should not record the unlock timestamp")
+	}
+	var d appsv1.Deployment
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "web"}, &d); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *d.Spec.Replicas != 2 {
+		t.Errorf("dry run should not scale down the deployment, got %d", *d.Spec.Replicas)
+	}
+	var rq corev1.ResourceQuota
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: constants.ResourceQuotaName}, &rq); err == nil {
+		t.Error("dry run should not create the ResourceQuota")
+	}
+}
+
+func expiredNamespace(name, policy string, allowDeletion bool) *corev1.Namespace {
+	ns := lockedNamespace(name, "")
+	ns.Annotations = map[string]string{
+		constants.UnlockTimestampLabel:   time.Now().Add(-time.Minute).Format(time.RFC3339),
+		constants.ExpiryPolicyAnnotation: policy,
+	}
+	if allowDeletion {
+		ns.Annotations[constants.AllowDeletionAnnotation] = "true"
+	}
+	return ns
+}
+
+// TestExpiredLockKeepsNamespaceByDefault 测试默认过期策略不会删除命名空间
+func TestExpiredLockKeepsNamespaceByDefault(t *testing.T) {
+	for _, ns := range []*corev1.Namespace{
+		expiredNamespace("default-policy", "", true),
+		expiredNamespace("no-opt-in", constants.ExpiryPolicyArchiveThenDelete, false),
+	} {
+		s := newTestScanner(ns)
+		ctx := context.Background()
+
+		if err := s.fastScan(ctx); err != nil {
+			t.Fatalf("fast scan failed: %v", err)
+		}
+
+		var got corev1.Namespace
+		if err := s.Get(ctx, client.ObjectKey{Name: ns.Name}, &got); err != nil {
+			t.Fatalf("namespace %s should not be deleted: %v", ns.Name, err)
+		}
+		if got.Labels[constants.StatusLabel] != constants.LockedStatus {
+			t.Errorf("namespace %s should stay locked", ns.Name)
+		}
+		if got.Annotations[constants.ExpiredAtAnnotation] == "" {
+			t.Errorf("namespace %s should record the expiration", ns.Name)
+		}
+	}
+}
+
+// TestExpiredLockUnlock 测试 unlock 过期策略
+func TestExpiredLockUnlock(t *testing.T) {
+	s := newTestScanner(expiredNamespace("tenant", constants.ExpiryPolicyUnlock, false))
+	ctx := context.Background()
+
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+
+	var got corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &got); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if got.Labels[constants.StatusLabel] != constants.ActiveStatus {
+		t.Errorf("namespace should be unlocked, got status %q", got.Labels[constants.StatusLabel])
+	}
+	if _, ok := got.Annotations[constants.UnlockTimestampLabel]; ok {
+		t.Error("unlock timestamp should be removed")
+	}
+}
+
+// TestExpiredLockArchiveThenDelete 测试归档后在宽限期结束时删除
+func TestExpiredLockArchiveThenDelete(t *testing.T) {
+	ns := expiredNamespace("tenant", constants.ExpiryPolicyArchiveThenDelete, true)
+	ns.Annotations[constants.ExpiryGracePeriodAnnotation] = "0s"
+	s := newTestScanner(ns, deployment("tenant", "web", 2, nil))
+	s.ArchiveNamespace = "block-system"
+	ctx := context.Background()
+
+	// 第一次扫描：归档并安排删除
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	var archive corev1.ConfigMap
+	if err := s.Get(ctx, client.ObjectKey{Namespace: "block-system", Name: "archive-tenant"}, &archive); err != nil {
+		t.Fatalf("archive should be created: %v", err)
+	}
+	if archive.Data["archive.json"] == "" {
+		t.Error("archive should contain the namespace workloads")
+	}
+	var got corev1.Namespace
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &got); err != nil {
+		t.Fatalf("namespace should still exist during the first scan: %v", err)
+	}
+	if got.Annotations[constants.DeletionScheduledAnnotation] == "" {
+		t.Error("deletion should be scheduled")
+	}
+
+	// 第二次扫描：宽限期结束，删除命名空间
+	if err := s.fastScan(ctx); err != nil {
+		t.Fatalf("fast scan failed: %v", err)
+	}
+	if err := s.Get(ctx, client.ObjectKey{Name: "tenant"}, &got); err == nil {
+		t.Error("namespace should be deleted after the grace period")
+	}
+}
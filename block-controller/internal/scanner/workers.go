@@ -0,0 +1,123 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/metrics"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	fastScanName = "fast"
+	slowScanName = "slow"
+
+	defaultScanWorkers = 1
+)
+
+// scanProgress tracks the namespaces processed by a single scan
+type scanProgress struct {
+	scan      string
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+func newScanProgress(scan string) *scanProgress {
+	metrics.ScanProgress.WithLabelValues(scan).Set(0)
+	return &scanProgress{scan: scan}
+}
+
+func (p *scanProgress) record(err error) {
+	if err != nil {
+		p.failed.Add(1)
+	}
+	metrics.ScanNamespaces.WithLabelValues(p.scan, metrics.Result(err)).Inc()
+	metrics.ScanProgress.WithLabelValues(p.scan).Set(float64(p.processed.Add(1)))
+}
+
+// processNamespaces processes namespaces with at most ScanWorkers concurrent workers.
+// A failure or panic while processing one namespace is logged and does not stop the others.
+func (s *NamespaceScanner) processNamespaces(ctx context.Context, log logr.Logger, progress *scanProgress, namespaces []corev1.Namespace) {
+	workers := s.ScanWorkers
+	if workers <= 0 {
+		workers = defaultScanWorkers
+	}
+	if workers > len(namespaces) {
+		workers = len(namespaces)
+	}
+
+	queue := make(chan *corev1.Namespace)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ns := range queue {
+				if !s.waitJitter(ctx) {
+					continue
+				}
+				err := s.processNamespaceSafely(ctx, *ns)
+				if err != nil {
+					log.Error(err, "failed to process namespace", "namespace", ns.Name)
+				}
+				progress.record(err)
+			}
+		}()
+	}
+
+send:
+	for i := range namespaces {
+		select {
+		case <-ctx.Done():
+			break send
+		case queue <- &namespaces[i]:
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// processNamespaceSafely runs processNamespace and turns a panic into an error
+func (s *NamespaceScanner) processNamespaceSafely(ctx context.Context, namespace corev1.Namespace) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while processing namespace: %v", r)
+		}
+	}()
+	return s.processNamespace(ctx, namespace)
+}
+
+// waitJitter sleeps for a random duration up to ScanJitter. It returns false if the
+// context was cancelled while waiting.
+func (s *NamespaceScanner) waitJitter(ctx context.Context) bool {
+	if s.ScanJitter <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(rand.N(s.ScanJitter))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+// PauseHPA disables the scale up of a HorizontalPodAutoscaler so that it cannot bring a locked
+// workload back, and records the original scale up behavior for ResumeHPA. Setting minReplicas
+// to 0 is not used because it requires the HPAScaleToZero feature gate and would let
+// object and external metrics scale the workload up from zero. It returns false if the
+// autoscaler is already paused.
+func PauseHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) (bool, error) {
+	if _, ok := hpa.Annotations[constants.OriginalHPAScaleUpAnnotation]; ok {
+		return false, nil
+	}
+
+	var original *autoscalingv2.HPAScalingRules
+	if hpa.Spec.Behavior != nil {
+		original = hpa.Spec.Behavior.ScaleUp
+	}
+	value, err := json.Marshal(original)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode scale up behavior: %w", err)
+	}
+
+	if hpa.Annotations == nil {
+		hpa.Annotations = make(map[string]string)
+	}
+	hpa.Annotations[constants.OriginalHPAScaleUpAnnotation] = string(value)
+	if hpa.Spec.Behavior == nil {
+		hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	}
+	disabled := autoscalingv2.DisabledPolicySelect
+	hpa.Spec.Behavior.ScaleUp = &autoscalingv2.HPAScalingRules{SelectPolicy: &disabled}
+	return true, nil
+}
+
+// ResumeHPA restores the scale up behavior recorded by PauseHPA. It returns false if the
+// autoscaler was not paused.
+func ResumeHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) (bool, error) {
+	value, ok := hpa.Annotations[constants.OriginalHPAScaleUpAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	var original *autoscalingv2.HPAScalingRules
+	if err := json.Unmarshal([]byte(value), &original); err != nil {
+		return false, fmt.Errorf("failed to parse original scale up behavior: %w", err)
+	}
+
+	if hpa.Spec.Behavior == nil {
+		hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	}
+	hpa.Spec.Behavior.ScaleUp = original
+	if hpa.Spec.Behavior.ScaleUp == nil && hpa.Spec.Behavior.ScaleDown == nil {
+		hpa.Spec.Behavior = nil
+	}
+	delete(hpa.Annotations, constants.OriginalHPAScaleUpAnnotation)
+	return true, nil
+}
+
+// HPATargetsExempt reports whether an autoscaler scales one of the exempt workloads, keyed by
+// "Kind/name". Autoscalers of exempt workloads are left running.
+func HPATargetsExempt(hpa *autoscalingv2.HorizontalPodAutoscaler, exempt map[string]bool) bool {
+	return exempt[hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name]
+}
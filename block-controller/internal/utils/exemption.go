@@ -0,0 +1,109 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsLockExempt reports whether a workload carries the lock exemption label.
+func IsLockExempt(obj metav1.Object) bool {
+	return obj.GetLabels()[constants.LockExemptLabel] == "true"
+}
+
+// ValidateLockExemption checks that an exempt workload stays capped while its namespace is locked.
+// Every container must declare CPU and memory limits, otherwise the exemption is not honored: the
+// limits size the quota allowance of the workload, and the lock quota rejects pods without them.
+func ValidateLockExemption(spec corev1.PodSpec) error {
+	containers := make([]corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers))
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := container.Resources.Limits[name]; !ok {
+				return fmt.Errorf("container %s has no %s limit", container.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// LockExemptionAllowance returns the share of the lock ResourceQuota an exempt workload needs to
+// keep running: one pod more than its replicas, so a rollout or an evicted pod can be replaced,
+// each sized by the requests and limits of the pod. Unset replicas count as one, unset requests
+// default to the limits like the API server does.
+func LockExemptionAllowance(replicas *int32, spec corev1.PodSpec) corev1.ResourceList {
+	pods := int64(1)
+	if replicas != nil {
+		pods = int64(*replicas)
+	}
+	pods++
+
+	allowance := corev1.ResourceList{corev1.ResourcePods: *resource.NewQuantity(pods, resource.DecimalSI)}
+	for quotaName, name := range map[corev1.ResourceName]corev1.ResourceName{
+		corev1.ResourceRequestsCPU:    corev1.ResourceCPU,
+		corev1.ResourceRequestsMemory: corev1.ResourceMemory,
+		corev1.ResourceLimitsCPU:      corev1.ResourceCPU,
+		corev1.ResourceLimitsMemory:   corev1.ResourceMemory,
+	} {
+		requests := quotaName == corev1.ResourceRequestsCPU || quotaName == corev1.ResourceRequestsMemory
+		perPod := podResource(spec, name, requests)
+		total := resource.Quantity{Format: perPod.Format}
+		for range pods {
+			total.Add(perPod)
+		}
+		allowance[quotaName] = total
+	}
+	return allowance
+}
+
+// podResource returns the request or limit of a pod for a resource: the sum over its containers,
+// or the largest init container when that is higher
+func podResource(spec corev1.PodSpec, name corev1.ResourceName, requests bool) resource.Quantity {
+	value := func(container corev1.Container) resource.Quantity {
+		if requests {
+			if quantity, ok := container.Resources.Requests[name]; ok {
+				return quantity
+			}
+		}
+		return container.Resources.Limits[name]
+	}
+	var sum resource.Quantity
+	for _, container := range spec.Containers {
+		sum.Add(value(container))
+	}
+	for _, container := range spec.InitContainers {
+		if quantity := value(container); quantity.Cmp(sum) > 0 {
+			sum = quantity
+		}
+	}
+	return sum
+}
+
+// AddResources adds the quantities of add to total.
+func AddResources(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
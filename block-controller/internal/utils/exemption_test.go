@@ -0,0 +1,108 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func limits(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func TestValidateLockExemption(t *testing.T) {
+	capped := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Limits: limits("100m", "64Mi")}}}}
+	if err := ValidateLockExemption(capped); err != nil {
+		t.Errorf("capped workload should be exempt, got %v", err)
+	}
+	uncappedInit := capped
+	uncappedInit.InitContainers = []corev1.Container{{Name: "init"}}
+	if err := ValidateLockExemption(uncappedInit); err == nil {
+		t.Error("workload with an uncapped init container should not be exempt")
+	}
+}
+
+func TestLockExemptionAllowance(t *testing.T) {
+	replicas := int32(2)
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "migrate", Resources: corev1.ResourceRequirements{Limits: limits("1", "64Mi")}}},
+		Containers: []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   limits("200m", "128Mi"),
+			}},
+			{Name: "sidecar", Resources: corev1.ResourceRequirements{Limits: limits("100m", "32Mi")}},
+		},
+	}
+
+	// 两个副本加一个替换 Pod；CPU 上限取更高的 init 容器，未设置的请求取上限
+	want := map[corev1.ResourceName]string{
+		corev1.ResourcePods:           "3",
+		corev1.ResourceRequestsCPU:    "3",
+		corev1.ResourceRequestsMemory: "480Mi",
+		corev1.ResourceLimitsCPU:      "3",
+		corev1.ResourceLimitsMemory:   "480Mi",
+	}
+	allowance := LockExemptionAllowance(&replicas, spec)
+	for name, value := range want {
+		if got := allowance[name]; got.Cmp(resource.MustParse(value)) != 0 {
+			t.Errorf("%s: expected %s, got %s", name, value, got.String())
+		}
+	}
+
+	// 不设置 init 容器时按容器之和计算，未设置副本数按 1 处理
+	spec.InitContainers = nil
+	allowance = LockExemptionAllowance(nil, spec)
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourcePods:        "2",
+		corev1.ResourceRequestsCPU: "400m",
+		corev1.ResourceLimitsCPU:   "600m",
+	} {
+		if got := allowance[name]; got.Cmp(resource.MustParse(value)) != 0 {
+			t.Errorf("%s: expected %s, got %s", name, value, got.String())
+		}
+	}
+}
+
+func TestCreateResourceQuotaAllowance(t *testing.T) {
+	replicas := int32(1)
+	spec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Limits: limits("100m", "64Mi")}}}}
+	rq := CreateResourceQuota("tenant", false, LockExemptionAllowance(&replicas, spec))
+
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourcePods:         "2",
+		corev1.ResourceLimitsCPU:    "200m",
+		corev1.ResourceLimitsMemory: "128Mi",
+		// 其余资源仍然禁止创建
+		corev1.ResourceServices: "0",
+		corev1.ResourceSecrets:  "0",
+	} {
+		if got := rq.Spec.Hard[name]; got.Cmp(resource.MustParse(value)) != 0 {
+			t.Errorf("%s: expected %s, got %s", name, value, got.String())
+		}
+	}
+
+	if pods := CreateResourceQuota("tenant", false, nil).Spec.Hard[corev1.ResourcePods]; !pods.IsZero() {
+		t.Errorf("quota without exempt workloads should allow no pods, got %s", pods.String())
+	}
+}
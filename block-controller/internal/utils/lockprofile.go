@@ -0,0 +1,108 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+)
+
+// LockAnnotations are the namespace annotations that are removed when a namespace is unlocked.
+var LockAnnotations = []string{
+	constants.UnlockTimestampLabel,
+	constants.LockProfileAnnotation,
+	constants.AppliedLockProfileAnnotation,
+	constants.ExpiryPolicyAnnotation,
+	constants.ExpiryGracePeriodAnnotation,
+	constants.ExpiredAtAnnotation,
+	constants.DeletionScheduledAnnotation,
+}
+
+// LockComponents describes which blocking measures are part of a lock.
+type LockComponents struct {
+	Quota   bool
+	Scale   bool
+	Network bool
+}
+
+// LockProfileComponents returns the blocking measures of a lock profile.
+// Empty or unknown profiles fall back to all measures so that namespaces locked
+// before profiles existed are fully reverted on unlock.
+func LockProfileComponents(profile string) LockComponents {
+	switch profile {
+	case constants.LockProfileQuotaOnly:
+		return LockComponents{Quota: true}
+	case constants.LockProfileScaleOnly:
+		return LockComponents{Scale: true}
+	case constants.LockProfileNetworkOnly:
+		return LockComponents{Network: true}
+	case constants.LockProfileQuotaScale:
+		return LockComponents{Quota: true, Scale: true}
+	default:
+		return LockComponents{Quota: true, Scale: true, Network: true}
+	}
+}
+
+// NormalizeExpiryPolicy returns policy if it is a known expiry policy and ExpiryPolicyKeepLocked
+// otherwise, so that a missing policy never leads to deletion.
+func NormalizeExpiryPolicy(policy string) string {
+	switch policy {
+	case constants.ExpiryPolicyUnlock, constants.ExpiryPolicyArchiveThenDelete:
+		return policy
+	default:
+		return constants.ExpiryPolicyKeepLocked
+	}
+}
+
+// NormalizeLockProfile returns profile if it is a known lock profile and LockProfileAll otherwise.
+func NormalizeLockProfile(profile string) string {
+	switch profile {
+	case constants.LockProfileQuotaOnly, constants.LockProfileScaleOnly, constants.LockProfileNetworkOnly,
+		constants.LockProfileQuotaScale:
+		return profile
+	default:
+		return constants.LockProfileAll
+	}
+}
+
+// ResolveLockProfile returns the lock profile of a locked namespace from its annotations.
+// Namespaces that were locked before lock profiles existed have an unlock timestamp but
+// neither a requested nor an applied profile; they keep the measures of that lock
+// (LockProfileQuotaScale) instead of being quarantined by the default profile.
+func ResolveLockProfile(annotations map[string]string) string {
+	if profile, ok := annotations[constants.LockProfileAnnotation]; ok {
+		return NormalizeLockProfile(profile)
+	}
+	_, locked := annotations[constants.UnlockTimestampLabel]
+	if _, applied := annotations[constants.AppliedLockProfileAnnotation]; locked && !applied {
+		return constants.LockProfileQuotaScale
+	}
+	return NormalizeLockProfile(annotations[constants.AppliedLockProfileAnnotation])
+}
+
+// Without returns the measures of c that are not part of other.
+func (c LockComponents) Without(other LockComponents) LockComponents {
+	return LockComponents{
+		Quota:   c.Quota && !other.Quota,
+		Scale:   c.Scale && !other.Scale,
+		Network: c.Network && !other.Network,
+	}
+}
+
+// Any reports whether at least one measure is set.
+func (c LockComponents) Any() bool {
+	return c.Quota || c.Scale || c.Network
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 CompliK Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+)
+
+func TestLockProfileComponents(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    LockComponents
+	}{
+		{constants.LockProfileAll, LockComponents{Quota: true, Scale: true, Network: true}},
+		{constants.LockProfileQuotaOnly, LockComponents{Quota: true}},
+		{constants.LockProfileScaleOnly, LockComponents{Scale: true}},
+		{constants.LockProfileNetworkOnly, LockComponents{Network: true}},
+		{constants.LockProfileQuotaScale, LockComponents{Quota: true, Scale: true}},
+		// 未记录或未知的配置全部撤销
+		{"", LockComponents{Quota: true, Scale: true, Network: true}},
+		{"unknown", LockComponents{Quota: true, Scale: true, Network: true}},
+	}
+	for _, tt := range tests {
+		if got := LockProfileComponents(tt.profile); got != tt.want {
+			t.Errorf("LockProfileComponents(%q) = %+v, want %+v", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLockProfile(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{constants.LockProfileAll, constants.LockProfileAll},
+		{constants.LockProfileQuotaOnly, constants.LockProfileQuotaOnly},
+		{constants.LockProfileScaleOnly, constants.LockProfileScaleOnly},
+		{constants.LockProfileNetworkOnly, constants.LockProfileNetworkOnly},
+		{constants.LockProfileQuotaScale, constants.LockProfileQuotaScale},
+		{"", constants.LockProfileAll},
+		{"unknown", constants.LockProfileAll},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLockProfile(tt.profile); got != tt.want {
+			t.Errorf("NormalizeLockProfile(%q) = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestResolveLockProfile(t *testing.T) {
+	unlock := "2025-01-01T00:00:00Z"
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{"new lock", nil, constants.LockProfileAll},
+		{"requested profile", map[string]string{
+			constants.LockProfileAnnotation: constants.LockProfileNetworkOnly,
+		}, constants.LockProfileNetworkOnly},
+		{"unknown requested profile", map[string]string{
+			constants.LockProfileAnnotation: "unknown",
+		}, constants.LockProfileAll},
+		{"requested profile overrides applied", map[string]string{
+			constants.UnlockTimestampLabel:         unlock,
+			constants.LockProfileAnnotation:        constants.LockProfileScaleOnly,
+			constants.AppliedLockProfileAnnotation: constants.LockProfileAll,
+		}, constants.LockProfileScaleOnly},
+		{"applied profile", map[string]string{
+			constants.UnlockTimestampLabel:         unlock,
+			constants.AppliedLockProfileAnnotation: constants.LockProfileQuotaOnly,
+		}, constants.LockProfileQuotaOnly},
+		{"locked before profiles", map[string]string{
+			constants.UnlockTimestampLabel: unlock,
+		}, constants.LockProfileQuotaScale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveLockProfile(tt.annotations); got != tt.want {
+				t.Errorf("ResolveLockProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockComponentsWithout(t *testing.T) {
+	all := LockProfileComponents(constants.LockProfileAll)
+	tests := []struct {
+		name     string
+		from, to LockComponents
+		want     LockComponents
+	}{
+		{"all to scale-only", all, LockComponents{Scale: true}, LockComponents{Quota: true, Network: true}},
+		{"quota-scale to all", LockComponents{Quota: true, Scale: true}, all, LockComponents{}},
+		{"network-only to quota-only", LockComponents{Network: true}, LockComponents{Quota: true}, LockComponents{Network: true}},
+		{"unchanged", all, all, LockComponents{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.Without(tt.to)
+			if got != tt.want {
+				t.Errorf("Without() = %+v, want %+v", got, tt.want)
+			}
+			if got.Any() != (tt.want != LockComponents{}) {
+				t.Errorf("Any() = %v for %+v", got.Any(), got)
+			}
+		})
+	}
+}
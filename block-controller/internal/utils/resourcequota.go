@@ -15,7 +15,7 @@ limitations under the License.
 */
 
 // Package utils provides utility functions for the block-controller.
-// This includes resource quota creation and management utilities.
+// This includes resource quota, quarantine network policy and lock profile utilities.
 package utils
 
 import (
@@ -26,9 +26,10 @@ import (
 )
 
 // CreateResourceQuota creates a ResourceQuota object that restricts resource creation in a namespace.
-// It sets all resource limits to 0 to effectively block new resource creation.
+// It sets all resource limits to 0 to effectively block new resource creation, except for the
+// allowance granted to lock exempt workloads, see LockExemptionAllowance.
 // If blockStorage is true, it also restricts storage requests.
-func CreateResourceQuota(namespace string, blockStorage bool) *v1.ResourceQuota {
+func CreateResourceQuota(namespace string, blockStorage bool, allowance v1.ResourceList) *v1.ResourceQuota {
 	resources := v1.ResourceList{
 		"pods":                   resource.MustParse("0"),
 		"services":               resource.MustParse("0"),
@@ -47,6 +48,7 @@ func CreateResourceQuota(namespace string, blockStorage bool) *v1.ResourceQuota
 	if blockStorage {
 		resources["requests.storage"] = resource.MustParse("0")
 	}
+	AddResources(resources, allowance)
 
 	return &v1.ResourceQuota{
 		ObjectMeta: metav1.ObjectMeta{
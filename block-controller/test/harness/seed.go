@@ -0,0 +1,187 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SeedOptions describes a fleet of fake namespaces and workloads.
+type SeedOptions struct {
+	// Prefix names the namespaces <prefix>-<index>. Namespaces are never fully deleted by
+	// envtest, which runs no namespace controller, so every test should use its own prefix.
+	Prefix string
+	// Namespaces is the number of namespaces to create
+	Namespaces int
+	// Deployments and StatefulSets are the number of workloads to create per namespace
+	Deployments  int
+	StatefulSets int
+	// Replicas is the replica count of every workload, 1 by default
+	Replicas int32
+	// Labels and Annotations are set on every namespace, e.g. to create them locked
+	Labels      map[string]string
+	Annotations map[string]string
+	// Concurrency is the number of namespaces created in parallel, 20 by default
+	Concurrency int
+}
+
+// Fleet is a seeded set of namespaces.
+type Fleet struct {
+	Namespaces []string
+	Options    SeedOptions
+}
+
+// Seed creates the namespaces and workloads described by opts. No pods are created, since
+// envtest runs no workload controllers; locks act on the replica counts of the workloads.
+func Seed(ctx context.Context, c client.Client, opts SeedOptions) (*Fleet, error) {
+	if opts.Replicas == 0 {
+		opts.Replicas = 1
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 20
+	}
+
+	fleet := &Fleet{Namespaces: make([]string, opts.Namespaces), Options: opts}
+	for i := range fleet.Namespaces {
+		fleet.Namespaces[i] = fmt.Sprintf("%s-%d", opts.Prefix, i)
+	}
+
+	err := fleet.ForEach(ctx, opts.Concurrency, func(ctx context.Context, name string) error {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      copyMap(opts.Labels),
+			Annotations: copyMap(opts.Annotations),
+		}}
+		if err := c.Create(ctx, ns); err != nil {
+			return fmt.Errorf("failed to create namespace %s: %w", name, err)
+		}
+		for i := 0; i < opts.Deployments; i++ {
+			if err := c.Create(ctx, deployment(name, fmt.Sprintf("deploy-%d", i), opts.Replicas)); err != nil {
+				return fmt.Errorf("failed to create deployment in %s: %w", name, err)
+			}
+		}
+		for i := 0; i < opts.StatefulSets; i++ {
+			if err := c.Create(ctx, statefulSet(name, fmt.Sprintf("sts-%d", i), opts.Replicas)); err != nil {
+				return fmt.Errorf("failed to create statefulset in %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fleet, nil
+}
+
+// ForEach calls fn for every namespace of the fleet with up to concurrency calls in flight
+// and returns the first error.
+func (f *Fleet) ForEach(ctx context.Context, concurrency int, fn func(ctx context.Context, namespace string) error) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	names := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				if err := fn(ctx, name); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	for _, name := range f.Namespaces {
+		if ctx.Err() != nil {
+			break
+		}
+		names <- name
+	}
+	close(names)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// Workloads returns the number of workloads of every namespace of the fleet.
+func (f *Fleet) Workloads() int {
+	return f.Options.Deployments + f.Options.StatefulSets
+}
+
+func deployment(namespace, name string, replicas int32) *appsv1.Deployment {
+	labels := map[string]string{"app": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: podTemplate(labels),
+		},
+	}
+}
+
+func statefulSet(namespace, name string, replicas int32) *appsv1.StatefulSet {
+	labels := map[string]string{"app": name}
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: name,
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			Template:    podTemplate(labels),
+		},
+	}
+}
+
+func podTemplate(labels map[string]string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "registry.k8s.io/pause:3.10"}},
+		},
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetStatus sets the status label of a namespace, as `kubectl block` or a BlockRequest does,
+// and merges annotations into its annotations. An empty annotation value removes the key.
+func SetStatus(ctx context.Context, c client.Client, namespace, status string, annotations map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var ns corev1.Namespace
+		if err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+			return err
+		}
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		ns.Labels[constants.StatusLabel] = status
+		if ns.Annotations == nil {
+			ns.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			if v == "" {
+				delete(ns.Annotations, k)
+			} else {
+				ns.Annotations[k] = v
+			}
+		}
+		return c.Update(ctx, &ns)
+	})
+}
+
+// Expire moves the unlock timestamp of a locked namespace into the past, so that the next
+// scan applies its expiry policy.
+func Expire(ctx context.Context, c client.Client, namespace string) error {
+	return SetStatus(ctx, c, namespace, constants.LockedStatus, map[string]string{
+		constants.UnlockTimestampLabel: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	})
+}
+
+// Status returns the status label of a namespace.
+func Status(ctx context.Context, c client.Client, namespace string) (string, error) {
+	var ns corev1.Namespace
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return "", err
+	}
+	return ns.Labels[constants.StatusLabel], nil
+}
+
+// Annotation returns an annotation of a namespace.
+func Annotation(ctx context.Context, c client.Client, namespace, key string) (string, error) {
+	var ns corev1.Namespace
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return "", err
+	}
+	return ns.Annotations[key], nil
+}
+
+// ScaledDown reports whether every Deployment and StatefulSet of a namespace was scaled to
+// zero with its original replica count recorded.
+func ScaledDown(ctx context.Context, c client.Client, namespace string) (bool, error) {
+	return allReplicas(ctx, c, namespace, func(replicas int32, annotations map[string]string) bool {
+		_, recorded := annotations[constants.OriginalReplicasAnnotation]
+		return replicas == 0 && recorded
+	})
+}
+
+// Restored reports whether every Deployment and StatefulSet of a namespace runs want replicas
+// again and no original replica count is left behind.
+func Restored(ctx context.Context, c client.Client, namespace string, want int32) (bool, error) {
+	return allReplicas(ctx, c, namespace, func(replicas int32, annotations map[string]string) bool {
+		_, recorded := annotations[constants.OriginalReplicasAnnotation]
+		return replicas == want && !recorded
+	})
+}
+
+// HasQuota reports whether the lock ResourceQuota exists in a namespace.
+func HasQuota(ctx context.Context, c client.Client, namespace string) (bool, error) {
+	var quota corev1.ResourceQuota
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: constants.ResourceQuotaName}, &quota)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func allReplicas(ctx context.Context, c client.Client, namespace string, match func(int32, map[string]string) bool) (bool, error) {
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	for _, d := range deployments.Items {
+		if !match(replicasOf(d.Spec.Replicas), d.Annotations) {
+			return false, nil
+		}
+	}
+	var statefulsets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulsets, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	for _, s := range statefulsets.Items {
+		if !match(replicasOf(s.Spec.Replicas), s.Annotations) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// WaitFor polls cond every interval until it holds, fails or timeout passes.
+func WaitFor(ctx context.Context, interval, timeout time.Duration, cond func(ctx context.Context) (bool, error)) error {
+	return wait.PollUntilContextTimeout(ctx, interval, timeout, true, cond)
+}
+
+// WaitAll polls cond for every namespace of the fleet until it holds for all of them. Namespaces
+// are polled again only while cond does not hold yet.
+func (f *Fleet) WaitAll(ctx context.Context, interval, timeout time.Duration, cond func(ctx context.Context, namespace string) (bool, error)) error {
+	pending := append([]string(nil), f.Namespaces...)
+	return WaitFor(ctx, interval, timeout, func(ctx context.Context) (bool, error) {
+		remaining := pending[:0]
+		for _, ns := range pending {
+			ok, err := cond(ctx, ns)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				remaining = append(remaining, ns)
+			}
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+}
+
+// HeapInUse returns the bytes of in-use heap spans after a garbage collection, to compare the
+// memory of a controller before and after it processed the fleet.
+func HeapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
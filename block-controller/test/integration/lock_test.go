@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"time"
+
+	apiv1 "github.com/bearslyricattack/CompliK/block-controller/api/v1"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/controller"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/scanner"
+	"github.com/bearslyricattack/CompliK/block-controller/test/harness"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const pollInterval = 500 * time.Millisecond
+
+var _ = Describe("Namespace scanner", Ordered, func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeAll(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		s := &scanner.NamespaceScanner{
+			Client:           env.Client,
+			Log:              GinkgoLogr.WithName("namespace-scanner"),
+			Scheme:           env.Scheme,
+			LockDuration:     time.Hour,
+			FastScanInterval: time.Second,
+			SlowScanInterval: time.Minute,
+			ScanBatchSize:    100,
+			ScanWorkers:      20,
+			ArchiveNamespace: "block-system",
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(s.Start(ctx)).To(Succeed())
+		}()
+	})
+
+	AfterAll(func() {
+		cancel()
+	})
+
+	It("should lock and unlock every namespace of the fleet", func() {
+		fleet := seed(ctx, "lock", nil, nil)
+
+		By("locking the fleet")
+		Expect(fleet.ForEach(ctx, 20, func(ctx context.Context, ns string) error {
+			return harness.SetStatus(ctx, env.Client, ns, constants.LockedStatus, nil)
+		})).To(Succeed())
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			if ok, err := harness.HasQuota(ctx, env.Client, ns); !ok || err != nil {
+				return false, err
+			}
+			return harness.ScaledDown(ctx, env.Client, ns)
+		})).To(Succeed())
+
+		By("unlocking the fleet")
+		Expect(fleet.ForEach(ctx, 20, func(ctx context.Context, ns string) error {
+			return harness.SetStatus(ctx, env.Client, ns, constants.ActiveStatus, nil)
+		})).To(Succeed())
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			if ok, err := harness.HasQuota(ctx, env.Client, ns); ok || err != nil {
+				return false, err
+			}
+			return harness.Restored(ctx, env.Client, ns, 2)
+		})).To(Succeed())
+	})
+
+	It("should unlock expired namespaces with the unlock policy", func() {
+		fleet := seed(ctx, "expire-unlock", map[string]string{
+			constants.StatusLabel: constants.LockedStatus,
+		}, map[string]string{
+			constants.ExpiryPolicyAnnotation: constants.ExpiryPolicyUnlock,
+		})
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			return harness.ScaledDown(ctx, env.Client, ns)
+		})).To(Succeed())
+
+		By("expiring the locks")
+		Expect(fleet.ForEach(ctx, 20, func(ctx context.Context, ns string) error {
+			return harness.Expire(ctx, env.Client, ns)
+		})).To(Succeed())
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			if status, err := harness.Status(ctx, env.Client, ns); status != constants.ActiveStatus || err != nil {
+				return false, err
+			}
+			return harness.Restored(ctx, env.Client, ns, 2)
+		})).To(Succeed())
+	})
+
+	It("should keep expired namespaces locked by default", func() {
+		fleet := seed(ctx, "expire-keep", map[string]string{
+			constants.StatusLabel: constants.LockedStatus,
+		}, nil)
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			return harness.ScaledDown(ctx, env.Client, ns)
+		})).To(Succeed())
+
+		By("expiring the locks")
+		Expect(fleet.ForEach(ctx, 20, func(ctx context.Context, ns string) error {
+			return harness.Expire(ctx, env.Client, ns)
+		})).To(Succeed())
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			expiredAt, err := harness.Annotation(ctx, env.Client, ns, constants.ExpiredAtAnnotation)
+			return expiredAt != "", err
+		})).To(Succeed())
+		for _, ns := range fleet.Namespaces {
+			Expect(harness.Status(ctx, env.Client, ns)).To(Equal(constants.LockedStatus))
+			Expect(harness.ScaledDown(ctx, env.Client, ns)).To(BeTrue())
+		}
+	})
+
+	It("should lock the namespaces of a BlockRequest in batches", func() {
+		fleet := seed(ctx, "request", nil, nil)
+
+		_, err := env.StartManager(ctx, func(mgr ctrl.Manager) error {
+			return (&controller.BlockRequestReconciler{
+				Client:           mgr.GetClient(),
+				NonCachingClient: env.Client,
+				Scheme:           mgr.GetScheme(),
+			}).SetupWithManager(mgr)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(env.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "block-requests"}})).To(Succeed())
+		request := &apiv1.BlockRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "lock-fleet", Namespace: "block-requests"},
+			Spec: apiv1.BlockRequestSpec{
+				NamespaceNames: fleet.Namespaces,
+				Action:         constants.LockedStatus,
+				LockProfile:    constants.LockProfileScaleOnly,
+			},
+		}
+		Expect(env.Client.Create(ctx, request)).To(Succeed())
+
+		Expect(fleet.WaitAll(ctx, pollInterval, timeout, func(ctx context.Context, ns string) (bool, error) {
+			return harness.ScaledDown(ctx, env.Client, ns)
+		})).To(Succeed())
+		for _, ns := range fleet.Namespaces {
+			Expect(harness.HasQuota(ctx, env.Client, ns)).To(BeFalse())
+		}
+	})
+})
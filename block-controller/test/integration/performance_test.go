@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/internal/constants"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/controller"
+	"github.com/bearslyricattack/CompliK/block-controller/internal/utils"
+	"github.com/bearslyricattack/CompliK/block-controller/test/harness"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Memory-efficient controller", func() {
+	It("should lock and restore the fleet within the heap budget", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		fleet := seed(ctx, "perf", nil, nil)
+
+		// Like cmd/main.go, write through the cached manager client and page the workload
+		// lists through the non-caching client, since the cache does not support Continue
+		var processor *controller.StreamProcessor
+		_, err := env.StartManager(ctx, func(mgr ctrl.Manager) error {
+			processor = controller.NewStreamProcessorWithReader(mgr.GetClient(), env.Client)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		components := utils.LockProfileComponents(constants.LockProfileAll)
+
+		run := func(action string) (time.Duration, int64) {
+			before := harness.HeapInUse()
+			start := time.Now()
+			Expect(fleet.ForEach(ctx, 10, func(ctx context.Context, ns string) error {
+				return processor.ProcessNamespaceWorkloads(ctx, ns, action, components)
+			})).To(Succeed())
+			elapsed := time.Since(start)
+			Expect(elapsed).To(BeNumerically("<", timeout))
+			return elapsed, int64(harness.HeapInUse()) - int64(before)
+		}
+
+		elapsed, growth := run(constants.LockedStatus)
+		AddReportEntry("lock", fmt.Sprintf("%d namespaces, %d workloads each: %s, heap growth %d KiB",
+			len(fleet.Namespaces), fleet.Workloads(), elapsed, growth/1024))
+		Expect(growth).To(BeNumerically("<", int64(maxHeapMB)<<20))
+		for _, ns := range fleet.Namespaces {
+			Expect(harness.ScaledDown(ctx, env.Client, ns)).To(BeTrue())
+		}
+
+		elapsed, growth = run(constants.ActiveStatus)
+		AddReportEntry("unlock", fmt.Sprintf("%d namespaces, %d workloads each: %s, heap growth %d KiB",
+			len(fleet.Namespaces), fleet.Workloads(), elapsed, growth/1024))
+		Expect(growth).To(BeNumerically("<", int64(maxHeapMB)<<20))
+		for _, ns := range fleet.Namespaces {
+			Expect(harness.Restored(ctx, env.Client, ns, 2)).To(BeTrue())
+		}
+	})
+})
@@ -0,0 +1,98 @@
+//go:build integration
+// +build integration
+
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/block-controller/test/harness"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// The size of the seeded fleets and the performance budgets can be tuned to match a
+// production cluster before an upgrade, e.g. BLOCK_IT_NAMESPACES=2000 make test-integration
+var (
+	namespaces   = envInt("BLOCK_IT_NAMESPACES", 200)
+	deployments  = envInt("BLOCK_IT_DEPLOYMENTS", 2)
+	statefulSets = envInt("BLOCK_IT_STATEFULSETS", 1)
+	maxHeapMB    = envInt("BLOCK_IT_MAX_HEAP_MB", 64)
+	timeout      = envDuration("BLOCK_IT_TIMEOUT", 3*time.Minute)
+)
+
+var env *harness.Env
+
+func TestIntegration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "block-controller integration suite")
+}
+
+var _ = BeforeSuite(func() {
+	ctrl.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	By("starting the envtest API server")
+	var err error
+	env, err = harness.Start()
+	if errors.Is(err, harness.ErrAssetsUnavailable) {
+		Skip(err.Error())
+	}
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	if env != nil {
+		Expect(env.Stop()).To(Succeed())
+	}
+})
+
+// seed creates a fleet of the configured size named after prefix
+func seed(ctx context.Context, prefix string, labels, annotations map[string]string) *harness.Fleet {
+	By("seeding " + strconv.Itoa(namespaces) + " namespaces")
+	fleet, err := harness.Seed(ctx, env.Client, harness.SeedOptions{
+		Prefix:       prefix,
+		Namespaces:   namespaces,
+		Deployments:  deployments,
+		StatefulSets: statefulSets,
+		Replicas:     2,
+		Labels:       labels,
+		Annotations:  annotations,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return fleet
+}
+
+func envInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/analyze/keywords"
+	"github.com/bearslyricattack/CompliK/analyze/usage"
+	"github.com/spf13/cobra"
+)
+
+func newAnalyzeCommand() *cobra.Command {
+	dsn := keywords.DefaultDSN
+	opts := keywords.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Chart keyword frequency and co-occurrence of stored detector records",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analyzer, err := keywords.NewKeywordAnalyzer(dsn)
+			if err != nil {
+				return fmt.Errorf("failed to create analyzer: %w", err)
+			}
+			defer analyzer.Close()
+			return analyzer.Run(opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&dsn, "dsn", dsn, "MySQL data source name")
+	flags.IntVar(&opts.TopN, "top", opts.TopN, "number of keywords in the histogram")
+	flags.StringVar(&opts.HistogramPath, "histogram", opts.HistogramPath, "histogram output path")
+	flags.IntVar(&opts.CooccurTopN, "cooccur-top", opts.CooccurTopN, "number of keywords in the co-occurrence matrix")
+	flags.Float64Var(&opts.Threshold, "threshold", opts.Threshold, "minimum average Jaccard similarity to merge clusters")
+	flags.StringVar(&opts.HeatmapPath, "heatmap", opts.HeatmapPath, "co-occurrence heatmap output path, empty to skip")
+	flags.StringVar(&opts.ClustersPath, "clusters", opts.ClustersPath, "cluster assignments output path, empty to skip")
+	cmd.AddCommand(newAnalyzeUsageCommand())
+	return cmd
+}
+
+func newAnalyzeUsageCommand() *cobra.Command {
+	dsn := keywords.DefaultDSN
+	opts := usage.DefaultOptions()
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Report the model token usage and cost of the reviews per tenant",
+		Long: `usage adds up the model tokens and their cost stored by the Postgres handler
+per namespace, region or day. The cost is priced with the modelPricing of the
+handler when the reviews were stored. --from and --to are UTC dates, --to is
+exclusive; the default is the last 30 days.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if from != "" {
+				if opts.From, err = time.Parse(time.DateOnly, from); err != nil {
+					return fmt.Errorf("invalid --from: %w", err)
+				}
+			}
+			if to != "" {
+				if opts.To, err = time.Parse(time.DateOnly, to); err != nil {
+					return fmt.Errorf("invalid --to: %w", err)
+				}
+			}
+			reporter, err := usage.NewReporter(dsn)
+			if err != nil {
+				return fmt.Errorf("failed to create usage reporter: %w", err)
+			}
+			defer reporter.Close()
+			return reporter.Run(cmd.OutOrStdout(), opts)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&dsn, "dsn", dsn, "MySQL data source name")
+	flags.StringVar(&from, "from", "", "first day of the report")
+	flags.StringVar(&to, "to", "", "day after the report")
+	flags.StringVar(&opts.GroupBy, "group-by", opts.GroupBy, "grouping: namespace, region or day")
+	flags.StringVar(&opts.CSVPath, "csv", "", "CSV output path, empty to skip")
+	return cmd
+}
@@ -0,0 +1,445 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CLI Suite")
+}
+
+// execute runs complik with args and returns its standard output
+func execute(args ...string) (string, error) {
+	var out bytes.Buffer
+	root := NewRootCommand()
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+// writeConfig writes a CompliK configuration with a single handler plugin
+func writeConfig(name string, settings any) string {
+	data, err := json.Marshal(settings)
+	Expect(err).NotTo(HaveOccurred())
+	content := fmt.Sprintf("plugins:\n  - name: %q\n    type: Handle\n    enabled: true\n    settings: %q\n", name, string(data))
+	path := filepath.Join(GinkgoT().TempDir(), "config.yml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	return path
+}
+
+func regexpGroup(pattern, s string) string {
+	match := regexp.MustCompile(pattern).FindStringSubmatch(s)
+	Expect(match).To(HaveLen(2))
+	return match[1]
+}
+
+var _ = Describe("records", func() {
+	var (
+		server  *httptest.Server
+		request *http.Request
+	)
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("COMPLIK_LABEL_API", "")
+		GinkgoT().Setenv("COMPLIK_LABEL_TOKEN", "")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			switch r.URL.Path {
+			case "/api/v1/records":
+				fmt.Fprint(w, `[{"id":7,"detector_name":"Custom","namespace":"ns-a","host":"a.example.com","is_illegal":true,
+					"label":{"record_id":7,"verdict":"false_positive"}}]`)
+			case "/api/v1/records/7/label":
+				fmt.Fprint(w, `{"record_id":7,"verdict":"true_positive","reviewer":"alice"}`)
+			case "/api/v1/reports/ns-a":
+				w.Header().Set("Content-Type", "application/pdf")
+				fmt.Fprint(w, "%PDF-1.3 report")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error":"record not found"}`)
+			}
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should list records with the table layout", func() {
+		out, err := execute("records", "list", "--server", server.URL, "--token", "secret", "--unlabeled")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("ID  DETECTOR"))
+		Expect(out).To(MatchRegexp(`7\s+Custom\s+ns-a\s+a\.example\.com\s+true\s+false_positive`))
+		Expect(request.URL.Query().Get("unlabeled")).To(Equal("true"))
+		Expect(request.Header.Get("Authorization")).To(Equal("Bearer secret"))
+	})
+
+	It("should pass the filters of search and print JSON", func() {
+		out, err := execute("records", "search", "casino", "--server", server.URL, "--namespace", "ns-a",
+			"--host", "a.example.com", "--from", "2025-06-01", "--illegal=false", "--json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring(`"detector_name": "Custom"`))
+		query := request.URL.Query()
+		Expect(query.Get("keyword")).To(Equal("casino"))
+		Expect(query.Get("namespace")).To(Equal("ns-a"))
+		Expect(query.Get("host")).To(Equal("a.example.com"))
+		Expect(query.Get("from")).To(Equal("2025-06-01"))
+		Expect(query.Get("illegal")).To(Equal("false"))
+
+		_, err = execute("records", "list", "--server", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.URL.Query().Has("illegal")).To(BeFalse())
+	})
+
+	It("should expand short verdicts when labeling", func() {
+		out, err := execute("records", "label", "7", "tp", "--server", server.URL, "--reviewer", "alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("record 7 labeled true_positive by alice"))
+	})
+
+	It("should download compliance reports", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "ns-a.pdf")
+		out, err := execute("records", "report", "ns-a", "--server", server.URL, "--from", "2025-06-01", "-o", path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("report of ns-a written to " + path + "\n"))
+		Expect(request.URL.Query().Get("format")).To(Equal("pdf"))
+		Expect(request.URL.Query().Get("from")).To(Equal("2025-06-01"))
+		Expect(os.ReadFile(path)).To(BeEquivalentTo("%PDF-1.3 report"))
+
+		_, err = execute("records", "report", "ns-a", "--server", server.URL, "--format", "docx")
+		Expect(err).To(MatchError(ContainSubstring("unknown report format")))
+	})
+
+	It("should surface API errors", func() {
+		_, err := execute("records", "show", "8", "--server", server.URL)
+		Expect(err).To(MatchError("record not found (HTTP 404)"))
+	})
+
+	It("should take the API address and token from the Postgres plugin settings", func() {
+		GinkgoT().Setenv("LABEL_TOKEN", "from-env")
+		path := writeConfig("Postgres", map[string]string{
+			"labelApiAddr":  ":9000",
+			"labelApiToken": "${LABEL_TOKEN}",
+		})
+		opts := &Options{ConfigPath: path}
+		server, token, err := opts.labelAPI("", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server).To(Equal("http://localhost:9000"))
+		Expect(token).To(Equal("from-env"))
+
+		server, token, err = opts.labelAPI("http://other:8091/", "flag")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server).To(Equal("http://other:8091"))
+		Expect(token).To(Equal("flag"))
+	})
+})
+
+var _ = Describe("whitelist", func() {
+	var configPath string
+
+	BeforeEach(func() {
+		configPath = writeConfig("Lark", map[string]any{
+			"webhook":           "https://open.feishu.cn/open-apis/bot/v2/hook/test",
+			"region":            "cn-test",
+			"enabled_whitelist": true,
+			"driver":            "sqlite",
+			"sqlitePath":        filepath.Join(GinkgoT().TempDir(), "complik.db"),
+		})
+	})
+
+	It("should add, list and remove entries in the configured region", func() {
+		out, err := execute("whitelist", "add", "--config", configPath, "--namespace", "ns-trusted", "--remark", "internal tooling")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchRegexp(`whitelist entry (\d+) added for namespace ns-trusted in region cn-test`))
+		id := regexpGroup(`entry (\d+) added`, out)
+
+		_, err = execute("whitelist", "add", "--config", configPath, "--host", "shop.example.com")
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err = execute("whitelist", "list", "--config", configPath, "--type", "namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchRegexp(id + `\s+namespace\s+cn-test\s+ns-trusted\s+-\s+ns-trusted`))
+		Expect(out).NotTo(ContainSubstring("shop.example.com"))
+
+		out, err = execute("whitelist", "remove", id, "--config", configPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("whitelist entry " + id + " removed"))
+
+		out, err = execute("whitelist", "list", "--config", configPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).NotTo(ContainSubstring("ns-trusted"))
+		Expect(out).To(ContainSubstring("shop.example.com"))
+	})
+
+	It("should reject ambiguous entries", func() {
+		_, err := execute("whitelist", "add", "--config", configPath, "--namespace", "a", "--host", "b")
+		Expect(err).To(MatchError(ContainSubstring("mutually exclusive")))
+		_, err = execute("whitelist", "add", "--config", configPath)
+		Expect(err).To(MatchError(ContainSubstring("either --namespace or --host")))
+	})
+
+	It("should fail when the Lark plugin is not configured", func() {
+		path := writeConfig("Postgres", map[string]string{})
+		_, err := execute("whitelist", "list", "--config", path)
+		Expect(err).To(MatchError(ContainSubstring("plugin Lark is not configured")))
+	})
+})
+
+var _ = Describe("plugins", func() {
+	var request *http.Request
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("COMPLIK_PLUGIN_API", "")
+		GinkgoT().Setenv("COMPLIK_PLUGIN_API_TOKEN", "")
+	})
+
+	serve := func() string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			switch r.URL.Path {
+			case "/api/v1/plugins":
+				fmt.Fprint(w, `[{"name":"Custom","type":"Compliance.Detector","enabled":true,
+					"status":{"state":"running","since":"2025-06-01T10:00:00Z"}}]`)
+			case "/api/v1/plugins/Custom/log-level":
+				body, _ := io.ReadAll(r.Body)
+				Expect(string(body)).To(MatchJSON(`{"level":"debug"}`))
+				fmt.Fprint(w, `{"name":"Custom","type":"Compliance.Detector","enabled":true,"logLevel":"debug",
+					"status":{"state":"running","since":"2025-06-01T10:00:00Z"}}`)
+			case "/api/v1/plugins/Lark/disable":
+				fmt.Fprint(w, `{"name":"Lark","type":"Handle.Lark","enabled":false,
+					"status":{"state":"stopped","since":"2025-06-01T10:00:00Z"}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error":"plugin not found: Nope"}`)
+			}
+		}))
+		DeferCleanup(server.Close)
+		return server.URL
+	}
+
+	It("should list plugins and their state", func() {
+		out, err := execute("plugins", "list", "--server", serve())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchRegexp(`Custom\s+Compliance\.Detector\s+true\s+running\s+2025-06-01 10:00:00\s+-`))
+	})
+
+	It("should take the API address and token from the configuration", func() {
+		url := serve()
+		GinkgoT().Setenv("PLUGIN_API_TOKEN", "from-env")
+		path := filepath.Join(GinkgoT().TempDir(), "config.yml")
+		content := fmt.Sprintf("pluginApi:\n  addr: %q\n  token: \"${PLUGIN_API_TOKEN}\"\n", url)
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+		out, err := execute("plugins", "disable", "Lark", "--config", path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.Method).To(Equal(http.MethodPost))
+		Expect(request.Header.Get("Authorization")).To(Equal("Bearer from-env"))
+		Expect(out).To(MatchRegexp(`Lark\s+Handle\.Lark\s+false\s+stopped`))
+	})
+
+	It("should change the log level of a plugin", func() {
+		out, err := execute("plugins", "log-level", "Custom", "debug", "--server", serve())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.Method).To(Equal(http.MethodPut))
+		Expect(out).To(Equal("Custom logs at debug level\n"))
+	})
+
+	It("should surface API errors", func() {
+		_, err := execute("plugins", "restart", "Nope", "--server", serve())
+		Expect(err).To(MatchError("plugin not found: Nope (HTTP 404)"))
+	})
+
+	It("should require an API address", func() {
+		_, err := execute("plugins", "list")
+		Expect(err).To(MatchError(ContainSubstring("plugin API address is not configured")))
+	})
+})
+
+var _ = Describe("rules", func() {
+	var body []byte
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("COMPLIK_SANDBOX_API", "")
+		GinkgoT().Setenv("COMPLIK_SANDBOX_TOKEN", "")
+	})
+
+	serve := func() string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer sandbox"))
+			switch r.URL.Path {
+			case "/api/v1/rules":
+				fmt.Fprint(w, `[{"type":"malware","keywords":"virus,trojan","description":""}]`)
+			case "/api/v1/rules/test":
+				body, _ = io.ReadAll(r.Body)
+				fmt.Fprint(w, `{"source":"url","rules":[{"type":"gambling","keywords":"casino"}],"duration_ms":12,
+					"result":{"url":"https://a.example.com","is_illegal":true,"keywords":["casino"],"explanation":"matched gambling"}}`)
+			}
+		}))
+		DeferCleanup(server.Close)
+		return server.URL
+	}
+
+	It("should test inline and file rules against a URL", func() {
+		rules := filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+		Expect(os.WriteFile(rules, []byte("- type: fraud\n  keywords: usdt\n"), 0o600)).To(Succeed())
+		out, err := execute("rules", "test", "--server", serve(), "--token", "sandbox",
+			"--url", "https://a.example.com", "--rules", rules, "--rule", "gambling:casino,baccarat:Gambling sites")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(MatchJSON(`{"url":"https://a.example.com","rules":[
+			{"type":"fraud","keywords":"usdt","description":""},
+			{"type":"gambling","keywords":"casino,baccarat","description":"Gambling sites"}]}`))
+		Expect(out).To(MatchRegexp(`Decision:\s+illegal`))
+		Expect(out).To(MatchRegexp(`Keywords:\s+casino`))
+	})
+
+	It("should send stored records", func() {
+		record := filepath.Join(GinkgoT().TempDir(), "sample.json")
+		Expect(os.WriteFile(record, []byte(`{"host":"a.example.com","html":"casino"}`), 0o600)).To(Succeed())
+		path := writeConfig("Custom", map[string]string{"sandboxApiAddr": serve(), "sandboxApiToken": "sandbox"})
+		_, err := execute("rules", "test", "--config", path, "--record", record, "--json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring(`"record":{`))
+		Expect(string(body)).NotTo(ContainSubstring(`"rules"`))
+	})
+
+	It("should list the loaded rules", func() {
+		out, err := execute("rules", "list", "--server", serve(), "--token", "sandbox")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchRegexp(`malware\s+virus,trojan\s+-`))
+	})
+
+	It("should reject incomplete tests", func() {
+		_, err := execute("rules", "test", "--server", "http://localhost:1")
+		Expect(err).To(MatchError("exactly one of --url and --record is required"))
+		_, err = execute("rules", "test", "--server", "http://localhost:1", "--url", "https://a.example.com", "--rule", "casino")
+		Expect(err).To(MatchError(ContainSubstring("use type:keywords[:description]")))
+	})
+})
+
+var _ = Describe("secrets", func() {
+	key := func(fill byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))
+	}
+	encrypt := func() string {
+		out, err := execute("secrets", "encrypt", "s3cret")
+		Expect(err).NotTo(HaveOccurred())
+		return strings.TrimSpace(out)
+	}
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k1="+key(1))
+	})
+
+	It("should re-encrypt configuration files with the primary key", func() {
+		value := encrypt()
+		Expect(value).To(HavePrefix("ENC(v2:k1:"))
+		dbPath := filepath.Join(GinkgoT().TempDir(), "complik.db")
+		configPath := writeConfig("Postgres", map[string]any{
+			"driver": "sqlite", "sqlitePath": dbPath, "password": value,
+		})
+
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k2="+key(2)+",k1="+key(1))
+		out, err := execute("secrets", "reencrypt", "--config", configPath, "--dry-run")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("1 values to re-encrypt"))
+		Expect(os.ReadFile(configPath)).To(ContainSubstring("ENC(v2:k1:"))
+
+		out, err = execute("secrets", "reencrypt", "--config", configPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("primary key: k2"))
+		Expect(out).To(ContainSubstring("1 values re-encrypted"))
+		data, err := os.ReadFile(configPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("ENC(v2:k2:"))
+		Expect(string(data)).NotTo(ContainSubstring("ENC(v2:k1:"))
+
+		out, err = execute("secrets", "reencrypt", "--config", configPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("0 values re-encrypted"))
+	})
+
+	It("should re-encrypt database columns", func() {
+		value := strings.TrimSuffix(strings.TrimPrefix(encrypt(), "ENC("), ")")
+		dbPath := filepath.Join(GinkgoT().TempDir(), "complik.db")
+		configPath := writeConfig("Lark", map[string]any{"driver": "sqlite", "sqlitePath": dbPath})
+		db, err := database.Open(database.Options{Driver: database.DriverSQLite, SQLitePath: dbPath})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Exec("CREATE TABLE credentials (id INTEGER PRIMARY KEY, secret TEXT)").Error).To(Succeed())
+		Expect(db.Exec("INSERT INTO credentials (id, secret) VALUES (1, ?), (2, 'plain')", "ENC("+value+")").Error).
+			To(Succeed())
+
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k2="+key(2)+",k1="+key(1))
+		out, err := execute("secrets", "reencrypt", "--config", configPath, "/dev/null",
+			"--plugin", "Lark", "--column", "credentials.secret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("credentials.secret: 1 values re-encrypted"))
+
+		var secrets []string
+		Expect(db.Raw("SELECT secret FROM credentials ORDER BY id").Scan(&secrets).Error).To(Succeed())
+		Expect(secrets[0]).To(HavePrefix("ENC(v2:k2:"))
+		Expect(secrets[1]).To(Equal("plain"))
+		Expect(config.GetSecureValue(secrets[0])).To(Equal("s3cret"))
+	})
+
+	It("should reject invalid arguments", func() {
+		_, err := execute("secrets", "reencrypt", "--column", "credentials.secret")
+		Expect(err).To(MatchError("--plugin and --column must be used together"))
+		_, err = execute("secrets", "reencrypt")
+		Expect(err).To(MatchError(ContainSubstring("no file or column")))
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k1")
+		_, err = execute("secrets", "encrypt", "s3cret")
+		Expect(err).To(MatchError(ContainSubstring("expected id=key")))
+	})
+})
+
+var _ = Describe("loadtest", func() {
+	It("should require an enabled detector", func() {
+		path := writeConfig("Lark", map[string]any{"webhook": "http://127.0.0.1:1"})
+		_, err := execute("loadtest", "--config", path, "--duration", "100ms")
+		Expect(err).To(MatchError(ContainSubstring("no detector plugin is enabled")))
+	})
+})
+
+var _ = Describe("root", func() {
+	It("should reject unknown log levels", func() {
+		_, err := execute("--log-level", "loud", "whitelist", "list")
+		Expect(err).To(MatchError(ContainSubstring(`unknown log level "loud"`)))
+	})
+
+	It("should reject unknown plugin log levels in the configuration", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yml")
+		content := "logging:\n  plugins:\n    Deployment: loud\n"
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		_, err := execute("whitelist", "list", "--config", path)
+		Expect(err).To(MatchError(ContainSubstring("invalid logging level of plugin Deployment")))
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/bearslyricattack/CompliK/complik/internal/evaluation"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+type evalOptions struct {
+	evidenceDir string
+	labelsPath  string
+	outputPath  string
+}
+
+func newEvalCommand(opts *Options) *cobra.Command {
+	eval := &evalOptions{}
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Compare two detector configurations on stored collector records",
+		Long: `eval replays stored collector records through the baseline and candidate
+detectors of the evaluation configuration given with --config and reports the
+precision and recall deltas against the ground truth labels.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.ConfigPath == "" {
+				return errors.New("--config is required")
+			}
+			return eval.run(opts.ConfigPath, cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().StringVar(&eval.evidenceDir, "evidence", "", "directory with stored CollectorInfo records")
+	cmd.Flags().StringVar(&eval.labelsPath, "labels", "", "CSV ground truth table with id and is_illegal columns")
+	cmd.Flags().StringVar(&eval.outputPath, "output", "", "optional path of the JSON comparison report")
+	_ = cmd.MarkFlagRequired("evidence")
+	_ = cmd.MarkFlagRequired("labels")
+	return cmd
+}
+
+func (o *evalOptions) run(configPath string, out io.Writer) error {
+	log := logger.GetLogger()
+	cfg, err := evaluation.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	baseline, err := cfg.Baseline.Build(log.WithField("variant", cfg.Baseline.Name))
+	if err != nil {
+		return err
+	}
+	candidate, err := cfg.Candidate.Build(log.WithField("variant", cfg.Candidate.Name))
+	if err != nil {
+		return err
+	}
+	samples, err := evaluation.LoadEvidence(o.evidenceDir)
+	if err != nil {
+		return err
+	}
+	truth, err := evaluation.LoadGroundTruth(o.labelsPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	log.Info("Starting evaluation", logger.Fields{
+		"samples":     len(samples),
+		"labels":      len(truth),
+		"baseline":    baseline.Name,
+		"candidate":   candidate.Name,
+		"concurrency": cfg.Concurrency,
+	})
+	comparison := evaluation.NewEvaluator(log, cfg.Concurrency).
+		Compare(ctx, samples, truth, baseline, candidate)
+
+	printComparison(out, comparison)
+	if o.outputPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(comparison, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal comparison: %w", err)
+	}
+	if err := os.WriteFile(o.outputPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+	return nil
+}
+
+func printComparison(out io.Writer, c *evaluation.Comparison) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "samples: %d (unlabeled skipped: %d)\n\n", c.Samples, c.Unlabeled)
+	fmt.Fprintln(w, "variant\tTP\tFP\tTN\tFN\terrors\tprecision\trecall\tF1")
+	for _, m := range []evaluation.Metrics{c.Baseline, c.Candidate} {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%.3f\t%.3f\t%.3f\n",
+			m.Variant, m.TruePositives, m.FalsePositives, m.TrueNegatives,
+			m.FalseNegatives, m.Errors, m.Precision, m.Recall, m.F1)
+	}
+	fmt.Fprintf(w, "delta\t\t\t\t\t\t%+.3f\t%+.3f\t%+.3f\n", c.PrecisionDelta, c.RecallDelta, c.F1Delta)
+	fmt.Fprintf(w, "\ndisagreements: %d\n", len(c.Disagreements))
+	_ = w.Flush()
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/internal/app"
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/loadtest"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/spf13/cobra"
+)
+
+type loadTestOptions struct {
+	load       loadtest.Config
+	reportPath string
+}
+
+func newLoadTestCommand(opts *Options) *cobra.Command {
+	lt := &loadTestOptions{load: loadtest.Config{}.WithDefaults()}
+	cmd := &cobra.Command{
+		Use:   "loadtest",
+		Short: "Measure the pipeline throughput and memory under synthesized load",
+		Long: `loadtest feeds the detectors of the configuration given with --config
+with synthesized discovery events and fake pages, and reports the throughput,
+the detection latency and the memory of the process. Discovery and collector
+plugins are not started and detectors run in dry-run mode with the stub
+reviewer, so no cluster, site or model API is touched and handlers are only
+simulated. Without --config the Safety detector is load tested alone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lt.run(opts, cmd.OutOrStdout())
+		},
+	}
+	flags := cmd.Flags()
+	flags.IntVar(&lt.load.Rate, "rate", lt.load.Rate, "discovery events per second")
+	flags.DurationVar(&lt.load.Duration, "duration", lt.load.Duration, "how long events are generated")
+	flags.DurationVar(&lt.load.Drain, "drain", lt.load.Drain, "how long in-flight targets may still be detected afterwards")
+	flags.IntVar(&lt.load.Namespaces, "namespaces", lt.load.Namespaces, "number of namespaces the targets are spread over")
+	flags.IntVar(&lt.load.HTMLBytes, "html-bytes", lt.load.HTMLBytes, "HTML size of every page, negative for empty pages")
+	flags.IntVar(&lt.load.ScreenshotBytes, "screenshot-bytes", lt.load.ScreenshotBytes, "screenshot size of every page, negative for none")
+	flags.Float64Var(&lt.load.FlaggedRatio, "flagged-ratio", lt.load.FlaggedRatio, "share of pages the stub reviewer flags")
+	flags.DurationVar(&lt.load.SampleInterval, "sample-interval", lt.load.SampleInterval, "how often progress and memory are sampled")
+	flags.StringVar(&lt.reportPath, "report", "", "optional path of the JSON load test report")
+	return cmd
+}
+
+func (o *loadTestOptions) run(opts *Options, out io.Writer) error {
+	cfg := defaultLoadTestConfig()
+	if opts.ConfigPath != "" {
+		var err error
+		if cfg, err = opts.loadConfig(); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	report, err := app.RunLoadTest(ctx, cfg, o.load)
+	if err != nil {
+		return err
+	}
+
+	printLoadTestReport(out, report)
+	if o.reportPath == "" {
+		return nil
+	}
+	return report.WriteReport(o.reportPath)
+}
+
+// defaultLoadTestConfig runs the Safety detector alone. Its API key is never
+// used, since load tests review with the stub reviewer.
+func defaultLoadTestConfig() *config.Config {
+	return &config.Config{Plugins: []config.PluginConfig{{
+		Name:     constants.ComplianceDetectorSafety,
+		Type:     constants.ComplianceDetectorPluginType,
+		Enabled:  true,
+		Settings: `{"apiKey": "loadtest"}`,
+	}}}
+}
+
+func printLoadTestReport(out io.Writer, r loadtest.RunReport) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "load: %d events/s for %s over %d namespaces\n\n", r.Config.Rate, r.Config.Duration, r.Config.Namespaces)
+	fmt.Fprintf(w, "detectors:\t%s\n", strings.Join(r.Detectors, ", "))
+	fmt.Fprintf(w, "published:\t%d\n", r.Published)
+	fmt.Fprintf(w, "collected:\t%d\n", r.Collected)
+	fmt.Fprintf(w, "detected:\t%d\t(flagged %d, lost %d)\n", r.Detected, r.Flagged, r.Lost)
+	fmt.Fprintf(w, "throughput:\t%.1f/s\n", r.Throughput)
+	fmt.Fprintf(w, "latency:\tp50 %s\tp95 %s\tp99 %s\tmax %s\n",
+		r.LatencyP50.Round(time.Millisecond), r.LatencyP95.Round(time.Millisecond),
+		r.LatencyP99.Round(time.Millisecond), r.LatencyMax.Round(time.Millisecond))
+	fmt.Fprintf(w, "peak heap:\t%.1f MB\n", r.PeakHeapMB)
+	fmt.Fprintf(w, "peak goroutines:\t%d\n", r.PeakGoroutines)
+	fmt.Fprintf(w, "peak in flight:\t%d\n", r.PeakInFlight)
+	_ = w.Flush()
+}
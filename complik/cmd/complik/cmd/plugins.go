@@ -0,0 +1,160 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/spf13/cobra"
+)
+
+func newPluginsCommand(opts *Options) *cobra.Command {
+	var server, token string
+	// Restarts wait for the plugin to stop and start again
+	c := &apiClient{http: &http.Client{Timeout: plugin.PluginStopTimeout + plugin.StartupGracePeriod + 10*time.Second}}
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "List, enable, disable, restart and tune the plugins of a running CompliK",
+		Long: `plugins talks to the plugin management API of a running CompliK. Changes
+take effect without restarting CompliK and are kept across restarts when
+pluginApi.statePath is configured.
+
+The API address and token are taken from the flags, then COMPLIK_PLUGIN_API and
+COMPLIK_PLUGIN_API_TOKEN, then pluginApi.addr and pluginApi.token of the
+CompliK configuration given with --config.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			var err error
+			c.server, c.token, err = opts.pluginAPI(server, token)
+			c.out = cmd.OutOrStdout()
+			return err
+		},
+	}
+	cmd.PersistentFlags().StringVar(&server, "server", "", "plugin API address")
+	cmd.PersistentFlags().StringVar(&token, "token", "", "plugin API token")
+	cmd.AddCommand(
+		newPluginsListCommand(c),
+		newPluginsChangeCommand(c, "enable", "Enable and start a plugin"),
+		newPluginsChangeCommand(c, "disable", "Stop a plugin and keep it disabled"),
+		newPluginsChangeCommand(c, "restart", "Restart an enabled plugin"),
+		newPluginsLogLevelCommand(c),
+	)
+	return cmd
+}
+
+// pluginAPI resolves the plugin management API address and token
+func (o *Options) pluginAPI(server, token string) (string, string, error) {
+	server = firstNonEmpty(server, os.Getenv("COMPLIK_PLUGIN_API"))
+	token = firstNonEmpty(token, os.Getenv("COMPLIK_PLUGIN_API_TOKEN"))
+	if (server == "" || token == "") && o.ConfigPath != "" {
+		cfg, err := o.loadConfig()
+		if err != nil {
+			return "", "", err
+		}
+		server = firstNonEmpty(server, apiURL(cfg.PluginAPI.Addr))
+		if token == "" && cfg.PluginAPI.Token != "" {
+			if token, err = config.GetSecureValue(cfg.PluginAPI.Token); err != nil {
+				return "", "", fmt.Errorf("failed to resolve plugin API token: %w", err)
+			}
+		}
+	}
+	if server == "" {
+		return "", "", errors.New("plugin API address is not configured, use --server or pluginApi.addr")
+	}
+	return strings.TrimRight(server, "/"), token, nil
+}
+
+func newPluginsListCommand(c *apiClient) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the loaded plugins and their state",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var plugins []plugin.PluginInfo
+			if err := c.do(http.MethodGet, "/api/v1/plugins", nil, &plugins); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTYPE\tENABLED\tSTATE\tSINCE\tLOG LEVEL\tERROR")
+			for _, p := range plugins {
+				printPlugin(w, p)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newPluginsChangeCommand(c *apiClient, action, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   action + " <name>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var info plugin.PluginInfo
+			path := "/api/v1/plugins/" + url.PathEscape(args[0]) + "/" + action
+			if err := c.do(http.MethodPost, path, nil, &info); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTYPE\tENABLED\tSTATE\tSINCE\tLOG LEVEL\tERROR")
+			printPlugin(w, info)
+			return w.Flush()
+		},
+	}
+}
+
+func newPluginsLogLevelCommand(c *apiClient) *cobra.Command {
+	return &cobra.Command{
+		Use:   "log-level <name> <level|default>",
+		Short: "Change the log level of a plugin",
+		Long: `log-level sets the log level (debug, info, warn, error) of a single plugin
+without restarting it. "default" makes the plugin log at the global level again.
+The change lasts until CompliK restarts, use logging.plugins to keep it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			level := args[1]
+			if level == "default" {
+				level = ""
+			}
+			var info plugin.PluginInfo
+			path := "/api/v1/plugins/" + url.PathEscape(args[0]) + "/log-level"
+			if err := c.do(http.MethodPut, path, map[string]string{"level": level}, &info); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.out, "%s logs at %s level\n", info.Name, firstNonEmpty(info.LogLevel, "the global"))
+			return nil
+		},
+	}
+}
+
+func printPlugin(w *tabwriter.Writer, p plugin.PluginInfo) {
+	state, since := orDash(p.Status.State), "-"
+	if !p.Status.Since.IsZero() {
+		since = p.Status.Since.Format("2006-01-02 15:04:05")
+	}
+	fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\t%s\n",
+		p.Name, p.Type, p.Enabled, state, since, orDash(p.LogLevel), orDash(p.Status.Error))
+}
@@ -0,0 +1,471 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/database/postages"
+	"github.com/spf13/cobra"
+)
+
+const defaultLabelAPI = "http://localhost:8091"
+
+// purgeTimeout bounds a purge, which archives the records in batches before
+// it answers
+const purgeTimeout = 30 * time.Minute
+
+var verdicts = map[string]string{
+	"tp": postages.VerdictTruePositive,
+	"fp": postages.VerdictFalsePositive,
+	"tn": postages.VerdictTrueNegative,
+	"fn": postages.VerdictFalseNegative,
+}
+
+// apiClient talks to the JSON APIs of CompliK, such as the labeling API of
+// the Postgres handler plugin
+type apiClient struct {
+	server string
+	token  string
+	http   *http.Client
+	out    io.Writer
+}
+
+func newRecordsCommand(opts *Options) *cobra.Command {
+	var server, token string
+	c := &apiClient{http: &http.Client{Timeout: 30 * time.Second}}
+	cmd := &cobra.Command{
+		Use:   "records",
+		Short: "Query and label stored detector records and generate compliance reports",
+		Long: `records queries and labels the detector records stored by the Postgres
+handler plugin through its labeling API, shows the resulting accuracy per
+detector and keyword, generates the compliance reports of namespaces and
+archives and deletes old records.
+
+The API address and token are taken from the flags, then COMPLIK_LABEL_API and
+COMPLIK_LABEL_TOKEN, then labelApiAddr and labelApiToken of the Postgres plugin
+in the CompliK configuration given with --config.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			var err error
+			c.server, c.token, err = opts.labelAPI(server, token)
+			c.out = cmd.OutOrStdout()
+			return err
+		},
+	}
+	cmd.PersistentFlags().StringVar(&server, "server", "", "labeling API address (default "+defaultLabelAPI+")")
+	cmd.PersistentFlags().StringVar(&token, "token", "", "labeling API token")
+	cmd.AddCommand(c.listCommand(), c.searchCommand(), c.getCommand(), c.transcriptsCommand(), c.labelCommand(), c.metricsCommand(), c.reportCommand(), c.purgeCommand())
+	return cmd
+}
+
+// labelAPI resolves the labeling API address and token
+func (o *Options) labelAPI(server, token string) (string, string, error) {
+	server = firstNonEmpty(server, os.Getenv("COMPLIK_LABEL_API"))
+	token = firstNonEmpty(token, os.Getenv("COMPLIK_LABEL_TOKEN"))
+	if (server == "" || token == "") && o.ConfigPath != "" {
+		settings, err := o.pluginSettings(constants.HandleDatabasePostgres)
+		if err != nil {
+			return "", "", err
+		}
+		var cfg postages.DatabaseConfig
+		if err := json.Unmarshal([]byte(settings), &cfg); err != nil {
+			return "", "", fmt.Errorf("failed to parse %s plugin settings: %w", constants.HandleDatabasePostgres, err)
+		}
+		if server == "" {
+			server = apiURL(cfg.LabelAPIAddr)
+		}
+		if token == "" && cfg.LabelAPIToken != "" {
+			if token, err = config.GetSecureValue(cfg.LabelAPIToken); err != nil {
+				return "", "", fmt.Errorf("failed to resolve label API token: %w", err)
+			}
+		}
+	}
+	return strings.TrimRight(firstNonEmpty(server, defaultLabelAPI), "/"), token, nil
+}
+
+// recordFilter holds the flags shared by list and search
+type recordFilter struct {
+	detector, namespace, host string
+	from, to                  string
+	illegal, unlabeled        bool
+	limit                     int
+	asJSON                    bool
+}
+
+func (f *recordFilter) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.detector, "detector", "", "only records of this detector")
+	cmd.Flags().StringVar(&f.namespace, "namespace", "", "only records of this namespace")
+	cmd.Flags().StringVar(&f.host, "host", "", "only records of this host")
+	cmd.Flags().StringVar(&f.from, "from", "", "only records detected at or after this date or RFC 3339 time")
+	cmd.Flags().StringVar(&f.to, "to", "", "only records detected before this date or RFC 3339 time")
+	cmd.Flags().BoolVar(&f.illegal, "illegal", false, "only flagged records, --illegal=false for compliant ones")
+	cmd.Flags().BoolVar(&f.unlabeled, "unlabeled", false, "only records without a label")
+	cmd.Flags().IntVar(&f.limit, "limit", 50, "maximum number of records")
+	cmd.Flags().BoolVar(&f.asJSON, "json", false, "print the records as JSON")
+}
+
+// query encodes the filter; illegal only filters when the flag was given
+func (f *recordFilter) query(cmd *cobra.Command, keyword string) url.Values {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(f.limit))
+	for key, value := range map[string]string{
+		"detector":  f.detector,
+		"namespace": f.namespace,
+		"host":      f.host,
+		"from":      f.from,
+		"to":        f.to,
+		"keyword":   keyword,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if cmd.Flags().Changed("illegal") {
+		query.Set("illegal", strconv.FormatBool(f.illegal))
+	}
+	if f.unlabeled {
+		query.Set("unlabeled", "true")
+	}
+	return query
+}
+
+func (c *apiClient) listCommand() *cobra.Command {
+	var filter recordFilter
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stored detector records, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.listRecords(filter.query(cmd, ""), filter.asJSON)
+		},
+	}
+	filter.register(cmd)
+	return cmd
+}
+
+func (c *apiClient) searchCommand() *cobra.Command {
+	var filter recordFilter
+	cmd := &cobra.Command{
+		Use:   "search <keyword>",
+		Short: "Search stored detector records by keyword or description",
+		Long: `search lists the records whose matched keywords or description contain
+keyword, newest first. The filters of list narrow the search further.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.listRecords(filter.query(cmd, args[0]), filter.asJSON)
+		},
+	}
+	filter.register(cmd)
+	return cmd
+}
+
+// listRecords prints the records matching query as a table or as JSON
+func (c *apiClient) listRecords(query url.Values, asJSON bool) error {
+	if asJSON {
+		data, err := c.send(http.MethodGet, "/api/v1/records?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		return c.printJSON(data)
+	}
+	var records []postages.LabeledRecord
+	if err := c.do(http.MethodGet, "/api/v1/records?"+query.Encode(), nil, &records); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDETECTOR\tNAMESPACE\tHOST\tILLEGAL\tVERDICT")
+	for _, record := range records {
+		verdict := "-"
+		if record.Label != nil {
+			verdict = record.Label.Verdict
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n", record.ID, record.DetectorName,
+			record.Namespace, record.Host, record.IsIllegal, verdict)
+	}
+	return w.Flush()
+}
+
+func (c *apiClient) getCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "get <record-id>",
+		Aliases: []string{"show"},
+		Short:   "Show a record and its label as JSON",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := c.send(http.MethodGet, "/api/v1/records/"+url.PathEscape(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			return c.printJSON(data)
+		},
+	}
+}
+
+func (c *apiClient) transcriptsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "transcripts <record-id>",
+		Short: "Show the model prompts and responses behind a record as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := c.send(http.MethodGet, "/api/v1/records/"+url.PathEscape(args[0])+"/transcripts", nil)
+			if err != nil {
+				return err
+			}
+			return c.printJSON(data)
+		},
+	}
+}
+
+// printJSON writes an API response indented
+func (c *apiClient) printJSON(data []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(c.out)
+	return err
+}
+
+func (c *apiClient) labelCommand() *cobra.Command {
+	var reviewer, comment string
+	cmd := &cobra.Command{
+		Use:   "label <record-id> <verdict>",
+		Short: "Label a record; verdict is one of tp, fp, tn, fn",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, verdict := args[0], args[1]
+			if full, ok := verdicts[strings.ToLower(verdict)]; ok {
+				verdict = full
+			}
+			body := map[string]string{"verdict": verdict, "reviewer": reviewer, "comment": comment}
+			var label postages.DetectorLabel
+			if err := c.do(http.MethodPost, "/api/v1/records/"+url.PathEscape(id)+"/label", body, &label); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.out, "record %d labeled %s by %s\n", label.RecordID, label.Verdict, label.Reviewer)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reviewer, "reviewer", os.Getenv("USER"), "name of the reviewer")
+	cmd.Flags().StringVar(&comment, "comment", "", "reviewer comment")
+	return cmd
+}
+
+func (c *apiClient) metricsCommand() *cobra.Command {
+	var detector string
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Show the accuracy per detector and keyword",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "/api/v1/labels/metrics"
+			if detector != "" {
+				path += "?detector=" + url.QueryEscape(detector)
+			}
+			var report postages.AccuracyReport
+			if err := c.do(http.MethodGet, path, nil, &report); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DETECTOR\tKEYWORD\tLABELED\tTP\tFP\tTN\tFN\tPRECISION\tRECALL\tACCURACY")
+			for _, rows := range [][]postages.Accuracy{report.Detectors, report.Keywords} {
+				for _, a := range rows {
+					keyword := a.Keyword
+					if keyword == "" {
+						keyword = "*"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.3f\t%.3f\t%.3f\n", a.Detector, keyword,
+						a.Labeled, a.TruePositives, a.FalsePositives, a.TrueNegatives, a.FalseNegatives,
+						a.Precision, a.Recall, a.Accuracy)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&detector, "detector", "", "only labels of this detector")
+	return cmd
+}
+
+func (c *apiClient) reportCommand() *cobra.Command {
+	var format, from, to, output string
+	cmd := &cobra.Command{
+		Use:   "report <namespace>",
+		Short: "Generate the compliance report of a namespace as HTML or PDF",
+		Long: `report generates the compliance report of a namespace: the violations found
+in the period with their evidence links and review status, for sharing with the
+tenant. --from and --to take dates (2006-01-02) or RFC 3339 times and default
+to the last 30 days.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+			query := url.Values{}
+			query.Set("format", format)
+			if from != "" {
+				query.Set("from", from)
+			}
+			if to != "" {
+				query.Set("to", to)
+			}
+			data, err := c.send(http.MethodGet, "/api/v1/reports/"+url.PathEscape(args[0])+"?"+query.Encode(), nil)
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				output = fmt.Sprintf("complik-report-%s-%s.%s", args[0], time.Now().Format("20060102"), format)
+			}
+			if output == "-" {
+				_, err = c.out.Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+			fmt.Fprintf(c.out, "report of %s written to %s\n", args[0], output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", report.FormatPDF, "report format (html, pdf)")
+	cmd.Flags().StringVar(&from, "from", "", "start of the reported period")
+	cmd.Flags().StringVar(&to, "to", "", "end of the reported period, exclusive")
+	cmd.Flags().StringVarP(&output, "output", "o", "", `output file, "-" for stdout (default complik-report-<namespace>-<date>.<format>)`)
+	return cmd
+}
+
+func (c *apiClient) purgeCommand() *cobra.Command {
+	var before string
+	var noArchive, dryRun bool
+	cmd := &cobra.Command{
+		Use:   "purge --before <date>",
+		Short: "Archive and delete the records last seen before a date",
+		Long: `purge moves the detector records last seen before --before to the archive
+store of the Postgres plugin, as gzip compressed CSV, and deletes them from the
+database, like the scheduled retention job. Labeled records and records with an
+appeal under review are kept. --before takes a date (2006-01-02) or an RFC 3339
+time.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]any{"before": before, "no_archive": noArchive, "dry_run": dryRun}
+			c.http.Timeout = purgeTimeout
+			var result postages.PurgeResult
+			if err := c.do(http.MethodPost, "/api/v1/records/purge", body, &result); err != nil {
+				return err
+			}
+			cutoff := result.Before.Format(time.RFC3339)
+			if result.DryRun {
+				fmt.Fprintf(c.out, "%d records last seen before %s would be purged\n", result.Purged, cutoff)
+				return nil
+			}
+			fmt.Fprintf(c.out, "%d records last seen before %s purged, %d archived in %d objects\n",
+				result.Purged, cutoff, result.Archived, len(result.Objects))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&before, "before", "", "purge the records last seen before this date or RFC 3339 time")
+	cmd.Flags().BoolVar(&noArchive, "no-archive", false, "delete the records without archiving them")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only count the records that would be purged")
+	_ = cmd.MarkFlagRequired("before")
+	return cmd
+}
+
+func (c *apiClient) do(method, path string, body, out any) error {
+	data, err := c.send(method, path, body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// send performs a request and returns the body of a successful response
+func (c *apiClient) send(method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.server+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s (HTTP %d)", apiErr.Error, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return data, nil
+}
+
+// apiURL turns a listen address such as ":9000" into the URL of the API, empty
+// for an empty address
+func apiURL(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return addr
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd implements the complik subcommands.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/spf13/cobra"
+)
+
+const version = "1.0.0"
+
+// Options are the flags shared by all subcommands
+type Options struct {
+	// ConfigPath is the configuration file of the component the subcommand
+	// drives: CompliK for run, loadtest, whitelist, records and plugins,
+	// ProcScan for scan and the evaluation config for eval
+	ConfigPath string
+	LogLevel   string
+	LogFormat  string
+}
+
+// NewRootCommand creates the complik command with all subcommands attached
+func NewRootCommand() *cobra.Command {
+	opts := &Options{}
+	run := &runOptions{}
+
+	root := &cobra.Command{
+		Use:   "complik",
+		Short: "CompliK compliance detection platform",
+		Long: `complik runs the CompliK detection pipeline and the tools around it:
+the ProcScan node scanner, keyword analysis, whitelist management, the
+golden dataset labeling of stored detector records, load tests of the
+detectors, the runtime management of plugins, the testing of custom keyword
+rules and the rotation of encrypted configuration values.
+
+Without a subcommand complik behaves like "complik run".`,
+		Version:       version,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return logger.Configure(opts.LogLevel, opts.LogFormat)
+		},
+		// Existing deployments start the binary as `manager --config=...`
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run.run(opts)
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.ConfigPath, "config", "", "path to the configuration file of the component")
+	flags.StringVar(&opts.LogLevel, "log-level", "",
+		"log level (debug, info, warn, error), overrides the configuration and COMPLIK_LOG_LEVEL")
+	flags.StringVar(&opts.LogFormat, "log-format", "", "log format (text, json), overrides COMPLIK_LOG_FORMAT")
+	run.addFlags(root.Flags())
+
+	root.AddCommand(
+		newRunCommand(opts),
+		newScanCommand(opts),
+		newAnalyzeCommand(),
+		newWhitelistCommand(opts),
+		newRecordsCommand(opts),
+		newEvalCommand(opts),
+		newLoadTestCommand(opts),
+		newPluginsCommand(opts),
+		newRulesCommand(opts),
+		newSecretsCommand(opts),
+	)
+	return root
+}
+
+// Execute runs the root command and exits with a non-zero status on failure
+func Execute() {
+	if err := NewRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads the CompliK configuration and applies its logging
+// configuration. The level and format of the configuration only apply when
+// they were not set by flag or environment.
+func (o *Options) loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(o.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := o.applyLogging(cfg.Logging); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (o *Options) applyLogging(cfg config.LoggingConfig) error {
+	level, format := "", ""
+	if o.LogLevel == "" && os.Getenv("COMPLIK_LOG_LEVEL") == "" {
+		level = cfg.Level
+	}
+	if o.LogFormat == "" && os.Getenv("COMPLIK_LOG_FORMAT") == "" {
+		format = cfg.Format
+	}
+	if err := logger.Configure(level, format); err != nil {
+		return fmt.Errorf("invalid logging configuration: %w", err)
+	}
+	for name, pluginLevel := range cfg.Plugins {
+		parsed, err := logger.ParseLevel(pluginLevel)
+		if err != nil {
+			return fmt.Errorf("invalid logging level of plugin %s: %w", name, err)
+		}
+		logger.SetPluginLevel(name, parsed)
+	}
+	logger.SetSampling(logger.SamplingConfig{
+		Disabled:   cfg.Sampling.Disabled,
+		Initial:    cfg.Sampling.Initial,
+		Thereafter: cfg.Sampling.Thereafter,
+		Tick:       time.Duration(cfg.Sampling.TickSecond) * time.Second,
+		MaxLevel:   logger.DefaultSampling.MaxLevel,
+	})
+	return nil
+}
+
+// pluginSettings returns the settings of the named plugin from the CompliK
+// configuration
+func (o *Options) pluginSettings(name string) (string, error) {
+	cfg, err := o.loadConfig()
+	if err != nil {
+		return "", err
+	}
+	for _, plugin := range cfg.Plugins {
+		if plugin.Name == name {
+			return plugin.Settings, nil
+		}
+	}
+	return "", fmt.Errorf("plugin %s is not configured in %s", name, o.ConfigPath)
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/custom"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newRulesCommand(opts *Options) *cobra.Command {
+	var server, token string
+	// A test fetches the sample and waits for the model review
+	c := &apiClient{http: &http.Client{Timeout: 2 * time.Minute}}
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Try custom keyword rules against a sample before deploying them",
+		Long: `rules talks to the rule sandbox of the custom detector plugin. A test judges a
+sample URL or a stored CollectorInfo record with the given rules, or with the
+rules the detector currently uses, and prints what the detector would decide.
+Nothing is published to the handlers or stored.
+
+The API address and token are taken from the flags, then COMPLIK_SANDBOX_API and
+COMPLIK_SANDBOX_TOKEN, then sandboxApiAddr and sandboxApiToken of the Custom
+plugin in the CompliK configuration given with --config.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			var err error
+			c.server, c.token, err = opts.sandboxAPI(server, token)
+			c.out = cmd.OutOrStdout()
+			return err
+		},
+	}
+	cmd.PersistentFlags().StringVar(&server, "server", "", "rule sandbox API address")
+	cmd.PersistentFlags().StringVar(&token, "token", "", "rule sandbox API token")
+	cmd.AddCommand(newRulesTestCommand(c), newRulesListCommand(c))
+	return cmd
+}
+
+// sandboxAPI resolves the rule sandbox API address and token
+func (o *Options) sandboxAPI(server, token string) (string, string, error) {
+	server = firstNonEmpty(server, os.Getenv("COMPLIK_SANDBOX_API"))
+	token = firstNonEmpty(token, os.Getenv("COMPLIK_SANDBOX_TOKEN"))
+	if (server == "" || token == "") && o.ConfigPath != "" {
+		settings, err := o.pluginSettings(constants.ComplianceDetectorCustom)
+		if err != nil {
+			return "", "", err
+		}
+		var cfg custom.CustomConfig
+		if err := json.Unmarshal([]byte(settings), &cfg); err != nil {
+			return "", "", fmt.Errorf("failed to parse %s plugin settings: %w", constants.ComplianceDetectorCustom, err)
+		}
+		server = firstNonEmpty(server, apiURL(cfg.SandboxAPIAddr))
+		if token == "" && cfg.SandboxAPIToken != "" {
+			if token, err = config.GetSecureValue(cfg.SandboxAPIToken); err != nil {
+				return "", "", fmt.Errorf("failed to resolve sandbox API token: %w", err)
+			}
+		}
+	}
+	if server == "" {
+		return "", "", errors.New("rule sandbox address is not configured, use --server or sandboxApiAddr")
+	}
+	return strings.TrimRight(server, "/"), token, nil
+}
+
+func newRulesTestCommand(c *apiClient) *cobra.Command {
+	var rulesPath, recordPath, sampleURL string
+	var inline []string
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Judge a sample URL or stored record with custom keyword rules",
+		Long: `test judges --url or --record with the rules of --rules and --rule. Without
+rules the rules currently loaded by the detector are used.
+
+--rules reads a YAML or JSON list of rules with type, keywords and description.
+--rule adds a rule as "type:keyword1,keyword2[:description]" and may be
+repeated. --record reads a CollectorInfo JSON file, such as an evidence file of
+"complik eval"; unlike --url it carries the screenshot the collector took.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := custom.SandboxRequest{URL: sampleURL}
+			if rulesPath != "" {
+				rules, err := readRules(rulesPath)
+				if err != nil {
+					return err
+				}
+				req.Rules = rules
+			}
+			for _, value := range inline {
+				rule, err := parseRule(value)
+				if err != nil {
+					return err
+				}
+				req.Rules = append(req.Rules, rule)
+			}
+			if recordPath != "" {
+				data, err := os.ReadFile(recordPath)
+				if err != nil {
+					return fmt.Errorf("failed to read record: %w", err)
+				}
+				req.Record = &models.CollectorInfo{}
+				if err := json.Unmarshal(data, req.Record); err != nil {
+					return fmt.Errorf("failed to parse record: %w", err)
+				}
+			}
+			if (req.URL == "") == (req.Record == nil) {
+				return errors.New("exactly one of --url and --record is required")
+			}
+
+			data, err := c.send(http.MethodPost, "/api/v1/rules/test", req)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				_, err = c.out.Write(data)
+				return err
+			}
+			var result custom.SandboxResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				return err
+			}
+			return printSandboxResult(c, &result)
+		},
+	}
+	cmd.Flags().StringVar(&sampleURL, "url", "", "URL of the sample page")
+	cmd.Flags().StringVar(&recordPath, "record", "", "stored CollectorInfo record to judge")
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "YAML or JSON file with the rules to test")
+	cmd.Flags().StringArrayVar(&inline, "rule", nil, `rule to test as "type:keywords[:description]"`)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the raw result")
+	return cmd
+}
+
+func newRulesListCommand(c *apiClient) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the rules the detector currently uses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var rules []utils.CustomKeywordRule
+			if err := c.do(http.MethodGet, "/api/v1/rules", nil, &rules); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TYPE\tKEYWORDS\tDESCRIPTION")
+			for _, rule := range rules {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", rule.Type, rule.Keywords, orDash(rule.Description))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// readRules reads a list of rules; JSON is valid YAML
+func readRules(path string) ([]utils.CustomKeywordRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	var rules []utils.CustomKeywordRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return rules, nil
+}
+
+func parseRule(value string) (utils.CustomKeywordRule, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return utils.CustomKeywordRule{}, fmt.Errorf("invalid rule %q, use type:keywords[:description]", value)
+	}
+	rule := utils.CustomKeywordRule{Type: parts[0], Keywords: parts[1]}
+	if len(parts) == 3 {
+		rule.Description = parts[2]
+	}
+	return rule, nil
+}
+
+func printSandboxResult(c *apiClient, result *custom.SandboxResult) error {
+	decision := "compliant"
+	if result.Result.IsIllegal {
+		decision = "illegal"
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Decision:\t%s\n", decision)
+	fmt.Fprintf(w, "Sample:\t%s (%s)\n", orDash(result.Result.URL), result.Source)
+	fmt.Fprintf(w, "Rules:\t%d\n", len(result.Rules))
+	fmt.Fprintf(w, "Keywords:\t%s\n", orDash(strings.Join(result.Result.Keywords, ", ")))
+	fmt.Fprintf(w, "Description:\t%s\n", orDash(result.Result.Description))
+	fmt.Fprintf(w, "Explanation:\t%s\n", orDash(result.Result.Explanation))
+	fmt.Fprintf(w, "Duration:\t%dms\n", result.DurationMs)
+	return w.Flush()
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/bearslyricattack/CompliK/complik/internal/app"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type runOptions struct {
+	dryRun     bool
+	reportPath string
+	kubeconfig string
+}
+
+func (o *runOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.dryRun, "dry-run", false, "run detectors with a stub reviewer and only log handler actions")
+	flags.StringVar(&o.reportPath, "simulation-report", "", "path of the JSON simulation report written in dry-run mode")
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "path to a kubeconfig, overrides the configuration")
+}
+
+func newRunCommand(opts *Options) *cobra.Command {
+	run := &runOptions{}
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the discovery, collection, detection and handling pipeline",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run.run(opts)
+		},
+	}
+	run.addFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *runOptions) run(opts *Options) error {
+	log := logger.GetLogger()
+	log.Info("Starting CompliK", logger.Fields{
+		"version": version,
+		"config":  opts.ConfigPath,
+		"dry_run": o.dryRun,
+	})
+
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return err
+	}
+	return app.RunWithConfig(cfg, app.Options{
+		DryRun:     o.dryRun,
+		ReportPath: o.reportPath,
+		Kubeconfig: o.kubeconfig,
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	procscan "github.com/bearslyricattack/CompliK/procscan/pkg/app"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/spf13/cobra"
+)
+
+func newScanCommand(opts *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "scan",
+		Short: "Run the ProcScan process scanner on this node",
+		Long: `scan runs the ProcScan scanner, which flags suspicious processes in the
+containers of the node and labels their namespaces. --config points at the
+ProcScan configuration, which is hot reloaded on change.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			// ProcScan logs through logrus, keep one format for the binary
+			logger.RedirectLogrus(legacy.L, "procscan")
+			return procscan.Run(ctx, procscan.Options{
+				ConfigPath: opts.ConfigPath,
+				LogLevel:   opts.LogLevel,
+				LogFormat:  opts.LogFormat,
+			})
+		},
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func newSecretsCommand(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Encrypt configuration values and rotate their master keys",
+		Long: `secrets encrypts values for ENC(...) and re-encrypts existing values after a
+master key rotation. The master keys are read from COMPLIK_ENCRYPTION_KEYS, a
+comma separated list of id=key entries whose first entry encrypts new values;
+COMPLIK_ENCRYPTION_KEY remains available as the "default" key.`,
+	}
+	cmd.AddCommand(
+		newSecretsEncryptCommand(),
+		newSecretsReencryptCommand(opts),
+	)
+	return cmd
+}
+
+func newSecretsEncryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt a value with the primary master key",
+		Long: `encrypt prints the ENC(...) form of a value. Without an argument the value is
+read from standard input, so it does not end up in the shell history.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var value string
+			if len(args) == 1 {
+				value = args[0]
+			} else {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed to read value: %w", err)
+				}
+				value = strings.TrimRight(string(data), "\r\n")
+			}
+			if value == "" {
+				return errors.New("value to encrypt is empty")
+			}
+			encrypted, err := config.EncryptValue(value)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt value: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "ENC(%s)\n", encrypted)
+			return nil
+		},
+	}
+}
+
+type reencryptOptions struct {
+	plugin  string
+	columns []string
+	dryRun  bool
+}
+
+func newSecretsReencryptCommand(opts *Options) *cobra.Command {
+	ro := &reencryptOptions{}
+	cmd := &cobra.Command{
+		Use:   "reencrypt [file...]",
+		Short: "Re-encrypt ENC(...) values with the primary master key",
+		Long: `reencrypt rewrites every ENC(...) value in the given files, or in the --config
+file when no file is given, with the primary master key. Values already
+encrypted with it are left unchanged, so the command can be repeated. With
+--plugin and --column the values stored in database columns are rotated as
+well, in the database configured in the settings of that plugin; the tables
+need an id column.
+
+To rotate a key, put the new key first in COMPLIK_ENCRYPTION_KEYS while
+keeping the old one, run reencrypt, and remove the old key afterwards.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ro.run(cmd, opts, args)
+		},
+	}
+	cmd.Flags().StringVar(&ro.plugin, "plugin", "", "plugin whose database holds the --column values")
+	cmd.Flags().StringArrayVar(&ro.columns, "column", nil, "database column with encrypted values, as table.column (repeatable)")
+	cmd.Flags().BoolVar(&ro.dryRun, "dry-run", false, "report the values that would be re-encrypted without writing them")
+	return cmd
+}
+
+func (ro *reencryptOptions) run(cmd *cobra.Command, opts *Options, files []string) error {
+	if (ro.plugin == "") != (len(ro.columns) == 0) {
+		return errors.New("--plugin and --column must be used together")
+	}
+	if len(files) == 0 && opts.ConfigPath != "" {
+		files = []string{opts.ConfigPath}
+	}
+	if len(files) == 0 && len(ro.columns) == 0 {
+		return errors.New("no file or column to re-encrypt, pass files, --config or --column")
+	}
+	keyring, err := config.DefaultKeyring()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	verb := "re-encrypted"
+	if ro.dryRun {
+		verb = "to re-encrypt"
+	}
+	fmt.Fprintf(out, "primary key: %s\n", keyring.PrimaryKeyID())
+
+	for _, path := range files {
+		changed, err := reencryptFile(ctx, keyring, path, ro.dryRun)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Fprintf(out, "%s: %d values %s\n", path, changed, verb)
+	}
+
+	if len(ro.columns) == 0 {
+		return nil
+	}
+	db, err := opts.pluginDatabase(ro.plugin)
+	if err != nil {
+		return err
+	}
+	for _, column := range ro.columns {
+		changed, err := reencryptColumn(ctx, keyring, db, column, ro.dryRun)
+		if err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+		fmt.Fprintf(out, "%s: %d values %s\n", column, changed, verb)
+	}
+	return nil
+}
+
+// reencryptFile rotates the values of a file, replacing it atomically so a
+// failure never leaves a partly written configuration
+func reencryptFile(ctx context.Context, keyring *config.Keyring, path string, dryRun bool) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	rotated, changed, err := keyring.ReencryptText(ctx, string(data))
+	if err != nil || changed == 0 || dryRun {
+		return changed, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(rotated); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return changed, os.Rename(tmp.Name(), path)
+}
+
+// reencryptColumn rotates the values of table.column in one transaction
+func reencryptColumn(ctx context.Context, keyring *config.Keyring, db *gorm.DB, column string, dryRun bool) (int, error) {
+	table, name, ok := strings.Cut(column, ".")
+	if !ok || !identifierPattern.MatchString(table) || !identifierPattern.MatchString(name) {
+		return 0, errors.New("invalid column, expected table.column")
+	}
+	changed := 0
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []map[string]any
+		if err := tx.Table(table).Select("id", name).Where(name+" LIKE ?", "%ENC(%").Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			var value string
+			switch v := row[name].(type) {
+			case string:
+				value = v
+			case []byte:
+				value = string(v)
+			default:
+				continue
+			}
+			rotated, n, err := keyring.ReencryptText(ctx, value)
+			if err != nil {
+				return fmt.Errorf("row %v: %w", row["id"], err)
+			}
+			if n == 0 {
+				continue
+			}
+			changed += n
+			if dryRun {
+				continue
+			}
+			if err := tx.Table(table).Where("id = ?", row["id"]).Update(name, rotated).Error; err != nil {
+				return fmt.Errorf("row %v: %w", row["id"], err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// pluginDatabase connects to the database configured in the settings of the
+// named plugin. All database plugins use the same setting names.
+func (o *Options) pluginDatabase(name string) (*gorm.DB, error) {
+	settings, err := o.pluginSettings(name)
+	if err != nil {
+		return nil, err
+	}
+	var s struct {
+		Driver       string `json:"driver"`
+		SQLitePath   string `json:"sqlitePath"`
+		Host         string `json:"host"`
+		Port         string `json:"port"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		DatabaseName string `json:"databaseName"`
+		Charset      string `json:"charset"`
+	}
+	if err := json.Unmarshal([]byte(settings), &s); err != nil {
+		return nil, fmt.Errorf("invalid settings of plugin %s: %w", name, err)
+	}
+	for _, field := range []*string{&s.Host, &s.Port, &s.Username, &s.Password} {
+		if *field == "" {
+			continue
+		}
+		if *field, err = config.GetSecureValue(*field); err != nil {
+			return nil, fmt.Errorf("failed to resolve the database settings of plugin %s: %w", name, err)
+		}
+	}
+	opts := database.Options{
+		Driver:       s.Driver,
+		Host:         s.Host,
+		Port:         s.Port,
+		Username:     s.Username,
+		Password:     s.Password,
+		DatabaseName: s.DatabaseName,
+		Charset:      s.Charset,
+		SQLitePath:   s.SQLitePath,
+	}
+	if opts.DatabaseName == "" {
+		opts.DatabaseName = "complik"
+	}
+	if opts.Charset == "" {
+		opts.Charset = "utf8mb4"
+	}
+	db, err := database.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database of plugin %s: %w", name, err)
+	}
+	return db, nil
+}
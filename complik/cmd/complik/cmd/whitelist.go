@@ -0,0 +1,166 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
+	"github.com/spf13/cobra"
+)
+
+func newWhitelistCommand(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whitelist",
+		Short: "Manage the namespaces and hosts exempt from Lark notifications",
+		Long: `whitelist manages the entries that suppress Lark notifications. It connects
+to the whitelist database of the Lark plugin in the CompliK configuration, and
+new entries are created in the region configured there.`,
+	}
+	cmd.AddCommand(
+		newWhitelistListCommand(opts),
+		newWhitelistAddCommand(opts),
+		newWhitelistRemoveCommand(opts),
+	)
+	return cmd
+}
+
+func (o *Options) openWhitelist() (*whitelist.WhitelistService, string, error) {
+	settings, err := o.pluginSettings(constants.HandleLark)
+	if err != nil {
+		return nil, "", err
+	}
+	return lark.OpenWhitelist(settings)
+}
+
+func newWhitelistListCommand(opts *Options) *cobra.Command {
+	var entryType, search string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List whitelist entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, _, err := opts.openWhitelist()
+			if err != nil {
+				return err
+			}
+			var entries []whitelist.Whitelist
+			switch {
+			case search != "":
+				entries, err = service.SearchWhitelists(search)
+			case entryType != "":
+				entries, err = service.GetWhitelistsByType(whitelist.WhitelistType(entryType))
+			default:
+				entries, err = service.GetAllWhitelists()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list whitelist entries: %w", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tREGION\tNAMESPACE\tHOST\tNAME\tCREATED\tREMARK")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.ID, e.Type, e.Region,
+					orDash(e.Namespace), orDash(e.Hostname), e.Name,
+					e.CreatedAt.Format("2006-01-02 15:04"), e.Remark)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&entryType, "type", "", "only entries of this type (namespace, host)")
+	cmd.Flags().StringVar(&search, "search", "", "only entries whose name, namespace, host or remark contain this text")
+	return cmd
+}
+
+func newWhitelistAddCommand(opts *Options) *cobra.Command {
+	var entry whitelist.Whitelist
+	cmd := &cobra.Command{
+		Use:   "add --namespace NAME | --host HOST",
+		Short: "Exempt a namespace or host from notifications",
+		Long: `add creates a whitelist entry. Namespace entries never expire; host entries
+expire after host_timeout_hour of the Lark plugin settings (7 days by default).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case entry.Namespace != "" && entry.Hostname != "":
+				return errors.New("--namespace and --host are mutually exclusive")
+			case entry.Namespace != "":
+				entry.Type = whitelist.WhitelistTypeNamespace
+			case entry.Hostname != "":
+				entry.Type = whitelist.WhitelistTypeHost
+			default:
+				return errors.New("either --namespace or --host is required")
+			}
+			if entry.Name == "" {
+				entry.Name = entry.Namespace + entry.Hostname
+			}
+
+			service, region, err := opts.openWhitelist()
+			if err != nil {
+				return err
+			}
+			entry.Region = region
+			if err := service.Create(&entry); err != nil {
+				return fmt.Errorf("failed to add whitelist entry: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "whitelist entry %d added for %s %s in region %s\n",
+				entry.ID, entry.Type, entry.Namespace+entry.Hostname, entry.Region)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&entry.Namespace, "namespace", "", "namespace to exempt")
+	cmd.Flags().StringVar(&entry.Hostname, "host", "", "host to exempt")
+	cmd.Flags().StringVar(&entry.Name, "name", "", "display name of the entry (default: the namespace or host)")
+	cmd.Flags().StringVar(&entry.Remark, "remark", "", "why the entry was added")
+	return cmd
+}
+
+func newWhitelistRemoveCommand(opts *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a whitelist entry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid whitelist id %q", args[0])
+			}
+			service, _, err := opts.openWhitelist()
+			if err != nil {
+				return err
+			}
+			if _, err := service.GetWhitelistByID(uint(id)); err != nil {
+				return fmt.Errorf("whitelist entry %d: %w", id, err)
+			}
+			if err := service.RemoveWhitelistByID(uint(id)); err != nil {
+				return fmt.Errorf("failed to remove whitelist entry: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "whitelist entry %d removed\n", id)
+			return nil
+		},
+	}
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -12,45 +12,44 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Command complik is the single entry point of CompliK; see the cmd package
+// for the subcommands.
 package main
 
 import (
-	"flag"
 	"os"
 	"runtime/debug"
 
-	"github.com/bearslyricattack/CompliK/complik/internal/app"
-	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/cmd/complik/cmd"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/tcp"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/correlation"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/custom"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/safety"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/secrets"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/services"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/compliance/watchdog"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/cronjob/complete"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/cronjob/devbox"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/customresource"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/deployment"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/devbox"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/endPointSlice"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/loadbalancer"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/statefulset"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/blockpage"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/database/postages"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/elasticsearch"
 	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/lark"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/quarantine"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/sealos"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/summary"
+	_ "github.com/bearslyricattack/CompliK/complik/plugins/handle/syslog"
 )
 
 func main() {
 	debug.SetTraceback("all")
 	os.Setenv("GOTRACEBACK", "all")
 
-	logger.Init()
-	log := logger.GetLogger()
-
-	configPath := flag.String("config", "", "path to configuration file")
-	flag.Parse()
-
-	log.Info("Starting CompliK", logger.Fields{
-		"version": "1.0.0",
-		"config":  *configPath,
-	})
-
-	if err := app.Run(*configPath); err != nil {
-		log.Fatal("Application failed", logger.Fields{
-			"error": err.Error(),
-		})
-	}
+	cmd.Execute()
 }
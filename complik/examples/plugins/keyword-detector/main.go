@@ -0,0 +1,118 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command keyword-detector is an example external detector plugin. It flags
+// collected pages whose HTML contains one of the configured keywords.
+//
+// Build it into the plugin directory of CompliK:
+//
+//	go build -o /etc/complik/plugins/keyword-detector ./examples/plugins/keyword-detector
+//
+// and enable it like a built-in plugin:
+//
+//	pluginDir: /etc/complik/plugins
+//	plugins:
+//	  - name: KeywordExample
+//	    type: Compliance.Detector
+//	    enabled: true
+//	    settings: '{"keywords": ["casino", "betting"]}'
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin/sdk"
+)
+
+const pluginName = "KeywordExample"
+
+type settings struct {
+	Keywords []string `json:"keywords"`
+}
+
+type detector struct {
+	keywords  []string
+	publisher sdk.Publisher
+}
+
+func (d *detector) Describe(context.Context) (sdk.Info, error) {
+	return sdk.Info{
+		Name:      pluginName,
+		Type:      constants.ComplianceDetectorPluginType,
+		Subscribe: []string{constants.CollectorTopic},
+	}, nil
+}
+
+func (d *detector) Start(_ context.Context, cfg sdk.Config, publisher sdk.Publisher) error {
+	var s settings
+	if cfg.Settings != "" {
+		if err := json.Unmarshal([]byte(cfg.Settings), &s); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+	}
+	for _, keyword := range s.Keywords {
+		d.keywords = append(d.keywords, strings.ToLower(keyword))
+	}
+	d.publisher = publisher
+	return nil
+}
+
+func (d *detector) Handle(ctx context.Context, event sdk.Event) error {
+	var collected models.CollectorInfo
+	if err := json.Unmarshal(event.Payload, &collected); err != nil {
+		return err
+	}
+	if collected.IsEmpty {
+		return nil
+	}
+
+	html := strings.ToLower(collected.HTML)
+	var matched []string
+	for _, keyword := range d.keywords {
+		if strings.Contains(html, keyword) {
+			matched = append(matched, keyword)
+		}
+	}
+	result := &models.DetectorInfo{
+		DiscoveryName: collected.DiscoveryName,
+		CollectorName: collected.CollectorName,
+		DetectorName:  pluginName,
+		Name:          collected.Name,
+		Namespace:     collected.Namespace,
+		Host:          collected.Host,
+		Path:          collected.Path,
+		URL:           collected.URL,
+		Keywords:      matched,
+		IsIllegal:     len(matched) > 0,
+		Severity:      models.SeverityLow,
+	}
+	if result.IsIllegal {
+		result.Severity = models.SeverityMedium
+		result.Explanation = "page contains " + strings.Join(matched, ", ")
+	}
+	return d.publisher.Publish(ctx, constants.DetectorTopic, result)
+}
+
+func (d *detector) Stop(context.Context) error {
+	return nil
+}
+
+func main() {
+	sdk.Serve(&detector{})
+}
@@ -15,19 +15,45 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/debug"
+	"github.com/bearslyricattack/CompliK/complik/pkg/enrichment"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/health"
 	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/overrides"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin/external"
+	"github.com/bearslyricattack/CompliK/complik/pkg/scanrun"
+	"github.com/bearslyricattack/CompliK/complik/pkg/simulation"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
 )
 
-func Run(configPath string) error {
+// Options are the command line overrides for a run
+type Options struct {
+	// DryRun runs discovery, collectors and detectors with a stub reviewer and
+	// replaces the handler plugins with a simulation recorder
+	DryRun bool
+	// ReportPath is where the simulation report is written on shutdown; the
+	// summary is only logged when it is empty
+	ReportPath string
+	// Kubeconfig replaces the kubeconfig path of the configuration when set
+	Kubeconfig string
+}
+
+func Run(configPath string, opts Options) error {
 	log := logger.GetLogger()
 
 	log.Info("Loading configuration", logger.Fields{"path": configPath})
@@ -36,6 +62,18 @@ func Run(configPath string) error {
 		log.Error("Failed to load configuration", logger.Fields{"error": err.Error()})
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	return RunWithConfig(cfg, opts)
+}
+
+// RunWithConfig starts CompliK with an already loaded configuration and blocks
+// until SIGINT or SIGTERM
+func RunWithConfig(cfg *config.Config, opts Options) error {
+	log := logger.GetLogger()
+	if opts.Kubeconfig != "" {
+		cfg.Kubeconfig = opts.Kubeconfig
+	}
+	// client-go and the informers log through klog
+	logger.RedirectKlog()
 
 	log.Info("Initializing Kubernetes client", logger.Fields{"kubeconfig": cfg.Kubeconfig})
 	if err := k8s.InitClient(cfg.Kubeconfig); err != nil {
@@ -45,9 +83,45 @@ func Run(configPath string) error {
 
 	log.Info("Creating event bus")
 	eventBus := eventbus.NewEventBus(100)
+	registry := eventbus.NewRegistry()
+	if err := models.RegisterSchemas(registry); err != nil {
+		return fmt.Errorf("failed to register payload schemas: %w", err)
+	}
+	eventBus.SetRegistry(registry)
+	policy, err := scanPolicy(cfg.ScanPolicy)
+	if err != nil {
+		return err
+	}
+	eventBus.SetPolicy(policy)
+	if err := overrides.Configure(cfg.ScanOverrides); err != nil {
+		return err
+	}
+	if !cfg.Enrichment.Disabled {
+		eventBus.AddStage(constants.DetectorTopic, enrichment.New(k8s.ClientSet, cfg.Enrichment).Stage())
+	}
 
 	log.Info("Initializing plugin manager")
 	m := plugin.NewManager(eventBus)
+	dryRun := opts.DryRun || cfg.DryRun
+	if dryRun {
+		log.Warn("Dry-run mode enabled: handlers will only log their actions")
+		m.SetDryRun(true)
+	}
+
+	if cfg.PluginAPI.StatePath != "" {
+		if err := m.SetStatePath(cfg.PluginAPI.StatePath); err != nil {
+			return fmt.Errorf("failed to load plugin state: %w", err)
+		}
+	}
+
+	if cfg.PluginDir != "" {
+		names, err := external.Register(cfg.PluginDir)
+		if err != nil {
+			log.Error("Failed to register external plugins", logger.Fields{"error": err.Error()})
+			return fmt.Errorf("failed to register external plugins: %w", err)
+		}
+		log.Info("External plugins discovered", logger.Fields{"dir": cfg.PluginDir, "plugins": names})
+	}
 
 	log.Info("Loading plugins", logger.Fields{"count": len(cfg.Plugins)})
 	if err := m.LoadPlugins(cfg.Plugins); err != nil {
@@ -55,16 +129,51 @@ func Run(configPath string) error {
 		return fmt.Errorf("failed to load plugins: %w", err)
 	}
 
-	log.Info("Starting all plugins")
-	if err := m.StartAll(); err != nil {
-		log.Error("Failed to start plugins", logger.Fields{"error": err.Error()})
-		return fmt.Errorf("failed to start plugins: %w", err)
+	probes := newHealthServer(cfg.Health, m, eventBus)
+	if probes != nil {
+		probes.Start()
+	}
+
+	runs := scanrun.NewTracker(0, 0)
+	runs.Start(eventBus)
+
+	pluginAPI, err := startPluginAPI(cfg.PluginAPI, m, runs)
+	if err != nil {
+		return err
+	}
+	debugServer, err := startDebugServer(cfg.Debug)
+	if err != nil {
+		return err
+	}
+
+	var recorder *simulation.Recorder
+	if dryRun {
+		recorder = simulation.NewRecorder(m.SimulatedHandlers(), 0)
+		recorder.Start(eventBus)
 	}
 
-	log.Info("Application started successfully, waiting for shutdown signal")
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-sigChan
+
+	log.Info("Starting all plugins")
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- m.StartAll()
+	}()
+
+	var sig os.Signal
+	for sig == nil {
+		select {
+		case err := <-startErr:
+			if err != nil {
+				log.Error("Failed to start plugins", logger.Fields{"error": err.Error()})
+				return fmt.Errorf("failed to start plugins: %w", err)
+			}
+			log.Info("Application started successfully, waiting for shutdown signal")
+			startErr = nil
+		case sig = <-sigChan:
+		}
+	}
 
 	log.Info("Received shutdown signal", logger.Fields{"signal": sig.String()})
 	log.Info("Shutting down gracefully...")
@@ -73,6 +182,151 @@ func Run(configPath string) error {
 		return fmt.Errorf("failed to stop plugins: %w", err)
 	}
 
+	if probes != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := probes.Stop(ctx); err != nil {
+			log.Warn("Failed to stop health server", logger.Fields{"error": err.Error()})
+		}
+		cancel()
+	}
+	if pluginAPI != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := pluginAPI.Shutdown(ctx); err != nil {
+			log.Warn("Failed to stop plugin API server", logger.Fields{"error": err.Error()})
+		}
+		cancel()
+	}
+
+	if debugServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := debugServer.Shutdown(ctx); err != nil {
+			log.Warn("Failed to stop debug server", logger.Fields{"error": err.Error()})
+		}
+		cancel()
+	}
+
+	if recorder != nil {
+		if err := writeSimulationReport(recorder, opts.ReportPath); err != nil {
+			log.Error("Failed to write simulation report", logger.Fields{"error": err.Error()})
+			return err
+		}
+	}
+
 	log.Info("Application shutdown completed")
 	return nil
 }
+
+// newHealthServer registers the liveness and readiness checks of the app, nil
+// when the probe server is disabled
+func newHealthServer(cfg config.HealthConfig, m *plugin.Manager, eventBus *eventbus.EventBus) *health.Server {
+	if cfg.Disabled {
+		return nil
+	}
+	addr := cfg.Addr
+	if addr == "" {
+		addr = config.DefaultHealthAddr
+	}
+	server := health.NewServer(addr)
+	if err := server.AddCollector(complikerrors.Collector()); err != nil {
+		logger.GetLogger().Warn("Failed to register failure metrics", logger.Fields{"error": err.Error()})
+	}
+	server.AddLivenessCheck("eventbus", health.EventBusCheck(eventBus))
+	server.AddReadinessCheck("kubernetes", k8s.Ping)
+	server.AddReadinessCheck("plugins", func(context.Context) error {
+		return m.Ready()
+	})
+	server.AddReadinessCheck("databases", func(ctx context.Context) error {
+		return health.Errors(m.CheckHealth(ctx))
+	})
+	return server
+}
+
+// scanPolicy converts the scan policy of the configuration, the reserves of
+// detection and handling must leave time for the collection
+func scanPolicy(cfg config.ScanPolicyConfig) (eventbus.Policy, error) {
+	if cfg.DeadlineSecond < 0 || cfg.DetectionSecond < 0 || cfg.HandlingSecond < 0 {
+		return eventbus.Policy{}, errors.New("scanPolicy durations must not be negative")
+	}
+	if cfg.DeadlineSecond > 0 && cfg.HandlingSecond == 0 {
+		return eventbus.Policy{}, errors.New("scanPolicy.handlingSecond must be set with a deadline")
+	}
+	if cfg.DeadlineSecond > 0 && cfg.DetectionSecond+cfg.HandlingSecond >= cfg.DeadlineSecond {
+		return eventbus.Policy{}, fmt.Errorf("scanPolicy.deadlineSecond %d leaves no time for the collection",
+			cfg.DeadlineSecond)
+	}
+	return eventbus.Policy{
+		Budget:    time.Duration(cfg.DeadlineSecond) * time.Second,
+		Detection: time.Duration(cfg.DetectionSecond) * time.Second,
+		Handling:  time.Duration(cfg.HandlingSecond) * time.Second,
+	}, nil
+}
+
+// startPluginAPI serves the plugin management and scan run APIs in the
+// background, nil when it is not configured
+func startPluginAPI(cfg config.PluginAPIConfig, m *plugin.Manager, runs *scanrun.Tracker) (*http.Server, error) {
+	if cfg.Addr == "" {
+		return nil, nil
+	}
+	log := logger.GetLogger().WithField("component", "plugin-api")
+	token, err := config.GetSecureValue(cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve plugin API token: %w", err)
+	}
+	// The API stops and restarts plugins, it is never served unauthenticated
+	if token == "" {
+		return nil, errors.New("pluginApi.token is required when pluginApi.addr is set")
+	}
+	plugins := plugin.NewAPI(log, token, m)
+	scans := scanrun.NewAPI(log, token, runs)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/plugins", plugins)
+	mux.Handle("/api/v1/plugins/", plugins)
+	mux.Handle("/api/v1/scans", scans)
+	mux.Handle("/api/v1/scans/", scans)
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Info("Starting plugin API server", logger.Fields{"addr": cfg.Addr})
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Plugin API server stopped", logger.Fields{"error": err.Error()})
+		}
+	}()
+	return server, nil
+}
+
+// startDebugServer serves the pprof and runtime endpoints in the background,
+// nil when they are not configured
+func startDebugServer(cfg config.DebugConfig) (*http.Server, error) {
+	if cfg.Addr == "" {
+		return nil, nil
+	}
+	token, err := config.GetSecureValue(cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve debug token: %w", err)
+	}
+	server, err := debug.Start(cfg.Addr, token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid debug configuration: %w", err)
+	}
+	return server, nil
+}
+
+func writeSimulationReport(recorder *simulation.Recorder, path string) error {
+	report := recorder.Report()
+	logger.GetLogger().Info("Simulation finished", logger.Fields{
+		"detections":   report.Detections,
+		"flagged":      report.FlaggedCount,
+		"by_detector":  report.ByDetector,
+		"by_severity":  report.BySeverity,
+		"handlers":     report.Handlers,
+		"report_path":  path,
+		"duration_sec": int(report.FinishedAt.Sub(report.StartedAt).Seconds()),
+	})
+	if path == "" {
+		return nil
+	}
+	return recorder.WriteReport(path)
+}
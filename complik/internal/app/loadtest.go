@@ -0,0 +1,124 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/loadtest"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+// loadTestReadyTimeout bounds the wait for the detectors before the load is
+// generated
+const loadTestReadyTimeout = time.Minute
+
+// LoadTestPlugins returns the plugins of cfg a load test starts: the
+// discovery and collector plugins are replaced by the load generator, so no
+// cluster or site is touched; detectors and correlation are kept
+func LoadTestPlugins(cfg *config.Config) []config.PluginConfig {
+	var plugins []config.PluginConfig
+	for _, pluginConfig := range cfg.Plugins {
+		factory, ok := plugin.PluginFactories[pluginConfig.Name]
+		if !ok {
+			continue
+		}
+		switch pluginType := factory().Type(); {
+		case strings.HasPrefix(pluginType, "Discovery."),
+			pluginType == constants.ComplianceCollectorPluginType,
+			pluginType == constants.ComplianceHigressPluginType:
+			continue
+		}
+		plugins = append(plugins, pluginConfig)
+	}
+	return plugins
+}
+
+// RunLoadTest loads the detectors of cfg in dry-run mode, so they review with
+// the stub reviewer and no handler acts, feeds them the synthesized load of
+// load and returns its report. ctx cancels the run early.
+func RunLoadTest(ctx context.Context, cfg *config.Config, load loadtest.Config) (loadtest.RunReport, error) {
+	log := logger.GetLogger()
+
+	eventBus := eventbus.NewEventBus(100)
+	registry := eventbus.NewRegistry()
+	if err := models.RegisterSchemas(registry); err != nil {
+		return loadtest.RunReport{}, fmt.Errorf("failed to register payload schemas: %w", err)
+	}
+	eventBus.SetRegistry(registry)
+
+	m := plugin.NewManager(eventBus)
+	m.SetDryRun(true)
+	plugins := LoadTestPlugins(cfg)
+	if err := m.LoadPlugins(plugins); err != nil {
+		return loadtest.RunReport{}, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	var detectors []string
+	for _, info := range m.Plugins() {
+		if info.Enabled && info.Type == constants.ComplianceDetectorPluginType {
+			detectors = append(detectors, info.Name)
+		}
+	}
+	if len(detectors) == 0 {
+		return loadtest.RunReport{}, errors.New("no detector plugin is enabled in the configuration")
+	}
+
+	log.Info("Starting plugins for the load test", logger.Fields{"detectors": detectors})
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- m.StartAll()
+	}()
+	if err := waitReady(ctx, m, startErr); err != nil {
+		_ = m.StopAll()
+		return loadtest.RunReport{}, err
+	}
+
+	report := loadtest.Run(ctx, eventBus, load)
+	if err := m.StopAll(); err != nil {
+		log.Warn("Failed to stop plugins", logger.Fields{"error": err.Error()})
+	}
+	return report, nil
+}
+
+// waitReady waits until every enabled plugin runs
+func waitReady(ctx context.Context, m *plugin.Manager, startErr <-chan error) error {
+	ctx, cancel := context.WithTimeout(ctx, loadTestReadyTimeout)
+	defer cancel()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-startErr:
+			if err != nil {
+				return fmt.Errorf("failed to start plugins: %w", err)
+			}
+		case <-ticker.C:
+			if m.Ready() == nil {
+				return nil
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("plugins are not ready: %w", errors.Join(m.Ready(), ctx.Err()))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes the two detector configurations to compare
+type Config struct {
+	Concurrency int           `yaml:"concurrency"`
+	Baseline    VariantConfig `yaml:"baseline"`
+	Candidate   VariantConfig `yaml:"candidate"`
+}
+
+// VariantConfig is a single detector configuration. Rules switch the reviewer
+// to the custom keyword prompt; PromptFile replaces the built-in prompt and
+// PromptSet renders both prompts from a template set file.
+type VariantConfig struct {
+	Name       string                    `yaml:"name"`
+	Stub       bool                      `yaml:"stub"`
+	APIKey     string                    `yaml:"apiKey"`
+	APIBase    string                    `yaml:"apiBase"`
+	APIPath    string                    `yaml:"apiPath"`
+	Model      string                    `yaml:"model"`
+	PromptFile string                    `yaml:"promptFile"`
+	PromptSet  string                    `yaml:"promptSet"`
+	Rules      []utils.CustomKeywordRule `yaml:"rules"`
+}
+
+// Variant is a detector configuration ready to be evaluated
+type Variant struct {
+	Name     string
+	Reviewer utils.Reviewer
+	Rules    []utils.CustomKeywordRule
+}
+
+// LoadConfig reads an evaluation configuration file. Relative prompt files
+// are resolved against the directory of the configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evaluation config: %w", err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse evaluation config: %w", err)
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.Baseline.Name == "" {
+		cfg.Baseline.Name = "baseline"
+	}
+	if cfg.Candidate.Name == "" {
+		cfg.Candidate.Name = "candidate"
+	}
+	if cfg.Baseline.Name == cfg.Candidate.Name {
+		return nil, errors.New("baseline and candidate must have different names")
+	}
+	dir := filepath.Dir(path)
+	for _, v := range []*VariantConfig{&cfg.Baseline, &cfg.Candidate} {
+		if v.PromptFile != "" && !filepath.IsAbs(v.PromptFile) {
+			v.PromptFile = filepath.Join(dir, v.PromptFile)
+		}
+		if v.PromptSet != "" && !filepath.IsAbs(v.PromptSet) {
+			v.PromptSet = filepath.Join(dir, v.PromptSet)
+		}
+	}
+	return cfg, nil
+}
+
+// Build creates the reviewer for the variant
+func (v VariantConfig) Build(log logger.Logger) (*Variant, error) {
+	variant := &Variant{Name: v.Name, Rules: v.Rules}
+	if v.Stub {
+		variant.Reviewer = utils.NewStubReviewer(log)
+		return variant, nil
+	}
+
+	if v.APIKey == "" {
+		return nil, fmt.Errorf("variant %s: apiKey is required", v.Name)
+	}
+	apiKey, err := config.GetSecureValue(v.APIKey)
+	if err != nil {
+		if config.IsSecretReference(v.APIKey) {
+			return nil, fmt.Errorf("variant %s: failed to resolve API key: %w", v.Name, err)
+		}
+		apiKey = v.APIKey
+	}
+	apiBase, apiPath, model := v.APIBase, v.APIPath, v.Model
+	if apiBase == "" {
+		apiBase = "https://aiproxy.usw.sealos.io/v1"
+	}
+	if apiPath == "" {
+		apiPath = "/chat/completions"
+	}
+	if model == "" {
+		model = "gpt-5"
+	}
+	reviewer := utils.NewContentReviewer(log, apiKey, apiBase, apiPath, model)
+	if v.PromptFile != "" {
+		prompt, err := os.ReadFile(v.PromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: failed to read prompt: %w", v.Name, err)
+		}
+		reviewer.SetPromptTemplate(string(prompt))
+	}
+	if v.PromptSet != "" {
+		source, err := os.ReadFile(v.PromptSet)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: failed to read prompt set: %w", v.Name, err)
+		}
+		set, err := prompts.Parse(v.Name, string(source))
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+		reviewer.SetPrompts(set)
+	}
+	variant.Reviewer = reviewer
+	return variant, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package evaluation replays stored collector records through two detector
+// configurations and compares their precision and recall against labeled
+// ground truth, so prompt, model and rule changes can be validated offline.
+package evaluation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Sample is a stored collector record identified by its file name
+type Sample struct {
+	ID   string
+	Info *models.CollectorInfo
+}
+
+// LoadEvidence reads every <id>.json CollectorInfo record in dir. When a record
+// has no embedded screenshot, <id>.png next to it is used instead.
+func LoadEvidence(dir string) ([]Sample, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evidence directory: %w", err)
+	}
+	var samples []Sample
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read evidence %s: %w", id, err)
+		}
+		info := &models.CollectorInfo{}
+		if err := json.Unmarshal(data, info); err != nil {
+			return nil, fmt.Errorf("failed to parse evidence %s: %w", id, err)
+		}
+		if len(info.Screenshot) == 0 {
+			screenshot, err := os.ReadFile(filepath.Join(dir, id+".png"))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to read screenshot %s: %w", id, err)
+			}
+			info.Screenshot = screenshot
+		}
+		samples = append(samples, Sample{ID: id, Info: info})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].ID < samples[j].ID })
+	return samples, nil
+}
+
+// LoadGroundTruth reads a CSV table with an id and an is_illegal column and
+// returns the labels by sample id. Extra columns are ignored.
+func LoadGroundTruth(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ground truth: %w", err)
+	}
+	defer file.Close()
+	return ParseGroundTruth(file)
+}
+
+// ParseGroundTruth parses the CSV format described in LoadGroundTruth
+func ParseGroundTruth(r io.Reader) (map[string]bool, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ground truth header: %w", err)
+	}
+	idCol, labelCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "id":
+			idCol = i
+		case "is_illegal", "label":
+			labelCol = i
+		}
+	}
+	if idCol < 0 || labelCol < 0 {
+		return nil, errors.New("ground truth must have id and is_illegal columns")
+	}
+
+	labels := make(map[string]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return labels, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) <= idCol || len(record) <= labelCol {
+			return nil, fmt.Errorf("line %d: missing columns", line)
+		}
+		label, err := parseLabel(record[labelCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		labels[strings.TrimSpace(record[idCol])] = label
+	}
+}
+
+func parseLabel(value string) (bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "yes", "illegal":
+		return true, nil
+	case "no", "legal":
+		return false, nil
+	}
+	label, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid label %q", value)
+	}
+	return label, nil
+}
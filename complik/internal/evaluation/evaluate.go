@@ -0,0 +1,182 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Metrics is the confusion matrix of a variant over the labeled samples.
+// Failed reviews are counted in Errors and left out of precision and recall.
+type Metrics struct {
+	Variant        string  `json:"variant"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Errors         int     `json:"errors"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// Outcome is the prediction of both variants for a single sample
+type Outcome struct {
+	ID        string `json:"id"`
+	Host      string `json:"host"`
+	Truth     bool   `json:"truth"`
+	Baseline  *bool  `json:"baseline"`
+	Candidate *bool  `json:"candidate"`
+}
+
+// Comparison is the result of an A/B evaluation
+type Comparison struct {
+	StartedAt      time.Time `json:"started_at"`
+	Duration       string    `json:"duration"`
+	Samples        int       `json:"samples"`
+	Unlabeled      int       `json:"unlabeled"`
+	Baseline       Metrics   `json:"baseline"`
+	Candidate      Metrics   `json:"candidate"`
+	PrecisionDelta float64   `json:"precision_delta"`
+	RecallDelta    float64   `json:"recall_delta"`
+	F1Delta        float64   `json:"f1_delta"`
+	// Disagreements lists the samples where the variants predicted differently
+	// or where one of them failed
+	Disagreements []Outcome `json:"disagreements"`
+}
+
+// Evaluator replays samples through two variants
+type Evaluator struct {
+	log         logger.Logger
+	concurrency int
+	timeout     time.Duration
+}
+
+func NewEvaluator(log logger.Logger, concurrency int) *Evaluator {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &Evaluator{log: log, concurrency: concurrency, timeout: 80 * time.Second}
+}
+
+// Compare runs every labeled sample through baseline and candidate. Samples
+// without a label are skipped and counted as unlabeled.
+func (e *Evaluator) Compare(
+	ctx context.Context,
+	samples []Sample,
+	truth map[string]bool,
+	baseline, candidate *Variant,
+) *Comparison {
+	started := time.Now()
+	var labeled []Sample
+	for _, sample := range samples {
+		if _, ok := truth[sample.ID]; ok {
+			labeled = append(labeled, sample)
+		}
+	}
+
+	outcomes := make([]Outcome, len(labeled))
+	semaphore := make(chan struct{}, e.concurrency)
+	var wg sync.WaitGroup
+	for i, sample := range labeled {
+		outcomes[i] = Outcome{ID: sample.ID, Host: sample.Info.Host, Truth: truth[sample.ID]}
+		for _, target := range []struct {
+			variant *Variant
+			result  **bool
+		}{
+			{baseline, &outcomes[i].Baseline},
+			{candidate, &outcomes[i].Candidate},
+		} {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				*target.result = e.predict(ctx, target.variant, sample)
+			}()
+		}
+	}
+	wg.Wait()
+
+	comparison := &Comparison{
+		StartedAt:     started,
+		Samples:       len(labeled),
+		Unlabeled:     len(samples) - len(labeled),
+		Baseline:      Metrics{Variant: baseline.Name},
+		Candidate:     Metrics{Variant: candidate.Name},
+		Disagreements: []Outcome{},
+	}
+	for _, outcome := range outcomes {
+		comparison.Baseline.add(outcome.Truth, outcome.Baseline)
+		comparison.Candidate.add(outcome.Truth, outcome.Candidate)
+		if outcome.Baseline == nil || outcome.Candidate == nil ||
+			*outcome.Baseline != *outcome.Candidate {
+			comparison.Disagreements = append(comparison.Disagreements, outcome)
+		}
+	}
+	comparison.Baseline.finish()
+	comparison.Candidate.finish()
+	comparison.PrecisionDelta = comparison.Candidate.Precision - comparison.Baseline.Precision
+	comparison.RecallDelta = comparison.Candidate.Recall - comparison.Baseline.Recall
+	comparison.F1Delta = comparison.Candidate.F1 - comparison.Baseline.F1
+	comparison.Duration = time.Since(started).Round(time.Millisecond).String()
+	return comparison
+}
+
+func (e *Evaluator) predict(ctx context.Context, variant *Variant, sample Sample) *bool {
+	taskCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	result, err := variant.Reviewer.ReviewSiteContent(taskCtx, sample.Info, variant.Name, variant.Rules)
+	if err != nil {
+		e.log.Warn("Review failed during evaluation", logger.Fields{
+			"variant": variant.Name,
+			"sample":  sample.ID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	return &result.IsIllegal
+}
+
+func (m *Metrics) add(truth bool, predicted *bool) {
+	switch {
+	case predicted == nil:
+		m.Errors++
+	case *predicted && truth:
+		m.TruePositives++
+	case *predicted && !truth:
+		m.FalsePositives++
+	case !*predicted && truth:
+		m.FalseNegatives++
+	default:
+		m.TrueNegatives++
+	}
+}
+
+func (m *Metrics) finish() {
+	if flagged := m.TruePositives + m.FalsePositives; flagged > 0 {
+		m.Precision = float64(m.TruePositives) / float64(flagged)
+	}
+	if positives := m.TruePositives + m.FalseNegatives; positives > 0 {
+		m.Recall = float64(m.TruePositives) / float64(positives)
+	}
+	if m.Precision+m.Recall > 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvaluation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Evaluation Suite")
+}
+
+// hostReviewer flags the hosts in its set and fails for the ones in failing
+type hostReviewer struct {
+	flagged map[string]bool
+	failing map[string]bool
+}
+
+func (r *hostReviewer) ReviewSiteContent(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	_ []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	if r.failing[content.Host] {
+		return nil, errors.New("api unavailable")
+	}
+	return &models.DetectorInfo{DetectorName: name, Host: content.Host, IsIllegal: r.flagged[content.Host]}, nil
+}
+
+func writeEvidence(dir, id string, info *models.CollectorInfo) {
+	data, err := json.Marshal(info)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(filepath.Join(dir, id+".json"), data, 0o600)).To(Succeed())
+}
+
+var _ = Describe("Dataset", func() {
+	It("should load evidence records and sidecar screenshots", func() {
+		dir := GinkgoT().TempDir()
+		writeEvidence(dir, "b", &models.CollectorInfo{Host: "b.example.com", Screenshot: []byte("inline")})
+		writeEvidence(dir, "a", &models.CollectorInfo{Host: "a.example.com"})
+		Expect(os.WriteFile(filepath.Join(dir, "a.png"), []byte("sidecar"), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600)).To(Succeed())
+
+		samples, err := LoadEvidence(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(samples).To(HaveLen(2))
+		Expect(samples[0].ID).To(Equal("a"))
+		Expect(samples[0].Info.Screenshot).To(Equal([]byte("sidecar")))
+		Expect(samples[1].Info.Screenshot).To(Equal([]byte("inline")))
+	})
+
+	It("should parse ground truth labels", func() {
+		labels, err := ParseGroundTruth(strings.NewReader(
+			"id,is_illegal,comment\na,yes,gambling\nb,false,\nc,1,\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal(map[string]bool{"a": true, "b": false, "c": true}))
+
+		_, err = ParseGroundTruth(strings.NewReader("host,label\na,yes\n"))
+		Expect(err).To(HaveOccurred())
+		_, err = ParseGroundTruth(strings.NewReader("id,label\na,maybe\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("LoadConfig", func() {
+	It("should apply defaults and resolve prompt files", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "eval.yaml")
+		Expect(os.WriteFile(path, []byte(`
+baseline:
+  stub: true
+candidate:
+  apiKey: test
+  promptFile: prompts/candidate.txt
+  rules:
+    - type: gambling
+      keywords: casino,poker
+`), 0o600)).To(Succeed())
+
+		cfg, err := LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Concurrency).To(Equal(4))
+		Expect(cfg.Baseline.Name).To(Equal("baseline"))
+		Expect(cfg.Candidate.PromptFile).To(Equal(filepath.Join(dir, "prompts", "candidate.txt")))
+		Expect(cfg.Candidate.Rules).To(HaveLen(1))
+		Expect(cfg.Candidate.Rules[0].Keywords).To(Equal("casino,poker"))
+
+		_, err = cfg.Candidate.Build(logger.GetLogger())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Evaluator", func() {
+	It("should report precision and recall deltas", func() {
+		samples := []Sample{
+			{ID: "1", Info: &models.CollectorInfo{Host: "casino.example.com"}},
+			{ID: "2", Info: &models.CollectorInfo{Host: "shop.example.com"}},
+			{ID: "3", Info: &models.CollectorInfo{Host: "porn.example.com"}},
+			{ID: "4", Info: &models.CollectorInfo{Host: "blog.example.com"}},
+			{ID: "5", Info: &models.CollectorInfo{Host: "unlabeled.example.com"}},
+		}
+		truth := map[string]bool{"1": true, "2": false, "3": true, "4": false}
+		baseline := &Variant{Name: "baseline", Reviewer: &hostReviewer{
+			flagged: map[string]bool{"casino.example.com": true, "shop.example.com": true},
+		}}
+		candidate := &Variant{Name: "candidate", Reviewer: &hostReviewer{
+			flagged: map[string]bool{"casino.example.com": true, "porn.example.com": true},
+			failing: map[string]bool{"blog.example.com": true},
+		}}
+
+		c := NewEvaluator(logger.GetLogger(), 2).Compare(context.Background(), samples, truth, baseline, candidate)
+		Expect(c.Samples).To(Equal(4))
+		Expect(c.Unlabeled).To(Equal(1))
+
+		Expect(c.Baseline.TruePositives).To(Equal(1))
+		Expect(c.Baseline.FalsePositives).To(Equal(1))
+		Expect(c.Baseline.FalseNegatives).To(Equal(1))
+		Expect(c.Baseline.Precision).To(BeNumerically("~", 0.5))
+		Expect(c.Baseline.Recall).To(BeNumerically("~", 0.5))
+
+		Expect(c.Candidate.TruePositives).To(Equal(2))
+		Expect(c.Candidate.Errors).To(Equal(1))
+		Expect(c.Candidate.Precision).To(BeNumerically("~", 1))
+		Expect(c.Candidate.Recall).To(BeNumerically("~", 1))
+
+		Expect(c.PrecisionDelta).To(BeNumerically("~", 0.5))
+		Expect(c.RecallDelta).To(BeNumerically("~", 0.5))
+		ids := []string{}
+		for _, d := range c.Disagreements {
+			ids = append(ids, d.ID)
+		}
+		Expect(ids).To(ConsistOf("2", "3", "4"))
+	})
+})
@@ -0,0 +1,188 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The aggregator types mirror pkg/models of procscan-aggregator, whose API is
+// described by procscan-aggregator/api/openapi.json
+
+// Violation is a process violation reported by procscan with its triage state
+type Violation struct {
+	ID        string       `json:"id"`
+	Pod       string       `json:"pod"`
+	Namespace string       `json:"namespace"`
+	Process   string       `json:"process"`
+	Cmdline   string       `json:"cmdline"`
+	Regex     string       `json:"regex"`
+	Status    string       `json:"status"`
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Timestamp string       `json:"timestamp"`
+	Node      string       `json:"node,omitempty"`
+	Triage    *TriageState `json:"triage,omitempty"`
+}
+
+// TriageState is the acknowledgement and assignment of a violation
+type TriageState struct {
+	Acknowledged   bool         `json:"acknowledged"`
+	AcknowledgedBy string       `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time   `json:"acknowledged_at,omitempty"`
+	Assignee       string       `json:"assignee,omitempty"`
+	AssignedBy     string       `json:"assigned_by,omitempty"`
+	AssignedAt     *time.Time   `json:"assigned_at,omitempty"`
+	Notes          []TriageNote `json:"notes,omitempty"`
+}
+
+// TriageNote records an acknowledgement or an assignment
+type TriageNote struct {
+	Action    string    `json:"action"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ViolationList is a page of violations
+type ViolationList struct {
+	Violations        []*Violation `json:"violations"`
+	UpdateTime        time.Time    `json:"update_time"`
+	TotalCount        int          `json:"total_count"`
+	AcknowledgedCount int          `json:"acknowledged_count"`
+	MatchedCount      int          `json:"matched_count"`
+	Offset            int          `json:"offset"`
+	// NextOffset is nil on the last page
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// TriageRequest is the body of an acknowledgement or an assignment. By
+// defaults to the user injected by the authenticating proxy.
+type TriageRequest struct {
+	By       string `json:"by,omitempty"`
+	Assignee string `json:"assignee,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// ViolationListOptions filters and pages the violations, the zero value
+// returns all unacknowledged violations
+type ViolationListOptions struct {
+	IncludeAcknowledged bool
+	Assignee            string
+	Namespace           string
+	Node                string
+	Process             string
+	Since               time.Time
+	// Limit is the page size, 0 returns all violations
+	Limit  int
+	Offset int
+}
+
+// AggregatorClient is a client of the procscan aggregator API
+type AggregatorClient struct {
+	t *transport
+}
+
+func NewAggregatorClient(cfg Config) *AggregatorClient {
+	return &AggregatorClient{t: newTransport(cfg)}
+}
+
+// ListViolations returns a page of violations sorted by namespace, pod and
+// process
+func (c *AggregatorClient) ListViolations(ctx context.Context, opts ViolationListOptions) (*ViolationList, error) {
+	query := url.Values{}
+	if opts.IncludeAcknowledged {
+		query.Set("include_acknowledged", "true")
+	}
+	for name, value := range map[string]string{
+		"assignee":  opts.Assignee,
+		"namespace": opts.Namespace,
+		"node":      opts.Node,
+		"process":   opts.Process,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var list ViolationList
+	if err := c.t.do(ctx, http.MethodGet, withQuery("/api/violations", query), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListAllViolations pages through all matching violations with pageSize
+// violations per request, Limit and Offset of opts are ignored
+func (c *AggregatorClient) ListAllViolations(ctx context.Context, opts ViolationListOptions, pageSize int) ([]*Violation, error) {
+	opts.Limit, opts.Offset = pageSize, 0
+	var all []*Violation
+	for {
+		list, err := c.ListViolations(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list.Violations...)
+		if list.NextOffset == nil {
+			return all, nil
+		}
+		opts.Offset = *list.NextOffset
+	}
+}
+
+// GetViolation returns a violation, the error satisfies IsNotFound for
+// unknown or cleared violations
+func (c *AggregatorClient) GetViolation(ctx context.Context, id string) (*Violation, error) {
+	var violation Violation
+	if err := c.t.do(ctx, http.MethodGet, "/api/violations/"+url.PathEscape(id), nil, &violation); err != nil {
+		return nil, err
+	}
+	return &violation, nil
+}
+
+// Acknowledge acknowledges a violation
+func (c *AggregatorClient) Acknowledge(ctx context.Context, id string, req TriageRequest) (*Violation, error) {
+	return c.triage(ctx, id, "ack", req)
+}
+
+// Assign assigns a violation to req.Assignee
+func (c *AggregatorClient) Assign(ctx context.Context, id string, req TriageRequest) (*Violation, error) {
+	return c.triage(ctx, id, "assign", req)
+}
+
+// Health checks that the aggregator is serving
+func (c *AggregatorClient) Health(ctx context.Context) error {
+	return c.t.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+func (c *AggregatorClient) triage(ctx context.Context, id, action string, req TriageRequest) (*Violation, error) {
+	var violation Violation
+	if err := c.t.do(ctx, http.MethodPost, "/api/violations/"+url.PathEscape(id)+"/"+action, req, &violation); err != nil {
+		return nil, err
+	}
+	return &violation, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client is a Go SDK for the HTTP APIs of CompliK: the plugin
+// management API, the records and labels API of the database plugin, the
+// whitelist API of the Lark plugin and the procscan aggregator API. Each API
+// is served on its own address with its own token, so every API has its own
+// client created from a Config.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+	DefaultRetryWait  = 500 * time.Millisecond
+	// maxRetryWait caps the exponential backoff and Retry-After
+	maxRetryWait = 30 * time.Second
+)
+
+// Config configures a client of one API
+type Config struct {
+	// BaseURL is the address the API listens on, e.g. http://complik:9091
+	BaseURL string
+	// Token is sent as a Bearer token when set
+	Token string
+	// HTTPClient defaults to a client with DefaultTimeout
+	HTTPClient *http.Client
+	// MaxRetries is the number of retries of a failed request, 0 uses
+	// DefaultMaxRetries and a negative value disables retries
+	MaxRetries int
+	// RetryWait is the wait before the first retry, doubled for each
+	// following one. It defaults to DefaultRetryWait.
+	RetryWait time.Duration
+}
+
+// Error is a non 2xx response of an API
+type Error struct {
+	StatusCode int
+	// Code is the error code of the aggregator API, empty for the others
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("api error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a 401 response
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// transport sends the requests of all clients
+type transport struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+}
+
+func newTransport(cfg Config) *transport {
+	t := &transport{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		token:      cfg.Token,
+		httpClient: cfg.HTTPClient,
+		maxRetries: cfg.MaxRetries,
+		retryWait:  cfg.RetryWait,
+	}
+	if t.httpClient == nil {
+		t.httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	switch {
+	case t.maxRetries == 0:
+		t.maxRetries = DefaultMaxRetries
+	case t.maxRetries < 0:
+		t.maxRetries = 0
+	}
+	if t.retryWait <= 0 {
+		t.retryWait = DefaultRetryWait
+	}
+	return t
+}
+
+// do sends a JSON request and decodes the JSON response into out
+func (t *transport) do(ctx context.Context, method, path string, body, out any) error {
+	resp, err := t.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends a request, retrying network errors and 429 and 5xx responses.
+// Requests that are not idempotent are only retried on 429, which the
+// server returns before doing anything. Non 2xx responses are returned as
+// *Error, the caller closes the body of the returned response.
+func (t *transport) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = data
+	}
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+
+	wait := t.retryWait
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(ctx, method, path, payload)
+		retry := false
+		switch {
+		case err != nil:
+			retry = idempotent && ctx.Err() == nil
+		case resp.StatusCode == http.StatusTooManyRequests:
+			retry = true
+		case resp.StatusCode >= 500:
+			retry = idempotent && resp.StatusCode != http.StatusNotImplemented
+		}
+		if !retry || attempt >= t.maxRetries {
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				defer resp.Body.Close()
+				return nil, readError(resp)
+			}
+			return resp, nil
+		}
+
+		delay := wait
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				delay = after
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(delay, maxRetryWait)):
+		}
+		wait *= 2
+	}
+}
+
+func (t *transport) attempt(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// readError converts an error response. The complik APIs respond with
+// {"error": "message"}, the aggregator with {"error": {"code", "message"}}.
+func readError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &envelope) == nil && len(envelope.Error) > 0 {
+		var detail struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(envelope.Error, &apiErr.Message) == nil {
+			return apiErr
+		}
+		if json.Unmarshal(envelope.Error, &detail) == nil && detail.Message != "" {
+			apiErr.Code, apiErr.Message = detail.Code, detail.Message
+			return apiErr
+		}
+	}
+	apiErr.Message = strings.TrimSpace(string(data))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// retryAfter returns the wait requested by a Retry-After header in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,359 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/client"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/scanrun"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/database/postages"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Client Suite")
+}
+
+// stubPlugin is a plugin that does nothing
+type stubPlugin struct{ name string }
+
+func (p *stubPlugin) Name() string { return p.name }
+func (p *stubPlugin) Type() string { return "discovery" }
+func (p *stubPlugin) Start(context.Context, config.PluginConfig, *eventbus.EventBus) error {
+	return nil
+}
+func (p *stubPlugin) Stop(context.Context) error { return nil }
+
+// expectMirrors checks that the JSON encoding of a server type decodes into
+// the SDK type without unknown fields, so that the mirrors do not drift
+func expectMirrors(server, mirror any) {
+	data, err := json.Marshal(server)
+	Expect(err).NotTo(HaveOccurred())
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	Expect(decoder.Decode(mirror)).To(Succeed())
+}
+
+func openDB(models ...any) *gorm.DB {
+	db, err := database.Open(database.Options{
+		Driver:     database.DriverSQLite,
+		SQLitePath: filepath.Join(GinkgoT().TempDir(), "client.db"),
+	})
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	Expect(db.AutoMigrate(models...)).To(Succeed())
+	return db
+}
+
+func fastRetries(url, token string) client.Config {
+	return client.Config{BaseURL: url, Token: token, RetryWait: time.Millisecond}
+}
+
+var _ = Describe("Client", func() {
+	ctx := context.Background()
+
+	Describe("transport", func() {
+		var (
+			calls  atomic.Int32
+			status int
+			server *httptest.Server
+		)
+
+		BeforeEach(func() {
+			calls.Store(0)
+			status = http.StatusServiceUnavailable
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if calls.Load() < 3 {
+					w.WriteHeader(status)
+					return
+				}
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer secret"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			DeferCleanup(server.Close)
+		})
+
+		It("retries idempotent requests on 5xx", func() {
+			entries, err := client.NewWhitelistClient(fastRetries(server.URL, "secret")).List(ctx, client.WhitelistListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+			Expect(calls.Load()).To(BeNumerically("==", 3))
+		})
+
+		It("does not retry POST requests on 5xx", func() {
+			_, err := client.NewWhitelistClient(fastRetries(server.URL, "secret")).
+				Create(ctx, client.WhitelistEntry{Namespace: "ns"})
+			Expect(err).To(MatchError(ContainSubstring("503")))
+			Expect(calls.Load()).To(BeNumerically("==", 1))
+		})
+
+		It("retries POST requests on 429", func() {
+			status = http.StatusTooManyRequests
+			_, err := client.NewPluginClient(fastRetries(server.URL, "secret")).Enable(ctx, "alpha")
+			// The stub returns an array, which does not decode into PluginInfo
+			Expect(err).To(MatchError(ContainSubstring("decode")))
+			Expect(calls.Load()).To(BeNumerically("==", 3))
+		})
+
+		It("gives up after MaxRetries", func() {
+			cfg := fastRetries(server.URL, "secret")
+			cfg.MaxRetries = -1
+			_, err := client.NewPluginClient(cfg).List(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(calls.Load()).To(BeNumerically("==", 1))
+		})
+	})
+
+	Describe("client.PluginClient", func() {
+		var (
+			plugins *client.PluginClient
+			baseURL string
+		)
+
+		BeforeEach(func() {
+			oldFactories := plugin.PluginFactories
+			plugin.PluginFactories = map[string]func() plugin.Plugin{
+				"alpha": func() plugin.Plugin { return &stubPlugin{name: "alpha"} },
+			}
+			DeferCleanup(func() { plugin.PluginFactories = oldFactories })
+
+			manager := plugin.NewManager(eventbus.NewEventBus(10))
+			Expect(manager.LoadPlugins([]config.PluginConfig{{Name: "alpha", Enabled: true}})).To(Succeed())
+			Expect(manager.StartAll()).To(Succeed())
+			DeferCleanup(manager.StopAll)
+
+			server := httptest.NewServer(plugin.NewAPI(logger.GetLogger(), "secret", manager))
+			DeferCleanup(server.Close)
+			baseURL = server.URL
+			plugins = client.NewPluginClient(fastRetries(baseURL, "secret"))
+		})
+
+		It("manages plugins", func() {
+			list, err := plugins.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Enabled).To(BeTrue())
+
+			info, err := plugins.Disable(ctx, "alpha")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Enabled).To(BeFalse())
+
+			info, err = plugins.Enable(ctx, "alpha")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Enabled).To(BeTrue())
+
+			info, err = plugins.SetLogLevel(ctx, "alpha", "debug")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.LogLevel).To(Equal("debug"))
+			_, err = plugins.SetLogLevel(ctx, "alpha", "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reads scan runs", func() {
+			tracker := scanrun.NewTracker(0, 0)
+			tracker.Begin(&models.ScanRunEvent{RunID: "complete-1", Source: "Complete", Total: 2})
+			tracker.Complete("complete-1", "a")
+			server := httptest.NewServer(scanrun.NewAPI(logger.GetLogger(), "secret", tracker))
+			DeferCleanup(server.Close)
+			scans := client.NewPluginClient(fastRetries(server.URL, "secret"))
+
+			runs, err := scans.ScanRuns(ctx, scanrun.StateRunning)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Percent).To(Equal(50.0))
+			run, err := scans.ScanRun(ctx, "complete-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(run.Completed).To(Equal(1))
+			_, err = scans.ScanRun(ctx, "missing")
+			Expect(client.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("returns typed errors", func() {
+			_, err := plugins.Get(ctx, "missing")
+			Expect(client.IsNotFound(err)).To(BeTrue())
+
+			_, err = client.NewPluginClient(client.Config{BaseURL: baseURL}).List(ctx)
+			Expect(client.IsUnauthorized(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("invalid token")))
+		})
+	})
+
+	Describe("client.RecordClient", func() {
+		var records *client.RecordClient
+
+		BeforeEach(func() {
+			db := openDB(&postages.DetectorRecord{}, &postages.DetectorLabel{})
+			Expect(db.Create(&postages.DetectorRecord{
+				DetectorName: "safety", Namespace: "ns-a", Host: "a.example.com", IsIllegal: true,
+			}).Error).To(Succeed())
+			server := httptest.NewServer(postages.NewLabelAPI(logger.GetLogger(), "secret", postages.NewLabelStore(db)))
+			DeferCleanup(server.Close)
+			records = client.NewRecordClient(fastRetries(server.URL, "secret"))
+		})
+
+		It("labels records and reads the metrics", func() {
+			list, err := records.List(ctx, client.RecordListOptions{Unlabeled: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Label).To(BeNil())
+
+			label, err := records.Label(ctx, list[0].ID, client.VerdictTruePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(label.Verdict).To(Equal(client.VerdictTruePositive))
+
+			record, err := records.Get(ctx, list[0].ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(record.Label).NotTo(BeNil())
+
+			accuracy, err := records.Metrics(ctx, "safety")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accuracy.Detectors).To(HaveLen(1))
+			Expect(accuracy.Detectors[0].TruePositives).To(Equal(1))
+
+			_, err = records.Get(ctx, 42)
+			Expect(client.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("downloads reports", func() {
+			report, err := records.Report(ctx, "ns-a", client.ReportOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.ContentType).To(ContainSubstring("text/html"))
+			Expect(string(report.Data)).To(ContainSubstring("ns-a"))
+
+			_, err = records.Report(ctx, "ns-a", client.ReportOptions{Format: "doc"})
+			Expect(err).To(MatchError(ContainSubstring("unknown report format")))
+		})
+
+		It("mirrors the server types", func() {
+			now := time.Now()
+			value := `["casino"]`
+			expectMirrors(postages.LabeledRecord{
+				DetectorRecord: postages.DetectorRecord{
+					ID: 1, Region: "cn", Unchanged: true, Description: "d", Keywords: &value,
+					Severity: "high", WorkloadKind: "Deployment", WorkloadName: "web", Images: &value,
+					OwnerUserID: "u", OwnerTeam: "t", WorkloadCreatedAt: &now,
+				},
+				Label: &postages.DetectorLabel{ID: 1, Comment: "c"},
+			}, &client.LabeledRecord{})
+			expectMirrors(postages.AccuracyReport{
+				Keywords: []postages.Accuracy{{Detector: "safety", Keyword: "casino"}},
+			}, &client.AccuracyReport{})
+		})
+	})
+
+	Describe("client.WhitelistClient", func() {
+		var whitelists *client.WhitelistClient
+
+		BeforeEach(func() {
+			db := openDB(&whitelist.Whitelist{})
+			service := whitelist.NewWhitelistService(db, 5*time.Second)
+			server := httptest.NewServer(whitelist.NewAPI(logger.GetLogger(), "secret", service, "cn"))
+			DeferCleanup(server.Close)
+			whitelists = client.NewWhitelistClient(fastRetries(server.URL, "secret"))
+		})
+
+		It("manages entries", func() {
+			created, err := whitelists.Create(ctx, client.WhitelistEntry{Namespace: "ns-a", Remark: "team"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Type).To(Equal(client.WhitelistTypeNamespace))
+			Expect(created.Region).To(Equal("cn"))
+			Expect(created.Name).To(Equal("ns-a"))
+
+			_, err = whitelists.Create(ctx, client.WhitelistEntry{Hostname: "a.example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			hosts, err := whitelists.List(ctx, client.WhitelistListOptions{Type: client.WhitelistTypeHost})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hosts).To(HaveLen(1))
+
+			entry, err := whitelists.Get(ctx, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entry.Remark).To(Equal("team"))
+
+			Expect(whitelists.Delete(ctx, created.ID)).To(Succeed())
+			_, err = whitelists.Get(ctx, created.ID)
+			Expect(client.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("rejects invalid entries", func() {
+			_, err := whitelists.Create(ctx, client.WhitelistEntry{Namespace: "ns", Hostname: "a.example.com"})
+			var apiErr *client.Error
+			Expect(err).To(BeAssignableToTypeOf(apiErr))
+			Expect(err.(*client.Error).StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("mirrors the server type", func() {
+			expectMirrors(whitelist.Whitelist{ID: 1, Type: whitelist.WhitelistTypeHost}, &client.Whitelist{})
+		})
+	})
+
+	Describe("AggregatorClient", func() {
+		It("pages through violations and reads the error envelope", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/api/violations":
+					if r.URL.Query().Get("offset") == "" {
+						_, _ = w.Write([]byte(`{"violations":[{"id":"pv-1","pod":"a","namespace":"ns",` +
+							`"process":"xmrig"}],"total_count":1,"matched_count":2,"offset":0,"next_offset":1}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"violations":[{"id":"pv-2","pod":"b","namespace":"ns",` +
+						`"process":"xmrig","triage":{"acknowledged":true}}],"total_count":1,"matched_count":2,"offset":1}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"violation not found"}}`))
+				}
+			}))
+			DeferCleanup(server.Close)
+			aggregator := client.NewAggregatorClient(fastRetries(server.URL, ""))
+
+			all, err := aggregator.ListAllViolations(ctx, client.ViolationListOptions{Namespace: "ns"}, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(all).To(HaveLen(2))
+			Expect(all[1].Triage.Acknowledged).To(BeTrue())
+
+			_, err = aggregator.GetViolation(ctx, "pv-3")
+			Expect(client.IsNotFound(err)).To(BeTrue())
+			Expect(err.(*client.Error).Code).To(Equal("not_found"))
+			Expect(err.(*client.Error).Message).To(Equal("violation not found"))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/scanrun"
+)
+
+// PluginClient is a client of the plugin management and scan run APIs served
+// on pluginApi.addr
+type PluginClient struct {
+	t *transport
+}
+
+func NewPluginClient(cfg Config) *PluginClient {
+	return &PluginClient{t: newTransport(cfg)}
+}
+
+// List returns all loaded plugins
+func (c *PluginClient) List(ctx context.Context) ([]plugin.PluginInfo, error) {
+	var plugins []plugin.PluginInfo
+	if err := c.t.do(ctx, http.MethodGet, "/api/v1/plugins", nil, &plugins); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
+
+// Get returns a plugin, the error satisfies IsNotFound for unknown plugins
+func (c *PluginClient) Get(ctx context.Context, name string) (*plugin.PluginInfo, error) {
+	return c.info(ctx, http.MethodGet, "/api/v1/plugins/"+url.PathEscape(name), nil)
+}
+
+// Enable starts a disabled plugin
+func (c *PluginClient) Enable(ctx context.Context, name string) (*plugin.PluginInfo, error) {
+	return c.info(ctx, http.MethodPost, "/api/v1/plugins/"+url.PathEscape(name)+"/enable", nil)
+}
+
+// Disable stops a plugin
+func (c *PluginClient) Disable(ctx context.Context, name string) (*plugin.PluginInfo, error) {
+	return c.info(ctx, http.MethodPost, "/api/v1/plugins/"+url.PathEscape(name)+"/disable", nil)
+}
+
+// Restart stops a plugin and starts a fresh instance of it
+func (c *PluginClient) Restart(ctx context.Context, name string) (*plugin.PluginInfo, error) {
+	return c.info(ctx, http.MethodPost, "/api/v1/plugins/"+url.PathEscape(name)+"/restart", nil)
+}
+
+// SetLogLevel changes the log level of a plugin, an empty level resets it to
+// the global level
+func (c *PluginClient) SetLogLevel(ctx context.Context, name, level string) (*plugin.PluginInfo, error) {
+	body := map[string]string{"level": level}
+	return c.info(ctx, http.MethodPut, "/api/v1/plugins/"+url.PathEscape(name)+"/log-level", body)
+}
+
+// ScanRuns returns the scan runs, newest first. A non-empty state such as
+// scanrun.StateRunning filters them.
+func (c *PluginClient) ScanRuns(ctx context.Context, state string) ([]scanrun.Run, error) {
+	query := url.Values{}
+	if state != "" {
+		query.Set("state", state)
+	}
+	var runs []scanrun.Run
+	if err := c.t.do(ctx, http.MethodGet, withQuery("/api/v1/scans", query), nil, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// ScanRun returns a scan run, the error satisfies IsNotFound for unknown runs
+func (c *PluginClient) ScanRun(ctx context.Context, id string) (*scanrun.Run, error) {
+	var run scanrun.Run
+	if err := c.t.do(ctx, http.MethodGet, "/api/v1/scans/"+url.PathEscape(id), nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (c *PluginClient) info(ctx context.Context, method, path string, body any) (*plugin.PluginInfo, error) {
+	var info plugin.PluginInfo
+	if err := c.t.do(ctx, method, path, body, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
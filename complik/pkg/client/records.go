@@ -0,0 +1,220 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Label verdicts, the same as those of the database plugin
+const (
+	VerdictTruePositive  = "true_positive"
+	VerdictFalsePositive = "false_positive"
+	VerdictTrueNegative  = "true_negative"
+	VerdictFalseNegative = "false_negative"
+)
+
+// Record is a detector decision stored by the database plugin (mirrors
+// postages.DetectorRecord). Path, Keywords and Images hold JSON arrays.
+type Record struct {
+	ID                uint       `json:"id"`
+	DiscoveryName     string     `json:"discovery_name"`
+	CollectorName     string     `json:"collector_name"`
+	DetectorName      string     `json:"detector_name"`
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	Region            string     `json:"region,omitempty"`
+	Host              string     `json:"host"`
+	Path              *string    `json:"path"`
+	URL               string     `json:"url"`
+	IsIllegal         bool       `json:"is_illegal"`
+	Unchanged         bool       `json:"unchanged,omitempty"`
+	Description       string     `json:"description,omitempty"`
+	Keywords          *string    `json:"keywords,omitempty"`
+	Severity          string     `json:"severity,omitempty"`
+	WorkloadKind      string     `json:"workload_kind,omitempty"`
+	WorkloadName      string     `json:"workload_name,omitempty"`
+	Images            *string    `json:"images,omitempty"`
+	OwnerUserID       string     `json:"owner_user_id,omitempty"`
+	OwnerTeam         string     `json:"owner_team,omitempty"`
+	Redactions        int        `json:"redactions,omitempty"`
+	ContentHash       string     `json:"content_hash,omitempty"`
+	Occurrences       int        `json:"occurrences"`
+	WorkloadCreatedAt *time.Time `json:"workload_created_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Label is the human verdict of a record (mirrors postages.DetectorLabel)
+type Label struct {
+	ID        uint      `json:"id"`
+	RecordID  uint      `json:"record_id"`
+	Verdict   string    `json:"verdict"`
+	Reviewer  string    `json:"reviewer"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LabeledRecord is a record with its label, nil until it is labeled
+type LabeledRecord struct {
+	Record
+	Label *Label `json:"label,omitempty"`
+}
+
+// Accuracy is the labeled accuracy of a detector or of one of its keywords
+type Accuracy struct {
+	Detector       string  `json:"detector"`
+	Keyword        string  `json:"keyword,omitempty"`
+	Labeled        int     `json:"labeled"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// AccuracyReport is the accuracy of all labeled detectors and keywords
+type AccuracyReport struct {
+	Detectors []Accuracy `json:"detectors"`
+	Keywords  []Accuracy `json:"keywords"`
+}
+
+// RecordListOptions filters the records returned by List. The server
+// returns 50 records when Limit is 0 and at most 500.
+type RecordListOptions struct {
+	Detector  string
+	Unlabeled bool
+	Limit     int
+	Offset    int
+}
+
+// ReportOptions selects the compliance report. Format is "html" (the
+// default) or "pdf", From and To default to the last 30 days.
+type ReportOptions struct {
+	Format string
+	From   time.Time
+	To     time.Time
+}
+
+// Report is a rendered compliance report
+type Report struct {
+	ContentType string
+	Data        []byte
+}
+
+// RecordClient is a client of the records and labels API served on
+// labelApiAddr
+type RecordClient struct {
+	t *transport
+}
+
+func NewRecordClient(cfg Config) *RecordClient {
+	return &RecordClient{t: newTransport(cfg)}
+}
+
+// List returns records, newest first
+func (c *RecordClient) List(ctx context.Context, opts RecordListOptions) ([]LabeledRecord, error) {
+	query := url.Values{}
+	if opts.Detector != "" {
+		query.Set("detector", opts.Detector)
+	}
+	if opts.Unlabeled {
+		query.Set("unlabeled", "true")
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var records []LabeledRecord
+	if err := c.t.do(ctx, http.MethodGet, withQuery("/api/v1/records", query), nil, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Get returns a record, the error satisfies IsNotFound for unknown records
+func (c *RecordClient) Get(ctx context.Context, id uint) (*LabeledRecord, error) {
+	var record LabeledRecord
+	if err := c.t.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/records/%d", id), nil, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Label sets the verdict of a record, replacing a previous label
+func (c *RecordClient) Label(ctx context.Context, id uint, verdict, reviewer, comment string) (*Label, error) {
+	body := map[string]string{"verdict": verdict, "reviewer": reviewer, "comment": comment}
+	var label Label
+	if err := c.t.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/records/%d/label", id), body, &label); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// Metrics returns the accuracy computed from the labels, of one detector
+// when detector is set
+func (c *RecordClient) Metrics(ctx context.Context, detector string) (*AccuracyReport, error) {
+	query := url.Values{}
+	if detector != "" {
+		query.Set("detector", detector)
+	}
+	var accuracy AccuracyReport
+	if err := c.t.do(ctx, http.MethodGet, withQuery("/api/v1/labels/metrics", query), nil, &accuracy); err != nil {
+		return nil, err
+	}
+	return &accuracy, nil
+}
+
+// Report renders the compliance report of a namespace
+func (c *RecordClient) Report(ctx context.Context, namespace string, opts ReportOptions) (*Report, error) {
+	query := url.Values{}
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339))
+	}
+	resp, err := c.t.send(ctx, http.MethodGet, withQuery("/api/v1/reports/"+url.PathEscape(namespace), query), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	return &Report{ContentType: resp.Header.Get("Content-Type"), Data: data}, nil
+}
+
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Whitelist entry types
+const (
+	WhitelistTypeNamespace = "namespace"
+	WhitelistTypeHost      = "host"
+)
+
+// Whitelist is an entry that exempts a namespace or a host from alerts
+// (mirrors whitelist.Whitelist of the Lark plugin)
+type Whitelist struct {
+	ID        uint      `json:"id"`
+	Region    string    `json:"region"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Hostname  string    `json:"hostname"`
+	Type      string    `json:"type"`
+	Remark    string    `json:"remark"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WhitelistEntry is a new entry, exactly one of Namespace and Hostname is
+// set. Name defaults to the namespace or hostname.
+type WhitelistEntry struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	Remark    string `json:"remark,omitempty"`
+}
+
+// WhitelistListOptions filters the entries returned by List, Search takes
+// precedence over Type
+type WhitelistListOptions struct {
+	Type   string
+	Search string
+}
+
+// WhitelistClient is a client of the whitelist API served on
+// whitelistApiAddr
+type WhitelistClient struct {
+	t *transport
+}
+
+func NewWhitelistClient(cfg Config) *WhitelistClient {
+	return &WhitelistClient{t: newTransport(cfg)}
+}
+
+// List returns the whitelist entries
+func (c *WhitelistClient) List(ctx context.Context, opts WhitelistListOptions) ([]Whitelist, error) {
+	query := url.Values{}
+	if opts.Type != "" {
+		query.Set("type", opts.Type)
+	}
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	var entries []Whitelist
+	if err := c.t.do(ctx, http.MethodGet, withQuery("/api/v1/whitelists", query), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Create adds an entry in the region of the server
+func (c *WhitelistClient) Create(ctx context.Context, entry WhitelistEntry) (*Whitelist, error) {
+	var created Whitelist
+	if err := c.t.do(ctx, http.MethodPost, "/api/v1/whitelists", entry, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Get returns an entry, the error satisfies IsNotFound for unknown entries
+func (c *WhitelistClient) Get(ctx context.Context, id uint) (*Whitelist, error) {
+	var entry Whitelist
+	if err := c.t.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/whitelists/%d", id), nil, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Delete removes an entry
+func (c *WhitelistClient) Delete(ctx context.Context, id uint) error {
+	return c.t.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/whitelists/%d", id), nil, nil)
+}
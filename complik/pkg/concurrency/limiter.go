@@ -0,0 +1,209 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package concurrency bounds the reviews a detector plugin runs at once. The
+// adaptive limiter follows AIMD: every fast, successful review raises the
+// limit by 1/limit, so it grows by about one worker per round of reviews, and
+// a failed or slow review cuts it by the backoff factor.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Config is the concurrency section of a detector plugin configuration
+type Config struct {
+	// Adaptive tunes the limit between MinWorkers and MaxWorkers from the
+	// observed review latency and errors; otherwise MaxWorkers is fixed
+	Adaptive   bool `json:"adaptive"`
+	MinWorkers int  `json:"minWorkers"`
+	// MaxWorkers defaults to the maxWorkers of the plugin
+	MaxWorkers     int `json:"maxWorkers"`
+	InitialWorkers int `json:"initialWorkers"`
+	// Reviews slower than TargetLatencySecond count as overload
+	TargetLatencySecond int     `json:"targetLatencySecond"`
+	Backoff             float64 `json:"backoff"`
+}
+
+// WithDefaults fills the unset values of c; maxWorkers is the static worker
+// count of the plugin
+func (c Config) WithDefaults(maxWorkers int) Config {
+	if c.MaxWorkers <= 0 {
+		c.MaxWorkers = maxWorkers
+	}
+	if c.MaxWorkers <= 0 {
+		c.MaxWorkers = 1
+	}
+	if c.MinWorkers <= 0 {
+		c.MinWorkers = 1
+	}
+	c.MinWorkers = min(c.MinWorkers, c.MaxWorkers)
+	if c.InitialWorkers <= 0 {
+		c.InitialWorkers = max(c.MinWorkers, c.MaxWorkers/2)
+	}
+	c.InitialWorkers = min(max(c.InitialWorkers, c.MinWorkers), c.MaxWorkers)
+	if c.TargetLatencySecond <= 0 {
+		c.TargetLatencySecond = 30
+	}
+	if c.Backoff <= 0 || c.Backoff >= 1 {
+		c.Backoff = 0.7
+	}
+	return c
+}
+
+// Stats is a snapshot of a limiter
+type Stats struct {
+	Limit     int   `json:"limit"`
+	InFlight  int   `json:"in_flight"`
+	Increases int64 `json:"increases"`
+	Decreases int64 `json:"decreases"`
+}
+
+// Token is a slot acquired from a limiter
+type Token struct {
+	started    time.Time
+	generation uint64
+}
+
+// Limiter hands out review slots. It is safe for concurrent use.
+type Limiter struct {
+	log    logger.Logger
+	cfg    Config
+	target time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	// generation counts the decreases. Reviews started before the last
+	// decrease ran under the old limit and must not cut it again.
+	generation uint64
+	increases  int64
+	decreases  int64
+	// changed is closed and replaced whenever a slot may have become free
+	changed chan struct{}
+}
+
+// NewLimiter creates a limiter for cfg, which must have its defaults filled
+func NewLimiter(log logger.Logger, cfg Config) *Limiter {
+	limit := cfg.MaxWorkers
+	if cfg.Adaptive {
+		limit = cfg.InitialWorkers
+	}
+	return &Limiter{
+		log:     log,
+		cfg:     cfg,
+		target:  time.Duration(cfg.TargetLatencySecond) * time.Second,
+		now:     time.Now,
+		limit:   float64(limit),
+		changed: make(chan struct{}),
+	}
+}
+
+// Acquire blocks until a slot is free or ctx is done
+func (l *Limiter) Acquire(ctx context.Context) (Token, error) {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.current() {
+			l.inFlight++
+			l.mu.Unlock()
+			return Token{started: l.now(), generation: l.generation}, nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Token{}, ctx.Err()
+		}
+	}
+}
+
+// Release frees the slot of token and adjusts the limit from the outcome of
+// the review it guarded. err is the review error; cancellations are not held
+// against the limit.
+func (l *Limiter) Release(token Token, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	defer l.broadcast()
+	if !l.cfg.Adaptive || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	latency := l.now().Sub(token.started)
+	before := l.current()
+	overloaded := err != nil || latency > l.target
+	switch {
+	case !overloaded:
+		l.limit = math.Min(l.limit+1/l.limit, float64(l.cfg.MaxWorkers))
+		if l.current() > before {
+			l.increases++
+			l.log.Debug("Detector concurrency increased", logger.Fields{"limit": l.current()})
+		}
+	case token.generation == l.generation:
+		l.limit = math.Max(l.limit*l.cfg.Backoff, float64(l.cfg.MinWorkers))
+		l.generation++
+		if l.current() < before {
+			l.decreases++
+			fields := logger.Fields{"limit": l.current(), "latency_ms": latency.Milliseconds()}
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+			l.log.Info("Detector concurrency decreased", fields)
+		}
+	}
+}
+
+// Wait blocks until no slot is in use
+func (l *Limiter) Wait() {
+	for {
+		l.mu.Lock()
+		if l.inFlight == 0 {
+			l.mu.Unlock()
+			return
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		<-changed
+	}
+}
+
+// Stats returns the current limit and usage
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{
+		Limit:     l.current(),
+		InFlight:  l.inFlight,
+		Increases: l.increases,
+		Decreases: l.decreases,
+	}
+}
+
+func (l *Limiter) current() int {
+	// The additive steps accumulate rounding errors
+	return int(l.limit + 1e-9)
+}
+
+func (l *Limiter) broadcast() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
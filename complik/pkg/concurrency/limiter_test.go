@@ -0,0 +1,140 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConcurrency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Concurrency Suite")
+}
+
+var _ = Describe("Limiter", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	adaptive := func(cfg Config) *Limiter {
+		cfg.Adaptive = true
+		l := NewLimiter(logger.GetLogger(), cfg.WithDefaults(10))
+		l.now = func() time.Time { return now }
+		return l
+	}
+
+	// review acquires a slot and releases it after latency
+	review := func(l *Limiter, latency time.Duration, err error) {
+		token, acquireErr := l.Acquire(context.Background())
+		Expect(acquireErr).NotTo(HaveOccurred())
+		now = now.Add(latency)
+		l.Release(token, err)
+	}
+
+	It("should fill defaults from the plugin worker count", func() {
+		cfg := Config{}.WithDefaults(20)
+		Expect(cfg).To(Equal(Config{
+			MinWorkers: 1, MaxWorkers: 20, InitialWorkers: 10, TargetLatencySecond: 30, Backoff: 0.7,
+		}))
+		cfg = Config{MinWorkers: 8, InitialWorkers: 50, Backoff: 3}.WithDefaults(4)
+		Expect(cfg.MinWorkers).To(Equal(4))
+		Expect(cfg.InitialWorkers).To(Equal(4))
+		Expect(cfg.Backoff).To(Equal(0.7))
+	})
+
+	It("should keep a static limit", func() {
+		l := NewLimiter(logger.GetLogger(), Config{}.WithDefaults(3))
+		for range 5 {
+			review(l, time.Minute, errors.New("timeout"))
+		}
+		Expect(l.Stats().Limit).To(Equal(3))
+	})
+
+	It("should grow by about one worker per round of fast reviews", func() {
+		l := adaptive(Config{InitialWorkers: 4})
+		for range 4 {
+			review(l, time.Second, nil)
+		}
+		Expect(l.Stats().Limit).To(Equal(4))
+		for range 6 {
+			review(l, time.Second, nil)
+		}
+		Expect(l.Stats().Limit).To(BeNumerically(">=", 5))
+		for range 200 {
+			review(l, time.Second, nil)
+		}
+		Expect(l.Stats()).To(Equal(Stats{Limit: 10, Increases: 6}))
+	})
+
+	It("should back off on errors and slow reviews down to the minimum", func() {
+		l := adaptive(Config{MinWorkers: 2})
+		review(l, time.Second, errors.New("HTTP 429"))
+		Expect(l.Stats().Limit).To(Equal(3))
+		review(l, time.Minute, nil)
+		Expect(l.Stats().Limit).To(Equal(2))
+		review(l, time.Minute, nil)
+		Expect(l.Stats().Limit).To(Equal(2))
+		review(l, time.Second, context.Canceled)
+		Expect(l.Stats().Decreases).To(Equal(int64(2)))
+	})
+
+	It("should cut the limit once for reviews running during a decrease", func() {
+		l := adaptive(Config{InitialWorkers: 10})
+		var tokens []Token
+		for range 5 {
+			token, err := l.Acquire(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			tokens = append(tokens, token)
+		}
+		for _, token := range tokens {
+			l.Release(token, errors.New("HTTP 503"))
+		}
+		Expect(l.Stats()).To(Equal(Stats{Limit: 7, Decreases: 1}))
+	})
+
+	It("should block at the limit until a slot is released", func() {
+		l := adaptive(Config{InitialWorkers: 1, MaxWorkers: 1})
+		token, err := l.Acquire(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = l.Acquire(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		acquired := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			next, err := l.Acquire(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			close(acquired)
+			l.Release(next, nil)
+		}()
+		Consistently(acquired, 20*time.Millisecond).ShouldNot(BeClosed())
+		l.Release(token, nil)
+		Eventually(acquired).Should(BeClosed())
+		l.Wait()
+		Expect(l.Stats().InFlight).To(BeZero())
+	})
+})
@@ -22,17 +22,31 @@ const (
 	DiscoveryInformerStatefulSetName     = "StatefulSet"
 	DiscoveryInformerEndPointSliceName   = "Endpointslice"
 	DiscoveryInformerServiceNodePortName = "NodePort"
+	DiscoveryInformerLoadBalancerName    = "LoadBalancer"
 	DiscoveryInformerIngressName         = "Ingress"
+	DiscoveryInformerDevboxName          = "DevboxInformer"
+	DiscoveryInformerCustomResourceName  = "CustomResource"
 )
 
 const (
 	ComplianceCollectorHigressName = "Higress"
 	ComplianceCollectorBrowserName = "Browser"
+	ComplianceCollectorTCPName     = "TCP"
 	ComplianceDetectorCustom       = "Custom"
 	ComplianceDetectorSafety       = "Safety"
+	ComplianceDetectorSecrets      = "Secrets"
+	ComplianceDetectorServices     = "Services"
+	ComplianceCorrelation          = "Correlation"
+	ComplianceWatchdog             = "Watchdog"
 )
 
 const (
 	HandleDatabasePostgres = "Postgres"
 	HandleLark             = "Lark"
+	HandleSealos           = "Sealos"
+	HandleElasticsearch    = "Elasticsearch"
+	HandleSyslog           = "Syslog"
+	HandleScanSummary      = "ScanSummary"
+	HandleBlockPage        = "BlockPage"
+	HandleQuarantine       = "Quarantine"
 )
@@ -20,12 +20,23 @@ const (
 )
 
 const (
-	ComplianceCollectorPluginType = "Compliance.Collector"
-	ComplianceDetectorPluginType  = "Compliance.Detector"
-	ComplianceHigressPluginType   = "Higress"
+	ComplianceCollectorPluginType   = "Compliance.Collector"
+	ComplianceDetectorPluginType    = "Compliance.Detector"
+	ComplianceHigressPluginType     = "Higress"
+	ComplianceCorrelationPluginType = "Compliance.Correlation"
+	ComplianceWatchdogPluginType    = "Compliance.Watchdog"
 )
 
 const (
-	HandleDatabasePluginType = "Handle.Database"
-	HandleLarkPluginType     = "Handle.Lark"
+	HandleDatabasePluginType      = "Handle.Database"
+	HandleLarkPluginType          = "Handle.Lark"
+	HandleSealosPluginType        = "Handle.Sealos"
+	HandleElasticsearchPluginType = "Handle.Elasticsearch"
+	HandleSyslogPluginType        = "Handle.Syslog"
+	HandleScanSummaryPluginType   = "Handle.ScanSummary"
+	HandleBlockPagePluginType     = "Handle.BlockPage"
+	HandleQuarantinePluginType    = "Handle.Quarantine"
+
+	// HandlePluginTypePrefix is shared by all handler plugin types
+	HandlePluginTypePrefix = "Handle."
 )
@@ -25,3 +25,40 @@ const (
 const (
 	DetectorTopic = "detector"
 )
+
+const (
+	// MiningTopic carries *models.MiningInfo from mining detectors
+	MiningTopic = "mining"
+)
+
+const (
+	// ServiceTopic carries *models.ServiceInfo from the TCP collector
+	ServiceTopic = "service"
+)
+
+const (
+	// CorrelationTopic carries *models.Incident from the correlation plugin
+	CorrelationTopic = "correlation"
+)
+
+const (
+	// HealthTopic carries the probe events of the health server
+	HealthTopic = "health"
+)
+
+const (
+	// ScanRunTopic carries *models.ScanRunEvent from the discovery plugins
+	// starting a scan run and from collectors failing a target of one
+	ScanRunTopic = "scanrun"
+)
+
+const (
+	// ScanSummaryTopic carries *models.ScanSummary after a scan run finished
+	ScanSummaryTopic = "scansummary"
+)
+
+const (
+	// AppealTopic carries *models.AppealEvent when a tenant appeals a
+	// violation record and when a reviewer decides the appeal
+	AppealTopic = "appeal"
+)
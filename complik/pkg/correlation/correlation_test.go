@@ -0,0 +1,141 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCorrelation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Correlation Suite")
+}
+
+var _ = Describe("Correlation", func() {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	website := func(at time.Time) models.Finding {
+		f, ok := FromDetector(&models.DetectorInfo{
+			DetectorName: "safety",
+			Namespace:    "ns-a",
+			Host:         "casino.example.com",
+			IsIllegal:    true,
+			Keywords:     []string{"casino"},
+		}, at)
+		Expect(ok).To(BeTrue())
+		return f
+	}
+	mining := func(at time.Time) models.Finding {
+		f, ok := FromMining(&models.MiningInfo{
+			Namespace: "ns-a",
+			PodName:   "web-1",
+			NodeName:  "node-1",
+			Command:   "xmrig",
+		}, at)
+		Expect(ok).To(BeTrue())
+		return f
+	}
+
+	Describe("Correlator", func() {
+		var correlator *Correlator
+
+		BeforeEach(func() {
+			correlator = NewCorrelator(time.Hour, 2)
+		})
+
+		It("should report a namespace once findings span two pipelines", func() {
+			_, ok := correlator.Add(website(start))
+			Expect(ok).To(BeFalse())
+
+			incident, ok := correlator.Add(mining(start.Add(10 * time.Minute)))
+			Expect(ok).To(BeTrue())
+			Expect(incident.Namespace).To(Equal("ns-a"))
+			Expect(incident.Sources).To(Equal([]string{models.SourceMining, models.SourceWebsite}))
+			Expect(incident.Findings).To(HaveLen(2))
+			Expect(incident.Severity).To(Equal(models.SeverityCritical))
+			Expect(incident.FirstSeen).To(Equal(start))
+		})
+
+		It("should not report the same incident again until it changes", func() {
+			correlator.Add(website(start))
+			_, ok := correlator.Add(mining(start.Add(time.Minute)))
+			Expect(ok).To(BeTrue())
+			_, ok = correlator.Add(mining(start.Add(2 * time.Minute)))
+			Expect(ok).To(BeFalse())
+
+			procscan, _ := FromProcessViolation(&ProcessViolation{
+				Namespace: "ns-a", Pod: "web-1", Process: "xmrig", Regex: "xmr",
+			}, "cn-beijing", start.Add(3*time.Minute))
+			incident, ok := correlator.Add(procscan)
+			Expect(ok).To(BeTrue())
+			Expect(incident.Sources).To(HaveLen(3))
+			Expect(incident.Region).To(Equal("cn-beijing"))
+		})
+
+		It("should only correlate findings within the window", func() {
+			correlator.Add(website(start))
+			_, ok := correlator.Add(mining(start.Add(2 * time.Hour)))
+			Expect(ok).To(BeFalse())
+
+			incident, ok := correlator.Add(website(start.Add(150 * time.Minute)))
+			Expect(ok).To(BeTrue())
+			Expect(incident.FirstSeen).To(Equal(start.Add(2 * time.Hour)))
+		})
+
+		It("should close incidents without recent findings", func() {
+			correlator.Add(website(start))
+			Expect(correlator.Expire(start.Add(30 * time.Minute))).To(BeZero())
+			Expect(correlator.Expire(start.Add(2 * time.Hour))).To(Equal(1))
+			Expect(correlator.Open()).To(BeZero())
+		})
+	})
+
+	Describe("CombinedSeverity", func() {
+		It("should raise the highest severity for multi-pipeline findings", func() {
+			low := models.Finding{Source: models.SourceWebsite, Severity: models.SeverityLow}
+			medium := models.Finding{Source: models.SourceProcscan, Severity: models.SeverityMedium}
+			Expect(CombinedSeverity([]models.Finding{low})).To(Equal(models.SeverityLow))
+			Expect(CombinedSeverity([]models.Finding{low, medium})).To(Equal(models.SeverityHigh))
+		})
+	})
+
+	Describe("FromDetector", func() {
+		It("should ignore legal results", func() {
+			_, ok := FromDetector(&models.DetectorInfo{Namespace: "ns-a"}, start)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("ProcscanClient", func() {
+		It("should read violations from the aggregator", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"violations":[{"pod":"web-1","namespace":"ns-a","process":"xmrig"}],"total_count":1}`))
+			}))
+			defer server.Close()
+
+			violations, err := NewProcscanClient(server.URL, time.Second).FetchViolations(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Process).To(Equal("xmrig"))
+		})
+	})
+})
@@ -0,0 +1,189 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package correlation joins the findings of the website, mining and procscan
+// pipelines by namespace within a time window, so a namespace that is both
+// mining and hosting illegal pages surfaces as a single incident.
+package correlation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// maxFindingsPerIncident bounds the findings kept for a single namespace
+const maxFindingsPerIncident = 50
+
+type openIncident struct {
+	incident  models.Incident
+	published string
+}
+
+// Correlator keeps one open incident per namespace. Findings older than the
+// window are dropped from an incident, and an incident without findings in
+// the window is closed.
+type Correlator struct {
+	window     time.Duration
+	minSources int
+
+	mu        sync.Mutex
+	incidents map[string]*openIncident
+}
+
+// NewCorrelator builds a correlator that reports incidents with findings from
+// at least minSources pipelines
+func NewCorrelator(window time.Duration, minSources int) *Correlator {
+	if minSources <= 0 {
+		minSources = 2
+	}
+	return &Correlator{
+		window:     window,
+		minSources: minSources,
+		incidents:  make(map[string]*openIncident),
+	}
+}
+
+// Add merges finding into the incident of its namespace. It returns the
+// incident when it spans at least minSources pipelines and its sources or
+// severity changed since it was last returned.
+func (c *Correlator) Add(finding models.Finding) (models.Incident, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	open := c.incidents[finding.Namespace]
+	if open != nil {
+		open.incident.Findings = c.inWindow(open.incident.Findings, finding.ObservedAt)
+		if len(open.incident.Findings) == 0 {
+			open = nil
+		}
+	}
+	if open == nil {
+		open = &openIncident{incident: models.Incident{
+			ID:        fmt.Sprintf("%s-%d", finding.Namespace, finding.ObservedAt.Unix()),
+			Namespace: finding.Namespace,
+			FirstSeen: finding.ObservedAt,
+		}}
+		c.incidents[finding.Namespace] = open
+	}
+
+	incident := &open.incident
+	replaced := false
+	for i := range incident.Findings {
+		if incident.Findings[i].Source == finding.Source && incident.Findings[i].Key == finding.Key {
+			incident.Findings[i] = finding
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if len(incident.Findings) >= maxFindingsPerIncident {
+			incident.Findings = incident.Findings[1:]
+		}
+		incident.Findings = append(incident.Findings, finding)
+	}
+	if finding.Region != "" {
+		incident.Region = finding.Region
+	}
+	if finding.ObservedAt.After(incident.LastSeen) {
+		incident.LastSeen = finding.ObservedAt
+	}
+	incident.Sources = sources(incident.Findings)
+	incident.Severity = CombinedSeverity(incident.Findings)
+
+	if len(incident.Sources) < c.minSources {
+		return models.Incident{}, false
+	}
+	signature := fmt.Sprintf("%v|%s", incident.Sources, incident.Severity)
+	if signature == open.published {
+		return models.Incident{}, false
+	}
+	open.published = signature
+	snapshot := *incident
+	snapshot.Sources = append([]string(nil), incident.Sources...)
+	snapshot.Findings = append([]models.Finding(nil), incident.Findings...)
+	return snapshot, true
+}
+
+// Expire closes the incidents without findings in the window ending at now
+// and returns how many were closed
+func (c *Correlator) Expire(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	closed := 0
+	for namespace, open := range c.incidents {
+		if now.Sub(open.incident.LastSeen) > c.window {
+			delete(c.incidents, namespace)
+			closed++
+		}
+	}
+	return closed
+}
+
+// Open returns the number of open incidents
+func (c *Correlator) Open() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.incidents)
+}
+
+func (c *Correlator) inWindow(findings []models.Finding, now time.Time) []models.Finding {
+	kept := findings[:0]
+	for _, f := range findings {
+		if now.Sub(f.ObservedAt) <= c.window {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func sources(findings []models.Finding) []string {
+	seen := make(map[string]struct{})
+	for _, f := range findings {
+		seen[f.Source] = struct{}{}
+	}
+	result := make([]string, 0, len(seen))
+	for source := range seen {
+		result = append(result, source)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CombinedSeverity returns the highest severity of findings, raised one level
+// when the findings come from more than one pipeline
+func CombinedSeverity(findings []models.Finding) string {
+	highest := 0
+	for _, f := range findings {
+		if rank := models.SeverityRank(f.Severity); rank > highest {
+			highest = rank
+		}
+	}
+	if len(sources(findings)) > 1 && highest < models.SeverityRank(models.SeverityCritical) {
+		highest++
+	}
+	switch highest {
+	case 4:
+		return models.SeverityCritical
+	case 3:
+		return models.SeverityHigh
+	case 2:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
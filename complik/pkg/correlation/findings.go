@@ -0,0 +1,89 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+)
+
+// FromDetector converts an illegal website detection into a finding
+func FromDetector(result *models.DetectorInfo, now time.Time) (models.Finding, bool) {
+	if result == nil || !result.IsIllegal || result.Namespace == "" {
+		return models.Finding{}, false
+	}
+	summary := fmt.Sprintf("%s flagged %s", result.DetectorName, result.Host)
+	if len(result.Keywords) > 0 {
+		summary += " (" + strings.Join(result.Keywords, ", ") + ")"
+	} else if result.Description != "" {
+		summary += ": " + result.Description
+	}
+	return models.Finding{
+		Source:     models.SourceWebsite,
+		Key:        result.DetectorName + "/" + result.Host,
+		Region:     result.Region,
+		Namespace:  result.Namespace,
+		Summary:    summary,
+		Severity:   routing.EffectiveSeverity(result),
+		ObservedAt: now,
+	}, true
+}
+
+// FromMining converts a mining detection into a finding
+func FromMining(info *models.MiningInfo, now time.Time) (models.Finding, bool) {
+	if info == nil || info.Namespace == "" {
+		return models.Finding{}, false
+	}
+	summary := fmt.Sprintf("mining process `%s` in pod %s on node %s", info.Command, info.PodName, info.NodeName)
+	if len(info.Wallets) > 0 {
+		summary += " to wallet " + strings.Join(info.Wallets, ", ")
+	}
+	if len(info.SharedWith) > 0 {
+		summary += ", also mined to from " + strings.Join(info.SharedWith, ", ")
+	}
+	severity := info.Severity
+	if severity == "" {
+		severity = models.SeverityHigh
+	}
+	return models.Finding{
+		Source:     models.SourceMining,
+		Key:        info.PodName + "/" + info.Command,
+		Region:     info.Region,
+		Namespace:  info.Namespace,
+		Summary:    summary,
+		Severity:   severity,
+		ObservedAt: now,
+	}, true
+}
+
+// FromProcessViolation converts a procscan violation into a finding
+func FromProcessViolation(record *ProcessViolation, region string, now time.Time) (models.Finding, bool) {
+	if record == nil || record.Namespace == "" {
+		return models.Finding{}, false
+	}
+	return models.Finding{
+		Source:     models.SourceProcscan,
+		Key:        record.Pod + "/" + record.Process,
+		Region:     region,
+		Namespace:  record.Namespace,
+		Summary:    fmt.Sprintf("process `%s` in pod %s matched rule `%s`", record.Process, record.Pod, record.Regex),
+		Severity:   models.SeverityHigh,
+		ObservedAt: now,
+	}, true
+}
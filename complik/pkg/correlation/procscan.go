@@ -0,0 +1,75 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ProcessViolation is a violation reported by the procscan aggregator (kept
+// in sync with the aggregator's ViolationRecord)
+type ProcessViolation struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Process   string `json:"process"`
+	Cmdline   string `json:"cmdline"`
+	Regex     string `json:"regex"`
+	Status    string `json:"status"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ProcscanClient reads the active violations from the procscan aggregator
+type ProcscanClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewProcscanClient builds a client for the aggregator violations endpoint,
+// e.g. http://procscan-aggregator:8080/api/violations
+func NewProcscanClient(url string, timeout time.Duration) *ProcscanClient {
+	return &ProcscanClient{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// FetchViolations returns the violations currently reported by the aggregator
+func (c *ProcscanClient) FetchViolations(ctx context.Context) ([]*ProcessViolation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("procscan aggregator request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("procscan aggregator returned %d", resp.StatusCode)
+	}
+	var body struct {
+		Violations []*ProcessViolation `json:"violations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode violations: %w", err)
+	}
+	return body.Violations, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debug serves the pprof profiles and runtime statistics of the
+// process for profiling in production. It is opt-in and requires a bearer
+// token unless it only listens on the loopback interface. Importing
+// net/http/pprof also registers the profiles on http.DefaultServeMux, which
+// no server of CompliK serves.
+package debug
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Runtime is the body of /debug/runtime
+type Runtime struct {
+	GoVersion  string `json:"go_version"`
+	Goroutines int    `json:"goroutines"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	// Memory statistics in bytes, see runtime.MemStats
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+	LastGC       string `json:"last_gc,omitempty"`
+}
+
+// Handler returns the debug endpoints, requiring token as bearer token when it
+// is not empty
+func Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReadRuntime())
+	})
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ReadRuntime returns the current runtime statistics
+func ReadRuntime() Runtime {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	info := Runtime{
+		GoVersion:    runtime.Version(),
+		Goroutines:   runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    stats.HeapAlloc,
+		HeapInuse:    stats.HeapInuse,
+		HeapObjects:  stats.HeapObjects,
+		StackInuse:   stats.StackInuse,
+		Sys:          stats.Sys,
+		NumGC:        stats.NumGC,
+		PauseTotalNs: stats.PauseTotalNs,
+	}
+	if stats.LastGC > 0 {
+		info.LastGC = time.Unix(0, int64(stats.LastGC)).UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// CheckExposure refuses a listen address other than the loopback interface
+// without a token, the profiles reveal the memory of the process
+func CheckExposure(addr, token string) error {
+	if token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return errors.New("debug endpoints listening beyond the loopback interface require a token")
+}
+
+// Start serves the debug endpoints on addr in the background. Profiles and
+// traces run for the requested seconds, so the server has no write timeout.
+func Start(addr, token string) (*http.Server, error) {
+	if err := CheckExposure(addr, token); err != nil {
+		return nil, err
+	}
+	log := logger.GetLogger().WithField("component", "debug")
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Info("Starting debug server", logger.Fields{"addr": addr})
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Debug server stopped", logger.Fields{"error": err.Error()})
+		}
+	}()
+	return server, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDebug(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Debug Suite")
+}
+
+var _ = Describe("Debug", func() {
+	get := func(handler http.Handler, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should require the token", func() {
+		handler := Handler("secret")
+		Expect(get(handler, "/debug/pprof/", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(get(handler, "/debug/pprof/", "wrong").Code).To(Equal(http.StatusUnauthorized))
+		rec := get(handler, "/debug/pprof/", "secret")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("goroutine"))
+	})
+
+	It("should serve profiles and runtime statistics", func() {
+		handler := Handler("")
+		Expect(get(handler, "/debug/pprof/heap?debug=1", "").Code).To(Equal(http.StatusOK))
+
+		rec := get(handler, "/debug/runtime", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var info Runtime
+		Expect(json.Unmarshal(rec.Body.Bytes(), &info)).To(Succeed())
+		Expect(info.Goroutines).To(BeNumerically(">", 0))
+		Expect(info.HeapAlloc).To(BeNumerically(">", 0))
+		Expect(info.GoVersion).NotTo(BeEmpty())
+	})
+
+	It("should only listen beyond the loopback interface with a token", func() {
+		Expect(CheckExposure("127.0.0.1:6060", "")).To(Succeed())
+		Expect(CheckExposure("[::1]:6060", "")).To(Succeed())
+		Expect(CheckExposure("localhost:6060", "")).To(Succeed())
+		Expect(CheckExposure(":6060", "")).To(MatchError(ContainSubstring("require a token")))
+		Expect(CheckExposure("0.0.0.0:6060", "")).NotTo(Succeed())
+		Expect(CheckExposure(":6060", "secret")).To(Succeed())
+		Expect(CheckExposure("6060", "")).To(MatchError(ContainSubstring("invalid debug address")))
+	})
+})
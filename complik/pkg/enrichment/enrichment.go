@@ -0,0 +1,347 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enrichment resolves the host behind a flagged detection result to the
+// workload serving it, its container images and the tenant that owns it, so
+// alerts and stored records name the responsible user directly.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	DefaultCacheTTL = 10 * time.Minute
+	DefaultTimeout  = 5 * time.Second
+)
+
+// Default owner keys; user.sealos.io/owner is the account label Sealos sets on
+// user namespaces
+var (
+	DefaultUserKeys = []string{"user.sealos.io/owner"}
+	DefaultTeamKeys = []string{"team"}
+)
+
+// ErrNotFound is returned when no workload serves the host
+var ErrNotFound = errors.New("no workload found")
+
+// podSampleSize bounds the pods listed to find the controller of a service
+const podSampleSize = 10
+
+type cacheEntry struct {
+	// workload is nil when the lookup found nothing
+	workload *models.WorkloadInfo
+	expires  time.Time
+}
+
+// Enricher resolves workload ownership from the Kubernetes API
+type Enricher struct {
+	log      logger.Logger
+	client   kubernetes.Interface
+	userKeys []string
+	teamKeys []string
+	ttl      time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// New creates an enricher querying client, applying defaults to unset fields of cfg
+func New(client kubernetes.Interface, cfg config.EnrichmentConfig) *Enricher {
+	e := &Enricher{
+		log:      logger.GetLogger().WithField("component", "enrichment"),
+		client:   client,
+		userKeys: cfg.UserKeys,
+		teamKeys: cfg.TeamKeys,
+		ttl:      time.Duration(cfg.CacheTTLSecond) * time.Second,
+		timeout:  time.Duration(cfg.TimeoutSecond) * time.Second,
+		now:      time.Now,
+		cache:    make(map[string]cacheEntry),
+	}
+	if len(e.userKeys) == 0 {
+		e.userKeys = DefaultUserKeys
+	}
+	if len(e.teamKeys) == 0 {
+		e.teamKeys = DefaultTeamKeys
+	}
+	if e.ttl <= 0 {
+		e.ttl = DefaultCacheTTL
+	}
+	if e.timeout <= 0 {
+		e.timeout = DefaultTimeout
+	}
+	return e
+}
+
+// Stage returns the event bus stage attaching the workload to flagged
+// detector results. Lookup failures are logged and leave the result unchanged.
+func (e *Enricher) Stage() eventbus.Stage {
+	return func(payload any) any {
+		result, ok := payload.(*models.DetectorInfo)
+		if !ok || result == nil || !result.IsIllegal || result.Workload != nil {
+			return payload
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+		defer cancel()
+		workload, err := e.Resolve(ctx, result.Namespace, result.Host, result.Name)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				e.log.Warn("Failed to resolve workload", logger.Fields{
+					"namespace": result.Namespace,
+					"host":      result.Host,
+					"error":     err.Error(),
+				})
+			}
+			return payload
+		}
+		result.Workload = workload
+		return result
+	}
+}
+
+// Resolve returns the workload serving host in namespace. name is the
+// discovered resource name and is tried as the service name when no ingress
+// routes host.
+func (e *Enricher) Resolve(ctx context.Context, namespace, host, name string) (*models.WorkloadInfo, error) {
+	key := namespace + "/" + host + "/" + name
+	now := e.now()
+	e.mu.Lock()
+	cached, ok := e.cache[key]
+	e.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return copyWorkload(cached.workload)
+	}
+
+	workload, err := e.resolve(ctx, namespace, host, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		// API errors are not cached so the next result retries the lookup
+		return nil, err
+	}
+	e.mu.Lock()
+	e.cache[key] = cacheEntry{workload: workload, expires: now.Add(e.ttl)}
+	e.mu.Unlock()
+	return copyWorkload(workload)
+}
+
+func copyWorkload(workload *models.WorkloadInfo) (*models.WorkloadInfo, error) {
+	if workload == nil {
+		return nil, ErrNotFound
+	}
+	copied := *workload
+	copied.Images = slices.Clone(workload.Images)
+	return &copied, nil
+}
+
+func (e *Enricher) resolve(ctx context.Context, namespace, host, name string) (*models.WorkloadInfo, error) {
+	services, err := e.servicesForHost(ctx, namespace, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 && name != "" {
+		services = []string{name}
+	}
+	for _, service := range services {
+		workload, meta, err := e.workloadForService(ctx, namespace, service)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		workload.Service = service
+		e.resolveOwner(ctx, workload, meta)
+		return workload, nil
+	}
+	return nil, ErrNotFound
+}
+
+// servicesForHost returns the backend services of the ingress rules for host
+func (e *Enricher) servicesForHost(ctx context.Context, namespace, host string) ([]string, error) {
+	if host == "" {
+		return nil, nil
+	}
+	ingresses, err := e.client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	var services []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(services, name) {
+			services = append(services, name)
+		}
+	}
+	for _, ingress := range ingresses.Items {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host != host || rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					add(path.Backend.Service.Name)
+				}
+			}
+		}
+	}
+	return services, nil
+}
+
+// workloadForService follows the selector of service to the controller of its pods
+func (e *Enricher) workloadForService(
+	ctx context.Context,
+	namespace, service string,
+) (*models.WorkloadInfo, metav1.ObjectMeta, error) {
+	svc, err := e.client.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, metav1.ObjectMeta{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to get service %s: %w", service, err)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, metav1.ObjectMeta{}, ErrNotFound
+	}
+	pods, err := e.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+		Limit:         podSampleSize,
+	})
+	if err != nil {
+		return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to list pods of service %s: %w", service, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, metav1.ObjectMeta{}, ErrNotFound
+	}
+	pod := pods.Items[0]
+	for _, candidate := range pods.Items {
+		if candidate.Status.Phase == corev1.PodRunning {
+			pod = candidate
+			break
+		}
+	}
+	return e.workloadForPod(ctx, &pod)
+}
+
+// workloadForPod walks the controller references of pod up to its Deployment,
+// StatefulSet or DaemonSet
+func (e *Enricher) workloadForPod(
+	ctx context.Context,
+	pod *corev1.Pod,
+) (*models.WorkloadInfo, metav1.ObjectMeta, error) {
+	apps := e.client.AppsV1()
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return describe("Pod", pod.ObjectMeta, pod.Spec), pod.ObjectMeta, nil
+	}
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := apps.ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to get replicaset %s: %w", owner.Name, err)
+		}
+		rsOwner := metav1.GetControllerOf(rs)
+		if rsOwner == nil || rsOwner.Kind != "Deployment" {
+			return describe("ReplicaSet", rs.ObjectMeta, rs.Spec.Template.Spec), rs.ObjectMeta, nil
+		}
+		deployment, err := apps.Deployments(pod.Namespace).Get(ctx, rsOwner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to get deployment %s: %w", rsOwner.Name, err)
+		}
+		return describe("Deployment", deployment.ObjectMeta, deployment.Spec.Template.Spec), deployment.ObjectMeta, nil
+	case "StatefulSet":
+		sts, err := apps.StatefulSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to get statefulset %s: %w", owner.Name, err)
+		}
+		return describe("StatefulSet", sts.ObjectMeta, sts.Spec.Template.Spec), sts.ObjectMeta, nil
+	case "DaemonSet":
+		ds, err := apps.DaemonSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, metav1.ObjectMeta{}, fmt.Errorf("failed to get daemonset %s: %w", owner.Name, err)
+		}
+		return describe("DaemonSet", ds.ObjectMeta, ds.Spec.Template.Spec), ds.ObjectMeta, nil
+	default:
+		// Other controllers (Jobs, operators) are reported by reference with the
+		// pod metadata standing in for theirs
+		workload := describe(owner.Kind, pod.ObjectMeta, pod.Spec)
+		workload.Name = owner.Name
+		return workload, pod.ObjectMeta, nil
+	}
+}
+
+func describe(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec) *models.WorkloadInfo {
+	workload := &models.WorkloadInfo{
+		Kind:      kind,
+		Name:      meta.Name,
+		CreatedAt: meta.CreationTimestamp.Time,
+	}
+	for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+		if container.Image != "" && !slices.Contains(workload.Images, container.Image) {
+			workload.Images = append(workload.Images, container.Image)
+		}
+	}
+	return workload
+}
+
+// resolveOwner fills the user and team from the workload metadata, falling
+// back to the namespace
+func (e *Enricher) resolveOwner(ctx context.Context, workload *models.WorkloadInfo, meta metav1.ObjectMeta) {
+	workload.UserID = lookup(meta, e.userKeys)
+	workload.Team = lookup(meta, e.teamKeys)
+	if workload.UserID != "" && workload.Team != "" {
+		return
+	}
+	ns, err := e.client.CoreV1().Namespaces().Get(ctx, meta.Namespace, metav1.GetOptions{})
+	if err != nil {
+		e.log.Debug("Failed to get namespace for owner lookup", logger.Fields{
+			"namespace": meta.Namespace,
+			"error":     err.Error(),
+		})
+		return
+	}
+	if workload.UserID == "" {
+		workload.UserID = lookup(ns.ObjectMeta, e.userKeys)
+	}
+	if workload.Team == "" {
+		workload.Team = lookup(ns.ObjectMeta, e.teamKeys)
+	}
+}
+
+// lookup returns the first of keys set as an annotation or label of meta
+func lookup(meta metav1.ObjectMeta, keys []string) string {
+	for _, key := range keys {
+		if value := meta.Annotations[key]; value != "" {
+			return value
+		}
+		if value := meta.Labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enrichment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnrichment(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Enrichment Suite")
+}
+
+func controller(kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+}
+
+var _ = Describe("Enricher", func() {
+	const ns = "ns-alice"
+	created := metav1.NewTime(time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC))
+
+	objects := func() []runtime.Object {
+		return []runtime.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   ns,
+				Labels: map[string]string{"user.sealos.io/owner": "alice"},
+			}},
+			&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+				Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+					Host: "web.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path: "/",
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{Name: "web-svc"},
+							},
+						}},
+					}},
+				}}},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-svc", Namespace: ns},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+			},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "web-7d9-abc",
+				Namespace:       ns,
+				Labels:          map[string]string{"app": "web"},
+				OwnerReferences: controller("ReplicaSet", "web-7d9"),
+			}},
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name:            "web-7d9",
+				Namespace:       ns,
+				OwnerReferences: controller("Deployment", "web"),
+			}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "web",
+					Namespace:         ns,
+					CreationTimestamp: created,
+					Annotations:       map[string]string{"team": "growth"},
+				},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+					Containers: []corev1.Container{
+						{Name: "web", Image: "nginx:1.27"},
+						{Name: "sidecar", Image: "busybox:1.36"},
+					},
+				}}},
+			},
+		}
+	}
+
+	It("should resolve a host through its ingress to the deployment", func() {
+		e := New(fake.NewClientset(objects()...), config.EnrichmentConfig{})
+		workload, err := e.Resolve(context.Background(), ns, "web.example.com", "web")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*workload).To(Equal(models.WorkloadInfo{
+			Kind:      "Deployment",
+			Name:      "web",
+			Service:   "web-svc",
+			Images:    []string{"busybox:1.36", "nginx:1.27"},
+			UserID:    "alice",
+			Team:      "growth",
+			CreatedAt: created.Time,
+		}))
+	})
+
+	It("should fall back to the resource name as service", func() {
+		e := New(fake.NewClientset(objects()...), config.EnrichmentConfig{})
+		workload, err := e.Resolve(context.Background(), ns, "other.example.com", "web-svc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workload.Name).To(Equal("web"))
+	})
+
+	It("should cache lookups, including misses", func() {
+		client := fake.NewClientset(objects()...)
+		e := New(client, config.EnrichmentConfig{})
+		_, err := e.Resolve(context.Background(), ns, "missing.example.com", "")
+		Expect(err).To(MatchError(ErrNotFound))
+		_, err = e.Resolve(context.Background(), ns, "web.example.com", "")
+		Expect(err).NotTo(HaveOccurred())
+		calls := len(client.Actions())
+
+		_, err = e.Resolve(context.Background(), ns, "missing.example.com", "")
+		Expect(err).To(MatchError(ErrNotFound))
+		_, err = e.Resolve(context.Background(), ns, "web.example.com", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Actions()).To(HaveLen(calls))
+	})
+
+	It("should only enrich flagged detector results", func() {
+		stage := New(fake.NewClientset(objects()...), config.EnrichmentConfig{}).Stage()
+
+		flagged := &models.DetectorInfo{Namespace: ns, Host: "web.example.com", IsIllegal: true}
+		Expect(stage(flagged)).To(BeIdenticalTo(flagged))
+		Expect(flagged.Workload).NotTo(BeNil())
+		Expect(flagged.Workload.UserID).To(Equal("alice"))
+
+		clean := &models.DetectorInfo{Namespace: ns, Host: "web.example.com"}
+		stage(clean)
+		Expect(clean.Workload).To(BeNil())
+		Expect(stage("other")).To(Equal("other"))
+	})
+})
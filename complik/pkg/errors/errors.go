@@ -0,0 +1,191 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errors classifies the failures of collectors, detectors and
+// handlers so that the pipeline decides uniformly whether to retry or drop
+// the work, and metrics break failures down by class instead of by error text.
+//
+// Plugins wrap the errors they know the nature of with Wrap or FromStatus;
+// ClassOf falls back to the standard library errors for the others.
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Class is the kind of a failure
+type Class string
+
+const (
+	// Transient failures, such as timeouts and unavailable services, are
+	// likely to succeed on a later attempt
+	Transient Class = "transient"
+	// Permanent failures fail the same way when retried, the work is dropped
+	Permanent Class = "permanent"
+	// RateLimited failures succeed after the backoff the service asked for
+	RateLimited Class = "rate_limited"
+	// AuthFailure failures need a configuration change, such as a new token
+	AuthFailure Class = "auth_failure"
+)
+
+// Retryable reports whether the work should be attempted again
+func (c Class) Retryable() bool {
+	return c == Transient || c == RateLimited
+}
+
+// Error is a classified error
+type Error struct {
+	Class Class
+	// RetryAfter is the backoff requested by a rate-limited service, zero when unknown
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap classifies err, nil stays nil. The class of an already classified
+// error is replaced.
+func Wrap(class Class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// Wrapf classifies a new error formatted like fmt.Errorf
+func Wrapf(class Class, format string, args ...any) error {
+	return &Error{Class: class, Err: fmt.Errorf(format, args...)}
+}
+
+// RateLimitedAfter classifies err as rate limited with the backoff the
+// service asked for
+func RateLimitedAfter(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Class: RateLimited, RetryAfter: retryAfter, Err: err}
+}
+
+// ClassOf returns the class of err. Errors wrapped by this package keep their
+// class; timeouts, refused and reset connections and context deadlines are
+// transient, cancellation and other errors are permanent. ClassOf returns ""
+// for nil.
+func ClassOf(err error) Class {
+	if err == nil {
+		return ""
+	}
+	var classified *Error
+	if stderrors.As(err, &classified) {
+		return classified.Class
+	}
+	if stderrors.Is(err, context.DeadlineExceeded) ||
+		stderrors.Is(err, syscall.ECONNREFUSED) ||
+		stderrors.Is(err, syscall.ECONNRESET) ||
+		stderrors.Is(err, syscall.EPIPE) {
+		return Transient
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return Transient
+	}
+	var dnsErr *net.DNSError
+	if stderrors.As(err, &dnsErr) && dnsErr.IsTemporary {
+		return Transient
+	}
+	return Permanent
+}
+
+// IsRetryable reports whether the work that failed with err should be
+// attempted again
+func IsRetryable(err error) bool {
+	return ClassOf(err).Retryable()
+}
+
+// Is reports whether err is of class
+func Is(err error, class Class) bool {
+	return err != nil && ClassOf(err) == class
+}
+
+// RetryAfter returns the backoff a rate-limited service asked for
+func RetryAfter(err error) (time.Duration, bool) {
+	var classified *Error
+	if stderrors.As(err, &classified) && classified.RetryAfter > 0 {
+		return classified.RetryAfter, true
+	}
+	return 0, false
+}
+
+// FromStatus classifies the failure of a request answered with status:
+// 401 and 403 are auth failures, 429 is rate limited, 408, 425 and 5xx
+// other than 501 are transient and the remaining statuses permanent.
+func FromStatus(status int, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return Wrap(AuthFailure, err)
+	case status == http.StatusTooManyRequests:
+		return Wrap(RateLimited, err)
+	case status == http.StatusRequestTimeout || status == http.StatusTooEarly:
+		return Wrap(Transient, err)
+	case status >= 500 && status != http.StatusNotImplemented:
+		return Wrap(Transient, err)
+	default:
+		return Wrap(Permanent, err)
+	}
+}
+
+// FromResponse classifies the failure of a request answered with resp like
+// FromStatus and takes the backoff of rate-limited responses from their
+// Retry-After header
+func FromResponse(resp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	classified := FromStatus(resp.StatusCode, err)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if after := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); after > 0 {
+			return &Error{Class: ClassOf(classified), RetryAfter: after, Err: err}
+		}
+	}
+	return classified
+}
+
+// parseRetryAfter parses the delay-seconds or HTTP-date form of Retry-After
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestErrors(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Errors Suite")
+}
+
+var _ = Describe("Errors", func() {
+	It("should keep the class of wrapped errors", func() {
+		cause := stderrors.New("quota exceeded")
+		err := fmt.Errorf("review failed: %w", Wrap(RateLimited, cause))
+		Expect(ClassOf(err)).To(Equal(RateLimited))
+		Expect(IsRetryable(err)).To(BeTrue())
+		Expect(stderrors.Is(err, cause)).To(BeTrue())
+		Expect(err.Error()).To(Equal("review failed: quota exceeded"))
+		Expect(Wrap(Transient, nil)).To(BeNil())
+		Expect(Is(Wrapf(AuthFailure, "token %s expired", "t"), AuthFailure)).To(BeTrue())
+		Expect(IsRetryable(Wrap(AuthFailure, cause))).To(BeFalse())
+	})
+
+	It("should classify unwrapped errors of the standard library", func() {
+		Expect(ClassOf(nil)).To(BeEmpty())
+		Expect(ClassOf(fmt.Errorf("collect: %w", context.DeadlineExceeded))).To(Equal(Transient))
+		Expect(ClassOf(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED})).To(Equal(Transient))
+		Expect(ClassOf(&net.DNSError{Err: "timeout", IsTimeout: true})).To(Equal(Transient))
+		Expect(ClassOf(&net.DNSError{Err: "no such host", IsNotFound: true})).To(Equal(Permanent))
+		Expect(ClassOf(context.Canceled)).To(Equal(Permanent))
+		Expect(ClassOf(stderrors.New("invalid settings"))).To(Equal(Permanent))
+	})
+
+	It("should classify HTTP statuses", func() {
+		cause := stderrors.New("request failed")
+		for status, class := range map[int]Class{
+			http.StatusUnauthorized:          AuthFailure,
+			http.StatusForbidden:             AuthFailure,
+			http.StatusTooManyRequests:       RateLimited,
+			http.StatusRequestTimeout:        Transient,
+			http.StatusBadGateway:            Transient,
+			http.StatusServiceUnavailable:    Transient,
+			http.StatusNotImplemented:        Permanent,
+			http.StatusBadRequest:            Permanent,
+			http.StatusRequestEntityTooLarge: Permanent,
+		} {
+			Expect(ClassOf(FromStatus(status, cause))).To(Equal(class), "status %d", status)
+		}
+		Expect(FromStatus(http.StatusBadGateway, nil)).To(BeNil())
+	})
+
+	It("should take the backoff of rate-limited responses from Retry-After", func() {
+		cause := stderrors.New("slow down")
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"30"}}}
+		err := FromResponse(resp, cause)
+		Expect(ClassOf(err)).To(Equal(RateLimited))
+		after, ok := RetryAfter(err)
+		Expect(ok).To(BeTrue())
+		Expect(after).To(Equal(30 * time.Second))
+
+		now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+		Expect(parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)).To(Equal(time.Minute))
+		Expect(parseRetryAfter("soon", now)).To(BeZero())
+
+		_, ok = RetryAfter(FromResponse(&http.Response{StatusCode: http.StatusBadGateway}, cause))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should count recorded failures by plugin and class", func() {
+		Expect(Record("test-plugin", nil)).To(BeEmpty())
+		Expect(Record("test-plugin", Wrap(Transient, stderrors.New("timeout")))).To(Equal(Transient))
+		Expect(Record("test-plugin", stderrors.New("invalid"))).To(Equal(Permanent))
+		Expect(testutil.ToFloat64(failures.WithLabelValues("test-plugin", string(Transient)))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(failures.WithLabelValues("test-plugin", string(Permanent)))).To(Equal(1.0))
+	})
+})
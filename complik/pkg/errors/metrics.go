@@ -0,0 +1,38 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// failures counts the failures recorded by the plugins by class
+var failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "complik",
+	Name:      "failures_total",
+	Help:      "Failures of collectors, detectors and handlers by plugin and class.",
+}, []string{"plugin", "class"})
+
+// Record counts err as a failure of plugin and returns its class, "" for nil
+func Record(plugin string, err error) Class {
+	class := ClassOf(err)
+	if class != "" {
+		failures.WithLabelValues(plugin, string(class)).Inc()
+	}
+	return class
+}
+
+// Collector returns the failure counters for a Prometheus registry
+func Collector() prometheus.Collector {
+	return failures
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Phase is a stage of the pipeline a target passes through
+type Phase int
+
+const (
+	PhaseCollect Phase = iota
+	PhaseDetect
+	PhaseHandle
+)
+
+// Policy is the time budget of a target in the pipeline. The deadline starts
+// when the target is collected and is carried by the events published for it;
+// every phase ends early enough to leave the later phases their reserve.
+type Policy struct {
+	// Budget is the time from the start of the collection to the deadline,
+	// zero disables deadlines
+	Budget time.Duration
+	// Detection and Handling are the parts of Budget reserved for the
+	// detection and the handling of the target
+	Detection time.Duration
+	Handling  time.Duration
+}
+
+// SetPolicy sets the time budget of the pipeline. It must be called before
+// plugins are started.
+func (eb *EventBus) SetPolicy(policy Policy) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.policy = policy
+}
+
+// Policy returns the policy set with SetPolicy
+func (eb *EventBus) Policy() Policy {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	return eb.policy
+}
+
+// Deadline returns the deadline of a target whose collection starts at now,
+// zero without a budget
+func (p Policy) Deadline(now time.Time) time.Time {
+	if p.Budget <= 0 {
+		return time.Time{}
+	}
+	return now.Add(p.Budget)
+}
+
+// Context returns a context for phase that ends at deadline minus the reserve
+// of the later phases. The handling always gets its reserve, even for targets
+// whose deadline passed while they were queued, so that actions are late
+// rather than dropped. A zero deadline leaves parent unbounded, so the stage
+// timeouts alone apply.
+func (p Policy) Context(parent context.Context, deadline time.Time, phase Phase) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(parent)
+	}
+	end := deadline.Add(-p.reserve(phase))
+	if phase == PhaseHandle {
+		if earliest := time.Now().Add(p.Handling); end.Before(earliest) {
+			end = earliest
+		}
+	}
+	return context.WithDeadline(parent, end)
+}
+
+// reserve returns the part of the budget the phases after phase need
+func (p Policy) reserve(phase Phase) time.Duration {
+	switch phase {
+	case PhaseCollect:
+		return p.Detection + p.Handling
+	case PhaseDetect:
+		return p.Handling
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	policy := Policy{Budget: 5 * time.Minute, Detection: 90 * time.Second, Handling: 30 * time.Second}
+
+	It("should leave the later phases their reserve", func() {
+		deadline := policy.Deadline(time.Now())
+		collect, cancel := policy.Context(context.Background(), deadline, PhaseCollect)
+		defer cancel()
+		end, ok := collect.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(end).To(Equal(deadline.Add(-2 * time.Minute)))
+
+		detect, cancel := policy.Context(context.Background(), deadline, PhaseDetect)
+		defer cancel()
+		end, _ = detect.Deadline()
+		Expect(end).To(Equal(deadline.Add(-30 * time.Second)))
+
+		handle, cancel := policy.Context(context.Background(), deadline, PhaseHandle)
+		defer cancel()
+		end, _ = handle.Deadline()
+		Expect(end).To(Equal(deadline))
+	})
+
+	It("should give the handling its reserve after the deadline passed", func() {
+		ctx, cancel := policy.Context(context.Background(), time.Now().Add(-time.Minute), PhaseHandle)
+		defer cancel()
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+		end, _ := ctx.Deadline()
+		Expect(time.Until(end)).To(BeNumerically("~", 30*time.Second, time.Second))
+
+		ctx, cancel = policy.Context(context.Background(), time.Now().Add(-time.Minute), PhaseDetect)
+		defer cancel()
+		Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("should not bound events without a deadline", func() {
+		Expect(Policy{}.Deadline(time.Now()).IsZero()).To(BeTrue())
+		ctx, cancel := policy.Context(context.Background(), time.Time{}, PhaseCollect)
+		defer cancel()
+		_, ok := ctx.Deadline()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should deliver the deadline with the event", func() {
+		eb := NewEventBus(1)
+		eb.SetPolicy(policy)
+		Expect(eb.Policy()).To(Equal(policy))
+		ch := eb.Subscribe("collector")
+		deadline := eb.Policy().Deadline(time.Now())
+		Expect(eb.Publish("collector", Event{Payload: 1, Deadline: deadline})).To(Succeed())
+		Eventually(ch).Should(Receive(Equal(Event{Payload: 1, Deadline: deadline, Key: IdempotencyKey("collector", 1)})))
+	})
+})
@@ -14,25 +14,79 @@
 
 // Package eventbus provides a lightweight publish-subscribe event bus for
 // decoupled communication between components in the system.
+//
+// Subscriptions may use wildcard patterns (see Match) and consumer groups:
+// the members of a group share the events of their topic, each event is
+// delivered to one of them, so that handlers can be scaled horizontally.
 package eventbus
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 )
 
 // Event represents a message that can be published to the event bus
 type Event struct {
 	Payload any
+	// Topic is the topic the event was published to. It is only set on events
+	// delivered to wildcard subscriptions, whose pattern does not identify it.
+	Topic string
+	// Deadline is the time by which the pipeline should be done with the
+	// target of the event, zero when it has none. Stages pass it on to the
+	// events they publish for the target, see Policy.
+	Deadline time.Time
+	// Key identifies the content of the event, so handlers with side effects
+	// can skip an event delivered again. Publish derives it from the payload
+	// when it is empty, see IdempotencyKey.
+	Key string
 }
 
+// Stage transforms the payload of a published event before it is delivered.
+// Stages must not fail; they return the payload unchanged when they cannot
+// process it.
+type Stage func(payload any) any
+
 // EventChan is a channel for delivering events to subscribers
 type EventChan chan Event
 
+// Filter decides whether an event published to topic is delivered to a
+// subscription
+type Filter func(topic string, payload any) bool
+
 // EventBus manages topic-based event subscriptions and publications
 type EventBus struct {
+	*bus
+	// filter applies to the subscriptions made through this view
+	filter Filter
+	// group is the consumer group the subscriptions of this view join
+	group string
+}
+
+// bus is the state shared by an event bus and its filtered views
+type bus struct {
 	mu          sync.RWMutex
 	subscribers map[string][]EventChan
+	groups      map[groupKey]*group
+	filters     map[EventChan]Filter
 	bufferSize  int
+	registry    *Registry
+	stages      map[string][]Stage
+	policy      Policy
+}
+
+// groupKey identifies a consumer group, the same name may be used on several topics
+type groupKey struct {
+	topic string
+	name  string
+}
+
+// group is a consumer group whose members receive the events in turn
+type group struct {
+	members []EventChan
+	next    atomic.Uint64
 }
 
 // NewEventBus creates a new event bus with the specified channel buffer size
@@ -40,33 +94,207 @@ func NewEventBus(bufferSize int) *EventBus {
 	if bufferSize <= 0 {
 		bufferSize = 10000
 	}
-	return &EventBus{
+	return &EventBus{bus: &bus{
 		subscribers: make(map[string][]EventChan),
+		groups:      make(map[groupKey]*group),
+		filters:     make(map[EventChan]Filter),
 		bufferSize:  bufferSize,
+		stages:      make(map[string][]Stage),
+	}}
+}
+
+// WithFilter returns a view of eb whose subscriptions only receive the events
+// filter accepts, on top of the filter of eb. Everything else, publishing
+// included, is shared with eb.
+func (eb *EventBus) WithFilter(filter Filter) *EventBus {
+	if parent := eb.filter; parent != nil {
+		child := filter
+		filter = func(topic string, payload any) bool {
+			return parent(topic, payload) && child(topic, payload)
+		}
 	}
+	return &EventBus{bus: eb.bus, filter: filter, group: eb.group}
 }
 
-// Publish sends an event to all subscribers of the specified topic
-func (eb *EventBus) Publish(topic string, event Event) {
+// WithGroup returns a view of eb whose subscriptions join the consumer group
+// name, see SubscribeGroup. An empty name leaves the group of eb unchanged.
+func (eb *EventBus) WithGroup(name string) *EventBus {
+	if name == "" {
+		return eb
+	}
+	return &EventBus{bus: eb.bus, filter: eb.filter, group: name}
+}
+
+// SetRegistry enables payload validation against registry on publish and
+// subscription. It must be called before plugins are started.
+func (eb *EventBus) SetRegistry(registry *Registry) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.registry = registry
+}
+
+// Registry returns the payload registry set with SetRegistry, nil without one
+func (eb *EventBus) Registry() *Registry {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	return eb.registry
+}
+
+// AddStage runs stage on every payload published to topic, after schema
+// normalization and in the order the stages were added
+func (eb *EventBus) AddStage(topic string, stage Stage) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.stages[topic] = append(eb.stages[topic], stage)
+}
+
+// delivery is a subscription an event is sent to
+type delivery struct {
+	ch       EventChan
+	filter   Filter
+	wildcard bool
+}
+
+// Publish sends an event to all subscribers of the specified topic and of the
+// patterns matching it, and to one member of every matching consumer group.
+// Payloads that do not match the schema registered for the topic are logged
+// and rejected instead of being delivered.
+func (eb *EventBus) Publish(topic string, event Event) error {
 	eb.mu.RLock()
-	subscribers := eb.subscribers[topic]
+	var deliveries []delivery
+	for pattern, subscribers := range eb.subscribers {
+		wildcard := pattern != topic
+		if wildcard && (!IsPattern(pattern) || !Match(pattern, topic)) {
+			continue
+		}
+		for _, subscriber := range subscribers {
+			deliveries = append(deliveries, delivery{ch: subscriber, filter: eb.filters[subscriber], wildcard: wildcard})
+		}
+	}
+	var groups []*group
+	var groupWildcard []bool
+	for key, g := range eb.groups {
+		if key.topic == topic || (IsPattern(key.topic) && Match(key.topic, topic)) {
+			groups = append(groups, g)
+			groupWildcard = append(groupWildcard, key.topic != topic)
+		}
+	}
+	// The members are chosen after the stages ran, their filters see the final payload
+	members := make([][]delivery, len(groups))
+	for i, g := range groups {
+		for _, member := range g.members {
+			members[i] = append(members[i], delivery{ch: member, filter: eb.filters[member], wildcard: groupWildcard[i]})
+		}
+	}
+	registry := eb.registry
+	stages := eb.stages[topic]
 	eb.mu.RUnlock()
-	for _, subscriber := range subscribers {
+	if registry != nil {
+		payload, err := registry.Normalize(topic, event.Payload)
+		if err != nil {
+			logger.GetLogger().Error("Rejected event with invalid payload", logger.Fields{
+				"topic": topic,
+				"error": err.Error(),
+			})
+			return err
+		}
+		event.Payload = payload
+	}
+	for _, stage := range stages {
+		event.Payload = stage(event.Payload)
+	}
+	if event.Key == "" {
+		event.Key = IdempotencyKey(topic, event.Payload)
+	}
+	for i, g := range groups {
+		if member, ok := g.pick(members[i], topic, event.Payload); ok {
+			deliveries = append(deliveries, member)
+		}
+	}
+	for _, d := range deliveries {
+		if d.filter != nil && !d.filter(topic, event.Payload) {
+			continue
+		}
+		delivered := event
+		if d.wildcard {
+			delivered.Topic = topic
+		}
 		go func(sub chan Event) {
-			sub <- event
-		}(subscriber)
+			sub <- delivered
+		}(d.ch)
 	}
+	return nil
 }
 
-// Subscribe creates a new subscription to the specified topic and returns a channel for receiving events
+// pick returns the next member in turn whose filter accepts the event
+func (g *group) pick(members []delivery, topic string, payload any) (delivery, bool) {
+	if len(members) == 0 {
+		return delivery{}, false
+	}
+	start := g.next.Add(1) - 1
+	for i := range members {
+		member := members[(start+uint64(i))%uint64(len(members))]
+		if member.filter == nil || member.filter(topic, payload) {
+			// The filter already accepted the event
+			member.filter = nil
+			return member, true
+		}
+	}
+	return delivery{}, false
+}
+
+// Subscribe creates a new subscription to the specified topic and returns a
+// channel for receiving events. Topic may be a wildcard pattern, see Match.
+// Subscriptions of a view created by WithGroup join its consumer group.
 func (eb *EventBus) Subscribe(topic string) EventChan {
+	if eb.group != "" {
+		return eb.SubscribeGroup(topic, eb.group)
+	}
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 	ch := make(EventChan, eb.bufferSize)
 	eb.subscribers[topic] = append(eb.subscribers[topic], ch)
+	if eb.filter != nil {
+		eb.filters[ch] = eb.filter
+	}
 	return ch
 }
 
+// SubscribeGroup subscribes to topic as a member of the consumer group name.
+// Every event of topic is delivered to one member of the group in turn
+// instead of to all of them; subscribers outside the group still receive
+// every event. Members leave the group with Unsubscribe.
+func (eb *EventBus) SubscribeGroup(topic, name string) EventChan {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	ch := make(EventChan, eb.bufferSize)
+	key := groupKey{topic: topic, name: name}
+	g, ok := eb.groups[key]
+	if !ok {
+		g = &group{}
+		eb.groups[key] = g
+	}
+	g.members = append(g.members, ch)
+	if eb.filter != nil {
+		eb.filters[ch] = eb.filter
+	}
+	return ch
+}
+
+// SubscribeVersion subscribes to topic for a consumer built against version of
+// the topic schema and fails if the published payloads are of another version
+func (eb *EventBus) SubscribeVersion(topic string, version int) (EventChan, error) {
+	eb.mu.RLock()
+	registry := eb.registry
+	eb.mu.RUnlock()
+	if registry != nil {
+		if err := registry.Check(topic, version); err != nil {
+			return nil, err
+		}
+	}
+	return eb.Subscribe(topic), nil
+}
+
 // Unsubscribe removes a subscription from the specified topic and closes the channel
 func (eb *EventBus) Unsubscribe(topic string, ch EventChan) {
 	eb.mu.Lock()
@@ -75,6 +303,25 @@ func (eb *EventBus) Unsubscribe(topic string, ch EventChan) {
 		for i, subscriber := range subscribers {
 			if ch == subscriber {
 				eb.subscribers[topic] = append(subscribers[:i], subscribers[i+1:]...)
+				delete(eb.filters, ch)
+				close(ch)
+				for range ch {
+				}
+				return
+			}
+		}
+	}
+	for key, g := range eb.groups {
+		if key.topic != topic {
+			continue
+		}
+		for i, member := range g.members {
+			if ch == member {
+				g.members = append(g.members[:i:i], g.members[i+1:]...)
+				if len(g.members) == 0 {
+					delete(eb.groups, key)
+				}
+				delete(eb.filters, ch)
 				close(ch)
 				for range ch {
 				}
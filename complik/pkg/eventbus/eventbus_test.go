@@ -141,10 +141,42 @@ var _ = Describe("EventBus", func() {
 			event := Event{Payload: "specific"}
 			eb.Publish("topic1", event)
 
+			event.Key = IdempotencyKey("topic1", "specific")
 			Eventually(ch1).Should(Receive(Equal(event)))
 			Consistently(ch2, 100*time.Millisecond).ShouldNot(Receive())
 		})
 
+		It("should deliver only the events the filter of a view accepts", func() {
+			even := eb.WithFilter(func(_ string, payload any) bool { return payload.(int)%2 == 0 })
+			filtered := even.Subscribe("numbers")
+			all := eb.Subscribe("numbers")
+
+			even.Publish("numbers", Event{Payload: 1})
+			Eventually(all).Should(Receive(Equal(Event{Payload: 1, Key: IdempotencyKey("numbers", 1)})))
+			Consistently(filtered, 100*time.Millisecond).ShouldNot(Receive())
+
+			eb.Publish("numbers", Event{Payload: 2})
+			Eventually(filtered).Should(Receive(Equal(Event{Payload: 2, Key: IdempotencyKey("numbers", 2)})))
+
+			even.Unsubscribe("numbers", filtered)
+			eb.mu.RLock()
+			Expect(eb.filters).To(BeEmpty())
+			eb.mu.RUnlock()
+		})
+
+		It("should run the stages of the topic before delivery", func() {
+			ch := eb.Subscribe("staged")
+			other := eb.Subscribe("plain")
+			eb.AddStage("staged", func(payload any) any { return payload.(string) + "-a" })
+			eb.AddStage("staged", func(payload any) any { return payload.(string) + "-b" })
+
+			eb.Publish("staged", Event{Payload: "x"})
+			eb.Publish("plain", Event{Payload: "x"})
+
+			Eventually(ch).Should(Receive(Equal(Event{Payload: "x-a-b", Key: IdempotencyKey("staged", "x-a-b")})))
+			Eventually(other).Should(Receive(Equal(Event{Payload: "x", Key: IdempotencyKey("plain", "x")})))
+		})
+
 		It("should handle publishing to topic with no subscribers", func() {
 			event := Event{Payload: "nobody listening"}
 			Expect(func() {
@@ -179,6 +211,111 @@ var _ = Describe("EventBus", func() {
 		}, 1.0)
 	})
 
+	Describe("Wildcards", func() {
+		It("should match topics segment by segment", func() {
+			Expect(Match("compliance.*", "compliance.detector")).To(BeTrue())
+			Expect(Match("compliance.*", "compliance")).To(BeFalse())
+			Expect(Match("compliance.*", "compliance.detector.v2")).To(BeFalse())
+			Expect(Match("compliance.**", "compliance")).To(BeTrue())
+			Expect(Match("compliance.**", "compliance.detector.v2")).To(BeTrue())
+			Expect(Match("*.detector", "compliance.detector")).To(BeTrue())
+			Expect(Match("*", "detector")).To(BeTrue())
+			Expect(Match("compliance.*", "handle.detector")).To(BeFalse())
+			Expect(IsPattern("compliance.*")).To(BeTrue())
+			Expect(IsPattern("compliance.detector")).To(BeFalse())
+		})
+
+		It("should deliver matching events with their topic", func() {
+			wildcard := eb.Subscribe("compliance.*")
+			exact := eb.Subscribe("compliance.detector")
+
+			eb.Publish("compliance.detector", Event{Payload: "a"})
+			eb.Publish("handle.lark", Event{Payload: "b"})
+
+			key := IdempotencyKey("compliance.detector", "a")
+
+			Eventually(wildcard).Should(Receive(Equal(Event{Payload: "a", Topic: "compliance.detector", Key: key})))
+			Eventually(exact).Should(Receive(Equal(Event{Payload: "a", Key: key})))
+			Consistently(wildcard, 100*time.Millisecond).ShouldNot(Receive())
+
+			eb.Unsubscribe("compliance.*", wildcard)
+			Eventually(wildcard).Should(BeClosed())
+		})
+	})
+
+	Describe("Consumer groups", func() {
+		It("should deliver every event to one member of the group", func() {
+			members := []EventChan{
+				eb.SubscribeGroup("detector", "lark"),
+				eb.SubscribeGroup("detector", "lark"),
+				eb.SubscribeGroup("detector", "lark"),
+			}
+			other := eb.SubscribeGroup("detector", "database")
+			all := eb.Subscribe("detector")
+
+			for i := 0; i < 30; i++ {
+				Expect(eb.Publish("detector", Event{Payload: i})).To(Succeed())
+			}
+
+			received := make([]int, len(members))
+			seen := make(map[int]bool)
+			Eventually(func() int {
+				for i, ch := range members {
+				drain:
+					for {
+						select {
+						case event := <-ch:
+							Expect(seen[event.Payload.(int)]).To(BeFalse())
+							seen[event.Payload.(int)] = true
+							received[i]++
+						default:
+							break drain
+						}
+					}
+				}
+				return len(seen)
+			}).Should(Equal(30))
+			Expect(received).To(Equal([]int{10, 10, 10}))
+			Eventually(other).Should(HaveLen(30))
+			Eventually(all).Should(HaveLen(30))
+		})
+
+		It("should subscribe views created by WithGroup to their group", func() {
+			workers := eb.WithGroup("workers")
+			a := workers.Subscribe("jobs")
+			b := workers.Subscribe("jobs")
+			Expect(eb.WithGroup("")).To(BeIdenticalTo(eb))
+
+			Expect(eb.Publish("jobs", Event{Payload: 1})).To(Succeed())
+			Expect(eb.Publish("jobs", Event{Payload: 2})).To(Succeed())
+			Eventually(func() int { return len(a) + len(b) }).Should(Equal(2))
+			Expect(a).To(HaveLen(1))
+			eb.mu.RLock()
+			Expect(eb.subscribers["jobs"]).To(BeEmpty())
+			Expect(eb.groups[groupKey{topic: "jobs", name: "workers"}].members).To(HaveLen(2))
+			eb.mu.RUnlock()
+		})
+
+		It("should skip members whose filter rejects the event", func() {
+			even := eb.WithFilter(func(_ string, payload any) bool { return payload.(int)%2 == 0 })
+			filtered := even.SubscribeGroup("numbers.*", "workers")
+			open := eb.SubscribeGroup("numbers.*", "workers")
+
+			for i := 1; i <= 3; i += 2 {
+				Expect(eb.Publish("numbers.odd", Event{Payload: i})).To(Succeed())
+			}
+			Eventually(open).Should(HaveLen(2))
+			Expect(filtered).To(BeEmpty())
+
+			eb.Unsubscribe("numbers.*", filtered)
+			eb.Unsubscribe("numbers.*", open)
+			eb.mu.RLock()
+			Expect(eb.groups).To(BeEmpty())
+			Expect(eb.filters).To(BeEmpty())
+			eb.mu.RUnlock()
+		})
+	})
+
 	Describe("Unsubscribe", func() {
 		It("should remove subscription and close channel", func() {
 			ch := eb.Subscribe("test")
@@ -360,6 +497,7 @@ var _ = Describe("EventBus", func() {
 			event := Event{Payload: "empty topic"}
 			eb.Publish("", event)
 
+			event.Key = IdempotencyKey("", "empty topic")
 			Eventually(ch).Should(Receive(Equal(event)))
 		})
 	})
@@ -0,0 +1,119 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// IdempotencyHeader is the HTTP header carrying the key of the event a
+// request was made for
+const IdempotencyHeader = "Idempotency-Key"
+
+// Keyer is implemented by payloads that name the content identifying them,
+// leaving out what differs between two publications of the same finding such
+// as the wording of a model review. Other payloads are identified by their
+// JSON encoding.
+type Keyer interface {
+	IdempotencyKey() string
+}
+
+// IdempotencyKey returns the key of an event of topic carrying payload, empty
+// when the payload cannot be encoded
+func IdempotencyKey(topic string, payload any) string {
+	var content []byte
+	if keyer, ok := payload.(Keyer); ok {
+		content = []byte(keyer.IdempotencyKey())
+	} else {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return ""
+		}
+		content = data
+	}
+	h := sha256.New()
+	h.Write([]byte(topic))
+	h.Write([]byte{0})
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey returns a context carrying the key of the event handled
+// with it, for the handlers passing it on to external services
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+// IdempotencyKeyFrom returns the key set with WithIdempotencyKey, empty
+// without one
+func IdempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContext{}).(string)
+	return key
+}
+
+// Dedup remembers the keys of the events a handler acted on for a TTL, so an
+// event delivered again within it is skipped
+type Dedup struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+func NewDedup(ttl time.Duration) *Dedup {
+	return &Dedup{ttl: ttl, seen: make(map[string]time.Time)}
+}
+
+// Claim records key at now and reports whether it was not claimed within the
+// TTL. Events without a key are always claimed.
+func (d *Dedup) Claim(key string, now time.Time) bool {
+	if key == "" {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now.Sub(d.pruned) >= d.ttl {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.ttl {
+				delete(d.seen, k)
+			}
+		}
+		d.pruned = now
+	}
+	if at, ok := d.seen[key]; ok && now.Sub(at) < d.ttl {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// Release forgets key, so that an event whose handling failed is handled
+// again when it is delivered again
+func (d *Dedup) Release(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, key)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type keyed struct {
+	ID     string
+	Review string
+}
+
+func (k keyed) IdempotencyKey() string { return k.ID }
+
+var _ = Describe("Idempotency", func() {
+	It("should derive the key of published events from their content", func() {
+		eb := NewEventBus(2)
+		ch := eb.Subscribe("detector")
+		Expect(eb.Publish("detector", Event{Payload: keyed{ID: "a", Review: "first"}})).To(Succeed())
+		Expect(eb.Publish("detector", Event{Payload: keyed{ID: "a", Review: "second"}})).To(Succeed())
+		var first, second Event
+		Eventually(ch).Should(Receive(&first))
+		Eventually(ch).Should(Receive(&second))
+		Expect(first.Key).NotTo(BeEmpty())
+		Expect(second.Key).To(Equal(first.Key))
+
+		Expect(IdempotencyKey("detector", "a")).NotTo(Equal(IdempotencyKey("collector", "a")))
+		Expect(IdempotencyKey("detector", keyed{ID: "b"})).NotTo(Equal(first.Key))
+	})
+
+	It("should keep the key set by the publisher", func() {
+		eb := NewEventBus(1)
+		ch := eb.Subscribe("detector")
+		Expect(eb.Publish("detector", Event{Payload: 1, Key: "retry-1"})).To(Succeed())
+		Eventually(ch).Should(Receive(HaveField("Key", "retry-1")))
+	})
+
+	It("should carry the key in a context", func() {
+		ctx := WithIdempotencyKey(context.Background(), "k")
+		Expect(IdempotencyKeyFrom(ctx)).To(Equal("k"))
+		Expect(IdempotencyKeyFrom(context.Background())).To(BeEmpty())
+	})
+
+	It("should claim a key once within the TTL", func() {
+		dedup := NewDedup(time.Minute)
+		now := time.Now()
+		Expect(dedup.Claim("k", now)).To(BeTrue())
+		Expect(dedup.Claim("k", now.Add(30*time.Second))).To(BeFalse())
+		Expect(dedup.Claim("k", now.Add(2*time.Minute))).To(BeTrue())
+
+		dedup.Release("k")
+		Expect(dedup.Claim("k", now.Add(2*time.Minute))).To(BeTrue())
+		Expect(dedup.Claim("", now)).To(BeTrue())
+		Expect(dedup.Claim("", now)).To(BeTrue())
+	})
+})
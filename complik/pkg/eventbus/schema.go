@@ -0,0 +1,204 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// SchemaVersionField is the struct field carrying the payload schema version.
+// A zero value means the publisher did not stamp a version and is taken as
+// the current one.
+const SchemaVersionField = "SchemaVersion"
+
+var (
+	// ErrPayloadType is returned when a payload does not have the type
+	// registered for its topic
+	ErrPayloadType = errors.New("unexpected payload type")
+	// ErrSchemaVersion is returned when a payload or subscriber version can
+	// not be converted to the version registered for its topic
+	ErrSchemaVersion = errors.New("unsupported schema version")
+)
+
+// Converter upgrades a payload of the registered type by one schema version
+type Converter func(payload any) (any, error)
+
+// Schema describes the payload a topic carries
+type Schema struct {
+	Type reflect.Type
+	// Version is the current schema version, 0 for unversioned payloads
+	Version    int
+	converters map[int]Converter
+}
+
+// Registry maps topics to their payload schema. Topics without a schema are
+// not validated.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewRegistry creates an empty payload registry
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]*Schema)}
+}
+
+// Register declares that topic carries payloads of the type of sample at the
+// given schema version. Versioned payloads must embed an int SchemaVersion field.
+func (r *Registry) Register(topic string, sample any, version int) error {
+	if sample == nil {
+		return fmt.Errorf("topic %s: sample payload cannot be nil", topic)
+	}
+	t := reflect.TypeOf(sample)
+	if version > 0 {
+		if _, ok := versionField(t); !ok {
+			return fmt.Errorf("topic %s: %v has no int %s field", topic, t, SchemaVersionField)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.schemas[topic]; exists {
+		return fmt.Errorf("topic %s already has a registered schema", topic)
+	}
+	r.schemas[topic] = &Schema{Type: t, Version: version, converters: make(map[int]Converter)}
+	return nil
+}
+
+// RegisterConverter adds the conversion of topic payloads from version from
+// to version from+1
+func (r *Registry) RegisterConverter(topic string, from int, convert Converter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schema, ok := r.schemas[topic]
+	if !ok {
+		return fmt.Errorf("topic %s has no registered schema", topic)
+	}
+	if from < 1 || from >= schema.Version {
+		return fmt.Errorf("topic %s: cannot convert from version %d, current version is %d",
+			topic, from, schema.Version)
+	}
+	schema.converters[from] = convert
+	return nil
+}
+
+// Schema returns the schema registered for topic
+func (r *Registry) Schema(topic string) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schema, ok := r.schemas[topic]
+	if !ok {
+		return Schema{}, false
+	}
+	return *schema, true
+}
+
+// Normalize validates payload against the schema of topic and returns it
+// converted to and stamped with the current version
+func (r *Registry) Normalize(topic string, payload any) (any, error) {
+	schema, ok := r.Schema(topic)
+	if !ok {
+		return payload, nil
+	}
+	if payload == nil || reflect.TypeOf(payload) != schema.Type {
+		return nil, fmt.Errorf("%w on topic %s: want %v, got %T", ErrPayloadType, topic, schema.Type, payload)
+	}
+	if v := reflect.ValueOf(payload); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, fmt.Errorf("%w on topic %s: nil %T", ErrPayloadType, topic, payload)
+	}
+	if schema.Version == 0 {
+		return payload, nil
+	}
+	version := payloadVersion(payload)
+	if version > schema.Version {
+		return nil, fmt.Errorf("%w on topic %s: payload version %d is newer than %d",
+			ErrSchemaVersion, topic, version, schema.Version)
+	}
+	if version > 0 {
+		for v := version; v < schema.Version; v++ {
+			convert, ok := schema.converters[v]
+			if !ok {
+				return nil, fmt.Errorf("%w on topic %s: no conversion from version %d",
+					ErrSchemaVersion, topic, v)
+			}
+			converted, err := convert(payload)
+			if err != nil {
+				return nil, fmt.Errorf("topic %s: converting from version %d: %w", topic, v, err)
+			}
+			if reflect.TypeOf(converted) != schema.Type {
+				return nil, fmt.Errorf("%w on topic %s: converter from version %d returned %T",
+					ErrPayloadType, topic, v, converted)
+			}
+			payload = converted
+		}
+	}
+	return stampVersion(payload, schema.Version), nil
+}
+
+// Check reports whether a subscriber built against version of the topic
+// schema can consume the payloads published on topic
+func (r *Registry) Check(topic string, version int) error {
+	schema, ok := r.Schema(topic)
+	if !ok || schema.Version == version {
+		return nil
+	}
+	return fmt.Errorf("%w on topic %s: subscriber expects version %d, payloads are version %d",
+		ErrSchemaVersion, topic, version, schema.Version)
+}
+
+// Decode returns the payload of event as T
+func Decode[T any](event Event) (T, error) {
+	payload, ok := event.Payload.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: want %T, got %T", ErrPayloadType, zero, event.Payload)
+	}
+	return payload, nil
+}
+
+func versionField(t reflect.Type) (reflect.StructField, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	field, ok := t.FieldByName(SchemaVersionField)
+	if !ok || field.Type.Kind() != reflect.Int {
+		return reflect.StructField{}, false
+	}
+	return field, true
+}
+
+func payloadVersion(payload any) int {
+	v := reflect.Indirect(reflect.ValueOf(payload))
+	return int(v.FieldByName(SchemaVersionField).Int())
+}
+
+// stampVersion sets the version field in place for pointer payloads and on a
+// copy for value payloads
+func stampVersion(payload any, version int) any {
+	v := reflect.ValueOf(payload)
+	if v.Kind() == reflect.Pointer {
+		v.Elem().FieldByName(SchemaVersionField).SetInt(int64(version))
+		return payload
+	}
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	copied.FieldByName(SchemaVersionField).SetInt(int64(version))
+	return copied.Interface()
+}
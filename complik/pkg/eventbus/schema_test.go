@@ -0,0 +1,136 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type versionedPayload struct {
+	SchemaVersion int
+	Name          string
+	Label         string
+}
+
+type unversionedPayload struct {
+	Name string
+}
+
+var _ = Describe("Registry", func() {
+	var registry *Registry
+
+	BeforeEach(func() {
+		registry = NewRegistry()
+		Expect(registry.Register("versioned", &versionedPayload{}, 2)).To(Succeed())
+		Expect(registry.RegisterConverter("versioned", 1, func(payload any) (any, error) {
+			p := payload.(*versionedPayload)
+			p.Label = "upgraded"
+			return p, nil
+		})).To(Succeed())
+		Expect(registry.Register("value", unversionedPayload{}, 0)).To(Succeed())
+	})
+
+	Describe("Register", func() {
+		It("should reject versioned types without a version field", func() {
+			Expect(registry.Register("other", &unversionedPayload{}, 1)).NotTo(Succeed())
+		})
+
+		It("should reject duplicate topics", func() {
+			Expect(registry.Register("versioned", &versionedPayload{}, 2)).NotTo(Succeed())
+		})
+
+		It("should reject converters outside the version range", func() {
+			Expect(registry.RegisterConverter("versioned", 2, nil)).NotTo(Succeed())
+			Expect(registry.RegisterConverter("missing", 1, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Normalize", func() {
+		It("should stamp unversioned payloads with the current version", func() {
+			payload, err := registry.Normalize("versioned", &versionedPayload{Name: "a"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload.(*versionedPayload).SchemaVersion).To(Equal(2))
+			Expect(payload.(*versionedPayload).Label).To(BeEmpty())
+		})
+
+		It("should convert older payloads", func() {
+			payload, err := registry.Normalize("versioned", &versionedPayload{SchemaVersion: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload.(*versionedPayload).SchemaVersion).To(Equal(2))
+			Expect(payload.(*versionedPayload).Label).To(Equal("upgraded"))
+		})
+
+		It("should reject newer payloads", func() {
+			_, err := registry.Normalize("versioned", &versionedPayload{SchemaVersion: 3})
+			Expect(errors.Is(err, ErrSchemaVersion)).To(BeTrue())
+		})
+
+		It("should reject payloads of another type", func() {
+			_, err := registry.Normalize("versioned", versionedPayload{})
+			Expect(errors.Is(err, ErrPayloadType)).To(BeTrue())
+			_, err = registry.Normalize("versioned", (*versionedPayload)(nil))
+			Expect(errors.Is(err, ErrPayloadType)).To(BeTrue())
+			_, err = registry.Normalize("value", nil)
+			Expect(errors.Is(err, ErrPayloadType)).To(BeTrue())
+		})
+
+		It("should pass through unregistered topics", func() {
+			payload, err := registry.Normalize("free", 42)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(Equal(42))
+		})
+	})
+
+	Describe("EventBus validation", func() {
+		var eb *EventBus
+
+		BeforeEach(func() {
+			eb = NewEventBus(10)
+			eb.SetRegistry(registry)
+		})
+
+		It("should deliver valid payloads and drop invalid ones", func() {
+			ch := eb.Subscribe("value")
+			Expect(eb.Publish("value", Event{Payload: &unversionedPayload{}})).NotTo(Succeed())
+			Expect(eb.Publish("value", Event{Payload: unversionedPayload{Name: "ok"}})).To(Succeed())
+
+			var received Event
+			Eventually(ch).Should(Receive(&received))
+			Expect(received.Payload).To(Equal(unversionedPayload{Name: "ok"}))
+			Consistently(ch).ShouldNot(Receive())
+		})
+
+		It("should refuse subscribers built against another version", func() {
+			_, err := eb.SubscribeVersion("versioned", 1)
+			Expect(errors.Is(err, ErrSchemaVersion)).To(BeTrue())
+			ch, err := eb.SubscribeVersion("versioned", 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ch).NotTo(BeNil())
+		})
+	})
+
+	Describe("Decode", func() {
+		It("should return typed payloads", func() {
+			payload, err := Decode[*versionedPayload](Event{Payload: &versionedPayload{Name: "a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload.Name).To(Equal("a"))
+			_, err = Decode[*versionedPayload](Event{Payload: "a"})
+			Expect(errors.Is(err, ErrPayloadType)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,71 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import "strings"
+
+const (
+	// TopicSeparator separates the segments of hierarchical topics such as
+	// "compliance.detector"
+	TopicSeparator = "."
+	// WildcardSegment matches exactly one segment of a topic
+	WildcardSegment = "*"
+	// WildcardTail matches any number of segments, including none
+	WildcardTail = "**"
+)
+
+// IsPattern reports whether topic contains wildcard segments
+func IsPattern(topic string) bool {
+	for _, segment := range strings.Split(topic, TopicSeparator) {
+		if segment == WildcardSegment || segment == WildcardTail {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether topic matches pattern. "compliance.*" matches
+// "compliance.detector" but not "compliance" or "compliance.detector.v2",
+// "compliance.**" matches all three.
+func Match(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+	return matchSegments(strings.Split(pattern, TopicSeparator), strings.Split(topic, TopicSeparator))
+}
+
+func matchSegments(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case WildcardTail:
+			for i := 0; i <= len(topic); i++ {
+				if matchSegments(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case WildcardSegment:
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || pattern[0] != topic[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// functions maps the function names to their number of arguments
+var functions = map[string]int{
+	"matches":    2,
+	"startsWith": 2,
+	"endsWith":   2,
+	"contains":   2,
+	"lower":      1,
+	"size":       1,
+}
+
+// match is a matches call with a literal pattern compiled at parse time
+type match struct {
+	subject node
+	re      *regexp.Regexp
+}
+
+// patterns caches the patterns of matches calls built at evaluation time
+var patterns sync.Map
+
+func (n *literal) eval(map[string]any) (any, error) {
+	return n.value, nil
+}
+
+// Missing fields are null
+func (n *field) eval(root map[string]any) (any, error) {
+	return root[n.name], nil
+}
+
+func (n *member) eval(root map[string]any) (any, error) {
+	object, err := n.object.eval(root)
+	if err != nil || object == nil {
+		return nil, err
+	}
+	fields, ok := object.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot select %s of %s", n.name, typeName(object))
+	}
+	return fields[n.name], nil
+}
+
+func (n *index) eval(root map[string]any) (any, error) {
+	object, err := n.object.eval(root)
+	if err != nil || object == nil {
+		return nil, err
+	}
+	key, err := n.key.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	switch object := object.(type) {
+	case map[string]any:
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot index a map with %s", typeName(key))
+		}
+		return object[name], nil
+	case []any:
+		i, ok := key.(float64)
+		if !ok || i != float64(int(i)) {
+			return nil, fmt.Errorf("cannot index a list with %v", key)
+		}
+		if i < 0 || int(i) >= len(object) {
+			return nil, nil
+		}
+		return object[int(i)], nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(object))
+}
+
+func (n *list) eval(root map[string]any) (any, error) {
+	items := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(root)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (n *not) eval(root map[string]any) (any, error) {
+	value, err := evalBool(n.operand, root)
+	if err != nil {
+		return nil, err
+	}
+	return !value, nil
+}
+
+func (n *binary) eval(root map[string]any) (any, error) {
+	switch n.op {
+	case "&&", "||":
+		left, err := evalBool(n.left, root)
+		if err != nil {
+			return nil, err
+		}
+		if left == (n.op == "||") {
+			return left, nil
+		}
+		return evalBool(n.right, root)
+	}
+	left, err := n.left.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	}
+	// Ordering null is false, so missing fields never match
+	if left == nil || right == nil {
+		return false, nil
+	}
+	c, err := compare(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func (n *match) eval(root map[string]any) (any, error) {
+	subject, err := evalString(n.subject, root)
+	if err != nil || subject == nil {
+		return false, err
+	}
+	return n.re.MatchString(*subject), nil
+}
+
+func (n *call) eval(root map[string]any) (any, error) {
+	args := make([]any, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(root)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	switch n.name {
+	case "size":
+		switch value := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(len([]rune(value))), nil
+		case []any:
+			return float64(len(value)), nil
+		case map[string]any:
+			return float64(len(value)), nil
+		}
+		return nil, fmt.Errorf("size of %s", typeName(args[0]))
+	case "lower":
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("lower of %s", typeName(args[0]))
+		}
+		return strings.ToLower(s), nil
+	}
+	if args[0] == nil {
+		return false, nil
+	}
+	s, ok1 := args[0].(string)
+	arg, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%s of %s and %s", n.name, typeName(args[0]), typeName(args[1]))
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	case "contains":
+		return strings.Contains(s, arg), nil
+	}
+	re, err := pattern(arg)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(s), nil
+}
+
+func pattern(s string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(s); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	patterns.Store(s, re)
+	return re, nil
+}
+
+func evalBool(n node, root map[string]any) (bool, error) {
+	value, err := n.eval(root)
+	if err != nil {
+		return false, err
+	}
+	switch value := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return value, nil
+	}
+	return false, fmt.Errorf("expected a boolean, got %s", typeName(value))
+}
+
+func evalString(n node, root map[string]any) (*string, error) {
+	value, err := n.eval(root)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %s", typeName(value))
+	}
+	return &s, nil
+}
+
+func equal(left, right any) bool {
+	return reflect.DeepEqual(left, right)
+}
+
+// contains reports whether item is an element of a list, a substring of a
+// string or a key of a map
+func contains(collection, item any) (bool, error) {
+	switch collection := collection.(type) {
+	case nil:
+		return false, nil
+	case []any:
+		for _, element := range collection {
+			if equal(element, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("%s in string", typeName(item))
+		}
+		return strings.Contains(collection, s), nil
+	case map[string]any:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("%s in map", typeName(item))
+		}
+		_, found := collection[s]
+		return found, nil
+	}
+	return false, fmt.Errorf("in %s", typeName(collection))
+}
+
+// compare orders numbers and strings; two severity levels compare by rank
+func compare(left, right any) (int, error) {
+	switch left := left.(type) {
+	case float64:
+		if right, ok := right.(float64); ok {
+			switch {
+			case left < right:
+				return -1, nil
+			case left > right:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if right, ok := right.(string); ok {
+			l, r := models.SeverityRank(left), models.SeverityRank(right)
+			if l > 0 && r > 0 {
+				return l - r, nil
+			}
+			return strings.Compare(left, right), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
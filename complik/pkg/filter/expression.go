@@ -0,0 +1,379 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filter evaluates the include and exclude expressions of plugin
+// subscriptions against event payloads. The expressions are a small subset
+// of CEL: payload fields addressed by their JSON names, string, number,
+// boolean, null and list literals, the comparison operators, in, !, && and
+// ||, and the functions matches, startsWith, endsWith, contains, lower and
+// size, also callable as methods:
+//
+//	severity >= "high" && namespace.matches("^ns-prod-.*")
+//	"casino" in keywords || workload.kind == "Deployment"
+//
+// Severity levels compare by rank, so "critical" > "high".
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind  int
+	text  string
+	value any
+	pos   int
+}
+
+// comparisons are the binary operators of comparison expressions
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", "."}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(source) && rune(source[end]) != c {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			literal := source[i : end+1]
+			if c == '\'' {
+				literal = `"` + strings.ReplaceAll(literal[1:len(literal)-1], `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(literal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i : end+1], value: value, pos: i})
+			i = end + 1
+		case unicode.IsDigit(c) || c == '-' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1])):
+			end := i + 1
+			for end < len(source) && (unicode.IsDigit(rune(source[end])) || source[end] == '.') {
+				end++
+			}
+			value, err := strconv.ParseFloat(source[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number at %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[i:end], value: value, pos: i})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(source) && (unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end])) || source[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// node is a parsed expression
+type node interface {
+	eval(root map[string]any) (any, error)
+}
+
+type (
+	literal struct{ value any }
+	field   struct{ name string }
+	member  struct {
+		object node
+		name   string
+	}
+	index  struct{ object, key node }
+	list   struct{ items []node }
+	not    struct{ operand node }
+	binary struct {
+		op          string
+		left, right node
+	}
+	call struct {
+		name string
+		args []node
+	}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the operator op if it is next
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expected %q at %d, found %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+// parse parses source into an expression tree
+func parse(source string) (node, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return expr, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right node
+		if right, err = p.and(); err == nil {
+			left = &binary{op: "||", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.comparison()
+	for err == nil && p.accept("&&") {
+		var right node
+		if right, err = p.comparison(); err == nil {
+			left = &binary{op: "&&", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	op := ""
+	switch {
+	case t.kind == tokenOperator && comparisons[t.text]:
+		op = t.text
+	case t.kind == tokenIdent && t.text == "in":
+		op = "in"
+	default:
+		return left, nil
+	}
+	p.next()
+	right, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	expr, err := p.primary()
+	for err == nil {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field or function name at %d", t.pos)
+			}
+			if p.accept("(") {
+				var args []node
+				if args, err = p.args(); err == nil {
+					expr, err = newCall(t.text, append([]node{expr}, args...))
+				}
+			} else {
+				expr = &member{object: expr, name: t.text}
+			}
+		case p.accept("["):
+			var key node
+			if key, err = p.or(); err == nil {
+				if err = p.expect("]"); err == nil {
+					expr = &index{object: expr, key: key}
+				}
+			}
+		default:
+			return expr, nil
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return &literal{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null":
+			return &literal{value: nil}, nil
+		}
+		if p.accept("(") {
+			args, err := p.args()
+			if err != nil {
+				return nil, err
+			}
+			return newCall(t.text, args)
+		}
+		return &field{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			expr, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+		case "[":
+			items, err := p.items("]")
+			if err != nil {
+				return nil, err
+			}
+			return &list{items: items}, nil
+		}
+	}
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// args parses the arguments of a call after its opening parenthesis
+func (p *parser) args() ([]node, error) {
+	return p.items(")")
+}
+
+// items parses expressions separated by commas up to the closing operator
+func (p *parser) items(closing string) ([]node, error) {
+	var items []node
+	if p.accept(closing) {
+		return items, nil
+	}
+	for {
+		item, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.accept(closing) {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// newCall checks the name and arity of a function call and compiles literal
+// regular expressions
+func newCall(name string, args []node) (node, error) {
+	arity, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	if len(args) != arity {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", name, arity, len(args))
+	}
+	if name == "matches" {
+		if pattern, ok := args[1].(*literal); ok {
+			s, ok := pattern.value.(string)
+			if !ok {
+				return nil, fmt.Errorf("matches takes a string pattern")
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", s, err)
+			}
+			return &match{subject: args[0], re: re}, nil
+		}
+	}
+	return &call{name: name, args: args}, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Expression is a compiled filter expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses source
+func Compile(source string) (*Expression, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of e
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates e against the JSON fields of payload
+func (e *Expression) Eval(payload any) (bool, error) {
+	root, err := fields(payload)
+	if err != nil {
+		return false, err
+	}
+	return e.eval(root)
+}
+
+func (e *Expression) eval(root map[string]any) (bool, error) {
+	matched, err := evalBool(e.root, root)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", e.source, err)
+	}
+	return matched, nil
+}
+
+// fields returns the JSON representation of payload, which must be an object
+func fields(payload any) (map[string]any, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("payload %T is not an object", payload)
+	}
+	return root, nil
+}
+
+// Filter selects the events of a subscription: those matching the include
+// expression, every event without one, and not matching the exclude
+// expression. An expression failing on an event does not match it.
+type Filter struct {
+	include *Expression
+	exclude *Expression
+}
+
+// New compiles the include and exclude expressions, either may be empty. It
+// returns nil when both are.
+func New(include, exclude string) (*Filter, error) {
+	if include == "" && exclude == "" {
+		return nil, nil
+	}
+	f := &Filter{}
+	var errs []error
+	var err error
+	if include != "" {
+		if f.include, err = Compile(include); err != nil {
+			errs = append(errs, fmt.Errorf("include: %w", err))
+		}
+	}
+	if exclude != "" {
+		if f.exclude, err = Compile(exclude); err != nil {
+			errs = append(errs, fmt.Errorf("exclude: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return f, nil
+}
+
+// Match reports whether payload passes f. The error reports an expression
+// that failed on payload; the result then follows the rule above.
+func (f *Filter) Match(payload any) (bool, error) {
+	root, err := fields(payload)
+	if err != nil {
+		return f.include == nil, err
+	}
+	var errs []error
+	if f.include != nil {
+		included, err := f.include.eval(root)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if !included {
+			return false, errors.Join(errs...)
+		}
+	}
+	if f.exclude != nil {
+		excluded, err := f.exclude.eval(root)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if excluded {
+			return false, errors.Join(errs...)
+		}
+	}
+	return true, errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFilter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Filter Suite")
+}
+
+var _ = Describe("Expression", func() {
+	result := &models.DetectorInfo{
+		DetectorName: "Safety",
+		Namespace:    "ns-prod-shop",
+		Host:         "shop.example.com",
+		IsIllegal:    true,
+		Severity:     models.SeverityHigh,
+		Keywords:     []string{"casino", "baccarat"},
+		Workload:     &models.WorkloadInfo{Kind: "Deployment", Name: "shop"},
+	}
+
+	DescribeTable("should evaluate against the JSON fields of payloads",
+		func(source string, expected bool) {
+			expr, err := Compile(source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expr.Eval(result)).To(Equal(expected))
+		},
+		Entry("severity rank", `severity >= "high" && namespace.matches("^ns-prod-.*")`, true),
+		Entry("higher severity", `severity > "high"`, false),
+		Entry("critical ranks above high", `"critical" > severity`, true),
+		Entry("boolean field", `is_illegal`, true),
+		Entry("negation", `!is_illegal || host == "shop.example.com"`, true),
+		Entry("list membership", `"casino" in keywords`, true),
+		Entry("value in a list literal", `detector_name in ["Custom", 'Safety']`, true),
+		Entry("nested field", `workload.kind == "Deployment" && workload["name"] == "shop"`, true),
+		Entry("missing field", `workload.owner == "x" || region >= "a"`, false),
+		Entry("missing object", `metadata.title.contains("casino")`, false),
+		Entry("functions", `size(keywords) == 2 && lower("SHOP").startsWith("sh") && host.endsWith(".com")`, true),
+		Entry("index", `keywords[1] == "baccarat" && keywords[5] == null`, true),
+		Entry("pattern from a field", `host.matches(workload.name) && !host.matches(detector_name)`, true),
+	)
+
+	DescribeTable("should reject invalid expressions",
+		func(source string) {
+			_, err := Compile(source)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ``),
+		Entry("unterminated string", `namespace == "ns`),
+		Entry("unknown function", `namespace.glob("ns-*")`),
+		Entry("wrong arity", `matches(namespace)`),
+		Entry("invalid pattern", `namespace.matches("(")`),
+		Entry("dangling operator", `is_illegal &&`),
+		Entry("trailing tokens", `is_illegal is_illegal`),
+	)
+
+	It("should report type errors", func() {
+		expr, err := Compile(`keywords > 1`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = expr.Eval(result)
+		Expect(err).To(MatchError(ContainSubstring("cannot compare list and number")))
+
+		expr, err = Compile(`namespace`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = expr.Eval(result)
+		Expect(err).To(MatchError(ContainSubstring("expected a boolean")))
+	})
+})
+
+var _ = Describe("Filter", func() {
+	high := &models.DetectorInfo{Namespace: "ns-prod-shop", Severity: models.SeverityHigh}
+	low := &models.DetectorInfo{Namespace: "ns-prod-blog", Severity: models.SeverityLow}
+	test := &models.DetectorInfo{Namespace: "ns-prod-test", Severity: models.SeverityCritical}
+
+	It("should select the included events not excluded", func() {
+		f, err := New(`severity >= "high"`, `namespace.endsWith("-test")`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Match(high)).To(BeTrue())
+		Expect(f.Match(low)).To(BeFalse())
+		Expect(f.Match(test)).To(BeFalse())
+
+		f, err = New("", `namespace.endsWith("-test")`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Match(low)).To(BeTrue())
+	})
+
+	It("should not match with an expression failing on the event", func() {
+		f, err := New(`namespace > 1`, "")
+		Expect(err).NotTo(HaveOccurred())
+		matched, err := f.Match(high)
+		Expect(err).To(HaveOccurred())
+		Expect(matched).To(BeFalse())
+
+		f, err = New("", `namespace > 1`)
+		Expect(err).NotTo(HaveOccurred())
+		matched, err = f.Match(high)
+		Expect(err).To(HaveOccurred())
+		Expect(matched).To(BeTrue())
+	})
+
+	It("should be nil without expressions and fail on invalid ones", func() {
+		Expect(New("", "")).To(BeNil())
+		_, err := New(`severity >=`, `namespace ==`)
+		Expect(err).To(MatchError(And(ContainSubstring("include"), ContainSubstring("exclude"))))
+	})
+})
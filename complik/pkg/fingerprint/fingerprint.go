@@ -0,0 +1,203 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fingerprint computes 64-bit similarity hashes of collected pages:
+// a perceptual hash (pHash) of the screenshot and a SimHash of the HTML text.
+// Similar inputs give hashes with a small Hamming distance.
+package fingerprint
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"regexp"
+	"sort"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// phashSize is the side of the grayscale thumbnail the DCT runs on
+	phashSize = 32
+	// phashBits is the side of the low-frequency block kept from the DCT
+	phashBits = 8
+	// shingleRunes is the length of the text shingles of the SimHash
+	shingleRunes = 4
+)
+
+var (
+	scriptPattern = regexp.MustCompile(`(?is)<(script|style|noscript)\b.*?</(script|style|noscript)>`)
+	tagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+	// blockPattern matches the tags that start a new line of visible text
+	blockPattern = regexp.MustCompile(`(?i)</?(address|article|aside|blockquote|br|button|dd|div|dl|dt|footer|form|h[1-6]|header|hr|li|main|nav|ol|option|p|pre|section|table|td|th|title|tr|ul)\b[^>]*>`)
+)
+
+// Distance is the number of differing bits of two hashes
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Image returns the perceptual hash of a PNG or JPEG image. The image is
+// reduced to a 32x32 grayscale thumbnail, and each bit of the hash tells
+// whether one of the 64 lowest DCT frequencies is above their median.
+func Image(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return 0, fmt.Errorf("empty image")
+	}
+	pixels := thumbnail(img)
+	coefficients := dct(pixels)
+
+	low := make([]float64, 0, phashBits*phashBits)
+	for y := range phashBits {
+		for x := range phashBits {
+			low = append(low, coefficients[y][x])
+		}
+	}
+	// The DC term is the mean brightness and is left out of the median
+	sorted := append([]float64(nil), low[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range low {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, nil
+}
+
+// thumbnail averages the pixels of img into a phashSize square of luma values.
+// Full-page screenshots have millions of pixels, so at most thumbnailSamples
+// pixels per side are read.
+func thumbnail(img image.Image) [phashSize][phashSize]float64 {
+	const thumbnailSamples = 256
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	stepX, stepY := max(1, width/thumbnailSamples), max(1, height/thumbnailSamples)
+	var sums, counts [phashSize][phashSize]float64
+	for y := 0; y < height; y += stepY {
+		ty := y * phashSize / height
+		for x := 0; x < width; x += stepX {
+			tx := x * phashSize / width
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			sums[ty][tx] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			counts[ty][tx]++
+		}
+	}
+	var pixels [phashSize][phashSize]float64
+	for y := range phashSize {
+		for x := range phashSize {
+			if counts[y][x] > 0 {
+				pixels[y][x] = sums[y][x] / counts[y][x] / 0xffff
+			}
+		}
+	}
+	return pixels
+}
+
+// dct is the unnormalized two-dimensional DCT-II of pixels, computed by rows
+// then columns
+func dct(pixels [phashSize][phashSize]float64) [phashSize][phashSize]float64 {
+	var cosines [phashSize][phashSize]float64
+	for k := range phashSize {
+		for n := range phashSize {
+			cosines[k][n] = math.Cos(math.Pi / phashSize * (float64(n) + 0.5) * float64(k))
+		}
+	}
+	var rows, out [phashSize][phashSize]float64
+	for y := range phashSize {
+		for k := range phashSize {
+			for n := range phashSize {
+				rows[y][k] += pixels[y][n] * cosines[k][n]
+			}
+		}
+	}
+	for x := range phashSize {
+		for k := range phashSize {
+			for n := range phashSize {
+				out[k][x] += rows[n][x] * cosines[k][n]
+			}
+		}
+	}
+	return out
+}
+
+// Text returns the SimHash of the visible text of an HTML page. Scripts,
+// styles and markup are dropped and whitespace is collapsed, so reformatting
+// does not change the hash; the text is split into overlapping shingles of
+// four characters, which works for languages without word separators.
+func Text(html string) uint64 {
+	text := scriptPattern.ReplaceAllString(html, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	runes := []rune(strings.ToLower(strings.Join(strings.Fields(text), " ")))
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle []rune) {
+		h := fnv.New64a()
+		h.Write([]byte(string(shingle)))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	if len(runes) < shingleRunes {
+		add(runes)
+	}
+	for i := 0; i+shingleRunes <= len(runes); i++ {
+		add(runes[i : i+shingleRunes])
+	}
+
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// Lines returns the visible text of an HTML page split at block elements.
+// Scripts, styles and markup are dropped and whitespace is collapsed within
+// each line; empty lines are left out.
+func Lines(html string) []string {
+	text := scriptPattern.ReplaceAllString(html, " ")
+	text = blockPattern.ReplaceAllString(text, "\n")
+	text = tagPattern.ReplaceAllString(text, " ")
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
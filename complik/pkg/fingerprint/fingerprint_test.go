@@ -0,0 +1,136 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fingerprint
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFingerprint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fingerprint Suite")
+}
+
+// page draws a page-like image at any size: a header bar and blocks of
+// "text" lines laid out on a 640x480 grid
+func page(width, height int, header color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		gy := y * 480 / height
+		for x := range width {
+			gx := x * 640 / width
+			c := color.Color(color.White)
+			switch {
+			case gy < 60:
+				c = header
+			case (gy/12)%3 == 0 && gx > 64 && gx < 64*(6+(gy/36)%3):
+				c = color.Gray{Y: 40}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("Image", func() {
+	blue := color.RGBA{R: 30, G: 60, B: 200, A: 255}
+
+	It("should give re-encoded and resized screenshots close hashes", func() {
+		original, err := Image(encodePNG(page(640, 480, blue)))
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		Expect(jpeg.Encode(&buf, page(1280, 960, blue), &jpeg.Options{Quality: 60})).To(Succeed())
+		resized, err := Image(buf.Bytes())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Distance(original, resized)).To(BeNumerically("<=", 4))
+	})
+
+	It("should tell different layouts apart", func() {
+		original, err := Image(encodePNG(page(640, 480, blue)))
+		Expect(err).NotTo(HaveOccurred())
+
+		changed := image.NewRGBA(image.Rect(0, 0, 640, 480))
+		for y := range 480 {
+			for x := range 640 {
+				c := color.Color(color.White)
+				if (x/80+y/80)%2 == 0 {
+					c = color.RGBA{R: 200, A: 255}
+				}
+				changed.Set(x, y, c)
+			}
+		}
+		other, err := Image(encodePNG(changed))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Distance(original, other)).To(BeNumerically(">", 10))
+	})
+
+	It("should reject data that is not an image", func() {
+		_, err := Image([]byte("<html>"))
+		Expect(err).To(MatchError(ContainSubstring("failed to decode image")))
+	})
+})
+
+var _ = Describe("Text", func() {
+	const article = `<html><head><style>body{color:red}</style></head><body>
+		<h1>Welcome to the bakery</h1><p>Fresh bread every morning, cakes on order and
+		coffee all day long. Visit us at the market square.</p></body></html>`
+
+	It("should ignore markup, scripts and whitespace", func() {
+		reformatted := `<html><body><div class="x"><h1>Welcome   to the bakery</h1>
+			<script>var t = Date.now()</script><p>Fresh bread every morning, cakes on order and coffee
+			all day long. Visit us at the market square.</p></div></body></html>`
+		Expect(Text(reformatted)).To(Equal(Text(article)))
+	})
+
+	It("should keep small edits close and new content far", func() {
+		edited := `<h1>Welcome to the bakery</h1><p>Fresh bread every morning, cakes on order and
+			coffee all day long. Visit us at the market square!</p>`
+		Expect(Distance(Text(article), Text(edited))).To(BeNumerically("<=", 3))
+
+		gambling := `<h1>在线赌场</h1><p>百家乐 老虎机 真人荷官，注册即送彩金，USDT 充值秒到账</p>`
+		Expect(Distance(Text(article), Text(gambling))).To(BeNumerically(">", 10))
+	})
+
+	It("should hash empty pages to zero", func() {
+		Expect(Text("<html><body> </body></html>")).To(BeZero())
+	})
+})
+
+var _ = Describe("Lines", func() {
+	It("should split the visible text at block elements", func() {
+		html := `<html><head><title>Bakery</title><script>var t = 1</script></head><body>
+			<h1>Welcome to   the <b>bakery</b></h1><p>Fresh bread<br>Cakes on order</p>
+			<div> </div><ul><li>Coffee</li></ul></body></html>`
+		Expect(Lines(html)).To(Equal([]string{
+			"Bakery", "Welcome to the bakery", "Fresh bread", "Cakes on order", "Coffee",
+		}))
+		Expect(Lines("<html><body> </body></html>")).To(BeEmpty())
+	})
+})
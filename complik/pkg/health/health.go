@@ -0,0 +1,227 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health serves the /healthz and /readyz endpoints used by the
+// Kubernetes liveness and readiness probes, and the /metrics of the process.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultCheckTimeout bounds every single check of a probe request
+const DefaultCheckTimeout = 3 * time.Second
+
+// Check reports the health of one dependency, nil when it is healthy
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a single check
+type Result struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Response is the body served by both endpoints
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Server runs registered checks on probe requests
+type Server struct {
+	log          logger.Logger
+	addr         string
+	checkTimeout time.Duration
+	server       *http.Server
+	registry     *prometheus.Registry
+
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// NewServer creates a probe server listening on addr
+func NewServer(addr string) *Server {
+	return &Server{
+		log:          logger.GetLogger().WithField("component", "health"),
+		addr:         addr,
+		checkTimeout: DefaultCheckTimeout,
+		registry:     prometheus.NewRegistry(),
+	}
+}
+
+// AddCollector serves the metrics of collector on /metrics
+func (s *Server) AddCollector(collector prometheus.Collector) error {
+	return s.registry.Register(collector)
+}
+
+// AddLivenessCheck adds a check to /healthz. Liveness checks should only fail
+// when restarting the process helps, so they must not cover external services.
+func (s *Server) AddLivenessCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.liveness = append(s.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds a check to /readyz
+func (s *Server) AddReadinessCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readiness = append(s.readiness, namedCheck{name: name, check: check})
+}
+
+// Handler returns the probe endpoints. /readyz also runs the liveness checks.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		checks := append([]namedCheck(nil), s.liveness...)
+		s.mu.RUnlock()
+		s.serve(w, r, checks)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		checks := append(append([]namedCheck(nil), s.liveness...), s.readiness...)
+		s.mu.RUnlock()
+		s.serve(w, r, checks)
+	})
+	mux.Handle("GET /metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+// Start serves the probe endpoints in the background
+func (s *Server) Start() {
+	s.server = &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		s.log.Info("Starting health server", logger.Fields{"addr": s.addr})
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("Health server stopped", logger.Fields{"error": err.Error()})
+		}
+	}()
+}
+
+// Stop shuts the probe server down
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, checks []namedCheck) {
+	response := s.run(r.Context(), checks)
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+		s.log.Warn("Health check failed", logger.Fields{"path": r.URL.Path, "checks": response.Checks})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// run executes checks concurrently, each bounded by the check timeout
+func (s *Server) run(ctx context.Context, checks []namedCheck) Response {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+			defer cancel()
+			results[i] = Result{Status: "ok"}
+			if err := c.check(checkCtx); err != nil {
+				results[i] = Result{Status: "fail", Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	response := Response{Status: "ok", Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		response.Checks[c.name] = results[i]
+		if results[i].Status != "ok" {
+			response.Status = "fail"
+		}
+	}
+	return response
+}
+
+// EventBusCheck verifies that events published on the bus are delivered
+func EventBusCheck(eventBus *eventbus.EventBus) Check {
+	events := eventBus.Subscribe(constants.HealthTopic)
+	var mu sync.Mutex
+	var sequence int
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sequence++
+		want := sequence
+		go func() {
+			_ = eventBus.Publish(constants.HealthTopic, eventbus.Event{Payload: want})
+		}()
+		for {
+			select {
+			case event := <-events:
+				// Replies to checks that timed out earlier are skipped
+				if event.Payload == want {
+					return nil
+				}
+			case <-ctx.Done():
+				return fmt.Errorf("event was not delivered: %w", ctx.Err())
+			}
+		}
+	}
+}
+
+// Errors combines the failures of a set of checks, e.g. one per plugin, into a
+// single error
+func Errors(failures map[string]error) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("%s: %w", name, failures[name]))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health Suite")
+}
+
+var _ = Describe("Server", func() {
+	var server *Server
+
+	get := func(path string) (int, Response) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var response Response
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+		return recorder.Code, response
+	}
+
+	BeforeEach(func() {
+		server = NewServer(":0")
+		server.AddLivenessCheck("live", func(context.Context) error { return nil })
+	})
+
+	It("should report ok when all checks pass", func() {
+		server.AddReadinessCheck("ready", func(context.Context) error { return nil })
+		code, response := get("/readyz")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(response.Status).To(Equal("ok"))
+		Expect(response.Checks).To(HaveKey("live"))
+		Expect(response.Checks).To(HaveKey("ready"))
+	})
+
+	It("should fail readiness without affecting liveness", func() {
+		server.AddReadinessCheck("db", func(context.Context) error { return errors.New("unreachable") })
+		code, response := get("/readyz")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(response.Checks["db"]).To(Equal(Result{Status: "fail", Error: "unreachable"}))
+
+		code, response = get("/healthz")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(response.Checks).NotTo(HaveKey("db"))
+	})
+
+	It("should bound slow checks by the check timeout", func() {
+		server.checkTimeout = 50 * time.Millisecond
+		server.AddReadinessCheck("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		code, _ := get("/readyz")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should serve the metrics of added collectors", func() {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "complik_test_total", Help: "Test counter."})
+		counter.Inc()
+		Expect(server.AddCollector(counter)).To(Succeed())
+		Expect(server.AddCollector(counter)).NotTo(Succeed())
+
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring("complik_test_total 1"))
+	})
+})
+
+var _ = Describe("EventBusCheck", func() {
+	It("should succeed while events are delivered", func() {
+		check := EventBusCheck(eventbus.NewEventBus(10))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		Expect(check(ctx)).To(Succeed())
+		Expect(check(ctx)).To(Succeed())
+	})
+})
+
+var _ = Describe("Errors", func() {
+	It("should combine failures in name order", func() {
+		Expect(Errors(nil)).To(Succeed())
+		err := Errors(map[string]error{"b": errors.New("down"), "a": errors.New("timeout")})
+		Expect(err).To(MatchError("a: timeout\nb: down"))
+	})
+})
@@ -0,0 +1,66 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP API Suite")
+}
+
+var _ = Describe("RequireBearer", func() {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	get := func(handler http.Handler, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should only serve requests carrying the token", func() {
+		handler := RequireBearer("secret", ok)
+		Expect(get(handler, "Bearer secret").Code).To(Equal(http.StatusOK))
+		Expect(get(handler, "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(get(handler, "Bearer wrong").Code).To(Equal(http.StatusUnauthorized))
+
+		rec := get(handler, "Bearer ")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		var body map[string]string
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(map[string]string{"error": "invalid token"}))
+	})
+
+	It("should refuse every request without a token", func() {
+		handler := RequireBearer("", ok)
+		Expect(get(handler, "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(get(handler, "Bearer ").Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -15,6 +15,9 @@
 package k8s
 
 import (
+	"context"
+	"errors"
+
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -43,3 +46,11 @@ func InitClient(kubeconfigPath string) error {
 	}
 	return nil
 }
+
+// Ping checks that the API server is reachable with the initialized client
+func Ping(ctx context.Context) error {
+	if ClientSet == nil {
+		return errors.New("kubernetes client is not initialized")
+	}
+	return ClientSet.Discovery().RESTClient().Get().AbsPath("/readyz").Do(ctx).Error()
+}
@@ -0,0 +1,267 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loadtest drives the detection pipeline with synthesized discovery
+// events and fake collected pages, and measures its throughput and memory,
+// so capacity can be planned without touching production targets.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Name is the discovery and collector name of the synthesized events
+const Name = "LoadTest"
+
+// hostSuffix keeps synthesized hosts out of any real DNS zone
+const hostSuffix = ".loadtest.invalid"
+
+// flaggedKeyword is matched by the stub reviewer of dry-run detectors
+const flaggedKeyword = "online casino"
+
+// pngSignature starts every fake screenshot
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// Config describes the generated load
+type Config struct {
+	// Rate is the number of discovery events per second, 50 by default
+	Rate int `json:"rate"`
+	// Duration is how long events are generated, one minute by default
+	Duration time.Duration `json:"duration"`
+	// Drain is how long in-flight events may still be detected after the
+	// generation stopped, 30 seconds by default
+	Drain time.Duration `json:"drain"`
+	// Namespaces is the number of namespaces the targets are spread over,
+	// 10000 by default
+	Namespaces int `json:"namespaces"`
+	// HTMLBytes and ScreenshotBytes are the sizes of every fake page, 20 KB
+	// and 200 KB by default; a negative size leaves the payload out
+	HTMLBytes       int `json:"html_bytes"`
+	ScreenshotBytes int `json:"screenshot_bytes"`
+	// FlaggedRatio is the share of pages containing a keyword the stub
+	// reviewer flags, 0.01 by default
+	FlaggedRatio float64 `json:"flagged_ratio"`
+	// SampleInterval is how often throughput and memory are sampled, five
+	// seconds by default
+	SampleInterval time.Duration `json:"sample_interval"`
+}
+
+// WithDefaults returns the config with defaults for the unset fields
+func (c Config) WithDefaults() Config {
+	if c.Rate <= 0 {
+		c.Rate = 50
+	}
+	if c.Duration <= 0 {
+		c.Duration = time.Minute
+	}
+	if c.Drain <= 0 {
+		c.Drain = 30 * time.Second
+	}
+	if c.Namespaces <= 0 {
+		c.Namespaces = 10000
+	}
+	if c.HTMLBytes == 0 {
+		c.HTMLBytes = 20 * 1024
+	}
+	if c.ScreenshotBytes == 0 {
+		c.ScreenshotBytes = 200 * 1024
+	}
+	if c.FlaggedRatio <= 0 {
+		c.FlaggedRatio = 0.01
+	}
+	if c.FlaggedRatio > 1 {
+		c.FlaggedRatio = 1
+	}
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = 5 * time.Second
+	}
+	return c
+}
+
+// Generator publishes synthesized discovery events at the configured rate and
+// stands in for the collectors by answering them with fake pages
+type Generator struct {
+	log   logger.Logger
+	cfg   Config
+	meter *Meter
+
+	published atomic.Int64
+	collected atomic.Int64
+
+	page        string
+	flaggedPage string
+}
+
+// NewGenerator creates a generator whose events are timed by meter
+func NewGenerator(cfg Config, meter *Meter) *Generator {
+	cfg = cfg.WithDefaults()
+	return &Generator{
+		log:         logger.GetLogger().WithField("component", "loadtest"),
+		cfg:         cfg,
+		meter:       meter,
+		page:        fakePage(cfg.HTMLBytes, ""),
+		flaggedPage: fakePage(cfg.HTMLBytes, flaggedKeyword),
+	}
+}
+
+// Start subscribes the fake collector to the discovery topic. It must be
+// called before Run.
+func (g *Generator) Start(ctx context.Context, eventBus *eventbus.EventBus) {
+	subscribe := eventBus.Subscribe(constants.DiscoveryTopic)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-subscribe:
+				discovery, ok := event.Payload.(models.DiscoveryInfo)
+				if !ok || discovery.DiscoveryName != Name {
+					continue
+				}
+				if err := eventBus.Publish(constants.CollectorTopic, eventbus.Event{Payload: g.collect(discovery)}); err == nil {
+					g.collected.Add(1)
+				}
+			}
+		}
+	}()
+}
+
+// Run publishes discovery events until the duration passed or ctx is done
+// and returns the number of published events
+func (g *Generator) Run(ctx context.Context, eventBus *eventbus.EventBus) int64 {
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.Duration)
+	defer cancel()
+
+	g.log.Info("Generating load", logger.Fields{
+		"rate":             g.cfg.Rate,
+		"duration":         g.cfg.Duration.String(),
+		"namespaces":       g.cfg.Namespaces,
+		"html_bytes":       g.cfg.HTMLBytes,
+		"screenshot_bytes": g.cfg.ScreenshotBytes,
+	})
+	// Events are published in small bursts that keep the average rate exact
+	// without a timer per event
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return g.published.Load()
+		case now := <-ticker.C:
+			due := int64(now.Sub(start).Seconds() * float64(g.cfg.Rate))
+			for g.published.Load() < due && ctx.Err() == nil {
+				g.publish(eventBus, g.published.Load())
+			}
+		}
+	}
+}
+
+// Published returns the number of discovery events published so far
+func (g *Generator) Published() int64 {
+	return g.published.Load()
+}
+
+// Collected returns the number of fake pages published so far
+func (g *Generator) Collected() int64 {
+	return g.collected.Load()
+}
+
+func (g *Generator) publish(eventBus *eventbus.EventBus, index int64) {
+	discovery := models.DiscoveryInfo{
+		DiscoveryName: Name,
+		Name:          fmt.Sprintf("load-%d", index),
+		Namespace:     fmt.Sprintf("ns-load-%d", index%int64(g.cfg.Namespaces)),
+		Host:          fmt.Sprintf("load-%d%s", index, hostSuffix),
+		Path:          []string{"/"},
+		ServiceName:   fmt.Sprintf("load-%d", index),
+		ServicePort:   80,
+		HasActivePods: true,
+		PodCount:      1,
+	}
+	g.meter.Discovered(discovery.Host)
+	g.published.Add(1)
+	if err := eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{Payload: discovery}); err != nil {
+		g.meter.Forget(discovery.Host)
+	}
+}
+
+// collect builds the fake page of a discovery. Every page gets its own copy
+// of the HTML and screenshot, like pages collected from real sites.
+func (g *Generator) collect(discovery models.DiscoveryInfo) *models.CollectorInfo {
+	page := g.page
+	if g.flagged(discovery.Name) {
+		page = g.flaggedPage
+	}
+	info := &models.CollectorInfo{
+		DiscoveryName: discovery.DiscoveryName,
+		CollectorName: Name,
+		Name:          discovery.Name,
+		Namespace:     discovery.Namespace,
+		Host:          discovery.Host,
+		Path:          discovery.Path,
+		URL:           "http://" + discovery.Host,
+		ScanRunID:     discovery.ScanRunID,
+	}
+	if g.cfg.HTMLBytes > 0 {
+		info.HTML = page + "<!-- " + discovery.Name + " -->"
+	} else {
+		info.IsEmpty = true
+	}
+	if g.cfg.ScreenshotBytes > 0 {
+		info.Screenshot = make([]byte, g.cfg.ScreenshotBytes)
+		copy(info.Screenshot, pngSignature)
+	}
+	return info
+}
+
+// flagged spreads the flagged pages evenly over the generated targets
+func (g *Generator) flagged(name string) bool {
+	index, err := strconv.ParseInt(strings.TrimPrefix(name, "load-"), 10, 64)
+	if err != nil {
+		return false
+	}
+	every := int64(1 / g.cfg.FlaggedRatio)
+	return index%every == every-1
+}
+
+// fakePage returns an HTML page of about size bytes, containing keyword when
+// it is not empty
+func fakePage(size int, keyword string) string {
+	if size <= 0 {
+		return ""
+	}
+	const paragraph = "<p>Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor.</p>\n"
+	var b strings.Builder
+	b.Grow(size + len(paragraph))
+	b.WriteString("<html><head><title>Load test</title></head><body>\n")
+	if keyword != "" {
+		b.WriteString("<h1>" + keyword + "</h1>\n")
+	}
+	for b.Len() < size-len("</body></html>") {
+		b.WriteString(paragraph)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadTest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Load Test Suite")
+}
+
+// startDetector flags the collected pages containing the stub keyword
+func startDetector(eb *eventbus.EventBus) {
+	subscribe := eb.Subscribe(constants.CollectorTopic)
+	go func() {
+		for event := range subscribe {
+			content := event.Payload.(*models.CollectorInfo)
+			_ = eb.Publish(constants.DetectorTopic, eventbus.Event{Payload: &models.DetectorInfo{
+				DetectorName: "Fake",
+				Host:         content.Host,
+				Namespace:    content.Namespace,
+				IsIllegal:    strings.Contains(content.HTML, "casino"),
+			}})
+		}
+	}()
+}
+
+var _ = Describe("Run", func() {
+	It("should measure every synthesized target through the pipeline", func() {
+		eb := eventbus.NewEventBus(100)
+		startDetector(eb)
+
+		report := Run(context.Background(), eb, Config{
+			Rate:            400,
+			Duration:        500 * time.Millisecond,
+			Namespaces:      7,
+			HTMLBytes:       2048,
+			ScreenshotBytes: 1024,
+			FlaggedRatio:    0.1,
+			SampleInterval:  100 * time.Millisecond,
+		})
+		Expect(report.Published).To(BeNumerically(">=", 150))
+		Expect(report.Collected).To(Equal(report.Published))
+		Expect(report.Detected).To(Equal(report.Published))
+		Expect(report.Lost).To(BeZero())
+		Expect(report.Flagged).To(Equal(report.Published / 10))
+		Expect(report.Detectors).To(Equal([]string{"Fake"}))
+		Expect(report.Throughput).To(BeNumerically(">", 0))
+		Expect(report.LatencyMax).To(BeNumerically(">=", report.LatencyP50))
+		Expect(report.Samples).NotTo(BeEmpty())
+		Expect(report.PeakHeapMB).To(BeNumerically(">", 0))
+
+		path := filepath.Join(GinkgoT().TempDir(), "report.json")
+		Expect(report.WriteReport(path)).To(Succeed())
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		var written RunReport
+		Expect(json.Unmarshal(data, &written)).To(Succeed())
+		Expect(written.Detected).To(Equal(report.Detected))
+	})
+
+	It("should report targets that were not detected within the drain period as lost", func() {
+		eb := eventbus.NewEventBus(100)
+		report := Run(context.Background(), eb, Config{
+			Rate:     100,
+			Duration: 200 * time.Millisecond,
+			Drain:    100 * time.Millisecond,
+		})
+		Expect(report.Published).To(BeNumerically(">", 0))
+		Expect(report.Detected).To(BeZero())
+		Expect(report.Lost).To(Equal(report.Published))
+	})
+})
+
+var _ = Describe("Generator", func() {
+	It("should build pages of the configured size", func() {
+		g := NewGenerator(Config{HTMLBytes: 4096, ScreenshotBytes: 512, FlaggedRatio: 0.5}, NewMeter())
+		page := g.collect(models.DiscoveryInfo{Name: "load-1", Namespace: "ns-load-1", Host: "load-1" + hostSuffix})
+		Expect(len(page.HTML)).To(BeNumerically("~", 4096, 128))
+		Expect(page.HTML).To(ContainSubstring(flaggedKeyword))
+		Expect(page.Screenshot).To(HaveLen(512))
+		Expect(page.Screenshot[:len(pngSignature)]).To(Equal(pngSignature))
+		Expect(page.URL).To(Equal("http://load-1.loadtest.invalid"))
+
+		page = g.collect(models.DiscoveryInfo{Name: "load-2"})
+		Expect(page.HTML).NotTo(ContainSubstring(flaggedKeyword))
+
+		empty := NewGenerator(Config{HTMLBytes: -1, ScreenshotBytes: -1}, NewMeter())
+		page = empty.collect(models.DiscoveryInfo{Name: "load-1"})
+		Expect(page.IsEmpty).To(BeTrue())
+		Expect(page.HTML).To(BeEmpty())
+		Expect(page.Screenshot).To(BeNil())
+	})
+})
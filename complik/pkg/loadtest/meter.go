@@ -0,0 +1,235 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Sample is the state of the pipeline at one point of the run
+type Sample struct {
+	Elapsed    time.Duration `json:"elapsed"`
+	Published  int64         `json:"published"`
+	Collected  int64         `json:"collected"`
+	Detected   int64         `json:"detected"`
+	InFlight   int64         `json:"in_flight"`
+	HeapMB     float64       `json:"heap_mb"`
+	Goroutines int           `json:"goroutines"`
+}
+
+// RunReport summarises a load test
+type RunReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Config     Config    `json:"config"`
+	Detectors  []string  `json:"detectors"`
+
+	Published int64 `json:"published"`
+	Collected int64 `json:"collected"`
+	Detected  int64 `json:"detected"`
+	Flagged   int64 `json:"flagged"`
+	// Lost is the number of published targets without a detection by the
+	// end of the drain period
+	Lost int64 `json:"lost"`
+	// Throughput is the rate of detections over the generation period,
+	// below Config.Rate when the pipeline cannot keep up
+	Throughput float64 `json:"throughput_per_second"`
+
+	LatencyP50 time.Duration `json:"latency_p50"`
+	LatencyP95 time.Duration `json:"latency_p95"`
+	LatencyP99 time.Duration `json:"latency_p99"`
+	LatencyMax time.Duration `json:"latency_max"`
+
+	PeakHeapMB     float64 `json:"peak_heap_mb"`
+	PeakGoroutines int     `json:"peak_goroutines"`
+	PeakInFlight   int64   `json:"peak_in_flight"`
+
+	Samples []Sample `json:"samples"`
+}
+
+// Meter times every synthesized target from its discovery to its first
+// detection and samples the memory of the process
+type Meter struct {
+	mu             sync.Mutex
+	started        time.Time
+	pending        map[string]time.Time
+	latencies      []time.Duration
+	detected       int64
+	flagged        int64
+	detectors      map[string]bool
+	samples        []Sample
+	peakHeap       uint64
+	peakGoroutines int
+	peakFlight     int64
+}
+
+// NewMeter creates a meter whose clock starts now
+func NewMeter() *Meter {
+	return &Meter{
+		started:   time.Now(),
+		pending:   make(map[string]time.Time),
+		detectors: make(map[string]bool),
+	}
+}
+
+// Start subscribes to the detector topic. It must be called before the
+// generator runs.
+func (m *Meter) Start(eventBus *eventbus.EventBus) {
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	go func() {
+		for event := range subscribe {
+			if result, ok := event.Payload.(*models.DetectorInfo); ok {
+				m.Detected(result)
+			}
+		}
+	}()
+}
+
+// Discovered starts the clock of a target
+func (m *Meter) Discovered(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[host] = time.Now()
+}
+
+// Forget stops the clock of a target whose discovery was rejected
+func (m *Meter) Forget(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, host)
+}
+
+// Detected records a detector result. Results of targets that were not
+// synthesized or were already detected by another detector only count
+// towards the detectors.
+func (m *Meter) Detected(result *models.DetectorInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.detectors[result.DetectorName] = true
+	discovered, ok := m.pending[result.Host]
+	if !ok {
+		return
+	}
+	delete(m.pending, result.Host)
+	m.latencies = append(m.latencies, time.Since(discovered))
+	m.detected++
+	if result.IsIllegal {
+		m.flagged++
+	}
+}
+
+// InFlight returns the number of discovered targets without a detection
+func (m *Meter) InFlight() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.pending))
+}
+
+// Sample records the current state of the pipeline and the process
+func (m *Meter) Sample(published, collected int64) Sample {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	goroutines := runtime.NumGoroutine()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sample := Sample{
+		Elapsed:    time.Since(m.started).Round(time.Millisecond),
+		Published:  published,
+		Collected:  collected,
+		Detected:   m.detected,
+		InFlight:   int64(len(m.pending)),
+		HeapMB:     megabytes(stats.HeapInuse),
+		Goroutines: goroutines,
+	}
+	m.samples = append(m.samples, sample)
+	m.peakHeap = max(m.peakHeap, stats.HeapInuse)
+	m.peakGoroutines = max(m.peakGoroutines, goroutines)
+	m.peakFlight = max(m.peakFlight, sample.InFlight)
+	return sample
+}
+
+// Report builds the report of a run whose generation took generated
+func (m *Meter) Report(cfg Config, published, collected int64, generated time.Duration) RunReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report := RunReport{
+		StartedAt:      m.started,
+		FinishedAt:     time.Now(),
+		Config:         cfg,
+		Published:      published,
+		Collected:      collected,
+		Detected:       m.detected,
+		Flagged:        m.flagged,
+		Lost:           int64(len(m.pending)),
+		PeakHeapMB:     megabytes(m.peakHeap),
+		PeakGoroutines: m.peakGoroutines,
+		PeakInFlight:   m.peakFlight,
+		Samples:        append([]Sample{}, m.samples...),
+	}
+	for name := range m.detectors {
+		report.Detectors = append(report.Detectors, name)
+	}
+	sort.Strings(report.Detectors)
+	if generated > 0 {
+		report.Throughput = float64(m.detected) / generated.Seconds()
+	}
+	if len(m.latencies) > 0 {
+		latencies := append([]time.Duration{}, m.latencies...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.LatencyP50 = percentile(latencies, 0.50)
+		report.LatencyP95 = percentile(latencies, 0.95)
+		report.LatencyP99 = percentile(latencies, 0.99)
+		report.LatencyMax = latencies[len(latencies)-1]
+	}
+	return report
+}
+
+// WriteReport writes the report as indented JSON to path
+func (r RunReport) WriteReport(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal load test report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write load test report: %w", err)
+	}
+	return nil
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted)-1) * p)
+	return sorted[index]
+}
+
+func megabytes(bytes uint64) float64 {
+	return float64(bytes) / (1 << 20)
+}
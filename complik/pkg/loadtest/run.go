@@ -0,0 +1,87 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Run generates the load of cfg on eventBus, whose detectors must already be
+// started, and returns the report once the in-flight targets were detected or
+// the drain period passed
+func Run(ctx context.Context, eventBus *eventbus.EventBus, cfg Config) RunReport {
+	cfg = cfg.WithDefaults()
+	log := logger.GetLogger().WithField("component", "loadtest")
+
+	meter := NewMeter()
+	meter.Start(eventBus)
+	generator := NewGenerator(cfg, meter)
+	generator.Start(ctx, eventBus)
+
+	sampleCtx, stopSampling := context.WithCancel(ctx)
+	defer stopSampling()
+	go func() {
+		ticker := time.NewTicker(cfg.SampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sampleCtx.Done():
+				return
+			case <-ticker.C:
+				sample := meter.Sample(generator.Published(), generator.Collected())
+				log.Info("Load test progress", logger.Fields{
+					"elapsed":    sample.Elapsed.String(),
+					"published":  sample.Published,
+					"collected":  sample.Collected,
+					"detected":   sample.Detected,
+					"in_flight":  sample.InFlight,
+					"heap_mb":    int(sample.HeapMB),
+					"goroutines": sample.Goroutines,
+				})
+			}
+		}
+	}()
+
+	start := time.Now()
+	generator.Run(ctx, eventBus)
+	generated := time.Since(start)
+
+	log.Info("Load generation finished, draining in-flight targets", logger.Fields{
+		"in_flight": meter.InFlight(),
+		"drain":     cfg.Drain.String(),
+	})
+	deadline := time.NewTimer(cfg.Drain)
+	defer deadline.Stop()
+	poll := time.NewTicker(100 * time.Millisecond)
+	defer poll.Stop()
+drain:
+	for meter.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			break drain
+		case <-deadline.C:
+			break drain
+		case <-poll.C:
+		}
+	}
+	stopSampling()
+
+	meter.Sample(generator.Published(), generator.Collected())
+	return meter.Report(cfg, generator.Published(), generator.Collected(), generated)
+}
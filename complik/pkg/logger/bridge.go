@@ -0,0 +1,136 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"k8s.io/klog/v2"
+)
+
+// ComponentField names the library or component a bridged log line comes from
+const ComponentField = "component"
+
+// RedirectKlog routes the klog output of client-go through the global logger.
+// Verbosity 0 lines are logged at info level and higher verbosities at debug
+// level.
+func RedirectKlog() {
+	klog.SetLogger(logr.New(&klogSink{log: bridged(GetLogger().WithField(ComponentField, "klog"))}))
+}
+
+// bridged turns off the caller fields of log, which would always point at the
+// bridge instead of the library call site
+func bridged(log Logger) Logger {
+	if std, ok := log.(*StandardLogger); ok {
+		std.mu.Lock()
+		std.showCaller = false
+		std.mu.Unlock()
+	}
+	return log
+}
+
+// klogSink adapts Logger to the logr interface klog logs through
+type klogSink struct {
+	log  Logger
+	name string
+}
+
+func (s *klogSink) Init(logr.RuntimeInfo) {}
+
+func (s *klogSink) Enabled(int) bool {
+	return true
+}
+
+func (s *klogSink) Info(level int, msg string, keysAndValues ...any) {
+	if level > 0 {
+		s.log.Debug(msg, s.fields(keysAndValues))
+		return
+	}
+	s.log.Info(msg, s.fields(keysAndValues))
+}
+
+func (s *klogSink) Error(err error, msg string, keysAndValues ...any) {
+	fields := s.fields(keysAndValues)
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	s.log.Error(msg, fields)
+}
+
+func (s *klogSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &klogSink{log: s.log.WithFields(s.fields(keysAndValues)), name: s.name}
+}
+
+func (s *klogSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &klogSink{log: s.log.WithField("logger", name), name: name}
+}
+
+func (s *klogSink) fields(keysAndValues []any) Fields {
+	fields := make(Fields, len(keysAndValues)/2+1)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	if len(keysAndValues)%2 == 1 {
+		fields["extra"] = keysAndValues[len(keysAndValues)-1]
+	}
+	return fields
+}
+
+// RedirectLogrus routes the entries of a logrus logger, such as the one of
+// ProcScan, through the global logger with the component field set. The level
+// of l stays the only filter of the forwarded entries.
+func RedirectLogrus(l *logrus.Logger, component string) {
+	log := bridged(GetLogger().WithField(ComponentField, component))
+	log.SetLevel(DebugLevel)
+	l.SetOutput(io.Discard)
+	l.ReplaceHooks(logrus.LevelHooks{})
+	l.AddHook(&logrusHook{log: log})
+}
+
+type logrusHook struct {
+	log Logger
+}
+
+func (h *logrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *logrusHook) Fire(entry *logrus.Entry) error {
+	fields := make(Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		fields[key] = value
+	}
+	switch entry.Level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		h.log.Debug(entry.Message, fields)
+	case logrus.InfoLevel:
+		h.log.Info(entry.Message, fields)
+	case logrus.WarnLevel:
+		h.log.Warn(entry.Message, fields)
+	default:
+		// logrus exits or panics itself after fatal and panic entries
+		h.log.Error(entry.Message, fields)
+	}
+	return nil
+}
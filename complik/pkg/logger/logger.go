@@ -61,6 +61,48 @@ var (
 	resetColor = "\033[0m"
 )
 
+// String returns the upper-case name of the level
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// PluginField is the field naming the plugin a log line belongs to; the level
+// set with SetPluginLevel applies to every logger carrying it
+const PluginField = "plugin"
+
+// pluginLevels overrides the level of the loggers of single plugins
+var pluginLevels = struct {
+	sync.RWMutex
+	levels map[string]LogLevel
+}{levels: make(map[string]LogLevel)}
+
+// SetPluginLevel sets the level of every logger whose plugin field is plugin,
+// including loggers created before the call. It takes precedence over the
+// level of the logger in both directions.
+func SetPluginLevel(plugin string, level LogLevel) {
+	pluginLevels.Lock()
+	defer pluginLevels.Unlock()
+	pluginLevels.levels[plugin] = level
+}
+
+// ResetPluginLevel makes the loggers of plugin use their own level again
+func ResetPluginLevel(plugin string) {
+	pluginLevels.Lock()
+	defer pluginLevels.Unlock()
+	delete(pluginLevels.levels, plugin)
+}
+
+// PluginLevel returns the level set for plugin with SetPluginLevel
+func PluginLevel(plugin string) (LogLevel, bool) {
+	pluginLevels.RLock()
+	defer pluginLevels.RUnlock()
+	level, ok := pluginLevels.levels[plugin]
+	return level, ok
+}
+
 // Fields represents a map of structured logging fields
 type Fields map[string]any
 
@@ -121,19 +163,8 @@ func Init() {
 // configureFromEnv configures the logger from environment variables
 func configureFromEnv() {
 	// Log level
-	if level := os.Getenv("COMPLIK_LOG_LEVEL"); level != "" {
-		switch strings.ToUpper(level) {
-		case "DEBUG":
-			globalLogger.SetLevel(DebugLevel)
-		case "INFO":
-			globalLogger.SetLevel(InfoLevel)
-		case "WARN":
-			globalLogger.SetLevel(WarnLevel)
-		case "ERROR":
-			globalLogger.SetLevel(ErrorLevel)
-		case "FATAL":
-			globalLogger.SetLevel(FatalLevel)
-		}
+	if level, err := ParseLevel(os.Getenv("COMPLIK_LOG_LEVEL")); err == nil {
+		globalLogger.SetLevel(level)
 	}
 
 	// Log format
@@ -162,6 +193,43 @@ func configureFromEnv() {
 	}
 }
 
+// ParseLevel converts a level name such as "debug" or "WARN" to a LogLevel
+func ParseLevel(name string) (LogLevel, error) {
+	for level, levelName := range logLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q", name)
+}
+
+// Configure overrides the level and format ("text" or "json") of the global
+// logger, e.g. from command line flags. Empty values keep the current setting.
+// Loggers derived with WithField before the call keep their old settings.
+func Configure(level, format string) error {
+	Init()
+	if level != "" {
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		globalLogger.SetLevel(parsed)
+	}
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	switch format {
+	case "":
+	case "json":
+		globalLogger.jsonFormat = true
+		globalLogger.colored = false
+	case "text":
+		globalLogger.jsonFormat = false
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	return nil
+}
+
 // GetLogger returns the global logger instance
 func GetLogger() Logger {
 	if globalLogger == nil {
@@ -294,10 +362,16 @@ func (l *StandardLogger) Fatal(msg string, fields ...Fields) {
 
 // log is the core logging method that handles message formatting and output
 func (l *StandardLogger) log(level LogLevel, msg string, extraFields ...Fields) {
+	l.logDepth(level, 1, msg, extraFields...)
+}
+
+// logDepth logs with the caller skip frames above the caller of logDepth's
+// caller, for wrappers of the level methods
+func (l *StandardLogger) logDepth(level LogLevel, skip int, msg string, extraFields ...Fields) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	if level < l.level {
+	if !l.enabled(level) {
 		return
 	}
 
@@ -319,7 +393,7 @@ func (l *StandardLogger) log(level LogLevel, msg string, extraFields ...Fields)
 
 	// Add caller information
 	if l.showCaller {
-		if pc, file, line, ok := runtime.Caller(2); ok {
+		if pc, file, line, ok := runtime.Caller(2 + skip); ok {
 			funcName := runtime.FuncForPC(pc).Name()
 			fields["caller"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
 			fields["func"] = filepath.Base(funcName)
@@ -338,6 +412,16 @@ func (l *StandardLogger) log(level LogLevel, msg string, extraFields ...Fields)
 	fmt.Fprint(l.output, output)
 }
 
+// enabled reports whether entries of level are written; callers hold l.mu
+func (l *StandardLogger) enabled(level LogLevel) bool {
+	if plugin, ok := l.fields[PluginField].(string); ok {
+		if override, ok := PluginLevel(plugin); ok {
+			return level >= override
+		}
+	}
+	return level >= l.level
+}
+
 // formatJSON formats the log entry as JSON
 func (l *StandardLogger) formatJSON(fields Fields) string {
 	data, err := json.Marshal(fields)
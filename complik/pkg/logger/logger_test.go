@@ -0,0 +1,163 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogger(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logger Suite")
+}
+
+// newJSONLogger returns a JSON logger writing to the returned buffer
+func newJSONLogger(level LogLevel) (*StandardLogger, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	log := New().(*StandardLogger)
+	log.jsonFormat = true
+	log.colored = false
+	log.SetLevel(level)
+	log.SetOutput(out)
+	return log, out
+}
+
+// entries decodes the JSON lines written to out
+func entries(out *bytes.Buffer) []map[string]any {
+	var decoded []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]any{}
+		Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+		decoded = append(decoded, entry)
+	}
+	return decoded
+}
+
+var _ = Describe("Plugin levels", func() {
+	AfterEach(func() {
+		ResetPluginLevel("Deployment")
+	})
+
+	It("should override the level of loggers carrying the plugin field", func() {
+		log, out := newJSONLogger(InfoLevel)
+		pluginLog := log.WithField(PluginField, "Deployment")
+
+		pluginLog.Debug("hidden")
+		SetPluginLevel("Deployment", DebugLevel)
+		pluginLog.Debug("shown")
+		log.Debug("other loggers keep their level")
+		SetPluginLevel("Deployment", ErrorLevel)
+		pluginLog.Warn("hidden")
+		ResetPluginLevel("Deployment")
+		pluginLog.Info("shown again")
+
+		messages := []any{}
+		for _, entry := range entries(out) {
+			messages = append(messages, entry["msg"])
+		}
+		Expect(messages).To(Equal([]any{"shown", "shown again"}))
+	})
+})
+
+var _ = Describe("Sampled", func() {
+	var (
+		out *bytes.Buffer
+		log Logger
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		SetSampling(SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Second, MaxLevel: DebugLevel})
+		DeferCleanup(SetSampling, DefaultSampling)
+		var std *StandardLogger
+		std, out = newJSONLogger(DebugLevel)
+		log = Sampled(std.WithField(PluginField, "Deployment"))
+		now = time.Unix(1000, 0)
+		log.(*SampledLogger).sampler.now = func() time.Time { return now }
+	})
+
+	It("should keep the first lines of a tick and then every n-th one", func() {
+		for range 8 {
+			log.Debug("Processing deployment")
+		}
+		log.Debug("Another message")
+		Expect(entries(out)).To(HaveLen(5))
+
+		now = now.Add(time.Second)
+		log.WithField("namespace", "ns").Debug("Processing deployment")
+		Expect(entries(out)).To(HaveLen(6))
+	})
+
+	It("should not sample levels above the maximum", func() {
+		for range 5 {
+			log.Info("Informer synced")
+		}
+		Expect(entries(out)).To(HaveLen(5))
+	})
+
+	It("should not sample when disabled", func() {
+		SetSampling(SamplingConfig{Disabled: true})
+		for range 5 {
+			log.Debug("Processing deployment")
+		}
+		Expect(entries(out)).To(HaveLen(5))
+	})
+
+	It("should report the caller of the sampled logger", func() {
+		log.Debug("Processing deployment")
+		Expect(entries(out)[0]["caller"]).To(HavePrefix("logger_test.go:"))
+	})
+})
+
+var _ = Describe("RedirectLogrus", func() {
+	It("should forward entries with the component field", func() {
+		global := GetLogger().(*StandardLogger)
+		out := &bytes.Buffer{}
+		global.mu.Lock()
+		previous, wasJSON := global.output, global.jsonFormat
+		global.output, global.jsonFormat = out, true
+		global.mu.Unlock()
+		DeferCleanup(func() {
+			global.mu.Lock()
+			defer global.mu.Unlock()
+			global.output, global.jsonFormat = previous, wasJSON
+		})
+
+		l := logrus.New()
+		l.SetLevel(logrus.DebugLevel)
+		RedirectLogrus(l, "procscan")
+		l.WithField("pid", 42).Debug("Scanning process")
+		l.Trace("filtered by logrus")
+
+		Expect(entries(out)).To(ConsistOf(And(
+			HaveKeyWithValue("msg", "Scanning process"),
+			HaveKeyWithValue("level", "DEBUG"),
+			HaveKeyWithValue(ComponentField, "procscan"),
+			HaveKeyWithValue("pid", BeNumerically("==", 42)),
+			Not(HaveKey("caller")),
+		)))
+	})
+})
@@ -0,0 +1,186 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SamplingConfig limits repeated lines of sampled loggers. Within every tick
+// the first Initial entries with the same level and message are written, then
+// every Thereafter-th one. Entries above MaxLevel are never sampled.
+type SamplingConfig struct {
+	Disabled   bool
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+	MaxLevel   LogLevel
+}
+
+// DefaultSampling samples debug lines only
+var DefaultSampling = SamplingConfig{
+	Initial:    10,
+	Thereafter: 100,
+	Tick:       time.Second,
+	MaxLevel:   DebugLevel,
+}
+
+var sampling = struct {
+	sync.RWMutex
+	config SamplingConfig
+}{config: DefaultSampling}
+
+// SetSampling replaces the configuration of all sampled loggers. Zero counts
+// and ticks fall back to DefaultSampling.
+func SetSampling(cfg SamplingConfig) {
+	if cfg.Initial <= 0 {
+		cfg.Initial = DefaultSampling.Initial
+	}
+	if cfg.Thereafter <= 0 {
+		cfg.Thereafter = DefaultSampling.Thereafter
+	}
+	if cfg.Tick <= 0 {
+		cfg.Tick = DefaultSampling.Tick
+	}
+	sampling.Lock()
+	defer sampling.Unlock()
+	sampling.config = cfg
+}
+
+func samplingConfig() SamplingConfig {
+	sampling.RLock()
+	defer sampling.RUnlock()
+	return sampling.config
+}
+
+type sampleKey struct {
+	level LogLevel
+	msg   string
+}
+
+type sampleCounter struct {
+	window time.Time
+	count  int
+}
+
+// sampler counts entries per level and message; it is shared by a sampled
+// logger and the loggers derived from it
+type sampler struct {
+	mu       sync.Mutex
+	counters map[sampleKey]*sampleCounter
+	now      func() time.Time
+}
+
+func (s *sampler) allow(level LogLevel, msg string) bool {
+	cfg := samplingConfig()
+	if cfg.Disabled || level > cfg.MaxLevel {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	key := sampleKey{level: level, msg: msg}
+	counter, ok := s.counters[key]
+	if !ok || now.Sub(counter.window) >= cfg.Tick {
+		counter = &sampleCounter{window: now}
+		s.counters[key] = counter
+	}
+	counter.count++
+	if counter.count <= cfg.Initial {
+		return true
+	}
+	return (counter.count-cfg.Initial)%cfg.Thereafter == 0
+}
+
+// SampledLogger samples the entries of high-volume loggers such as those of
+// the informer plugins
+type SampledLogger struct {
+	Logger
+	sampler *sampler
+}
+
+// Sampled wraps log so that repeated entries up to the sampling MaxLevel are
+// sampled as configured with SetSampling
+func Sampled(log Logger) Logger {
+	return &SampledLogger{
+		Logger:  log,
+		sampler: &sampler{counters: make(map[sampleKey]*sampleCounter), now: time.Now},
+	}
+}
+
+func (l *SampledLogger) Debug(msg string, fields ...Fields) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *SampledLogger) Info(msg string, fields ...Fields) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *SampledLogger) Warn(msg string, fields ...Fields) {
+	l.log(WarnLevel, msg, fields)
+}
+
+func (l *SampledLogger) Error(msg string, fields ...Fields) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+// log writes through StandardLogger.logDepth so the caller reported in the
+// entry is the caller of the sampled logger
+func (l *SampledLogger) log(level LogLevel, msg string, fields []Fields) {
+	std, standard := l.Logger.(*StandardLogger)
+	if standard {
+		std.mu.RLock()
+		enabled := std.enabled(level)
+		std.mu.RUnlock()
+		if !enabled {
+			return
+		}
+	}
+	if !l.sampler.allow(level, msg) {
+		return
+	}
+	if standard {
+		std.logDepth(level, 1, msg, fields...)
+		return
+	}
+	switch level {
+	case DebugLevel:
+		l.Logger.Debug(msg, fields...)
+	case InfoLevel:
+		l.Logger.Info(msg, fields...)
+	case WarnLevel:
+		l.Logger.Warn(msg, fields...)
+	default:
+		l.Logger.Error(msg, fields...)
+	}
+}
+
+func (l *SampledLogger) WithField(key string, value any) Logger {
+	return &SampledLogger{Logger: l.Logger.WithField(key, value), sampler: l.sampler}
+}
+
+func (l *SampledLogger) WithFields(fields Fields) Logger {
+	return &SampledLogger{Logger: l.Logger.WithFields(fields), sampler: l.sampler}
+}
+
+func (l *SampledLogger) WithContext(ctx context.Context) Logger {
+	return &SampledLogger{Logger: l.Logger.WithContext(ctx), sampler: l.sampler}
+}
+
+func (l *SampledLogger) WithError(err error) Logger {
+	return &SampledLogger{Logger: l.Logger.WithError(err), sampler: l.sampler}
+}
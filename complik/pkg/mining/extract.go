@@ -0,0 +1,166 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mining extracts the wallet addresses and pool URLs of mining
+// processes and indexes the wallets across namespaces, so a wallet mined to
+// from several tenants is recognized as one campaign.
+package mining
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// addressPatterns match wallet addresses anywhere in a command line or
+	// environment value. Only formats distinctive enough to rarely match
+	// anything else are searched for outside of the user of a pool.
+	addressPatterns = []*regexp.Regexp{
+		// Monero standard, sub- and integrated addresses
+		regexp.MustCompile(`\b[48][0-9AB][1-9A-HJ-NP-Za-km-z]{93}(?:[1-9A-HJ-NP-Za-km-z]{11})?\b`),
+		// Ethereum and EVM chains
+		regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`),
+		// Bitcoin bech32
+		regexp.MustCompile(`\bbc1[02-9ac-hj-np-z]{11,71}\b`),
+	}
+	// userAddress matches the base58 addresses of Bitcoin, Litecoin, Ravencoin
+	// and similar coins, only checked for the user of a pool
+	userAddress = regexp.MustCompile(`^[13LMR][1-9A-HJ-NP-Za-km-z]{25,34}$`)
+	poolURL     = regexp.MustCompile(`\bstratum[0-9]?(?:\+(?:tcp|ssl|tls))?://[^\s'"]+`)
+)
+
+// Miner options naming the pool and the wallet, as used by xmrig, cpuminer,
+// lolMiner, T-Rex and most of their forks
+var (
+	poolOptions = map[string]bool{"-o": true, "--url": true, "--pool": true, "-P": true}
+	userOptions = map[string]bool{"-u": true, "--user": true, "--wallet": true, "--address": true, "-w": true}
+	// environment variables miner images commonly read their settings from
+	poolEnv = map[string]bool{"POOL": true, "POOL_URL": true, "MINING_POOL": true, "POOL_ADDRESS": true}
+	userEnv = map[string]bool{"WALLET": true, "WALLET_ADDRESS": true, "ADDRESS": true, "MINING_ADDRESS": true, "XMR_WALLET": true, "USER_WALLET": true}
+)
+
+// Extract returns the wallet addresses and pool URLs found in the command line
+// and the KEY=VALUE environment of a process, in order of appearance. Pool
+// URLs are returned without their credentials, whose user usually is the wallet.
+func Extract(command string, environ []string) (wallets, pools []string) {
+	var found extraction
+	args := strings.Fields(command)
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		switch {
+		case poolOptions[name] && (hasValue || i+1 < len(args)):
+			found.pool(value)
+		case userOptions[name] && (hasValue || i+1 < len(args)):
+			found.user(value)
+		}
+		found.search(arg)
+	}
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case poolEnv[key]:
+			found.pool(value)
+		case userEnv[key]:
+			found.user(value)
+		}
+		found.search(value)
+	}
+	return found.wallets.values, found.pools.values
+}
+
+type extraction struct {
+	wallets ordered
+	pools   ordered
+}
+
+// search adds the pool URLs and distinctive addresses contained in text
+func (e *extraction) search(text string) {
+	for _, url := range poolURL.FindAllString(text, -1) {
+		e.pool(url)
+	}
+	for _, pattern := range addressPatterns {
+		for _, address := range pattern.FindAllString(text, -1) {
+			e.wallets.add(address)
+		}
+	}
+}
+
+// pool adds a pool, taking the wallet from its credentials
+func (e *extraction) pool(value string) {
+	value = strings.Trim(value, `'"`)
+	if value == "" {
+		return
+	}
+	scheme, rest, hasScheme := strings.Cut(value, "://")
+	if !hasScheme {
+		scheme, rest = "", value
+	}
+	if userinfo, host, ok := strings.Cut(rest, "@"); ok {
+		user, _, _ := strings.Cut(userinfo, ":")
+		e.user(user)
+		rest = host
+	}
+	rest = strings.TrimRight(rest, "/")
+	if rest == "" {
+		return
+	}
+	if hasScheme {
+		rest = scheme + "://" + rest
+	}
+	e.pools.add(rest)
+}
+
+// user adds the wallet of a pool user, which may be followed by a worker name
+// or a payment id, as in WALLET.worker or WALLET+difficulty
+func (e *extraction) user(value string) {
+	value = strings.Trim(value, `'"`)
+	if i := strings.IndexAny(value, ".+/"); i >= 0 {
+		value = value[:i]
+	}
+	if value == "" {
+		return
+	}
+	if userAddress.MatchString(value) {
+		e.wallets.add(value)
+		return
+	}
+	for _, pattern := range addressPatterns {
+		if address := pattern.FindString(value); address == value {
+			e.wallets.add(value)
+			return
+		}
+	}
+}
+
+type ordered struct {
+	values []string
+	seen   map[string]bool
+}
+
+func (o *ordered) add(value string) {
+	if o.seen == nil {
+		o.seen = make(map[string]bool)
+	}
+	if o.seen[value] {
+		return
+	}
+	o.seen[value] = true
+	o.values = append(o.values, value)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mining
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Index remembers the namespaces that mined to each wallet within a window
+type Index struct {
+	window     time.Duration
+	namespaces int
+
+	mu      sync.Mutex
+	wallets map[string]map[string]time.Time // wallet -> namespace -> last seen
+}
+
+// NewIndex builds an index escalating wallets seen in at least namespaces
+// namespaces within window
+func NewIndex(window time.Duration, namespaces int) *Index {
+	if namespaces < 2 {
+		namespaces = 2
+	}
+	return &Index{
+		window:     window,
+		namespaces: namespaces,
+		wallets:    make(map[string]map[string]time.Time),
+	}
+}
+
+// Observe records that namespace mined to wallets at now and returns the other
+// namespaces that mined to any of them within the window, sorted
+func (i *Index) Observe(namespace string, wallets []string, now time.Time) []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	shared := make(map[string]struct{})
+	for _, wallet := range wallets {
+		seen := i.wallets[wallet]
+		if seen == nil {
+			seen = make(map[string]time.Time)
+			i.wallets[wallet] = seen
+		}
+		seen[namespace] = now
+		for other, at := range seen {
+			if other != namespace && now.Sub(at) <= i.window {
+				shared[other] = struct{}{}
+			}
+		}
+	}
+	result := make([]string, 0, len(shared))
+	for other := range shared {
+		result = append(result, other)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Enrich extracts the wallets and pools of info when the detector did not,
+// records its wallets and raises its severity to critical once one of them
+// was mined to from the configured number of namespaces
+func (i *Index) Enrich(info *models.MiningInfo, now time.Time) {
+	if len(info.Wallets) == 0 && len(info.Pools) == 0 {
+		info.Wallets, info.Pools = Extract(info.Command, info.Environ)
+	}
+	if info.Severity == "" {
+		info.Severity = models.SeverityHigh
+	}
+	if len(info.Wallets) == 0 || info.Namespace == "" {
+		return
+	}
+	info.SharedWith = i.Observe(info.Namespace, info.Wallets, now)
+	if len(info.SharedWith)+1 >= i.namespaces {
+		info.Severity = models.SeverityCritical
+	}
+}
+
+// Expire forgets the namespaces that did not mine to a wallet within the
+// window ending at now and returns the number of wallets still indexed
+func (i *Index) Expire(now time.Time) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for wallet, seen := range i.wallets {
+		for namespace, at := range seen {
+			if now.Sub(at) > i.window {
+				delete(seen, namespace)
+			}
+		}
+		if len(seen) == 0 {
+			delete(i.wallets, wallet)
+		}
+	}
+	return len(i.wallets)
+}
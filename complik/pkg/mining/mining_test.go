@@ -0,0 +1,111 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mining
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMining(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mining Suite")
+}
+
+const (
+	monero    = "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A"
+	ethereum  = "0x52908400098527886E0F7030069857D2E4169EE7"
+	ravencoin = "RKtT3mMKA6LMWbrzMHSjDYdsgYHvUw3Y2D"
+)
+
+var _ = Describe("Extract", func() {
+	It("should read the pool and wallet options of xmrig", func() {
+		wallets, pools := Extract("/tmp/.x/xmrig -o pool.supportxmr.com:443 -u "+monero+".rig1 -p x --tls", nil)
+		Expect(wallets).To(Equal([]string{monero}))
+		Expect(pools).To(Equal([]string{"pool.supportxmr.com:443"}))
+	})
+
+	It("should take the wallet from the credentials of a stratum URL", func() {
+		wallets, pools := Extract("t-rex -a kawpow --url=stratum+tcp://"+ravencoin+".w1:x@rvn.2miners.com:6060", nil)
+		Expect(wallets).To(Equal([]string{ravencoin}))
+		Expect(pools).To(Equal([]string{"stratum+tcp://rvn.2miners.com:6060"}))
+	})
+
+	It("should search the environment and dedupe what it finds", func() {
+		wallets, pools := Extract("./miner --config /etc/miner.json", []string{
+			"PATH=/usr/bin",
+			"POOL=stratum+ssl://eth.f2pool.com:6688",
+			"WALLET=" + ethereum,
+			"EXTRA=--user " + ethereum,
+		})
+		Expect(wallets).To(Equal([]string{ethereum}))
+		Expect(pools).To(Equal([]string{"stratum+ssl://eth.f2pool.com:6688"}))
+	})
+
+	It("should only accept base58 addresses as the user of a pool", func() {
+		wallets, pools := Extract("python3 app.py --token "+ravencoin, []string{"HOME=/root"})
+		Expect(wallets).To(BeEmpty())
+		Expect(pools).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Index", func() {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	detection := func(namespace string) *models.MiningInfo {
+		return &models.MiningInfo{
+			Namespace: namespace,
+			PodName:   "web-1",
+			Command:   "xmrig -o pool.supportxmr.com:443 -u " + monero,
+		}
+	}
+
+	It("should escalate a wallet mined to from several namespaces", func() {
+		index := NewIndex(time.Hour, 2)
+		first := detection("ns-a")
+		index.Enrich(first, start)
+		Expect(first.Wallets).To(Equal([]string{monero}))
+		Expect(first.Pools).To(Equal([]string{"pool.supportxmr.com:443"}))
+		Expect(first.Severity).To(Equal(models.SeverityHigh))
+		Expect(first.SharedWith).To(BeEmpty())
+
+		second := detection("ns-b")
+		index.Enrich(second, start.Add(10*time.Minute))
+		Expect(second.Severity).To(Equal(models.SeverityCritical))
+		Expect(second.SharedWith).To(Equal([]string{"ns-a"}))
+
+		// The same namespace again does not count twice
+		again := detection("ns-a")
+		Expect(index.Observe("ns-c", []string{ethereum}, start)).To(BeEmpty())
+		index.Enrich(again, start.Add(20*time.Minute))
+		Expect(again.SharedWith).To(Equal([]string{"ns-b"}))
+	})
+
+	It("should forget namespaces outside the window", func() {
+		index := NewIndex(time.Hour, 3)
+		index.Enrich(detection("ns-a"), start)
+		index.Enrich(detection("ns-b"), start.Add(30*time.Minute))
+
+		late := detection("ns-c")
+		index.Enrich(late, start.Add(90*time.Minute))
+		Expect(late.SharedWith).To(Equal([]string{"ns-b"}))
+		Expect(late.Severity).To(Equal(models.SeverityHigh))
+
+		Expect(index.Expire(start.Add(3 * time.Hour))).To(BeZero())
+	})
+})
@@ -0,0 +1,47 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// Statuses of a tenant appeal
+const (
+	AppealPending  = "pending"
+	AppealApproved = "approved"
+	AppealRejected = "rejected"
+	// AppealFailed is an approved appeal whose namespace could not be
+	// unlocked, it can be decided again
+	AppealFailed = "failed"
+)
+
+// AppealEvent is published when a tenant appeals a violation record and
+// again when a reviewer decides the appeal, so the handlers can notify the
+// compliance staff and the tenant
+type AppealEvent struct {
+	ID        uint      `json:"id"`
+	RecordID  uint      `json:"record_id"`
+	Region    string    `json:"region"`
+	Namespace string    `json:"namespace"`
+	Host      string    `json:"host,omitempty"`
+	Detector  string    `json:"detector,omitempty"`
+	Contact   string    `json:"contact,omitempty"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	Reviewer  string    `json:"reviewer,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Unlocked  bool      `json:"unlocked,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
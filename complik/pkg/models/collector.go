@@ -15,6 +15,8 @@
 package models
 
 type CollectorInfo struct {
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	DiscoveryName string `json:"discovery_name"`
 	CollectorName string `json:"collector_name"`
 
@@ -30,4 +32,65 @@ type CollectorInfo struct {
 	HTML       string `json:"html"`
 	IsEmpty    bool   `json:"is_empty"`
 	Screenshot []byte `json:"screenshot"`
+
+	// ScanRunID is copied from the collected DiscoveryInfo
+	ScanRunID string `json:"scan_run_id,omitempty"`
+
+	// Metadata is the site context gathered next to the page, nil when the
+	// collector does not gather it
+	Metadata *SiteMetadata `json:"metadata,omitempty"`
+
+	// Pages holds every page collected when several paths of the target were
+	// visited, in visiting order. URL, HTML and Screenshot are those of the
+	// first page with content. Empty when a single page was collected.
+	Pages []PageInfo `json:"pages,omitempty"`
+
+	// Overrides are copied from the collected DiscoveryInfo
+	Overrides *ScanOverrides `json:"overrides,omitempty"`
+}
+
+// PageInfo is the page collected for one path of a target
+type PageInfo struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+
+	CollectorMessage string `json:"collector_message,omitempty"`
+
+	HTML       string `json:"html"`
+	IsEmpty    bool   `json:"is_empty"`
+	Screenshot []byte `json:"screenshot"`
+}
+
+// PageViews returns one CollectorInfo per collected page with Path set to the
+// path of the page, so detectors can review the pages one by one. It returns
+// c itself when a single page was collected.
+func (c *CollectorInfo) PageViews() []*CollectorInfo {
+	if len(c.Pages) == 0 {
+		return []*CollectorInfo{c}
+	}
+	views := make([]*CollectorInfo, 0, len(c.Pages))
+	for _, page := range c.Pages {
+		view := *c
+		view.Path = []string{page.Path}
+		view.URL = page.URL
+		view.CollectorMessage = page.CollectorMessage
+		view.HTML = page.HTML
+		view.IsEmpty = page.IsEmpty
+		view.Screenshot = page.Screenshot
+		view.Pages = nil
+		views = append(views, &view)
+	}
+	return views
+}
+
+// SiteMetadata is the context of a site besides its page content: the meta
+// tags of the page and the robots.txt and security.txt files of its host
+type SiteMetadata struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Generator   string `json:"generator,omitempty"`
+	// RobotsTxt and SecurityTxt are the files of the host, truncated and
+	// empty when the host does not serve them
+	RobotsTxt   string `json:"robots_txt,omitempty"`
+	SecurityTxt string `json:"security_txt,omitempty"`
 }
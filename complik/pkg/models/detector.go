@@ -26,6 +26,8 @@ import (
 
 // DetectorInfo contains information about a detected resource and its compliance status
 type DetectorInfo struct {
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	DiscoveryName string `json:"discovery_name"`
 	CollectorName string `json:"collector_name"`
 	DetectorName  string `json:"detector_name"`
@@ -43,6 +45,48 @@ type DetectorInfo struct {
 
 	IsIllegal   bool   `json:"is_illegal"`
 	Explanation string `json:"explanation,omitempty"`
+	// Unchanged results repeat the last compliant review of a site whose
+	// screenshot and HTML did not change, without reviewing it again
+	Unchanged bool `json:"unchanged,omitempty"`
+	// Incremental results come from a review of the text changes of a site
+	// since its last compliant review instead of the whole page
+	Incremental bool   `json:"incremental,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+
+	// Metadata is copied from the reviewed CollectorInfo
+	Metadata *SiteMetadata `json:"metadata,omitempty"`
+
+	// Workload is attached to flagged results by the enrichment stage
+	Workload *WorkloadInfo `json:"workload,omitempty"`
+
+	// Usage is the tokens the model review consumed, nil without a model call
+	Usage *TokenUsage `json:"usage,omitempty"`
+	// Transcript is the model exchange of the review, when captured
+	Transcript *ModelTranscript `json:"-"`
+}
+
+// Severity levels attached to detection results
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank returns a comparable rank for a severity level, 0 for unknown values
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
 }
 
 // SaveToFile persists the detector information to a JSON file in the specified directory
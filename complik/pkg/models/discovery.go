@@ -15,6 +15,8 @@
 package models
 
 type DiscoveryInfo struct {
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	DiscoveryName string `json:"discovery_name"`
 
 	Name      string `json:"name"`
@@ -24,7 +26,22 @@ type DiscoveryInfo struct {
 	Path []string `json:"path"`
 
 	ServiceName string `json:"service_name"`
+	ServicePort int    `json:"service_port,omitempty"`
+	// Protocol is ProtocolTCP for raw TCP endpoints, which only the TCP
+	// collector handles; empty means an HTTP site
+	Protocol string `json:"protocol,omitempty"`
 
 	HasActivePods bool `json:"has_active_pods"`
 	PodCount      int  `json:"pod_count"`
+
+	// ScanRunID is the scan run the target was discovered in, empty for
+	// targets discovered outside a run such as informer events
+	ScanRunID string `json:"scan_run_id,omitempty"`
+
+	// Overrides are the scan settings the Ingress or Service of the target
+	// overrides with annotations, nil when it overrides none
+	Overrides *ScanOverrides `json:"overrides,omitempty"`
 }
+
+// ProtocolTCP marks discoveries of TCP ports that do not serve websites
+const ProtocolTCP = "tcp"
@@ -0,0 +1,47 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The idempotency keys of the pipeline payloads, see eventbus.Keyer. They
+// name the target and the verdict, but neither the page content nor the model
+// output, which differ between two collections or reviews of the same target.
+
+func (d DiscoveryInfo) IdempotencyKey() string {
+	return contentKey(d.DiscoveryName, d.Namespace, d.Name, d.Host, strings.Join(d.Path, ","),
+		d.ServiceName, strconv.Itoa(d.ServicePort), d.Protocol, d.ScanRunID)
+}
+
+func (c *CollectorInfo) IdempotencyKey() string {
+	return contentKey(c.DiscoveryName, c.CollectorName, c.Namespace, c.Name, c.Host,
+		strings.Join(c.Path, ","), c.URL, c.ScanRunID)
+}
+
+func (d *DetectorInfo) IdempotencyKey() string {
+	return contentKey(d.DiscoveryName, d.CollectorName, d.DetectorName, d.Region, d.Namespace,
+		d.Name, d.Host, strings.Join(d.Path, ","), d.URL, strconv.FormatBool(d.IsIllegal), d.Severity)
+}
+
+func (i *MiningInfo) IdempotencyKey() string {
+	return contentKey(i.Region, i.Namespace, i.PodName, i.NodeName, i.Command, i.Severity)
+}
+
+func contentKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
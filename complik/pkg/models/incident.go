@@ -0,0 +1,51 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// Sources of correlated findings
+const (
+	SourceWebsite  = "website"
+	SourceMining   = "mining"
+	SourceProcscan = "procscan"
+	// SourceWatchdog findings are violations in the scanning namespaces
+	SourceWatchdog = "watchdog"
+)
+
+// Finding is a single detection from one of the pipelines, normalized so it
+// can be correlated with detections from other pipelines
+type Finding struct {
+	Source     string    `json:"source"`
+	Key        string    `json:"key"`
+	Region     string    `json:"region"`
+	Namespace  string    `json:"namespace"`
+	Summary    string    `json:"summary"`
+	Severity   string    `json:"severity"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Incident groups the findings of several pipelines for one namespace within
+// the correlation window
+type Incident struct {
+	ID        string    `json:"id"`
+	Region    string    `json:"region"`
+	Namespace string    `json:"namespace"`
+	Severity  string    `json:"severity"`
+	Sources   []string  `json:"sources"`
+	Findings  []Finding `json:"findings"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
@@ -20,4 +20,13 @@ type MiningInfo struct {
 	PodName   string `json:"podName"`
 	NodeName  string `json:"nodeName"`
 	Command   string `json:"command"`
+	// Environ is the KEY=VALUE environment of the mining process
+	Environ []string `json:"environ,omitempty"`
+	// Wallets and Pools are extracted from the command line and environment
+	Wallets []string `json:"wallets,omitempty"`
+	Pools   []string `json:"pools,omitempty"`
+	// SharedWith lists the other namespaces mining to one of Wallets
+	SharedWith []string `json:"sharedWith,omitempty"`
+	// Severity is high, or critical when a wallet is shared across namespaces
+	Severity string `json:"severity,omitempty"`
 }
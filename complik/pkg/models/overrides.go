@@ -0,0 +1,43 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "strings"
+
+// ScanOverrides are the settings of the global scan policy a target
+// overrides, zero fields keep the policy
+type ScanOverrides struct {
+	// IntervalMinute is the interval the cronjob discovery plugins scan the
+	// target at
+	IntervalMinute int `json:"interval_minute,omitempty"`
+	// Detectors are the names of the only detectors reviewing the target
+	Detectors []string `json:"detectors,omitempty"`
+	// MaxDepth is the number of path segments the collectors visit at most
+	MaxDepth int `json:"max_depth,omitempty"`
+}
+
+// Allows reports whether detector reviews the target, every detector does
+// without overrides
+func (o *ScanOverrides) Allows(detector string) bool {
+	if o == nil || len(o.Detectors) == 0 {
+		return true
+	}
+	for _, name := range o.Detectors {
+		if strings.EqualFold(name, detector) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ScanRunEvent announces a scan run, or reports a target of the run that
+// could not be collected when Target is set
+type ScanRunEvent struct {
+	RunID string `json:"run_id"`
+
+	// Source is the discovery plugin of the run and Total the number of
+	// targets it published
+	Source    string    `json:"source,omitempty"`
+	Total     int       `json:"total,omitempty"`
+	StartedAt time.Time `json:"started_at,omitzero"`
+
+	// Target is the failed target as returned by ScanTarget, Error why it
+	// failed
+	Target string `json:"target,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ScanSummary is the outcome of a finished scan run
+type ScanSummary struct {
+	RunID  string `json:"run_id"`
+	Source string `json:"source"`
+	// State is "completed", or "incomplete" for runs superseded by the next
+	// run before all targets were collected
+	State      string    `json:"state"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	// Violations counts the flagged targets and NewIncidents the correlated
+	// incidents opened in the namespaces of the run
+	Violations   int `json:"violations"`
+	NewIncidents int `json:"new_incidents"`
+}
+
+// Duration is the time from the start of the run to its last target
+func (s *ScanSummary) Duration() time.Duration {
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// NewScanRunID returns the ID of a run of source started at start, e.g.
+// "complete-2024-06-01T02:00:00Z"
+func NewScanRunID(source string, start time.Time) string {
+	return strings.ToLower(source) + "-" + start.UTC().Format(time.RFC3339)
+}
+
+// ScanTarget identifies a target within a scan run
+func ScanTarget(namespace, name, host string, path []string) string {
+	return namespace + "/" + name + " " + host + strings.Join(path, ",")
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+)
+
+// Schema versions of the pipeline payloads. Bump the version and register a
+// converter from the previous one when a change alters the meaning of a field.
+const (
+	DiscoveryInfoVersion = 1
+	CollectorInfoVersion = 1
+	// DetectorInfoVersion 2 added the graded Severity field
+	DetectorInfoVersion = 2
+)
+
+// RegisterSchemas registers the payload types carried by the pipeline topics
+func RegisterSchemas(registry *eventbus.Registry) error {
+	schemas := []struct {
+		topic   string
+		sample  any
+		version int
+	}{
+		{constants.DiscoveryTopic, DiscoveryInfo{}, DiscoveryInfoVersion},
+		{constants.CollectorTopic, &CollectorInfo{}, CollectorInfoVersion},
+		{constants.DetectorTopic, &DetectorInfo{}, DetectorInfoVersion},
+		{constants.MiningTopic, &MiningInfo{}, 0},
+		{constants.ServiceTopic, &ServiceInfo{}, 0},
+		{constants.CorrelationTopic, &Incident{}, 0},
+		{constants.ScanRunTopic, &ScanRunEvent{}, 0},
+		{constants.ScanSummaryTopic, &ScanSummary{}, 0},
+		{constants.AppealTopic, &AppealEvent{}, 0},
+	}
+	for _, schema := range schemas {
+		if err := registry.Register(schema.topic, schema.sample, schema.version); err != nil {
+			return err
+		}
+	}
+	return registry.RegisterConverter(constants.DetectorTopic, 1, upgradeDetectorInfoV1)
+}
+
+// upgradeDetectorInfoV1 grades v1 results, which only carried IsIllegal
+func upgradeDetectorInfoV1(payload any) (any, error) {
+	info, ok := payload.(*DetectorInfo)
+	if !ok {
+		return nil, fmt.Errorf("unexpected payload %T", payload)
+	}
+	if info.Severity == "" {
+		info.Severity = SeverityLow
+		if info.IsIllegal {
+			info.Severity = SeverityHigh
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// ServiceInfo is the fingerprint of an exposed TCP port
+type ServiceInfo struct {
+	DiscoveryName string `json:"discovery_name"`
+	CollectorName string `json:"collector_name"`
+
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// Host is the host:port that was probed
+	Host string `json:"host"`
+
+	// Service is the detected protocol, e.g. "ssh" or "minecraft", empty
+	// when the port answered but was not recognized
+	Service string `json:"service,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Banner is the printable start of the first response of the port
+	Banner string `json:"banner,omitempty"`
+
+	CollectorMessage string `json:"collector_message"`
+	IsEmpty          bool   `json:"is_empty"`
+}
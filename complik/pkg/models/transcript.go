@@ -0,0 +1,30 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// ModelTranscript is the exact exchange with the review model behind a
+// detection result. It is only captured when a detector enables it and never
+// leaves the process as JSON with the result: it holds the page content and
+// is stored by the handlers configured to keep it.
+type ModelTranscript struct {
+	Model string `json:"model"`
+	// Prompt is the rendered text prompt; the screenshot sent with it is not kept
+	Prompt string `json:"prompt"`
+	// Response is the raw body returned by the model API
+	Response         string `json:"response"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+}
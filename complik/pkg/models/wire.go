@@ -0,0 +1,338 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+
+	pipelinepb "github.com/bearslyricattack/CompliK/complik/pkg/models/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The protobuf messages of proto/pipeline.proto are the wire format of the
+// pipeline payloads shared by the integrations exporting them. The converters
+// below map the Go models to and from them, fields the messages do not define,
+// such as the transcript of a review, are not exported.
+
+func (d *DiscoveryInfo) ToProto() *pipelinepb.DiscoveryInfo {
+	return &pipelinepb.DiscoveryInfo{
+		SchemaVersion: int32(d.SchemaVersion),
+		DiscoveryName: d.DiscoveryName,
+		Name:          d.Name,
+		Namespace:     d.Namespace,
+		Host:          d.Host,
+		Path:          d.Path,
+		ServiceName:   d.ServiceName,
+		ServicePort:   int32(d.ServicePort),
+		Protocol:      d.Protocol,
+		HasActivePods: d.HasActivePods,
+		PodCount:      int32(d.PodCount),
+		ScanRunId:     d.ScanRunID,
+		Overrides:     d.Overrides.toProto(),
+	}
+}
+
+func DiscoveryInfoFromProto(m *pipelinepb.DiscoveryInfo) DiscoveryInfo {
+	return DiscoveryInfo{
+		SchemaVersion: int(m.GetSchemaVersion()),
+		DiscoveryName: m.GetDiscoveryName(),
+		Name:          m.GetName(),
+		Namespace:     m.GetNamespace(),
+		Host:          m.GetHost(),
+		Path:          m.GetPath(),
+		ServiceName:   m.GetServiceName(),
+		ServicePort:   int(m.GetServicePort()),
+		Protocol:      m.GetProtocol(),
+		HasActivePods: m.GetHasActivePods(),
+		PodCount:      int(m.GetPodCount()),
+		ScanRunID:     m.GetScanRunId(),
+		Overrides:     scanOverridesFromProto(m.GetOverrides()),
+	}
+}
+
+func (c *CollectorInfo) ToProto() *pipelinepb.CollectorInfo {
+	m := &pipelinepb.CollectorInfo{
+		SchemaVersion:    int32(c.SchemaVersion),
+		DiscoveryName:    c.DiscoveryName,
+		CollectorName:    c.CollectorName,
+		Name:             c.Name,
+		Namespace:        c.Namespace,
+		Host:             c.Host,
+		Path:             c.Path,
+		Url:              c.URL,
+		CollectorMessage: c.CollectorMessage,
+		Html:             c.HTML,
+		IsEmpty:          c.IsEmpty,
+		Screenshot:       c.Screenshot,
+		ScanRunId:        c.ScanRunID,
+		Metadata:         c.Metadata.toProto(),
+		Overrides:        c.Overrides.toProto(),
+	}
+	for _, page := range c.Pages {
+		m.Pages = append(m.Pages, &pipelinepb.PageInfo{
+			Path:             page.Path,
+			Url:              page.URL,
+			CollectorMessage: page.CollectorMessage,
+			Html:             page.HTML,
+			IsEmpty:          page.IsEmpty,
+			Screenshot:       page.Screenshot,
+		})
+	}
+	return m
+}
+
+func CollectorInfoFromProto(m *pipelinepb.CollectorInfo) *CollectorInfo {
+	c := &CollectorInfo{
+		SchemaVersion:    int(m.GetSchemaVersion()),
+		DiscoveryName:    m.GetDiscoveryName(),
+		CollectorName:    m.GetCollectorName(),
+		Name:             m.GetName(),
+		Namespace:        m.GetNamespace(),
+		Host:             m.GetHost(),
+		Path:             m.GetPath(),
+		URL:              m.GetUrl(),
+		CollectorMessage: m.GetCollectorMessage(),
+		HTML:             m.GetHtml(),
+		IsEmpty:          m.GetIsEmpty(),
+		Screenshot:       m.GetScreenshot(),
+		ScanRunID:        m.GetScanRunId(),
+		Metadata:         siteMetadataFromProto(m.GetMetadata()),
+		Overrides:        scanOverridesFromProto(m.GetOverrides()),
+	}
+	for _, page := range m.GetPages() {
+		c.Pages = append(c.Pages, PageInfo{
+			Path:             page.GetPath(),
+			URL:              page.GetUrl(),
+			CollectorMessage: page.GetCollectorMessage(),
+			HTML:             page.GetHtml(),
+			IsEmpty:          page.GetIsEmpty(),
+			Screenshot:       page.GetScreenshot(),
+		})
+	}
+	return c
+}
+
+func (d *DetectorInfo) ToProto() *pipelinepb.DetectorInfo {
+	m := &pipelinepb.DetectorInfo{
+		SchemaVersion: int32(d.SchemaVersion),
+		DiscoveryName: d.DiscoveryName,
+		CollectorName: d.CollectorName,
+		DetectorName:  d.DetectorName,
+		Name:          d.Name,
+		Namespace:     d.Namespace,
+		Region:        d.Region,
+		Host:          d.Host,
+		Path:          d.Path,
+		Url:           d.URL,
+		Description:   d.Description,
+		Keywords:      d.Keywords,
+		IsIllegal:     d.IsIllegal,
+		Explanation:   d.Explanation,
+		Unchanged:     d.Unchanged,
+		Incremental:   d.Incremental,
+		Severity:      d.Severity,
+		Metadata:      d.Metadata.toProto(),
+	}
+	if w := d.Workload; w != nil {
+		m.Workload = &pipelinepb.WorkloadInfo{
+			Kind:    w.Kind,
+			Name:    w.Name,
+			Service: w.Service,
+			Images:  w.Images,
+			UserId:  w.UserID,
+			Team:    w.Team,
+		}
+		if !w.CreatedAt.IsZero() {
+			m.Workload.CreatedAt = timestamppb.New(w.CreatedAt)
+		}
+	}
+	if u := d.Usage; u != nil {
+		m.Usage = &pipelinepb.TokenUsage{
+			Model:            u.Model,
+			PromptTokens:     int64(u.PromptTokens),
+			CompletionTokens: int64(u.CompletionTokens),
+			TotalTokens:      int64(u.TotalTokens),
+		}
+	}
+	return m
+}
+
+func DetectorInfoFromProto(m *pipelinepb.DetectorInfo) *DetectorInfo {
+	d := &DetectorInfo{
+		SchemaVersion: int(m.GetSchemaVersion()),
+		DiscoveryName: m.GetDiscoveryName(),
+		CollectorName: m.GetCollectorName(),
+		DetectorName:  m.GetDetectorName(),
+		Name:          m.GetName(),
+		Namespace:     m.GetNamespace(),
+		Region:        m.GetRegion(),
+		Host:          m.GetHost(),
+		Path:          m.GetPath(),
+		URL:           m.GetUrl(),
+		Description:   m.GetDescription(),
+		Keywords:      m.GetKeywords(),
+		IsIllegal:     m.GetIsIllegal(),
+		Explanation:   m.GetExplanation(),
+		Unchanged:     m.GetUnchanged(),
+		Incremental:   m.GetIncremental(),
+		Severity:      m.GetSeverity(),
+		Metadata:      siteMetadataFromProto(m.GetMetadata()),
+	}
+	if w := m.GetWorkload(); w != nil {
+		d.Workload = &WorkloadInfo{
+			Kind:    w.GetKind(),
+			Name:    w.GetName(),
+			Service: w.GetService(),
+			Images:  w.GetImages(),
+			UserID:  w.GetUserId(),
+			Team:    w.GetTeam(),
+		}
+		if w.GetCreatedAt() != nil {
+			d.Workload.CreatedAt = w.GetCreatedAt().AsTime()
+		}
+	}
+	if u := m.GetUsage(); u != nil {
+		d.Usage = &TokenUsage{
+			Model:            u.GetModel(),
+			PromptTokens:     int(u.GetPromptTokens()),
+			CompletionTokens: int(u.GetCompletionTokens()),
+			TotalTokens:      int(u.GetTotalTokens()),
+		}
+	}
+	return d
+}
+
+func (i *MiningInfo) ToProto() *pipelinepb.MiningInfo {
+	return &pipelinepb.MiningInfo{
+		Region:     i.Region,
+		Namespace:  i.Namespace,
+		PodName:    i.PodName,
+		NodeName:   i.NodeName,
+		Command:    i.Command,
+		Environ:    i.Environ,
+		Wallets:    i.Wallets,
+		Pools:      i.Pools,
+		SharedWith: i.SharedWith,
+		Severity:   i.Severity,
+	}
+}
+
+func MiningInfoFromProto(m *pipelinepb.MiningInfo) *MiningInfo {
+	return &MiningInfo{
+		Region:     m.GetRegion(),
+		Namespace:  m.GetNamespace(),
+		PodName:    m.GetPodName(),
+		NodeName:   m.GetNodeName(),
+		Command:    m.GetCommand(),
+		Environ:    m.GetEnviron(),
+		Wallets:    m.GetWallets(),
+		Pools:      m.GetPools(),
+		SharedWith: m.GetSharedWith(),
+		Severity:   m.GetSeverity(),
+	}
+}
+
+func (s *SiteMetadata) toProto() *pipelinepb.SiteMetadata {
+	if s == nil {
+		return nil
+	}
+	return &pipelinepb.SiteMetadata{
+		Title:       s.Title,
+		Description: s.Description,
+		Generator:   s.Generator,
+		RobotsTxt:   s.RobotsTxt,
+		SecurityTxt: s.SecurityTxt,
+	}
+}
+
+func siteMetadataFromProto(m *pipelinepb.SiteMetadata) *SiteMetadata {
+	if m == nil {
+		return nil
+	}
+	return &SiteMetadata{
+		Title:       m.GetTitle(),
+		Description: m.GetDescription(),
+		Generator:   m.GetGenerator(),
+		RobotsTxt:   m.GetRobotsTxt(),
+		SecurityTxt: m.GetSecurityTxt(),
+	}
+}
+
+func (o *ScanOverrides) toProto() *pipelinepb.ScanOverrides {
+	if o == nil {
+		return nil
+	}
+	return &pipelinepb.ScanOverrides{
+		IntervalMinute: int32(o.IntervalMinute),
+		Detectors:      o.Detectors,
+		MaxDepth:       int32(o.MaxDepth),
+	}
+}
+
+func scanOverridesFromProto(m *pipelinepb.ScanOverrides) *ScanOverrides {
+	if m == nil {
+		return nil
+	}
+	return &ScanOverrides{
+		IntervalMinute: int(m.GetIntervalMinute()),
+		Detectors:      m.GetDetectors(),
+		MaxDepth:       int(m.GetMaxDepth()),
+	}
+}
+
+// MarshalEnvelope encodes the payload of a discovery, collector, detector or
+// mining event as an Envelope
+func MarshalEnvelope(topic string, payload any) ([]byte, error) {
+	envelope := &pipelinepb.Envelope{Topic: topic}
+	switch p := payload.(type) {
+	case DiscoveryInfo:
+		envelope.Payload = &pipelinepb.Envelope_Discovery{Discovery: p.ToProto()}
+	case *DiscoveryInfo:
+		envelope.Payload = &pipelinepb.Envelope_Discovery{Discovery: p.ToProto()}
+	case *CollectorInfo:
+		envelope.Payload = &pipelinepb.Envelope_Collector{Collector: p.ToProto()}
+	case *DetectorInfo:
+		envelope.Payload = &pipelinepb.Envelope_Detector{Detector: p.ToProto()}
+	case *MiningInfo:
+		envelope.Payload = &pipelinepb.Envelope_Mining{Mining: p.ToProto()}
+	default:
+		return nil, fmt.Errorf("no wire format for payload %T of topic %q", payload, topic)
+	}
+	return proto.Marshal(envelope)
+}
+
+// UnmarshalEnvelope decodes an Envelope into its topic and the payload type
+// registered for the topic by RegisterSchemas
+func UnmarshalEnvelope(data []byte) (string, any, error) {
+	var envelope pipelinepb.Envelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+	var payload any
+	switch p := envelope.GetPayload().(type) {
+	case *pipelinepb.Envelope_Discovery:
+		payload = DiscoveryInfoFromProto(p.Discovery)
+	case *pipelinepb.Envelope_Collector:
+		payload = CollectorInfoFromProto(p.Collector)
+	case *pipelinepb.Envelope_Detector:
+		payload = DetectorInfoFromProto(p.Detector)
+	case *pipelinepb.Envelope_Mining:
+		payload = MiningInfoFromProto(p.Mining)
+	default:
+		return "", nil, fmt.Errorf("envelope of topic %q has no payload", envelope.GetTopic())
+	}
+	return envelope.GetTopic(), payload, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	pipelinepb "github.com/bearslyricattack/CompliK/complik/pkg/models/proto"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestModels(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Models Suite")
+}
+
+var _ = Describe("Wire format", func() {
+	It("should round trip the pipeline payloads through envelopes", func() {
+		detector := &DetectorInfo{
+			SchemaVersion: DetectorInfoVersion,
+			DetectorName:  "safety",
+			Namespace:     "ns-a",
+			Host:          "a.example.com",
+			Path:          []string{"/"},
+			URL:           "https://a.example.com/",
+			Keywords:      []string{"casino"},
+			IsIllegal:     true,
+			Severity:      SeverityHigh,
+			Metadata:      &SiteMetadata{Title: "A", RobotsTxt: "User-agent: *"},
+			Workload: &WorkloadInfo{
+				Kind:      "Deployment",
+				Name:      "web",
+				Images:    []string{"nginx"},
+				CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			},
+			Usage: &TokenUsage{Model: "m", PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+		}
+		payloads := map[string]any{
+			constants.DiscoveryTopic: DiscoveryInfo{
+				SchemaVersion: DiscoveryInfoVersion, Name: "web", Namespace: "ns-a",
+				Host: "a.example.com", Path: []string{"/", "/login"}, ServicePort: 8080, HasActivePods: true, PodCount: 2,
+				Overrides: &ScanOverrides{IntervalMinute: 60, Detectors: []string{"Custom"}, MaxDepth: 3},
+			},
+			constants.CollectorTopic: &CollectorInfo{
+				SchemaVersion: CollectorInfoVersion, Namespace: "ns-a", URL: "https://a.example.com/",
+				HTML: "<html></html>", Screenshot: []byte{1, 2},
+				Pages:     []PageInfo{{Path: "/", URL: "https://a.example.com/", HTML: "<html></html>"}},
+				Overrides: &ScanOverrides{MaxDepth: 1},
+			},
+			constants.DetectorTopic: detector,
+			constants.MiningTopic: &MiningInfo{
+				Namespace: "ns-a", PodName: "miner", Wallets: []string{"w"}, SharedWith: []string{"ns-b"},
+				Severity: SeverityCritical,
+			},
+		}
+		for topic, payload := range payloads {
+			data, err := MarshalEnvelope(topic, payload)
+			Expect(err).NotTo(HaveOccurred(), topic)
+			decoded, got, err := UnmarshalEnvelope(data)
+			Expect(err).NotTo(HaveOccurred(), topic)
+			Expect(decoded).To(Equal(topic))
+			Expect(got).To(Equal(payload), topic)
+		}
+	})
+
+	It("should not export the transcript of a review", func() {
+		info := &DetectorInfo{Namespace: "ns-a", Transcript: &ModelTranscript{}}
+		data, err := MarshalEnvelope(constants.DetectorTopic, info)
+		Expect(err).NotTo(HaveOccurred())
+		_, got, err := UnmarshalEnvelope(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.(*DetectorInfo).Transcript).To(BeNil())
+	})
+
+	It("should reject payloads without a wire format", func() {
+		_, err := MarshalEnvelope(constants.ServiceTopic, &ServiceInfo{})
+		Expect(err).To(MatchError(ContainSubstring("no wire format")))
+		_, _, err = UnmarshalEnvelope([]byte{0xff})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should define a field for every JSON field of the models", func() {
+		models := []struct {
+			model   any
+			message proto.Message
+		}{
+			{DiscoveryInfo{}, &pipelinepb.DiscoveryInfo{}},
+			{CollectorInfo{}, &pipelinepb.CollectorInfo{}},
+			{PageInfo{}, &pipelinepb.PageInfo{}},
+			{SiteMetadata{}, &pipelinepb.SiteMetadata{}},
+			{DetectorInfo{}, &pipelinepb.DetectorInfo{}},
+			{WorkloadInfo{}, &pipelinepb.WorkloadInfo{}},
+			{TokenUsage{}, &pipelinepb.TokenUsage{}},
+			{MiningInfo{}, &pipelinepb.MiningInfo{}},
+			{ScanOverrides{}, &pipelinepb.ScanOverrides{}},
+		}
+		for _, m := range models {
+			typ := reflect.TypeOf(m.model)
+			fields := m.message.ProtoReflect().Descriptor().Fields()
+			exported := 0
+			for i := 0; i < typ.NumField(); i++ {
+				name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+				if name == "-" {
+					continue
+				}
+				exported++
+				// MiningInfo uses the camel case JSON names of protobuf
+				field := fields.ByName(protoreflect.Name(name))
+				if field == nil {
+					field = fields.ByJSONName(name)
+				}
+				Expect(field).NotTo(BeNil(), "%s.%s", typ.Name(), name)
+			}
+			Expect(fields.Len()).To(Equal(exported), typ.Name())
+		}
+	})
+})
+
+var _ = Describe("Idempotency keys", func() {
+	It("should ignore the model output of a review", func() {
+		first := &DetectorInfo{DetectorName: "safety", Namespace: "ns-a", Host: "a.example.com", IsIllegal: true,
+			Explanation: "gambling", Usage: &TokenUsage{TotalTokens: 10}}
+		again := *first
+		again.Explanation = "online casino"
+		again.Usage = &TokenUsage{TotalTokens: 12}
+		Expect(again.IdempotencyKey()).To(Equal(first.IdempotencyKey()))
+
+		again.Severity = SeverityCritical
+		Expect(again.IdempotencyKey()).NotTo(Equal(first.IdempotencyKey()))
+	})
+
+	It("should tell two scan runs of a target apart", func() {
+		first := DiscoveryInfo{Namespace: "ns-a", Host: "a.example.com", ScanRunID: "run-1"}
+		second := first
+		second.ScanRunID = "run-2"
+		Expect(second.IdempotencyKey()).NotTo(Equal(first.IdempotencyKey()))
+	})
+})
+
+var _ = Describe("Scan overrides", func() {
+	It("should allow only the detectors a target names", func() {
+		var none *ScanOverrides
+		Expect(none.Allows("Safety")).To(BeTrue())
+		Expect((&ScanOverrides{MaxDepth: 1}).Allows("Safety")).To(BeTrue())
+
+		overrides := &ScanOverrides{Detectors: []string{"custom"}}
+		Expect(overrides.Allows("Custom")).To(BeTrue())
+		Expect(overrides.Allows("Safety")).To(BeFalse())
+	})
+})
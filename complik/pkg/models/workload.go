@@ -0,0 +1,30 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// WorkloadInfo identifies the workload serving a flagged host and the tenant
+// responsible for it
+type WorkloadInfo struct {
+	// Kind is Deployment, StatefulSet, DaemonSet or the kind of the pod owner
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Service   string    `json:"service,omitempty"`
+	Images    []string  `json:"images,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Team      string    `json:"team,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
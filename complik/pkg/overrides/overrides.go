@@ -0,0 +1,156 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package overrides reads the annotations an Ingress or Service overrides the
+// scan policy of its targets with. Tenants own the annotations of their
+// resources, so they are only honored in the namespaces the configuration
+// allows.
+package overrides
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+// Annotations overriding the scan policy
+const (
+	// ScanIntervalAnnotation is a duration such as "24h" or "7d"
+	ScanIntervalAnnotation = "complik.io/scan-interval"
+	// DetectorAnnotation lists the detectors reviewing the target, e.g.
+	// "custom-only" or "safety,secrets"
+	DetectorAnnotation = "complik.io/detector"
+	// MaxDepthAnnotation is the number of path segments visited at most
+	MaxDepthAnnotation = "complik.io/max-depth"
+)
+
+var (
+	mu     sync.RWMutex
+	policy config.ScanOverridesConfig
+)
+
+// Configure sets the namespaces whose annotations are honored
+func Configure(cfg config.ScanOverridesConfig) error {
+	for _, pattern := range cfg.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid scan override namespace pattern %q: %w", pattern, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	policy = cfg
+	return nil
+}
+
+// Honored reports whether the annotations of the resources in namespace are
+// honored
+func Honored(namespace string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if !policy.Enabled {
+		return false
+	}
+	if len(policy.Namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range policy.Namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FromAnnotations returns the overrides of a resource in namespace, nil when
+// it sets none or its namespace is not honored. Invalid values are left out
+// and reported in the error.
+func FromAnnotations(namespace string, annotations map[string]string) (*models.ScanOverrides, error) {
+	if !Honored(namespace) {
+		return nil, nil
+	}
+	return Parse(annotations)
+}
+
+// Parse returns the overrides set by annotations, nil when they set none.
+// Invalid values are left out and reported in the error.
+func Parse(annotations map[string]string) (*models.ScanOverrides, error) {
+	var (
+		overrides models.ScanOverrides
+		errs      []error
+	)
+	if value, ok := annotations[ScanIntervalAnnotation]; ok {
+		interval, err := parseInterval(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ScanIntervalAnnotation, err))
+		}
+		overrides.IntervalMinute = int(interval / time.Minute)
+	}
+	if value, ok := annotations[DetectorAnnotation]; ok {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), "-only")
+			if name != "" {
+				overrides.Detectors = append(overrides.Detectors, name)
+			}
+		}
+		if len(overrides.Detectors) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no detector named", DetectorAnnotation))
+		}
+	}
+	if value, ok := annotations[MaxDepthAnnotation]; ok {
+		depth, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil && depth < 1 {
+			err = errors.New("must be at least 1")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", MaxDepthAnnotation, err))
+		} else {
+			overrides.MaxDepth = depth
+		}
+	}
+	err := errors.Join(errs...)
+	if overrides.IntervalMinute == 0 && len(overrides.Detectors) == 0 && overrides.MaxDepth == 0 {
+		return nil, err
+	}
+	return &overrides, err
+}
+
+// parseInterval parses a duration of at least a minute, with days as "d"
+func parseInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var (
+		interval time.Duration
+		err      error
+	)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		interval = time.Duration(n) * 24 * time.Hour
+	} else {
+		interval, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if interval < time.Minute {
+		return 0, errors.New("must be at least 1m")
+	}
+	return interval, nil
+}
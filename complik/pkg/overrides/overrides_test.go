@@ -0,0 +1,100 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package overrides
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOverrides(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Overrides Suite")
+}
+
+var _ = Describe("Parse", func() {
+	It("should read every override", func() {
+		overrides, err := Parse(map[string]string{
+			ScanIntervalAnnotation: "7d",
+			DetectorAnnotation:     "custom-only",
+			MaxDepthAnnotation:     "3",
+			"unrelated":            "x",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides).To(Equal(&models.ScanOverrides{
+			IntervalMinute: 7 * 24 * 60,
+			Detectors:      []string{"custom"},
+			MaxDepth:       3,
+		}))
+
+		overrides, err = Parse(map[string]string{
+			ScanIntervalAnnotation: "90m",
+			DetectorAnnotation:     " Safety, secrets ",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides.IntervalMinute).To(Equal(90))
+		Expect(overrides.Detectors).To(Equal([]string{"safety", "secrets"}))
+	})
+
+	It("should return nil without overrides", func() {
+		overrides, err := Parse(map[string]string{"app": "web"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides).To(BeNil())
+	})
+
+	It("should leave out invalid values", func() {
+		overrides, err := Parse(map[string]string{
+			ScanIntervalAnnotation: "10s",
+			DetectorAnnotation:     ",",
+			MaxDepthAnnotation:     "2",
+		})
+		Expect(err).To(MatchError(ContainSubstring(ScanIntervalAnnotation)))
+		Expect(err).To(MatchError(ContainSubstring(DetectorAnnotation)))
+		Expect(overrides).To(Equal(&models.ScanOverrides{MaxDepth: 2}))
+
+		overrides, err = Parse(map[string]string{MaxDepthAnnotation: "0"})
+		Expect(err).To(HaveOccurred())
+		Expect(overrides).To(BeNil())
+	})
+})
+
+var _ = Describe("FromAnnotations", func() {
+	annotations := map[string]string{MaxDepthAnnotation: "1"}
+
+	AfterEach(func() {
+		Expect(Configure(config.ScanOverridesConfig{})).To(Succeed())
+	})
+
+	It("should ignore annotations unless enabled", func() {
+		overrides, err := FromAnnotations("ns-a", annotations)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides).To(BeNil())
+
+		Expect(Configure(config.ScanOverridesConfig{Enabled: true})).To(Succeed())
+		Expect(FromAnnotations("ns-a", annotations)).To(Equal(&models.ScanOverrides{MaxDepth: 1}))
+	})
+
+	It("should honor only the configured namespaces", func() {
+		Expect(Configure(config.ScanOverridesConfig{Enabled: true, Namespaces: []string{"ns-trusted-*"}})).To(Succeed())
+		Expect(Honored("ns-trusted-a")).To(BeTrue())
+		Expect(Honored("ns-a")).To(BeFalse())
+
+		Expect(Configure(config.ScanOverridesConfig{Enabled: true, Namespaces: []string{"["}})).NotTo(Succeed())
+	})
+})
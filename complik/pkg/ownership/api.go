@@ -0,0 +1,64 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ownership
+
+import (
+	"net/http"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+)
+
+// API serves the ownership endpoints:
+//
+//	GET    /api/v1/owners               cached owners
+//	GET    /api/v1/owners/{namespace}   owner of a namespace, resolved when not cached
+//	DELETE /api/v1/owners/{namespace}   drop the cached owner, "*" drops all
+type API struct {
+	resolver *Resolver
+	mux      *http.ServeMux
+	handler  http.Handler
+}
+
+// NewAPI returns the API of resolver, requiring token as bearer token. Every
+// request is refused when token is empty.
+func NewAPI(token string, resolver *Resolver) *API {
+	api := &API{resolver: resolver, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/owners", api.list)
+	api.mux.HandleFunc("GET /api/v1/owners/{namespace}", api.get)
+	api.mux.HandleFunc("DELETE /api/v1/owners/{namespace}", api.invalidate)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) list(w http.ResponseWriter, r *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, map[string]any{"owners": a.resolver.Owners()})
+}
+
+func (a *API) get(w http.ResponseWriter, r *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.resolver.Resolve(r.Context(), r.PathValue("namespace")))
+}
+
+func (a *API) invalidate(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	if namespace == "*" {
+		namespace = ""
+	}
+	a.resolver.Invalidate(namespace)
+	w.WriteHeader(http.StatusNoContent)
+}
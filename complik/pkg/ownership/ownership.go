@@ -0,0 +1,274 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ownership resolves the team owning a namespace from its annotations
+// and labels, so that notifications reach the owning team instead of the
+// platform channel. Resolved owners are cached and can be inspected through a
+// small HTTP API.
+package ownership
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Default keys of the namespace annotations and labels read for the owner
+const (
+	DefaultTeamKey    = "owner.clawcloud.run/team"
+	DefaultContactKey = "owner.clawcloud.run/contact"
+	DefaultWebhookKey = "owner.clawcloud.run/lark-webhook"
+	DefaultEmailKey   = "owner.clawcloud.run/email"
+)
+
+// Sources of an owner's channels
+const (
+	SourceNamespace = "namespace"
+	SourceTeam      = "team"
+	// SourceFallback marks namespaces without a channel of their own, their
+	// notifications go to the platform channel
+	SourceFallback = "fallback"
+)
+
+// defaultWebhookPrefixes are the Lark and Feishu bot webhooks
+var defaultWebhookPrefixes = []string{
+	"https://open.feishu.cn/open-apis/bot/",
+	"https://open.larksuite.com/open-apis/bot/",
+}
+
+// Config is the ownership section of a notifier plugin configuration
+type Config struct {
+	// TeamKey, ContactKey, WebhookKey and EmailKey are the annotations, or
+	// labels, of a namespace naming its team, contact, Lark group webhook and
+	// comma separated email addresses
+	TeamKey    string `json:"teamKey"`
+	ContactKey string `json:"contactKey"`
+	WebhookKey string `json:"webhookKey"`
+	EmailKey   string `json:"emailKey"`
+	// Teams are the channels of the teams named by TeamKey, a channel set on
+	// the namespace itself takes precedence
+	Teams map[string]Team `json:"teams"`
+	// WebhookPrefixes restrict the webhooks taken from namespaces, by default
+	// to Lark and Feishu bots, so tenants cannot point alerts elsewhere
+	WebhookPrefixes []string `json:"webhookPrefixes"`
+	CacheMinute     int      `json:"cacheMinute"`
+
+	// APIAddr serves the API listing the resolved owners when set
+	APIAddr  string `json:"apiAddr"`
+	APIToken string `json:"apiToken"`
+}
+
+// Team is where the notifications of a team are delivered
+type Team struct {
+	Webhook string   `json:"webhook"`
+	Emails  []string `json:"emails"`
+	Contact string   `json:"contact"`
+}
+
+// Owner is the resolved owner of a namespace
+type Owner struct {
+	Namespace  string    `json:"namespace"`
+	Team       string    `json:"team,omitempty"`
+	Contact    string    `json:"contact,omitempty"`
+	Webhook    string    `json:"webhook,omitempty"`
+	Emails     []string  `json:"emails,omitempty"`
+	Source     string    `json:"source"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+	// Error is the reason a lookup failed, such owners are not cached
+	Error string `json:"error,omitempty"`
+}
+
+// Routable reports whether the owner has a channel of its own
+func (o Owner) Routable() bool {
+	return o.Webhook != "" || len(o.Emails) > 0
+}
+
+// NamespaceLookup returns the labels and annotations of a namespace
+type NamespaceLookup func(ctx context.Context, namespace string) (labels, annotations map[string]string, err error)
+
+type cacheEntry struct {
+	owner   Owner
+	expires time.Time
+}
+
+// Resolver resolves and caches the owners of namespaces
+type Resolver struct {
+	cfg    Config
+	lookup NamespaceLookup
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewResolver validates cfg and returns a resolver reading namespaces with
+// lookup
+func NewResolver(cfg Config, lookup NamespaceLookup) (*Resolver, error) {
+	if cfg.TeamKey == "" {
+		cfg.TeamKey = DefaultTeamKey
+	}
+	if cfg.ContactKey == "" {
+		cfg.ContactKey = DefaultContactKey
+	}
+	if cfg.WebhookKey == "" {
+		cfg.WebhookKey = DefaultWebhookKey
+	}
+	if cfg.EmailKey == "" {
+		cfg.EmailKey = DefaultEmailKey
+	}
+	if cfg.WebhookPrefixes == nil {
+		cfg.WebhookPrefixes = defaultWebhookPrefixes
+	}
+	if cfg.CacheMinute <= 0 {
+		cfg.CacheMinute = 10
+	}
+	for name, team := range cfg.Teams {
+		if team.Webhook == "" && len(team.Emails) == 0 {
+			return nil, fmt.Errorf("team %q: webhook or emails are required", name)
+		}
+		if _, err := parseEmails(strings.Join(team.Emails, ",")); err != nil {
+			return nil, fmt.Errorf("team %q: %w", name, err)
+		}
+	}
+	return &Resolver{
+		cfg:    cfg,
+		lookup: lookup,
+		ttl:    time.Duration(cfg.CacheMinute) * time.Minute,
+		now:    time.Now,
+		cache:  make(map[string]cacheEntry),
+	}, nil
+}
+
+// Resolve returns the owner of namespace. A namespace without a channel, or
+// one that cannot be read, resolves to a fallback owner.
+func (r *Resolver) Resolve(ctx context.Context, namespace string) Owner {
+	now := r.now()
+	r.mu.Lock()
+	entry, ok := r.cache[namespace]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.owner
+	}
+
+	labels, annotations, err := r.lookup(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return Owner{Namespace: namespace, Source: SourceFallback, ResolvedAt: now, Error: err.Error()}
+	}
+	owner := r.owner(namespace, labels, annotations)
+	owner.ResolvedAt = now
+	r.mu.Lock()
+	r.cache[namespace] = cacheEntry{owner: owner, expires: now.Add(r.ttl)}
+	r.mu.Unlock()
+	return owner
+}
+
+// owner builds the owner of a namespace, annotations take precedence over
+// labels and the channels of the namespace over those of its team
+func (r *Resolver) owner(namespace string, labels, annotations map[string]string) Owner {
+	value := func(key string) string {
+		if v := strings.TrimSpace(annotations[key]); v != "" {
+			return v
+		}
+		return strings.TrimSpace(labels[key])
+	}
+	owner := Owner{
+		Namespace: namespace,
+		Team:      value(r.cfg.TeamKey),
+		Contact:   value(r.cfg.ContactKey),
+		Source:    SourceFallback,
+	}
+	if webhook := value(r.cfg.WebhookKey); webhook != "" && r.allowedWebhook(webhook) {
+		owner.Webhook = webhook
+	}
+	// Invalid addresses are ignored rather than failing the whole owner
+	owner.Emails, _ = parseEmails(value(r.cfg.EmailKey))
+	if owner.Routable() {
+		owner.Source = SourceNamespace
+		return owner
+	}
+	if team, ok := r.cfg.Teams[owner.Team]; ok && owner.Team != "" {
+		owner.Webhook = team.Webhook
+		owner.Emails = team.Emails
+		if owner.Contact == "" {
+			owner.Contact = team.Contact
+		}
+		owner.Source = SourceTeam
+	}
+	return owner
+}
+
+func (r *Resolver) allowedWebhook(webhook string) bool {
+	for _, prefix := range r.cfg.WebhookPrefixes {
+		if strings.HasPrefix(webhook, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Owners returns the cached owners sorted by namespace, including expired ones
+// until they are resolved again
+func (r *Resolver) Owners() []Owner {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	owners := make([]Owner, 0, len(r.cache))
+	for _, entry := range r.cache {
+		owners = append(owners, entry.owner)
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Namespace < owners[j].Namespace })
+	return owners
+}
+
+// Invalidate drops the cached owner of namespace, or of every namespace when
+// namespace is empty
+func (r *Resolver) Invalidate(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if namespace == "" {
+		r.cache = make(map[string]cacheEntry)
+		return
+	}
+	delete(r.cache, namespace)
+}
+
+// parseEmails splits comma separated addresses and returns the valid ones,
+// with an error naming the first invalid address
+func parseEmails(value string) ([]string, error) {
+	var (
+		emails  []string
+		invalid error
+	)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(part)
+		if err != nil {
+			if invalid == nil {
+				invalid = fmt.Errorf("invalid email %q", part)
+			}
+			continue
+		}
+		emails = append(emails, address.Address)
+	}
+	return emails, invalid
+}
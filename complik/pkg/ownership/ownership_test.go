@@ -0,0 +1,187 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ownership
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestOwnership(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ownership Suite")
+}
+
+const teamWebhook = "https://open.feishu.cn/open-apis/bot/v2/hook/payments"
+
+type namespace struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var _ = Describe("Resolver", func() {
+	ctx := context.Background()
+	var (
+		namespaces map[string]namespace
+		lookups    int
+		failure    error
+		resolver   *Resolver
+		now        time.Time
+	)
+
+	lookup := func(_ context.Context, name string) (map[string]string, map[string]string, error) {
+		lookups++
+		if failure != nil {
+			return nil, nil, failure
+		}
+		ns, ok := namespaces[name]
+		if !ok {
+			return nil, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name)
+		}
+		return ns.labels, ns.annotations, nil
+	}
+
+	BeforeEach(func() {
+		lookups, failure = 0, nil
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		namespaces = map[string]namespace{
+			"ns-pay": {labels: map[string]string{DefaultTeamKey: "payments"}},
+			"ns-own": {annotations: map[string]string{
+				DefaultTeamKey:    "search",
+				DefaultContactKey: "bob",
+				DefaultWebhookKey: "https://open.larksuite.com/open-apis/bot/v2/hook/search",
+				DefaultEmailKey:   "search@example.com, not-an-email",
+			}},
+			"ns-evil": {annotations: map[string]string{DefaultWebhookKey: "http://attacker.example.com/hook"}},
+			"ns-none": {},
+		}
+		var err error
+		resolver, err = NewResolver(Config{
+			Teams: map[string]Team{"payments": {Webhook: teamWebhook, Contact: "alice"}},
+		}, lookup)
+		Expect(err).NotTo(HaveOccurred())
+		resolver.now = func() time.Time { return now }
+	})
+
+	It("should take the channels of the team of a namespace", func() {
+		owner := resolver.Resolve(ctx, "ns-pay")
+		Expect(owner.Source).To(Equal(SourceTeam))
+		Expect(owner.Webhook).To(Equal(teamWebhook))
+		Expect(owner.Contact).To(Equal("alice"))
+		Expect(owner.Routable()).To(BeTrue())
+	})
+
+	It("should prefer the channels set on the namespace", func() {
+		owner := resolver.Resolve(ctx, "ns-own")
+		Expect(owner.Source).To(Equal(SourceNamespace))
+		Expect(owner.Team).To(Equal("search"))
+		Expect(owner.Contact).To(Equal("bob"))
+		Expect(owner.Webhook).To(HavePrefix("https://open.larksuite.com/"))
+		Expect(owner.Emails).To(Equal([]string{"search@example.com"}))
+	})
+
+	It("should fall back for namespaces without a channel or with a foreign webhook", func() {
+		for _, name := range []string{"ns-none", "ns-evil", "ns-deleted"} {
+			owner := resolver.Resolve(ctx, name)
+			Expect(owner.Source).To(Equal(SourceFallback), name)
+			Expect(owner.Routable()).To(BeFalse(), name)
+		}
+	})
+
+	It("should cache owners until they expire", func() {
+		resolver.Resolve(ctx, "ns-pay")
+		resolver.Resolve(ctx, "ns-pay")
+		Expect(lookups).To(Equal(1))
+		now = now.Add(11 * time.Minute)
+		resolver.Resolve(ctx, "ns-pay")
+		Expect(lookups).To(Equal(2))
+
+		resolver.Invalidate("ns-pay")
+		resolver.Resolve(ctx, "ns-pay")
+		Expect(lookups).To(Equal(3))
+		Expect(resolver.Owners()).To(HaveLen(1))
+	})
+
+	It("should not cache failed lookups", func() {
+		failure = errors.New("connection refused")
+		owner := resolver.Resolve(ctx, "ns-pay")
+		Expect(owner.Source).To(Equal(SourceFallback))
+		Expect(owner.Error).To(ContainSubstring("connection refused"))
+		failure = nil
+		Expect(resolver.Resolve(ctx, "ns-pay").Source).To(Equal(SourceTeam))
+		Expect(lookups).To(Equal(2))
+	})
+
+	It("should reject teams without a valid channel", func() {
+		_, err := NewResolver(Config{Teams: map[string]Team{"empty": {}}}, lookup)
+		Expect(err).To(MatchError(ContainSubstring("webhook or emails are required")))
+		_, err = NewResolver(Config{Teams: map[string]Team{"bad": {Emails: []string{"nobody"}}}}, lookup)
+		Expect(err).To(MatchError(ContainSubstring("invalid email")))
+	})
+
+	Describe("API", func() {
+		It("should list, resolve and invalidate owners with the token", func() {
+			api := NewAPI("secret", resolver)
+			request := func(method, path string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, nil)
+				req.Header.Set("Authorization", "Bearer secret")
+				rec := httptest.NewRecorder()
+				api.ServeHTTP(rec, req)
+				return rec
+			}
+
+			rec := request(http.MethodGet, "/api/v1/owners/ns-pay")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var owner Owner
+			Expect(json.Unmarshal(rec.Body.Bytes(), &owner)).To(Succeed())
+			Expect(owner.Team).To(Equal("payments"))
+
+			rec = request(http.MethodGet, "/api/v1/owners")
+			var list struct {
+				Owners []Owner `json:"owners"`
+			}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &list)).To(Succeed())
+			Expect(list.Owners).To(HaveLen(1))
+
+			Expect(request(http.MethodDelete, "/api/v1/owners/*").Code).To(Equal(http.StatusNoContent))
+			Expect(resolver.Owners()).To(BeEmpty())
+
+			rec = httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/owners", nil))
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should refuse every request without a configured token", func() {
+			resolver.Resolve(ctx, "ns-pay")
+			api := NewAPI("", resolver)
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/owners/*", nil)
+			req.Header.Set("Authorization", "Bearer ")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(resolver.Owners()).To(HaveLen(1))
+		})
+	})
+})
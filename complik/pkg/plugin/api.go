@@ -0,0 +1,112 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// API serves the plugin management endpoints:
+//
+//	GET  /api/v1/plugins
+//	GET  /api/v1/plugins/{name}
+//	POST /api/v1/plugins/{name}/enable
+//	POST /api/v1/plugins/{name}/disable
+//	POST /api/v1/plugins/{name}/restart
+//	PUT  /api/v1/plugins/{name}/log-level  {"level": "debug"}
+//
+// The changing endpoints respond with the plugin state after the change. An
+// empty level resets the plugin to the global log level.
+type API struct {
+	log     logger.Logger
+	manager *Manager
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewAPI(log logger.Logger, token string, manager *Manager) *API {
+	api := &API{log: log, manager: manager, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/plugins", api.list)
+	api.mux.HandleFunc("GET /api/v1/plugins/{name}", api.get)
+	api.mux.HandleFunc("POST /api/v1/plugins/{name}/enable", api.change(manager.Enable))
+	api.mux.HandleFunc("POST /api/v1/plugins/{name}/disable", api.change(manager.Disable))
+	api.mux.HandleFunc("POST /api/v1/plugins/{name}/restart", api.change(manager.Restart))
+	api.mux.HandleFunc("PUT /api/v1/plugins/{name}/log-level", api.setLogLevel)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) list(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.manager.Plugins())
+}
+
+func (a *API) get(w http.ResponseWriter, r *http.Request) {
+	info, err := a.manager.Plugin(r.PathValue("name"))
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, info)
+}
+
+func (a *API) change(apply func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := apply(name); err != nil {
+			a.fail(w, err)
+			return
+		}
+		a.get(w, r)
+	}
+}
+
+func (a *API) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := a.manager.SetLogLevel(r.PathValue("name"), req.Level); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.get(w, r)
+}
+
+// fail maps manager errors to HTTP status codes
+func (a *API) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPluginNotFound):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrPluginDisabled):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidLogLevel):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		a.log.Error("Plugin API request failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
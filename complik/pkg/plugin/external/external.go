@@ -0,0 +1,336 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package external runs plugins built with the plugin sdk as separate
+// processes and adapts them to the plugin manager.
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin/sdk"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+const (
+	// DescribeTimeout bounds launching a plugin and asking for its identity
+	DescribeTimeout = 30 * time.Second
+	// HandleTimeout bounds the delivery of a single event to a plugin
+	HandleTimeout = 5 * time.Minute
+)
+
+// pluginTypePrefixes are the plugin types an external plugin may declare
+var pluginTypePrefixes = []string{"Discovery.", "Compliance.", "Handle."}
+
+// Register launches every executable in dir, asks it for its identity and
+// registers a factory under its name in plugin.PluginFactories, so external
+// plugins are configured like built-in ones. The probe processes are stopped
+// again; a plugin only keeps running between Start and Stop. Executables that
+// fail the handshake or reuse the name of a registered plugin are skipped.
+func Register(dir string) ([]string, error) {
+	log := logger.GetLogger()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !isExecutable(path) {
+			continue
+		}
+		info, err := describe(path)
+		if err != nil {
+			log.Warn("Skipping external plugin", logger.Fields{"path": path, "error": err.Error()})
+			continue
+		}
+		if _, exists := plugin.PluginFactories[info.Name]; exists {
+			log.Warn("Skipping external plugin with the name of a registered plugin", logger.Fields{
+				"path":   path,
+				"plugin": info.Name,
+			})
+			continue
+		}
+		plugin.PluginFactories[info.Name] = func() plugin.Plugin {
+			return New(path, info)
+		}
+		names = append(names, info.Name)
+		log.Info("External plugin registered", logger.Fields{
+			"path":      path,
+			"plugin":    info.Name,
+			"type":      info.Type,
+			"subscribe": info.Subscribe,
+		})
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func isExecutable(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.Mode().IsRegular() && stat.Mode().Perm()&0o111 != 0
+}
+
+func describe(path string) (sdk.Info, error) {
+	client, remote, err := launch(path)
+	if err != nil {
+		return sdk.Info{}, err
+	}
+	defer client.Kill()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DescribeTimeout)
+	defer cancel()
+	info, err := remote.Describe(ctx)
+	if err != nil {
+		return sdk.Info{}, fmt.Errorf("describe failed: %w", err)
+	}
+	if info.Name == "" {
+		return sdk.Info{}, errors.New("plugin has no name")
+	}
+	if !validType(info.Type) {
+		return sdk.Info{}, fmt.Errorf("plugin %s has unsupported type %q", info.Name, info.Type)
+	}
+	return info, nil
+}
+
+func validType(pluginType string) bool {
+	for _, prefix := range pluginTypePrefixes {
+		if strings.HasPrefix(pluginType, prefix) && len(pluginType) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func launch(path string) (*goplugin.Client, sdk.Plugin, error) {
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  sdk.Handshake,
+		Plugins:          sdk.PluginSet(nil),
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		StartTimeout:     DescribeTimeout,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin." + filepath.Base(path),
+			Output: os.Stderr,
+			Level:  hclog.Info,
+		}),
+	})
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("failed to launch plugin: %w", err)
+	}
+	raw, err := rpcClient.Dispense(sdk.PluginName)
+	if err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("failed to dispense plugin: %w", err)
+	}
+	remote, ok := raw.(sdk.Plugin)
+	if !ok {
+		client.Kill()
+		return nil, nil, fmt.Errorf("unexpected plugin client %T", raw)
+	}
+	return client, remote, nil
+}
+
+// Plugin runs an external plugin process as a plugin.Plugin. Start launches
+// the process and forwards the events of the subscribed topics to it; events
+// the plugin publishes are decoded into the registered topic models and put
+// on the event bus.
+type Plugin struct {
+	path string
+	info sdk.Info
+
+	mu            sync.Mutex
+	client        *goplugin.Client
+	remote        sdk.Plugin
+	eventBus      *eventbus.EventBus
+	subscriptions map[string]eventbus.EventChan
+	wg            sync.WaitGroup
+}
+
+// New creates the plugin for the executable at path described by info
+func New(path string, info sdk.Info) *Plugin {
+	return &Plugin{path: path, info: info}
+}
+
+func (p *Plugin) Name() string {
+	return p.info.Name
+}
+
+func (p *Plugin) Type() string {
+	return p.info.Type
+}
+
+func (p *Plugin) Start(ctx context.Context, pluginConfig config.PluginConfig, eventBus *eventbus.EventBus) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return fmt.Errorf("plugin %s is already started", p.info.Name)
+	}
+
+	client, remote, err := launch(p.path)
+	if err != nil {
+		return err
+	}
+	cfg := sdk.Config{Settings: pluginConfig.Settings, DryRun: pluginConfig.DryRun}
+	if err := remote.Start(ctx, cfg, &busPublisher{eventBus: eventBus}); err != nil {
+		client.Kill()
+		return fmt.Errorf("plugin %s failed to start: %w", p.info.Name, err)
+	}
+	p.client, p.remote, p.eventBus = client, remote, eventBus
+
+	p.subscriptions = make(map[string]eventbus.EventChan, len(p.info.Subscribe))
+	for _, topic := range p.info.Subscribe {
+		ch := eventBus.Subscribe(topic)
+		p.subscriptions[topic] = ch
+		p.wg.Add(1)
+		go p.forward(topic, ch, remote)
+	}
+	return nil
+}
+
+// forward delivers the events of topic to the plugin until ch is closed
+func (p *Plugin) forward(topic string, ch eventbus.EventChan, remote sdk.Plugin) {
+	defer p.wg.Done()
+	log := logger.GetLogger().WithField("plugin", p.info.Name)
+	for event := range ch {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			log.Error("Failed to encode event for external plugin", logger.Fields{
+				"topic": topic,
+				"error": err.Error(),
+			})
+			continue
+		}
+		// Events of wildcard subscriptions carry the topic they were published to
+		published := topic
+		if event.Topic != "" {
+			published = event.Topic
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), HandleTimeout)
+		err = remote.Handle(ctx, sdk.Event{Topic: published, Payload: payload})
+		cancel()
+		if err != nil {
+			log.Error("External plugin failed to handle event", logger.Fields{
+				"topic": topic,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (p *Plugin) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		return nil
+	}
+	for topic, ch := range p.subscriptions {
+		p.eventBus.Unsubscribe(topic, ch)
+	}
+	p.subscriptions = nil
+	p.wg.Wait()
+
+	err := p.remote.Stop(ctx)
+	p.client.Kill()
+	p.client, p.remote = nil, nil
+	if err != nil {
+		return fmt.Errorf("plugin %s failed to stop: %w", p.info.Name, err)
+	}
+	return nil
+}
+
+// HealthCheck reports whether the plugin process is still serving
+func (p *Plugin) HealthCheck(ctx context.Context) error {
+	p.mu.Lock()
+	client := p.client
+	p.mu.Unlock()
+	if client == nil {
+		return errors.New("plugin process is not running")
+	}
+	if client.Exited() {
+		return errors.New("plugin process exited")
+	}
+	rpcClient, err := client.Client()
+	if err != nil {
+		return err
+	}
+	return rpcClient.Ping()
+}
+
+// busPublisher puts the events published by a plugin on the event bus
+type busPublisher struct {
+	eventBus *eventbus.EventBus
+}
+
+func (b *busPublisher) Publish(_ context.Context, topic string, payload any) error {
+	raw, ok := payload.(json.RawMessage)
+	if !ok {
+		return b.eventBus.Publish(topic, eventbus.Event{Payload: payload})
+	}
+	decoded, err := decodePayload(b.eventBus.Registry(), topic, raw)
+	if err != nil {
+		return err
+	}
+	return b.eventBus.Publish(topic, eventbus.Event{Payload: decoded})
+}
+
+// decodePayload decodes raw into the model registered for topic, or into a
+// generic JSON value for topics without a schema
+func decodePayload(registry *eventbus.Registry, topic string, raw json.RawMessage) (any, error) {
+	var schema eventbus.Schema
+	ok := false
+	if registry != nil {
+		schema, ok = registry.Schema(topic)
+	}
+	if !ok {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("invalid payload on topic %s: %w", topic, err)
+		}
+		return value, nil
+	}
+
+	t := schema.Type
+	pointer := t.Kind() == reflect.Pointer
+	if pointer {
+		t = t.Elem()
+	}
+	value := reflect.New(t)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid payload on topic %s: %w", topic, err)
+	}
+	if pointer {
+		return value.Interface(), nil
+	}
+	return value.Elem().Interface(), nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin/sdk"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// servePluginEnv makes the test binary serve echoPlugin instead of running
+// the suite, so the suite can launch itself as an external plugin
+const servePluginEnv = "COMPLIK_TEST_SERVE_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnv) != "" {
+		sdk.Serve(&echoPlugin{})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestExternal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "External Plugin Suite")
+}
+
+// echoPlugin turns every discovered resource into an empty collector result
+// carrying its settings
+type echoPlugin struct {
+	cfg       sdk.Config
+	publisher sdk.Publisher
+}
+
+func (p *echoPlugin) Describe(context.Context) (sdk.Info, error) {
+	return sdk.Info{
+		Name:      "Echo",
+		Type:      constants.ComplianceCollectorPluginType,
+		Subscribe: []string{constants.DiscoveryTopic},
+	}, nil
+}
+
+func (p *echoPlugin) Start(_ context.Context, cfg sdk.Config, publisher sdk.Publisher) error {
+	p.cfg, p.publisher = cfg, publisher
+	return nil
+}
+
+func (p *echoPlugin) Handle(ctx context.Context, event sdk.Event) error {
+	var discovery models.DiscoveryInfo
+	if err := json.Unmarshal(event.Payload, &discovery); err != nil {
+		return err
+	}
+	return p.publisher.Publish(ctx, constants.CollectorTopic, &models.CollectorInfo{
+		DiscoveryName:    discovery.DiscoveryName,
+		CollectorName:    "Echo",
+		Name:             discovery.Name,
+		Namespace:        discovery.Namespace,
+		Host:             discovery.Host,
+		CollectorMessage: fmt.Sprintf("%s dry-run=%t", p.cfg.Settings, p.cfg.DryRun),
+		IsEmpty:          true,
+	})
+}
+
+func (p *echoPlugin) Stop(context.Context) error {
+	return nil
+}
+
+func writeScript(dir, name, body string) {
+	path := filepath.Join(dir, name)
+	Expect(os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755)).To(Succeed())
+}
+
+var _ = Describe("External plugins", func() {
+	var dir string
+
+	BeforeEach(func() {
+		executable, err := os.Executable()
+		Expect(err).NotTo(HaveOccurred())
+		dir = GinkgoT().TempDir()
+		writeScript(dir, "echo", fmt.Sprintf("%s=1 exec %q", servePluginEnv, executable))
+		DeferCleanup(func() {
+			delete(plugin.PluginFactories, "Echo")
+		})
+	})
+
+	It("should register the plugins of the directory and skip the others", func() {
+		writeScript(dir, "broken", "exit 1")
+		Expect(os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644)).To(Succeed())
+
+		names, err := Register(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"Echo"}))
+		Expect(plugin.PluginFactories).To(HaveKey("Echo"))
+
+		p := plugin.PluginFactories["Echo"]()
+		Expect(p.Name()).To(Equal("Echo"))
+		Expect(p.Type()).To(Equal(constants.ComplianceCollectorPluginType))
+	})
+
+	It("should not replace registered plugins", func() {
+		_, err := Register(dir)
+		Expect(err).NotTo(HaveOccurred())
+		names, err := Register(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(BeEmpty())
+	})
+
+	It("should fail for a missing directory", func() {
+		_, err := Register(filepath.Join(dir, "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read plugin directory")))
+	})
+
+	It("should exchange typed events with the plugin process", func() {
+		_, err := Register(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		bus := eventbus.NewEventBus(10)
+		registry := eventbus.NewRegistry()
+		Expect(models.RegisterSchemas(registry)).To(Succeed())
+		bus.SetRegistry(registry)
+		results := bus.Subscribe(constants.CollectorTopic)
+
+		p := plugin.PluginFactories["Echo"]()
+		Expect(p.Start(context.Background(), config.PluginConfig{
+			Name:     "Echo",
+			Settings: `{"mode":"test"}`,
+			DryRun:   true,
+		}, bus)).To(Succeed())
+		DeferCleanup(p.Stop, context.Background())
+		Expect(p.(plugin.HealthChecker).HealthCheck(context.Background())).To(Succeed())
+
+		Expect(bus.Publish(constants.DiscoveryTopic, eventbus.Event{Payload: models.DiscoveryInfo{
+			DiscoveryName: "Ingress",
+			Name:          "shop",
+			Namespace:     "ns-a",
+			Host:          "shop.example.com",
+		}})).To(Succeed())
+
+		var event eventbus.Event
+		Eventually(results, 10*time.Second).Should(Receive(&event))
+		info, err := eventbus.Decode[*models.CollectorInfo](event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.CollectorName).To(Equal("Echo"))
+		Expect(info.Host).To(Equal("shop.example.com"))
+		Expect(info.CollectorMessage).To(Equal(`{"mode":"test"} dry-run=true`))
+		Expect(info.SchemaVersion).To(Equal(models.CollectorInfoVersion))
+
+		Expect(p.Stop(context.Background())).To(Succeed())
+		Expect(p.(plugin.HealthChecker).HealthCheck(context.Background())).
+			To(MatchError("plugin process is not running"))
+	})
+
+	It("should decode payloads of topics without a schema generically", func() {
+		payload, err := decodePayload(eventbus.NewRegistry(), "custom", json.RawMessage(`{"a":1}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload).To(Equal(map[string]any{"a": float64(1)}))
+
+		_, err = decodePayload(nil, "custom", json.RawMessage(`{`))
+		Expect(err).To(MatchError(ContainSubstring("invalid payload on topic custom")))
+	})
+})
@@ -31,5 +31,11 @@ type Plugin interface {
 	Stop(ctx context.Context) error
 }
 
+// HealthChecker is implemented by plugins that depend on external services,
+// such as a database, and can report whether those are reachable.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // PluginFactory creates plugin instances by name.
 type PluginFactory func(name string) Plugin
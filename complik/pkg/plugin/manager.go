@@ -17,37 +17,95 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/filter"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
 )
 
 const (
 	PluginStopTimeout = 20 * time.Second
+	// StartupGracePeriod is how long a plugin may stay in Start before it is
+	// considered running. Plugins whose Start runs their event loop never return.
+	StartupGracePeriod = 5 * time.Second
 )
 
+// Plugin lifecycle states reported by Manager.Statuses
+const (
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StateFailed   = "failed"
+	StateStopped  = "stopped"
+)
+
+// PluginStatus is the lifecycle state of a started plugin
+type PluginStatus struct {
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+	Since time.Time `json:"since"`
+}
+
 var PluginFactories = make(map[string]func() Plugin)
 
 type PluginInstance struct {
 	Plugin Plugin
 	Config config.PluginConfig
+
+	// eventBus is the event bus filtered by the filter of Config, joining
+	// the consumer group of Config
+	eventBus *eventbus.EventBus
+
+	// factory creates the fresh instance a runtime restart starts
+	factory func() Plugin
 }
 type Manager struct {
 	pluginInstances map[string]*PluginInstance
 	eventBus        *eventbus.EventBus
 	mu              sync.RWMutex
+
+	dryRun            bool
+	simulatedHandlers []string
+
+	statusMu sync.RWMutex
+	statuses map[string]PluginStatus
+
+	// opMu serializes runtime enable, disable and restart requests
+	opMu      sync.Mutex
+	statePath string
+	overrides map[string]bool
 }
 
 func NewManager(eventBus *eventbus.EventBus) *Manager {
 	return &Manager{
 		pluginInstances: make(map[string]*PluginInstance),
 		eventBus:        eventBus,
+		statuses:        make(map[string]PluginStatus),
+		overrides:       make(map[string]bool),
 	}
 }
 
+// SetDryRun switches the manager to simulation mode. Handler plugins loaded
+// afterwards are not started and every other plugin is started with
+// PluginConfig.DryRun set.
+func (m *Manager) SetDryRun(dryRun bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dryRun = dryRun
+}
+
+// SimulatedHandlers returns the enabled handler plugins skipped in dry-run mode
+func (m *Manager) SimulatedHandlers() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.simulatedHandlers...)
+}
+
 func (m *Manager) LoadPlugins(pluginConfigs []config.PluginConfig) error {
 	log := logger.GetLogger()
 	log.Info("Loading plugins", logger.Fields{"count": len(pluginConfigs)})
@@ -83,10 +141,46 @@ func (m *Manager) LoadPlugin(pluginConfig config.PluginConfig) error {
 		return nil
 	}
 
+	if enabled, ok := m.overrides[pluginConfig.Name]; ok && enabled != pluginConfig.Enabled {
+		log.Info("Plugin enablement overridden by runtime state", logger.Fields{
+			"plugin":  pluginConfig.Name,
+			"enabled": enabled,
+		})
+		pluginConfig.Enabled = enabled
+	}
+
+	eventBus, err := m.filteredBus(pluginConfig)
+	if err != nil {
+		return err
+	}
+	if pluginConfig.Group != "" {
+		log.Info("Plugin joins consumer group", logger.Fields{
+			"plugin": pluginConfig.Name,
+			"group":  pluginConfig.Group,
+		})
+		eventBus = eventBus.WithGroup(pluginConfig.Group)
+	}
+
 	plugin := factory()
+	if m.dryRun {
+		if strings.HasPrefix(plugin.Type(), constants.HandlePluginTypePrefix) {
+			if pluginConfig.Enabled {
+				m.simulatedHandlers = append(m.simulatedHandlers, pluginConfig.Name)
+			}
+			log.Info("Dry-run: handler plugin replaced by simulation", logger.Fields{
+				"plugin": pluginConfig.Name,
+				"type":   plugin.Type(),
+			})
+			return nil
+		}
+		pluginConfig.DryRun = true
+	}
+
 	instance := &PluginInstance{
-		Plugin: plugin,
-		Config: pluginConfig,
+		Plugin:   plugin,
+		Config:   pluginConfig,
+		eventBus: eventBus,
+		factory:  factory,
 	}
 	m.pluginInstances[pluginConfig.Name] = instance
 
@@ -98,6 +192,34 @@ func (m *Manager) LoadPlugin(pluginConfig config.PluginConfig) error {
 	return nil
 }
 
+// filteredBus returns the view of the event bus the plugin of pluginConfig
+// subscribes through, the event bus itself without a filter
+func (m *Manager) filteredBus(pluginConfig config.PluginConfig) (*eventbus.EventBus, error) {
+	f, err := filter.New(pluginConfig.Filter.Include, pluginConfig.Filter.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter of plugin %s: %w", pluginConfig.Name, err)
+	}
+	if f == nil {
+		return m.eventBus, nil
+	}
+	log := logger.GetLogger().WithField("plugin", pluginConfig.Name)
+	log.Info("Events are filtered", logger.Fields{
+		"include": pluginConfig.Filter.Include,
+		"exclude": pluginConfig.Filter.Exclude,
+	})
+	return m.eventBus.WithFilter(func(topic string, payload any) bool {
+		matched, err := f.Match(payload)
+		if err != nil {
+			log.Warn("Event filter failed", logger.Fields{
+				"topic":   topic,
+				"matched": matched,
+				"error":   err.Error(),
+			})
+		}
+		return matched
+	}), nil
+}
+
 func getRegisteredFactoryNames() []string {
 	names := make([]string, 0, len(PluginFactories))
 	for name := range PluginFactories {
@@ -112,45 +234,76 @@ func (m *Manager) StartAll() error {
 
 func (m *Manager) StartAllWithTimeout() error {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	instances := make(map[string]*PluginInstance, len(m.pluginInstances))
+	for name, instance := range m.pluginInstances {
+		instances[name] = instance
+	}
+	m.mu.RUnlock()
+
 	log := logger.GetLogger()
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(m.pluginInstances))
-	for name, instance := range m.pluginInstances {
+	errChan := make(chan error, len(instances))
+	for name, instance := range instances {
 		if !instance.Config.Enabled {
 			log.Debug("Plugin disabled, skipping", logger.Fields{"plugin": name})
 			continue
 		}
 		wg.Add(1)
-		log.Info("Starting plugin", logger.Fields{"plugin": name})
-		go func(name string, instance *PluginInstance) {
+		go func() {
 			defer wg.Done()
-			pluginLog := log.WithField("plugin", name)
-			if err := instance.Plugin.Start(context.Background(), instance.Config, m.eventBus); err != nil {
-				pluginLog.Error("Plugin failed", logger.Fields{"error": err.Error()})
-				errChan <- fmt.Errorf("plugin %s failed to start: %w", name, err)
-			} else {
-				pluginLog.Info("Plugin started successfully")
+			if err := <-m.startPlugin(name, instance); err != nil {
+				errChan <- err
 			}
-		}(name, instance)
+		}()
+	}
+	wg.Wait()
+	close(errChan)
+	var errors []error
+	for err := range errChan {
+		errors = append(errors, err)
 	}
-	done := make(chan struct{})
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to start %d plugins: %v", len(errors), errors)
+	}
+	return nil
+}
+
+// startPlugin starts instance in the background and records its lifecycle
+// state. The returned channel receives the result once Start returns.
+func (m *Manager) startPlugin(name string, instance *PluginInstance) <-chan error {
+	log := logger.GetLogger()
+	log.Info("Starting plugin", logger.Fields{"plugin": name})
+	m.setStatus(name, StateStarting, nil)
+	result := make(chan error, 1)
 	go func() {
-		wg.Wait()
-		close(done)
-	}()
-	select {
-	case <-done:
-		close(errChan)
-		var errors []error
-		for err := range errChan {
-			errors = append(errors, err)
+		pluginLog := log.WithField("plugin", name)
+		grace := time.AfterFunc(StartupGracePeriod, func() {
+			m.transition(name, StateStarting, StateRunning)
+		})
+		err := instance.Plugin.Start(context.Background(), instance.Config, instance.eventBus)
+		grace.Stop()
+		if !m.isCurrent(name, instance) {
+			// Replaced by a runtime restart while starting
+			result <- err
+			return
 		}
-		if len(errors) > 0 {
-			return fmt.Errorf("failed to start %d plugins: %v", len(errors), errors)
+		if err != nil {
+			m.setStatus(name, StateFailed, err)
+			pluginLog.Error("Plugin failed", logger.Fields{"error": err.Error()})
+			result <- fmt.Errorf("plugin %s failed to start: %w", name, err)
+			return
 		}
-		return nil
-	}
+		m.transition(name, StateStarting, StateRunning)
+		pluginLog.Info("Plugin started successfully")
+		result <- nil
+	}()
+	return result
+}
+
+func (m *Manager) isCurrent(name string, instance *PluginInstance) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pluginInstances[name] == instance
 }
 
 func (m *Manager) StopAll() error {
@@ -159,7 +312,12 @@ func (m *Manager) StopAll() error {
 	defer m.mu.RUnlock()
 	log := logger.GetLogger()
 	log.Info("Stopping all plugins")
+	statuses := m.Statuses()
 	for name, instance := range m.pluginInstances {
+		if statuses[name].State == StateStopped {
+			// Disabled at runtime
+			continue
+		}
 		log.Info("Stopping plugin", logger.Fields{"plugin": name})
 		if err := instance.Plugin.Stop(ctx); err != nil {
 			log.Error("Error stopping plugin", logger.Fields{
@@ -169,8 +327,91 @@ func (m *Manager) StopAll() error {
 		} else {
 			log.Debug("Plugin stopped", logger.Fields{"plugin": name})
 		}
+		m.transition(name, "", StateStopped)
 	}
 	cancel()
 	log.Info("All plugins stopped")
 	return nil
 }
+
+// Statuses returns the lifecycle state of every plugin StartAll started
+func (m *Manager) Statuses() map[string]PluginStatus {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	statuses := make(map[string]PluginStatus, len(m.statuses))
+	for name, status := range m.statuses {
+		statuses[name] = status
+	}
+	return statuses
+}
+
+// Ready returns an error unless every enabled plugin is running
+func (m *Manager) Ready() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := m.Statuses()
+	var notReady []string
+	for name, instance := range m.pluginInstances {
+		if !instance.Config.Enabled {
+			continue
+		}
+		status, ok := statuses[name]
+		switch {
+		case !ok:
+			notReady = append(notReady, name+" (not started)")
+		case status.State != StateRunning:
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", name, status.State))
+		}
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return fmt.Errorf("plugins not running: %s", strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+// CheckHealth runs the health checks of the running plugins that implement
+// HealthChecker and returns the failures by plugin name
+func (m *Manager) CheckHealth(ctx context.Context) map[string]error {
+	m.mu.RLock()
+	checkers := make(map[string]HealthChecker)
+	for name, instance := range m.pluginInstances {
+		if checker, ok := instance.Plugin.(HealthChecker); ok {
+			checkers[name] = checker
+		}
+	}
+	m.mu.RUnlock()
+	statuses := m.Statuses()
+	failures := make(map[string]error)
+	for name, checker := range checkers {
+		if statuses[name].State != StateRunning {
+			continue
+		}
+		if err := checker.HealthCheck(ctx); err != nil {
+			failures[name] = err
+		}
+	}
+	return failures
+}
+
+func (m *Manager) setStatus(name, state string, err error) {
+	status := PluginStatus{State: state, Since: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	m.statuses[name] = status
+}
+
+// transition moves name to state if it is currently in from; an empty from
+// matches any state of a started plugin
+func (m *Manager) transition(name, from, state string) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	current, ok := m.statuses[name]
+	if !ok || (from != "" && current.State != from) {
+		return
+	}
+	m.statuses[name] = PluginStatus{State: state, Since: time.Now()}
+}
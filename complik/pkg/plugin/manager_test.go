@@ -199,6 +199,83 @@ var _ = Describe("PluginManager", func() {
 		})
 	})
 
+	Describe("Filter", func() {
+		It("should subscribe plugins through their filter", func() {
+			PluginFactories["lark"] = func() Plugin { return NewMockPlugin("lark", "handle") }
+			err := manager.LoadPlugin(config.PluginConfig{
+				Name:    "lark",
+				Enabled: true,
+				Filter:  config.FilterConfig{Include: `severity >= "high"`},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			manager.mu.RLock()
+			bus := manager.pluginInstances["lark"].eventBus
+			manager.mu.RUnlock()
+			ch := bus.Subscribe("detector")
+			eb.Publish("detector", eventbus.Event{Payload: map[string]any{"severity": "low"}})
+			eb.Publish("detector", eventbus.Event{Payload: map[string]any{"severity": "critical"}})
+			critical := map[string]any{"severity": "critical"}
+			Eventually(ch).Should(Receive(Equal(eventbus.Event{
+				Payload: critical,
+				Key:     eventbus.IdempotencyKey("detector", critical),
+			})))
+			Consistently(ch, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("should share the events of plugins in the same consumer group", func() {
+			for _, name := range []string{"lark-a", "lark-b"} {
+				PluginFactories[name] = func() Plugin { return NewMockPlugin(name, "handle") }
+				Expect(manager.LoadPlugin(config.PluginConfig{Name: name, Enabled: true, Group: "lark"})).To(Succeed())
+			}
+
+			manager.mu.RLock()
+			a := manager.pluginInstances["lark-a"].eventBus.Subscribe("detector")
+			b := manager.pluginInstances["lark-b"].eventBus.Subscribe("detector")
+			manager.mu.RUnlock()
+			eb.Publish("detector", eventbus.Event{Payload: 1})
+			eb.Publish("detector", eventbus.Event{Payload: 2})
+			Eventually(func() int { return len(a) + len(b) }).Should(Equal(2))
+			Expect(a).To(HaveLen(1))
+			Expect(b).To(HaveLen(1))
+		})
+
+		It("should not load plugins with an invalid filter", func() {
+			PluginFactories["lark"] = func() Plugin { return NewMockPlugin("lark", "handle") }
+			err := manager.LoadPlugin(config.PluginConfig{
+				Name:   "lark",
+				Filter: config.FilterConfig{Exclude: `namespace.matches(`},
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid filter of plugin lark")))
+			Expect(manager.pluginInstances).NotTo(HaveKey("lark"))
+		})
+	})
+
+	Describe("DryRun", func() {
+		It("should skip handlers and flag the remaining plugins", func() {
+			detector := NewMockPlugin("test-detector", "Compliance.Detector")
+			handler := NewMockPlugin("test-handler", "Handle.Lark")
+			PluginFactories["test-detector"] = func() Plugin { return detector }
+			PluginFactories["test-handler"] = func() Plugin { return handler }
+
+			manager.SetDryRun(true)
+			Expect(manager.LoadPlugins([]config.PluginConfig{
+				{Name: "test-detector", Type: "Compliance", Enabled: true},
+				{Name: "test-handler", Type: "Handle", Enabled: true},
+			})).To(Succeed())
+
+			manager.mu.RLock()
+			instance, detectorLoaded := manager.pluginInstances["test-detector"]
+			_, handlerLoaded := manager.pluginInstances["test-handler"]
+			manager.mu.RUnlock()
+
+			Expect(detectorLoaded).To(BeTrue())
+			Expect(instance.Config.DryRun).To(BeTrue())
+			Expect(handlerLoaded).To(BeFalse())
+			Expect(manager.SimulatedHandlers()).To(ConsistOf("test-handler"))
+		})
+	})
+
 	Describe("LoadPlugins", func() {
 		It("should load multiple plugins", func() {
 			plugin1 := NewMockPlugin("plugin1", "discovery")
@@ -429,3 +506,64 @@ var _ = Describe("PluginManager", func() {
 		})
 	})
 })
+
+var _ = Describe("PluginManager status", func() {
+	var (
+		manager      *Manager
+		oldFactories map[string]func() Plugin
+	)
+
+	BeforeEach(func() {
+		oldFactories = PluginFactories
+		PluginFactories = make(map[string]func() Plugin)
+		manager = NewManager(eventbus.NewEventBus(10))
+	})
+
+	AfterEach(func() {
+		PluginFactories = oldFactories
+	})
+
+	It("should only be ready once every enabled plugin runs", func() {
+		ok := NewMockPlugin("ok", "discovery")
+		broken := NewMockPlugin("broken", "compliance")
+		broken.startErr = errors.New("no database")
+		PluginFactories["ok"] = func() Plugin { return ok }
+		PluginFactories["broken"] = func() Plugin { return broken }
+		Expect(manager.LoadPlugins([]config.PluginConfig{
+			{Name: "ok", Enabled: true},
+			{Name: "broken", Enabled: true},
+		})).To(Succeed())
+		Expect(manager.Ready()).To(MatchError(ContainSubstring("not started")))
+
+		Expect(manager.StartAll()).NotTo(Succeed())
+		statuses := manager.Statuses()
+		Expect(statuses["ok"].State).To(Equal(StateRunning))
+		Expect(statuses["broken"].State).To(Equal(StateFailed))
+		Expect(statuses["broken"].Error).To(Equal("no database"))
+		Expect(manager.Ready()).To(MatchError(ContainSubstring("broken (failed)")))
+
+		Expect(manager.StopAll()).To(Succeed())
+		Expect(manager.Statuses()["ok"].State).To(Equal(StateStopped))
+	})
+
+	It("should run the health checks of running plugins", func() {
+		db := &checkedPlugin{MockPlugin: NewMockPlugin("db", "handler"), err: errors.New("ping failed")}
+		PluginFactories["db"] = func() Plugin { return db }
+		Expect(manager.LoadPlugins([]config.PluginConfig{{Name: "db", Enabled: true}})).To(Succeed())
+		Expect(manager.CheckHealth(context.Background())).To(BeEmpty())
+
+		Expect(manager.StartAll()).To(Succeed())
+		Expect(manager.Ready()).To(Succeed())
+		failures := manager.CheckHealth(context.Background())
+		Expect(failures).To(HaveKeyWithValue("db", MatchError("ping failed")))
+	})
+})
+
+type checkedPlugin struct {
+	*MockPlugin
+	err error
+}
+
+func (p *checkedPlugin) HealthCheck(context.Context) error {
+	return p.err
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+var (
+	// ErrPluginNotFound is returned for plugins that are not loaded, including
+	// the handlers replaced by the simulation in dry-run mode
+	ErrPluginNotFound = errors.New("plugin not found")
+	// ErrPluginDisabled is returned when restarting a disabled plugin
+	ErrPluginDisabled = errors.New("plugin is disabled")
+	// ErrInvalidLogLevel is returned for log levels logger.ParseLevel rejects
+	ErrInvalidLogLevel = errors.New("invalid log level")
+)
+
+// PluginInfo describes a loaded plugin
+type PluginInfo struct {
+	Name    string       `json:"name"`
+	Type    string       `json:"type"`
+	Enabled bool         `json:"enabled"`
+	Status  PluginStatus `json:"status"`
+	// LogLevel is the level set for the plugin at runtime or in
+	// logging.plugins, empty when the plugin logs at the global level
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// SetStatePath loads the enablement set changed at runtime from path. Plugins
+// loaded afterwards take their enablement from it instead of the
+// configuration, and Enable and Disable write it back. A missing file is an
+// empty state.
+func (m *Manager) SetStatePath(path string) error {
+	overrides := make(map[string]bool)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read plugin state: %w", err)
+	default:
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			return fmt.Errorf("failed to parse plugin state %s: %w", path, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statePath = path
+	m.overrides = overrides
+	return nil
+}
+
+// Plugins returns the loaded plugins sorted by name
+func (m *Manager) Plugins() []PluginInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := m.Statuses()
+	plugins := make([]PluginInfo, 0, len(m.pluginInstances))
+	for name, instance := range m.pluginInstances {
+		info := PluginInfo{
+			Name:    name,
+			Type:    instance.Plugin.Type(),
+			Enabled: instance.Config.Enabled,
+			Status:  statuses[name],
+		}
+		if level, ok := logger.PluginLevel(name); ok {
+			info.LogLevel = strings.ToLower(level.String())
+		}
+		plugins = append(plugins, info)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Plugin returns the loaded plugin called name
+func (m *Manager) Plugin(name string) (PluginInfo, error) {
+	for _, info := range m.Plugins() {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return PluginInfo{}, fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+}
+
+// Enable enables the plugin name and starts it unless it is already starting
+// or running. It returns once the plugin started or the startup grace period
+// passed.
+func (m *Manager) Enable(name string) error {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+	instance, err := m.instance(name)
+	if err != nil {
+		return err
+	}
+	if err := m.persist(name, true); err != nil {
+		return err
+	}
+	state := m.Statuses()[name].State
+	if instance.Config.Enabled && (state == StateStarting || state == StateRunning) {
+		return nil
+	}
+	logger.GetLogger().Info("Enabling plugin", logger.Fields{"plugin": name})
+	return m.restart(name, instance)
+}
+
+// Disable stops the plugin name and keeps it stopped
+func (m *Manager) Disable(name string) error {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+	instance, err := m.instance(name)
+	if err != nil {
+		return err
+	}
+	if err := m.persist(name, false); err != nil {
+		return err
+	}
+	logger.GetLogger().Info("Disabling plugin", logger.Fields{"plugin": name})
+	m.mu.Lock()
+	instance.Config.Enabled = false
+	m.mu.Unlock()
+	m.stopPlugin(name, instance)
+	return nil
+}
+
+// Restart stops the enabled plugin name and starts a fresh instance of it
+func (m *Manager) Restart(name string) error {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+	instance, err := m.instance(name)
+	if err != nil {
+		return err
+	}
+	if !instance.Config.Enabled {
+		return fmt.Errorf("%w: %s", ErrPluginDisabled, name)
+	}
+	logger.GetLogger().Info("Restarting plugin", logger.Fields{"plugin": name})
+	return m.restart(name, instance)
+}
+
+// SetLogLevel changes the log level of the plugin name without restarting it.
+// An empty level makes the plugin log at the global level again. The change is
+// not persisted.
+func (m *Manager) SetLogLevel(name, level string) error {
+	if _, err := m.instance(name); err != nil {
+		return err
+	}
+	if level == "" {
+		logger.ResetPluginLevel(name)
+		logger.GetLogger().Info("Reset plugin log level", logger.Fields{"plugin": name})
+		return nil
+	}
+	parsed, err := logger.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidLogLevel, level)
+	}
+	logger.SetPluginLevel(name, parsed)
+	logger.GetLogger().Info("Changed plugin log level", logger.Fields{"plugin": name, "level": parsed.String()})
+	return nil
+}
+
+func (m *Manager) instance(name string) (*PluginInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instance, ok := m.pluginInstances[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	return instance, nil
+}
+
+// restart stops the running instance of name, if any, and starts a fresh one
+// from the plugin factory, since plugins are not required to support being
+// started again after Stop
+func (m *Manager) restart(name string, current *PluginInstance) error {
+	m.stopPlugin(name, current)
+
+	config := current.Config
+	config.Enabled = true
+	fresh := &PluginInstance{
+		Plugin:   current.factory(),
+		Config:   config,
+		eventBus: current.eventBus,
+		factory:  current.factory,
+	}
+	m.mu.Lock()
+	m.pluginInstances[name] = fresh
+	m.mu.Unlock()
+
+	select {
+	case err := <-m.startPlugin(name, fresh):
+		return err
+	case <-time.After(StartupGracePeriod):
+		return nil
+	}
+}
+
+// stopPlugin stops a started instance; stop errors are logged like in StopAll
+func (m *Manager) stopPlugin(name string, instance *PluginInstance) {
+	state, started := m.Statuses()[name]
+	if !started || state.State == StateStopped {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), PluginStopTimeout)
+	defer cancel()
+	log := logger.GetLogger()
+	log.Info("Stopping plugin", logger.Fields{"plugin": name})
+	if err := instance.Plugin.Stop(ctx); err != nil {
+		log.Error("Error stopping plugin", logger.Fields{"plugin": name, "error": err.Error()})
+	}
+	m.transition(name, "", StateStopped)
+}
+
+// persist records the enablement of name in the state file. Without a state
+// path the change only lasts until the next restart of the process.
+func (m *Manager) persist(name string, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, had := m.overrides[name]
+	m.overrides[name] = enabled
+	if m.statePath == "" {
+		return nil
+	}
+	if err := writeState(m.statePath, m.overrides); err != nil {
+		if had {
+			m.overrides[name] = previous
+		} else {
+			delete(m.overrides, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// writeState replaces the state file atomically
+func writeState(path string, overrides map[string]bool) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create plugin state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".plugin-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write plugin state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write plugin state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write plugin state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write plugin state: %w", err)
+	}
+	return nil
+}
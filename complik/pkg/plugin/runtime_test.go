@@ -0,0 +1,237 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// mockFactory records every instance it creates
+type mockFactory struct {
+	mu        sync.Mutex
+	name      string
+	instances []*MockPlugin
+}
+
+func (f *mockFactory) create() Plugin {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	instance := NewMockPlugin(f.name, "discovery")
+	f.instances = append(f.instances, instance)
+	return instance
+}
+
+func (f *mockFactory) latest() *MockPlugin {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.instances[len(f.instances)-1]
+}
+
+var _ = Describe("PluginManager runtime changes", func() {
+	var (
+		manager      *Manager
+		statePath    string
+		alpha, beta  *mockFactory
+		oldFactories map[string]func() Plugin
+	)
+
+	load := func() {
+		manager = NewManager(eventbus.NewEventBus(10))
+		Expect(manager.SetStatePath(statePath)).To(Succeed())
+		Expect(manager.LoadPlugins([]config.PluginConfig{
+			{Name: "alpha", Enabled: true},
+			{Name: "beta", Enabled: false},
+		})).To(Succeed())
+		Expect(manager.StartAll()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		oldFactories = PluginFactories
+		alpha = &mockFactory{name: "alpha"}
+		beta = &mockFactory{name: "beta"}
+		PluginFactories = map[string]func() Plugin{"alpha": alpha.create, "beta": beta.create}
+		statePath = filepath.Join(GinkgoT().TempDir(), "state", "plugins.json")
+		load()
+	})
+
+	AfterEach(func() {
+		PluginFactories = oldFactories
+	})
+
+	It("should stop disabled plugins and keep them stopped", func() {
+		started := alpha.latest()
+		Expect(manager.Disable("alpha")).To(Succeed())
+		Expect(started.IsStopped()).To(BeTrue())
+
+		info, err := manager.Plugin("alpha")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Enabled).To(BeFalse())
+		Expect(info.Status.State).To(Equal(StateStopped))
+		Expect(manager.Ready()).To(Succeed())
+		Expect(manager.Restart("alpha")).To(MatchError(ErrPluginDisabled))
+	})
+
+	It("should start a fresh instance when a plugin is enabled or restarted", func() {
+		Expect(manager.Enable("beta")).To(Succeed())
+		Expect(beta.instances).To(HaveLen(2))
+		Expect(beta.instances[0].IsStarted()).To(BeFalse())
+		Expect(beta.latest().IsStarted()).To(BeTrue())
+		Expect(manager.Ready()).To(Succeed())
+
+		first := alpha.latest()
+		Expect(manager.Restart("alpha")).To(Succeed())
+		Expect(first.IsStopped()).To(BeTrue())
+		Expect(alpha.latest()).NotTo(BeIdenticalTo(first))
+		Expect(alpha.latest().IsStarted()).To(BeTrue())
+		Expect(manager.Statuses()["alpha"].State).To(Equal(StateRunning))
+
+		Expect(manager.StopAll()).To(Succeed())
+		Expect(alpha.latest().IsStopped()).To(BeTrue())
+	})
+
+	It("should not restart plugins that are already running when enabled", func() {
+		Expect(manager.Enable("alpha")).To(Succeed())
+		Expect(alpha.instances).To(HaveLen(1))
+	})
+
+	It("should keep the enablement set across restarts", func() {
+		Expect(manager.Disable("alpha")).To(Succeed())
+		Expect(manager.Enable("beta")).To(Succeed())
+		Expect(manager.StopAll()).To(Succeed())
+
+		load()
+		plugins := manager.Plugins()
+		Expect(plugins).To(HaveLen(2))
+		Expect(plugins[0].Name).To(Equal("alpha"))
+		Expect(plugins[0].Enabled).To(BeFalse())
+		Expect(plugins[1].Enabled).To(BeTrue())
+		Expect(plugins[1].Status.State).To(Equal(StateRunning))
+	})
+
+	It("should reject unknown plugins", func() {
+		Expect(manager.Enable("gamma")).To(MatchError(ErrPluginNotFound))
+		_, err := manager.Plugin("gamma")
+		Expect(err).To(MatchError(ErrPluginNotFound))
+	})
+
+	It("should leave the enablement unchanged when the state cannot be written", func() {
+		Expect(os.RemoveAll(filepath.Dir(statePath))).To(Succeed())
+		Expect(os.WriteFile(filepath.Dir(statePath), nil, 0o644)).To(Succeed())
+		Expect(manager.Disable("alpha")).To(MatchError(ContainSubstring("plugin state")))
+		info, err := manager.Plugin("alpha")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Enabled).To(BeTrue())
+		Expect(info.Status.State).To(Equal(StateRunning))
+	})
+
+	Describe("API", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(NewAPI(logger.GetLogger(), "secret", manager))
+			DeferCleanup(server.Close)
+		})
+
+		post := func(path, token string) (*http.Response, map[string]any) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			return resp, body
+		}
+
+		It("should change plugins and report their state", func() {
+			resp, body := post("/api/v1/plugins/beta/enable", "secret")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body).To(HaveKeyWithValue("enabled", true))
+			Expect(body["status"]).To(HaveKeyWithValue("state", StateRunning))
+
+			resp, body = post("/api/v1/plugins/alpha/disable", "secret")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["status"]).To(HaveKeyWithValue("state", StateStopped))
+
+			resp, _ = post("/api/v1/plugins/alpha/restart", "secret")
+			Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+			resp, _ = post("/api/v1/plugins/gamma/enable", "secret")
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should change the log level of plugins", func() {
+			DeferCleanup(logger.ResetPluginLevel, "alpha")
+			put := func(path, body string) (*http.Response, map[string]any) {
+				req, err := http.NewRequest(http.MethodPut, server.URL+path, strings.NewReader(body))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Authorization", "Bearer secret")
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				var decoded map[string]any
+				Expect(json.NewDecoder(resp.Body).Decode(&decoded)).To(Succeed())
+				return resp, decoded
+			}
+
+			resp, body := put("/api/v1/plugins/alpha/log-level", `{"level":"DEBUG"}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body).To(HaveKeyWithValue("logLevel", "debug"))
+			level, ok := logger.PluginLevel("alpha")
+			Expect(ok).To(BeTrue())
+			Expect(level).To(Equal(logger.DebugLevel))
+
+			resp, body = put("/api/v1/plugins/alpha/log-level", `{"level":""}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body).NotTo(HaveKey("logLevel"))
+
+			resp, _ = put("/api/v1/plugins/alpha/log-level", `{"level":"loud"}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			resp, _ = put("/api/v1/plugins/gamma/log-level", `{"level":"debug"}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should require the token", func() {
+			resp, _ := post("/api/v1/plugins/alpha/disable", "wrong")
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(alpha.latest().IsStopped()).To(BeFalse())
+		})
+
+		It("should refuse every request without a configured token", func() {
+			unauthenticated := httptest.NewServer(NewAPI(logger.GetLogger(), "", manager))
+			DeferCleanup(unauthenticated.Close)
+			req, err := http.NewRequest(http.MethodPost, unauthenticated.URL+"/api/v1/plugins/alpha/disable", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer ")
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(alpha.latest().IsStopped()).To(BeFalse())
+		})
+	})
+})
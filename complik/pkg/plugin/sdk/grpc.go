@@ -0,0 +1,176 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin/sdk/proto"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// GRPCPlugin carries Plugin over gRPC. The plugin process serves Impl and
+// CompliK gets a *Client; events published by the plugin travel back over a
+// Host service CompliK serves on the plugin broker.
+type GRPCPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	Impl Plugin
+}
+
+func (p *GRPCPlugin) GRPCServer(broker *goplugin.GRPCBroker, s *grpc.Server) error {
+	proto.RegisterPluginServer(s, &grpcServer{impl: p.Impl, broker: broker})
+	return nil
+}
+
+func (p *GRPCPlugin) GRPCClient(_ context.Context, broker *goplugin.GRPCBroker, conn *grpc.ClientConn) (any, error) {
+	return &Client{client: proto.NewPluginClient(conn), broker: broker}, nil
+}
+
+// grpcServer runs in the plugin process
+type grpcServer struct {
+	proto.UnimplementedPluginServer
+	impl   Plugin
+	broker *goplugin.GRPCBroker
+
+	mu   sync.Mutex
+	host *grpc.ClientConn
+}
+
+func (s *grpcServer) Describe(ctx context.Context, _ *proto.DescribeRequest) (*proto.DescribeResponse, error) {
+	info, err := s.impl.Describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.DescribeResponse{Name: info.Name, Type: info.Type, Subscribe: info.Subscribe}, nil
+}
+
+func (s *grpcServer) Start(ctx context.Context, req *proto.StartRequest) (*proto.StartResponse, error) {
+	conn, err := s.broker.Dial(req.HostBrokerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to host: %w", err)
+	}
+	s.mu.Lock()
+	s.host = conn
+	s.mu.Unlock()
+	cfg := Config{Settings: req.Settings, DryRun: req.DryRun}
+	if err := s.impl.Start(ctx, cfg, &hostClient{client: proto.NewHostClient(conn)}); err != nil {
+		return nil, err
+	}
+	return &proto.StartResponse{}, nil
+}
+
+func (s *grpcServer) Handle(ctx context.Context, event *proto.Event) (*proto.HandleResponse, error) {
+	if err := s.impl.Handle(ctx, Event{Topic: event.Topic, Payload: event.Payload}); err != nil {
+		return nil, err
+	}
+	return &proto.HandleResponse{}, nil
+}
+
+func (s *grpcServer) Stop(ctx context.Context, _ *proto.StopRequest) (*proto.StopResponse, error) {
+	err := s.impl.Stop(ctx)
+	s.mu.Lock()
+	if s.host != nil {
+		_ = s.host.Close()
+		s.host = nil
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &proto.StopResponse{}, nil
+}
+
+// hostClient publishes events of the plugin process to CompliK
+type hostClient struct {
+	client proto.HostClient
+}
+
+func (c *hostClient) Publish(ctx context.Context, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	_, err = c.client.Publish(ctx, &proto.Event{Topic: topic, Payload: data})
+	return err
+}
+
+// Client is the Plugin CompliK gets for an external plugin process
+type Client struct {
+	client proto.PluginClient
+	broker *goplugin.GRPCBroker
+
+	mu   sync.Mutex
+	host *grpc.Server
+}
+
+func (c *Client) Describe(ctx context.Context) (Info, error) {
+	resp, err := c.client.Describe(ctx, &proto.DescribeRequest{})
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Name: resp.Name, Type: resp.Type, Subscribe: resp.Subscribe}, nil
+}
+
+// Start serves publisher to the plugin on the broker before starting it
+func (c *Client) Start(ctx context.Context, cfg Config, publisher Publisher) error {
+	id := c.broker.NextId()
+	go c.broker.AcceptAndServe(id, func(opts []grpc.ServerOption) *grpc.Server {
+		server := grpc.NewServer(opts...)
+		proto.RegisterHostServer(server, &hostServer{publisher: publisher})
+		c.mu.Lock()
+		c.host = server
+		c.mu.Unlock()
+		return server
+	})
+	_, err := c.client.Start(ctx, &proto.StartRequest{
+		Settings:     cfg.Settings,
+		DryRun:       cfg.DryRun,
+		HostBrokerId: id,
+	})
+	return err
+}
+
+func (c *Client) Handle(ctx context.Context, event Event) error {
+	_, err := c.client.Handle(ctx, &proto.Event{Topic: event.Topic, Payload: event.Payload})
+	return err
+}
+
+func (c *Client) Stop(ctx context.Context) error {
+	_, err := c.client.Stop(ctx, &proto.StopRequest{})
+	c.mu.Lock()
+	if c.host != nil {
+		c.host.Stop()
+		c.host = nil
+	}
+	c.mu.Unlock()
+	return err
+}
+
+// hostServer runs in CompliK and receives the events of the plugin
+type hostServer struct {
+	proto.UnimplementedHostServer
+	publisher Publisher
+}
+
+func (s *hostServer) Publish(ctx context.Context, event *proto.Event) (*proto.PublishResponse, error) {
+	if err := s.publisher.Publish(ctx, event.Topic, json.RawMessage(event.Payload)); err != nil {
+		return nil, err
+	}
+	return &proto.PublishResponse{}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdk is the protocol between CompliK and external plugins. An
+// external plugin is a separate executable that implements Plugin and calls
+// Serve from its main function; CompliK launches it from the plugin directory
+// and talks to it over gRPC.
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// PluginName is the name the plugin is dispensed under in the plugin set
+const PluginName = "complik"
+
+// Handshake is shared by CompliK and its external plugins. A plugin started
+// outside of CompliK exits with an explanation instead of serving. Bump the
+// protocol version on incompatible changes of the protocol.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "COMPLIK_PLUGIN",
+	MagicCookieValue: "7c1f0e2a-complik-external-plugin",
+}
+
+// Info identifies an external plugin. Name is the plugin name referenced by
+// the plugins section of the configuration, Type is a CompliK plugin type such
+// as Compliance.Detector, and Subscribe lists the topics delivered to Handle.
+type Info struct {
+	Name      string
+	Type      string
+	Subscribe []string
+}
+
+// Config is the plugin configuration passed to Start
+type Config struct {
+	Settings string
+	// DryRun is set when CompliK runs in simulation mode; the plugin must not
+	// cause side effects outside CompliK
+	DryRun bool
+}
+
+// Event is an event bus message. Payload is the JSON encoding of the model of
+// the topic, for example models.DetectorInfo on the detector topic.
+type Event struct {
+	Topic   string
+	Payload json.RawMessage
+}
+
+// Publisher puts events on the CompliK event bus. Payloads are encoded as JSON
+// and decoded into the model registered for the topic.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, payload any) error
+}
+
+// Plugin is implemented by external plugins
+type Plugin interface {
+	Describe(ctx context.Context) (Info, error)
+	// Start must return once the plugin is started; ctx is only valid for the
+	// duration of the call. The publisher stays usable until Stop.
+	Start(ctx context.Context, cfg Config, publisher Publisher) error
+	// Handle is called for every event on the subscribed topics
+	Handle(ctx context.Context, event Event) error
+	Stop(ctx context.Context) error
+}
+
+// PluginSet is the set of plugins served by an external plugin process
+func PluginSet(impl Plugin) goplugin.PluginSet {
+	return goplugin.PluginSet{PluginName: &GRPCPlugin{Impl: impl}}
+}
+
+// Serve serves impl to CompliK and blocks until CompliK stops the plugin
+func Serve(impl Plugin) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         PluginSet(impl),
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
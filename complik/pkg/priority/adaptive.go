@@ -0,0 +1,226 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priority
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// namespaceState is what the adaptive strategy remembers about a namespace
+type namespaceState struct {
+	lastScanned   time.Time
+	lastViolation time.Time
+	// score is the violation count, decayed with the configured half-life
+	score       float64
+	scoredAt    time.Time
+	cleanStreak int
+	fingerprint string
+}
+
+// Adaptive treats every namespace as an arm whose reward is a violation. A
+// namespace is due once the time since its last scan reaches its interval:
+// the base interval shortened by its decayed violation score and lengthened
+// by the number of consecutive clean scans. Namespaces that are new or whose
+// endpoints changed since the last scan are always due.
+type Adaptive struct {
+	baseInterval time.Duration
+	minInterval  time.Duration
+	maxInterval  time.Duration
+	boost        float64
+	halfLife     time.Duration
+	cleanDecay   float64
+	maxPerRun    int
+
+	mu         sync.Mutex
+	namespaces map[string]*namespaceState
+}
+
+// NewAdaptive builds the adaptive strategy, filling unset values of cfg with
+// defaults derived from baseInterval
+func NewAdaptive(cfg Config, baseInterval time.Duration) *Adaptive {
+	if baseInterval <= 0 {
+		baseInterval = 24 * time.Hour
+	}
+	a := &Adaptive{
+		baseInterval: baseInterval,
+		minInterval:  time.Duration(cfg.MinIntervalMinute) * time.Minute,
+		maxInterval:  time.Duration(cfg.MaxIntervalMinute) * time.Minute,
+		boost:        cfg.ViolationBoost,
+		halfLife:     time.Duration(cfg.ViolationHalfLifeHour) * time.Hour,
+		cleanDecay:   cfg.CleanDecay,
+		maxPerRun:    cfg.MaxNamespacesPerRun,
+		namespaces:   make(map[string]*namespaceState),
+	}
+	if a.minInterval <= 0 {
+		a.minInterval = baseInterval / 24
+	}
+	if a.maxInterval <= 0 {
+		a.maxInterval = 4 * baseInterval
+	}
+	if a.boost <= 0 {
+		a.boost = 2
+	}
+	if a.halfLife <= 0 {
+		a.halfLife = 7 * 24 * time.Hour
+	}
+	if a.cleanDecay <= 0 {
+		a.cleanDecay = 0.5
+	}
+	return a
+}
+
+func (a *Adaptive) Name() string { return StrategyAdaptive }
+
+// Select returns the items of the namespaces that are due, most overdue first,
+// and marks them as scanned at now
+func (a *Adaptive) Select(items []models.DiscoveryInfo, now time.Time) []models.DiscoveryInfo {
+	byNamespace := make(map[string][]models.DiscoveryInfo)
+	for _, item := range items {
+		byNamespace[item.Namespace] = append(byNamespace[item.Namespace], item)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	type candidate struct {
+		namespace string
+		priority  float64
+	}
+	var due []candidate
+	for namespace, nsItems := range byNamespace {
+		state := a.namespaces[namespace]
+		fingerprint := endpointFingerprint(nsItems)
+		if state == nil {
+			state = &namespaceState{}
+			a.namespaces[namespace] = state
+		}
+		priority := a.priority(state, fingerprint, now)
+		state.fingerprint = fingerprint
+		if priority >= 1 {
+			due = append(due, candidate{namespace: namespace, priority: priority})
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].priority != due[j].priority {
+			return due[i].priority > due[j].priority
+		}
+		return due[i].namespace < due[j].namespace
+	})
+	if a.maxPerRun > 0 && len(due) > a.maxPerRun {
+		due = due[:a.maxPerRun]
+	}
+
+	var selected []models.DiscoveryInfo
+	for _, c := range due {
+		state := a.namespaces[c.namespace]
+		if !state.lastScanned.IsZero() {
+			if state.lastViolation.Before(state.lastScanned) {
+				state.cleanStreak++
+			} else {
+				state.cleanStreak = 0
+			}
+		}
+		state.lastScanned = now
+		selected = append(selected, byNamespace[c.namespace]...)
+	}
+	return selected
+}
+
+// Observe updates the violation score of the namespace of result
+func (a *Adaptive) Observe(result *models.DetectorInfo, now time.Time) {
+	if result == nil || !result.IsIllegal || result.Namespace == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := a.namespaces[result.Namespace]
+	if state == nil {
+		state = &namespaceState{}
+		a.namespaces[result.Namespace] = state
+	}
+	state.score = a.decayedScore(state, now) + 1
+	state.scoredAt = now
+	state.lastViolation = now
+	state.cleanStreak = 0
+}
+
+// Interval returns the current scan interval of namespace
+func (a *Adaptive) Interval(namespace string, now time.Time) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := a.namespaces[namespace]
+	if state == nil {
+		return a.baseInterval
+	}
+	return a.interval(state, now)
+}
+
+// priority is the fraction of its interval a namespace has waited; values of
+// one or more mean the namespace is due
+func (a *Adaptive) priority(state *namespaceState, fingerprint string, now time.Time) float64 {
+	if state.lastScanned.IsZero() {
+		return math.Inf(1)
+	}
+	waited := float64(now.Sub(state.lastScanned))
+	interval := float64(a.interval(state, now))
+	if state.fingerprint != fingerprint {
+		// Changed endpoints run ahead of everything except new namespaces
+		return math.Max(1, waited/interval) + 1
+	}
+	return waited / interval
+}
+
+func (a *Adaptive) interval(state *namespaceState, now time.Time) time.Duration {
+	factor := (1 + a.cleanDecay*float64(state.cleanStreak)) / (1 + a.boost*a.decayedScore(state, now))
+	interval := time.Duration(float64(a.baseInterval) * factor)
+	if interval < a.minInterval {
+		return a.minInterval
+	}
+	if interval > a.maxInterval {
+		return a.maxInterval
+	}
+	return interval
+}
+
+func (a *Adaptive) decayedScore(state *namespaceState, now time.Time) float64 {
+	if state.score == 0 {
+		return 0
+	}
+	elapsed := now.Sub(state.scoredAt)
+	if elapsed <= 0 {
+		return state.score
+	}
+	return state.score * math.Pow(0.5, float64(elapsed)/float64(a.halfLife))
+}
+
+// endpointFingerprint hashes the hosts, paths and services of a namespace so
+// changed endpoints can be detected between runs
+func endpointFingerprint(items []models.DiscoveryInfo) string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Host+"|"+strings.Join(item.Path, ",")+"|"+item.ServiceName)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
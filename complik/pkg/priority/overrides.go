@@ -0,0 +1,70 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priority
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// intervalSlack lets a target due on the next tick be selected although the
+// tick fires slightly early
+const intervalSlack = time.Minute
+
+// WithTargetIntervals returns strategy selecting the targets that override
+// the scan interval whenever their own interval elapsed, the other targets
+// are left to strategy. Targets are selected on the ticks of the plugin, so
+// intervals shorter than the tick are rounded up to it.
+func WithTargetIntervals(strategy Strategy) Strategy {
+	return &targetIntervals{Strategy: strategy, lastScanned: make(map[string]time.Time)}
+}
+
+type targetIntervals struct {
+	Strategy
+
+	mu          sync.Mutex
+	lastScanned map[string]time.Time
+}
+
+func (t *targetIntervals) Select(items []models.DiscoveryInfo, now time.Time) []models.DiscoveryInfo {
+	var rest, due []models.DiscoveryInfo
+	discovered := make(map[string]bool)
+
+	t.mu.Lock()
+	for _, item := range items {
+		if item.Overrides == nil || item.Overrides.IntervalMinute <= 0 {
+			rest = append(rest, item)
+			continue
+		}
+		key := models.ScanTarget(item.Namespace, item.Name, item.Host, item.Path)
+		discovered[key] = true
+		interval := time.Duration(item.Overrides.IntervalMinute) * time.Minute
+		if last, ok := t.lastScanned[key]; ok && now.Sub(last)+intervalSlack < interval {
+			continue
+		}
+		t.lastScanned[key] = now
+		due = append(due, item)
+	}
+	for key := range t.lastScanned {
+		if !discovered[key] {
+			delete(t.lastScanned, key)
+		}
+	}
+	t.mu.Unlock()
+
+	return append(t.Strategy.Select(rest, now), due...)
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package priority decides which discovered namespaces a cronjob discovery
+// plugin scans on each run. Strategies are pluggable; the adaptive strategy
+// scans namespaces with past violations or changed endpoints more often and
+// backs off on namespaces that stay clean.
+package priority
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Built-in strategies
+const (
+	StrategyAll      = "all"
+	StrategyAdaptive = "adaptive"
+)
+
+// Config is the prioritization section of a cronjob discovery plugin configuration
+type Config struct {
+	Strategy string `json:"strategy"`
+	// TickMinute is how often the plugin asks the strategy for due namespaces;
+	// the plugin interval becomes the base scan interval of a namespace
+	TickMinute            int     `json:"tickMinute"`
+	MinIntervalMinute     int     `json:"minIntervalMinute"`
+	MaxIntervalMinute     int     `json:"maxIntervalMinute"`
+	ViolationBoost        float64 `json:"violationBoost"`
+	ViolationHalfLifeHour int     `json:"violationHalfLifeHour"`
+	CleanDecay            float64 `json:"cleanDecay"`
+	MaxNamespacesPerRun   int     `json:"maxNamespacesPerRun"`
+}
+
+// Strategy selects the discovery results to publish on a run and learns from
+// the detection results of earlier runs. Implementations must be safe for
+// concurrent use.
+type Strategy interface {
+	Name() string
+	Select(items []models.DiscoveryInfo, now time.Time) []models.DiscoveryInfo
+	Observe(result *models.DetectorInfo, now time.Time)
+}
+
+// Factory builds a strategy from cfg. baseInterval is the interval configured
+// on the discovery plugin.
+type Factory func(cfg Config, baseInterval time.Duration) Strategy
+
+// Strategies holds the registered strategy factories by name
+var Strategies = map[string]Factory{
+	StrategyAll: func(Config, time.Duration) Strategy {
+		return allStrategy{}
+	},
+	StrategyAdaptive: func(cfg Config, baseInterval time.Duration) Strategy {
+		return NewAdaptive(cfg, baseInterval)
+	},
+}
+
+// New builds the strategy selected by cfg. A nil cfg or an empty strategy name
+// selects StrategyAll, which keeps the scan-everything behaviour.
+// Targets overriding the scan interval are selected on it by every strategy,
+// see WithTargetIntervals.
+func New(cfg *Config, baseInterval time.Duration) (Strategy, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	name := cfg.Strategy
+	if name == "" {
+		name = StrategyAll
+	}
+	factory, ok := Strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prioritization strategy %q", name)
+	}
+	return WithTargetIntervals(factory(*cfg, baseInterval)), nil
+}
+
+// TickInterval returns how often a plugin using cfg should run. Strategies that
+// prioritize run on TickMinute so boosted namespaces are picked up between
+// full intervals.
+func TickInterval(cfg *Config, baseInterval time.Duration) time.Duration {
+	if cfg == nil || cfg.Strategy == "" || cfg.Strategy == StrategyAll || cfg.TickMinute <= 0 {
+		return baseInterval
+	}
+	tick := time.Duration(cfg.TickMinute) * time.Minute
+	if tick > baseInterval {
+		return baseInterval
+	}
+	return tick
+}
+
+// Watch feeds the detection results published on eventBus to strategy until
+// ctx is cancelled
+func Watch(ctx context.Context, eventBus *eventbus.EventBus, strategy Strategy) {
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	go func() {
+		defer eventBus.Unsubscribe(constants.DetectorTopic, subscribe)
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					return
+				}
+				if result, ok := event.Payload.(*models.DetectorInfo); ok {
+					strategy.Observe(result, time.Now())
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// allStrategy scans every discovered namespace on every run
+type allStrategy struct{}
+
+func (allStrategy) Name() string { return StrategyAll }
+
+func (allStrategy) Select(items []models.DiscoveryInfo, _ time.Time) []models.DiscoveryInfo {
+	return items
+}
+
+func (allStrategy) Observe(*models.DetectorInfo, time.Time) {}
@@ -0,0 +1,179 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priority
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPriority(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Priority Suite")
+}
+
+func namespacesOf(items []models.DiscoveryInfo) []string {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if !seen[item.Namespace] {
+			seen[item.Namespace] = true
+			namespaces = append(namespaces, item.Namespace)
+		}
+	}
+	return namespaces
+}
+
+var _ = Describe("Priority", func() {
+	base := 24 * time.Hour
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	items := []models.DiscoveryInfo{
+		{Namespace: "ns-bad", Host: "bad.example.com", Path: []string{"/"}},
+		{Namespace: "ns-clean", Host: "clean.example.com", Path: []string{"/"}},
+	}
+
+	Describe("New", func() {
+		It("should default to scanning everything", func() {
+			strategy, err := New(nil, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strategy.Name()).To(Equal(StrategyAll))
+			Expect(strategy.Select(items, start)).To(Equal(items))
+			Expect(TickInterval(nil, base)).To(Equal(base))
+		})
+
+		It("should reject unknown strategies", func() {
+			_, err := New(&Config{Strategy: "random"}, base)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should tick faster than the base interval for adaptive", func() {
+			cfg := &Config{Strategy: StrategyAdaptive, TickMinute: 60}
+			Expect(TickInterval(cfg, base)).To(Equal(time.Hour))
+			cfg.TickMinute = 48 * 60
+			Expect(TickInterval(cfg, base)).To(Equal(base))
+		})
+	})
+
+	Describe("Adaptive", func() {
+		var adaptive *Adaptive
+
+		BeforeEach(func() {
+			adaptive = NewAdaptive(Config{}, base)
+		})
+
+		It("should scan new namespaces immediately", func() {
+			Expect(namespacesOf(adaptive.Select(items, start))).
+				To(ConsistOf("ns-bad", "ns-clean"))
+			Expect(adaptive.Select(items, start.Add(time.Hour))).To(BeEmpty())
+		})
+
+		It("should boost namespaces with violations", func() {
+			adaptive.Select(items, start)
+			adaptive.Observe(&models.DetectorInfo{Namespace: "ns-bad", IsIllegal: true}, start)
+			Expect(adaptive.Interval("ns-bad", start)).To(Equal(8 * time.Hour))
+
+			selected := adaptive.Select(items, start.Add(9*time.Hour))
+			Expect(namespacesOf(selected)).To(Equal([]string{"ns-bad"}))
+		})
+
+		It("should decay the violation boost over time", func() {
+			adaptive.Select(items, start)
+			adaptive.Observe(&models.DetectorInfo{Namespace: "ns-bad", IsIllegal: true}, start)
+			early := adaptive.Interval("ns-bad", start)
+			late := adaptive.Interval("ns-bad", start.Add(28*24*time.Hour))
+			Expect(late).To(BeNumerically(">", early))
+			Expect(late).To(BeNumerically("<", base))
+		})
+
+		It("should back off on namespaces that stay clean", func() {
+			now := start
+			for range 3 {
+				adaptive.Select(items, now)
+				now = now.Add(adaptive.Interval("ns-clean", now))
+			}
+			// The last scan happened one interval (2 * base) before now
+			Expect(adaptive.Interval("ns-clean", now)).To(Equal(2 * base))
+			Expect(adaptive.Select(items, now.Add(-base))).To(BeEmpty())
+			Expect(adaptive.Select(items, now)).To(HaveLen(2))
+		})
+
+		It("should scan namespaces whose endpoints changed", func() {
+			adaptive.Select(items, start)
+			changed := []models.DiscoveryInfo{
+				items[0],
+				{Namespace: "ns-clean", Host: "new.example.com", Path: []string{"/"}},
+			}
+			Expect(namespacesOf(adaptive.Select(changed, start.Add(time.Hour)))).
+				To(Equal([]string{"ns-clean"}))
+		})
+
+		It("should limit the namespaces per run to the most overdue", func() {
+			adaptive = NewAdaptive(Config{MaxNamespacesPerRun: 1}, base)
+			Expect(adaptive.Select(items, start)).To(HaveLen(1))
+			Expect(namespacesOf(adaptive.Select(items, start))).To(HaveLen(1))
+		})
+	})
+
+	Describe("Watch", func() {
+		It("should feed detection results to the strategy", func() {
+			eventBus := eventbus.NewEventBus(10)
+			adaptive := NewAdaptive(Config{}, base)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Watch(ctx, eventBus, adaptive)
+
+			eventBus.Publish(constants.DetectorTopic, eventbus.Event{
+				Payload: &models.DetectorInfo{Namespace: "ns-bad", IsIllegal: true},
+			})
+			Eventually(func() time.Duration {
+				return adaptive.Interval("ns-bad", time.Now())
+			}).Should(BeNumerically("<", base))
+		})
+	})
+})
+
+var _ = Describe("WithTargetIntervals", func() {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	daily := models.DiscoveryInfo{
+		Namespace: "ns-a", Name: "daily", Host: "daily.example.com", Path: []string{"/"},
+		Overrides: &models.ScanOverrides{IntervalMinute: 24 * 60},
+	}
+	plain := models.DiscoveryInfo{Namespace: "ns-a", Name: "web", Host: "web.example.com", Path: []string{"/"}}
+
+	It("should scan targets on their own interval", func() {
+		strategy, err := New(nil, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		items := []models.DiscoveryInfo{daily, plain}
+
+		Expect(strategy.Select(items, start)).To(ConsistOf(daily, plain))
+		Expect(strategy.Select(items, start.Add(time.Hour))).To(ConsistOf(plain))
+		// A tick firing a little early still scans the target
+		Expect(strategy.Select(items, start.Add(24*time.Hour-time.Second))).To(ConsistOf(daily, plain))
+	})
+
+	It("should forget targets no longer discovered", func() {
+		strategy := WithTargetIntervals(allStrategy{})
+		Expect(strategy.Select([]models.DiscoveryInfo{daily}, start)).To(HaveLen(1))
+		Expect(strategy.Select([]models.DiscoveryInfo{plain}, start.Add(time.Hour))).To(ConsistOf(plain))
+		Expect(strategy.Select([]models.DiscoveryInfo{daily}, start.Add(2*time.Hour))).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,148 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact strips obvious personal data from the evidence the handlers
+// persist: email addresses, phone numbers and identity numbers found in page
+// text, descriptions and explanations. Matches are masked, or replaced with a
+// salted hash so equal values can still be correlated without storing them.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Modes of a Redactor
+const (
+	ModeMask = "mask"
+	ModeHash = "hash"
+)
+
+// Kinds of redacted values
+const (
+	KindEmail    = "email"
+	KindIDNumber = "id_number"
+	KindPhone    = "phone"
+)
+
+// patterns are applied in order; identity numbers run before phone numbers,
+// whose digits they contain
+var patterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{KindEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+	// Mainland resident identity numbers and US social security numbers
+	{KindIDNumber, regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b|\b\d{3}-\d{2}-\d{4}\b`)},
+	// Mainland mobile numbers and international numbers with a country code
+	{KindPhone, regexp.MustCompile(`(?:\+86[- ]?)?\b1[3-9]\d{9}\b|\+\d{1,3}[ -]?(?:\(\d{1,4}\)[ -]?)?\d{2,4}(?:[ -]?\d{2,4}){1,3}\b`)},
+}
+
+// Counts is the number of redacted values by kind
+type Counts map[string]int
+
+// Total returns the number of redacted values of all kinds
+func (c Counts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// String lists the counts sorted by kind, e.g. "email=2 phone=1"
+func (c Counts) String() string {
+	kinds := make([]string, 0, len(c))
+	for kind := range c {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s=%d", kind, c[kind]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Redactor replaces personal data in text. A nil Redactor leaves text
+// unchanged, so handlers can call it whether redaction is enabled or not.
+type Redactor struct {
+	mode string
+	salt []byte
+}
+
+// New returns a Redactor for mode, ModeMask when empty. salt keys the hashes
+// of ModeHash; without it equal values hash alike across deployments.
+func New(mode, salt string) (*Redactor, error) {
+	switch mode {
+	case "":
+		mode = ModeMask
+	case ModeMask, ModeHash:
+	default:
+		return nil, fmt.Errorf("unknown redaction mode %q, expected %s or %s", mode, ModeMask, ModeHash)
+	}
+	return &Redactor{mode: mode, salt: []byte(salt)}, nil
+}
+
+// Redact returns text with every match replaced and adds the matches to counts
+func (r *Redactor) Redact(text string, counts Counts) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, pattern := range patterns {
+		text = pattern.re.ReplaceAllStringFunc(text, func(match string) string {
+			counts[pattern.kind]++
+			return r.replacement(pattern.kind, match)
+		})
+	}
+	return text
+}
+
+// Detection returns a copy of info with the free text fields redacted and the
+// counts of the values removed. info itself is shared with the other
+// handlers and is never modified.
+func (r *Redactor) Detection(info *models.DetectorInfo) (*models.DetectorInfo, Counts) {
+	counts := Counts{}
+	if r == nil || info == nil {
+		return info, counts
+	}
+	redacted := *info
+	redacted.Description = r.Redact(info.Description, counts)
+	redacted.Explanation = r.Redact(info.Explanation, counts)
+	if info.Metadata != nil {
+		metadata := *info.Metadata
+		metadata.Title = r.Redact(metadata.Title, counts)
+		metadata.Description = r.Redact(metadata.Description, counts)
+		metadata.RobotsTxt = r.Redact(metadata.RobotsTxt, counts)
+		metadata.SecurityTxt = r.Redact(metadata.SecurityTxt, counts)
+		redacted.Metadata = &metadata
+	}
+	return &redacted, counts
+}
+
+func (r *Redactor) replacement(kind, match string) string {
+	if r.mode == ModeMask {
+		return "[" + kind + "]"
+	}
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(strings.ToLower(match)))
+	return "[" + kind + ":" + hex.EncodeToString(mac.Sum(nil))[:12] + "]"
+}
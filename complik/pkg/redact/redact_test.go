@@ -0,0 +1,96 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRedact(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Redact Suite")
+}
+
+var _ = Describe("Redactor", func() {
+	mask, _ := New("", "")
+
+	DescribeTable("should mask personal data",
+		func(text, redacted, kind string) {
+			counts := Counts{}
+			Expect(mask.Redact(text, counts)).To(Equal(redacted))
+			Expect(counts).To(Equal(Counts{kind: 1}))
+		},
+		Entry("email", "contact alice.w+shop@mail.example.co.uk now", "contact [email] now", KindEmail),
+		Entry("resident identity number", "身份证 11010519491231002X 号", "身份证 [id_number] 号", KindIDNumber),
+		Entry("social security number", "SSN 078-05-1120", "SSN [id_number]", KindIDNumber),
+		Entry("mainland mobile", "加微信13812345678领取", "加微信[phone]领取", KindPhone),
+		Entry("mobile with country code", "call +86 13812345678", "call [phone]", KindPhone),
+		Entry("international number", "tel +1 (415) 555-2671.", "tel [phone].", KindPhone),
+	)
+
+	It("should leave other numbers alone", func() {
+		counts := Counts{}
+		text := "order 20250601 costs 1299.00, build 12345678901234, version 1.2.3"
+		Expect(mask.Redact(text, counts)).To(Equal(text))
+		Expect(counts.Total()).To(BeZero())
+	})
+
+	It("should hash values so equal values can be correlated", func() {
+		hash, err := New(ModeHash, "salt")
+		Expect(err).NotTo(HaveOccurred())
+		counts := Counts{}
+		redacted := hash.Redact("Alice@example.com wrote to alice@example.com and bob@example.com", counts)
+		Expect(redacted).To(MatchRegexp(`^\[email:[0-9a-f]{12}\] wrote to \[email:[0-9a-f]{12}\] and \[email:[0-9a-f]{12}\]$`))
+		Expect(redacted[:20]).To(Equal(redacted[30:50]))
+		Expect(redacted).NotTo(ContainSubstring("example.com"))
+		Expect(counts[KindEmail]).To(Equal(3))
+
+		other, _ := New(ModeHash, "pepper")
+		Expect(other.Redact("alice@example.com", Counts{})).NotTo(Equal(redacted[:20]))
+	})
+
+	It("should reject unknown modes", func() {
+		_, err := New("scramble", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should redact a copy of a detection", func() {
+		info := &models.DetectorInfo{
+			Description: "Contact 13812345678 for odds",
+			Explanation: "mentions bob@example.com",
+			Metadata:    &models.SiteMetadata{Title: "Casino", SecurityTxt: "Contact: mailto:sec@example.com"},
+		}
+		redacted, counts := mask.Detection(info)
+		Expect(redacted.Description).To(Equal("Contact [phone] for odds"))
+		Expect(redacted.Explanation).To(Equal("mentions [email]"))
+		Expect(redacted.Metadata.SecurityTxt).To(Equal("Contact: mailto:[email]"))
+		Expect(counts).To(Equal(Counts{KindEmail: 2, KindPhone: 1}))
+		Expect(counts.String()).To(Equal("email=2 phone=1"))
+		Expect(info.Description).To(ContainSubstring("13812345678"))
+		Expect(info.Metadata.SecurityTxt).To(ContainSubstring("sec@example.com"))
+	})
+
+	It("should leave detections unchanged when disabled", func() {
+		var disabled *Redactor
+		info := &models.DetectorInfo{Description: "13812345678"}
+		redacted, counts := disabled.Detection(info)
+		Expect(redacted).To(BeIdenticalTo(info))
+		Expect(counts.Total()).To(BeZero())
+	})
+})
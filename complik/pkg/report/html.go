@@ -0,0 +1,83 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time":   func(t time.Time) string { return t.Format(timeLayout) },
+	"date":   func(t time.Time) string { return t.Format("2006-01-02") },
+	"status": statusText,
+	"join":   strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Compliance report {{.Namespace}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Noto Sans", "PingFang SC", sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+.meta { color: #666; margin-bottom: 1.5em; }
+.summary td { padding: 0.2em 1.5em 0.2em 0; }
+table.violations { border-collapse: collapse; width: 100%; margin-top: 1em; }
+table.violations th, table.violations td { border: 1px solid #ccc; padding: 0.4em; text-align: left; vertical-align: top; }
+table.violations th { background: #f3f3f3; }
+.status-confirmed { color: #b00020; font-weight: bold; }
+.status-dismissed { color: #2e7d32; }
+.status-pending_review { color: #8a6d00; }
+.comment { color: #555; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Compliance report: {{.Namespace}}</h1>
+<div class="meta">
+Period {{date .From}} to {{date .To}}{{if .Region}}, region {{.Region}}{{end}}. Generated {{time .GeneratedAt}}.
+</div>
+<table class="summary">
+<tr><td>Violations</td><td>{{.Summary.Total}}</td></tr>
+<tr><td>Confirmed</td><td>{{.Summary.Confirmed}}</td></tr>
+<tr><td>Pending review</td><td>{{.Summary.Pending}}</td></tr>
+<tr><td>Dismissed</td><td>{{.Summary.Dismissed}}</td></tr>
+</table>
+{{if .Violations}}
+<table class="violations">
+<tr><th>#</th><th>Detected</th><th>Resource</th><th>Finding</th><th>Evidence</th><th>Status</th></tr>
+{{range .Violations}}
+<tr>
+<td>{{.RecordID}}</td>
+<td>{{time .DetectedAt}}</td>
+<td>{{.Name}}{{if .Workload}}<br>{{.Workload}}{{end}}<br>{{.Host}}</td>
+<td>{{if .Severity}}<strong>{{.Severity}}</strong> {{end}}{{.Description}}{{if .Keywords}}<br>Keywords: {{join .Keywords ", "}}{{end}}<br>Detector: {{.Detector}}</td>
+<td>{{range .Evidence}}<a href="{{.}}">{{.}}</a><br>{{end}}</td>
+<td><span class="status-{{.Status}}">{{status .Status}}</span>{{if .Reviewer}}<div class="comment">by {{.Reviewer}}{{with .ReviewedAt}} on {{time .}}{{end}}{{if .Comment}}: {{.Comment}}{{end}}</div>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No violations were found in this period.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes r as a self-contained HTML page
+func WriteHTML(w io.Writer, r *Report) error {
+	return htmlTemplate.Execute(w, r)
+}
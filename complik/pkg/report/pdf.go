@@ -0,0 +1,144 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfFont       = "Helvetica"
+	pdfCustomFont = "ReportFont"
+	pdfLineHeight = 5.0
+)
+
+// WritePDF writes r as an A4 PDF document, one block per violation
+func WritePDF(w io.Writer, r *Report, opts Options) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Compliance report "+r.Namespace, true)
+	pdf.SetCreator("CompliK", true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(pdfFontFamily(opts), "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%s - page %d/{nb}", r.Namespace, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	// The core fonts are encoded in cp1252, a TrueType font takes UTF-8
+	text := pdf.UnicodeTranslatorFromDescriptor("")
+	if opts.FontPath != "" {
+		pdf.AddUTF8Font(pdfCustomFont, "", opts.FontPath)
+		pdf.AddUTF8Font(pdfCustomFont, "B", opts.FontPath)
+		text = func(s string) string { return s }
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("failed to load report font: %w", err)
+		}
+	}
+	font := pdfFontFamily(opts)
+	pdf.AddPage()
+
+	pdf.SetFont(font, "B", 16)
+	pdf.MultiCell(0, 8, text("Compliance report: "+r.Namespace), "", "L", false)
+	pdf.SetFont(font, "", 9)
+	pdf.SetTextColor(100, 100, 100)
+	meta := fmt.Sprintf("Period %s to %s", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	if r.Region != "" {
+		meta += ", region " + r.Region
+	}
+	meta += ". Generated " + r.GeneratedAt.Format(timeLayout) + "."
+	pdf.MultiCell(0, pdfLineHeight, text(meta), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(3)
+
+	pdf.SetFont(font, "", 10)
+	for _, row := range [][2]string{
+		{"Violations", fmt.Sprint(r.Summary.Total)},
+		{"Confirmed", fmt.Sprint(r.Summary.Confirmed)},
+		{"Pending review", fmt.Sprint(r.Summary.Pending)},
+		{"Dismissed", fmt.Sprint(r.Summary.Dismissed)},
+	} {
+		pdf.CellFormat(40, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 6, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	if len(r.Violations) == 0 {
+		pdf.MultiCell(0, pdfLineHeight, "No violations were found in this period.", "", "L", false)
+	}
+	for _, v := range r.Violations {
+		pdf.SetFont(font, "B", 10)
+		pdf.SetFillColor(243, 243, 243)
+		heading := fmt.Sprintf("#%d  %s  %s", v.RecordID, v.DetectedAt.Format(timeLayout), statusText(v.Status))
+		pdf.MultiCell(0, 6, text(heading), "T", "L", true)
+		pdf.SetFont(font, "", 9)
+		field := func(name, value string) {
+			if value == "" {
+				return
+			}
+			pdf.SetFont(font, "B", 9)
+			pdf.CellFormat(25, pdfLineHeight, name, "", 0, "L", false, 0, "")
+			pdf.SetFont(font, "", 9)
+			pdf.MultiCell(0, pdfLineHeight, text(value), "", "L", false)
+		}
+		field("Resource", strings.TrimSpace(v.Name+" "+v.Workload))
+		field("Host", v.Host)
+		field("Detector", v.Detector)
+		field("Severity", v.Severity)
+		field("Finding", v.Description)
+		field("Keywords", strings.Join(v.Keywords, ", "))
+		for i, link := range v.Evidence {
+			name := ""
+			if i == 0 {
+				name = "Evidence"
+			}
+			pdf.SetFont(font, "B", 9)
+			pdf.CellFormat(25, pdfLineHeight, name, "", 0, "L", false, 0, "")
+			pdf.SetFont(font, "", 9)
+			pdf.SetTextColor(0, 70, 160)
+			// WriteLinkString wraps long links, unlike a cell
+			pdf.WriteLinkString(pdfLineHeight, text(link), link)
+			pdf.Ln(pdfLineHeight)
+			pdf.SetTextColor(0, 0, 0)
+		}
+		if v.Reviewer != "" {
+			review := v.Reviewer
+			if v.ReviewedAt != nil {
+				review += " on " + v.ReviewedAt.Format(timeLayout)
+			}
+			if v.Comment != "" {
+				review += ": " + v.Comment
+			}
+			field("Review", review)
+		}
+		pdf.Ln(3)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render PDF report: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func pdfFontFamily(opts Options) string {
+	if opts.FontPath != "" {
+		return pdfCustomFont
+	}
+	return pdfFont
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report renders the compliance report of a namespace, the violations
+// found in it with their evidence and review status, as HTML or PDF for
+// sharing with the tenant.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report formats
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+// Resolution status of a violation, derived from the review label of the record
+const (
+	// StatusPending violations have not been reviewed yet
+	StatusPending = "pending_review"
+	// StatusConfirmed violations were confirmed by a reviewer
+	StatusConfirmed = "confirmed"
+	// StatusDismissed violations were found to be false positives
+	StatusDismissed = "dismissed"
+)
+
+// Violation is a single flagged detection
+type Violation struct {
+	RecordID    uint      `json:"record_id"`
+	DetectedAt  time.Time `json:"detected_at"`
+	Detector    string    `json:"detector"`
+	Name        string    `json:"name"`
+	Workload    string    `json:"workload,omitempty"`
+	Host        string    `json:"host"`
+	Severity    string    `json:"severity,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Keywords    []string  `json:"keywords,omitempty"`
+	// Evidence are the links the content was collected from
+	Evidence   []string   `json:"evidence"`
+	Status     string     `json:"status"`
+	Reviewer   string     `json:"reviewer,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+}
+
+// Summary counts the violations of a report per status
+type Summary struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending_review"`
+	Confirmed int `json:"confirmed"`
+	Dismissed int `json:"dismissed"`
+}
+
+// Report is the compliance report of a namespace for the period [From, To)
+type Report struct {
+	Namespace   string      `json:"namespace"`
+	Region      string      `json:"region,omitempty"`
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Summary     Summary     `json:"summary"`
+	Violations  []Violation `json:"violations"`
+}
+
+// New builds the report of namespace with the violations sorted by detection
+// time
+func New(namespace string, from, to time.Time, violations []Violation) *Report {
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].DetectedAt.Before(violations[j].DetectedAt)
+	})
+	summary := Summary{Total: len(violations)}
+	for _, v := range violations {
+		switch v.Status {
+		case StatusConfirmed:
+			summary.Confirmed++
+		case StatusDismissed:
+			summary.Dismissed++
+		default:
+			summary.Pending++
+		}
+	}
+	return &Report{
+		Namespace:   namespace,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		Summary:     summary,
+		Violations:  violations,
+	}
+}
+
+// ParseFormat validates a report format; an empty format is HTML
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatHTML:
+		return FormatHTML, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q, use html or pdf", format)
+	}
+}
+
+// ContentType returns the MIME type of format
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// FileName returns the file name of the report in format, e.g.
+// "complik-report-ns-demo-20250601.pdf"
+func (r *Report) FileName(format string) string {
+	return fmt.Sprintf("complik-report-%s-%s.%s", r.Namespace, r.GeneratedAt.Format("20060102"), format)
+}
+
+// Options tune the rendering of reports
+type Options struct {
+	// FontPath is a TrueType font used for PDF reports. The built-in PDF font
+	// only covers Latin-1, so text in other scripts, such as Chinese
+	// descriptions, needs a font that covers it.
+	FontPath string
+}
+
+// Render writes r to w in format
+func Render(w io.Writer, r *Report, format string, opts Options) error {
+	switch format {
+	case FormatHTML:
+		return WriteHTML(w, r)
+	case FormatPDF:
+		return WritePDF(w, r, opts)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// statusText is the status as shown to tenants
+func statusText(status string) string {
+	switch status {
+	case StatusConfirmed:
+		return "Confirmed"
+	case StatusDismissed:
+		return "Dismissed (false positive)"
+	default:
+		return "Pending review"
+	}
+}
+
+const timeLayout = "2006-01-02 15:04 MST"
@@ -0,0 +1,95 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Report Suite")
+}
+
+var _ = Describe("Report", func() {
+	var (
+		from = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		to   = from.AddDate(0, 1, 0)
+	)
+
+	sample := func() *report.Report {
+		reviewed := from.Add(72 * time.Hour)
+		return report.New("ns-demo", from, to, []report.Violation{
+			{
+				RecordID: 2, DetectedAt: from.Add(48 * time.Hour), Detector: "safety", Host: "blog.example.com",
+				Description: "包含赌博内容", Evidence: []string{"https://blog.example.com"},
+				Status: report.StatusDismissed, Reviewer: "bob", ReviewedAt: &reviewed, Comment: "news article",
+			},
+			{
+				RecordID: 1, DetectedAt: from.Add(24 * time.Hour), Detector: "safety", Host: "shop.example.com",
+				Severity: "high", Evidence: []string{"https://shop.example.com", "javascript:alert(1)"},
+				Status: report.StatusConfirmed,
+			},
+			{RecordID: 3, DetectedAt: from.Add(96 * time.Hour), Host: "new.example.com", Status: report.StatusPending},
+		})
+	}
+
+	It("should sort violations and count them per status", func() {
+		r := sample()
+		Expect(r.Violations[0].RecordID).To(Equal(uint(1)))
+		Expect(r.Summary).To(Equal(report.Summary{Total: 3, Pending: 1, Confirmed: 1, Dismissed: 1}))
+		Expect(r.FileName(report.FormatPDF)).To(MatchRegexp(`^complik-report-ns-demo-\d{8}\.pdf$`))
+	})
+
+	It("should parse formats", func() {
+		Expect(report.ParseFormat("")).To(Equal(report.FormatHTML))
+		Expect(report.ParseFormat("PDF")).To(Equal(report.FormatPDF))
+		_, err := report.ParseFormat("docx")
+		Expect(err).To(MatchError(ContainSubstring("unknown report format")))
+	})
+
+	It("should render HTML with safe evidence links", func() {
+		var buf bytes.Buffer
+		Expect(report.Render(&buf, sample(), report.FormatHTML, report.Options{})).To(Succeed())
+		html := buf.String()
+		Expect(html).To(ContainSubstring("Compliance report: ns-demo"))
+		Expect(html).To(ContainSubstring("包含赌博内容"))
+		Expect(html).To(ContainSubstring("Dismissed (false positive)"))
+		Expect(html).To(ContainSubstring(`href="https://shop.example.com"`))
+		Expect(html).NotTo(ContainSubstring(`href="javascript:`))
+
+		buf.Reset()
+		Expect(report.WriteHTML(&buf, report.New("ns-empty", from, to, nil))).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("No violations were found"))
+	})
+
+	It("should render PDF with the built-in font", func() {
+		var buf bytes.Buffer
+		Expect(report.Render(&buf, sample(), report.FormatPDF, report.Options{})).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("%PDF-"))
+	})
+
+	It("should fail on unusable fonts", func() {
+		var buf bytes.Buffer
+		err := report.WritePDF(&buf, sample(), report.Options{FontPath: "/nonexistent.ttf"})
+		Expect(err).To(MatchError(ContainSubstring("failed to load report font")))
+	})
+})
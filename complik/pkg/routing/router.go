@@ -0,0 +1,353 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package routing decides where and when detection notifications are delivered.
+// It is shared by the notifier plugins and supports region and severity based
+// routes, timezone-aware quiet hours and escalation of unacknowledged criticals.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata" // quiet hours must work in images without a zoneinfo database
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/ownership"
+)
+
+// Config is the routing section of a notifier plugin configuration
+type Config struct {
+	Timezone       string            `json:"timezone"`
+	DefaultWebhook string            `json:"defaultWebhook"`
+	QuietHours     *QuietHours       `json:"quietHours"`
+	Routes         []Route           `json:"routes"`
+	Escalation     *EscalationConfig `json:"escalation"`
+}
+
+// Route sends matching detections to Webhook. Empty Regions match any region
+// and an empty MinSeverity matches any severity.
+type Route struct {
+	Name        string      `json:"name"`
+	Regions     []string    `json:"regions"`
+	MinSeverity string      `json:"minSeverity"`
+	Webhook     string      `json:"webhook"`
+	QuietHours  *QuietHours `json:"quietHours"`
+	Continue    bool        `json:"continue"`
+}
+
+// QuietHours defers deliveries between Start and End (HH:MM, local to Timezone)
+// unless the detection severity is at least BypassSeverity.
+type QuietHours struct {
+	Start          string `json:"start"`
+	End            string `json:"end"`
+	Timezone       string `json:"timezone"`
+	BypassSeverity string `json:"bypassSeverity"`
+}
+
+// EscalationConfig adds Webhook as a target once Threshold critical detections
+// for the same namespace are left unacknowledged within WindowMinute.
+type EscalationConfig struct {
+	Threshold    int    `json:"threshold"`
+	WindowMinute int    `json:"windowMinute"`
+	Webhook      string `json:"webhook"`
+}
+
+// Target is a single delivery decided by the router
+type Target struct {
+	Route   string
+	Webhook string
+	// Emails are the addresses of an owning team, see ResolveFor
+	Emails     []string
+	Escalated  bool
+	DeferUntil time.Time
+}
+
+// Deferred reports whether the delivery has to wait for quiet hours to end
+func (t Target) Deferred() bool {
+	return !t.DeferUntil.IsZero()
+}
+
+type quietWindow struct {
+	start    int
+	end      int
+	location *time.Location
+	bypass   string
+}
+
+type compiledRoute struct {
+	Route
+	regions map[string]struct{}
+	quiet   *quietWindow
+}
+
+// Router resolves delivery targets for detection results
+type Router struct {
+	defaultWebhook string
+	quiet          *quietWindow
+	routes         []compiledRoute
+	escalation     *EscalationConfig
+
+	mu         sync.Mutex
+	unacked    map[string][]time.Time
+	escalating map[string]bool
+}
+
+// NewRouter validates cfg and builds a router. fallbackWebhook is used when the
+// configuration does not define a default webhook.
+func NewRouter(cfg *Config, fallbackWebhook string) (*Router, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	r := &Router{
+		defaultWebhook: cfg.DefaultWebhook,
+		unacked:        make(map[string][]time.Time),
+		escalating:     make(map[string]bool),
+	}
+	if r.defaultWebhook == "" {
+		r.defaultWebhook = fallbackWebhook
+	}
+
+	var err error
+	if r.quiet, err = compileQuietHours(cfg.QuietHours, cfg.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	for i, route := range cfg.Routes {
+		if route.Webhook == "" {
+			return nil, fmt.Errorf("route %d (%s): webhook cannot be empty", i, route.Name)
+		}
+		if route.MinSeverity != "" && models.SeverityRank(route.MinSeverity) == 0 {
+			return nil, fmt.Errorf("route %d (%s): unknown severity %q", i, route.Name, route.MinSeverity)
+		}
+		compiled := compiledRoute{Route: route}
+		if len(route.Regions) > 0 {
+			compiled.regions = make(map[string]struct{}, len(route.Regions))
+			for _, region := range route.Regions {
+				compiled.regions[region] = struct{}{}
+			}
+		}
+		if compiled.quiet, err = compileQuietHours(route.QuietHours, cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("route %d (%s): invalid quiet hours: %w", i, route.Name, err)
+		}
+		if compiled.quiet == nil {
+			compiled.quiet = r.quiet
+		}
+		r.routes = append(r.routes, compiled)
+	}
+	if cfg.Escalation != nil && cfg.Escalation.Threshold > 0 {
+		if cfg.Escalation.Webhook == "" {
+			return nil, errors.New("escalation webhook cannot be empty")
+		}
+		escalation := *cfg.Escalation
+		if escalation.WindowMinute <= 0 {
+			escalation.WindowMinute = 60
+		}
+		r.escalation = &escalation
+	}
+	return r, nil
+}
+
+// Resolve returns the delivery targets for result at time now
+func (r *Router) Resolve(result *models.DetectorInfo, now time.Time) []Target {
+	return r.ResolveFor(result, nil, now)
+}
+
+// ResolveFor returns the delivery targets for result at time now. When owner
+// has a channel of its own, it replaces the routes and the default webhook,
+// which remain the platform channel for all other namespaces. Escalations
+// still go to the escalation webhook.
+func (r *Router) ResolveFor(result *models.DetectorInfo, owner *ownership.Owner, now time.Time) []Target {
+	severity := EffectiveSeverity(result)
+	var targets []Target
+	if owner != nil && owner.Routable() {
+		name := owner.Team
+		if name == "" {
+			name = owner.Namespace
+		}
+		targets = append(targets, Target{
+			Route:      "owner:" + name,
+			Webhook:    owner.Webhook,
+			Emails:     owner.Emails,
+			DeferUntil: r.quiet.deferUntil(severity, now),
+		})
+	} else {
+		targets = r.routeTargets(result.Region, severity, now)
+	}
+	if r.escalation != nil && severity == models.SeverityCritical &&
+		r.recordCritical(result.Namespace, now) {
+		targets = append(targets, Target{
+			Route:     "escalation",
+			Webhook:   r.escalation.Webhook,
+			Escalated: true,
+		})
+	}
+	return targets
+}
+
+// routeTargets returns the targets of the routes matching region and
+// severity, or the default webhook when none matches
+func (r *Router) routeTargets(region, severity string, now time.Time) []Target {
+	var targets []Target
+	for _, route := range r.routes {
+		if !route.matches(region, severity) {
+			continue
+		}
+		targets = append(targets, Target{
+			Route:      route.Name,
+			Webhook:    route.Webhook,
+			DeferUntil: route.quiet.deferUntil(severity, now),
+		})
+		if !route.Continue {
+			break
+		}
+	}
+	if len(targets) == 0 && r.defaultWebhook != "" {
+		targets = append(targets, Target{
+			Route:      "default",
+			Webhook:    r.defaultWebhook,
+			DeferUntil: r.quiet.deferUntil(severity, now),
+		})
+	}
+	return targets
+}
+
+// Acknowledge clears the unacknowledged critical counter for namespace
+func (r *Router) Acknowledge(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.unacked, namespace)
+	delete(r.escalating, namespace)
+}
+
+// Unacknowledged returns the number of open criticals tracked for namespace
+func (r *Router) Unacknowledged(namespace string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.unacked[namespace])
+}
+
+func (r *Router) recordCritical(namespace string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	window := time.Duration(r.escalation.WindowMinute) * time.Minute
+	kept := r.unacked[namespace][:0]
+	for _, t := range r.unacked[namespace] {
+		if now.Sub(t) < window {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	r.unacked[namespace] = kept
+	if len(kept) < r.escalation.Threshold {
+		r.escalating[namespace] = false
+		return false
+	}
+	// Escalate once per crossing; the counter is reset on acknowledgement
+	if r.escalating[namespace] {
+		return false
+	}
+	r.escalating[namespace] = true
+	return true
+}
+
+// EffectiveSeverity returns the severity of result, treating illegal results
+// from detectors that do not grade severity as high
+func EffectiveSeverity(result *models.DetectorInfo) string {
+	if result.Severity != "" {
+		return result.Severity
+	}
+	if result.IsIllegal {
+		return models.SeverityHigh
+	}
+	return models.SeverityLow
+}
+
+func (c compiledRoute) matches(region, severity string) bool {
+	if c.regions != nil {
+		if _, ok := c.regions[region]; !ok {
+			return false
+		}
+	}
+	if c.MinSeverity != "" && models.SeverityRank(severity) < models.SeverityRank(c.MinSeverity) {
+		return false
+	}
+	return true
+}
+
+func compileQuietHours(q *QuietHours, defaultTimezone string) (*quietWindow, error) {
+	if q == nil || q.Start == "" || q.End == "" {
+		return nil, nil
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return nil, err
+	}
+	tz := q.Timezone
+	if tz == "" {
+		tz = defaultTimezone
+	}
+	location := time.UTC
+	if tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", tz, err)
+		}
+	}
+	bypass := q.BypassSeverity
+	if bypass == "" {
+		bypass = models.SeverityCritical
+	}
+	return &quietWindow{start: start, end: end, location: location, bypass: bypass}, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// deferUntil returns the end of the current quiet window, or the zero time when
+// the delivery can happen immediately
+func (q *quietWindow) deferUntil(severity string, now time.Time) time.Time {
+	if q == nil || q.start == q.end {
+		return time.Time{}
+	}
+	if models.SeverityRank(severity) >= models.SeverityRank(q.bypass) {
+		return time.Time{}
+	}
+	local := now.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, q.location)
+	endToday := midnight.Add(time.Duration(q.end) * time.Minute)
+	if q.start < q.end {
+		if minute >= q.start && minute < q.end {
+			return endToday
+		}
+		return time.Time{}
+	}
+	// The window wraps around midnight, e.g. 22:00-08:00
+	if minute >= q.start {
+		return endToday.AddDate(0, 0, 1)
+	}
+	if minute < q.end {
+		return endToday
+	}
+	return time.Time{}
+}
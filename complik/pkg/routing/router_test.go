@@ -0,0 +1,164 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/ownership"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRouting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Routing Suite")
+}
+
+var _ = Describe("Router", func() {
+	noon := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC)
+
+	Describe("NewRouter", func() {
+		It("should reject routes without webhooks", func() {
+			_, err := NewRouter(&Config{Routes: []Route{{Name: "empty"}}}, "")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject unknown severities", func() {
+			_, err := NewRouter(&Config{Routes: []Route{
+				{Name: "bad", Webhook: "http://a", MinSeverity: "urgent"},
+			}}, "")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject unknown timezones", func() {
+			_, err := NewRouter(&Config{
+				Timezone:   "Mars/Olympus",
+				QuietHours: &QuietHours{Start: "22:00", End: "08:00"},
+			}, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Resolve", func() {
+		It("should fall back to the default webhook", func() {
+			r, err := NewRouter(nil, "http://default")
+			Expect(err).NotTo(HaveOccurred())
+			targets := r.Resolve(&models.DetectorInfo{IsIllegal: true}, noon)
+			Expect(targets).To(HaveLen(1))
+			Expect(targets[0].Webhook).To(Equal("http://default"))
+		})
+
+		It("should route by region and severity", func() {
+			r, err := NewRouter(&Config{Routes: []Route{
+				{Name: "bj-critical", Regions: []string{"cn-beijing"}, MinSeverity: "critical", Webhook: "http://bj-oncall"},
+				{Name: "bj", Regions: []string{"cn-beijing"}, Webhook: "http://bj"},
+			}}, "http://default")
+			Expect(err).NotTo(HaveOccurred())
+
+			targets := r.Resolve(&models.DetectorInfo{Region: "cn-beijing", Severity: "critical"}, noon)
+			Expect(targets).To(HaveLen(1))
+			Expect(targets[0].Webhook).To(Equal("http://bj-oncall"))
+
+			targets = r.Resolve(&models.DetectorInfo{Region: "cn-beijing", Severity: "medium"}, noon)
+			Expect(targets[0].Webhook).To(Equal("http://bj"))
+
+			targets = r.Resolve(&models.DetectorInfo{Region: "us-west", Severity: "critical"}, noon)
+			Expect(targets[0].Webhook).To(Equal("http://default"))
+		})
+
+		It("should defer non-critical deliveries during quiet hours", func() {
+			r, err := NewRouter(&Config{
+				QuietHours: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"},
+			}, "http://default")
+			Expect(err).NotTo(HaveOccurred())
+
+			targets := r.Resolve(&models.DetectorInfo{IsIllegal: true}, night)
+			Expect(targets[0].Deferred()).To(BeTrue())
+			Expect(targets[0].DeferUntil).To(Equal(time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)))
+
+			targets = r.Resolve(&models.DetectorInfo{IsIllegal: true, Severity: "critical"}, night)
+			Expect(targets[0].Deferred()).To(BeFalse())
+
+			targets = r.Resolve(&models.DetectorInfo{IsIllegal: true}, noon)
+			Expect(targets[0].Deferred()).To(BeFalse())
+		})
+
+		It("should evaluate quiet hours in the configured timezone", func() {
+			r, err := NewRouter(&Config{
+				Timezone:   "Asia/Shanghai",
+				QuietHours: &QuietHours{Start: "22:00", End: "08:00"},
+			}, "http://default")
+			Expect(err).NotTo(HaveOccurred())
+			// 15:00 UTC is 23:00 in Shanghai
+			afternoonUTC := time.Date(2025, 6, 1, 15, 0, 0, 0, time.UTC)
+			targets := r.Resolve(&models.DetectorInfo{IsIllegal: true}, afternoonUTC)
+			Expect(targets[0].Deferred()).To(BeTrue())
+		})
+
+		It("should escalate once after unacknowledged criticals", func() {
+			r, err := NewRouter(&Config{
+				Escalation: &EscalationConfig{Threshold: 2, Webhook: "http://escalate"},
+			}, "http://default")
+			Expect(err).NotTo(HaveOccurred())
+			critical := &models.DetectorInfo{Namespace: "ns-a", Severity: "critical"}
+
+			Expect(r.Resolve(critical, noon)).To(HaveLen(1))
+			targets := r.Resolve(critical, noon.Add(time.Minute))
+			Expect(targets).To(HaveLen(2))
+			Expect(targets[1].Escalated).To(BeTrue())
+			Expect(r.Resolve(critical, noon.Add(2*time.Minute))).To(HaveLen(1))
+
+			r.Acknowledge("ns-a")
+			Expect(r.Unacknowledged("ns-a")).To(BeZero())
+			Expect(r.Resolve(critical, noon.Add(3*time.Minute))).To(HaveLen(1))
+		})
+	})
+
+	Describe("ResolveFor", func() {
+		It("should send to the owning team instead of the routes", func() {
+			r, err := NewRouter(&Config{
+				QuietHours: &QuietHours{Start: "22:00", End: "08:00"},
+				Routes:     []Route{{Name: "all", Webhook: "http://platform"}},
+				Escalation: &EscalationConfig{Threshold: 1, Webhook: "http://escalate"},
+			}, "")
+			Expect(err).NotTo(HaveOccurred())
+			owner := &ownership.Owner{Namespace: "ns-a", Team: "payments", Webhook: "http://team", Emails: []string{"oncall@example.com"}}
+
+			targets := r.ResolveFor(&models.DetectorInfo{Namespace: "ns-a", IsIllegal: true}, owner, night)
+			Expect(targets).To(HaveLen(1))
+			Expect(targets[0].Route).To(Equal("owner:payments"))
+			Expect(targets[0].Emails).To(ConsistOf("oncall@example.com"))
+			Expect(targets[0].Deferred()).To(BeTrue())
+
+			targets = r.ResolveFor(&models.DetectorInfo{Namespace: "ns-a", Severity: "critical"}, owner, noon)
+			Expect(targets).To(HaveLen(2))
+			Expect(targets[0].Webhook).To(Equal("http://team"))
+			Expect(targets[1].Escalated).To(BeTrue())
+		})
+
+		It("should fall back to the platform routes without an owner channel", func() {
+			r, err := NewRouter(&Config{Routes: []Route{{Name: "all", Webhook: "http://platform"}}}, "")
+			Expect(err).NotTo(HaveOccurred())
+			owner := &ownership.Owner{Namespace: "ns-a", Team: "unknown", Source: ownership.SourceFallback}
+			targets := r.ResolveFor(&models.DetectorInfo{Namespace: "ns-a", IsIllegal: true}, owner, noon)
+			Expect(targets).To(HaveLen(1))
+			Expect(targets[0].Webhook).To(Equal("http://platform"))
+		})
+	})
+})
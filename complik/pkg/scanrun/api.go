@@ -0,0 +1,80 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanrun
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// API serves the scan run endpoints:
+//
+//	GET /api/v1/scans         runs, newest first; ?state=running filters
+//	GET /api/v1/scans/{id}
+type API struct {
+	log     logger.Logger
+	tracker *Tracker
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewAPI(log logger.Logger, token string, tracker *Tracker) *API {
+	api := &API{log: log, tracker: tracker, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/scans", api.list)
+	api.mux.HandleFunc("GET /api/v1/scans/{id}", api.get)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) list(w http.ResponseWriter, r *http.Request) {
+	runs := a.tracker.Runs()
+	if state := r.URL.Query().Get("state"); state != "" {
+		filtered := make([]Run, 0, len(runs))
+		for _, run := range runs {
+			if run.State == state {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+	httpapi.WriteJSON(w, http.StatusOK, runs)
+}
+
+func (a *API) get(w http.ResponseWriter, r *http.Request) {
+	run, err := a.tracker.Run(r.PathValue("id"))
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, run)
+}
+
+// fail maps tracker errors to HTTP status codes
+func (a *API) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRunNotFound):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+	default:
+		a.log.Error("Scan run API request failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanrun
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScanRun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scan Run Suite")
+}
+
+// newTestTracker returns a tracker whose clock is advanced with the returned
+// function
+func newTestTracker(history int) (*Tracker, func(time.Duration)) {
+	now := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	tracker := NewTracker(history, 0)
+	tracker.now = func() time.Time { return now }
+	return tracker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func announce(tracker *Tracker, id, source string, total int) {
+	tracker.Begin(&models.ScanRunEvent{RunID: id, Source: source, Total: total, StartedAt: tracker.now()})
+}
+
+var _ = Describe("Tracker", func() {
+	It("should count targets and estimate the remaining time", func() {
+		tracker, advance := newTestTracker(0)
+		announce(tracker, "complete-1", "Complete", 4)
+
+		advance(time.Minute)
+		tracker.Complete("complete-1", "a")
+		tracker.Complete("complete-1", "a")
+		tracker.Fail("complete-1", "b", "navigation failed")
+
+		run, err := tracker.Run("complete-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(run.State).To(Equal(StateRunning))
+		Expect(run.Completed).To(Equal(1))
+		Expect(run.Failed).To(Equal(1))
+		Expect(run.Percent).To(Equal(50.0))
+		Expect(*run.ETA).To(Equal(run.StartedAt.Add(2 * time.Minute)))
+
+		tracker.Complete("complete-1", "c")
+		tracker.Complete("complete-1", "d")
+		run, _ = tracker.Run("complete-1")
+		Expect(run.State).To(Equal(StateCompleted))
+		Expect(run.Percent).To(Equal(100.0))
+		Expect(run.ETA).To(BeNil())
+		Expect(run.FinishedAt).NotTo(BeNil())
+
+		_, err = tracker.Run("missing")
+		Expect(err).To(MatchError(ErrRunNotFound))
+	})
+
+	It("should keep results that overtake the announcement", func() {
+		tracker, _ := newTestTracker(0)
+		tracker.Complete("complete-1", "a")
+		run, _ := tracker.Run("complete-1")
+		Expect(run.State).To(Equal(StateRunning))
+
+		announce(tracker, "complete-1", "Complete", 1)
+		run, _ = tracker.Run("complete-1")
+		Expect(run.State).To(Equal(StateCompleted))
+		Expect(run.Source).To(Equal("Complete"))
+	})
+
+	It("should close superseded runs and drop unannounced ones", func() {
+		tracker, advance := newTestTracker(1)
+		tracker.Complete("before-restart", "a")
+		announce(tracker, "complete-1", "Complete", 2)
+		announce(tracker, "devbox-1", "Devbox", 1)
+		advance(time.Hour)
+		announce(tracker, "complete-2", "Complete", 2)
+
+		_, err := tracker.Run("before-restart")
+		Expect(err).To(MatchError(ErrRunNotFound))
+		run, _ := tracker.Run("complete-1")
+		Expect(run.State).To(Equal(StateIncomplete))
+		Expect(run.Percent).To(BeZero())
+
+		tracker.Complete("devbox-1", "a")
+		runs := tracker.Runs()
+		Expect(runs).To(HaveLen(2))
+		Expect(runs[0].ID).To(Equal("complete-2"))
+		Expect(runs[1].ID).To(Equal("devbox-1"))
+	})
+
+	It("should track runs from the event bus", func() {
+		eb := eventbus.NewEventBus(10)
+		tracker := NewTracker(0, 0)
+		tracker.Start(eb)
+
+		eb.Publish(constants.ScanRunTopic, eventbus.Event{Payload: &models.ScanRunEvent{
+			RunID: "complete-1", Source: "Complete", Total: 2,
+		}})
+		Eventually(func() error { _, err := tracker.Run("complete-1"); return err }).Should(Succeed())
+		eb.Publish(constants.CollectorTopic, eventbus.Event{Payload: &models.CollectorInfo{
+			ScanRunID: "complete-1", Namespace: "ns-a", Name: "web", Host: "a.example.com",
+		}})
+		eb.Publish(constants.ScanRunTopic, eventbus.Event{Payload: &models.ScanRunEvent{
+			RunID: "complete-1", Target: "ns-b/web b.example.com", Error: "net::ERR_CONNECTION_REFUSED",
+		}})
+
+		Eventually(func() string { run, _ := tracker.Run("complete-1"); return run.State }).Should(Equal(StateCompleted))
+		run, _ := tracker.Run("complete-1")
+		Expect(run.Completed).To(Equal(1))
+		Expect(run.Failed).To(Equal(1))
+	})
+
+	It("should publish the summary with the violations and new incidents", func() {
+		eb := eventbus.NewEventBus(10)
+		summaries := eb.Subscribe(constants.ScanSummaryTopic)
+		tracker := NewTracker(0, 50*time.Millisecond)
+		tracker.Start(eb)
+		started := time.Now().Add(-time.Minute)
+		tracker.Begin(&models.ScanRunEvent{RunID: "complete-1", Source: "Complete", Total: 2, StartedAt: started})
+		tracker.Complete("complete-1", models.ScanTarget("ns-a", "web", "a.example.com", nil))
+		tracker.Fail("complete-1", "ns-b/web b.example.com", "net::ERR_CONNECTION_REFUSED")
+
+		flagged := &models.DetectorInfo{Namespace: "ns-a", Name: "web", Host: "a.example.com", IsIllegal: true}
+		tracker.Detected(flagged)
+		tracker.Detected(flagged)
+		tracker.Detected(&models.DetectorInfo{Namespace: "ns-c", Name: "web", Host: "c.example.com", IsIllegal: true})
+		tracker.Incident(&models.Incident{ID: "1", Namespace: "ns-a", FirstSeen: time.Now()})
+		tracker.Incident(&models.Incident{ID: "2", Namespace: "ns-a", FirstSeen: started.Add(-time.Hour)})
+		tracker.Incident(&models.Incident{ID: "3", Namespace: "ns-c", FirstSeen: time.Now()})
+
+		var event eventbus.Event
+		Eventually(summaries).Should(Receive(&event))
+		summary := event.Payload.(*models.ScanSummary)
+		Expect(summary.RunID).To(Equal("complete-1"))
+		Expect(summary.State).To(Equal(StateCompleted))
+		Expect(summary.Completed).To(Equal(1))
+		Expect(summary.Failed).To(Equal(1))
+		Expect(summary.Violations).To(Equal(1))
+		Expect(summary.NewIncidents).To(Equal(1))
+		Expect(summary.Duration()).To(BeNumerically(">=", time.Minute))
+
+		tracker.Detected(&models.DetectorInfo{Namespace: "ns-a", Name: "web", Host: "b.example.com", IsIllegal: true})
+		run, _ := tracker.Run("complete-1")
+		Expect(run.Violations).To(Equal(1))
+	})
+
+	It("should attribute detections of one path to the target collected on several paths", func() {
+		tracker, _ := newTestTracker(0)
+		announce(tracker, "complete-1", "Complete", 2)
+		tracker.Complete("complete-1", models.ScanTarget("ns-a", "web", "a.example.com", []string{"/", "/shop"}))
+
+		tracker.Detected(&models.DetectorInfo{Namespace: "ns-a", Name: "web", Host: "a.example.com", Path: []string{"/admin"}, IsIllegal: true})
+		run, _ := tracker.Run("complete-1")
+		Expect(run.Violations).To(Equal(0))
+
+		tracker.Detected(&models.DetectorInfo{Namespace: "ns-a", Name: "web", Host: "a.example.com", Path: []string{"/shop"}, IsIllegal: true})
+		tracker.Detected(&models.DetectorInfo{Namespace: "ns-a", Name: "web", Host: "a.example.com", Path: []string{"/", "/shop"}, IsIllegal: true})
+		run, _ = tracker.Run("complete-1")
+		Expect(run.Violations).To(Equal(1))
+	})
+})
+
+var _ = Describe("API", func() {
+	It("should list and get runs", func() {
+		tracker, _ := newTestTracker(0)
+		announce(tracker, "complete-1", "Complete", 1)
+		announce(tracker, "devbox-1", "Devbox", 2)
+		tracker.Complete("complete-1", "a")
+		api := NewAPI(logger.GetLogger(), "secret", tracker)
+
+		request := func(path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := request("/api/v1/scans?state=running")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var runs []Run
+		Expect(json.Unmarshal(rec.Body.Bytes(), &runs)).To(Succeed())
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].ID).To(Equal("devbox-1"))
+
+		rec = request("/api/v1/scans/complete-1")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var run Run
+		Expect(json.Unmarshal(rec.Body.Bytes(), &run)).To(Succeed())
+		Expect(run.State).To(Equal(StateCompleted))
+
+		Expect(request("/api/v1/scans/missing").Code).To(Equal(http.StatusNotFound))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scans", nil)
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/scans", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		rec = httptest.NewRecorder()
+		NewAPI(logger.GetLogger(), "", tracker).ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
+
+var _ = Describe("NewScanRunID", func() {
+	It("should name runs after their source and start", func() {
+		Expect(models.NewScanRunID("Complete", time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC))).
+			To(Equal("complete-2024-06-01T02:00:00Z"))
+	})
+})
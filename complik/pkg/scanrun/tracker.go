@@ -0,0 +1,465 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scanrun tracks the progress of scan runs: the targets a discovery
+// plugin publishes in one cycle, counted as completed once a collector
+// published their result and as failed when the collection failed for good.
+// Shortly after a run finished its summary is published on ScanSummaryTopic.
+package scanrun
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// States of a run
+const (
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	// StateIncomplete marks runs that were superseded by the next run of
+	// their source before all targets were collected
+	StateIncomplete = "incomplete"
+)
+
+// DefaultHistory is the number of finished runs kept by default
+const DefaultHistory = 20
+
+// DefaultSummaryDelay is how long the detections of the last collected
+// targets are waited for before the summary of a run is published
+const DefaultSummaryDelay = 2 * time.Minute
+
+// ErrRunNotFound is returned for unknown run IDs
+var ErrRunNotFound = errors.New("scan run not found")
+
+// Run is the progress of a scan run
+type Run struct {
+	ID         string     `json:"id"`
+	Source     string     `json:"source"`
+	State      string     `json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	Failed     int        `json:"failed"`
+	// Percent is the share of completed and failed targets
+	Percent float64 `json:"percent"`
+	// Violations counts the targets flagged by a detector and NewIncidents
+	// the correlated incidents opened in the namespaces of the run
+	Violations   int `json:"violations"`
+	NewIncidents int `json:"new_incidents"`
+	// ETA extrapolates the rate so far, it is only set for running runs
+	ETA *time.Time `json:"eta,omitempty"`
+}
+
+type run struct {
+	Run
+	// announced is false for runs only known from results that overtook
+	// the announcement on the event bus
+	announced bool
+	// done, flagged and incidents are dropped with the summary
+	done       map[string]bool
+	namespaces map[string]bool
+	flagged    map[string]bool
+	incidents  map[string]bool
+}
+
+// Tracker keeps the running scan runs and the most recent finished ones
+type Tracker struct {
+	log          logger.Logger
+	history      int
+	summaryDelay time.Duration
+	now          func() time.Time
+	// eventBus receives the summaries once Start was called
+	eventBus *eventbus.EventBus
+
+	mu   sync.Mutex
+	runs map[string]*run
+	// order lists the run IDs from oldest to newest
+	order []string
+}
+
+// NewTracker creates a tracker keeping history finished runs and publishing
+// their summaries summaryDelay after they finished. Values that are not
+// positive select DefaultHistory and DefaultSummaryDelay.
+func NewTracker(history int, summaryDelay time.Duration) *Tracker {
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	if summaryDelay <= 0 {
+		summaryDelay = DefaultSummaryDelay
+	}
+	return &Tracker{
+		log:          logger.GetLogger().WithField("component", "scanrun"),
+		history:      history,
+		summaryDelay: summaryDelay,
+		now:          time.Now,
+		runs:         make(map[string]*run),
+	}
+}
+
+// Start subscribes to the run announcements, the collector and detector
+// results and the incidents, and tracks them in the background. It must be
+// called before the plugins start.
+func (t *Tracker) Start(eventBus *eventbus.EventBus) {
+	t.mu.Lock()
+	t.eventBus = eventBus
+	t.mu.Unlock()
+	runs := eventBus.Subscribe(constants.ScanRunTopic)
+	results := eventBus.Subscribe(constants.CollectorTopic)
+	detections := eventBus.Subscribe(constants.DetectorTopic)
+	incidents := eventBus.Subscribe(constants.CorrelationTopic)
+	go func() {
+		for {
+			select {
+			case event, ok := <-runs:
+				if !ok {
+					return
+				}
+				e, ok := event.Payload.(*models.ScanRunEvent)
+				if !ok {
+					t.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.ScanRunEvent",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if e.Target != "" {
+					t.Fail(e.RunID, e.Target, e.Error)
+				} else {
+					t.Begin(e)
+				}
+			case event, ok := <-results:
+				if !ok {
+					return
+				}
+				if result, ok := event.Payload.(*models.CollectorInfo); ok && result.ScanRunID != "" {
+					t.Complete(result.ScanRunID, models.ScanTarget(result.Namespace, result.Name, result.Host, result.Path))
+				}
+			case event, ok := <-detections:
+				if !ok {
+					return
+				}
+				if result, ok := event.Payload.(*models.DetectorInfo); ok {
+					t.Detected(result)
+				}
+			case event, ok := <-incidents:
+				if !ok {
+					return
+				}
+				if incident, ok := event.Payload.(*models.Incident); ok {
+					t.Incident(incident)
+				}
+			}
+		}
+	}()
+}
+
+// Begin records the announcement of a run. Unfinished runs of the same
+// source are closed as incomplete, and runs that were never announced, such
+// as those of retried targets discovered before a restart, are dropped.
+func (t *Tracker) Begin(e *models.ScanRunEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range slices.Clone(t.order) {
+		r, ok := t.runs[id]
+		switch {
+		case !ok || id == e.RunID || r.State != StateRunning:
+		case !r.announced:
+			t.drop(id)
+		case r.Source == e.Source:
+			t.finish(r, StateIncomplete)
+		}
+	}
+	r := t.get(e.RunID)
+	r.Source = e.Source
+	r.Total = e.Total
+	if !e.StartedAt.IsZero() {
+		r.StartedAt = e.StartedAt
+	}
+	r.announced = true
+	t.log.Info("Scan run started", logger.Fields{
+		"run_id": r.ID,
+		"source": r.Source,
+		"total":  r.Total,
+	})
+	t.check(r)
+}
+
+// Complete counts target of run as collected
+func (t *Tracker) Complete(runID, target string) {
+	t.count(runID, target, func(r *run) { r.Completed++ })
+}
+
+// Fail counts target of run as failed
+func (t *Tracker) Fail(runID, target, cause string) {
+	t.count(runID, target, func(r *run) { r.Failed++ })
+	t.log.Debug("Scan run target failed", logger.Fields{
+		"run_id": runID,
+		"target": target,
+		"error":  cause,
+	})
+}
+
+func (t *Tracker) count(runID, target string, add func(*run)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.get(runID)
+	// Targets are counted once, a retried target may be reported again
+	if r.State != StateRunning || r.done[target] {
+		return
+	}
+	r.done[target] = true
+	if namespace, _, ok := strings.Cut(target, "/"); ok {
+		r.namespaces[namespace] = true
+	}
+	add(r)
+	t.check(r)
+}
+
+// get returns the run with id, creating it when it is unknown. The caller
+// must hold the lock.
+func (t *Tracker) get(id string) *run {
+	if r, ok := t.runs[id]; ok {
+		return r
+	}
+	r := &run{
+		Run:        Run{ID: id, State: StateRunning, StartedAt: t.now()},
+		done:       make(map[string]bool),
+		namespaces: make(map[string]bool),
+		flagged:    make(map[string]bool),
+		incidents:  make(map[string]bool),
+	}
+	t.runs[id] = r
+	t.order = append(t.order, id)
+	return r
+}
+
+// check finishes r once all its targets were counted
+func (t *Tracker) check(r *run) {
+	if r.announced && r.State == StateRunning && r.Completed+r.Failed >= r.Total {
+		t.finish(r, StateCompleted)
+	}
+}
+
+// finish closes r, logs its summary and drops the oldest finished runs
+// beyond the history
+func (t *Tracker) finish(r *run, state string) {
+	finished := t.now()
+	r.State = state
+	r.FinishedAt = &finished
+	if t.eventBus != nil {
+		id := r.ID
+		time.AfterFunc(t.summaryDelay, func() { t.summarize(id) })
+	} else {
+		r.release()
+	}
+	t.log.Info("Scan run finished", logger.Fields{
+		"run_id":       r.ID,
+		"source":       r.Source,
+		"state":        state,
+		"total":        r.Total,
+		"completed":    r.Completed,
+		"failed":       r.Failed,
+		"percent":      percent(&r.Run),
+		"duration_sec": int(finished.Sub(r.StartedAt).Seconds()),
+	})
+
+	finishedRuns := 0
+	for _, id := range t.order {
+		if t.runs[id].State != StateRunning {
+			finishedRuns++
+		}
+	}
+	kept := t.order[:0]
+	for _, id := range t.order {
+		if finishedRuns > t.history && t.runs[id].State != StateRunning {
+			delete(t.runs, id)
+			finishedRuns--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	t.order = kept
+}
+
+// Detected counts targets of a run flagged by a detector. Detections arrive
+// after the collection, possibly after the run finished, so they are
+// attributed to the newest run that collected the target until its summary
+// was published.
+func (t *Tracker) Detected(result *models.DetectorInfo) {
+	if !result.IsIllegal {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range slices.Backward(t.order) {
+		r := t.runs[id]
+		if target, ok := r.collected(result); ok {
+			if !r.flagged[target] {
+				r.flagged[target] = true
+				r.Violations++
+			}
+			return
+		}
+	}
+}
+
+// Incident counts incidents opened in a namespace of a run while the run
+// was going on or waiting for its summary
+func (t *Tracker) Incident(incident *models.Incident) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range t.order {
+		r := t.runs[id]
+		if r.namespaces[incident.Namespace] && !incident.FirstSeen.Before(r.StartedAt) && !r.incidents[incident.ID] {
+			r.incidents[incident.ID] = true
+			r.NewIncidents++
+		}
+	}
+}
+
+// summarize publishes the summary of the finished run with id and drops the
+// state only needed for it
+func (t *Tracker) summarize(id string) {
+	t.mu.Lock()
+	r, ok := t.runs[id]
+	if !ok || r.FinishedAt == nil {
+		t.mu.Unlock()
+		return
+	}
+	summary := &models.ScanSummary{
+		RunID:        r.ID,
+		Source:       r.Source,
+		State:        r.State,
+		StartedAt:    r.StartedAt,
+		FinishedAt:   *r.FinishedAt,
+		Total:        r.Total,
+		Completed:    r.Completed,
+		Failed:       r.Failed,
+		Violations:   r.Violations,
+		NewIncidents: r.NewIncidents,
+	}
+	r.release()
+	eventBus := t.eventBus
+	t.mu.Unlock()
+
+	t.log.Info("Publishing scan run summary", logger.Fields{
+		"run_id":        summary.RunID,
+		"violations":    summary.Violations,
+		"new_incidents": summary.NewIncidents,
+	})
+	eventBus.Publish(constants.ScanSummaryTopic, eventbus.Event{Payload: summary})
+}
+
+// collected returns the target of r result was detected on. Results of a
+// target collected on several ingress paths carry the flagged paths only.
+func (r *run) collected(result *models.DetectorInfo) (string, bool) {
+	target := models.ScanTarget(result.Namespace, result.Name, result.Host, result.Path)
+	if r.done[target] {
+		return target, true
+	}
+	if len(result.Path) == 0 {
+		return "", false
+	}
+	prefix := models.ScanTarget(result.Namespace, result.Name, result.Host, nil)
+	for done := range r.done {
+		if paths, ok := strings.CutPrefix(done, prefix); ok && containsAll(strings.Split(paths, ","), result.Path) {
+			return done, true
+		}
+	}
+	return "", false
+}
+
+func containsAll(paths, subset []string) bool {
+	for _, path := range subset {
+		if !slices.Contains(paths, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// release drops the per target state of a run whose summary is out
+func (r *run) release() {
+	r.done = nil
+	r.namespaces = nil
+	r.flagged = nil
+	r.incidents = nil
+}
+
+// drop forgets the run with id
+func (t *Tracker) drop(id string) {
+	delete(t.runs, id)
+	t.order = slices.DeleteFunc(t.order, func(other string) bool { return other == id })
+}
+
+// Runs returns the tracked runs, newest first
+func (t *Tracker) Runs() []Run {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	runs := make([]Run, 0, len(t.order))
+	for _, id := range t.order {
+		runs = append(runs, t.snapshot(t.runs[id]))
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs
+}
+
+// Run returns the run with id
+func (t *Tracker) Run(id string) (Run, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.runs[id]
+	if !ok {
+		return Run{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return t.snapshot(r), nil
+}
+
+// snapshot copies r and fills in the derived fields
+func (t *Tracker) snapshot(r *run) Run {
+	s := r.Run
+	s.Percent = percent(&s)
+	processed := s.Completed + s.Failed
+	if s.State == StateRunning && r.announced && processed > 0 && processed < s.Total {
+		elapsed := t.now().Sub(s.StartedAt)
+		eta := s.StartedAt.Add(time.Duration(float64(elapsed) * float64(s.Total) / float64(processed)))
+		s.ETA = &eta
+	}
+	return s
+}
+
+// percent is the processed share of r with one decimal
+func percent(r *Run) float64 {
+	if r.Total == 0 {
+		if r.State == StateCompleted {
+			return 100
+		}
+		return 0
+	}
+	return math.Floor(float64(r.Completed+r.Failed)*1000/float64(r.Total)) / 10
+}
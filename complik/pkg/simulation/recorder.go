@@ -0,0 +1,187 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulation records what the handler plugins would have done during a
+// dry run and summarises it in a simulation report.
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+)
+
+// Flagged is a detection that the handlers would have acted on
+type Flagged struct {
+	DetectedAt  time.Time `json:"detected_at"`
+	Detector    string    `json:"detector"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Host        string    `json:"host"`
+	URL         string    `json:"url"`
+	Severity    string    `json:"severity"`
+	Keywords    []string  `json:"keywords,omitempty"`
+	Explanation string    `json:"explanation,omitempty"`
+}
+
+// RunReport summarises a dry run
+type RunReport struct {
+	StartedAt    time.Time      `json:"started_at"`
+	FinishedAt   time.Time      `json:"finished_at"`
+	Handlers     []string       `json:"handlers"`
+	Detections   int            `json:"detections"`
+	FlaggedCount int            `json:"flagged"`
+	ByDetector   map[string]int `json:"flagged_by_detector"`
+	BySeverity   map[string]int `json:"flagged_by_severity"`
+	ByNamespace  map[string]int `json:"flagged_by_namespace"`
+	Flagged      []Flagged      `json:"flagged_detections"`
+}
+
+// Recorder stands in for the handler plugins during a dry run. It consumes
+// detector results, logs the actions that were skipped and builds a RunReport.
+type Recorder struct {
+	log        logger.Logger
+	handlers   []string
+	maxFlagged int
+
+	mu     sync.Mutex
+	report RunReport
+}
+
+// NewRecorder creates a recorder for the given skipped handler plugins.
+// maxFlagged limits the detections kept in the report, 0 keeps all of them.
+func NewRecorder(handlers []string, maxFlagged int) *Recorder {
+	return &Recorder{
+		log:        logger.GetLogger().WithField("component", "simulation"),
+		handlers:   handlers,
+		maxFlagged: maxFlagged,
+		report: RunReport{
+			StartedAt:   time.Now(),
+			Handlers:    append([]string{}, handlers...),
+			ByDetector:  make(map[string]int),
+			BySeverity:  make(map[string]int),
+			ByNamespace: make(map[string]int),
+			Flagged:     []Flagged{},
+		},
+	}
+}
+
+// Start subscribes to the detector topic and records results in the
+// background. It must be called before the detectors start.
+func (r *Recorder) Start(eventBus *eventbus.EventBus) {
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	go func() {
+		for event := range subscribe {
+			result, ok := event.Payload.(*models.DetectorInfo)
+			if !ok {
+				r.log.Error("Invalid event payload type", logger.Fields{
+					"expected": "*models.DetectorInfo",
+					"actual":   fmt.Sprintf("%T", event.Payload),
+				})
+				continue
+			}
+			r.Record(result)
+		}
+	}()
+}
+
+// Record adds a single detector result to the report
+func (r *Recorder) Record(result *models.DetectorInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Detections++
+	if !result.IsIllegal {
+		return
+	}
+
+	severity := routing.EffectiveSeverity(result)
+	r.report.FlaggedCount++
+	r.report.ByDetector[result.DetectorName]++
+	r.report.BySeverity[severity]++
+	r.report.ByNamespace[result.Namespace]++
+	r.log.Warn("Dry-run: detection would be handled", logger.Fields{
+		"detector":  result.DetectorName,
+		"namespace": result.Namespace,
+		"host":      result.Host,
+		"severity":  severity,
+		"keywords":  result.Keywords,
+		"handlers":  r.handlers,
+	})
+	if r.maxFlagged > 0 && len(r.report.Flagged) >= r.maxFlagged {
+		return
+	}
+	r.report.Flagged = append(r.report.Flagged, Flagged{
+		DetectedAt:  time.Now(),
+		Detector:    result.DetectorName,
+		Namespace:   result.Namespace,
+		Name:        result.Name,
+		Host:        result.Host,
+		URL:         result.URL,
+		Severity:    severity,
+		Keywords:    result.Keywords,
+		Explanation: result.Explanation,
+	})
+}
+
+// Report returns a snapshot of the simulation report
+func (r *Recorder) Report() RunReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.report
+	report.FinishedAt = time.Now()
+	report.Flagged = append([]Flagged{}, r.report.Flagged...)
+	sort.SliceStable(report.Flagged, func(i, j int) bool {
+		return models.SeverityRank(report.Flagged[i].Severity) >
+			models.SeverityRank(report.Flagged[j].Severity)
+	})
+	report.ByDetector = copyCounts(r.report.ByDetector)
+	report.BySeverity = copyCounts(r.report.BySeverity)
+	report.ByNamespace = copyCounts(r.report.ByNamespace)
+	return report
+}
+
+// WriteReport writes the report as indented JSON to path
+func (r *Recorder) WriteReport(path string) error {
+	data, err := json.MarshalIndent(r.Report(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal simulation report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write simulation report: %w", err)
+	}
+	return nil
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for k, v := range counts {
+		copied[k] = v
+	}
+	return copied
+}
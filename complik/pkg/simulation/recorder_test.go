@@ -0,0 +1,89 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSimulation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulation Suite")
+}
+
+var _ = Describe("Recorder", func() {
+	It("should summarise flagged detections from the event bus", func() {
+		eb := eventbus.NewEventBus(10)
+		recorder := NewRecorder([]string{"Lark", "Postgres"}, 0)
+		recorder.Start(eb)
+
+		eb.Publish(constants.DetectorTopic, eventbus.Event{Payload: &models.DetectorInfo{
+			DetectorName: "Safety", Namespace: "ns-a", Host: "a.example.com",
+		}})
+		eb.Publish(constants.DetectorTopic, eventbus.Event{Payload: &models.DetectorInfo{
+			DetectorName: "Safety", Namespace: "ns-a", Host: "b.example.com", IsIllegal: true,
+		}})
+		eb.Publish(constants.DetectorTopic, eventbus.Event{Payload: &models.DetectorInfo{
+			DetectorName: "Secrets", Namespace: "ns-b", Host: "c.example.com",
+			IsIllegal: true, Severity: models.SeverityCritical,
+		}})
+		eb.Publish(constants.DetectorTopic, eventbus.Event{Payload: "unexpected"})
+
+		Eventually(func() int { return recorder.Report().Detections }).Should(Equal(3))
+		report := recorder.Report()
+		Expect(report.FlaggedCount).To(Equal(2))
+		Expect(report.Handlers).To(ConsistOf("Lark", "Postgres"))
+		Expect(report.ByDetector).To(Equal(map[string]int{"Safety": 1, "Secrets": 1}))
+		Expect(report.BySeverity).To(Equal(map[string]int{
+			models.SeverityHigh: 1, models.SeverityCritical: 1,
+		}))
+		Expect(report.ByNamespace).To(Equal(map[string]int{"ns-a": 1, "ns-b": 1}))
+		Expect(report.Flagged).To(HaveLen(2))
+		Expect(report.Flagged[0].Host).To(Equal("c.example.com"))
+	})
+
+	It("should cap the kept detections but keep counting", func() {
+		recorder := NewRecorder(nil, 1)
+		for range 3 {
+			recorder.Record(&models.DetectorInfo{DetectorName: "Safety", IsIllegal: true})
+		}
+		report := recorder.Report()
+		Expect(report.FlaggedCount).To(Equal(3))
+		Expect(report.Flagged).To(HaveLen(1))
+	})
+
+	It("should write the report as JSON", func() {
+		recorder := NewRecorder([]string{"Lark"}, 0)
+		recorder.Record(&models.DetectorInfo{DetectorName: "Safety", Host: "a.example.com", IsIllegal: true})
+		path := filepath.Join(GinkgoT().TempDir(), "reports", "simulation.json")
+		Expect(recorder.WriteReport(path)).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		var report RunReport
+		Expect(json.Unmarshal(data, &report)).To(Succeed())
+		Expect(report.FlaggedCount).To(Equal(1))
+		Expect(report.Flagged[0].Host).To(Equal("a.example.com"))
+	})
+})
@@ -15,9 +15,24 @@
 package config
 
 type Config struct {
-	Plugins    []PluginConfig `yaml:"plugins"    json:"plugins"`
-	Logging    LoggingConfig  `yaml:"logging"    json:"logging"`
-	Kubeconfig string         `yaml:"kubeconfig" json:"kubeconfig"`
+	Plugins    []PluginConfig   `yaml:"plugins"    json:"plugins"`
+	Logging    LoggingConfig    `yaml:"logging"    json:"logging"`
+	Kubeconfig string           `yaml:"kubeconfig" json:"kubeconfig"`
+	DryRun     bool             `yaml:"dryRun"     json:"dryRun"`
+	Health     HealthConfig     `yaml:"health"     json:"health"`
+	Enrichment EnrichmentConfig `yaml:"enrichment" json:"enrichment"`
+	// PluginDir holds the executables of external plugins, which are
+	// configured in Plugins like built-in ones; empty disables them
+	PluginDir string `yaml:"pluginDir" json:"pluginDir"`
+	// PluginAPI serves runtime enable, disable and restart of plugins
+	PluginAPI PluginAPIConfig `yaml:"pluginApi" json:"pluginApi"`
+	// ScanPolicy bounds the time a target spends in the pipeline
+	ScanPolicy ScanPolicyConfig `yaml:"scanPolicy" json:"scanPolicy"`
+	// ScanOverrides lets Ingresses and Services override the scan policy of
+	// their targets with annotations
+	ScanOverrides ScanOverridesConfig `yaml:"scanOverrides" json:"scanOverrides"`
+	// Debug serves pprof profiles and runtime statistics
+	Debug DebugConfig `yaml:"debug" json:"debug"`
 }
 
 type PluginConfig struct {
@@ -25,10 +40,107 @@ type PluginConfig struct {
 	Type     string `yaml:"type"     json:"type"`
 	Enabled  bool   `yaml:"enabled"  json:"enabled"`
 	Settings string `yaml:"settings" json:"settings"`
+	// Filter selects the events delivered to the subscriptions of the plugin
+	Filter FilterConfig `yaml:"filter" json:"filter"`
+	// Group is the event bus consumer group the subscriptions of the plugin
+	// join; plugins of the same group share the events of their topics
+	Group string `yaml:"group" json:"group,omitempty"`
+
+	// DryRun is set by the plugin manager when the application runs in
+	// simulation mode; plugins must not cause side effects outside CompliK
+	DryRun bool `yaml:"-" json:"-"`
+}
+
+// FilterConfig holds the include and exclude expressions of the events a
+// plugin receives, see package filter. Events match when the include
+// expression, if any, is true and the exclude expression, if any, is not.
+type FilterConfig struct {
+	Include string `yaml:"include" json:"include,omitempty"`
+	Exclude string `yaml:"exclude" json:"exclude,omitempty"`
+}
+
+// PluginAPIConfig configures the plugin management API
+type PluginAPIConfig struct {
+	// Addr enables the API when set, e.g. ":8093"
+	Addr string `yaml:"addr" json:"addr"`
+	// Token is the bearer token required by the API; supports ${ENV} and
+	// secret references
+	Token string `yaml:"token" json:"token"`
+	// StatePath persists the plugins enabled or disabled at runtime, which
+	// take precedence over the enabled flags of the configuration
+	StatePath string `yaml:"statePath" json:"statePath"`
+}
+
+// HealthConfig configures the /healthz and /readyz probe server
+type HealthConfig struct {
+	// Addr defaults to DefaultHealthAddr
+	Addr     string `yaml:"addr"     json:"addr"`
+	Disabled bool   `yaml:"disabled" json:"disabled"`
+}
+
+// DebugConfig configures the pprof and runtime debug endpoints, served only
+// when Addr is set
+type DebugConfig struct {
+	// Addr such as "127.0.0.1:6060"; addresses beyond the loopback interface
+	// require a token
+	Addr string `yaml:"addr" json:"addr"`
+	// Token is required as bearer token, it may be an ${ENV} or other secret
+	// reference
+	Token string `yaml:"token" json:"token"`
+}
+
+// DefaultHealthAddr is the container port exposed by the deployment manifests
+const DefaultHealthAddr = ":8428"
+
+// EnrichmentConfig configures how flagged results are resolved to the workload and
+// tenant behind them
+type EnrichmentConfig struct {
+	Disabled bool `yaml:"disabled" json:"disabled"`
+	// UserKeys and TeamKeys are the annotation or label keys holding the owner,
+	// looked up on the workload first and on its namespace second
+	UserKeys       []string `yaml:"userKeys"       json:"userKeys"`
+	TeamKeys       []string `yaml:"teamKeys"       json:"teamKeys"`
+	CacheTTLSecond int      `yaml:"cacheTTLSecond" json:"cacheTTLSecond"`
+	TimeoutSecond  int      `yaml:"timeoutSecond"  json:"timeoutSecond"`
+}
+
+// ScanPolicyConfig is the deadline of a target, counted from the start of its
+// collection. The collection ends early enough to leave DetectionSecond and
+// HandlingSecond, the detection to leave HandlingSecond. DeadlineSecond 0
+// disables deadlines, only the timeouts of the plugins apply.
+type ScanPolicyConfig struct {
+	DeadlineSecond  int `yaml:"deadlineSecond"  json:"deadlineSecond"`
+	DetectionSecond int `yaml:"detectionSecond" json:"detectionSecond"`
+	HandlingSecond  int `yaml:"handlingSecond"  json:"handlingSecond"`
+}
+
+// ScanOverridesConfig selects the namespaces whose annotations override the
+// scan policy, tenants own the annotations of their resources so none are
+// honored unless Enabled
+type ScanOverridesConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Namespaces are path.Match patterns of the honored namespaces, every
+	// namespace is honored when empty
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
 }
 
 type LoggingConfig struct {
 	Level string `yaml:"level" json:"level"`
+	// Format is "text" (default) or "json"
+	Format string `yaml:"format" json:"format"`
+	// Plugins sets the level of single plugins, e.g. {"Deployment": "debug"}
+	Plugins  map[string]string `yaml:"plugins"  json:"plugins"`
+	Sampling LogSamplingConfig `yaml:"sampling" json:"sampling"`
+}
+
+// LogSamplingConfig limits repeated debug lines of the informer plugins: per
+// tick the first Initial lines with the same message are logged, then every
+// Thereafter-th one
+type LogSamplingConfig struct {
+	Disabled   bool `yaml:"disabled"   json:"disabled"`
+	Initial    int  `yaml:"initial"    json:"initial"`
+	Thereafter int  `yaml:"thereafter" json:"thereafter"`
+	TickSecond int  `yaml:"tickSecond" json:"tickSecond"`
 }
 
 type ClusterConfig struct {
@@ -0,0 +1,456 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// envelopePrefix marks values encrypted with a data key wrapped by a
+	// master key: v2:<key id>:<wrapped data key>:<nonce and ciphertext>
+	envelopePrefix = "v2:"
+	// DefaultKeyID is the ID of the COMPLIK_ENCRYPTION_KEY master key, which
+	// also decrypts values written before key IDs were introduced
+	DefaultKeyID = "default"
+	// VaultTransitScheme selects a master key held by the Vault Transit
+	// secrets engine: vault-transit://[mount/]key
+	VaultTransitScheme = "vault-transit://"
+
+	dataKeySize = 32
+)
+
+var (
+	keyIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	encryptedPattern = regexp.MustCompile(`ENC\(([^()\s]*)\)`)
+)
+
+// MasterKey wraps and unwraps the data keys of envelope encrypted values.
+// Implementations backed by a KMS never expose the key material.
+type MasterKey interface {
+	ID() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// KeyProvider creates the master key with the given ID from a key reference
+// without its scheme prefix
+type KeyProvider func(id, ref string) (MasterKey, error)
+
+var (
+	keyProvidersMu sync.RWMutex
+	keyProviders   = map[string]KeyProvider{
+		VaultTransitScheme: func(id, ref string) (MasterKey, error) {
+			return NewVaultTransitKey(id, ref, nil)
+		},
+	}
+)
+
+// RegisterKeyProvider registers p for master key references starting with
+// scheme, so keys of other KMS can be used in COMPLIK_ENCRYPTION_KEYS
+func RegisterKeyProvider(scheme string, p KeyProvider) {
+	keyProvidersMu.Lock()
+	defer keyProvidersMu.Unlock()
+	keyProviders[scheme] = p
+}
+
+// Keyring holds the master keys that values can be decrypted with. New values
+// are encrypted with the primary key.
+type Keyring struct {
+	primary string
+	keys    map[string]MasterKey
+}
+
+// NewKeyring returns a keyring whose primary key is the first of keys
+func NewKeyring(keys ...MasterKey) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring needs at least one master key")
+	}
+	ring := &Keyring{primary: keys[0].ID(), keys: make(map[string]MasterKey, len(keys))}
+	for _, key := range keys {
+		if !keyIDPattern.MatchString(key.ID()) {
+			return nil, fmt.Errorf("invalid master key id %q", key.ID())
+		}
+		if _, ok := ring.keys[key.ID()]; ok {
+			return nil, fmt.Errorf("duplicate master key id %q", key.ID())
+		}
+		ring.keys[key.ID()] = key
+	}
+	return ring, nil
+}
+
+// PrimaryKeyID returns the ID of the key new values are encrypted with
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Encrypt encrypts plaintext with a fresh data key wrapped by the primary
+// key. The result is the content of an ENC(...) value.
+func (k *Keyring) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := k.keys[k.primary].Wrap(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key with %s: %w", k.primary, err)
+	}
+	return envelopePrefix + k.primary + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the content of an ENC(...) value. Values without a key ID
+// are decrypted with the default key.
+func (k *Keyring) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	keyID, wrapped, sealed, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	key, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("master key %s is not configured", keyID)
+	}
+	dataKey := wrapped
+	if wrapped != nil {
+		if dataKey, err = key.Unwrap(ctx, wrapped); err != nil {
+			return "", fmt.Errorf("failed to unwrap data key with %s: %w", keyID, err)
+		}
+	} else if local, ok := key.(*LocalKey); ok {
+		dataKey = local.key
+	} else {
+		return "", fmt.Errorf("master key %s cannot decrypt values without a key id", keyID)
+	}
+	plaintext, err := open(dataKey, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Reencrypt encrypts the content of an ENC(...) value with the primary key.
+// It returns false and the value unchanged when it already is.
+func (k *Keyring) Reencrypt(ctx context.Context, ciphertext string) (string, bool, error) {
+	keyID, wrapped, _, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	if wrapped != nil && keyID == k.primary {
+		return ciphertext, false, nil
+	}
+	plaintext, err := k.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	rotated, err := k.Encrypt(ctx, plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return rotated, true, nil
+}
+
+// ReencryptText rotates every ENC(...) value in text, such as a configuration
+// file or a database column, to the primary key. It returns the number of
+// values that changed.
+func (k *Keyring) ReencryptText(ctx context.Context, text string) (string, int, error) {
+	var (
+		changed  int
+		firstErr error
+	)
+	result := encryptedPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		rotated, ok, err := k.Reencrypt(ctx, encryptedPattern.FindStringSubmatch(match)[1])
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		if !ok {
+			return match
+		}
+		changed++
+		return "ENC(" + rotated + ")"
+	})
+	if firstErr != nil {
+		return "", 0, firstErr
+	}
+	return result, changed, nil
+}
+
+// parseEnvelope splits an encrypted value. The wrapped key is nil for values
+// without a key ID, which are sealed with the default key directly.
+func parseEnvelope(value string) (string, []byte, []byte, error) {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		sealed, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("invalid encrypted value: %w", err)
+		}
+		return DefaultKeyID, nil, sealed, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, errors.New("invalid encrypted value, expected v2:key:data-key:ciphertext")
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(wrapped) == 0 {
+		return "", nil, nil, errors.New("invalid wrapped data key")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+// seal encrypts plaintext with AES-256-GCM and prepends the nonce
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt value, wrong key or corrupted ciphertext")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// LocalKey is a 256-bit master key held in the configuration
+type LocalKey struct {
+	id  string
+	key []byte
+}
+
+func NewLocalKey(id string, key []byte) (*LocalKey, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("master key %s must be %d bytes, got %d", id, dataKeySize, len(key))
+	}
+	return &LocalKey{id: id, key: key}, nil
+}
+
+func (k *LocalKey) ID() string { return k.id }
+
+func (k *LocalKey) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	return seal(k.key, dataKey)
+}
+
+func (k *LocalKey) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	return open(k.key, wrapped)
+}
+
+// VaultTransitKey wraps data keys with a key of the Vault Transit secrets
+// engine, which never leaves Vault. It authenticates like the VaultResolver.
+type VaultTransitKey struct {
+	id       string
+	mount    string
+	name     string
+	resolver *VaultResolver
+}
+
+// NewVaultTransitKey returns the master key for ref, [mount/]key with the
+// mount defaulting to transit. A nil resolver uses the VAULT_* environment.
+func NewVaultTransitKey(id, ref string, resolver *VaultResolver) (*VaultTransitKey, error) {
+	mount, name := "transit", strings.Trim(ref, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		mount, name = name[:i], name[i+1:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid vault transit key %q, expected [mount/]key", ref)
+	}
+	if resolver == nil {
+		resolver = &VaultResolver{}
+	}
+	return &VaultTransitKey{id: id, mount: mount, name: name, resolver: resolver}, nil
+}
+
+func (k *VaultTransitKey) ID() string { return k.id }
+
+func (k *VaultTransitKey) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	data, err := k.call(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, _ := data["ciphertext"].(string)
+	if ciphertext == "" {
+		return nil, errors.New("vault transit returned no ciphertext")
+	}
+	return []byte(ciphertext), nil
+}
+
+func (k *VaultTransitKey) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	data, err := k.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)})
+	if err != nil {
+		return nil, err
+	}
+	plaintext, _ := data["plaintext"].(string)
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+func (k *VaultTransitKey) call(ctx context.Context, operation string, payload map[string]string) (map[string]any, error) {
+	r := k.resolver
+	address := firstNonEmpty(r.Address, os.Getenv("VAULT_ADDR"))
+	if address == "" {
+		return nil, errors.New("vault address is not configured")
+	}
+	url := strings.TrimSuffix(address, "/") + "/v1/" + k.mount + "/" + operation + "/" + k.name
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.token(ctx, address, false)
+	if err != nil {
+		return nil, err
+	}
+	data, status, err := k.post(ctx, url, token, body)
+	if status == http.StatusForbidden && r.usesLogin() {
+		if token, err = r.token(ctx, address, true); err != nil {
+			return nil, err
+		}
+		data, _, err = k.post(ctx, url, token, body)
+	}
+	return data, err
+}
+
+func (k *VaultTransitKey) post(ctx context.Context, url, token string, body []byte) (map[string]any, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := firstNonEmpty(k.resolver.Namespace, os.Getenv("VAULT_NAMESPACE")); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := k.resolver.client().Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault transit request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("vault transit returned status %d", resp.StatusCode)
+	}
+	var result struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to decode vault transit response: %w", err)
+	}
+	return result.Data, resp.StatusCode, nil
+}
+
+// DefaultKeyring builds the keyring from COMPLIK_ENCRYPTION_KEYS, a comma
+// separated list of id=key entries whose first entry is the primary key. A
+// key is a vault-transit:// reference, a reference of a registered key
+// provider, or the base64 encoding of 32 bytes given directly or through any
+// value GetSecureValue resolves. Unless the list defines the default key,
+// COMPLIK_ENCRYPTION_KEY is added as the default key for decryption.
+func DefaultKeyring() (*Keyring, error) {
+	var keys []MasterKey
+	hasDefault := false
+	for _, entry := range strings.Split(os.Getenv("COMPLIK_ENCRYPTION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, spec, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || spec == "" {
+			return nil, fmt.Errorf("invalid COMPLIK_ENCRYPTION_KEYS entry %q, expected id=key", entry)
+		}
+		key, err := parseMasterKey(strings.TrimSpace(id), strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		hasDefault = hasDefault || key.ID() == DefaultKeyID
+		keys = append(keys, key)
+	}
+	if !hasDefault {
+		key, err := NewLocalKey(DefaultKeyID, getEncryptionKey())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(keys...)
+}
+
+func parseMasterKey(id, spec string) (MasterKey, error) {
+	keyProvidersMu.RLock()
+	for scheme, provider := range keyProviders {
+		if strings.HasPrefix(spec, scheme) {
+			keyProvidersMu.RUnlock()
+			return provider(id, strings.TrimPrefix(spec, scheme))
+		}
+	}
+	keyProvidersMu.RUnlock()
+
+	if strings.HasPrefix(spec, "ENC(") {
+		return nil, fmt.Errorf("master key %s cannot be an encrypted value", id)
+	}
+	value, err := GetSecureValue(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve master key %s: %w", id, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("master key %s is not base64 encoded: %w", id, err)
+	}
+	return NewLocalKey(id, key)
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func testKey(id string, fill byte) *LocalKey {
+	key, err := NewLocalKey(id, bytes.Repeat([]byte{fill}, 32))
+	Expect(err).NotTo(HaveOccurred())
+	return key
+}
+
+func keySpec(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))
+}
+
+var _ = Describe("Keyring", func() {
+	ctx := context.Background()
+
+	It("should encrypt with the primary key and decrypt with any key", func() {
+		oldRing, err := NewKeyring(testKey("k1", 1))
+		Expect(err).NotTo(HaveOccurred())
+		encrypted, err := oldRing.Encrypt(ctx, "s3cret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encrypted).To(HavePrefix("v2:k1:"))
+
+		ring, err := NewKeyring(testKey("k2", 2), testKey("k1", 1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ring.Decrypt(ctx, encrypted)).To(Equal("s3cret"))
+
+		rotated, changed, err := ring.Reencrypt(ctx, encrypted)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(rotated).To(HavePrefix("v2:k2:"))
+		Expect(ring.Decrypt(ctx, rotated)).To(Equal("s3cret"))
+
+		_, changed, err = ring.Reencrypt(ctx, rotated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		newRing, err := NewKeyring(testKey("k2", 2))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = newRing.Decrypt(ctx, encrypted)
+		Expect(err).To(MatchError(ContainSubstring("master key k1 is not configured")))
+	})
+
+	It("should reject tampered values and invalid keys", func() {
+		ring, err := NewKeyring(testKey("k1", 1))
+		Expect(err).NotTo(HaveOccurred())
+		encrypted, err := ring.Encrypt(ctx, "s3cret")
+		Expect(err).NotTo(HaveOccurred())
+
+		parts := strings.Split(encrypted, ":")
+		sealed, _ := base64.StdEncoding.DecodeString(parts[3])
+		sealed[len(sealed)-1] ^= 1
+		parts[3] = base64.StdEncoding.EncodeToString(sealed)
+		_, err = ring.Decrypt(ctx, strings.Join(parts, ":"))
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewLocalKey("short", []byte("short"))
+		Expect(err).To(HaveOccurred())
+		_, err = NewKeyring(testKey("k1", 1), testKey("k1", 2))
+		Expect(err).To(MatchError(ContainSubstring("duplicate")))
+		_, err = NewKeyring(testKey("bad:id", 1))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should rotate the values embedded in text", func() {
+		ring, err := NewKeyring(testKey("k1", 1))
+		Expect(err).NotTo(HaveOccurred())
+		first, _ := ring.Encrypt(ctx, "one")
+		second, _ := ring.Encrypt(ctx, "two")
+		text := "password: ENC(" + first + ")\nsettings: '{\"token\": \"ENC(" + second + ")\"}'\nplain: value\n"
+
+		ring, err = NewKeyring(testKey("k2", 2), testKey("k1", 1))
+		Expect(err).NotTo(HaveOccurred())
+		rotated, changed, err := ring.ReencryptText(ctx, text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal(2))
+		Expect(rotated).To(ContainSubstring("plain: value"))
+		Expect(strings.Count(rotated, "ENC(v2:k2:")).To(Equal(2))
+
+		_, changed, err = ring.ReencryptText(ctx, rotated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeZero())
+	})
+
+	It("should wrap data keys with Vault Transit", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			Expect(r.Header.Get("X-Vault-Token")).To(Equal("root"))
+			// The fake engine "encrypts" by prefixing the plaintext
+			switch r.URL.Path {
+			case "/v1/kms/encrypt/complik":
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+					"ciphertext": "vault:v1:" + body["plaintext"],
+				}})
+			case "/v1/kms/decrypt/complik":
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+					"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:"),
+				}})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		key, err := NewVaultTransitKey("kms", "kms/complik", &VaultResolver{Address: server.URL, Token: "root"})
+		Expect(err).NotTo(HaveOccurred())
+		ring, err := NewKeyring(key)
+		Expect(err).NotTo(HaveOccurred())
+		encrypted, err := ring.Encrypt(ctx, "s3cret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ring.Decrypt(ctx, encrypted)).To(Equal("s3cret"))
+	})
+})
+
+var _ = Describe("DefaultKeyring", func() {
+	It("should read the keys from the environment", func() {
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEY", "")
+		GinkgoT().Setenv("COMPLIK_TEST_MASTER_KEY", keySpec(2))
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k2=${COMPLIK_TEST_MASTER_KEY}, k1="+keySpec(1))
+
+		ring, err := DefaultKeyring()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ring.PrimaryKeyID()).To(Equal("k2"))
+		Expect(ring.keys).To(HaveKey(DefaultKeyID))
+
+		encrypted, err := EncryptValue("s3cret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encrypted).To(HavePrefix("v2:k2:"))
+		Expect(GetSecureValue("ENC(" + encrypted + ")")).To(Equal("s3cret"))
+	})
+
+	It("should decrypt values without a key id with the default key", func() {
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "")
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEY", strings.Repeat("x", 32))
+		sealed, err := seal([]byte(strings.Repeat("x", 32)), []byte("legacy"))
+		Expect(err).NotTo(HaveOccurred())
+		legacy := base64.StdEncoding.EncodeToString(sealed)
+		Expect(DecryptValue(legacy)).To(Equal("legacy"))
+
+		GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", "k1="+keySpec(1))
+		ring, err := DefaultKeyring()
+		Expect(err).NotTo(HaveOccurred())
+		rotated, changed, err := ring.Reencrypt(context.Background(), legacy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(DecryptValue(rotated)).To(Equal("legacy"))
+	})
+
+	It("should reject invalid entries", func() {
+		for _, keys := range []string{"k1", "k1=not-base64!", "k1=" + base64.StdEncoding.EncodeToString([]byte("short"))} {
+			GinkgoT().Setenv("COMPLIK_ENCRYPTION_KEYS", keys)
+			_, err := DefaultKeyring()
+			Expect(err).To(HaveOccurred(), keys)
+		}
+	})
+})
@@ -17,19 +17,21 @@ package config
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"gopkg.in/yaml.v3"
 )
 
+// LoadConfig reads the configuration at configPath. A configuration with an
+// extends key is an overlay, e.g. of a region, and is merged onto the
+// configuration it extends.
 func LoadConfig(configPath string) (*Config, error) {
 	cfg := &Config{}
 	if configPath == "" {
 		return nil, errors.New("config path is required")
 	}
-	data, err := os.ReadFile(configPath)
+	data, err := readLayered(configPath, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -0,0 +1,187 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxExtendsDepth bounds the chain of configuration files extending each other
+const maxExtendsDepth = 8
+
+// readLayered reads the configuration file at path. A file with an extends
+// key is an overlay of the file it names, relative to its own directory: the
+// overlay is merged onto the resolved base and the merged document returned.
+// Files without extends are returned as they are.
+func readLayered(path string, chain []string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var overlay map[string]any
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	extends, _ := overlay["extends"].(string)
+	if extends == "" {
+		return data, nil
+	}
+	delete(overlay, "extends")
+
+	if len(chain) == maxExtendsDepth {
+		return nil, fmt.Errorf("config file %s extends more than %d files", path, maxExtendsDepth)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file %s: %w", path, err)
+	}
+	for _, seen := range chain {
+		if seen == absPath {
+			return nil, fmt.Errorf("config file %s extends itself: %s", path, strings.Join(append(chain, absPath), " -> "))
+		}
+	}
+	if !filepath.IsAbs(extends) {
+		extends = filepath.Join(filepath.Dir(path), extends)
+	}
+	baseData, err := readLayered(extends, append(chain, absPath))
+	if err != nil {
+		return nil, err
+	}
+	var base map[string]any
+	if err := yaml.Unmarshal(baseData, &base); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", extends, err)
+	}
+	merged, err := mergeConfig(base, overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply config overlay %s: %w", path, err)
+	}
+	return yaml.Marshal(merged)
+}
+
+// mergeConfig merges overlay onto base. Mappings are merged key by key and a
+// null value removes the key, plugins are merged by name, other values and
+// lists replace those of base.
+func mergeConfig(base, overlay map[string]any) (map[string]any, error) {
+	if base == nil {
+		base = make(map[string]any)
+	}
+	for key, value := range overlay {
+		if key == "plugins" {
+			plugins, err := mergePlugins(base[key], value)
+			if err != nil {
+				return nil, err
+			}
+			base[key] = plugins
+			continue
+		}
+		base[key] = mergePatch(base[key], value)
+	}
+	return base, nil
+}
+
+// mergePlugins merges the plugins of overlay onto those of base with the same
+// name; plugins only configured in overlay are appended
+func mergePlugins(base, overlay any) ([]any, error) {
+	basePlugins, _ := base.([]any)
+	overlayPlugins, ok := overlay.([]any)
+	if !ok {
+		return nil, errors.New("plugins must be a list")
+	}
+	byName := make(map[string]map[string]any, len(basePlugins))
+	for _, plugin := range basePlugins {
+		if plugin, ok := plugin.(map[string]any); ok {
+			if name, _ := plugin["name"].(string); name != "" {
+				byName[name] = plugin
+			}
+		}
+	}
+	for _, entry := range overlayPlugins {
+		plugin, ok := entry.(map[string]any)
+		if !ok {
+			return nil, errors.New("plugins must be mappings")
+		}
+		name, _ := plugin["name"].(string)
+		if name == "" {
+			return nil, errors.New("plugins of an overlay need a name")
+		}
+		target, ok := byName[name]
+		if !ok {
+			target = map[string]any{}
+			byName[name] = target
+			basePlugins = append(basePlugins, target)
+		}
+		for key, value := range plugin {
+			if key != "settings" {
+				target[key] = mergePatch(target[key], value)
+				continue
+			}
+			settings, err := mergeSettings(target[key], value)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", name, err)
+			}
+			target[key] = settings
+		}
+	}
+	return basePlugins, nil
+}
+
+// mergeSettings merges the settings of an overlay plugin, a JSON string or a
+// mapping, onto the JSON settings of the base plugin
+func mergeSettings(base, overlay any) (string, error) {
+	patch := overlay
+	if text, ok := overlay.(string); ok {
+		if err := json.Unmarshal([]byte(text), &patch); err != nil {
+			return "", fmt.Errorf("invalid settings: %w", err)
+		}
+	}
+	var current any
+	if text, ok := base.(string); ok && strings.TrimSpace(text) != "" {
+		if err := json.Unmarshal([]byte(text), &current); err != nil {
+			return "", fmt.Errorf("invalid base settings: %w", err)
+		}
+	}
+	data, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		return "", fmt.Errorf("invalid settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// mergePatch applies patch to target like a JSON merge patch (RFC 7386)
+func mergePatch(target, patch any) any {
+	patchMap, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetMap, ok := target.(map[string]any)
+	if !ok {
+		targetMap = make(map[string]any, len(patchMap))
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+		targetMap[key] = mergePatch(targetMap[key], value)
+	}
+	return targetMap
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config overlays", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+	settings := func(cfg *Config, name string) map[string]any {
+		for _, plugin := range cfg.Plugins {
+			if plugin.Name == name {
+				var parsed map[string]any
+				Expect(json.Unmarshal([]byte(plugin.Settings), &parsed)).To(Succeed())
+				return parsed
+			}
+		}
+		Fail("plugin " + name + " is not configured")
+		return nil
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		write("base.yml", `
+logging:
+  level: info
+  plugins:
+    Deployment: debug
+plugins:
+  - name: "Lark"
+    type: "Handle"
+    enabled: true
+    settings: |
+      {
+        "webhook": "https://open.feishu.cn/hook/base",
+        "region": "UNKNOWN",
+        "routes": [{"name": "oncall", "webhook": "https://open.feishu.cn/hook/oncall"}]
+      }
+  - name: "Postgres"
+    type: "Handle"
+    enabled: true
+    settings: '{"host": "db.base", "port": "3306", "labelApiAddr": ":8091"}'
+  - name: "Safety"
+    type: "Detector"
+    enabled: false
+    settings: '{}'
+`)
+	})
+
+	It("should load configurations without extends as before", func() {
+		cfg, err := LoadConfig(filepath.Join(dir, "base.yml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Plugins).To(HaveLen(3))
+		Expect(cfg.Plugins[1].Settings).To(Equal(`{"host": "db.base", "port": "3306", "labelApiAddr": ":8091"}`))
+	})
+
+	It("should merge a region overlay onto the base", func() {
+		path := write("regions/hzh.yml", `
+extends: ../base.yml
+logging:
+  plugins:
+    Deployment: null
+    Lark: warn
+plugins:
+  - name: "Lark"
+    settings: '{"webhook": "https://open.feishu.cn/hook/hzh", "region": "hzh"}'
+  - name: "Postgres"
+    settings:
+      host: db.hzh
+      labelApiAddr: null
+  - name: "Safety"
+    enabled: true
+    settings:
+      prompt: "Review pages of the hzh region"
+  - name: "Syslog"
+    type: "Handle"
+    enabled: true
+    settings: '{"address": "siem.hzh:514"}'
+`)
+		cfg, err := LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Logging.Level).To(Equal("info"))
+		Expect(cfg.Logging.Plugins).To(Equal(map[string]string{"Lark": "warn"}))
+
+		Expect(cfg.Plugins).To(HaveLen(4))
+		Expect(cfg.Plugins[0].Type).To(Equal("Handle"))
+		Expect(cfg.Plugins[0].Enabled).To(BeTrue())
+		lark := settings(cfg, "Lark")
+		Expect(lark).To(HaveKeyWithValue("webhook", "https://open.feishu.cn/hook/hzh"))
+		Expect(lark).To(HaveKeyWithValue("region", "hzh"))
+		Expect(lark["routes"]).To(HaveLen(1))
+
+		Expect(settings(cfg, "Postgres")).To(Equal(map[string]any{"host": "db.hzh", "port": "3306"}))
+		Expect(cfg.Plugins[2].Enabled).To(BeTrue())
+		Expect(settings(cfg, "Safety")).To(HaveKeyWithValue("prompt", "Review pages of the hzh region"))
+		Expect(cfg.Plugins[3].Name).To(Equal("Syslog"))
+	})
+
+	It("should resolve chains of overlays", func() {
+		write("cn.yml", `
+extends: base.yml
+plugins:
+  - name: "Lark"
+    settings: '{"region": "cn"}'
+`)
+		path := write("hzh.yml", `
+extends: cn.yml
+plugins:
+  - name: "Lark"
+    settings: '{"webhook": "https://open.feishu.cn/hook/hzh"}'
+`)
+		cfg, err := LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings(cfg, "Lark")).To(And(
+			HaveKeyWithValue("region", "cn"),
+			HaveKeyWithValue("webhook", "https://open.feishu.cn/hook/hzh"),
+		))
+	})
+
+	It("should reject cycles and invalid overlays", func() {
+		write("a.yml", "extends: b.yml\n")
+		write("b.yml", "extends: a.yml\n")
+		_, err := LoadConfig(filepath.Join(dir, "a.yml"))
+		Expect(err).To(MatchError(ContainSubstring("extends itself")))
+
+		_, err = LoadConfig(write("missing.yml", "extends: nowhere.yml\n"))
+		Expect(err).To(MatchError(ContainSubstring("nowhere.yml")))
+
+		_, err = LoadConfig(write("unnamed.yml", "extends: base.yml\nplugins:\n  - enabled: true\n"))
+		Expect(err).To(MatchError(ContainSubstring("need a name")))
+
+		_, err = LoadConfig(write("broken.yml", "extends: base.yml\nplugins:\n  - name: Lark\n    settings: '{broken'\n"))
+		Expect(err).To(MatchError(ContainSubstring("plugin Lark")))
+	})
+})
@@ -0,0 +1,240 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Secret reference schemes understood by GetSecureValue
+const (
+	SecretKeyRefScheme = "secretkeyref://"
+	VaultScheme        = "vault://"
+)
+
+const (
+	defaultSecretCacheTTL = 5 * time.Minute
+	secretResolveTimeout  = 10 * time.Second
+)
+
+// Resolver resolves a secret reference. The reference is passed without its
+// scheme prefix.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		SecretKeyRefScheme: &SecretKeyRefResolver{},
+		VaultScheme:        &VaultResolver{},
+	}
+
+	secretCache = newSecretCache(secretCacheTTL())
+)
+
+// RegisterResolver registers r for references starting with scheme, replacing
+// any resolver registered for the same scheme
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// IsSecretReference reports whether value refers to an external secret store
+func IsSecretReference(value string) bool {
+	_, _, ok := lookupResolver(value)
+	return ok
+}
+
+func lookupResolver(value string) (Resolver, string, bool) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	for scheme, r := range resolvers {
+		if strings.HasPrefix(value, scheme) {
+			return r, strings.TrimPrefix(value, scheme), true
+		}
+	}
+	return nil, "", false
+}
+
+// resolveReference resolves value through the cache. It returns false when
+// value is not a secret reference.
+func resolveReference(value string) (string, bool, error) {
+	r, ref, ok := lookupResolver(value)
+	if !ok {
+		return "", false, nil
+	}
+	resolved, err := secretCache.get(value, func(ctx context.Context) (string, error) {
+		return r.Resolve(ctx, ref)
+	})
+	return resolved, true, err
+}
+
+// InvalidateSecretCache drops all cached secret values so the next lookup
+// fetches them again
+func InvalidateSecretCache() {
+	secretCache.clear()
+}
+
+// WatchSecureValue calls onChange whenever the secret referenced by value
+// resolves to a different value, checking every interval until ctx is done.
+// Values that are not secret references never change and are not watched.
+func WatchSecureValue(
+	ctx context.Context,
+	value string,
+	interval time.Duration,
+	onChange func(string),
+) {
+	r, ref, ok := lookupResolver(value)
+	if !ok {
+		return
+	}
+	fetch := func(ctx context.Context) (string, error) {
+		return r.Resolve(ctx, ref)
+	}
+	current, _ := secretCache.get(value, fetch)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latest, err := secretCache.refresh(value, fetch)
+			if err != nil || latest == current {
+				continue
+			}
+			current = latest
+			onChange(latest)
+		}
+	}
+}
+
+func secretCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("COMPLIK_SECRET_CACHE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultSecretCacheTTL
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// secretCacheStore keeps resolved secrets for ttl. When a refresh fails the last
+// known value keeps being served, so a secret store outage does not break
+// plugins that already resolved their credentials.
+type secretCacheStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cachedSecret
+}
+
+func newSecretCache(ttl time.Duration) *secretCacheStore {
+	return &secretCacheStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedSecret),
+	}
+}
+
+func (c *secretCacheStore) get(key string, fetch func(context.Context) (string, error)) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+	return c.refresh(key, fetch)
+}
+
+func (c *secretCacheStore) refresh(key string, fetch func(context.Context) (string, error)) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	value, err := fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if stale, ok := c.entries[key]; ok {
+			logger.GetLogger().Warn("Failed to refresh secret, using cached value", logger.Fields{
+				"reference": redactReference(key),
+				"error":     err.Error(),
+			})
+			return stale.value, nil
+		}
+		return "", err
+	}
+	c.entries[key] = cachedSecret{value: value, expiresAt: c.now().Add(c.ttl)}
+	return value, nil
+}
+
+func (c *secretCacheStore) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cachedSecret)
+}
+
+// redactReference drops the field of a reference before it is logged
+func redactReference(ref string) string {
+	ref, _, _ = strings.Cut(ref, "#")
+	return ref
+}
+
+// SecretKeyRefResolver reads keys of Kubernetes Secrets referenced as
+// secretkeyref://namespace/name/key. Client defaults to the shared clientset.
+type SecretKeyRefResolver struct {
+	Client kubernetes.Interface
+}
+
+func (r *SecretKeyRefResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid secret reference %q, expected namespace/name/key", ref)
+	}
+	var client kubernetes.Interface
+	switch {
+	case r.Client != nil:
+		client = r.Client
+	case k8s.ClientSet != nil:
+		client = k8s.ClientSet
+	default:
+		return "", errors.New("kubernetes client is not initialized")
+	}
+
+	secret, err := client.CoreV1().Secrets(parts[0]).Get(ctx, parts[1], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %w", parts[0], parts[1], err)
+	}
+	value, ok := secret.Data[parts[2]]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s", parts[0], parts[1], parts[2])
+	}
+	return string(value), nil
+}
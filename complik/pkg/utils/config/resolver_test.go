@@ -0,0 +1,208 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config Suite")
+}
+
+// stubResolver returns value or err and counts its calls
+type stubResolver struct {
+	mu    sync.Mutex
+	value string
+	err   error
+	calls int
+}
+
+func (s *stubResolver) Resolve(_ context.Context, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.value, s.err
+}
+
+func (s *stubResolver) set(value string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value, s.err = value, err
+}
+
+var _ = Describe("GetSecureValue", func() {
+	AfterEach(func() {
+		InvalidateSecretCache()
+	})
+
+	It("should resolve Kubernetes Secret references", func() {
+		client := fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "complik", Name: "db"},
+			Data:       map[string][]byte{"password": []byte("s3cret")},
+		})
+		RegisterResolver(SecretKeyRefScheme, &SecretKeyRefResolver{Client: client})
+		DeferCleanup(RegisterResolver, SecretKeyRefScheme, &SecretKeyRefResolver{})
+
+		Expect(GetSecureValue("secretkeyref://complik/db/password")).To(Equal("s3cret"))
+		_, err := GetSecureValue("secretkeyref://complik/db/missing")
+		Expect(err).To(HaveOccurred())
+		_, err = GetSecureValue("secretkeyref://complik/db")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should keep plain and environment values working", func() {
+		Expect(GetSecureValue("plain")).To(Equal("plain"))
+		GinkgoT().Setenv("COMPLIK_TEST_SECRET", "from-env")
+		Expect(GetSecureValue("${COMPLIK_TEST_SECRET}")).To(Equal("from-env"))
+		Expect(IsSecretReference("${COMPLIK_TEST_SECRET}")).To(BeFalse())
+		Expect(IsSecretReference("vault://secret/data/db#password")).To(BeTrue())
+	})
+})
+
+var _ = Describe("secretCacheStore", func() {
+	var (
+		cache *secretCacheStore
+		now   time.Time
+		stub  *stubResolver
+		fetch func(context.Context) (string, error)
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		cache = newSecretCache(time.Minute)
+		cache.now = func() time.Time { return now }
+		stub = &stubResolver{value: "v1"}
+		fetch = func(ctx context.Context) (string, error) { return stub.Resolve(ctx, "") }
+	})
+
+	It("should cache values until the TTL expires", func() {
+		Expect(cache.get("key", fetch)).To(Equal("v1"))
+		stub.set("v2", nil)
+		Expect(cache.get("key", fetch)).To(Equal("v1"))
+
+		now = now.Add(2 * time.Minute)
+		Expect(cache.get("key", fetch)).To(Equal("v2"))
+		Expect(stub.calls).To(Equal(2))
+	})
+
+	It("should serve the last value when a refresh fails", func() {
+		Expect(cache.get("key", fetch)).To(Equal("v1"))
+		stub.set("", errors.New("store unavailable"))
+		now = now.Add(2 * time.Minute)
+		Expect(cache.get("key", fetch)).To(Equal("v1"))
+
+		stub.set("", errors.New("store unavailable"))
+		_, err := cache.get("other", fetch)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WatchSecureValue", func() {
+	It("should report rotated values", func() {
+		stub := &stubResolver{value: "v1"}
+		RegisterResolver("stub://", stub)
+		DeferCleanup(InvalidateSecretCache)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := make(chan string, 1)
+		go WatchSecureValue(ctx, "stub://token", 10*time.Millisecond, func(value string) {
+			changes <- value
+		})
+
+		Consistently(changes, 50*time.Millisecond).ShouldNot(Receive())
+		stub.set("v2", nil)
+		Eventually(changes).Should(Receive(Equal("v2")))
+	})
+})
+
+var _ = Describe("VaultResolver", func() {
+	var (
+		server *httptest.Server
+		logins int
+	)
+
+	BeforeEach(func() {
+		logins = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/auth/kubernetes/login":
+				logins++
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"auth": map[string]any{"client_token": "login-token"},
+				})
+				return
+			case "/v1/secret/data/complik/db":
+				if r.Header.Get("X-Vault-Token") == "" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+					"data":     map[string]any{"password": "kv2-secret"},
+					"metadata": map[string]any{"version": 3},
+				}})
+			case "/v1/kv/complik/lark":
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+					"webhook": "https://open.feishu.cn/hook/abc",
+				}})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should read KV v2 and KV v1 secrets with a static token", func() {
+		r := &VaultResolver{Address: server.URL, Token: "root"}
+		Expect(r.Resolve(context.Background(), "secret/data/complik/db#password")).To(Equal("kv2-secret"))
+		Expect(r.Resolve(context.Background(), "kv/complik/lark#webhook")).
+			To(Equal("https://open.feishu.cn/hook/abc"))
+
+		_, err := r.Resolve(context.Background(), "secret/data/complik/db#missing")
+		Expect(err).To(HaveOccurred())
+		_, err = r.Resolve(context.Background(), "secret/data/complik/db")
+		Expect(err).To(HaveOccurred())
+		_, err = r.Resolve(context.Background(), "secret/data/unknown#password")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should log in with the Kubernetes auth method without a token", func() {
+		GinkgoT().Setenv("VAULT_TOKEN", "")
+		tokenPath := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenPath, []byte("sa-jwt\n"), 0o600)).To(Succeed())
+
+		r := &VaultResolver{Address: server.URL, Role: "complik", TokenPath: tokenPath}
+		Expect(r.Resolve(context.Background(), "secret/data/complik/db#password")).To(Equal("kv2-secret"))
+		Expect(r.Resolve(context.Background(), "secret/data/complik/db#password")).To(Equal("kv2-secret"))
+		Expect(logins).To(Equal(1))
+	})
+})
@@ -13,24 +13,26 @@
 // limitations under the License.
 
 // Package config provides secure configuration management with support for
-// environment variables and encrypted values.
+// environment variables, encrypted values and references to Kubernetes Secrets
+// and Vault.
 package config
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/base64"
-	"errors"
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 )
 
-// GetSecureValue retrieves a secure configuration value, supporting both
-// environment variable references (${VAR_NAME}) and encrypted values (ENC(...))
+// GetSecureValue retrieves a secure configuration value, supporting environment
+// variable references (${VAR_NAME}), encrypted values (ENC(...)), Kubernetes
+// Secret references (secretkeyref://namespace/name/key) and Vault references
+// (vault://path#field). Secret references are cached for COMPLIK_SECRET_CACHE_TTL.
 func GetSecureValue(value string) (string, error) {
+	if resolved, ok, err := resolveReference(value); ok {
+		return resolved, err
+	}
+
 	// Check if this is an environment variable reference
 	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
 		envVar := strings.TrimSuffix(strings.TrimPrefix(value, "${"), "}")
@@ -51,63 +53,32 @@ func GetSecureValue(value string) (string, error) {
 	return value, nil
 }
 
-// EncryptValue encrypts a configuration value using AES-GCM
+// EncryptValue encrypts a configuration value with a fresh AES-256-GCM data
+// key wrapped by the primary key of DefaultKeyring. The result goes into
+// ENC(...).
 func EncryptValue(plaintext string) (string, error) {
-	key := getEncryptionKey()
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
+	keyring, err := DefaultKeyring()
 	if err != nil {
 		return "", err
 	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	return keyring.Encrypt(ctx, plaintext)
 }
 
-// DecryptValue decrypts a configuration value using AES-GCM
+// DecryptValue decrypts the content of an ENC(...) value with the master key
+// it names, or with the default key for values without a key ID
 func DecryptValue(ciphertext string) (string, error) {
-	key := getEncryptionKey()
-
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	keyring, err := DefaultKeyring()
 	if err != nil {
 		return "", err
 	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
-	}
-
-	// nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	// plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	// if err != nil {
-	// 	return "", err
-	// }
-
-	return "", nil
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	return keyring.Decrypt(ctx, ciphertext)
 }
 
-// getEncryptionKey retrieves the encryption key from environment variables
+// getEncryptionKey retrieves the default master key from environment variables
 func getEncryptionKey() []byte {
 	key := os.Getenv("COMPLIK_ENCRYPTION_KEY")
 	if key == "" {
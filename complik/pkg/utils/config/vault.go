@@ -0,0 +1,194 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultResolver reads fields of Vault secrets referenced as vault://path#field,
+// for example vault://secret/data/complik/db#password. Both KV v1 and KV v2
+// responses are supported. Empty fields fall back to the standard VAULT_ADDR,
+// VAULT_TOKEN and VAULT_NAMESPACE environment variables. Without a token the
+// resolver logs in with the Kubernetes auth method using VAULT_K8S_ROLE.
+type VaultResolver struct {
+	Address    string
+	Token      string
+	Namespace  string
+	Role       string
+	AuthPath   string
+	TokenPath  string
+	HTTPClient *http.Client
+
+	mu         sync.Mutex
+	loginToken string
+}
+
+func (r *VaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field, found := strings.Cut(ref, "#")
+	if !found || path == "" || field == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected path#field", ref)
+	}
+	address := firstNonEmpty(r.Address, os.Getenv("VAULT_ADDR"))
+	if address == "" {
+		return "", errors.New("vault address is not configured")
+	}
+	url := strings.TrimSuffix(address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+
+	token, err := r.token(ctx, address, false)
+	if err != nil {
+		return "", err
+	}
+	data, status, err := r.read(ctx, url, token)
+	if status == http.StatusForbidden && r.usesLogin() {
+		// The login token may have expired; log in again once
+		if token, err = r.token(ctx, address, true); err != nil {
+			return "", err
+		}
+		data, _, err = r.read(ctx, url, token)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (r *VaultResolver) read(ctx context.Context, url, token string) (map[string]any, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := firstNonEmpty(r.Namespace, os.Getenv("VAULT_NAMESPACE")); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	// KV v2 nests the secret under data.data next to data.metadata
+	if inner, ok := body.Data["data"].(map[string]any); ok {
+		if _, hasMetadata := body.Data["metadata"]; hasMetadata {
+			return inner, resp.StatusCode, nil
+		}
+	}
+	return body.Data, resp.StatusCode, nil
+}
+
+func (r *VaultResolver) usesLogin() bool {
+	return firstNonEmpty(r.Token, os.Getenv("VAULT_TOKEN")) == ""
+}
+
+func (r *VaultResolver) token(ctx context.Context, address string, renew bool) (string, error) {
+	if token := firstNonEmpty(r.Token, os.Getenv("VAULT_TOKEN")); token != "" {
+		return token, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loginToken != "" && !renew {
+		return r.loginToken, nil
+	}
+
+	role := firstNonEmpty(r.Role, os.Getenv("VAULT_K8S_ROLE"))
+	if role == "" {
+		return "", errors.New("vault token is not configured and VAULT_K8S_ROLE is not set")
+	}
+	jwt, err := os.ReadFile(firstNonEmpty(r.TokenPath, defaultServiceAccountTokenPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %w", err)
+	}
+	payload, err := json.Marshal(map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", err
+	}
+	authPath := firstNonEmpty(r.AuthPath, os.Getenv("VAULT_K8S_AUTH_PATH"), "kubernetes")
+	url := strings.TrimSuffix(address, "/") + "/v1/auth/" + strings.Trim(authPath, "/") + "/login"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if namespace := firstNonEmpty(r.Namespace, os.Getenv("VAULT_NAMESPACE")); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault login failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault login returned status %d", resp.StatusCode)
+	}
+	var body struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault login response: %w", err)
+	}
+	if body.Auth.ClientToken == "" {
+		return "", errors.New("vault login returned no token")
+	}
+	r.loginToken = body.Auth.ClientToken
+	return r.loginToken, nil
+}
+
+func (r *VaultResolver) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
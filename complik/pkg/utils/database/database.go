@@ -0,0 +1,192 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package database opens the gorm connections shared by the plugins that
+// persist data. MySQL is the default; the embedded SQLite driver lets a single
+// binary run without an external database.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// Supported drivers
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// DefaultSQLitePath is used when the SQLite driver is selected without a path
+const DefaultSQLitePath = "data/complik.db"
+
+// Options selects the database a plugin connects to. Host, Port, Username,
+// Password, DatabaseName and Charset apply to MySQL, SQLitePath to SQLite.
+// A non-empty DSN replaces the MySQL connection string built from the fields.
+type Options struct {
+	Driver       string
+	DSN          string
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	DatabaseName string
+	Charset      string
+	SQLitePath   string
+}
+
+// IsSQLite reports whether the options select the embedded SQLite driver
+func (o Options) IsSQLite() bool {
+	return o.Driver == DriverSQLite
+}
+
+// Validate checks that the options contain what the driver needs
+func (o Options) Validate() error {
+	switch o.Driver {
+	case "", DriverMySQL:
+		if o.DSN != "" {
+			return nil
+		}
+		if o.Host == "" {
+			return errors.New("host configuration cannot be empty")
+		}
+		if o.Port == "" {
+			return errors.New("port configuration cannot be empty")
+		}
+		if o.Username == "" {
+			return errors.New("username configuration cannot be empty")
+		}
+		if o.Password == "" {
+			return errors.New("password configuration cannot be empty")
+		}
+	case DriverSQLite:
+	default:
+		return fmt.Errorf("unsupported database driver %q", o.Driver)
+	}
+	return nil
+}
+
+// Open connects to the configured database. For MySQL the database is
+// created first when it does not exist; for SQLite the file and its directory
+// are created on demand.
+func Open(opts Options) (*gorm.DB, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	dbConfig := &gorm.Config{
+		Logger: gormLogger.New(
+			log.New(os.Stdout, "\r\n", log.LstdFlags),
+			gormLogger.Config{
+				SlowThreshold: 3 * time.Second,  // Slow query threshold set to 3 seconds
+				LogLevel:      gormLogger.Error, // Show only error logs
+				Colorful:      false,            // Disable color output
+			},
+		),
+	}
+	if opts.IsSQLite() {
+		return openSQLite(opts.SQLitePath, dbConfig)
+	}
+	return openMySQL(opts, dbConfig)
+}
+
+func openMySQL(opts Options, dbConfig *gorm.Config) (*gorm.DB, error) {
+	if opts.Charset == "" {
+		opts.Charset = "utf8mb4"
+	}
+	db, err := gorm.Open(mysql.Open(mysqlDSN(opts, false)), dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MySQL server: %w", err)
+	}
+	err = db.Exec(fmt.Sprintf(
+		"CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET %s COLLATE %s_unicode_ci",
+		opts.DatabaseName,
+		opts.Charset,
+		opts.Charset,
+	)).Error
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		_ = sqlDB.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+	db, err = gorm.Open(mysql.Open(mysqlDSN(opts, true)), dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+func mysqlDSN(opts Options, includeDB bool) string {
+	if opts.DSN != "" {
+		return opts.DSN
+	}
+	dbPart := "/"
+	if includeDB {
+		dbPart = "/" + opts.DatabaseName
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)%s?charset=%s&parseTime=True&loc=Local",
+		opts.Username,
+		opts.Password,
+		opts.Host,
+		opts.Port,
+		dbPart,
+		opts.Charset,
+	)
+}
+
+func openSQLite(path string, dbConfig *gorm.Config) (*gorm.DB, error) {
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+		}
+	}
+	// Several plugins may share the file; WAL and a busy timeout let their
+	// connections wait for each other instead of failing with SQLITE_BUSY
+	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQLite connection: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// Ping checks that the database behind db is reachable
+func Ping(ctx context.Context, db *gorm.DB) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
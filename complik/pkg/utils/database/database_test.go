@@ -0,0 +1,91 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestDatabase(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Database Suite")
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+type item struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+var _ = Describe("Database", func() {
+	Describe("Validate", func() {
+		It("should require MySQL connection settings", func() {
+			Expect(Options{}.Validate()).To(MatchError(ContainSubstring("host")))
+			Expect(Options{Driver: DriverMySQL, Host: "db", Port: "3306", Username: "root"}.Validate()).
+				To(MatchError(ContainSubstring("password")))
+			Expect(Options{DSN: "root:pw@tcp(db:3306)/complik"}.Validate()).To(Succeed())
+		})
+
+		It("should not require connection settings for SQLite", func() {
+			Expect(Options{Driver: DriverSQLite}.Validate()).To(Succeed())
+		})
+
+		It("should reject unknown drivers", func() {
+			Expect(Options{Driver: "postgres"}.Validate()).To(MatchError(ContainSubstring("unsupported")))
+		})
+	})
+
+	Describe("Open", func() {
+		It("should create the SQLite file and its directory", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "nested", "complik.db")
+			db, err := Open(Options{Driver: DriverSQLite, SQLitePath: path})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(closeDB, db)
+			Expect(path).To(BeAnExistingFile())
+
+			Expect(db.AutoMigrate(&item{})).To(Succeed())
+			Expect(db.Create(&item{Name: "a"}).Error).To(Succeed())
+			var count int64
+			Expect(db.Model(&item{}).Count(&count).Error).To(Succeed())
+			Expect(count).To(BeEquivalentTo(1))
+		})
+
+		It("should share one SQLite file between connections", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "shared.db")
+			first, err := Open(Options{Driver: DriverSQLite, SQLitePath: path})
+			Expect(err).NotTo(HaveOccurred())
+			second, err := Open(Options{Driver: DriverSQLite, SQLitePath: path})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(closeDB, first)
+			DeferCleanup(closeDB, second)
+
+			Expect(first.AutoMigrate(&item{})).To(Succeed())
+			Expect(first.Create(&item{Name: "a"}).Error).To(Succeed())
+			var names []string
+			Expect(second.Model(&item{}).Pluck("name", &names).Error).To(Succeed())
+			Expect(names).To(Equal([]string{"a"}))
+		})
+	})
+})
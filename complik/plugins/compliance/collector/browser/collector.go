@@ -20,13 +20,20 @@ package browser
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/metadata"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/paths"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/precheck"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/screenshot"
 	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/utils"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/waitfor"
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"golang.org/x/net/context"
@@ -49,7 +56,14 @@ type CollectorInfo struct {
 }
 
 type Collector struct {
-	log logger.Logger
+	log      logger.Logger
+	network  *network.Network
+	precheck *precheck.Checker
+	waitFor  waitfor.Config
+	metadata *metadata.Fetcher
+	maxPaths int
+	// screenshot is the format and size of the screenshots, JPEG by default
+	screenshot screenshot.Config
 }
 
 func NewCollector() *Collector {
@@ -58,6 +72,121 @@ func NewCollector() *Collector {
 	}
 }
 
+// UseNetwork makes the collector scan targets through n
+func (s *Collector) UseNetwork(n *network.Network) {
+	s.network = n
+}
+
+// UseWaitFor makes the collector wait for the readiness rules of cfg after
+// the load event of a page
+func (s *Collector) UseWaitFor(cfg waitfor.Config) {
+	s.waitFor = cfg
+}
+
+// UsePrecheck makes the collector check targets with c before taking a
+// browser from the pool
+func (s *Collector) UsePrecheck(c *precheck.Checker) {
+	s.precheck = c
+}
+
+// UseMetadata makes the collector attach the site metadata gathered by f to
+// the collected pages
+func (s *Collector) UseMetadata(f *metadata.Fetcher) {
+	s.metadata = f
+}
+
+// UseScreenshot makes the collector take screenshots as cfg configures
+func (s *Collector) UseScreenshot(cfg screenshot.Config) {
+	s.screenshot = cfg
+}
+
+// UsePaths makes the collector visit at most max ingress paths per target
+func (s *Collector) UsePaths(max int) {
+	s.maxPaths = max
+}
+
+// Collect collects the page of every ingress path of discovery selected by
+// paths.Select and aggregates them into one result for the target. Paths
+// that fail are recorded as empty pages; the error is returned only when no
+// page could be collected. The timeout of ctx covers all pages. Paths deeper
+// than the MaxDepth override of the target are visited at that depth.
+func (s *Collector) Collect(
+	ctx context.Context,
+	discovery models.DiscoveryInfo,
+	browserPool *utils.BrowserPool,
+	name string,
+	duration time.Duration,
+) (*models.CollectorInfo, error) {
+	depth := 0
+	if discovery.Overrides != nil {
+		depth = discovery.Overrides.MaxDepth
+	}
+	selected := paths.Select(paths.TrimDepth(discovery.Path, depth), s.maxPaths)
+	if len(selected) == 1 || discovery.PodCount == 0 {
+		result, err := s.CollectorAndScreenshot(ctx, visit(discovery, selected[0]), browserPool, name, duration)
+		if result != nil {
+			result.Path = discovery.Path
+			result.Overrides = discovery.Overrides
+		}
+		return result, err
+	}
+
+	var primary *models.CollectorInfo
+	var firstErr error
+	pages := make([]models.PageInfo, 0, len(selected))
+	for _, path := range selected {
+		result, err := s.CollectorAndScreenshot(ctx, visit(discovery, path), browserPool, name, duration)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			s.log.Warn("Failed to collect path", logger.Fields{
+				"error":     err.Error(),
+				"host":      discovery.Host,
+				"path":      path,
+				"namespace": discovery.Namespace,
+				"name":      discovery.Name,
+			})
+			pages = append(pages, models.PageInfo{Path: path, IsEmpty: true, CollectorMessage: err.Error()})
+			continue
+		}
+		if primary == nil || (primary.IsEmpty && !result.IsEmpty) {
+			primary = result
+		}
+		pages = append(pages, models.PageInfo{
+			Path:             path,
+			URL:              result.URL,
+			CollectorMessage: result.CollectorMessage,
+			HTML:             result.HTML,
+			IsEmpty:          result.IsEmpty,
+			Screenshot:       result.Screenshot,
+		})
+	}
+	if primary == nil {
+		return nil, firstErr
+	}
+	aggregated := *primary
+	aggregated.Path = discovery.Path
+	aggregated.Pages = pages
+	aggregated.Overrides = discovery.Overrides
+	s.log.Debug("Collected target paths", logger.Fields{
+		"host":      discovery.Host,
+		"paths":     selected,
+		"namespace": discovery.Namespace,
+		"name":      discovery.Name,
+	})
+	return &aggregated, nil
+}
+
+// visit returns discovery narrowed to a single path
+func visit(discovery models.DiscoveryInfo, path string) models.DiscoveryInfo {
+	discovery.Path = []string{path}
+	return discovery
+}
+
 func (s *Collector) CollectorAndScreenshot(
 	ctx context.Context,
 	discovery models.DiscoveryInfo,
@@ -76,6 +205,7 @@ func (s *Collector) CollectorAndScreenshot(
 		})
 		return &models.CollectorInfo{
 			DiscoveryName: discovery.DiscoveryName,
+			ScanRunID:     discovery.ScanRunID,
 			CollectorName: name,
 			Name:          discovery.Name,
 			Namespace:     discovery.Namespace,
@@ -88,6 +218,55 @@ func (s *Collector) CollectorAndScreenshot(
 		}, nil
 	}
 
+	proxy, err := s.proxyFor(discovery.Namespace)
+	if err != nil {
+		s.log.Warn("Refusing to scan without a proxy", logger.Fields{
+			"error":     err.Error(),
+			"namespace": discovery.Namespace,
+			"name":      discovery.Name,
+		})
+		return nil, err
+	}
+
+	if s.precheck != nil {
+		result := s.precheck.Check(taskCtx, s.formatURL(discovery), proxy)
+		fields := logger.Fields{
+			"url":           result.URL,
+			"method":        result.Method,
+			"status_code":   result.StatusCode,
+			"dns_ms":        result.DNS.Milliseconds(),
+			"connect_ms":    result.Connect.Milliseconds(),
+			"tls_ms":        result.TLS.Milliseconds(),
+			"first_byte_ms": result.FirstByte.Milliseconds(),
+			"total_ms":      result.Total.Milliseconds(),
+			"skipped":       result.Skipped,
+			"namespace":     discovery.Namespace,
+			"name":          discovery.Name,
+		}
+		if err := taskCtx.Err(); err != nil {
+			return nil, err
+		}
+		if result.Err != nil {
+			fields["error"] = result.Err.Error()
+			s.log.Debug("Pre-check failed, skipping browser collection", fields)
+			return &models.CollectorInfo{
+				DiscoveryName:    discovery.DiscoveryName,
+				ScanRunID:        discovery.ScanRunID,
+				CollectorName:    name,
+				Name:             discovery.Name,
+				Namespace:        discovery.Namespace,
+				Host:             discovery.Host,
+				Path:             discovery.Path,
+				URL:              "",
+				HTML:             "",
+				Screenshot:       nil,
+				IsEmpty:          true,
+				CollectorMessage: "precheck: " + result.Err.Error(),
+			}, nil
+		}
+		s.log.Debug("Pre-check passed", fields)
+	}
+
 	// Get browser instance
 	instance, err := browserPool.Get(taskCtx)
 	if err != nil {
@@ -101,13 +280,14 @@ func (s *Collector) CollectorAndScreenshot(
 	defer browserPool.Put(instance)
 
 	// Setup page with proper cleanup tracking
-	page, err := s.setupPage(taskCtx, instance)
+	page, browserContext, err := s.setupPage(taskCtx, browserPool, instance, proxy)
 	if err != nil {
 		s.log.Error("Failed to setup page", logger.Fields{
 			"error":     err.Error(),
 			"namespace": discovery.Namespace,
 			"name":      discovery.Name,
 		})
+		s.disposeContext(instance, browserContext)
 		return nil, err
 	}
 	if page == nil {
@@ -134,9 +314,14 @@ func (s *Collector) CollectorAndScreenshot(
 				})
 			}
 			pageClosed = true
+			s.disposeContext(instance, browserContext)
 		}
 	}
 	defer closePage()
+	if s.network != nil && s.network.RestrictsEgress() {
+		stop := s.restrictEgress(page, discovery)
+		defer stop()
+	}
 	url := s.formatURL(discovery)
 
 	s.log.Debug("Starting page navigation", logger.Fields{
@@ -145,6 +330,14 @@ func (s *Collector) CollectorAndScreenshot(
 		"name":      discovery.Name,
 	})
 
+	// The network activity a rule waits for may start with the navigation
+	rule := s.waitFor.Match(hostname(discovery.Host))
+	var watch *waitfor.Watch
+	if rule != nil {
+		watch = waitfor.Start(taskCtx, page, rule)
+		defer watch.Stop()
+	}
+
 	wait := page.EachEvent(func(e *proto.NetworkResponseReceived) {
 		if e.Type == proto.NetworkResourceTypeDocument && (e.Response.URL == url) {
 			if e.Response.Status == 502 || e.Response.Status == 503 || e.Response.Status == 504 ||
@@ -184,6 +377,7 @@ func (s *Collector) CollectorAndScreenshot(
 			if discovery.PodCount == 0 {
 				return &models.CollectorInfo{
 					DiscoveryName: discovery.DiscoveryName,
+					ScanRunID:     discovery.ScanRunID,
 					CollectorName: name,
 					Name:          discovery.Name,
 					Namespace:     discovery.Namespace,
@@ -207,6 +401,21 @@ func (s *Collector) CollectorAndScreenshot(
 		})
 		return nil, err
 	}
+	if watch != nil {
+		if err := watch.Wait(taskCtx); err != nil {
+			fields := logger.Fields{
+				"error":     err.Error(),
+				"url":       url,
+				"namespace": discovery.Namespace,
+				"name":      discovery.Name,
+			}
+			if taskCtx.Err() != nil || rule.Required {
+				s.log.Error("Page did not become ready", fields)
+				return nil, err
+			}
+			s.log.Warn("Page did not become ready, collecting it as it is", fields)
+		}
+	}
 
 	content, err := page.HTML()
 	if err != nil {
@@ -230,6 +439,7 @@ func (s *Collector) CollectorAndScreenshot(
 		closePage() // Explicitly close page before returning
 		return &models.CollectorInfo{
 			DiscoveryName: discovery.DiscoveryName,
+			ScanRunID:     discovery.ScanRunID,
 			CollectorName: name,
 			Name:          discovery.Name,
 			Namespace:     discovery.Namespace,
@@ -250,6 +460,10 @@ func (s *Collector) CollectorAndScreenshot(
 	} else {
 		duration = 0
 	}
+	var siteMetadata *models.SiteMetadata
+	if s.metadata != nil {
+		siteMetadata = s.metadata.Collect(taskCtx, url, content, proxy)
+	}
 	s.log.Debug("Collection completed", logger.Fields{
 		"url":             url,
 		"html_length":     len(content),
@@ -260,6 +474,7 @@ func (s *Collector) CollectorAndScreenshot(
 	})
 	return &models.CollectorInfo{
 		DiscoveryName: discovery.DiscoveryName,
+		ScanRunID:     discovery.ScanRunID,
 		CollectorName: name,
 		Name:          discovery.Name,
 		Namespace:     discovery.Namespace,
@@ -269,50 +484,107 @@ func (s *Collector) CollectorAndScreenshot(
 		HTML:          content,
 		Screenshot:    screenshot,
 		IsEmpty:       false,
+		Metadata:      siteMetadata,
 	}, nil
 }
 
+// formatURL returns the URL of the page of ingress, including its path when
+// it has a single one
 func (s *Collector) formatURL(ingress models.DiscoveryInfo) string {
+	path := ""
+	if len(ingress.Path) == 1 {
+		path = paths.Literal(ingress.Path[0])
+	}
+	if s.network != nil {
+		if target := s.network.TargetURL(ingress); target != "" {
+			return paths.Join(target, path)
+		}
+	}
 	host := ingress.Host
 	if host == "" {
 		return ""
 	}
 	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
-		return host
+		return paths.Join(host, path)
+	}
+	return paths.Join("http://"+host, path)
+}
+
+// hostname strips the scheme, path and port of an ingress host
+func hostname(host string) string {
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
 	}
-	return "http://" + host
+	return host
 }
 
 func (s *Collector) setupPage(
 	ctx context.Context,
+	browserPool *utils.BrowserPool,
 	instance *utils.BrowserInstance,
-) (*rod.Page, error) {
+	proxy *network.ProxyConfig,
+) (*rod.Page, proto.BrowserBrowserContextID, error) {
 	var page *rod.Page
+	var browserContext proto.BrowserBrowserContextID
 	if instance == nil {
 		s.log.Error("Browser instance is nil")
-		return nil, errors.New("browser instance is nil")
+		return nil, "", errors.New("browser instance is nil")
 	}
 	if instance.Browser == nil {
 		s.log.Error("Browser object is nil", logger.Fields{
 			"pid": instance.PID,
 		})
-		return nil, errors.New("browser object is nil")
+		return nil, "", errors.New("browser object is nil")
 	}
 
 	s.log.Debug("Creating new page", logger.Fields{
 		"pid": instance.PID,
 	})
 
-	err := rod.Try(func() {
-		page = instance.Browser.MustPage().Context(ctx)
-	})
+	browser := instance.Browser
+	if proxy != nil {
+		// Each proxied page gets its own browser context, as Chrome only
+		// configures proxies per context
+		res, err := proto.TargetCreateBrowserContext{
+			ProxyServer:     proxy.Server,
+			ProxyBypassList: proxy.BypassList(),
+		}.Call(instance.Browser)
+		if err != nil {
+			s.log.Error("Failed to create proxied browser context", logger.Fields{
+				"error": err.Error(),
+				"proxy": proxy.Server,
+				"pid":   instance.PID,
+			})
+			return nil, "", fmt.Errorf("failed to create proxied browser context: %w", err)
+		}
+		browserContext = res.BrowserContextID
+		scoped := *instance.Browser
+		scoped.BrowserContextID = browserContext
+		browser = &scoped
+	}
+
+	var err error
+	if browserContext == "" {
+		// Pages of the default context may come from the spare pages
+		page, err = browserPool.Page(instance)
+	} else {
+		err = rod.Try(func() {
+			page = browser.MustPage()
+		})
+	}
 	if err != nil {
 		s.log.Error("Failed to create page", logger.Fields{
 			"error": err.Error(),
 			"pid":   instance.PID,
 		})
-		return nil, fmt.Errorf("failed to create page: %w", err)
+		s.disposeContext(instance, browserContext)
+		return nil, "", fmt.Errorf("failed to create page: %w", err)
 	}
+	page = page.Context(ctx)
 
 	err = page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
 		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36",
@@ -322,7 +594,7 @@ func (s *Collector) setupPage(
 			"error": err.Error(),
 			"pid":   instance.PID,
 		})
-		return nil, err
+		return nil, browserContext, err
 	}
 
 	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
@@ -338,14 +610,60 @@ func (s *Collector) setupPage(
 			"height": 768,
 			"pid":    instance.PID,
 		})
-		return nil, err
+		return nil, browserContext, err
 	}
 
 	s.log.Debug("Page setup completed", logger.Fields{
 		"pid": instance.PID,
 	})
 
-	return page, nil
+	return page, browserContext, nil
+}
+
+// proxyFor returns the proxy of namespace, nil when targets are reached directly
+func (s *Collector) proxyFor(namespace string) (*network.ProxyConfig, error) {
+	if s.network == nil {
+		return nil, nil
+	}
+	return s.network.ProxyFor(namespace)
+}
+
+// disposeContext closes a browser context created for a proxied page
+func (s *Collector) disposeContext(instance *utils.BrowserInstance, browserContext proto.BrowserBrowserContextID) {
+	if browserContext == "" {
+		return
+	}
+	if err := (proto.TargetDisposeBrowserContext{BrowserContextID: browserContext}).Call(instance.Browser); err != nil {
+		s.log.Warn("Failed to dispose browser context", logger.Fields{
+			"error": err.Error(),
+			"pid":   instance.PID,
+		})
+	}
+}
+
+// restrictEgress aborts every request of page to a host outside the egress
+// allowlist and returns the function that stops the interception
+func (s *Collector) restrictEgress(page *rod.Page, discovery models.DiscoveryInfo) func() {
+	router := page.HijackRequests()
+	router.MustAdd("*", func(ctx *rod.Hijack) {
+		requestURL := ctx.Request.URL().String()
+		if s.network.Allowed(requestURL) {
+			ctx.ContinueRequest(&proto.FetchContinueRequest{})
+			return
+		}
+		s.log.Warn("Blocked request outside the egress allowlist", logger.Fields{
+			"url":       requestURL,
+			"namespace": discovery.Namespace,
+			"name":      discovery.Name,
+		})
+		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+	})
+	go router.Run()
+	return func() {
+		if err := router.Stop(); err != nil {
+			s.log.Debug("Failed to stop request interception", logger.Fields{"error": err.Error()})
+		}
+	}
 }
 
 func (s *Collector) waitForPageLoad(ctx context.Context, page *rod.Page) error {
@@ -393,10 +711,7 @@ func (s *Collector) takeScreenshot(ctx context.Context, page *rod.Page) ([]byte,
 	var screenshot []byte
 	var err error
 	if rodErr := rod.Try(func() {
-		screenshot, err = page.Context(ctx).Screenshot(true, &proto.PageCaptureScreenshot{
-			Format:  proto.PageCaptureScreenshotFormatJpeg,
-			Quality: &[]int{75}[0],
-		})
+		screenshot, err = s.screenshot.Capture(ctx, page)
 	}); rodErr != nil {
 		s.log.Error("Critical error during screenshot", logger.Fields{
 			"error": rodErr.Error(),
@@ -0,0 +1,275 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metadata gathers the context of a site next to its page: the
+// title, description and generator meta tags of the page, and the robots.txt
+// and security.txt files of its host. The files are fetched once per host and
+// cached, the way the browser reaches the host.
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+	"golang.org/x/net/html"
+)
+
+// userAgent is the user agent of the browser pages
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36"
+
+// maxTagLength limits the meta tag values, pages stuff them with keywords
+const maxTagLength = 500
+
+// securityTxtPaths are tried in order, the root location is the legacy one
+var securityTxtPaths = []string{"/.well-known/security.txt", "/security.txt"}
+
+// Config is the metadata section of the browser collector settings
+type Config struct {
+	Enabled       *bool `json:"enabled"`
+	TimeoutSecond int   `json:"timeoutSecond"`
+	// CacheMinute is how long the files of a host are reused
+	CacheMinute int `json:"cacheMinute"`
+	// MaxBytes truncates robots.txt and security.txt
+	MaxBytes int `json:"maxBytes"`
+}
+
+// DefaultConfig returns the metadata defaults
+func DefaultConfig() Config {
+	enabled := true
+	return Config{
+		Enabled:       &enabled,
+		TimeoutSecond: 5,
+		CacheMinute:   360,
+		MaxBytes:      4096,
+	}
+}
+
+// Merge overrides the defaults with the set fields of cfg
+func (c Config) Merge(cfg Config) Config {
+	if cfg.Enabled != nil {
+		c.Enabled = cfg.Enabled
+	}
+	if cfg.TimeoutSecond > 0 {
+		c.TimeoutSecond = cfg.TimeoutSecond
+	}
+	if cfg.CacheMinute > 0 {
+		c.CacheMinute = cfg.CacheMinute
+	}
+	if cfg.MaxBytes > 0 {
+		c.MaxBytes = cfg.MaxBytes
+	}
+	return c
+}
+
+// files are the well-known files of a host
+type files struct {
+	robots    string
+	security  string
+	fetchedAt time.Time
+}
+
+// Fetcher gathers site metadata
+type Fetcher struct {
+	timeout  time.Duration
+	ttl      time.Duration
+	maxBytes int64
+	network  *network.Network
+	now      func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]files
+}
+
+// New returns a Fetcher reaching hosts through n, which may be nil
+func New(cfg Config, n *network.Network) *Fetcher {
+	cfg = DefaultConfig().Merge(cfg)
+	return &Fetcher{
+		timeout:  time.Duration(cfg.TimeoutSecond) * time.Second,
+		ttl:      time.Duration(cfg.CacheMinute) * time.Minute,
+		maxBytes: int64(cfg.MaxBytes),
+		network:  n,
+		now:      time.Now,
+		hosts:    make(map[string]files),
+	}
+}
+
+// Collect returns the metadata of the page at pageURL whose HTML is content.
+// The files are fetched through proxy, nil for a direct connection; hosts
+// that cannot be reached leave them empty.
+func (f *Fetcher) Collect(
+	ctx context.Context,
+	pageURL, content string,
+	proxy *network.ProxyConfig,
+) *models.SiteMetadata {
+	metadata := ParseHTML(content)
+	target, err := url.Parse(pageURL)
+	if err != nil || target.Host == "" {
+		return metadata
+	}
+	origin := target.Scheme + "://" + target.Host
+	f.mu.Lock()
+	cached, ok := f.hosts[origin]
+	f.mu.Unlock()
+	if !ok || f.now().Sub(cached.fetchedAt) >= f.ttl {
+		cached = f.fetchFiles(ctx, origin, proxy)
+		// A cancelled collection says nothing about the host
+		if ctx.Err() != nil {
+			return metadata
+		}
+		f.mu.Lock()
+		f.hosts[origin] = cached
+		f.mu.Unlock()
+	}
+	metadata.RobotsTxt = cached.robots
+	metadata.SecurityTxt = cached.security
+	return metadata
+}
+
+func (f *Fetcher) fetchFiles(ctx context.Context, origin string, proxy *network.ProxyConfig) files {
+	result := files{fetchedAt: f.now()}
+	if f.network != nil && !f.network.Allowed(origin) {
+		return result
+	}
+	transport, ok := f.network.Transport(proxy)
+	if !ok {
+		return result
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			if f.network != nil && !f.network.Allowed(req.URL.String()) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	result.robots = f.fetch(ctx, client, origin+"/robots.txt")
+	for _, path := range securityTxtPaths {
+		if result.security = f.fetch(ctx, client, origin+path); result.security != "" {
+			break
+		}
+	}
+	return result
+}
+
+// fetch returns the text file at rawURL, empty when the host does not serve
+// it. Sites answering every path with their index page are told apart by the
+// content type.
+func (f *Fetcher) fetch(ctx context.Context, client *http.Client, rawURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/plain" {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return ""
+	}
+	text := strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+	if strings.HasPrefix(text, "<") {
+		return ""
+	}
+	return text
+}
+
+// ParseHTML returns the title, description and generator of a page
+func ParseHTML(content string) *models.SiteMetadata {
+	metadata := &models.SiteMetadata{}
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return metadata
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = metadata.Title == ""
+			case "meta":
+				var name, value string
+				for _, attr := range token.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "name", "property":
+						name = strings.ToLower(attr.Val)
+					case "content":
+						value = clean(attr.Val)
+					}
+				}
+				switch name {
+				case "description", "og:description":
+					if metadata.Description == "" {
+						metadata.Description = value
+					}
+				case "generator":
+					if metadata.Generator == "" {
+						metadata.Generator = value
+					}
+				}
+			case "body":
+				// The title and meta tags belong to the head
+				return metadata
+			}
+		case html.TextToken:
+			if inTitle {
+				metadata.Title = clean(string(tokenizer.Text()))
+				inTitle = false
+			}
+		case html.EndTagToken:
+			inTitle = false
+		}
+	}
+}
+
+// clean collapses the white space of a tag value and truncates it
+func clean(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if len(value) <= maxTagLength {
+		return value
+	}
+	value = value[:maxTagLength]
+	for !utf8.ValidString(value) {
+		value = value[:len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMetadata(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Metadata Suite")
+}
+
+const page = `<!DOCTYPE html><html><head>
+<meta charset="utf-8">
+<title>
+  Lucky Casino | Online   Slots
+</title>
+<meta name="Description" content="Play baccarat and slots">
+<meta property="og:description" content="Ignored, the description is set">
+<meta name="generator" content="WordPress 6.5">
+</head><body><svg><title>Logo</title></svg><meta name="generator" content="Other"></body></html>`
+
+var _ = Describe("Metadata", func() {
+	ctx := context.Background()
+
+	It("should read the title and meta tags of the head", func() {
+		metadata := ParseHTML(page)
+		Expect(metadata.Title).To(Equal("Lucky Casino | Online Slots"))
+		Expect(metadata.Description).To(Equal("Play baccarat and slots"))
+		Expect(metadata.Generator).To(Equal("WordPress 6.5"))
+		Expect(ParseHTML("")).NotTo(BeNil())
+		Expect(ParseHTML("<title>" + strings.Repeat("赌", 300) + "</title>").Title).To(HaveLen(498))
+	})
+
+	It("should fetch robots.txt and security.txt once per host", func() {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			switch r.URL.Path {
+			case "/robots.txt":
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+			case "/security.txt":
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("Contact: mailto:security@example.com\n"))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		f := New(Config{}, nil)
+		metadata := f.Collect(ctx, server.URL+"/index.html", page, nil)
+		Expect(metadata.Title).To(Equal("Lucky Casino | Online Slots"))
+		Expect(metadata.RobotsTxt).To(Equal("User-agent: *\nDisallow: /admin"))
+		Expect(metadata.SecurityTxt).To(Equal("Contact: mailto:security@example.com"))
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+
+		Expect(f.Collect(ctx, server.URL+"/other", "", nil).RobotsTxt).NotTo(BeEmpty())
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+
+		f.now = func() time.Time { return time.Now().Add(7 * time.Hour) }
+		f.Collect(ctx, server.URL, "", nil)
+		Expect(requests.Load()).To(BeEquivalentTo(6))
+	})
+
+	It("should ignore pages served for every path", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/.well-known/security.txt" {
+				// Served without a content type
+				w.Write([]byte("<!doctype html><html></html>"))
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(page))
+		}))
+		DeferCleanup(server.Close)
+
+		metadata := New(Config{}, nil).Collect(ctx, server.URL, page, nil)
+		Expect(metadata.RobotsTxt).To(BeEmpty())
+		Expect(metadata.SecurityTxt).To(BeEmpty())
+	})
+
+	It("should truncate large files", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("Disallow: /x\n", 1000)))
+		}))
+		DeferCleanup(server.Close)
+
+		metadata := New(Config{MaxBytes: 100}, nil).Collect(ctx, server.URL, "", nil)
+		Expect(len(metadata.RobotsTxt)).To(BeNumerically("<=", 100))
+	})
+
+	It("should not fetch hosts outside the egress allowlist", func() {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		DeferCleanup(server.Close)
+		n, err := network.New(network.Config{Egress: network.EgressConfig{AllowedHosts: []string{"example.com"}}})
+		Expect(err).NotTo(HaveOccurred())
+
+		metadata := New(Config{}, n).Collect(ctx, server.URL, page, nil)
+		Expect(metadata.Title).NotTo(BeEmpty())
+		Expect(metadata.RobotsTxt).To(BeEmpty())
+		Expect(requests.Load()).To(BeZero())
+	})
+})
@@ -0,0 +1,258 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package network decides how the browser collector reaches scan targets in
+// clusters where the public ingress hostnames do not resolve: static DNS
+// overrides, hosts file entries and direct connections to the backend service.
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Defaults of the direct-to-service mode
+const (
+	DefaultClusterDomain = "cluster.local"
+	DefaultServicePort   = 80
+)
+
+// Config is the network section of the browser collector settings
+type Config struct {
+	// DNSOverrides maps hostnames to the IP the browser connects to. A leading
+	// "*." matches every subdomain.
+	DNSOverrides map[string]string `json:"dnsOverrides"`
+	// HostsFile is a file in /etc/hosts format merged into the overrides
+	HostsFile string `json:"hostsFile"`
+	// Hosts are additional lines in /etc/hosts format
+	Hosts []string `json:"hosts"`
+
+	// DirectService scans every target through its backend service instead
+	// of the public host
+	DirectService bool `json:"directService"`
+	// ServiceTargets maps hosts to "namespace/service:port" and applies
+	// whether or not DirectService is set
+	ServiceTargets     map[string]string `json:"serviceTargets"`
+	ClusterDomain      string            `json:"clusterDomain"`
+	DefaultServicePort int               `json:"defaultServicePort"`
+
+	// Proxy is used for every namespace without an entry in NamespaceProxies
+	Proxy *ProxyConfig `json:"proxy"`
+	// NamespaceProxies maps namespaces, or path.Match patterns such as
+	// "ns-team-*", to the proxy their targets are scanned through
+	NamespaceProxies map[string]ProxyConfig `json:"namespaceProxies"`
+	Egress           EgressConfig           `json:"egress"`
+}
+
+// ServiceTarget is a Kubernetes service a scan connects to directly
+type ServiceTarget struct {
+	Namespace string
+	Service   string
+	Port      int
+}
+
+// ParseServiceTarget parses "namespace/service:port"; the port is optional
+func ParseServiceTarget(value string) (ServiceTarget, error) {
+	namespace, rest, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || rest == "" {
+		return ServiceTarget{}, fmt.Errorf("invalid service target %q, expected namespace/service:port", value)
+	}
+	target := ServiceTarget{Namespace: namespace, Service: rest}
+	if service, port, ok := strings.Cut(rest, ":"); ok {
+		number, err := strconv.Atoi(port)
+		if err != nil || number <= 0 || number > 65535 {
+			return ServiceTarget{}, fmt.Errorf("invalid port in service target %q", value)
+		}
+		target.Service = service
+		target.Port = number
+	}
+	if target.Service == "" {
+		return ServiceTarget{}, fmt.Errorf("invalid service target %q, expected namespace/service:port", value)
+	}
+	return target, nil
+}
+
+// URL returns the in-cluster URL of the service
+func (t ServiceTarget) URL(clusterDomain string) string {
+	return fmt.Sprintf("http://%s.%s.svc.%s:%d", t.Service, t.Namespace, clusterDomain, t.Port)
+}
+
+// Network is a validated Config
+type Network struct {
+	overrides     map[string]string
+	rules         string
+	direct        bool
+	targets       map[string]ServiceTarget
+	clusterDomain string
+	defaultPort   int
+	proxies       *proxies
+	egress        *egress
+}
+
+// New validates cfg, reading the hosts file when one is configured
+func New(cfg Config) (*Network, error) {
+	n := &Network{
+		direct:        cfg.DirectService,
+		targets:       make(map[string]ServiceTarget, len(cfg.ServiceTargets)),
+		clusterDomain: cfg.ClusterDomain,
+		defaultPort:   cfg.DefaultServicePort,
+	}
+	if n.clusterDomain == "" {
+		n.clusterDomain = DefaultClusterDomain
+	}
+	if n.defaultPort <= 0 {
+		n.defaultPort = DefaultServicePort
+	}
+	for host, value := range cfg.ServiceTargets {
+		target, err := ParseServiceTarget(value)
+		if err != nil {
+			return nil, fmt.Errorf("service target for %s: %w", host, err)
+		}
+		if target.Port == 0 {
+			target.Port = n.defaultPort
+		}
+		n.targets[host] = target
+	}
+
+	overrides := make(map[string]string)
+	if cfg.HostsFile != "" {
+		file, err := os.Open(cfg.HostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open hosts file: %w", err)
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if err := parseHostsLine(scanner.Text(), overrides); err != nil {
+				return nil, fmt.Errorf("hosts file %s: %w", cfg.HostsFile, err)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read hosts file: %w", err)
+		}
+	}
+	for _, line := range cfg.Hosts {
+		if err := parseHostsLine(line, overrides); err != nil {
+			return nil, err
+		}
+	}
+	for host, ip := range cfg.DNSOverrides {
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("DNS override for %s: invalid IP %q", host, ip)
+		}
+		overrides[host] = ip
+	}
+	n.rules = resolverRules(overrides)
+	n.overrides = make(map[string]string, len(overrides))
+	for host, ip := range overrides {
+		n.overrides[strings.ToLower(host)] = ip
+	}
+
+	var err error
+	if n.proxies, err = newProxies(cfg.Proxy, cfg.NamespaceProxies); err != nil {
+		return nil, err
+	}
+	if n.egress, err = newEgress(cfg.Egress); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// ResolverRules returns the Chrome --host-resolver-rules value of the
+// overrides, empty when there are none
+func (n *Network) ResolverRules() string {
+	return n.rules
+}
+
+// Override returns the IP host is mapped to, ok is false when the host is
+// resolved through DNS. Like the resolver rules, "*." entries only match
+// subdomains.
+func (n *Network) Override(host string) (ip string, ok bool) {
+	host = strings.ToLower(host)
+	if ip, ok := n.overrides[host]; ok {
+		return ip, true
+	}
+	for pattern, ip := range n.overrides {
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return ip, true
+		}
+	}
+	return "", false
+}
+
+// TargetURL returns the URL a discovery is scanned through, empty when the
+// public host is used
+func (n *Network) TargetURL(discovery models.DiscoveryInfo) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(discovery.Host, "https://"), "http://")
+	if target, ok := n.targets[host]; ok {
+		return target.URL(n.clusterDomain)
+	}
+	if !n.direct || discovery.ServiceName == "" || discovery.Namespace == "" {
+		return ""
+	}
+	port := discovery.ServicePort
+	if port <= 0 {
+		port = n.defaultPort
+	}
+	return ServiceTarget{
+		Namespace: discovery.Namespace,
+		Service:   discovery.ServiceName,
+		Port:      port,
+	}.URL(n.clusterDomain)
+}
+
+// parseHostsLine adds the hostnames of an /etc/hosts line to overrides
+func parseHostsLine(line string, overrides map[string]string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("invalid hosts line %q", line)
+	}
+	if net.ParseIP(fields[0]) == nil {
+		return fmt.Errorf("invalid IP %q in hosts line", fields[0])
+	}
+	for _, host := range fields[1:] {
+		overrides[host] = fields[0]
+	}
+	return nil
+}
+
+func resolverRules(overrides map[string]string) string {
+	hosts := make([]string, 0, len(overrides))
+	for host := range overrides {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	rules := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		ip := overrides[host]
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		rules = append(rules, fmt.Sprintf("MAP %s %s", host, ip))
+	}
+	return strings.Join(rules, ", ")
+}
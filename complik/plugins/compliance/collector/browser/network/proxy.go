@@ -0,0 +1,236 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrProxyRequired is returned for namespaces without a proxy when the egress
+// policy requires one
+var ErrProxyRequired = errors.New("egress policy requires a proxy")
+
+// ProxyConfig is an HTTP(S) or SOCKS proxy the browser connects through
+type ProxyConfig struct {
+	// Server is a proxy URL with an http, https, socks4 or socks5 scheme
+	Server string `json:"server"`
+	// Bypass are hosts connected to directly, in Chrome --proxy-bypass-list
+	// syntax, e.g. "*.svc.cluster.local" or "10.0.0.0/8"
+	Bypass []string `json:"bypass"`
+}
+
+// BypassList returns the bypass entries as a Chrome proxy bypass list
+func (c ProxyConfig) BypassList() string {
+	return strings.Join(c.Bypass, ";")
+}
+
+// EgressConfig restricts where the browser may connect to
+type EgressConfig struct {
+	// RequireProxy refuses to scan namespaces that have no proxy configured
+	RequireProxy bool `json:"requireProxy"`
+	// AllowedHosts limits the requests of a scan to these hosts. Entries are
+	// hostnames, "*." subdomain wildcards, IPs or CIDRs; empty allows all.
+	AllowedHosts []string `json:"allowedHosts"`
+}
+
+// Bypasses reports whether host is connected to directly. It understands the
+// hostname, "*." or "." suffix, IP and CIDR entries of the bypass list.
+func (c ProxyConfig) Bypasses(host string) bool {
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	ip := net.ParseIP(host)
+	for _, entry := range c.Bypass {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == host:
+			return true
+		case strings.HasPrefix(entry, "*."), strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, strings.TrimPrefix(entry, "*")) {
+				return true
+			}
+		case strings.Contains(entry, "/") && ip != nil:
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks4": true, "socks5": true}
+
+func (c ProxyConfig) validate() error {
+	u, err := url.Parse(c.Server)
+	if err != nil || u.Host == "" || !proxySchemes[u.Scheme] {
+		return fmt.Errorf("invalid proxy server %q, expected scheme://host:port with http, https, socks4 or socks5", c.Server)
+	}
+	if u.User != nil {
+		return fmt.Errorf("proxy server %q: credentials are not supported, allow the scanner by source address", c.Server)
+	}
+	return nil
+}
+
+type namespaceProxy struct {
+	pattern string
+	proxy   ProxyConfig
+}
+
+type proxies struct {
+	global   *ProxyConfig
+	exact    map[string]ProxyConfig
+	patterns []namespaceProxy
+}
+
+func newProxies(global *ProxyConfig, namespaces map[string]ProxyConfig) (*proxies, error) {
+	p := &proxies{exact: make(map[string]ProxyConfig)}
+	if global != nil && global.Server != "" {
+		if err := global.validate(); err != nil {
+			return nil, err
+		}
+		p.global = global
+	}
+	for namespace, proxy := range namespaces {
+		if err := proxy.validate(); err != nil {
+			return nil, fmt.Errorf("proxy of namespace %s: %w", namespace, err)
+		}
+		if !strings.ContainsAny(namespace, "*?[") {
+			p.exact[namespace] = proxy
+			continue
+		}
+		if _, err := path.Match(namespace, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %w", namespace, err)
+		}
+		p.patterns = append(p.patterns, namespaceProxy{pattern: namespace, proxy: proxy})
+	}
+	// Longer patterns are more specific and win
+	sort.Slice(p.patterns, func(i, j int) bool {
+		if len(p.patterns[i].pattern) != len(p.patterns[j].pattern) {
+			return len(p.patterns[i].pattern) > len(p.patterns[j].pattern)
+		}
+		return p.patterns[i].pattern < p.patterns[j].pattern
+	})
+	return p, nil
+}
+
+func (p *proxies) lookup(namespace string) (ProxyConfig, bool) {
+	if proxy, ok := p.exact[namespace]; ok {
+		return proxy, true
+	}
+	for _, entry := range p.patterns {
+		if ok, _ := path.Match(entry.pattern, namespace); ok {
+			return entry.proxy, true
+		}
+	}
+	if p.global != nil {
+		return *p.global, true
+	}
+	return ProxyConfig{}, false
+}
+
+type egress struct {
+	requireProxy bool
+	hosts        map[string]bool
+	suffixes     []string
+	networks     []*net.IPNet
+}
+
+func newEgress(cfg EgressConfig) (*egress, error) {
+	e := &egress{requireProxy: cfg.RequireProxy, hosts: make(map[string]bool)}
+	for _, entry := range cfg.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			return nil, errors.New("empty egress allowlist entry")
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid egress CIDR %q: %w", entry, err)
+			}
+			e.networks = append(e.networks, network)
+		case strings.HasPrefix(entry, "*."):
+			e.suffixes = append(e.suffixes, entry[1:])
+		default:
+			e.hosts[entry] = true
+		}
+	}
+	return e, nil
+}
+
+func (e *egress) restricted() bool {
+	return len(e.hosts) > 0 || len(e.suffixes) > 0 || len(e.networks) > 0
+}
+
+func (e *egress) allows(host string) bool {
+	if !e.restricted() {
+		return true
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if e.hosts[host] {
+		return true
+	}
+	for _, suffix := range e.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range e.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProxyFor returns the proxy targets of namespace are scanned through. It
+// fails with ErrProxyRequired when there is none and the policy requires one.
+func (n *Network) ProxyFor(namespace string) (*ProxyConfig, error) {
+	proxy, ok := n.proxies.lookup(namespace)
+	if !ok {
+		if n.egress.requireProxy {
+			return nil, fmt.Errorf("%w for namespace %s", ErrProxyRequired, namespace)
+		}
+		return nil, nil
+	}
+	return &proxy, nil
+}
+
+// RestrictsEgress reports whether requests have to be checked with Allowed
+func (n *Network) RestrictsEgress() bool {
+	return n.egress.restricted()
+}
+
+// Allowed reports whether the browser may request rawURL. URLs that do not
+// leave the browser, such as data: and blob:, are always allowed.
+func (n *Network) Allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "data", "blob", "about":
+		return true
+	case "http", "https", "ws", "wss":
+		return n.egress.allows(u.Hostname())
+	default:
+		return false
+	}
+}
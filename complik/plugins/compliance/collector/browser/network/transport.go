@@ -0,0 +1,71 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Transport returns an HTTP transport connecting like the browser: through
+// the DNS overrides and proxy, nil for a direct connection. ok is false when
+// the proxy is not supported by net/http. n may be nil.
+func (n *Network) Transport(proxy *ProxyConfig) (transport *http.Transport, ok bool) {
+	dialer := &net.Dialer{}
+	transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, n.resolve(address))
+		},
+		// Certificate problems are left to the browser, requests made
+		// besides it only need the answer
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		DisableKeepAlives: true,
+		// The custom dialer and TLS config turn HTTP/2 off, but like the
+		// browser the requests must reach servers speaking only HTTP/2
+		ForceAttemptHTTP2: true,
+	}
+	if proxy == nil {
+		return transport, true
+	}
+	proxyURL, err := url.Parse(proxy.Server)
+	if err != nil || proxyURL.Scheme == "socks4" {
+		return nil, false
+	}
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if proxy.Bypasses(req.URL.Hostname()) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+	return transport, true
+}
+
+// resolve replaces the host of address with its DNS override
+func (n *Network) resolve(address string) string {
+	if n == nil {
+		return address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if ip, ok := n.Override(host); ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return address
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paths selects the ingress paths of a scan target the browser
+// collector visits. Ingress paths may be prefixes, wildcards or regular
+// expressions; only their literal part can be opened in a browser.
+package paths
+
+import (
+	"strings"
+)
+
+// DefaultMax is the number of paths visited per target when none is configured
+const DefaultMax = 5
+
+// metaChars start the non-literal part of a wildcard or regular expression path
+const metaChars = `*([{?$|\+^`
+
+// Select returns the distinct paths worth visiting among paths, in their
+// order and at most max of them, DefaultMax when max is not positive. The
+// literal prefix of wildcard and regular expression paths is visited instead
+// of the pattern. "/" is returned for targets without paths.
+func Select(paths []string, max int) []string {
+	if max <= 0 {
+		max = DefaultMax
+	}
+	selected := make([]string, 0, min(len(paths), max))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		path = Literal(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		selected = append(selected, path)
+		if len(selected) == max {
+			break
+		}
+	}
+	if len(selected) == 0 {
+		selected = append(selected, "/")
+	}
+	return selected
+}
+
+// Literal returns the part of an ingress path before its first wildcard or
+// regular expression, always starting with "/"
+func Literal(path string) string {
+	if i := strings.IndexAny(path, metaChars); i >= 0 {
+		// The dot of ".*" matches any character
+		path = strings.TrimRight(path[:i], ".")
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// TrimDepth cuts every path after its first depth segments, leaving paths
+// unchanged when depth is not positive. Paths sharing their first segments
+// are visited once, Select drops the repetitions.
+func TrimDepth(paths []string, depth int) []string {
+	if depth <= 0 {
+		return paths
+	}
+	trimmed := make([]string, 0, len(paths))
+	for _, path := range paths {
+		segments := strings.Split(strings.Trim(Literal(path), "/"), "/")
+		if len(segments) > depth {
+			path = "/" + strings.Join(segments[:depth], "/")
+		}
+		trimmed = append(trimmed, path)
+	}
+	return trimmed
+}
+
+// Join appends path to the base URL of a target. The root path leaves base
+// unchanged, so targets without paths are visited as before.
+func Join(base, path string) string {
+	if base == "" || path == "" || path == "/" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + path
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paths
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPaths(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Paths Suite")
+}
+
+var _ = Describe("Select", func() {
+	It("should visit the root of targets without paths", func() {
+		Expect(Select(nil, 0)).To(Equal([]string{"/"}))
+		Expect(Select([]string{}, 3)).To(Equal([]string{"/"}))
+	})
+
+	It("should keep distinct paths in order", func() {
+		Expect(Select([]string{"/", "/shop", "/", "/api"}, 0)).To(Equal([]string{"/", "/shop", "/api"}))
+	})
+
+	It("should bound the number of paths", func() {
+		Expect(Select([]string{"/a", "/b", "/c"}, 2)).To(Equal([]string{"/a", "/b"}))
+		Expect(Select([]string{"/1", "/2", "/3", "/4", "/5", "/6"}, 0)).To(HaveLen(DefaultMax))
+	})
+
+	It("should fold patterns sharing a literal prefix", func() {
+		Expect(Select([]string{"/api(/|$)(.*)", "/api", "/*"}, 0)).To(Equal([]string{"/api", "/"}))
+	})
+})
+
+var _ = Describe("Literal", func() {
+	DescribeTable("should strip the pattern part",
+		func(path, literal string) {
+			Expect(Literal(path)).To(Equal(literal))
+		},
+		Entry("plain path", "/shop/cart", "/shop/cart"),
+		Entry("file with a dot", "/index.html", "/index.html"),
+		Entry("wildcard", "/static/*", "/static/"),
+		Entry("regular expression", "/static/.*", "/static/"),
+		Entry("capture group", "/api(/|$)(.*)", "/api"),
+		Entry("missing slash", "docs", "/docs"),
+		Entry("empty", "", "/"),
+	)
+})
+
+var _ = Describe("TrimDepth", func() {
+	It("should cut paths after depth segments", func() {
+		trimmed := TrimDepth([]string{"/", "/shop", "/shop/cart/items", "/docs/v1/api/*"}, 2)
+		Expect(trimmed).To(Equal([]string{"/", "/shop", "/shop/cart", "/docs/v1"}))
+		Expect(Select(TrimDepth([]string{"/a/b", "/a/c"}, 1), 0)).To(Equal([]string{"/a"}))
+		Expect(TrimDepth([]string{"/a/b"}, 0)).To(Equal([]string{"/a/b"}))
+	})
+})
+
+var _ = Describe("Join", func() {
+	It("should append paths other than the root", func() {
+		Expect(Join("http://example.com", "/")).To(Equal("http://example.com"))
+		Expect(Join("http://example.com/", "/shop")).To(Equal("http://example.com/shop"))
+		Expect(Join("", "/shop")).To(BeEmpty())
+	})
+})
@@ -17,18 +17,32 @@ package browser
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/metadata"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/paths"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/precheck"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/retry"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/scheduler"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/screenshot"
 	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/utils"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/waitfor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
@@ -36,6 +50,9 @@ const (
 	pluginType = constants.ComplianceCollectorPluginType
 )
 
+// retryPollInterval is how often due retries are handed back to the workers
+const retryPollInterval = 10 * time.Second
+
 func init() {
 	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
 		return &BrowserPlugin{
@@ -50,6 +67,9 @@ type BrowserPlugin struct {
 	browserConfig BrowserConfig
 	browserPool   *utils.BrowserPool
 	collector     *Collector
+	retries       *retry.Queue
+	server        *http.Server
+	metricsServer *http.Server
 }
 
 func (p *BrowserPlugin) Name() string {
@@ -65,6 +85,43 @@ type BrowserConfig struct {
 	MaxWorkers             int `json:"maxWorkers"`
 	BrowserNumber          int `json:"browserNumber"`
 	BrowserTimeoutMinute   int `json:"browserTimeout"`
+	MaxPerNamespace        int `json:"maxPerNamespace"`
+	MaxQueued              int `json:"maxQueued"`
+	MaxPaths               int `json:"maxPaths"`
+
+	// Region selects the entry of Regions that replaces Network
+	Region  string                    `json:"region"`
+	Network network.Config            `json:"network"`
+	Regions map[string]network.Config `json:"regions"`
+
+	// Retry re-collects targets that failed with a transient error
+	Retry retry.Config `json:"retry"`
+	// Precheck skips targets that do not answer before taking a browser
+	Precheck precheck.Config `json:"precheck"`
+	// Metadata gathers the meta tags, robots.txt and security.txt of sites
+	Metadata metadata.Config `json:"metadata"`
+	// Pool tunes the warmup and recycling of the browser pool
+	Pool PoolConfig `json:"pool"`
+	// WaitFor holds the readiness rules of pages that render after the load
+	// event, such as single page applications fed by a WebSocket
+	WaitFor waitfor.Config `json:"waitFor"`
+	// Screenshot sets the format, resolution and size limit of screenshots
+	Screenshot screenshot.Config `json:"screenshot"`
+}
+
+// PoolConfig is the pool section of the settings. Browsers are launched on
+// demand and recycled by age only when it is empty.
+type PoolConfig struct {
+	// Warmup browsers are launched at start and relaunched after recycling
+	Warmup int `json:"warmup"`
+	// Pages blank pages are kept ready in every browser for unproxied targets
+	Pages int `json:"pages"`
+	// MaxPages and MaxRSSMB recycle a browser after serving that many pages
+	// or when its processes use more memory
+	MaxPages int `json:"maxPages"`
+	MaxRSSMB int `json:"maxRSSMB"`
+	// MetricsAddr serves the pool metrics to Prometheus on /metrics when set
+	MetricsAddr string `json:"metricsAddr"`
 }
 
 func (p *BrowserPlugin) getDefaultBrowserConfig() BrowserConfig {
@@ -73,6 +130,12 @@ func (p *BrowserPlugin) getDefaultBrowserConfig() BrowserConfig {
 		MaxWorkers:             20,
 		BrowserNumber:          20,
 		BrowserTimeoutMinute:   300,
+		MaxPerNamespace:        2,
+		MaxQueued:              1000,
+		MaxPaths:               paths.DefaultMax,
+		Retry:                  retry.DefaultConfig(),
+		Precheck:               precheck.DefaultConfig(),
+		Metadata:               metadata.DefaultConfig(),
 	}
 }
 
@@ -103,9 +166,53 @@ func (p *BrowserPlugin) loadConfig(setting string) error {
 	if configFromJSON.BrowserTimeoutMinute > 0 {
 		p.browserConfig.BrowserTimeoutMinute = configFromJSON.BrowserTimeoutMinute
 	}
+	if configFromJSON.MaxPerNamespace > 0 {
+		p.browserConfig.MaxPerNamespace = configFromJSON.MaxPerNamespace
+	}
+	if configFromJSON.MaxQueued > 0 {
+		p.browserConfig.MaxQueued = configFromJSON.MaxQueued
+	}
+	if configFromJSON.MaxPaths > 0 {
+		p.browserConfig.MaxPaths = configFromJSON.MaxPaths
+	}
+	p.browserConfig.Region = configFromJSON.Region
+	p.browserConfig.Network = configFromJSON.Network
+	p.browserConfig.Regions = configFromJSON.Regions
+	p.browserConfig.Retry = p.browserConfig.Retry.Merge(configFromJSON.Retry)
+	p.browserConfig.Precheck = p.browserConfig.Precheck.Merge(configFromJSON.Precheck)
+	p.browserConfig.Metadata = p.browserConfig.Metadata.Merge(configFromJSON.Metadata)
+	p.browserConfig.Pool = configFromJSON.Pool
+	if err := configFromJSON.WaitFor.Validate(); err != nil {
+		return fmt.Errorf("invalid waitFor configuration: %w", err)
+	}
+	p.browserConfig.WaitFor = configFromJSON.WaitFor
+	if err := configFromJSON.Screenshot.Validate(); err != nil {
+		return fmt.Errorf("invalid screenshot configuration: %w", err)
+	}
+	p.browserConfig.Screenshot = configFromJSON.Screenshot
+	if configFromJSON.Retry.APIToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.Retry.APIToken); err == nil {
+			p.browserConfig.Retry.APIToken = token
+		} else if config.IsSecretReference(configFromJSON.Retry.APIToken) {
+			return fmt.Errorf("failed to resolve retry API token: %w", err)
+		}
+	}
+	// Dead letters can be dropped and requeued, the API is never served
+	// unauthenticated
+	if p.browserConfig.Retry.APIAddr != "" && p.browserConfig.Retry.APIToken == "" {
+		return errors.New("retry apiToken configuration cannot be empty when apiAddr is set")
+	}
 	return nil
 }
 
+// networkConfig returns the network settings of the configured region
+func (c BrowserConfig) networkConfig() network.Config {
+	if regional, ok := c.Regions[c.Region]; ok && c.Region != "" {
+		return regional
+	}
+	return c.Network
+}
+
 func (p *BrowserPlugin) Start(
 	ctx context.Context,
 	config config.PluginConfig,
@@ -116,123 +223,387 @@ func (p *BrowserPlugin) Start(
 		return err
 	}
 
+	targets, err := network.New(p.browserConfig.networkConfig())
+	if err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+	p.collector.UseNetwork(targets)
+	if p.precheckEnabled() {
+		p.collector.UsePrecheck(precheck.New(p.browserConfig.Precheck, targets))
+	}
+	if p.metadataEnabled() {
+		p.collector.UseMetadata(metadata.New(p.browserConfig.Metadata, targets))
+	}
+	p.collector.UseWaitFor(p.browserConfig.WaitFor)
+	p.collector.UseScreenshot(p.browserConfig.Screenshot)
+	p.collector.UsePaths(p.browserConfig.MaxPaths)
+
+	if p.retryEnabled() {
+		p.retries, err = retry.NewQueue(p.browserConfig.Retry)
+		if err != nil {
+			return fmt.Errorf("failed to load retry queue: %w", err)
+		}
+		if p.browserConfig.Retry.APIAddr != "" {
+			p.startRetryAPI()
+		}
+	}
+
 	p.log.Info("Starting browser plugin", logger.Fields{
 		"timeout_seconds":   p.browserConfig.CollectorTimeoutSecond,
 		"max_workers":       p.browserConfig.MaxWorkers,
 		"browser_pool_size": p.browserConfig.BrowserNumber,
+		"max_per_namespace": p.browserConfig.MaxPerNamespace,
+		"max_queued":        p.browserConfig.MaxQueued,
+		"max_paths":         p.browserConfig.MaxPaths,
+		"region":            p.browserConfig.Region,
+		"resolver_rules":    targets.ResolverRules(),
+		"direct_service":    p.browserConfig.networkConfig().DirectService,
+		"egress_restricted": targets.RestrictsEgress(),
+		"retry_enabled":     p.retryEnabled(),
+		"precheck_enabled":  p.precheckEnabled(),
+		"metadata_enabled":  p.metadataEnabled(),
+		"wait_rules":        len(p.browserConfig.WaitFor.Rules),
 	})
 
+	launchFlags := map[string]string{}
+	if rules := targets.ResolverRules(); rules != "" {
+		launchFlags["host-resolver-rules"] = rules
+	}
 	p.browserPool = utils.NewBrowserPool(
 		p.browserConfig.BrowserNumber,
 		time.Duration(p.browserConfig.BrowserTimeoutMinute)*time.Minute,
+		launchFlags,
+		utils.PoolOptions{
+			Warmup:   p.browserConfig.Pool.Warmup,
+			Pages:    p.browserConfig.Pool.Pages,
+			MaxPages: p.browserConfig.Pool.MaxPages,
+			MaxRSS:   int64(p.browserConfig.Pool.MaxRSSMB) << 20,
+		},
 	)
+	if p.browserConfig.Pool.MetricsAddr != "" {
+		p.startMetricsServer()
+	}
+	if p.browserConfig.Pool.Warmup > 0 {
+		go p.browserPool.Warmup()
+	}
+	queue := scheduler.NewScheduler(
+		p.browserConfig.MaxPerNamespace,
+		p.browserConfig.MaxQueued,
+	)
+	go func() {
+		<-ctx.Done()
+		queue.Close()
+	}()
+	if p.retries != nil {
+		go p.scheduleRetries(ctx, queue)
+	}
+
+	var workers sync.WaitGroup
+	for range p.browserConfig.MaxWorkers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				ingress, ok := queue.Pop()
+				if !ok {
+					return
+				}
+				p.collect(ctx, ingress, eventBus)
+				queue.Done(ingress.Namespace)
+			}
+		}()
+	}
+
 	subscribe := eventBus.Subscribe(constants.DiscoveryTopic)
-	semaphore := make(chan struct{}, p.browserConfig.MaxWorkers)
 	for {
 		select {
 		case event, ok := <-subscribe:
 			if !ok {
 				p.log.Info("Event subscription channel closed")
+				queue.Close()
+				workers.Wait()
 				return nil
 			}
-			semaphore <- struct{}{}
-			go func(e eventbus.Event) {
-				defer func() { <-semaphore }()
-				defer func() {
-					if r := recover(); r != nil {
-						p.log.Error("Goroutine panic recovered", logger.Fields{
-							"panic": r,
-							"stack": string(debug.Stack()),
-						})
-					}
-				}()
-				ingress, ok := e.Payload.(models.DiscoveryInfo)
-				if !ok {
-					p.log.Error("Invalid event payload type", logger.Fields{
-						"expected": "models.DiscoveryInfo",
-						"actual":   fmt.Sprintf("%T", e.Payload),
-					})
-					return
-				}
-				var result *models.CollectorInfo
-				taskCtx, cancel := context.WithTimeout(
-					ctx,
-					time.Duration(p.browserConfig.CollectorTimeoutSecond)*time.Second,
-				)
-				taskCtx = context.WithValue(taskCtx, "start_time", time.Now())
-				defer cancel()
-
-				p.log.Debug("Processing discovery", logger.Fields{
-					"namespace": ingress.Namespace,
-					"name":      ingress.Name,
-					"host":      ingress.Host,
+			ingress, ok := event.Payload.(models.DiscoveryInfo)
+			if !ok {
+				p.log.Error("Invalid event payload type", logger.Fields{
+					"expected": "models.DiscoveryInfo",
+					"actual":   fmt.Sprintf("%T", event.Payload),
 				})
-
-				result, err := p.collector.CollectorAndScreenshot(
-					taskCtx,
-					ingress,
-					p.browserPool,
-					p.Name(),
-					time.Duration(p.browserConfig.CollectorTimeoutSecond)*time.Second,
-				)
-				if err != nil {
-					if p.shouldSkipError(err) {
-						result = &models.CollectorInfo{
-							DiscoveryName:    ingress.DiscoveryName,
-							CollectorName:    p.Name(),
-							Name:             ingress.Name,
-							Namespace:        ingress.Namespace,
-							Host:             ingress.Host,
-							Path:             ingress.Path,
-							URL:              "",
-							HTML:             "",
-							Screenshot:       nil,
-							IsEmpty:          true,
-							CollectorMessage: err.Error(),
-						}
-						eventBus.Publish(constants.CollectorTopic, eventbus.Event{
-							Payload: result,
-						})
-						p.log.Debug("Skipped known error", logger.Fields{
-							"host":  ingress.Host,
-							"error": err.Error(),
-						})
-					} else {
-						p.log.Error("Collection failed", logger.Fields{
-							"host":      ingress.Host,
-							"namespace": ingress.Namespace,
-							"name":      ingress.Name,
-							"error":     err.Error(),
-						})
-					}
-				} else {
-					eventBus.Publish(constants.CollectorTopic, eventbus.Event{
-						Payload: result,
-					})
-					p.log.Debug("Collection successful", logger.Fields{
-						"host":      ingress.Host,
-						"namespace": ingress.Namespace,
-						"name":      ingress.Name,
-					})
-				}
-			}(event)
-		case <-ctx.Done():
-			for range p.browserConfig.MaxWorkers {
-				semaphore <- struct{}{}
+				continue
+			}
+			// TCP ports without a website are probed by the TCP collector
+			if ingress.Protocol == models.ProtocolTCP {
+				continue
 			}
+			queue.Push(ingress)
+		case <-ctx.Done():
+			workers.Wait()
 			return nil
 		}
 	}
 }
 
+func (p *BrowserPlugin) collect(
+	ctx context.Context,
+	ingress models.DiscoveryInfo,
+	eventBus *eventbus.EventBus,
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.log.Error("Goroutine panic recovered", logger.Fields{
+				"panic": r,
+				"stack": string(debug.Stack()),
+			})
+		}
+	}()
+	var result *models.CollectorInfo
+	// The deadline of the target starts now, the collection leaves the
+	// detection and handling their share of it
+	policy := eventBus.Policy()
+	deadline := policy.Deadline(time.Now())
+	budgetCtx, cancelBudget := policy.Context(ctx, deadline, eventbus.PhaseCollect)
+	defer cancelBudget()
+	taskCtx, cancel := context.WithTimeout(
+		budgetCtx,
+		time.Duration(p.browserConfig.CollectorTimeoutSecond)*time.Second,
+	)
+	taskCtx = context.WithValue(taskCtx, "start_time", time.Now())
+	defer cancel()
+
+	p.log.Debug("Processing discovery", logger.Fields{
+		"namespace": ingress.Namespace,
+		"name":      ingress.Name,
+		"host":      ingress.Host,
+	})
+
+	result, err := p.collector.Collect(
+		taskCtx,
+		ingress,
+		p.browserPool,
+		p.Name(),
+		time.Duration(p.browserConfig.CollectorTimeoutSecond)*time.Second,
+	)
+	if err != nil {
+		if ctx.Err() == nil {
+			complikerrors.Record(p.Name(), complikerrors.Wrap(retry.Classify(err), err))
+		}
+		if p.scheduleRetry(ctx, ingress, err) {
+			return
+		}
+		if p.shouldSkipError(err) {
+			result = &models.CollectorInfo{
+				DiscoveryName:    ingress.DiscoveryName,
+				ScanRunID:        ingress.ScanRunID,
+				CollectorName:    p.Name(),
+				Name:             ingress.Name,
+				Namespace:        ingress.Namespace,
+				Host:             ingress.Host,
+				Path:             ingress.Path,
+				URL:              "",
+				HTML:             "",
+				Screenshot:       nil,
+				IsEmpty:          true,
+				CollectorMessage: err.Error(),
+				Overrides:        ingress.Overrides,
+			}
+			eventBus.Publish(constants.CollectorTopic, eventbus.Event{
+				Payload:  result,
+				Deadline: deadline,
+			})
+			p.log.Debug("Skipped known error", logger.Fields{
+				"host":  ingress.Host,
+				"error": err.Error(),
+			})
+		} else {
+			p.log.Error("Collection failed", logger.Fields{
+				"host":      ingress.Host,
+				"namespace": ingress.Namespace,
+				"name":      ingress.Name,
+				"error":     err.Error(),
+			})
+			if ingress.ScanRunID != "" {
+				eventBus.Publish(constants.ScanRunTopic, eventbus.Event{
+					Payload: &models.ScanRunEvent{
+						RunID:  ingress.ScanRunID,
+						Target: models.ScanTarget(ingress.Namespace, ingress.Name, ingress.Host, ingress.Path),
+						Error:  err.Error(),
+					},
+				})
+			}
+		}
+	} else {
+		p.recordSuccess(ingress)
+		eventBus.Publish(constants.CollectorTopic, eventbus.Event{
+			Payload:  result,
+			Deadline: deadline,
+		})
+		p.log.Debug("Collection successful", logger.Fields{
+			"host":      ingress.Host,
+			"namespace": ingress.Namespace,
+			"name":      ingress.Name,
+		})
+	}
+}
+
 func (p *BrowserPlugin) Stop(ctx context.Context) error {
 	p.log.Info("Stopping browser plugin")
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down retry API server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+	if p.metricsServer != nil {
+		if err := p.metricsServer.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down browser pool metrics server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
 	if p.browserPool != nil {
 		p.browserPool.Close()
 	}
 	return nil
 }
 
+func (p *BrowserPlugin) retryEnabled() bool {
+	enabled := p.browserConfig.Retry.Enabled
+	return enabled == nil || *enabled
+}
+
+func (p *BrowserPlugin) precheckEnabled() bool {
+	enabled := p.browserConfig.Precheck.Enabled
+	return enabled == nil || *enabled
+}
+
+func (p *BrowserPlugin) metadataEnabled() bool {
+	enabled := p.browserConfig.Metadata.Enabled
+	return enabled == nil || *enabled
+}
+
+// scheduleRetry records a transient collection failure and reports whether the
+// target was queued for another attempt. Targets that used up their attempts
+// are dead-lettered and handled like any other failure.
+func (p *BrowserPlugin) scheduleRetry(
+	ctx context.Context,
+	ingress models.DiscoveryInfo,
+	cause error,
+) bool {
+	// Failures caused by shutdown say nothing about the target
+	if p.retries == nil || ctx.Err() != nil || !retry.IsTransient(cause) {
+		return false
+	}
+	item, dead, err := p.retries.Fail(ingress, cause, time.Now())
+	if err != nil {
+		p.log.Warn("Failed to persist retry queue", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+	if dead {
+		p.log.Warn("Collection retries exhausted, target dead-lettered", logger.Fields{
+			"id":        item.ID,
+			"host":      ingress.Host,
+			"namespace": ingress.Namespace,
+			"name":      ingress.Name,
+			"attempts":  item.Attempts,
+			"error":     cause.Error(),
+		})
+		return false
+	}
+	p.log.Info("Collection failed, retry scheduled", logger.Fields{
+		"host":         ingress.Host,
+		"namespace":    ingress.Namespace,
+		"name":         ingress.Name,
+		"attempt":      item.Attempts,
+		"next_attempt": item.NextAttemptAt,
+		"error":        cause.Error(),
+	})
+	return true
+}
+
+func (p *BrowserPlugin) recordSuccess(ingress models.DiscoveryInfo) {
+	if p.retries == nil {
+		return
+	}
+	recovered, err := p.retries.Succeed(ingress)
+	if err != nil {
+		p.log.Warn("Failed to persist retry queue", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+	if recovered {
+		p.log.Info("Collection recovered after retry", logger.Fields{
+			"host":      ingress.Host,
+			"namespace": ingress.Namespace,
+			"name":      ingress.Name,
+		})
+	}
+}
+
+// scheduleRetries hands targets whose backoff expired back to the workers
+func (p *BrowserPlugin) scheduleRetries(ctx context.Context, queue *scheduler.Scheduler) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, discovery := range p.retries.Due(now) {
+				if !queue.Push(discovery) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// startMetricsServer exports the browser pool metrics to Prometheus. The
+// exporter has its own registry so restarting the plugin registers it again.
+func (p *BrowserPlugin) startMetricsServer() {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(utils.NewPoolExporter(p.browserPool))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	p.metricsServer = &http.Server{
+		Addr:              p.browserConfig.Pool.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Browser pool metrics server started", logger.Fields{
+			"addr": p.browserConfig.Pool.MetricsAddr,
+		})
+		if err := p.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Browser pool metrics server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// startRetryAPI serves the retry queue and dead-letter endpoints
+func (p *BrowserPlugin) startRetryAPI() {
+	p.server = &http.Server{
+		Addr:              p.browserConfig.Retry.APIAddr,
+		Handler:           retry.NewAPI(p.log, p.browserConfig.Retry.APIToken, p.retries),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Retry API server started", logger.Fields{
+			"addr": p.browserConfig.Retry.APIAddr,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Retry API server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
 func (p *BrowserPlugin) shouldSkipError(err error) bool {
 	if err == nil {
 		return false
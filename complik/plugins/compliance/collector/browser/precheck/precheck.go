@@ -0,0 +1,234 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package precheck tests whether a scan target answers before the browser
+// collector spends a browser session on it. A check is a single HEAD request,
+// or a GET when the target refuses HEAD, sent the way the browser would send
+// it: through the DNS overrides, the proxy of the namespace and the egress
+// allowlist.
+package precheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+)
+
+// userAgent is the user agent of the browser pages, some gateways answer
+// unknown clients differently
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36"
+
+// maxRedirects matches the redirect limit of net/http
+const maxRedirects = 10
+
+var (
+	// ErrStatus is returned for targets answering with a 4xx or 5xx status
+	ErrStatus = errors.New("error status")
+	// ErrBlocked is returned for targets outside the egress allowlist
+	ErrBlocked = errors.New("blocked by the egress allowlist")
+)
+
+// Config is the precheck section of the browser collector settings
+type Config struct {
+	Enabled       *bool `json:"enabled"`
+	TimeoutSecond int   `json:"timeoutSecond"`
+	// AllowStatus are 4xx and 5xx statuses that are still collected, e.g.
+	// 401 for sites showing a login page
+	AllowStatus []int `json:"allowStatus"`
+}
+
+// DefaultConfig returns the precheck defaults
+func DefaultConfig() Config {
+	enabled := true
+	return Config{
+		Enabled:       &enabled,
+		TimeoutSecond: 10,
+	}
+}
+
+// Merge overrides the defaults with the set fields of cfg
+func (c Config) Merge(cfg Config) Config {
+	if cfg.Enabled != nil {
+		c.Enabled = cfg.Enabled
+	}
+	if cfg.TimeoutSecond > 0 {
+		c.TimeoutSecond = cfg.TimeoutSecond
+	}
+	if cfg.AllowStatus != nil {
+		c.AllowStatus = cfg.AllowStatus
+	}
+	return c
+}
+
+// Result is the outcome of a check. The timings are those of the first
+// connection; FirstByte and Total span redirects and the GET fallback.
+type Result struct {
+	URL        string
+	Method     string
+	StatusCode int
+	// Skipped is set when the target could not be checked the way the
+	// browser reaches it, such as through a SOCKS4 proxy
+	Skipped bool
+
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Total     time.Duration
+
+	// Err is set when the target is unreachable, blocked or answered with
+	// an error status; such targets are not worth a browser session
+	Err error
+}
+
+// Checker checks scan targets
+type Checker struct {
+	timeout time.Duration
+	allow   []int
+	network *network.Network
+}
+
+// New returns a Checker reaching targets through n, which may be nil
+func New(cfg Config, n *network.Network) *Checker {
+	cfg = DefaultConfig().Merge(cfg)
+	return &Checker{
+		timeout: time.Duration(cfg.TimeoutSecond) * time.Second,
+		allow:   cfg.AllowStatus,
+		network: n,
+	}
+}
+
+// Check requests rawURL through proxy, nil for a direct connection
+func (c *Checker) Check(ctx context.Context, rawURL string, proxy *network.ProxyConfig) Result {
+	result := Result{URL: rawURL, Method: http.MethodHead}
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Host == "" {
+		result.Err = fmt.Errorf("invalid URL %q", rawURL)
+		return result
+	}
+	if c.network != nil && !c.network.Allowed(rawURL) {
+		result.Err = fmt.Errorf("%s: %w", target.Hostname(), ErrBlocked)
+		return result
+	}
+	transport, ok := c.network.Transport(proxy)
+	if !ok {
+		result.Skipped = true
+		return result
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if c.network != nil && !c.network.Allowed(req.URL.String()) {
+				return fmt.Errorf("redirect to %s: %w", req.URL.Hostname(), ErrBlocked)
+			}
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	timing := &timings{start: time.Now()}
+	ctx = httptrace.WithClientTrace(ctx, timing.trace())
+
+	status, err := c.request(ctx, client, http.MethodHead, rawURL)
+	// Plenty of servers mishandle HEAD, only a GET tells for sure
+	if err == nil && status >= http.StatusBadRequest {
+		result.Method = http.MethodGet
+		status, err = c.request(ctx, client, http.MethodGet, rawURL)
+	}
+	timing.apply(&result)
+	result.StatusCode = status
+	switch {
+	case err != nil:
+		result.Err = err
+	case status >= http.StatusBadRequest && !slices.Contains(c.allow, status):
+		result.Err = fmt.Errorf("%w %d", ErrStatus, status)
+	}
+	return result
+}
+
+// request sends one request and returns the status without reading the body
+func (c *Checker) request(ctx context.Context, client *http.Client, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// timings records the timings of the first connection of a check. Dialing
+// can outlive a request and dual stack hosts are dialed concurrently, hence
+// the lock.
+type timings struct {
+	mu                                       sync.Mutex
+	start, dnsStart, connectStart, tlsStart  time.Time
+	dnsTime, connectTime, tlsTime, firstByte time.Duration
+}
+
+func (t *timings) mark(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+func (t *timings) record(field *time.Duration, since *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if *field == 0 && !since.IsZero() {
+		*field = time.Since(*since)
+	}
+}
+
+func (t *timings) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.record(&t.dnsTime, &t.dnsStart) },
+		ConnectStart:         func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.record(&t.connectTime, &t.connectStart) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.record(&t.tlsTime, &t.tlsStart) },
+		GotFirstResponseByte: func() { t.record(&t.firstByte, &t.start) },
+	}
+}
+
+func (t *timings) apply(result *Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result.DNS = t.dnsTime
+	result.Connect = t.connectTime
+	result.TLS = t.tlsTime
+	result.FirstByte = t.firstByte
+	result.Total = time.Since(t.start)
+}
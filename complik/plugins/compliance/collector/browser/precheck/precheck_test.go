@@ -0,0 +1,145 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package precheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/network"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPrecheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Precheck Suite")
+}
+
+func serve(handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	DeferCleanup(server.Close)
+	return server
+}
+
+var _ = Describe("Checker", func() {
+	ctx := context.Background()
+
+	It("should pass reachable targets and time the request", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodHead))
+		}))
+		DeferCleanup(server.Close)
+
+		result := New(Config{}, nil).Check(ctx, server.URL, nil)
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.StatusCode).To(Equal(http.StatusOK))
+		Expect(result.TLS).To(BeNumerically(">", 0))
+		Expect(result.Total).To(BeNumerically(">=", result.FirstByte))
+	})
+
+	It("should negotiate HTTP/2", func() {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			}
+		}))
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		DeferCleanup(server.Close)
+
+		result := New(Config{}, nil).Check(ctx, server.URL, nil)
+		Expect(result.Err).NotTo(HaveOccurred())
+	})
+
+	It("should fall back to GET when HEAD fails", func() {
+		var methods []string
+		server := serve(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+
+		result := New(Config{}, nil).Check(ctx, server.URL, nil)
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.Method).To(Equal(http.MethodGet))
+		Expect(methods).To(Equal([]string{http.MethodHead, http.MethodGet}))
+	})
+
+	It("should fail error statuses unless they are allowed", func() {
+		server := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		result := New(Config{}, nil).Check(ctx, server.URL, nil)
+		Expect(result.Err).To(MatchError(ErrStatus))
+		Expect(result.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		result = New(Config{AllowStatus: []int{http.StatusUnauthorized}}, nil).Check(ctx, server.URL, nil)
+		Expect(result.Err).NotTo(HaveOccurred())
+	})
+
+	It("should fail unreachable targets", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		result := New(Config{}, nil).Check(ctx, "http://"+address, nil)
+		Expect(result.Err).To(HaveOccurred())
+		Expect(result.StatusCode).To(BeZero())
+	})
+
+	It("should connect through the DNS overrides and the egress allowlist", func() {
+		server := serve(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Host).To(HavePrefix("portal.example.test:"))
+		})
+		_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		n, err := network.New(network.Config{
+			DNSOverrides: map[string]string{"portal.example.test": "127.0.0.1"},
+			Egress:       network.EgressConfig{AllowedHosts: []string{"portal.example.test"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		checker := New(Config{}, n)
+
+		result := checker.Check(ctx, "http://portal.example.test:"+port, nil)
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.DNS).To(BeZero())
+
+		result = checker.Check(ctx, server.URL, nil)
+		Expect(result.Err).To(MatchError(ErrBlocked))
+	})
+
+	It("should connect through the namespace proxy", func() {
+		var proxied []string
+		proxy := serve(func(w http.ResponseWriter, r *http.Request) {
+			proxied = append(proxied, r.URL.String())
+		})
+		proxyConfig := &network.ProxyConfig{Server: proxy.URL, Bypass: []string{"*.svc.cluster.local"}}
+
+		result := New(Config{}, nil).Check(ctx, "http://portal.example.test/", proxyConfig)
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(proxied).To(Equal([]string{"http://portal.example.test/"}))
+
+		proxyURL, _ := url.Parse(proxy.URL)
+		result = New(Config{}, nil).Check(ctx, "http://"+proxyURL.Host, &network.ProxyConfig{Server: "socks4://127.0.0.1:1080"})
+		Expect(result.Skipped).To(BeTrue())
+	})
+})
@@ -0,0 +1,98 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// API serves the retry queue endpoints:
+//
+//	GET    /api/v1/retries
+//	GET    /api/v1/retries/dead
+//	POST   /api/v1/retries/dead/{id}/requeue
+//	DELETE /api/v1/retries/dead/{id}
+//	GET    /api/v1/retries/metrics
+type API struct {
+	log     logger.Logger
+	queue   *Queue
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewAPI(log logger.Logger, token string, queue *Queue) *API {
+	api := &API{log: log, queue: queue, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/retries", api.listPending)
+	api.mux.HandleFunc("GET /api/v1/retries/dead", api.listDead)
+	api.mux.HandleFunc("POST /api/v1/retries/dead/{id}/requeue", api.requeue)
+	api.mux.HandleFunc("DELETE /api/v1/retries/dead/{id}", api.drop)
+	api.mux.HandleFunc("GET /api/v1/retries/metrics", api.metrics)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) listPending(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.queue.Pending())
+}
+
+func (a *API) listDead(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.queue.Dead())
+}
+
+func (a *API) requeue(w http.ResponseWriter, r *http.Request) {
+	entry, err := a.queue.Requeue(r.PathValue("id"), time.Now())
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Dead-letter entry requeued", logger.Fields{
+		"id":   entry.ID,
+		"host": entry.Discovery.Host,
+	})
+	httpapi.WriteJSON(w, http.StatusOK, entry)
+}
+
+func (a *API) drop(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := a.queue.Drop(id); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Dead-letter entry dropped", logger.Fields{"id": id})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) metrics(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.queue.Stats())
+}
+
+// fail maps queue errors to HTTP status codes
+func (a *API) fail(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrItemNotFound) {
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	a.log.Error("Retry API request failed", logger.Fields{"error": err.Error()})
+	httpapi.WriteError(w, http.StatusInternalServerError, "internal error")
+}
@@ -0,0 +1,418 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry keeps collections that failed with a transient error, such as
+// a 502 or a timeout, and hands them back to the collector with exponential
+// backoff. Targets that keep failing are moved to a dead-letter list.
+package retry
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// DefaultStatePath is where the queue is persisted when no path is configured
+const DefaultStatePath = "data/collector-retry.json"
+
+// ErrItemNotFound is returned for unknown dead-letter IDs
+var ErrItemNotFound = errors.New("retry item not found")
+
+// Config is the retry section of the browser collector settings
+type Config struct {
+	Enabled *bool `json:"enabled"`
+	// MaxAttempts counts every collection of a target, including the first
+	MaxAttempts          int     `json:"maxAttempts"`
+	InitialBackoffSecond int     `json:"initialBackoffSecond"`
+	MaxBackoffSecond     int     `json:"maxBackoffSecond"`
+	Multiplier           float64 `json:"multiplier"`
+	// StatePath is the JSON file the queue survives restarts in
+	StatePath       string `json:"statePath"`
+	DeadLetterLimit int    `json:"deadLetterLimit"`
+	// APIAddr serves the retry API when set
+	APIAddr  string `json:"apiAddr"`
+	APIToken string `json:"apiToken"`
+}
+
+// DefaultConfig returns the retry defaults
+func DefaultConfig() Config {
+	enabled := true
+	return Config{
+		Enabled:              &enabled,
+		MaxAttempts:          5,
+		InitialBackoffSecond: 60,
+		MaxBackoffSecond:     3600,
+		Multiplier:           2,
+		StatePath:            DefaultStatePath,
+		DeadLetterLimit:      1000,
+	}
+}
+
+// Merge overrides the defaults with the set fields of cfg
+func (c Config) Merge(cfg Config) Config {
+	if cfg.Enabled != nil {
+		c.Enabled = cfg.Enabled
+	}
+	if cfg.MaxAttempts > 0 {
+		c.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoffSecond > 0 {
+		c.InitialBackoffSecond = cfg.InitialBackoffSecond
+	}
+	if cfg.MaxBackoffSecond > 0 {
+		c.MaxBackoffSecond = cfg.MaxBackoffSecond
+	}
+	if cfg.Multiplier >= 1 {
+		c.Multiplier = cfg.Multiplier
+	}
+	if cfg.StatePath != "" {
+		c.StatePath = cfg.StatePath
+	}
+	if cfg.DeadLetterLimit > 0 {
+		c.DeadLetterLimit = cfg.DeadLetterLimit
+	}
+	c.APIAddr = cfg.APIAddr
+	c.APIToken = cfg.APIToken
+	return c
+}
+
+// Item is a target waiting for a retry or in the dead-letter list
+type Item struct {
+	ID            string               `json:"id"`
+	Discovery     models.DiscoveryInfo `json:"discovery"`
+	Attempts      int                  `json:"attempts"`
+	LastError     string               `json:"lastError"`
+	FirstFailedAt time.Time            `json:"firstFailedAt"`
+	LastFailedAt  time.Time            `json:"lastFailedAt"`
+	NextAttemptAt time.Time            `json:"nextAttemptAt,omitzero"`
+
+	inFlight bool
+}
+
+// Stats are the counters of the queue since it was created
+type Stats struct {
+	Pending      int `json:"pending"`
+	InFlight     int `json:"inFlight"`
+	Dead         int `json:"dead"`
+	Scheduled    int `json:"scheduled"`
+	Retried      int `json:"retried"`
+	Recovered    int `json:"recovered"`
+	DeadLettered int `json:"deadLettered"`
+}
+
+type state struct {
+	Pending []*Item `json:"pending"`
+	Dead    []*Item `json:"dead"`
+}
+
+// Queue is the retry queue of a collector
+type Queue struct {
+	mu      sync.Mutex
+	cfg     Config
+	pending map[string]*Item
+	dead    map[string]*Item
+	stats   Stats
+}
+
+// NewQueue creates a queue and restores the entries saved at cfg.StatePath
+func NewQueue(cfg Config) (*Queue, error) {
+	q := &Queue{
+		cfg:     cfg,
+		pending: make(map[string]*Item),
+		dead:    make(map[string]*Item),
+	}
+	if cfg.StatePath == "" {
+		return q, nil
+	}
+	data, err := os.ReadFile(cfg.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry state: %w", err)
+	}
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse retry state %s: %w", cfg.StatePath, err)
+	}
+	for _, entry := range saved.Pending {
+		q.pending[entry.ID] = entry
+	}
+	for _, entry := range saved.Dead {
+		q.dead[entry.ID] = entry
+	}
+	return q, nil
+}
+
+// Key identifies the target of a discovery across collection cycles
+func Key(discovery models.DiscoveryInfo) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		discovery.Namespace,
+		discovery.Name,
+		discovery.Host,
+		strings.Join(discovery.Path, ","),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Backoff returns the delay before the retry that follows attempts failed
+// collections
+func (q *Queue) Backoff(attempts int) time.Duration {
+	initial := float64(q.cfg.InitialBackoffSecond)
+	delay := initial * math.Pow(q.cfg.Multiplier, float64(attempts-1))
+	if maxDelay := float64(q.cfg.MaxBackoffSecond); delay > maxDelay {
+		delay = maxDelay
+	}
+	return time.Duration(delay) * time.Second
+}
+
+// Fail records a transient failure of discovery. It reports whether the
+// target was moved to the dead-letter list instead of being scheduled.
+func (q *Queue) Fail(discovery models.DiscoveryInfo, cause error, now time.Time) (Item, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := Key(discovery)
+	entry, ok := q.pending[id]
+	if !ok {
+		entry = &Item{ID: id, FirstFailedAt: now}
+		q.pending[id] = entry
+	}
+	entry.Discovery = discovery
+	entry.Attempts++
+	entry.LastError = cause.Error()
+	entry.LastFailedAt = now
+	entry.inFlight = false
+
+	dead := entry.Attempts >= q.cfg.MaxAttempts
+	if dead {
+		delete(q.pending, id)
+		entry.NextAttemptAt = time.Time{}
+		q.dead[id] = entry
+		q.stats.DeadLettered++
+		q.trimDead()
+	} else {
+		entry.NextAttemptAt = now.Add(q.Backoff(entry.Attempts))
+		q.stats.Scheduled++
+	}
+	return *entry, dead, q.save()
+}
+
+// Succeed removes discovery from the queue after a successful collection and
+// reports whether it was being retried
+func (q *Queue) Succeed(discovery models.DiscoveryInfo) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := Key(discovery)
+	_, pending := q.pending[id]
+	_, dead := q.dead[id]
+	if !pending && !dead {
+		return false, nil
+	}
+	delete(q.pending, id)
+	delete(q.dead, id)
+	q.stats.Recovered++
+	return true, q.save()
+}
+
+// Due returns the targets whose backoff expired and marks them in flight until
+// they are reported with Fail or Succeed
+func (q *Queue) Due(now time.Time) []models.DiscoveryInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*Item
+	for _, entry := range q.pending {
+		if !entry.inFlight && !entry.NextAttemptAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	discoveries := make([]models.DiscoveryInfo, 0, len(due))
+	for _, entry := range due {
+		entry.inFlight = true
+		q.stats.Retried++
+		discoveries = append(discoveries, entry.Discovery)
+	}
+	return discoveries
+}
+
+// Requeue moves a dead-letter entry back to the queue with a fresh attempt budget
+func (q *Queue) Requeue(id string, now time.Time) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.dead[id]
+	if !ok {
+		return Item{}, ErrItemNotFound
+	}
+	delete(q.dead, id)
+	entry.Attempts = 0
+	entry.NextAttemptAt = now
+	q.pending[id] = entry
+	return *entry, q.save()
+}
+
+// Drop removes a dead-letter entry
+func (q *Queue) Drop(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.dead[id]; !ok {
+		return ErrItemNotFound
+	}
+	delete(q.dead, id)
+	return q.save()
+}
+
+// Pending returns the scheduled retries ordered by their next attempt
+func (q *Queue) Pending() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := snapshot(q.pending)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].NextAttemptAt.Before(entries[j].NextAttemptAt) })
+	return entries
+}
+
+// Dead returns the dead-letter list, most recent failure first
+func (q *Queue) Dead() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := snapshot(q.dead)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastFailedAt.After(entries[j].LastFailedAt) })
+	return entries
+}
+
+// Stats returns the current queue sizes and counters
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	stats.Pending = len(q.pending)
+	stats.Dead = len(q.dead)
+	for _, entry := range q.pending {
+		if entry.inFlight {
+			stats.InFlight++
+		}
+	}
+	return stats
+}
+
+func snapshot(entries map[string]*Item) []Item {
+	list := make([]Item, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, *entry)
+	}
+	return list
+}
+
+// trimDead drops the oldest dead-letter entries above the limit
+func (q *Queue) trimDead() {
+	if q.cfg.DeadLetterLimit <= 0 || len(q.dead) <= q.cfg.DeadLetterLimit {
+		return
+	}
+	entries := snapshot(q.dead)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastFailedAt.Before(entries[j].LastFailedAt) })
+	for _, entry := range entries[:len(entries)-q.cfg.DeadLetterLimit] {
+		delete(q.dead, entry.ID)
+	}
+}
+
+// save writes the queue to the state file; callers hold q.mu
+func (q *Queue) save() error {
+	if q.cfg.StatePath == "" {
+		return nil
+	}
+	saved := state{Pending: make([]*Item, 0, len(q.pending)), Dead: make([]*Item, 0, len(q.dead))}
+	for _, entry := range q.pending {
+		saved.Pending = append(saved.Pending, entry)
+	}
+	for _, entry := range q.dead {
+		saved.Dead = append(saved.Dead, entry)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode retry state: %w", err)
+	}
+	if dir := filepath.Dir(q.cfg.StatePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create retry state directory: %w", err)
+		}
+	}
+	// Write through a temporary file so a crash never leaves a truncated state
+	tmp := q.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write retry state: %w", err)
+	}
+	if err := os.Rename(tmp, q.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to replace retry state: %w", err)
+	}
+	return nil
+}
+
+// transientPatterns are collection errors worth retrying before the next cycle
+var transientPatterns = []string{
+	"ERR_CONNECTION_RESET",
+	"ERR_CONNECTION_REFUSED",
+	"ERR_CONNECTION_CLOSED",
+	"ERR_CONNECTION_TIMED_OUT",
+	"ERR_TIMED_OUT",
+	"ERR_EMPTY_RESPONSE",
+	"ERR_HTTP_RESPONSE_CODE_FAILURE",
+	"ERR_NETWORK_CHANGED",
+	"timeout waiting for browser instance",
+	// waitfor.ErrNotReady of required wait rules, e.g. a WebSocket backend
+	// still starting
+	"page not ready",
+}
+
+// IsTransient reports whether a collection failure is likely to succeed on a
+// later attempt, see Classify
+func IsTransient(err error) bool {
+	return Classify(err).Retryable()
+}
+
+// Classify returns the class of a collection failure. Errors classified where
+// they occurred keep their class. Gateway errors (502/503/504) cancel the
+// collection context, so cancellation and deadline errors count as transient,
+// like the browser network errors of transientPatterns. Other failures are
+// permanent. Classify returns "" for nil.
+func Classify(err error) complikerrors.Class {
+	if err == nil {
+		return ""
+	}
+	var classified *complikerrors.Error
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return complikerrors.Transient
+	}
+	message := err.Error()
+	for _, pattern := range transientPatterns {
+		if strings.Contains(message, pattern) {
+			return complikerrors.Transient
+		}
+	}
+	return complikerrors.ClassOf(err)
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRetry(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Retry Suite")
+}
+
+var _ = Describe("Queue", func() {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	site := models.DiscoveryInfo{Namespace: "ns-a", Name: "web", Host: "a.example.com", Path: []string{"/"}}
+	gateway := errors.New("navigation failed: net::ERR_HTTP_RESPONSE_CODE_FAILURE")
+
+	newQueue := func(path string) *Queue {
+		cfg := DefaultConfig()
+		cfg.MaxAttempts = 3
+		cfg.StatePath = path
+		q, err := NewQueue(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		return q
+	}
+
+	It("should back off exponentially up to the maximum", func() {
+		q := newQueue("")
+		Expect(q.Backoff(1)).To(Equal(time.Minute))
+		Expect(q.Backoff(2)).To(Equal(2 * time.Minute))
+		Expect(q.Backoff(3)).To(Equal(4 * time.Minute))
+		Expect(q.Backoff(20)).To(Equal(time.Hour))
+	})
+
+	It("should hand out failed targets once their backoff expires", func() {
+		q := newQueue("")
+		entry, dead, err := q.Fail(site, gateway, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dead).To(BeFalse())
+		Expect(entry.NextAttemptAt).To(Equal(start.Add(time.Minute)))
+
+		Expect(q.Due(start.Add(30 * time.Second))).To(BeEmpty())
+		Expect(q.Due(start.Add(time.Minute))).To(ConsistOf(site))
+		// In-flight retries are not handed out twice
+		Expect(q.Due(start.Add(time.Hour))).To(BeEmpty())
+
+		recovered, err := q.Succeed(site)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(BeTrue())
+		Expect(q.Pending()).To(BeEmpty())
+		Expect(q.Stats()).To(Equal(Stats{Scheduled: 1, Retried: 1, Recovered: 1}))
+	})
+
+	It("should dead-letter targets after the maximum attempts", func() {
+		q := newQueue("")
+		for i := range 2 {
+			_, dead, err := q.Fail(site, gateway, start.Add(time.Duration(i)*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dead).To(BeFalse())
+		}
+		entry, dead, err := q.Fail(site, gateway, start.Add(2*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dead).To(BeTrue())
+		Expect(entry.Attempts).To(Equal(3))
+		Expect(q.Pending()).To(BeEmpty())
+		Expect(q.Dead()).To(HaveLen(1))
+
+		requeued, err := q.Requeue(entry.ID, start.Add(3*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeued.Attempts).To(BeZero())
+		Expect(q.Dead()).To(BeEmpty())
+		Expect(q.Due(start.Add(3 * time.Hour))).To(ConsistOf(site))
+
+		Expect(q.Drop(entry.ID)).To(MatchError(ErrItemNotFound))
+	})
+
+	It("should keep only the most recent dead letters", func() {
+		cfg := DefaultConfig()
+		cfg.MaxAttempts = 1
+		cfg.StatePath = ""
+		cfg.DeadLetterLimit = 2
+		q, err := NewQueue(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		for i := range 3 {
+			target := site
+			target.Host = fmt.Sprintf("%d.example.com", i)
+			_, _, err := q.Fail(target, gateway, start.Add(time.Duration(i)*time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		dead := q.Dead()
+		Expect(dead).To(HaveLen(2))
+		Expect(dead[0].Discovery.Host).To(Equal("2.example.com"))
+		Expect(dead[1].Discovery.Host).To(Equal("1.example.com"))
+	})
+
+	It("should restore its entries from the state file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "state", "retry.json")
+		q := newQueue(path)
+		_, _, err := q.Fail(site, gateway, start)
+		Expect(err).NotTo(HaveOccurred())
+
+		restored := newQueue(path)
+		pending := restored.Pending()
+		Expect(pending).To(HaveLen(1))
+		Expect(pending[0].Discovery.Host).To(Equal(site.Host))
+		Expect(pending[0].NextAttemptAt.Equal(start.Add(time.Minute))).To(BeTrue())
+	})
+
+	It("should classify transient errors", func() {
+		Expect(IsTransient(gateway)).To(BeTrue())
+		Expect(IsTransient(fmt.Errorf("collect: %w", context.DeadlineExceeded))).To(BeTrue())
+		Expect(IsTransient(errors.New("failed to get browser instance: timeout waiting for browser instance"))).To(BeTrue())
+		Expect(IsTransient(errors.New("page not ready: websocket /ws"))).To(BeTrue())
+		Expect(IsTransient(errors.New("net::ERR_NAME_NOT_RESOLVED"))).To(BeFalse())
+		Expect(IsTransient(nil)).To(BeFalse())
+	})
+
+	It("should keep the class of classified errors", func() {
+		Expect(Classify(complikerrors.Wrap(complikerrors.RateLimited, errors.New("page not ready: websocket /ws")))).
+			To(Equal(complikerrors.RateLimited))
+		Expect(IsTransient(complikerrors.Wrap(complikerrors.Permanent, context.DeadlineExceeded))).To(BeFalse())
+		Expect(Classify(errors.New("net::ERR_NAME_NOT_RESOLVED"))).To(Equal(complikerrors.Permanent))
+	})
+})
+
+var _ = Describe("API", func() {
+	var (
+		q   *Queue
+		api *API
+	)
+
+	BeforeEach(func() {
+		cfg := DefaultConfig()
+		cfg.MaxAttempts = 1
+		cfg.StatePath = ""
+		var err error
+		q, err = NewQueue(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		api = NewAPI(logger.GetLogger(), "secret", q)
+	})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should reject requests without the token", func() {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/retries", nil))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should refuse every request without a configured token", func() {
+		entry, _, err := q.Fail(models.DiscoveryInfo{Host: "a.example.com"}, errors.New("ERR_TIMED_OUT"), time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		api = NewAPI(logger.GetLogger(), "", q)
+		Expect(do(http.MethodPost, "/api/v1/retries/dead/"+entry.ID+"/requeue").Code).To(Equal(http.StatusUnauthorized))
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/retries/dead/"+entry.ID, nil)
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(q.Dead()).To(HaveLen(1))
+		Expect(q.Pending()).To(BeEmpty())
+	})
+
+	It("should list, requeue and drop dead letters", func() {
+		entry, _, err := q.Fail(models.DiscoveryInfo{Host: "a.example.com"}, errors.New("ERR_TIMED_OUT"), time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		rec := do(http.MethodGet, "/api/v1/retries/dead")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var dead []Item
+		Expect(json.Unmarshal(rec.Body.Bytes(), &dead)).To(Succeed())
+		Expect(dead).To(HaveLen(1))
+		Expect(dead[0].LastError).To(Equal("ERR_TIMED_OUT"))
+
+		Expect(do(http.MethodPost, "/api/v1/retries/dead/"+entry.ID+"/requeue").Code).To(Equal(http.StatusOK))
+		Expect(q.Pending()).To(HaveLen(1))
+		Expect(do(http.MethodDelete, "/api/v1/retries/dead/"+entry.ID).Code).To(Equal(http.StatusNotFound))
+
+		rec = do(http.MethodGet, "/api/v1/retries/metrics")
+		var stats Stats
+		Expect(json.Unmarshal(rec.Body.Bytes(), &stats)).To(Succeed())
+		Expect(stats.Pending).To(Equal(1))
+		Expect(stats.DeadLettered).To(Equal(1))
+	})
+})
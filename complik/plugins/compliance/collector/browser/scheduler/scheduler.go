@@ -0,0 +1,153 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduler queues collection tasks per namespace and hands them out in
+// round-robin order, so a tenant with many ingresses cannot starve the others.
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Scheduler is a fair queue of discovery tasks. At most perNamespace tasks of
+// one namespace run at the same time and Push blocks while maxQueued tasks are
+// waiting.
+type Scheduler struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	perNamespace int
+	maxQueued    int
+
+	queues map[string][]models.DiscoveryInfo
+	order  []string // namespaces with queued tasks, in round-robin order
+	next   int
+	active map[string]int
+	queued int
+	closed bool
+}
+
+func NewScheduler(perNamespace, maxQueued int) *Scheduler {
+	if perNamespace <= 0 {
+		perNamespace = 1
+	}
+	if maxQueued <= 0 {
+		maxQueued = 1
+	}
+	s := &Scheduler{
+		perNamespace: perNamespace,
+		maxQueued:    maxQueued,
+		queues:       make(map[string][]models.DiscoveryInfo),
+		active:       make(map[string]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Push queues info, blocking while the queue is full. It returns false once the
+// scheduler is closed.
+func (s *Scheduler) Push(info models.DiscoveryInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.closed && s.queued >= s.maxQueued {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return false
+	}
+	if len(s.queues[info.Namespace]) == 0 {
+		s.order = append(s.order, info.Namespace)
+	}
+	s.queues[info.Namespace] = append(s.queues[info.Namespace], info)
+	s.queued++
+	s.cond.Broadcast()
+	return true
+}
+
+// Pop returns the next task from the first namespace in round-robin order that
+// is below its concurrency quota, blocking until one is available. It returns
+// false once the scheduler is closed. Callers must call Done after the task.
+func (s *Scheduler) Pop() (models.DiscoveryInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if s.closed {
+			return models.DiscoveryInfo{}, false
+		}
+		if info, ok := s.take(); ok {
+			s.cond.Broadcast()
+			return info, true
+		}
+		s.cond.Wait()
+	}
+}
+
+func (s *Scheduler) take() (models.DiscoveryInfo, bool) {
+	for i := range s.order {
+		idx := (s.next + i) % len(s.order)
+		namespace := s.order[idx]
+		if s.active[namespace] >= s.perNamespace {
+			continue
+		}
+		queue := s.queues[namespace]
+		info := queue[0]
+		queue[0] = models.DiscoveryInfo{}
+		if len(queue) == 1 {
+			delete(s.queues, namespace)
+			s.order = append(s.order[:idx], s.order[idx+1:]...)
+			s.next = idx
+		} else {
+			s.queues[namespace] = queue[1:]
+			s.next = idx + 1
+		}
+		if len(s.order) > 0 {
+			s.next %= len(s.order)
+		} else {
+			s.next = 0
+		}
+		s.active[namespace]++
+		s.queued--
+		return info, true
+	}
+	return models.DiscoveryInfo{}, false
+}
+
+// Done releases the concurrency slot taken by a task of namespace
+func (s *Scheduler) Done(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[namespace] <= 1 {
+		delete(s.active, namespace)
+	} else {
+		s.active[namespace]--
+	}
+	s.cond.Broadcast()
+}
+
+// Close wakes up all blocked callers; queued tasks are dropped
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+}
+
+// Stats returns the number of queued tasks and of namespaces with queued tasks
+func (s *Scheduler) Stats() (queued, namespaces int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queued, len(s.order)
+}
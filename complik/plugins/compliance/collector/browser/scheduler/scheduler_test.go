@@ -0,0 +1,116 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScheduler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Scheduler Suite")
+}
+
+func task(namespace string, i int) models.DiscoveryInfo {
+	return models.DiscoveryInfo{Namespace: namespace, Name: fmt.Sprintf("%s-%d", namespace, i)}
+}
+
+var _ = Describe("Scheduler", func() {
+	It("should interleave namespaces in round-robin order", func() {
+		s := NewScheduler(10, 100)
+		for i := range 5 {
+			Expect(s.Push(task("ns-big", i))).To(BeTrue())
+		}
+		Expect(s.Push(task("ns-a", 0))).To(BeTrue())
+		Expect(s.Push(task("ns-b", 0))).To(BeTrue())
+
+		var namespaces []string
+		for range 7 {
+			info, ok := s.Pop()
+			Expect(ok).To(BeTrue())
+			namespaces = append(namespaces, info.Namespace)
+			s.Done(info.Namespace)
+		}
+		Expect(namespaces[:3]).To(ConsistOf("ns-big", "ns-a", "ns-b"))
+		Expect(namespaces[3:]).To(Equal([]string{"ns-big", "ns-big", "ns-big", "ns-big"}))
+	})
+
+	It("should keep per-namespace order", func() {
+		s := NewScheduler(1, 100)
+		for i := range 3 {
+			s.Push(task("ns-a", i))
+		}
+		for i := range 3 {
+			info, _ := s.Pop()
+			Expect(info.Name).To(Equal(fmt.Sprintf("ns-a-%d", i)))
+			s.Done(info.Namespace)
+		}
+	})
+
+	It("should enforce the per-namespace quota", func() {
+		s := NewScheduler(1, 100)
+		s.Push(task("ns-big", 0))
+		s.Push(task("ns-big", 1))
+		s.Push(task("ns-a", 0))
+
+		first, _ := s.Pop()
+		Expect(first.Namespace).To(Equal("ns-big"))
+		second, _ := s.Pop()
+		Expect(second.Namespace).To(Equal("ns-a"))
+
+		popped := make(chan models.DiscoveryInfo)
+		go func() {
+			info, _ := s.Pop()
+			popped <- info
+		}()
+		Consistently(popped, 50*time.Millisecond).ShouldNot(Receive())
+		s.Done("ns-big")
+		Eventually(popped).Should(Receive(HaveField("Name", "ns-big-1")))
+	})
+
+	It("should block producers while the queue is full", func() {
+		s := NewScheduler(1, 1)
+		Expect(s.Push(task("ns-a", 0))).To(BeTrue())
+
+		pushed := make(chan bool)
+		go func() { pushed <- s.Push(task("ns-b", 0)) }()
+		Consistently(pushed, 50*time.Millisecond).ShouldNot(Receive())
+
+		_, ok := s.Pop()
+		Expect(ok).To(BeTrue())
+		Eventually(pushed).Should(Receive(BeTrue()))
+		queued, namespaces := s.Stats()
+		Expect(queued).To(Equal(1))
+		Expect(namespaces).To(Equal(1))
+	})
+
+	It("should release blocked callers on close", func() {
+		s := NewScheduler(1, 1)
+		done := make(chan bool)
+		go func() {
+			_, ok := s.Pop()
+			done <- ok
+		}()
+		s.Close()
+		Eventually(done).Should(Receive(BeFalse()))
+		Expect(s.Push(task("ns-a", 0))).To(BeFalse())
+	})
+})
@@ -0,0 +1,172 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package screenshot captures the full page screenshots of the collector in
+// the configured format and resolution. Screenshots larger than the size
+// limit are captured again at a lower quality and then at a lower
+// resolution until they fit.
+package screenshot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Formats supported by the capture API of Chrome
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+const (
+	// DefaultQuality is the quality of lossy formats without a configured quality
+	DefaultQuality = 75
+
+	// minQuality and minScale bound the reductions of oversized screenshots
+	minQuality = 30
+	minScale   = 0.25
+	// qualityStep and scaleStep are the reductions of every further attempt
+	qualityStep = 15
+	scaleStep   = 0.7
+)
+
+// Config is the screenshot section of the browser collector settings
+type Config struct {
+	// Format is jpeg (default), png or webp
+	Format string `json:"format"`
+	// Quality of jpeg and webp screenshots, 1 to 100
+	Quality int `json:"quality"`
+	// Scale of the screenshot to the page, e.g. 0.5 for half the width and
+	// height; 1 by default
+	Scale float64 `json:"scale"`
+	// MaxHeight crops pages taller than that many CSS pixels, 0 keeps the
+	// full page
+	MaxHeight int `json:"maxHeight"`
+	// MaxBytes is the size screenshots are reduced to, 0 for no limit
+	MaxBytes int `json:"maxBytes"`
+}
+
+// Validate checks the format and the ranges of the settings
+func (c Config) Validate() error {
+	switch strings.ToLower(c.Format) {
+	case "", FormatJPEG, FormatPNG, FormatWebP:
+	case "avif":
+		return fmt.Errorf("screenshot format avif is not supported by the Chrome capture API, use webp")
+	default:
+		return fmt.Errorf("unknown screenshot format %q", c.Format)
+	}
+	if c.Quality < 0 || c.Quality > 100 {
+		return fmt.Errorf("screenshot quality %d is not between 1 and 100", c.Quality)
+	}
+	if c.Scale < 0 || c.Scale > 1 {
+		return fmt.Errorf("screenshot scale %g is not between 0 and 1", c.Scale)
+	}
+	if c.MaxHeight < 0 || c.MaxBytes < 0 {
+		return fmt.Errorf("screenshot maxHeight and maxBytes must not be negative")
+	}
+	return nil
+}
+
+func (c Config) format() proto.PageCaptureScreenshotFormat {
+	switch strings.ToLower(c.Format) {
+	case FormatPNG:
+		return proto.PageCaptureScreenshotFormatPng
+	case FormatWebP:
+		return proto.PageCaptureScreenshotFormatWebp
+	default:
+		return proto.PageCaptureScreenshotFormatJpeg
+	}
+}
+
+// Attempt is the quality and scale of one capture
+type Attempt struct {
+	// Quality is 0 for png
+	Quality int
+	Scale   float64
+}
+
+// Attempts returns the captures tried in order: the configured one, then with
+// a lower quality down to 30 and then with a lower scale down to 0.25. Only
+// the first is used without a size limit.
+func (c Config) Attempts() []Attempt {
+	first := Attempt{Quality: c.Quality, Scale: c.Scale}
+	if first.Quality == 0 {
+		first.Quality = DefaultQuality
+	}
+	if c.format() == proto.PageCaptureScreenshotFormatPng {
+		first.Quality = 0
+	}
+	if first.Scale == 0 {
+		first.Scale = 1
+	}
+	attempts := []Attempt{first}
+	if c.MaxBytes == 0 {
+		return attempts
+	}
+	next := first
+	for next.Quality > minQuality {
+		next.Quality = max(next.Quality-qualityStep, minQuality)
+		attempts = append(attempts, next)
+	}
+	for next.Scale > minScale {
+		next.Scale = math.Max(math.Round(next.Scale*scaleStep*100)/100, minScale)
+		attempts = append(attempts, next)
+	}
+	return attempts
+}
+
+// Capture takes the full page screenshot of page. When every attempt exceeds
+// MaxBytes the smallest screenshot is returned.
+func (c Config) Capture(ctx context.Context, page *rod.Page) ([]byte, error) {
+	page = page.Context(ctx)
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	if metrics.CSSContentSize == nil {
+		return nil, fmt.Errorf("failed to get page size")
+	}
+	width, height := metrics.CSSContentSize.Width, metrics.CSSContentSize.Height
+	if c.MaxHeight > 0 {
+		height = math.Min(height, float64(c.MaxHeight))
+	}
+
+	var smallest []byte
+	for _, attempt := range c.Attempts() {
+		req := &proto.PageCaptureScreenshot{
+			Format: c.format(),
+			Clip:   &proto.PageViewport{Width: width, Height: height, Scale: attempt.Scale},
+		}
+		if attempt.Quality > 0 {
+			req.Quality = &attempt.Quality
+		}
+		data, err := page.Screenshot(true, req)
+		if err != nil {
+			return nil, err
+		}
+		if smallest == nil || len(data) < len(smallest) {
+			smallest = data
+		}
+		if c.MaxBytes == 0 || len(data) <= c.MaxBytes {
+			return data, nil
+		}
+	}
+	return smallest, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package screenshot
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScreenshot(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Screenshot Suite")
+}
+
+var _ = Describe("Config", func() {
+	It("should accept the formats of the capture API", func() {
+		for _, format := range []string{"", "jpeg", "png", "webp", "WebP"} {
+			Expect(Config{Format: format}.Validate()).To(Succeed())
+		}
+		Expect(Config{Format: "avif"}.Validate()).To(MatchError(ContainSubstring("use webp")))
+		Expect(Config{Format: "gif"}.Validate()).To(MatchError(ContainSubstring("unknown screenshot format")))
+		Expect(Config{Quality: 101}.Validate()).NotTo(Succeed())
+		Expect(Config{Scale: 1.5}.Validate()).NotTo(Succeed())
+		Expect(Config{MaxBytes: -1}.Validate()).NotTo(Succeed())
+	})
+
+	It("should capture once as JPEG at full resolution by default", func() {
+		Expect(Config{}.Attempts()).To(Equal([]Attempt{{Quality: DefaultQuality, Scale: 1}}))
+	})
+
+	It("should lower the quality and then the scale of oversized screenshots", func() {
+		attempts := Config{Format: "webp", Quality: 70, Scale: 0.75, MaxBytes: 200 << 10}.Attempts()
+		Expect(attempts).To(Equal([]Attempt{
+			{Quality: 70, Scale: 0.75},
+			{Quality: 55, Scale: 0.75},
+			{Quality: 40, Scale: 0.75},
+			{Quality: 30, Scale: 0.75},
+			{Quality: 30, Scale: 0.52},
+			{Quality: 30, Scale: 0.36},
+			{Quality: 30, Scale: 0.25},
+		}))
+	})
+
+	It("should only lower the scale of PNG screenshots", func() {
+		attempts := Config{Format: "png", MaxBytes: 1 << 20}.Attempts()
+		Expect(attempts[0]).To(Equal(Attempt{Scale: 1}))
+		Expect(attempts[1]).To(Equal(Attempt{Scale: 0.7}))
+		Expect(attempts[len(attempts)-1]).To(Equal(Attempt{Scale: minScale}))
+	})
+})
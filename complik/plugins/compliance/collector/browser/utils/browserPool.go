@@ -15,6 +15,8 @@
 // Package utils provides a browser pool implementation for managing headless browser instances.
 // The pool supports concurrent access, automatic instance expiration, and graceful cleanup.
 // It includes a wait queue mechanism to handle requests when the pool is at capacity.
+// Browsers can be launched ahead of time, keep blank pages ready and are recycled after a
+// number of pages or above a memory limit.
 package utils
 
 import (
@@ -23,12 +25,23 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons a browser instance is recycled
+const (
+	RecycleAge    = "age"
+	RecyclePages  = "pages"
+	RecycleMemory = "memory"
 )
 
 type BrowserInstance struct {
@@ -37,6 +50,27 @@ type BrowserInstance struct {
 	Created  time.Time
 	InUse    bool
 	PID      int // Chrome process ID for tracking
+	Served   int // Pages served, counted by Get
+
+	// rss is the last measured memory of the browser processes, in bytes
+	rss atomic.Int64
+
+	spareMu   sync.Mutex
+	spare     []*rod.Page
+	refilling bool
+}
+
+// PoolOptions tune the warmup and recycling of a pool. The zero value
+// launches browsers on demand and recycles them by age only.
+type PoolOptions struct {
+	// Warmup browsers are launched by Warmup and relaunched after recycling
+	Warmup int
+	// Pages blank pages are kept ready in every browser
+	Pages int
+	// MaxPages recycles a browser once it served that many pages
+	MaxPages int
+	// MaxRSS recycles a browser whose processes use more memory, in bytes
+	MaxRSS int64
 }
 
 type BrowserPool struct {
@@ -49,21 +83,47 @@ type BrowserPool struct {
 	log         logger.Logger
 	cleanupWg   sync.WaitGroup // Wait group for tracking cleanup goroutines
 	cleanupDone chan struct{}  // Signal channel for background cleanup goroutine
+	launchFlags map[string]string
+	opts        PoolOptions
+	launching   int // Browsers being launched outside the lock
+
+	launches       atomic.Int64
+	launchFailures atomic.Int64
+	pageHits       atomic.Int64
+	pageMisses     atomic.Int64
+	recycles       map[string]int64
+	acquire        prometheus.Histogram
 }
 
-func NewBrowserPool(maxSize int, maxAge time.Duration) *BrowserPool {
+// NewBrowserPool creates a pool of up to maxSize browsers that are replaced
+// after maxAge. launchFlags are passed to every launched browser.
+func NewBrowserPool(maxSize int, maxAge time.Duration, launchFlags map[string]string, opts PoolOptions) *BrowserPool {
 	pool := &BrowserPool{
 		instances:   make([]*BrowserInstance, 0, maxSize),
 		maxSize:     maxSize,
 		maxAge:      maxAge,
+		launchFlags: launchFlags,
+		opts:        opts,
 		waitQueue:   make(chan chan *BrowserInstance, 100), // Buffered queue
 		log:         logger.GetLogger().WithField("component", "browser_pool"),
 		cleanupDone: make(chan struct{}),
+		recycles:    make(map[string]int64),
+		acquire: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "acquire_seconds",
+			Help:      "Time taken to get a browser from the pool, including launches and waits.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		}),
 	}
 
 	pool.log.Info("Browser pool created", logger.Fields{
 		"max_size":        maxSize,
 		"max_age_minutes": maxAge.Minutes(),
+		"warmup":          opts.Warmup,
+		"spare_pages":     opts.Pages,
+		"max_pages":       opts.MaxPages,
+		"max_rss_mb":      opts.MaxRSS >> 20,
 	})
 
 	// Start background cleanup goroutine
@@ -71,7 +131,22 @@ func NewBrowserPool(maxSize int, maxAge time.Duration) *BrowserPool {
 	return pool
 }
 
+// Get takes a browser from the pool, launching one or waiting for one when
+// none is free
 func (p *BrowserPool) Get(ctx context.Context) (*BrowserInstance, error) {
+	start := time.Now()
+	instance, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	instance.Served++
+	p.mu.Unlock()
+	p.acquire.Observe(time.Since(start).Seconds())
+	return instance, nil
+}
+
+func (p *BrowserPool) get(ctx context.Context) (*BrowserInstance, error) {
 	p.mu.RLock()
 	for _, instance := range p.instances {
 		if !instance.InUse && time.Since(instance.Created) < p.maxAge {
@@ -88,7 +163,7 @@ func (p *BrowserPool) Get(ctx context.Context) (*BrowserInstance, error) {
 	}
 	p.mu.RUnlock()
 	p.mu.Lock()
-	if len(p.instances) < p.maxSize {
+	if len(p.instances)+p.launching < p.maxSize {
 		instance, err := p.createInstance()
 		if err != nil {
 			p.mu.Unlock()
@@ -124,23 +199,70 @@ func (p *BrowserPool) Put(instance *BrowserInstance) {
 		return
 	}
 
+	// Measured before locking, reading the process table takes a while
+	if p.opts.MaxRSS > 0 {
+		p.measure(instance)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if time.Since(instance.Created) >= p.maxAge {
-		p.log.Info("Instance expired, cleaning up", logger.Fields{
-			"pid": instance.PID,
-			"age": time.Since(instance.Created).String(),
+	if reason := p.recycleReason(instance); reason != "" {
+		p.log.Info("Recycling browser instance", logger.Fields{
+			"pid":       instance.PID,
+			"reason":    reason,
+			"age":       time.Since(instance.Created).String(),
+			"served":    instance.Served,
+			"rss_bytes": instance.rss.Load(),
 		})
+		p.recycles[reason]++
 		p.removeInstance(instance)
 		p.cleanupWg.Add(1)
 		go func() {
 			defer p.cleanupWg.Done()
 			p.cleanupInstance(instance)
 		}()
+		if p.opts.Warmup > 0 {
+			go p.replenish()
+		}
+		return
+	}
+	p.release(instance)
+}
+
+// recycleReason returns why instance must be replaced, "" to keep it
+func (p *BrowserPool) recycleReason(instance *BrowserInstance) string {
+	switch {
+	case time.Since(instance.Created) >= p.maxAge:
+		return RecycleAge
+	case p.opts.MaxPages > 0 && instance.Served >= p.opts.MaxPages:
+		return RecyclePages
+	case p.opts.MaxRSS > 0 && instance.rss.Load() >= p.opts.MaxRSS:
+		return RecycleMemory
+	default:
+		return ""
+	}
+}
+
+// measure records the memory used by the processes of instance
+func (p *BrowserPool) measure(instance *BrowserInstance) {
+	rss, err := processTreeRSS(procRoot, instance.PID)
+	if err != nil {
+		p.log.Debug("Failed to measure browser memory", logger.Fields{
+			"pid":   instance.PID,
+			"error": err.Error(),
+		})
 		return
 	}
+	instance.rss.Store(rss)
+}
 
+// release hands instance to a waiter or marks it available. The lock must
+// be held.
+func (p *BrowserPool) release(instance *BrowserInstance) {
+	if p.opts.Pages > 0 {
+		go p.refill(instance)
+	}
 	// Check if there are any waiters
 	select {
 	case waitChan := <-p.waitQueue:
@@ -170,6 +292,9 @@ func (p *BrowserPool) createInstance() (*BrowserInstance, error) {
 		Set("disable-web-security", "").
 		Set("disable-features", "VizDisplayCompositor").
 		Headless(true)
+	for name, value := range p.launchFlags {
+		l = l.Set(flags.Flag(name), value)
+	}
 	u, err := l.Launch()
 	if err != nil {
 		p.log.Error("Failed to launch browser", logger.Fields{
@@ -194,15 +319,181 @@ func (p *BrowserPool) createInstance() (*BrowserInstance, error) {
 		PID:      pid,
 	}
 
+	p.launches.Add(1)
 	p.log.Info("Browser instance created successfully", logger.Fields{
-		"instance_count": len(p.instances) + 1,
-		"pid":            pid,
-		"control_url":    u,
+		"pid":         pid,
+		"control_url": u,
 	})
 
 	return instance, nil
 }
 
+// Warmup launches browsers until PoolOptions.Warmup of them run, so the
+// first targets do not wait for a cold start. Browsers are launched one
+// after another to keep the CPU available for collections.
+func (p *BrowserPool) Warmup() {
+	start := time.Now()
+	if launched := p.replenish(); launched > 0 {
+		p.log.Info("Browser pool warmed up", logger.Fields{
+			"launched":    launched,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// replenish launches browsers until the warmup size is reached and returns
+// how many were launched
+func (p *BrowserPool) replenish() int {
+	launched := 0
+	for p.reserve() {
+		instance, err := p.launch()
+		p.mu.Lock()
+		p.launching--
+		if err != nil {
+			p.mu.Unlock()
+			p.launchFailures.Add(1)
+			p.log.Error("Failed to warm up browser instance", logger.Fields{
+				"error": err.Error(),
+			})
+			return launched
+		}
+		if p.closed {
+			p.mu.Unlock()
+			p.cleanupInstance(instance)
+			return launched
+		}
+		p.instances = append(p.instances, instance)
+		p.release(instance)
+		p.mu.Unlock()
+		launched++
+	}
+	return launched
+}
+
+// reserve claims a slot for a warmup launch
+func (p *BrowserPool) reserve() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	target := min(p.opts.Warmup, p.maxSize)
+	if p.closed || len(p.instances)+p.launching >= target {
+		return false
+	}
+	p.launching++
+	return true
+}
+
+// launch creates an instance outside the lock, turning the panics of rod
+// into errors
+func (p *BrowserPool) launch() (*BrowserInstance, error) {
+	var (
+		instance *BrowserInstance
+		err      error
+	)
+	if panicked := rod.Try(func() {
+		instance, err = p.createInstance()
+	}); panicked != nil {
+		return nil, panicked
+	}
+	return instance, err
+}
+
+// Page returns a blank page of the default browser context of instance,
+// one of its spare pages when there is one
+func (p *BrowserPool) Page(instance *BrowserInstance) (*rod.Page, error) {
+	instance.spareMu.Lock()
+	if n := len(instance.spare); n > 0 {
+		page := instance.spare[n-1]
+		instance.spare = instance.spare[:n-1]
+		instance.spareMu.Unlock()
+		p.pageHits.Add(1)
+		return page, nil
+	}
+	instance.spareMu.Unlock()
+	if p.opts.Pages > 0 {
+		p.pageMisses.Add(1)
+	}
+	return instance.Browser.Page(proto.TargetCreateTarget{})
+}
+
+// refill creates the spare pages of instance
+func (p *BrowserPool) refill(instance *BrowserInstance) {
+	instance.spareMu.Lock()
+	if instance.refilling {
+		instance.spareMu.Unlock()
+		return
+	}
+	instance.refilling = true
+	instance.spareMu.Unlock()
+	defer func() {
+		instance.spareMu.Lock()
+		instance.refilling = false
+		instance.spareMu.Unlock()
+	}()
+
+	for {
+		instance.spareMu.Lock()
+		missing := p.opts.Pages - len(instance.spare)
+		instance.spareMu.Unlock()
+		if missing <= 0 || instance.Browser == nil {
+			return
+		}
+		page, err := instance.Browser.Page(proto.TargetCreateTarget{})
+		if err != nil {
+			// The browser is usually being recycled
+			p.log.Debug("Failed to create spare page", logger.Fields{
+				"pid":   instance.PID,
+				"error": err.Error(),
+			})
+			return
+		}
+		instance.spareMu.Lock()
+		instance.spare = append(instance.spare, page)
+		instance.spareMu.Unlock()
+	}
+}
+
+// PoolStats is a snapshot of the pool for metrics
+type PoolStats struct {
+	Instances      int              `json:"instances"`
+	InUse          int              `json:"in_use"`
+	Launching      int              `json:"launching"`
+	SparePages     int              `json:"spare_pages"`
+	RSSBytes       int64            `json:"rss_bytes"`
+	Launches       int64            `json:"launches"`
+	LaunchFailures int64            `json:"launch_failures"`
+	PageHits       int64            `json:"page_hits"`
+	PageMisses     int64            `json:"page_misses"`
+	Recycles       map[string]int64 `json:"recycles"`
+}
+
+// Stats returns a snapshot of the pool
+func (p *BrowserPool) Stats() PoolStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	stats := PoolStats{
+		Instances:      len(p.instances),
+		Launching:      p.launching,
+		Launches:       p.launches.Load(),
+		LaunchFailures: p.launchFailures.Load(),
+		PageHits:       p.pageHits.Load(),
+		PageMisses:     p.pageMisses.Load(),
+		Recycles:       make(map[string]int64, len(p.recycles)),
+	}
+	for reason, count := range p.recycles {
+		stats.Recycles[reason] = count
+	}
+	for _, instance := range p.instances {
+		if instance.InUse {
+			stats.InUse++
+		}
+		stats.RSSBytes += instance.rss.Load()
+		instance.spareMu.Lock()
+		stats.SparePages += len(instance.spare)
+		instance.spareMu.Unlock()
+	}
+	return stats
+}
+
 func (p *BrowserPool) cleanupExpired() {
 	var validInstances []*BrowserInstance
 	var expiredInstances []*BrowserInstance
@@ -219,6 +510,10 @@ func (p *BrowserPool) cleanupExpired() {
 			"expired_count":   len(expiredInstances),
 			"remaining_count": len(validInstances),
 		})
+		p.recycles[RecycleAge] += int64(len(expiredInstances))
+		if p.opts.Warmup > 0 {
+			go p.replenish()
+		}
 		for _, inst := range expiredInstances {
 			p.log.Debug("Expiring instance", logger.Fields{
 				"pid": inst.PID,
@@ -0,0 +1,99 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "complik"
+	metricsSubsystem = "browser_pool"
+)
+
+var (
+	instancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "instances"),
+		"Browser instances by state.",
+		[]string{"state"}, nil,
+	)
+	sparePagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "spare_pages"),
+		"Blank pages ready for the next targets.",
+		nil, nil,
+	)
+	rssDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "rss_bytes"),
+		"Last measured resident memory of the browser processes.",
+		nil, nil,
+	)
+	launchesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "launches_total"),
+		"Browsers launched.",
+		nil, nil,
+	)
+	launchFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "launch_failures_total"),
+		"Failed warmup launches.",
+		nil, nil,
+	)
+	recyclesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "recycles_total"),
+		"Browsers recycled by reason.",
+		[]string{"reason"}, nil,
+	)
+	pagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "pages_total"),
+		"Pages taken from the spare pages (hit) or created on demand (miss).",
+		[]string{"result"}, nil,
+	)
+)
+
+// PoolExporter publishes the statistics of a pool as Prometheus metrics
+type PoolExporter struct {
+	pool *BrowserPool
+}
+
+func NewPoolExporter(pool *BrowserPool) *PoolExporter {
+	return &PoolExporter{pool: pool}
+}
+
+func (e *PoolExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instancesDesc
+	ch <- sparePagesDesc
+	ch <- rssDesc
+	ch <- launchesDesc
+	ch <- launchFailuresDesc
+	ch <- recyclesDesc
+	ch <- pagesDesc
+	e.pool.acquire.Describe(ch)
+}
+
+func (e *PoolExporter) Collect(ch chan<- prometheus.Metric) {
+	stats := e.pool.Stats()
+	ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(stats.InUse), "busy")
+	ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(stats.Instances-stats.InUse), "idle")
+	ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(stats.Launching), "launching")
+	ch <- prometheus.MustNewConstMetric(sparePagesDesc, prometheus.GaugeValue, float64(stats.SparePages))
+	ch <- prometheus.MustNewConstMetric(rssDesc, prometheus.GaugeValue, float64(stats.RSSBytes))
+	ch <- prometheus.MustNewConstMetric(launchesDesc, prometheus.CounterValue, float64(stats.Launches))
+	ch <- prometheus.MustNewConstMetric(launchFailuresDesc, prometheus.CounterValue, float64(stats.LaunchFailures))
+	for _, reason := range []string{RecycleAge, RecyclePages, RecycleMemory} {
+		ch <- prometheus.MustNewConstMetric(recyclesDesc, prometheus.CounterValue, float64(stats.Recycles[reason]), reason)
+	}
+	ch <- prometheus.MustNewConstMetric(pagesDesc, prometheus.CounterValue, float64(stats.PageHits), "hit")
+	ch <- prometheus.MustNewConstMetric(pagesDesc, prometheus.CounterValue, float64(stats.PageMisses), "miss")
+	e.pool.acquire.Collect(ch)
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Browser Pool Suite")
+}
+
+// writeStat adds a process to a fake process table
+func writeStat(root string, pid, ppid int, comm string, rss int64) {
+	dir := filepath.Join(root, fmt.Sprint(pid))
+	Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+	fields := fmt.Sprintf("%d (%s) S %d 1 1 0 -1 4194560 100 0 0 0 10 5 0 0 20 0 4 0 100 200000 %d", pid, comm, ppid, rss)
+	Expect(os.WriteFile(filepath.Join(dir, "stat"), []byte(fields), 0o644)).To(Succeed())
+}
+
+var _ = Describe("Memory", func() {
+	It("should parse stat lines with odd command names", func() {
+		ppid, rss, err := parseStat("42 (chrome (renderer) x) S 7 1 1 0 -1 4194560 100 0 0 0 10 5 0 0 20 0 4 0 100 200000 1234 18446744073709551615")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ppid).To(Equal(7))
+		Expect(rss).To(Equal(int64(1234)))
+
+		_, _, err = parseStat("42 chrome S 7")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should sum the resident memory of the process tree", func() {
+		root := GinkgoT().TempDir()
+		writeStat(root, 10, 1, "chrome", 100)
+		writeStat(root, 11, 10, "chrome renderer", 50)
+		writeStat(root, 12, 11, "chrome", 25)
+		writeStat(root, 20, 1, "other", 1000)
+		Expect(os.MkdirAll(filepath.Join(root, "self"), 0o755)).To(Succeed())
+
+		rss, err := processTreeRSS(root, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rss).To(Equal(175 * int64(os.Getpagesize())))
+
+		_, err = processTreeRSS(root, 99)
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should measure the test process on Linux", func() {
+		if _, err := os.Stat("/proc/self/stat"); err != nil {
+			Skip("no process table")
+		}
+		rss, err := processTreeRSS(procRoot, os.Getpid())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rss).To(BeNumerically(">", 0))
+	})
+})
+
+var _ = Describe("BrowserPool", func() {
+	var pool *BrowserPool
+
+	BeforeEach(func() {
+		pool = NewBrowserPool(2, time.Hour, nil, PoolOptions{MaxPages: 3, MaxRSS: 1 << 30})
+		DeferCleanup(pool.Close)
+	})
+
+	It("should recycle browsers by age, pages served and memory", func() {
+		instance := &BrowserInstance{Created: time.Now()}
+		Expect(pool.recycleReason(instance)).To(BeEmpty())
+
+		instance.Served = 3
+		Expect(pool.recycleReason(instance)).To(Equal(RecyclePages))
+
+		instance.Served = 1
+		instance.rss.Store(2 << 30)
+		Expect(pool.recycleReason(instance)).To(Equal(RecycleMemory))
+
+		instance.Created = time.Now().Add(-2 * time.Hour)
+		Expect(pool.recycleReason(instance)).To(Equal(RecycleAge))
+	})
+
+	It("should not warm up without a warmup size", func() {
+		pool.Warmup()
+		Expect(pool.Stats().Launches).To(BeZero())
+	})
+
+	It("should export the pool statistics", func() {
+		instance := &BrowserInstance{Created: time.Now(), InUse: true}
+		instance.rss.Store(4096)
+		pool.instances = append(pool.instances, instance, &BrowserInstance{Created: time.Now()})
+		pool.recycles[RecyclePages] = 2
+
+		stats := pool.Stats()
+		Expect(stats.Instances).To(Equal(2))
+		Expect(stats.InUse).To(Equal(1))
+		Expect(stats.RSSBytes).To(Equal(int64(4096)))
+
+		exporter := NewPoolExporter(pool)
+		Expect(testutil.CollectAndCount(exporter, "complik_browser_pool_recycles_total")).To(Equal(3))
+		Expect(testutil.CollectAndCount(exporter, "complik_browser_pool_acquire_seconds")).To(Equal(1))
+		pool.instances = nil
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot is where the process table is read from
+const procRoot = "/proc"
+
+// processTreeRSS returns the resident memory of pid and its descendants in
+// bytes. Chrome runs its renderers and GPU process as children of the
+// browser process, which alone says little about the memory of a browser.
+func processTreeRSS(root string, pid int) (int64, error) {
+	if pid <= 0 {
+		return 0, errors.New("unknown process")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return 0, err
+	}
+	children := make(map[int][]int)
+	pages := make(map[int]int64)
+	for _, entry := range entries {
+		id, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes may exit while the table is read
+		data, err := os.ReadFile(filepath.Join(root, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		ppid, rss, err := parseStat(string(data))
+		if err != nil {
+			continue
+		}
+		children[ppid] = append(children[ppid], id)
+		pages[id] = rss
+	}
+	if _, ok := pages[pid]; !ok {
+		return 0, fmt.Errorf("process %d not found", pid)
+	}
+
+	var total int64
+	queue := []int{pid}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		total += pages[id]
+		queue = append(queue, children[id]...)
+	}
+	return total * int64(os.Getpagesize()), nil
+}
+
+// parseStat returns the parent and the resident pages of a /proc/<pid>/stat
+// line. The command name may contain spaces and parentheses, so the fields
+// are counted from its closing parenthesis.
+func parseStat(stat string) (ppid int, rss int64, err error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, 0, errors.New("malformed stat")
+	}
+	// Fields from the state (3) on, rss is field 24
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return 0, 0, errors.New("malformed stat")
+	}
+	if ppid, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, err
+	}
+	if rss, err = strconv.ParseInt(fields[21], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return ppid, rss, nil
+}
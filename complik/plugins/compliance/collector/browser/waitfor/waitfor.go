@@ -0,0 +1,266 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package waitfor decides when a page is ready to be collected. By default
+// the collector takes the page once its load event fired, which is too early
+// for single page applications that render after a WebSocket or an API call.
+// Rules matched by host name wait for such network activity, a selector or
+// an idle network on top of the load event.
+package waitfor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrNotReady is returned when the conditions of a rule were not met in time
+var ErrNotReady = errors.New("page not ready")
+
+// defaultTimeoutSecond bounds the wait of rules without a timeout
+const defaultTimeoutSecond = 30
+
+// Config is the waitFor section of the browser collector settings
+type Config struct {
+	// Rules are matched in order, the first rule matching the host applies
+	Rules []Rule `json:"rules"`
+}
+
+// Rule lists the conditions a page must meet before it is collected. All
+// set conditions must be met.
+type Rule struct {
+	// Hosts are host names, "*.example.com" for subdomains or "*" for all
+	Hosts []string `json:"hosts"`
+
+	// WebSocket waits for the first frame received on a WebSocket whose URL
+	// contains the value, "*" for any WebSocket
+	WebSocket string `json:"webSocket"`
+	// Response waits for a response to a URL containing the value
+	Response string `json:"response"`
+	// Selector waits for an element matching the CSS selector
+	Selector string `json:"selector"`
+	// NetworkIdleMillis waits until no request was pending for that long;
+	// WebSocket, media, image and font requests are ignored
+	NetworkIdleMillis int `json:"networkIdleMillis"`
+	// SettleMillis is waited after the other conditions, e.g. for animations
+	SettleMillis int `json:"settleMillis"`
+
+	// TimeoutSecond bounds the wait, 30 seconds by default
+	TimeoutSecond int `json:"timeoutSecond"`
+	// Required fails the collection when the conditions are not met in time.
+	// Otherwise the page is collected as it is.
+	Required bool `json:"required"`
+}
+
+// Validate checks that every rule has hosts and a condition
+func (c Config) Validate() error {
+	for i, rule := range c.Rules {
+		if len(rule.Hosts) == 0 {
+			return fmt.Errorf("wait rule %d has no hosts", i)
+		}
+		for _, host := range rule.Hosts {
+			if strings.TrimSpace(host) == "" {
+				return fmt.Errorf("wait rule %d has an empty host", i)
+			}
+		}
+		if rule.WebSocket == "" && rule.Response == "" && rule.Selector == "" &&
+			rule.NetworkIdleMillis <= 0 && rule.SettleMillis <= 0 {
+			return fmt.Errorf("wait rule %d has no condition", i)
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule applying to host, nil when there is none
+func (c Config) Match(host string) *Rule {
+	host = strings.ToLower(host)
+	for i := range c.Rules {
+		for _, pattern := range c.Rules[i].Hosts {
+			if matchHost(strings.ToLower(strings.TrimSpace(pattern)), host) {
+				return &c.Rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+func matchHost(pattern, host string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*."):
+		return strings.HasSuffix(host, pattern[1:])
+	default:
+		return pattern == host
+	}
+}
+
+// matchURL reports whether rawURL contains pattern, "*" matching any URL
+func matchURL(pattern, rawURL string) bool {
+	return pattern == "*" || strings.Contains(rawURL, pattern)
+}
+
+func (r *Rule) timeout() time.Duration {
+	if r.TimeoutSecond > 0 {
+		return time.Duration(r.TimeoutSecond) * time.Second
+	}
+	return defaultTimeoutSecond * time.Second
+}
+
+// Watch observes the network activity of a page for a rule. It is started
+// before the navigation so that early WebSockets and responses are seen.
+type Watch struct {
+	rule *Rule
+	page *rod.Page
+	stop context.CancelFunc
+
+	websocket *signal
+	response  *signal
+}
+
+// Start watches page for rule. The watch must be ended with Wait or Stop.
+func Start(ctx context.Context, page *rod.Page, rule *Rule) *Watch {
+	w := &Watch{rule: rule, page: page}
+	if rule.WebSocket == "" && rule.Response == "" {
+		return w
+	}
+	ctx, w.stop = context.WithCancel(ctx)
+	var callbacks []any
+	if rule.WebSocket != "" {
+		w.websocket = newSignal()
+		sockets := make(map[proto.NetworkRequestID]bool)
+		callbacks = append(callbacks,
+			func(e *proto.NetworkWebSocketCreated) bool {
+				if matchURL(rule.WebSocket, e.URL) {
+					sockets[e.RequestID] = true
+				}
+				return false
+			},
+			func(e *proto.NetworkWebSocketFrameReceived) bool {
+				if sockets[e.RequestID] {
+					w.websocket.fire()
+				}
+				return w.met()
+			},
+		)
+	}
+	if rule.Response != "" {
+		w.response = newSignal()
+		callbacks = append(callbacks, func(e *proto.NetworkResponseReceived) bool {
+			if matchURL(rule.Response, e.Response.URL) {
+				w.response.fire()
+			}
+			return w.met()
+		})
+	}
+	// The subscription is made now, the events are consumed in the background
+	go page.Context(ctx).EachEvent(callbacks...)()
+	return w
+}
+
+// met reports whether the network conditions of the watch are met
+func (w *Watch) met() bool {
+	return (w.websocket == nil || w.websocket.fired()) && (w.response == nil || w.response.fired())
+}
+
+// Stop ends watching the network activity
+func (w *Watch) Stop() {
+	if w.stop != nil {
+		w.stop()
+	}
+}
+
+// Wait waits until the page meets the rule after its load event. It returns
+// an error wrapping ErrNotReady naming the first unmet condition, or the
+// error of ctx when ctx ended.
+func (w *Watch) Wait(ctx context.Context) error {
+	defer w.Stop()
+	waitCtx, cancel := context.WithTimeout(ctx, w.rule.timeout())
+	defer cancel()
+
+	notReady := func(condition string, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s: %w", ErrNotReady, condition, err)
+		}
+		return fmt.Errorf("%w: %s", ErrNotReady, condition)
+	}
+	for _, s := range []struct {
+		condition string
+		signal    *signal
+	}{
+		{"websocket " + w.rule.WebSocket, w.websocket},
+		{"response " + w.rule.Response, w.response},
+	} {
+		if s.signal == nil {
+			continue
+		}
+		select {
+		case <-s.signal.done:
+		case <-waitCtx.Done():
+			return notReady(s.condition, nil)
+		}
+	}
+	if w.rule.Selector != "" {
+		if _, err := w.page.Context(waitCtx).Element(w.rule.Selector); err != nil {
+			return notReady("selector "+w.rule.Selector, err)
+		}
+	}
+	if w.rule.NetworkIdleMillis > 0 {
+		idle := time.Duration(w.rule.NetworkIdleMillis) * time.Millisecond
+		w.page.Context(waitCtx).WaitRequestIdle(idle, nil, nil, nil)()
+		if waitCtx.Err() != nil {
+			return notReady("network idle", nil)
+		}
+	}
+	if w.rule.SettleMillis > 0 {
+		select {
+		case <-time.After(time.Duration(w.rule.SettleMillis) * time.Millisecond):
+		case <-waitCtx.Done():
+			return notReady("settle", nil)
+		}
+	}
+	return nil
+}
+
+// signal is closed once when its condition is met
+type signal struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func newSignal() *signal {
+	return &signal{done: make(chan struct{})}
+}
+
+func (s *signal) fire() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *signal) fired() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitfor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWaitFor(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collector Wait For Suite")
+}
+
+var _ = Describe("Config", func() {
+	cfg := Config{Rules: []Rule{
+		{Hosts: []string{"app.example.com"}, Selector: "#root > *"},
+		{Hosts: []string{"*.ws.example.com"}, WebSocket: "/socket"},
+		{Hosts: []string{"*"}, SettleMillis: 500},
+	}}
+
+	It("should match the first rule applying to the host", func() {
+		Expect(cfg.Match("APP.example.com").Selector).To(Equal("#root > *"))
+		Expect(cfg.Match("chat.ws.example.com").WebSocket).To(Equal("/socket"))
+		Expect(cfg.Match("ws.example.com").SettleMillis).To(Equal(500))
+		Expect(Config{}.Match("app.example.com")).To(BeNil())
+	})
+
+	It("should reject rules without hosts or conditions", func() {
+		Expect(cfg.Validate()).To(Succeed())
+		Expect(Config{Rules: []Rule{{Selector: "#root"}}}.Validate()).To(MatchError(ContainSubstring("no hosts")))
+		Expect(Config{Rules: []Rule{{Hosts: []string{""}, Selector: "#root"}}}.Validate()).To(MatchError(ContainSubstring("empty host")))
+		Expect(Config{Rules: []Rule{{Hosts: []string{"*"}, TimeoutSecond: 5}}}.Validate()).To(MatchError(ContainSubstring("no condition")))
+	})
+
+	It("should match URLs by substring", func() {
+		Expect(matchURL("*", "wss://app.example.com/socket")).To(BeTrue())
+		Expect(matchURL("/api/bootstrap", "https://app.example.com/api/bootstrap?v=2")).To(BeTrue())
+		Expect(matchURL("/socket", "wss://app.example.com/live")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Watch", func() {
+	ctx := context.Background()
+
+	It("should wait for the network conditions and settle", func() {
+		w := &Watch{rule: &Rule{WebSocket: "*", SettleMillis: 20}, websocket: newSignal()}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			w.websocket.fire()
+			w.websocket.fire()
+		}()
+		start := time.Now()
+		Expect(w.Wait(ctx)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", 30*time.Millisecond))
+	})
+
+	It("should name the unmet condition after the timeout", func() {
+		w := &Watch{rule: &Rule{Response: "/api/bootstrap", TimeoutSecond: 1}, response: newSignal()}
+		err := w.Wait(ctx)
+		Expect(err).To(MatchError(ErrNotReady))
+		Expect(err).To(MatchError(ContainSubstring("response /api/bootstrap")))
+	})
+
+	It("should return the error of an ended context", func() {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		w := &Watch{rule: &Rule{WebSocket: "*"}, websocket: newSignal()}
+		Expect(w.Wait(canceled)).To(MatchError(context.Canceled))
+	})
+})
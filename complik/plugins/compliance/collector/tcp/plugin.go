@@ -0,0 +1,204 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tcp implements a collector plugin for exposed TCP ports that do not
+// serve websites, such as game servers and databases behind LoadBalancer
+// services. It records the banner and a protocol fingerprint of every TCP
+// discovery and publishes them on the service topic.
+package tcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.ComplianceCollectorTCPName
+	pluginType = constants.ComplianceCollectorPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &TCPPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type TCPPlugin struct {
+	log       logger.Logger
+	prober    *Prober
+	tcpConfig TCPConfig
+}
+
+func (p *TCPPlugin) Name() string {
+	return pluginName
+}
+
+func (p *TCPPlugin) Type() string {
+	return pluginType
+}
+
+type TCPConfig struct {
+	// TimeoutSecond bounds each connection of a probe
+	TimeoutSecond int `json:"timeout"`
+	MaxWorkers    int `json:"maxWorkers"`
+	BannerBytes   int `json:"bannerBytes"`
+}
+
+func (p *TCPPlugin) getDefaultConfig() TCPConfig {
+	return TCPConfig{
+		TimeoutSecond: 3,
+		MaxWorkers:    10,
+		BannerBytes:   256,
+	}
+}
+
+func (p *TCPPlugin) loadConfig(setting string) error {
+	p.tcpConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default TCP collector configuration")
+		return nil
+	}
+	var configFromJSON TCPConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse configuration", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.TimeoutSecond > 0 {
+		p.tcpConfig.TimeoutSecond = configFromJSON.TimeoutSecond
+	}
+	if configFromJSON.MaxWorkers > 0 {
+		p.tcpConfig.MaxWorkers = configFromJSON.MaxWorkers
+	}
+	if configFromJSON.BannerBytes > 0 {
+		p.tcpConfig.BannerBytes = configFromJSON.BannerBytes
+	}
+	return nil
+}
+
+func (p *TCPPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.prober = &Prober{
+		Timeout:   time.Duration(p.tcpConfig.TimeoutSecond) * time.Second,
+		MaxBanner: p.tcpConfig.BannerBytes,
+	}
+
+	subscribe := eventBus.Subscribe(constants.DiscoveryTopic)
+	semaphore := make(chan struct{}, p.tcpConfig.MaxWorkers)
+	go func() {
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				discovery, ok := event.Payload.(models.DiscoveryInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "models.DiscoveryInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if discovery.Protocol != models.ProtocolTCP {
+					continue
+				}
+				semaphore <- struct{}{}
+				go func() {
+					defer func() { <-semaphore }()
+					defer func() {
+						if r := recover(); r != nil {
+							p.log.Error("Goroutine panic in TCP collector", logger.Fields{
+								"panic":       r,
+								"stack_trace": string(debug.Stack()),
+							})
+						}
+					}()
+					eventBus.Publish(constants.ServiceTopic, eventbus.Event{
+						Payload: p.collect(ctx, discovery),
+					})
+				}()
+			case <-ctx.Done():
+				p.log.Info("Shutting down TCP collector plugin")
+				return
+			}
+		}
+	}()
+
+	p.log.Info("TCP collector started", logger.Fields{
+		"timeout_seconds": p.tcpConfig.TimeoutSecond,
+		"max_workers":     p.tcpConfig.MaxWorkers,
+	})
+	return nil
+}
+
+func (p *TCPPlugin) Stop(ctx context.Context) error {
+	p.log.Info("Stopping TCP collector plugin")
+	return nil
+}
+
+func (p *TCPPlugin) collect(ctx context.Context, discovery models.DiscoveryInfo) *models.ServiceInfo {
+	info := &models.ServiceInfo{
+		DiscoveryName: discovery.DiscoveryName,
+		CollectorName: p.Name(),
+		Name:          discovery.Name,
+		Namespace:     discovery.Namespace,
+		Host:          discovery.Host,
+	}
+	if discovery.PodCount == 0 {
+		info.IsEmpty = true
+		info.CollectorMessage = "no pods behind the service"
+		return info
+	}
+	fingerprint, err := p.prober.Probe(ctx, discovery.Host)
+	if err != nil {
+		info.IsEmpty = true
+		info.CollectorMessage = err.Error()
+		p.log.Debug("Port not reachable", logger.Fields{
+			"host":  discovery.Host,
+			"error": err.Error(),
+		})
+		return info
+	}
+	info.Service = fingerprint.Service
+	info.Version = fingerprint.Version
+	info.Banner = fingerprint.Banner
+	p.log.Debug("Port fingerprinted", logger.Fields{
+		"host":      discovery.Host,
+		"namespace": discovery.Namespace,
+		"service":   fingerprint.Service,
+		"version":   fingerprint.Version,
+	})
+	return info
+}
@@ -0,0 +1,279 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Fingerprint is what a probe learned about a port
+type Fingerprint struct {
+	Service string
+	Version string
+	Banner  string
+}
+
+// Prober identifies the service behind a TCP port. It reads the banner of
+// services that greet first, then tries the probes of services that wait
+// for the client, each on a fresh connection.
+type Prober struct {
+	// Timeout bounds every connection and read
+	Timeout time.Duration
+	// MaxBanner is the most bytes kept from a response
+	MaxBanner int
+}
+
+// probe sends a request and recognizes the response of one kind of service
+type probe struct {
+	service string
+	request func(host string, port int) []byte
+	match   func(response []byte) (version string, ok bool)
+}
+
+var probes = []probe{
+	{service: "http", request: httpRequest, match: matchHTTP},
+	{service: "redis", request: fixed("PING\r\n"), match: matchRedis},
+	{service: "postgresql", request: postgresRequest, match: matchPostgres},
+	{service: "minecraft", request: minecraftRequest, match: matchMinecraft},
+}
+
+// Probe fingerprints address, a host:port. An error means the port could
+// not be connected to; a port that answers nothing known gives an empty
+// Service.
+func (p *Prober) Probe(ctx context.Context, address string) (Fingerprint, error) {
+	host, portValue, err := net.SplitHostPort(address)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	port, _ := strconv.Atoi(portValue)
+
+	banner, err := p.exchange(ctx, address, nil)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	if len(banner) > 0 {
+		service, version := matchBanner(banner)
+		return Fingerprint{Service: service, Version: version, Banner: p.printable(banner)}, nil
+	}
+
+	for _, probe := range probes {
+		response, err := p.exchange(ctx, address, probe.request(host, port))
+		if err != nil || len(response) == 0 {
+			continue
+		}
+		if version, ok := probe.match(response); ok {
+			return Fingerprint{Service: probe.service, Version: version, Banner: p.printable(response)}, nil
+		}
+	}
+	if p.isTLS(ctx, address, host) {
+		return Fingerprint{Service: "tls"}, nil
+	}
+	return Fingerprint{}, nil
+}
+
+// exchange connects to address, sends request unless it is nil and returns
+// what the service answered within the timeout
+func (p *Prober) exchange(ctx context.Context, address string, request []byte) ([]byte, error) {
+	dialer := net.Dialer{Timeout: p.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(p.Timeout)); err != nil {
+		return nil, err
+	}
+	if request != nil {
+		if _, err := conn.Write(request); err != nil {
+			return nil, nil
+		}
+	}
+	buf := make([]byte, max(p.MaxBanner, 1024))
+	n, err := io.ReadAtLeast(conn, buf, 1)
+	var netErr net.Error
+	if err != nil && !errors.Is(err, io.EOF) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		return nil, nil
+	}
+	return buf[:n], nil
+}
+
+func (p *Prober) isTLS(ctx context.Context, address, host string) bool {
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: p.Timeout},
+		// Only the handshake matters, not who the certificate is for
+		Config: &tls.Config{InsecureSkipVerify: true, ServerName: host}, //nolint:gosec
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// printable keeps the start of a response with line breaks turned into
+// spaces and other control bytes into dots
+func (p *Prober) printable(data []byte) string {
+	if len(data) > p.MaxBanner {
+		data = data[:p.MaxBanner]
+	}
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\r' || r == '\n' || r == '\t':
+			return ' '
+		case r == utf8.RuneError || r < 0x20 || r == 0x7f:
+			return '.'
+		}
+		return r
+	}, string(data)))
+}
+
+// matchBanner recognizes services that greet first
+func matchBanner(banner []byte) (service, version string) {
+	text := string(banner)
+	firstLine, _, _ := strings.Cut(text, "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	upper := strings.ToUpper(firstLine)
+	switch {
+	case strings.HasPrefix(text, "SSH-"):
+		return "ssh", firstLine
+	case strings.HasPrefix(text, "RFB "):
+		return "vnc", firstLine
+	case banner[0] == 0xff:
+		// Telnet servers open with IAC option negotiation
+		return "telnet", ""
+	case strings.HasPrefix(text, "220") && strings.Contains(upper, "SMTP"):
+		return "smtp", firstLine
+	case strings.HasPrefix(text, "220"):
+		return "ftp", firstLine
+	case strings.HasPrefix(text, "* OK"):
+		return "imap", firstLine
+	case strings.HasPrefix(text, "+OK"):
+		return "pop3", firstLine
+	}
+	// MySQL: 3 byte length, sequence 0, protocol 10 and the server version
+	if len(banner) > 5 && banner[3] == 0 && banner[4] == 0x0a {
+		if end := bytes.IndexByte(banner[5:], 0); end > 0 {
+			return "mysql", string(banner[5 : 5+end])
+		}
+	}
+	return "", ""
+}
+
+func fixed(request string) func(string, int) []byte {
+	return func(string, int) []byte { return []byte(request) }
+}
+
+func httpRequest(host string, _ int) []byte {
+	return []byte("HEAD / HTTP/1.0\r\nHost: " + host + "\r\n\r\n")
+}
+
+func matchHTTP(response []byte) (string, bool) {
+	if !bytes.HasPrefix(response, []byte("HTTP/")) {
+		return "", false
+	}
+	for _, line := range strings.Split(string(response), "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "server") {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", true
+}
+
+func matchRedis(response []byte) (string, bool) {
+	for _, prefix := range []string{"+PONG", "-NOAUTH", "-DENIED"} {
+		if bytes.HasPrefix(response, []byte(prefix)) {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// postgresRequest is an SSLRequest, which servers answer with S or N
+func postgresRequest(string, int) []byte {
+	return binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), 80877103)
+}
+
+func matchPostgres(response []byte) (string, bool) {
+	return "", len(response) == 1 && (response[0] == 'S' || response[0] == 'N')
+}
+
+// minecraftRequest is a Server List Ping: a handshake asking for the status
+// followed by the status request
+func minecraftRequest(host string, port int) []byte {
+	var handshake []byte
+	handshake = binary.AppendUvarint(handshake, 0x00)
+	// Protocol version -1 asks the server for its own version
+	handshake = binary.AppendUvarint(handshake, 0xffffffff)
+	handshake = binary.AppendUvarint(handshake, uint64(len(host)))
+	handshake = append(handshake, host...)
+	handshake = binary.BigEndian.AppendUint16(handshake, uint16(port))
+	handshake = binary.AppendUvarint(handshake, 1)
+
+	request := binary.AppendUvarint(nil, uint64(len(handshake)))
+	request = append(request, handshake...)
+	return append(request, 0x01, 0x00)
+}
+
+func matchMinecraft(response []byte) (string, bool) {
+	reader := bytes.NewReader(response)
+	for range 3 {
+		// packet length, packet id and JSON length
+		if _, err := binary.ReadUvarint(reader); err != nil {
+			return "", false
+		}
+	}
+	var status struct {
+		Version *struct {
+			Name string `json:"name"`
+		} `json:"version"`
+	}
+	// Long player lists are cut off by the read, the version comes first
+	rest := response[len(response)-reader.Len():]
+	if !bytes.HasPrefix(rest, []byte("{")) {
+		return "", false
+	}
+	if err := json.Unmarshal(rest, &status); err == nil && status.Version != nil {
+		return status.Version.Name, true
+	}
+	if name, ok := jsonVersionName(rest); ok {
+		return name, true
+	}
+	return "", false
+}
+
+// jsonVersionName finds the version name of a truncated status response
+func jsonVersionName(data []byte) (string, bool) {
+	_, rest, ok := bytes.Cut(data, []byte(`"version":{"name":"`))
+	if !ok {
+		return "", false
+	}
+	name, _, _ := bytes.Cut(rest, []byte(`"`))
+	return string(name), true
+}
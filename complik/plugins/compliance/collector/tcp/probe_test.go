@@ -0,0 +1,136 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTCP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TCP Suite")
+}
+
+// serve accepts connections on a local port and hands them to handle
+func serve(handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(listener.Close)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(time.Second))
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// greet writes banner as soon as a client connects
+func greet(banner []byte) func(net.Conn) {
+	return func(conn net.Conn) { _, _ = conn.Write(banner) }
+}
+
+// reply answers the first request starting with prefix
+func reply(prefix string, response []byte) func(net.Conn) {
+	return func(conn net.Conn) {
+		buf := make([]byte, 512)
+		n, err := conn.Read(buf)
+		if err != nil || n < len(prefix) || string(buf[:len(prefix)]) != prefix {
+			return
+		}
+		_, _ = conn.Write(response)
+	}
+}
+
+var _ = Describe("Prober", func() {
+	prober := &Prober{Timeout: 200 * time.Millisecond, MaxBanner: 64}
+
+	probe := func(address string) Fingerprint {
+		fingerprint, err := prober.Probe(context.Background(), address)
+		Expect(err).NotTo(HaveOccurred())
+		return fingerprint
+	}
+
+	It("should recognize services that greet first", func() {
+		Expect(probe(serve(greet([]byte("SSH-2.0-OpenSSH_9.6\r\n"))))).To(Equal(Fingerprint{
+			Service: "ssh", Version: "SSH-2.0-OpenSSH_9.6", Banner: "SSH-2.0-OpenSSH_9.6",
+		}))
+
+		handshake := []byte{0x4a, 0x00, 0x00, 0x00, 0x0a}
+		handshake = append(handshake, "8.0.36\x00\x08\x00\x00\x00"...)
+		fingerprint := probe(serve(greet(handshake)))
+		Expect(fingerprint.Service).To(Equal("mysql"))
+		Expect(fingerprint.Version).To(Equal("8.0.36"))
+		Expect(fingerprint.Banner).To(Equal("J... 8.0.36....."))
+
+		Expect(probe(serve(greet([]byte("220 mail.example.com ESMTP Postfix\r\n")))).Service).To(Equal("smtp"))
+	})
+
+	It("should probe services that wait for the client", func() {
+		Expect(probe(serve(reply("PING", []byte("-NOAUTH Authentication required.\r\n")))).Service).
+			To(Equal("redis"))
+		Expect(probe(serve(reply("HEAD /", []byte("HTTP/1.1 200 OK\r\nServer: nginx\r\n\r\n"))))).
+			To(Equal(Fingerprint{Service: "http", Version: "nginx", Banner: "HTTP/1.1 200 OK  Server: nginx"}))
+
+		ssl := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), 80877103)
+		Expect(probe(serve(reply(string(ssl), []byte("N")))).Service).To(Equal("postgresql"))
+	})
+
+	It("should read the version of a Minecraft server", func() {
+		address := serve(func(conn net.Conn) {
+			reader := bufio.NewReader(conn)
+			length, err := binary.ReadUvarint(reader)
+			if err != nil || length == 0 {
+				return
+			}
+			handshake := make([]byte, length)
+			if _, err := reader.Read(handshake); err != nil || handshake[0] != 0x00 {
+				return
+			}
+			status := `{"version":{"name":"Paper 1.21.1","protocol":767},"players":{"max":20,"online":3}}`
+			packet := append(binary.AppendUvarint([]byte{0x00}, uint64(len(status))), status...)
+			_, _ = conn.Write(append(binary.AppendUvarint(nil, uint64(len(packet))), packet...))
+		})
+		fingerprint := probe(address)
+		Expect(fingerprint.Service).To(Equal("minecraft"))
+		Expect(fingerprint.Version).To(Equal("Paper 1.21.1"))
+	})
+
+	It("should leave silent ports unrecognized and fail on closed ports", func() {
+		Expect(probe(serve(func(conn net.Conn) { time.Sleep(time.Second) }))).To(Equal(Fingerprint{}))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		listener.Close()
+		_, err = prober.Probe(context.Background(), address)
+		Expect(err).To(MatchError(ContainSubstring("failed to connect")))
+	})
+})
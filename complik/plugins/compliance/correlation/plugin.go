@@ -0,0 +1,284 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package correlation implements a plugin that joins website detections,
+// mining detections and procscan violations by namespace and publishes a
+// consolidated incident when a namespace is flagged by several pipelines.
+package correlation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/correlation"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/mining"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.ComplianceCorrelation
+	pluginType = constants.ComplianceCorrelationPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &CorrelationPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type CorrelationPlugin struct {
+	log               logger.Logger
+	correlationConfig CorrelationConfig
+	correlator        *correlation.Correlator
+	wallets           *mining.Index
+}
+
+func (p *CorrelationPlugin) Name() string {
+	return pluginName
+}
+
+func (p *CorrelationPlugin) Type() string {
+	return pluginType
+}
+
+type CorrelationConfig struct {
+	Region       string `json:"region"`
+	WindowMinute int    `json:"windowMinute"`
+	MinSources   int    `json:"minSources"`
+	// ProcscanURL is the violations endpoint of the procscan aggregator,
+	// polling is disabled when empty
+	ProcscanURL            string `json:"procscanURL"`
+	ProcscanIntervalSecond int    `json:"procscanIntervalSecond"`
+	// A mining detection is critical once its wallet was mined to from
+	// WalletNamespaces namespaces within WalletWindowHour
+	WalletWindowHour int `json:"walletWindowHour"`
+	WalletNamespaces int `json:"walletNamespaces"`
+}
+
+func (p *CorrelationPlugin) getDefaultConfig() CorrelationConfig {
+	return CorrelationConfig{
+		Region:                 "UNKNOWN",
+		WindowMinute:           60,
+		MinSources:             2,
+		ProcscanIntervalSecond: 60,
+		WalletWindowHour:       24,
+		WalletNamespaces:       2,
+	}
+}
+
+func (p *CorrelationPlugin) loadConfig(setting string) error {
+	p.correlationConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default correlation configuration")
+		return nil
+	}
+	var configFromJSON CorrelationConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse configuration", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.Region != "" {
+		p.correlationConfig.Region = configFromJSON.Region
+	}
+	if configFromJSON.WindowMinute > 0 {
+		p.correlationConfig.WindowMinute = configFromJSON.WindowMinute
+	}
+	if configFromJSON.MinSources > 0 {
+		p.correlationConfig.MinSources = configFromJSON.MinSources
+	}
+	if configFromJSON.ProcscanIntervalSecond > 0 {
+		p.correlationConfig.ProcscanIntervalSecond = configFromJSON.ProcscanIntervalSecond
+	}
+	if configFromJSON.WalletWindowHour > 0 {
+		p.correlationConfig.WalletWindowHour = configFromJSON.WalletWindowHour
+	}
+	if configFromJSON.WalletNamespaces > 0 {
+		p.correlationConfig.WalletNamespaces = configFromJSON.WalletNamespaces
+	}
+	p.correlationConfig.ProcscanURL = configFromJSON.ProcscanURL
+
+	p.log.Info("Correlation configuration loaded", logger.Fields{
+		"window_minutes": p.correlationConfig.WindowMinute,
+		"min_sources":    p.correlationConfig.MinSources,
+		"procscan_url":   p.correlationConfig.ProcscanURL,
+	})
+	return nil
+}
+
+func (p *CorrelationPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	window := time.Duration(p.correlationConfig.WindowMinute) * time.Minute
+	p.correlator = correlation.NewCorrelator(window, p.correlationConfig.MinSources)
+	p.wallets = mining.NewIndex(
+		time.Duration(p.correlationConfig.WalletWindowHour)*time.Hour,
+		p.correlationConfig.WalletNamespaces,
+	)
+
+	var (
+		procscan     *correlation.ProcscanClient
+		procscanPoll <-chan time.Time
+	)
+	if p.correlationConfig.ProcscanURL != "" {
+		interval := time.Duration(p.correlationConfig.ProcscanIntervalSecond) * time.Second
+		procscan = correlation.NewProcscanClient(p.correlationConfig.ProcscanURL, min(interval, 30*time.Second))
+		ticker := time.NewTicker(interval)
+		procscanPoll = ticker.C
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+
+	detections := eventBus.Subscribe(constants.DetectorTopic)
+	mining := eventBus.Subscribe(constants.MiningTopic)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		expireTicker := time.NewTicker(time.Minute)
+		defer expireTicker.Stop()
+		for {
+			select {
+			case event, ok := <-detections:
+				if !ok {
+					p.log.Info("Detector subscription channel closed")
+					return
+				}
+				result, ok := event.Payload.(*models.DetectorInfo)
+				if !ok {
+					p.invalidPayload("*models.DetectorInfo", event.Payload)
+					continue
+				}
+				if finding, ok := correlation.FromDetector(result, time.Now()); ok {
+					p.add(eventBus, finding)
+				}
+			case event, ok := <-mining:
+				if !ok {
+					p.log.Info("Mining subscription channel closed")
+					return
+				}
+				info, ok := event.Payload.(*models.MiningInfo)
+				if !ok {
+					p.invalidPayload("*models.MiningInfo", event.Payload)
+					continue
+				}
+				if finding, ok := correlation.FromMining(p.enrichMining(info, time.Now()), time.Now()); ok {
+					p.add(eventBus, finding)
+				}
+			case now := <-procscanPoll:
+				p.pollProcscan(ctx, eventBus, procscan, now)
+			case now := <-expireTicker.C:
+				p.wallets.Expire(now)
+				if closed := p.correlator.Expire(now); closed > 0 {
+					p.log.Debug("Correlated incidents closed", logger.Fields{
+						"closed": closed,
+						"open":   p.correlator.Open(),
+					})
+				}
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *CorrelationPlugin) pollProcscan(
+	ctx context.Context,
+	eventBus *eventbus.EventBus,
+	client *correlation.ProcscanClient,
+	now time.Time,
+) {
+	violations, err := client.FetchViolations(ctx)
+	if err != nil {
+		p.log.Error("Failed to fetch procscan violations", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	for _, violation := range violations {
+		if finding, ok := correlation.FromProcessViolation(violation, p.correlationConfig.Region, now); ok {
+			p.add(eventBus, finding)
+		}
+	}
+}
+
+// enrichMining returns a copy of info with its wallets indexed, the payload is
+// shared with the other subscribers of the mining topic
+func (p *CorrelationPlugin) enrichMining(info *models.MiningInfo, now time.Time) *models.MiningInfo {
+	enriched := *info
+	p.wallets.Enrich(&enriched, now)
+	if len(enriched.SharedWith) > 0 {
+		p.log.Warn("Wallet mined to from several namespaces", logger.Fields{
+			"namespace":   enriched.Namespace,
+			"wallets":     enriched.Wallets,
+			"shared_with": enriched.SharedWith,
+			"severity":    enriched.Severity,
+		})
+	}
+	return &enriched
+}
+
+func (p *CorrelationPlugin) add(eventBus *eventbus.EventBus, finding models.Finding) {
+	if finding.Region == "" {
+		finding.Region = p.correlationConfig.Region
+	}
+	incident, ok := p.correlator.Add(finding)
+	if !ok {
+		return
+	}
+	p.log.Warn("Correlated incident detected", logger.Fields{
+		"incident":  incident.ID,
+		"namespace": incident.Namespace,
+		"sources":   incident.Sources,
+		"severity":  incident.Severity,
+	})
+	eventBus.Publish(constants.CorrelationTopic, eventbus.Event{
+		Payload: &incident,
+	})
+}
+
+func (p *CorrelationPlugin) invalidPayload(expected string, payload any) {
+	p.log.Error("Invalid event payload type", logger.Fields{
+		"expected": expected,
+		"actual":   fmt.Sprintf("%T", payload),
+	})
+}
+
+func (p *CorrelationPlugin) Stop(ctx context.Context) error {
+	p.log.Info("Stopping correlation plugin")
+	return nil
+}
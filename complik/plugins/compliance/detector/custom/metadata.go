@@ -0,0 +1,101 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+)
+
+// metadataFields are the site metadata a keyword rule can be matched against
+var metadataFields = map[string]func(*models.SiteMetadata) string{
+	"title":       func(m *models.SiteMetadata) string { return m.Title },
+	"description": func(m *models.SiteMetadata) string { return m.Description },
+	"generator":   func(m *models.SiteMetadata) string { return m.Generator },
+	"robots":      func(m *models.SiteMetadata) string { return m.RobotsTxt },
+	"security":    func(m *models.SiteMetadata) string { return m.SecurityTxt },
+}
+
+// splitList splits a comma-separated rule column, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateFields checks the metadata fields of a rule
+func validateFields(rule utils.CustomKeywordRule) error {
+	for _, field := range splitList(rule.Fields) {
+		if _, ok := metadataFields[strings.ToLower(field)]; !ok {
+			return fmt.Errorf("unknown metadata field %q", field)
+		}
+	}
+	return nil
+}
+
+// metadataMatch is a keyword of a rule found in the site metadata
+type metadataMatch struct {
+	rule    string
+	keyword string
+	field   string
+}
+
+// matchMetadata matches the rules with fields against metadata and returns
+// the keywords found and the rules left to the model
+func matchMetadata(
+	metadata *models.SiteMetadata,
+	rules []utils.CustomKeywordRule,
+) (matches []metadataMatch, reviewed []utils.CustomKeywordRule) {
+	for _, rule := range rules {
+		fields := splitList(rule.Fields)
+		if len(fields) == 0 {
+			reviewed = append(reviewed, rule)
+			continue
+		}
+		if metadata == nil {
+			continue
+		}
+		for _, field := range fields {
+			value, ok := metadataFields[strings.ToLower(field)]
+			if !ok {
+				continue
+			}
+			text := strings.ToLower(value(metadata))
+			for _, keyword := range splitList(rule.Keywords) {
+				if strings.Contains(text, strings.ToLower(keyword)) {
+					matches = append(matches, metadataMatch{rule: rule.Type, keyword: keyword, field: field})
+				}
+			}
+		}
+	}
+	return matches, reviewed
+}
+
+// explainMatches describes the metadata matches in a detection result
+func explainMatches(matches []metadataMatch) (keywords []string, explanation string) {
+	found := make([]string, 0, len(matches))
+	for _, match := range matches {
+		keywords = append(keywords, match.keyword)
+		found = append(found, fmt.Sprintf("%q in %s (%s)", match.keyword, match.field, match.rule))
+	}
+	return keywords, "Site metadata matched " + strings.Join(found, ", ")
+}
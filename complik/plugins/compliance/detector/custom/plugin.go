@@ -13,7 +13,7 @@
 // limitations under the License.
 
 // Package custom provides a compliance detector plugin that uses custom keyword rules
-// stored in a MySQL database to detect potentially illegal or non-compliant content.
+// stored in a MySQL or embedded SQLite database to detect potentially illegal or non-compliant content.
 // The plugin periodically refreshes keyword rules from the database and uses an AI-powered
 // content reviewer to analyze collected website content against these rules.
 package custom
@@ -23,22 +23,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"net/http"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bearslyricattack/CompliK/complik/pkg/concurrency"
 	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/unchanged"
 	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	gormLogger "gorm.io/gorm/logger"
 )
 
 const (
@@ -56,10 +58,12 @@ func init() {
 
 type CustomPlugin struct {
 	log          logger.Logger
-	reviewer     *utils.ContentReviewer
+	reviewer     utils.Reviewer
 	db           *gorm.DB
+	mu           sync.RWMutex
 	keywords     []utils.CustomKeywordRule
 	customConfig CustomConfig
+	server       *http.Server
 }
 
 func (p *CustomPlugin) Name() string {
@@ -71,6 +75,9 @@ func (p *CustomPlugin) Type() string {
 }
 
 type CustomConfig struct {
+	// Driver is "mysql" (default) or "sqlite" for the embedded single-binary mode
+	Driver       string `json:"driver"`
+	SQLitePath   string `json:"sqlitePath"`
 	Dsn          string `json:"dsn"`
 	DatabaseName string `json:"databaseName"`
 	TickerMinute int    `json:"tickerMinute"`
@@ -85,10 +92,23 @@ type CustomConfig struct {
 	APIBase      string `json:"apiBase"`
 	APIPath      string `json:"apiPath"`
 	Model        string `json:"model"`
+	// Concurrency tunes the worker count to the review API latency
+	Concurrency concurrency.Config `json:"concurrency"`
+	// SkipUnchanged answers for sites unchanged since their last compliant review
+	SkipUnchanged unchanged.Config `json:"skipUnchanged"`
+	// SandboxAPIAddr serves the rule testing endpoint when set
+	SandboxAPIAddr  string `json:"sandboxApiAddr"`
+	SandboxAPIToken string `json:"sandboxApiToken"`
+	// CaptureTranscript attaches the model prompt and response to the results
+	// for the handlers storing transcripts
+	CaptureTranscript bool `json:"captureTranscript"`
+	// Prompts renders the review prompts from a hot-reloaded template set
+	Prompts prompts.Config `json:"prompts"`
 }
 
 func (p *CustomPlugin) getDefaultConfig() CustomConfig {
 	return CustomConfig{
+		Driver:       database.DriverMySQL,
 		DatabaseName: "custom",
 		Charset:      "utf8mb4",
 		TickerMinute: 600,
@@ -118,17 +138,18 @@ func (p *CustomPlugin) loadConfig(setting string) error {
 		return err
 	}
 
-	if configFromJSON.Host == "" {
-		return errors.New("host configuration cannot be empty")
+	if configFromJSON.Driver != "" {
+		p.customConfig.Driver = configFromJSON.Driver
 	}
-	if configFromJSON.Port == "" {
-		return errors.New("port configuration cannot be empty")
-	}
-	if configFromJSON.Username == "" {
-		return errors.New("username configuration cannot be empty")
-	}
-	if configFromJSON.Password == "" {
-		return errors.New("password configuration cannot be empty")
+	p.customConfig.SQLitePath = configFromJSON.SQLitePath
+	if err := (database.Options{
+		Driver:   p.customConfig.Driver,
+		Host:     configFromJSON.Host,
+		Port:     configFromJSON.Port,
+		Username: configFromJSON.Username,
+		Password: configFromJSON.Password,
+	}).Validate(); err != nil {
+		return err
 	}
 	if configFromJSON.APIKey == "" {
 		return errors.New("APIKey configuration cannot be empty")
@@ -138,19 +159,26 @@ func (p *CustomPlugin) loadConfig(setting string) error {
 	p.customConfig.Port = configFromJSON.Port
 	p.customConfig.Username = configFromJSON.Username
 
-	// Support secure password from environment variable or encryption
-	if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
-		p.customConfig.Password = pwd
-		p.log.Debug("Using secure password from environment/encryption")
-	} else {
-		p.customConfig.Password = configFromJSON.Password
-		p.log.Warn("Using plain text password - consider using environment variables")
+	// Support secure password from environment variable or encryption.
+	// SQLite mode runs without a password.
+	if configFromJSON.Password != "" {
+		if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
+			p.customConfig.Password = pwd
+			p.log.Debug("Using secure password from environment/encryption")
+		} else if config.IsSecretReference(configFromJSON.Password) {
+			return fmt.Errorf("failed to resolve password: %w", err)
+		} else {
+			p.customConfig.Password = configFromJSON.Password
+			p.log.Warn("Using plain text password - consider using environment variables")
+		}
 	}
 
 	// Support secure API key from environment variable or encryption
 	if apiKey, err := config.GetSecureValue(configFromJSON.APIKey); err == nil {
 		p.customConfig.APIKey = apiKey
 		p.log.Debug("Using secure API key from environment/encryption")
+	} else if config.IsSecretReference(configFromJSON.APIKey) {
+		return fmt.Errorf("failed to resolve API key: %w", err)
 	} else {
 		p.customConfig.APIKey = configFromJSON.APIKey
 		p.log.Warn("Using plain text API key - consider using environment variables")
@@ -174,6 +202,10 @@ func (p *CustomPlugin) loadConfig(setting string) error {
 	if configFromJSON.MaxWorkers > 0 {
 		p.customConfig.MaxWorkers = configFromJSON.MaxWorkers
 	}
+	p.customConfig.Concurrency = configFromJSON.Concurrency.WithDefaults(p.customConfig.MaxWorkers)
+	p.customConfig.SkipUnchanged = configFromJSON.SkipUnchanged.WithDefaults()
+	p.customConfig.CaptureTranscript = configFromJSON.CaptureTranscript
+	p.customConfig.Prompts = configFromJSON.Prompts.WithDefaults(strings.ToLower(pluginName))
 	if configFromJSON.Charset != "" {
 		p.customConfig.Charset = configFromJSON.Charset
 	}
@@ -183,13 +215,30 @@ func (p *CustomPlugin) loadConfig(setting string) error {
 	if configFromJSON.Model != "" {
 		p.customConfig.Model = configFromJSON.Model
 	}
+	p.customConfig.SandboxAPIAddr = configFromJSON.SandboxAPIAddr
+	if configFromJSON.SandboxAPIToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.SandboxAPIToken); err == nil {
+			p.customConfig.SandboxAPIToken = token
+		} else if config.IsSecretReference(configFromJSON.SandboxAPIToken) {
+			return fmt.Errorf("failed to resolve sandbox API token: %w", err)
+		} else {
+			p.customConfig.SandboxAPIToken = configFromJSON.SandboxAPIToken
+		}
+	}
+	// Rule tests fetch arbitrary URLs and spend reviewer tokens, the sandbox
+	// is never served unauthenticated
+	if p.customConfig.SandboxAPIAddr != "" && p.customConfig.SandboxAPIToken == "" {
+		return errors.New("sandboxApiToken configuration cannot be empty when sandboxApiAddr is set")
+	}
 
 	p.log.Info("Custom detector configuration loaded", logger.Fields{
+		"driver":         p.customConfig.Driver,
 		"database":       p.customConfig.DatabaseName,
 		"table":          p.customConfig.TableName,
 		"api_base":       p.customConfig.APIBase,
 		"model":          p.customConfig.Model,
-		"max_workers":    p.customConfig.MaxWorkers,
+		"max_workers":    p.customConfig.Concurrency.MaxWorkers,
+		"adaptive":       p.customConfig.Concurrency.Adaptive,
 		"ticker_minutes": p.customConfig.TickerMinute,
 	})
 
@@ -218,14 +267,33 @@ func (p *CustomPlugin) Start(
 		})
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
-	p.reviewer = utils.NewContentReviewer(
-		p.log,
-		p.customConfig.APIKey,
-		p.customConfig.APIBase,
-		p.customConfig.APIPath,
-		p.customConfig.Model,
-	)
-	p.log.Debug("Content reviewer initialized")
+	if config.DryRun {
+		p.reviewer = utils.NewStubReviewer(p.log)
+		p.log.Info("Dry-run: using stub content reviewer")
+	} else {
+		reviewer := utils.NewContentReviewer(
+			p.log,
+			p.customConfig.APIKey,
+			p.customConfig.APIBase,
+			p.customConfig.APIPath,
+			p.customConfig.Model,
+		)
+		reviewer.SetCaptureTranscript(p.customConfig.CaptureTranscript)
+		if p.customConfig.Prompts.Dir != "" {
+			loader, err := prompts.NewLoader(p.log, p.customConfig.Prompts)
+			if err != nil {
+				return fmt.Errorf("failed to load prompt set: %w", err)
+			}
+			go loader.Run(ctx)
+			reviewer.SetPrompts(loader)
+			p.log.Info("Prompts are rendered from a template set", logger.Fields{
+				"path":                    p.customConfig.Prompts.Path(),
+				"reload_interval_seconds": p.customConfig.Prompts.ReloadIntervalSecond,
+			})
+		}
+		p.reviewer = reviewer
+		p.log.Debug("Content reviewer initialized")
+	}
 	err = p.readFromDatabase(ctx)
 	if err != nil {
 		p.log.Error("Failed to read keywords from database", logger.Fields{
@@ -234,17 +302,34 @@ func (p *CustomPlugin) Start(
 		return err
 	}
 	p.log.Info("Keywords loaded from database", logger.Fields{
-		"keyword_count": len(p.keywords),
+		"keyword_count": len(p.rules()),
 	})
+	// The sandbox judges every sample, so it takes the reviewer before
+	// unchanged sites are skipped
+	if p.customConfig.SandboxAPIAddr != "" {
+		p.startSandboxAPI()
+	}
+	if p.customConfig.SkipUnchanged.Enabled {
+		skipper, err := unchanged.NewReviewer(p.log, p.reviewer, p.customConfig.SkipUnchanged)
+		if err != nil {
+			return fmt.Errorf("failed to load fingerprints of unchanged sites: %w", err)
+		}
+		p.reviewer = skipper
+		p.log.Info("Reviews of unchanged sites are skipped", logger.Fields{
+			"max_age_hours": p.customConfig.SkipUnchanged.MaxAgeHour,
+		})
+	}
+	// Targets collected on several ingress paths are reviewed page by page
+	p.reviewer = utils.NewPageReviewer(p.reviewer)
 	subscribe := eventBus.Subscribe(constants.CollectorTopic)
 	p.log.Debug("Subscribed to collector topic", logger.Fields{
 		"topic": constants.CollectorTopic,
 	})
-	semaphore := make(chan struct{}, p.customConfig.MaxWorkers)
+	limiter := concurrency.NewLimiter(p.log, p.customConfig.Concurrency)
 	ticker := time.NewTicker(time.Duration(p.customConfig.TickerMinute) * time.Minute)
 	defer ticker.Stop()
 	p.log.Info("Custom detector started", logger.Fields{
-		"worker_pool_size":         p.customConfig.MaxWorkers,
+		"worker_pool_size":         limiter.Stats().Limit,
 		"refresh_interval_minutes": p.customConfig.TickerMinute,
 	})
 	for {
@@ -254,9 +339,22 @@ func (p *CustomPlugin) Start(
 				p.log.Info("Event subscription channel closed")
 				return nil
 			}
-			semaphore <- struct{}{}
+			if res, ok := event.Payload.(*models.CollectorInfo); ok && !res.Overrides.Allows(p.Name()) {
+				p.log.Debug("Skipping target overriding the detectors", logger.Fields{
+					"namespace": res.Namespace,
+					"host":      res.Host,
+					"detectors": res.Overrides.Detectors,
+				})
+				continue
+			}
+			token, err := limiter.Acquire(ctx)
+			if err != nil {
+				// ctx is done, the next iteration shuts down
+				continue
+			}
 			go func(e eventbus.Event) {
-				defer func() { <-semaphore }()
+				var reviewErr error
+				defer func() { limiter.Release(token, reviewErr) }()
 				defer func() {
 					if r := recover(); r != nil {
 						p.log.Error("Goroutine panic in custom detector", logger.Fields{
@@ -279,12 +377,15 @@ func (p *CustomPlugin) Start(
 					"namespace":     res.Namespace,
 					"name":          res.Name,
 					"host":          res.Host,
-					"keyword_rules": len(p.keywords),
+					"keyword_rules": len(p.rules()),
 				})
 
 				startTime := time.Now()
-				result, err := p.customJudge(ctx, res)
+				taskCtx, cancel := eventBus.Policy().Context(ctx, e.Deadline, eventbus.PhaseDetect)
+				result, err := p.customJudge(taskCtx, res)
+				cancel()
 				duration := time.Since(startTime)
+				reviewErr = err
 
 				if err != nil {
 					p.log.Error("Custom judgement failed", logger.Fields{
@@ -302,7 +403,8 @@ func (p *CustomPlugin) Start(
 				}
 
 				eventBus.Publish(constants.DetectorTopic, eventbus.Event{
-					Payload: result,
+					Payload:  result,
+					Deadline: e.Deadline,
 				})
 			}(event)
 		case <-ticker.C:
@@ -325,23 +427,35 @@ func (p *CustomPlugin) Start(
 				}
 
 				p.log.Info("Keywords refreshed from database", logger.Fields{
-					"keyword_count": len(p.keywords),
+					"keyword_count": len(p.rules()),
 				})
 			}()
 		case <-ctx.Done():
 			p.log.Info("Shutting down custom detector plugin")
 			// Wait for all workers to finish
-			for range p.customConfig.MaxWorkers {
-				semaphore <- struct{}{}
-			}
-			p.log.Debug("All workers finished")
+			limiter.Wait()
+			p.log.Debug("All workers finished", logger.Fields{
+				"concurrency": limiter.Stats(),
+			})
 			return nil
 		}
 	}
 }
 
+// HealthCheck reports whether the rule database is reachable
+func (p *CustomPlugin) HealthCheck(ctx context.Context) error {
+	return database.Ping(ctx, p.db)
+}
+
 func (p *CustomPlugin) Stop(ctx context.Context) error {
 	p.log.Info("Stopping custom detector plugin")
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down sandbox API server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
 
 	if p.db != nil {
 		sqlDB, err := p.db.DB()
@@ -356,38 +470,15 @@ func (p *CustomPlugin) Stop(ctx context.Context) error {
 
 func (p *CustomPlugin) initDB() error {
 	p.log.Debug("Initializing database", logger.Fields{
+		"driver":   p.customConfig.Driver,
 		"host":     p.customConfig.Host,
 		"port":     p.customConfig.Port,
 		"database": p.customConfig.DatabaseName,
 	})
-	serverDSN := p.buildDSN(false)
-	dbConfig := &gorm.Config{
-		Logger: gormLogger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags),
-			gormLogger.Config{
-				SlowThreshold: 3 * time.Second,  // Slow query threshold set to 3 seconds
-				LogLevel:      gormLogger.Error, // Show error logs only
-				Colorful:      false,            // Disable color output
-			},
-		),
-	}
-	db, err := gorm.Open(mysql.Open(serverDSN), dbConfig)
+	opts := p.dbOptions()
+	db, err := database.Open(opts)
 	if err != nil {
-		return fmt.Errorf("failed to connect to MySQL server: %w", err)
-	}
-	err = db.Exec(
-		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET %s COLLATE %s_unicode_ci",
-			p.customConfig.DatabaseName,
-			p.customConfig.Charset,
-			p.customConfig.Charset),
-	).Error
-	if err != nil {
-		return fmt.Errorf("failed to create database: %w", err)
-	}
-	dbDSN := p.buildDSN(true)
-	db, err = gorm.Open(mysql.Open(dbDSN), dbConfig)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
 	}
 	p.db = db
 	tableName := db.NamingStrategy.TableName("CustomKeywordRule")
@@ -396,16 +487,18 @@ func (p *CustomPlugin) initDB() error {
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
-	var tableExists bool
-	err = db.Raw("SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
-		p.customConfig.TableName, tableName).
-		Scan(&tableExists).
-		Error
-	if err != nil {
-		p.log.Warn("Failed to check table existence", logger.Fields{
-			"error": err.Error(),
-			"table": tableName,
-		})
+	if !opts.IsSQLite() {
+		var tableExists bool
+		err = db.Raw("SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
+			p.customConfig.TableName, tableName).
+			Scan(&tableExists).
+			Error
+		if err != nil {
+			p.log.Warn("Failed to check table existence", logger.Fields{
+				"error": err.Error(),
+				"table": tableName,
+			})
+		}
 	}
 	var count int64
 	err = db.Model(&utils.CustomKeywordRule{}).Count(&count).Error
@@ -440,22 +533,18 @@ func (p *CustomPlugin) initDB() error {
 	return nil
 }
 
-func (p *CustomPlugin) buildDSN(includeDB bool) string {
-	if p.customConfig.Dsn != "" {
-		return p.customConfig.Dsn
-	}
-	dbPart := "/"
-	if includeDB {
-		dbPart = "/" + p.customConfig.DatabaseName
-	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)%s?charset=%s&parseTime=True&loc=Local",
-		p.customConfig.Username,
-		p.customConfig.Password,
-		p.customConfig.Host,
-		p.customConfig.Port,
-		dbPart,
-		p.customConfig.Charset,
-	)
+func (p *CustomPlugin) dbOptions() database.Options {
+	return database.Options{
+		Driver:       p.customConfig.Driver,
+		DSN:          p.customConfig.Dsn,
+		Host:         p.customConfig.Host,
+		Port:         p.customConfig.Port,
+		Username:     p.customConfig.Username,
+		Password:     p.customConfig.Password,
+		DatabaseName: p.customConfig.DatabaseName,
+		Charset:      p.customConfig.Charset,
+		SQLitePath:   p.customConfig.SQLitePath,
+	}
 }
 
 func (p *CustomPlugin) readFromDatabase(ctx context.Context) error {
@@ -470,8 +559,10 @@ func (p *CustomPlugin) readFromDatabase(ctx context.Context) error {
 		return err
 	}
 
+	p.mu.Lock()
 	oldCount := len(p.keywords)
 	p.keywords = models
+	p.mu.Unlock()
 
 	p.log.Debug("Keyword rules updated", logger.Fields{
 		"old_count": oldCount,
@@ -481,27 +572,66 @@ func (p *CustomPlugin) readFromDatabase(ctx context.Context) error {
 	return nil
 }
 
+// rules returns the keyword rules loaded from the database
+func (p *CustomPlugin) rules() []utils.CustomKeywordRule {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keywords
+}
+
+// startSandboxAPI serves the rule testing endpoint
+func (p *CustomPlugin) startSandboxAPI() {
+	sandbox := NewSandbox(p.log, p.reviewer, p.rules)
+	p.server = &http.Server{
+		Addr:              p.customConfig.SandboxAPIAddr,
+		Handler:           NewSandboxAPI(p.log, p.customConfig.SandboxAPIToken, sandbox),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Sandbox API server started", logger.Fields{
+			"addr": p.customConfig.SandboxAPIAddr,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Sandbox API server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
 func (p *CustomPlugin) customJudge(
 	ctx context.Context,
 	collector *models.CollectorInfo,
+) (res *models.DetectorInfo, err error) {
+	return judge(ctx, p.log, p.reviewer, collector, p.rules())
+}
+
+// judge reviews collected content against the keyword rules. The detector and
+// the rule sandbox share it so both reach the same decision.
+func judge(
+	ctx context.Context,
+	log logger.Logger,
+	reviewer utils.Reviewer,
+	collector *models.CollectorInfo,
+	rules []utils.CustomKeywordRule,
 ) (res *models.DetectorInfo, err error) {
 	taskCtx, cancel := context.WithTimeout(ctx, 80*time.Second)
 	defer cancel()
 
-	p.log.Debug("Starting custom judgement", logger.Fields{
+	log.Debug("Starting custom judgement", logger.Fields{
 		"url":           collector.URL,
 		"is_empty":      collector.IsEmpty,
-		"keyword_rules": len(p.keywords),
+		"keyword_rules": len(rules),
 	})
 
 	if collector.IsEmpty {
-		p.log.Debug("Skipping empty content", logger.Fields{
+		log.Debug("Skipping empty content", logger.Fields{
 			"host": collector.Host,
 		})
 		return &models.DetectorInfo{
 			DiscoveryName: collector.DiscoveryName,
 			CollectorName: collector.CollectorName,
-			DetectorName:  p.Name(),
+			DetectorName:  pluginName,
 			Name:          collector.Name,
 			Namespace:     collector.Namespace,
 			Host:          collector.Host,
@@ -512,12 +642,57 @@ func (p *CustomPlugin) customJudge(
 			Keywords:      []string{},
 		}, nil
 	}
-	result, err := p.reviewer.ReviewSiteContent(taskCtx, collector, p.Name(), p.keywords)
+	// Rules with fields are decided on the site metadata without the model
+	matches, reviewed := matchMetadata(collector.Metadata, rules)
+	var title string
+	if collector.Metadata != nil {
+		title = collector.Metadata.Title
+	}
+	if len(matches) > 0 {
+		keywords, explanation := explainMatches(matches)
+		log.Debug("Site metadata matched keyword rules", logger.Fields{
+			"host":     collector.Host,
+			"keywords": keywords,
+		})
+		return &models.DetectorInfo{
+			DiscoveryName: collector.DiscoveryName,
+			CollectorName: collector.CollectorName,
+			DetectorName:  pluginName,
+			Name:          collector.Name,
+			Namespace:     collector.Namespace,
+			Host:          collector.Host,
+			Path:          collector.Path,
+			URL:           collector.URL,
+			IsIllegal:     true,
+			Description:   title,
+			Keywords:      keywords,
+			Explanation:   explanation,
+			Metadata:      collector.Metadata,
+		}, nil
+	}
+	if len(reviewed) == 0 && len(rules) > 0 {
+		return &models.DetectorInfo{
+			DiscoveryName: collector.DiscoveryName,
+			CollectorName: collector.CollectorName,
+			DetectorName:  pluginName,
+			Name:          collector.Name,
+			Namespace:     collector.Namespace,
+			Host:          collector.Host,
+			Path:          collector.Path,
+			URL:           collector.URL,
+			IsIllegal:     false,
+			Description:   title,
+			Keywords:      []string{},
+			Explanation:   "No keyword found in the site metadata",
+			Metadata:      collector.Metadata,
+		}, nil
+	}
+	result, err := reviewer.ReviewSiteContent(taskCtx, collector, pluginName, reviewed)
 	if err != nil {
 		return &models.DetectorInfo{
 			DiscoveryName: collector.DiscoveryName,
 			CollectorName: collector.CollectorName,
-			DetectorName:  p.Name(),
+			DetectorName:  pluginName,
 			Name:          collector.Name,
 			Namespace:     collector.Namespace,
 			Host:          collector.Host,
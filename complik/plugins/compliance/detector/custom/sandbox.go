@@ -0,0 +1,223 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/collector/browser/metadata"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+)
+
+// SandboxCollectorName is the collector name of samples fetched by the sandbox
+const SandboxCollectorName = "sandbox"
+
+// maxSampleBytes limits the page fetched for a sample URL
+const maxSampleBytes = 2 << 20
+
+var (
+	ErrInvalidSandboxRequest = errors.New("invalid rule test")
+	ErrSampleUnavailable     = errors.New("sample unavailable")
+	ErrReviewFailed          = errors.New("review failed")
+)
+
+// SandboxRequest is a rule test. Rules default to the rules currently loaded
+// by the detector; the sample is either a URL or a stored CollectorInfo
+// record, such as an evidence file of "complik eval".
+type SandboxRequest struct {
+	Rules  []utils.CustomKeywordRule `json:"rules,omitempty"`
+	URL    string                    `json:"url,omitempty"`
+	Record *models.CollectorInfo     `json:"record,omitempty"`
+}
+
+// SandboxResult is the decision the detector would have published
+type SandboxResult struct {
+	Source     string                    `json:"source"`
+	Rules      []utils.CustomKeywordRule `json:"rules"`
+	Result     *models.DetectorInfo      `json:"result"`
+	DurationMs int64                     `json:"duration_ms"`
+}
+
+// Sandbox judges samples the way the custom detector does, without publishing
+// or storing the results
+type Sandbox struct {
+	log      logger.Logger
+	reviewer utils.Reviewer
+	rules    func() []utils.CustomKeywordRule
+	http     *http.Client
+}
+
+func NewSandbox(
+	log logger.Logger,
+	reviewer utils.Reviewer,
+	rules func() []utils.CustomKeywordRule,
+) *Sandbox {
+	return &Sandbox{
+		log:      log,
+		reviewer: reviewer,
+		rules:    rules,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Test runs a rule test
+func (s *Sandbox) Test(ctx context.Context, req SandboxRequest) (*SandboxResult, error) {
+	if (req.URL == "") == (req.Record == nil) {
+		return nil, fmt.Errorf("%w: exactly one of url and record is required", ErrInvalidSandboxRequest)
+	}
+	rules := req.Rules
+	if len(rules) == 0 {
+		rules = s.rules()
+	}
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Type) == "" || strings.TrimSpace(rule.Keywords) == "" {
+			return nil, fmt.Errorf("%w: rule %d needs a type and keywords", ErrInvalidSandboxRequest, i+1)
+		}
+		if err := validateFields(rule); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %w", ErrInvalidSandboxRequest, i+1, err)
+		}
+	}
+
+	result := &SandboxResult{Source: "record", Rules: rules}
+	sample := req.Record
+	if req.URL != "" {
+		var err error
+		if sample, err = s.fetch(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		result.Source = "url"
+	}
+
+	startTime := time.Now()
+	detected, err := judge(ctx, s.log, s.reviewer, sample, rules)
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReviewFailed, err)
+	}
+	result.Result = detected
+	s.log.Info("Rule test completed", logger.Fields{
+		"source":      result.Source,
+		"host":        sample.Host,
+		"rules":       len(rules),
+		"is_illegal":  detected.IsIllegal,
+		"duration_ms": result.DurationMs,
+	})
+	return result, nil
+}
+
+// fetch collects a sample with a plain HTTP request. Unlike the browser
+// collector it does not run scripts, take a screenshot or fetch robots.txt
+// and security.txt.
+func (s *Sandbox) fetch(ctx context.Context, rawURL string) (*models.CollectorInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSandboxRequest)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSandboxRequest, err)
+	}
+	req.Header.Set("User-Agent", "CompliK-Sandbox/1.0")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSampleUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: %s returned %s", ErrSampleUnavailable, u.Host, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSampleBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSampleUnavailable, err)
+	}
+	html := string(body)
+	return &models.CollectorInfo{
+		DiscoveryName:    SandboxCollectorName,
+		CollectorName:    SandboxCollectorName,
+		Host:             u.Hostname(),
+		Path:             []string{u.Path},
+		URL:              u.String(),
+		CollectorMessage: "Fetched by the rule sandbox",
+		HTML:             html,
+		IsEmpty:          strings.TrimSpace(html) == "",
+		Metadata:         metadata.ParseHTML(html),
+	}, nil
+}
+
+// SandboxAPI serves the rule testing endpoint:
+//
+//	POST /api/v1/rules/test
+//	GET  /api/v1/rules
+type SandboxAPI struct {
+	log     logger.Logger
+	sandbox *Sandbox
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewSandboxAPI(log logger.Logger, token string, sandbox *Sandbox) *SandboxAPI {
+	api := &SandboxAPI{log: log, sandbox: sandbox, mux: http.NewServeMux()}
+	api.mux.HandleFunc("POST /api/v1/rules/test", api.test)
+	api.mux.HandleFunc("GET /api/v1/rules", api.listRules)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *SandboxAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *SandboxAPI) test(w http.ResponseWriter, r *http.Request) {
+	var req SandboxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	result, err := a.sandbox.Test(r.Context(), req)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, result)
+}
+
+func (a *SandboxAPI) listRules(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.sandbox.rules())
+}
+
+// fail maps sandbox errors to HTTP status codes
+func (a *SandboxAPI) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidSandboxRequest):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSampleUnavailable), errors.Is(err, ErrReviewFailed):
+		a.log.Warn("Rule test failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusBadGateway, err.Error())
+	default:
+		a.log.Error("Sandbox API request failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCustom(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Custom Detector Suite")
+}
+
+var _ = Describe("Sandbox", func() {
+	var (
+		api   http.Handler
+		pages *httptest.Server
+	)
+
+	BeforeEach(func() {
+		log := logger.GetLogger()
+		loaded := []utils.CustomKeywordRule{{Type: "malware", Keywords: "trojan,backdoor"}}
+		sandbox := NewSandbox(log, utils.NewStubReviewer(log), func() []utils.CustomKeywordRule { return loaded })
+		api = NewSandboxAPI(log, "secret", sandbox)
+		pages = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.URL.Path == "/titled" {
+				fmt.Fprint(w, "<html><head><title>Free trojan downloads</title></head><body></body></html>")
+				return
+			}
+			fmt.Fprint(w, "<html><body>Online Casino, download our trojan</body></html>")
+		}))
+		DeferCleanup(pages.Close)
+	})
+
+	test := func(body string) (*httptest.ResponseRecorder, SandboxResult) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rules/test", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		var result SandboxResult
+		if rec.Code == http.StatusOK {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &result)).To(Succeed())
+		}
+		return rec, result
+	}
+
+	It("should judge a URL with the given rules", func() {
+		rec, result := test(fmt.Sprintf(`{"url":%q,"rules":[{"type":"gambling","keywords":"casino"}]}`, pages.URL+"/shop"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Source).To(Equal("url"))
+		Expect(result.Result.IsIllegal).To(BeTrue())
+		Expect(result.Result.Keywords).To(Equal([]string{"casino"}))
+		Expect(result.Result.DetectorName).To(Equal(pluginName))
+		Expect(result.Result.Path).To(Equal([]string{"/shop"}))
+	})
+
+	It("should match rules with fields against the site metadata", func() {
+		rec, result := test(`{"record":{"host":"a.example.com","html":"<p>casino</p>",` +
+			`"metadata":{"title":"Lucky Casino","generator":"WordPress"}},` +
+			`"rules":[{"type":"gambling","keywords":"casino,slots","fields":"title,description"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Result.IsIllegal).To(BeTrue())
+		Expect(result.Result.Keywords).To(Equal([]string{"casino"}))
+		Expect(result.Result.Explanation).To(ContainSubstring(`"casino" in title (gambling)`))
+		Expect(result.Result.Metadata.Generator).To(Equal("WordPress"))
+
+		// Rules with fields only look at the metadata, not at the page HTML
+		rec, result = test(fmt.Sprintf(`{"url":%q,"rules":[{"type":"gambling","keywords":"casino","fields":"title"}]}`, pages.URL))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Result.IsIllegal).To(BeFalse())
+		Expect(result.Result.Explanation).To(Equal("No keyword found in the site metadata"))
+	})
+
+	It("should read the title of fetched samples", func() {
+		rec, result := test(fmt.Sprintf(`{"url":%q,"rules":[{"type":"malware","keywords":"trojan","fields":"title"}]}`, pages.URL+"/titled"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Result.IsIllegal).To(BeTrue())
+		Expect(result.Result.Description).To(Equal("Free trojan downloads"))
+	})
+
+	It("should fall back to the loaded rules for stored records", func() {
+		rec, result := test(`{"record":{"host":"a.example.com","html":"nothing to see"}}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Source).To(Equal("record"))
+		Expect(result.Rules).To(HaveLen(1))
+		Expect(result.Result.IsIllegal).To(BeFalse())
+	})
+
+	It("should not review empty samples", func() {
+		rec, result := test(`{"record":{"host":"a.example.com","is_empty":true,"collector_message":"timeout"}}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(result.Result.IsIllegal).To(BeFalse())
+		Expect(result.Result.Description).To(Equal("timeout"))
+	})
+
+	It("should reject invalid tests", func() {
+		for _, body := range []string{
+			`{}`,
+			`{"url":"https://a.example.com","record":{}}`,
+			`{"url":"file:///etc/passwd"}`,
+			`{"url":"https://a.example.com","rules":[{"type":"gambling"}]}`,
+			`{"url":"https://a.example.com","rules":[{"type":"gambling","keywords":"casino","fields":"footer"}]}`,
+			`not json`,
+		} {
+			rec, _ := test(body)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest), body)
+		}
+	})
+
+	It("should report unavailable samples", func() {
+		rec, _ := test(fmt.Sprintf(`{"url":%q}`, pages.URL+"/missing"))
+		Expect(rec.Code).To(Equal(http.StatusBadGateway))
+		Expect(rec.Body.String()).To(ContainSubstring("404"))
+	})
+
+	It("should require the token", func() {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rules", nil)
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+
+		req.Header.Set("Authorization", "Bearer secret")
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("trojan,backdoor"))
+	})
+
+	It("should refuse every request without a configured token", func() {
+		log := logger.GetLogger()
+		api = NewSandboxAPI(log, "", NewSandbox(log, utils.NewStubReviewer(log), func() []utils.CustomKeywordRule { return nil }))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rules/test", strings.NewReader(`{"url":"https://example.com"}`))
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+
+		p := &CustomPlugin{log: log}
+		Expect(p.loadConfig(`{"driver":"sqlite","apiKey":"key","sandboxApiAddr":":8094"}`)).
+			To(MatchError(ContainSubstring("sandboxApiToken")))
+		Expect(p.loadConfig(`{"driver":"sqlite","apiKey":"key","sandboxApiAddr":":8094","sandboxApiToken":"secret"}`)).
+			To(Succeed())
+	})
+})
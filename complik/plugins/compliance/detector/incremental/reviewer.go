@@ -0,0 +1,352 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package incremental reviews the text changes of a site since its last
+// compliant review instead of the whole page. The normalized visible text of
+// each compliant review is kept per site; a later scan whose text changed
+// little sends only the added and removed lines, the previous summary and the
+// screenshot to a cheaper model. Large changes, flagged diffs and old reviews
+// fall back to a full review.
+package incremental
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/fingerprint"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+)
+
+// maxTextBytes bounds the text kept and compared per site
+const maxTextBytes = 20000
+
+// Config is the incrementalReview section of a detector plugin configuration
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// Model reviews the diffs, the model of the detector when empty
+	Model string `json:"model"`
+	// MaxChangedRatio is the largest share of added and removed lines among
+	// the lines of both versions reviewed as a diff
+	MaxChangedRatio float64 `json:"maxChangedRatio"`
+	// MaxDiffBytes is the largest diff text reviewed as a diff
+	MaxDiffBytes int `json:"maxDiffBytes"`
+	// MaxAgeHour forces a full review of sites reviewed as diffs for this long
+	MaxAgeHour int `json:"maxAgeHour"`
+	// StatePath is the JSON file the reviewed texts survive restarts in
+	StatePath string `json:"statePath"`
+}
+
+// WithDefaults fills the unset values of c
+func (c Config) WithDefaults() Config {
+	if c.MaxChangedRatio <= 0 || c.MaxChangedRatio > 1 {
+		c.MaxChangedRatio = 0.3
+	}
+	if c.MaxDiffBytes <= 0 {
+		c.MaxDiffBytes = 4000
+	}
+	if c.MaxAgeHour <= 0 {
+		c.MaxAgeHour = 7 * 24
+	}
+	return c
+}
+
+// DiffReviewer reviews the text changes of a page
+type DiffReviewer interface {
+	ReviewSiteDiff(
+		ctx context.Context,
+		content *models.CollectorInfo,
+		name string,
+		diff *prompts.Diff,
+	) (*models.DetectorInfo, error)
+}
+
+// site is the text of the last compliant review of a site
+type site struct {
+	Hash        string    `json:"hash"`
+	Lines       []string  `json:"lines"`
+	FullAt      time.Time `json:"full_at"`
+	ReviewedAt  time.Time `json:"reviewed_at"`
+	Description string    `json:"description,omitempty"`
+	Keywords    []string  `json:"keywords,omitempty"`
+}
+
+// Stats counts the reviews since the start
+type Stats struct {
+	Diffs     int64 `json:"diffs"`
+	Full      int64 `json:"full"`
+	Fallbacks int64 `json:"fallbacks"`
+}
+
+// Reviewer wraps the full reviewer of a detector and reviews small changes
+// with the diff reviewer
+type Reviewer struct {
+	log    logger.Logger
+	next   utils.Reviewer
+	diff   DiffReviewer
+	cfg    Config
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*site
+	stats   Stats
+}
+
+// NewReviewer wraps next with the diff reviews of diff, loading the texts of
+// cfg.StatePath
+func NewReviewer(log logger.Logger, next utils.Reviewer, diff DiffReviewer, cfg Config) (*Reviewer, error) {
+	cfg = cfg.WithDefaults()
+	r := &Reviewer{
+		log:     log,
+		next:    next,
+		diff:    diff,
+		cfg:     cfg,
+		maxAge:  time.Duration(cfg.MaxAgeHour) * time.Hour,
+		now:     time.Now,
+		entries: make(map[string]*site),
+	}
+	if cfg.StatePath == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(cfg.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incremental review state: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse incremental review state %s: %w", cfg.StatePath, err)
+	}
+	return r, nil
+}
+
+func (r *Reviewer) ReviewSiteContent(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	// Custom rules are matched against the whole page
+	if content == nil || content.IsEmpty || len(customRules) > 0 {
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	lines := bound(fingerprint.Lines(content.HTML))
+	current := site{Hash: hash(lines), Lines: lines}
+	key := name + "|" + siteKey(content)
+
+	var usage *models.TokenUsage
+	if diff := r.diffSince(key, current); diff != nil {
+		result, err := r.diff.ReviewSiteDiff(ctx, content, name, diff)
+		switch {
+		case err != nil:
+			r.log.Warn("Diff review failed, reviewing the whole page", logger.Fields{
+				"host":  content.Host,
+				"error": err.Error(),
+			})
+		case result.IsIllegal:
+			// The cheaper model only decides on compliant changes
+			r.log.Debug("Diff review flagged the site, reviewing the whole page", logger.Fields{
+				"host": content.Host,
+			})
+		default:
+			result.Incremental = true
+			r.record(key, current, result, false)
+			return result, nil
+		}
+		r.count(func(s *Stats) { s.Fallbacks++ })
+		if result != nil && result.Usage != nil {
+			usage = result.Usage
+		}
+	}
+
+	result, err := r.next.ReviewSiteContent(ctx, content, name, customRules)
+	if result != nil && usage != nil {
+		if result.Usage == nil {
+			result.Usage = &models.TokenUsage{Model: usage.Model}
+		}
+		result.Usage.Add(usage)
+	}
+	if err != nil {
+		return result, err
+	}
+	r.count(func(s *Stats) { s.Full++ })
+	r.record(key, current, result, true)
+	return result, nil
+}
+
+// diffSince returns the text changes of current since the last compliant
+// review of key, nil when the site needs a full review
+func (r *Reviewer) diffSince(key string, current site) *prompts.Diff {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last, ok := r.entries[key]
+	if !ok || r.now().Sub(last.FullAt) >= r.maxAge {
+		return nil
+	}
+	diff := &prompts.Diff{Description: last.Description, Keywords: last.Keywords}
+	if last.Hash != current.Hash {
+		diff.Added, diff.Removed = changes(last.Lines, current.Lines)
+	}
+	changed := len(diff.Added) + len(diff.Removed)
+	if total := len(last.Lines) + len(current.Lines); total > 0 &&
+		float64(changed)/float64(total) > r.cfg.MaxChangedRatio {
+		return nil
+	}
+	if size(diff.Added)+size(diff.Removed) > r.cfg.MaxDiffBytes {
+		return nil
+	}
+	r.stats.Diffs++
+	return diff
+}
+
+// record keeps the text of a compliant review; an illegal result forgets the
+// site so it gets full reviews until it is fixed
+func (r *Reviewer) record(key string, entry site, result *models.DetectorInfo, full bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if result.IsIllegal {
+		if _, ok := r.entries[key]; !ok {
+			return
+		}
+		delete(r.entries, key)
+	} else {
+		entry.ReviewedAt = now
+		entry.FullAt = now
+		if last, ok := r.entries[key]; ok && !full {
+			entry.FullAt = last.FullAt
+		}
+		entry.Description, entry.Keywords = result.Description, result.Keywords
+		r.entries[key] = &entry
+	}
+	for key, last := range r.entries {
+		if now.Sub(last.FullAt) >= r.maxAge {
+			delete(r.entries, key)
+		}
+	}
+	// A lost state only costs full reviews
+	if err := r.save(); err != nil {
+		r.log.Warn("Failed to save incremental review state", logger.Fields{"error": err.Error()})
+	}
+}
+
+func (r *Reviewer) count(update func(*Stats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(&r.stats)
+}
+
+// Stats returns the number of reviews so far
+func (r *Reviewer) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// save writes the texts to the state file; callers hold r.mu
+func (r *Reviewer) save() error {
+	if r.cfg.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode incremental review state: %w", err)
+	}
+	if dir := filepath.Dir(r.cfg.StatePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create incremental review state directory: %w", err)
+		}
+	}
+	// Write through a temporary file so a crash never leaves a truncated state
+	tmp := r.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write incremental review state: %w", err)
+	}
+	if err := os.Rename(tmp, r.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to replace incremental review state: %w", err)
+	}
+	return nil
+}
+
+// bound keeps the first lines within maxTextBytes, so both versions of a
+// long page are compared on the same prefix
+func bound(lines []string) []string {
+	total := 0
+	for i, line := range lines {
+		total += len(line) + 1
+		if total > maxTextBytes {
+			return lines[:i]
+		}
+	}
+	return lines
+}
+
+// size is the length of lines joined by newlines
+func size(lines []string) int {
+	total := 0
+	for _, line := range lines {
+		total += len(line) + 1
+	}
+	return total
+}
+
+// hash identifies a text version
+func hash(lines []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// changes returns the lines of current missing from last and the lines of
+// last missing from current, in their order; repeated lines are counted
+func changes(last, current []string) (added, removed []string) {
+	counts := make(map[string]int, len(last))
+	for _, line := range last {
+		counts[line]++
+	}
+	for _, line := range current {
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range last {
+		if counts[line] > 0 {
+			counts[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+// siteKey identifies a site across scans
+func siteKey(content *models.CollectorInfo) string {
+	if content.URL != "" {
+		return content.URL
+	}
+	return content.Host + "/" + strings.Join(content.Path, ",")
+}
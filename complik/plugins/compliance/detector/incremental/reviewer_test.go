@@ -0,0 +1,196 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incremental
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestIncremental(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Incremental Suite")
+}
+
+// fullReviewer flags pages containing "casino" and counts its reviews
+type fullReviewer struct {
+	reviews int
+}
+
+func (f *fullReviewer) ReviewSiteContent(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	_ []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	f.reviews++
+	return &models.DetectorInfo{
+		DetectorName: name, Host: content.Host, URL: content.URL,
+		IsIllegal:   strings.Contains(content.HTML, "casino"),
+		Description: "A bakery", Keywords: []string{"bread"},
+		Usage: &models.TokenUsage{Model: "gpt-5", PromptTokens: 5000, TotalTokens: 5000},
+	}, nil
+}
+
+// diffReviewer flags diffs adding "casino" and keeps the last diff
+type diffReviewer struct {
+	reviews int
+	last    *prompts.Diff
+	err     error
+}
+
+func (d *diffReviewer) ReviewSiteDiff(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	diff *prompts.Diff,
+) (*models.DetectorInfo, error) {
+	d.reviews++
+	d.last = diff
+	usage := &models.TokenUsage{Model: "gpt-5-mini", PromptTokens: 800, TotalTokens: 800}
+	if d.err != nil {
+		return &models.DetectorInfo{Host: content.Host, Usage: usage}, d.err
+	}
+	return &models.DetectorInfo{
+		DetectorName: name, Host: content.Host, URL: content.URL,
+		IsIllegal:   strings.Contains(strings.Join(diff.Added, "\n"), "casino"),
+		Description: "A bakery with a new menu", Keywords: []string{"bread", "menu"},
+		Usage: usage,
+	}, nil
+}
+
+const bakery = `<h1>Bakery</h1><p>Fresh bread every morning</p><p>Cakes on order</p>
+	<p>Coffee all day long</p><p>Visit us at the market square</p><p>Open on Sundays</p>`
+
+var _ = Describe("Reviewer", func() {
+	var (
+		full     *fullReviewer
+		diff     *diffReviewer
+		reviewer *Reviewer
+		now      time.Time
+	)
+
+	page := func(html string) *models.CollectorInfo {
+		return &models.CollectorInfo{Host: "bakery.example.com", URL: "http://bakery.example.com", HTML: html}
+	}
+	review := func(html string) *models.DetectorInfo {
+		result, err := reviewer.ReviewSiteContent(context.Background(), page(html), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	BeforeEach(func() {
+		full, diff = &fullReviewer{}, &diffReviewer{}
+		var err error
+		reviewer, err = NewReviewer(logger.GetLogger(), full, diff, Config{Enabled: true})
+		Expect(err).NotTo(HaveOccurred())
+		now = time.Now()
+		reviewer.now = func() time.Time { return now }
+	})
+
+	It("should review small changes of compliant sites as diffs", func() {
+		Expect(review(bakery).Incremental).To(BeFalse())
+		Expect(full.reviews).To(Equal(1))
+
+		result := review(strings.Replace(bakery, "Open on Sundays", "Closed on Mondays", 1))
+		Expect(full.reviews).To(Equal(1))
+		Expect(diff.reviews).To(Equal(1))
+		Expect(result.Incremental).To(BeTrue())
+		Expect(result.Usage.Model).To(Equal("gpt-5-mini"))
+		Expect(diff.last.Description).To(Equal("A bakery"))
+		Expect(diff.last.Added).To(Equal([]string{"Closed on Mondays"}))
+		Expect(diff.last.Removed).To(Equal([]string{"Open on Sundays"}))
+
+		// The summary of the diff review is the base of the next one
+		review(strings.Replace(bakery, "Open on Sundays", "Closed on Mondays", 1))
+		Expect(diff.last.Description).To(Equal("A bakery with a new menu"))
+		Expect(diff.last.Added).To(BeEmpty())
+		Expect(reviewer.Stats()).To(Equal(Stats{Diffs: 2, Full: 1}))
+	})
+
+	It("should review large changes in full", func() {
+		review(bakery)
+		review(`<h1>Bakery</h1><p>Now a florist</p><p>Roses</p><p>Tulips</p>`)
+		Expect(full.reviews).To(Equal(2))
+		Expect(diff.reviews).To(BeZero())
+	})
+
+	It("should confirm flagged diffs with a full review", func() {
+		review(bakery)
+		result := review(bakery + "<p>Online casino</p>")
+		Expect(diff.reviews).To(Equal(1))
+		Expect(full.reviews).To(Equal(2))
+		Expect(result.IsIllegal).To(BeTrue())
+		Expect(result.Incremental).To(BeFalse())
+		Expect(result.Usage.PromptTokens).To(Equal(5800))
+
+		// Flagged sites get full reviews until they are fixed
+		review(bakery + "<p>Online casino</p>")
+		Expect(full.reviews).To(Equal(3))
+		Expect(diff.reviews).To(Equal(1))
+	})
+
+	It("should fall back to a full review when the diff review fails", func() {
+		review(bakery)
+		diff.err = errors.New("model unavailable")
+		result := review(bakery + "<p>New pastries</p>")
+		Expect(full.reviews).To(Equal(2))
+		Expect(result.Incremental).To(BeFalse())
+		Expect(reviewer.Stats().Fallbacks).To(Equal(int64(1)))
+	})
+
+	It("should review sites in full after the maximum age", func() {
+		review(bakery)
+		now = now.Add(8 * 24 * time.Hour)
+		review(bakery)
+		Expect(full.reviews).To(Equal(2))
+		Expect(diff.reviews).To(BeZero())
+	})
+
+	It("should review pages with custom rules in full", func() {
+		rules := []utils.CustomKeywordRule{{Type: "gambling", Keywords: "casino"}}
+		for range 2 {
+			_, err := reviewer.ReviewSiteContent(context.Background(), page(bakery), "custom", rules)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(full.reviews).To(Equal(2))
+	})
+
+	It("should keep the reviewed texts across restarts", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "incremental.json")
+		first, err := NewReviewer(logger.GetLogger(), full, diff, Config{StatePath: path})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = first.ReviewSiteContent(context.Background(), page(bakery), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := NewReviewer(logger.GetLogger(), full, diff, Config{StatePath: path})
+		Expect(err).NotTo(HaveOccurred())
+		result, err := second.ReviewSiteContent(context.Background(), page(bakery), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Incremental).To(BeTrue())
+		Expect(full.reviews).To(Equal(1))
+	})
+})
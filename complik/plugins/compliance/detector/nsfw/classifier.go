@@ -0,0 +1,97 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nsfw scores the screenshot of a page locally before it is sent to
+// the review model. A low score can skip the model review; the score never
+// flags a page on its own, every other page is reviewed by the model.
+package nsfw
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Classifier scores an image with the probability, from 0 to 1, that it shows
+// explicit content
+type Classifier interface {
+	Score(img image.Image) (float64, error)
+}
+
+// SkinClassifier is a heuristic classifier scoring images by the share of
+// their pixels in the skin tone range. Pages made of large skin areas score
+// high, but so do pages with a beige or peach background; text, product
+// pictures and most illustrations score close to zero.
+type SkinClassifier struct {
+	// Low and High are the skin shares scored 0 and 1, linear in between
+	Low, High float64
+}
+
+// NewSkinClassifier returns a SkinClassifier with the default shares
+func NewSkinClassifier() *SkinClassifier {
+	return &SkinClassifier{Low: 0.15, High: 0.5}
+}
+
+// Score samples at most 256 pixels per side of img
+func (c *SkinClassifier) Score(img image.Image) (float64, error) {
+	const samples = 256
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0, fmt.Errorf("empty image")
+	}
+	stepX, stepY := max(1, width/samples), max(1, height/samples)
+	var skin, total int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if isSkin(r>>8, g>>8, b>>8) {
+				skin++
+			}
+			total++
+		}
+	}
+	share := float64(skin) / float64(total)
+	switch {
+	case share <= c.Low:
+		return 0, nil
+	case share >= c.High:
+		return 1, nil
+	default:
+		return (share - c.Low) / (c.High - c.Low), nil
+	}
+}
+
+// isSkin applies the YCbCr skin tone bounds of Chai and Ngan to an 8-bit RGB
+// pixel
+func isSkin(r, g, b uint32) bool {
+	fr, fg, fb := float64(r), float64(g), float64(b)
+	y := 0.299*fr + 0.587*fg + 0.114*fb
+	cb := 128 - 0.168736*fr - 0.331264*fg + 0.5*fb
+	cr := 128 + 0.5*fr - 0.418688*fg - 0.081312*fb
+	return y > 40 && cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173
+}
+
+// decode decodes a PNG or JPEG screenshot
+func decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsfw
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+)
+
+// Config is the nsfwPrefilter section of a detector plugin configuration
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// SkipThreshold answers pages scoring at most this as compliant without a
+	// model review; 0 reviews them. Only the screenshot is scored, so keep it
+	// off for detectors looking for more than explicit content in the text.
+	SkipThreshold float64 `json:"skipThreshold"`
+}
+
+// WithDefaults fills the unset values of c
+func (c Config) WithDefaults() Config {
+	if c.SkipThreshold < 0 || c.SkipThreshold >= 1 {
+		c.SkipThreshold = 0
+	}
+	return c
+}
+
+// Reviewer wraps the reviewer of a detector and answers for the pages the
+// classifier scores as low risk. It never flags a page: a local score is not
+// reliable enough to hand a site to the remediation handlers.
+type Reviewer struct {
+	log        logger.Logger
+	next       utils.Reviewer
+	classifier Classifier
+	cfg        Config
+
+	skipped atomic.Int64
+}
+
+// NewReviewer wraps next with the prefilter of classifier
+func NewReviewer(log logger.Logger, next utils.Reviewer, classifier Classifier, cfg Config) *Reviewer {
+	return &Reviewer{log: log, next: next, classifier: classifier, cfg: cfg.WithDefaults()}
+}
+
+func (r *Reviewer) ReviewSiteContent(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	if content == nil || content.IsEmpty || len(content.Screenshot) == 0 {
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	score, err := r.score(content.Screenshot)
+	if err != nil {
+		r.log.Debug("Screenshot not classified", logger.Fields{
+			"host":  content.Host,
+			"error": err.Error(),
+		})
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	if r.cfg.SkipThreshold <= 0 || score > r.cfg.SkipThreshold {
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	r.skipped.Add(1)
+	r.log.Debug("Review skipped by the NSFW prefilter", logger.Fields{
+		"host":  content.Host,
+		"score": score,
+	})
+	return r.result(content, name, score), nil
+}
+
+// Skipped returns the number of pages answered without a model review
+func (r *Reviewer) Skipped() int64 {
+	return r.skipped.Load()
+}
+
+func (r *Reviewer) score(screenshot []byte) (float64, error) {
+	img, err := decode(screenshot)
+	if err != nil {
+		return 0, err
+	}
+	return r.classifier.Score(img)
+}
+
+// result is the compliant answer of the prefilter for content
+func (r *Reviewer) result(content *models.CollectorInfo, name string, score float64) *models.DetectorInfo {
+	return &models.DetectorInfo{
+		DiscoveryName: content.DiscoveryName,
+		CollectorName: content.CollectorName,
+		DetectorName:  name,
+		Name:          content.Name,
+		Namespace:     content.Namespace,
+		Host:          content.Host,
+		Path:          content.Path,
+		URL:           content.URL,
+		Keywords:      []string{},
+		Explanation:   fmt.Sprintf("Screenshot scored %.2f by the local NSFW classifier", score),
+		Metadata:      content.Metadata,
+	}
+}
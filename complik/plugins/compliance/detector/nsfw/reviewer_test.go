@@ -0,0 +1,172 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsfw
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestNSFW(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NSFW Prefilter Suite")
+}
+
+// countingReviewer answers compliant and counts its reviews
+type countingReviewer struct {
+	reviews int
+}
+
+func (c *countingReviewer) ReviewSiteContent(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	_ []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	c.reviews++
+	return &models.DetectorInfo{DetectorName: name, Host: content.Host, Description: "reviewed"}, nil
+}
+
+// screenshot encodes an image whose top share rows are skin coloured and
+// the rest white
+func screenshot(share float64) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := range 100 {
+		for x := range 100 {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if float64(y) < share*100 {
+				c = color.RGBA{R: 224, G: 172, B: 140, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("SkinClassifier", func() {
+	score := func(data []byte) float64 {
+		img, err := decode(data)
+		Expect(err).NotTo(HaveOccurred())
+		s, err := NewSkinClassifier().Score(img)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("should score images by their share of skin tones", func() {
+		Expect(score(screenshot(0))).To(BeZero())
+		Expect(score(screenshot(0.1))).To(BeZero())
+		Expect(score(screenshot(0.3))).To(BeNumerically("~", 0.43, 0.05))
+		Expect(score(screenshot(0.8))).To(Equal(1.0))
+	})
+
+	It("should not count dark or saturated pixels as skin", func() {
+		Expect(isSkin(224, 172, 140)).To(BeTrue())
+		Expect(isSkin(20, 12, 10)).To(BeFalse())
+		Expect(isSkin(255, 0, 0)).To(BeFalse())
+		Expect(isSkin(40, 90, 200)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Reviewer", func() {
+	var next *countingReviewer
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		next = &countingReviewer{}
+	})
+
+	page := func(data []byte) *models.CollectorInfo {
+		return &models.CollectorInfo{Host: "a.example.com", Path: []string{"/"}, URL: "http://a.example.com", Screenshot: data}
+	}
+
+	It("should never flag pages without a model review", func() {
+		r := NewReviewer(logger.GetLogger(), next, NewSkinClassifier(), Config{Enabled: true, SkipThreshold: 0.05})
+		result, err := r.ReviewSiteContent(ctx, page(screenshot(0.9)), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Description).To(Equal("reviewed"))
+		Expect(next.reviews).To(Equal(1))
+		Expect(r.Skipped()).To(BeZero())
+	})
+
+	It("should send pages with a skin coloured background to the model", func() {
+		wheat := color.RGBA{R: 0xF5, G: 0xDE, B: 0xB3, A: 255}
+		img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(img, img.Bounds(), &image.Uniform{C: wheat}, image.Point{}, draw.Src)
+		var buf bytes.Buffer
+		Expect(png.Encode(&buf, img)).To(Succeed())
+
+		s, err := NewSkinClassifier().Score(img)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(1.0))
+
+		r := NewReviewer(logger.GetLogger(), next, NewSkinClassifier(), Config{Enabled: true, SkipThreshold: 0.05})
+		result, err := r.ReviewSiteContent(ctx, page(buf.Bytes()), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Description).To(Equal("reviewed"))
+		Expect(next.reviews).To(Equal(1))
+	})
+
+	It("should only skip low risk pages when configured", func() {
+		r := NewReviewer(logger.GetLogger(), next, NewSkinClassifier(), Config{Enabled: true})
+		result, err := r.ReviewSiteContent(ctx, page(screenshot(0)), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Description).To(Equal("reviewed"))
+		Expect(next.reviews).To(Equal(1))
+
+		r = NewReviewer(logger.GetLogger(), next, NewSkinClassifier(), Config{Enabled: true, SkipThreshold: 0.05})
+		result, err = r.ReviewSiteContent(ctx, page(screenshot(0)), "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Explanation).To(ContainSubstring("scored 0.00"))
+		Expect(next.reviews).To(Equal(1))
+		Expect(r.Skipped()).To(Equal(int64(1)))
+	})
+
+	It("should review uncertain pages and pages without a usable screenshot", func() {
+		r := NewReviewer(logger.GetLogger(), next, NewSkinClassifier(), Config{Enabled: true, SkipThreshold: 0.05})
+		for _, content := range []*models.CollectorInfo{
+			page(screenshot(0.3)),
+			page(nil),
+			page([]byte("not an image")),
+		} {
+			result, err := r.ReviewSiteContent(ctx, content, "safety", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Description).To(Equal("reviewed"))
+		}
+		Expect(next.reviews).To(Equal(3))
+	})
+
+	It("should keep thresholds consistent", func() {
+		Expect(Config{}.WithDefaults()).To(Equal(Config{}))
+		Expect(Config{SkipThreshold: 0.05}.WithDefaults().SkipThreshold).To(Equal(0.05))
+		Expect(Config{SkipThreshold: 1}.WithDefaults().SkipThreshold).To(BeZero())
+		Expect(Config{SkipThreshold: -0.1}.WithDefaults().SkipThreshold).To(BeZero())
+	})
+})
@@ -0,0 +1,135 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Config is the prompts section of a detector plugin configuration. The set
+// is read from <dir>/<set>.tmpl, so a single ConfigMap mounted on dir holds
+// the sets of every detector.
+type Config struct {
+	// Dir holds the template sets; empty keeps the built-in prompts
+	Dir string `json:"dir"`
+	// Set names the template set of the detector, the detector name by default
+	Set string `json:"set"`
+	// ReloadIntervalSecond is how often the set file is checked for changes
+	ReloadIntervalSecond int `json:"reloadIntervalSecond"`
+}
+
+// WithDefaults fills the unset values of c, naming the set after detector
+func (c Config) WithDefaults(detector string) Config {
+	if c.Set == "" {
+		c.Set = detector
+	}
+	if c.ReloadIntervalSecond <= 0 {
+		c.ReloadIntervalSecond = 30
+	}
+	return c
+}
+
+// Path returns the file of the configured set
+func (c Config) Path() string {
+	return filepath.Join(c.Dir, c.Set+".tmpl")
+}
+
+// Loader is the Source of a template set file, reloaded when it changes. An
+// invalid change is logged and the previous set is kept.
+type Loader struct {
+	log  logger.Logger
+	cfg  Config
+	set  atomic.Pointer[Set]
+	stat atomic.Pointer[fileStamp]
+}
+
+// fileStamp tells versions of the set file apart
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewLoader loads the set of cfg, failing when it is missing or invalid
+func NewLoader(log logger.Logger, cfg Config) (*Loader, error) {
+	l := &Loader{log: log, cfg: cfg}
+	if _, err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Current returns the last valid set
+func (l *Loader) Current() *Set {
+	return l.set.Load()
+}
+
+// Reload reads the set file again when it changed since the last load and
+// reports whether a new set is in use
+func (l *Loader) Reload() (bool, error) {
+	path := l.cfg.Path()
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read prompt set: %w", err)
+	}
+	stamp := &fileStamp{modTime: info.ModTime(), size: info.Size()}
+	if last := l.stat.Load(); last != nil && *last == *stamp {
+		return false, nil
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read prompt set: %w", err)
+	}
+	// A rejected version is not parsed again until the file changes
+	l.stat.Store(stamp)
+	set, err := Parse(l.cfg.Set, string(source))
+	if err != nil {
+		return false, err
+	}
+	l.set.Store(set)
+	return true, nil
+}
+
+// Run reloads the set every ReloadIntervalSecond until ctx is done
+func (l *Loader) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(l.cfg.ReloadIntervalSecond) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := l.Reload()
+			if err != nil {
+				l.log.Error("Prompt set not reloaded, keeping the previous one", logger.Fields{
+					"path":  l.cfg.Path(),
+					"error": err.Error(),
+				})
+				continue
+			}
+			if reloaded {
+				l.log.Info("Prompt set reloaded", logger.Fields{
+					"path": l.cfg.Path(),
+				})
+			}
+		}
+	}
+}
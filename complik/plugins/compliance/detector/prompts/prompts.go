@@ -0,0 +1,191 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prompts renders the prompts of the content reviewer from Go
+// templates. A template set defines the "site" prompt, used without custom
+// keyword rules, the "rules" prompt and the "diff" prompt of incremental
+// reviews; templates missing from a set fall back to the built-in ones, so a
+// set may override a single prompt.
+package prompts
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Names of the templates of a set
+const (
+	SiteTemplate  = "site"
+	RulesTemplate = "rules"
+	DiffTemplate  = "diff"
+)
+
+// templates are the names a set may define
+var templates = []string{SiteTemplate, RulesTemplate, DiffTemplate}
+
+//go:embed builtin.tmpl
+var builtinSource string
+
+var builtin = mustParse("builtin", builtinSource)
+
+// funcs are available to every template
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Rule is a custom keyword rule as seen by the "rules" template
+type Rule struct {
+	Type        string
+	Description string
+	Keywords    []string
+}
+
+// Diff is the change of the visible text of a page since its last compliant
+// review, as seen by the "diff" template. Description and Keywords are the
+// summary of that review.
+type Diff struct {
+	Description string
+	Keywords    []string
+	Added       []string
+	Removed     []string
+}
+
+// Data is the input of a prompt template. HTML is the truncated page HTML,
+// Metadata the rendered site metadata section, empty when nothing was
+// gathered, Rules the custom keyword rules of the "rules" template and Diff
+// the text changes of the "diff" template.
+type Data struct {
+	HTML     string
+	Metadata string
+	Rules    []Rule
+	Diff     *Diff
+}
+
+// Source provides the prompt set of the next review
+type Source interface {
+	Current() *Set
+}
+
+// Set is a parsed and validated template set
+type Set struct {
+	name string
+	tmpl *template.Template
+}
+
+// Builtin returns the set of the built-in prompts
+func Builtin() *Set {
+	return builtin
+}
+
+// Parse parses the template set source, completes it with the built-in
+// templates it does not define and validates every template against sample
+// data: "site" and "rules" must place {{.HTML}}, "rules" must also place the
+// rules, "diff" the added lines, and unknown templates or fields are rejected.
+func Parse(name, source string) (*Set, error) {
+	return parse(name, source, builtin)
+}
+
+func parse(name, source string, base *Set) (*Set, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt set %s: %w", name, err)
+	}
+	for _, defined := range tmpl.Templates() {
+		if defined.Name() != name && !slices.Contains(templates, defined.Name()) {
+			return nil, fmt.Errorf("prompt set %s: unknown template %q", name, defined.Name())
+		}
+	}
+	for _, required := range templates {
+		if tmpl.Lookup(required) == nil && base != nil {
+			if _, err := tmpl.AddParseTree(required, base.tmpl.Lookup(required).Tree); err != nil {
+				return nil, fmt.Errorf("prompt set %s: %w", name, err)
+			}
+		}
+	}
+	set := &Set{name: name, tmpl: tmpl}
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func mustParse(name, source string) *Set {
+	set, err := parse(name, source, nil)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// Placeholders of the sample data the templates are validated with
+const (
+	sampleHTML = "\x00html\x00"
+	sampleRule = "\x00rule\x00"
+	sampleLine = "\x00line\x00"
+)
+
+func (s *Set) validate() error {
+	sample := Data{
+		HTML:     sampleHTML,
+		Metadata: "\n# Site Metadata:\n",
+		Rules:    []Rule{{Type: sampleRule, Description: "sample", Keywords: []string{"sample"}}},
+		Diff: &Diff{
+			Description: "sample",
+			Keywords:    []string{"sample"},
+			Added:       []string{sampleLine},
+			Removed:     []string{"sample"},
+		},
+	}
+	var errs []error
+	for _, name := range templates {
+		prompt, err := s.Render(name, sample)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if name != DiffTemplate && !strings.Contains(prompt, sampleHTML) {
+			errs = append(errs, fmt.Errorf("prompt set %s: template %q does not place {{.HTML}}", s.name, name))
+		}
+		if name == RulesTemplate && !strings.Contains(prompt, sampleRule) {
+			errs = append(errs, fmt.Errorf("prompt set %s: template %q does not place the rules", s.name, name))
+		}
+		if name == DiffTemplate && !strings.Contains(prompt, sampleLine) {
+			errs = append(errs, fmt.Errorf("prompt set %s: template %q does not place the added lines", s.name, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Name returns the name the set was parsed with
+func (s *Set) Name() string {
+	return s.name
+}
+
+// Current returns s, a set is the Source of itself
+func (s *Set) Current() *Set {
+	return s
+}
+
+// Render executes the template name of the set with data
+func (s *Set) Render(name string, data Data) (string, error) {
+	var builder strings.Builder
+	if err := s.tmpl.ExecuteTemplate(&builder, name, data); err != nil {
+		return "", fmt.Errorf("prompt set %s: %w", s.name, err)
+	}
+	return builder.String(), nil
+}
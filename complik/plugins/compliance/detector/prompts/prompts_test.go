@@ -0,0 +1,142 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPrompts(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Prompts Suite")
+}
+
+var _ = Describe("Set", func() {
+	data := Data{
+		HTML:     "<h1>online casino</h1>",
+		Metadata: "\n# Site Metadata:\n- Title: Casino\n",
+		Rules:    []Rule{{Type: "gambling", Description: "Gambling sites", Keywords: []string{"casino", "baccarat"}}},
+	}
+
+	It("should render the built-in prompts", func() {
+		prompt, err := Builtin().Render(SiteTemplate, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prompt).To(HavePrefix("# Role: Content Analysis and Compliance Checker"))
+		Expect(prompt).To(ContainSubstring("```html\n<h1>online casino</h1>\n```\n\n# Site Metadata:\n- Title: Casino\n"))
+
+		prompt, err = Builtin().Render(RulesTemplate, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prompt).To(ContainSubstring("### gambling\n- Description: Gambling sites\n- Keywords: casino, baccarat\n"))
+	})
+
+	It("should render the text changes of incremental reviews", func() {
+		prompt, err := Builtin().Render(DiffTemplate, Data{Diff: &Diff{
+			Description: "A bakery",
+			Keywords:    []string{"bread", "cake"},
+			Added:       []string{"Online casino"},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prompt).To(ContainSubstring("- Description: A bakery\n- Keywords: bread, cake\n"))
+		Expect(prompt).To(ContainSubstring("Lines added since the previous review:\n+ Online casino\n\n"))
+		Expect(prompt).To(ContainSubstring("Lines removed since the previous review:\n(none)\n"))
+	})
+
+	It("should fall back to the built-in templates a set does not define", func() {
+		set, err := Parse("strict", `{{define "site"}}Strict review of {{.HTML}}{{.Metadata}}{{end}}`)
+		Expect(err).NotTo(HaveOccurred())
+		prompt, err := set.Render(SiteTemplate, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prompt).To(Equal("Strict review of <h1>online casino</h1>\n# Site Metadata:\n- Title: Casino\n"))
+
+		prompt, err = set.Render(RulesTemplate, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prompt).To(HavePrefix("# Role: Intelligent Webpage Content Compliance Detection Expert"))
+	})
+
+	DescribeTable("should reject invalid sets",
+		func(source, message string) {
+			_, err := Parse("invalid", source)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("syntax error", `{{define "site"}}{{.HTML}`, "failed to parse"),
+		Entry("missing page HTML", `{{define "site"}}Review the page{{end}}`, "does not place {{.HTML}}"),
+		Entry("missing rules", `{{define "rules"}}{{.HTML}}{{end}}`, "does not place the rules"),
+		Entry("missing added lines", `{{define "diff"}}{{.Diff.Description}}{{end}}`, "does not place the added lines"),
+		Entry("unknown field", `{{define "site"}}{{.HTML}} {{.Screenshot}}{{end}}`, "can't evaluate field Screenshot"),
+		Entry("unknown template", `{{define "sites"}}{{.HTML}}{{end}}`, `unknown template "sites"`),
+	)
+})
+
+var _ = Describe("Loader", func() {
+	var cfg Config
+
+	write := func(source string, modTime time.Time) {
+		Expect(os.WriteFile(cfg.Path(), []byte(source), 0o644)).To(Succeed())
+		Expect(os.Chtimes(cfg.Path(), modTime, modTime)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cfg = Config{Dir: GinkgoT().TempDir()}.WithDefaults("safety")
+	})
+
+	It("should read the set named after the detector", func() {
+		Expect(cfg.Path()).To(Equal(filepath.Join(cfg.Dir, "safety.tmpl")))
+		Expect(cfg.ReloadIntervalSecond).To(Equal(30))
+	})
+
+	It("should fail on a missing or invalid set", func() {
+		_, err := NewLoader(logger.GetLogger(), cfg)
+		Expect(err).To(HaveOccurred())
+
+		write(`{{define "site"}}no placeholder{{end}}`, time.Now())
+		_, err = NewLoader(logger.GetLogger(), cfg)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reload changed sets and keep the previous one on errors", func() {
+		start := time.Now().Add(-time.Hour)
+		write(`{{define "site"}}v1 {{.HTML}}{{end}}`, start)
+		loader, err := NewLoader(logger.GetLogger(), cfg)
+		Expect(err).NotTo(HaveOccurred())
+		render := func() string {
+			prompt, err := loader.Current().Render(SiteTemplate, Data{HTML: "page"})
+			Expect(err).NotTo(HaveOccurred())
+			return prompt
+		}
+		Expect(render()).To(Equal("v1 page"))
+
+		reloaded, err := loader.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeFalse())
+
+		write(`{{define "site"}}v2 {{.HTML}}{{end}}`, start.Add(time.Minute))
+		reloaded, err = loader.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeTrue())
+		Expect(render()).To(Equal("v2 page"))
+
+		write(`{{define "site"}}v3 without the page{{end}}`, start.Add(2*time.Minute))
+		_, err = loader.Reload()
+		Expect(err).To(HaveOccurred())
+		Expect(render()).To(Equal("v2 page"))
+	})
+})
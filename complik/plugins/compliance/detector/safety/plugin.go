@@ -23,14 +23,20 @@ import (
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/bearslyricattack/CompliK/complik/pkg/concurrency"
 	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/incremental"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/nsfw"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/unchanged"
 	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
 )
 
@@ -49,7 +55,7 @@ func init() {
 
 type SafetyPlugin struct {
 	log          logger.Logger
-	reviewer     *utils.ContentReviewer
+	reviewer     utils.Reviewer
 	safetyConfig SafetyConfig
 }
 
@@ -67,6 +73,20 @@ type SafetyConfig struct {
 	APIBase    string `json:"apiBase"`
 	APIPath    string `json:"apiPath"`
 	Model      string `json:"model"`
+	// Concurrency tunes the worker count to the review API latency
+	Concurrency concurrency.Config `json:"concurrency"`
+	// SkipUnchanged answers for sites unchanged since their last compliant review
+	SkipUnchanged unchanged.Config `json:"skipUnchanged"`
+	// NSFWPrefilter scores screenshots locally before the model review
+	NSFWPrefilter nsfw.Config `json:"nsfwPrefilter"`
+	// CaptureTranscript attaches the model prompt and response to the results
+	// for the handlers storing transcripts
+	CaptureTranscript bool `json:"captureTranscript"`
+	// Prompts renders the review prompts from a hot-reloaded template set
+	Prompts prompts.Config `json:"prompts"`
+	// IncrementalReview sends only the text changes of compliant sites to a
+	// cheaper model
+	IncrementalReview incremental.Config `json:"incrementalReview"`
 }
 
 func (p *SafetyPlugin) getDefaultConfig() SafetyConfig {
@@ -105,6 +125,8 @@ func (p *SafetyPlugin) loadConfig(setting string) error {
 	if apiKey, err := config.GetSecureValue(safetyConfig.APIKey); err == nil {
 		p.safetyConfig.APIKey = apiKey
 		p.log.Debug("Using secure API key from environment/encryption")
+	} else if config.IsSecretReference(safetyConfig.APIKey) {
+		return fmt.Errorf("failed to resolve API key: %w", err)
 	} else {
 		p.safetyConfig.APIKey = safetyConfig.APIKey
 		p.log.Warn("Using plain text API key - consider using environment variables")
@@ -122,12 +144,22 @@ func (p *SafetyPlugin) loadConfig(setting string) error {
 	if safetyConfig.MaxWorkers > 0 {
 		p.safetyConfig.MaxWorkers = safetyConfig.MaxWorkers
 	}
+	p.safetyConfig.Concurrency = safetyConfig.Concurrency.WithDefaults(p.safetyConfig.MaxWorkers)
+	p.safetyConfig.SkipUnchanged = safetyConfig.SkipUnchanged.WithDefaults()
+	p.safetyConfig.NSFWPrefilter = safetyConfig.NSFWPrefilter.WithDefaults()
+	p.safetyConfig.CaptureTranscript = safetyConfig.CaptureTranscript
+	p.safetyConfig.Prompts = safetyConfig.Prompts.WithDefaults(strings.ToLower(pluginName))
+	p.safetyConfig.IncrementalReview = safetyConfig.IncrementalReview.WithDefaults()
+	if p.safetyConfig.IncrementalReview.Model == "" {
+		p.safetyConfig.IncrementalReview.Model = p.safetyConfig.Model
+	}
 
 	p.log.Info("Safety detector configuration loaded", logger.Fields{
-		"api_base":    p.safetyConfig.APIBase,
-		"api_path":    p.safetyConfig.APIPath,
-		"model":       p.safetyConfig.Model,
-		"max_workers": p.safetyConfig.MaxWorkers,
+		"api_base":             p.safetyConfig.APIBase,
+		"api_path":             p.safetyConfig.APIPath,
+		"model":                p.safetyConfig.Model,
+		"max_workers":          p.safetyConfig.Concurrency.MaxWorkers,
+		"adaptive_concurrency": p.safetyConfig.Concurrency.Adaptive,
 	})
 
 	return nil
@@ -148,40 +180,104 @@ func (p *SafetyPlugin) Start(
 		return err
 	}
 
-	p.reviewer = utils.NewContentReviewer(
-		p.log,
-		p.safetyConfig.APIKey,
-		p.safetyConfig.APIBase,
-		p.safetyConfig.APIPath,
-		p.safetyConfig.Model,
-	)
-	p.log.Debug("Content reviewer initialized")
+	if config.DryRun {
+		p.reviewer = utils.NewStubReviewer(p.log)
+		p.log.Info("Dry-run: using stub content reviewer")
+	} else {
+		reviewer := utils.NewContentReviewer(
+			p.log,
+			p.safetyConfig.APIKey,
+			p.safetyConfig.APIBase,
+			p.safetyConfig.APIPath,
+			p.safetyConfig.Model,
+		)
+		reviewer.SetCaptureTranscript(p.safetyConfig.CaptureTranscript)
+		var source prompts.Source = prompts.Builtin()
+		if p.safetyConfig.Prompts.Dir != "" {
+			loader, err := prompts.NewLoader(p.log, p.safetyConfig.Prompts)
+			if err != nil {
+				return fmt.Errorf("failed to load prompt set: %w", err)
+			}
+			go loader.Run(ctx)
+			source = loader
+			p.log.Info("Prompts are rendered from a template set", logger.Fields{
+				"path":                    p.safetyConfig.Prompts.Path(),
+				"reload_interval_seconds": p.safetyConfig.Prompts.ReloadIntervalSecond,
+			})
+		}
+		reviewer.SetPrompts(source)
+		p.reviewer = reviewer
+		p.log.Debug("Content reviewer initialized")
+
+		if cfg := p.safetyConfig.IncrementalReview; cfg.Enabled {
+			diffReviewer := utils.NewContentReviewer(
+				p.log,
+				p.safetyConfig.APIKey,
+				p.safetyConfig.APIBase,
+				p.safetyConfig.APIPath,
+				cfg.Model,
+			)
+			diffReviewer.SetCaptureTranscript(p.safetyConfig.CaptureTranscript)
+			diffReviewer.SetPrompts(source)
+			diffs, err := incremental.NewReviewer(p.log, p.reviewer, diffReviewer, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load texts of reviewed sites: %w", err)
+			}
+			p.reviewer = diffs
+			p.log.Info("Small changes of compliant sites are reviewed as diffs", logger.Fields{
+				"diff_model":        cfg.Model,
+				"max_changed_ratio": cfg.MaxChangedRatio,
+				"max_diff_bytes":    cfg.MaxDiffBytes,
+			})
+		}
+	}
+
+	if prefilter := p.safetyConfig.NSFWPrefilter; prefilter.Enabled {
+		p.reviewer = nsfw.NewReviewer(p.log, p.reviewer, nsfw.NewSkinClassifier(), prefilter)
+		p.log.Info("Screenshots are scored by the NSFW prefilter", logger.Fields{
+			"skip_threshold": prefilter.SkipThreshold,
+		})
+	}
+	if p.safetyConfig.SkipUnchanged.Enabled {
+		skipper, err := unchanged.NewReviewer(p.log, p.reviewer, p.safetyConfig.SkipUnchanged)
+		if err != nil {
+			return fmt.Errorf("failed to load fingerprints of unchanged sites: %w", err)
+		}
+		p.reviewer = skipper
+		p.log.Info("Reviews of unchanged sites are skipped", logger.Fields{
+			"max_age_hours": p.safetyConfig.SkipUnchanged.MaxAgeHour,
+		})
+	}
+	// Targets collected on several ingress paths are reviewed page by page
+	p.reviewer = utils.NewPageReviewer(p.reviewer)
 
 	subscribe := eventBus.Subscribe(constants.CollectorTopic)
 	p.log.Debug("Subscribed to collector topic", logger.Fields{
 		"topic": constants.CollectorTopic,
 	})
 
-	semaphore := make(chan struct{}, p.safetyConfig.MaxWorkers)
+	limiter := concurrency.NewLimiter(p.log, p.safetyConfig.Concurrency)
 	p.log.Info("Safety detector started", logger.Fields{
-		"worker_pool_size": p.safetyConfig.MaxWorkers,
-	})
-	time.Sleep(30 * time.Second)
-	eventBus.Publish(constants.DetectorTopic, eventbus.Event{
-		Payload: &models.DetectorInfo{
-			DiscoveryName: "Program started, Feishu notification test",
-			CollectorName: "Program started, Feishu notification test",
-			DetectorName:  p.Name(),
-			Name:          "Program started, Feishu notification test",
-			Namespace:     "Program started, Feishu notification test",
-			Host:          "",
-			Path:          nil,
-			URL:           "Program started, Feishu notification test",
-			IsIllegal:     true,
-			Description:   "Feishu message test - Program successfully started",
-			Keywords:      []string{"program_start", "feishu_test", "system_initialization"},
-		},
+		"worker_pool_size": limiter.Stats().Limit,
 	})
+	if !config.DryRun {
+		time.Sleep(30 * time.Second)
+		eventBus.Publish(constants.DetectorTopic, eventbus.Event{
+			Payload: &models.DetectorInfo{
+				DiscoveryName: "Program started, Feishu notification test",
+				CollectorName: "Program started, Feishu notification test",
+				DetectorName:  p.Name(),
+				Name:          "Program started, Feishu notification test",
+				Namespace:     "Program started, Feishu notification test",
+				Host:          "",
+				Path:          nil,
+				URL:           "Program started, Feishu notification test",
+				IsIllegal:     true,
+				Description:   "Feishu message test - Program successfully started",
+				Keywords:      []string{"program_start", "feishu_test", "system_initialization"},
+			},
+		})
+	}
 	for {
 		select {
 		case event, ok := <-subscribe:
@@ -189,9 +285,22 @@ func (p *SafetyPlugin) Start(
 				p.log.Info("Event subscription channel closed")
 				return nil
 			}
-			semaphore <- struct{}{}
+			if res, ok := event.Payload.(*models.CollectorInfo); ok && !res.Overrides.Allows(p.Name()) {
+				p.log.Debug("Skipping target overriding the detectors", logger.Fields{
+					"namespace": res.Namespace,
+					"host":      res.Host,
+					"detectors": res.Overrides.Detectors,
+				})
+				continue
+			}
+			token, err := limiter.Acquire(ctx)
+			if err != nil {
+				// ctx is done, the next iteration shuts down
+				continue
+			}
 			go func(e eventbus.Event) {
-				defer func() { <-semaphore }()
+				var reviewErr error
+				defer func() { limiter.Release(token, reviewErr) }()
 				defer func() {
 					if r := recover(); r != nil {
 						p.log.Error("Goroutine panic in safety detector", logger.Fields{
@@ -218,8 +327,11 @@ func (p *SafetyPlugin) Start(
 				})
 
 				startTime := time.Now()
-				result, err := p.safetyJudge(ctx, res)
+				taskCtx, cancel := eventBus.Policy().Context(ctx, e.Deadline, eventbus.PhaseDetect)
+				result, err := p.safetyJudge(taskCtx, res)
+				cancel()
 				duration := time.Since(startTime)
+				reviewErr = err
 
 				if err != nil {
 					p.log.Error("Safety judgement failed", logger.Fields{
@@ -255,16 +367,17 @@ func (p *SafetyPlugin) Start(
 				}
 
 				eventBus.Publish(constants.DetectorTopic, eventbus.Event{
-					Payload: result,
+					Payload:  result,
+					Deadline: e.Deadline,
 				})
 			}(event)
 		case <-ctx.Done():
 			p.log.Info("Shutting down safety detector plugin")
 			// Wait for all workers to finish
-			for range p.safetyConfig.MaxWorkers {
-				semaphore <- struct{}{}
-			}
-			p.log.Debug("All workers finished")
+			limiter.Wait()
+			p.log.Debug("All workers finished", logger.Fields{
+				"concurrency": limiter.Stats(),
+			})
 			return nil
 		}
 	}
@@ -0,0 +1,249 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets provides a compliance detector plugin that scans collected HTML and
+// inline JavaScript for leaked credentials such as cloud access keys, JWTs, private keys
+// and kubeconfig tokens. Matches are validated with an entropy check and only redacted
+// snippets are published in the detection result.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.ComplianceDetectorSecrets
+	pluginType = constants.ComplianceDetectorPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &SecretsPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type SecretsPlugin struct {
+	log           logger.Logger
+	scanner       *Scanner
+	secretsConfig SecretsConfig
+}
+
+func (p *SecretsPlugin) Name() string {
+	return pluginName
+}
+
+func (p *SecretsPlugin) Type() string {
+	return pluginType
+}
+
+type SecretsConfig struct {
+	MaxWorkers  int     `json:"maxWorkers"`
+	MinEntropy  float64 `json:"minEntropy"`
+	MaxFindings int     `json:"maxFindings"`
+}
+
+func (p *SecretsPlugin) getDefaultConfig() SecretsConfig {
+	return SecretsConfig{
+		MaxWorkers:  10,
+		MinEntropy:  3.5,
+		MaxFindings: 20,
+	}
+}
+
+func (p *SecretsPlugin) loadConfig(setting string) error {
+	p.secretsConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default secrets detector configuration")
+		return nil
+	}
+	var configFromJSON SecretsConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse configuration", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.MaxWorkers > 0 {
+		p.secretsConfig.MaxWorkers = configFromJSON.MaxWorkers
+	}
+	if configFromJSON.MinEntropy > 0 {
+		p.secretsConfig.MinEntropy = configFromJSON.MinEntropy
+	}
+	if configFromJSON.MaxFindings > 0 {
+		p.secretsConfig.MaxFindings = configFromJSON.MaxFindings
+	}
+
+	p.log.Info("Secrets detector configuration loaded", logger.Fields{
+		"max_workers":  p.secretsConfig.MaxWorkers,
+		"min_entropy":  p.secretsConfig.MinEntropy,
+		"max_findings": p.secretsConfig.MaxFindings,
+	})
+	return nil
+}
+
+func (p *SecretsPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	p.log.Info("Starting secrets detector plugin")
+
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.scanner = NewScanner(p.secretsConfig.MinEntropy, p.secretsConfig.MaxFindings)
+
+	subscribe := eventBus.Subscribe(constants.CollectorTopic)
+	semaphore := make(chan struct{}, p.secretsConfig.MaxWorkers)
+	go func() {
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				if res, ok := event.Payload.(*models.CollectorInfo); ok && !res.Overrides.Allows(p.Name()) {
+					p.log.Debug("Skipping target overriding the detectors", logger.Fields{
+						"namespace": res.Namespace,
+						"host":      res.Host,
+						"detectors": res.Overrides.Detectors,
+					})
+					continue
+				}
+				semaphore <- struct{}{}
+				go func(e eventbus.Event) {
+					defer func() { <-semaphore }()
+					defer func() {
+						if r := recover(); r != nil {
+							p.log.Error("Goroutine panic in secrets detector", logger.Fields{
+								"panic":       r,
+								"stack_trace": string(debug.Stack()),
+							})
+						}
+					}()
+
+					res, ok := e.Payload.(*models.CollectorInfo)
+					if !ok {
+						p.log.Error("Invalid event payload type", logger.Fields{
+							"expected": "*models.CollectorInfo",
+							"actual":   fmt.Sprintf("%T", e.Payload),
+						})
+						return
+					}
+
+					result := p.detect(res)
+					if result.IsIllegal {
+						p.log.Warn("Leaked credentials detected", logger.Fields{
+							"host":      result.Host,
+							"namespace": result.Namespace,
+							"severity":  result.Severity,
+							"rules":     result.Keywords,
+						})
+					}
+					eventBus.Publish(constants.DetectorTopic, eventbus.Event{
+						Payload:  result,
+						Deadline: e.Deadline,
+					})
+				}(event)
+			case <-ctx.Done():
+				p.log.Info("Shutting down secrets detector plugin")
+				return
+			}
+		}
+	}()
+
+	p.log.Info("Secrets detector started", logger.Fields{
+		"worker_pool_size": p.secretsConfig.MaxWorkers,
+	})
+	return nil
+}
+
+func (p *SecretsPlugin) Stop(ctx context.Context) error {
+	p.log.Info("Stopping secrets detector plugin")
+	return nil
+}
+
+func (p *SecretsPlugin) detect(collector *models.CollectorInfo) *models.DetectorInfo {
+	result := &models.DetectorInfo{
+		DiscoveryName: collector.DiscoveryName,
+		CollectorName: collector.CollectorName,
+		DetectorName:  p.Name(),
+		Name:          collector.Name,
+		Namespace:     collector.Namespace,
+		Host:          collector.Host,
+		Path:          collector.Path,
+		URL:           collector.URL,
+		IsIllegal:     false,
+		Keywords:      []string{},
+	}
+	if collector.IsEmpty || collector.HTML == "" {
+		result.Description = collector.CollectorMessage
+		return result
+	}
+
+	// Every page of a target collected on several paths is scanned, the
+	// result names the paths that leak
+	var findings []Finding
+	var leaking []string
+	for _, page := range collector.PageViews() {
+		found := p.scanner.Scan(page.HTML)
+		if len(found) > 0 {
+			findings = append(findings, found...)
+			leaking = append(leaking, page.Path...)
+		}
+	}
+	if len(findings) == 0 {
+		return result
+	}
+	if len(collector.Pages) > 0 {
+		result.Path = leaking
+	}
+
+	severity := ""
+	rules := make(map[string]struct{})
+	snippets := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		if models.SeverityRank(finding.Severity) > models.SeverityRank(severity) {
+			severity = finding.Severity
+		}
+		if _, ok := rules[finding.Rule]; !ok {
+			rules[finding.Rule] = struct{}{}
+			result.Keywords = append(result.Keywords, finding.Rule)
+		}
+		snippets = append(snippets, fmt.Sprintf("%s: %s", finding.Rule, finding.Snippet))
+	}
+
+	result.IsIllegal = true
+	result.Severity = severity
+	result.Description = fmt.Sprintf(
+		"Found %d potential leaked credential(s) in page source",
+		len(findings),
+	)
+	result.Explanation = strings.Join(snippets, "; ")
+	return result
+}
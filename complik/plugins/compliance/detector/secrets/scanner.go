@@ -0,0 +1,175 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// SecretRule describes a single credential pattern. When SecretGroup is set,
+// only that capture group is treated as the secret value.
+type SecretRule struct {
+	Name         string
+	Severity     string
+	Pattern      *regexp.Regexp
+	SecretGroup  int
+	CheckEntropy bool
+}
+
+// Finding is a single credential match with its value already redacted
+type Finding struct {
+	Rule     string  `json:"rule"`
+	Severity string  `json:"severity"`
+	Snippet  string  `json:"snippet"`
+	Entropy  float64 `json:"entropy"`
+}
+
+var defaultRules = []SecretRule{
+	{
+		Name:        "aws_access_key_id",
+		Severity:    models.SeverityCritical,
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16})\b`),
+		SecretGroup: 1,
+	},
+	{
+		Name:     "aws_secret_access_key",
+		Severity: models.SeverityCritical,
+		Pattern: regexp.MustCompile(
+			`(?i)aws.{0,20}?(?:secret|private)?.{0,20}?['"\s:=]+([0-9a-zA-Z/+]{40})\b`,
+		),
+		SecretGroup:  1,
+		CheckEntropy: true,
+	},
+	{
+		Name:     "private_key",
+		Severity: models.SeverityCritical,
+		Pattern: regexp.MustCompile(
+			`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`,
+		),
+	},
+	{
+		Name:     "kubeconfig_credential",
+		Severity: models.SeverityCritical,
+		Pattern: regexp.MustCompile(
+			`(?:client-key-data|client-certificate-data|token)["']?\s*:\s*["']?([A-Za-z0-9+/=._-]{40,})`,
+		),
+		SecretGroup:  1,
+		CheckEntropy: true,
+	},
+	{
+		Name:     "jwt",
+		Severity: models.SeverityHigh,
+		Pattern: regexp.MustCompile(
+			`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`,
+		),
+		SecretGroup: 1,
+	},
+	{
+		Name:     "generic_api_key",
+		Severity: models.SeverityMedium,
+		Pattern: regexp.MustCompile(
+			`(?i)(?:api[_-]?key|api[_-]?secret|access[_-]?token|client[_-]?secret)["']?\s*[:=]\s*["']([A-Za-z0-9_\-]{20,})["']`,
+		),
+		SecretGroup:  1,
+		CheckEntropy: true,
+	},
+}
+
+// Scanner matches credential patterns in page content
+type Scanner struct {
+	rules       []SecretRule
+	minEntropy  float64
+	maxFindings int
+}
+
+func NewScanner(minEntropy float64, maxFindings int) *Scanner {
+	return &Scanner{
+		rules:       defaultRules,
+		minEntropy:  minEntropy,
+		maxFindings: maxFindings,
+	}
+}
+
+// Scan returns the redacted findings in content, deduplicated by secret value
+func (s *Scanner) Scan(content string) []Finding {
+	var findings []Finding
+	seen := make(map[string]struct{})
+	for _, rule := range s.rules {
+		for _, match := range rule.Pattern.FindAllStringSubmatch(content, -1) {
+			secret := match[0]
+			if rule.SecretGroup > 0 && rule.SecretGroup < len(match) {
+				secret = match[rule.SecretGroup]
+			}
+			if _, ok := seen[secret]; ok {
+				continue
+			}
+			entropy := ShannonEntropy(secret)
+			if rule.CheckEntropy && entropy < s.minEntropy {
+				continue
+			}
+			seen[secret] = struct{}{}
+			findings = append(findings, Finding{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Snippet:  Redact(secret),
+				Entropy:  math.Round(entropy*100) / 100,
+			})
+			if s.maxFindings > 0 && len(findings) >= s.maxFindings {
+				return findings
+			}
+		}
+	}
+	return findings
+}
+
+// ShannonEntropy returns the per-character Shannon entropy of value in bits
+func ShannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Redact keeps a short prefix and suffix of value so findings can be
+// correlated without exposing the credential itself
+func Redact(value string) string {
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return value
+	}
+	runes := []rune(value)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	masked := len(runes) - 6
+	if masked > 16 {
+		masked = 16
+	}
+	return string(runes[:4]) + strings.Repeat("*", masked) + string(runes[len(runes)-2:])
+}
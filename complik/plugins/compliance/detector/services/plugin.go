@@ -0,0 +1,189 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package services provides a compliance detector plugin for the exposed TCP
+// ports fingerprinted by the TCP collector. A configurable rule set names the
+// service types tenants may not expose, e.g. game servers or remote desktops.
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.ComplianceDetectorServices
+	pluginType = constants.ComplianceDetectorPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &ServicesPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type ServicesPlugin struct {
+	log   logger.Logger
+	rules RuleSet
+}
+
+func (p *ServicesPlugin) Name() string {
+	return pluginName
+}
+
+func (p *ServicesPlugin) Type() string {
+	return pluginType
+}
+
+type ServicesConfig struct {
+	// Rules replace DefaultRules
+	Rules []Rule `json:"rules"`
+}
+
+func (p *ServicesPlugin) loadConfig(setting string) error {
+	var configFromJSON ServicesConfig
+	if setting != "" {
+		if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+			p.log.Error("Failed to parse configuration", logger.Fields{
+				"error": err.Error(),
+			})
+			return err
+		}
+	}
+	rules := configFromJSON.Rules
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	set, err := NewRuleSet(rules)
+	if err != nil {
+		return fmt.Errorf("invalid service rules: %w", err)
+	}
+	p.rules = set
+	return nil
+}
+
+func (p *ServicesPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+
+	subscribe := eventBus.Subscribe(constants.ServiceTopic)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Goroutine panic in services detector", logger.Fields{
+					"panic":       r,
+					"stack_trace": string(debug.Stack()),
+				})
+			}
+		}()
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				info, ok := event.Payload.(*models.ServiceInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.ServiceInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				result := p.detect(info)
+				if result.IsIllegal {
+					p.log.Warn("Disallowed service exposed", logger.Fields{
+						"host":      result.Host,
+						"namespace": result.Namespace,
+						"service":   info.Service,
+						"severity":  result.Severity,
+					})
+				}
+				eventBus.Publish(constants.DetectorTopic, eventbus.Event{
+					Payload: result,
+				})
+			case <-ctx.Done():
+				p.log.Info("Shutting down services detector plugin")
+				return
+			}
+		}
+	}()
+
+	p.log.Info("Services detector started", logger.Fields{
+		"rules": len(p.rules),
+	})
+	return nil
+}
+
+func (p *ServicesPlugin) Stop(ctx context.Context) error {
+	p.log.Info("Stopping services detector plugin")
+	return nil
+}
+
+func (p *ServicesPlugin) detect(info *models.ServiceInfo) *models.DetectorInfo {
+	result := &models.DetectorInfo{
+		DiscoveryName: info.DiscoveryName,
+		CollectorName: info.CollectorName,
+		DetectorName:  p.Name(),
+		Name:          info.Name,
+		Namespace:     info.Namespace,
+		Host:          info.Host,
+		Path:          []string{},
+		URL:           "tcp://" + info.Host,
+		IsIllegal:     false,
+		Keywords:      []string{},
+	}
+	if info.IsEmpty {
+		result.Description = info.CollectorMessage
+		return result
+	}
+	service := info.Service
+	if service == "" {
+		service = "unknown"
+	}
+	result.Description = "Exposed " + service + " service"
+	if info.Version != "" {
+		result.Description += " (" + info.Version + ")"
+	}
+
+	rule := p.rules.Match(info)
+	if rule == nil {
+		return result
+	}
+	result.IsIllegal = true
+	result.Severity = rule.Severity
+	result.Keywords = []string{service}
+	if rule.Description != "" {
+		result.Description = rule.Description + ": " + result.Description
+	}
+	result.Explanation = fmt.Sprintf("Banner of %s: %q", info.Host, info.Banner)
+	return result
+}
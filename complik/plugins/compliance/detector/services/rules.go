@@ -0,0 +1,96 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Rule disallows a kind of exposed service. Service matches the detected
+// protocol and Banner is a regular expression matched against the banner;
+// a rule with both needs both to match.
+type Rule struct {
+	Service     string `json:"service"`
+	Banner      string `json:"banner"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// DefaultRules are used when the configuration lists no rules
+var DefaultRules = []Rule{
+	{Service: "minecraft", Severity: models.SeverityMedium, Description: "Game server"},
+	{Service: "vnc", Severity: models.SeverityMedium, Description: "Remote desktop"},
+	{Service: "telnet", Severity: models.SeverityHigh, Description: "Unencrypted remote shell"},
+}
+
+type compiledRule struct {
+	Rule
+	banner *regexp.Regexp
+}
+
+// RuleSet is a validated list of rules
+type RuleSet []compiledRule
+
+// NewRuleSet validates rules, which are matched in order
+func NewRuleSet(rules []Rule) (RuleSet, error) {
+	set := make(RuleSet, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Service == "" && rule.Banner == "" {
+			return nil, fmt.Errorf("rule %d: service or banner is required", i)
+		}
+		if rule.Severity == "" {
+			rule.Severity = models.SeverityMedium
+		}
+		if models.SeverityRank(rule.Severity) == 0 {
+			return nil, fmt.Errorf("rule %d: unknown severity %q", i, rule.Severity)
+		}
+		compiled := compiledRule{Rule: rule}
+		if rule.Banner != "" {
+			pattern, err := regexp.Compile(rule.Banner)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			compiled.banner = pattern
+		}
+		set = append(set, compiled)
+	}
+	if len(set) == 0 {
+		return nil, errors.New("no rules")
+	}
+	return set, nil
+}
+
+// Match returns the first rule info breaks, nil when it breaks none
+func (s RuleSet) Match(info *models.ServiceInfo) *Rule {
+	if info.IsEmpty {
+		return nil
+	}
+	for i := range s {
+		rule := &s[i]
+		if rule.Service != "" && !strings.EqualFold(rule.Service, info.Service) {
+			continue
+		}
+		if rule.banner != nil && !rule.banner.MatchString(info.Banner) {
+			continue
+		}
+		return &rule.Rule
+	}
+	return nil
+}
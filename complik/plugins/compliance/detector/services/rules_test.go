@@ -0,0 +1,85 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestServices(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Services Suite")
+}
+
+var _ = Describe("RuleSet", func() {
+	It("should match services and banners", func() {
+		set, err := NewRuleSet([]Rule{
+			{Service: "ssh", Banner: `(?i)dropbear`, Severity: models.SeverityLow},
+			{Service: "Minecraft", Description: "Game server"},
+			{Banner: `xmrig`, Severity: models.SeverityCritical},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(set.Match(&models.ServiceInfo{Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"})).To(BeNil())
+		Expect(set.Match(&models.ServiceInfo{Service: "ssh", Banner: "SSH-2.0-dropbear_2022"}).Severity).
+			To(Equal(models.SeverityLow))
+		Expect(set.Match(&models.ServiceInfo{Service: "minecraft"}).Severity).To(Equal(models.SeverityMedium))
+		Expect(set.Match(&models.ServiceInfo{Banner: "xmrig-proxy 6.21"}).Severity).To(Equal(models.SeverityCritical))
+		Expect(set.Match(&models.ServiceInfo{Service: "minecraft", IsEmpty: true})).To(BeNil())
+	})
+
+	It("should reject invalid rules", func() {
+		_, err := NewRuleSet([]Rule{{Severity: models.SeverityHigh}})
+		Expect(err).To(MatchError(ContainSubstring("service or banner is required")))
+		_, err = NewRuleSet([]Rule{{Service: "ssh", Severity: "urgent"}})
+		Expect(err).To(MatchError(ContainSubstring("unknown severity")))
+		_, err = NewRuleSet([]Rule{{Banner: "("}})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ServicesPlugin", func() {
+	It("should flag disallowed services with the default rules", func() {
+		p := &ServicesPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig("")).To(Succeed())
+
+		result := p.detect(&models.ServiceInfo{
+			Name: "mc", Namespace: "ns-demo", Host: "203.0.113.7:25565",
+			Service: "minecraft", Version: "Paper 1.21.1", Banner: "{...}",
+		})
+		Expect(result.IsIllegal).To(BeTrue())
+		Expect(result.Severity).To(Equal(models.SeverityMedium))
+		Expect(result.URL).To(Equal("tcp://203.0.113.7:25565"))
+		Expect(result.Keywords).To(Equal([]string{"minecraft"}))
+		Expect(result.Description).To(Equal("Game server: Exposed minecraft service (Paper 1.21.1)"))
+
+		result = p.detect(&models.ServiceInfo{Host: "203.0.113.7:5432", Service: "postgresql"})
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Description).To(Equal("Exposed postgresql service"))
+	})
+
+	It("should replace the default rules with configured ones", func() {
+		p := &ServicesPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"rules":[{"service":"postgresql","severity":"high"}]}`)).To(Succeed())
+		Expect(p.detect(&models.ServiceInfo{Service: "minecraft"}).IsIllegal).To(BeFalse())
+		Expect(p.detect(&models.ServiceInfo{Service: "postgresql"}).Severity).To(Equal(models.SeverityHigh))
+		Expect(p.loadConfig(`{"rules":[{"severity":"high"}]}`)).To(MatchError(ContainSubstring("invalid service rules")))
+	})
+})
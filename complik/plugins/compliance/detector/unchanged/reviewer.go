@@ -0,0 +1,266 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package unchanged skips the model review of sites that did not change since
+// their last compliant review. The screenshot and HTML of each review are
+// fingerprinted; a later page whose fingerprints are within the configured
+// distances of the last compliant review gets a "no change" result instead.
+package unchanged
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/fingerprint"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+)
+
+// Config is the skipUnchanged section of a detector plugin configuration
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// ScreenshotDistance and HTMLDistance are the most bits out of 64 the
+	// screenshot pHash and the HTML SimHash may differ by
+	ScreenshotDistance int `json:"screenshotDistance"`
+	HTMLDistance       int `json:"htmlDistance"`
+	// MaxAgeHour forces a review of sites unchanged for this long
+	MaxAgeHour int `json:"maxAgeHour"`
+	// StatePath is the JSON file the fingerprints survive restarts in
+	StatePath string `json:"statePath"`
+}
+
+// WithDefaults fills the unset values of c
+func (c Config) WithDefaults() Config {
+	if c.ScreenshotDistance <= 0 {
+		c.ScreenshotDistance = 4
+	}
+	if c.HTMLDistance <= 0 {
+		c.HTMLDistance = 3
+	}
+	if c.MaxAgeHour <= 0 {
+		c.MaxAgeHour = 7 * 24
+	}
+	return c
+}
+
+// site is the fingerprint of the last compliant review of a site
+type site struct {
+	Screenshot  uint64    `json:"screenshot"`
+	HTML        uint64    `json:"html"`
+	Rules       string    `json:"rules,omitempty"`
+	ReviewedAt  time.Time `json:"reviewed_at"`
+	Description string    `json:"description,omitempty"`
+	Keywords    []string  `json:"keywords,omitempty"`
+}
+
+// Reviewer wraps the reviewer of a detector and answers for unchanged sites
+type Reviewer struct {
+	log    logger.Logger
+	next   utils.Reviewer
+	cfg    Config
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*site
+	skipped int64
+}
+
+// NewReviewer wraps next, loading the fingerprints of cfg.StatePath
+func NewReviewer(log logger.Logger, next utils.Reviewer, cfg Config) (*Reviewer, error) {
+	cfg = cfg.WithDefaults()
+	r := &Reviewer{
+		log:     log,
+		next:    next,
+		cfg:     cfg,
+		maxAge:  time.Duration(cfg.MaxAgeHour) * time.Hour,
+		now:     time.Now,
+		entries: make(map[string]*site),
+	}
+	if cfg.StatePath == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(cfg.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint state: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse fingerprint state %s: %w", cfg.StatePath, err)
+	}
+	return r, nil
+}
+
+func (r *Reviewer) ReviewSiteContent(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	// Without a screenshot the page cannot be compared
+	if content == nil || content.IsEmpty || len(content.Screenshot) == 0 {
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	screenshot, err := fingerprint.Image(content.Screenshot)
+	if err != nil {
+		r.log.Debug("Screenshot not fingerprinted", logger.Fields{
+			"host":  content.Host,
+			"error": err.Error(),
+		})
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	current := site{
+		Screenshot: screenshot,
+		HTML:       fingerprint.Text(content.HTML),
+		Rules:      rulesDigest(customRules),
+	}
+	key := name + "|" + siteKey(content)
+
+	if last := r.unchanged(key, current); last != nil {
+		r.log.Debug("Review skipped, site unchanged", logger.Fields{
+			"host":        content.Host,
+			"reviewed_at": last.ReviewedAt,
+		})
+		return &models.DetectorInfo{
+			DiscoveryName: content.DiscoveryName,
+			CollectorName: content.CollectorName,
+			DetectorName:  name,
+			Name:          content.Name,
+			Namespace:     content.Namespace,
+			Host:          content.Host,
+			Path:          content.Path,
+			URL:           content.URL,
+			IsIllegal:     false,
+			Unchanged:     true,
+			Description:   last.Description,
+			Keywords:      last.Keywords,
+			Explanation:   "No change since the compliant review of " + last.ReviewedAt.Format(time.RFC3339),
+			Metadata:      content.Metadata,
+		}, nil
+	}
+
+	result, err := r.next.ReviewSiteContent(ctx, content, name, customRules)
+	if err != nil {
+		return result, err
+	}
+	current.ReviewedAt = r.now()
+	current.Description, current.Keywords = result.Description, result.Keywords
+	r.record(key, current, result.IsIllegal)
+	return result, nil
+}
+
+// unchanged returns the last compliant review of key if current matches it
+func (r *Reviewer) unchanged(key string, current site) *site {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last, ok := r.entries[key]
+	if !ok || last.Rules != current.Rules || r.now().Sub(last.ReviewedAt) >= r.maxAge ||
+		fingerprint.Distance(last.Screenshot, current.Screenshot) > r.cfg.ScreenshotDistance ||
+		fingerprint.Distance(last.HTML, current.HTML) > r.cfg.HTMLDistance {
+		return nil
+	}
+	r.skipped++
+	copied := *last
+	return &copied
+}
+
+// record keeps the fingerprint of a compliant review; an illegal result
+// forgets the site so it is reviewed every time until it is fixed
+func (r *Reviewer) record(key string, entry site, illegal bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if illegal {
+		if _, ok := r.entries[key]; !ok {
+			return
+		}
+		delete(r.entries, key)
+	} else {
+		r.entries[key] = &entry
+	}
+	now := r.now()
+	for key, last := range r.entries {
+		if now.Sub(last.ReviewedAt) >= r.maxAge {
+			delete(r.entries, key)
+		}
+	}
+	// A lost state only costs extra reviews
+	if err := r.save(); err != nil {
+		r.log.Warn("Failed to save fingerprint state", logger.Fields{"error": err.Error()})
+	}
+}
+
+// Skipped returns the number of reviews skipped so far
+func (r *Reviewer) Skipped() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipped
+}
+
+// save writes the fingerprints to the state file; callers hold r.mu
+func (r *Reviewer) save() error {
+	if r.cfg.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode fingerprint state: %w", err)
+	}
+	if dir := filepath.Dir(r.cfg.StatePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create fingerprint state directory: %w", err)
+		}
+	}
+	// Write through a temporary file so a crash never leaves a truncated state
+	tmp := r.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write fingerprint state: %w", err)
+	}
+	if err := os.Rename(tmp, r.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to replace fingerprint state: %w", err)
+	}
+	return nil
+}
+
+// siteKey identifies a site across scans
+func siteKey(content *models.CollectorInfo) string {
+	if content.URL != "" {
+		return content.URL
+	}
+	return content.Host + "/" + strings.Join(content.Path, ",")
+}
+
+// rulesDigest changes whenever the rules a review used change, so edited
+// rules are applied to unchanged sites too
+func rulesDigest(rules []utils.CustomKeywordRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, rule := range rules {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00", rule.Type, rule.Keywords, rule.Description)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unchanged
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnchanged(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Unchanged Suite")
+}
+
+// countingReviewer flags pages containing "casino" and counts its reviews
+type countingReviewer struct {
+	reviews int
+	err     error
+}
+
+func (c *countingReviewer) ReviewSiteContent(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	_ []utils.CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	c.reviews++
+	if c.err != nil {
+		return &models.DetectorInfo{Host: content.Host}, c.err
+	}
+	illegal := bytes.Contains([]byte(content.HTML), []byte("casino"))
+	return &models.DetectorInfo{
+		DetectorName: name, Host: content.Host, URL: content.URL,
+		IsIllegal: illegal, Description: "A bakery", Keywords: []string{"bread"},
+	}, nil
+}
+
+func screenshot(stripes int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for y := range 240 {
+		for x := range 320 {
+			c := color.Color(color.White)
+			if (y*stripes/240)%2 == 0 {
+				c = color.Gray{Y: 30}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("Reviewer", func() {
+	const bakery = "<h1>Welcome to the bakery</h1><p>Fresh bread every morning and coffee all day long.</p>"
+
+	var (
+		next *countingReviewer
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		next = &countingReviewer{}
+		now = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	newReviewer := func(cfg Config) *Reviewer {
+		r, err := NewReviewer(logger.GetLogger(), next, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		r.now = func() time.Time { return now }
+		return r
+	}
+
+	review := func(r *Reviewer, html string, shot []byte, rules ...utils.CustomKeywordRule) *models.DetectorInfo {
+		result, err := r.ReviewSiteContent(context.Background(), &models.CollectorInfo{
+			Host: "a.example.com", URL: "https://a.example.com", HTML: html, Screenshot: shot,
+		}, "Safety", rules)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	It("should skip the review of an unchanged compliant site", func() {
+		r := newReviewer(Config{})
+		Expect(review(r, bakery, screenshot(8)).Unchanged).To(BeFalse())
+		now = now.Add(time.Hour)
+		result := review(r, "<div>"+bakery+"</div>", screenshot(8))
+		Expect(next.reviews).To(Equal(1))
+		Expect(result.Unchanged).To(BeTrue())
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Description).To(Equal("A bakery"))
+		Expect(result.Explanation).To(ContainSubstring("2025-06-01T00:00:00Z"))
+		Expect(r.Skipped()).To(Equal(int64(1)))
+	})
+
+	It("should review changed pages, changed rules and old reviews", func() {
+		r := newReviewer(Config{MaxAgeHour: 24})
+		review(r, bakery, screenshot(8))
+		review(r, bakery, screenshot(2))
+		Expect(next.reviews).To(Equal(2))
+		review(r, "<h1>Online casino</h1><p>Roulette, poker and slots with instant payouts</p>", screenshot(2))
+		Expect(next.reviews).To(Equal(3))
+		review(r, bakery, screenshot(2), utils.CustomKeywordRule{Type: "fraud", Keywords: "usdt"})
+		Expect(next.reviews).To(Equal(4))
+		now = now.Add(25 * time.Hour)
+		review(r, bakery, screenshot(2), utils.CustomKeywordRule{Type: "fraud", Keywords: "usdt"})
+		Expect(next.reviews).To(Equal(5))
+	})
+
+	It("should keep reviewing sites once they were flagged", func() {
+		r := newReviewer(Config{})
+		casino := "<h1>Bakery</h1><p>casino</p>"
+		review(r, bakery, screenshot(8))
+		Expect(review(r, casino, screenshot(8)).IsIllegal).To(BeTrue())
+		Expect(review(r, casino, screenshot(8)).IsIllegal).To(BeTrue())
+		Expect(next.reviews).To(Equal(3))
+	})
+
+	It("should pass through pages without screenshots and review errors", func() {
+		r := newReviewer(Config{})
+		review(r, bakery, nil)
+		review(r, bakery, nil)
+		Expect(next.reviews).To(Equal(2))
+
+		next.err = errors.New("HTTP 429")
+		_, err := r.ReviewSiteContent(context.Background(), &models.CollectorInfo{
+			Host: "b.example.com", HTML: bakery, Screenshot: screenshot(8),
+		}, "Safety", nil)
+		Expect(err).To(MatchError("HTTP 429"))
+		next.err = nil
+		review(r, bakery, screenshot(8))
+		Expect(next.reviews).To(Equal(4))
+	})
+
+	It("should keep fingerprints across restarts", func() {
+		cfg := Config{StatePath: filepath.Join(GinkgoT().TempDir(), "state", "fingerprints.json")}
+		review(newReviewer(cfg), bakery, screenshot(8))
+		Expect(review(newReviewer(cfg), bakery, screenshot(8)).Unchanged).To(BeTrue())
+		Expect(next.reviews).To(Equal(1))
+	})
+})
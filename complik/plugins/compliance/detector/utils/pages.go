@@ -0,0 +1,94 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// PageReviewer reviews the pages of a target collected for several ingress
+// paths one by one. The first flagged page decides the result, whose Path is
+// the path of that page and whose Usage adds up the tokens of every review.
+type PageReviewer struct {
+	next Reviewer
+}
+
+// NewPageReviewer wraps next, which reviews a single page
+func NewPageReviewer(next Reviewer) *PageReviewer {
+	return &PageReviewer{next: next}
+}
+
+// ReviewSiteContent reviews every page of content with the wrapped
+// reviewer. Compliant targets are reported with the paths of content; a page
+// that could not be reviewed returns its error next to the result of the
+// other pages.
+func (r *PageReviewer) ReviewSiteContent(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	views := content.PageViews()
+	if len(views) == 1 {
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	var compliant, failed *models.DetectorInfo
+	var reviewErr error
+	var usage *models.TokenUsage
+	withUsage := func(result *models.DetectorInfo) *models.DetectorInfo {
+		if result != nil && usage != nil {
+			result.Usage = usage
+		}
+		return result
+	}
+	for _, view := range views {
+		if view.IsEmpty {
+			continue
+		}
+		result, err := r.next.ReviewSiteContent(ctx, view, name, customRules)
+		if result != nil && result.Usage != nil {
+			if usage == nil {
+				usage = &models.TokenUsage{}
+			}
+			usage.Add(result.Usage)
+		}
+		if err != nil {
+			if reviewErr == nil {
+				failed, reviewErr = result, err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if result.IsIllegal {
+			return withUsage(result), nil
+		}
+		if compliant == nil {
+			compliant = result
+		}
+	}
+	if compliant == nil {
+		if reviewErr != nil {
+			return withUsage(failed), reviewErr
+		}
+		return r.next.ReviewSiteContent(ctx, content, name, customRules)
+	}
+	compliant.Path = content.Path
+	compliant.URL = content.URL
+	return withUsage(compliant), reviewErr
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Detector Utils Suite")
+}
+
+// failingReviewer fails the review of the pages with HTML "fail"
+type failingReviewer struct {
+	next Reviewer
+}
+
+func (r failingReviewer) ReviewSiteContent(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	if content.HTML == "fail" {
+		return &models.DetectorInfo{Host: content.Host, Path: content.Path}, errors.New("model unavailable")
+	}
+	return r.next.ReviewSiteContent(ctx, content, name, customRules)
+}
+
+var _ = Describe("PageReviewer", func() {
+	ctx := context.Background()
+	reviewer := NewPageReviewer(failingReviewer{next: NewStubReviewer(logger.GetLogger())})
+
+	target := func(pages ...models.PageInfo) *models.CollectorInfo {
+		return &models.CollectorInfo{
+			Name:      "web",
+			Namespace: "ns-test",
+			Host:      "shop.example.com",
+			Path:      []string{"/", "/shop", "/admin"},
+			URL:       "http://shop.example.com",
+			HTML:      pages[0].HTML,
+			Pages:     pages,
+		}
+	}
+
+	It("should review targets with a single page as they are", func() {
+		result, err := reviewer.ReviewSiteContent(ctx, &models.CollectorInfo{
+			Host: "shop.example.com",
+			Path: []string{"/"},
+			HTML: "online casino",
+		}, "test", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeTrue())
+		Expect(result.Path).To(Equal([]string{"/"}))
+	})
+
+	It("should report the path of the flagged page", func() {
+		result, err := reviewer.ReviewSiteContent(ctx, target(
+			models.PageInfo{Path: "/", URL: "http://shop.example.com", HTML: "welcome"},
+			models.PageInfo{Path: "/shop", IsEmpty: true},
+			models.PageInfo{Path: "/admin", URL: "http://shop.example.com/admin", HTML: "baccarat tables"},
+		), "test", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeTrue())
+		Expect(result.Path).To(Equal([]string{"/admin"}))
+		Expect(result.URL).To(Equal("http://shop.example.com/admin"))
+	})
+
+	It("should report compliant targets with all their paths", func() {
+		result, err := reviewer.ReviewSiteContent(ctx, target(
+			models.PageInfo{Path: "/", URL: "http://shop.example.com", HTML: "welcome"},
+			models.PageInfo{Path: "/shop", URL: "http://shop.example.com/shop", HTML: "shoes"},
+		), "test", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeFalse())
+		Expect(result.Path).To(Equal([]string{"/", "/shop", "/admin"}))
+		Expect(result.URL).To(Equal("http://shop.example.com"))
+	})
+
+	It("should return review errors next to the result of the other pages", func() {
+		result, err := reviewer.ReviewSiteContent(ctx, target(
+			models.PageInfo{Path: "/", HTML: "fail"},
+			models.PageInfo{Path: "/shop", HTML: "shoes"},
+		), "test", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(result.IsIllegal).To(BeFalse())
+
+		result, err = reviewer.ReviewSiteContent(ctx, target(
+			models.PageInfo{Path: "/", HTML: "fail"},
+			models.PageInfo{Path: "/shop", HTML: "casino"},
+		), "test", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Path).To(Equal([]string{"/shop"}))
+	})
+})
@@ -29,8 +29,10 @@ import (
 	"strings"
 	"time"
 
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
 )
 
 type ContentReviewer struct {
@@ -38,6 +40,12 @@ type ContentReviewer struct {
 	apiKey string
 	apiURL string
 	model  string
+
+	promptTemplate string
+	// prompts renders the prompts, the built-in ones when nil
+	prompts prompts.Source
+	// captureTranscript attaches the model exchange to the results
+	captureTranscript bool
 }
 
 func NewContentReviewer(
@@ -53,6 +61,25 @@ func NewContentReviewer(
 	}
 }
 
+// SetPromptTemplate replaces the built-in prompt used when no custom rules are
+// given. The template must contain the {{html}} placeholder for the page HTML
+// and may contain the {{metadata}} placeholder for the site metadata.
+func (r *ContentReviewer) SetPromptTemplate(template string) {
+	r.promptTemplate = template
+}
+
+// SetPrompts renders the prompts with the current set of source, which takes
+// precedence over the built-in prompts but not over SetPromptTemplate
+func (r *ContentReviewer) SetPrompts(source prompts.Source) {
+	r.prompts = source
+}
+
+// SetCaptureTranscript attaches the rendered prompt, the raw model response and
+// the token usage to the results, for explaining disputed decisions
+func (r *ContentReviewer) SetCaptureTranscript(capture bool) {
+	r.captureTranscript = capture
+}
+
 func (r *ContentReviewer) ReviewSiteContent(
 	ctx context.Context,
 	content *models.CollectorInfo,
@@ -69,7 +96,7 @@ func (r *ContentReviewer) ReviewSiteContent(
 		"has_custom_rules": len(customRules) > 0,
 	})
 
-	requestData, err := r.prepareRequestData(content, customRules)
+	prompt, err := r.renderPrompt(r.truncateHTML(content.HTML), buildMetadata(content.Metadata), customRules)
 	if err != nil {
 		r.log.Error("Failed to prepare request data", logger.Fields{
 			"error": err.Error(),
@@ -77,6 +104,49 @@ func (r *ContentReviewer) ReviewSiteContent(
 		})
 		return nil, fmt.Errorf("failed to prepare request data: %w", err)
 	}
+	return r.review(ctx, content, name, prompt)
+}
+
+// ReviewSiteDiff reviews the text changes of content since its last
+// compliant review, summarized by diff, together with the current screenshot
+func (r *ContentReviewer) ReviewSiteDiff(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name string,
+	diff *prompts.Diff,
+) (*models.DetectorInfo, error) {
+	if content == nil {
+		r.log.Error("Review called with nil content")
+		return nil, errors.New("ScrapeResult parameter is nil")
+	}
+
+	r.log.Debug("Preparing diff review request", logger.Fields{
+		"host":          content.Host,
+		"added_lines":   len(diff.Added),
+		"removed_lines": len(diff.Removed),
+	})
+
+	prompt, err := r.currentPrompts().Render(prompts.DiffTemplate, prompts.Data{
+		Metadata: buildMetadata(content.Metadata),
+		Diff:     diff,
+	})
+	if err != nil {
+		r.log.Error("Failed to prepare request data", logger.Fields{
+			"error": err.Error(),
+			"host":  content.Host,
+		})
+		return nil, fmt.Errorf("failed to prepare request data: %w", err)
+	}
+	return r.review(ctx, content, name, prompt)
+}
+
+// review sends prompt with the screenshot of content to the model
+func (r *ContentReviewer) review(
+	ctx context.Context,
+	content *models.CollectorInfo,
+	name, prompt string,
+) (*models.DetectorInfo, error) {
+	requestData := r.prepareRequestData(content, prompt)
 
 	r.log.Debug("Calling review API", logger.Fields{
 		"api_url": r.apiURL,
@@ -88,6 +158,7 @@ func (r *ContentReviewer) ReviewSiteContent(
 		r.log.Error("API call failed", logger.Fields{
 			"error": err.Error(),
 			"host":  content.Host,
+			"class": complikerrors.Record(name, err),
 		})
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
@@ -101,6 +172,26 @@ func (r *ContentReviewer) ReviewSiteContent(
 		})
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	model := response.Model
+	if model == "" {
+		model = r.model
+	}
+	result.Usage = &models.TokenUsage{
+		Model:            model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}
+	if r.captureTranscript {
+		result.Transcript = &models.ModelTranscript{
+			Model:            model,
+			Prompt:           prompt,
+			Response:         response.raw,
+			PromptTokens:     response.Usage.PromptTokens,
+			CompletionTokens: response.Usage.CompletionTokens,
+			TotalTokens:      response.Usage.TotalTokens,
+		}
+	}
 
 	r.log.Debug("Review completed", logger.Fields{
 		"host":           content.Host,
@@ -111,12 +202,8 @@ func (r *ContentReviewer) ReviewSiteContent(
 	return result, nil
 }
 
-func (r *ContentReviewer) prepareRequestData(
-	content *models.CollectorInfo,
-	customRules []CustomKeywordRule,
-) (map[string]any, error) {
-	base64Image := base64.StdEncoding.EncodeToString(content.Screenshot)
-	htmlContent := content.HTML
+// truncateHTML bounds the HTML placed in the prompt
+func (r *ContentReviewer) truncateHTML(htmlContent string) string {
 	originalLength := len(htmlContent)
 	if len(htmlContent) > 10000 {
 		htmlContent = htmlContent[:10000] + "..."
@@ -125,12 +212,20 @@ func (r *ContentReviewer) prepareRequestData(
 			"truncated_to":    10000,
 		})
 	}
-	var prompt string
-	if customRules == nil || len(customRules) == 0 {
-		prompt = r.buildPrompt(htmlContent)
-	} else {
-		prompt = r.buildCustomPrompt(htmlContent, customRules)
+	return htmlContent
+}
+
+// imageType returns the media type of a screenshot, whose format depends on
+// the collector settings
+func imageType(screenshot []byte) string {
+	if contentType := http.DetectContentType(screenshot); strings.HasPrefix(contentType, "image/") {
+		return contentType
 	}
+	return "image/png"
+}
+
+func (r *ContentReviewer) prepareRequestData(content *models.CollectorInfo, prompt string) map[string]any {
+	base64Image := base64.StdEncoding.EncodeToString(content.Screenshot)
 	requestData := map[string]any{
 		"model": r.model,
 		"messages": []map[string]any{
@@ -144,7 +239,7 @@ func (r *ContentReviewer) prepareRequestData(
 					{
 						"type": "image_url",
 						"image_url": map[string]string{
-							"url": "data:image/png;base64," + base64Image,
+							"url": "data:" + imageType(content.Screenshot) + ";base64," + base64Image,
 						},
 					},
 				},
@@ -153,128 +248,78 @@ func (r *ContentReviewer) prepareRequestData(
 		"max_completion_tokens": 6000,
 		"response_format":       ReviewResultSchema,
 	}
-	return requestData, nil
+	return requestData
 }
 
-func (r *ContentReviewer) buildPrompt(htmlContent string) string {
-	return `# Role: Content Analysis and Compliance Checker
-
-# Goal:
-1. Provide a brief one-sentence description of the given webpage content or purpose.
-2. Extract several keywords relevant to the webpage.
-3. Determine whether the webpage contains content that violates Chinese laws and regulations, particularly in the following categories: pornography, political sensitivity, prohibited items, gambling, cult activities, violence/terrorism, fraud, and infringement.
-
-# Instructions:
-1. **Content Description**: Based on the HTML file and webpage screenshot, generate a one-sentence summary describing the main content or purpose of the webpage.
-
-2. **Keyword Extraction**: Extract up to 5 keywords most relevant to the webpage content.
-
-3. **Compliance Assessment**: Analyze the webpage content to determine if it contains the following illegal or non-compliant content, and provide a brief explanation.
-   - **Pornographic Content**: Are there any sexually explicit images, text, or videos?
-   - **Political Sensitivity**: Is there politically sensitive information or criticism of the Chinese government?
-   - **Prohibited Items**: Are there any items, behaviors, or services prohibited by Chinese law?
-   - **Gambling Content**: Does it involve gambling activities or advertisements?
-   - **Cult Content**: Does it promote cult or extreme religious ideology?
-   - **Violence/Terrorism**: Does it contain violent or terrorist content?
-   - **Fraud Content**: Does it contain online fraud content?
-   - **Infringement Content**: Does it contain infringing content?
-   - **Fraud Detection**: Pay special attention to chat pages; if it's a chat page, determine whether it involves suspected fraud.
-
-# Important Notes:
-I am providing you with both a webpage screenshot and HTML code. Please analyze both sources comprehensively. Some content may be more obvious in the screenshot, while other content may need to be analyzed from the HTML code. Stay vigilant; even seemingly normal websites may hide non-compliant content in the code.
-Pay special attention to social platforms like Weibo, WeChat, Douyin, Kuaishou, Xiaohongshu, and other well-known platforms to prevent fraudulent content. Also be particularly alert for keywords related to gambling, pornography, political sensitivity, violence/terrorism, and cult activities.
-
-## Special Reminder
-If the page shows 404 errors, various errors, blank pages, or missing resources, it should be considered compliant.
-
-# HTML Code Excerpt:
-` + "```html\n" + htmlContent + "\n```" + `
-
-# Output:
-Please output strictly in the following JSON format without any additional explanation or text:
-
-{
-  "description": "<Generated webpage description>",
-  "keywords": ["<keyword1>", "<keyword2>", "<keyword3>", "<keyword4>", "<keyword5>"],
-  "compliance": {
-    "is_illegal": "<Yes/No>",
-    "explanation": "<Brief explanation listing specific violated categories and evidence>"
-  }
-}`
+// currentPrompts returns the prompt set of the next review
+func (r *ContentReviewer) currentPrompts() *prompts.Set {
+	if r.prompts != nil {
+		return r.prompts.Current()
+	}
+	return prompts.Builtin()
 }
 
-func (r *ContentReviewer) buildRulesDescription(rules []CustomKeywordRule) string {
-	var builder strings.Builder
-	for _, rule := range rules {
+// renderPrompt renders the "site" prompt, or the "rules" prompt when custom
+// rules are given
+func (r *ContentReviewer) renderPrompt(
+	htmlContent, metadata string,
+	customRules []CustomKeywordRule,
+) (string, error) {
+	if len(customRules) == 0 && r.promptTemplate != "" {
+		return strings.NewReplacer("{{html}}", htmlContent, "{{metadata}}", metadata).Replace(r.promptTemplate), nil
+	}
+	set := r.currentPrompts()
+	data := prompts.Data{HTML: htmlContent, Metadata: metadata}
+	if len(customRules) == 0 {
+		return set.Render(prompts.SiteTemplate, data)
+	}
+	data.Rules = make([]prompts.Rule, 0, len(customRules))
+	for _, rule := range customRules {
 		keywords := strings.Split(rule.Keywords, ".")
-		for j, keyword := range keywords {
-			trimmed := strings.TrimSpace(keyword)
-			keywords[j] = trimmed
+		for i, keyword := range keywords {
+			keywords[i] = strings.TrimSpace(keyword)
 		}
-
-		ruleText := fmt.Sprintf(`
-### %s
-- Description: %s
-- Keywords: %s
-`, rule.Type, rule.Description, strings.Join(keywords, ", "))
-
-		builder.WriteString(ruleText)
+		data.Rules = append(data.Rules, prompts.Rule{
+			Type:        rule.Type,
+			Description: rule.Description,
+			Keywords:    keywords,
+		})
 	}
-	result := builder.String()
-	return result
+	return set.Render(prompts.RulesTemplate, data)
 }
 
-func (r *ContentReviewer) buildCustomPrompt(
-	htmlContent string,
-	customRules []CustomKeywordRule,
-) string {
-	rulesDescription := r.buildRulesDescription(customRules)
-	return fmt.Sprintf(`# Role: Intelligent Webpage Content Compliance Detection Expert
-
-# Task Objective:
-Conduct a comprehensive analysis of the provided webpage content, focusing on detecting custom keyword rules, and output results strictly in JSON format.
-
-# Analysis Requirements:
-
-## 1. Content Description
-- Based on HTML code analysis, provide a one-sentence concise summary of the webpage's main content or purpose
-- The description should be accurate, objective, and no more than 50 characters
-
-## 2. Keyword Extraction
-- Extract keywords that best represent the webpage content
-- Multiple keywords separated by commas, up to 5
-- Keywords should accurately reflect the core content of the webpage
-
-## 3. Custom Rule Detection
-Please strictly detect according to the following custom rules:
-
-%s
-
-## Detection Instructions:
-- Carefully analyze the text content in the HTML code
-- Check each custom rule one by one
-- Record all matching keywords and corresponding rules
-
-# HTML Code:
-%s
-
-# Important Notes:
-I am providing you with both a webpage screenshot and HTML code. Please analyze both sources comprehensively. Some content may be more obvious in the screenshot, while other content may need to be analyzed from the HTML code. Stay vigilant; even seemingly normal websites may hide non-compliant content in the code.
-If the page shows access errors, is blank, or resources do not exist, it should be considered compliant.
-
-# Output Requirements:
-Please output strictly in the following JSON format without any additional explanation or text:
-
-{
-  "is_compliant": true,
-  "keywords": "keyword1,keyword2,keyword3",
-  "description": "One-sentence description of webpage content"
-}
-
-Notes:
-- is_compliant: true indicates compliant content, false indicates non-compliant content found
-- keywords: Multiple keywords separated by commas
-- description: Concise one-sentence description`, rulesDescription, htmlContent)
+// buildMetadata renders the site metadata as a prompt section, empty when
+// nothing was gathered
+func buildMetadata(metadata *models.SiteMetadata) string {
+	if metadata == nil {
+		return ""
+	}
+	var builder strings.Builder
+	for _, field := range []struct {
+		name, value string
+	}{
+		{"Title", metadata.Title},
+		{"Description", metadata.Description},
+		{"Generator", metadata.Generator},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&builder, "- %s: %s\n", field.name, field.value)
+		}
+	}
+	for _, file := range []struct {
+		name, value string
+	}{
+		{"robots.txt", metadata.RobotsTxt},
+		{"security.txt", metadata.SecurityTxt},
+	} {
+		if file.value != "" {
+			fmt.Fprintf(&builder, "- %s:\n```text\n%s\n```\n", file.name, file.value)
+		}
+	}
+	if builder.Len() == 0 {
+		return ""
+	}
+	return "\n# Site Metadata:\nThe title and meta tags of the page and the well-known files of its host; they often name the real purpose of a site.\n" + builder.String()
 }
 
 func (r *ContentReviewer) callAPI(
@@ -341,12 +386,13 @@ func (r *ContentReviewer) callAPI(
 			"error_text":  errorText,
 			"url":         r.apiURL,
 		})
-		return nil, fmt.Errorf("API call failed: status code %d", resp.StatusCode)
+		return nil, complikerrors.FromResponse(resp, fmt.Errorf("API call failed: status code %d", resp.StatusCode))
 	}
 	var responseData APIResponse
 	if err := json.Unmarshal(body, &responseData); err != nil {
 		return nil, fmt.Errorf("failed to decode API response: %w", err)
 	}
+	responseData.raw = string(body)
 	if len(responseData.Choices) == 0 {
 		r.log.Error("API response has no choices")
 		return nil, errors.New("no results in API response")
@@ -399,6 +445,7 @@ func (r *ContentReviewer) parseResponse(
 		Description:   result.Description,
 		Keywords:      keywords,
 		Explanation:   explanation,
+		Metadata:      content.Metadata,
 	}, nil
 }
 
@@ -413,6 +460,10 @@ type CustomKeywordRule struct {
 	Type        string `json:"type"`
 	Keywords    string `json:"keywords"`
 	Description string `json:"description"`
+	// Fields matches the comma-separated keywords against the site metadata
+	// instead of asking the model: comma-separated title, description,
+	// generator, robots and security
+	Fields string `json:"fields,omitempty"`
 }
 
 type CustomComplianceResult struct {
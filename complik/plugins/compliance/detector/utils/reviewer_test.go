@@ -0,0 +1,91 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/plugins/compliance/detector/prompts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContentReviewer", func() {
+	const body = `{"model":"gpt-5-2025","choices":[{"message":{"content":` +
+		`"{\"description\":\"A casino\",\"keywords\":[\"casino\"],\"compliance\":{\"is_illegal\":\"Yes\",\"explanation\":\"gambling\"}}"}}],` +
+		`"usage":{"prompt_tokens":1200,"completion_tokens":80,"total_tokens":1280}}`
+
+	var reviewer *ContentReviewer
+
+	BeforeEach(func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		DeferCleanup(server.Close)
+		reviewer = NewContentReviewer(logger.GetLogger(), "key", server.URL, "/chat/completions", "gpt-5")
+	})
+
+	content := &models.CollectorInfo{Host: "a.example.com", HTML: "<h1>online casino</h1>"}
+
+	It("should not attach transcripts unless enabled", func() {
+		result, err := reviewer.ReviewSiteContent(context.Background(), content, "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsIllegal).To(BeTrue())
+		Expect(result.Transcript).To(BeNil())
+	})
+
+	It("should attach the prompt, raw response and token usage", func() {
+		reviewer.SetCaptureTranscript(true)
+		result, err := reviewer.ReviewSiteContent(context.Background(), content, "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Transcript).NotTo(BeNil())
+		Expect(result.Transcript.Model).To(Equal("gpt-5-2025"))
+		Expect(result.Transcript.Prompt).To(ContainSubstring("<h1>online casino</h1>"))
+		Expect(result.Transcript.Response).To(Equal(body))
+		Expect(result.Transcript.PromptTokens).To(Equal(1200))
+		Expect(result.Transcript.CompletionTokens).To(Equal(80))
+		Expect(result.Transcript.TotalTokens).To(Equal(1280))
+	})
+
+	It("should send screenshots with the media type of their format", func() {
+		webp := []byte("RIFF\x10\x00\x00\x00WEBPVP8 ")
+		request := reviewer.prepareRequestData(&models.CollectorInfo{Screenshot: webp}, "prompt")
+		parts := request["messages"].([]map[string]any)[0]["content"].([]map[string]any)
+		Expect(parts[1]["image_url"].(map[string]string)["url"]).To(HavePrefix("data:image/webp;base64,"))
+		Expect(imageType([]byte{0xff, 0xd8, 0xff, 0xe0})).To(Equal("image/jpeg"))
+		Expect(imageType(nil)).To(Equal("image/png"))
+	})
+
+	It("should render the prompts of the configured template set", func() {
+		set, err := prompts.Parse("strict", `{{define "site"}}Review strictly: {{.HTML}}{{end}}`)
+		Expect(err).NotTo(HaveOccurred())
+		reviewer.SetPrompts(set)
+		reviewer.SetCaptureTranscript(true)
+		result, err := reviewer.ReviewSiteContent(context.Background(), content, "safety", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Transcript.Prompt).To(Equal("Review strictly: <h1>online casino</h1>"))
+
+		result, err = reviewer.ReviewSiteContent(context.Background(), content, "custom", []CustomKeywordRule{
+			{Type: "gambling", Keywords: "casino. baccarat", Description: "Gambling sites"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Transcript.Prompt).To(ContainSubstring("### gambling\n- Description: Gambling sites\n- Keywords: casino, baccarat\n"))
+	})
+})
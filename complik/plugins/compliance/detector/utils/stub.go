@@ -0,0 +1,110 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Reviewer judges collected content. ContentReviewer calls the model API and
+// StubReviewer is used in dry-run mode.
+type Reviewer interface {
+	ReviewSiteContent(
+		ctx context.Context,
+		content *models.CollectorInfo,
+		name string,
+		customRules []CustomKeywordRule,
+	) (*models.DetectorInfo, error)
+}
+
+// stubDefaultRules is used when a detector does not provide its own rules
+var stubDefaultRules = []CustomKeywordRule{
+	{Type: "gambling", Keywords: "casino,baccarat,博彩,赌场,百家乐", Description: "Gambling content"},
+	{Type: "pornography", Keywords: "porn,xxx,色情,成人视频", Description: "Pornographic content"},
+	{Type: "fraud", Keywords: "刷单,代付,usdt返利", Description: "Fraud content"},
+}
+
+// StubReviewer flags content by plain keyword matching without calling the
+// model API, so a simulation run costs nothing and is deterministic
+type StubReviewer struct {
+	log logger.Logger
+}
+
+func NewStubReviewer(log logger.Logger) *StubReviewer {
+	return &StubReviewer{log: log}
+}
+
+func (r *StubReviewer) ReviewSiteContent(
+	_ context.Context,
+	content *models.CollectorInfo,
+	name string,
+	customRules []CustomKeywordRule,
+) (*models.DetectorInfo, error) {
+	rules := customRules
+	if len(rules) == 0 {
+		rules = stubDefaultRules
+	}
+	text := content.HTML
+	if m := content.Metadata; m != nil {
+		text = strings.Join([]string{text, m.Title, m.Description, m.Generator}, "\n")
+	}
+	text = strings.ToLower(text)
+	keywords := []string{}
+	var violated []string
+	for _, rule := range rules {
+		matched := false
+		for _, keyword := range strings.Split(rule.Keywords, ",") {
+			keyword = strings.TrimSpace(keyword)
+			if keyword == "" || !strings.Contains(text, strings.ToLower(keyword)) {
+				continue
+			}
+			keywords = append(keywords, keyword)
+			matched = true
+		}
+		if matched {
+			violated = append(violated, rule.Type)
+		}
+	}
+
+	explanation := "Dry-run stub review: no rule keywords found"
+	if len(violated) > 0 {
+		explanation = "Dry-run stub review: matched " + strings.Join(violated, ", ")
+	}
+	r.log.Debug("Stub review completed", logger.Fields{
+		"host":       content.Host,
+		"is_illegal": len(violated) > 0,
+		"keywords":   keywords,
+	})
+
+	return &models.DetectorInfo{
+		DiscoveryName: content.DiscoveryName,
+		CollectorName: content.CollectorName,
+		DetectorName:  name,
+		Name:          content.Name,
+		Namespace:     content.Namespace,
+		Host:          content.Host,
+		Path:          content.Path,
+		URL:           content.URL,
+		IsIllegal:     len(violated) > 0,
+		Description:   "Simulated review",
+		Keywords:      keywords,
+		Explanation:   explanation,
+		Metadata:      content.Metadata,
+	}, nil
+}
@@ -143,6 +143,9 @@ func (p *HigressPlugin) Start(
 					})
 					return
 				}
+				if ingress.Protocol == models.ProtocolTCP {
+					return
+				}
 
 				p.log.Debug("Processing discovery event", logger.Fields{
 					"host":      ingress.Host,
@@ -0,0 +1,176 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// repositoryRoot holds the shipped deployments
+const repositoryRoot = "../../../.."
+
+// newPod returns the pod the API server creates from template
+func newPod(namespace, name string, template corev1.PodTemplateSpec) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: template.ObjectMeta, Spec: template.Spec}
+	pod.Namespace, pod.Name = namespace, name+"-abcde"
+	// The container ports of host network pods are bound on the host
+	if pod.Spec.HostNetwork {
+		for i := range pod.Spec.Containers {
+			for j := range pod.Spec.Containers[i].Ports {
+				port := &pod.Spec.Containers[i].Ports[j]
+				port.HostPort = port.ContainerPort
+			}
+		}
+	}
+	return pod
+}
+
+// manifestPods returns a pod of every Deployment and DaemonSet of a manifest
+func manifestPods(path string) []*corev1.Pod {
+	file, err := os.Open(filepath.Join(repositoryRoot, path))
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	var pods []*corev1.Pod
+	decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		var workload struct {
+			Kind     string            `json:"kind"`
+			Metadata metav1.ObjectMeta `json:"metadata"`
+			Spec     struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		err := decoder.Decode(&workload)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		Expect(err).NotTo(HaveOccurred(), path)
+		if workload.Kind == "Deployment" || workload.Kind == "DaemonSet" {
+			pods = append(pods, newPod(workload.Metadata.Namespace, workload.Metadata.Name, workload.Spec.Template))
+		}
+	}
+	Expect(pods).NotTo(BeEmpty(), path)
+	return pods
+}
+
+// procscanChartPod renders the DaemonSet of the procscan chart from its values
+func procscanChartPod() *corev1.Pod {
+	file, err := os.Open(filepath.Join(repositoryRoot, "complik/deploy/deploy/charts/procscan/values.yaml"))
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+	var values struct {
+		Image struct {
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+		} `json:"image"`
+		Namespace string `json:"namespace"`
+		DaemonSet struct {
+			HostNetwork     bool                   `json:"hostNetwork"`
+			HostPID         bool                   `json:"hostPID"`
+			SecurityContext corev1.SecurityContext `json:"securityContext"`
+		} `json:"daemonset"`
+		Config struct {
+			Metrics struct {
+				Enabled bool  `json:"enabled"`
+				Port    int32 `json:"port"`
+			} `json:"metrics"`
+		} `json:"config"`
+		Volumes map[string]struct {
+			HostPath string `json:"hostPath"`
+		} `json:"volumes"`
+	}
+	Expect(yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&values)).To(Succeed())
+
+	container := corev1.Container{
+		Name:            "scanner",
+		Image:           values.Image.Repository + ":" + values.Image.Tag,
+		SecurityContext: &values.DaemonSet.SecurityContext,
+	}
+	if values.Config.Metrics.Enabled {
+		container.Ports = []corev1.ContainerPort{{Name: "metrics", ContainerPort: values.Config.Metrics.Port}}
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+			"app.kubernetes.io/name":     "procscan",
+			"app.kubernetes.io/instance": "procscan",
+		}},
+		Spec: corev1.PodSpec{
+			HostNetwork: values.DaemonSet.HostNetwork,
+			HostPID:     values.DaemonSet.HostPID,
+			Containers:  []corev1.Container{container},
+		},
+	}
+	for _, name := range []string{"proc", "containerdSock"} {
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: values.Volumes[name].HostPath},
+			},
+		})
+	}
+	return newPod(values.Namespace, "procscan-proc-scan", template)
+}
+
+var _ = Describe("Default profiles", func() {
+	It("should allow the shipped deployments", func() {
+		pods := []*corev1.Pod{procscanChartPod()}
+		for _, path := range []string{
+			"procscan/deploy/manifests/daemonset.yaml",
+			"block-controller/deploy/procscan/daemonset.yaml",
+			"block-controller/deploy/block/deployment.yaml",
+			"block-controller/deploy/block/deployment-simple.yaml",
+			"complik/deploy/manifests/deploy.yaml",
+		} {
+			pods = append(pods, manifestPods(path)...)
+		}
+		// The complik chart deploys the Deployment of the manifest into complik
+		chart := manifestPods("complik/deploy/manifests/deploy.yaml")[0]
+		chart.Namespace = "complik"
+		pods = append(pods, chart)
+		// The KubeBlocks MySQL cluster of the complik-database chart
+		database := pod("complik", "complik-db-mysql-0",
+			"apecloud-registry.cn-zhangjiakou.cr.aliyuncs.com/apecloud/apecloud-mysql-server:8.0.30")
+		database.Labels = map[string]string{"app.kubernetes.io/managed-by": "kubeblocks"}
+		pods = append(pods, database)
+
+		defaults := (&WatchdogPlugin{}).getDefaultConfig().Namespaces
+		for _, pod := range pods {
+			profiles, ok := defaults[pod.Namespace]
+			Expect(ok).To(BeTrue(), "namespace %s of %s", pod.Namespace, pod.Name)
+			violations, matched := AuditProfiles(pod, profiles)
+			Expect(matched).To(BeTrue(), pod.Name)
+			Expect(violations).To(BeEmpty(), pod.Name)
+		}
+	})
+
+	It("should still report an intruder in the scanning namespaces", func() {
+		defaults := (&WatchdogPlugin{}).getDefaultConfig().Namespaces
+		for _, namespace := range []string{"complik", "block-system"} {
+			violations, matched := AuditProfiles(pod(namespace, "miner", "xmrig/xmrig"), defaults[namespace])
+			Expect(matched).To(BeTrue())
+			Expect(violations).NotTo(BeEmpty())
+		}
+	})
+})
@@ -0,0 +1,232 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watchdog implements a plugin that audits the namespaces of the
+// scanning tooling itself, so that an unexpected image or privilege next to
+// the privileged scanners is reported before it can abuse them.
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.ComplianceWatchdog
+	pluginType = constants.ComplianceWatchdogPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &WatchdogPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type WatchdogPlugin struct {
+	log            logger.Logger
+	watchdogConfig WatchdogConfig
+	watchdog       *Watchdog
+}
+
+func (p *WatchdogPlugin) Name() string {
+	return pluginName
+}
+
+func (p *WatchdogPlugin) Type() string {
+	return pluginType
+}
+
+type WatchdogConfig struct {
+	Region         string `json:"region"`
+	IntervalSecond int    `json:"intervalSecond"`
+	// Namespaces maps the scanning namespaces to the profiles of what may run
+	// in them, they replace the default namespaces when set
+	Namespaces map[string][]Profile `json:"namespaces"`
+}
+
+// The profiles of the shipped deployments, see the Helm charts and manifests
+// under deploy
+var (
+	// complikProfile is the complik Deployment of the complik chart and manifest
+	complikProfile = Profile{
+		Images: []string{"bearslyricattack/sealos-complik-service"},
+	}
+	// databaseProfile is the KubeBlocks MySQL cluster of the complik-database chart
+	databaseProfile = Profile{
+		Selector: map[string]string{"app.kubernetes.io/managed-by": "kubeblocks"},
+		Images:   []string{"apecloud/*", "*/apecloud/*"},
+	}
+	// procscanChartProfile is the DaemonSet of the procscan chart
+	procscanChartProfile = Profile{
+		Images:      []string{"layzer/sealos-procscan"},
+		Privileged:  true,
+		RunAsRoot:   true,
+		HostNetwork: true,
+		HostPID:     true,
+		HostPorts:   []int32{8080},
+		HostPaths:   []string{"/proc", "/var/run/containerd/containerd.sock"},
+	}
+	// procscanManifestProfile is the DaemonSet of the procscan and
+	// block-controller manifests
+	procscanManifestProfile = Profile{
+		Images:      []string{"bearslyricattack/sealos-procscan", "layzer/block-procscan"},
+		Privileged:  true,
+		RunAsRoot:   true,
+		HostNetwork: true,
+		HostPID:     true,
+		HostPorts:   []int32{8080, 9090},
+		HostPaths: []string{
+			"/proc", "/var/run/containerd/containerd.sock", "/var/run/procscan", "/etc", "/usr/local",
+		},
+	}
+	// blockControllerProfile is the Deployment of the block-controller manifests
+	blockControllerProfile = Profile{
+		Images: []string{"layzer/block-controller"},
+	}
+)
+
+func (p *WatchdogPlugin) getDefaultConfig() WatchdogConfig {
+	// The sealos namespace is shared with the Sealos components, only the
+	// pods of complik and procscan are audited there
+	sealosComplik := complikProfile
+	sealosComplik.Selector = map[string]string{"app": "service-complik"}
+	sealosProcscan := procscanChartProfile
+	sealosProcscan.Selector = map[string]string{"app.kubernetes.io/name": "procscan"}
+	return WatchdogConfig{
+		Region:         "UNKNOWN",
+		IntervalSecond: 300,
+		Namespaces: map[string][]Profile{
+			"complik":      {complikProfile, databaseProfile},
+			"sealos":       {sealosComplik, sealosProcscan},
+			"block-system": {blockControllerProfile, procscanManifestProfile},
+		},
+	}
+}
+
+func (p *WatchdogPlugin) loadConfig(setting string) error {
+	p.watchdogConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default watchdog configuration")
+		return nil
+	}
+	var configFromJSON WatchdogConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse configuration", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.Region != "" {
+		p.watchdogConfig.Region = configFromJSON.Region
+	}
+	if configFromJSON.IntervalSecond > 0 {
+		p.watchdogConfig.IntervalSecond = configFromJSON.IntervalSecond
+	}
+	if len(configFromJSON.Namespaces) > 0 {
+		p.watchdogConfig.Namespaces = configFromJSON.Namespaces
+	}
+
+	p.log.Info("Watchdog configuration loaded", logger.Fields{
+		"interval_seconds": p.watchdogConfig.IntervalSecond,
+		"namespaces":       len(p.watchdogConfig.Namespaces),
+	})
+	return nil
+}
+
+func (p *WatchdogPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	if k8s.ClientSet == nil {
+		return errors.New("kubernetes client is not initialized")
+	}
+	p.watchdog = NewWatchdog(k8s.ClientSet, p.watchdogConfig.Namespaces, p.watchdogConfig.Region)
+	p.log.Info("Watchdog started", logger.Fields{
+		"namespaces":       p.watchdog.Namespaces(),
+		"interval_seconds": p.watchdogConfig.IntervalSecond,
+	})
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		ticker := time.NewTicker(time.Duration(p.watchdogConfig.IntervalSecond) * time.Second)
+		defer ticker.Stop()
+		p.check(ctx, eventBus, time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				p.check(ctx, eventBus, now)
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *WatchdogPlugin) check(ctx context.Context, eventBus *eventbus.EventBus, now time.Time) {
+	for _, namespace := range p.watchdog.Namespaces() {
+		checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		incident, ok, err := p.watchdog.Check(checkCtx, namespace, now)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				p.log.Error("Failed to audit scanning namespace", logger.Fields{
+					"namespace": namespace,
+					"error":     err.Error(),
+					"class":     complikerrors.Record(p.Name(), err),
+				})
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		p.log.Warn("Unexpected workload in scanning namespace", logger.Fields{
+			"incident":   incident.ID,
+			"namespace":  incident.Namespace,
+			"violations": len(incident.Findings),
+		})
+		eventBus.Publish(constants.CorrelationTopic, eventbus.Event{
+			Payload: &incident,
+		})
+	}
+}
+
+func (p *WatchdogPlugin) Stop(ctx context.Context) error {
+	p.log.Info("Stopping watchdog plugin")
+	return nil
+}
@@ -0,0 +1,372 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Rules of a violation
+const (
+	RuleImage               = "image"
+	RulePrivileged          = "privileged"
+	RulePrivilegeEscalation = "privilegeEscalation"
+	RuleRunAsRoot           = "runAsRoot"
+	RuleCapability          = "capability"
+	RuleHostNetwork         = "hostNetwork"
+	RuleHostPID             = "hostPID"
+	RuleHostIPC             = "hostIPC"
+	RuleHostPort            = "hostPort"
+	RuleHostPath            = "hostPath"
+)
+
+// Profile is what may run in a scanning namespace
+type Profile struct {
+	// Selector limits the profile to the pods with these labels, all pods
+	// of the namespace match an empty selector
+	Selector map[string]string `json:"selector"`
+	// Images are path.Match patterns of the allowed images, a pattern
+	// without a tag or digest allows every tag of the repository. Images
+	// are not checked when empty.
+	Images                   []string `json:"images"`
+	Privileged               bool     `json:"privileged"`
+	AllowPrivilegeEscalation bool     `json:"allowPrivilegeEscalation"`
+	// RunAsRoot allows containers to run as user 0
+	RunAsRoot bool `json:"runAsRoot"`
+	// Capabilities are the capabilities containers may add
+	Capabilities []string `json:"capabilities"`
+	HostNetwork  bool     `json:"hostNetwork"`
+	HostPID      bool     `json:"hostPID"`
+	HostIPC      bool     `json:"hostIPC"`
+	// HostPorts are the host ports containers may bind. On the host network
+	// every container port is a host port.
+	HostPorts []int32 `json:"hostPorts"`
+	// HostPaths are the host paths that may be mounted, with everything
+	// below them
+	HostPaths []string `json:"hostPaths"`
+}
+
+// Matches reports whether pod has the labels of the selector
+func (p Profile) Matches(pod *corev1.Pod) bool {
+	for key, value := range p.Selector {
+		if pod.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Violation is a pod of a scanning namespace running something its profile
+// does not allow
+type Violation struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	// Container is empty for the violations of the pod spec
+	Container string `json:"container,omitempty"`
+	Rule      string `json:"rule"`
+	Detail    string `json:"detail"`
+}
+
+// Key identifies the violation between two audits
+func (v Violation) Key() string {
+	return strings.Join([]string{v.Pod, v.Container, v.Rule, v.Detail}, "/")
+}
+
+func (v Violation) Summary() string {
+	target := "pod " + v.Pod
+	if v.Container != "" {
+		target = fmt.Sprintf("container %s of pod %s", v.Container, v.Pod)
+	}
+	switch v.Rule {
+	case RuleImage:
+		return fmt.Sprintf("%s runs unexpected image `%s`", target, v.Detail)
+	case RuleCapability:
+		return fmt.Sprintf("%s adds capability `%s`", target, v.Detail)
+	case RuleHostPath:
+		return fmt.Sprintf("%s mounts host path `%s`", target, v.Detail)
+	case RuleHostPort:
+		return fmt.Sprintf("%s binds host port `%s`", target, v.Detail)
+	case RuleRunAsRoot:
+		return fmt.Sprintf("%s runs as root", target)
+	default:
+		return fmt.Sprintf("%s uses %s", target, v.Rule)
+	}
+}
+
+// Audit returns the violations of pod against profile
+func Audit(pod *corev1.Pod, profile Profile) []Violation {
+	var violations []Violation
+	add := func(container, rule, detail string) {
+		violations = append(violations, Violation{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			Container: container,
+			Rule:      rule,
+			Detail:    detail,
+		})
+	}
+
+	spec := &pod.Spec
+	if spec.HostNetwork && !profile.HostNetwork {
+		add("", RuleHostNetwork, "")
+	}
+	if spec.HostPID && !profile.HostPID {
+		add("", RuleHostPID, "")
+	}
+	if spec.HostIPC && !profile.HostIPC {
+		add("", RuleHostIPC, "")
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil && !hostPathAllowed(volume.HostPath.Path, profile.HostPaths) {
+			add("", RuleHostPath, volume.HostPath.Path)
+		}
+	}
+
+	// The user of a container defaults to the one of the pod
+	var podUser *int64
+	var podNonRoot *bool
+	if spec.SecurityContext != nil {
+		podUser, podNonRoot = spec.SecurityContext.RunAsUser, spec.SecurityContext.RunAsNonRoot
+	}
+
+	audit := func(name, image string, ports []corev1.ContainerPort, security *corev1.SecurityContext) {
+		if len(profile.Images) > 0 && !imageAllowed(image, profile.Images) {
+			add(name, RuleImage, image)
+		}
+		for _, port := range ports {
+			hostPort := port.HostPort
+			if hostPort == 0 && spec.HostNetwork {
+				hostPort = port.ContainerPort
+			}
+			if hostPort != 0 && !slices.Contains(profile.HostPorts, hostPort) {
+				add(name, RuleHostPort, strconv.Itoa(int(hostPort)))
+			}
+		}
+		user, nonRoot := podUser, podNonRoot
+		if security != nil && security.RunAsUser != nil {
+			user = security.RunAsUser
+		}
+		if security != nil && security.RunAsNonRoot != nil {
+			nonRoot = security.RunAsNonRoot
+		}
+		// The kubelet refuses to start root containers that must run as non-root
+		if user != nil && *user == 0 && (nonRoot == nil || !*nonRoot) && !profile.RunAsRoot {
+			add(name, RuleRunAsRoot, "")
+		}
+		if security == nil {
+			return
+		}
+		if security.Privileged != nil && *security.Privileged && !profile.Privileged {
+			add(name, RulePrivileged, "")
+		}
+		if security.AllowPrivilegeEscalation != nil && *security.AllowPrivilegeEscalation &&
+			!profile.AllowPrivilegeEscalation && !profile.Privileged {
+			add(name, RulePrivilegeEscalation, "")
+		}
+		if security.Capabilities != nil {
+			for _, capability := range security.Capabilities.Add {
+				if !capabilityAllowed(string(capability), profile.Capabilities) {
+					add(name, RuleCapability, string(capability))
+				}
+			}
+		}
+	}
+	for _, container := range spec.InitContainers {
+		audit(container.Name, container.Image, container.Ports, container.SecurityContext)
+	}
+	for _, container := range spec.Containers {
+		audit(container.Name, container.Image, container.Ports, container.SecurityContext)
+	}
+	// Ephemeral containers are started with kubectl debug into running pods
+	for _, container := range spec.EphemeralContainers {
+		audit(container.Name, container.Image, container.Ports, container.SecurityContext)
+	}
+	return violations
+}
+
+// AuditProfiles audits pod against the profiles that match it. The pod passes
+// when one of them allows it, otherwise the violations of the closest profile,
+// the one with the fewest, are returned. matched is false when no profile
+// matches, so a namespace shared with other workloads can select the scanner
+// pods.
+func AuditProfiles(pod *corev1.Pod, profiles []Profile) (violations []Violation, matched bool) {
+	for _, profile := range profiles {
+		if !profile.Matches(pod) {
+			continue
+		}
+		found := Audit(pod, profile)
+		if len(found) == 0 {
+			return nil, true
+		}
+		if !matched || len(found) < len(violations) {
+			violations = found
+		}
+		matched = true
+	}
+	return violations, matched
+}
+
+// imageAllowed matches image and its repository against the patterns, the
+// docker.io registry of both is optional
+func imageAllowed(image string, patterns []string) bool {
+	image = normalizeImage(image)
+	repository := imageRepository(image)
+	for _, pattern := range patterns {
+		pattern = normalizeImage(pattern)
+		if ok, _ := path.Match(pattern, image); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeImage(image string) string {
+	image = strings.TrimPrefix(image, "docker.io/")
+	return strings.TrimPrefix(image, "library/")
+}
+
+// imageRepository strips the tag and digest of image, keeping the port of
+// its registry
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func hostPathAllowed(hostPath string, allowed []string) bool {
+	hostPath = path.Clean(hostPath)
+	for _, prefix := range allowed {
+		prefix = path.Clean(prefix)
+		if hostPath == prefix || strings.HasPrefix(hostPath, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func capabilityAllowed(capability string, allowed []string) bool {
+	capability = strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		return strings.TrimPrefix(strings.ToUpper(a), "CAP_") == capability
+	})
+}
+
+// Watchdog audits the pods of the scanning namespaces and reports every
+// violation once, until it is no longer found
+type Watchdog struct {
+	client   kubernetes.Interface
+	profiles map[string][]Profile
+	region   string
+	// reported holds the keys of the open violations by namespace
+	reported map[string]map[string]bool
+}
+
+func NewWatchdog(client kubernetes.Interface, profiles map[string][]Profile, region string) *Watchdog {
+	return &Watchdog{
+		client:   client,
+		profiles: profiles,
+		region:   region,
+		reported: make(map[string]map[string]bool),
+	}
+}
+
+// Namespaces returns the audited namespaces, sorted
+func (w *Watchdog) Namespaces() []string {
+	namespaces := make([]string, 0, len(w.profiles))
+	for namespace := range w.profiles {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Check audits namespace and returns an incident with the violations that
+// were not reported yet, ok is false without new violations. The violations
+// of a namespace that cannot be listed are kept open.
+func (w *Watchdog) Check(ctx context.Context, namespace string, now time.Time) (models.Incident, bool, error) {
+	profiles, ok := w.profiles[namespace]
+	if !ok {
+		return models.Incident{}, false, fmt.Errorf("no profile for namespace %s", namespace)
+	}
+	pods, err := w.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return models.Incident{}, false, fmt.Errorf("failed to list pods of %s: %w", namespace, err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	open := make(map[string]bool)
+	var findings []models.Finding
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		violations, _ := AuditProfiles(pod, profiles)
+		for _, violation := range violations {
+			key := violation.Key()
+			if open[key] {
+				continue
+			}
+			open[key] = true
+			if w.reported[namespace][key] {
+				continue
+			}
+			findings = append(findings, models.Finding{
+				Source:     models.SourceWatchdog,
+				Key:        key,
+				Region:     w.region,
+				Namespace:  namespace,
+				Summary:    violation.Summary(),
+				Severity:   models.SeverityCritical,
+				ObservedAt: now,
+			})
+		}
+	}
+	w.reported[namespace] = open
+	if len(findings) == 0 {
+		return models.Incident{}, false, nil
+	}
+	return models.Incident{
+		ID:        fmt.Sprintf("watchdog-%s-%d", namespace, now.Unix()),
+		Region:    w.region,
+		Namespace: namespace,
+		Severity:  models.SeverityCritical,
+		Sources:   []string{models.SourceWatchdog},
+		Findings:  findings,
+		FirstSeen: now,
+		LastSeen:  now,
+	}, true, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatchdog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Watchdog Suite")
+}
+
+func pod(namespace, name, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Image: image}},
+		},
+	}
+}
+
+var procscanProfile = Profile{
+	Images:      []string{"bearslyricattack/sealos-procscan"},
+	Privileged:  true,
+	RunAsRoot:   true,
+	HostNetwork: true,
+	HostPID:     true,
+	HostPorts:   []int32{8080},
+	HostPaths:   []string{"/proc", "/var/run/containerd/containerd.sock"},
+}
+
+var _ = Describe("Audit", func() {
+	It("should allow the expected images and privileges", func() {
+		scanner := pod("procscan", "proc-scan-abcde", "docker.io/bearslyricattack/sealos-procscan:v0.0.2")
+		scanner.Spec.HostNetwork = true
+		scanner.Spec.HostPID = true
+		scanner.Spec.Volumes = []corev1.Volume{{
+			Name:         "proc",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/proc/"}},
+		}}
+		privileged := true
+		scanner.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+		Expect(Audit(scanner, procscanProfile)).To(BeEmpty())
+	})
+
+	It("should report unexpected images, privileges and host access", func() {
+		intruder := pod("complik", "debug", "alpine:3")
+		intruder.Spec.HostIPC = true
+		intruder.Spec.Volumes = []corev1.Volume{{
+			Name:         "root",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}},
+		}}
+		escalation := true
+		intruder.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &escalation,
+			Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}},
+		}
+		intruder.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "shell", Image: "busybox"},
+		}}
+
+		violations := Audit(intruder, Profile{Images: []string{"bearslyricattack/sealos-complik-service:*"}})
+		rules := make(map[string]string)
+		for _, violation := range violations {
+			rules[violation.Container+"/"+violation.Rule] = violation.Detail
+		}
+		Expect(rules).To(Equal(map[string]string{
+			"/" + RuleHostIPC:                 "",
+			"/" + RuleHostPath:                "/",
+			"main/" + RuleImage:               "alpine:3",
+			"main/" + RulePrivilegeEscalation: "",
+			"main/" + RuleCapability:          "SYS_ADMIN",
+			"shell/" + RuleImage:              "busybox",
+		}))
+	})
+
+	It("should report root users and host ports of the pod and its containers", func() {
+		root, user := int64(0), int64(1000)
+		nonRoot := true
+		scanner := pod("complik", "complik-abcde", "bearslyricattack/sealos-complik-service:latest")
+		scanner.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &root}
+		scanner.Spec.Containers = append(scanner.Spec.Containers,
+			corev1.Container{Name: "user", Image: "bearslyricattack/sealos-complik-service",
+				SecurityContext: &corev1.SecurityContext{RunAsUser: &user}},
+			corev1.Container{Name: "nonroot", Image: "bearslyricattack/sealos-complik-service",
+				SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &nonRoot}},
+		)
+		scanner.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 8428, HostPort: 30080}}
+
+		violations := Audit(scanner, Profile{Images: []string{"bearslyricattack/sealos-complik-service"}})
+		rules := make(map[string]string)
+		for _, violation := range violations {
+			rules[violation.Container+"/"+violation.Rule] = violation.Detail
+		}
+		Expect(rules).To(Equal(map[string]string{
+			"main/" + RuleRunAsRoot: "",
+			"main/" + RuleHostPort:  "30080",
+		}))
+	})
+
+	It("should treat the container ports on the host network as host ports", func() {
+		scanner := pod("procscan", "proc-scan-abcde", "bearslyricattack/sealos-procscan")
+		scanner.Spec.HostNetwork = true
+		scanner.Spec.HostPID = true
+		scanner.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 8080}, {ContainerPort: 9090}}
+		violations := Audit(scanner, procscanProfile)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Summary()).To(Equal("container main of pod proc-scan-abcde binds host port `9090`"))
+	})
+
+	It("should audit pods against the closest matching profile", func() {
+		privileged := true
+		scanner := pod("block-system", "procscan", "bearslyricattack/sealos-procscan")
+		scanner.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+		controller := pod("block-system", "controller", "layzer/block-controller:v0.1.5")
+		profiles := []Profile{{Images: []string{"layzer/block-controller"}}, procscanProfile}
+
+		violations, matched := AuditProfiles(scanner, profiles)
+		Expect(matched).To(BeTrue())
+		Expect(violations).To(BeEmpty())
+		_, matched = AuditProfiles(controller, profiles)
+		Expect(matched).To(BeTrue())
+
+		// The controller image must not run privileged
+		controller.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+		violations, _ = AuditProfiles(controller, profiles)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Rule).To(Equal(RulePrivileged))
+
+		// Pods of shared namespaces are only audited when a selector matches
+		selected := []Profile{{Selector: map[string]string{"app": "service-complik"}, Images: []string{"bearslyricattack/sealos-complik-service"}}}
+		_, matched = AuditProfiles(pod("sealos", "desktop", "labring/sealos-desktop"), selected)
+		Expect(matched).To(BeFalse())
+		forged := pod("sealos", "forged", "xmrig/xmrig")
+		forged.Labels = map[string]string{"app": "service-complik"}
+		violations, matched = AuditProfiles(forged, selected)
+		Expect(matched).To(BeTrue())
+		Expect(violations).To(HaveLen(1))
+	})
+
+	It("should match images by pattern and repository", func() {
+		Expect(imageAllowed("ghcr.io/labring/complik:v1", []string{"ghcr.io/labring/*"})).To(BeTrue())
+		Expect(imageAllowed("registry:5000/complik@sha256:abc", []string{"registry:5000/complik"})).To(BeTrue())
+		Expect(imageAllowed("ghcr.io/labring/complik:v1", []string{"ghcr.io/labring/complik:v2"})).To(BeFalse())
+		Expect(imageAllowed("ghcr.io/evil/labring", []string{"ghcr.io/labring/*"})).To(BeFalse())
+	})
+
+	It("should not allow paths next to an allowed host path", func() {
+		Expect(hostPathAllowed("/proc/1/root", []string{"/proc"})).To(BeTrue())
+		Expect(hostPathAllowed("/procfs", []string{"/proc"})).To(BeFalse())
+		Expect(hostPathAllowed("/proc/../etc", []string{"/proc"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("Watchdog", func() {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should report a violation once until it disappears", func() {
+		client := fake.NewClientset(
+			pod("procscan", "proc-scan-abcde", "bearslyricattack/sealos-procscan:v1"),
+			pod("procscan", "miner", "xmrig/xmrig:latest"),
+		)
+		watchdog := NewWatchdog(client, map[string][]Profile{"procscan": {procscanProfile}}, "hzh")
+
+		incident, ok, err := watchdog.Check(ctx, "procscan", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(incident.Namespace).To(Equal("procscan"))
+		Expect(incident.Region).To(Equal("hzh"))
+		Expect(incident.Severity).To(Equal(models.SeverityCritical))
+		Expect(incident.Sources).To(Equal([]string{models.SourceWatchdog}))
+		Expect(incident.Findings).To(HaveLen(1))
+		Expect(incident.Findings[0].Summary).To(ContainSubstring("xmrig/xmrig:latest"))
+
+		_, ok, err = watchdog.Check(ctx, "procscan", now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(client.CoreV1().Pods("procscan").Delete(ctx, "miner", metav1.DeleteOptions{})).To(Succeed())
+		_, ok, _ = watchdog.Check(ctx, "procscan", now.Add(2*time.Minute))
+		Expect(ok).To(BeFalse())
+		_, err = client.CoreV1().Pods("procscan").Create(ctx, pod("procscan", "miner", "xmrig/xmrig:latest"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, ok, _ = watchdog.Check(ctx, "procscan", now.Add(3*time.Minute))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should skip finished pods", func() {
+		finished := pod("complik", "job", "alpine")
+		finished.Status.Phase = corev1.PodSucceeded
+		watchdog := NewWatchdog(fake.NewClientset(finished),
+			map[string][]Profile{"complik": {{Images: []string{"bearslyricattack/sealos-complik-service"}}}}, "")
+		_, ok, err := watchdog.Check(ctx, "complik", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -29,6 +29,7 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/priority"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
 	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	discoveryv1 "k8s.io/api/discovery/v1"
@@ -52,6 +53,7 @@ func init() {
 type CompletePlugin struct {
 	log            logger.Logger
 	completeConfig CompleteConfig
+	strategy       priority.Strategy
 }
 
 func (p *CompletePlugin) Name() string {
@@ -66,6 +68,8 @@ type CompleteConfig struct {
 	IntervalMinute  int   `json:"intervalMinute"`
 	AutoStart       *bool `json:"autoStart"`
 	StartTimeSecond int   `json:"startTimeSecond"`
+
+	Prioritization *priority.Config `json:"prioritization"`
 }
 
 func (p *CompletePlugin) getDefaultCompleteConfig() CompleteConfig {
@@ -119,6 +123,8 @@ func (p *CompletePlugin) loadConfig(setting string) error {
 		)
 	}
 
+	p.completeConfig.Prioritization = configFromJSON.Prioritization
+
 	p.log.Info("Complete configuration loaded successfully", logger.Fields{
 		"intervalMinute":  p.completeConfig.IntervalMinute,
 		"autoStart":       p.completeConfig.AutoStart != nil && *p.completeConfig.AutoStart,
@@ -145,6 +151,19 @@ func (p *CompletePlugin) Start(
 		return err
 	}
 
+	interval := time.Duration(p.completeConfig.IntervalMinute) * time.Minute
+	p.strategy, err = priority.New(p.completeConfig.Prioritization, interval)
+	if err != nil {
+		p.log.Error("Failed to create prioritization strategy", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	priority.Watch(ctx, eventBus, p.strategy)
+	p.log.Info("Scan prioritization configured", logger.Fields{
+		"strategy": p.strategy.Name(),
+	})
+
 	if p.completeConfig.AutoStart != nil && *p.completeConfig.AutoStart {
 		p.log.Info("Auto-start enabled, executing initial task", logger.Fields{
 			"startDelay": p.completeConfig.StartTimeSecond,
@@ -158,12 +177,13 @@ func (p *CompletePlugin) Start(
 	}
 
 	go func() {
-		interval := time.Duration(p.completeConfig.IntervalMinute) * time.Minute
+		tick := priority.TickInterval(p.completeConfig.Prioritization, interval)
 		p.log.Info("Starting scheduled task ticker", logger.Fields{
 			"interval": interval.String(),
+			"tick":     tick.String(),
 		})
 
-		ticker := time.NewTicker(interval)
+		ticker := time.NewTicker(tick)
 		defer ticker.Stop()
 		for {
 			select {
@@ -193,9 +213,17 @@ func (p *CompletePlugin) executeTask(ctx context.Context, eventBus *eventbus.Eve
 		})
 		return
 	}
+	discoveredCount := len(ingressList)
+	ingressList = p.strategy.Select(ingressList, time.Now())
+	run := &models.ScanRunEvent{Source: p.Name(), Total: len(ingressList), StartedAt: time.Now()}
+	run.RunID = models.NewScanRunID(run.Source, run.StartedAt)
+	eventBus.Publish(constants.ScanRunTopic, eventbus.Event{Payload: run})
 
 	p.log.Info("Publishing Complete discovery events", logger.Fields{
-		"ingressCount": len(ingressList),
+		"ingressCount":    len(ingressList),
+		"discoveredCount": discoveredCount,
+		"strategy":        p.strategy.Name(),
+		"runID":           run.RunID,
 	})
 
 	publishedCount := 0
@@ -208,6 +236,7 @@ func (p *CompletePlugin) executeTask(ctx context.Context, eventBus *eventbus.Eve
 			})
 			return
 		default:
+			ingress.ScanRunID = run.RunID
 			eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
 				Payload: ingress,
 			})
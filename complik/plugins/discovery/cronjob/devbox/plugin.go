@@ -26,6 +26,7 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/priority"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
 	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -60,12 +61,15 @@ func init() {
 type DevboxPlugin struct {
 	log          logger.Logger
 	devboxConfig DevboxConfig
+	strategy     priority.Strategy
 }
 
 type DevboxConfig struct {
 	IntervalMinute  int  `json:"intervalMinute"`
 	AutoStart       bool `json:"autoStart"`
 	StartTimeSecond int  `json:"startTimeSecond"`
+
+	Prioritization *priority.Config `json:"prioritization"`
 }
 
 func (p *DevboxPlugin) getDefaultDevboxConfig() DevboxConfig {
@@ -115,6 +119,8 @@ func (p *DevboxPlugin) loadConfig(setting string) error {
 		)
 	}
 
+	p.devboxConfig.Prioritization = configFromJSON.Prioritization
+
 	p.log.Info("DevBox configuration loaded successfully", logger.Fields{
 		"intervalMinute":  p.devboxConfig.IntervalMinute,
 		"autoStart":       p.devboxConfig.AutoStart,
@@ -149,6 +155,19 @@ func (p *DevboxPlugin) Start(
 		return err
 	}
 
+	interval := time.Duration(p.devboxConfig.IntervalMinute) * time.Minute
+	p.strategy, err = priority.New(p.devboxConfig.Prioritization, interval)
+	if err != nil {
+		p.log.Error("Failed to create prioritization strategy", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	priority.Watch(ctx, eventBus, p.strategy)
+	p.log.Info("Scan prioritization configured", logger.Fields{
+		"strategy": p.strategy.Name(),
+	})
+
 	if p.devboxConfig.AutoStart {
 		p.log.Info("Auto-start enabled, executing initial task", logger.Fields{
 			"startDelay": p.devboxConfig.StartTimeSecond,
@@ -160,12 +179,13 @@ func (p *DevboxPlugin) Start(
 	}
 
 	go func() {
-		interval := time.Duration(p.devboxConfig.IntervalMinute) * time.Minute
+		tick := priority.TickInterval(p.devboxConfig.Prioritization, interval)
 		p.log.Info("Starting scheduled task ticker", logger.Fields{
 			"interval": interval.String(),
+			"tick":     tick.String(),
 		})
 
-		ticker := time.NewTicker(interval)
+		ticker := time.NewTicker(tick)
 		defer ticker.Stop()
 		for {
 			select {
@@ -203,6 +223,17 @@ func (p *DevboxPlugin) executeTask(ctx context.Context, eventBus *eventbus.Event
 		p.log.Error("Failed to get ingress list", logger.Fields{"error": err.Error()})
 		return
 	}
+	discoveredCount := len(ingressList)
+	ingressList = p.strategy.Select(ingressList, time.Now())
+	run := &models.ScanRunEvent{Source: p.Name(), Total: len(ingressList), StartedAt: time.Now()}
+	run.RunID = models.NewScanRunID(run.Source, run.StartedAt)
+	eventBus.Publish(constants.ScanRunTopic, eventbus.Event{Payload: run})
+	p.log.Debug("Selected DevBox ingresses to scan", logger.Fields{
+		"selectedCount":   len(ingressList),
+		"discoveredCount": discoveredCount,
+		"strategy":        p.strategy.Name(),
+		"runID":           run.RunID,
+	})
 
 	publishedCount := 0
 	for i, ingress := range ingressList {
@@ -217,6 +248,7 @@ func (p *DevboxPlugin) executeTask(ctx context.Context, eventBus *eventbus.Event
 			default:
 			}
 		}
+		ingress.ScanRunID = run.RunID
 		eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
 			Payload: ingress,
 		})
@@ -0,0 +1,162 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coalesce limits the discovery events the informer plugins publish.
+// Rolling updates and EndpointSlice churn fire many events for the same
+// service within minutes, each of them turning into another scan. A Coalescer
+// publishes the first event of a service right away and folds the events
+// received during the following window into one event carrying the latest
+// state, published when the window ends.
+package coalesce
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// DefaultWindowSecond is the window used when the plugin configures none
+const DefaultWindowSecond = 300
+
+// Coalescer publishes at most one event per key and window
+type Coalescer struct {
+	window  time.Duration
+	publish func(payload any)
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	closed  bool
+}
+
+type entry struct {
+	timer   *time.Timer
+	pending any // latest payload received during the window, nil when there is none
+}
+
+// NewCoalescer creates a coalescer calling publish for the events it lets
+// through. Events are published directly when window is not positive.
+func NewCoalescer(window time.Duration, publish func(payload any)) *Coalescer {
+	return &Coalescer{
+		window:  window,
+		publish: publish,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Window converts the configured window in seconds, a negative value disables coalescing
+func Window(seconds int) time.Duration {
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Publisher publishes payloads on the discovery topic of eventBus
+func Publisher(eventBus *eventbus.EventBus) func(payload any) {
+	return func(payload any) {
+		eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
+			Payload: payload,
+		})
+	}
+}
+
+// Key identifies the service route a discovery is about. A service exposed on
+// several hosts, paths or ports is scanned once per route.
+func Key(info models.DiscoveryInfo) string {
+	service := info.ServiceName
+	if service == "" {
+		service = info.Name
+	}
+	return fmt.Sprintf("%s/%s %s %s:%d%s",
+		info.Namespace, service, info.Protocol, info.Host, info.ServicePort, strings.Join(info.Path, ","))
+}
+
+// PushDiscovery pushes info under the key of its service route
+func (c *Coalescer) PushDiscovery(info models.DiscoveryInfo) bool {
+	return c.Push(Key(info), info)
+}
+
+// Push publishes payload when no event of key was published during the last
+// window. Otherwise payload replaces the pending event of key, which is
+// published when the window ends. It reports whether payload was published.
+func (c *Coalescer) Push(key string, payload any) bool {
+	if c.window <= 0 {
+		c.publish(payload)
+		return true
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return false
+	}
+	if e, ok := c.entries[key]; ok {
+		e.pending = payload
+		c.mu.Unlock()
+		return false
+	}
+	c.entries[key] = &entry{timer: time.AfterFunc(c.window, func() { c.flush(key) })}
+	c.mu.Unlock()
+	c.publish(payload)
+	return true
+}
+
+// flush ends the window of key, publishing the pending event and starting a
+// new window for it
+func (c *Coalescer) flush(key string) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok || c.closed {
+		c.mu.Unlock()
+		return
+	}
+	if e.pending == nil {
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return
+	}
+	payload := e.pending
+	e.pending = nil
+	e.timer = time.AfterFunc(c.window, func() { c.flush(key) })
+	c.mu.Unlock()
+	c.publish(payload)
+}
+
+// Pending returns the number of keys with an event waiting for the end of their window
+func (c *Coalescer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := 0
+	for _, e := range c.entries {
+		if e.pending != nil {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Close stops the windows, pending events are dropped
+func (c *Coalescer) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for key, e := range c.entries {
+		e.timer.Stop()
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coalesce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCoalesce(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Discovery Coalescer Suite")
+}
+
+var _ = Describe("Coalescer", func() {
+	var (
+		mu        sync.Mutex
+		published []models.DiscoveryInfo
+	)
+	publish := func(payload any) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, payload.(models.DiscoveryInfo))
+	}
+	events := func() []models.DiscoveryInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]models.DiscoveryInfo(nil), published...)
+	}
+	info := func(service string, podCount int) models.DiscoveryInfo {
+		return models.DiscoveryInfo{
+			Namespace: "ns-a", ServiceName: service, Host: service + ".example.com", Path: []string{"/"}, PodCount: podCount,
+		}
+	}
+
+	BeforeEach(func() {
+		published = nil
+	})
+
+	It("should publish the first event and the latest event of the window", func() {
+		c := NewCoalescer(100*time.Millisecond, publish)
+		DeferCleanup(c.Close)
+
+		Expect(c.PushDiscovery(info("shop", 1))).To(BeTrue())
+		Expect(c.PushDiscovery(info("shop", 2))).To(BeFalse())
+		Expect(c.PushDiscovery(info("shop", 3))).To(BeFalse())
+		Expect(c.PushDiscovery(info("blog", 1))).To(BeTrue())
+		Expect(c.Pending()).To(Equal(1))
+		Expect(events()).To(HaveLen(2))
+
+		Eventually(events).Should(HaveLen(3))
+		Expect(events()[2].PodCount).To(Equal(3))
+		Consistently(events, 250*time.Millisecond).Should(HaveLen(3))
+		Expect(c.Pending()).To(BeZero())
+
+		// The window of a quiet service ends and its next event is published right away
+		Expect(c.PushDiscovery(info("blog", 2))).To(BeTrue())
+	})
+
+	It("should key events by service route", func() {
+		a, b := info("shop", 1), info("shop", 1)
+		b.Host = "www.example.com"
+		Expect(Key(a)).NotTo(Equal(Key(b)))
+		b.Host, b.PodCount, b.Name = a.Host, 5, "other-ingress"
+		Expect(Key(a)).To(Equal(Key(b)))
+		Expect(Key(models.DiscoveryInfo{Namespace: "ns-a", Name: "devbox"})).To(ContainSubstring("ns-a/devbox"))
+	})
+
+	It("should publish directly without a window and drop pending events on close", func() {
+		c := NewCoalescer(Window(-1), publish)
+		Expect(c.PushDiscovery(info("shop", 1))).To(BeTrue())
+		Expect(c.PushDiscovery(info("shop", 2))).To(BeTrue())
+		Expect(events()).To(HaveLen(2))
+
+		c = NewCoalescer(50*time.Millisecond, publish)
+		c.PushDiscovery(info("blog", 1))
+		c.PushDiscovery(info("blog", 2))
+		c.Close()
+		Consistently(events, 150*time.Millisecond).Should(HaveLen(3))
+		Expect(c.PushDiscovery(info("blog", 3))).To(BeFalse())
+	})
+})
@@ -0,0 +1,261 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package customresource implements a discovery plugin that watches arbitrary
+// custom resources with dynamic informers. The exposed host of each resource is
+// read with a configurable JSONPath and discovery events are published when a
+// resource becomes ready or stops being ready.
+package customresource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	pluginName = constants.DiscoveryInformerCustomResourceName
+	pluginType = constants.DiscoveryInformerPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &CustomResourcePlugin{
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, pluginName)),
+		}
+	}
+}
+
+type CustomResourcePlugin struct {
+	log      logger.Logger
+	stopChan chan struct{}
+	eventBus *eventbus.EventBus
+	events   *coalesce.Coalescer
+	resume   *resume.Tracker
+	config   CustomResourceConfig
+	handlers []*ReadinessHandler
+}
+
+type CustomResourceConfig struct {
+	ResyncTimeSecond   int              `json:"resyncTimeSecond"`
+	AgeThresholdSecond int              `json:"ageThresholdSecond"`
+	DedupWindowSecond  int              `json:"dedupWindowSecond"`
+	ResumeStateDir     string           `json:"resumeStateDir"`
+	FullResync         bool             `json:"fullResync"`
+	NamespacePrefix    string           `json:"namespacePrefix"`
+	Resources          []ResourceConfig `json:"resources"`
+}
+
+func (p *CustomResourcePlugin) getDefaultConfig() CustomResourceConfig {
+	return CustomResourceConfig{
+		ResyncTimeSecond:   60,
+		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
+		NamespacePrefix:    "ns-",
+	}
+}
+
+func (p *CustomResourcePlugin) loadConfig(setting string) error {
+	p.config = p.getDefaultConfig()
+	if setting == "" {
+		return errors.New("at least one resource must be configured")
+	}
+	var configFromJSON CustomResourceConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.ResyncTimeSecond > 0 {
+		p.config.ResyncTimeSecond = configFromJSON.ResyncTimeSecond
+	}
+	if configFromJSON.AgeThresholdSecond > 0 {
+		p.config.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
+	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.config.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.config.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.config.FullResync = configFromJSON.FullResync
+	if configFromJSON.NamespacePrefix != "" {
+		p.config.NamespacePrefix = configFromJSON.NamespacePrefix
+	}
+	if len(configFromJSON.Resources) == 0 {
+		return errors.New("at least one resource must be configured")
+	}
+	p.config.Resources = configFromJSON.Resources
+	return nil
+}
+
+func (p *CustomResourcePlugin) Name() string {
+	return pluginName
+}
+
+func (p *CustomResourcePlugin) Type() string {
+	return pluginType
+}
+
+func (p *CustomResourcePlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.handlers = p.handlers[:0]
+	for i, cfg := range p.config.Resources {
+		resource, err := NewResource(cfg)
+		if err != nil {
+			return fmt.Errorf("resource %d: %w", i, err)
+		}
+		if resource.host == nil {
+			return fmt.Errorf("resource %d (%s): hostPath cannot be empty", i, resource)
+		}
+		p.handlers = append(p.handlers, &ReadinessHandler{
+			Resource:     resource,
+			AgeThreshold: time.Duration(p.config.AgeThresholdSecond) * time.Second,
+			Filter:       NamespacePrefixFilter(p.config.NamespacePrefix),
+			OnChange: func(obj *unstructured.Unstructured, ready bool) {
+				p.publish(resource, obj, ready)
+			},
+		})
+	}
+	p.stopChan = make(chan struct{})
+	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.config.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.config.ResumeStateDir, p.Name(), p.config.FullResync, p.log)
+	go Watch(ctx, p.log, p.stopChan, time.Duration(p.config.ResyncTimeSecond)*time.Second, p.handlers, p.resume)
+	return nil
+}
+
+func (p *CustomResourcePlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
+	if p.stopChan != nil {
+		close(p.stopChan)
+	}
+	return nil
+}
+
+func (p *CustomResourcePlugin) publish(resource *Resource, obj *unstructured.Unstructured, ready bool) {
+	for _, info := range DiscoveryInfos(p.Name(), resource, obj, ready) {
+		p.events.PushDiscovery(info)
+	}
+}
+
+// DiscoveryInfos builds one discovery entry per exposed host of obj. Resources
+// that are no longer ready without a resolvable host still produce an entry so
+// consumers learn that the workload went away.
+func DiscoveryInfos(
+	discoveryName string,
+	resource *Resource,
+	obj *unstructured.Unstructured,
+	ready bool,
+) []models.DiscoveryInfo {
+	podCount := 0
+	if ready {
+		podCount = 1
+	}
+	entry := func(host string) models.DiscoveryInfo {
+		return models.DiscoveryInfo{
+			DiscoveryName: discoveryName,
+			Name:          obj.GetName(),
+			Namespace:     obj.GetNamespace(),
+			Host:          host,
+			Path:          []string{"/"},
+			HasActivePods: ready,
+			PodCount:      podCount,
+		}
+	}
+	hosts := resource.Hosts(obj)
+	if len(hosts) == 0 {
+		if ready {
+			return nil
+		}
+		return []models.DiscoveryInfo{entry("")}
+	}
+	infos := make([]models.DiscoveryInfo, 0, len(hosts))
+	for _, host := range hosts {
+		infos = append(infos, entry(host))
+	}
+	return infos
+}
+
+// NamespacePrefixFilter accepts objects in namespaces starting with prefix
+func NamespacePrefixFilter(prefix string) func(obj *unstructured.Unstructured) bool {
+	return func(obj *unstructured.Unstructured) bool {
+		return strings.HasPrefix(obj.GetNamespace(), prefix)
+	}
+}
+
+// Watch starts one dynamic informer per handler and blocks until ctx is done or
+// stopChan is closed. The handlers share tracker, which may be nil.
+func Watch(
+	ctx context.Context,
+	log logger.Logger,
+	stopChan chan struct{},
+	resync time.Duration,
+	handlers []*ReadinessHandler,
+	tracker *resume.Tracker,
+) {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(k8s.DynamicClient, resync)
+	synced := make([]cache.InformerSynced, 0, len(handlers))
+	for _, handler := range handlers {
+		informer := factory.ForResource(handler.Resource.GVR()).Informer()
+		if _, err := informer.AddEventHandler(tracker.Wrap(handler)); err != nil {
+			log.Error("Failed to add custom resource event handler", logger.Fields{
+				"resource": handler.Resource.String(),
+				"error":    err.Error(),
+			})
+			return
+		}
+		synced = append(synced, informer.HasSynced)
+	}
+
+	factory.Start(stopChan)
+	if !cache.WaitForCacheSync(stopChan, synced...) {
+		log.Error("Failed to wait for custom resource caches to sync")
+		return
+	}
+	go tracker.Run(stopChan)
+	log.Info("Custom resource informer watcher started successfully", logger.Fields{
+		"resources": len(handlers),
+	})
+	select {
+	case <-ctx.Done():
+		log.Info("Custom resource watcher stopping due to context cancellation")
+	case <-stopChan:
+		log.Info("Custom resource watcher stopping due to stop signal")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customresource
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// DefaultReadyPath reads the status of the standard Ready condition
+const DefaultReadyPath = `{.status.conditions[?(@.type=="Ready")].status}`
+
+// ResourceConfig describes a custom resource to watch. HostPath is a JSONPath
+// template to the exposed host(s); ReadyPath and ReadyValues decide readiness,
+// defaulting to a Ready condition with status True.
+type ResourceConfig struct {
+	Group       string   `json:"group"`
+	Version     string   `json:"version"`
+	Resource    string   `json:"resource"`
+	HostPath    string   `json:"hostPath"`
+	ReadyPath   string   `json:"readyPath"`
+	ReadyValues []string `json:"readyValues"`
+}
+
+// Resource is a validated ResourceConfig with its JSONPath templates parsed
+type Resource struct {
+	Config ResourceConfig
+	host   *jsonpath.JSONPath
+	ready  *jsonpath.JSONPath
+}
+
+func NewResource(cfg ResourceConfig) (*Resource, error) {
+	if cfg.Version == "" || cfg.Resource == "" {
+		return nil, errors.New("version and resource cannot be empty")
+	}
+	if cfg.ReadyPath == "" {
+		cfg.ReadyPath = DefaultReadyPath
+		if len(cfg.ReadyValues) == 0 {
+			cfg.ReadyValues = []string{"True"}
+		}
+	}
+	r := &Resource{Config: cfg}
+
+	var err error
+	if r.ready, err = parsePath("ready", cfg.ReadyPath); err != nil {
+		return nil, err
+	}
+	if cfg.HostPath != "" {
+		if r.host, err = parsePath("host", cfg.HostPath); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func parsePath(name, template string) (*jsonpath.JSONPath, error) {
+	path := jsonpath.New(name).AllowMissingKeys(true)
+	if err := path.Parse(template); err != nil {
+		return nil, fmt.Errorf("invalid %s path %q: %w", name, template, err)
+	}
+	return path, nil
+}
+
+func (r *Resource) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    r.Config.Group,
+		Version:  r.Config.Version,
+		Resource: r.Config.Resource,
+	}
+}
+
+func (r *Resource) String() string {
+	return r.GVR().String()
+}
+
+// IsReady reports whether any value at the ready path is one of the ready
+// values. Without ready values any non-empty value counts as ready.
+func (r *Resource) IsReady(obj *unstructured.Unstructured) bool {
+	for _, value := range findStrings(r.ready, obj) {
+		if len(r.Config.ReadyValues) == 0 || slices.Contains(r.Config.ReadyValues, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hosts returns the non-empty values at the host path. URLs such as Knative's
+// status.url are reduced to their host.
+func (r *Resource) Hosts(obj *unstructured.Unstructured) []string {
+	if r.host == nil {
+		return nil
+	}
+	hosts := findStrings(r.host, obj)
+	for i, host := range hosts {
+		if u, err := url.Parse(host); err == nil && u.Scheme != "" && u.Host != "" {
+			hosts[i] = u.Hostname()
+		}
+	}
+	return hosts
+}
+
+func findStrings(path *jsonpath.JSONPath, obj *unstructured.Unstructured) []string {
+	results, err := path.FindResults(obj.Object)
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || !value.CanInterface() {
+				continue
+			}
+			if s := fmt.Sprint(value.Interface()); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// ReadinessHandler calls OnChange when a watched object becomes ready, stops
+// being ready, changes its hosts while ready, or is deleted. Objects added
+// longer than AgeThreshold ago are ignored so the initial list does not flood
+// the event bus.
+type ReadinessHandler struct {
+	Resource     *Resource
+	AgeThreshold time.Duration
+	Filter       func(obj *unstructured.Unstructured) bool
+	OnChange     func(obj *unstructured.Unstructured, ready bool)
+}
+
+var _ cache.ResourceEventHandler = (*ReadinessHandler)(nil)
+
+func (h *ReadinessHandler) OnAdd(obj any, _ bool) {
+	u, ok := h.accept(obj)
+	if !ok {
+		return
+	}
+	if time.Since(u.GetCreationTimestamp().Time) > h.AgeThreshold {
+		return
+	}
+	if h.Resource.IsReady(u) {
+		h.OnChange(u, true)
+	}
+}
+
+func (h *ReadinessHandler) OnUpdate(oldObj, newObj any) {
+	oldU, ok := h.accept(oldObj)
+	if !ok {
+		return
+	}
+	newU, ok := h.accept(newObj)
+	if !ok {
+		return
+	}
+	wasReady := h.Resource.IsReady(oldU)
+	ready := h.Resource.IsReady(newU)
+	if wasReady != ready ||
+		(ready && !slices.Equal(h.Resource.Hosts(oldU), h.Resource.Hosts(newU))) {
+		h.OnChange(newU, ready)
+	}
+}
+
+func (h *ReadinessHandler) OnDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if u, ok := h.accept(obj); ok {
+		h.OnChange(u, false)
+	}
+}
+
+func (h *ReadinessHandler) accept(obj any) (*unstructured.Unstructured, bool) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	if h.Filter != nil && !h.Filter(u) {
+		return nil, false
+	}
+	return u, true
+}
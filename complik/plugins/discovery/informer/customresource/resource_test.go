@@ -0,0 +1,174 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customresource
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestCustomResource(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Custom Resource Discovery Suite")
+}
+
+func newObject(namespace string, status map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"status":     status,
+	}}
+	obj.SetName("app")
+	obj.SetNamespace(namespace)
+	obj.SetCreationTimestamp(metav1.Now())
+	return obj
+}
+
+func readyStatus(ready, url string) map[string]any {
+	return map[string]any{
+		"url": url,
+		"conditions": []any{
+			map[string]any{"type": "Ready", "status": ready},
+		},
+	}
+}
+
+var _ = Describe("Resource", func() {
+	It("should reject configurations without a resource", func() {
+		_, err := NewResource(ResourceConfig{Version: "v1"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid JSONPath templates", func() {
+		_, err := NewResource(ResourceConfig{Version: "v1", Resource: "services", HostPath: "{.status.url"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should use the Ready condition by default", func() {
+		r, err := NewResource(ResourceConfig{Version: "v1", Resource: "services"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.IsReady(newObject("ns-a", readyStatus("True", "")))).To(BeTrue())
+		Expect(r.IsReady(newObject("ns-a", readyStatus("False", "")))).To(BeFalse())
+		Expect(r.IsReady(newObject("ns-a", map[string]any{}))).To(BeFalse())
+	})
+
+	It("should match custom ready values", func() {
+		r, err := NewResource(ResourceConfig{
+			Version:     "v1alpha1",
+			Resource:    "devboxes",
+			ReadyPath:   "{.status.phase}",
+			ReadyValues: []string{"Running"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.IsReady(newObject("ns-a", map[string]any{"phase": "Running"}))).To(BeTrue())
+		Expect(r.IsReady(newObject("ns-a", map[string]any{"phase": "Stopped"}))).To(BeFalse())
+	})
+
+	It("should extract hosts from URLs and plain values", func() {
+		r, err := NewResource(ResourceConfig{Version: "v1", Resource: "services", HostPath: "{.status.url}"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Hosts(newObject("ns-a", readyStatus("True", "https://app.example.com")))).
+			To(Equal([]string{"app.example.com"}))
+
+		r, err = NewResource(ResourceConfig{Version: "v1", Resource: "apps", HostPath: "{.spec.hosts[*]}"})
+		Expect(err).NotTo(HaveOccurred())
+		obj := newObject("ns-a", nil)
+		obj.Object["spec"] = map[string]any{"hosts": []any{"a.example.com", "b.example.com"}}
+		Expect(r.Hosts(obj)).To(Equal([]string{"a.example.com", "b.example.com"}))
+	})
+})
+
+var _ = Describe("ReadinessHandler", func() {
+	type change struct {
+		name  string
+		ready bool
+	}
+	var (
+		changes []change
+		handler *ReadinessHandler
+	)
+
+	BeforeEach(func() {
+		changes = nil
+		r, err := NewResource(ResourceConfig{Version: "v1", Resource: "services", HostPath: "{.status.url}"})
+		Expect(err).NotTo(HaveOccurred())
+		handler = &ReadinessHandler{
+			Resource:     r,
+			AgeThreshold: time.Minute,
+			Filter:       NamespacePrefixFilter("ns-"),
+			OnChange: func(obj *unstructured.Unstructured, ready bool) {
+				changes = append(changes, change{name: obj.GetName(), ready: ready})
+			},
+		}
+	})
+
+	It("should report new ready objects and skip old or filtered ones", func() {
+		handler.OnAdd(newObject("ns-a", readyStatus("True", "https://a")), false)
+		Expect(changes).To(Equal([]change{{"app", true}}))
+
+		old := newObject("ns-a", readyStatus("True", "https://a"))
+		old.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+		handler.OnAdd(old, true)
+		handler.OnAdd(newObject("kube-system", readyStatus("True", "https://a")), false)
+		handler.OnAdd(newObject("ns-a", readyStatus("False", "https://a")), false)
+		Expect(changes).To(HaveLen(1))
+	})
+
+	It("should report readiness and host changes only", func() {
+		notReady := newObject("ns-a", readyStatus("False", "https://a"))
+		ready := newObject("ns-a", readyStatus("True", "https://a"))
+		moved := newObject("ns-a", readyStatus("True", "https://b"))
+
+		handler.OnUpdate(notReady, notReady)
+		handler.OnUpdate(notReady, ready)
+		handler.OnUpdate(ready, ready)
+		handler.OnUpdate(ready, moved)
+		handler.OnUpdate(moved, notReady)
+		Expect(changes).To(Equal([]change{{"app", true}, {"app", true}, {"app", false}}))
+	})
+
+	It("should report deletions including tombstones", func() {
+		handler.OnDelete(newObject("ns-a", readyStatus("True", "https://a")))
+		handler.OnDelete(cache.DeletedFinalStateUnknown{
+			Key: "ns-a/app",
+			Obj: newObject("ns-a", readyStatus("True", "https://a")),
+		})
+		Expect(changes).To(Equal([]change{{"app", false}, {"app", false}}))
+	})
+})
+
+var _ = Describe("DiscoveryInfos", func() {
+	It("should emit one entry per host and an empty entry for unready objects", func() {
+		r, err := NewResource(ResourceConfig{Version: "v1", Resource: "services", HostPath: "{.status.url}"})
+		Expect(err).NotTo(HaveOccurred())
+
+		infos := DiscoveryInfos("CustomResource", r, newObject("ns-a", readyStatus("True", "https://a.example.com")), true)
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Host).To(Equal("a.example.com"))
+		Expect(infos[0].HasActivePods).To(BeTrue())
+
+		Expect(DiscoveryInfos("CustomResource", r, newObject("ns-a", readyStatus("True", "")), true)).To(BeEmpty())
+		infos = DiscoveryInfos("CustomResource", r, newObject("ns-a", readyStatus("False", "")), false)
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Host).To(BeEmpty())
+		Expect(infos[0].HasActivePods).To(BeFalse())
+	})
+})
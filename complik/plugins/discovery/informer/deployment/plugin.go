@@ -29,6 +29,8 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
 	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,7 +50,7 @@ const (
 func init() {
 	plugin.PluginFactories[deploymentPluginName] = func() plugin.Plugin {
 		return &DeploymentPlugin{
-			log: logger.GetLogger().WithField("plugin", deploymentPluginName),
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, deploymentPluginName)),
 		}
 	}
 }
@@ -57,20 +59,26 @@ type DeploymentPlugin struct {
 	log                logger.Logger
 	stopChan           chan struct{}
 	eventBus           *eventbus.EventBus
+	events             *coalesce.Coalescer
+	resume             *resume.Tracker
 	factory            informers.SharedInformerFactory
 	deploymentInformer cache.SharedIndexInformer
 	deploymentConfig   DeploymentConfig
 }
 
 type DeploymentConfig struct {
-	ResyncTimeSecond   int `json:"resyncTimeSecond"`
-	AgeThresholdSecond int `json:"ageThresholdSecond"`
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
 }
 
 func (p *DeploymentPlugin) getDefaultDeploymentConfig() DeploymentConfig {
 	return DeploymentConfig{
 		ResyncTimeSecond:   5,
 		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
 	}
 }
 
@@ -94,6 +102,11 @@ func (p *DeploymentPlugin) loadConfig(setting string) error {
 	if configFromJSON.AgeThresholdSecond > 0 {
 		p.deploymentConfig.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
 	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.deploymentConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.deploymentConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.deploymentConfig.FullResync = configFromJSON.FullResync
 	return nil
 }
 
@@ -130,6 +143,8 @@ func (p *DeploymentPlugin) Start(
 	}
 	p.stopChan = make(chan struct{})
 	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.deploymentConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.deploymentConfig.ResumeStateDir, p.Name(), p.deploymentConfig.FullResync, p.log)
 	go p.startDeploymentInformerWatch(ctx)
 	return nil
 }
@@ -144,7 +159,7 @@ func (p *DeploymentPlugin) startDeploymentInformerWatch(ctx context.Context) {
 	if p.deploymentInformer == nil {
 		p.deploymentInformer = p.factory.Apps().V1().Deployments().Informer()
 	}
-	_, err := p.deploymentInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, err := p.deploymentInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			deployment, ok := obj.(*appsv1.Deployment)
 			if !ok {
@@ -194,7 +209,7 @@ func (p *DeploymentPlugin) startDeploymentInformerWatch(ctx context.Context) {
 				}
 			}
 		},
-	})
+	}))
 	if err != nil {
 		p.log.Error("Deployment informer stopped with error", logger.Fields{})
 		return
@@ -204,6 +219,7 @@ func (p *DeploymentPlugin) startDeploymentInformerWatch(ctx context.Context) {
 		p.log.Error("Failed to wait for deployment caches to sync")
 		return
 	}
+	go p.resume.Run(p.stopChan)
 	p.log.Info("Deployment informer watcher started successfully")
 	select {
 	case <-ctx.Done():
@@ -214,6 +230,10 @@ func (p *DeploymentPlugin) startDeploymentInformerWatch(ctx context.Context) {
 }
 
 func (p *DeploymentPlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
 	if p.stopChan != nil {
 		close(p.stopChan)
 	}
@@ -271,9 +291,7 @@ func compareStringSlices(slice1, slice2 []string) bool {
 
 func (p *DeploymentPlugin) handleDeploymentEvent(discoveryInfo []models.DiscoveryInfo) {
 	for _, info := range discoveryInfo {
-		p.eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
-			Payload: info,
-		})
+		p.events.PushDiscovery(info)
 	}
 }
 
@@ -0,0 +1,229 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package devbox implements an informer-based discovery plugin for Sealos DevBox
+// resources. Unlike the cronjob DevBox plugin it watches the DevBox CRD and
+// publishes the ingresses of a DevBox when it starts or stops running.
+package devbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/customresource"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	pluginName = constants.DiscoveryInformerDevboxName
+	pluginType = constants.DiscoveryInformerPluginType
+)
+
+const (
+	DevboxGroup        = "devbox.sealos.io"
+	DevboxVersion      = "v1alpha1"
+	DevboxResource     = "devboxes"
+	DevboxManagerLabel = "cloud.sealos.io/devbox-manager"
+	DevboxRunningPhase = "Running"
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &DevboxInformerPlugin{
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, pluginName)),
+		}
+	}
+}
+
+type DevboxInformerPlugin struct {
+	log          logger.Logger
+	stopChan     chan struct{}
+	eventBus     *eventbus.EventBus
+	events       *coalesce.Coalescer
+	resume       *resume.Tracker
+	devboxConfig DevboxInformerConfig
+}
+
+type DevboxInformerConfig struct {
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
+	NamespacePrefix    string `json:"namespacePrefix"`
+}
+
+func (p *DevboxInformerPlugin) getDefaultDevboxConfig() DevboxInformerConfig {
+	return DevboxInformerConfig{
+		ResyncTimeSecond:   60,
+		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
+		NamespacePrefix:    "ns-",
+	}
+}
+
+func (p *DevboxInformerPlugin) loadConfig(setting string) error {
+	p.devboxConfig = p.getDefaultDevboxConfig()
+	if setting == "" {
+		p.log.Info("Using default DevBox informer configuration")
+		return nil
+	}
+	var configFromJSON DevboxInformerConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config, using defaults", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.ResyncTimeSecond > 0 {
+		p.devboxConfig.ResyncTimeSecond = configFromJSON.ResyncTimeSecond
+	}
+	if configFromJSON.AgeThresholdSecond > 0 {
+		p.devboxConfig.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
+	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.devboxConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.devboxConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.devboxConfig.FullResync = configFromJSON.FullResync
+	if configFromJSON.NamespacePrefix != "" {
+		p.devboxConfig.NamespacePrefix = configFromJSON.NamespacePrefix
+	}
+	return nil
+}
+
+func (p *DevboxInformerPlugin) Name() string {
+	return pluginName
+}
+
+func (p *DevboxInformerPlugin) Type() string {
+	return pluginType
+}
+
+func (p *DevboxInformerPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	resource, err := customresource.NewResource(customresource.ResourceConfig{
+		Group:       DevboxGroup,
+		Version:     DevboxVersion,
+		Resource:    DevboxResource,
+		ReadyPath:   "{.status.phase}",
+		ReadyValues: []string{DevboxRunningPhase},
+	})
+	if err != nil {
+		return err
+	}
+	handler := &customresource.ReadinessHandler{
+		Resource:     resource,
+		AgeThreshold: time.Duration(p.devboxConfig.AgeThresholdSecond) * time.Second,
+		Filter:       customresource.NamespacePrefixFilter(p.devboxConfig.NamespacePrefix),
+		OnChange: func(obj *unstructured.Unstructured, running bool) {
+			p.handleDevboxEvent(ctx, obj, running)
+		},
+	}
+	p.stopChan = make(chan struct{})
+	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.devboxConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.devboxConfig.ResumeStateDir, p.Name(), p.devboxConfig.FullResync, p.log)
+	go customresource.Watch(
+		ctx,
+		p.log,
+		p.stopChan,
+		time.Duration(p.devboxConfig.ResyncTimeSecond)*time.Second,
+		[]*customresource.ReadinessHandler{handler},
+		p.resume,
+	)
+	return nil
+}
+
+func (p *DevboxInformerPlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
+	if p.stopChan != nil {
+		close(p.stopChan)
+	}
+	return nil
+}
+
+func (p *DevboxInformerPlugin) handleDevboxEvent(
+	ctx context.Context,
+	devbox *unstructured.Unstructured,
+	running bool,
+) {
+	discoveryInfos, err := p.getDevboxIngresses(ctx, devbox, running)
+	if err != nil {
+		p.log.Error("Failed to get DevBox ingresses", logger.Fields{
+			"devbox":    devbox.GetName(),
+			"namespace": devbox.GetNamespace(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	p.log.Debug("DevBox readiness changed", logger.Fields{
+		"devbox":    devbox.GetName(),
+		"namespace": devbox.GetNamespace(),
+		"running":   running,
+		"ingresses": len(discoveryInfos),
+	})
+	for _, info := range discoveryInfos {
+		p.events.PushDiscovery(info)
+	}
+}
+
+func (p *DevboxInformerPlugin) getDevboxIngresses(
+	ctx context.Context,
+	devbox *unstructured.Unstructured,
+	running bool,
+) ([]models.DiscoveryInfo, error) {
+	ingresses, err := k8s.ClientSet.NetworkingV1().
+		Ingresses(devbox.GetNamespace()).
+		List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", DevboxManagerLabel, devbox.GetName()),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	podCount := 0
+	if running {
+		podCount = 1
+	}
+	var discoveryInfos []models.DiscoveryInfo
+	for _, ingress := range ingresses.Items {
+		discoveryInfos = append(
+			discoveryInfos,
+			utils.GenerateDiscoveryInfo(ingress, running, podCount, p.Name())...,
+		)
+	}
+	return discoveryInfos, nil
+}
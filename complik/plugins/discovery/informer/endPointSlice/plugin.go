@@ -16,6 +16,7 @@ package endPointSlice
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -26,6 +27,8 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
@@ -40,15 +43,44 @@ const (
 func init() {
 	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
 		return &EndPointInformerPlugin{
-			log: logger.GetLogger().WithField("plugin", pluginName),
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, pluginName)),
 		}
 	}
 }
 
 type EndPointInformerPlugin struct {
-	log      logger.Logger
-	stopChan chan struct{}
-	eventBus *eventbus.EventBus
+	log            logger.Logger
+	stopChan       chan struct{}
+	eventBus       *eventbus.EventBus
+	events         *coalesce.Coalescer
+	resume         *resume.Tracker
+	endpointConfig EndpointSliceConfig
+}
+
+type EndpointSliceConfig struct {
+	DedupWindowSecond int    `json:"dedupWindowSecond"`
+	ResumeStateDir    string `json:"resumeStateDir"`
+	FullResync        bool   `json:"fullResync"`
+}
+
+func (p *EndPointInformerPlugin) loadConfig(setting string) error {
+	p.endpointConfig = EndpointSliceConfig{DedupWindowSecond: coalesce.DefaultWindowSecond}
+	if setting == "" {
+		return nil
+	}
+	var configFromJSON EndpointSliceConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.endpointConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.endpointConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.endpointConfig.FullResync = configFromJSON.FullResync
+	return nil
 }
 
 type EndpointSliceInfo struct {
@@ -88,8 +120,14 @@ func (p *EndPointInformerPlugin) Start(
 		"plugin": pluginName,
 	})
 
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
 	p.stopChan = make(chan struct{})
 	p.eventBus = eventBus
+	// Rolling updates change the EndpointSlices of a service many times in a row
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.endpointConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.endpointConfig.ResumeStateDir, p.Name(), p.endpointConfig.FullResync, p.log)
 	go p.startInformerWatch(ctx)
 
 	p.log.Info("EndPointSlice informer plugin started successfully")
@@ -103,7 +141,7 @@ func (p *EndPointInformerPlugin) startInformerWatch(ctx context.Context) {
 
 	factory := informers.NewSharedInformerFactory(k8s.ClientSet, 60*time.Second)
 	endpointSliceInformer := factory.Discovery().V1().EndpointSlices().Informer()
-	_, err := endpointSliceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, err := endpointSliceInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
 			if !ok {
@@ -195,7 +233,7 @@ func (p *EndPointInformerPlugin) startInformerWatch(ctx context.Context) {
 				})
 			}
 		},
-	})
+	}))
 	if err != nil {
 		return
 	}
@@ -208,6 +246,7 @@ func (p *EndPointInformerPlugin) startInformerWatch(ctx context.Context) {
 		p.log.Error("Failed to wait for caches to sync")
 		return
 	}
+	go p.resume.Run(p.stopChan)
 
 	p.log.Info("EndpointSlice informer watcher started successfully")
 	select {
@@ -221,6 +260,10 @@ func (p *EndPointInformerPlugin) startInformerWatch(ctx context.Context) {
 
 func (p *EndPointInformerPlugin) Stop(ctx context.Context) error {
 	p.log.Info("Stopping EndPointSlice informer plugin")
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
 	if p.stopChan != nil {
 		close(p.stopChan)
 		p.log.Debug("Stop channel closed")
@@ -455,9 +498,13 @@ func (p *EndPointInformerPlugin) handleEndpointSliceEvent(endpointInfo *Endpoint
 		"matchedIngresses": len(endpointInfo.MatchedIngresses),
 	})
 
-	p.eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
-		Payload: endpointInfo,
-	})
+	if !p.events.Push(endpointInfo.Namespace+"/"+endpointInfo.ServiceName, endpointInfo) {
+		p.log.Debug("EndpointSlice event coalesced into the next event of the service", logger.Fields{
+			"namespace":   endpointInfo.Namespace,
+			"serviceName": endpointInfo.ServiceName,
+		})
+		return
+	}
 
 	p.log.Debug("EndpointSlice event published successfully")
 }
@@ -31,6 +31,8 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
 	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -51,7 +53,7 @@ const (
 func init() {
 	plugin.PluginFactories[ingressPluginName] = func() plugin.Plugin {
 		return &IngressPlugin{
-			log: logger.GetLogger().WithField("plugin", ingressPluginName),
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, ingressPluginName)),
 		}
 	}
 }
@@ -60,20 +62,26 @@ type IngressPlugin struct {
 	log             logger.Logger
 	stopChan        chan struct{}
 	eventBus        *eventbus.EventBus
+	events          *coalesce.Coalescer
+	resume          *resume.Tracker
 	factory         informers.SharedInformerFactory
 	ingressInformer cache.SharedIndexInformer
 	ingressConfig   IngressConfig
 }
 
 type IngressConfig struct {
-	ResyncTimeSecond   int `json:"resyncTimeSecond"`
-	AgeThresholdSecond int `json:"ageThresholdSecond"`
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
 }
 
 func (p *IngressPlugin) getDefaultIngressConfig() IngressConfig {
 	return IngressConfig{
 		ResyncTimeSecond:   5,
 		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
 	}
 }
 
@@ -97,6 +105,11 @@ func (p *IngressPlugin) loadConfig(setting string) error {
 	if configFromJSON.AgeThresholdSecond > 0 {
 		p.ingressConfig.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
 	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.ingressConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.ingressConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.ingressConfig.FullResync = configFromJSON.FullResync
 	return nil
 }
 
@@ -119,6 +132,8 @@ func (p *IngressPlugin) Start(
 	}
 	p.stopChan = make(chan struct{})
 	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.ingressConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.ingressConfig.ResumeStateDir, p.Name(), p.ingressConfig.FullResync, p.log)
 	go p.startIngressInformerWatch(ctx)
 	return nil
 }
@@ -134,7 +149,7 @@ func (p *IngressPlugin) startIngressInformerWatch(ctx context.Context) {
 		p.ingressInformer = p.factory.Networking().V1().Ingresses().Informer()
 	}
 
-	_, err := p.ingressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, err := p.ingressInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			ingress, ok := obj.(*networkingv1.Ingress)
 			if !ok {
@@ -201,7 +216,7 @@ func (p *IngressPlugin) startIngressInformerWatch(ctx context.Context) {
 				p.handleIngressEvent(discoveryInfos)
 			}
 		},
-	})
+	}))
 	if err != nil {
 		return
 	}
@@ -211,6 +226,7 @@ func (p *IngressPlugin) startIngressInformerWatch(ctx context.Context) {
 		p.log.Error("Failed to wait for ingress caches to sync")
 		return
 	}
+	go p.resume.Run(p.stopChan)
 	p.log.Info("Ingress informer watcher started successfully")
 	select {
 	case <-ctx.Done():
@@ -221,6 +237,10 @@ func (p *IngressPlugin) startIngressInformerWatch(ctx context.Context) {
 }
 
 func (p *IngressPlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
 	if p.stopChan != nil {
 		close(p.stopChan)
 	}
@@ -280,9 +300,7 @@ func (p *IngressPlugin) hasIngressChanged(oldIngress, newIngress *networkingv1.I
 
 func (p *IngressPlugin) handleIngressEvent(discoveryInfo []models.DiscoveryInfo) {
 	for _, info := range discoveryInfo {
-		p.eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
-			Payload: info,
-		})
+		p.events.PushDiscovery(info)
 	}
 }
 
@@ -0,0 +1,153 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancer
+
+import (
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultHTTPPorts are the service ports scanned as websites without a name
+// or appProtocol saying so
+var defaultHTTPPorts = []int{80, 443, 8080, 8443}
+
+// endpoint is a TCP port on an external address of a service
+type endpoint struct {
+	host string
+	// port is the external port, the node port of NodePort services
+	port int
+	// servicePort is the port of the service the external port leads to
+	servicePort int
+	// web ports are also scanned by the browser, over https when tls is set
+	web bool
+	tls bool
+}
+
+// url returns the address the browser opens for a web endpoint
+func (e endpoint) url() string {
+	if e.tls {
+		return "https://" + e.host
+	}
+	return e.host
+}
+
+// exposure classifies the ports of exposed services
+type exposure struct {
+	types     []corev1.ServiceType
+	httpPorts []int
+}
+
+// exposed reports whether service is of a watched type
+func (x exposure) exposed(service *corev1.Service) bool {
+	return slices.Contains(x.types, service.Spec.Type)
+}
+
+// endpoints returns every TCP port on every external address of service.
+// LoadBalancer services are reached on their ingress addresses, NodePort
+// services on the node port of nodeAddress.
+func (x exposure) endpoints(service *corev1.Service, nodeAddress string) []endpoint {
+	var found []endpoint
+	for _, port := range service.Spec.Ports {
+		if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			continue
+		}
+		web, tls := x.classify(port)
+		add := func(address string, external int32) {
+			found = append(found, endpoint{
+				host:        net.JoinHostPort(address, strconv.Itoa(int(external))),
+				port:        int(external),
+				servicePort: int(port.Port),
+				web:         web,
+				tls:         tls,
+			})
+		}
+		switch service.Spec.Type {
+		case corev1.ServiceTypeLoadBalancer:
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				address := ingress.IP
+				if address == "" {
+					address = ingress.Hostname
+				}
+				if address != "" {
+					add(address, port.Port)
+				}
+			}
+		case corev1.ServiceTypeNodePort:
+			if nodeAddress != "" && port.NodePort > 0 {
+				add(nodeAddress, port.NodePort)
+			}
+		}
+	}
+	return found
+}
+
+// classify reports whether port serves a website and whether over TLS. The
+// appProtocol wins over the port name, which wins over the port number.
+func (x exposure) classify(port corev1.ServicePort) (web bool, tls bool) {
+	if port.AppProtocol != nil {
+		switch strings.ToLower(*port.AppProtocol) {
+		case "http", "kubernetes.io/h2c", "kubernetes.io/ws":
+			return true, false
+		case "https", "kubernetes.io/wss":
+			return true, true
+		}
+	}
+	name := strings.ToLower(port.Name)
+	switch {
+	case name == "https" || strings.HasPrefix(name, "https-"):
+		return true, true
+	case name == "http" || name == "web" || strings.HasPrefix(name, "http-"):
+		return true, false
+	}
+	if slices.Contains(x.httpPorts, int(port.Port)) {
+		return true, port.Port == 443 || port.Port == 8443
+	}
+	return false, false
+}
+
+// nodeAddress returns the address NodePort services are reached on: the
+// external IP of the first ready node by name, or its internal IP when no
+// ready node has an external one
+func nodeAddress(nodes []*corev1.Node) string {
+	nodes = slices.Clone(nodes)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, kind := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for _, node := range nodes {
+			if !nodeReady(node) {
+				continue
+			}
+			for _, address := range node.Status.Addresses {
+				if address.Type == kind && address.Address != "" {
+					return address.Address
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancer
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadBalancer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LoadBalancer Discovery Suite")
+}
+
+func newService(serviceType corev1.ServiceType, ports ...corev1.ServicePort) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns-test"},
+		Spec:       corev1.ServiceSpec{Type: serviceType, Ports: ports},
+	}
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+	}
+	return service
+}
+
+func newNode(name string, ready bool, addresses ...corev1.NodeAddress) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Addresses:  addresses,
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+var _ = Describe("Exposure", func() {
+	x := exposure{
+		types:     []corev1.ServiceType{corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort},
+		httpPorts: defaultHTTPPorts,
+	}
+
+	It("should publish every TCP port of LoadBalancer services and mark websites", func() {
+		https := "https"
+		service := newService(corev1.ServiceTypeLoadBalancer,
+			corev1.ServicePort{Name: "game", Port: 25565},
+			corev1.ServicePort{Name: "web", Port: 3000},
+			corev1.ServicePort{Name: "api", Port: 9443, AppProtocol: &https},
+			corev1.ServicePort{Name: "tls", Port: 443},
+			corev1.ServicePort{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP},
+		)
+		Expect(x.endpoints(service, "")).To(Equal([]endpoint{
+			{host: "203.0.113.10:25565", port: 25565, servicePort: 25565},
+			{host: "203.0.113.10:3000", port: 3000, servicePort: 3000, web: true},
+			{host: "203.0.113.10:9443", port: 9443, servicePort: 9443, web: true, tls: true},
+			{host: "203.0.113.10:443", port: 443, servicePort: 443, web: true, tls: true},
+		}))
+	})
+
+	It("should publish NodePort services on the node port of the node address", func() {
+		service := newService(corev1.ServiceTypeNodePort,
+			corev1.ServicePort{Name: "http", Port: 80, NodePort: 30080},
+			corev1.ServicePort{Name: "https-admin", Port: 8000, NodePort: 30443},
+		)
+		found := x.endpoints(service, "198.51.100.7")
+		Expect(found).To(HaveLen(2))
+		Expect(found[0].url()).To(Equal("198.51.100.7:30080"))
+		Expect(found[0].servicePort).To(Equal(80))
+		Expect(found[1].url()).To(Equal("https://198.51.100.7:30443"))
+		Expect(x.endpoints(service, "")).To(BeEmpty())
+	})
+
+	It("should only watch the configured service types", func() {
+		lbOnly := exposure{types: []corev1.ServiceType{corev1.ServiceTypeLoadBalancer}}
+		Expect(lbOnly.exposed(newService(corev1.ServiceTypeLoadBalancer))).To(BeTrue())
+		Expect(lbOnly.exposed(newService(corev1.ServiceTypeNodePort))).To(BeFalse())
+		Expect(x.exposed(newService(corev1.ServiceTypeClusterIP))).To(BeFalse())
+	})
+
+	It("should not treat unnamed ports as websites without HTTP ports", func() {
+		noPorts := exposure{types: x.types}
+		web, _ := noPorts.classify(corev1.ServicePort{Port: 80})
+		Expect(web).To(BeFalse())
+		web, tls := noPorts.classify(corev1.ServicePort{Name: "http-metrics", Port: 9100})
+		Expect(web).To(BeTrue())
+		Expect(tls).To(BeFalse())
+	})
+
+	It("should prefer the external IP of the first ready node", func() {
+		nodes := []*corev1.Node{
+			newNode("node-c", true, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "198.51.100.3"}),
+			newNode("node-a", false, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "198.51.100.1"}),
+			newNode("node-b", true, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.2"}),
+		}
+		Expect(nodeAddress(nodes)).To(Equal("198.51.100.3"))
+		Expect(nodeAddress(nodes[1:])).To(Equal("10.0.0.2"))
+		Expect(nodeAddress(nil)).To(BeEmpty())
+	})
+
+	It("should watch LoadBalancer services by default and reject other types", func() {
+		p := plugin.PluginFactories[pluginName]().(*LoadBalancerPlugin)
+		Expect(p.loadConfig("")).To(Succeed())
+		Expect(p.exposure.types).To(Equal([]corev1.ServiceType{corev1.ServiceTypeLoadBalancer}))
+		Expect(p.exposure.httpPorts).To(Equal(defaultHTTPPorts))
+
+		Expect(p.loadConfig(`{"serviceTypes": ["LoadBalancer", "NodePort"], "httpPorts": []}`)).To(Succeed())
+		Expect(p.exposure.types).To(HaveLen(2))
+		Expect(p.exposure.httpPorts).To(BeEmpty())
+
+		Expect(p.loadConfig(`{"serviceTypes": ["ExternalName"]}`)).To(MatchError(ContainSubstring("unsupported service type")))
+	})
+})
@@ -0,0 +1,367 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loadbalancer implements a discovery plugin that monitors Kubernetes
+// LoadBalancer and, optionally, NodePort Services. Tenants expose game servers
+// and databases this way, so every TCP port of the external addresses is
+// published as a TCP discovery for the TCP collector. Ports serving websites
+// are also published as HTTP discoveries for the browser, so content exposed
+// without an Ingress is scanned as well.
+package loadbalancer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	pluginName = constants.DiscoveryInformerLoadBalancerName
+	pluginType = constants.DiscoveryInformerPluginType
+)
+
+const (
+	AppDeployManagerLabel = "cloud.sealos.io/app-deploy-manager"
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &LoadBalancerPlugin{
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, pluginName)),
+		}
+	}
+}
+
+type LoadBalancerPlugin struct {
+	log             logger.Logger
+	stopChan        chan struct{}
+	eventBus        *eventbus.EventBus
+	events          *coalesce.Coalescer
+	resume          *resume.Tracker
+	factory         informers.SharedInformerFactory
+	serviceInformer cache.SharedIndexInformer
+	nodeLister      corelisters.NodeLister
+	config          LoadBalancerConfig
+	exposure        exposure
+}
+
+type LoadBalancerConfig struct {
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
+	// ServiceTypes are the watched service types, LoadBalancer and NodePort
+	ServiceTypes []string `json:"serviceTypes"`
+	// HTTPPorts are the service ports also scanned as websites, in addition
+	// to ports named or with an appProtocol of http or https
+	HTTPPorts []int `json:"httpPorts"`
+}
+
+func (p *LoadBalancerPlugin) getDefaultConfig() LoadBalancerConfig {
+	return LoadBalancerConfig{
+		ResyncTimeSecond:   5,
+		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
+		ServiceTypes:       []string{string(corev1.ServiceTypeLoadBalancer)},
+		HTTPPorts:          defaultHTTPPorts,
+	}
+}
+
+func (p *LoadBalancerPlugin) loadConfig(setting string) error {
+	p.config = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default load balancer configuration")
+		return p.buildExposure()
+	}
+	var configFromJSON LoadBalancerConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse configuration, using defaults", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.ResyncTimeSecond > 0 {
+		p.config.ResyncTimeSecond = configFromJSON.ResyncTimeSecond
+	}
+	if configFromJSON.AgeThresholdSecond > 0 {
+		p.config.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
+	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.config.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	if len(configFromJSON.ServiceTypes) > 0 {
+		p.config.ServiceTypes = configFromJSON.ServiceTypes
+	}
+	if configFromJSON.HTTPPorts != nil {
+		p.config.HTTPPorts = configFromJSON.HTTPPorts
+	}
+	p.config.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.config.FullResync = configFromJSON.FullResync
+	return p.buildExposure()
+}
+
+func (p *LoadBalancerPlugin) buildExposure() error {
+	p.exposure = exposure{httpPorts: p.config.HTTPPorts}
+	for _, serviceType := range p.config.ServiceTypes {
+		switch corev1.ServiceType(serviceType) {
+		case corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort:
+			p.exposure.types = append(p.exposure.types, corev1.ServiceType(serviceType))
+		default:
+			return fmt.Errorf("unsupported service type %q, use LoadBalancer or NodePort", serviceType)
+		}
+	}
+	return nil
+}
+
+func (p *LoadBalancerPlugin) Name() string {
+	return pluginName
+}
+
+func (p *LoadBalancerPlugin) Type() string {
+	return pluginType
+}
+
+func (p *LoadBalancerPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.stopChan = make(chan struct{})
+	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.config.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.config.ResumeStateDir, p.Name(), p.config.FullResync, p.log)
+	go p.watch(ctx)
+
+	p.log.Info("LoadBalancer service informer started", logger.Fields{
+		"resync_seconds":        p.config.ResyncTimeSecond,
+		"age_threshold_seconds": p.config.AgeThresholdSecond,
+		"service_types":         p.config.ServiceTypes,
+	})
+	return nil
+}
+
+func (p *LoadBalancerPlugin) watch(ctx context.Context) {
+	if p.factory == nil {
+		p.factory = informers.NewSharedInformerFactory(
+			k8s.ClientSet,
+			time.Duration(p.config.ResyncTimeSecond)*time.Second,
+		)
+	}
+	if p.serviceInformer == nil {
+		p.serviceInformer = p.factory.Core().V1().Services().Informer()
+	}
+	synced := []cache.InformerSynced{p.serviceInformer.HasSynced}
+	if slices.Contains(p.exposure.types, corev1.ServiceTypeNodePort) {
+		nodes := p.factory.Core().V1().Nodes()
+		p.nodeLister = nodes.Lister()
+		synced = append(synced, nodes.Informer().HasSynced)
+	}
+	_, err := p.serviceInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			service, ok := obj.(*corev1.Service)
+			if !ok || !p.shouldProcess(service) {
+				return
+			}
+			if time.Since(service.CreationTimestamp.Time) >
+				time.Duration(p.config.AgeThresholdSecond)*time.Second {
+				return
+			}
+			p.publish(service)
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldService, ok := oldObj.(*corev1.Service)
+			if !ok {
+				return
+			}
+			newService, ok := newObj.(*corev1.Service)
+			if !ok || !p.shouldProcess(newService) {
+				return
+			}
+			// The external address is assigned after the service is created
+			node := p.nodeAddress()
+			if p.shouldProcess(oldService) &&
+				slices.Equal(p.exposure.endpoints(oldService, node), p.exposure.endpoints(newService, node)) {
+				return
+			}
+			p.publish(newService)
+		},
+	}))
+	if err != nil {
+		p.log.Error("Failed to add service event handler", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	p.factory.Start(p.stopChan)
+	if !cache.WaitForCacheSync(p.stopChan, synced...) {
+		p.log.Error("Failed to wait for service caches to sync")
+		return
+	}
+	go p.resume.Run(p.stopChan)
+
+	select {
+	case <-ctx.Done():
+		p.log.Info("LoadBalancer watcher stopping due to context cancellation")
+	case <-p.stopChan:
+		p.log.Info("LoadBalancer watcher stopping due to stop signal")
+	}
+}
+
+func (p *LoadBalancerPlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
+	if p.stopChan != nil {
+		close(p.stopChan)
+	}
+	return nil
+}
+
+func (p *LoadBalancerPlugin) shouldProcess(service *corev1.Service) bool {
+	if !p.exposure.exposed(service) {
+		return false
+	}
+	return strings.HasPrefix(service.Namespace, "ns-")
+}
+
+// nodeAddress returns the address NodePort services are published on, empty
+// when NodePort services are not watched
+func (p *LoadBalancerPlugin) nodeAddress() string {
+	if p.nodeLister == nil {
+		return ""
+	}
+	nodes, err := p.nodeLister.List(labels.Everything())
+	if err != nil {
+		p.log.Warn("Failed to list nodes", logger.Fields{"error": err.Error()})
+		return ""
+	}
+	return nodeAddress(nodes)
+}
+
+func (p *LoadBalancerPlugin) publish(service *corev1.Service) {
+	found := p.exposure.endpoints(service, p.nodeAddress())
+	if len(found) == 0 {
+		return
+	}
+	kind := strings.ToLower(string(service.Spec.Type))
+	name := service.Name
+	if appName, ok := service.Labels[AppDeployManagerLabel]; ok {
+		name = appName
+	}
+	podCount, hasActivePods, err := podInfo(service)
+	if err != nil {
+		p.log.Warn("Failed to get pod info for service", logger.Fields{
+			"namespace": service.Namespace,
+			"name":      service.Name,
+			"error":     err.Error(),
+		})
+	}
+	for _, target := range found {
+		info := models.DiscoveryInfo{
+			DiscoveryName: fmt.Sprintf(
+				"%s-%s-%s-%d",
+				kind,
+				service.Namespace,
+				service.Name,
+				target.port,
+			),
+			Name:          name,
+			Namespace:     service.Namespace,
+			Host:          target.host,
+			Path:          []string{},
+			ServiceName:   service.Name,
+			ServicePort:   target.servicePort,
+			Protocol:      models.ProtocolTCP,
+			HasActivePods: hasActivePods,
+			PodCount:      podCount,
+		}
+		p.log.Debug("Found exposed service port", logger.Fields{
+			"namespace": service.Namespace,
+			"name":      service.Name,
+			"type":      service.Spec.Type,
+			"host":      target.host,
+			"web":       target.web,
+			"pod_count": podCount,
+		})
+		p.events.PushDiscovery(info)
+		if !target.web {
+			continue
+		}
+		// The website on the port is opened by the browser, the TCP
+		// discovery above still fingerprints the port
+		info.DiscoveryName += "-http"
+		info.Host = target.url()
+		info.Path = []string{"/"}
+		info.Protocol = ""
+		p.events.PushDiscovery(info)
+	}
+}
+
+// podInfo returns the number of pods behind service and whether one is ready
+func podInfo(service *corev1.Service) (int, bool, error) {
+	if len(service.Spec.Selector) == 0 {
+		return 0, false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: service.Spec.Selector,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to build label selector: %w", err)
+	}
+	pods, err := k8s.ClientSet.CoreV1().
+		Pods(service.Namespace).
+		List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get Pod list: %w", err)
+	}
+	active := false
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		ready := true
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				ready = condition.Status == corev1.ConditionTrue
+				break
+			}
+		}
+		active = active || ready
+	}
+	return len(pods.Items), active, nil
+}
@@ -32,6 +32,9 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
@@ -50,7 +53,7 @@ const (
 func init() {
 	plugin.PluginFactories[servicePluginName] = func() plugin.Plugin {
 		return &ServicePlugin{
-			log: logger.GetLogger().WithField("plugin", servicePluginName),
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, servicePluginName)),
 		}
 	}
 }
@@ -59,20 +62,26 @@ type ServicePlugin struct {
 	log             logger.Logger
 	stopChan        chan struct{}
 	eventBus        *eventbus.EventBus
+	events          *coalesce.Coalescer
+	resume          *resume.Tracker
 	factory         informers.SharedInformerFactory
 	serviceInformer cache.SharedIndexInformer
 	serviceConfig   ServiceConfig
 }
 
 type ServiceConfig struct {
-	ResyncTimeSecond   int `json:"resyncTimeSecond"`
-	AgeThresholdSecond int `json:"ageThresholdSecond"`
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
 }
 
 func (p *ServicePlugin) getDefaultServiceConfig() ServiceConfig {
 	return ServiceConfig{
 		ResyncTimeSecond:   5,
 		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
 	}
 }
 
@@ -99,6 +108,11 @@ func (p *ServicePlugin) loadConfig(setting string) error {
 	if configFromJSON.AgeThresholdSecond > 0 {
 		p.serviceConfig.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
 	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.serviceConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.serviceConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.serviceConfig.FullResync = configFromJSON.FullResync
 
 	p.log.Info("Service configuration loaded", logger.Fields{
 		"resync_seconds":        p.serviceConfig.ResyncTimeSecond,
@@ -133,6 +147,8 @@ func (p *ServicePlugin) Start(
 
 	p.stopChan = make(chan struct{})
 	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.serviceConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.serviceConfig.ResumeStateDir, p.Name(), p.serviceConfig.FullResync, p.log)
 
 	p.log.Debug("Starting service informer watcher")
 	go p.startServiceInformerWatch(ctx)
@@ -151,7 +167,7 @@ func (p *ServicePlugin) startServiceInformerWatch(ctx context.Context) {
 	if p.serviceInformer == nil {
 		p.serviceInformer = p.factory.Core().V1().Services().Informer()
 	}
-	_, err := p.serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, err := p.serviceInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			service, ok := obj.(*corev1.Service)
 			if !ok {
@@ -220,7 +236,7 @@ func (p *ServicePlugin) startServiceInformerWatch(ctx context.Context) {
 				}
 			}
 		},
-	})
+	}))
 	if err != nil {
 		return
 	}
@@ -229,6 +245,7 @@ func (p *ServicePlugin) startServiceInformerWatch(ctx context.Context) {
 		p.log.Error("Failed to wait for service caches to sync")
 		return
 	}
+	go p.resume.Run(p.stopChan)
 
 	p.log.Info("Service informer watcher started successfully")
 	select {
@@ -242,6 +259,10 @@ func (p *ServicePlugin) startServiceInformerWatch(ctx context.Context) {
 func (p *ServicePlugin) Stop(ctx context.Context) error {
 	p.log.Info("Stopping NodePort service informer plugin")
 
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
 	if p.stopChan != nil {
 		close(p.stopChan)
 		p.log.Debug("Stop channel closed")
@@ -308,9 +329,7 @@ func (p *ServicePlugin) handleServiceEvent(discoveryInfo []models.DiscoveryInfo)
 	})
 
 	for _, info := range discoveryInfo {
-		p.eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
-			Payload: info,
-		})
+		p.events.PushDiscovery(info)
 	}
 }
 
@@ -365,6 +384,7 @@ func (p *ServicePlugin) getServiceDiscoveryInfo(
 		})
 	}
 	var discoveryInfos []models.DiscoveryInfo
+	scanOverrides := utils.ScanOverrides(service.ObjectMeta)
 	for _, port := range service.Spec.Ports {
 		if port.NodePort > 0 {
 			paths := []string{"/"}
@@ -380,8 +400,10 @@ func (p *ServicePlugin) getServiceDiscoveryInfo(
 				Host:          fmt.Sprintf("%s:%d", nodeIP, port.NodePort),
 				Path:          paths,
 				ServiceName:   service.Name,
+				ServicePort:   int(port.Port),
 				HasActivePods: hasActivePods,
 				PodCount:      podCount,
+				Overrides:     scanOverrides,
 			}
 			discoveryInfos = append(discoveryInfos, discoveryInfo)
 
@@ -0,0 +1,282 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resume lets the informer plugins pick up where they stopped after a
+// restart. An informer lists every object again when it starts and reports
+// each of them as added, which turns into a scan of every exposed service. A
+// Tracker persists the resourceVersion last processed for every object and
+// drops the initial adds of objects that did not change while the plugin was
+// down, so only new and modified objects are processed.
+package resume
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SaveInterval is how often the processed resourceVersions are written
+const SaveInterval = time.Minute
+
+// Tracker records the resourceVersion processed for every object of an
+// informer. A nil Tracker disables resuming.
+type Tracker struct {
+	path string
+	log  logger.Logger
+
+	mu       sync.Mutex
+	previous map[string]string // resourceVersions by UID persisted by the last run
+	current  map[string]string // resourceVersions by UID processed in this run
+	synced   bool
+	dirty    bool
+	skipped  int
+}
+
+// NewTracker loads the state of plugin from dir. It returns nil when dir is
+// empty. With fullResync the persisted state is ignored and every object is
+// processed again; the state is still written for the next restart. A state
+// that cannot be read also results in a full resync.
+func NewTracker(dir, plugin string, fullResync bool, log logger.Logger) *Tracker {
+	if dir == "" {
+		return nil
+	}
+	t := &Tracker{
+		path:     filepath.Join(dir, plugin+".json"),
+		log:      log,
+		previous: make(map[string]string),
+		current:  make(map[string]string),
+	}
+	if fullResync {
+		log.Info("Full resync requested, ignoring the persisted informer state", logger.Fields{"path": t.path})
+		return t
+	}
+	if err := t.load(); err != nil {
+		log.Warn("Failed to load informer state, resyncing all objects", logger.Fields{
+			"path":  t.path,
+			"error": err.Error(),
+		})
+		t.previous = make(map[string]string)
+	}
+	return t
+}
+
+// Wrap returns handler with the initial adds of unchanged objects dropped.
+// Every object passed on is recorded as processed.
+func (t *Tracker) Wrap(handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	if t == nil {
+		return handler
+	}
+	return &trackingHandler{tracker: t, handler: handler}
+}
+
+// Unchanged records obj as processed and reports whether it has the
+// resourceVersion it had when the previous run last processed it
+func (t *Tracker) Unchanged(obj any) bool {
+	if t == nil {
+		return false
+	}
+	uid, version, ok := key(obj)
+	if !ok {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(uid, version)
+	if version == "" || t.previous[uid] != version {
+		return false
+	}
+	t.skipped++
+	return true
+}
+
+// Processed records the resourceVersion of obj
+func (t *Tracker) Processed(obj any) {
+	if t == nil {
+		return
+	}
+	if uid, version, ok := key(obj); ok {
+		t.mu.Lock()
+		t.record(uid, version)
+		t.mu.Unlock()
+	}
+}
+
+// Forget removes obj after it was deleted
+func (t *Tracker) Forget(obj any) {
+	if t == nil {
+		return
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if uid, _, ok := key(obj); ok {
+		t.mu.Lock()
+		delete(t.current, uid)
+		t.dirty = true
+		t.mu.Unlock()
+	}
+}
+
+// Skipped returns the number of initial adds dropped because the object did
+// not change since the previous run
+func (t *Tracker) Skipped() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.skipped
+}
+
+// Run is called once the informer caches synced, from then on every existing
+// object was recorded and the state is written every SaveInterval until stop
+// is closed. Objects deleted while the plugin was down are not part of the
+// written state.
+func (t *Tracker) Run(stop <-chan struct{}) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.synced = true
+	t.dirty = true
+	t.previous = nil
+	skipped := t.skipped
+	t.mu.Unlock()
+	t.log.Info("Informer resumed from persisted state", logger.Fields{
+		"path":      t.path,
+		"unchanged": skipped,
+	})
+
+	ticker := time.NewTicker(SaveInterval)
+	defer ticker.Stop()
+	for {
+		t.saveAndLog()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Save writes the state when it changed. Nothing is written before the
+// informer caches synced, as the state of the objects not listed yet would be lost.
+func (t *Tracker) Save() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.synced || !t.dirty {
+		return nil
+	}
+	data, err := json.Marshal(t.current)
+	if err != nil {
+		return fmt.Errorf("failed to encode informer state: %w", err)
+	}
+	if dir := filepath.Dir(t.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create informer state directory: %w", err)
+		}
+	}
+	// Write through a temporary file so a crash never leaves a truncated state
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write informer state: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("failed to replace informer state: %w", err)
+	}
+	t.dirty = false
+	return nil
+}
+
+// Close writes the state a last time when the plugin stops
+func (t *Tracker) Close() {
+	if t == nil {
+		return
+	}
+	t.saveAndLog()
+}
+
+// saveAndLog saves the state, a lost state only costs a full resync on the next restart
+func (t *Tracker) saveAndLog() {
+	if err := t.Save(); err != nil {
+		t.log.Warn("Failed to save informer state", logger.Fields{
+			"path":  t.path,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (t *Tracker) load() error {
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read informer state: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.previous); err != nil {
+		return fmt.Errorf("failed to parse informer state: %w", err)
+	}
+	return nil
+}
+
+// record stores the version of uid; callers hold t.mu
+func (t *Tracker) record(uid, version string) {
+	if t.current[uid] != version {
+		t.current[uid] = version
+		t.dirty = true
+	}
+}
+
+func key(obj any) (string, string, bool) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil || accessor.GetUID() == "" {
+		return "", "", false
+	}
+	return string(accessor.GetUID()), accessor.GetResourceVersion(), true
+}
+
+type trackingHandler struct {
+	tracker *Tracker
+	handler cache.ResourceEventHandler
+}
+
+func (h *trackingHandler) OnAdd(obj any, isInInitialList bool) {
+	if isInInitialList && h.tracker.Unchanged(obj) {
+		return
+	}
+	h.tracker.Processed(obj)
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h *trackingHandler) OnUpdate(oldObj, newObj any) {
+	h.tracker.Processed(newObj)
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h *trackingHandler) OnDelete(obj any) {
+	h.tracker.Forget(obj)
+	h.handler.OnDelete(obj)
+}
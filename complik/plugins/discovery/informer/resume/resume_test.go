@@ -0,0 +1,140 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestResume(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Informer Resume Suite")
+}
+
+func service(uid, version string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:            uid,
+		Namespace:       "ns-test",
+		UID:             types.UID(uid),
+		ResourceVersion: version,
+	}}
+}
+
+var _ = Describe("Tracker", func() {
+	var (
+		dir   string
+		added []string
+		inner cache.ResourceEventHandler
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		added = nil
+		inner = cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj any) { added = append(added, obj.(*corev1.Service).Name) },
+		}
+	})
+
+	// restart simulates a run of the plugin listing objs and stopping
+	restart := func(fullResync bool, objs ...*corev1.Service) *Tracker {
+		tracker := NewTracker(dir, "test", fullResync, logger.GetLogger())
+		handler := tracker.Wrap(inner)
+		for _, obj := range objs {
+			handler.OnAdd(obj, true)
+		}
+		stop := make(chan struct{})
+		close(stop)
+		tracker.Run(stop)
+		return tracker
+	}
+
+	It("is disabled without a state directory", func() {
+		tracker := NewTracker("", "test", false, logger.GetLogger())
+		Expect(tracker).To(BeNil())
+		tracker.Wrap(inner).OnAdd(service("a", "1"), true)
+		Expect(added).To(Equal([]string{"a"}))
+		Expect(tracker.Save()).To(Succeed())
+	})
+
+	It("drops the initial adds of objects unchanged since the last run", func() {
+		restart(false, service("a", "1"), service("b", "1"))
+		Expect(added).To(Equal([]string{"a", "b"}))
+
+		added = nil
+		tracker := restart(false, service("a", "1"), service("b", "2"), service("c", "1"))
+		Expect(added).To(Equal([]string{"b", "c"}))
+		Expect(tracker.Skipped()).To(Equal(1))
+	})
+
+	It("passes on adds after the initial list", func() {
+		restart(false, service("a", "1"))
+
+		added = nil
+		tracker := NewTracker(dir, "test", false, logger.GetLogger())
+		handler := tracker.Wrap(inner)
+		handler.OnAdd(service("a", "1"), false)
+		Expect(added).To(Equal([]string{"a"}))
+	})
+
+	It("forgets deleted objects", func() {
+		tracker := NewTracker(dir, "test", false, logger.GetLogger())
+		handler := tracker.Wrap(inner)
+		handler.OnAdd(service("a", "1"), true)
+		handler.OnAdd(service("b", "1"), true)
+		handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns-test/b", Obj: service("b", "1")})
+		stop := make(chan struct{})
+		close(stop)
+		tracker.Run(stop)
+
+		added = nil
+		restart(false, service("a", "1"), service("b", "1"))
+		Expect(added).To(Equal([]string{"b"}))
+	})
+
+	It("processes every object again when a full resync is requested", func() {
+		restart(false, service("a", "1"))
+
+		added = nil
+		restart(true, service("a", "1"))
+		Expect(added).To(Equal([]string{"a"}))
+
+		added = nil
+		restart(false, service("a", "1"))
+		Expect(added).To(BeEmpty())
+	})
+
+	It("resyncs everything when the state cannot be parsed", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "test.json"), []byte("{"), 0o600)).To(Succeed())
+		restart(false, service("a", "1"))
+		Expect(added).To(Equal([]string{"a"}))
+	})
+
+	It("does not write the state before the caches synced", func() {
+		tracker := NewTracker(dir, "test", false, logger.GetLogger())
+		tracker.Wrap(inner).OnAdd(service("a", "1"), true)
+		Expect(tracker.Save()).To(Succeed())
+		Expect(filepath.Join(dir, "test.json")).NotTo(BeAnExistingFile())
+	})
+})
@@ -31,6 +31,8 @@ import (
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/coalesce"
+	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/informer/resume"
 	"github.com/bearslyricattack/CompliK/complik/plugins/discovery/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,7 +52,7 @@ const (
 func init() {
 	plugin.PluginFactories[statefulsetPluginName] = func() plugin.Plugin {
 		return &StatefulSetPlugin{
-			log: logger.GetLogger().WithField("plugin", statefulsetPluginName),
+			log: logger.Sampled(logger.GetLogger().WithField(logger.PluginField, statefulsetPluginName)),
 		}
 	}
 }
@@ -59,19 +61,25 @@ type StatefulSetPlugin struct {
 	log                 logger.Logger
 	stopChan            chan struct{}
 	eventBus            *eventbus.EventBus
+	events              *coalesce.Coalescer
+	resume              *resume.Tracker
 	factory             informers.SharedInformerFactory
 	statefulsetInformer cache.SharedIndexInformer
 	statefulSetConfig   StatefulSetConfig
 }
 type StatefulSetConfig struct {
-	ResyncTimeSecond   int `json:"resyncTimeSecond"`
-	AgeThresholdSecond int `json:"ageThresholdSecond"`
+	ResyncTimeSecond   int    `json:"resyncTimeSecond"`
+	AgeThresholdSecond int    `json:"ageThresholdSecond"`
+	DedupWindowSecond  int    `json:"dedupWindowSecond"`
+	ResumeStateDir     string `json:"resumeStateDir"`
+	FullResync         bool   `json:"fullResync"`
 }
 
 func (p *StatefulSetPlugin) getDefaultStatefulSetConfig() StatefulSetConfig {
 	return StatefulSetConfig{
 		ResyncTimeSecond:   5,
 		AgeThresholdSecond: 180,
+		DedupWindowSecond:  coalesce.DefaultWindowSecond,
 	}
 }
 
@@ -95,6 +103,11 @@ func (p *StatefulSetPlugin) loadConfig(setting string) error {
 	if configFromJSON.AgeThresholdSecond > 0 {
 		p.statefulSetConfig.AgeThresholdSecond = configFromJSON.AgeThresholdSecond
 	}
+	if configFromJSON.DedupWindowSecond != 0 {
+		p.statefulSetConfig.DedupWindowSecond = configFromJSON.DedupWindowSecond
+	}
+	p.statefulSetConfig.ResumeStateDir = configFromJSON.ResumeStateDir
+	p.statefulSetConfig.FullResync = configFromJSON.FullResync
 	return nil
 }
 
@@ -124,6 +137,8 @@ func (p *StatefulSetPlugin) Start(
 	}
 	p.stopChan = make(chan struct{})
 	p.eventBus = eventBus
+	p.events = coalesce.NewCoalescer(coalesce.Window(p.statefulSetConfig.DedupWindowSecond), coalesce.Publisher(eventBus))
+	p.resume = resume.NewTracker(p.statefulSetConfig.ResumeStateDir, p.Name(), p.statefulSetConfig.FullResync, p.log)
 	go p.startStatefulSetInformerWatch(ctx)
 	return nil
 }
@@ -138,7 +153,7 @@ func (p *StatefulSetPlugin) startStatefulSetInformerWatch(ctx context.Context) {
 	if p.statefulsetInformer == nil {
 		p.statefulsetInformer = p.factory.Apps().V1().StatefulSets().Informer()
 	}
-	_, err := p.statefulsetInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, err := p.statefulsetInformer.AddEventHandler(p.resume.Wrap(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			statefulset, ok := obj.(*appsv1.StatefulSet)
 			if !ok {
@@ -191,7 +206,7 @@ func (p *StatefulSetPlugin) startStatefulSetInformerWatch(ctx context.Context) {
 				}
 			}
 		},
-	})
+	}))
 	if err != nil {
 		return
 	}
@@ -201,6 +216,7 @@ func (p *StatefulSetPlugin) startStatefulSetInformerWatch(ctx context.Context) {
 		p.log.Error("Failed to wait for StatefulSet caches to sync")
 		return
 	}
+	go p.resume.Run(p.stopChan)
 	p.log.Info("StatefulSet informer watcher started successfully")
 	select {
 	case <-ctx.Done():
@@ -211,6 +227,10 @@ func (p *StatefulSetPlugin) startStatefulSetInformerWatch(ctx context.Context) {
 }
 
 func (p *StatefulSetPlugin) Stop(ctx context.Context) error {
+	if p.events != nil {
+		p.events.Close()
+	}
+	p.resume.Close()
 	if p.stopChan != nil {
 		close(p.stopChan)
 	}
@@ -268,9 +288,7 @@ func extractImagesFromStatefulSet(statefulset *appsv1.StatefulSet) []string {
 
 func (p *StatefulSetPlugin) handleStatefulSetEvent(discoveryInfo []models.DiscoveryInfo) {
 	for _, info := range discoveryInfo {
-		p.eventBus.Publish(constants.DiscoveryTopic, eventbus.Event{
-			Payload: info,
-		})
+		p.events.PushDiscovery(info)
 	}
 }
 
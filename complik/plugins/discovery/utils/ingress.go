@@ -27,6 +27,7 @@ func GenerateDiscoveryInfo(
 	discoveryName string,
 ) []models.DiscoveryInfo {
 	var discoveryList []models.DiscoveryInfo
+	scanOverrides := ScanOverrides(ing.ObjectMeta)
 	for _, rule := range ing.Spec.Rules {
 		host := "*"
 		if rule.Host != "" {
@@ -34,10 +35,7 @@ func GenerateDiscoveryInfo(
 		}
 		if rule.HTTP != nil {
 			for _, path := range rule.HTTP.Paths {
-				serviceName := ""
-				if path.Backend.Service != nil {
-					serviceName = path.Backend.Service.Name
-				}
+				serviceName, servicePort := backendService(path.Backend)
 				pathPattern := "/"
 				if path.Path != "" {
 					pathPattern = path.Path
@@ -51,8 +49,10 @@ func GenerateDiscoveryInfo(
 						pathPattern,
 					},
 					ServiceName:   serviceName,
+					ServicePort:   servicePort,
 					HasActivePods: hasActivePod,
 					PodCount:      podCount,
+					Overrides:     scanOverrides,
 				}
 				discoveryList = append(discoveryList, discoveryInfo)
 			}
@@ -67,6 +67,7 @@ func GenerateIngressAndPodInfo(
 	discoveryName string,
 ) []models.DiscoveryInfo {
 	var discoveryList []models.DiscoveryInfo
+	scanOverrides := ScanOverrides(ing.ObjectMeta)
 	for _, rule := range ing.Spec.Rules {
 		host := "*"
 		if rule.Host != "" {
@@ -74,10 +75,7 @@ func GenerateIngressAndPodInfo(
 		}
 		if rule.HTTP != nil {
 			for _, path := range rule.HTTP.Paths {
-				serviceName := ""
-				if path.Backend.Service != nil {
-					serviceName = path.Backend.Service.Name
-				}
+				serviceName, servicePort := backendService(path.Backend)
 				pathPattern := "/"
 				if path.Path != "" {
 					pathPattern = path.Path
@@ -96,8 +94,10 @@ func GenerateIngressAndPodInfo(
 						pathPattern,
 					},
 					ServiceName:   serviceName,
+					ServicePort:   servicePort,
 					HasActivePods: hasActivePod,
 					PodCount:      podCount,
+					Overrides:     scanOverrides,
 				}
 				discoveryList = append(discoveryList, discoveryInfo)
 			}
@@ -106,6 +106,15 @@ func GenerateIngressAndPodInfo(
 	return discoveryList
 }
 
+// backendService returns the service of an ingress backend and its port
+// number, 0 when the port is referenced by name
+func backendService(backend networkingv1.IngressBackend) (string, int) {
+	if backend.Service == nil {
+		return "", 0
+	}
+	return backend.Service.Name, int(backend.Service.Port.Number)
+}
+
 func getInfoFromEndpointSlices(
 	endpointSlicesMap map[string]map[string][]*discoveryv1.EndpointSlice,
 	namespace, serviceName string,
@@ -0,0 +1,36 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/overrides"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScanOverrides returns the scan overrides set by the annotations of an
+// Ingress or Service, invalid values are logged and ignored
+func ScanOverrides(meta metav1.ObjectMeta) *models.ScanOverrides {
+	scanOverrides, err := overrides.FromAnnotations(meta.Namespace, meta.Annotations)
+	if err != nil {
+		logger.GetLogger().Debug("Ignoring invalid scan override annotations", logger.Fields{
+			"namespace": meta.Namespace,
+			"name":      meta.Name,
+			"error":     err.Error(),
+		})
+	}
+	return scanOverrides
+}
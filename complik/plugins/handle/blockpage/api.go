@@ -0,0 +1,98 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpage
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// kinds maps the kinds of the API paths to route kinds
+var kinds = map[string]string{
+	"ingress":   KindIngress,
+	"httproute": KindHTTPRoute,
+}
+
+// API serves the blocked routes:
+//
+//	GET    /api/v1/blocks
+//	DELETE /api/v1/blocks/{kind}/{namespace}/{name}
+type API struct {
+	log     logger.Logger
+	blocker *Blocker
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewAPI(log logger.Logger, token string, blocker *Blocker) *API {
+	api := &API{log: log, blocker: blocker, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/blocks", api.list)
+	api.mux.HandleFunc("DELETE /api/v1/blocks/{kind}/{namespace}/{name}", api.restore)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) list(w http.ResponseWriter, r *http.Request) {
+	blocks, err := a.blocker.List(r.Context())
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, blocks)
+}
+
+func (a *API) restore(w http.ResponseWriter, r *http.Request) {
+	kind, ok := kinds[strings.ToLower(r.PathValue("kind"))]
+	if !ok {
+		httpapi.WriteError(w, http.StatusBadRequest, ErrUnknownKind.Error())
+		return
+	}
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+	if err := a.blocker.Restore(r.Context(), kind, namespace, name); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Warn("Blocked page removed", logger.Fields{
+		"kind":      kind,
+		"namespace": namespace,
+		"name":      name,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotBlocked):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrUnknownKind):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+	case apierrors.IsNotFound(err):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+	default:
+		a.log.Error("Blocked page request failed", logger.Fields{
+			"error": err.Error(),
+		})
+		httpapi.WriteError(w, http.StatusBadGateway, err.Error())
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Kinds of the blocked routes
+const (
+	KindIngress   = "Ingress"
+	KindHTTPRoute = "HTTPRoute"
+)
+
+// Label and annotations recording a block on a route
+const (
+	BlockedLabel        = "clawcloud.run/blocked-page"
+	BlockedAtAnnotation = "core.clawcloud.run/blocked-page-at"
+	ReasonAnnotation    = "core.clawcloud.run/blocked-page-reason"
+	// OriginalAnnotation holds what the block replaced: the previous values
+	// of the annotations of an Ingress or the rules of an HTTPRoute
+	OriginalAnnotation = "core.clawcloud.run/original-routing"
+)
+
+// maxReasonLength keeps the reason annotation readable
+const maxReasonLength = 256
+
+var (
+	// ErrNotBlocked is returned when restoring a route that is not blocked
+	ErrNotBlocked = errors.New("route is not blocked")
+	// ErrUnknownKind is returned for kinds other than Ingress and HTTPRoute
+	ErrUnknownKind = errors.New("unknown route kind")
+)
+
+// httpRoutes is the Gateway API resource patched besides Ingresses
+var httpRoutes = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+// Block is a route serving the blocked page
+type Block struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Hosts     []string  `json:"hosts"`
+	BlockedAt time.Time `json:"blockedAt"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Backend is the Service serving the blocked page to HTTPRoutes
+type Backend struct {
+	Namespace string
+	Name      string
+	Port      int
+}
+
+// ParseBackend parses "namespace/service:port"
+func ParseBackend(value string) (Backend, error) {
+	namespace, rest, ok := strings.Cut(value, "/")
+	name, port, hasPort := strings.Cut(rest, ":")
+	number, err := strconv.Atoi(port)
+	if !ok || !hasPort || namespace == "" || name == "" || err != nil || number <= 0 || number > 65535 {
+		return Backend{}, fmt.Errorf("invalid backend %q, expected namespace/service:port", value)
+	}
+	return Backend{Namespace: namespace, Name: name, Port: number}, nil
+}
+
+// Blocker makes the routes of a host serve the blocked page and restores them
+type Blocker struct {
+	client      kubernetes.Interface
+	dynamic     dynamic.Interface
+	annotations map[string]string
+	backend     *Backend
+	now         func() time.Time
+}
+
+// NewBlocker returns a Blocker stamping annotations on Ingresses. HTTPRoutes
+// are pointed to backend; they are left alone when backend or dyn is nil.
+func NewBlocker(
+	client kubernetes.Interface,
+	dyn dynamic.Interface,
+	annotations map[string]string,
+	backend *Backend,
+) *Blocker {
+	return &Blocker{
+		client:      client,
+		dynamic:     dyn,
+		annotations: annotations,
+		backend:     backend,
+		now:         time.Now,
+	}
+}
+
+// Block makes the routes of host in namespace serve the blocked page and
+// returns the routes it blocked. Routes that are already blocked are left as
+// they are.
+func (b *Blocker) Block(ctx context.Context, namespace, host, reason string) ([]Block, error) {
+	host = normalizeHost(host)
+	if len(reason) > maxReasonLength {
+		reason = reason[:maxReasonLength]
+	}
+	var blocked []Block
+	ingresses, err := b.client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingresses.Items {
+		if !matchesHost(ingressHosts(&ingress), host) || ingress.Labels[BlockedLabel] == "true" {
+			continue
+		}
+		block, err := b.blockIngress(ctx, namespace, ingress.Name, reason)
+		if err != nil {
+			return blocked, err
+		}
+		blocked = append(blocked, block)
+	}
+	if b.dynamic == nil || b.backend == nil {
+		return blocked, nil
+	}
+	routes, err := b.dynamic.Resource(httpRoutes).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return blocked, fmt.Errorf("failed to list HTTPRoutes: %w", err)
+	}
+	for _, route := range routes.Items {
+		if !matchesHost(routeHosts(&route), host) || route.GetLabels()[BlockedLabel] == "true" {
+			continue
+		}
+		block, err := b.blockRoute(ctx, namespace, route.GetName(), reason)
+		if err != nil {
+			return blocked, err
+		}
+		blocked = append(blocked, block)
+	}
+	return blocked, nil
+}
+
+func (b *Blocker) blockIngress(ctx context.Context, namespace, name, reason string) (Block, error) {
+	var block Block
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ingress, err := b.client.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		original := make(map[string]*string, len(b.annotations))
+		for key := range b.annotations {
+			if value, ok := ingress.Annotations[key]; ok {
+				original[key] = &value
+			} else {
+				original[key] = nil
+			}
+		}
+		data, err := json.Marshal(original)
+		if err != nil {
+			return err
+		}
+		if ingress.Annotations == nil {
+			ingress.Annotations = make(map[string]string)
+		}
+		for key, value := range b.annotations {
+			ingress.Annotations[key] = value
+		}
+		blockedAt := b.mark(&ingress.ObjectMeta, string(data), reason)
+		if _, err := b.client.NetworkingV1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		block = Block{Kind: KindIngress, Namespace: namespace, Name: name, Hosts: ingressHosts(ingress), BlockedAt: blockedAt, Reason: reason}
+		return nil
+	})
+	if err != nil {
+		return block, fmt.Errorf("failed to block ingress %s/%s: %w", namespace, name, err)
+	}
+	return block, nil
+}
+
+func (b *Blocker) blockRoute(ctx context.Context, namespace, name, reason string) (Block, error) {
+	var block Block
+	routes := b.dynamic.Resource(httpRoutes).Namespace(namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		route, err := routes.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(rules)
+		if err != nil {
+			return err
+		}
+		// Every request of the route is sent to the blocked page
+		blockedRules := []any{map[string]any{
+			"backendRefs": []any{map[string]any{
+				"group":     "",
+				"kind":      "Service",
+				"namespace": b.backend.Namespace,
+				"name":      b.backend.Name,
+				"port":      int64(b.backend.Port),
+			}},
+		}}
+		if err := unstructured.SetNestedSlice(route.Object, blockedRules, "spec", "rules"); err != nil {
+			return err
+		}
+		meta := metav1.ObjectMeta{Labels: route.GetLabels(), Annotations: route.GetAnnotations()}
+		blockedAt := b.mark(&meta, string(data), reason)
+		route.SetLabels(meta.Labels)
+		route.SetAnnotations(meta.Annotations)
+		if _, err := routes.Update(ctx, route, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		block = Block{Kind: KindHTTPRoute, Namespace: namespace, Name: name, Hosts: routeHosts(route), BlockedAt: blockedAt, Reason: reason}
+		return nil
+	})
+	if err != nil {
+		return block, fmt.Errorf("failed to block HTTPRoute %s/%s: %w", namespace, name, err)
+	}
+	return block, nil
+}
+
+// mark records the block on the metadata of a route
+func (b *Blocker) mark(meta *metav1.ObjectMeta, original, reason string) time.Time {
+	blockedAt := b.now().UTC().Truncate(time.Second)
+	if meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Labels[BlockedLabel] = "true"
+	meta.Annotations[BlockedAtAnnotation] = blockedAt.Format(time.RFC3339)
+	meta.Annotations[OriginalAnnotation] = original
+	if reason != "" {
+		meta.Annotations[ReasonAnnotation] = reason
+	}
+	return blockedAt
+}
+
+// unmark removes the block from the metadata of a route and returns what the
+// block replaced
+func unmark(meta *metav1.ObjectMeta) (string, error) {
+	if meta.Labels[BlockedLabel] != "true" {
+		return "", ErrNotBlocked
+	}
+	original := meta.Annotations[OriginalAnnotation]
+	delete(meta.Labels, BlockedLabel)
+	for _, key := range []string{BlockedAtAnnotation, ReasonAnnotation, OriginalAnnotation} {
+		delete(meta.Annotations, key)
+	}
+	return original, nil
+}
+
+// Restore gives a blocked route back its own annotations or rules
+func (b *Blocker) Restore(ctx context.Context, kind, namespace, name string) error {
+	switch kind {
+	case KindIngress:
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			ingress, err := b.client.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			data, err := unmark(&ingress.ObjectMeta)
+			if err != nil {
+				return err
+			}
+			var original map[string]*string
+			if err := json.Unmarshal([]byte(data), &original); err != nil {
+				return fmt.Errorf("invalid %s annotation: %w", OriginalAnnotation, err)
+			}
+			for key, value := range original {
+				if value == nil {
+					delete(ingress.Annotations, key)
+				} else {
+					ingress.Annotations[key] = *value
+				}
+			}
+			_, err = b.client.NetworkingV1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+			return err
+		})
+	case KindHTTPRoute:
+		if b.dynamic == nil {
+			return ErrUnknownKind
+		}
+		routes := b.dynamic.Resource(httpRoutes).Namespace(namespace)
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			route, err := routes.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			meta := metav1.ObjectMeta{Labels: route.GetLabels(), Annotations: route.GetAnnotations()}
+			data, err := unmark(&meta)
+			if err != nil {
+				return err
+			}
+			// The apimachinery decoder keeps integers such as ports as int64
+			var rules []any
+			if err := utiljson.Unmarshal([]byte(data), &rules); err != nil {
+				return fmt.Errorf("invalid %s annotation: %w", OriginalAnnotation, err)
+			}
+			if err := unstructured.SetNestedSlice(route.Object, rules, "spec", "rules"); err != nil {
+				return err
+			}
+			route.SetLabels(meta.Labels)
+			route.SetAnnotations(meta.Annotations)
+			_, err = routes.Update(ctx, route, metav1.UpdateOptions{})
+			return err
+		})
+	default:
+		return ErrUnknownKind
+	}
+}
+
+// List returns the blocked routes of all namespaces
+func (b *Blocker) List(ctx context.Context) ([]Block, error) {
+	selector := metav1.ListOptions{LabelSelector: BlockedLabel + "=true"}
+	ingresses, err := b.client.NetworkingV1().Ingresses(metav1.NamespaceAll).List(ctx, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	blocks := make([]Block, 0, len(ingresses.Items))
+	for _, ingress := range ingresses.Items {
+		blocks = append(blocks, describe(KindIngress, &ingress.ObjectMeta, ingressHosts(&ingress)))
+	}
+	if b.dynamic == nil || b.backend == nil {
+		return blocks, nil
+	}
+	routes, err := b.dynamic.Resource(httpRoutes).Namespace(metav1.NamespaceAll).List(ctx, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HTTPRoutes: %w", err)
+	}
+	for _, route := range routes.Items {
+		meta := metav1.ObjectMeta{Namespace: route.GetNamespace(), Name: route.GetName(), Annotations: route.GetAnnotations()}
+		blocks = append(blocks, describe(KindHTTPRoute, &meta, routeHosts(&route)))
+	}
+	return blocks, nil
+}
+
+func describe(kind string, meta *metav1.ObjectMeta, hosts []string) Block {
+	blockedAt, _ := time.Parse(time.RFC3339, meta.Annotations[BlockedAtAnnotation])
+	return Block{
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Hosts:     hosts,
+		BlockedAt: blockedAt,
+		Reason:    meta.Annotations[ReasonAnnotation],
+	}
+}
+
+func ingressHosts(ingress *networkingv1.Ingress) []string {
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	return hosts
+}
+
+func routeHosts(route *unstructured.Unstructured) []string {
+	hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	return hosts
+}
+
+// matchesHost reports whether one of hosts, which may be "*." wildcards,
+// serves host
+func matchesHost(hosts []string, host string) bool {
+	for _, pattern := range hosts {
+		pattern = strings.ToLower(pattern)
+		if pattern == host || (strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost strips the scheme, path and port of a detected host
+func normalizeHost(host string) string {
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.ToLower(host)
+}
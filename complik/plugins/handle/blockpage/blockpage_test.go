@@ -0,0 +1,231 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBlockPage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Block Page Handler Suite")
+}
+
+const snippet = "nginx.ingress.kubernetes.io/configuration-snippet"
+
+func ingress(namespace, name string, annotations map[string]string, hosts ...string) *networkingv1.Ingress {
+	ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations}}
+	for _, host := range hosts {
+		ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{Host: host})
+	}
+	return ing
+}
+
+func httpRoute(namespace, name string, hosts ...any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]any{"namespace": namespace, "name": name},
+		"spec": map[string]any{
+			"hostnames": hosts,
+			"rules": []any{map[string]any{
+				"backendRefs": []any{map[string]any{"name": "shop", "port": int64(80)}},
+			}},
+		},
+	}}
+}
+
+var _ = Describe("Block page", func() {
+	ctx := context.Background()
+	var (
+		client  *fake.Clientset
+		blocker *Blocker
+		routes  *dynamicfake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		client = fake.NewClientset(
+			ingress("ns-alice", "shop", map[string]string{snippet: "more_set_headers \"X-Shop: 1\";", "keep": "me"}, "casino.example.com"),
+			ingress("ns-alice", "blog", nil, "blog.example.com"),
+		)
+		routes = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{httpRoutes: "HTTPRouteList"},
+			httpRoute("ns-alice", "gateway", "*.example.com"),
+		)
+		p := &BlockPagePlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"gatewayBackend":"complik/blocked-page:8080"}`)).To(Succeed())
+		backend, _ := ParseBackend(p.blockPageConfig.GatewayBackend)
+		blocker = NewBlocker(client, routes, p.blockPageConfig.renderAnnotations(), &backend)
+		blocker.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+	})
+
+	getIngress := func(name string) *networkingv1.Ingress {
+		ing, err := client.NetworkingV1().Ingresses("ns-alice").Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return ing
+	}
+	getRoute := func() *unstructured.Unstructured {
+		route, err := routes.Resource(httpRoutes).Namespace("ns-alice").Get(ctx, "gateway", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return route
+	}
+
+	It("should render the page into the annotations", func() {
+		config := BlockPageConfig{StatusCode: 451, Page: "<p>It's\nblocked</p>", Annotations: defaultAnnotations}
+		Expect(config.renderAnnotations()).To(HaveKeyWithValue(snippet, "default_type text/html;\nreturn 451 '<p>It\\'s blocked</p>';"))
+	})
+
+	It("should block the routes of the host and restore them", func() {
+		blocked, err := blocker.Block(ctx, "ns-alice", "https://Casino.example.com:443/pay", "safety detected a violation")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(HaveLen(2))
+		Expect(blocked[0]).To(MatchFields(IgnoreExtras, Fields{"Kind": Equal(KindIngress), "Name": Equal("shop")}))
+		Expect(blocked[1]).To(MatchFields(IgnoreExtras, Fields{"Kind": Equal(KindHTTPRoute), "Name": Equal("gateway")}))
+
+		shop := getIngress("shop")
+		Expect(shop.Labels).To(HaveKeyWithValue(BlockedLabel, "true"))
+		Expect(shop.Annotations[snippet]).To(HavePrefix("default_type text/html;\nreturn 451 '<html>"))
+		Expect(shop.Annotations).To(HaveKeyWithValue(BlockedAtAnnotation, "2025-06-01T12:00:00Z"))
+		Expect(shop.Annotations).To(HaveKeyWithValue(ReasonAnnotation, "safety detected a violation"))
+		Expect(getIngress("blog").Labels).NotTo(HaveKey(BlockedLabel))
+
+		rules, _, _ := unstructured.NestedSlice(getRoute().Object, "spec", "rules")
+		Expect(rules).To(Equal([]any{map[string]any{"backendRefs": []any{map[string]any{
+			"group": "", "kind": "Service", "namespace": "complik", "name": "blocked-page", "port": int64(8080),
+		}}}}))
+
+		// Blocked routes are left as they are
+		blocked, err = blocker.Block(ctx, "ns-alice", "casino.example.com", "again")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeEmpty())
+
+		list, err := blocker.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(2))
+		Expect(list[0].BlockedAt).To(Equal(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+
+		Expect(blocker.Restore(ctx, KindIngress, "ns-alice", "shop")).To(Succeed())
+		shop = getIngress("shop")
+		Expect(shop.Annotations).To(Equal(map[string]string{snippet: "more_set_headers \"X-Shop: 1\";", "keep": "me"}))
+		Expect(shop.Labels).NotTo(HaveKey(BlockedLabel))
+
+		Expect(blocker.Restore(ctx, KindHTTPRoute, "ns-alice", "gateway")).To(Succeed())
+		rules, _, _ = unstructured.NestedSlice(getRoute().Object, "spec", "rules")
+		Expect(rules).To(Equal([]any{map[string]any{
+			"backendRefs": []any{map[string]any{"name": "shop", "port": int64(80)}},
+		}}))
+		Expect(getRoute().GetAnnotations()).NotTo(HaveKey(OriginalAnnotation))
+
+		Expect(blocker.Restore(ctx, KindIngress, "ns-alice", "shop")).To(MatchError(ErrNotBlocked))
+	})
+
+	It("should only block confirmed violations of the configured severity", func() {
+		p := &BlockPagePlugin{log: logger.GetLogger(), blocker: blocker}
+		Expect(p.loadConfig(`{"minSeverity":"critical"}`)).To(Succeed())
+		result := &models.DetectorInfo{
+			DetectorName: "safety",
+			Namespace:    "ns-alice",
+			Host:         "blog.example.com",
+			IsIllegal:    true,
+			Severity:     models.SeverityHigh,
+			Keywords:     []string{"casino"},
+		}
+		Expect(p.handle(ctx, result)).To(Succeed())
+		Expect(getIngress("blog").Labels).NotTo(HaveKey(BlockedLabel))
+
+		result.Severity = models.SeverityCritical
+		Expect(p.handle(ctx, result)).To(Succeed())
+		Expect(getIngress("blog").Annotations).To(HaveKeyWithValue(ReasonAnnotation, "safety detected a violation: casino"))
+	})
+
+	It("should validate the configuration", func() {
+		p := &BlockPagePlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig("")).To(Succeed())
+		Expect(p.blockPageConfig.StatusCode).To(Equal(http.StatusUnavailableForLegalReasons))
+		Expect(p.loadConfig(`{"statusCode":200}`)).To(MatchError(ContainSubstring("statusCode")))
+		Expect(p.loadConfig(`{"gatewayBackend":"blocked-page"}`)).To(MatchError(ContainSubstring("namespace/service:port")))
+		Expect(p.loadConfig(`{"annotations":{}}`)).To(MatchError(ContainSubstring("required")))
+		Expect(p.loadConfig(`{"apiAddr":":8094"}`)).To(MatchError(ContainSubstring("apiToken")))
+		Expect(p.loadConfig(`{"apiAddr":":8094","apiToken":"secret"}`)).To(Succeed())
+	})
+
+	It("should serve the blocks", func() {
+		api := NewAPI(logger.GetLogger(), "secret", blocker)
+		_, err := blocker.Block(ctx, "ns-alice", "blog.example.com", "")
+		Expect(err).NotTo(HaveOccurred())
+		call := func(method, path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := call(http.MethodGet, "/api/v1/blocks")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var blocks []Block
+		Expect(json.Unmarshal(rec.Body.Bytes(), &blocks)).To(Succeed())
+		Expect(blocks).To(ConsistOf(
+			MatchFields(IgnoreExtras, Fields{"Kind": Equal(KindIngress), "Hosts": Equal([]string{"blog.example.com"})}),
+			MatchFields(IgnoreExtras, Fields{"Kind": Equal(KindHTTPRoute), "Hosts": Equal([]string{"*.example.com"})}),
+		))
+
+		Expect(call(http.MethodDelete, "/api/v1/blocks/ingress/ns-alice/blog").Code).To(Equal(http.StatusNoContent))
+		Expect(call(http.MethodDelete, "/api/v1/blocks/ingress/ns-alice/blog").Code).To(Equal(http.StatusConflict))
+		Expect(call(http.MethodDelete, "/api/v1/blocks/ingress/ns-alice/missing").Code).To(Equal(http.StatusNotFound))
+		Expect(call(http.MethodDelete, "/api/v1/blocks/service/ns-alice/blog").Code).To(Equal(http.StatusBadRequest))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blocks", nil)
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should refuse every request without a configured token", func() {
+		_, err := blocker.Block(ctx, "ns-alice", "blog.example.com", "")
+		Expect(err).NotTo(HaveOccurred())
+		api := NewAPI(logger.GetLogger(), "", blocker)
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			path := "/api/v1/blocks"
+			if method == http.MethodDelete {
+				path += "/ingress/ns-alice/blog"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", "Bearer ")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		}
+		Expect(getIngress("blog").Labels).To(HaveKeyWithValue(BlockedLabel, "true"))
+	})
+})
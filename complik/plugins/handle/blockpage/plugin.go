@@ -0,0 +1,299 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package blockpage implements a handler plugin that answers confirmed
+// violations with a "content blocked" page instead of scaling the workload
+// down: the Ingresses of the offending host get annotations making the
+// ingress controller serve the page with HTTP 451, and its Gateway API
+// HTTPRoutes are pointed to a Service serving the page. A block is recorded
+// on the route and can be lifted through a small HTTP API.
+package blockpage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	pluginName = constants.HandleBlockPage
+	pluginType = constants.HandleBlockPagePluginType
+)
+
+// defaultPage is served when no page is configured
+const defaultPage = `<html><head><title>451 Unavailable For Legal Reasons</title></head>` +
+	`<body><h1>Content blocked</h1><p>This site has been blocked for violating the terms of service.</p></body></html>`
+
+// defaultAnnotations make ingress-nginx answer every request with the page
+var defaultAnnotations = map[string]string{
+	"nginx.ingress.kubernetes.io/configuration-snippet": "default_type text/html;\nreturn {{status}} '{{page}}';",
+}
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &BlockPagePlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type BlockPagePlugin struct {
+	log             logger.Logger
+	blockPageConfig BlockPageConfig
+	blocker         *Blocker
+	server          *http.Server
+}
+
+func (p *BlockPagePlugin) Name() string {
+	return pluginName
+}
+
+func (p *BlockPagePlugin) Type() string {
+	return pluginType
+}
+
+type BlockPageConfig struct {
+	MinSeverity string `json:"minSeverity"`
+	StatusCode  int    `json:"statusCode"`
+	// Page is the HTML of the blocked page
+	Page string `json:"page"`
+	// Annotations are set on the Ingresses of a blocked host. {{status}} is
+	// replaced with StatusCode and {{page}} with Page, escaped for a single
+	// quoted nginx string.
+	Annotations map[string]string `json:"annotations"`
+	// GatewayBackend is the "namespace/service:port" serving the page to the
+	// HTTPRoutes of a blocked host, HTTPRoutes are left alone when empty
+	GatewayBackend string `json:"gatewayBackend"`
+
+	// APIAddr serves the API listing and lifting blocks when set
+	APIAddr  string `json:"apiAddr"`
+	APIToken string `json:"apiToken"`
+}
+
+func (p *BlockPagePlugin) getDefaultConfig() BlockPageConfig {
+	return BlockPageConfig{
+		MinSeverity: models.SeverityHigh,
+		StatusCode:  http.StatusUnavailableForLegalReasons,
+		Page:        defaultPage,
+		Annotations: defaultAnnotations,
+	}
+}
+
+func (p *BlockPagePlugin) loadConfig(setting string) error {
+	p.blockPageConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default block page configuration")
+		return nil
+	}
+	var configFromJSON BlockPageConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	c := &p.blockPageConfig
+	if configFromJSON.MinSeverity != "" {
+		if models.SeverityRank(configFromJSON.MinSeverity) == 0 {
+			return fmt.Errorf("unknown severity %q", configFromJSON.MinSeverity)
+		}
+		c.MinSeverity = configFromJSON.MinSeverity
+	}
+	if configFromJSON.StatusCode != 0 {
+		if configFromJSON.StatusCode < 400 || configFromJSON.StatusCode > 599 {
+			return fmt.Errorf("invalid statusCode %d, expected a 4xx or 5xx status", configFromJSON.StatusCode)
+		}
+		c.StatusCode = configFromJSON.StatusCode
+	}
+	if configFromJSON.Page != "" {
+		c.Page = configFromJSON.Page
+	}
+	if configFromJSON.Annotations != nil {
+		c.Annotations = configFromJSON.Annotations
+	}
+	if configFromJSON.GatewayBackend != "" {
+		if _, err := ParseBackend(configFromJSON.GatewayBackend); err != nil {
+			return err
+		}
+		c.GatewayBackend = configFromJSON.GatewayBackend
+	}
+	c.APIAddr = configFromJSON.APIAddr
+	if configFromJSON.APIToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.APIToken); err == nil {
+			c.APIToken = token
+		} else if config.IsSecretReference(configFromJSON.APIToken) {
+			return fmt.Errorf("failed to resolve API token: %w", err)
+		} else {
+			c.APIToken = configFromJSON.APIToken
+		}
+	}
+	// Lifting a block publishes a violating host again, the API is never served unauthenticated
+	if c.APIAddr != "" && c.APIToken == "" {
+		return errors.New("apiToken configuration cannot be empty when apiAddr is set")
+	}
+	if len(c.Annotations) == 0 && c.GatewayBackend == "" {
+		return errors.New("annotations or gatewayBackend configuration is required")
+	}
+	return nil
+}
+
+// renderAnnotations fills the placeholders of the configured annotations
+func (c BlockPageConfig) renderAnnotations() map[string]string {
+	page := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", " ").Replace(c.Page)
+	replacer := strings.NewReplacer("{{status}}", strconv.Itoa(c.StatusCode), "{{page}}", page)
+	annotations := make(map[string]string, len(c.Annotations))
+	for key, value := range c.Annotations {
+		annotations[key] = replacer.Replace(value)
+	}
+	return annotations
+}
+
+func (p *BlockPagePlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	if k8s.ClientSet == nil {
+		return errors.New("kubernetes client is not initialized")
+	}
+	var backend *Backend
+	var dyn dynamic.Interface
+	if p.blockPageConfig.GatewayBackend != "" {
+		parsed, _ := ParseBackend(p.blockPageConfig.GatewayBackend)
+		backend = &parsed
+		dyn = k8s.DynamicClient
+	}
+	p.blocker = NewBlocker(k8s.ClientSet, dyn, p.blockPageConfig.renderAnnotations(), backend)
+	if p.blockPageConfig.APIAddr != "" {
+		p.startAPIServer()
+	}
+	p.log.Info("Block page handler started", logger.Fields{
+		"min_severity":    p.blockPageConfig.MinSeverity,
+		"status_code":     p.blockPageConfig.StatusCode,
+		"annotations":     len(p.blockPageConfig.Annotations),
+		"gateway_backend": p.blockPageConfig.GatewayBackend,
+	})
+
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				result, ok := event.Payload.(*models.DetectorInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.DetectorInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				budgetCtx, cancelBudget := eventBus.Policy().Context(ctx, event.Deadline, eventbus.PhaseHandle)
+				taskCtx, cancel := context.WithTimeout(budgetCtx, 30*time.Second)
+				if err := p.handle(taskCtx, result); err != nil {
+					p.log.Error("Failed to block host", logger.Fields{
+						"namespace": result.Namespace,
+						"host":      result.Host,
+						"error":     err.Error(),
+					})
+				}
+				cancel()
+				cancelBudget()
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// handle blocks the host of result when it is a confirmed violation of at
+// least the configured severity
+func (p *BlockPagePlugin) handle(ctx context.Context, result *models.DetectorInfo) error {
+	severity := routing.EffectiveSeverity(result)
+	if !result.IsIllegal || result.Namespace == "" || result.Host == "" ||
+		models.SeverityRank(severity) < models.SeverityRank(p.blockPageConfig.MinSeverity) {
+		return nil
+	}
+	reason := result.DetectorName + " detected a violation"
+	if len(result.Keywords) > 0 {
+		reason += ": " + strings.Join(result.Keywords, ", ")
+	}
+	blocked, err := p.blocker.Block(ctx, result.Namespace, result.Host, reason)
+	for _, block := range blocked {
+		p.log.Warn("Host blocked with the block page", logger.Fields{
+			"kind":      block.Kind,
+			"namespace": block.Namespace,
+			"name":      block.Name,
+			"host":      result.Host,
+			"severity":  severity,
+		})
+	}
+	return err
+}
+
+// startAPIServer serves the API listing and lifting blocks
+func (p *BlockPagePlugin) startAPIServer() {
+	p.server = &http.Server{
+		Addr:              p.blockPageConfig.APIAddr,
+		Handler:           NewAPI(p.log, p.blockPageConfig.APIToken, p.blocker),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Block page API server started", logger.Fields{
+			"addr": p.blockPageConfig.APIAddr,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Block page API server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+func (p *BlockPagePlugin) Stop(ctx context.Context) error {
+	if p.server != nil {
+		return p.server.Shutdown(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
+)
+
+// labelRequest is the body of a label submission
+type labelRequest struct {
+	Verdict  string `json:"verdict"`
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment"`
+}
+
+// LabelAPI serves the golden dataset labeling endpoints:
+//
+//	GET  /api/v1/records?detector=&namespace=&host=&illegal=&keyword=&from=&to=&unlabeled=true&limit=&offset=
+//	GET  /api/v1/records/{id}
+//	POST /api/v1/records/{id}/label
+//	GET  /api/v1/records/{id}/transcripts
+//	POST /api/v1/records/purge
+//	GET  /api/v1/labels/metrics?detector=
+//	GET  /api/v1/reports/{namespace}?format=html|pdf&from=&to=
+type LabelAPI struct {
+	log     logger.Logger
+	store   *LabelStore
+	mux     *http.ServeMux
+	handler http.Handler
+	// reportOptions are used to render the compliance reports
+	reportOptions report.Options
+	// archiver purges the expired records, nil disables the purge endpoint
+	archiver *RecordArchiver
+}
+
+// purgeRequest is the body of a manual purge. Before is a date or an RFC 3339
+// time; records are archived unless NoArchive is set.
+type purgeRequest struct {
+	Before    string `json:"before"`
+	NoArchive bool   `json:"no_archive"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+func NewLabelAPI(log logger.Logger, token string, store *LabelStore) *LabelAPI {
+	api := &LabelAPI{log: log, store: store, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/records", api.listRecords)
+	api.mux.HandleFunc("GET /api/v1/records/{id}", api.getRecord)
+	api.mux.HandleFunc("POST /api/v1/records/{id}/label", api.labelRecord)
+	api.mux.HandleFunc("GET /api/v1/records/{id}/transcripts", api.transcripts)
+	api.mux.HandleFunc("POST /api/v1/records/purge", api.purge)
+	api.mux.HandleFunc("GET /api/v1/labels/metrics", api.metrics)
+	api.mux.HandleFunc("GET /api/v1/reports/{namespace}", api.report)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *LabelAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *LabelAPI) listRecords(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := ListOptions{
+		Detector:  query.Get("detector"),
+		Namespace: query.Get("namespace"),
+		Host:      query.Get("host"),
+		Keyword:   query.Get("keyword"),
+		Unlabeled: query.Get("unlabeled") == "true",
+	}
+	if value := query.Get("illegal"); value != "" {
+		illegal, err := strconv.ParseBool(value)
+		if err != nil {
+			httpapi.WriteError(w, http.StatusBadRequest, "invalid illegal: "+err.Error())
+			return
+		}
+		opts.IsIllegal = &illegal
+	}
+	var err error
+	if opts.From, err = parseReportTime(query.Get("from"), time.Time{}); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	}
+	if opts.To, err = parseReportTime(query.Get("to"), time.Time{}); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	}
+	opts.Limit, _ = strconv.Atoi(query.Get("limit"))
+	opts.Offset, _ = strconv.Atoi(query.Get("offset"))
+	records, err := a.store.List(opts)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, records)
+}
+
+func (a *LabelAPI) getRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := recordID(w, r)
+	if !ok {
+		return
+	}
+	record, err := a.store.Get(id)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, record)
+}
+
+// transcripts returns the model transcripts of a record, newest first
+func (a *LabelAPI) transcripts(w http.ResponseWriter, r *http.Request) {
+	id, ok := recordID(w, r)
+	if !ok {
+		return
+	}
+	transcripts, err := a.store.Transcripts(id)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, transcripts)
+}
+
+func (a *LabelAPI) labelRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := recordID(w, r)
+	if !ok {
+		return
+	}
+	var req labelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid label payload")
+		return
+	}
+	if req.Reviewer == "" {
+		httpapi.WriteError(w, http.StatusBadRequest, "reviewer is required")
+		return
+	}
+	label, err := a.store.Label(id, req.Verdict, req.Reviewer, req.Comment)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Detector record labeled", logger.Fields{
+		"record_id": id,
+		"verdict":   label.Verdict,
+		"reviewer":  label.Reviewer,
+	})
+	httpapi.WriteJSON(w, http.StatusOK, label)
+}
+
+// purge archives and deletes the records last seen before the requested time
+func (a *LabelAPI) purge(w http.ResponseWriter, r *http.Request) {
+	if a.archiver == nil {
+		httpapi.WriteError(w, http.StatusNotImplemented, "record purge is not enabled")
+		return
+	}
+	var req purgeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid purge payload")
+		return
+	}
+	before, err := parseReportTime(req.Before, time.Time{})
+	if err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid before: "+err.Error())
+		return
+	}
+	if before.IsZero() || before.After(time.Now()) {
+		httpapi.WriteError(w, http.StatusBadRequest, "before must be a time in the past")
+		return
+	}
+	result, err := a.archiver.Purge(r.Context(), before, !req.NoArchive, req.DryRun)
+	switch {
+	case errors.Is(err, ErrArchiveRunning):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, ErrNoArchiveStore):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		a.log.Error("Record purge failed", logger.Fields{
+			"error":    err.Error(),
+			"archived": result.Archived,
+			"purged":   result.Purged,
+		})
+		httpapi.WriteError(w, http.StatusInternalServerError, "purge failed after "+
+			strconv.FormatInt(result.Purged, 10)+" records: "+err.Error())
+		return
+	}
+	a.log.Info("Records purged", logger.Fields{
+		"before":   before.Format(time.RFC3339),
+		"archived": result.Archived,
+		"purged":   result.Purged,
+		"dry_run":  req.DryRun,
+	})
+	httpapi.WriteJSON(w, http.StatusOK, result)
+}
+
+func (a *LabelAPI) metrics(w http.ResponseWriter, r *http.Request) {
+	report, err := a.store.Metrics(r.URL.Query().Get("detector"))
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, report)
+}
+
+// report renders the compliance report of a namespace. from and to are dates
+// (2006-01-02) or RFC 3339 times and default to the last 30 days.
+func (a *LabelAPI) report(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format, err := report.ParseFormat(query.Get("format"))
+	if err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseReportTime(query.Get("to"), time.Now())
+	if err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	}
+	from, err := parseReportTime(query.Get("from"), to.Add(-DefaultReportPeriod))
+	if err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	}
+	if !from.Before(to) {
+		httpapi.WriteError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+	namespace := r.PathValue("namespace")
+	rep, err := a.store.Report(namespace, from, to)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := report.Render(&buf, rep, format, a.reportOptions); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Compliance report generated", logger.Fields{
+		"namespace":  namespace,
+		"format":     format,
+		"violations": rep.Summary.Total,
+	})
+	w.Header().Set("Content-Type", report.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", rep.FileName(format)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+// parseReportTime parses a date or RFC 3339 time, empty values are fallback
+func parseReportTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// fail maps store errors to HTTP status codes
+func (a *LabelAPI) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRecordNotFound):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errInvalidVerdict):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		a.log.Error("Label API request failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func recordID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid record id")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// ErrAppealNotFound is returned for unknown appeal IDs
+	ErrAppealNotFound = errors.New("appeal not found")
+	// ErrAppealDecided is returned when an appeal was already approved or rejected
+	ErrAppealDecided = errors.New("appeal already decided")
+	// ErrAppealPending is returned when the record already has a pending appeal
+	ErrAppealPending = errors.New("record already has a pending appeal")
+)
+
+// errInvalidAppeal wraps every appeal validation error
+var errInvalidAppeal = errors.New("invalid appeal")
+
+// Appeal is the request of a tenant to review a violation record of their
+// namespace, together with the decision of the reviewer
+type Appeal struct {
+	ID        uint       `gorm:"primaryKey"     json:"id"`
+	RecordID  uint       `gorm:"index"          json:"record_id"`
+	Namespace string     `gorm:"size:255;index" json:"namespace"`
+	Contact   string     `gorm:"size:255"       json:"contact,omitempty"`
+	Reason    string     `gorm:"type:text"      json:"reason"`
+	Status    string     `gorm:"size:16;index"  json:"status"`
+	Reviewer  string     `gorm:"size:255"       json:"reviewer,omitempty"`
+	Comment   string     `gorm:"type:text"      json:"comment,omitempty"`
+	Unlocked  bool       `                      json:"unlocked"`
+	Error     string     `gorm:"type:text"      json:"error,omitempty"`
+	CreatedAt time.Time  `                      json:"created_at"`
+	DecidedAt *time.Time `                      json:"decided_at,omitempty"`
+}
+
+func (Appeal) TableName() string {
+	return "appeals"
+}
+
+// AppealToken returns the token the tenant of namespace authenticates with.
+// Tokens are derived from secret, so they can be handed out without storing
+// them.
+func AppealToken(secret, namespace string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(namespace))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Unlocker lifts the lock of a namespace after its appeal was approved
+type Unlocker interface {
+	Unlock(ctx context.Context, appeal *Appeal) error
+}
+
+// blockRequests are the block-controller requests locking and unlocking
+// namespaces
+var blockRequests = schema.GroupVersionResource{
+	Group:    "core.clawcloud.run",
+	Version:  "v1",
+	Resource: "blockrequests",
+}
+
+// BlockRequestUnlocker unlocks namespaces by creating a block-controller
+// BlockRequest with the active action
+type BlockRequestUnlocker struct {
+	client    dynamic.Interface
+	namespace string
+}
+
+// NewBlockRequestUnlocker creates the BlockRequests in namespace, the
+// namespace the block-controller watches
+func NewBlockRequestUnlocker(client dynamic.Interface, namespace string) *BlockRequestUnlocker {
+	return &BlockRequestUnlocker{client: client, namespace: namespace}
+}
+
+// Unlock creates the BlockRequest unlocking the namespace of appeal. The
+// request is named after the appeal, so retrying an unlock is idempotent.
+func (u *BlockRequestUnlocker) Unlock(ctx context.Context, appeal *Appeal) error {
+	request := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": blockRequests.GroupVersion().String(),
+		"kind":       "BlockRequest",
+		"metadata": map[string]any{
+			"name":      fmt.Sprintf("complik-appeal-%d", appeal.ID),
+			"namespace": u.namespace,
+			"labels": map[string]any{
+				"app.kubernetes.io/managed-by": "complik",
+			},
+			"annotations": map[string]any{
+				"core.clawcloud.run/appeal-reviewer": appeal.Reviewer,
+			},
+		},
+		"spec": map[string]any{
+			"namespaceNames": []any{appeal.Namespace},
+			"action":         "active",
+		},
+	}}
+	_, err := u.client.Resource(blockRequests).Namespace(u.namespace).Create(ctx, request, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create unlock request: %w", err)
+	}
+	return nil
+}
+
+// AppealStore stores the appeals next to the detector records
+type AppealStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewAppealStore(db *gorm.DB) *AppealStore {
+	return &AppealStore{db: db, now: time.Now}
+}
+
+// Submit opens an appeal of the tenant of namespace for the violation record
+// recordID. Records of other namespaces are reported as not found.
+func (s *AppealStore) Submit(namespace string, recordID uint, contact, reason string) (*Appeal, *DetectorRecord, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, fmt.Errorf("%w: reason is required", errInvalidAppeal)
+	}
+	var record DetectorRecord
+	if err := s.db.First(&record, recordID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrRecordNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load record: %w", err)
+	}
+	if record.Namespace != namespace {
+		return nil, nil, ErrRecordNotFound
+	}
+	if !record.IsIllegal {
+		return nil, nil, fmt.Errorf("%w: record is not a violation", errInvalidAppeal)
+	}
+	var pending int64
+	err := s.db.Model(&Appeal{}).
+		Where("record_id = ? AND status IN ?", recordID, []string{models.AppealPending, models.AppealFailed}).
+		Count(&pending).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load appeals: %w", err)
+	}
+	if pending > 0 {
+		return nil, nil, ErrAppealPending
+	}
+	appeal := &Appeal{
+		RecordID:  recordID,
+		Namespace: namespace,
+		Contact:   contact,
+		Reason:    reason,
+		Status:    models.AppealPending,
+	}
+	if err := s.db.Create(appeal).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to save appeal: %w", err)
+	}
+	return appeal, &record, nil
+}
+
+// AppealListOptions filters the appeals returned by List
+type AppealListOptions struct {
+	Namespace string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// List returns appeals, newest first
+func (s *AppealStore) List(opts AppealListOptions) ([]Appeal, error) {
+	if opts.Limit <= 0 || opts.Limit > 500 {
+		opts.Limit = 50
+	}
+	query := s.db.Order("id DESC").Limit(opts.Limit).Offset(opts.Offset)
+	if opts.Namespace != "" {
+		query = query.Where("namespace = ?", opts.Namespace)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	var appeals []Appeal
+	if err := query.Find(&appeals).Error; err != nil {
+		return nil, fmt.Errorf("failed to list appeals: %w", err)
+	}
+	return appeals, nil
+}
+
+// Get returns a single appeal
+func (s *AppealStore) Get(id uint) (*Appeal, error) {
+	var appeal Appeal
+	if err := s.db.First(&appeal, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAppealNotFound
+		}
+		return nil, fmt.Errorf("failed to load appeal: %w", err)
+	}
+	return &appeal, nil
+}
+
+// Decide approves or rejects the pending appeal id. The namespace of an
+// approved appeal is unlocked with unlocker unless it is nil; a failed unlock
+// marks the appeal failed, and a failed appeal can be decided again to retry
+// it.
+func (s *AppealStore) Decide(
+	ctx context.Context,
+	unlocker Unlocker,
+	id uint,
+	approve bool,
+	reviewer, comment string,
+) (*Appeal, error) {
+	status := models.AppealApproved
+	if !approve {
+		status = models.AppealRejected
+	}
+	now := s.now()
+	// Claim the appeal so a concurrent decision cannot unlock it twice
+	claim := s.db.Model(&Appeal{}).
+		Where("id = ? AND status IN ?", id, []string{models.AppealPending, models.AppealFailed}).
+		Updates(map[string]any{
+			"status":     status,
+			"reviewer":   reviewer,
+			"comment":    comment,
+			"error":      "",
+			"decided_at": now,
+		})
+	if claim.Error != nil {
+		return nil, fmt.Errorf("failed to decide appeal: %w", claim.Error)
+	}
+	appeal, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.RowsAffected == 0 {
+		return appeal, ErrAppealDecided
+	}
+	if !approve || unlocker == nil {
+		return appeal, nil
+	}
+
+	unlockErr := unlocker.Unlock(ctx, appeal)
+	updates := map[string]any{"unlocked": true}
+	appeal.Unlocked = true
+	if unlockErr != nil {
+		updates = map[string]any{"status": models.AppealFailed, "error": unlockErr.Error()}
+		appeal.Unlocked = false
+		appeal.Status = models.AppealFailed
+		appeal.Error = unlockErr.Error()
+	}
+	if err := s.db.Model(&Appeal{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return appeal, errors.Join(unlockErr, fmt.Errorf("failed to save appeal: %w", err))
+	}
+	return appeal, unlockErr
+}
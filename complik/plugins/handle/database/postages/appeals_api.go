@@ -0,0 +1,251 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// appealRequest is the body of a tenant appeal
+type appealRequest struct {
+	RecordID uint   `json:"record_id"`
+	Contact  string `json:"contact"`
+	Reason   string `json:"reason"`
+}
+
+// decisionRequest is the body of an approve or reject call
+type decisionRequest struct {
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment"`
+}
+
+// AppealAPI serves the tenant appeals. Tenants authenticate with the
+// AppealToken of their namespace, reviewers with the staff token:
+//
+//	POST /api/v1/namespaces/{namespace}/appeals
+//	GET  /api/v1/namespaces/{namespace}/appeals
+//	GET  /api/v1/appeals?namespace=&status=&limit=&offset=
+//	GET  /api/v1/appeals/{id}
+//	POST /api/v1/appeals/{id}/approve
+//	POST /api/v1/appeals/{id}/reject
+type AppealAPI struct {
+	log    logger.Logger
+	secret string
+	token  string
+	store  *AppealStore
+	mux    *http.ServeMux
+	// unlocker unlocks the namespaces of approved appeals when set
+	unlocker Unlocker
+	// publish notifies the handlers of submitted and decided appeals
+	publish func(*models.AppealEvent)
+	region  string
+}
+
+func NewAppealAPI(log logger.Logger, secret, token string, store *AppealStore) *AppealAPI {
+	api := &AppealAPI{
+		log:     log,
+		secret:  secret,
+		token:   token,
+		store:   store,
+		mux:     http.NewServeMux(),
+		publish: func(*models.AppealEvent) {},
+	}
+	api.mux.HandleFunc("POST /api/v1/namespaces/{namespace}/appeals", api.tenant(api.submit))
+	api.mux.HandleFunc("GET /api/v1/namespaces/{namespace}/appeals", api.tenant(api.listOwn))
+	api.mux.HandleFunc("GET /api/v1/appeals", api.staff(api.list))
+	api.mux.HandleFunc("GET /api/v1/appeals/{id}", api.staff(api.get))
+	api.mux.HandleFunc("POST /api/v1/appeals/{id}/approve", api.staff(func(w http.ResponseWriter, r *http.Request) {
+		api.decide(w, r, true)
+	}))
+	api.mux.HandleFunc("POST /api/v1/appeals/{id}/reject", api.staff(func(w http.ResponseWriter, r *http.Request) {
+		api.decide(w, r, false)
+	}))
+	return api
+}
+
+func (a *AppealAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+// tenant only passes requests carrying the appeal token of the namespace
+func (a *AppealAPI) tenant(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected := AppealToken(a.secret, r.PathValue("namespace"))
+		if a.secret == "" || !httpapi.ValidBearer(r, expected) {
+			httpapi.WriteError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// staff only passes requests carrying the staff token, without one every
+// request is refused
+func (a *AppealAPI) staff(next http.HandlerFunc) http.HandlerFunc {
+	return httpapi.RequireBearer(a.token, next).ServeHTTP
+}
+
+func (a *AppealAPI) submit(w http.ResponseWriter, r *http.Request) {
+	var req appealRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid appeal payload")
+		return
+	}
+	if req.RecordID == 0 {
+		httpapi.WriteError(w, http.StatusBadRequest, "record_id is required")
+		return
+	}
+	namespace := r.PathValue("namespace")
+	appeal, record, err := a.store.Submit(namespace, req.RecordID, req.Contact, req.Reason)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Appeal submitted", logger.Fields{
+		"appeal_id": appeal.ID,
+		"record_id": appeal.RecordID,
+		"namespace": namespace,
+	})
+	event := a.event(appeal)
+	event.Host = record.Host
+	event.Detector = record.DetectorName
+	a.publish(event)
+	httpapi.WriteJSON(w, http.StatusCreated, appeal)
+}
+
+func (a *AppealAPI) listOwn(w http.ResponseWriter, r *http.Request) {
+	a.listWith(w, r, r.PathValue("namespace"))
+}
+
+func (a *AppealAPI) list(w http.ResponseWriter, r *http.Request) {
+	a.listWith(w, r, r.URL.Query().Get("namespace"))
+}
+
+func (a *AppealAPI) listWith(w http.ResponseWriter, r *http.Request, namespace string) {
+	query := r.URL.Query()
+	opts := AppealListOptions{Namespace: namespace, Status: query.Get("status")}
+	opts.Limit, _ = strconv.Atoi(query.Get("limit"))
+	opts.Offset, _ = strconv.Atoi(query.Get("offset"))
+	appeals, err := a.store.List(opts)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, appeals)
+}
+
+func (a *AppealAPI) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := appealID(w, r)
+	if !ok {
+		return
+	}
+	appeal, err := a.store.Get(id)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, appeal)
+}
+
+func (a *AppealAPI) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, ok := appealID(w, r)
+	if !ok {
+		return
+	}
+	var req decisionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid decision payload")
+		return
+	}
+	if req.Reviewer == "" {
+		httpapi.WriteError(w, http.StatusBadRequest, "reviewer is required")
+		return
+	}
+	appeal, err := a.store.Decide(r.Context(), a.unlocker, id, approve, req.Reviewer, req.Comment)
+	switch {
+	case errors.Is(err, ErrAppealDecided):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case appeal == nil:
+		a.fail(w, err)
+		return
+	case err != nil:
+		a.log.Error("Failed to unlock the namespace of an approved appeal", logger.Fields{
+			"appeal_id": appeal.ID,
+			"namespace": appeal.Namespace,
+			"error":     err.Error(),
+		})
+		a.publish(a.event(appeal))
+		httpapi.WriteJSON(w, http.StatusBadGateway, appeal)
+		return
+	}
+	a.log.Warn("Appeal decided", logger.Fields{
+		"appeal_id": appeal.ID,
+		"namespace": appeal.Namespace,
+		"status":    appeal.Status,
+		"reviewer":  appeal.Reviewer,
+		"unlocked":  appeal.Unlocked,
+	})
+	a.publish(a.event(appeal))
+	httpapi.WriteJSON(w, http.StatusOK, appeal)
+}
+
+func (a *AppealAPI) event(appeal *Appeal) *models.AppealEvent {
+	return &models.AppealEvent{
+		ID:        appeal.ID,
+		RecordID:  appeal.RecordID,
+		Region:    a.region,
+		Namespace: appeal.Namespace,
+		Contact:   appeal.Contact,
+		Reason:    appeal.Reason,
+		Status:    appeal.Status,
+		Reviewer:  appeal.Reviewer,
+		Comment:   appeal.Comment,
+		Unlocked:  appeal.Unlocked,
+		Error:     appeal.Error,
+		CreatedAt: appeal.CreatedAt,
+	}
+}
+
+// fail maps store errors to HTTP status codes
+func (a *AppealAPI) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRecordNotFound), errors.Is(err, ErrAppealNotFound):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrAppealPending):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, errInvalidAppeal):
+		httpapi.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		a.log.Error("Appeal API request failed", logger.Fields{"error": err.Error()})
+		httpapi.WriteError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func appealID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid appeal id")
+		return 0, false
+	}
+	return uint(id), true
+}
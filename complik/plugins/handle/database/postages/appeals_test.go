@@ -0,0 +1,244 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// recordingUnlocker records the unlocked namespaces and fails with err
+type recordingUnlocker struct {
+	unlocked []string
+	err      error
+}
+
+func (u *recordingUnlocker) Unlock(_ context.Context, appeal *Appeal) error {
+	if u.err != nil {
+		return u.err
+	}
+	u.unlocked = append(u.unlocked, appeal.Namespace)
+	return nil
+}
+
+var _ = Describe("Appeals", func() {
+	var (
+		store    *AppealStore
+		api      *AppealAPI
+		unlocker *recordingUnlocker
+		events   []*models.AppealEvent
+	)
+
+	BeforeEach(func() {
+		db, err := database.Open(database.Options{
+			Driver:     database.DriverSQLite,
+			SQLitePath: filepath.Join(GinkgoT().TempDir(), "appeals.db"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		Expect(db.AutoMigrate(&DetectorRecord{}, &Appeal{})).To(Succeed())
+		Expect(db.Create(&[]DetectorRecord{
+			{DetectorName: "safety", Namespace: "ns-alice", Host: "casino.example.com", IsIllegal: true},
+			{DetectorName: "safety", Namespace: "ns-alice", Host: "blog.example.com", IsIllegal: false},
+			{DetectorName: "safety", Namespace: "ns-bob", Host: "shop.example.com", IsIllegal: true},
+		}).Error).To(Succeed())
+		store = NewAppealStore(db)
+		unlocker = &recordingUnlocker{}
+		events = nil
+		api = NewAppealAPI(logger.GetLogger(), "appeal-secret", "staff", store)
+		api.unlocker = unlocker
+		api.region = "hzh"
+		api.publish = func(event *models.AppealEvent) {
+			events = append(events, event)
+		}
+	})
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) Appeal {
+		var appeal Appeal
+		Expect(json.Unmarshal(rec.Body.Bytes(), &appeal)).To(Succeed())
+		return appeal
+	}
+
+	It("should only accept the appeal token of the namespace", func() {
+		body := `{"record_id":1,"reason":"the content was removed"}`
+		Expect(send(http.MethodPost, "/api/v1/namespaces/ns-alice/appeals", "", body).Code).
+			To(Equal(http.StatusUnauthorized))
+		Expect(send(http.MethodPost, "/api/v1/namespaces/ns-alice/appeals", AppealToken("appeal-secret", "ns-bob"), body).Code).
+			To(Equal(http.StatusUnauthorized))
+		Expect(send(http.MethodPost, "/api/v1/namespaces/ns-alice/appeals", "staff", body).Code).
+			To(Equal(http.StatusUnauthorized))
+		Expect(send(http.MethodGet, "/api/v1/appeals", AppealToken("appeal-secret", "ns-alice"), "").Code).
+			To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should refuse appeal decisions without a configured staff token", func() {
+		_, _, err := store.Submit("ns-alice", 1, "", "removed")
+		Expect(err).NotTo(HaveOccurred())
+		api = NewAppealAPI(logger.GetLogger(), "appeal-secret", "", store)
+		api.unlocker = unlocker
+		body := `{"reviewer":"alice"}`
+		Expect(send(http.MethodPost, "/api/v1/appeals/1/approve", "", body).Code).To(Equal(http.StatusUnauthorized))
+		Expect(send(http.MethodPost, "/api/v1/appeals/1/approve", AppealToken("appeal-secret", "ns-alice"), body).Code).
+			To(Equal(http.StatusUnauthorized))
+		Expect(send(http.MethodGet, "/api/v1/appeals", "", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(unlocker.unlocked).To(BeEmpty())
+
+		p := &DatabasePlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"driver":"sqlite","appealApiAddr":":8096","appealSecret":"appeal-secret"}`)).
+			To(MatchError(ContainSubstring("labelApiToken")))
+		Expect(p.loadConfig(`{"driver":"sqlite","appealApiAddr":":8096","appealSecret":"appeal-secret","labelApiToken":"staff"}`)).
+			To(Succeed())
+	})
+
+	It("should validate appeals against the records of the namespace", func() {
+		token := AppealToken("appeal-secret", "ns-alice")
+		submit := func(body string) int {
+			return send(http.MethodPost, "/api/v1/namespaces/ns-alice/appeals", token, body).Code
+		}
+		Expect(submit(`not json`)).To(Equal(http.StatusBadRequest))
+		Expect(submit(`{"reason":"removed"}`)).To(Equal(http.StatusBadRequest))
+		Expect(submit(`{"record_id":1}`)).To(Equal(http.StatusBadRequest))
+		Expect(submit(`{"record_id":2,"reason":"removed"}`)).To(Equal(http.StatusBadRequest))
+		Expect(submit(`{"record_id":3,"reason":"removed"}`)).To(Equal(http.StatusNotFound))
+		Expect(submit(`{"record_id":1,"reason":"removed"}`)).To(Equal(http.StatusCreated))
+		Expect(submit(`{"record_id":1,"reason":"removed"}`)).To(Equal(http.StatusConflict))
+		Expect(events).To(HaveLen(1))
+	})
+
+	It("should notify the staff and unlock the namespace of approved appeals", func() {
+		token := AppealToken("appeal-secret", "ns-alice")
+		rec := send(http.MethodPost, "/api/v1/namespaces/ns-alice/appeals", token,
+			`{"record_id":1,"contact":"alice@example.com","reason":"the content was removed"}`)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		appeal := decode(rec)
+		Expect(appeal.Status).To(Equal(models.AppealPending))
+		Expect(events).To(HaveLen(1))
+		Expect(*events[0]).To(And(
+			HaveField("Region", "hzh"),
+			HaveField("Host", "casino.example.com"),
+			HaveField("Detector", "safety"),
+			HaveField("Status", models.AppealPending),
+		))
+
+		rec = send(http.MethodGet, "/api/v1/appeals?status=pending", "staff", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("the content was removed"))
+
+		path := "/api/v1/appeals/1/approve"
+		Expect(send(http.MethodPost, path, "staff", `{}`).Code).To(Equal(http.StatusBadRequest))
+		rec = send(http.MethodPost, path, "staff", `{"reviewer":"carol","comment":"verified"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		appeal = decode(rec)
+		Expect(appeal.Status).To(Equal(models.AppealApproved))
+		Expect(appeal.Unlocked).To(BeTrue())
+		Expect(appeal.DecidedAt).NotTo(BeNil())
+		Expect(unlocker.unlocked).To(Equal([]string{"ns-alice"}))
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].Status).To(Equal(models.AppealApproved))
+
+		Expect(send(http.MethodPost, "/api/v1/appeals/1/reject", "staff", `{"reviewer":"dave"}`).Code).
+			To(Equal(http.StatusConflict))
+		Expect(send(http.MethodPost, "/api/v1/appeals/9/reject", "staff", `{"reviewer":"dave"}`).Code).
+			To(Equal(http.StatusNotFound))
+
+		rec = send(http.MethodGet, "/api/v1/namespaces/ns-alice/appeals", token, "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring(`"status":"approved"`))
+		rec = send(http.MethodGet, "/api/v1/namespaces/ns-bob/appeals", AppealToken("appeal-secret", "ns-bob"), "")
+		Expect(rec.Body.String()).To(Equal("[]\n"))
+	})
+
+	It("should keep rejected appeals locked", func() {
+		appeal, _, err := store.Submit("ns-alice", 1, "", "removed")
+		Expect(err).NotTo(HaveOccurred())
+		decided, err := store.Decide(context.Background(), unlocker, appeal.ID, false, "carol", "still online")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decided.Status).To(Equal(models.AppealRejected))
+		Expect(decided.Unlocked).To(BeFalse())
+		Expect(unlocker.unlocked).To(BeEmpty())
+
+		// A rejected appeal does not block a new one
+		_, _, err = store.Submit("ns-alice", 1, "", "removed for real")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should let failed unlocks be decided again", func() {
+		appeal, _, err := store.Submit("ns-alice", 1, "", "removed")
+		Expect(err).NotTo(HaveOccurred())
+		unlocker.err = errors.New("forbidden")
+		decided, err := store.Decide(context.Background(), unlocker, appeal.ID, true, "carol", "")
+		Expect(err).To(MatchError("forbidden"))
+		Expect(decided.Status).To(Equal(models.AppealFailed))
+		Expect(decided.Error).To(Equal("forbidden"))
+		_, _, err = store.Submit("ns-alice", 1, "", "again")
+		Expect(err).To(MatchError(ErrAppealPending))
+
+		unlocker.err = nil
+		decided, err = store.Decide(context.Background(), unlocker, appeal.ID, true, "carol", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decided.Status).To(Equal(models.AppealApproved))
+		Expect(decided.Error).To(BeEmpty())
+		stored, err := store.Get(appeal.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Unlocked).To(BeTrue())
+		Expect(stored.Error).To(BeEmpty())
+	})
+
+	It("should unlock namespaces with a block-controller request", func() {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{blockRequests: "BlockRequestList"})
+		unlocker := NewBlockRequestUnlocker(client, "system")
+		appeal := &Appeal{ID: 7, Namespace: "ns-alice", Reviewer: "carol"}
+		Expect(unlocker.Unlock(context.Background(), appeal)).To(Succeed())
+		Expect(unlocker.Unlock(context.Background(), appeal)).To(Succeed())
+
+		request, err := client.Resource(blockRequests).Namespace("system").
+			Get(context.Background(), "complik-appeal-7", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		action, _, _ := unstructured.NestedString(request.Object, "spec", "action")
+		Expect(action).To(Equal("active"))
+		namespaces, _, _ := unstructured.NestedStringSlice(request.Object, "spec", "namespaceNames")
+		Expect(namespaces).To(Equal([]string{"ns-alice"}))
+	})
+})
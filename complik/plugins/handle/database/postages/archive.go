@@ -0,0 +1,379 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// archiveContentType is the content type of the archived objects
+const archiveContentType = "application/gzip"
+
+// ErrArchiveRunning is returned when a purge is requested while another one
+// is still running
+var ErrArchiveRunning = errors.New("record archive already running")
+
+// ErrNoArchiveStore is returned when records are to be archived without
+// archiveDir or archiveBucket configured
+var ErrNoArchiveStore = errors.New("no archive store configured")
+
+// archiveColumns are the CSV columns of the archived records
+var archiveColumns = []string{
+	"id", "created_at", "updated_at", "region", "namespace", "name", "host", "url", "path",
+	"discovery_name", "collector_name", "detector_name", "is_illegal", "unchanged", "severity",
+	"description", "keywords", "workload_kind", "workload_name", "images", "owner_user_id",
+	"owner_team", "redactions", "content_hash", "occurrences", "workload_created_at",
+}
+
+// PurgeResult is the outcome of an archive run
+type PurgeResult struct {
+	Before   time.Time `json:"before"`
+	DryRun   bool      `json:"dry_run,omitempty"`
+	Archived int64     `json:"archived"`
+	Purged   int64     `json:"purged"`
+	Objects  []string  `json:"objects,omitempty"`
+}
+
+// RecordArchiver moves the detector records last seen before a cutoff to an
+// archive store as gzip compressed CSV and deletes them from the database.
+// Records with a label or an appeal under review are kept, they belong to the
+// golden dataset or are still being reviewed.
+type RecordArchiver struct {
+	log       logger.Logger
+	db        *gorm.DB
+	store     ArchiveStore
+	prefix    string
+	batchSize int
+
+	// mu serialises the scheduled and the manual runs
+	mu sync.Mutex
+
+	archived prometheus.Counter
+	purged   prometheus.Counter
+	failures prometheus.Counter
+	lastRun  prometheus.Gauge
+}
+
+// NewRecordArchiver creates an archiver writing objects of at most batchSize
+// records under prefix of store. A nil store deletes without archiving.
+func NewRecordArchiver(log logger.Logger, db *gorm.DB, store ArchiveStore, prefix string, batchSize int) *RecordArchiver {
+	if batchSize <= 0 {
+		batchSize = 5000
+	}
+	return &RecordArchiver{
+		log:       log,
+		db:        db,
+		store:     store,
+		prefix:    prefix,
+		batchSize: batchSize,
+		archived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_archived_records_total",
+			Help:      "Detector records written to the archive store.",
+		}),
+		purged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_purged_records_total",
+			Help:      "Detector records deleted from the database by the retention job.",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_archive_failures_total",
+			Help:      "Archive runs that failed before all expired records were purged.",
+		}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_archive_last_success_timestamp_seconds",
+			Help:      "Time of the last archive run that purged all expired records.",
+		}),
+	}
+}
+
+// Purge archives and deletes the records last seen before cutoff, in batches
+// ordered by ID. A batch is only deleted once its object was stored, so a
+// failed run is simply repeated. archive false skips the store, dryRun only
+// counts the records that would be purged.
+func (a *RecordArchiver) Purge(ctx context.Context, cutoff time.Time, archive, dryRun bool) (PurgeResult, error) {
+	if !a.mu.TryLock() {
+		return PurgeResult{}, ErrArchiveRunning
+	}
+	defer a.mu.Unlock()
+
+	result := PurgeResult{Before: cutoff, DryRun: dryRun}
+	if archive && a.store == nil {
+		return result, ErrNoArchiveStore
+	}
+	if dryRun {
+		err := a.expired(ctx, cutoff).Model(&DetectorRecord{}).Count(&result.Purged).Error
+		if err != nil {
+			return result, fmt.Errorf("failed to count expired records: %w", err)
+		}
+		return result, nil
+	}
+
+	err := a.purge(ctx, cutoff, archive, &result)
+	if err != nil {
+		a.failures.Inc()
+		return result, err
+	}
+	a.lastRun.SetToCurrentTime()
+	return result, nil
+}
+
+func (a *RecordArchiver) purge(ctx context.Context, cutoff time.Time, archive bool, result *PurgeResult) error {
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var records []DetectorRecord
+		if err := a.expired(ctx, cutoff).Where("id > ?", lastID).
+			Order("id").Limit(a.batchSize).Find(&records).Error; err != nil {
+			return fmt.Errorf("failed to load expired records: %w", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		lastID = records[len(records)-1].ID
+
+		if archive {
+			key, err := a.archive(ctx, records)
+			if err != nil {
+				return err
+			}
+			result.Archived += int64(len(records))
+			result.Objects = append(result.Objects, key)
+			a.archived.Add(float64(len(records)))
+		}
+
+		ids := make([]uint, len(records))
+		for i, record := range records {
+			ids[i] = record.ID
+		}
+		deleted := a.db.WithContext(ctx).Delete(&DetectorRecord{}, ids)
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete archived records: %w", deleted.Error)
+		}
+		result.Purged += deleted.RowsAffected
+		a.purged.Add(float64(deleted.RowsAffected))
+		a.log.Debug("Expired records purged", logger.Fields{
+			"records": deleted.RowsAffected,
+			"last_id": lastID,
+		})
+		if len(records) < a.batchSize {
+			return nil
+		}
+	}
+}
+
+// expired selects the records last seen before cutoff without a label or an
+// appeal under review
+func (a *RecordArchiver) expired(ctx context.Context, cutoff time.Time) *gorm.DB {
+	return a.db.WithContext(ctx).
+		Where("updated_at < ?", cutoff).
+		Where("id NOT IN (?)", a.db.Model(&DetectorLabel{}).Select("record_id")).
+		Where("id NOT IN (?)", a.db.Model(&Appeal{}).Select("record_id").Where("status IN ?", []string{models.AppealPending, models.AppealFailed}))
+}
+
+// archive stores records as one object and returns its key. Keys are derived
+// from the IDs, so repeating a failed run replaces the object.
+func (a *RecordArchiver) archive(ctx context.Context, records []DetectorRecord) (string, error) {
+	data, err := encodeArchive(records)
+	if err != nil {
+		return "", err
+	}
+	first, last := records[0], records[len(records)-1]
+	key := fmt.Sprintf("%sdetector_records/%s/detector_records-%010d-%010d.csv.gz",
+		a.prefix, first.CreatedAt.UTC().Format("2006/01/02"), first.ID, last.ID)
+	if err := a.store.Put(ctx, key, data); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// encodeArchive writes records as gzip compressed CSV with a header row
+func encodeArchive(records []DetectorRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	w := csv.NewWriter(zw)
+	if err := w.Write(archiveColumns); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		row := []string{
+			strconv.FormatUint(uint64(r.ID), 10),
+			r.CreatedAt.UTC().Format(time.RFC3339),
+			r.UpdatedAt.UTC().Format(time.RFC3339),
+			r.Region, r.Namespace, r.Name, r.Host, r.URL, deref(r.Path),
+			r.DiscoveryName, r.CollectorName, r.DetectorName,
+			strconv.FormatBool(r.IsIllegal), strconv.FormatBool(r.Unchanged), r.Severity,
+			r.Description, deref(r.Keywords), r.WorkloadKind, r.WorkloadName, deref(r.Images),
+			r.OwnerUserID, r.OwnerTeam, strconv.Itoa(r.Redactions), r.ContentHash,
+			strconv.Itoa(r.Occurrences), "",
+		}
+		if r.WorkloadCreatedAt != nil {
+			row[len(row)-1] = r.WorkloadCreatedAt.UTC().Format(time.RFC3339)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// run archives the records older than retention every interval until ctx is
+// done. It needs a store.
+func (a *RecordArchiver) run(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := a.Purge(ctx, time.Now().Add(-retention), true, false)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			a.log.Warn("Failed to archive expired records", logger.Fields{
+				"error":    err.Error(),
+				"archived": result.Archived,
+				"purged":   result.Purged,
+			})
+		case result.Purged > 0:
+			a.log.Info("Expired records archived", logger.Fields{
+				"archived": result.Archived,
+				"purged":   result.Purged,
+				"objects":  len(result.Objects),
+				"store":    a.store.String(),
+			})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadArchiveConfig applies the retention settings of cfg and opens the
+// archive store
+func (p *DatabasePlugin) loadArchiveConfig(cfg DatabaseConfig) error {
+	p.databaseConfig.RecordRetentionDay = cfg.RecordRetentionDay
+	if cfg.ArchiveIntervalHour > 0 {
+		p.databaseConfig.ArchiveIntervalHour = cfg.ArchiveIntervalHour
+	}
+	if cfg.ArchiveBatchSize > 0 {
+		p.databaseConfig.ArchiveBatchSize = cfg.ArchiveBatchSize
+	}
+	if cfg.ArchiveRegion != "" {
+		p.databaseConfig.ArchiveRegion = cfg.ArchiveRegion
+	}
+	p.databaseConfig.ArchiveDir = cfg.ArchiveDir
+	p.databaseConfig.ArchiveEndpoint = cfg.ArchiveEndpoint
+	p.databaseConfig.ArchiveBucket = cfg.ArchiveBucket
+	p.databaseConfig.ArchivePrefix = cfg.ArchivePrefix
+	if prefix := p.databaseConfig.ArchivePrefix; prefix != "" && !strings.HasSuffix(prefix, "/") {
+		p.databaseConfig.ArchivePrefix = prefix + "/"
+	}
+	for _, secret := range []struct {
+		name  string
+		value string
+		dst   *string
+	}{
+		{"archive access key", cfg.ArchiveAccessKey, &p.databaseConfig.ArchiveAccessKey},
+		{"archive secret key", cfg.ArchiveSecretKey, &p.databaseConfig.ArchiveSecretKey},
+	} {
+		*secret.dst = ""
+		if secret.value == "" {
+			continue
+		}
+		if value, err := config.GetSecureValue(secret.value); err == nil {
+			*secret.dst = value
+		} else if config.IsSecretReference(secret.value) {
+			return fmt.Errorf("failed to resolve %s: %w", secret.name, err)
+		} else {
+			*secret.dst = secret.value
+		}
+	}
+
+	p.archiveStore = nil
+	switch c := p.databaseConfig; {
+	case c.ArchiveDir != "" && c.ArchiveBucket != "":
+		return errors.New("archiveDir and archiveBucket are mutually exclusive")
+	case c.ArchiveDir != "":
+		p.archiveStore = NewDirStore(c.ArchiveDir)
+	case c.ArchiveBucket != "":
+		store, err := NewS3Store(c.ArchiveEndpoint, c.ArchiveBucket, c.ArchiveRegion, c.ArchiveAccessKey, c.ArchiveSecretKey)
+		if err != nil {
+			return err
+		}
+		p.archiveStore = store
+	case c.RecordRetentionDay > 0:
+		return errors.New("archiveDir or archiveBucket is required to archive records")
+	}
+	return nil
+}
+
+// startRecordRetention archives the records older than RecordRetentionDay
+// every ArchiveIntervalHour
+func (p *DatabasePlugin) startRecordRetention(ctx context.Context) {
+	p.log.Info("Record retention enabled", logger.Fields{
+		"retention_day": p.databaseConfig.RecordRetentionDay,
+		"interval_hour": p.databaseConfig.ArchiveIntervalHour,
+		"store":         p.archiveStore.String(),
+	})
+	go p.archiver.run(ctx,
+		time.Duration(p.databaseConfig.RecordRetentionDay)*24*time.Hour,
+		time.Duration(p.databaseConfig.ArchiveIntervalHour)*time.Hour)
+}
+
+func (a *RecordArchiver) Describe(ch chan<- *prometheus.Desc) {
+	a.archived.Describe(ch)
+	a.purged.Describe(ch)
+	a.failures.Describe(ch)
+	a.lastRun.Describe(ch)
+}
+
+func (a *RecordArchiver) Collect(ch chan<- prometheus.Metric) {
+	a.archived.Collect(ch)
+	a.purged.Collect(ch)
+	a.failures.Collect(ch)
+	a.lastRun.Collect(ch)
+}
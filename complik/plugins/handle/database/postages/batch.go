@@ -0,0 +1,113 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// upsertColumns are refreshed when a finding is detected again; the columns
+// hashed into its key never change
+var upsertColumns = []string{"url", "path", "unchanged", "images", "updated_at"}
+
+// contentHash identifies what the detector found, independently of where it
+// was found: the verdict, severity, description and keywords of result
+func contentHash(result *models.DetectorInfo) string {
+	keywords := append([]string(nil), result.Keywords...)
+	sort.Strings(keywords)
+	h := sha256.New()
+	for _, part := range []string{
+		strconv.FormatBool(result.IsIllegal),
+		result.Severity,
+		result.Description,
+		strings.Join(keywords, "\x1f"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// findingKey is the unique key of a finding. The four parts are hashed into a
+// single column to stay within the index length limit of MySQL.
+func findingKey(namespace, host, detector, contentHash string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{namespace, host, detector, contentHash}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// setFindingKey derives the finding key of r from its content hash. Records
+// stored before content hashes were introduced keep a NULL key.
+func (r *DetectorRecord) setFindingKey() {
+	if r.ContentHash == "" {
+		return
+	}
+	key := findingKey(r.Namespace, r.Host, r.DetectorName, r.ContentHash)
+	r.FindingKey = &key
+}
+
+// coalesceRecords merges the records of a batch with the same finding key into
+// the last of them, adding up their occurrences. The order of the first
+// occurrence of every finding is kept.
+func coalesceRecords(records []DetectorRecord) []DetectorRecord {
+	merged := make([]DetectorRecord, 0, len(records))
+	index := make(map[string]int, len(records))
+	for _, record := range records {
+		if record.FindingKey == nil {
+			merged = append(merged, record)
+			continue
+		}
+		i, ok := index[*record.FindingKey]
+		if !ok {
+			index[*record.FindingKey] = len(merged)
+			merged = append(merged, record)
+			continue
+		}
+		record.Occurrences += merged[i].Occurrences
+		record.CreatedAt = merged[i].CreatedAt
+		record.EventKeys = append(append([]string(nil), merged[i].EventKeys...), record.EventKeys...)
+		merged[i] = record
+	}
+	return merged
+}
+
+// upsertRecords inserts records in batches. A record whose finding is already
+// stored refreshes the stored row and adds its occurrences to it instead of
+// adding a duplicate row.
+func upsertRecords(db *gorm.DB, records []DetectorRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	occurrences := "occurrences + excluded.occurrences"
+	if db.Dialector.Name() == "mysql" {
+		occurrences = "occurrences + VALUES(occurrences)"
+	}
+	updates := clause.AssignmentColumns(upsertColumns)
+	updates = append(updates, clause.Assignment{
+		Column: clause.Column{Name: "occurrences"},
+		Value:  gorm.Expr(occurrences),
+	})
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "finding_key"}},
+		DoUpdates: updates,
+	}).CreateInBatches(records, len(records)).Error
+}
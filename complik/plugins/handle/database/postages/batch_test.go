@@ -0,0 +1,173 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Batched writes", func() {
+	var (
+		db *gorm.DB
+		p  *DatabasePlugin
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = database.Open(database.Options{
+			Driver:     database.DriverSQLite,
+			SQLitePath: filepath.Join(GinkgoT().TempDir(), "records.db"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		Expect(db.AutoMigrate(&DetectorRecord{}, &HandledEvent{})).To(Succeed())
+		p = &DatabasePlugin{log: logger.GetLogger(), db: db}
+		Expect(p.loadConfig(`{"driver":"sqlite"}`)).To(Succeed())
+	})
+
+	finding := func(host, description string) *models.DetectorInfo {
+		return &models.DetectorInfo{
+			DetectorName: "safety",
+			Namespace:    "ns-test",
+			Host:         host,
+			URL:          "http://" + host,
+			IsIllegal:    true,
+			Description:  description,
+			Keywords:     []string{"casino", "bet"},
+		}
+	}
+
+	records := func() []DetectorRecord {
+		var stored []DetectorRecord
+		Expect(db.Order("id").Find(&stored).Error).To(Succeed())
+		return stored
+	}
+
+	It("should ignore the order of keywords in the content hash", func() {
+		reordered := finding("a.example.com", "gambling")
+		reordered.Keywords = []string{"bet", "casino"}
+		Expect(contentHash(reordered)).To(Equal(contentHash(finding("a.example.com", "gambling"))))
+		Expect(contentHash(finding("a.example.com", "lottery"))).
+			NotTo(Equal(contentHash(finding("a.example.com", "gambling"))))
+	})
+
+	It("should count repeated findings on a single row", func() {
+		Expect(p.saveResults(finding("a.example.com", "gambling"))).To(Succeed())
+		again := finding("a.example.com", "gambling")
+		again.URL = "http://a.example.com/shop"
+		Expect(p.saveResults(again)).To(Succeed())
+		Expect(p.saveResults(finding("a.example.com", "lottery"))).To(Succeed())
+		Expect(p.saveResults(finding("b.example.com", "gambling"))).To(Succeed())
+
+		stored := records()
+		Expect(stored).To(HaveLen(3))
+		Expect(stored[0].Occurrences).To(Equal(2))
+		Expect(stored[0].URL).To(Equal("http://a.example.com/shop"))
+		Expect(stored[0].UpdatedAt).NotTo(BeTemporally("<", stored[0].CreatedAt))
+		Expect(stored[1].Occurrences).To(Equal(1))
+		Expect(stored[2].Host).To(Equal("b.example.com"))
+	})
+
+	It("should merge the duplicates of a batch before writing it", func() {
+		batch := []DetectorRecord{
+			p.newRecord(finding("a.example.com", "gambling")),
+			p.newRecord(finding("b.example.com", "gambling")),
+			p.newRecord(finding("a.example.com", "gambling")),
+		}
+		Expect(p.writeRecords(batch)).To(Succeed())
+		Expect(p.writeRecords(batch[:1])).To(Succeed())
+
+		stored := records()
+		Expect(stored).To(HaveLen(2))
+		Expect(stored[0].Host).To(Equal("a.example.com"))
+		Expect(stored[0].Occurrences).To(Equal(3))
+		Expect(stored[1].Occurrences).To(Equal(1))
+	})
+
+	It("should store an event delivered again once", func() {
+		keyed := func(key string) DetectorRecord {
+			record := p.newRecord(finding("a.example.com", "gambling"))
+			record.EventKeys = []string{key}
+			return record
+		}
+		Expect(p.writeRecords([]DetectorRecord{keyed("k1"), keyed("k1")})).To(Succeed())
+		Expect(p.writeRecords([]DetectorRecord{keyed("k1")})).To(Succeed())
+		Expect(records()[0].Occurrences).To(Equal(1))
+
+		Expect(p.writeRecords([]DetectorRecord{keyed("k2")})).To(Succeed())
+		Expect(records()[0].Occurrences).To(Equal(2))
+
+		// The unique key rejects a duplicate the check missed, without
+		// counting it
+		Expect(storeRecords(db, []DetectorRecord{keyed("k2")})).NotTo(Succeed())
+		Expect(records()[0].Occurrences).To(Equal(2))
+
+		// Past the dedup window the event counts again
+		expired := time.Now().Add(-time.Hour)
+		Expect(db.Model(&HandledEvent{}).Where("event_key = ?", "k1").Update("created_at", expired).Error).To(Succeed())
+		Expect(p.writeRecords([]DetectorRecord{keyed("k1")})).To(Succeed())
+		Expect(records()[0].Occurrences).To(Equal(3))
+
+		purged, err := PurgeHandledEvents(db, time.Now().Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(purged).To(Equal(int64(2)))
+	})
+
+	It("should flush batches when full and when stopping", func() {
+		Expect(p.loadConfig(`{"driver":"sqlite","batchSize":2,"flushIntervalSecond":3600}`)).To(Succeed())
+		events := make(eventbus.EventChan, 3)
+		for _, host := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+			events <- eventbus.Event{Payload: finding(host, "gambling")}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p.done = make(chan struct{})
+		go p.consume(ctx, events)
+
+		Eventually(records).Should(HaveLen(2))
+		Consistently(records, 200*time.Millisecond).Should(HaveLen(2))
+		cancel()
+		Eventually(p.done).Should(BeClosed())
+		Expect(records()).To(HaveLen(3))
+	})
+
+	It("should flush partial batches on the interval", func() {
+		Expect(p.loadConfig(`{"driver":"sqlite","flushIntervalSecond":1}`)).To(Succeed())
+		events := make(eventbus.EventChan, 1)
+		events <- eventbus.Event{Payload: finding("a.example.com", "gambling")}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p.done = make(chan struct{})
+		go p.consume(ctx, events)
+
+		Eventually(records, 3*time.Second).Should(HaveLen(1))
+		cancel()
+		Eventually(p.done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,205 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const metricsNamespace = "complik"
+
+var (
+	violationsDesc = prometheus.NewDesc(
+		metricsNamespace+"_violations",
+		"Stored violations per detector and review status.",
+		[]string{"detector", "status"}, nil,
+	)
+	regionViolationsDesc = prometheus.NewDesc(
+		metricsNamespace+"_region_violations",
+		"Stored violations per region and review status.",
+		[]string{"region", "status"}, nil,
+	)
+	recordsDesc = prometheus.NewDesc(
+		metricsNamespace+"_detector_records",
+		"Stored detector records, flagged or not, per detector.",
+		[]string{"detector"}, nil,
+	)
+	todayViolationsDesc = prometheus.NewDesc(
+		metricsNamespace+"_violations_today",
+		"Violations detected since midnight of the database time zone.",
+		nil, nil,
+	)
+	usageTokensDesc = prometheus.NewDesc(
+		metricsNamespace+"_llm_tokens_today",
+		"Model tokens consumed by the reviews of the current UTC day per region, namespace and token type.",
+		[]string{"region", "namespace", "type"}, nil,
+	)
+	usageCostDesc = prometheus.NewDesc(
+		metricsNamespace+"_llm_cost_usd_today",
+		"Cost in USD of the model reviews of the current UTC day per region and namespace.",
+		[]string{"region", "namespace"}, nil,
+	)
+	usageReviewsDesc = prometheus.NewDesc(
+		metricsNamespace+"_llm_reviews_today",
+		"Model reviews of the current UTC day per region and namespace.",
+		[]string{"region", "namespace"}, nil,
+	)
+	refreshDesc = prometheus.NewDesc(
+		metricsNamespace+"_posture_last_refresh_timestamp_seconds",
+		"Time of the last successful refresh of the compliance posture metrics.",
+		nil, nil,
+	)
+)
+
+// postureSnapshot holds the rows of the views at the last refresh
+type postureSnapshot struct {
+	days      []DayViolations
+	regions   []RegionViolations
+	detectors []DetectorViolations
+	usage     []NamespaceUsage
+	refreshed time.Time
+}
+
+// PostureExporter publishes the aggregates of the dashboard views as
+// Prometheus metrics. The views are queried once per refresh instead of per
+// scrape, so dashboards scraping often do not load the database.
+type PostureExporter struct {
+	log      logger.Logger
+	db       *gorm.DB
+	errors   prometheus.Counter
+	mu       sync.RWMutex
+	snapshot postureSnapshot
+}
+
+func NewPostureExporter(log logger.Logger, db *gorm.DB) *PostureExporter {
+	return &PostureExporter{
+		log: log,
+		db:  db,
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "posture_refresh_errors_total",
+			Help:      "Failed refreshes of the compliance posture metrics.",
+		}),
+	}
+}
+
+// Refresh reads the views
+func (e *PostureExporter) Refresh(ctx context.Context) error {
+	var snapshot postureSnapshot
+	db := e.db.WithContext(ctx)
+	queries := []struct {
+		view string
+		dest any
+	}{
+		{ViewViolationsPerDay, &snapshot.days},
+		{ViewViolationsPerRegion, &snapshot.regions},
+		{ViewViolationsPerDetector, &snapshot.detectors},
+	}
+	for _, q := range queries {
+		if err := db.Table(q.view).Find(q.dest).Error; err != nil {
+			e.errors.Inc()
+			return fmt.Errorf("failed to query %s: %w", q.view, err)
+		}
+	}
+	if err := db.Model(&DetectorTokenUsage{}).
+		Select("region, namespace, SUM(reviews) AS reviews, SUM(prompt_tokens) AS prompt_tokens, "+
+			"SUM(completion_tokens) AS completion_tokens, SUM(cost_usd) AS cost_usd").
+		Where("day = ?", time.Now().UTC().Format(time.DateOnly)).
+		Group("region, namespace").Scan(&snapshot.usage).Error; err != nil {
+		e.errors.Inc()
+		return fmt.Errorf("failed to query token usage: %w", err)
+	}
+	snapshot.refreshed = time.Now()
+	e.mu.Lock()
+	e.snapshot = snapshot
+	e.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the metrics every interval until ctx is done
+func (e *PostureExporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Refresh(ctx); err != nil && ctx.Err() == nil {
+			e.log.Warn("Failed to refresh compliance posture metrics", logger.Fields{"error": err.Error()})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *PostureExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- violationsDesc
+	ch <- regionViolationsDesc
+	ch <- recordsDesc
+	ch <- todayViolationsDesc
+	ch <- usageTokensDesc
+	ch <- usageCostDesc
+	ch <- usageReviewsDesc
+	ch <- refreshDesc
+	e.errors.Describe(ch)
+}
+
+func (e *PostureExporter) Collect(ch chan<- prometheus.Metric) {
+	e.errors.Collect(ch)
+	e.mu.RLock()
+	snapshot := e.snapshot
+	e.mu.RUnlock()
+	if snapshot.refreshed.IsZero() {
+		return
+	}
+	gauge := func(desc *prometheus.Desc, value int, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), labels...)
+	}
+	for _, d := range snapshot.detectors {
+		gauge(recordsDesc, d.Records, d.DetectorName)
+		gauge(violationsDesc, d.Confirmed, d.DetectorName, "confirmed")
+		gauge(violationsDesc, d.Dismissed, d.DetectorName, "dismissed")
+		gauge(violationsDesc, d.Pending, d.DetectorName, "pending_review")
+	}
+	for _, r := range snapshot.regions {
+		gauge(regionViolationsDesc, r.Confirmed, r.Region, "confirmed")
+		gauge(regionViolationsDesc, r.Dismissed, r.Region, "dismissed")
+		gauge(regionViolationsDesc, r.Pending, r.Region, "pending_review")
+	}
+	// MySQL returns the day as a timestamp, SQLite as a date
+	today := 0
+	day := time.Now().Format(time.DateOnly)
+	for _, d := range snapshot.days {
+		if strings.HasPrefix(d.Day, day) {
+			today = d.Violations
+		}
+	}
+	gauge(todayViolationsDesc, today)
+	for _, u := range snapshot.usage {
+		ch <- prometheus.MustNewConstMetric(usageTokensDesc, prometheus.GaugeValue, float64(u.PromptTokens), u.Region, u.Namespace, "prompt")
+		ch <- prometheus.MustNewConstMetric(usageTokensDesc, prometheus.GaugeValue, float64(u.CompletionTokens), u.Region, u.Namespace, "completion")
+		ch <- prometheus.MustNewConstMetric(usageCostDesc, prometheus.GaugeValue, u.CostUSD, u.Region, u.Namespace)
+		ch <- prometheus.MustNewConstMetric(usageReviewsDesc, prometheus.GaugeValue, float64(u.Reviews), u.Region, u.Namespace)
+	}
+	ch <- prometheus.MustNewConstMetric(refreshDesc, prometheus.GaugeValue, float64(snapshot.refreshed.Unix()))
+}
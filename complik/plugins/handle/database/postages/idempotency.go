@@ -0,0 +1,132 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// HandledEvent is the idempotency key of a stored detection event. The key is
+// unique, so an event delivered again cannot add its occurrences twice, even
+// when two replicas write it at once.
+type HandledEvent struct {
+	EventKey  string    `gorm:"primaryKey;size:64"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (HandledEvent) TableName() string {
+	return "handled_events"
+}
+
+// dropHandled drops the records of events stored within the dedup window and
+// the repeated events of records. When the stored events cannot be read the
+// records are kept, and the unique key rejects the duplicates on write.
+func (p *DatabasePlugin) dropHandled(records []DetectorRecord) []DetectorRecord {
+	var keys []string
+	for _, record := range records {
+		keys = append(keys, record.EventKeys...)
+	}
+	if len(keys) == 0 {
+		return records
+	}
+	handled := make(map[string]bool, len(keys))
+	// Expired keys are removed first, so an event delivered again after the
+	// window is counted again
+	cutoff := time.Now().Add(-time.Duration(p.databaseConfig.DedupMinute) * time.Minute)
+	err := p.db.Where("event_key IN ? AND created_at < ?", keys, cutoff).Delete(&HandledEvent{}).Error
+	if err == nil {
+		var stored []string
+		err = p.db.Model(&HandledEvent{}).Where("event_key IN ?", keys).Pluck("event_key", &stored).Error
+		for _, key := range stored {
+			handled[key] = true
+		}
+	}
+	if err != nil {
+		p.log.Warn("Failed to read the handled events", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
+	kept := make([]DetectorRecord, 0, len(records))
+	for _, record := range records {
+		duplicate := false
+		for _, key := range record.EventKeys {
+			duplicate = duplicate || handled[key]
+			handled[key] = true
+		}
+		if duplicate {
+			p.log.Debug("Skipping detection event already stored", logger.Fields{
+				"host":      record.Host,
+				"namespace": record.Namespace,
+			})
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+// storeRecords upserts records and inserts the keys of their events in one
+// transaction, a key stored meanwhile fails the whole write
+func storeRecords(db *gorm.DB, records []DetectorRecord) error {
+	var handled []HandledEvent
+	now := time.Now()
+	for _, record := range records {
+		for _, key := range record.EventKeys {
+			handled = append(handled, HandledEvent{EventKey: key, CreatedAt: now})
+		}
+	}
+	if len(handled) == 0 {
+		return upsertRecords(db, records)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := upsertRecords(tx, records); err != nil {
+			return err
+		}
+		return tx.CreateInBatches(handled, len(handled)).Error
+	})
+}
+
+// PurgeHandledEvents deletes the keys of the events stored before before
+func PurgeHandledEvents(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("created_at < ?", before).Delete(&HandledEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// startHandledEventPurge deletes the keys older than the dedup window every
+// hour
+func (p *DatabasePlugin) startHandledEventPurge(ctx context.Context) {
+	window := time.Duration(p.databaseConfig.DedupMinute) * time.Minute
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := PurgeHandledEvents(p.db.WithContext(ctx), time.Now().Add(-window)); err != nil && ctx.Err() == nil {
+				p.log.Warn("Failed to purge expired event keys", logger.Fields{
+					"error": err.Error(),
+				})
+			}
+		}
+	}()
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Verdicts a reviewer can give a stored detector record
+const (
+	VerdictTruePositive  = "true_positive"
+	VerdictFalsePositive = "false_positive"
+	VerdictTrueNegative  = "true_negative"
+	VerdictFalseNegative = "false_negative"
+)
+
+// ErrRecordNotFound is returned when labeling a record that does not exist
+var ErrRecordNotFound = errors.New("detector record not found")
+
+// errInvalidVerdict wraps every verdict validation error
+var errInvalidVerdict = errors.New("invalid verdict")
+
+// DetectorLabel is the reviewer verdict for a detector record. Every record
+// has at most one label; labeling it again replaces the previous verdict.
+type DetectorLabel struct {
+	ID        uint      `gorm:"primaryKey"    json:"id"`
+	RecordID  uint      `gorm:"uniqueIndex"   json:"record_id"`
+	Verdict   string    `gorm:"size:32;index" json:"verdict"`
+	Reviewer  string    `gorm:"size:255"      json:"reviewer"`
+	Comment   string    `gorm:"type:text"     json:"comment,omitempty"`
+	CreatedAt time.Time `                     json:"created_at"`
+	UpdatedAt time.Time `                     json:"updated_at"`
+}
+
+func (DetectorLabel) TableName() string {
+	return "detector_labels"
+}
+
+// ValidateVerdict checks that verdict is known and consistent with the
+// detector decision: positives are only possible for flagged records
+func ValidateVerdict(verdict string, isIllegal bool) error {
+	switch verdict {
+	case VerdictTruePositive, VerdictFalsePositive:
+		if !isIllegal {
+			return fmt.Errorf("%w: %s requires a record flagged as illegal", errInvalidVerdict, verdict)
+		}
+	case VerdictTrueNegative, VerdictFalseNegative:
+		if isIllegal {
+			return fmt.Errorf("%w: %s requires a record not flagged as illegal", errInvalidVerdict, verdict)
+		}
+	default:
+		return fmt.Errorf("%w: unknown verdict %q", errInvalidVerdict, verdict)
+	}
+	return nil
+}
+
+// LabelStore stores reviewer labels next to the detector records
+type LabelStore struct {
+	db *gorm.DB
+	// region is shown in the compliance reports built from the records
+	region string
+}
+
+func NewLabelStore(db *gorm.DB) *LabelStore {
+	return &LabelStore{db: db}
+}
+
+// Label records the verdict of reviewer for the detector record recordID
+func (s *LabelStore) Label(recordID uint, verdict, reviewer, comment string) (*DetectorLabel, error) {
+	var record DetectorRecord
+	if err := s.db.First(&record, recordID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to load record: %w", err)
+	}
+	if err := ValidateVerdict(verdict, record.IsIllegal); err != nil {
+		return nil, err
+	}
+	label := &DetectorLabel{
+		RecordID: recordID,
+		Verdict:  verdict,
+		Reviewer: reviewer,
+		Comment:  comment,
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "record_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"verdict", "reviewer", "comment", "updated_at"}),
+	}).Create(label).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save label: %w", err)
+	}
+	return label, nil
+}
+
+// LabeledRecord is a detector record together with its label, if any
+type LabeledRecord struct {
+	DetectorRecord
+	Label *DetectorLabel `gorm:"-" json:"label,omitempty"`
+}
+
+// ListOptions filters the records returned by List. Zero values do not
+// filter; From and To bound the detection time as [From, To).
+type ListOptions struct {
+	Detector  string
+	Namespace string
+	Host      string
+	IsIllegal *bool
+	// Keyword matches the keywords and the description of records
+	Keyword   string
+	From      time.Time
+	To        time.Time
+	Unlabeled bool
+	Limit     int
+	Offset    int
+}
+
+// List returns detector records, newest first, with their labels
+func (s *LabelStore) List(opts ListOptions) ([]LabeledRecord, error) {
+	if opts.Limit <= 0 || opts.Limit > 500 {
+		opts.Limit = 50
+	}
+	query := s.db.Model(&DetectorRecord{}).Order("id DESC").Limit(opts.Limit).Offset(opts.Offset)
+	if opts.Detector != "" {
+		query = query.Where("detector_name = ?", opts.Detector)
+	}
+	if opts.Namespace != "" {
+		query = query.Where("namespace = ?", opts.Namespace)
+	}
+	if opts.Host != "" {
+		query = query.Where("host = ?", opts.Host)
+	}
+	if opts.IsIllegal != nil {
+		query = query.Where("is_illegal = ?", *opts.IsIllegal)
+	}
+	if opts.Keyword != "" {
+		pattern := "%" + opts.Keyword + "%"
+		query = query.Where("keywords LIKE ? OR description LIKE ?", pattern, pattern)
+	}
+	if !opts.From.IsZero() {
+		query = query.Where("created_at >= ?", opts.From)
+	}
+	if !opts.To.IsZero() {
+		query = query.Where("created_at < ?", opts.To)
+	}
+	if opts.Unlabeled {
+		query = query.Where("id NOT IN (?)", s.db.Model(&DetectorLabel{}).Select("record_id"))
+	}
+	var records []DetectorRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+	return s.attachLabels(records)
+}
+
+// Get returns a single detector record with its label
+func (s *LabelStore) Get(recordID uint) (*LabeledRecord, error) {
+	var record DetectorRecord
+	if err := s.db.First(&record, recordID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to load record: %w", err)
+	}
+	records, err := s.attachLabels([]DetectorRecord{record})
+	if err != nil {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+func (s *LabelStore) attachLabels(records []DetectorRecord) ([]LabeledRecord, error) {
+	ids := make([]uint, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	var labels []DetectorLabel
+	if len(ids) > 0 {
+		if err := s.db.Where("record_id IN ?", ids).Find(&labels).Error; err != nil {
+			return nil, fmt.Errorf("failed to load labels: %w", err)
+		}
+	}
+	byRecord := make(map[uint]*DetectorLabel, len(labels))
+	for i := range labels {
+		byRecord[labels[i].RecordID] = &labels[i]
+	}
+	result := make([]LabeledRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, LabeledRecord{DetectorRecord: record, Label: byRecord[record.ID]})
+	}
+	return result, nil
+}
+
+// Metrics aggregates the labels, optionally restricted to one detector
+func (s *LabelStore) Metrics(detector string) (*AccuracyReport, error) {
+	query := s.db.Table("detector_labels AS l").
+		Select("r.detector_name AS detector_name, r.keywords AS keywords, l.verdict AS verdict").
+		Joins("JOIN detector_records AS r ON r.id = l.record_id")
+	if detector != "" {
+		query = query.Where("r.detector_name = ?", detector)
+	}
+	var rows []LabelRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load labels: %w", err)
+	}
+	return AggregateLabels(rows), nil
+}
+
+// LabelRow is the data needed to aggregate a single label
+type LabelRow struct {
+	DetectorName string
+	Keywords     *string
+	Verdict      string
+}
+
+// Accuracy counts the verdicts for a detector or keyword
+type Accuracy struct {
+	Detector       string  `json:"detector"`
+	Keyword        string  `json:"keyword,omitempty"`
+	Labeled        int     `json:"labeled"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// AccuracyReport is the aggregate of all labels. Keywords are sorted by false
+// positives so the rules that need tuning come first.
+type AccuracyReport struct {
+	Detectors []Accuracy `json:"detectors"`
+	Keywords  []Accuracy `json:"keywords"`
+}
+
+// AggregateLabels computes per detector and per keyword accuracy
+func AggregateLabels(rows []LabelRow) *AccuracyReport {
+	detectors := make(map[string]*Accuracy)
+	keywords := make(map[[2]string]*Accuracy)
+	for _, row := range rows {
+		d, ok := detectors[row.DetectorName]
+		if !ok {
+			d = &Accuracy{Detector: row.DetectorName}
+			detectors[row.DetectorName] = d
+		}
+		d.add(row.Verdict)
+
+		if row.Keywords == nil {
+			continue
+		}
+		var words []string
+		if err := json.Unmarshal([]byte(*row.Keywords), &words); err != nil {
+			continue
+		}
+		seen := make(map[string]struct{}, len(words))
+		for _, word := range words {
+			if _, dup := seen[word]; dup || word == "" {
+				continue
+			}
+			seen[word] = struct{}{}
+			key := [2]string{row.DetectorName, word}
+			k, ok := keywords[key]
+			if !ok {
+				k = &Accuracy{Detector: row.DetectorName, Keyword: word}
+				keywords[key] = k
+			}
+			k.add(row.Verdict)
+		}
+	}
+
+	report := &AccuracyReport{Detectors: []Accuracy{}, Keywords: []Accuracy{}}
+	for _, d := range detectors {
+		d.finish()
+		report.Detectors = append(report.Detectors, *d)
+	}
+	for _, k := range keywords {
+		k.finish()
+		report.Keywords = append(report.Keywords, *k)
+	}
+	sort.Slice(report.Detectors, func(i, j int) bool {
+		return report.Detectors[i].Detector < report.Detectors[j].Detector
+	})
+	sort.Slice(report.Keywords, func(i, j int) bool {
+		a, b := report.Keywords[i], report.Keywords[j]
+		if a.FalsePositives != b.FalsePositives {
+			return a.FalsePositives > b.FalsePositives
+		}
+		if a.Detector != b.Detector {
+			return a.Detector < b.Detector
+		}
+		return a.Keyword < b.Keyword
+	})
+	return report
+}
+
+func (a *Accuracy) add(verdict string) {
+	a.Labeled++
+	switch verdict {
+	case VerdictTruePositive:
+		a.TruePositives++
+	case VerdictFalsePositive:
+		a.FalsePositives++
+	case VerdictTrueNegative:
+		a.TrueNegatives++
+	case VerdictFalseNegative:
+		a.FalseNegatives++
+	}
+}
+
+func (a *Accuracy) finish() {
+	if flagged := a.TruePositives + a.FalsePositives; flagged > 0 {
+		a.Precision = float64(a.TruePositives) / float64(flagged)
+	}
+	if positives := a.TruePositives + a.FalseNegatives; positives > 0 {
+		a.Recall = float64(a.TruePositives) / float64(positives)
+	}
+	if a.Labeled > 0 {
+		a.Accuracy = float64(a.TruePositives+a.TrueNegatives) / float64(a.Labeled)
+	}
+}
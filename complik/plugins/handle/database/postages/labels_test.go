@@ -0,0 +1,347 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPostages(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Postgres Handler Suite")
+}
+
+func keywords(value string) *string {
+	return &value
+}
+
+var _ = Describe("Labels", func() {
+	Describe("ValidateVerdict", func() {
+		It("should only allow positives for flagged records", func() {
+			Expect(ValidateVerdict(VerdictFalsePositive, true)).To(Succeed())
+			Expect(ValidateVerdict(VerdictFalseNegative, false)).To(Succeed())
+			Expect(ValidateVerdict(VerdictTruePositive, false)).NotTo(Succeed())
+			Expect(ValidateVerdict(VerdictTrueNegative, true)).NotTo(Succeed())
+			Expect(ValidateVerdict("maybe", true)).To(MatchError(errInvalidVerdict))
+		})
+	})
+
+	Describe("AggregateLabels", func() {
+		It("should compute accuracy per detector and keyword", func() {
+			report := AggregateLabels([]LabelRow{
+				{DetectorName: "Safety", Keywords: keywords(`["casino","poker"]`), Verdict: VerdictTruePositive},
+				{DetectorName: "Safety", Keywords: keywords(`["casino"]`), Verdict: VerdictFalsePositive},
+				{DetectorName: "Safety", Keywords: keywords(`["casino"]`), Verdict: VerdictFalsePositive},
+				{DetectorName: "Safety", Verdict: VerdictTrueNegative},
+				{DetectorName: "Safety", Verdict: VerdictFalseNegative},
+				{DetectorName: "Custom", Keywords: keywords(`["trojan","trojan"]`), Verdict: VerdictTruePositive},
+			})
+
+			Expect(report.Detectors).To(HaveLen(2))
+			custom, safety := report.Detectors[0], report.Detectors[1]
+			Expect(custom.Detector).To(Equal("Custom"))
+			Expect(custom.Precision).To(BeNumerically("~", 1))
+			Expect(safety.Labeled).To(Equal(5))
+			Expect(safety.Precision).To(BeNumerically("~", 1.0/3))
+			Expect(safety.Recall).To(BeNumerically("~", 0.5))
+			Expect(safety.Accuracy).To(BeNumerically("~", 0.4))
+
+			Expect(report.Keywords[0].Keyword).To(Equal("casino"))
+			Expect(report.Keywords[0].FalsePositives).To(Equal(2))
+			Expect(report.Keywords).To(ContainElement(
+				And(HaveField("Keyword", "trojan"), HaveField("Labeled", 1))))
+		})
+	})
+
+	Describe("LabelAPI", func() {
+		var api *LabelAPI
+
+		BeforeEach(func() {
+			api = NewLabelAPI(logger.GetLogger(), "secret", NewLabelStore(nil))
+		})
+
+		It("should reject requests without the token", func() {
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/labels/metrics", nil))
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should refuse every request without a configured token", func() {
+			api = NewLabelAPI(logger.GetLogger(), "", NewLabelStore(nil))
+			for _, path := range []string{"/api/v1/records/purge", "/api/v1/records/1/label"} {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"before":"2020-01-01"}`))
+				req.Header.Set("Authorization", "Bearer ")
+				rec := httptest.NewRecorder()
+				api.ServeHTTP(rec, req)
+				Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			}
+
+			p := &DatabasePlugin{log: logger.GetLogger()}
+			Expect(p.loadConfig(`{"driver":"sqlite","labelApiAddr":":8091"}`)).
+				To(MatchError(ContainSubstring("labelApiToken")))
+			Expect(p.loadConfig(`{"driver":"sqlite","labelApiAddr":":8091","labelApiToken":"secret"}`)).
+				To(Succeed())
+		})
+
+		It("should validate label requests before touching the store", func() {
+			send := func(path, body string) int {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+				req.Header.Set("Authorization", "Bearer secret")
+				rec := httptest.NewRecorder()
+				api.ServeHTTP(rec, req)
+				return rec.Code
+			}
+			Expect(send("/api/v1/records/abc/label", `{"verdict":"true_positive","reviewer":"a"}`)).
+				To(Equal(http.StatusBadRequest))
+			Expect(send("/api/v1/records/1/label", `not json`)).To(Equal(http.StatusBadRequest))
+			Expect(send("/api/v1/records/1/label", `{"verdict":"true_positive"}`)).
+				To(Equal(http.StatusBadRequest))
+		})
+	})
+	Describe("LabelStore with SQLite", func() {
+		var store *LabelStore
+
+		BeforeEach(func() {
+			db, err := database.Open(database.Options{
+				Driver:     database.DriverSQLite,
+				SQLitePath: filepath.Join(GinkgoT().TempDir(), "labels.db"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() {
+				if sqlDB, err := db.DB(); err == nil {
+					_ = sqlDB.Close()
+				}
+			})
+			Expect(db.AutoMigrate(&DetectorRecord{}, &DetectorLabel{}, &DetectorTokenUsage{})).To(Succeed())
+			Expect(db.Create(&[]DetectorRecord{
+				{DetectorName: "safety", Host: "a.example.com", IsIllegal: true, Keywords: keywords(`["casino"]`)},
+				{DetectorName: "safety", Host: "b.example.com", IsIllegal: false},
+				{DetectorName: "custom", Host: "c.example.com", IsIllegal: true, Keywords: keywords(`["casino"]`)},
+			}).Error).To(Succeed())
+			store = NewLabelStore(db)
+		})
+
+		It("should upsert labels and list unlabeled records", func() {
+			_, err := store.Label(1, VerdictTruePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			label, err := store.Label(1, VerdictFalsePositive, "bob", "parked domain")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(label.Reviewer).To(Equal("bob"))
+
+			record, err := store.Get(1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(record.Label.Verdict).To(Equal(VerdictFalsePositive))
+
+			unlabeled, err := store.List(ListOptions{Detector: "safety", Unlabeled: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unlabeled).To(HaveLen(1))
+			Expect(unlabeled[0].Host).To(Equal("b.example.com"))
+
+			_, err = store.Label(42, VerdictTruePositive, "alice", "")
+			Expect(err).To(MatchError(ErrRecordNotFound))
+		})
+
+		It("should filter listed records", func() {
+			hosts := func(opts ListOptions) []string {
+				records, err := store.List(opts)
+				Expect(err).NotTo(HaveOccurred())
+				result := make([]string, 0, len(records))
+				for _, record := range records {
+					result = append(result, record.Host)
+				}
+				return result
+			}
+			compliant := false
+			Expect(hosts(ListOptions{IsIllegal: &compliant})).To(Equal([]string{"b.example.com"}))
+			Expect(hosts(ListOptions{Keyword: "casino"})).To(Equal([]string{"c.example.com", "a.example.com"}))
+			Expect(hosts(ListOptions{Host: "a.example.com"})).To(Equal([]string{"a.example.com"}))
+			Expect(hosts(ListOptions{Namespace: "ns-missing"})).To(BeEmpty())
+			Expect(hosts(ListOptions{To: time.Now().Add(-time.Hour)})).To(BeEmpty())
+			Expect(hosts(ListOptions{From: time.Now().Add(-time.Hour)})).To(HaveLen(3))
+		})
+
+		It("should store records with personal data redacted", func() {
+			p := &DatabasePlugin{log: logger.GetLogger(), db: store.db}
+			Expect(p.loadConfig(`{"driver":"sqlite","redactPII":true,"redactMode":"hash","redactSalt":"salt"}`)).To(Succeed())
+			Expect(p.saveResults(&models.DetectorInfo{
+				DetectorName: "safety",
+				Host:         "d.example.com",
+				IsIllegal:    true,
+				Description:  "收款人身份证 11010519491231002X，电话 13812345678",
+			})).To(Succeed())
+
+			var record DetectorRecord
+			Expect(store.db.Where("host = ?", "d.example.com").First(&record).Error).To(Succeed())
+			Expect(record.Description).To(MatchRegexp(`^收款人身份证 \[id_number:[0-9a-f]{12}\]，电话 \[phone:[0-9a-f]{12}\]$`))
+			Expect(record.Redactions).To(Equal(2))
+		})
+
+		It("should aggregate metrics from the joined tables", func() {
+			_, err := store.Label(1, VerdictFalsePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = store.Label(2, VerdictTrueNegative, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = store.Label(3, VerdictTruePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := store.Metrics("safety")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Detectors).To(HaveLen(1))
+			Expect(report.Detectors[0].Labeled).To(Equal(2))
+			Expect(report.Detectors[0].FalsePositives).To(Equal(1))
+			Expect(report.Keywords[0].Keyword).To(Equal("casino"))
+		})
+
+		It("should aggregate violations in the dashboard views and export them", func() {
+			Expect(store.db.Create(&[]DetectorRecord{
+				{DetectorName: "safety", Region: "hzh", Namespace: "ns-a", IsIllegal: true},
+				{DetectorName: "safety", Region: "bja", Namespace: "ns-b", IsIllegal: true,
+					CreatedAt: time.Now().AddDate(0, 0, -3)},
+			}).Error).To(Succeed())
+			_, err := store.Label(1, VerdictTruePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = store.Label(3, VerdictFalsePositive, "alice", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(MigrateViews(store.db)).To(Succeed())
+			// Migrations run on every start
+			Expect(MigrateViews(store.db)).To(Succeed())
+
+			var detectors []DetectorViolations
+			Expect(store.db.Table(ViewViolationsPerDetector).Order("detector_name").Find(&detectors).Error).To(Succeed())
+			Expect(detectors).To(Equal([]DetectorViolations{
+				{DetectorName: "custom", Records: 1, Violations: 1, Dismissed: 1},
+				{DetectorName: "safety", Records: 4, Violations: 3, Confirmed: 1, Pending: 2},
+			}))
+			var regions []RegionViolations
+			Expect(store.db.Table(ViewViolationsPerRegion).Order("region").Find(&regions).Error).To(Succeed())
+			Expect(regions).To(HaveLen(3))
+			Expect(regions[0]).To(Equal(RegionViolations{Region: "", Violations: 2, Namespaces: 1, Confirmed: 1, Dismissed: 1}))
+			var days []DayViolations
+			Expect(store.db.Table(ViewViolationsPerDay).Order("day").Find(&days).Error).To(Succeed())
+			Expect(days).To(HaveLen(2))
+			Expect(days[1].Day).To(Equal(time.Now().Format(time.DateOnly)))
+			Expect(days[1].Violations).To(Equal(3))
+
+			exporter := NewPostureExporter(logger.GetLogger(), store.db)
+			Expect(testutil.CollectAndCount(exporter, "complik_violations")).To(BeZero())
+			Expect(exporter.Refresh(context.Background())).To(Succeed())
+			Expect(testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP complik_region_violations Stored violations per region and review status.
+# TYPE complik_region_violations gauge
+complik_region_violations{region="",status="confirmed"} 1
+complik_region_violations{region="",status="dismissed"} 1
+complik_region_violations{region="",status="pending_review"} 0
+complik_region_violations{region="bja",status="confirmed"} 0
+complik_region_violations{region="bja",status="dismissed"} 0
+complik_region_violations{region="bja",status="pending_review"} 1
+complik_region_violations{region="hzh",status="confirmed"} 0
+complik_region_violations{region="hzh",status="dismissed"} 0
+complik_region_violations{region="hzh",status="pending_review"} 1
+# HELP complik_violations_today Violations detected since midnight of the database time zone.
+# TYPE complik_violations_today gauge
+complik_violations_today 3
+`), "complik_region_violations", "complik_violations_today")).To(Succeed())
+		})
+
+		Describe("compliance reports", func() {
+			var from, to time.Time
+
+			BeforeEach(func() {
+				from, to = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+				Expect(store.db.Create(&[]DetectorRecord{
+					{DetectorName: "safety", Namespace: "ns-demo", Name: "shop", Host: "shop.example.com",
+						URL: "https://shop.example.com", Path: keywords(`["/", "/pay"]`), IsIllegal: true,
+						Description: "gambling <script>", Keywords: keywords(`["casino"]`)},
+					{DetectorName: "safety", Namespace: "ns-demo", Host: "ok.example.com", IsIllegal: false},
+					{DetectorName: "custom", Namespace: "ns-demo", Host: "blog.example.com", IsIllegal: true},
+					{DetectorName: "custom", Namespace: "ns-other", Host: "x.example.com", IsIllegal: true,
+						CreatedAt: from.Add(-24 * time.Hour)},
+				}).Error).To(Succeed())
+				store.region = "hzh"
+			})
+
+			It("should report the violations of a namespace with their review status", func() {
+				_, err := store.Label(4, VerdictTruePositive, "alice", "")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = store.Label(6, VerdictFalsePositive, "bob", "blog post about casinos")
+				Expect(err).NotTo(HaveOccurred())
+
+				r, err := store.Report("ns-demo", from, to)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(r.Region).To(Equal("hzh"))
+				Expect(r.Summary).To(Equal(report.Summary{Total: 2, Confirmed: 1, Dismissed: 1}))
+				Expect(r.Violations[0].Evidence).To(Equal([]string{
+					"https://shop.example.com", "https://shop.example.com/", "https://shop.example.com/pay",
+				}))
+				Expect(r.Violations[1].Status).To(Equal(report.StatusDismissed))
+				Expect(r.Violations[1].Comment).To(Equal("blog post about casinos"))
+
+				namespaces, err := store.ReportNamespaces(from, to)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(namespaces).To(Equal([]string{"ns-demo"}))
+			})
+
+			It("should serve reports and write them on schedule", func() {
+				api := NewLabelAPI(logger.GetLogger(), "secret", store)
+				get := func(path string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodGet, path, nil)
+					req.Header.Set("Authorization", "Bearer secret")
+					rec := httptest.NewRecorder()
+					api.ServeHTTP(rec, req)
+					return rec
+				}
+				rec := get("/api/v1/reports/ns-demo?format=html")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+				Expect(rec.Body.String()).To(ContainSubstring("gambling &lt;script&gt;"))
+				Expect(rec.Body.String()).To(ContainSubstring(`href="https://shop.example.com/pay"`))
+
+				rec = get("/api/v1/reports/ns-demo?format=pdf&from=2020-01-01")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).To(HavePrefix("%PDF-"))
+				Expect(get("/api/v1/reports/ns-demo?format=doc").Code).To(Equal(http.StatusBadRequest))
+				Expect(get("/api/v1/reports/ns-demo?from=2030-01-01&to=2020-01-01").Code).
+					To(Equal(http.StatusBadRequest))
+
+				scheduler := &reportScheduler{
+					log:    logger.GetLogger(),
+					store:  store,
+					dir:    filepath.Join(GinkgoT().TempDir(), "reports"),
+					format: report.FormatHTML,
+					period: 2 * time.Hour,
+				}
+				Expect(scheduler.generate(to)).To(Succeed())
+				files, err := filepath.Glob(filepath.Join(scheduler.dir, "*.html"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(HaveLen(1))
+				Expect(filepath.Base(files[0])).To(HavePrefix("complik-report-ns-demo-"))
+			})
+		})
+	})
+})
@@ -22,19 +22,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"net/http"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/redact"
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
-	"gorm.io/driver/mysql"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
-	gormLogger "gorm.io/gorm/logger"
 )
 
 const (
@@ -54,9 +57,34 @@ type DatabasePlugin struct {
 	log            logger.Logger
 	db             *gorm.DB
 	databaseConfig DatabaseConfig
+	server         *http.Server
+	metricsServer  *http.Server
+	appealServer   *http.Server
+	// redactor strips personal data before records are stored, nil when
+	// RedactPII is off
+	redactor *redact.Redactor
+	// spill buffers the records that could not be written, nil when
+	// SpillDir is not set
+	spill *SpillBuffer
+	// archiveStore keeps the archived records, nil when neither ArchiveDir
+	// nor ArchiveBucket is set
+	archiveStore ArchiveStore
+	// archiver purges the expired records, nil unless the retention job or
+	// the label API runs
+	archiver *RecordArchiver
+
+	// batch holds the records waiting for the next flush, only touched by
+	// the event loop
+	batch []DetectorRecord
+	// cancel stops the event loop, which closes done after its last flush
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 type DatabaseConfig struct {
-	Region       string `json:"region"`
+	Region string `json:"region"`
+	// Driver is "mysql" (default) or "sqlite" for the embedded single-binary mode
+	Driver       string `json:"driver"`
+	SQLitePath   string `json:"sqlitePath"`
 	Host         string `json:"host"`
 	Port         string `json:"port"`
 	Username     string `json:"username"`
@@ -64,14 +92,127 @@ type DatabaseConfig struct {
 	DatabaseName string `json:"databaseName"`
 	TableName    string `json:"tableName"`
 	Charset      string `json:"charset"`
+
+	// LabelAPIAddr enables the golden dataset labeling API when set. It also
+	// serves the compliance reports.
+	LabelAPIAddr  string `json:"labelApiAddr"`
+	LabelAPIToken string `json:"labelApiToken"`
+
+	// ReportDir enables scheduled compliance reports: every
+	// ReportIntervalHour the report of each namespace with violations in the
+	// last ReportPeriodDay days is written to it
+	ReportDir          string `json:"reportDir"`
+	ReportFormat       string `json:"reportFormat"`
+	ReportIntervalHour int    `json:"reportIntervalHour"`
+	ReportPeriodDay    int    `json:"reportPeriodDay"`
+	// ReportFontPath is a TrueType font for PDF reports with non-Latin text
+	ReportFontPath string `json:"reportFontPath"`
+
+	// MetricsAddr serves the aggregates of the dashboard views as Prometheus
+	// metrics on /metrics when set; they are refreshed every
+	// MetricsIntervalSecond
+	MetricsAddr           string `json:"metricsAddr"`
+	MetricsIntervalSecond int    `json:"metricsIntervalSecond"`
+
+	// AppealAPIAddr serves the tenant appeals when set. Tenants authenticate
+	// with the AppealToken of AppealSecret and their namespace, reviewers with
+	// LabelAPIToken.
+	AppealAPIAddr string `json:"appealApiAddr"`
+	AppealSecret  string `json:"appealSecret"`
+	// AppealAutoUnlock unlocks the namespace of approved appeals with a
+	// block-controller BlockRequest created in AppealUnlockNamespace
+	AppealAutoUnlock      bool   `json:"appealAutoUnlock"`
+	AppealUnlockNamespace string `json:"appealUnlockNamespace"`
+
+	// RedactPII strips email addresses, phone numbers and identity numbers
+	// from the stored descriptions. RedactMode is "mask" (default) or "hash",
+	// which keeps an HMAC of the value keyed with RedactSalt.
+	RedactPII  bool   `json:"redactPII"`
+	RedactMode string `json:"redactMode"`
+	RedactSalt string `json:"redactSalt"`
+
+	// SpillDir keeps the records that could not be written while the
+	// database is unavailable, up to SpillMaxMB, and replays them every
+	// SpillReplayIntervalSecond once it recovered
+	SpillDir                  string `json:"spillDir"`
+	SpillMaxMB                int    `json:"spillMaxMB"`
+	SpillReplayIntervalSecond int    `json:"spillReplayIntervalSecond"`
+
+	// BatchSize records are written at once, the records received since the
+	// last write are flushed every FlushIntervalSecond
+	BatchSize           int `json:"batchSize"`
+	FlushIntervalSecond int `json:"flushIntervalSecond"`
+	// DedupMinute skips a detection event delivered again within that many
+	// minutes, as told by its idempotency key, instead of counting it again
+	DedupMinute int `json:"dedupMinute"`
+
+	// StoreTranscripts keeps the model transcripts captured by the detectors
+	// for TranscriptRetentionDay days, redacted like the records
+	StoreTranscripts       bool `json:"storeTranscripts"`
+	TranscriptRetentionDay int  `json:"transcriptRetentionDay"`
+
+	// ModelPricing prices the tokens of the reviews per model, in USD per
+	// million tokens
+	ModelPricing Pricing `json:"modelPricing"`
+
+	// RecordRetentionDay moves the records last seen more than that many days
+	// ago to the archive every ArchiveIntervalHour, 0 keeps them in the
+	// database. Records are archived as gzip compressed CSV objects of
+	// ArchiveBatchSize records, to ArchiveDir or to ArchiveBucket of the S3
+	// compatible ArchiveEndpoint, under ArchivePrefix.
+	RecordRetentionDay  int    `json:"recordRetentionDay"`
+	ArchiveIntervalHour int    `json:"archiveIntervalHour"`
+	ArchiveBatchSize    int    `json:"archiveBatchSize"`
+	ArchiveDir          string `json:"archiveDir"`
+	ArchiveEndpoint     string `json:"archiveEndpoint"`
+	ArchiveBucket       string `json:"archiveBucket"`
+	ArchiveRegion       string `json:"archiveRegion"`
+	ArchiveAccessKey    string `json:"archiveAccessKey"`
+	ArchiveSecretKey    string `json:"archiveSecretKey"`
+	ArchivePrefix       string `json:"archivePrefix"`
 }
 
 func (p *DatabasePlugin) getDefaultConfig() DatabaseConfig {
 	return DatabaseConfig{
+		Driver:       database.DriverMySQL,
 		DatabaseName: "complik",
 		Charset:      "utf8mb4",
 		TableName:    "detectorRecord",
 		Region:       "UNKNOWN",
+
+		ReportFormat:       report.FormatPDF,
+		ReportIntervalHour: 24,
+		ReportPeriodDay:    30,
+
+		MetricsIntervalSecond: 60,
+
+		AppealUnlockNamespace: "system",
+
+		SpillMaxMB:                64,
+		SpillReplayIntervalSecond: 30,
+
+		BatchSize:           100,
+		FlushIntervalSecond: 1,
+		DedupMinute:         10,
+
+		TranscriptRetentionDay: 30,
+
+		ArchiveIntervalHour: 24,
+		ArchiveBatchSize:    5000,
+		ArchiveRegion:       "us-east-1",
+	}
+}
+
+func (p *DatabasePlugin) dbOptions() database.Options {
+	return database.Options{
+		Driver:       p.databaseConfig.Driver,
+		Host:         p.databaseConfig.Host,
+		Port:         p.databaseConfig.Port,
+		Username:     p.databaseConfig.Username,
+		Password:     p.databaseConfig.Password,
+		DatabaseName: p.databaseConfig.DatabaseName,
+		Charset:      p.databaseConfig.Charset,
+		SQLitePath:   p.databaseConfig.SQLitePath,
 	}
 }
 
@@ -92,30 +233,36 @@ func (p *DatabasePlugin) loadConfig(setting string) error {
 		})
 		return err
 	}
-	if configFromJSON.Host == "" {
-		return errors.New("host configuration cannot be empty")
-	}
-	if configFromJSON.Port == "" {
-		return errors.New("port configuration cannot be empty")
-	}
-	if configFromJSON.Username == "" {
-		return errors.New("username configuration cannot be empty")
+	if configFromJSON.Driver != "" {
+		p.databaseConfig.Driver = configFromJSON.Driver
 	}
-	if configFromJSON.Password == "" {
-		return errors.New("password configuration cannot be empty")
+	p.databaseConfig.SQLitePath = configFromJSON.SQLitePath
+	if err := (database.Options{
+		Driver:   p.databaseConfig.Driver,
+		Host:     configFromJSON.Host,
+		Port:     configFromJSON.Port,
+		Username: configFromJSON.Username,
+		Password: configFromJSON.Password,
+	}).Validate(); err != nil {
+		return err
 	}
 
 	p.databaseConfig.Host = configFromJSON.Host
 	p.databaseConfig.Port = configFromJSON.Port
 	p.databaseConfig.Username = configFromJSON.Username
 
-	// Support secure password from environment variable or encryption
-	if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
-		p.databaseConfig.Password = pwd
-		p.log.Debug("Using secure password from environment/encryption")
-	} else {
-		p.databaseConfig.Password = configFromJSON.Password
-		p.log.Warn("Using plain text password - consider using environment variables")
+	// Support secure password from environment variable or encryption.
+	// SQLite mode runs without a password.
+	if configFromJSON.Password != "" {
+		if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
+			p.databaseConfig.Password = pwd
+			p.log.Debug("Using secure password from environment/encryption")
+		} else if config.IsSecretReference(configFromJSON.Password) {
+			return fmt.Errorf("failed to resolve password: %w", err)
+		} else {
+			p.databaseConfig.Password = configFromJSON.Password
+			p.log.Warn("Using plain text password - consider using environment variables")
+		}
 	}
 
 	p.databaseConfig.Region = configFromJSON.Region
@@ -131,33 +278,161 @@ func (p *DatabasePlugin) loadConfig(setting string) error {
 	if configFromJSON.TableName != "" {
 		p.databaseConfig.TableName = configFromJSON.TableName
 	}
+	p.databaseConfig.LabelAPIAddr = configFromJSON.LabelAPIAddr
+	if configFromJSON.LabelAPIToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.LabelAPIToken); err == nil {
+			p.databaseConfig.LabelAPIToken = token
+		} else if config.IsSecretReference(configFromJSON.LabelAPIToken) {
+			return fmt.Errorf("failed to resolve label API token: %w", err)
+		} else {
+			p.databaseConfig.LabelAPIToken = configFromJSON.LabelAPIToken
+		}
+	}
+	// The label API writes labels and purges records, it is never served
+	// unauthenticated
+	if p.databaseConfig.LabelAPIAddr != "" && p.databaseConfig.LabelAPIToken == "" {
+		return errors.New("labelApiToken configuration cannot be empty when labelApiAddr is set")
+	}
+
+	p.databaseConfig.ReportDir = configFromJSON.ReportDir
+	p.databaseConfig.ReportFontPath = configFromJSON.ReportFontPath
+	if configFromJSON.ReportFormat != "" {
+		format, err := report.ParseFormat(configFromJSON.ReportFormat)
+		if err != nil {
+			return err
+		}
+		p.databaseConfig.ReportFormat = format
+	}
+	if configFromJSON.ReportIntervalHour > 0 {
+		p.databaseConfig.ReportIntervalHour = configFromJSON.ReportIntervalHour
+	}
+	if configFromJSON.ReportPeriodDay > 0 {
+		p.databaseConfig.ReportPeriodDay = configFromJSON.ReportPeriodDay
+	}
+	p.databaseConfig.MetricsAddr = configFromJSON.MetricsAddr
+	if configFromJSON.MetricsIntervalSecond > 0 {
+		p.databaseConfig.MetricsIntervalSecond = configFromJSON.MetricsIntervalSecond
+	}
+
+	p.databaseConfig.AppealAPIAddr = configFromJSON.AppealAPIAddr
+	if configFromJSON.AppealSecret != "" {
+		if secret, err := config.GetSecureValue(configFromJSON.AppealSecret); err == nil {
+			p.databaseConfig.AppealSecret = secret
+		} else if config.IsSecretReference(configFromJSON.AppealSecret) {
+			return fmt.Errorf("failed to resolve appeal secret: %w", err)
+		} else {
+			p.databaseConfig.AppealSecret = configFromJSON.AppealSecret
+		}
+	}
+	if p.databaseConfig.AppealAPIAddr != "" && p.databaseConfig.AppealSecret == "" {
+		return errors.New("appealSecret is required to serve the appeal API")
+	}
+	// Approving an appeal can unlock a namespace, the decisions are never
+	// served unauthenticated next to the tenant routes
+	if p.databaseConfig.AppealAPIAddr != "" && p.databaseConfig.LabelAPIToken == "" {
+		return errors.New("labelApiToken is required to serve the appeal API")
+	}
+	p.databaseConfig.AppealAutoUnlock = configFromJSON.AppealAutoUnlock
+	if configFromJSON.AppealUnlockNamespace != "" {
+		p.databaseConfig.AppealUnlockNamespace = configFromJSON.AppealUnlockNamespace
+	}
+
+	p.databaseConfig.RedactPII = configFromJSON.RedactPII
+	p.databaseConfig.RedactMode = configFromJSON.RedactMode
+	if configFromJSON.RedactSalt != "" {
+		if salt, err := config.GetSecureValue(configFromJSON.RedactSalt); err == nil {
+			p.databaseConfig.RedactSalt = salt
+		} else if config.IsSecretReference(configFromJSON.RedactSalt) {
+			return fmt.Errorf("failed to resolve redaction salt: %w", err)
+		} else {
+			p.databaseConfig.RedactSalt = configFromJSON.RedactSalt
+		}
+	}
+	p.redactor = nil
+	if p.databaseConfig.RedactPII {
+		redactor, err := redact.New(p.databaseConfig.RedactMode, p.databaseConfig.RedactSalt)
+		if err != nil {
+			return err
+		}
+		p.redactor = redactor
+	}
+
+	p.databaseConfig.SpillDir = configFromJSON.SpillDir
+	if configFromJSON.SpillMaxMB > 0 {
+		p.databaseConfig.SpillMaxMB = configFromJSON.SpillMaxMB
+	}
+	if configFromJSON.SpillReplayIntervalSecond > 0 {
+		p.databaseConfig.SpillReplayIntervalSecond = configFromJSON.SpillReplayIntervalSecond
+	}
+	if configFromJSON.BatchSize > 0 {
+		p.databaseConfig.BatchSize = configFromJSON.BatchSize
+	}
+	if configFromJSON.FlushIntervalSecond > 0 {
+		p.databaseConfig.FlushIntervalSecond = configFromJSON.FlushIntervalSecond
+	}
+	if configFromJSON.DedupMinute > 0 {
+		p.databaseConfig.DedupMinute = configFromJSON.DedupMinute
+	}
+	p.databaseConfig.StoreTranscripts = configFromJSON.StoreTranscripts
+	if configFromJSON.TranscriptRetentionDay > 0 {
+		p.databaseConfig.TranscriptRetentionDay = configFromJSON.TranscriptRetentionDay
+	}
+	p.databaseConfig.ModelPricing = configFromJSON.ModelPricing
+	if err := p.loadArchiveConfig(configFromJSON); err != nil {
+		return err
+	}
 
 	p.log.Info("Database configuration loaded", logger.Fields{
+		"driver":   p.databaseConfig.Driver,
 		"host":     p.databaseConfig.Host,
 		"port":     p.databaseConfig.Port,
 		"database": p.databaseConfig.DatabaseName,
 		"table":    p.databaseConfig.TableName,
 		"region":   p.databaseConfig.Region,
+		"redact":   p.databaseConfig.RedactPII,
+		"spill":    p.databaseConfig.SpillDir,
 	})
 
 	return nil
 }
 
 type DetectorRecord struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	DiscoveryName string    `gorm:"size:255"   json:"discovery_name"`
-	CollectorName string    `gorm:"size:255"   json:"collector_name"`
-	DetectorName  string    `gorm:"size:255"   json:"detector_name"`
-	Name          string    `gorm:"size:255"   json:"name"`
-	Namespace     string    `gorm:"size:255"   json:"namespace"`
-	Host          string    `gorm:"size:255"   json:"host"`
-	Path          *string   `gorm:"type:json"  json:"path"`
-	URL           string    `gorm:"size:500"   json:"url"`
-	IsIllegal     bool      `                  json:"is_illegal"`
-	Description   string    `gorm:"type:text"  json:"description,omitempty"`
-	Keywords      *string   `gorm:"type:json"  json:"keywords,omitempty"`
-	CreatedAt     time.Time `                  json:"created_at"`
-	UpdatedAt     time.Time `                  json:"updated_at"`
+	ID                uint       `gorm:"primaryKey"          json:"id"`
+	DiscoveryName     string     `gorm:"size:255"            json:"discovery_name"`
+	CollectorName     string     `gorm:"size:255"            json:"collector_name"`
+	DetectorName      string     `gorm:"size:255"            json:"detector_name"`
+	Name              string     `gorm:"size:255"            json:"name"`
+	Namespace         string     `gorm:"size:255"            json:"namespace"`
+	Region            string     `gorm:"size:64;index"       json:"region,omitempty"`
+	Host              string     `gorm:"size:255"            json:"host"`
+	Path              *string    `gorm:"type:json"           json:"path"`
+	URL               string     `gorm:"size:500"            json:"url"`
+	IsIllegal         bool       `                           json:"is_illegal"`
+	Unchanged         bool       `                           json:"unchanged,omitempty"`
+	Description       string     `gorm:"type:text"           json:"description,omitempty"`
+	Keywords          *string    `gorm:"type:json"           json:"keywords,omitempty"`
+	Severity          string     `gorm:"size:32"             json:"severity,omitempty"`
+	WorkloadKind      string     `gorm:"size:64"             json:"workload_kind,omitempty"`
+	WorkloadName      string     `gorm:"size:255"            json:"workload_name,omitempty"`
+	Images            *string    `gorm:"type:json"           json:"images,omitempty"`
+	OwnerUserID       string     `gorm:"size:255;index"      json:"owner_user_id,omitempty"`
+	OwnerTeam         string     `gorm:"size:255"            json:"owner_team,omitempty"`
+	Redactions        int        `                           json:"redactions,omitempty"`
+	ContentHash       string     `gorm:"size:64"             json:"content_hash,omitempty"`
+	FindingKey        *string    `gorm:"size:64;uniqueIndex" json:"-"`
+	Occurrences       int        `gorm:"default:1"           json:"occurrences"`
+	WorkloadCreatedAt *time.Time `                           json:"workload_created_at,omitempty"`
+	CreatedAt         time.Time  `                           json:"created_at"`
+	UpdatedAt         time.Time  `                           json:"updated_at"`
+
+	// EventKeys are the idempotency keys of the events of the record, they
+	// are kept in the spill buffer
+	EventKeys []string `gorm:"-" json:"event_keys,omitempty"`
+
+	// transcript is stored with the record when transcripts are kept
+	transcript *DetectorTranscript
+	// usage is added to the token usage of the day
+	usage *models.TokenUsage
 }
 
 func (p *DatabasePlugin) Name() string { return pluginName }
@@ -187,7 +462,7 @@ func (p *DatabasePlugin) Start(
 	}
 
 	p.log.Debug("Running database migration")
-	if err := p.db.AutoMigrate(&DetectorRecord{}); err != nil {
+	if err := p.db.AutoMigrate(&DetectorRecord{}, &DetectorLabel{}, &Appeal{}, &DetectorTranscript{}, &DetectorTokenUsage{}, &HandledEvent{}); err != nil {
 		p.log.Error("Database migration failed", logger.Fields{
 			"error": err.Error(),
 			"table": p.databaseConfig.TableName,
@@ -195,7 +470,45 @@ func (p *DatabasePlugin) Start(
 		return fmt.Errorf("database migration failed: %w", err)
 	}
 
+	// The views only serve dashboards, storing results works without them
+	if err := MigrateViews(p.db); err != nil {
+		p.log.Warn("Failed to create dashboard views", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
 	p.log.Info("Database migration completed successfully")
+	p.archiver = nil
+	if p.databaseConfig.RecordRetentionDay > 0 || p.databaseConfig.LabelAPIAddr != "" {
+		p.archiver = NewRecordArchiver(p.log, p.db, p.archiveStore,
+			p.databaseConfig.ArchivePrefix, p.databaseConfig.ArchiveBatchSize)
+	}
+	if p.databaseConfig.RecordRetentionDay > 0 {
+		p.startRecordRetention(ctx)
+	}
+	p.startHandledEventPurge(ctx)
+	if p.databaseConfig.SpillDir != "" {
+		if err := p.startSpill(ctx); err != nil {
+			return err
+		}
+	}
+	if p.databaseConfig.LabelAPIAddr != "" {
+		p.startLabelAPI()
+	}
+	if p.databaseConfig.ReportDir != "" {
+		p.startReportScheduler(ctx)
+	}
+	if p.databaseConfig.StoreTranscripts {
+		p.startTranscriptRetention(ctx)
+	}
+	if p.databaseConfig.MetricsAddr != "" {
+		p.startMetricsServer(ctx)
+	}
+	if p.databaseConfig.AppealAPIAddr != "" {
+		if err := p.startAppealAPI(eventBus); err != nil {
+			return err
+		}
+	}
 	subscribe := eventBus.Subscribe(constants.DetectorTopic)
 	p.log.Debug("Subscribed to detector topic", logger.Fields{
 		"topic": constants.DetectorTopic,
@@ -203,64 +516,310 @@ func (p *DatabasePlugin) Start(
 
 	p.log.Info("Database plugin started successfully")
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				p.log.Error("Database plugin panic", logger.Fields{
-					"panic": fmt.Sprintf("%v", r),
+	loopCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.consume(loopCtx, subscribe)
+
+	return nil
+}
+
+// consume batches the detection results of subscribe until ctx is done
+func (p *DatabasePlugin) consume(ctx context.Context, subscribe eventbus.EventChan) {
+	defer close(p.done)
+	defer p.flush()
+	defer func() {
+		if r := recover(); r != nil {
+			p.log.Error("Database plugin panic", logger.Fields{
+				"panic": fmt.Sprintf("%v", r),
+			})
+		}
+	}()
+
+	ticker := time.NewTicker(time.Duration(p.databaseConfig.FlushIntervalSecond) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush()
+		case event, ok := <-subscribe:
+			if !ok {
+				p.log.Info("Event subscription channel closed")
+				return
+			}
+
+			result, ok := event.Payload.(*models.DetectorInfo)
+			if !ok {
+				p.log.Error("Invalid event payload type", logger.Fields{
+					"expected": "*models.DetectorInfo",
+					"actual":   fmt.Sprintf("%T", event.Payload),
 				})
+				continue
 			}
-		}()
 
+			result.Region = p.databaseConfig.Region
+
+			p.log.Debug("Queueing detection result for the database", logger.Fields{
+				"host":       result.Host,
+				"namespace":  result.Namespace,
+				"is_illegal": result.IsIllegal,
+			})
+
+			record := p.newRecord(result)
+			if event.Key != "" {
+				record.EventKeys = []string{event.Key}
+			}
+			p.batch = append(p.batch, record)
+			if len(p.batch) >= p.databaseConfig.BatchSize {
+				p.flush()
+			}
+		case <-ctx.Done():
+			p.log.Info("Database plugin stopping")
+			return
+		}
+	}
+}
+
+// startLabelAPI serves the labeling endpoints used to build the golden dataset
+func (p *DatabasePlugin) startLabelAPI() {
+	api := NewLabelAPI(p.log, p.databaseConfig.LabelAPIToken, p.labelStore())
+	api.reportOptions = p.reportOptions()
+	api.archiver = p.archiver
+	p.server = &http.Server{
+		Addr:              p.databaseConfig.LabelAPIAddr,
+		Handler:           api,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Label API server started", logger.Fields{
+			"addr": p.databaseConfig.LabelAPIAddr,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Label API server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// startReportScheduler writes the compliance reports of all namespaces with
+// violations to the report directory once per interval
+func (p *DatabasePlugin) startReportScheduler(ctx context.Context) {
+	scheduler := &reportScheduler{
+		log:      p.log,
+		store:    p.labelStore(),
+		dir:      p.databaseConfig.ReportDir,
+		format:   p.databaseConfig.ReportFormat,
+		interval: time.Duration(p.databaseConfig.ReportIntervalHour) * time.Hour,
+		period:   time.Duration(p.databaseConfig.ReportPeriodDay) * 24 * time.Hour,
+		options:  p.reportOptions(),
+	}
+	p.log.Info("Scheduled compliance reports enabled", logger.Fields{
+		"dir":           scheduler.dir,
+		"format":        scheduler.format,
+		"interval_hour": p.databaseConfig.ReportIntervalHour,
+	})
+	go scheduler.run(ctx)
+}
+
+// startMetricsServer exports the compliance posture to Prometheus. The
+// exporter has its own registry so restarting the plugin registers it again.
+func (p *DatabasePlugin) startMetricsServer(ctx context.Context) {
+	exporter := NewPostureExporter(p.log, p.db)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter)
+	if p.spill != nil {
+		registry.MustRegister(p.spill)
+	}
+	if p.archiver != nil {
+		registry.MustRegister(p.archiver)
+	}
+	go exporter.Run(ctx, time.Duration(p.databaseConfig.MetricsIntervalSecond)*time.Second)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	p.metricsServer = &http.Server{
+		Addr:              p.databaseConfig.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Compliance posture metrics server started", logger.Fields{
+			"addr": p.databaseConfig.MetricsAddr,
+		})
+		if err := p.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Compliance posture metrics server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// startAppealAPI serves the tenant appeals. Submitted and decided appeals are
+// published on the appeal topic so the notification handlers reach the
+// compliance staff.
+func (p *DatabasePlugin) startAppealAPI(eventBus *eventbus.EventBus) error {
+	api := NewAppealAPI(p.log, p.databaseConfig.AppealSecret, p.databaseConfig.LabelAPIToken, NewAppealStore(p.db))
+	api.region = p.databaseConfig.Region
+	api.publish = func(event *models.AppealEvent) {
+		eventBus.Publish(constants.AppealTopic, eventbus.Event{Payload: event})
+	}
+	if p.databaseConfig.AppealAutoUnlock {
+		if k8s.DynamicClient == nil {
+			return errors.New("kubernetes client is not initialized, appeals cannot unlock namespaces")
+		}
+		api.unlocker = NewBlockRequestUnlocker(k8s.DynamicClient, p.databaseConfig.AppealUnlockNamespace)
+	}
+	p.appealServer = &http.Server{
+		Addr:              p.databaseConfig.AppealAPIAddr,
+		Handler:           api,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Appeal API server started", logger.Fields{
+			"addr":        p.databaseConfig.AppealAPIAddr,
+			"auto_unlock": p.databaseConfig.AppealAutoUnlock,
+		})
+		if err := p.appealServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Appeal API server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+	return nil
+}
+
+// startSpill opens the spill buffer and replays the records in it, including
+// those spilled before a restart, whenever the database is reachable
+func (p *DatabasePlugin) startSpill(ctx context.Context) error {
+	spill, err := NewSpillBuffer(p.databaseConfig.SpillDir, int64(p.databaseConfig.SpillMaxMB)<<20)
+	if err != nil {
+		return err
+	}
+	p.spill = spill
+	p.log.Info("Spill buffer enabled", logger.Fields{
+		"dir":     p.databaseConfig.SpillDir,
+		"max_mb":  p.databaseConfig.SpillMaxMB,
+		"pending": spill.Pending(),
+	})
+	go func() {
+		ticker := time.NewTicker(time.Duration(p.databaseConfig.SpillReplayIntervalSecond) * time.Second)
+		defer ticker.Stop()
 		for {
+			p.replaySpill(ctx)
 			select {
-			case event, ok := <-subscribe:
-				if !ok {
-					p.log.Info("Event subscription channel closed")
-					return
-				}
-
-				result, ok := event.Payload.(*models.DetectorInfo)
-				if !ok {
-					p.log.Error("Invalid event payload type", logger.Fields{
-						"expected": "*models.DetectorInfo",
-						"actual":   fmt.Sprintf("%T", event.Payload),
-					})
-					continue
-				}
-
-				result.Region = p.databaseConfig.Region
-
-				p.log.Debug("Saving detection result to database", logger.Fields{
-					"host":       result.Host,
-					"namespace":  result.Namespace,
-					"is_illegal": result.IsIllegal,
-				})
-
-				if err := p.saveResults(result); err != nil {
-					p.log.Error("Failed to save result to database", logger.Fields{
-						"error":     err.Error(),
-						"host":      result.Host,
-						"namespace": result.Namespace,
-					})
-				} else {
-					p.log.Debug("Result saved successfully", logger.Fields{
-						"host": result.Host,
-					})
-				}
 			case <-ctx.Done():
-				p.log.Info("Database plugin stopping")
 				return
+			case <-ticker.C:
 			}
 		}
 	}()
-
 	return nil
 }
 
+// flush writes the batched records
+func (p *DatabasePlugin) flush() {
+	if len(p.batch) == 0 {
+		return
+	}
+	records := p.batch
+	p.batch = nil
+	if err := p.writeRecords(records); err != nil {
+		p.log.Error("Failed to save results to database", logger.Fields{
+			"error":   err.Error(),
+			"records": len(records),
+		})
+	}
+}
+
+// replaySpill writes the spilled records once the database answers again
+func (p *DatabasePlugin) replaySpill(ctx context.Context) {
+	if p.spill.Pending() == 0 || database.Ping(ctx, p.db) != nil {
+		return
+	}
+	healthy := func() bool { return database.Ping(ctx, p.db) == nil }
+	recovered, err := p.spill.Replay(func(record *DetectorRecord) error {
+		// The finding key is not part of the spilled JSON
+		record.setFindingKey()
+		records := p.dropHandled([]DetectorRecord{*record})
+		if len(records) == 0 {
+			return nil
+		}
+		return storeRecords(p.db.WithContext(ctx), records)
+	}, healthy)
+	fields := logger.Fields{
+		"recovered": recovered,
+		"pending":   p.spill.Pending(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		p.log.Warn("Failed to replay spilled records", fields)
+		return
+	}
+	if recovered > 0 {
+		p.log.Info("Spilled records written to the database", fields)
+	}
+}
+
+func (p *DatabasePlugin) labelStore() *LabelStore {
+	store := NewLabelStore(p.db)
+	store.region = p.databaseConfig.Region
+	return store
+}
+
+func (p *DatabasePlugin) reportOptions() report.Options {
+	return report.Options{FontPath: p.databaseConfig.ReportFontPath}
+}
+
+// HealthCheck reports whether the result database is reachable
+func (p *DatabasePlugin) HealthCheck(ctx context.Context) error {
+	return database.Ping(ctx, p.db)
+}
+
 func (p *DatabasePlugin) Stop(ctx context.Context) error {
 	p.log.Info("Stopping database plugin")
 
+	// Write the batched records before the database connection is closed
+	if p.cancel != nil {
+		p.cancel()
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			p.log.Warn("Timed out flushing batched records")
+		}
+	}
+
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down label API server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+	if p.metricsServer != nil {
+		if err := p.metricsServer.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down metrics server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+	if p.appealServer != nil {
+		if err := p.appealServer.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to shut down appeal API server", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if p.spill != nil {
+		if err := p.spill.Close(); err != nil {
+			p.log.Warn("Failed to close spill buffer", logger.Fields{
+				"error":   err.Error(),
+				"pending": p.spill.Pending(),
+			})
+		}
+	}
+
 	if p.db != nil {
 		sqlDB, err := p.db.DB()
 		if err != nil {
@@ -285,63 +844,25 @@ func (p *DatabasePlugin) Stop(ctx context.Context) error {
 
 func (p *DatabasePlugin) initDB() error {
 	p.log.Debug("Initializing database", logger.Fields{
+		"driver":   p.databaseConfig.Driver,
 		"host":     p.databaseConfig.Host,
 		"port":     p.databaseConfig.Port,
 		"database": p.databaseConfig.DatabaseName,
 	})
-	serverDSN := p.buildDSN(false)
-	dbConfig := &gorm.Config{
-		Logger: gormLogger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags),
-			gormLogger.Config{
-				SlowThreshold: 3 * time.Second,
-				LogLevel:      gormLogger.Error,
-				Colorful:      false,
-			},
-		),
-	}
-	db, err := gorm.Open(mysql.Open(serverDSN), dbConfig)
-	if err != nil {
-		return fmt.Errorf("failed to connect to MySQL server: %w", err)
-	}
-	err = db.Exec(
-		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET %s COLLATE %s_unicode_ci",
-			p.databaseConfig.DatabaseName,
-			p.databaseConfig.Charset,
-			p.databaseConfig.Charset),
-	).Error
-	if err != nil {
-		return fmt.Errorf("failed to create database: %w", err)
-	}
-	dbDSN := p.buildDSN(true)
-	db, err = gorm.Open(mysql.Open(dbDSN), dbConfig)
+	db, err := database.Open(p.dbOptions())
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
 	}
 	p.db = db
 
 	p.log.Info("Database initialized successfully", logger.Fields{
+		"driver":   p.databaseConfig.Driver,
 		"database": p.databaseConfig.DatabaseName,
 	})
 
 	return nil
 }
 
-func (p *DatabasePlugin) buildDSN(includeDB bool) string {
-	dbPart := "/"
-	if includeDB {
-		dbPart = "/" + p.databaseConfig.DatabaseName
-	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)%s?charset=%s&parseTime=True&loc=Local",
-		p.databaseConfig.Username,
-		p.databaseConfig.Password,
-		p.databaseConfig.Host,
-		p.databaseConfig.Port,
-		dbPart,
-		p.databaseConfig.Charset,
-	)
-}
-
 func (p *DatabasePlugin) saveResults(result *models.DetectorInfo) error {
 	if p == nil {
 		p.log.Error("DatabasePlugin instance is nil")
@@ -355,16 +876,34 @@ func (p *DatabasePlugin) saveResults(result *models.DetectorInfo) error {
 		p.log.Error("Detection result is nil")
 		return errors.New("detection result is nil")
 	}
+	return p.writeRecords([]DetectorRecord{p.newRecord(result)})
+}
+
+// newRecord converts result into the stored record, with personal data
+// redacted and the key of the finding set
+func (p *DatabasePlugin) newRecord(result *models.DetectorInfo) DetectorRecord {
+	result, redacted := p.redactor.Detection(result)
+	if redacted.Total() > 0 {
+		p.log.Info("Personal data redacted from stored record", logger.Fields{
+			"host":      result.Host,
+			"namespace": result.Namespace,
+			"redacted":  redacted.String(),
+		})
+	}
 	record := DetectorRecord{
 		DiscoveryName: result.DiscoveryName,
 		CollectorName: result.CollectorName,
 		DetectorName:  result.DetectorName,
 		Name:          result.Name,
 		Namespace:     result.Namespace,
+		Region:        result.Region,
 		Host:          result.Host,
 		URL:           result.URL,
 		IsIllegal:     result.IsIllegal,
+		Unchanged:     result.Unchanged,
 		Description:   result.Description,
+		Severity:      result.Severity,
+		Redactions:    redacted.Total(),
 	}
 	if len(result.Path) > 0 {
 		if pathJSON, err := json.Marshal(result.Path); err == nil {
@@ -372,26 +911,111 @@ func (p *DatabasePlugin) saveResults(result *models.DetectorInfo) error {
 			record.Path = &pathStr
 		}
 	}
+	if workload := result.Workload; workload != nil {
+		record.WorkloadKind = workload.Kind
+		record.WorkloadName = workload.Name
+		record.OwnerUserID = workload.UserID
+		record.OwnerTeam = workload.Team
+		if !workload.CreatedAt.IsZero() {
+			createdAt := workload.CreatedAt
+			record.WorkloadCreatedAt = &createdAt
+		}
+		if len(workload.Images) > 0 {
+			if imagesJSON, err := json.Marshal(workload.Images); err == nil {
+				imagesStr := string(imagesJSON)
+				record.Images = &imagesStr
+			}
+		}
+	}
 	if len(result.Keywords) > 0 {
 		if keywordsJSON, err := json.Marshal(result.Keywords); err == nil {
 			keywordsStr := string(keywordsJSON)
 			record.Keywords = &keywordsStr
 		}
 	}
-	if err := p.db.Create(&record).Error; err != nil {
-		p.log.Error("Failed to insert record", logger.Fields{
-			"error":     err.Error(),
-			"host":      record.Host,
-			"namespace": record.Namespace,
+	record.ContentHash = contentHash(result)
+	record.setFindingKey()
+	record.Occurrences = 1
+	record.CreatedAt = time.Now()
+	record.UpdatedAt = record.CreatedAt
+	record.usage = result.Usage
+	if p.databaseConfig.StoreTranscripts && result.Transcript != nil {
+		record.transcript = newTranscript(&record, result.Transcript, p.redactor)
+	}
+	return record
+}
+
+// writeRecords upserts records, skipping the events already stored and
+// spilling the records when the write fails. The transcripts and token usage
+// of the records are stored once the records were written; a spilled record
+// loses both.
+func (p *DatabasePlugin) writeRecords(records []DetectorRecord) error {
+	records = p.dropHandled(records)
+	if len(records) == 0 {
+		return nil
+	}
+	usage := aggregateUsage(records, p.databaseConfig.ModelPricing)
+	var transcripts []*DetectorTranscript
+	for _, record := range records {
+		if record.transcript != nil {
+			transcripts = append(transcripts, record.transcript)
+		}
+	}
+	records = coalesceRecords(records)
+	if err := storeRecords(p.db, records); err != nil {
+		p.log.Error("Failed to insert records", logger.Fields{
+			"error":   err.Error(),
+			"records": len(records),
 		})
-		return err
+		if p.spill == nil {
+			return err
+		}
+		var errs []error
+		for i := range records {
+			if spillErr := p.spillRecord(&records[i], err); spillErr != nil {
+				errs = append(errs, spillErr)
+			}
+		}
+		return errors.Join(errs...)
 	}
 
-	p.log.Debug("Record saved successfully", logger.Fields{
-		"host":       record.Host,
-		"namespace":  record.Namespace,
-		"is_illegal": record.IsIllegal,
+	p.log.Debug("Records saved successfully", logger.Fields{
+		"records": len(records),
 	})
+	if err := upsertUsage(p.db, usage); err != nil {
+		p.log.Warn("Failed to store token usage", logger.Fields{
+			"error": err.Error(),
+			"rows":  len(usage),
+		})
+	}
+	if len(transcripts) > 0 {
+		if err := p.db.CreateInBatches(transcripts, len(transcripts)).Error; err != nil {
+			p.log.Warn("Failed to store model transcripts", logger.Fields{
+				"error":       err.Error(),
+				"transcripts": len(transcripts),
+			})
+		}
+	}
+	return nil
+}
 
+// spillRecord keeps a record whose write failed with writeErr in the spill
+// buffer. The time it was detected is kept, so replayed records are not dated
+// to the recovery of the database.
+func (p *DatabasePlugin) spillRecord(record *DetectorRecord, writeErr error) error {
+	if p.spill == nil {
+		return writeErr
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	if err := p.spill.Add(record); err != nil {
+		return errors.Join(writeErr, err)
+	}
+	p.log.Warn("Record spilled to disk until the database recovers", logger.Fields{
+		"host":      record.Host,
+		"namespace": record.Namespace,
+		"pending":   p.spill.Pending(),
+	})
 	return nil
 }
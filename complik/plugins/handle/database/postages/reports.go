@@ -0,0 +1,196 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/report"
+)
+
+// DefaultReportPeriod is the period covered by reports without an explicit
+// start
+const DefaultReportPeriod = 30 * 24 * time.Hour
+
+// Report builds the compliance report of namespace from the records flagged
+// as illegal in [from, to). The resolution status of a violation is taken
+// from its review label.
+func (s *LabelStore) Report(namespace string, from, to time.Time) (*report.Report, error) {
+	var records []DetectorRecord
+	err := s.db.Where("namespace = ? AND is_illegal = ? AND created_at >= ? AND created_at < ?",
+		namespace, true, from, to).Order("created_at").Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load records: %w", err)
+	}
+	labeled, err := s.attachLabels(records)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]report.Violation, 0, len(labeled))
+	for _, record := range labeled {
+		violations = append(violations, violation(record))
+	}
+	r := report.New(namespace, from, to, violations)
+	r.Region = s.region
+	return r, nil
+}
+
+// ReportNamespaces returns the namespaces with records flagged as illegal in
+// [from, to)
+func (s *LabelStore) ReportNamespaces(from, to time.Time) ([]string, error) {
+	var namespaces []string
+	err := s.db.Model(&DetectorRecord{}).
+		Where("namespace <> ? AND is_illegal = ? AND created_at >= ? AND created_at < ?", "", true, from, to).
+		Distinct("namespace").Order("namespace").Pluck("namespace", &namespaces).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return namespaces, nil
+}
+
+func violation(record LabeledRecord) report.Violation {
+	v := report.Violation{
+		RecordID:    record.ID,
+		DetectedAt:  record.CreatedAt,
+		Detector:    record.DetectorName,
+		Name:        record.Name,
+		Host:        record.Host,
+		Severity:    record.Severity,
+		Description: record.Description,
+		Keywords:    decodeStrings(record.Keywords),
+		Evidence:    evidence(record.DetectorRecord),
+		Status:      report.StatusPending,
+	}
+	if record.WorkloadKind != "" {
+		v.Workload = record.WorkloadKind + "/" + record.WorkloadName
+	}
+	if label := record.Label; label != nil {
+		switch label.Verdict {
+		case VerdictTruePositive:
+			v.Status = report.StatusConfirmed
+		case VerdictFalsePositive:
+			v.Status = report.StatusDismissed
+		}
+		reviewedAt := label.UpdatedAt
+		v.Reviewer, v.ReviewedAt, v.Comment = label.Reviewer, &reviewedAt, label.Comment
+	}
+	return v
+}
+
+// evidence returns the collected URL followed by the other paths of the host
+func evidence(record DetectorRecord) []string {
+	links := make([]string, 0, 1)
+	seen := make(map[string]struct{})
+	add := func(link string) {
+		if _, dup := seen[link]; dup || link == "" {
+			return
+		}
+		seen[link] = struct{}{}
+		links = append(links, link)
+	}
+	add(record.URL)
+	if record.Host != "" {
+		for _, path := range decodeStrings(record.Path) {
+			add("https://" + record.Host + "/" + strings.TrimPrefix(path, "/"))
+		}
+	}
+	return links
+}
+
+func decodeStrings(value *string) []string {
+	if value == nil {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(*value), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// reportScheduler writes the reports of all namespaces with violations in the
+// last period to a directory once per interval
+type reportScheduler struct {
+	log      logger.Logger
+	store    *LabelStore
+	dir      string
+	format   string
+	interval time.Duration
+	period   time.Duration
+	options  report.Options
+}
+
+func (s *reportScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.generate(now); err != nil {
+				s.log.Error("Failed to generate scheduled reports", logger.Fields{"error": err.Error()})
+			}
+		}
+	}
+}
+
+// generate writes one report per namespace with violations in the period
+// ending at now
+func (s *reportScheduler) generate(now time.Time) error {
+	from := now.Add(-s.period)
+	namespaces, err := s.store.ReportNamespaces(from, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	for _, namespace := range namespaces {
+		r, err := s.store.Report(namespace, from, now)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(s.dir, r.FileName(s.format))
+		if err := writeReport(path, r, s.format, s.options); err != nil {
+			return err
+		}
+		s.log.Debug("Compliance report written", logger.Fields{"namespace": namespace, "path": path})
+	}
+	s.log.Info("Scheduled compliance reports generated", logger.Fields{
+		"namespaces": len(namespaces),
+		"dir":        s.dir,
+	})
+	return nil
+}
+
+func writeReport(path string, r *report.Report, format string, opts report.Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := report.Render(file, r, format, opts); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,238 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// spillFile is the name of the spill buffer in the spill directory
+const spillFile = "records.jsonl"
+
+// ErrSpillFull is returned when a record does not fit into the spill buffer
+var ErrSpillFull = errors.New("spill buffer full")
+
+// SpillBuffer keeps the records that could not be written while the database
+// was unavailable. Records are appended to a file in the spill directory and
+// synced before Add returns, so they survive a restart, and are replayed in
+// order once the database recovered.
+type SpillBuffer struct {
+	path     string
+	maxBytes int64
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	pending int
+
+	spilled   prometheus.Counter
+	recovered prometheus.Counter
+	dropped   prometheus.Counter
+	backlog   prometheus.GaugeFunc
+}
+
+// NewSpillBuffer opens the spill buffer in dir, keeping the records spilled
+// before a restart. The file grows to at most maxBytes.
+func NewSpillBuffer(dir string, maxBytes int64) (*SpillBuffer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	b := &SpillBuffer{
+		path:     filepath.Join(dir, spillFile),
+		maxBytes: maxBytes,
+		spilled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_spilled_records_total",
+			Help:      "Records spilled to disk because the database write failed.",
+		}),
+		recovered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_recovered_records_total",
+			Help:      "Spilled records written to the database after it recovered.",
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "database_dropped_records_total",
+			Help:      "Records lost because the spill buffer was full, unreadable or rejected by the database.",
+		}),
+	}
+	b.backlog = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "database_spill_pending_records",
+		Help:      "Records waiting in the spill buffer.",
+	}, func() float64 { return float64(b.Pending()) })
+	if err := b.open(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// open opens the spill file for appending and counts the records in it;
+// callers hold b.mu or own b exclusively
+func (b *SpillBuffer) open() error {
+	file, err := os.OpenFile(b.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open spill buffer: %w", err)
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to read spill buffer: %w", err)
+	}
+	b.file = file
+	b.size = int64(len(data))
+	b.pending = bytes.Count(data, []byte("\n"))
+	return nil
+}
+
+// Add appends record to the buffer
+func (b *SpillBuffer) Add(record *DetectorRecord) error {
+	spilled := *record
+	// The database assigns the ID when the record is replayed
+	spilled.ID = 0
+	line, err := json.Marshal(&spilled)
+	if err != nil {
+		b.dropped.Inc()
+		return fmt.Errorf("failed to encode spilled record: %w", err)
+	}
+	line = append(line, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.size+int64(len(line)) > b.maxBytes {
+		b.dropped.Inc()
+		return ErrSpillFull
+	}
+	if _, err := b.file.Write(line); err != nil {
+		b.dropped.Inc()
+		return fmt.Errorf("failed to write spill buffer: %w", err)
+	}
+	if err := b.file.Sync(); err != nil {
+		b.dropped.Inc()
+		return fmt.Errorf("failed to sync spill buffer: %w", err)
+	}
+	b.size += int64(len(line))
+	b.pending++
+	b.spilled.Inc()
+	return nil
+}
+
+// Pending returns the number of records in the buffer
+func (b *SpillBuffer) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending
+}
+
+// Replay writes the buffered records in order with write. When a write fails
+// and healthy reports the database as reachable, the record itself is at
+// fault and dropped; otherwise replaying stops and the remaining records
+// are kept for the next attempt. Replay returns the number of recovered
+// records.
+func (b *SpillBuffer) Replay(write func(*DetectorRecord) error, healthy func() bool) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == 0 {
+		return 0, nil
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spill buffer: %w", err)
+	}
+
+	recovered := 0
+	var replayErr error
+	var rest []byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), int(b.maxBytes)+1)
+	offset := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		next := offset + len(line) + 1
+		var record DetectorRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// A line torn by a crash while it was written
+			b.dropped.Inc()
+			offset = next
+			continue
+		}
+		if err := write(&record); err != nil {
+			if healthy() {
+				b.dropped.Inc()
+				offset = next
+				continue
+			}
+			replayErr = err
+			rest = data[min(offset, len(data)):]
+			break
+		}
+		recovered++
+		b.recovered.Inc()
+		offset = next
+	}
+	if err := scanner.Err(); err != nil && replayErr == nil {
+		replayErr = fmt.Errorf("failed to read spill buffer: %w", err)
+		rest = data[min(offset, len(data)):]
+	}
+	if err := b.rewrite(rest); err != nil {
+		return recovered, errors.Join(replayErr, err)
+	}
+	return recovered, replayErr
+}
+
+// rewrite replaces the buffer with the records not replayed yet; callers hold b.mu
+func (b *SpillBuffer) rewrite(rest []byte) error {
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, rest, 0o600); err != nil {
+		return fmt.Errorf("failed to write spill buffer: %w", err)
+	}
+	_ = b.file.Close()
+	if err := os.Rename(tmp, b.path); err != nil {
+		_ = b.open()
+		return fmt.Errorf("failed to replace spill buffer: %w", err)
+	}
+	return b.open()
+}
+
+// Close closes the spill file, the buffered records are replayed after the
+// next start
+func (b *SpillBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.file.Close()
+}
+
+func (b *SpillBuffer) Describe(ch chan<- *prometheus.Desc) {
+	b.spilled.Describe(ch)
+	b.recovered.Describe(ch)
+	b.dropped.Describe(ch)
+	b.backlog.Describe(ch)
+}
+
+func (b *SpillBuffer) Collect(ch chan<- prometheus.Metric) {
+	b.spilled.Collect(ch)
+	b.recovered.Collect(ch)
+	b.dropped.Collect(ch)
+	b.backlog.Collect(ch)
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("SpillBuffer", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	hosts := func(records []*DetectorRecord) []string {
+		result := make([]string, 0, len(records))
+		for _, record := range records {
+			result = append(result, record.Host)
+		}
+		return result
+	}
+
+	It("should keep spilled records across restarts and replay them in order", func() {
+		spill, err := NewSpillBuffer(dir, 1<<20)
+		Expect(err).NotTo(HaveOccurred())
+		for _, host := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+			Expect(spill.Add(&DetectorRecord{ID: 9, Host: host})).To(Succeed())
+		}
+		Expect(spill.Close()).To(Succeed())
+
+		spill, err = NewSpillBuffer(dir, 1<<20)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spill.Pending()).To(Equal(3))
+
+		// The database goes away again after the first record
+		var written []*DetectorRecord
+		recovered, err := spill.Replay(func(record *DetectorRecord) error {
+			if len(written) == 1 {
+				return errors.New("connection refused")
+			}
+			written = append(written, record)
+			return nil
+		}, func() bool { return false })
+		Expect(err).To(MatchError("connection refused"))
+		Expect(recovered).To(Equal(1))
+		Expect(spill.Pending()).To(Equal(2))
+
+		recovered, err = spill.Replay(func(record *DetectorRecord) error {
+			written = append(written, record)
+			return nil
+		}, func() bool { return true })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(2))
+		Expect(hosts(written)).To(Equal([]string{"a.example.com", "b.example.com", "c.example.com"}))
+		Expect(written[0].ID).To(BeZero())
+		Expect(spill.Pending()).To(BeZero())
+		Expect(testutil.ToFloat64(spill.recovered)).To(Equal(3.0))
+	})
+
+	It("should bound the buffer and drop records it cannot replay", func() {
+		line, err := json.Marshal(&DetectorRecord{Host: "a.example.com"})
+		Expect(err).NotTo(HaveOccurred())
+		spill, err := NewSpillBuffer(dir, int64(len(line)+10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spill.Add(&DetectorRecord{Host: "a.example.com"})).To(Succeed())
+		Expect(testutil.ToFloat64(spill.spilled)).To(Equal(1.0))
+		Expect(spill.Add(&DetectorRecord{Host: "b.example.com"})).To(MatchError(ErrSpillFull))
+		Expect(testutil.ToFloat64(spill.dropped)).To(Equal(1.0))
+
+		// A record torn by a crash and one the database rejects
+		file, err := os.OpenFile(filepath.Join(dir, spillFile), os.O_APPEND|os.O_WRONLY, 0o600)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString("{\"host\":\"torn\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+		Expect(spill.Close()).To(Succeed())
+		spill, err = NewSpillBuffer(dir, 1<<20)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spill.Pending()).To(Equal(2))
+
+		recovered, err := spill.Replay(func(record *DetectorRecord) error {
+			return errors.New("duplicate entry")
+		}, func() bool { return true })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(BeZero())
+		Expect(spill.Pending()).To(BeZero())
+		Expect(testutil.ToFloat64(spill.dropped)).To(Equal(2.0))
+	})
+
+	It("should spill failed writes of the plugin and replay them once the database recovered", func() {
+		db, err := database.Open(database.Options{
+			Driver:     database.DriverSQLite,
+			SQLitePath: filepath.Join(dir, "records.db"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		p := &DatabasePlugin{log: logger.GetLogger(), db: db}
+		Expect(p.loadConfig(`{"driver":"sqlite","spillDir":"` + filepath.Join(dir, "spill") + `"}`)).To(Succeed())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(p.startSpill(ctx)).To(Succeed())
+
+		// The table is missing until the migration ran, so the write fails
+		Expect(p.saveResults(&models.DetectorInfo{Host: "a.example.com", IsIllegal: true})).To(Succeed())
+		Expect(p.spill.Pending()).To(Equal(1))
+
+		Expect(db.AutoMigrate(&DetectorRecord{})).To(Succeed())
+		p.replaySpill(ctx)
+		Expect(p.spill.Pending()).To(BeZero())
+		var record DetectorRecord
+		Expect(db.First(&record).Error).To(Succeed())
+		Expect(record.Host).To(Equal("a.example.com"))
+		Expect(record.CreatedAt).NotTo(BeZero())
+	})
+})
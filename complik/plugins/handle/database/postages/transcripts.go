@@ -0,0 +1,137 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/redact"
+	"gorm.io/gorm"
+)
+
+// DetectorTranscript is the model exchange behind one detection of a finding.
+// Transcripts are linked to their record by the finding key, so every
+// occurrence of a finding keeps its own transcript.
+type DetectorTranscript struct {
+	ID               uint      `gorm:"primaryKey"      json:"id"`
+	FindingKey       string    `gorm:"size:64;index"   json:"-"`
+	DetectorName     string    `gorm:"size:255"        json:"detector_name"`
+	Namespace        string    `gorm:"size:255"        json:"namespace"`
+	Host             string    `gorm:"size:255"        json:"host"`
+	Model            string    `gorm:"size:255"        json:"model"`
+	Prompt           string    `gorm:"type:mediumtext" json:"prompt"`
+	Response         string    `gorm:"type:mediumtext" json:"response"`
+	PromptTokens     int       `                       json:"prompt_tokens"`
+	CompletionTokens int       `                       json:"completion_tokens"`
+	TotalTokens      int       `                       json:"total_tokens"`
+	Redactions       int       `                       json:"redactions,omitempty"`
+	CreatedAt        time.Time `gorm:"index"           json:"created_at"`
+}
+
+func (DetectorTranscript) TableName() string {
+	return "detector_transcripts"
+}
+
+// newTranscript converts the transcript of result for record, with personal
+// data redacted from the prompt and the response
+func newTranscript(
+	record *DetectorRecord,
+	transcript *models.ModelTranscript,
+	redactor *redact.Redactor,
+) *DetectorTranscript {
+	counts := redact.Counts{}
+	stored := &DetectorTranscript{
+		DetectorName:     record.DetectorName,
+		Namespace:        record.Namespace,
+		Host:             record.Host,
+		Model:            transcript.Model,
+		Prompt:           redactor.Redact(transcript.Prompt, counts),
+		Response:         redactor.Redact(transcript.Response, counts),
+		PromptTokens:     transcript.PromptTokens,
+		CompletionTokens: transcript.CompletionTokens,
+		TotalTokens:      transcript.TotalTokens,
+		CreatedAt:        record.CreatedAt,
+	}
+	stored.Redactions = counts.Total()
+	if record.FindingKey != nil {
+		stored.FindingKey = *record.FindingKey
+	}
+	return stored
+}
+
+// Transcripts returns the transcripts of a record, newest first. Records
+// stored before transcripts were kept, or without a finding key, have none.
+func (s *LabelStore) Transcripts(recordID uint) ([]DetectorTranscript, error) {
+	var record DetectorRecord
+	if err := s.db.First(&record, recordID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to load record: %w", err)
+	}
+	transcripts := []DetectorTranscript{}
+	if record.FindingKey == nil {
+		return transcripts, nil
+	}
+	if err := s.db.Where("finding_key = ?", *record.FindingKey).
+		Order("created_at DESC").Find(&transcripts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transcripts: %w", err)
+	}
+	return transcripts, nil
+}
+
+// PurgeTranscripts deletes the transcripts created before cutoff
+func PurgeTranscripts(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Where("created_at < ?", cutoff).Delete(&DetectorTranscript{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge transcripts: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// startTranscriptRetention deletes the transcripts older than
+// TranscriptRetentionDay every hour
+func (p *DatabasePlugin) startTranscriptRetention(ctx context.Context) {
+	retention := time.Duration(p.databaseConfig.TranscriptRetentionDay) * 24 * time.Hour
+	p.log.Info("Model transcripts are stored", logger.Fields{
+		"retention_day": p.databaseConfig.TranscriptRetentionDay,
+	})
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeTranscripts(p.db.WithContext(ctx), time.Now().Add(-retention))
+			if err != nil && ctx.Err() == nil {
+				p.log.Warn("Failed to purge expired transcripts", logger.Fields{
+					"error": err.Error(),
+				})
+			} else if purged > 0 {
+				p.log.Info("Expired transcripts purged", logger.Fields{
+					"purged": purged,
+				})
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transcripts", func() {
+	var p *DatabasePlugin
+
+	BeforeEach(func() {
+		db, err := database.Open(database.Options{
+			Driver:     database.DriverSQLite,
+			SQLitePath: filepath.Join(GinkgoT().TempDir(), "records.db"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		Expect(db.AutoMigrate(&DetectorRecord{}, &DetectorTranscript{})).To(Succeed())
+		p = &DatabasePlugin{log: logger.GetLogger(), db: db}
+	})
+
+	result := func(prompt string) *models.DetectorInfo {
+		return &models.DetectorInfo{
+			DetectorName: "safety",
+			Namespace:    "ns-test",
+			Host:         "a.example.com",
+			IsIllegal:    true,
+			Description:  "gambling",
+			Transcript: &models.ModelTranscript{
+				Model:       "gpt-5",
+				Prompt:      prompt,
+				Response:    `{"choices":[]}`,
+				TotalTokens: 1280,
+			},
+		}
+	}
+
+	count := func() int64 {
+		var n int64
+		Expect(p.db.Model(&DetectorTranscript{}).Count(&n).Error).To(Succeed())
+		return n
+	}
+
+	It("should only store transcripts when enabled", func() {
+		Expect(p.loadConfig(`{"driver":"sqlite"}`)).To(Succeed())
+		Expect(p.saveResults(result("review this"))).To(Succeed())
+		Expect(count()).To(BeZero())
+	})
+
+	It("should keep a redacted transcript per occurrence and serve them by record", func() {
+		Expect(p.loadConfig(`{"driver":"sqlite","storeTranscripts":true,"redactPII":true}`)).To(Succeed())
+		Expect(p.saveResults(result("contact admin@example.com"))).To(Succeed())
+		Expect(p.saveResults(result("second review"))).To(Succeed())
+
+		var record DetectorRecord
+		Expect(p.db.First(&record).Error).To(Succeed())
+		Expect(record.Occurrences).To(Equal(2))
+
+		api := NewLabelAPI(logger.GetLogger(), "secret", NewLabelStore(p.db))
+		get := func(path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			return rec
+		}
+		rec := get("/api/v1/records/1/transcripts")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var transcripts []DetectorTranscript
+		Expect(json.Unmarshal(rec.Body.Bytes(), &transcripts)).To(Succeed())
+		Expect(transcripts).To(HaveLen(2))
+		prompts := []string{transcripts[0].Prompt, transcripts[1].Prompt}
+		Expect(prompts).To(ContainElement("second review"))
+		Expect(prompts).NotTo(ContainElement(ContainSubstring("admin@example.com")))
+		Expect(transcripts[0].TotalTokens).To(Equal(1280))
+
+		rec = get("/api/v1/records/9/transcripts")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should purge expired transcripts", func() {
+		Expect(p.db.Create(&[]DetectorTranscript{
+			{Host: "old.example.com", CreatedAt: time.Now().Add(-40 * 24 * time.Hour)},
+			{Host: "new.example.com", CreatedAt: time.Now()},
+		}).Error).To(Succeed())
+		purged, err := PurgeTranscripts(p.db, time.Now().Add(-30*24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(purged).To(Equal(int64(1)))
+		Expect(count()).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,149 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DetectorTokenUsage adds up the model tokens and their cost per UTC day,
+// region, namespace, detector and model, for attributing the review spend to
+// tenants
+type DetectorTokenUsage struct {
+	ID               uint      `gorm:"primaryKey"                         json:"-"`
+	Day              string    `gorm:"size:10;uniqueIndex:idx_usage_key"  json:"day"`
+	Region           string    `gorm:"size:64;uniqueIndex:idx_usage_key"  json:"region"`
+	Namespace        string    `gorm:"size:255;uniqueIndex:idx_usage_key" json:"namespace"`
+	DetectorName     string    `gorm:"size:128;uniqueIndex:idx_usage_key" json:"detector_name"`
+	Model            string    `gorm:"size:128;uniqueIndex:idx_usage_key" json:"model"`
+	Reviews          int64     `                                          json:"reviews"`
+	PromptTokens     int64     `                                          json:"prompt_tokens"`
+	CompletionTokens int64     `                                          json:"completion_tokens"`
+	TotalTokens      int64     `                                          json:"total_tokens"`
+	CostUSD          float64   `                                          json:"cost_usd"`
+	UpdatedAt        time.Time `                                          json:"updated_at"`
+}
+
+func (DetectorTokenUsage) TableName() string {
+	return "detector_token_usage"
+}
+
+// NamespaceUsage is the token usage of a namespace over a period
+type NamespaceUsage struct {
+	Region           string  `json:"region"`
+	Namespace        string  `json:"namespace"`
+	Reviews          int64   `json:"reviews"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"inputPerMillion"`
+	OutputPerMillion float64 `json:"outputPerMillion"`
+}
+
+// Pricing maps model names to their price. A model without an exact entry
+// takes the price of the longest name it starts with, so "gpt-5" prices the
+// dated "gpt-5-2025-08-07" snapshots.
+type Pricing map[string]ModelPrice
+
+// Cost returns the cost of usage, 0 for models without a price
+func (p Pricing) Cost(usage *models.TokenUsage) float64 {
+	price, ok := p[usage.Model]
+	if !ok {
+		best := ""
+		for name, candidate := range p {
+			if strings.HasPrefix(usage.Model, name) && len(name) > len(best) {
+				best, price = name, candidate
+			}
+		}
+	}
+	return (float64(usage.PromptTokens)*price.InputPerMillion +
+		float64(usage.CompletionTokens)*price.OutputPerMillion) / 1e6
+}
+
+// aggregateUsage adds up the usage of records per usage key, in key order
+func aggregateUsage(records []DetectorRecord, pricing Pricing) []DetectorTokenUsage {
+	byKey := make(map[[5]string]*DetectorTokenUsage)
+	for _, record := range records {
+		usage := record.usage
+		if usage == nil {
+			continue
+		}
+		day := record.CreatedAt
+		if day.IsZero() {
+			day = time.Now()
+		}
+		key := [5]string{day.UTC().Format(time.DateOnly), record.Region, record.Namespace, record.DetectorName, usage.Model}
+		row, ok := byKey[key]
+		if !ok {
+			row = &DetectorTokenUsage{
+				Day: key[0], Region: key[1], Namespace: key[2], DetectorName: key[3], Model: key[4],
+			}
+			byKey[key] = row
+		}
+		row.Reviews++
+		row.PromptTokens += int64(usage.PromptTokens)
+		row.CompletionTokens += int64(usage.CompletionTokens)
+		row.TotalTokens += int64(usage.TotalTokens)
+		row.CostUSD += pricing.Cost(usage)
+	}
+	rows := make([]DetectorTokenUsage, 0, len(byKey))
+	for _, row := range byKey {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		return slices.Compare(
+			[]string{a.Day, a.Region, a.Namespace, a.DetectorName, a.Model},
+			[]string{b.Day, b.Region, b.Namespace, b.DetectorName, b.Model},
+		) < 0
+	})
+	return rows
+}
+
+// upsertUsage adds rows to the stored usage
+func upsertUsage(db *gorm.DB, rows []DetectorTokenUsage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	added := "%[1]s + excluded.%[1]s"
+	if db.Dialector.Name() == "mysql" {
+		added = "%[1]s + VALUES(%[1]s)"
+	}
+	updates := clause.AssignmentColumns([]string{"updated_at"})
+	for _, column := range []string{"reviews", "prompt_tokens", "completion_tokens", "total_tokens", "cost_usd"} {
+		updates = append(updates, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr(fmt.Sprintf(added, column)),
+		})
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "day"}, {Name: "region"}, {Name: "namespace"}, {Name: "detector_name"}, {Name: "model"},
+		},
+		DoUpdates: updates,
+	}).CreateInBatches(rows, len(rows)).Error
+}
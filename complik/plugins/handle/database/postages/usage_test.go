@@ -0,0 +1,107 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Token usage", func() {
+	pricing := Pricing{
+		"gpt-5":      {InputPerMillion: 1.25, OutputPerMillion: 10},
+		"gpt-5-mini": {InputPerMillion: 0.25, OutputPerMillion: 2},
+	}
+
+	It("should price models by their longest matching name", func() {
+		usage := func(model string) *models.TokenUsage {
+			return &models.TokenUsage{Model: model, PromptTokens: 1_000_000, CompletionTokens: 100_000}
+		}
+		Expect(pricing.Cost(usage("gpt-5"))).To(BeNumerically("~", 2.25))
+		Expect(pricing.Cost(usage("gpt-5-2025-08-07"))).To(BeNumerically("~", 2.25))
+		Expect(pricing.Cost(usage("gpt-5-mini-2025-08-07"))).To(BeNumerically("~", 0.45))
+		Expect(pricing.Cost(usage("qwen-vl"))).To(BeZero())
+	})
+
+	It("should add up the usage per tenant and day and export today's spend", func() {
+		db, err := database.Open(database.Options{
+			Driver:     database.DriverSQLite,
+			SQLitePath: filepath.Join(GinkgoT().TempDir(), "records.db"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		Expect(db.AutoMigrate(&DetectorRecord{}, &DetectorLabel{}, &DetectorTokenUsage{})).To(Succeed())
+		Expect(MigrateViews(db)).To(Succeed())
+		p := &DatabasePlugin{log: logger.GetLogger(), db: db}
+		Expect(p.loadConfig(`{"driver":"sqlite","region":"hzh","modelPricing":{"gpt-5":{"inputPerMillion":1.25,"outputPerMillion":10}}}`)).
+			To(Succeed())
+
+		review := func(namespace, host string) *models.DetectorInfo {
+			return &models.DetectorInfo{
+				DetectorName: "safety",
+				Namespace:    namespace,
+				Region:       "hzh",
+				Host:         host,
+				Usage:        &models.TokenUsage{Model: "gpt-5", PromptTokens: 4000, CompletionTokens: 200, TotalTokens: 4200},
+			}
+		}
+		Expect(p.writeRecords([]DetectorRecord{
+			p.newRecord(review("ns-a", "a.example.com")),
+			p.newRecord(review("ns-a", "b.example.com")),
+			p.newRecord(review("ns-b", "c.example.com")),
+		})).To(Succeed())
+		// A repeated finding is stored once, but every review is paid for
+		Expect(p.saveResults(review("ns-a", "a.example.com"))).To(Succeed())
+		Expect(p.saveResults(&models.DetectorInfo{DetectorName: "safety", Namespace: "ns-a", Host: "d.example.com"})).
+			To(Succeed())
+
+		var rows []DetectorTokenUsage
+		Expect(db.Order("namespace").Find(&rows).Error).To(Succeed())
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[0].Namespace).To(Equal("ns-a"))
+		Expect(rows[0].Reviews).To(Equal(int64(3)))
+		Expect(rows[0].PromptTokens).To(Equal(int64(12000)))
+		Expect(rows[0].TotalTokens).To(Equal(int64(12600)))
+		Expect(rows[0].CostUSD).To(BeNumerically("~", 0.021))
+		Expect(rows[1].Reviews).To(Equal(int64(1)))
+
+		exporter := NewPostureExporter(logger.GetLogger(), db)
+		Expect(exporter.Refresh(context.Background())).To(Succeed())
+		Expect(testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP complik_llm_reviews_today Model reviews of the current UTC day per region and namespace.
+# TYPE complik_llm_reviews_today gauge
+complik_llm_reviews_today{namespace="ns-a",region="hzh"} 3
+complik_llm_reviews_today{namespace="ns-b",region="hzh"} 1
+# HELP complik_llm_tokens_today Model tokens consumed by the reviews of the current UTC day per region, namespace and token type.
+# TYPE complik_llm_tokens_today gauge
+complik_llm_tokens_today{namespace="ns-a",region="hzh",type="completion"} 600
+complik_llm_tokens_today{namespace="ns-a",region="hzh",type="prompt"} 12000
+complik_llm_tokens_today{namespace="ns-b",region="hzh",type="completion"} 200
+complik_llm_tokens_today{namespace="ns-b",region="hzh",type="prompt"} 4000
+`), "complik_llm_reviews_today", "complik_llm_tokens_today")).To(Succeed())
+	})
+})
@@ -0,0 +1,111 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postages
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Read-optimized views over the detector records for dashboards. A violation
+// is a record flagged as illegal; its status comes from the review label.
+const (
+	ViewViolationsPerDay      = "complik_violations_per_day"
+	ViewViolationsPerRegion   = "complik_violations_per_region"
+	ViewViolationsPerDetector = "complik_violations_per_detector"
+)
+
+// statusColumns counts the violations of a group per review status; the
+// statements only use SQL shared by MySQL and SQLite
+const statusColumns = `
+	SUM(CASE WHEN l.verdict = '` + VerdictTruePositive + `' THEN 1 ELSE 0 END) AS confirmed,
+	SUM(CASE WHEN l.verdict = '` + VerdictFalsePositive + `' THEN 1 ELSE 0 END) AS dismissed,
+	SUM(CASE WHEN l.verdict IS NULL THEN 1 ELSE 0 END) AS pending`
+
+var views = []struct {
+	name  string
+	query string
+}{
+	{ViewViolationsPerDay, `
+SELECT DATE(r.created_at) AS day,
+	COUNT(*) AS violations,
+	COUNT(DISTINCT r.namespace) AS namespaces,` + statusColumns + `
+FROM detector_records r LEFT JOIN detector_labels l ON l.record_id = r.id
+WHERE r.is_illegal = 1
+GROUP BY DATE(r.created_at)`},
+	{ViewViolationsPerRegion, `
+SELECT r.region AS region,
+	COUNT(*) AS violations,
+	COUNT(DISTINCT r.namespace) AS namespaces,` + statusColumns + `,
+	MAX(r.created_at) AS last_detected_at
+FROM detector_records r LEFT JOIN detector_labels l ON l.record_id = r.id
+WHERE r.is_illegal = 1
+GROUP BY r.region`},
+	{ViewViolationsPerDetector, `
+SELECT r.detector_name AS detector_name,
+	COUNT(*) AS records,
+	SUM(CASE WHEN r.is_illegal = 1 THEN 1 ELSE 0 END) AS violations,
+	SUM(CASE WHEN r.is_illegal = 1 AND l.verdict = '` + VerdictTruePositive + `' THEN 1 ELSE 0 END) AS confirmed,
+	SUM(CASE WHEN r.is_illegal = 1 AND l.verdict = '` + VerdictFalsePositive + `' THEN 1 ELSE 0 END) AS dismissed,
+	SUM(CASE WHEN r.is_illegal = 1 AND l.verdict IS NULL THEN 1 ELSE 0 END) AS pending
+FROM detector_records r LEFT JOIN detector_labels l ON l.record_id = r.id
+GROUP BY r.detector_name`},
+}
+
+// MigrateViews creates or replaces the dashboard views. It runs after the
+// tables were migrated, since the views select from them.
+func MigrateViews(db *gorm.DB) error {
+	for _, view := range views {
+		// SQLite has no CREATE OR REPLACE VIEW
+		if err := db.Exec("DROP VIEW IF EXISTS " + view.name).Error; err != nil {
+			return fmt.Errorf("failed to drop view %s: %w", view.name, err)
+		}
+		if err := db.Exec("CREATE VIEW " + view.name + " AS" + view.query).Error; err != nil {
+			return fmt.Errorf("failed to create view %s: %w", view.name, err)
+		}
+	}
+	return nil
+}
+
+// DayViolations is a row of ViewViolationsPerDay
+type DayViolations struct {
+	Day        string `json:"day"`
+	Violations int    `json:"violations"`
+	Namespaces int    `json:"namespaces"`
+	Confirmed  int    `json:"confirmed"`
+	Dismissed  int    `json:"dismissed"`
+	Pending    int    `json:"pending"`
+}
+
+// RegionViolations is a row of ViewViolationsPerRegion
+type RegionViolations struct {
+	Region     string `json:"region"`
+	Violations int    `json:"violations"`
+	Namespaces int    `json:"namespaces"`
+	Confirmed  int    `json:"confirmed"`
+	Dismissed  int    `json:"dismissed"`
+	Pending    int    `json:"pending"`
+}
+
+// DetectorViolations is a row of ViewViolationsPerDetector
+type DetectorViolations struct {
+	DetectorName string `json:"detector_name"`
+	Records      int    `json:"records"`
+	Violations   int    `json:"violations"`
+	Confirmed    int    `json:"confirmed"`
+	Dismissed    int    `json:"dismissed"`
+	Pending      int    `json:"pending"`
+}
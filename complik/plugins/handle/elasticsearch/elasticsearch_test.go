@@ -0,0 +1,246 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestElasticsearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Elasticsearch Handler Suite")
+}
+
+// cluster is a fake Elasticsearch recording templates and bulk documents
+type cluster struct {
+	mu        sync.Mutex
+	auth      []string
+	templates map[string]map[string]any
+	indices   map[string][]map[string]any
+	status    int
+	reject    string
+}
+
+func newCluster() (*cluster, *httptest.Server) {
+	c := &cluster{
+		templates: map[string]map[string]any{},
+		indices:   map[string][]map[string]any{},
+		status:    http.StatusOK,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /_index_template/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var template map[string]any
+		Expect(json.NewDecoder(r.Body).Decode(&template)).To(Succeed())
+		c.mu.Lock()
+		c.auth = append(c.auth, r.Header.Get("Authorization"))
+		c.templates[r.PathValue("name")] = template
+		c.mu.Unlock()
+		w.Write([]byte(`{"acknowledged":true}`))
+	})
+	mux.HandleFunc("POST /_bulk", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.auth = append(c.auth, r.Header.Get("Authorization"))
+		if c.status != http.StatusOK {
+			w.WriteHeader(c.status)
+			return
+		}
+		var items []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action map[string]map[string]string
+			Expect(json.Unmarshal(scanner.Bytes(), &action)).To(Succeed())
+			Expect(scanner.Scan()).To(BeTrue())
+			var doc map[string]any
+			Expect(json.Unmarshal(scanner.Bytes(), &doc)).To(Succeed())
+			if doc["host"] == c.reject {
+				items = append(items, `{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}`)
+				continue
+			}
+			index := action["index"]["_index"]
+			c.indices[index] = append(c.indices[index], doc)
+			items = append(items, `{"index":{"status":201}}`)
+		}
+		body, _ := json.Marshal(map[string]any{"errors": c.reject != "", "items": json.RawMessage("[" + join(items) + "]")})
+		w.Write(body)
+	})
+	return c, httptest.NewServer(mux)
+}
+
+func join(items []string) string {
+	var buf bytes.Buffer
+	for i, item := range items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(item)
+	}
+	return buf.String()
+}
+
+func (c *cluster) count(index string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.indices[index])
+}
+
+var _ = Describe("Indexer", func() {
+	ctx := context.Background()
+	day := time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC)
+
+	var (
+		c      *cluster
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		c, server = newCluster()
+		DeferCleanup(server.Close)
+	})
+
+	doc := func(host string, at time.Time) Document {
+		return Document{Timestamp: at, DetectorInfo: &models.DetectorInfo{
+			DetectorName: "safety", Namespace: "ns-demo", Host: host, IsIllegal: true,
+			Severity: models.SeverityHigh, Keywords: []string{"casino"},
+		}}
+	}
+
+	It("should install the template for the configured prefix and policy", func() {
+		indexer := NewIndexer(logger.GetLogger(), ClientConfig{URL: server.URL + "/", APIKey: "key"}, "soc-complik", 10, 100)
+		Expect(indexer.InstallTemplate(ctx, "soc-complik", "complik-90d")).To(Succeed())
+		template := c.templates["soc-complik"]
+		Expect(template["index_patterns"]).To(Equal([]any{"soc-complik-*"}))
+		settings := template["template"].(map[string]any)["settings"].(map[string]any)
+		Expect(settings["index.lifecycle.name"]).To(Equal("complik-90d"))
+		Expect(c.auth).To(Equal([]string{"ApiKey key"}))
+	})
+
+	It("should index documents into daily indices in batches", func() {
+		indexer := NewIndexer(logger.GetLogger(), ClientConfig{URL: server.URL, Username: "elastic", Password: "pw"},
+			"complik-detections", 2, 100)
+		Expect(indexer.Add(doc("a.example.com", day))).To(BeFalse())
+		Expect(indexer.Add(doc("b.example.com", day.Add(time.Hour)))).To(BeTrue())
+		indexer.Add(doc("c.example.com", day.Add(time.Hour)))
+		Expect(indexer.Flush(ctx)).To(Succeed())
+		Expect(indexer.Pending()).To(BeZero())
+
+		Expect(c.count("complik-detections-2025.06.01")).To(Equal(1))
+		Expect(c.count("complik-detections-2025.06.02")).To(Equal(2))
+		indexed := c.indices["complik-detections-2025.06.01"][0]
+		Expect(indexed["@timestamp"]).To(Equal("2025-06-01T23:30:00Z"))
+		Expect(indexed["severity"]).To(Equal("high"))
+		Expect(indexed["keywords"]).To(Equal([]any{"casino"}))
+		Expect(c.auth).To(HaveLen(2))
+		Expect(c.auth[0]).To(HavePrefix("Basic "))
+	})
+
+	It("should keep documents while the cluster is unavailable", func() {
+		indexer := NewIndexer(logger.GetLogger(), ClientConfig{URL: server.URL}, "complik-detections", 2, 2)
+		c.status = http.StatusServiceUnavailable
+		indexer.Add(doc("a.example.com", day))
+		indexer.Add(doc("b.example.com", day))
+		Expect(indexer.Flush(ctx)).To(MatchError(ContainSubstring("HTTP 503")))
+		indexer.Add(doc("c.example.com", day))
+		Expect(indexer.Pending()).To(Equal(2))
+
+		c.status = http.StatusOK
+		Expect(indexer.Flush(ctx)).To(Succeed())
+		hosts := []any{}
+		for _, d := range c.indices["complik-detections-2025.06.01"] {
+			hosts = append(hosts, d["host"])
+		}
+		Expect(hosts).To(Equal([]any{"b.example.com", "c.example.com"}))
+	})
+
+	It("should drop documents the cluster rejects", func() {
+		indexer := NewIndexer(logger.GetLogger(), ClientConfig{URL: server.URL}, "complik-detections", 10, 100)
+		c.reject = "bad.example.com"
+		indexer.Add(doc("bad.example.com", day))
+		indexer.Add(doc("a.example.com", day))
+		Expect(indexer.Flush(ctx)).To(Succeed())
+		Expect(indexer.Pending()).To(BeZero())
+		Expect(c.count("complik-detections-2025.06.01")).To(Equal(1))
+	})
+
+	It("should drop batches the cluster refuses permanently and keep them on auth failures", func() {
+		indexer := NewIndexer(logger.GetLogger(), ClientConfig{URL: server.URL}, "complik-detections", 10, 100)
+		c.status = http.StatusUnauthorized
+		indexer.Add(doc("a.example.com", day))
+		err := indexer.Flush(ctx)
+		Expect(complikerrors.ClassOf(err)).To(Equal(complikerrors.AuthFailure))
+		Expect(indexer.Pending()).To(Equal(1))
+
+		c.status = http.StatusBadRequest
+		err = indexer.Flush(ctx)
+		Expect(complikerrors.ClassOf(err)).To(Equal(complikerrors.Permanent))
+		Expect(indexer.Pending()).To(BeZero())
+	})
+})
+
+var _ = Describe("ElasticsearchPlugin", func() {
+	It("should require a url and fill the region of documents", func() {
+		p := &ElasticsearchPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{}`)).To(MatchError(ContainSubstring("url")))
+		Expect(p.loadConfig(`{"url":"http://es:9200","region":"hzh","batchSize":50}`)).To(Succeed())
+		Expect(p.esConfig.BatchSize).To(Equal(50))
+		Expect(*p.esConfig.InstallTemplate).To(BeTrue())
+
+		result := &models.DetectorInfo{Host: "a.example.com"}
+		d := p.document(result, time.Now())
+		Expect(d.Region).To(Equal("hzh"))
+		Expect(result.Region).To(BeEmpty())
+	})
+
+	It("should redact personal data from the indexed copy", func() {
+		p := &ElasticsearchPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"url":"http://es:9200","redactPII":true,"redactMode":"scramble"}`)).
+			To(MatchError(ContainSubstring("redaction mode")))
+		Expect(p.loadConfig(`{"url":"http://es:9200","redactPII":true}`)).To(Succeed())
+
+		result := &models.DetectorInfo{Host: "a.example.com", Description: "call 13812345678 or mail a@example.com"}
+		d := p.document(result, time.Now())
+		Expect(d.Description).To(Equal("call [phone] or mail [email]"))
+		Expect(d.Redactions).To(Equal(2))
+		Expect(result.Description).To(ContainSubstring("13812345678"))
+		data, err := json.Marshal(d)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"redactions":2`))
+	})
+
+	It("should index the remaining results on stop", func() {
+		c, server := newCluster()
+		defer server.Close()
+		p := &ElasticsearchPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"url":"` + server.URL + `","flushIntervalSecond":3600}`)).To(Succeed())
+		p.indexer = NewIndexer(p.log, ClientConfig{URL: server.URL}, p.esConfig.IndexPrefix, 10, 100)
+		p.indexer.Add(p.document(&models.DetectorInfo{Host: "a.example.com"}, time.Now()))
+		Expect(p.Stop(context.Background())).To(Succeed())
+		Expect(c.count(p.indexer.IndexName(time.Now()))).To(Equal(1))
+	})
+})
@@ -0,0 +1,253 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Template is the index template installed for the daily indices; its
+// index pattern and lifecycle policy are set from the configuration
+//
+//go:embed template.json
+var Template []byte
+
+// Document is a detector result as it is indexed
+type Document struct {
+	Timestamp time.Time `json:"@timestamp"`
+	*models.DetectorInfo
+	// Redactions is the number of personal data values removed from the result
+	Redactions int `json:"redactions,omitempty"`
+}
+
+// ClientConfig is how the indexer reaches the cluster
+type ClientConfig struct {
+	URL      string
+	Username string
+	Password string
+	APIKey   string
+	Timeout  time.Duration
+}
+
+// Indexer batches documents into daily indices through the bulk API
+type Indexer struct {
+	log         logger.Logger
+	client      *http.Client
+	cfg         ClientConfig
+	indexPrefix string
+	batchSize   int
+	maxPending  int
+
+	// flushMu keeps one flush at a time so batches are indexed in order
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	pending []Document
+}
+
+// NewIndexer indexes into prefix-YYYY.MM.DD. Up to maxPending documents are
+// kept while the cluster is unavailable; older ones are dropped.
+func NewIndexer(log logger.Logger, cfg ClientConfig, prefix string, batchSize, maxPending int) *Indexer {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &Indexer{
+		log:         log,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		cfg:         cfg,
+		indexPrefix: prefix,
+		batchSize:   batchSize,
+		maxPending:  max(maxPending, batchSize),
+	}
+}
+
+// IndexName returns the daily index of a document indexed at t
+func (i *Indexer) IndexName(t time.Time) string {
+	return i.indexPrefix + "-" + t.UTC().Format("2006.01.02")
+}
+
+// InstallTemplate creates or replaces the index template name. A non-empty
+// policy is set as the index.lifecycle.name of new indices.
+func (i *Indexer) InstallTemplate(ctx context.Context, name, policy string) error {
+	var template map[string]any
+	if err := json.Unmarshal(Template, &template); err != nil {
+		return fmt.Errorf("invalid index template: %w", err)
+	}
+	template["index_patterns"] = []string{i.indexPrefix + "-*"}
+	if policy != "" {
+		settings := template["template"].(map[string]any)["settings"].(map[string]any)
+		settings["index.lifecycle.name"] = policy
+	}
+	body, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("failed to encode index template: %w", err)
+	}
+	_, err = i.request(ctx, http.MethodPut, "/_index_template/"+name, "application/json", body)
+	return err
+}
+
+// Add queues doc and reports whether a full batch is waiting
+func (i *Indexer) Add(doc Document) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pending = append(i.pending, doc)
+	i.dropOldest()
+	return len(i.pending) >= i.batchSize
+}
+
+// dropOldest keeps the newest maxPending documents; callers hold i.mu
+func (i *Indexer) dropOldest() {
+	if dropped := len(i.pending) - i.maxPending; dropped > 0 {
+		i.log.Warn("Dropping unindexed detector results", logger.Fields{
+			"dropped": dropped,
+		})
+		i.pending = append([]Document(nil), i.pending[dropped:]...)
+	}
+}
+
+// Pending returns the number of documents waiting to be indexed
+func (i *Indexer) Pending() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.pending)
+}
+
+// Flush indexes the pending documents batch by batch. Documents of a failed
+// request stay pending for the next flush unless the failure is permanent,
+// such as a malformed bulk request; documents the cluster rejected are logged
+// and dropped, as retrying them fails the same way.
+func (i *Indexer) Flush(ctx context.Context) error {
+	i.flushMu.Lock()
+	defer i.flushMu.Unlock()
+	for {
+		i.mu.Lock()
+		n := min(len(i.pending), i.batchSize)
+		batch := i.pending[:n:n]
+		i.pending = i.pending[n:]
+		i.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if err := i.bulk(ctx, batch); err != nil {
+			if complikerrors.Is(err, complikerrors.Permanent) {
+				i.log.Error("Dropping detector results the cluster cannot index", logger.Fields{
+					"dropped": len(batch),
+					"error":   err.Error(),
+				})
+				return err
+			}
+			i.mu.Lock()
+			i.pending = append(batch, i.pending...)
+			i.dropOldest()
+			i.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// bulkResponse is the part of a bulk API response the indexer reads
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func (i *Indexer) bulk(ctx context.Context, batch []Document) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range batch {
+		action := map[string]map[string]string{"index": {"_index": i.IndexName(doc.Timestamp)}}
+		if err := encoder.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+	data, err := i.request(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return err
+	}
+	var response bulkResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil
+	}
+	for n, item := range response.Items {
+		for _, result := range item {
+			if result.Error == nil || n >= len(batch) {
+				continue
+			}
+			i.log.Error("Detector result rejected by the cluster", logger.Fields{
+				"host":   batch[n].Host,
+				"status": result.Status,
+				"type":   result.Error.Type,
+				"reason": result.Error.Reason,
+			})
+		}
+	}
+	return nil
+}
+
+func (i *Indexer) request(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, i.cfg.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch {
+	case i.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+i.cfg.APIKey)
+	case i.cfg.Username != "":
+		req.SetBasicAuth(i.cfg.Username, i.cfg.Password)
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, complikerrors.Wrap(complikerrors.Transient, fmt.Errorf("request to %s failed: %w", path, err))
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s: %w", path, err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, complikerrors.FromResponse(resp, fmt.Errorf("%s returned HTTP %d: %s", path, resp.StatusCode, truncate(data, 512)))
+	}
+	return data, nil
+}
+
+func truncate(data []byte, n int) string {
+	if len(data) > n {
+		data = data[:n]
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elasticsearch implements a handler plugin that indexes detector
+// results into Elasticsearch or OpenSearch, one index per day, so violations
+// can be searched next to other security logs in Kibana or OpenSearch
+// Dashboards.
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/redact"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.HandleElasticsearch
+	pluginType = constants.HandleElasticsearchPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &ElasticsearchPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type ElasticsearchPlugin struct {
+	log      logger.Logger
+	esConfig ElasticsearchConfig
+	indexer  *Indexer
+	cancel   context.CancelFunc
+	done     chan struct{}
+	// redactor strips personal data from the indexed documents, nil when
+	// RedactPII is off
+	redactor *redact.Redactor
+}
+
+func (p *ElasticsearchPlugin) Name() string {
+	return pluginName
+}
+
+func (p *ElasticsearchPlugin) Type() string {
+	return pluginType
+}
+
+type ElasticsearchConfig struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	APIKey   string `json:"apiKey"`
+	Region   string `json:"region"`
+
+	// IndexPrefix names the daily indices, <indexPrefix>-YYYY.MM.DD
+	IndexPrefix string `json:"indexPrefix"`
+	// TemplateName is the index template installed on start unless
+	// InstallTemplate is false
+	TemplateName    string `json:"templateName"`
+	InstallTemplate *bool  `json:"installTemplate"`
+	// ILMPolicy is set as index.lifecycle.name of new indices; leave it
+	// empty on OpenSearch, which attaches ISM policies by index pattern
+	ILMPolicy string `json:"ilmPolicy"`
+
+	BatchSize           int `json:"batchSize"`
+	MaxPending          int `json:"maxPending"`
+	FlushIntervalSecond int `json:"flushIntervalSecond"`
+	TimeoutSecond       int `json:"timeoutSecond"`
+
+	// RedactPII strips email addresses, phone numbers and identity numbers
+	// from the indexed free text; RedactMode and RedactSalt are those of the
+	// Postgres handler
+	RedactPII  bool   `json:"redactPII"`
+	RedactMode string `json:"redactMode"`
+	RedactSalt string `json:"redactSalt"`
+}
+
+func (p *ElasticsearchPlugin) getDefaultConfig() ElasticsearchConfig {
+	b := true
+	return ElasticsearchConfig{
+		Region:              "UNKNOWN",
+		IndexPrefix:         "complik-detections",
+		TemplateName:        "complik-detections",
+		InstallTemplate:     &b,
+		BatchSize:           200,
+		MaxPending:          10000,
+		FlushIntervalSecond: 5,
+		TimeoutSecond:       10,
+	}
+}
+
+func (p *ElasticsearchPlugin) loadConfig(setting string) error {
+	p.esConfig = p.getDefaultConfig()
+	if setting == "" {
+		return errors.New("configuration cannot be empty")
+	}
+	var configFromJSON ElasticsearchConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.URL == "" {
+		return errors.New("url configuration cannot be empty")
+	}
+	p.esConfig.URL = configFromJSON.URL
+	p.esConfig.Username = configFromJSON.Username
+	p.esConfig.ILMPolicy = configFromJSON.ILMPolicy
+	if configFromJSON.Region != "" {
+		p.esConfig.Region = configFromJSON.Region
+	}
+	if configFromJSON.IndexPrefix != "" {
+		p.esConfig.IndexPrefix = configFromJSON.IndexPrefix
+	}
+	if configFromJSON.TemplateName != "" {
+		p.esConfig.TemplateName = configFromJSON.TemplateName
+	}
+	if configFromJSON.InstallTemplate != nil {
+		p.esConfig.InstallTemplate = configFromJSON.InstallTemplate
+	}
+	if configFromJSON.BatchSize > 0 {
+		p.esConfig.BatchSize = configFromJSON.BatchSize
+	}
+	if configFromJSON.MaxPending > 0 {
+		p.esConfig.MaxPending = configFromJSON.MaxPending
+	}
+	if configFromJSON.FlushIntervalSecond > 0 {
+		p.esConfig.FlushIntervalSecond = configFromJSON.FlushIntervalSecond
+	}
+	if configFromJSON.TimeoutSecond > 0 {
+		p.esConfig.TimeoutSecond = configFromJSON.TimeoutSecond
+	}
+
+	// The password and API key may come from a secret store
+	for _, secret := range []struct {
+		name   string
+		value  string
+		target *string
+	}{
+		{"password", configFromJSON.Password, &p.esConfig.Password},
+		{"API key", configFromJSON.APIKey, &p.esConfig.APIKey},
+		{"redaction salt", configFromJSON.RedactSalt, &p.esConfig.RedactSalt},
+	} {
+		if secret.value == "" {
+			continue
+		}
+		if value, err := config.GetSecureValue(secret.value); err == nil {
+			*secret.target = value
+		} else if config.IsSecretReference(secret.value) {
+			return fmt.Errorf("failed to resolve %s: %w", secret.name, err)
+		} else {
+			*secret.target = secret.value
+		}
+	}
+
+	p.esConfig.RedactPII = configFromJSON.RedactPII
+	p.esConfig.RedactMode = configFromJSON.RedactMode
+	p.redactor = nil
+	if p.esConfig.RedactPII {
+		redactor, err := redact.New(p.esConfig.RedactMode, p.esConfig.RedactSalt)
+		if err != nil {
+			return err
+		}
+		p.redactor = redactor
+	}
+
+	p.log.Info("Elasticsearch configuration loaded", logger.Fields{
+		"url":          p.esConfig.URL,
+		"index_prefix": p.esConfig.IndexPrefix,
+		"ilm_policy":   p.esConfig.ILMPolicy,
+		"batch_size":   p.esConfig.BatchSize,
+		"redact":       p.esConfig.RedactPII,
+	})
+	return nil
+}
+
+func (p *ElasticsearchPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.indexer = NewIndexer(p.log, ClientConfig{
+		URL:      p.esConfig.URL,
+		Username: p.esConfig.Username,
+		Password: p.esConfig.Password,
+		APIKey:   p.esConfig.APIKey,
+		Timeout:  time.Duration(p.esConfig.TimeoutSecond) * time.Second,
+	}, p.esConfig.IndexPrefix, p.esConfig.BatchSize, p.esConfig.MaxPending)
+
+	if *p.esConfig.InstallTemplate {
+		templateCtx, cancel := context.WithTimeout(ctx, time.Duration(p.esConfig.TimeoutSecond)*time.Second)
+		err := p.indexer.InstallTemplate(templateCtx, p.esConfig.TemplateName, p.esConfig.ILMPolicy)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to install index template: %w", err)
+		}
+	}
+
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		ticker := time.NewTicker(time.Duration(p.esConfig.FlushIntervalSecond) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				result, ok := event.Payload.(*models.DetectorInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.DetectorInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if p.indexer.Add(p.document(result, time.Now())) {
+					p.flush(ctx)
+				}
+			case <-ticker.C:
+				p.flush(ctx)
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// document copies result so the region can be filled and personal data
+// redacted without touching the event other handlers share
+func (p *ElasticsearchPlugin) document(result *models.DetectorInfo, now time.Time) Document {
+	redactedInfo, redacted := p.redactor.Detection(result)
+	if redacted.Total() > 0 {
+		p.log.Info("Personal data redacted from indexed document", logger.Fields{
+			"host":      result.Host,
+			"namespace": result.Namespace,
+			"redacted":  redacted.String(),
+		})
+	}
+	info := *redactedInfo
+	if info.Region == "" {
+		info.Region = p.esConfig.Region
+	}
+	return Document{Timestamp: now, DetectorInfo: &info, Redactions: redacted.Total()}
+}
+
+func (p *ElasticsearchPlugin) flush(ctx context.Context) {
+	if err := p.indexer.Flush(ctx); err != nil {
+		p.log.Error("Failed to index detector results", logger.Fields{
+			"pending": p.indexer.Pending(),
+			"error":   err.Error(),
+			"class":   complikerrors.Record(p.Name(), err),
+		})
+	}
+}
+
+func (p *ElasticsearchPlugin) Stop(ctx context.Context) error {
+	if p.indexer == nil {
+		return nil
+	}
+	if p.cancel != nil {
+		p.cancel()
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+		}
+	}
+	// Index what is left within the shutdown deadline
+	return p.indexer.Flush(ctx)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+var appealTemplates = map[string]string{
+	models.AppealPending:  "orange",
+	models.AppealApproved: "green",
+	models.AppealRejected: "grey",
+	models.AppealFailed:   "red",
+}
+
+// SendAppealNotification tells the compliance staff about a submitted appeal
+// and about the decision on it. Appeals are routed like a violation of the
+// namespace and are never skipped for whitelisted namespaces, as the appeal
+// itself asks for a review.
+func (f *Notifier) SendAppealNotification(appeal *models.AppealEvent) error {
+	if f.WebhookURL == "" && f.Router == nil {
+		return errors.New("webhook URL not configured, skipping notification")
+	}
+	if appeal == nil {
+		return errors.New("appeal is empty")
+	}
+	message := LarkMessage{
+		MsgType: "interactive",
+		Card:    buildAppealMessage(appeal),
+	}
+	return f.deliver(&models.DetectorInfo{
+		Region:    appeal.Region,
+		Namespace: appeal.Namespace,
+		Host:      appeal.Host,
+		IsIllegal: true,
+	}, message)
+}
+
+func buildAppealMessage(appeal *models.AppealEvent) map[string]any {
+	div := func(content string) map[string]any {
+		return map[string]any{
+			"tag": "div",
+			"text": map[string]any{
+				"content": content,
+				"tag":     "lark_md",
+			},
+		}
+	}
+
+	elements := []map[string]any{
+		div("**Region:** " + appeal.Region),
+		div("**Namespace:** " + appeal.Namespace),
+		div(fmt.Sprintf("**Record:** #%d", appeal.RecordID)),
+	}
+	if appeal.Host != "" {
+		elements = append(elements, div("**Host:** "+appeal.Host))
+	}
+	if appeal.Detector != "" {
+		elements = append(elements, div("**Detector:** "+appeal.Detector))
+	}
+	if appeal.Contact != "" {
+		elements = append(elements, div("**Contact:** "+appeal.Contact))
+	}
+	elements = append(elements,
+		map[string]any{"tag": "hr"},
+		div("**Reason:** "+appeal.Reason),
+	)
+
+	title := fmt.Sprintf("Appeal #%d submitted: %s", appeal.ID, appeal.Namespace)
+	if appeal.Status == models.AppealPending {
+		elements = append(elements, div("**Please review the appeal promptly!**"))
+	} else {
+		title = fmt.Sprintf("Appeal #%d %s: %s", appeal.ID, appeal.Status, appeal.Namespace)
+		elements = append(elements, map[string]any{"tag": "hr"}, div("**Reviewer:** "+appeal.Reviewer))
+		if appeal.Comment != "" {
+			elements = append(elements, div("**Comment:** "+appeal.Comment))
+		}
+		if appeal.Unlocked {
+			elements = append(elements, div("**Namespace unlocked**"))
+		}
+		if appeal.Error != "" {
+			elements = append(elements, div("**Unlock failed:** "+appeal.Error))
+		}
+	}
+
+	return map[string]any{
+		"config": map[string]any{
+			"wide_screen_mode": true,
+		},
+		"header": map[string]any{
+			"template": appealTemplates[appeal.Status],
+			"title": map[string]any{
+				"content": title,
+				"tag":     "plain_text",
+			},
+		},
+		"elements": elements,
+	}
+}
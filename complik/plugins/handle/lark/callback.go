@@ -0,0 +1,263 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lark
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// Card actions that can be triggered from alert card buttons
+const (
+	ActionAcknowledge = "acknowledge"
+	ActionSnooze      = "snooze"
+	ActionWhitelist   = "whitelist"
+)
+
+// SnoozeDuration is how long a snoozed host stays silent
+const SnoozeDuration = 24 * time.Hour
+
+// AlertAction records an action taken by an on-call engineer from a Lark card
+type AlertAction struct {
+	ID        uint       `gorm:"primaryKey"     json:"id"`
+	Action    string     `gorm:"size:32;index"  json:"action"`
+	Region    string     `gorm:"size:64;index"  json:"region"`
+	Namespace string     `gorm:"size:255;index" json:"namespace"`
+	Host      string     `gorm:"size:255;index" json:"host"`
+	Operator  string     `gorm:"size:255"       json:"operator"`
+	ExpiresAt *time.Time `gorm:"index"          json:"expires_at,omitempty"`
+	CreatedAt time.Time  `                      json:"created_at"`
+}
+
+func (AlertAction) TableName() string {
+	return "alert_actions"
+}
+
+// actionValue is the payload attached to every card button
+type actionValue struct {
+	Action    string `json:"action"`
+	Region    string `json:"region"`
+	Namespace string `json:"namespace"`
+	Host      string `json:"host"`
+	Name      string `json:"name"`
+}
+
+// cardCallback is the request body Lark sends for URL verification and card interactions
+type cardCallback struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Token     string `json:"token"`
+	OpenID    string `json:"open_id"`
+	UserID    string `json:"user_id"`
+	Action    struct {
+		Tag   string      `json:"tag"`
+		Value actionValue `json:"value"`
+	} `json:"action"`
+}
+
+// CallbackHandler handles interactive card callbacks from Lark
+type CallbackHandler struct {
+	log      logger.Logger
+	token    string
+	notifier *Notifier
+	db       *gorm.DB
+}
+
+func NewCallbackHandler(
+	log logger.Logger,
+	token string,
+	notifier *Notifier,
+	db *gorm.DB,
+) *CallbackHandler {
+	return &CallbackHandler{
+		log:      log,
+		token:    token,
+		notifier: notifier,
+		db:       db,
+	}
+}
+
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var callback cardCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		http.Error(w, "invalid callback payload", http.StatusBadRequest)
+		return
+	}
+	// Without a token every callback is refused
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(callback.Token), []byte(h.token)) != 1 {
+		h.log.Warn("Rejected Lark callback with invalid token")
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if callback.Type == "url_verification" {
+		_ = json.NewEncoder(w).Encode(map[string]string{"challenge": callback.Challenge})
+		return
+	}
+
+	operator := callback.OpenID
+	if callback.UserID != "" {
+		operator = callback.UserID
+	}
+	if err := h.handleAction(callback.Action.Value, operator); err != nil {
+		h.log.Error("Failed to handle card action", logger.Fields{
+			"action":    callback.Action.Value.Action,
+			"namespace": callback.Action.Value.Namespace,
+			"host":      callback.Action.Value.Host,
+			"error":     err.Error(),
+		})
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	h.log.Info("Card action handled", logger.Fields{
+		"action":    callback.Action.Value.Action,
+		"namespace": callback.Action.Value.Namespace,
+		"host":      callback.Action.Value.Host,
+		"operator":  operator,
+	})
+	_, _ = w.Write([]byte("{}"))
+}
+
+func (h *CallbackHandler) handleAction(value actionValue, operator string) error {
+	record := &AlertAction{
+		Action:    value.Action,
+		Region:    value.Region,
+		Namespace: value.Namespace,
+		Host:      value.Host,
+		Operator:  operator,
+	}
+	switch value.Action {
+	case ActionAcknowledge:
+		if h.notifier.Router != nil {
+			h.notifier.Router.Acknowledge(value.Namespace)
+		}
+	case ActionSnooze:
+		if value.Host == "" {
+			return errors.New("snooze requires a host")
+		}
+		if h.db == nil {
+			return errors.New("snooze requires the whitelist database")
+		}
+		expiresAt := time.Now().Add(SnoozeDuration)
+		record.ExpiresAt = &expiresAt
+	case ActionWhitelist:
+		if value.Host == "" && value.Namespace == "" {
+			return errors.New("whitelist requires a host or namespace")
+		}
+		if h.db == nil {
+			return errors.New("whitelist requires the whitelist database")
+		}
+		entry := &whitelist.Whitelist{
+			Region:    value.Region,
+			Name:      value.Name,
+			Namespace: value.Namespace,
+			Hostname:  value.Host,
+			Type:      whitelist.WhitelistTypeHost,
+			Remark:    "Added from Lark alert card by " + operator,
+		}
+		if value.Host == "" {
+			entry.Type = whitelist.WhitelistTypeNamespace
+		}
+		if err := h.notifier.WhitelistService.Create(entry); err != nil {
+			return fmt.Errorf("failed to add whitelist entry: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", value.Action)
+	}
+
+	if h.db == nil {
+		return nil
+	}
+	if err := h.db.Create(record).Error; err != nil {
+		return fmt.Errorf("failed to record action: %w", err)
+	}
+	return nil
+}
+
+// IsSnoozed reports whether host has an active snooze recorded in the database
+func IsSnoozed(db *gorm.DB, host, region string) (bool, error) {
+	if db == nil || host == "" {
+		return false, nil
+	}
+	var count int64
+	err := db.Session(&gorm.Session{Logger: gormLogger.Discard}).
+		Model(&AlertAction{}).
+		Where("action = ? AND host = ? AND region = ? AND expires_at > ?", ActionSnooze, host, region, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// buildActionElements returns the button row attached to alert cards. The
+// snooze and whitelist buttons are only offered when persistent, since both
+// are stored in the whitelist database.
+func buildActionElements(region, namespace, host, name string, persistent bool) []map[string]any {
+	button := func(text, buttonType, action string) map[string]any {
+		return map[string]any{
+			"tag": "button",
+			"text": map[string]any{
+				"content": text,
+				"tag":     "plain_text",
+			},
+			"type": buttonType,
+			"value": actionValue{
+				Action:    action,
+				Region:    region,
+				Namespace: namespace,
+				Host:      host,
+				Name:      name,
+			},
+		}
+	}
+	actions := []map[string]any{button("Acknowledge", "primary", ActionAcknowledge)}
+	if persistent && host == "" {
+		// Cards that are not about a single host act on the whole namespace
+		actions = append(actions, button("Add Namespace to Whitelist", "danger", ActionWhitelist))
+	} else if persistent {
+		actions = append(actions,
+			button("Snooze Host 24h", "default", ActionSnooze),
+			button("Add Host to Whitelist", "danger", ActionWhitelist),
+		)
+	}
+	return []map[string]any{
+		{
+			"tag":     "action",
+			"actions": actions,
+		},
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lark
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+)
+
+// EmailConfig is the SMTP server sending the alerts of owning teams with
+// email addresses
+type EmailConfig struct {
+	// Addr is the host:port of the SMTP server
+	Addr     string `json:"addr"`
+	From     string `json:"from"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// emailMessage is the plain text form of an alert
+type emailMessage struct {
+	subject string
+	body    string
+}
+
+// Mailer sends alerts to the email addresses of owning teams
+type Mailer struct {
+	cfg  EmailConfig
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewMailer(cfg EmailConfig) *Mailer {
+	return &Mailer{cfg: cfg, send: smtp.SendMail}
+}
+
+// Send delivers message to the addresses, STARTTLS is used when the server
+// offers it
+func (m *Mailer) Send(to []string, message emailMessage) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(m.cfg.Addr)
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", m.cfg.header(message.subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(message.body, "\n", "\r\n"))
+	if err := m.send(m.cfg.Addr, auth, m.cfg.From, to, []byte(msg.String())); err != nil {
+		return complikerrors.Wrap(complikerrors.Transient, fmt.Errorf("failed to send email: %w", err))
+	}
+	return nil
+}
+
+// header strips line breaks so a subject cannot add headers
+func (c EmailConfig) header(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// buildEmail renders results as a plain text alert
+func buildEmail(results *models.DetectorInfo) emailMessage {
+	severity := routing.EffectiveSeverity(results)
+	subject := fmt.Sprintf("[CompliK] %s violation in namespace %s", severity, results.Namespace)
+	lines := []string{
+		"Region: " + results.Region,
+		"Namespace: " + results.Namespace,
+		"Resource Name: " + results.Name,
+	}
+	if results.Host != "" {
+		lines = append(lines, "Host: "+results.Host)
+	}
+	if results.URL != "" {
+		lines = append(lines, "URL: "+results.URL)
+	}
+	lines = append(lines, "Severity: "+severity)
+	if results.Explanation != "" {
+		lines = append(lines, "", results.Explanation)
+	}
+	return emailMessage{subject: subject, body: strings.Join(lines, "\n")}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lark
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// SendIncidentNotification sends a single card summarizing the findings of
+// every pipeline that flagged the incident namespace
+func (f *Notifier) SendIncidentNotification(incident *models.Incident) error {
+	if f.WebhookURL == "" && f.Router == nil {
+		return errors.New("webhook URL not configured, skipping notification")
+	}
+	if incident == nil {
+		return errors.New("incident is empty")
+	}
+	if f.db != nil && f.WhitelistService != nil {
+		whitelisted, _, err := f.WhitelistService.IsWhitelisted(incident.Namespace, "", f.Region)
+		if err != nil {
+			log.Printf("Whitelist check failed: %v", err)
+		} else if whitelisted {
+			log.Printf("Namespace %s is in whitelist, skipping incident notification", incident.Namespace)
+			return nil
+		}
+	}
+	message := LarkMessage{
+		MsgType: "interactive",
+		Card:    f.buildIncidentMessage(incident),
+	}
+	// Route incidents like a detection of the combined severity
+	return f.deliver(&models.DetectorInfo{
+		Region:    incident.Region,
+		Namespace: incident.Namespace,
+		IsIllegal: true,
+		Severity:  incident.Severity,
+	}, message)
+}
+
+var sourceTitles = map[string]string{
+	models.SourceWebsite:  "Website content",
+	models.SourceMining:   "Mining",
+	models.SourceProcscan: "Process scan",
+	models.SourceWatchdog: "Scanner integrity",
+}
+
+func sourceTitle(source string) string {
+	if title, ok := sourceTitles[source]; ok {
+		return title
+	}
+	return source
+}
+
+func (f *Notifier) buildIncidentMessage(incident *models.Incident) map[string]any {
+	titles := make([]string, 0, len(incident.Sources))
+	for _, source := range incident.Sources {
+		titles = append(titles, sourceTitle(source))
+	}
+	div := func(content string) map[string]any {
+		return map[string]any{
+			"tag": "div",
+			"text": map[string]any{
+				"content": content,
+				"tag":     "lark_md",
+			},
+		}
+	}
+
+	elements := []map[string]any{
+		div("**Region:** " + incident.Region),
+		div("**Namespace:** " + incident.Namespace),
+		div("**Severity:** " + incident.Severity),
+		div("**Flagged By:** " + strings.Join(titles, " + ")),
+		{"tag": "hr"},
+	}
+	for _, source := range incident.Sources {
+		content := fmt.Sprintf("**%s**\n", sourceTitle(source))
+		shown := 0
+		for _, finding := range incident.Findings {
+			if finding.Source != source {
+				continue
+			}
+			if shown == 5 {
+				content += "  • ...\n"
+				break
+			}
+			content += fmt.Sprintf("  • %s\n", finding.Summary)
+			shown++
+		}
+		elements = append(elements, div(content))
+	}
+	elements = append(elements,
+		map[string]any{"tag": "hr"},
+		div(fmt.Sprintf("**Window:** %s - %s",
+			incident.FirstSeen.Format(time.DateTime), incident.LastSeen.Format(time.DateTime))),
+		div("**Please handle the violation content promptly!**"),
+	)
+	if f.ActionsEnabled {
+		elements = append(elements, buildActionElements(incident.Region, incident.Namespace, "", "", f.db != nil)...)
+	}
+
+	return map[string]any{
+		"config": map[string]any{
+			"wide_screen_mode": true,
+		},
+		"header": map[string]any{
+			"template": "carmine",
+			"title": map[string]any{
+				"content": fmt.Sprintf("Correlated Incident: %s flagged by %d pipelines", incident.Namespace, len(incident.Sources)),
+				"tag":     "plain_text",
+			},
+		},
+		"elements": elements,
+	}
+}
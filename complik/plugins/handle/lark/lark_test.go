@@ -0,0 +1,135 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lark
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLark(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lark Handler Suite")
+}
+
+var _ = Describe("CallbackHandler", func() {
+	var handler *CallbackHandler
+
+	send := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/lark/callback", strings.NewReader(body)))
+		return rec
+	}
+
+	BeforeEach(func() {
+		handler = NewCallbackHandler(logger.GetLogger(), "secret", NewNotifier("", nil, 0, "", nil), nil)
+	})
+
+	It("should reject callbacks without the token", func() {
+		Expect(send(`{"type":"url_verification","challenge":"abc"}`).Code).To(Equal(http.StatusUnauthorized))
+		Expect(send(`{"token":"wrong","action":{"value":{"action":"acknowledge"}}}`).Code).
+			To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject every callback without a configured token", func() {
+		handler = NewCallbackHandler(logger.GetLogger(), "", NewNotifier("", nil, 0, "", nil), nil)
+		Expect(send(`{"token":"","action":{"value":{"action":"acknowledge"}}}`).Code).
+			To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should answer the URL verification", func() {
+		rec := send(`{"type":"url_verification","challenge":"abc","token":"secret"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var response map[string]string
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response["challenge"]).To(Equal("abc"))
+	})
+
+	It("should handle card actions", func() {
+		Expect(send(`{"token":"secret","action":{"value":{"action":"acknowledge","namespace":"ns-a"}}}`).Code).
+			To(Equal(http.StatusOK))
+		Expect(send(`{"token":"secret","action":{"value":{"action":"snooze","host":"a.example.com"}}}`).Code).
+			To(Equal(http.StatusInternalServerError))
+	})
+})
+
+var _ = Describe("Card actions", func() {
+	labels := func(elements []map[string]any) []string {
+		var texts []string
+		for _, action := range elements[0]["actions"].([]map[string]any) {
+			texts = append(texts, action["text"].(map[string]any)["content"].(string))
+		}
+		return texts
+	}
+
+	It("should only offer acknowledge without the whitelist database", func() {
+		Expect(labels(buildActionElements("hzh", "ns-a", "a.example.com", "", false))).
+			To(Equal([]string{"Acknowledge"}))
+	})
+
+	It("should offer snooze and whitelist with the whitelist database", func() {
+		Expect(labels(buildActionElements("hzh", "ns-a", "a.example.com", "", true))).
+			To(Equal([]string{"Acknowledge", "Snooze Host 24h", "Add Host to Whitelist"}))
+		Expect(labels(buildActionElements("hzh", "ns-a", "", "", true))).
+			To(Equal([]string{"Acknowledge", "Add Namespace to Whitelist"}))
+	})
+})
+
+var _ = Describe("LarkPlugin", func() {
+	It("should not serve callbacks without a token", func() {
+		p := &LarkPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","callbackAddr":":8093"}`)).
+			To(MatchError(ContainSubstring("callbackToken")))
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","callbackAddr":":8093","callbackToken":"secret"}`)).
+			To(Succeed())
+	})
+
+	It("should not serve the ownership API without a token", func() {
+		p := &LarkPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","ownership":{"apiAddr":":8096"}}`)).
+			To(MatchError(ContainSubstring("ownership apiToken")))
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","ownership":{"apiAddr":":8096","apiToken":"secret"}}`)).
+			To(Succeed())
+	})
+
+	It("should not serve the whitelist API without a token", func() {
+		p := &LarkPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","whitelistApiAddr":":8094"}`)).
+			To(MatchError(ContainSubstring("whitelistApiToken")))
+		Expect(p.loadConfig(`{"webhook":"https://open.feishu.cn/hook","whitelistApiAddr":":8094","whitelistApiToken":"secret"}`)).
+			To(Succeed())
+
+		api := whitelist.NewAPI(logger.GetLogger(), "", nil, "hzh")
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+			path := "/api/v1/whitelists"
+			if method == http.MethodDelete {
+				path += "/1"
+			}
+			req := httptest.NewRequest(method, path, strings.NewReader(`{"namespace":"ns-a"}`))
+			req.Header.Set("Authorization", "Bearer ")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		}
+	})
+})
@@ -19,27 +19,60 @@ package lark
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/ownership"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
 	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
 	"gorm.io/gorm"
 )
 
+// maxDeferredMessages bounds the messages held back during quiet hours
+const maxDeferredMessages = 1000
+
 type Notifier struct {
 	WebhookURL       string
 	HTTPClient       *http.Client
 	WhitelistService *whitelist.WhitelistService
 	Region           string
+	Router           *routing.Router
+	// ActionsEnabled adds acknowledge/snooze/whitelist buttons to alert cards.
+	// It is only set when the callback endpoint is served.
+	ActionsEnabled bool
+	// Owners routes the alerts of namespaces with an owning team to the team,
+	// Mailer sends them to its email addresses
+	Owners *ownership.Resolver
+	Mailer *Mailer
+
+	db         *gorm.DB
+	deferredMu sync.Mutex
+	deferred   []deferredMessage
+}
+
+type deferredMessage struct {
+	target  routing.Target
+	message LarkMessage
+	email   emailMessage
 }
 
-func NewNotifier(webhookURL string, db *gorm.DB, timeout time.Duration, region string) *Notifier {
+func NewNotifier(
+	webhookURL string,
+	db *gorm.DB,
+	timeout time.Duration,
+	region string,
+	router *routing.Router,
+) *Notifier {
 	return &Notifier{
 		WebhookURL: webhookURL,
 		HTTPClient: &http.Client{
@@ -47,11 +80,13 @@ func NewNotifier(webhookURL string, db *gorm.DB, timeout time.Duration, region s
 		},
 		WhitelistService: whitelist.NewWhitelistService(db, timeout),
 		Region:           region,
+		Router:           router,
+		db:               db,
 	}
 }
 
 func (f *Notifier) SendAnalysisNotification(results *models.DetectorInfo) error {
-	if f.WebhookURL == "" {
+	if f.WebhookURL == "" && f.Router == nil {
 		fmt.Println("Webhook URL not configured, skipping notification")
 		return errors.New("webhook URL not configured, skipping notification")
 	}
@@ -62,6 +97,13 @@ func (f *Notifier) SendAnalysisNotification(results *models.DetectorInfo) error
 	if !results.IsIllegal {
 		return nil
 	}
+	snoozed, err := IsSnoozed(f.db, results.Host, f.Region)
+	if err != nil {
+		log.Printf("Snooze check failed: %v", err)
+	} else if snoozed {
+		log.Printf("Host %s is snoozed, skipping notification", results.Host)
+		return nil
+	}
 	isWhitelisted := false
 	var whitelistInfo *whitelist.Whitelist
 	if f.WhitelistService != nil {
@@ -89,7 +131,119 @@ func (f *Notifier) SendAnalysisNotification(results *models.DetectorInfo) error
 		MsgType: "interactive",
 		Card:    cardContent,
 	}
-	return f.sendMessage(message)
+	return f.deliver(results, message)
+}
+
+// deliver sends message to every target the router resolves for results,
+// holding back deliveries that fall into quiet hours
+func (f *Notifier) deliver(results *models.DetectorInfo, message LarkMessage) error {
+	if f.Router == nil {
+		return f.sendMessage(f.WebhookURL, message)
+	}
+	targets := f.Router.ResolveFor(results, f.owner(results.Namespace), time.Now())
+	if len(targets) == 0 {
+		log.Printf("No notification route matched [Namespace: %s, Host: %s]", results.Namespace, results.Host)
+		return nil
+	}
+	email := buildEmail(results)
+	var errs []error
+	for _, target := range targets {
+		msg := message
+		if target.Escalated {
+			msg = f.buildEscalationMessage(message, f.Router.Unacknowledged(results.Namespace))
+		}
+		if target.Deferred() {
+			f.deferMessage(deferredMessage{target: target, message: msg, email: email})
+			continue
+		}
+		if err := f.sendTarget(target, msg, email); err != nil {
+			errs = append(errs, fmt.Errorf("route %s: %w", target.Route, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// owner returns the owner of namespace, nil without ownership routing
+func (f *Notifier) owner(namespace string) *ownership.Owner {
+	if f.Owners == nil || namespace == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	owner := f.Owners.Resolve(ctx, namespace)
+	if owner.Error != "" {
+		log.Printf("Failed to resolve the owner of namespace %s, using the platform channel: %s", namespace, owner.Error)
+	}
+	return &owner
+}
+
+// sendTarget sends message to the webhook of target and email to its
+// addresses
+func (f *Notifier) sendTarget(target routing.Target, message LarkMessage, email emailMessage) error {
+	var errs []error
+	if target.Webhook != "" {
+		errs = append(errs, f.sendMessage(target.Webhook, message))
+	}
+	if len(target.Emails) > 0 {
+		if f.Mailer == nil {
+			log.Printf("Email not configured, skipping the email of route %s", target.Route)
+		} else {
+			errs = append(errs, f.Mailer.Send(target.Emails, email))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Notifier) deferMessage(pending deferredMessage) {
+	f.deferredMu.Lock()
+	defer f.deferredMu.Unlock()
+	if len(f.deferred) >= maxDeferredMessages {
+		log.Printf("Deferred notification queue full, dropping oldest message")
+		f.deferred = f.deferred[1:]
+	}
+	f.deferred = append(f.deferred, pending)
+}
+
+// FlushDeferred sends the messages whose quiet hours have ended by now
+func (f *Notifier) FlushDeferred(now time.Time) {
+	f.deferredMu.Lock()
+	var due []deferredMessage
+	remaining := f.deferred[:0]
+	for _, pending := range f.deferred {
+		if now.Before(pending.target.DeferUntil) {
+			remaining = append(remaining, pending)
+		} else {
+			due = append(due, pending)
+		}
+	}
+	f.deferred = remaining
+	f.deferredMu.Unlock()
+
+	for _, pending := range due {
+		if err := f.sendTarget(pending.target, pending.message, pending.email); err != nil {
+			log.Printf("Failed to send deferred notification via route %s: %v", pending.target.Route, err)
+		}
+	}
+}
+
+// buildEscalationMessage marks an alert card as escalated without mutating the original
+func (f *Notifier) buildEscalationMessage(message LarkMessage, unacked int) LarkMessage {
+	card, ok := message.Card.(map[string]any)
+	if !ok {
+		return message
+	}
+	escalated := make(map[string]any, len(card))
+	for k, v := range card {
+		escalated[k] = v
+	}
+	escalated["header"] = map[string]any{
+		"template": "carmine",
+		"title": map[string]any{
+			"content": fmt.Sprintf("[Escalated] %d unacknowledged critical alerts", unacked),
+			"tag":     "plain_text",
+		},
+	}
+	return LarkMessage{MsgType: message.MsgType, Card: escalated}
 }
 
 func (f *Notifier) buildWhitelistMessage(
@@ -141,6 +295,10 @@ func (f *Notifier) buildWhitelistMessage(
 		},
 	}
 
+	if results.Workload != nil {
+		basicInfoElements = append(basicInfoElements, buildWorkloadElements(results.Workload)...)
+	}
+
 	if len(results.Path) > 0 {
 		pathContent := "**Detection Paths:**\n"
 		for i, path := range results.Path {
@@ -477,6 +635,10 @@ func (f *Notifier) buildAlertMessage(results *models.DetectorInfo) map[string]an
 				"tag":     "lark_md",
 			},
 		})
+		if f.ActionsEnabled {
+			elements = append(elements,
+				buildActionElements(results.Region, results.Namespace, results.Host, results.Name, f.db != nil)...)
+		}
 	}
 
 	template := "green"
@@ -501,31 +663,75 @@ func (f *Notifier) buildAlertMessage(results *models.DetectorInfo) map[string]an
 	}
 }
 
-func (f *Notifier) sendMessage(message LarkMessage) error {
+// buildWorkloadElements describes the workload and tenant behind a flagged host
+func buildWorkloadElements(workload *models.WorkloadInfo) []map[string]any {
+	lines := []string{fmt.Sprintf("**Workload:** %s/%s", workload.Kind, workload.Name)}
+	if workload.Service != "" {
+		lines = append(lines, "**Service:** "+workload.Service)
+	}
+	if len(workload.Images) > 0 {
+		lines = append(lines, "**Images:** `"+strings.Join(workload.Images, "`, `")+"`")
+	}
+	if workload.UserID != "" {
+		lines = append(lines, "**Owner:** "+workload.UserID)
+	}
+	if workload.Team != "" {
+		lines = append(lines, "**Team:** "+workload.Team)
+	}
+	if !workload.CreatedAt.IsZero() {
+		lines = append(lines, "**Created:** "+workload.CreatedAt.Format(time.DateTime))
+	}
+	elements := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		elements = append(elements, map[string]any{
+			"tag": "div",
+			"text": map[string]any{
+				"content": line,
+				"tag":     "lark_md",
+			},
+		})
+	}
+	return elements
+}
+
+// Lark webhook error codes that tell the failure apart from the HTTP status
+const (
+	larkCodeRateLimited  = 11232
+	larkCodeBadSignature = 19021
+)
+
+func (f *Notifier) sendMessage(webhookURL string, message LarkMessage) error {
 	jsonData, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to serialize message: %w", err)
 	}
 	resp, err := f.HTTPClient.Post(
-		f.WebhookURL,
+		webhookURL,
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to send HTTP request: %w", err)
+		return complikerrors.Wrap(complikerrors.Transient, fmt.Errorf("failed to send HTTP request: %w", err))
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return complikerrors.Wrap(complikerrors.Transient, fmt.Errorf("failed to read response: %w", err))
 	}
 	var larkResp LarkResponse
 	if err := json.Unmarshal(body, &larkResp); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return complikerrors.FromResponse(resp, fmt.Errorf("failed to parse response: %w", err))
 	}
 	if resp.StatusCode != http.StatusOK || larkResp.Code != 0 {
-		return fmt.Errorf("Lark webhook notification failed: HTTP status %d, Lark error code %d, error message: %s",
+		err := fmt.Errorf("Lark webhook notification failed: HTTP status %d, Lark error code %d, error message: %s",
 			resp.StatusCode, larkResp.Code, larkResp.Msg)
+		switch larkResp.Code {
+		case larkCodeRateLimited:
+			return complikerrors.Wrap(complikerrors.RateLimited, err)
+		case larkCodeBadSignature:
+			return complikerrors.Wrap(complikerrors.AuthFailure, err)
+		}
+		return complikerrors.FromResponse(resp, err)
 	}
 	return nil
 }
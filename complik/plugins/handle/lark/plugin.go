@@ -22,20 +22,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"net/http"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
 	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
 	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
 	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/ownership"
 	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
 	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/database"
 	"github.com/bearslyricattack/CompliK/complik/plugins/handle/lark/whitelist"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	gormLogger "gorm.io/gorm/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -55,6 +58,10 @@ type LarkPlugin struct {
 	log        logger.Logger
 	notifier   *Notifier
 	larkConfig LarkConfig
+	server     *http.Server
+	apiServer  *http.Server
+	// ownerServer serves the ownership API
+	ownerServer *http.Server
 }
 
 func (p *LarkPlugin) Name() string {
@@ -69,14 +76,34 @@ type LarkConfig struct {
 	Region           string `json:"region"`
 	Webhook          string `json:"webhook"`
 	EnabledWhitelist *bool  `json:"enabled_whitelist"`
-	Host             string `json:"host"`
-	Port             string `json:"port"`
-	Username         string `json:"username"`
-	Password         string `json:"password"`
-	DatabaseName     string `json:"databaseName"`
-	TableName        string `json:"tableName"`
-	Charset          string `json:"charset"`
-	HostTimeoutHour  int    `json:"host_timeout_hour"`
+	// Driver is "mysql" (default) or "sqlite" for the embedded single-binary mode
+	Driver          string `json:"driver"`
+	SQLitePath      string `json:"sqlitePath"`
+	Host            string `json:"host"`
+	Port            string `json:"port"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	DatabaseName    string `json:"databaseName"`
+	TableName       string `json:"tableName"`
+	Charset         string `json:"charset"`
+	HostTimeoutHour int    `json:"host_timeout_hour"`
+	CallbackAddr    string `json:"callbackAddr"`
+	CallbackPath    string `json:"callbackPath"`
+	CallbackToken   string `json:"callbackToken"`
+	// WhitelistAPIAddr enables the whitelist management API when the
+	// whitelist is enabled, WhitelistAPIToken protects it
+	WhitelistAPIAddr  string `json:"whitelistApiAddr"`
+	WhitelistAPIToken string `json:"whitelistApiToken"`
+
+	Routing *routing.Config `json:"routing"`
+	// Ownership sends the alerts of namespaces owned by a team to the team,
+	// the routes above remain the platform channel
+	Ownership *ownership.Config `json:"ownership"`
+	// Email is the SMTP server of teams reached by email
+	Email *EmailConfig `json:"email"`
+	// DedupMinute suppresses the notifications of an event delivered again
+	// within that many minutes, as told by its idempotency key
+	DedupMinute int `json:"dedupMinute"`
 }
 
 func (p *LarkPlugin) getDefaultConfig() LarkConfig {
@@ -84,9 +111,12 @@ func (p *LarkPlugin) getDefaultConfig() LarkConfig {
 	return LarkConfig{
 		Region:           "UNKNOWN",
 		EnabledWhitelist: &b,
+		Driver:           database.DriverMySQL,
 		DatabaseName:     "complik",
 		TableName:        "whitelist",
 		Charset:          "utf8mb4",
+		CallbackPath:     "/lark/callback",
+		DedupMinute:      10,
 	}
 }
 
@@ -103,36 +133,46 @@ func (p *LarkPlugin) loadConfig(setting string) error {
 		})
 		return err
 	}
-	if configFromJSON.Webhook == "" {
+	if configFromJSON.Webhook == "" &&
+		(configFromJSON.Routing == nil || configFromJSON.Routing.DefaultWebhook == "") {
 		return errors.New("webhook configuration cannot be empty")
 	}
 	if configFromJSON.EnabledWhitelist != nil && *configFromJSON.EnabledWhitelist {
 		p.larkConfig.EnabledWhitelist = configFromJSON.EnabledWhitelist
-		if configFromJSON.Host == "" {
-			return errors.New("host configuration cannot be empty")
-		}
-		if configFromJSON.Port == "" {
-			return errors.New("port configuration cannot be empty")
+		if configFromJSON.Driver != "" {
+			p.larkConfig.Driver = configFromJSON.Driver
 		}
-		if configFromJSON.Username == "" {
-			return errors.New("username configuration cannot be empty")
-		}
-		if configFromJSON.Password == "" {
-			return errors.New("password configuration cannot be empty")
+		p.larkConfig.SQLitePath = configFromJSON.SQLitePath
+		if err := (database.Options{
+			Driver:   p.larkConfig.Driver,
+			Host:     configFromJSON.Host,
+			Port:     configFromJSON.Port,
+			Username: configFromJSON.Username,
+			Password: configFromJSON.Password,
+		}).Validate(); err != nil {
+			return err
 		}
 		p.larkConfig.Host = configFromJSON.Host
 		p.larkConfig.Port = configFromJSON.Port
 		p.larkConfig.Username = configFromJSON.Username
-		// Support retrieving password from environment variable or encrypted value
-		if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
-			p.larkConfig.Password = pwd
-		} else {
-			p.larkConfig.Password = configFromJSON.Password
+		// Support retrieving password from environment variable or encrypted value.
+		// SQLite mode runs without a password.
+		if configFromJSON.Password != "" {
+			if pwd, err := config.GetSecureValue(configFromJSON.Password); err == nil {
+				p.larkConfig.Password = pwd
+			} else if config.IsSecretReference(configFromJSON.Password) {
+				return fmt.Errorf("failed to resolve password: %w", err)
+			} else {
+				p.larkConfig.Password = configFromJSON.Password
+			}
 		}
 	}
 	if configFromJSON.HostTimeoutHour > 0 {
 		p.larkConfig.HostTimeoutHour = configFromJSON.HostTimeoutHour
 	}
+	if configFromJSON.DedupMinute > 0 {
+		p.larkConfig.DedupMinute = configFromJSON.DedupMinute
+	}
 	if configFromJSON.DatabaseName != "" {
 		p.larkConfig.DatabaseName = configFromJSON.DatabaseName
 	}
@@ -142,60 +182,120 @@ func (p *LarkPlugin) loadConfig(setting string) error {
 	if configFromJSON.Charset != "" {
 		p.larkConfig.Charset = configFromJSON.Charset
 	}
-	p.larkConfig.Webhook = configFromJSON.Webhook
+	// Webhook URLs embed credentials and may also come from a secret store
+	if webhook, err := config.GetSecureValue(configFromJSON.Webhook); err == nil {
+		p.larkConfig.Webhook = webhook
+	} else if config.IsSecretReference(configFromJSON.Webhook) {
+		return fmt.Errorf("failed to resolve webhook: %w", err)
+	} else {
+		p.larkConfig.Webhook = configFromJSON.Webhook
+	}
 	if configFromJSON.Region != "" {
 		p.larkConfig.Region = configFromJSON.Region
 	}
+	p.larkConfig.Routing = configFromJSON.Routing
+	p.larkConfig.CallbackAddr = configFromJSON.CallbackAddr
+	if configFromJSON.CallbackPath != "" {
+		p.larkConfig.CallbackPath = configFromJSON.CallbackPath
+	}
+	if configFromJSON.CallbackToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.CallbackToken); err == nil {
+			p.larkConfig.CallbackToken = token
+		} else if config.IsSecretReference(configFromJSON.CallbackToken) {
+			return fmt.Errorf("failed to resolve callback token: %w", err)
+		} else {
+			p.larkConfig.CallbackToken = configFromJSON.CallbackToken
+		}
+	}
+	// Card callbacks add whitelist entries, they are never served unverified
+	if p.larkConfig.CallbackAddr != "" && p.larkConfig.CallbackToken == "" {
+		return errors.New("callbackToken configuration cannot be empty when callbackAddr is set")
+	}
+	p.larkConfig.Ownership = configFromJSON.Ownership
+	if p.larkConfig.Ownership != nil {
+		if err := resolveSecret("ownership API token", &p.larkConfig.Ownership.APIToken); err != nil {
+			return err
+		}
+		// Invalidating owners reroutes alerts, the API is never served
+		// unauthenticated
+		if p.larkConfig.Ownership.APIAddr != "" && p.larkConfig.Ownership.APIToken == "" {
+			return errors.New("ownership apiToken configuration cannot be empty when apiAddr is set")
+		}
+	}
+	p.larkConfig.Email = configFromJSON.Email
+	if p.larkConfig.Email != nil {
+		if p.larkConfig.Email.Addr == "" || p.larkConfig.Email.From == "" {
+			return errors.New("email addr and from configuration cannot be empty")
+		}
+		if err := resolveSecret("email password", &p.larkConfig.Email.Password); err != nil {
+			return err
+		}
+	}
+	p.larkConfig.WhitelistAPIAddr = configFromJSON.WhitelistAPIAddr
+	if configFromJSON.WhitelistAPIToken != "" {
+		if token, err := config.GetSecureValue(configFromJSON.WhitelistAPIToken); err == nil {
+			p.larkConfig.WhitelistAPIToken = token
+		} else if config.IsSecretReference(configFromJSON.WhitelistAPIToken) {
+			return fmt.Errorf("failed to resolve whitelist API token: %w", err)
+		} else {
+			p.larkConfig.WhitelistAPIToken = configFromJSON.WhitelistAPIToken
+		}
+	}
+	// Whitelisted namespaces and hosts escape detection, the API is never
+	// served unauthenticated
+	if p.larkConfig.WhitelistAPIAddr != "" && p.larkConfig.WhitelistAPIToken == "" {
+		return errors.New("whitelistApiToken configuration cannot be empty when whitelistApiAddr is set")
+	}
 	return nil
 }
 
-func (p *LarkPlugin) initDB() (db *gorm.DB, err error) {
-	serverDSN := p.buildDSN(false)
-	dbConfig := &gorm.Config{
-		Logger: gormLogger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags),
-			gormLogger.Config{
-				SlowThreshold: 3 * time.Second,  // Slow query threshold set to 3 seconds
-				LogLevel:      gormLogger.Error, // Show only error logs
-				Colorful:      false,            // Disable color output
-			},
-		),
-	}
-	db, err = gorm.Open(mysql.Open(serverDSN), dbConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MySQL server: %w", err)
-	}
-	createDBSQL := fmt.Sprintf(
-		"CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET %s COLLATE %s_unicode_ci",
-		p.larkConfig.DatabaseName,
-		p.larkConfig.Charset,
-		p.larkConfig.Charset,
-	)
-	err = db.Exec(createDBSQL).Error
-	if err != nil {
-		return nil, fmt.Errorf("failed to create database: %w", err)
+// resolveSecret replaces a secret reference in value with the secret, plain
+// values are kept
+func resolveSecret(name string, value *string) error {
+	if *value == "" {
+		return nil
 	}
-	dbDSN := p.buildDSN(true)
-	db, err = gorm.Open(mysql.Open(dbDSN), dbConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	if secret, err := config.GetSecureValue(*value); err == nil {
+		*value = secret
+	} else if config.IsSecretReference(*value) {
+		return fmt.Errorf("failed to resolve %s: %w", name, err)
 	}
-	return db, nil
+	return nil
 }
 
-func (p *LarkPlugin) buildDSN(includeDB bool) string {
-	dbPart := "/"
-	if includeDB {
-		dbPart = "/" + p.larkConfig.DatabaseName
-	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)%s?charset=%s&parseTime=True&loc=Local",
-		p.larkConfig.Username,
-		p.larkConfig.Password,
-		p.larkConfig.Host,
-		p.larkConfig.Port,
-		dbPart,
-		p.larkConfig.Charset,
-	)
+func (p *LarkPlugin) initDB() (*gorm.DB, error) {
+	return database.Open(database.Options{
+		Driver:       p.larkConfig.Driver,
+		Host:         p.larkConfig.Host,
+		Port:         p.larkConfig.Port,
+		Username:     p.larkConfig.Username,
+		Password:     p.larkConfig.Password,
+		DatabaseName: p.larkConfig.DatabaseName,
+		Charset:      p.larkConfig.Charset,
+		SQLitePath:   p.larkConfig.SQLitePath,
+	})
+}
+
+// OpenWhitelist connects to the whitelist database configured in the plugin
+// settings so entries can be managed without starting the plugin. It also
+// returns the configured region, which whitelist lookups are scoped to.
+func OpenWhitelist(settings string) (*whitelist.WhitelistService, string, error) {
+	p := &LarkPlugin{log: logger.GetLogger().WithField("plugin", pluginName)}
+	if err := p.loadConfig(settings); err != nil {
+		return nil, "", err
+	}
+	if !*p.larkConfig.EnabledWhitelist {
+		return nil, "", errors.New("whitelist is not enabled in the lark plugin settings")
+	}
+	db, err := p.initDB()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if err := db.AutoMigrate(&whitelist.Whitelist{}); err != nil {
+		return nil, "", fmt.Errorf("database migration failed: %w", err)
+	}
+	timeout := time.Duration(p.larkConfig.HostTimeoutHour) * time.Hour
+	return whitelist.NewWhitelistService(db, timeout), p.larkConfig.Region, nil
 }
 
 func (p *LarkPlugin) Start(
@@ -207,12 +307,36 @@ func (p *LarkPlugin) Start(
 	if err != nil {
 		return err
 	}
+	var router *routing.Router
+	if p.larkConfig.Routing != nil {
+		if router, err = routing.NewRouter(p.larkConfig.Routing, p.larkConfig.Webhook); err != nil {
+			return fmt.Errorf("invalid routing configuration: %w", err)
+		}
+		p.log.Info("Notification routing enabled", logger.Fields{
+			"routes": len(p.larkConfig.Routing.Routes),
+		})
+	}
+	var owners *ownership.Resolver
+	if p.larkConfig.Ownership != nil {
+		if owners, err = ownership.NewResolver(*p.larkConfig.Ownership, namespaceMeta); err != nil {
+			return fmt.Errorf("invalid ownership configuration: %w", err)
+		}
+		// Owner routing needs the router for the platform channel fallback
+		if router == nil {
+			if router, err = routing.NewRouter(nil, p.larkConfig.Webhook); err != nil {
+				return err
+			}
+		}
+		p.log.Info("Ownership routing enabled", logger.Fields{
+			"teams": len(p.larkConfig.Ownership.Teams),
+		})
+	}
+	var db *gorm.DB
 	if *p.larkConfig.EnabledWhitelist {
-		var db *gorm.DB
 		if db, err = p.initDB(); err != nil {
 			return fmt.Errorf("failed to initialize database: %w", err)
 		}
-		if err := db.AutoMigrate(&whitelist.Whitelist{}); err != nil {
+		if err := db.AutoMigrate(&whitelist.Whitelist{}, &AlertAction{}); err != nil {
 			return fmt.Errorf("database migration failed: %w", err)
 		}
 		p.notifier = NewNotifier(
@@ -220,6 +344,7 @@ func (p *LarkPlugin) Start(
 			db,
 			time.Duration(p.larkConfig.HostTimeoutHour)*time.Hour,
 			p.larkConfig.Region,
+			router,
 		)
 		var count int64
 		db.Model(&whitelist.Whitelist{}).Count(&count)
@@ -243,9 +368,36 @@ func (p *LarkPlugin) Start(
 			}
 		}
 	} else {
-		p.notifier = NewNotifier(p.larkConfig.Webhook, nil, 0, "")
+		p.notifier = NewNotifier(p.larkConfig.Webhook, nil, 0, "", router)
+	}
+	p.notifier.Owners = owners
+	if p.larkConfig.Email != nil {
+		p.notifier.Mailer = NewMailer(*p.larkConfig.Email)
+	}
+	if owners != nil && p.larkConfig.Ownership.APIAddr != "" {
+		p.startOwnershipAPI(owners)
+	}
+	if p.larkConfig.CallbackAddr != "" {
+		p.startCallbackServer(db)
+	}
+	if p.larkConfig.WhitelistAPIAddr != "" && db != nil {
+		p.startWhitelistAPI(db)
 	}
 	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	incidents := eventBus.Subscribe(constants.CorrelationTopic)
+	appeals := eventBus.Subscribe(constants.AppealTopic)
+	dedup := eventbus.NewDedup(time.Duration(p.larkConfig.DedupMinute) * time.Minute)
+	// duplicate claims the key of event, reporting whether it was already
+	// notified within the dedup window
+	duplicate := func(event eventbus.Event) bool {
+		if dedup.Claim(event.Key, time.Now()) {
+			return false
+		}
+		p.log.Debug("Skipping notification already sent for the event", logger.Fields{
+			"key": event.Key,
+		})
+		return true
+	}
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -254,8 +406,12 @@ func (p *LarkPlugin) Start(
 				})
 			}
 		}()
+		flushTicker := time.NewTicker(time.Minute)
+		defer flushTicker.Stop()
 		for {
 			select {
+			case now := <-flushTicker.C:
+				p.notifier.FlushDeferred(now)
 			case event, ok := <-subscribe:
 				if !ok {
 					p.log.Info("Event subscription channel closed")
@@ -269,11 +425,70 @@ func (p *LarkPlugin) Start(
 					})
 					continue
 				}
+				if duplicate(event) {
+					continue
+				}
 				result.Region = p.larkConfig.Region
 				err := p.notifier.SendAnalysisNotification(result)
 				if err != nil {
+					dedup.Release(event.Key)
 					p.log.Error("Failed to send notification", logger.Fields{
 						"error": err.Error(),
+						"class": complikerrors.Record(p.Name(), err),
+					})
+				}
+			case event, ok := <-incidents:
+				if !ok {
+					p.log.Info("Incident subscription channel closed")
+					return
+				}
+				incident, ok := event.Payload.(*models.Incident)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.Incident",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if duplicate(event) {
+					continue
+				}
+				if incident.Region == "" {
+					incident.Region = p.larkConfig.Region
+				}
+				if err := p.notifier.SendIncidentNotification(incident); err != nil {
+					dedup.Release(event.Key)
+					p.log.Error("Failed to send incident notification", logger.Fields{
+						"incident": incident.ID,
+						"error":    err.Error(),
+						"class":    complikerrors.Record(p.Name(), err),
+					})
+				}
+			case event, ok := <-appeals:
+				if !ok {
+					p.log.Info("Appeal subscription channel closed")
+					return
+				}
+				appeal, ok := event.Payload.(*models.AppealEvent)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.AppealEvent",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if duplicate(event) {
+					continue
+				}
+				if appeal.Region == "" {
+					appeal.Region = p.larkConfig.Region
+				}
+				if err := p.notifier.SendAppealNotification(appeal); err != nil {
+					dedup.Release(event.Key)
+					p.log.Error("Failed to send appeal notification", logger.Fields{
+						"appeal": appeal.ID,
+						"error":  err.Error(),
+						"class":  complikerrors.Record(p.Name(), err),
 					})
 				}
 			case <-ctx.Done():
@@ -285,6 +500,95 @@ func (p *LarkPlugin) Start(
 	return nil
 }
 
+// startCallbackServer serves the card callback endpoint and enables the
+// action buttons on alert cards
+func (p *LarkPlugin) startCallbackServer(db *gorm.DB) {
+	mux := http.NewServeMux()
+	mux.Handle(p.larkConfig.CallbackPath,
+		NewCallbackHandler(p.log, p.larkConfig.CallbackToken, p.notifier, db))
+	p.server = &http.Server{
+		Addr:              p.larkConfig.CallbackAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	p.notifier.ActionsEnabled = true
+	go func() {
+		p.log.Info("Lark callback server started", logger.Fields{
+			"addr": p.larkConfig.CallbackAddr,
+			"path": p.larkConfig.CallbackPath,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Lark callback server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// startWhitelistAPI serves the whitelist management API
+func (p *LarkPlugin) startWhitelistAPI(db *gorm.DB) {
+	service := whitelist.NewWhitelistService(db, time.Duration(p.larkConfig.HostTimeoutHour)*time.Hour)
+	p.apiServer = &http.Server{
+		Addr: p.larkConfig.WhitelistAPIAddr,
+		Handler: whitelist.NewAPI(p.log, p.larkConfig.WhitelistAPIToken, service,
+			p.larkConfig.Region),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Whitelist API started", logger.Fields{"addr": p.larkConfig.WhitelistAPIAddr})
+		if err := p.apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Whitelist API stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// startOwnershipAPI serves the API listing the resolved namespace owners
+func (p *LarkPlugin) startOwnershipAPI(owners *ownership.Resolver) {
+	addr := p.larkConfig.Ownership.APIAddr
+	p.ownerServer = &http.Server{
+		Addr:              addr,
+		Handler:           ownership.NewAPI(p.larkConfig.Ownership.APIToken, owners),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Ownership API started", logger.Fields{"addr": addr})
+		if err := p.ownerServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Ownership API stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// namespaceMeta reads the labels and annotations of a namespace from the
+// cluster
+func namespaceMeta(ctx context.Context, namespace string) (map[string]string, map[string]string, error) {
+	if k8s.ClientSet == nil {
+		return nil, nil, errors.New("kubernetes client is not initialized")
+	}
+	ns, err := k8s.ClientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ns.Labels, ns.Annotations, nil
+}
+
+// HealthCheck reports whether the whitelist database is reachable
+func (p *LarkPlugin) HealthCheck(ctx context.Context) error {
+	if !*p.larkConfig.EnabledWhitelist {
+		return nil
+	}
+	return database.Ping(ctx, p.notifier.db)
+}
+
 func (p *LarkPlugin) Stop(ctx context.Context) error {
-	return nil
+	var errs []error
+	for _, server := range []*http.Server{p.server, p.apiServer, p.ownerServer} {
+		if server != nil {
+			errs = append(errs, server.Shutdown(ctx))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,168 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelist
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	applog "github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// API serves the whitelist management endpoints:
+//
+//	GET    /api/v1/whitelists?type=namespace|host&search=
+//	POST   /api/v1/whitelists
+//	GET    /api/v1/whitelists/{id}
+//	DELETE /api/v1/whitelists/{id}
+//
+// New entries are created in the region of the plugin, like those of the
+// whitelist command.
+type API struct {
+	log     applog.Logger
+	service *WhitelistService
+	region  string
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewAPI(log applog.Logger, token string, service *WhitelistService, region string) *API {
+	api := &API{log: log, service: service, region: region, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/whitelists", api.list)
+	api.mux.HandleFunc("POST /api/v1/whitelists", api.create)
+	api.mux.HandleFunc("GET /api/v1/whitelists/{id}", api.get)
+	api.mux.HandleFunc("DELETE /api/v1/whitelists/{id}", api.remove)
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var (
+		entries []Whitelist
+		err     error
+	)
+	switch entryType := WhitelistType(query.Get("type")); {
+	case query.Get("search") != "":
+		entries, err = a.service.SearchWhitelists(query.Get("search"))
+	case entryType == WhitelistTypeNamespace || entryType == WhitelistTypeHost:
+		entries, err = a.service.GetWhitelistsByType(entryType)
+	case entryType != "":
+		httpapi.WriteError(w, http.StatusBadRequest, "type must be namespace or host")
+		return
+	default:
+		entries, err = a.service.GetAllWhitelists()
+	}
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	if entries == nil {
+		entries = []Whitelist{}
+	}
+	httpapi.WriteJSON(w, http.StatusOK, entries)
+}
+
+func (a *API) create(w http.ResponseWriter, r *http.Request) {
+	var entry Whitelist
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&entry); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid whitelist payload")
+		return
+	}
+	switch {
+	case entry.Namespace != "" && entry.Hostname != "":
+		httpapi.WriteError(w, http.StatusBadRequest, "namespace and hostname are mutually exclusive")
+		return
+	case entry.Namespace != "":
+		entry.Type = WhitelistTypeNamespace
+	case entry.Hostname != "":
+		entry.Type = WhitelistTypeHost
+	default:
+		httpapi.WriteError(w, http.StatusBadRequest, "either namespace or hostname is required")
+		return
+	}
+	if entry.Name == "" {
+		entry.Name = entry.Namespace + entry.Hostname
+	}
+	// The server assigns the identity and the region of new entries
+	entry.ID, entry.Region = 0, a.region
+	if err := a.service.Create(&entry); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Whitelist entry added", applog.Fields{
+		"id":     entry.ID,
+		"type":   entry.Type,
+		"target": entry.Namespace + entry.Hostname,
+	})
+	httpapi.WriteJSON(w, http.StatusCreated, entry)
+}
+
+func (a *API) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+	entry, err := a.service.GetWhitelistByID(id)
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, entry)
+}
+
+func (a *API) remove(w http.ResponseWriter, r *http.Request) {
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := a.service.GetWhitelistByID(id); err != nil {
+		a.fail(w, err)
+		return
+	}
+	if err := a.service.RemoveWhitelistByID(id); err != nil {
+		a.fail(w, err)
+		return
+	}
+	a.log.Info("Whitelist entry removed", applog.Fields{"id": id})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fail maps database errors to HTTP status codes
+func (a *API) fail(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		httpapi.WriteError(w, http.StatusNotFound, "whitelist entry not found")
+		return
+	}
+	a.log.Error("Whitelist API request failed", applog.Fields{"error": err.Error()})
+	httpapi.WriteError(w, http.StatusInternalServerError, "internal error")
+}
+
+func entryID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid whitelist id")
+		return 0, false
+	}
+	return uint(id), true
+}
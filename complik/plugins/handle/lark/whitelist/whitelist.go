@@ -153,6 +153,11 @@ func (s *WhitelistService) AddWhitelist(
 	return s.db.Create(whitelist).Error
 }
 
+// Create stores a fully populated whitelist entry, including its region
+func (s *WhitelistService) Create(whitelist *Whitelist) error {
+	return s.db.Create(whitelist).Error
+}
+
 func (s *WhitelistService) RemoveWhitelistByID(id uint) error {
 	return s.db.Delete(&Whitelist{}, id).Error
 }
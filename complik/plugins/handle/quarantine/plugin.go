@@ -0,0 +1,310 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quarantine implements a handler plugin that cuts the egress of pods
+// caught mining or reported by procscan with a NetworkPolicy, or a
+// CiliumNetworkPolicy, selecting the labels of the offending workload rather
+// than the whole namespace. Policies are removed after a configurable period
+// without new detections, and as soon as the procscan violations they were
+// created for are resolved.
+package quarantine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/correlation"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.HandleQuarantine
+	pluginType = constants.HandleQuarantinePluginType
+)
+
+// statusExcepted marks procscan records allowed by an exception
+const statusExcepted = "excepted"
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &QuarantinePlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type QuarantinePlugin struct {
+	log              logger.Logger
+	quarantineConfig QuarantineConfig
+	quarantiner      *Quarantiner
+}
+
+func (p *QuarantinePlugin) Name() string {
+	return pluginName
+}
+
+func (p *QuarantinePlugin) Type() string {
+	return pluginType
+}
+
+type QuarantineConfig struct {
+	// PolicyKind is NetworkPolicy or CiliumNetworkPolicy
+	PolicyKind string `json:"policyKind"`
+	// TTLMinute is the time a policy is kept after the last detection
+	TTLMinute   int    `json:"ttlMinute"`
+	MinSeverity string `json:"minSeverity"`
+	// ProcscanURL is the violations endpoint of the procscan aggregator,
+	// procscan violations are not quarantined when empty
+	ProcscanURL            string `json:"procscanURL"`
+	ProcscanIntervalSecond int    `json:"procscanIntervalSecond"`
+	SweepIntervalSecond    int    `json:"sweepIntervalSecond"`
+}
+
+func (p *QuarantinePlugin) getDefaultConfig() QuarantineConfig {
+	return QuarantineConfig{
+		PolicyKind:             KindNetworkPolicy,
+		TTLMinute:              60,
+		MinSeverity:            models.SeverityHigh,
+		ProcscanIntervalSecond: 60,
+		SweepIntervalSecond:    60,
+	}
+}
+
+func (p *QuarantinePlugin) loadConfig(setting string) error {
+	p.quarantineConfig = p.getDefaultConfig()
+	if setting == "" {
+		p.log.Info("Using default quarantine configuration")
+		return nil
+	}
+	var configFromJSON QuarantineConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	c := &p.quarantineConfig
+	if configFromJSON.PolicyKind != "" {
+		if _, ok := resources[configFromJSON.PolicyKind]; !ok {
+			return fmt.Errorf("unknown policyKind %q, expected %s or %s", configFromJSON.PolicyKind, KindNetworkPolicy, KindCilium)
+		}
+		c.PolicyKind = configFromJSON.PolicyKind
+	}
+	if configFromJSON.TTLMinute > 0 {
+		c.TTLMinute = configFromJSON.TTLMinute
+	}
+	if configFromJSON.MinSeverity != "" {
+		if models.SeverityRank(configFromJSON.MinSeverity) == 0 {
+			return fmt.Errorf("unknown severity %q", configFromJSON.MinSeverity)
+		}
+		c.MinSeverity = configFromJSON.MinSeverity
+	}
+	c.ProcscanURL = configFromJSON.ProcscanURL
+	if configFromJSON.ProcscanIntervalSecond > 0 {
+		c.ProcscanIntervalSecond = configFromJSON.ProcscanIntervalSecond
+	}
+	if configFromJSON.SweepIntervalSecond > 0 {
+		c.SweepIntervalSecond = configFromJSON.SweepIntervalSecond
+	}
+	return nil
+}
+
+func (p *QuarantinePlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	if k8s.ClientSet == nil || k8s.DynamicClient == nil {
+		return errors.New("kubernetes client is not initialized")
+	}
+	quarantiner, err := NewQuarantiner(
+		k8s.ClientSet,
+		k8s.DynamicClient,
+		p.quarantineConfig.PolicyKind,
+		time.Duration(p.quarantineConfig.TTLMinute)*time.Minute,
+	)
+	if err != nil {
+		return err
+	}
+	p.quarantiner = quarantiner
+	p.log.Info("Quarantine handler started", logger.Fields{
+		"policy_kind":  p.quarantineConfig.PolicyKind,
+		"ttl_minutes":  p.quarantineConfig.TTLMinute,
+		"min_severity": p.quarantineConfig.MinSeverity,
+		"procscan_url": p.quarantineConfig.ProcscanURL,
+	})
+
+	var (
+		procscan     *correlation.ProcscanClient
+		procscanPoll <-chan time.Time
+	)
+	if p.quarantineConfig.ProcscanURL != "" {
+		interval := time.Duration(p.quarantineConfig.ProcscanIntervalSecond) * time.Second
+		procscan = correlation.NewProcscanClient(p.quarantineConfig.ProcscanURL, min(interval, 30*time.Second))
+		ticker := time.NewTicker(interval)
+		procscanPoll = ticker.C
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+
+	mining := eventBus.Subscribe(constants.MiningTopic)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		sweepTicker := time.NewTicker(time.Duration(p.quarantineConfig.SweepIntervalSecond) * time.Second)
+		defer sweepTicker.Stop()
+		for {
+			select {
+			case event, ok := <-mining:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				info, ok := event.Payload.(*models.MiningInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.MiningInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				budgetCtx, cancelBudget := eventBus.Policy().Context(ctx, event.Deadline, eventbus.PhaseHandle)
+				taskCtx, cancel := context.WithTimeout(budgetCtx, 30*time.Second)
+				p.handleMining(taskCtx, info)
+				cancel()
+				cancelBudget()
+			case <-procscanPoll:
+				taskCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+				p.pollProcscan(taskCtx, procscan)
+				cancel()
+			case <-sweepTicker.C:
+				taskCtx, cancel := context.WithTimeout(ctx, time.Minute)
+				p.sweep(taskCtx, nil)
+				cancel()
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *QuarantinePlugin) Stop(ctx context.Context) error {
+	return nil
+}
+
+// handleMining quarantines the pod of a mining detection of at least the
+// configured severity
+func (p *QuarantinePlugin) handleMining(ctx context.Context, info *models.MiningInfo) {
+	if info.Namespace == "" || info.PodName == "" {
+		return
+	}
+	if models.SeverityRank(info.Severity) < models.SeverityRank(p.quarantineConfig.MinSeverity) {
+		return
+	}
+	reason := fmt.Sprintf("mining process `%s`", info.Command)
+	p.quarantine(ctx, info.Namespace, info.PodName, SourceMining, reason)
+}
+
+// pollProcscan quarantines the pods of the active procscan violations and
+// removes the quarantines of the resolved ones. Procscan violations are high
+// severity, they are skipped when a higher minimum is configured.
+func (p *QuarantinePlugin) pollProcscan(ctx context.Context, client *correlation.ProcscanClient) {
+	violations, err := client.FetchViolations(ctx)
+	if err != nil {
+		p.log.Error("Failed to fetch procscan violations", logger.Fields{
+			"error": err.Error(),
+			"class": complikerrors.Record(p.Name(), err),
+		})
+		return
+	}
+	quarantine := models.SeverityRank(models.SeverityHigh) >= models.SeverityRank(p.quarantineConfig.MinSeverity)
+	active := make(map[string]bool, len(violations))
+	for _, violation := range violations {
+		if violation == nil || violation.Namespace == "" || violation.Pod == "" || violation.Status == statusExcepted {
+			continue
+		}
+		key := violation.Namespace + "/" + violation.Pod
+		if active[key] {
+			continue
+		}
+		active[key] = true
+		if quarantine {
+			reason := fmt.Sprintf("process `%s` matched rule `%s`", violation.Process, violation.Regex)
+			p.quarantine(ctx, violation.Namespace, violation.Pod, SourceProcscan, reason)
+		}
+	}
+	p.sweep(ctx, active)
+}
+
+func (p *QuarantinePlugin) quarantine(ctx context.Context, namespace, pod, source, reason string) {
+	quarantine, err := p.quarantiner.Quarantine(ctx, namespace, pod, source, reason)
+	if err != nil {
+		p.log.Error("Failed to quarantine pod", logger.Fields{
+			"namespace": namespace,
+			"pod":       pod,
+			"source":    source,
+			"error":     err.Error(),
+			"class":     complikerrors.Record(p.Name(), err),
+		})
+		return
+	}
+	p.log.Info("Pod egress quarantined", logger.Fields{
+		"namespace":  namespace,
+		"pod":        pod,
+		"source":     source,
+		"policy":     quarantine.Name,
+		"selector":   quarantine.Selector,
+		"expires_at": quarantine.ExpiresAt,
+	})
+}
+
+// sweep removes the expired and resolved quarantines, see Quarantiner.Sweep
+func (p *QuarantinePlugin) sweep(ctx context.Context, active map[string]bool) {
+	removed, err := p.quarantiner.Sweep(ctx, active)
+	for _, quarantine := range removed {
+		p.log.Info("Pod egress quarantine removed", logger.Fields{
+			"namespace": quarantine.Namespace,
+			"policy":    quarantine.Name,
+			"pods":      quarantine.Pods,
+		})
+	}
+	if err != nil {
+		p.log.Error("Failed to remove quarantines", logger.Fields{
+			"error": err.Error(),
+			"class": complikerrors.Record(p.Name(), err),
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quarantine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestQuarantine(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quarantine Handler Suite")
+}
+
+func pod(namespace, name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+}
+
+var _ = Describe("Quarantiner", func() {
+	ctx := context.Background()
+	var (
+		client   *fake.Clientset
+		policies *dynamicfake.FakeDynamicClient
+		now      time.Time
+	)
+
+	newQuarantiner := func(kind string) *Quarantiner {
+		q, err := NewQuarantiner(client, policies, kind, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		q.now = func() time.Time { return now }
+		return q
+	}
+
+	get := func(kind, namespace, name string) *unstructured.Unstructured {
+		obj, err := policies.Resource(resources[kind]).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return obj
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		client = fake.NewClientset(
+			pod("ns-alice", "miner-7d9f-abcde", map[string]string{"app": "miner", "pod-template-hash": "7d9f"}),
+			pod("ns-alice", "miner-7d9f-fghij", map[string]string{"app": "miner", "pod-template-hash": "7d9f"}),
+			pod("ns-alice", "bare", nil),
+		)
+		policies = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			resources[KindNetworkPolicy]: "NetworkPolicyList",
+			resources[KindCilium]:        "CiliumNetworkPolicyList",
+		})
+	})
+
+	It("should deny the egress of the workload labels of the pod", func() {
+		q := newQuarantiner(KindNetworkPolicy)
+		quarantine, err := q.Quarantine(ctx, "ns-alice", "miner-7d9f-abcde", SourceMining, "mining process `xmrig`")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quarantine.Selector).To(Equal(map[string]string{"app": "miner"}))
+		Expect(quarantine.ExpiresAt).To(Equal(now.Add(time.Hour)))
+
+		policy := get(KindNetworkPolicy, "ns-alice", quarantine.Name)
+		Expect(policy.GetLabels()).To(HaveKeyWithValue(ManagedLabel, "true"))
+		Expect(policy.GetAnnotations()).To(HaveKeyWithValue(ExpiresAnnotation, "2025-06-01T13:00:00Z"))
+		selector, _, _ := unstructured.NestedStringMap(policy.Object, "spec", "podSelector", "matchLabels")
+		Expect(selector).To(Equal(map[string]string{"app": "miner"}))
+		types, _, _ := unstructured.NestedStringSlice(policy.Object, "spec", "policyTypes")
+		Expect(types).To(Equal([]string{"Egress"}))
+		egress, found, _ := unstructured.NestedSlice(policy.Object, "spec", "egress")
+		Expect(found).To(BeTrue())
+		Expect(egress).To(BeEmpty())
+	})
+
+	It("should renew the policy of another pod of the same workload", func() {
+		q := newQuarantiner(KindNetworkPolicy)
+		first, err := q.Quarantine(ctx, "ns-alice", "miner-7d9f-abcde", SourceMining, "mining")
+		Expect(err).NotTo(HaveOccurred())
+		now = now.Add(30 * time.Minute)
+		second, err := q.Quarantine(ctx, "ns-alice", "miner-7d9f-fghij", SourceProcscan, "procscan")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Name).To(Equal(first.Name))
+		Expect(second.Pods).To(Equal([]string{"miner-7d9f-abcde", "miner-7d9f-fghij"}))
+		Expect(second.Sources).To(Equal([]string{SourceMining, SourceProcscan}))
+
+		quarantines, err := q.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quarantines).To(HaveLen(1))
+		Expect(quarantines[0].ExpiresAt).To(Equal(now.Add(time.Hour)))
+		Expect(quarantines[0].Reason).To(Equal("procscan"))
+	})
+
+	It("should label and select a pod without labels alone", func() {
+		q := newQuarantiner(KindNetworkPolicy)
+		quarantine, err := q.Quarantine(ctx, "ns-alice", "bare", SourceMining, "mining")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quarantine.Selector).To(HaveKey(IsolatedPodLabel))
+		labeled, err := client.CoreV1().Pods("ns-alice").Get(ctx, "bare", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labeled.Labels).To(HaveKeyWithValue(IsolatedPodLabel, quarantine.Selector[IsolatedPodLabel]))
+
+		Expect(q.Release(ctx, quarantine)).To(Succeed())
+		released, err := client.CoreV1().Pods("ns-alice").Get(ctx, "bare", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(released.Labels).NotTo(HaveKey(IsolatedPodLabel))
+	})
+
+	It("should create Cilium policies denying all egress", func() {
+		q := newQuarantiner(KindCilium)
+		quarantine, err := q.Quarantine(ctx, "ns-alice", "miner-7d9f-abcde", SourceMining, "mining")
+		Expect(err).NotTo(HaveOccurred())
+		policy := get(KindCilium, "ns-alice", quarantine.Name)
+		Expect(policy.GetAPIVersion()).To(Equal("cilium.io/v2"))
+		selector, _, _ := unstructured.NestedStringMap(policy.Object, "spec", "endpointSelector", "matchLabels")
+		Expect(selector).To(Equal(map[string]string{"app": "miner"}))
+		deny, _, _ := unstructured.NestedSlice(policy.Object, "spec", "egressDeny")
+		Expect(deny).To(Equal([]any{map[string]any{"toEntities": []any{"all"}}}))
+	})
+
+	It("should remove expired quarantines and resolved procscan ones", func() {
+		q := newQuarantiner(KindNetworkPolicy)
+		mined, err := q.Quarantine(ctx, "ns-alice", "bare", SourceMining, "mining")
+		Expect(err).NotTo(HaveOccurred())
+		scanned, err := q.Quarantine(ctx, "ns-alice", "miner-7d9f-abcde", SourceProcscan, "procscan")
+		Expect(err).NotTo(HaveOccurred())
+
+		removed, err := q.Sweep(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeEmpty())
+		removed, err = q.Sweep(ctx, map[string]bool{"ns-alice/miner-7d9f-abcde": true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeEmpty())
+
+		removed, err = q.Sweep(ctx, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(HaveLen(1))
+		Expect(removed[0].Name).To(Equal(scanned.Name))
+
+		now = now.Add(time.Hour + time.Second)
+		removed, err = q.Sweep(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(HaveLen(1))
+		Expect(removed[0].Name).To(Equal(mined.Name))
+		quarantines, err := q.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quarantines).To(BeEmpty())
+	})
+
+	It("should fail for pods that do not exist and unknown kinds", func() {
+		_, err := newQuarantiner(KindNetworkPolicy).Quarantine(ctx, "ns-alice", "gone", SourceMining, "mining")
+		Expect(err).To(MatchError(ContainSubstring("failed to get pod ns-alice/gone")))
+		_, err = NewQuarantiner(client, policies, "Firewall", time.Hour)
+		Expect(err).To(MatchError(ContainSubstring("unknown policy kind")))
+	})
+})
+
+var _ = Describe("Config", func() {
+	It("should default to NetworkPolicies kept for an hour", func() {
+		p := &QuarantinePlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig("")).To(Succeed())
+		Expect(p.quarantineConfig.PolicyKind).To(Equal(KindNetworkPolicy))
+		Expect(p.quarantineConfig.TTLMinute).To(Equal(60))
+	})
+
+	It("should reject unknown policy kinds and severities", func() {
+		p := &QuarantinePlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"policyKind": "Calico"}`)).To(MatchError(ContainSubstring("unknown policyKind")))
+		Expect(p.loadConfig(`{"minSeverity": "urgent"}`)).To(MatchError(ContainSubstring("unknown severity")))
+		Expect(p.loadConfig(`{"policyKind": "CiliumNetworkPolicy", "ttlMinute": 15}`)).To(Succeed())
+		Expect(p.quarantineConfig.TTLMinute).To(Equal(15))
+	})
+})
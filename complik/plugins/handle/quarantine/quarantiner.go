@@ -0,0 +1,386 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quarantine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Kinds of the quarantine policies
+const (
+	KindNetworkPolicy = "NetworkPolicy"
+	KindCilium        = "CiliumNetworkPolicy"
+)
+
+// Sources of a quarantine
+const (
+	SourceMining   = "mining"
+	SourceProcscan = "procscan"
+)
+
+// Label and annotations recording a quarantine on its policy
+const (
+	ManagedLabel      = "clawcloud.run/quarantine"
+	ExpiresAnnotation = "core.clawcloud.run/quarantine-expires"
+	PodsAnnotation    = "core.clawcloud.run/quarantine-pods"
+	SourcesAnnotation = "core.clawcloud.run/quarantine-sources"
+	ReasonAnnotation  = "core.clawcloud.run/quarantine-reason"
+	// IsolatedPodLabel selects a pod without labels of its own
+	IsolatedPodLabel = "clawcloud.run/quarantined"
+)
+
+const (
+	policyNamePrefix = "complik-quarantine-"
+	// maxReasonLength keeps the reason annotation readable
+	maxReasonLength = 256
+	hashLength      = 10
+)
+
+// volatileLabels change with every rollout of a workload, selecting them would
+// let the replacement pods escape the quarantine
+var volatileLabels = []string{
+	"pod-template-hash",
+	"controller-revision-hash",
+	"pod-template-generation",
+}
+
+var resources = map[string]schema.GroupVersionResource{
+	KindNetworkPolicy: {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	KindCilium:        {Group: "cilium.io", Version: "v2", Resource: "ciliumnetworkpolicies"},
+}
+
+// Quarantine is a policy blocking the egress of the pods matching Selector
+type Quarantine struct {
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Selector  map[string]string `json:"selector"`
+	// Pods are the offending pods the policy was created or renewed for
+	Pods      []string  `json:"pods"`
+	Sources   []string  `json:"sources"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Quarantiner creates, renews and removes the quarantine policies
+type Quarantiner struct {
+	client   kubernetes.Interface
+	dynamic  dynamic.Interface
+	kind     string
+	resource schema.GroupVersionResource
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewQuarantiner returns a Quarantiner for policies of kind that expire ttl
+// after the last detection of their pods
+func NewQuarantiner(
+	client kubernetes.Interface,
+	dyn dynamic.Interface,
+	kind string,
+	ttl time.Duration,
+) (*Quarantiner, error) {
+	resource, ok := resources[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy kind %q, expected %s or %s", kind, KindNetworkPolicy, KindCilium)
+	}
+	return &Quarantiner{
+		client:   client,
+		dynamic:  dyn,
+		kind:     kind,
+		resource: resource,
+		ttl:      ttl,
+		now:      time.Now,
+	}, nil
+}
+
+// Quarantine blocks the egress of pod and of the other pods of its workload.
+// The policy selects the labels of the pod without the ones changing between
+// rollouts; a pod without other labels is labeled and selected alone. A
+// policy of the same pods is renewed instead of created again.
+func (q *Quarantiner) Quarantine(ctx context.Context, namespace, pod, source, reason string) (Quarantine, error) {
+	target, err := q.client.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return Quarantine{}, fmt.Errorf("failed to get pod %s/%s: %w", namespace, pod, err)
+	}
+	selector := WorkloadSelector(target.Labels)
+	if len(selector) == 0 {
+		selector = map[string]string{IsolatedPodLabel: hash(namespace, pod)}
+		if err := q.patchPodLabel(ctx, namespace, pod, selector[IsolatedPodLabel]); err != nil {
+			return Quarantine{}, err
+		}
+	}
+	name := policyNamePrefix + hash(namespace, selectorString(selector))
+	expires := q.now().Add(q.ttl)
+	reason = truncate(reason, maxReasonLength)
+
+	var result Quarantine
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		policies := q.dynamic.Resource(q.resource).Namespace(namespace)
+		existing, err := policies.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			result = Quarantine{
+				Kind:      q.kind,
+				Namespace: namespace,
+				Name:      name,
+				Selector:  selector,
+				Pods:      []string{pod},
+				Sources:   []string{source},
+				ExpiresAt: expires,
+				Reason:    reason,
+			}
+			_, err = policies.Create(ctx, q.manifest(result), metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		result = q.record(existing)
+		result.Pods = appendUnique(result.Pods, pod)
+		result.Sources = appendUnique(result.Sources, source)
+		result.ExpiresAt = expires
+		result.Reason = reason
+		existing.SetAnnotations(mergeAnnotations(existing.GetAnnotations(), annotations(result)))
+		_, err = policies.Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return Quarantine{}, fmt.Errorf("failed to apply %s %s/%s: %w", q.kind, namespace, name, err)
+	}
+	return result, nil
+}
+
+// List returns the quarantine policies of all namespaces
+func (q *Quarantiner) List(ctx context.Context) ([]Quarantine, error) {
+	list, err := q.dynamic.Resource(q.resource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: ManagedLabel + "=true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", q.resource.Resource, err)
+	}
+	quarantines := make([]Quarantine, 0, len(list.Items))
+	for i := range list.Items {
+		quarantines = append(quarantines, q.record(&list.Items[i]))
+	}
+	return quarantines, nil
+}
+
+// Sweep removes the expired quarantines. When active is not nil, it holds the
+// "namespace/pod" keys of the violations procscan still reports, and
+// quarantines created only for procscan violations none of whose pods are
+// active are removed as resolved.
+func (q *Quarantiner) Sweep(ctx context.Context, active map[string]bool) ([]Quarantine, error) {
+	quarantines, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := q.now()
+	var removed []Quarantine
+	for _, quarantine := range quarantines {
+		if !now.After(quarantine.ExpiresAt) && !quarantine.resolved(active) {
+			continue
+		}
+		if err := q.Release(ctx, quarantine); err != nil {
+			return removed, err
+		}
+		removed = append(removed, quarantine)
+	}
+	return removed, nil
+}
+
+// Release deletes the policy of quarantine and the label isolating its pod
+func (q *Quarantiner) Release(ctx context.Context, quarantine Quarantine) error {
+	err := q.dynamic.Resource(q.resource).Namespace(quarantine.Namespace).Delete(ctx, quarantine.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s %s/%s: %w", q.kind, quarantine.Namespace, quarantine.Name, err)
+	}
+	if _, isolated := quarantine.Selector[IsolatedPodLabel]; !isolated {
+		return nil
+	}
+	for _, pod := range quarantine.Pods {
+		if err := q.patchPodLabel(ctx, quarantine.Namespace, pod, nil); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolved reports whether the procscan violations of a quarantine created
+// only for them are no longer active
+func (quarantine Quarantine) resolved(active map[string]bool) bool {
+	if active == nil || len(quarantine.Sources) != 1 || quarantine.Sources[0] != SourceProcscan {
+		return false
+	}
+	for _, pod := range quarantine.Pods {
+		if active[quarantine.Namespace+"/"+pod] {
+			return false
+		}
+	}
+	return true
+}
+
+// manifest returns the policy of quarantine denying all egress of its pods
+func (q *Quarantiner) manifest(quarantine Quarantine) *unstructured.Unstructured {
+	matchLabels := make(map[string]any, len(quarantine.Selector))
+	for key, value := range quarantine.Selector {
+		matchLabels[key] = value
+	}
+	var spec map[string]any
+	apiVersion := q.resource.Group + "/" + q.resource.Version
+	if q.kind == KindCilium {
+		spec = map[string]any{
+			"endpointSelector": map[string]any{"matchLabels": matchLabels},
+			"egressDeny":       []any{map[string]any{"toEntities": []any{"all"}}},
+		}
+	} else {
+		// An Egress policy without rules allows no egress at all
+		spec = map[string]any{
+			"podSelector": map[string]any{"matchLabels": matchLabels},
+			"policyTypes": []any{"Egress"},
+			"egress":      []any{},
+		}
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       q.kind,
+		"spec":       spec,
+	}}
+	obj.SetNamespace(quarantine.Namespace)
+	obj.SetName(quarantine.Name)
+	obj.SetLabels(map[string]string{ManagedLabel: "true"})
+	obj.SetAnnotations(annotations(quarantine))
+	return obj
+}
+
+// record reads the quarantine of a policy
+func (q *Quarantiner) record(obj *unstructured.Unstructured) Quarantine {
+	field := "podSelector"
+	if q.kind == KindCilium {
+		field = "endpointSelector"
+	}
+	selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", field, "matchLabels")
+	objAnnotations := obj.GetAnnotations()
+	quarantine := Quarantine{
+		Kind:      q.kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Selector:  selector,
+		Pods:      splitList(objAnnotations[PodsAnnotation]),
+		Sources:   splitList(objAnnotations[SourcesAnnotation]),
+		Reason:    objAnnotations[ReasonAnnotation],
+	}
+	// A policy with an unreadable expiry is removed by the next sweep
+	quarantine.ExpiresAt, _ = time.Parse(time.RFC3339, objAnnotations[ExpiresAnnotation])
+	return quarantine
+}
+
+// patchPodLabel sets the isolation label of a pod, or removes it for nil
+func (q *Quarantiner) patchPodLabel(ctx context.Context, namespace, pod string, value any) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{IsolatedPodLabel: value}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = q.client.CoreV1().Pods(namespace).Patch(ctx, pod, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to label pod %s/%s: %w", namespace, pod, err)
+	}
+	return nil
+}
+
+// WorkloadSelector returns labels without the ones changing between rollouts
+func WorkloadSelector(labels map[string]string) map[string]string {
+	selector := make(map[string]string, len(labels))
+	for key, value := range labels {
+		if !slices.Contains(volatileLabels, key) {
+			selector[key] = value
+		}
+	}
+	return selector
+}
+
+func annotations(quarantine Quarantine) map[string]string {
+	return map[string]string{
+		ExpiresAnnotation: quarantine.ExpiresAt.UTC().Format(time.RFC3339),
+		PodsAnnotation:    strings.Join(quarantine.Pods, ","),
+		SourcesAnnotation: strings.Join(quarantine.Sources, ","),
+		ReasonAnnotation:  quarantine.Reason,
+	}
+}
+
+func mergeAnnotations(existing, updates map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(updates))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range updates {
+		merged[key] = value
+	}
+	return merged
+}
+
+// selectorString renders a selector with sorted keys
+func selectorString(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for key, value := range selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/httpapi"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+)
+
+// Statuses of a queued account action
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusFailed   = "failed"
+)
+
+var (
+	// ErrActionNotFound is returned for unknown action IDs
+	ErrActionNotFound = errors.New("action not found")
+	// ErrActionDecided is returned when an action was already approved or rejected
+	ErrActionDecided = errors.New("action already decided")
+)
+
+// PendingAction is an account action waiting for, or after, manual approval
+type PendingAction struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Request   AccountRequest `json:"request"`
+	Reviewer  string         `json:"reviewer,omitempty"`
+	Comment   string         `json:"comment,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	DecidedAt *time.Time     `json:"decidedAt,omitempty"`
+}
+
+// ApprovalQueue holds account actions in manual-approval mode. The queue is
+// kept in memory; pending actions are lost on restart.
+type ApprovalQueue struct {
+	mu      sync.Mutex
+	nextID  int
+	actions map[string]*PendingAction
+}
+
+func NewApprovalQueue() *ApprovalQueue {
+	return &ApprovalQueue{actions: make(map[string]*PendingAction)}
+}
+
+// Add queues req and returns the pending action
+func (q *ApprovalQueue) Add(req AccountRequest) PendingAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	action := &PendingAction{
+		ID:      strconv.Itoa(q.nextID),
+		Status:  StatusPending,
+		Request: req,
+	}
+	q.actions[action.ID] = action
+	return *action
+}
+
+// List returns the actions with status, or all actions when status is empty,
+// oldest first
+func (q *ApprovalQueue) List(status string) []PendingAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	result := make([]PendingAction, 0, len(q.actions))
+	for _, action := range q.actions {
+		if status == "" || action.Status == status {
+			result = append(result, *action)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.Atoi(result[i].ID)
+		b, _ := strconv.Atoi(result[j].ID)
+		return a < b
+	})
+	return result
+}
+
+// Decide approves or rejects the pending action id. Approved actions are
+// applied with applier; a failed application marks the action failed, and a
+// failed action can be decided again to retry it.
+func (q *ApprovalQueue) Decide(
+	ctx context.Context,
+	applier Applier,
+	id string,
+	approve bool,
+	reviewer, comment string,
+) (PendingAction, error) {
+	q.mu.Lock()
+	action, ok := q.actions[id]
+	if !ok {
+		q.mu.Unlock()
+		return PendingAction{}, ErrActionNotFound
+	}
+	if action.Status != StatusPending && action.Status != StatusFailed {
+		q.mu.Unlock()
+		return *action, ErrActionDecided
+	}
+	// Claim the action so a concurrent decision cannot apply it twice
+	action.Status = StatusApproved
+	if !approve {
+		action.Status = StatusRejected
+	}
+	action.Reviewer = reviewer
+	action.Comment = comment
+	action.Error = ""
+	now := time.Now()
+	action.DecidedAt = &now
+	req := action.Request
+	q.mu.Unlock()
+
+	var err error
+	if approve {
+		req.Approver = reviewer
+		err = applier.Apply(ctx, req)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err != nil {
+		action.Status = StatusFailed
+		action.Error = err.Error()
+	}
+	return *action, err
+}
+
+// decisionRequest is the body of an approve or reject call
+type decisionRequest struct {
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment"`
+}
+
+// ApprovalAPI serves the manual-approval endpoints:
+//
+//	GET  /api/v1/actions?status=pending
+//	POST /api/v1/actions/{id}/approve
+//	POST /api/v1/actions/{id}/reject
+type ApprovalAPI struct {
+	log     logger.Logger
+	queue   *ApprovalQueue
+	applier Applier
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+func NewApprovalAPI(log logger.Logger, token string, queue *ApprovalQueue, applier Applier) *ApprovalAPI {
+	api := &ApprovalAPI{log: log, queue: queue, applier: applier, mux: http.NewServeMux()}
+	api.mux.HandleFunc("GET /api/v1/actions", api.list)
+	api.mux.HandleFunc("POST /api/v1/actions/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		api.decide(w, r, true)
+	})
+	api.mux.HandleFunc("POST /api/v1/actions/{id}/reject", func(w http.ResponseWriter, r *http.Request) {
+		api.decide(w, r, false)
+	})
+	api.handler = httpapi.RequireBearer(token, api.mux)
+	return api
+}
+
+func (a *ApprovalAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *ApprovalAPI) list(w http.ResponseWriter, r *http.Request) {
+	httpapi.WriteJSON(w, http.StatusOK, a.queue.List(r.URL.Query().Get("status")))
+}
+
+func (a *ApprovalAPI) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req decisionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		httpapi.WriteError(w, http.StatusBadRequest, "invalid decision payload")
+		return
+	}
+	if req.Reviewer == "" {
+		httpapi.WriteError(w, http.StatusBadRequest, "reviewer is required")
+		return
+	}
+	action, err := a.queue.Decide(r.Context(), a.applier, r.PathValue("id"), approve, req.Reviewer, req.Comment)
+	switch {
+	case errors.Is(err, ErrActionNotFound):
+		httpapi.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, ErrActionDecided):
+		httpapi.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		a.log.Error("Approved account action failed", logger.Fields{
+			"id":      action.ID,
+			"account": action.Request.AccountID,
+			"error":   err.Error(),
+		})
+		httpapi.WriteJSON(w, http.StatusBadGateway, action)
+		return
+	}
+	a.log.Warn("Account action decided", logger.Fields{
+		"id":       action.ID,
+		"account":  action.Request.AccountID,
+		"status":   action.Status,
+		"reviewer": action.Reviewer,
+	})
+	httpapi.WriteJSON(w, http.StatusOK, action)
+}
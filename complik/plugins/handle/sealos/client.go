@@ -0,0 +1,107 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+)
+
+// Actions the account service can take on an account
+const (
+	ActionSuspend = "suspend"
+	ActionFlag    = "flag"
+)
+
+// AccountRequest is the body sent to the account service
+type AccountRequest struct {
+	AccountID string    `json:"accountId"`
+	Action    string    `json:"action"`
+	Region    string    `json:"region"`
+	Namespace string    `json:"namespace"`
+	Host      string    `json:"host"`
+	URL       string    `json:"url"`
+	Detector  string    `json:"detector"`
+	Severity  string    `json:"severity"`
+	Keywords  []string  `json:"keywords,omitempty"`
+	Reason    string    `json:"reason"`
+	Approver  string    `json:"approver,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// IdempotencyKey is the key of the event the action was taken for, sent
+	// as a header so the account service applies a retried request once
+	IdempotencyKey string `json:"-"`
+}
+
+// AccountClient calls the Sealos account service
+type AccountClient struct {
+	httpClient  *http.Client
+	apiBase     string
+	suspendPath string
+	flagPath    string
+	token       string
+}
+
+func NewAccountClient(apiBase, suspendPath, flagPath, token string, timeout time.Duration) *AccountClient {
+	return &AccountClient{
+		httpClient:  &http.Client{Timeout: timeout},
+		apiBase:     strings.TrimRight(apiBase, "/"),
+		suspendPath: suspendPath,
+		flagPath:    flagPath,
+		token:       token,
+	}
+}
+
+// Apply sends req to the endpoint of its action
+func (c *AccountClient) Apply(ctx context.Context, req AccountRequest) error {
+	path := c.flagPath
+	if req.Action == ActionSuspend {
+		path = c.suspendPath
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set(eventbus.IdempotencyHeader, req.IdempotencyKey)
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("account service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return complikerrors.FromResponse(resp,
+			fmt.Errorf("account service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet))))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+)
+
+// Outcomes of handling a detection result
+const (
+	OutcomeSkipped = "skipped"
+	OutcomeQueued  = "queued"
+	OutcomeApplied = "applied"
+)
+
+// Applier performs an account action
+type Applier interface {
+	Apply(ctx context.Context, req AccountRequest) error
+}
+
+// Handler decides whether a detection result warrants an account action and
+// either applies it or queues it for approval
+type Handler struct {
+	log         logger.Logger
+	mapper      *AccountMapper
+	applier     Applier
+	queue       *ApprovalQueue
+	action      string
+	region      string
+	minSeverity string
+	cooldown    time.Duration
+
+	mu     sync.Mutex
+	recent map[string]time.Time
+}
+
+// NewHandler builds a handler. A nil queue applies actions immediately.
+func NewHandler(
+	log logger.Logger,
+	mapper *AccountMapper,
+	applier Applier,
+	queue *ApprovalQueue,
+	action, region, minSeverity string,
+	cooldown time.Duration,
+) *Handler {
+	return &Handler{
+		log:         log,
+		mapper:      mapper,
+		applier:     applier,
+		queue:       queue,
+		action:      action,
+		region:      region,
+		minSeverity: minSeverity,
+		cooldown:    cooldown,
+		recent:      make(map[string]time.Time),
+	}
+}
+
+// Handle acts on result when it is a confirmed violation of at least the
+// configured severity and the account was not acted on within the cooldown
+func (h *Handler) Handle(ctx context.Context, result *models.DetectorInfo, now time.Time) (string, error) {
+	severity := routing.EffectiveSeverity(result)
+	if !result.IsIllegal || result.Namespace == "" ||
+		models.SeverityRank(severity) < models.SeverityRank(h.minSeverity) {
+		return OutcomeSkipped, nil
+	}
+	accountID, err := h.mapper.Resolve(ctx, result.Namespace)
+	if err != nil {
+		return OutcomeSkipped, err
+	}
+	if !h.claim(accountID, now) {
+		h.log.Debug("Account action skipped during cooldown", logger.Fields{
+			"account":   accountID,
+			"namespace": result.Namespace,
+		})
+		return OutcomeSkipped, nil
+	}
+
+	req := AccountRequest{
+		AccountID: accountID,
+		Action:    h.action,
+		Region:    h.region,
+		Namespace: result.Namespace,
+		Host:      result.Host,
+		URL:       result.URL,
+		Detector:  result.DetectorName,
+		Severity:  severity,
+		Keywords:  result.Keywords,
+		Reason:    reason(result),
+		CreatedAt: now,
+
+		IdempotencyKey: eventbus.IdempotencyKeyFrom(ctx),
+	}
+	if h.queue != nil {
+		pending := h.queue.Add(req)
+		h.log.Warn("Account action awaiting approval", logger.Fields{
+			"id":        pending.ID,
+			"account":   accountID,
+			"namespace": result.Namespace,
+			"action":    h.action,
+		})
+		return OutcomeQueued, nil
+	}
+	if err := h.applier.Apply(ctx, req); err != nil {
+		h.release(accountID)
+		return OutcomeSkipped, err
+	}
+	h.log.Warn("Account action applied", logger.Fields{
+		"account":   accountID,
+		"namespace": result.Namespace,
+		"action":    h.action,
+		"severity":  severity,
+	})
+	return OutcomeApplied, nil
+}
+
+// claim records an action on accountID unless one happened within the cooldown
+func (h *Handler) claim(accountID string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if last, ok := h.recent[accountID]; ok && now.Sub(last) < h.cooldown {
+		return false
+	}
+	h.recent[accountID] = now
+	return true
+}
+
+func (h *Handler) release(accountID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.recent, accountID)
+}
+
+func reason(result *models.DetectorInfo) string {
+	parts := []string{fmt.Sprintf("%s detected a violation on %s", result.DetectorName, result.Host)}
+	if len(result.Keywords) > 0 {
+		parts = append(parts, "keywords: "+strings.Join(result.Keywords, ", "))
+	}
+	if result.Explanation != "" {
+		parts = append(parts, result.Explanation)
+	}
+	return strings.Join(parts, "; ")
+}
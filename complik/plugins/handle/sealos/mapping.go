@@ -0,0 +1,107 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrNoAccount is returned when no mapping rule matches a namespace
+var ErrNoAccount = errors.New("no account mapped to namespace")
+
+// MappingRule maps namespaces matching Pattern to the account ID produced by
+// expanding Account with the capture groups of the match, e.g. "$1"
+type MappingRule struct {
+	Pattern string `json:"pattern"`
+	Account string `json:"account"`
+}
+
+// MappingConfig resolves the account of a namespace. Static entries win over
+// the owner label, which wins over the rules.
+type MappingConfig struct {
+	Static     map[string]string `json:"static"`
+	OwnerLabel string            `json:"ownerLabel"`
+	Rules      []MappingRule     `json:"rules"`
+}
+
+// LabelLookup returns the labels of a namespace
+type LabelLookup func(ctx context.Context, namespace string) (map[string]string, error)
+
+type compiledRule struct {
+	pattern *regexp.Regexp
+	account string
+}
+
+// AccountMapper resolves the tenant account linked to a namespace
+type AccountMapper struct {
+	static     map[string]string
+	ownerLabel string
+	lookup     LabelLookup
+	rules      []compiledRule
+}
+
+// NewAccountMapper compiles cfg. lookup is only used when an owner label is
+// configured and may be nil otherwise.
+func NewAccountMapper(cfg MappingConfig, lookup LabelLookup) (*AccountMapper, error) {
+	m := &AccountMapper{
+		static:     cfg.Static,
+		ownerLabel: cfg.OwnerLabel,
+		lookup:     lookup,
+	}
+	if m.ownerLabel != "" && lookup == nil {
+		return nil, errors.New("owner label mapping requires a namespace lookup")
+	}
+	for i, rule := range cfg.Rules {
+		if rule.Account == "" {
+			return nil, fmt.Errorf("mapping rule %d: account cannot be empty", i)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("mapping rule %d: invalid pattern: %w", i, err)
+		}
+		m.rules = append(m.rules, compiledRule{pattern: pattern, account: rule.Account})
+	}
+	return m, nil
+}
+
+// Resolve returns the account ID of namespace or ErrNoAccount
+func (m *AccountMapper) Resolve(ctx context.Context, namespace string) (string, error) {
+	if account, ok := m.static[namespace]; ok && account != "" {
+		return account, nil
+	}
+	if m.ownerLabel != "" {
+		labels, err := m.lookup(ctx, namespace)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up namespace %s: %w", namespace, err)
+		}
+		if account := labels[m.ownerLabel]; account != "" {
+			return account, nil
+		}
+	}
+	for _, rule := range m.rules {
+		match := rule.pattern.FindStringSubmatchIndex(namespace)
+		if match == nil {
+			continue
+		}
+		account := string(rule.pattern.ExpandString(nil, rule.account, namespace, match))
+		if account != "" {
+			return account, nil
+		}
+	}
+	return "", fmt.Errorf("%w %s", ErrNoAccount, namespace)
+}
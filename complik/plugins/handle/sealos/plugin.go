@@ -0,0 +1,318 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sealos implements a handler plugin that suspends or flags the Sealos
+// tenant account owning a namespace through the account service when a
+// confirmed critical violation is detected. Actions can require manual
+// approval through a small HTTP API before they are applied.
+package sealos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	complikerrors "github.com/bearslyricattack/CompliK/complik/pkg/errors"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/k8s"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	pluginName = constants.HandleSealos
+	pluginType = constants.HandleSealosPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &SealosPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type SealosPlugin struct {
+	log          logger.Logger
+	sealosConfig SealosConfig
+	handler      *Handler
+	server       *http.Server
+}
+
+func (p *SealosPlugin) Name() string {
+	return pluginName
+}
+
+func (p *SealosPlugin) Type() string {
+	return pluginType
+}
+
+type SealosConfig struct {
+	Region        string `json:"region"`
+	APIBase       string `json:"apiBase"`
+	SuspendPath   string `json:"suspendPath"`
+	FlagPath      string `json:"flagPath"`
+	Token         string `json:"token"`
+	Action        string `json:"action"`
+	MinSeverity   string `json:"minSeverity"`
+	CooldownHour  int    `json:"cooldownHour"`
+	TimeoutSecond int    `json:"timeoutSecond"`
+
+	// ManualApproval queues actions until they are approved through the
+	// approval API served on ApprovalAddr
+	ManualApproval *bool  `json:"manualApproval"`
+	ApprovalAddr   string `json:"approvalAddr"`
+	ApprovalToken  string `json:"approvalToken"`
+
+	Mapping MappingConfig `json:"mapping"`
+}
+
+func (p *SealosPlugin) getDefaultConfig() SealosConfig {
+	b := true
+	return SealosConfig{
+		Region:         "UNKNOWN",
+		SuspendPath:    "/account/v1alpha1/suspend",
+		FlagPath:       "/account/v1alpha1/flag",
+		Action:         ActionSuspend,
+		MinSeverity:    models.SeverityCritical,
+		CooldownHour:   24,
+		TimeoutSecond:  10,
+		ManualApproval: &b,
+		Mapping: MappingConfig{
+			OwnerLabel: "user.sealos.io/owner",
+			Rules:      []MappingRule{{Pattern: `^ns-(.+)$`, Account: "$1"}},
+		},
+	}
+}
+
+func (p *SealosPlugin) loadConfig(setting string) error {
+	p.sealosConfig = p.getDefaultConfig()
+	if setting == "" {
+		return errors.New("configuration cannot be empty")
+	}
+	var configFromJSON SealosConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.APIBase == "" {
+		return errors.New("apiBase configuration cannot be empty")
+	}
+	p.sealosConfig.APIBase = configFromJSON.APIBase
+	if configFromJSON.Region != "" {
+		p.sealosConfig.Region = configFromJSON.Region
+	}
+	if configFromJSON.SuspendPath != "" {
+		p.sealosConfig.SuspendPath = configFromJSON.SuspendPath
+	}
+	if configFromJSON.FlagPath != "" {
+		p.sealosConfig.FlagPath = configFromJSON.FlagPath
+	}
+	if configFromJSON.Action != "" {
+		p.sealosConfig.Action = configFromJSON.Action
+	}
+	if p.sealosConfig.Action != ActionSuspend && p.sealosConfig.Action != ActionFlag {
+		return fmt.Errorf("unknown action %q, expected %s or %s", p.sealosConfig.Action, ActionSuspend, ActionFlag)
+	}
+	if configFromJSON.MinSeverity != "" {
+		if models.SeverityRank(configFromJSON.MinSeverity) == 0 {
+			return fmt.Errorf("unknown severity %q", configFromJSON.MinSeverity)
+		}
+		p.sealosConfig.MinSeverity = configFromJSON.MinSeverity
+	}
+	if configFromJSON.CooldownHour > 0 {
+		p.sealosConfig.CooldownHour = configFromJSON.CooldownHour
+	}
+	if configFromJSON.TimeoutSecond > 0 {
+		p.sealosConfig.TimeoutSecond = configFromJSON.TimeoutSecond
+	}
+	if configFromJSON.ManualApproval != nil {
+		p.sealosConfig.ManualApproval = configFromJSON.ManualApproval
+	}
+	p.sealosConfig.ApprovalAddr = configFromJSON.ApprovalAddr
+	if *p.sealosConfig.ManualApproval && p.sealosConfig.ApprovalAddr == "" {
+		return errors.New("approvalAddr configuration cannot be empty in manual approval mode")
+	}
+	if configFromJSON.Mapping.Static != nil {
+		p.sealosConfig.Mapping.Static = configFromJSON.Mapping.Static
+	}
+	if configFromJSON.Mapping.OwnerLabel != "" {
+		p.sealosConfig.Mapping.OwnerLabel = configFromJSON.Mapping.OwnerLabel
+	}
+	if configFromJSON.Mapping.Rules != nil {
+		p.sealosConfig.Mapping.Rules = configFromJSON.Mapping.Rules
+	}
+
+	// The account service token and approval token may come from a secret store
+	for _, secret := range []struct {
+		name   string
+		value  string
+		target *string
+	}{
+		{"token", configFromJSON.Token, &p.sealosConfig.Token},
+		{"approval token", configFromJSON.ApprovalToken, &p.sealosConfig.ApprovalToken},
+	} {
+		if secret.value == "" {
+			continue
+		}
+		if value, err := config.GetSecureValue(secret.value); err == nil {
+			*secret.target = value
+		} else if config.IsSecretReference(secret.value) {
+			return fmt.Errorf("failed to resolve %s: %w", secret.name, err)
+		} else {
+			*secret.target = secret.value
+		}
+	}
+
+	// Approving an action suspends an account, the approval API is never served unauthenticated
+	if *p.sealosConfig.ManualApproval && p.sealosConfig.ApprovalToken == "" {
+		return errors.New("approvalToken configuration cannot be empty in manual approval mode")
+	}
+
+	p.log.Info("Sealos account configuration loaded", logger.Fields{
+		"api_base":        p.sealosConfig.APIBase,
+		"action":          p.sealosConfig.Action,
+		"min_severity":    p.sealosConfig.MinSeverity,
+		"manual_approval": *p.sealosConfig.ManualApproval,
+		"cooldown_hours":  p.sealosConfig.CooldownHour,
+	})
+	return nil
+}
+
+func (p *SealosPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	mapper, err := NewAccountMapper(p.sealosConfig.Mapping, namespaceLabels)
+	if err != nil {
+		return fmt.Errorf("invalid account mapping: %w", err)
+	}
+	client := NewAccountClient(
+		p.sealosConfig.APIBase,
+		p.sealosConfig.SuspendPath,
+		p.sealosConfig.FlagPath,
+		p.sealosConfig.Token,
+		time.Duration(p.sealosConfig.TimeoutSecond)*time.Second,
+	)
+	var queue *ApprovalQueue
+	if *p.sealosConfig.ManualApproval {
+		queue = NewApprovalQueue()
+		p.startApprovalServer(queue, client)
+	}
+	p.handler = NewHandler(
+		p.log,
+		mapper,
+		client,
+		queue,
+		p.sealosConfig.Action,
+		p.sealosConfig.Region,
+		p.sealosConfig.MinSeverity,
+		time.Duration(p.sealosConfig.CooldownHour)*time.Hour,
+	)
+
+	subscribe := eventBus.Subscribe(constants.DetectorTopic)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				result, ok := event.Payload.(*models.DetectorInfo)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.DetectorInfo",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				budgetCtx, cancelBudget := eventBus.Policy().Context(ctx, event.Deadline, eventbus.PhaseHandle)
+				taskCtx, cancel := context.WithTimeout(eventbus.WithIdempotencyKey(budgetCtx, event.Key), 30*time.Second)
+				if _, err := p.handler.Handle(taskCtx, result, time.Now()); err != nil {
+					p.log.Error("Failed to handle account action", logger.Fields{
+						"namespace": result.Namespace,
+						"host":      result.Host,
+						"error":     err.Error(),
+						"class":     complikerrors.Record(p.Name(), err),
+					})
+				}
+				cancel()
+				cancelBudget()
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// startApprovalServer serves the manual-approval API
+func (p *SealosPlugin) startApprovalServer(queue *ApprovalQueue, applier Applier) {
+	p.server = &http.Server{
+		Addr:              p.sealosConfig.ApprovalAddr,
+		Handler:           NewApprovalAPI(p.log, p.sealosConfig.ApprovalToken, queue, applier),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		p.log.Info("Account approval server started", logger.Fields{
+			"addr": p.sealosConfig.ApprovalAddr,
+		})
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("Account approval server stopped", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+func (p *SealosPlugin) Stop(ctx context.Context) error {
+	if p.server != nil {
+		return p.server.Shutdown(ctx)
+	}
+	return nil
+}
+
+// namespaceLabels reads the labels of a namespace from the cluster
+func namespaceLabels(ctx context.Context, namespace string) (map[string]string, error) {
+	if k8s.ClientSet == nil {
+		return nil, errors.New("kubernetes client is not initialized")
+	}
+	ns, err := k8s.ClientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ns.Labels, nil
+}
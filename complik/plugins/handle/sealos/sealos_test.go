@@ -0,0 +1,283 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSealos(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sealos Handler Suite")
+}
+
+type recordingApplier struct {
+	mu       sync.Mutex
+	requests []AccountRequest
+	err      error
+}
+
+func (a *recordingApplier) Apply(_ context.Context, req AccountRequest) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, req)
+	return a.err
+}
+
+var _ = Describe("Sealos", func() {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	critical := &models.DetectorInfo{
+		DetectorName: "safety",
+		Namespace:    "ns-alice",
+		Host:         "casino.example.com",
+		IsIllegal:    true,
+		Severity:     models.SeverityCritical,
+		Keywords:     []string{"casino"},
+	}
+
+	Describe("AccountMapper", func() {
+		It("should prefer static entries, then the owner label, then rules", func() {
+			mapper, err := NewAccountMapper(MappingConfig{
+				Static:     map[string]string{"ns-static": "acct-static"},
+				OwnerLabel: "user.sealos.io/owner",
+				Rules:      []MappingRule{{Pattern: `^ns-(.+)$`, Account: "user-$1"}},
+			}, func(_ context.Context, namespace string) (map[string]string, error) {
+				if namespace == "ns-labeled" {
+					return map[string]string{"user.sealos.io/owner": "acct-owner"}, nil
+				}
+				return nil, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mapper.Resolve(ctx, "ns-static")).To(Equal("acct-static"))
+			Expect(mapper.Resolve(ctx, "ns-labeled")).To(Equal("acct-owner"))
+			Expect(mapper.Resolve(ctx, "ns-bob")).To(Equal("user-bob"))
+			_, err = mapper.Resolve(ctx, "kube-system")
+			Expect(err).To(MatchError(ErrNoAccount))
+		})
+
+		It("should reject invalid rules", func() {
+			_, err := NewAccountMapper(MappingConfig{Rules: []MappingRule{{Pattern: "(", Account: "$1"}}}, nil)
+			Expect(err).To(HaveOccurred())
+			_, err = NewAccountMapper(MappingConfig{OwnerLabel: "owner"}, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("AccountClient", func() {
+		It("should post the request to the action endpoint", func() {
+			var gotPath, gotAuth, gotKey string
+			var got AccountRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				gotKey = r.Header.Get("Idempotency-Key")
+				_ = json.NewDecoder(r.Body).Decode(&got)
+			}))
+			defer server.Close()
+
+			client := NewAccountClient(server.URL+"/", "/suspend", "/flag", "secret", time.Second)
+			Expect(client.Apply(ctx, AccountRequest{AccountID: "alice", Action: ActionSuspend})).To(Succeed())
+			Expect(gotPath).To(Equal("/suspend"))
+			Expect(gotAuth).To(Equal("Bearer secret"))
+			Expect(got.AccountID).To(Equal("alice"))
+
+			Expect(gotKey).To(BeEmpty())
+
+			Expect(client.Apply(ctx, AccountRequest{AccountID: "alice", Action: ActionFlag, IdempotencyKey: "k"})).To(Succeed())
+			Expect(gotPath).To(Equal("/flag"))
+			Expect(gotKey).To(Equal("k"))
+		})
+
+		It("should report non-success responses", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "account locked", http.StatusConflict)
+			}))
+			defer server.Close()
+
+			client := NewAccountClient(server.URL, "/suspend", "/flag", "", time.Second)
+			err := client.Apply(ctx, AccountRequest{Action: ActionSuspend})
+			Expect(err).To(MatchError(ContainSubstring("409: account locked")))
+		})
+	})
+
+	Describe("Handler", func() {
+		var (
+			mapper  *AccountMapper
+			applier *recordingApplier
+		)
+
+		BeforeEach(func() {
+			var err error
+			mapper, err = NewAccountMapper(MappingConfig{
+				Rules: []MappingRule{{Pattern: `^ns-(.+)$`, Account: "$1"}},
+			}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			applier = &recordingApplier{}
+		})
+
+		It("should only act on confirmed violations of the minimum severity", func() {
+			handler := NewHandler(logger.GetLogger(), mapper, applier, nil,
+				ActionSuspend, "cn-beijing", models.SeverityCritical, time.Hour)
+
+			high := *critical
+			high.Severity = models.SeverityHigh
+			Expect(handler.Handle(ctx, &high, now)).To(Equal(OutcomeSkipped))
+			legal := *critical
+			legal.IsIllegal = false
+			Expect(handler.Handle(ctx, &legal, now)).To(Equal(OutcomeSkipped))
+			Expect(applier.requests).To(BeEmpty())
+
+			Expect(handler.Handle(ctx, critical, now)).To(Equal(OutcomeApplied))
+			Expect(applier.requests).To(HaveLen(1))
+			Expect(applier.requests[0].AccountID).To(Equal("alice"))
+			Expect(applier.requests[0].Region).To(Equal("cn-beijing"))
+			Expect(applier.requests[0].Reason).To(ContainSubstring("casino"))
+		})
+
+		It("should not act on the same account again within the cooldown", func() {
+			handler := NewHandler(logger.GetLogger(), mapper, applier, nil,
+				ActionFlag, "", models.SeverityCritical, time.Hour)
+			Expect(handler.Handle(ctx, critical, now)).To(Equal(OutcomeApplied))
+			Expect(handler.Handle(ctx, critical, now.Add(time.Minute))).To(Equal(OutcomeSkipped))
+			Expect(handler.Handle(ctx, critical, now.Add(2*time.Hour))).To(Equal(OutcomeApplied))
+		})
+
+		It("should retry after a failed call", func() {
+			applier.err = errors.New("unavailable")
+			handler := NewHandler(logger.GetLogger(), mapper, applier, nil,
+				ActionSuspend, "", models.SeverityCritical, time.Hour)
+			_, err := handler.Handle(ctx, critical, now)
+			Expect(err).To(HaveOccurred())
+
+			applier.err = nil
+			Expect(handler.Handle(ctx, critical, now.Add(time.Minute))).To(Equal(OutcomeApplied))
+		})
+
+		It("should queue actions in manual approval mode", func() {
+			queue := NewApprovalQueue()
+			handler := NewHandler(logger.GetLogger(), mapper, applier, queue,
+				ActionSuspend, "", models.SeverityCritical, time.Hour)
+			Expect(handler.Handle(ctx, critical, now)).To(Equal(OutcomeQueued))
+			Expect(applier.requests).To(BeEmpty())
+			Expect(queue.List(StatusPending)).To(HaveLen(1))
+		})
+	})
+
+	Describe("ApprovalAPI", func() {
+		var (
+			queue   *ApprovalQueue
+			applier *recordingApplier
+			api     *ApprovalAPI
+		)
+
+		send := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			return rec
+		}
+
+		BeforeEach(func() {
+			queue = NewApprovalQueue()
+			applier = &recordingApplier{}
+			api = NewApprovalAPI(logger.GetLogger(), "secret", queue, applier)
+			queue.Add(AccountRequest{AccountID: "alice", Action: ActionSuspend})
+			queue.Add(AccountRequest{AccountID: "bob", Action: ActionSuspend})
+		})
+
+		It("should reject requests without the token", func() {
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/actions", nil))
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject every request without a configured token", func() {
+			api = NewApprovalAPI(logger.GetLogger(), "", queue, applier)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/actions/1/approve", strings.NewReader(`{"reviewer":"carol"}`))
+			req.Header.Set("Authorization", "Bearer ")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(applier.requests).To(BeEmpty())
+		})
+
+		It("should not start manual approval without a token", func() {
+			p := &SealosPlugin{log: logger.GetLogger()}
+			Expect(p.loadConfig(`{"apiBase":"http://account","approvalAddr":":8092"}`)).
+				To(MatchError(ContainSubstring("approvalToken")))
+			Expect(p.loadConfig(`{"apiBase":"http://account","approvalAddr":":8092","approvalToken":"secret"}`)).
+				To(Succeed())
+			Expect(p.loadConfig(`{"apiBase":"http://account","manualApproval":false}`)).To(Succeed())
+		})
+
+		It("should apply approved actions once", func() {
+			rec := send(http.MethodPost, "/api/v1/actions/1/approve", `{"reviewer":"carol"}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(applier.requests).To(HaveLen(1))
+			Expect(applier.requests[0].Approver).To(Equal("carol"))
+
+			rec = send(http.MethodPost, "/api/v1/actions/1/approve", `{"reviewer":"carol"}`)
+			Expect(rec.Code).To(Equal(http.StatusConflict))
+			Expect(applier.requests).To(HaveLen(1))
+		})
+
+		It("should reject actions without applying them", func() {
+			rec := send(http.MethodPost, "/api/v1/actions/2/reject", `{"reviewer":"carol","comment":"false positive"}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(applier.requests).To(BeEmpty())
+
+			var pending []PendingAction
+			rec = send(http.MethodGet, "/api/v1/actions?status=pending", "")
+			Expect(json.Unmarshal(rec.Body.Bytes(), &pending)).To(Succeed())
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].Request.AccountID).To(Equal("alice"))
+		})
+
+		It("should mark actions failed when the account service fails", func() {
+			applier.err = errors.New("unavailable")
+			rec := send(http.MethodPost, "/api/v1/actions/1/approve", `{"reviewer":"carol"}`)
+			Expect(rec.Code).To(Equal(http.StatusBadGateway))
+			Expect(queue.List(StatusFailed)).To(HaveLen(1))
+
+			applier.err = nil
+			rec = send(http.MethodPost, "/api/v1/actions/1/approve", `{"reviewer":"carol"}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(queue.List(StatusApproved)).To(HaveLen(1))
+		})
+
+		It("should validate decisions", func() {
+			Expect(send(http.MethodPost, "/api/v1/actions/1/approve", `{}`).Code).
+				To(Equal(http.StatusBadRequest))
+			Expect(send(http.MethodPost, "/api/v1/actions/9/approve", `{"reviewer":"carol"}`).Code).
+				To(Equal(http.StatusNotFound))
+		})
+	})
+})
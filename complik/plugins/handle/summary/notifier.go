@@ -0,0 +1,197 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/scanrun"
+)
+
+// Notifier sends scan summaries to the configured webhooks
+type Notifier struct {
+	LarkWebhook  string
+	SlackWebhook string
+	Region       string
+	HTTPClient   *http.Client
+}
+
+// Send posts summary to every configured webhook
+func (n *Notifier) Send(ctx context.Context, summary *models.ScanSummary) error {
+	var errs []error
+	if n.LarkWebhook != "" {
+		if err := n.sendLark(ctx, summary); err != nil {
+			errs = append(errs, fmt.Errorf("lark: %w", err))
+		}
+	}
+	if n.SlackWebhook != "" {
+		if err := n.sendSlack(ctx, summary); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// title names the run in the card header
+func (n *Notifier) title(summary *models.ScanSummary) string {
+	title := fmt.Sprintf("Scan Summary: %s in %s", summary.Source, n.Region)
+	if summary.State != scanrun.StateCompleted {
+		title += " (" + summary.State + ")"
+	}
+	return title
+}
+
+// lines are the facts shown by both cards, as label and value
+func (n *Notifier) lines(summary *models.ScanSummary) [][2]string {
+	return [][2]string{
+		{"Run", summary.RunID},
+		{"Targets", fmt.Sprintf("%d (%d collected, %d failed)", summary.Total, summary.Completed, summary.Failed)},
+		{"Violations", fmt.Sprint(summary.Violations)},
+		{"New Incidents", fmt.Sprint(summary.NewIncidents)},
+		{"Duration", summary.Duration().Round(time.Second).String()},
+		{"Window", summary.StartedAt.Format(time.DateTime) + " - " + summary.FinishedAt.Format(time.DateTime)},
+	}
+}
+
+// template picks the header color: red for violations, orange for failed
+// targets and green otherwise
+func template(summary *models.ScanSummary) string {
+	switch {
+	case summary.Violations > 0 || summary.NewIncidents > 0:
+		return "red"
+	case summary.Failed > 0 || summary.State != scanrun.StateCompleted:
+		return "orange"
+	default:
+		return "green"
+	}
+}
+
+func (n *Notifier) buildLarkMessage(summary *models.ScanSummary) map[string]any {
+	elements := make([]map[string]any, 0, 6)
+	for _, line := range n.lines(summary) {
+		elements = append(elements, map[string]any{
+			"tag": "div",
+			"text": map[string]any{
+				"content": fmt.Sprintf("**%s:** %s", line[0], line[1]),
+				"tag":     "lark_md",
+			},
+		})
+	}
+	return map[string]any{
+		"msg_type": "interactive",
+		"card": map[string]any{
+			"config": map[string]any{
+				"wide_screen_mode": true,
+			},
+			"header": map[string]any{
+				"template": template(summary),
+				"title": map[string]any{
+					"content": n.title(summary),
+					"tag":     "plain_text",
+				},
+			},
+			"elements": elements,
+		},
+	}
+}
+
+func (n *Notifier) buildSlackMessage(summary *models.ScanSummary) map[string]any {
+	fields := make([]map[string]any, 0, 6)
+	for _, line := range n.lines(summary) {
+		fields = append(fields, map[string]any{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s:*\n%s", line[0], line[1]),
+		})
+	}
+	title := n.title(summary)
+	return map[string]any{
+		// text is the fallback shown in notifications
+		"text": title,
+		"blocks": []map[string]any{
+			{
+				"type": "header",
+				"text": map[string]any{"type": "plain_text", "text": title},
+			},
+			{
+				"type":   "section",
+				"fields": fields,
+			},
+		},
+	}
+}
+
+func (n *Notifier) sendLark(ctx context.Context, summary *models.ScanSummary) error {
+	body, status, err := n.post(ctx, n.LarkWebhook, n.buildLarkMessage(summary))
+	if err != nil {
+		return err
+	}
+	var larkResp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &larkResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if status != http.StatusOK || larkResp.Code != 0 {
+		return fmt.Errorf("HTTP status %d, Lark error code %d, error message: %s", status, larkResp.Code, larkResp.Msg)
+	}
+	return nil
+}
+
+func (n *Notifier) sendSlack(ctx context.Context, summary *models.ScanSummary) error {
+	body, status, err := n.post(ctx, n.SlackWebhook, n.buildSlackMessage(summary))
+	if err != nil {
+		return err
+	}
+	// Slack answers errors with a status and a plain text reason
+	if status != http.StatusOK {
+		return fmt.Errorf("HTTP status %d: %s", status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (n *Notifier) post(ctx context.Context, webhookURL string, message any) ([]byte, int, error) {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to serialize message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := eventbus.IdempotencyKeyFrom(ctx); key != "" {
+		req.Header.Set(eventbus.IdempotencyHeader, key)
+	}
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
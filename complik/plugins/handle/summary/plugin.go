@@ -0,0 +1,207 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package summary implements a handler plugin that sends one summary card per
+// finished scan run to Lark and Slack: the targets, violations, new incidents,
+// failures and duration of the run.
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.HandleScanSummary
+	pluginType = constants.HandleScanSummaryPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &SummaryPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type SummaryPlugin struct {
+	log           logger.Logger
+	summaryConfig SummaryConfig
+	notifier      *Notifier
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+func (p *SummaryPlugin) Name() string {
+	return pluginName
+}
+
+func (p *SummaryPlugin) Type() string {
+	return pluginType
+}
+
+type SummaryConfig struct {
+	Region string `json:"region"`
+	// LarkWebhook and SlackWebhook receive the summaries, at least one of
+	// them is required
+	LarkWebhook  string `json:"larkWebhook"`
+	SlackWebhook string `json:"slackWebhook"`
+	// Sources limits the summaries to the runs of these discovery plugins,
+	// empty for all runs
+	Sources       []string `json:"sources"`
+	TimeoutSecond int      `json:"timeoutSecond"`
+}
+
+func (p *SummaryPlugin) getDefaultConfig() SummaryConfig {
+	return SummaryConfig{
+		Region:        "UNKNOWN",
+		TimeoutSecond: 10,
+	}
+}
+
+func (p *SummaryPlugin) loadConfig(setting string) error {
+	p.summaryConfig = p.getDefaultConfig()
+	if setting == "" {
+		return errors.New("configuration cannot be empty")
+	}
+	var configFromJSON SummaryConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	c := &p.summaryConfig
+	// Webhook URLs embed credentials and may also come from a secret store
+	for _, webhook := range []struct {
+		name string
+		from string
+		to   *string
+	}{
+		{"lark webhook", configFromJSON.LarkWebhook, &c.LarkWebhook},
+		{"slack webhook", configFromJSON.SlackWebhook, &c.SlackWebhook},
+	} {
+		if webhook.from == "" {
+			continue
+		}
+		if value, err := config.GetSecureValue(webhook.from); err == nil {
+			*webhook.to = value
+		} else if config.IsSecretReference(webhook.from) {
+			return fmt.Errorf("failed to resolve %s: %w", webhook.name, err)
+		} else {
+			*webhook.to = webhook.from
+		}
+	}
+	if c.LarkWebhook == "" && c.SlackWebhook == "" {
+		return errors.New("larkWebhook or slackWebhook configuration is required")
+	}
+	if configFromJSON.Region != "" {
+		c.Region = configFromJSON.Region
+	}
+	if configFromJSON.TimeoutSecond > 0 {
+		c.TimeoutSecond = configFromJSON.TimeoutSecond
+	}
+	c.Sources = configFromJSON.Sources
+
+	p.notifier = &Notifier{
+		LarkWebhook:  c.LarkWebhook,
+		SlackWebhook: c.SlackWebhook,
+		Region:       c.Region,
+		HTTPClient:   &http.Client{Timeout: time.Duration(c.TimeoutSecond) * time.Second},
+	}
+	p.log.Info("Scan summary configuration loaded", logger.Fields{
+		"region":  c.Region,
+		"lark":    c.LarkWebhook != "",
+		"slack":   c.SlackWebhook != "",
+		"sources": c.Sources,
+	})
+	return nil
+}
+
+func (p *SummaryPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+
+	subscribe := eventBus.Subscribe(constants.ScanSummaryTopic)
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		for {
+			select {
+			case event, ok := <-subscribe:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				summary, ok := event.Payload.(*models.ScanSummary)
+				if !ok {
+					p.log.Error("Invalid event payload type", logger.Fields{
+						"expected": "*models.ScanSummary",
+						"actual":   fmt.Sprintf("%T", event.Payload),
+					})
+					continue
+				}
+				if len(p.summaryConfig.Sources) > 0 && !slices.Contains(p.summaryConfig.Sources, summary.Source) {
+					continue
+				}
+				if err := p.notifier.Send(eventbus.WithIdempotencyKey(ctx, event.Key), summary); err != nil {
+					p.log.Error("Failed to send scan summary", logger.Fields{
+						"run_id": summary.RunID,
+						"error":  err.Error(),
+					})
+				}
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *SummaryPlugin) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
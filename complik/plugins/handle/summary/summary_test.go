@@ -0,0 +1,119 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSummary(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scan Summary Handler Suite")
+}
+
+// webhook records the JSON bodies posted to it and answers with reply
+func webhook(reply string) (*httptest.Server, chan map[string]any) {
+	bodies := make(chan map[string]any, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		bodies <- body
+		w.Write([]byte(reply))
+	}))
+	DeferCleanup(server.Close)
+	return server, bodies
+}
+
+var _ = Describe("Scan summary", func() {
+	started := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	summary := &models.ScanSummary{
+		RunID:        "complete-2025-06-01T02:00:00Z",
+		Source:       "Complete",
+		State:        "completed",
+		StartedAt:    started,
+		FinishedAt:   started.Add(42 * time.Minute),
+		Total:        120,
+		Completed:    117,
+		Failed:       3,
+		Violations:   2,
+		NewIncidents: 1,
+	}
+
+	It("should send the summary card to Lark and Slack", func() {
+		lark, larkBodies := webhook(`{"code":0}`)
+		slack, slackBodies := webhook("ok")
+		n := &Notifier{LarkWebhook: lark.URL, SlackWebhook: slack.URL, Region: "hzh", HTTPClient: http.DefaultClient}
+		Expect(n.Send(context.Background(), summary)).To(Succeed())
+
+		card := (<-larkBodies)["card"].(map[string]any)
+		header := card["header"].(map[string]any)
+		Expect(header["template"]).To(Equal("red"))
+		Expect(header["title"]).To(HaveKeyWithValue("content", "Scan Summary: Complete in hzh"))
+		data, _ := json.Marshal(card["elements"])
+		Expect(string(data)).To(ContainSubstring("**Targets:** 120 (117 collected, 3 failed)"))
+		Expect(string(data)).To(ContainSubstring("**New Incidents:** 1"))
+		Expect(string(data)).To(ContainSubstring("**Duration:** 42m0s"))
+
+		message := <-slackBodies
+		Expect(message["text"]).To(Equal("Scan Summary: Complete in hzh"))
+		data, _ = json.Marshal(message["blocks"])
+		Expect(string(data)).To(ContainSubstring(`*Violations:*\n2`))
+	})
+
+	It("should report webhook errors", func() {
+		lark, _ := webhook(`{"code":19021,"msg":"sign match fail"}`)
+		n := &Notifier{LarkWebhook: lark.URL, HTTPClient: http.DefaultClient}
+		Expect(n.Send(context.Background(), summary)).To(MatchError(ContainSubstring("sign match fail")))
+	})
+
+	It("should require a webhook", func() {
+		p := &SummaryPlugin{log: logger.GetLogger()}
+		Expect(p.loadConfig(`{"region":"hzh"}`)).To(MatchError(ContainSubstring("required")))
+		Expect(p.loadConfig(`{"slackWebhook":"https://hooks.slack.com/services/x"}`)).To(Succeed())
+		Expect(p.summaryConfig.Region).To(Equal("UNKNOWN"))
+	})
+
+	It("should send the summaries of the configured sources", func() {
+		slack, bodies := webhook("ok")
+		eb := eventbus.NewEventBus(10)
+		p := &SummaryPlugin{log: logger.GetLogger()}
+		Expect(p.Start(context.Background(), config.PluginConfig{
+			Settings: `{"slackWebhook":"` + slack.URL + `","sources":["Complete"]}`,
+		}, eb)).To(Succeed())
+		DeferCleanup(p.Stop, context.Background())
+
+		devbox := *summary
+		devbox.Source = "Devbox"
+		eb.Publish(constants.ScanSummaryTopic, eventbus.Event{Payload: &devbox})
+		eb.Publish(constants.ScanSummaryTopic, eventbus.Event{Payload: summary})
+		var message map[string]any
+		Eventually(bodies).Should(Receive(&message))
+		Expect(message["text"]).To(Equal("Scan Summary: Complete in UNKNOWN"))
+		Consistently(bodies, "100ms").ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,139 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/correlation"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/routing"
+)
+
+// Canonical field names of an Event. The field mapping of a format turns
+// them into CEF extension or LEEF attribute keys.
+const (
+	FieldRegion      = "region"
+	FieldNamespace   = "namespace"
+	FieldName        = "name"
+	FieldHost        = "host"
+	FieldURL         = "url"
+	FieldDetector    = "detector"
+	FieldKeywords    = "keywords"
+	FieldDescription = "description"
+	FieldExplanation = "explanation"
+	FieldPod         = "pod"
+	FieldNode        = "node"
+	FieldProcess     = "process"
+	FieldCmdline     = "cmdline"
+	FieldRule        = "rule"
+)
+
+// Event is a detection or violation ready to be formatted
+type Event struct {
+	Time time.Time
+	// SignatureID identifies the kind of event, e.g. website:safety
+	SignatureID string
+	Name        string
+	Severity    string
+	Fields      map[string]string
+}
+
+// FromDetector converts a detector result
+func FromDetector(result *models.DetectorInfo, region string, now time.Time) Event {
+	if result.Region != "" {
+		region = result.Region
+	}
+	name := "Compliant website"
+	if result.IsIllegal {
+		name = "Website violation"
+	}
+	return Event{
+		Time:        now,
+		SignatureID: models.SourceWebsite + ":" + strings.ToLower(result.DetectorName),
+		Name:        name,
+		Severity:    routing.EffectiveSeverity(result),
+		Fields: map[string]string{
+			FieldRegion:      region,
+			FieldNamespace:   result.Namespace,
+			FieldName:        result.Name,
+			FieldHost:        result.Host,
+			FieldURL:         result.URL,
+			FieldDetector:    result.DetectorName,
+			FieldKeywords:    strings.Join(result.Keywords, ","),
+			FieldDescription: result.Description,
+			FieldExplanation: result.Explanation,
+		},
+	}
+}
+
+// FromMining converts a mining detection
+func FromMining(info *models.MiningInfo, region string, now time.Time) Event {
+	if info.Region != "" {
+		region = info.Region
+	}
+	severity := info.Severity
+	if severity == "" {
+		severity = models.SeverityHigh
+	}
+	var description []string
+	if len(info.Wallets) > 0 {
+		description = append(description, "wallets: "+strings.Join(info.Wallets, ","))
+	}
+	if len(info.Pools) > 0 {
+		description = append(description, "pools: "+strings.Join(info.Pools, ","))
+	}
+	if len(info.SharedWith) > 0 {
+		description = append(description, "shared with: "+strings.Join(info.SharedWith, ","))
+	}
+	return Event{
+		Time:        now,
+		SignatureID: models.SourceMining,
+		Name:        "Mining process",
+		Severity:    severity,
+		Fields: map[string]string{
+			FieldRegion:      region,
+			FieldNamespace:   info.Namespace,
+			FieldPod:         info.PodName,
+			FieldNode:        info.NodeName,
+			FieldProcess:     info.Command,
+			FieldDescription: strings.Join(description, "; "),
+		},
+	}
+}
+
+// FromProcessViolation converts a procscan violation
+func FromProcessViolation(violation *correlation.ProcessViolation, region string, now time.Time) Event {
+	if t, err := time.Parse(time.RFC3339, violation.Timestamp); err == nil {
+		now = t
+	}
+	return Event{
+		Time:        now,
+		SignatureID: models.SourceProcscan + ":" + violation.Type,
+		Name:        fmt.Sprintf("Process violation (%s)", violation.Type),
+		Severity:    models.SeverityHigh,
+		Fields: map[string]string{
+			FieldRegion:    region,
+			FieldNamespace: violation.Namespace,
+			FieldName:      violation.Name,
+			FieldPod:       violation.Pod,
+			FieldProcess:   violation.Process,
+			FieldCmdline:   violation.Cmdline,
+			FieldRule:      violation.Regex,
+		},
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Message formats
+const (
+	FormatCEF  = "cef"
+	FormatLEEF = "leef"
+)
+
+// DefaultCEFFields maps the event fields to CEF extension keys. Custom
+// string keys (cs1-cs6, flexString1-2) get a label naming the field.
+var DefaultCEFFields = map[string]string{
+	FieldRegion:      "cs1",
+	FieldNamespace:   "cs2",
+	FieldDetector:    "cs3",
+	FieldKeywords:    "cs4",
+	FieldPod:         "cs5",
+	FieldNode:        "cs6",
+	FieldRule:        "flexString1",
+	FieldCmdline:     "flexString2",
+	FieldName:        "destinationServiceName",
+	FieldHost:        "dhost",
+	FieldURL:         "request",
+	FieldDescription: "msg",
+	FieldExplanation: "reason",
+	FieldProcess:     "dproc",
+}
+
+// DefaultLEEFFields maps the event fields to LEEF attributes
+var DefaultLEEFFields = map[string]string{
+	FieldRegion:      "region",
+	FieldNamespace:   "namespace",
+	FieldName:        "name",
+	FieldHost:        "dstHost",
+	FieldURL:         "url",
+	FieldDetector:    "detector",
+	FieldKeywords:    "keywords",
+	FieldDescription: "msg",
+	FieldExplanation: "reason",
+	FieldPod:         "pod",
+	FieldNode:        "node",
+	FieldProcess:     "proc",
+	FieldCmdline:     "cmdline",
+	FieldRule:        "rule",
+}
+
+// Formatter renders events as CEF or LEEF messages
+type Formatter struct {
+	format  string
+	vendor  string
+	product string
+	version string
+	fields  map[string]string
+}
+
+// NewFormatter merges overrides into the default field mapping of format;
+// mapping a field to "" leaves it out
+func NewFormatter(format, vendor, product, version string, overrides map[string]string) (*Formatter, error) {
+	var fields map[string]string
+	switch format {
+	case FormatCEF:
+		fields = maps.Clone(DefaultCEFFields)
+	case FormatLEEF:
+		fields = maps.Clone(DefaultLEEFFields)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s or %s", format, FormatCEF, FormatLEEF)
+	}
+	for field, key := range overrides {
+		if key == "" {
+			delete(fields, field)
+			continue
+		}
+		if strings.ContainsAny(key, " =\t|") {
+			return nil, fmt.Errorf("invalid key %q for field %s", key, field)
+		}
+		fields[field] = key
+	}
+	return &Formatter{format: format, vendor: vendor, product: product, version: version, fields: fields}, nil
+}
+
+// Format renders event
+func (f *Formatter) Format(event Event) string {
+	if f.format == FormatLEEF {
+		return f.leef(event)
+	}
+	return f.cef(event)
+}
+
+func (f *Formatter) cef(event Event) string {
+	header := strings.Join([]string{
+		"CEF:0",
+		cefHeader(f.vendor),
+		cefHeader(f.product),
+		cefHeader(f.version),
+		cefHeader(event.SignatureID),
+		cefHeader(event.Name),
+		strconv.Itoa(cefSeverity(event.Severity)),
+	}, "|")
+
+	extension := []string{"rt=" + strconv.FormatInt(event.Time.UnixMilli(), 10)}
+	for _, field := range slices.Sorted(maps.Keys(f.fields)) {
+		value := event.Fields[field]
+		if value == "" {
+			continue
+		}
+		key := f.fields[field]
+		extension = append(extension, key+"="+cefValue(value))
+		if strings.HasPrefix(key, "cs") || strings.HasPrefix(key, "flexString") {
+			extension = append(extension, key+"Label="+cefValue(field))
+		}
+	}
+	return header + "|" + strings.Join(extension, " ")
+}
+
+func (f *Formatter) leef(event Event) string {
+	header := strings.Join([]string{
+		"LEEF:1.0",
+		leefHeader(f.vendor),
+		leefHeader(f.product),
+		leefHeader(f.version),
+		leefHeader(event.SignatureID),
+	}, "|")
+
+	attributes := []string{
+		"devTime=" + event.Time.UTC().Format("Jan 02 2006 15:04:05") + " UTC",
+		"devTimeFormat=MMM dd yyyy HH:mm:ss z",
+		"sev=" + strconv.Itoa(cefSeverity(event.Severity)),
+		"cat=" + leefValue(event.Name),
+	}
+	for _, field := range slices.Sorted(maps.Keys(f.fields)) {
+		if value := event.Fields[field]; value != "" {
+			attributes = append(attributes, f.fields[field]+"="+leefValue(value))
+		}
+	}
+	return header + "|" + strings.Join(attributes, "\t")
+}
+
+// cefSeverity maps a CompliK severity to the 0-10 scale of CEF and LEEF
+func cefSeverity(severity string) int {
+	switch severity {
+	case models.SeverityCritical:
+		return 10
+	case models.SeverityHigh:
+		return 8
+	case models.SeverityMedium:
+		return 5
+	case models.SeverityLow:
+		return 3
+	default:
+		return 0
+	}
+}
+
+var (
+	cefHeaderEscaper  = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefValueEscaper   = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+	leefHeaderEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ", "|", "/")
+	leefValueEscaper  = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+func cefHeader(value string) string { return cefHeaderEscaper.Replace(value) }
+
+func cefValue(value string) string { return cefValueEscaper.Replace(value) }
+
+// leefHeader and leefValue replace the delimiters, LEEF 1.0 has no escaping
+func leefHeader(value string) string { return leefHeaderEscaper.Replace(value) }
+
+func leefValue(value string) string { return leefValueEscaper.Replace(value) }
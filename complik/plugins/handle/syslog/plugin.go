@@ -0,0 +1,339 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syslog implements a handler plugin that forwards detector results,
+// mining detections and procscan violations to a SIEM such as ArcSight or
+// QRadar as CEF or LEEF messages over syslog (UDP, TCP or TLS).
+package syslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/constants"
+	"github.com/bearslyricattack/CompliK/complik/pkg/correlation"
+	"github.com/bearslyricattack/CompliK/complik/pkg/eventbus"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	"github.com/bearslyricattack/CompliK/complik/pkg/plugin"
+	"github.com/bearslyricattack/CompliK/complik/pkg/utils/config"
+)
+
+const (
+	pluginName = constants.HandleSyslog
+	pluginType = constants.HandleSyslogPluginType
+)
+
+func init() {
+	plugin.PluginFactories[pluginName] = func() plugin.Plugin {
+		return &SyslogPlugin{
+			log: logger.GetLogger().WithField("plugin", pluginName),
+		}
+	}
+}
+
+type SyslogPlugin struct {
+	log          logger.Logger
+	syslogConfig SyslogConfig
+	formatter    *Formatter
+	writer       *Writer
+	// violations are the procscan violations already sent, by key
+	violations map[string]struct{}
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func (p *SyslogPlugin) Name() string {
+	return pluginName
+}
+
+func (p *SyslogPlugin) Type() string {
+	return pluginType
+}
+
+type SyslogConfig struct {
+	Region string `json:"region"`
+
+	// Network is udp, tcp or tls
+	Network       string `json:"network"`
+	Address       string `json:"address"`
+	Header        string `json:"header"`
+	Facility      string `json:"facility"`
+	Hostname      string `json:"hostname"`
+	AppName       string `json:"appName"`
+	OctetCounting bool   `json:"octetCounting"`
+	CAFile        string `json:"caFile"`
+	ServerName    string `json:"serverName"`
+	TimeoutSecond int    `json:"timeoutSecond"`
+
+	// Format is cef or leef; Fields overrides the keys of the event fields
+	Format         string            `json:"format"`
+	Vendor         string            `json:"vendor"`
+	Product        string            `json:"product"`
+	ProductVersion string            `json:"productVersion"`
+	Fields         map[string]string `json:"fields"`
+
+	// IncludeCompliant also sends the results that flagged nothing
+	IncludeCompliant bool `json:"includeCompliant"`
+
+	// ProcscanURL is the violations endpoint of the procscan aggregator,
+	// polled every ProcscanIntervalSecond when set
+	ProcscanURL            string `json:"procscanURL"`
+	ProcscanIntervalSecond int    `json:"procscanIntervalSecond"`
+}
+
+func (p *SyslogPlugin) getDefaultConfig() SyslogConfig {
+	hostname, _ := os.Hostname()
+	return SyslogConfig{
+		Region:                 "UNKNOWN",
+		Network:                "udp",
+		Header:                 HeaderRFC5424,
+		Facility:               "local0",
+		Hostname:               hostname,
+		AppName:                "complik",
+		TimeoutSecond:          5,
+		Format:                 FormatCEF,
+		Vendor:                 "CompliK",
+		Product:                "CompliK",
+		ProductVersion:         "1.0",
+		ProcscanIntervalSecond: 60,
+	}
+}
+
+func (p *SyslogPlugin) loadConfig(setting string) error {
+	p.syslogConfig = p.getDefaultConfig()
+	if setting == "" {
+		return errors.New("configuration cannot be empty")
+	}
+	var configFromJSON SyslogConfig
+	if err := json.Unmarshal([]byte(setting), &configFromJSON); err != nil {
+		p.log.Error("Failed to parse config", logger.Fields{
+			"error": err.Error(),
+		})
+		return err
+	}
+	if configFromJSON.Address == "" {
+		return errors.New("address configuration cannot be empty")
+	}
+	c := &p.syslogConfig
+	c.Address = configFromJSON.Address
+	for _, value := range []struct {
+		from string
+		to   *string
+	}{
+		{configFromJSON.Region, &c.Region},
+		{configFromJSON.Network, &c.Network},
+		{configFromJSON.Header, &c.Header},
+		{configFromJSON.Facility, &c.Facility},
+		{configFromJSON.Hostname, &c.Hostname},
+		{configFromJSON.AppName, &c.AppName},
+		{configFromJSON.Format, &c.Format},
+		{configFromJSON.Vendor, &c.Vendor},
+		{configFromJSON.Product, &c.Product},
+		{configFromJSON.ProductVersion, &c.ProductVersion},
+	} {
+		if value.from != "" {
+			*value.to = value.from
+		}
+	}
+	if c.Hostname == "" {
+		c.Hostname = "-"
+	}
+	if configFromJSON.TimeoutSecond > 0 {
+		c.TimeoutSecond = configFromJSON.TimeoutSecond
+	}
+	if configFromJSON.ProcscanIntervalSecond > 0 {
+		c.ProcscanIntervalSecond = configFromJSON.ProcscanIntervalSecond
+	}
+	c.OctetCounting = configFromJSON.OctetCounting
+	c.CAFile = configFromJSON.CAFile
+	c.ServerName = configFromJSON.ServerName
+	c.Fields = configFromJSON.Fields
+	c.IncludeCompliant = configFromJSON.IncludeCompliant
+	c.ProcscanURL = configFromJSON.ProcscanURL
+
+	var err error
+	p.formatter, err = NewFormatter(c.Format, c.Vendor, c.Product, c.ProductVersion, c.Fields)
+	if err != nil {
+		return err
+	}
+	p.writer, err = NewWriter(WriterConfig{
+		Network:       c.Network,
+		Address:       c.Address,
+		Header:        c.Header,
+		Facility:      c.Facility,
+		Hostname:      c.Hostname,
+		AppName:       c.AppName,
+		OctetCounting: c.OctetCounting,
+		CAFile:        c.CAFile,
+		ServerName:    c.ServerName,
+		Timeout:       time.Duration(c.TimeoutSecond) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.log.Info("Syslog configuration loaded", logger.Fields{
+		"network":      c.Network,
+		"address":      c.Address,
+		"format":       c.Format,
+		"header":       c.Header,
+		"procscan_url": c.ProcscanURL,
+	})
+	return nil
+}
+
+func (p *SyslogPlugin) Start(
+	ctx context.Context,
+	config config.PluginConfig,
+	eventBus *eventbus.EventBus,
+) error {
+	if err := p.loadConfig(config.Settings); err != nil {
+		return err
+	}
+	p.violations = make(map[string]struct{})
+
+	detector := eventBus.Subscribe(constants.DetectorTopic)
+	mining := eventBus.Subscribe(constants.MiningTopic)
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Error("Plugin goroutine panic", logger.Fields{
+					"panic": r,
+				})
+			}
+		}()
+		var (
+			procscan     *correlation.ProcscanClient
+			procscanPoll <-chan time.Time
+		)
+		if p.syslogConfig.ProcscanURL != "" {
+			interval := time.Duration(p.syslogConfig.ProcscanIntervalSecond) * time.Second
+			procscan = correlation.NewProcscanClient(p.syslogConfig.ProcscanURL, min(interval, 30*time.Second))
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			procscanPoll = ticker.C
+		}
+		for {
+			select {
+			case event, ok := <-detector:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				result, ok := event.Payload.(*models.DetectorInfo)
+				if !ok {
+					p.invalidPayload("*models.DetectorInfo", event.Payload)
+					continue
+				}
+				if result.IsIllegal || p.syslogConfig.IncludeCompliant {
+					p.send(FromDetector(result, p.syslogConfig.Region, time.Now()))
+				}
+			case event, ok := <-mining:
+				if !ok {
+					p.log.Info("Event subscription channel closed")
+					return
+				}
+				info, ok := event.Payload.(*models.MiningInfo)
+				if !ok {
+					p.invalidPayload("*models.MiningInfo", event.Payload)
+					continue
+				}
+				p.send(FromMining(info, p.syslogConfig.Region, time.Now()))
+			case now := <-procscanPoll:
+				p.pollProcscan(ctx, procscan, now)
+			case <-ctx.Done():
+				p.log.Info("Plugin received stop signal")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// pollProcscan sends the violations that appeared since the last poll. A
+// violation that disappears and comes back is sent again.
+func (p *SyslogPlugin) pollProcscan(ctx context.Context, client *correlation.ProcscanClient, now time.Time) {
+	violations, err := client.FetchViolations(ctx)
+	if err != nil {
+		p.log.Error("Failed to fetch procscan violations", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	p.sendViolations(violations, now)
+}
+
+func (p *SyslogPlugin) sendViolations(violations []*correlation.ProcessViolation, now time.Time) {
+	active := make(map[string]struct{}, len(violations))
+	for _, violation := range violations {
+		if violation == nil {
+			continue
+		}
+		key := violation.Namespace + "/" + violation.Pod + "/" + violation.Process + "/" + violation.Regex
+		active[key] = struct{}{}
+		if _, sent := p.violations[key]; sent {
+			continue
+		}
+		if p.send(FromProcessViolation(violation, p.syslogConfig.Region, now)) {
+			p.violations[key] = struct{}{}
+		}
+	}
+	for key := range p.violations {
+		if _, ok := active[key]; !ok {
+			delete(p.violations, key)
+		}
+	}
+}
+
+// send formats and writes event, reporting whether it was sent
+func (p *SyslogPlugin) send(event Event) bool {
+	if err := p.writer.Write(event.Severity, event.Time, p.formatter.Format(event)); err != nil {
+		p.log.Error("Failed to send syslog message", logger.Fields{
+			"signature": event.SignatureID,
+			"namespace": event.Fields[FieldNamespace],
+			"error":     err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func (p *SyslogPlugin) invalidPayload(expected string, payload any) {
+	p.log.Error("Invalid event payload type", logger.Fields{
+		"expected": expected,
+		"actual":   fmt.Sprintf("%T", payload),
+	})
+}
+
+func (p *SyslogPlugin) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+		}
+	}
+	if p.writer != nil {
+		return p.writer.Close()
+	}
+	return nil
+}
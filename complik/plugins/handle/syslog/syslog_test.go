@@ -0,0 +1,195 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/correlation"
+	"github.com/bearslyricattack/CompliK/complik/pkg/logger"
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSyslog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Syslog Handler Suite")
+}
+
+var _ = Describe("Syslog", func() {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	detection := &models.DetectorInfo{
+		DetectorName: "Safety",
+		Namespace:    "ns-alice",
+		Host:         "casino.example.com",
+		URL:          "https://casino.example.com/?a=b",
+		IsIllegal:    true,
+		Severity:     models.SeverityCritical,
+		Keywords:     []string{"casino", "usdt"},
+		Description:  "Online casino | bets",
+		Explanation:  "line one\nline two",
+	}
+
+	Describe("Formatter", func() {
+		It("should render CEF with escaped values and labelled custom strings", func() {
+			f, err := NewFormatter(FormatCEF, "CompliK", "CompliK", "1.0", nil)
+			Expect(err).NotTo(HaveOccurred())
+			msg := f.Format(FromDetector(detection, "hzh", now))
+			Expect(msg).To(HavePrefix("CEF:0|CompliK|CompliK|1.0|website:safety|Website violation|10|rt=1748779200000 "))
+			Expect(msg).To(ContainSubstring(`cs1=hzh cs1Label=region`))
+			Expect(msg).To(ContainSubstring(`cs4=casino,usdt cs4Label=keywords`))
+			Expect(msg).To(ContainSubstring(`request=https://casino.example.com/?a\=b`))
+			Expect(msg).To(ContainSubstring(`reason=line one\nline two`))
+			Expect(msg).To(ContainSubstring(`msg=Online casino | bets`))
+			Expect(msg).NotTo(ContainSubstring("\n"))
+		})
+
+		It("should apply field overrides", func() {
+			f, err := NewFormatter(FormatCEF, "CompliK", "CompliK", "1.0", map[string]string{
+				FieldNamespace: "duser", FieldExplanation: "",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			msg := f.Format(FromDetector(detection, "hzh", now))
+			Expect(msg).To(ContainSubstring("duser=ns-alice"))
+			Expect(msg).NotTo(ContainSubstring("cs2="))
+			Expect(msg).NotTo(ContainSubstring("reason="))
+
+			_, err = NewFormatter(FormatCEF, "", "", "", map[string]string{FieldHost: "bad key"})
+			Expect(err).To(MatchError(ContainSubstring("invalid key")))
+			_, err = NewFormatter("json", "", "", "", nil)
+			Expect(err).To(MatchError(ContainSubstring("unknown format")))
+		})
+
+		It("should render LEEF with tab separated attributes", func() {
+			f, err := NewFormatter(FormatLEEF, "CompliK", "CompliK", "1.0", nil)
+			Expect(err).NotTo(HaveOccurred())
+			msg := f.Format(FromProcessViolation(&correlation.ProcessViolation{
+				Pod: "miner-0", Namespace: "ns-bob", Process: "xmrig", Cmdline: "xmrig -o pool:3333",
+				Regex: "^xmrig$", Type: "app", Name: "miner", Timestamp: "2025-06-01T11:00:00Z",
+			}, "hzh", now))
+			header, attributes, _ := strings.Cut(msg, "|procscan:app|")
+			Expect(header).To(Equal("LEEF:1.0|CompliK|CompliK|1.0"))
+			Expect(strings.Split(attributes, "\t")).To(ContainElements(
+				"devTime=Jun 01 2025 11:00:00 UTC",
+				"sev=8",
+				"cat=Process violation (app)",
+				"proc=xmrig",
+				"cmdline=xmrig -o pool:3333",
+				"namespace=ns-bob",
+			))
+		})
+	})
+
+	Describe("Writer", func() {
+		It("should send RFC 5424 messages over UDP", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+			w, err := NewWriter(WriterConfig{
+				Network: "udp", Address: conn.LocalAddr().String(), Header: HeaderRFC5424,
+				Facility: "local0", Hostname: "complik-0", AppName: "complik", Timeout: time.Second,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer w.Close()
+			Expect(w.Write(models.SeverityCritical, now, "CEF:0|x")).To(Succeed())
+
+			buf := make([]byte, 1024)
+			Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+			n, _, err := conn.ReadFrom(buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf[:n])).To(Equal("<130>1 2025-06-01T12:00:00.000Z complik-0 complik - - - CEF:0|x"))
+		})
+
+		It("should frame TCP messages and reconnect after the server closed", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+			lines := make(chan string, 10)
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					line, _ := bufio.NewReader(conn).ReadString('\n')
+					lines <- line
+					conn.Close()
+				}
+			}()
+			w, err := NewWriter(WriterConfig{
+				Network: "tcp", Address: listener.Addr().String(), Header: HeaderRFC3164,
+				Facility: "auth", Hostname: "complik-0", AppName: "complik", Timeout: time.Second,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer w.Close()
+			Expect(w.Write(models.SeverityLow, now, "first")).To(Succeed())
+			Eventually(lines).Should(Receive(Equal("<37>Jun  1 12:00:00 complik-0 complik: first\n")))
+
+			// The server closed the first connection after one line
+			time.Sleep(50 * time.Millisecond)
+			Expect(w.Write(models.SeverityLow, now, "second")).To(Succeed())
+			Eventually(lines).Should(Receive(HaveSuffix("complik: second\n")))
+		})
+
+		It("should validate the configuration", func() {
+			_, err := NewWriter(WriterConfig{Network: "http", Header: HeaderRFC5424, Facility: "local0"})
+			Expect(err).To(MatchError(ContainSubstring("unknown network")))
+			_, err = NewWriter(WriterConfig{Network: "udp", Header: "rfc9999", Facility: "local0"})
+			Expect(err).To(MatchError(ContainSubstring("unknown header")))
+			_, err = NewWriter(WriterConfig{Network: "udp", Header: HeaderRFC5424, Facility: "local9"})
+			Expect(err).To(MatchError(ContainSubstring("unknown facility")))
+		})
+	})
+
+	Describe("SyslogPlugin", func() {
+		It("should send each procscan violation once while it is active", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+			p := &SyslogPlugin{log: logger.GetLogger(), violations: map[string]struct{}{}}
+			Expect(p.loadConfig(`{"address":"` + conn.LocalAddr().String() + `","format":"leef"}`)).To(Succeed())
+			defer p.writer.Close()
+
+			violation := &correlation.ProcessViolation{Pod: "miner-0", Namespace: "ns-bob", Process: "xmrig"}
+			received := func() int {
+				count := 0
+				buf := make([]byte, 2048)
+				for {
+					_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+					if _, _, err := conn.ReadFrom(buf); err != nil {
+						return count
+					}
+					count++
+				}
+			}
+			p.sendViolations([]*correlation.ProcessViolation{violation}, now)
+			p.sendViolations([]*correlation.ProcessViolation{violation}, now)
+			Expect(received()).To(Equal(1))
+			p.sendViolations(nil, now)
+			p.sendViolations([]*correlation.ProcessViolation{violation}, now)
+			Expect(received()).To(Equal(1))
+		})
+
+		It("should require an address", func() {
+			p := &SyslogPlugin{log: logger.GetLogger()}
+			Expect(p.loadConfig(`{"network":"tcp"}`)).To(MatchError(ContainSubstring("address")))
+		})
+	})
+})
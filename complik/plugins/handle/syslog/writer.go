@@ -0,0 +1,219 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/complik/pkg/models"
+)
+
+// Syslog header formats
+const (
+	HeaderRFC5424 = "rfc5424"
+	HeaderRFC3164 = "rfc3164"
+)
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "daemon": 3, "auth": 4, "syslog": 5, "authpriv": 10,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// WriterConfig is where and how syslog messages are sent
+type WriterConfig struct {
+	// Network is udp, tcp or tls
+	Network  string
+	Address  string
+	Header   string
+	Facility string
+	Hostname string
+	AppName  string
+	// OctetCounting frames TCP and TLS messages with their length (RFC 6587)
+	// instead of a trailing newline
+	OctetCounting bool
+	// CAFile verifies the server certificate of TLS connections
+	CAFile     string
+	ServerName string
+	Timeout    time.Duration
+}
+
+// Writer sends syslog messages, reconnecting after failures
+type Writer struct {
+	cfg       WriterConfig
+	facility  int
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewWriter validates cfg; the connection is opened by the first Write
+func NewWriter(cfg WriterConfig) (*Writer, error) {
+	w := &Writer{cfg: cfg}
+	switch cfg.Network {
+	case "udp", "tcp":
+	case "tls":
+		host, _, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", cfg.Address, err)
+		}
+		w.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		if cfg.ServerName != "" {
+			w.tlsConfig.ServerName = cfg.ServerName
+		}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates in CA file %s", cfg.CAFile)
+			}
+			w.tlsConfig.RootCAs = pool
+		}
+	default:
+		return nil, fmt.Errorf("unknown network %q, expected udp, tcp or tls", cfg.Network)
+	}
+	if cfg.Header != HeaderRFC5424 && cfg.Header != HeaderRFC3164 {
+		return nil, fmt.Errorf("unknown header %q, expected %s or %s", cfg.Header, HeaderRFC5424, HeaderRFC3164)
+	}
+	facility, ok := facilities[cfg.Facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown facility %q", cfg.Facility)
+	}
+	w.facility = facility
+	return w, nil
+}
+
+// Write sends msg with the syslog priority of severity. A failed send is
+// retried once on a new connection.
+func (w *Writer) Write(severity string, t time.Time, msg string) error {
+	frame := w.frame(severity, t, msg)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	for range 2 {
+		if err = w.send(frame); err == nil {
+			return nil
+		}
+		w.closeLocked()
+	}
+	return err
+}
+
+func (w *Writer) send(frame []byte) error {
+	if w.conn != nil && w.cfg.Network != "udp" && !w.alive() {
+		w.closeLocked()
+	}
+	if w.conn == nil {
+		dialer := &net.Dialer{Timeout: w.cfg.Timeout}
+		var err error
+		if w.tlsConfig != nil {
+			w.conn, err = tls.DialWithDialer(dialer, "tcp", w.cfg.Address, w.tlsConfig)
+		} else {
+			w.conn, err = dialer.Dial(w.cfg.Network, w.cfg.Address)
+		}
+		if err != nil {
+			w.conn = nil
+			return fmt.Errorf("failed to connect to %s: %w", w.cfg.Address, err)
+		}
+	}
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.cfg.Timeout)); err != nil {
+		return err
+	}
+	if _, err := w.conn.Write(frame); err != nil {
+		return fmt.Errorf("failed to send to %s: %w", w.cfg.Address, err)
+	}
+	return nil
+}
+
+// alive tells whether the server still has the stream connection open.
+// Writes to a connection the server closed succeed until the kernel notices,
+// losing the message, so the connection is checked for EOF first; syslog
+// servers never send anything.
+func (w *Writer) alive() bool {
+	if err := w.conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+		return false
+	}
+	var one [1]byte
+	_, err := w.conn.Read(one[:])
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// frame renders the syslog message of msg, framed for the transport
+func (w *Writer) frame(severity string, t time.Time, msg string) []byte {
+	pri := "<" + strconv.Itoa(w.facility*8+syslogSeverity(severity)) + ">"
+	var line string
+	if w.cfg.Header == HeaderRFC3164 {
+		line = pri + t.Format(time.Stamp) + " " + w.cfg.Hostname + " " + w.cfg.AppName + ": " + msg
+	} else {
+		line = pri + "1 " + t.UTC().Format("2006-01-02T15:04:05.000Z07:00") + " " +
+			w.cfg.Hostname + " " + w.cfg.AppName + " - - - " + msg
+	}
+	switch {
+	case w.cfg.Network == "udp":
+		return []byte(line)
+	case w.cfg.OctetCounting:
+		return []byte(strconv.Itoa(len(line)) + " " + line)
+	default:
+		return []byte(line + "\n")
+	}
+}
+
+// Close closes the connection
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeLocked()
+}
+
+func (w *Writer) closeLocked() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// syslogSeverity maps a CompliK severity to a syslog severity level
+func syslogSeverity(severity string) int {
+	switch severity {
+	case models.SeverityCritical:
+		return 2
+	case models.SeverityHigh:
+		return 3
+	case models.SeverityMedium:
+		return 4
+	case models.SeverityLow:
+		return 5
+	default:
+		return 6
+	}
+}
@@ -16,7 +16,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
@@ -25,7 +24,12 @@ import (
 	"syscall"
 
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/aggregator"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/api"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/debug"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/k8s"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/rules"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/signatures"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/triage"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/config"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
@@ -54,15 +58,54 @@ func main() {
 		logger.L.WithError(err).Fatal("Failed to create Kubernetes client")
 	}
 
+	// 加载违规处理状态
+	triageStore, err := triage.NewStore(cfg.Triage.StatePath)
+	if err != nil {
+		logger.L.WithError(err).Fatal("Failed to load triage state")
+	}
+
+	// 加载下发给 procscan 的规则
+	var ruleStore *rules.Store
+	if cfg.Rules.Path != "" {
+		ruleStore, err = rules.NewStore(cfg.Rules.Path)
+		if err != nil {
+			logger.L.WithError(err).Fatal("Failed to load rules")
+		}
+	}
+
 	// 创建聚合器
-	agg := aggregator.NewAggregator(cfg, k8sClient)
+	agg := aggregator.NewAggregator(cfg, k8sClient, triageStore)
 
 	// 创建 context 用于优雅关闭
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// 订阅特征库，合并到下发给 procscan 的规则中
+	var feed *signatures.Feed
+	if cfg.Signatures.URL != "" {
+		feed, err = signatures.NewFeed(cfg.Signatures)
+		if err != nil {
+			logger.L.WithError(err).Fatal("Failed to create signature feed")
+		}
+		if ruleStore != nil {
+			ruleStore.SetSignatures(feed)
+		} else {
+			logger.L.Warn("Signature feed configured without rules.path, signatures are only served by /api/signatures")
+		}
+		feed.Start(ctx)
+	}
+
 	// 启动 HTTP 服务器
-	go startHTTPServer(cfg, agg)
+	go startHTTPServer(cfg, agg, triageStore, ruleStore, feed)
+
+	// 启动调试接口
+	var debugServer *http.Server
+	if cfg.Debug.Addr != "" {
+		debugServer, err = debug.Start(cfg.Debug.Addr, os.ExpandEnv(cfg.Debug.Token))
+		if err != nil {
+			logger.L.WithError(err).Fatal("Failed to start debug server")
+		}
+	}
 
 	// 启动聚合器
 	go func() {
@@ -78,31 +121,33 @@ func main() {
 
 	logger.L.WithField("signal", sig.String()).Info("Received shutdown signal")
 	cancel()
+	if debugServer != nil {
+		_ = debugServer.Close()
+	}
 
 	logger.L.Info("ProcScan Aggregator stopped")
 }
 
 // startHTTPServer 启动 HTTP 服务器
-func startHTTPServer(cfg *models.Config, agg *aggregator.Aggregator) {
-	mux := http.NewServeMux()
-
-	// API: 获取聚合的违规记录
-	mux.HandleFunc("/api/violations", func(w http.ResponseWriter, r *http.Request) {
-		violations := agg.GetViolations()
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(violations)
-	})
-
-	// 健康检查
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-	})
+func startHTTPServer(
+	cfg *models.Config,
+	agg *aggregator.Aggregator,
+	triageStore *triage.Store,
+	ruleStore *rules.Store,
+	feed *signatures.Feed,
+) {
+	handler := api.NewHandler(agg, triageStore, os.ExpandEnv(cfg.Triage.Token))
+	if ruleStore != nil {
+		handler.SetRuleSource(ruleStore)
+	}
+	if feed != nil {
+		handler.SetSignatureSource(feed)
+	}
 
 	addr := fmt.Sprintf(":%d", cfg.Aggregator.Port)
 	logger.L.WithField("addr", addr).Info("HTTP server starting")
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
+	if err := http.ListenAndServe(addr, handler); err != nil {
 		logger.L.WithError(err).Fatal("HTTP server failed")
 	}
 }
@@ -0,0 +1,44 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// openapi 输出聚合器接口的 OpenAPI 定义，由 internal/api 中的 go generate 调用
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/api"
+)
+
+var output = flag.String("o", "", "输出文件路径，为空时输出到标准输出")
+
+func main() {
+	flag.Parse()
+
+	data, err := api.OpenAPIJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+}
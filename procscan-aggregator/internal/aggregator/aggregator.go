@@ -18,6 +18,7 @@ package aggregator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +27,8 @@ import (
 
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/crd"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/k8s"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/triage"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/webhook"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/config"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
@@ -37,18 +40,38 @@ type Aggregator struct {
 	config       *models.Config
 	k8sClient    *k8s.Client
 	crdGenerator *crd.Generator
+	crdWriter    *crd.ViolationWriter
+	webhooks     *webhook.Dispatcher
+	digests      *webhook.Digester
+	triage       *triage.Store
 	httpClient   *http.Client
 	ticker       *time.Ticker
 	violations   *models.AggregatedViolations
 	violationsMu sync.RWMutex
+
+	fullSyncInterval time.Duration
+	agents           map[string]*agentState // key 为 Pod IP
+	agentsMu         sync.Mutex
+
+	// webhooksPrimed 首次聚合完成后才分发 Webhook，避免重启后重复通知已有违规
+	webhooksPrimed bool
+}
+
+// agentState 记录单个 DaemonSet Pod 的违规状态，用于应用增量
+type agentState struct {
+	epoch    string
+	seq      uint64
+	records  map[string]*models.ViolationRecord
+	lastFull time.Time
 }
 
-// NewAggregator 创建新的聚合器
-func NewAggregator(config *models.Config, k8sClient *k8s.Client) *Aggregator {
+// NewAggregator 创建新的聚合器，triageStore 为 nil 时不清理处理状态
+func NewAggregator(config *models.Config, k8sClient *k8s.Client, triageStore *triage.Store) *Aggregator {
 	return &Aggregator{
 		config:       config,
 		k8sClient:    k8sClient,
 		crdGenerator: crd.NewGenerator(),
+		triage:       triageStore,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -56,6 +79,7 @@ func NewAggregator(config *models.Config, k8sClient *k8s.Client) *Aggregator {
 			Violations: make([]*models.ViolationRecord, 0),
 			UpdateTime: time.Now(),
 		},
+		agents: make(map[string]*agentState),
 	}
 }
 
@@ -67,7 +91,46 @@ func (a *Aggregator) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to parse scan interval: %w", err)
 	}
 
-	logger.L.WithField("interval", scanInterval).Info("Starting aggregator")
+	a.fullSyncInterval, err = config.GetFullSyncInterval(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to parse full sync interval: %w", err)
+	}
+
+	if a.config.CRD.Enabled {
+		ttl, err := config.GetCRDTTL(a.config)
+		if err != nil {
+			return fmt.Errorf("failed to parse crd ttl: %w", err)
+		}
+		a.crdWriter = crd.NewViolationWriter(a.k8sClient.Dynamic(), ttl)
+		logger.L.WithField("ttl", ttl).Info("ProcessViolation output enabled")
+	}
+
+	if len(a.config.Webhooks) > 0 {
+		a.webhooks, err = webhook.NewDispatcher(a.config.Webhooks)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook dispatcher: %w", err)
+		}
+		a.webhooks.Start(ctx)
+		logger.L.WithField("webhooks", len(a.config.Webhooks)).Info("Webhook fan-out enabled")
+	}
+
+	if len(a.config.Digests) > 0 {
+		var triageSource webhook.TriageSource
+		if a.triage != nil {
+			triageSource = a.triage
+		}
+		a.digests, err = webhook.NewDigester(a.config.Digests, a, triageSource)
+		if err != nil {
+			return fmt.Errorf("failed to create digest scheduler: %w", err)
+		}
+		a.digests.Start(ctx)
+		logger.L.WithField("digests", len(a.config.Digests)).Info("Scheduled digests enabled")
+	}
+
+	logger.L.WithFields(logrus.Fields{
+		"interval":           scanInterval,
+		"full_sync_interval": a.fullSyncInterval,
+	}).Info("Starting aggregator")
 
 	a.ticker = time.NewTicker(scanInterval)
 	defer a.ticker.Stop()
@@ -109,13 +172,15 @@ func (a *Aggregator) collectAndProcess(ctx context.Context) error {
 		return nil
 	}
 
-	// 2. 并发获取每个 Pod 的违规记录
-	violations := a.fetchViolationsFromPods(ctx, podIPs)
+	// 2. 并发获取每个 Pod 的违规记录，命中例外的记录不参与后续处理
+	violations, exceptions := splitExceptions(a.fetchViolationsFromPods(ctx, podIPs))
 
 	// 3. 更新聚合结果
 	a.violationsMu.Lock()
+	previous := a.violations.Violations
 	a.violations = &models.AggregatedViolations{
 		Violations: violations,
+		Exceptions: exceptions,
 		UpdateTime: time.Now(),
 		TotalCount: len(violations),
 	}
@@ -123,10 +188,37 @@ func (a *Aggregator) collectAndProcess(ctx context.Context) error {
 
 	logger.L.WithFields(logrus.Fields{
 		"total_violations": len(violations),
+		"excepted":         len(exceptions),
 		"pod_count":        len(podIPs),
 	}).Info("Violations collected successfully")
 
-	// 4. 生成和应用 CRD
+	// 4. 将违规变化分发到匹配的 Webhook，首次聚合只记录基线
+	if a.webhooks != nil {
+		if a.webhooksPrimed {
+			a.webhooks.Dispatch(webhook.Diff(previous, violations, time.Now()))
+		}
+		a.webhooksPrimed = true
+	}
+
+	// 5. 清理已清除违规的确认和指派状态，有 Pod 尚未同步成功时跳过，避免误删
+	if a.triage != nil && a.allAgentsSynced() {
+		active := make(map[string]struct{}, len(violations))
+		for _, violation := range violations {
+			active[violation.ID()] = struct{}{}
+		}
+		if err := a.triage.Prune(active); err != nil {
+			logger.L.WithError(err).Error("Failed to prune triage state")
+		}
+	}
+
+	// 6. 同步 ProcessViolation 资源，违规为空时也需要执行以清除过期资源
+	if a.crdWriter != nil {
+		if err := a.crdWriter.Sync(ctx, violations, time.Now()); err != nil {
+			logger.L.WithError(err).Error("Failed to sync ProcessViolation resources")
+		}
+	}
+
+	// 7. 生成和应用 CRD
 	if len(violations) > 0 {
 		if err := a.generateAndApplyCRDs(ctx, violations); err != nil {
 			logger.L.WithError(err).Error("Failed to generate and apply CRDs")
@@ -136,45 +228,193 @@ func (a *Aggregator) collectAndProcess(ctx context.Context) error {
 	return nil
 }
 
-// fetchViolationsFromPods 从所有 Pod 获取违规记录
+// fetchViolationsFromPods 从所有 Pod 拉取违规记录变化，并返回合并后的完整状态
 func (a *Aggregator) fetchViolationsFromPods(ctx context.Context, podIPs []string) []*models.ViolationRecord {
-	var (
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		violations []*models.ViolationRecord
-	)
+	var wg sync.WaitGroup
+
+	a.agentsMu.Lock()
+	active := make(map[string]struct{}, len(podIPs))
+	for _, ip := range podIPs {
+		active[ip] = struct{}{}
+		if _, ok := a.agents[ip]; !ok {
+			a.agents[ip] = &agentState{records: make(map[string]*models.ViolationRecord)}
+		}
+	}
+	// 清理已经不存在的 Pod 的状态
+	for ip := range a.agents {
+		if _, ok := active[ip]; !ok {
+			delete(a.agents, ip)
+		}
+	}
+	a.agentsMu.Unlock()
 
 	for _, ip := range podIPs {
 		wg.Add(1)
 		go func(podIP string) {
 			defer wg.Done()
 
-			records, err := a.fetchViolationsFromPod(ctx, podIP)
-			if err != nil {
+			a.agentsMu.Lock()
+			state := a.agents[podIP]
+			a.agentsMu.Unlock()
+
+			if err := a.syncAgent(ctx, podIP, state); err != nil {
 				logger.L.WithFields(logrus.Fields{
 					"pod_ip": podIP,
 					"error":  err.Error(),
 				}).Warn("Failed to fetch violations from pod")
-				return
-			}
-
-			if len(records) > 0 {
-				mu.Lock()
-				violations = append(violations, records...)
-				mu.Unlock()
-
-				logger.L.WithFields(logrus.Fields{
-					"pod_ip":          podIP,
-					"violation_count": len(records),
-				}).Debug("Fetched violations from pod")
 			}
 		}(ip)
 	}
 
 	wg.Wait()
+
+	a.agentsMu.Lock()
+	defer a.agentsMu.Unlock()
+	var violations []*models.ViolationRecord
+	for _, state := range a.agents {
+		for _, record := range state.records {
+			violations = append(violations, record)
+		}
+	}
 	return violations
 }
 
+// splitExceptions 将命中例外的记录从违规中分离出来
+func splitExceptions(records []*models.ViolationRecord) (violations, exceptions []*models.ViolationRecord) {
+	for _, record := range records {
+		if record.Status == models.StatusExcepted {
+			exceptions = append(exceptions, record)
+		} else {
+			violations = append(violations, record)
+		}
+	}
+	return violations, exceptions
+}
+
+// allAgentsSynced 判断所有 Pod 是否都至少完成过一次全量同步
+func (a *Aggregator) allAgentsSynced() bool {
+	a.agentsMu.Lock()
+	defer a.agentsMu.Unlock()
+	for _, state := range a.agents {
+		if state.lastFull.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// syncAgent 拉取单个 Pod 自上次同步以来的变化并应用到 state
+// 到达全量对账间隔时请求全量状态；Pod 不支持增量接口时回退到全量接口
+func (a *Aggregator) syncAgent(ctx context.Context, podIP string, state *agentState) error {
+	now := time.Now()
+	since := state.seq
+	if state.lastFull.IsZero() || now.Sub(state.lastFull) >= a.fullSyncInterval {
+		since = 0
+	}
+
+	delta, err := a.fetchDeltaFromPod(ctx, podIP, since)
+	if errors.Is(err, errDeltaUnsupported) {
+		records, err := a.fetchViolationsFromPod(ctx, podIP)
+		if err != nil {
+			return err
+		}
+		delta = &models.ViolationDelta{Full: true, Violations: records}
+	} else if err != nil {
+		return err
+	}
+	// Pod IP 在 procscan 重启后不变而 seq 从头计数，epoch 变化时增量基于另一份快照，改为请求全量状态
+	if !delta.Full && delta.Epoch != state.epoch {
+		logger.L.WithFields(logrus.Fields{
+			"pod_ip": podIP,
+			"epoch":  delta.Epoch,
+		}).Info("Agent restarted, requesting full state")
+		delta, err = a.fetchDeltaFromPod(ctx, podIP, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 记录上报节点，供接口按节点过滤
+	for _, records := range [][]*models.ViolationRecord{delta.Violations, delta.Added, delta.Changed} {
+		for _, record := range records {
+			record.Node = delta.Node
+		}
+	}
+
+	if delta.Full {
+		state.records = make(map[string]*models.ViolationRecord, len(delta.Violations))
+		for _, record := range delta.Violations {
+			state.records[record.Key()] = record
+		}
+		state.lastFull = now
+	} else {
+		for _, record := range delta.Added {
+			state.records[record.Key()] = record
+		}
+		for _, record := range delta.Changed {
+			state.records[record.Key()] = record
+		}
+		for _, record := range delta.Cleared {
+			delete(state.records, record.Key())
+		}
+	}
+	state.epoch = delta.Epoch
+	state.seq = delta.Seq
+
+	logger.L.WithFields(logrus.Fields{
+		"pod_ip":          podIP,
+		"epoch":           delta.Epoch,
+		"seq":             delta.Seq,
+		"full":            delta.Full,
+		"added":           len(delta.Added),
+		"changed":         len(delta.Changed),
+		"cleared":         len(delta.Cleared),
+		"violation_count": len(state.records),
+	}).Debug("Synced violations from pod")
+	return nil
+}
+
+// errDeltaUnsupported 表示 Pod 运行的 procscan 版本没有增量接口
+var errDeltaUnsupported = errors.New("delta api not supported")
+
+// fetchDeltaFromPod 从单个 Pod 获取自 since 以来的违规记录变化
+func (a *Aggregator) fetchDeltaFromPod(ctx context.Context, podIP string, since uint64) (*models.ViolationDelta, error) {
+	if a.config.DaemonSet.DeltaPath == "" {
+		return nil, errDeltaUnsupported
+	}
+	url := fmt.Sprintf("http://%s:%d%s?since=%d",
+		podIP,
+		a.config.DaemonSet.APIPort,
+		a.config.DaemonSet.DeltaPath,
+		since,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errDeltaUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	var delta models.ViolationDelta
+	if err := json.NewDecoder(resp.Body).Decode(&delta); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &delta, nil
+}
+
 // fetchViolationsFromPod 从单个 Pod 获取违规记录
 func (a *Aggregator) fetchViolationsFromPod(ctx context.Context, podIP string) ([]*models.ViolationRecord, error) {
 	url := fmt.Sprintf("http://%s:%d%s",
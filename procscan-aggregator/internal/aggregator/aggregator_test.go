@@ -0,0 +1,103 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// fakeAgent 模拟 procscan 的增量接口，seq 固定为 1，since 为 0 时返回全量状态
+type fakeAgent struct {
+	epoch   string
+	records []*models.ViolationRecord
+	since   []uint64
+}
+
+func (f *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+	f.since = append(f.since, since)
+	delta := models.ViolationDelta{Node: "node-1", Epoch: f.epoch, Seq: 1, Since: since}
+	if since == 0 {
+		delta.Full = true
+		delta.Violations = f.records
+	}
+	_ = json.NewEncoder(w).Encode(delta)
+}
+
+func newTestAggregator(t *testing.T, agent *fakeAgent) (*Aggregator, string) {
+	t.Helper()
+	server := httptest.NewServer(agent)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to parse server address: %v", err)
+	}
+	apiPort, _ := strconv.Atoi(port)
+	a := NewAggregator(&models.Config{
+		DaemonSet: models.DaemonSetConfig{APIPort: apiPort, APIPath: "/violations", DeltaPath: "/violations/delta"},
+	}, nil, nil)
+	a.fullSyncInterval = time.Hour
+	return a, host
+}
+
+// TestSyncAgentRequestsFullStateAfterRestart 测试 procscan 重启后 seq 重新计数时聚合器改为请求全量状态
+func TestSyncAgentRequestsFullStateAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	agent := &fakeAgent{
+		epoch:   "a",
+		records: []*models.ViolationRecord{{Namespace: "ns-a", Pod: "p1", Process: "xmrig"}},
+	}
+	a, podIP := newTestAggregator(t, agent)
+	state := &agentState{records: make(map[string]*models.ViolationRecord)}
+
+	if err := a.syncAgent(ctx, podIP, state); err != nil {
+		t.Fatalf("Failed to sync agent: %v", err)
+	}
+	if err := a.syncAgent(ctx, podIP, state); err != nil {
+		t.Fatalf("Failed to sync agent: %v", err)
+	}
+	if !slices.Equal(agent.since, []uint64{0, 1}) {
+		t.Fatalf("Expected an incremental sync since 1, got %v", agent.since)
+	}
+	if state.epoch != "a" || len(state.records) != 1 {
+		t.Fatalf("Unexpected state after incremental sync: epoch %q, %d records", state.epoch, len(state.records))
+	}
+
+	// 重启后的 procscan 再次到达 seq 1，但快照已不同
+	agent.epoch = "b"
+	agent.records = []*models.ViolationRecord{{Namespace: "ns-b", Pod: "p2", Process: "nc"}}
+	if err := a.syncAgent(ctx, podIP, state); err != nil {
+		t.Fatalf("Failed to sync agent: %v", err)
+	}
+	if !slices.Equal(agent.since, []uint64{0, 1, 1, 0}) {
+		t.Fatalf("Expected a full sync after the restart, got requests %v", agent.since)
+	}
+	if state.epoch != "b" {
+		t.Errorf("Expected epoch b, got %q", state.epoch)
+	}
+	if _, ok := state.records["ns-b/p2/nc"]; !ok || len(state.records) != 1 {
+		t.Errorf("Expected only the violation of the restarted agent, got %v", state.records)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package api 提供聚合器的 HTTP 接口，包括违规查询以及确认和指派操作
+//
+// 接口定义见 OpenAPISpec，生成的文件位于 api/openapi.json，修改接口后执行 go generate 更新
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/signatures"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/triage"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// ViolationSource 提供当前聚合的违规记录
+type ViolationSource interface {
+	GetViolations() *models.AggregatedViolations
+}
+
+// RuleSource 提供下发给节点的检测规则
+type RuleSource interface {
+	ForNode(node string) (*models.DistributedRules, error)
+}
+
+// SignatureSource 特征库订阅，提供状态查询、回滚和固定版本
+type SignatureSource interface {
+	Status() *models.SignatureStatus
+	Rollback() (*models.SignatureSet, error)
+	Pin(version string) error
+}
+
+// identityHeader 未在请求体中指定操作人时，使用认证代理注入的用户名
+const identityHeader = "X-Forwarded-User"
+
+// Handler 聚合器 HTTP 接口
+type Handler struct {
+	source ViolationSource
+	store  *triage.Store
+	rules  RuleSource
+	sigs   SignatureSource
+	token  string
+	mux    *http.ServeMux
+	spec   []byte
+	now    func() time.Time
+}
+
+// NewHandler 创建 HTTP 接口，确认、指派和特征库操作接口需要携带 Bearer Token，token 为空时拒绝这些请求
+func NewHandler(source ViolationSource, store *triage.Store, token string) *Handler {
+	h := &Handler{
+		source: source,
+		store:  store,
+		token:  token,
+		mux:    http.NewServeMux(),
+		now:    time.Now,
+	}
+	// 接口定义只包含固定的类型，编码不会失败
+	h.spec, _ = OpenAPIJSON()
+	h.mux.HandleFunc("GET /api/violations", h.listViolations)
+	h.mux.HandleFunc("GET /api/violations/{id}", h.getViolation)
+	h.mux.HandleFunc("GET /api/exceptions", h.listExceptions)
+	h.mux.HandleFunc("GET /api/rules", h.getRules)
+	h.mux.HandleFunc("GET /api/signatures", h.getSignatures)
+	h.mux.HandleFunc("POST /api/signatures/rollback", h.requireToken(h.rollbackSignatures))
+	h.mux.HandleFunc("POST /api/signatures/pin", h.requireToken(h.pinSignatures))
+	h.mux.HandleFunc("POST /api/violations/{id}/ack", h.requireToken(h.acknowledge))
+	h.mux.HandleFunc("POST /api/violations/{id}/assign", h.requireToken(h.assign))
+	h.mux.HandleFunc("GET /api/openapi.json", h.openAPI)
+	h.mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	// 未知路径也返回统一的错误格式
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "route not found")
+	})
+	return h
+}
+
+// SetRuleSource 启用规则下发，未设置时规则接口返回 503
+func (h *Handler) SetRuleSource(rules RuleSource) {
+	h.rules = rules
+}
+
+// SetSignatureSource 启用特征库接口，未设置时特征库接口返回 503
+func (h *Handler) SetSignatureSource(sigs SignatureSource) {
+	h.sigs = sigs
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// listViolations 默认隐藏已确认的违规，include_acknowledged=true 时返回全部，
+// 其余过滤和分页参数见 parseListQuery。记录按 namespace/pod/process 排序，保证分页稳定
+func (h *Handler) listViolations(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r.URL.Query(), h.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	aggregated := h.source.GetViolations()
+	list := &models.ViolationList{
+		Violations:    make([]*models.ViolationView, 0),
+		UpdateTime:    aggregated.UpdateTime,
+		ExceptedCount: len(aggregated.Exceptions),
+		Offset:        query.offset,
+	}
+	var matched []*models.ViolationView
+	for _, record := range aggregated.Violations {
+		view := h.view(record)
+		acknowledged := view.Triage != nil && view.Triage.Acknowledged
+		if acknowledged {
+			list.AcknowledgedCount++
+		}
+		if acknowledged && !query.includeAcknowledged {
+			continue
+		}
+		if query.matches(view) {
+			matched = append(matched, view)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Key() < matched[j].Key()
+	})
+
+	list.MatchedCount = len(matched)
+	if query.offset < len(matched) {
+		end := len(matched)
+		if query.limit > 0 && query.offset+query.limit < end {
+			end = query.offset + query.limit
+			list.NextOffset = &end
+		}
+		list.Violations = append(list.Violations, matched[query.offset:end]...)
+	}
+	list.TotalCount = len(list.Violations)
+	writeJSON(w, http.StatusOK, list)
+}
+
+// listExceptions 返回命中 procscan 例外的记录，最早过期的排在前面
+func (h *Handler) listExceptions(w http.ResponseWriter, r *http.Request) {
+	aggregated := h.source.GetViolations()
+	list := &models.ExceptionList{
+		Exceptions: make([]*models.ViolationView, 0, len(aggregated.Exceptions)),
+		UpdateTime: aggregated.UpdateTime,
+		TotalCount: len(aggregated.Exceptions),
+	}
+	for _, record := range aggregated.Exceptions {
+		list.Exceptions = append(list.Exceptions, &models.ViolationView{ID: record.ID(), ViolationRecord: record})
+	}
+	sort.Slice(list.Exceptions, func(i, j int) bool {
+		a, b := list.Exceptions[i], list.Exceptions[j]
+		if a.Exception != nil && b.Exception != nil && !a.Exception.Expires.Equal(b.Exception.Expires) {
+			return a.Exception.Expires.Before(b.Exception.Expires)
+		}
+		return a.Key() < b.Key()
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+// getRules 返回下发给 node 参数指定节点的规则，ETag 为规则版本，
+// If-None-Match 与当前版本一致时返回 304，procscan 据此跳过未变化的规则
+func (h *Handler) getRules(w http.ResponseWriter, r *http.Request) {
+	if h.rules == nil {
+		writeError(w, http.StatusServiceUnavailable, "rule distribution is not enabled")
+		return
+	}
+	node := r.URL.Query().Get("node")
+	rules, err := h.rules.ForNode(node)
+	if err != nil {
+		logger.L.WithFields(logrus.Fields{
+			"node":  node,
+			"error": err.Error(),
+		}).Error("Failed to get rules")
+		writeError(w, http.StatusInternalServerError, "failed to get rules")
+		return
+	}
+	etag := strconv.Quote(rules.Version)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// getSignatures 返回特征库订阅状态，包括各版本的矿池域名，供 procscan 以外的检测使用
+func (h *Handler) getSignatures(w http.ResponseWriter, r *http.Request) {
+	if h.sigs == nil {
+		writeError(w, http.StatusServiceUnavailable, "signature feed is not enabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.sigs.Status())
+}
+
+// rollbackSignatures 固定到当前版本的上一个版本
+func (h *Handler) rollbackSignatures(w http.ResponseWriter, r *http.Request) {
+	if h.sigs == nil {
+		writeError(w, http.StatusServiceUnavailable, "signature feed is not enabled")
+		return
+	}
+	set, err := h.sigs.Rollback()
+	if err != nil {
+		h.signatureError(w, err)
+		return
+	}
+	logger.L.WithFields(logrus.Fields{
+		"version": set.Version,
+		"by":      r.Header.Get(identityHeader),
+	}).Info("Signatures rolled back")
+	writeJSON(w, http.StatusOK, h.sigs.Status())
+}
+
+// pinSignatures 固定特征库版本，version 为空时恢复使用最新版本
+func (h *Handler) pinSignatures(w http.ResponseWriter, r *http.Request) {
+	if h.sigs == nil {
+		writeError(w, http.StatusServiceUnavailable, "signature feed is not enabled")
+		return
+	}
+	var req models.SignaturePinRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if err := h.sigs.Pin(strings.TrimSpace(req.Version)); err != nil {
+		h.signatureError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.sigs.Status())
+}
+
+func (h *Handler) signatureError(w http.ResponseWriter, err error) {
+	if errors.Is(err, signatures.ErrUnknownVersion) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	logger.L.WithError(err).Error("Failed to update signature state")
+	writeError(w, http.StatusInternalServerError, "failed to update signature state")
+}
+
+func (h *Handler) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.spec)
+}
+
+func (h *Handler) getViolation(w http.ResponseWriter, r *http.Request) {
+	record := h.find(r.PathValue("id"))
+	if record == nil {
+		writeError(w, http.StatusNotFound, "violation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.view(record))
+}
+
+func (h *Handler) acknowledge(w http.ResponseWriter, r *http.Request) {
+	h.triage(w, r, func(id string, req models.TriageRequest) (*models.TriageState, error) {
+		return h.store.Acknowledge(id, req.By, req.Note, h.now())
+	})
+}
+
+func (h *Handler) assign(w http.ResponseWriter, r *http.Request) {
+	h.triage(w, r, func(id string, req models.TriageRequest) (*models.TriageState, error) {
+		if req.Assignee == "" {
+			return nil, errMissingAssignee
+		}
+		return h.store.Assign(id, req.Assignee, req.By, req.Note, h.now())
+	})
+}
+
+type triageError string
+
+func (e triageError) Error() string { return string(e) }
+
+const errMissingAssignee = triageError("assignee is required")
+
+// triage 解析请求并对存在的违规执行操作，返回更新后的违规记录
+func (h *Handler) triage(
+	w http.ResponseWriter,
+	r *http.Request,
+	apply func(id string, req models.TriageRequest) (*models.TriageState, error),
+) {
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "triage is not enabled")
+		return
+	}
+	id := r.PathValue("id")
+	record := h.find(id)
+	if record == nil {
+		writeError(w, http.StatusNotFound, "violation not found")
+		return
+	}
+
+	var req models.TriageRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	req.By = strings.TrimSpace(req.By)
+	if req.By == "" {
+		req.By = r.Header.Get(identityHeader)
+	}
+	if req.By == "" {
+		writeError(w, http.StatusBadRequest, "by is required")
+		return
+	}
+
+	state, err := apply(id, req)
+	if err != nil {
+		if _, ok := err.(triageError); ok {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.L.WithFields(logrus.Fields{
+			"id":    id,
+			"error": err.Error(),
+		}).Error("Failed to update triage state")
+		writeError(w, http.StatusInternalServerError, "failed to update triage state")
+		return
+	}
+
+	logger.L.WithFields(logrus.Fields{
+		"id":        id,
+		"namespace": record.Namespace,
+		"pod":       record.Pod,
+		"process":   record.Process,
+		"by":        req.By,
+		"assignee":  state.Assignee,
+		"path":      r.URL.Path,
+	}).Info("Violation triage updated")
+	writeJSON(w, http.StatusOK, &models.ViolationView{ID: id, ViolationRecord: record, Triage: state})
+}
+
+func (h *Handler) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 未配置 Token 时拒绝所有写请求
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *Handler) find(id string) *models.ViolationRecord {
+	for _, record := range h.source.GetViolations().Violations {
+		if record.ID() == id {
+			return record
+		}
+	}
+	return nil
+}
+
+func (h *Handler) view(record *models.ViolationRecord) *models.ViolationView {
+	view := &models.ViolationView{ID: record.ID(), ViolationRecord: record}
+	if h.store != nil {
+		view.Triage = h.store.Get(view.ID)
+	}
+	return view
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError 按状态码返回统一的错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	code := models.ErrorCodeInternal
+	switch status {
+	case http.StatusBadRequest:
+		code = models.ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		code = models.ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		code = models.ErrorCodeNotFound
+	case http.StatusServiceUnavailable:
+		code = models.ErrorCodeUnavailable
+	}
+	writeJSON(w, status, &models.ErrorResponse{Error: models.APIError{Code: code, Message: message}})
+}
@@ -0,0 +1,490 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/signatures"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/triage"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+type staticSource struct {
+	violations []*models.ViolationRecord
+	exceptions []*models.ViolationRecord
+}
+
+func (s *staticSource) GetViolations() *models.AggregatedViolations {
+	return &models.AggregatedViolations{
+		Violations: s.violations,
+		Exceptions: s.exceptions,
+		UpdateTime: time.Now(),
+		TotalCount: len(s.violations),
+	}
+}
+
+func newTestHandler(t *testing.T, token string) (*Handler, []*models.ViolationRecord) {
+	t.Helper()
+	violations := []*models.ViolationRecord{
+		{Namespace: "ns-a", Pod: "p1", Process: "xmrig"},
+		{Namespace: "ns-b", Pod: "p2", Process: "nc"},
+	}
+	store, err := triage.NewStore("")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	return NewHandler(&staticSource{violations: violations}, store, token), violations
+}
+
+func serve(h http.Handler, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeList(t *testing.T, rec *httptest.ResponseRecorder) *models.ViolationList {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list models.ViolationList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	return &list
+}
+
+func TestAcknowledgeHidesViolationFromDefaultList(t *testing.T) {
+	h, violations := newTestHandler(t, "secret")
+	id := violations[0].ID()
+
+	rec := serve(h, http.MethodPost, "/api/violations/"+id+"/ack", `{"by":"alice","note":"known batch job"}`,
+		map[string]string{"Authorization": "Bearer secret"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var view models.ViolationView
+	if err := json.Unmarshal(rec.Body.Bytes(), &view); err != nil {
+		t.Fatalf("Failed to decode view: %v", err)
+	}
+	if view.ID != id || view.Pod != "p1" || view.Triage == nil || view.Triage.AcknowledgedBy != "alice" {
+		t.Errorf("Unexpected ack response: %s", rec.Body.String())
+	}
+
+	list := decodeList(t, serve(h, http.MethodGet, "/api/violations", "", nil))
+	if list.TotalCount != 1 || list.AcknowledgedCount != 1 || list.Violations[0].Pod != "p2" {
+		t.Errorf("Expected only the unacknowledged violation, got %+v", list)
+	}
+
+	list = decodeList(t, serve(h, http.MethodGet, "/api/violations?include_acknowledged=true", "", nil))
+	if list.TotalCount != 2 {
+		t.Errorf("Expected all violations, got %d", list.TotalCount)
+	}
+}
+
+func TestAssignFiltersByAssignee(t *testing.T) {
+	h, violations := newTestHandler(t, "secret")
+	id := violations[1].ID()
+
+	rec := serve(h, http.MethodPost, "/api/violations/"+id+"/assign", `{"assignee":"carol"}`,
+		map[string]string{identityHeader: "bob", "Authorization": "Bearer secret"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	list := decodeList(t, serve(h, http.MethodGet, "/api/violations?assignee=carol", "", nil))
+	if list.TotalCount != 1 || list.Violations[0].ID != id {
+		t.Fatalf("Expected the assigned violation, got %+v", list)
+	}
+	if got := list.Violations[0].Triage; got.AssignedBy != "bob" || got.Acknowledged {
+		t.Errorf("Unexpected triage state: %+v", got)
+	}
+
+	rec = serve(h, http.MethodGet, "/api/violations/"+id, "", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"assignee":"carol"`) {
+		t.Errorf("Expected single violation with assignee, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTriageRejectsInvalidRequests(t *testing.T) {
+	h, violations := newTestHandler(t, "secret")
+	id := violations[0].ID()
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{"unknown violation", "/api/violations/pv-unknown/ack", `{"by":"alice"}`, http.StatusNotFound},
+		{"missing identity", "/api/violations/" + id + "/ack", `{"note":"x"}`, http.StatusBadRequest},
+		{"invalid body", "/api/violations/" + id + "/ack", `{`, http.StatusBadRequest},
+		{"missing assignee", "/api/violations/" + id + "/assign", `{"by":"alice"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodPost, tt.target, tt.body, map[string]string{"Authorization": "Bearer secret"})
+			if rec.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTriageRequiresToken(t *testing.T) {
+	h, violations := newTestHandler(t, "secret")
+	target := "/api/violations/" + violations[0].ID() + "/ack"
+
+	if rec := serve(h, http.MethodPost, target, `{"by":"alice"}`, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, target, `{"by":"alice"}`,
+		map[string]string{"Authorization": "Bearer wrong"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong token, got %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, target, `{"by":"alice"}`,
+		map[string]string{"Authorization": "Bearer secret"}); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// 查询接口不需要 Token
+	if rec := serve(h, http.MethodGet, "/api/violations", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected list to be readable without token, got %d", rec.Code)
+	}
+}
+
+func TestTriageRefusedWithoutConfiguredToken(t *testing.T) {
+	h, violations := newTestHandler(t, "")
+	id := violations[0].ID()
+	auth := map[string]string{"Authorization": "Bearer "}
+
+	for _, target := range []string{"/api/violations/" + id + "/ack", "/api/violations/" + id + "/assign"} {
+		if rec := serve(h, http.MethodPost, target, `{"by":"alice","assignee":"alice"}`, auth); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for %s without a configured token, got %d", target, rec.Code)
+		}
+	}
+	list := decodeList(t, serve(h, http.MethodGet, "/api/violations", "", nil))
+	if list.TotalCount != 2 || list.AcknowledgedCount != 0 {
+		t.Errorf("Expected both violations to stay unacknowledged, got %+v", list)
+	}
+}
+
+func TestListFiltersAndPaginates(t *testing.T) {
+	now := time.Date(2025, 12, 22, 12, 0, 0, 0, time.UTC)
+	source := &staticSource{}
+	for i, ns := range []string{"ns-c", "ns-a", "ns-b", "ns-a", "ns-a"} {
+		source.violations = append(source.violations, &models.ViolationRecord{
+			Namespace: ns,
+			Pod:       fmt.Sprintf("p%d", i),
+			Process:   []string{"xmrig", "nc"}[i%2],
+			Node:      []string{"node-1", "node-2"}[i%2],
+			Timestamp: now.Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
+		})
+	}
+	h := NewHandler(source, nil, "")
+	h.now = func() time.Time { return now }
+
+	tests := []struct {
+		query   string
+		pods    []string
+		matched int
+		next    int // 0 表示没有下一页
+	}{
+		{"", []string{"p1", "p3", "p4", "p2", "p0"}, 5, 0},
+		{"?limit=2", []string{"p1", "p3"}, 5, 2},
+		{"?limit=2&offset=4", []string{"p0"}, 5, 0},
+		{"?offset=10", []string{}, 5, 0},
+		{"?namespace=ns-a", []string{"p1", "p3", "p4"}, 3, 0},
+		{"?node=node-2&process=nc", []string{"p1", "p3"}, 2, 0},
+		{"?since=2h30m", []string{"p1", "p2", "p0"}, 3, 0},
+		{"?since=2025-12-22T09:00:00Z&namespace=ns-a", []string{"p1", "p3"}, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			list := decodeList(t, serve(h, http.MethodGet, "/api/violations"+tt.query, "", nil))
+			pods := make([]string, 0, len(list.Violations))
+			for _, view := range list.Violations {
+				pods = append(pods, view.Pod)
+			}
+			if strings.Join(pods, ",") != strings.Join(tt.pods, ",") {
+				t.Errorf("Expected pods %v, got %v", tt.pods, pods)
+			}
+			if list.MatchedCount != tt.matched || list.TotalCount != len(tt.pods) {
+				t.Errorf("Expected %d matched and %d returned, got %d and %d",
+					tt.matched, len(tt.pods), list.MatchedCount, list.TotalCount)
+			}
+			if (tt.next == 0) != (list.NextOffset == nil) || (list.NextOffset != nil && *list.NextOffset != tt.next) {
+				t.Errorf("Expected next offset %d, got %v", tt.next, list.NextOffset)
+			}
+		})
+	}
+}
+
+func TestListExceptionsSortsByExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	excepted := func(pod string, expires time.Time) *models.ViolationRecord {
+		return &models.ViolationRecord{
+			Namespace: "ns-lab", Pod: pod, Process: "xmrig", Status: models.StatusExcepted,
+			Exception: &models.Exception{Namespace: "ns-lab", Process: "^xmrig$", Expires: expires, Justification: "Benchmark"},
+		}
+	}
+	source := &staticSource{
+		violations: []*models.ViolationRecord{{Namespace: "ns-a", Pod: "p1", Process: "xmrig"}},
+		exceptions: []*models.ViolationRecord{excepted("p2", now.Add(48*time.Hour)), excepted("p3", now.Add(time.Hour))},
+	}
+	h := NewHandler(source, nil, "")
+
+	rec := serve(h, http.MethodGet, "/api/exceptions", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list models.ExceptionList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode exceptions: %v", err)
+	}
+	if list.TotalCount != 2 || list.Exceptions[0].Pod != "p3" || list.Exceptions[1].Pod != "p2" {
+		t.Errorf("Expected exceptions sorted by expiry, got %s", rec.Body.String())
+	}
+	if got := list.Exceptions[0]; got.ID == "" || got.Exception.Justification != "Benchmark" {
+		t.Errorf("Unexpected exception view: %+v", got)
+	}
+
+	violations := decodeList(t, serve(h, http.MethodGet, "/api/violations", "", nil))
+	if violations.TotalCount != 1 || violations.ExceptedCount != 2 {
+		t.Errorf("Expected one violation and two excepted records, got %+v", violations)
+	}
+}
+
+type staticRules map[string]*models.DistributedRules
+
+func (s staticRules) ForNode(node string) (*models.DistributedRules, error) {
+	if rules, ok := s[node]; ok {
+		return rules, nil
+	}
+	return s[""], nil
+}
+
+func TestGetRulesSupportsConditionalRequests(t *testing.T) {
+	h, _ := newTestHandler(t, "")
+	if rec := serve(h, http.MethodGet, "/api/rules?node=node-1", "", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a rule source, got %d", rec.Code)
+	}
+
+	h.SetRuleSource(staticRules{
+		"":       {Version: "v1"},
+		"node-2": {Version: "v2", Canary: true},
+	})
+	rec := serve(h, http.MethodGet, "/api/rules?node=node-2", "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"v2"` {
+		t.Fatalf("Expected 200 with the canary ETag, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	var rules models.DistributedRules
+	if err := json.Unmarshal(rec.Body.Bytes(), &rules); err != nil {
+		t.Fatalf("Failed to decode rules: %v", err)
+	}
+	if rules.Version != "v2" || !rules.Canary {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+
+	rec = serve(h, http.MethodGet, "/api/rules?node=node-2", "", map[string]string{"If-None-Match": `"v2"`})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected 304 for an unchanged version, got %d", rec.Code)
+	}
+	rec = serve(h, http.MethodGet, "/api/rules?node=node-1", "", map[string]string{"If-None-Match": `"v2"`})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"v1"` {
+		t.Errorf("Expected the stable rules for node-1, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+// staticSignatures 内存中的特征库版本，versions 按发布时间倒序
+type staticSignatures struct {
+	versions []string
+	pinned   string
+}
+
+func (s *staticSignatures) Status() *models.SignatureStatus {
+	status := &models.SignatureStatus{Pinned: s.pinned, Active: s.pinned}
+	for _, version := range s.versions {
+		status.Versions = append(status.Versions, &models.SignatureSet{Version: version})
+	}
+	if status.Active == "" && len(s.versions) > 0 {
+		status.Active = s.versions[0]
+	}
+	return status
+}
+
+func (s *staticSignatures) Rollback() (*models.SignatureSet, error) {
+	if len(s.versions) < 2 {
+		return nil, signatures.ErrUnknownVersion
+	}
+	s.pinned = s.versions[1]
+	return &models.SignatureSet{Version: s.pinned}, nil
+}
+
+func (s *staticSignatures) Pin(version string) error {
+	for _, v := range s.versions {
+		if v == version || version == "" {
+			s.pinned = version
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", signatures.ErrUnknownVersion, version)
+}
+
+func TestSignaturesRollbackAndPin(t *testing.T) {
+	h, _ := newTestHandler(t, "secret")
+	if rec := serve(h, http.MethodGet, "/api/signatures", "", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a signature feed, got %d", rec.Code)
+	}
+	h.SetSignatureSource(&staticSignatures{versions: []string{"v2", "v1"}})
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	if rec := serve(h, http.MethodPost, "/api/signatures/rollback", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+	decode := func(rec *httptest.ResponseRecorder) *models.SignatureStatus {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var status models.SignatureStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("Failed to decode status: %v", err)
+		}
+		return &status
+	}
+	if status := decode(serve(h, http.MethodPost, "/api/signatures/rollback", "", auth)); status.Active != "v1" || status.Pinned != "v1" {
+		t.Errorf("Expected rollback to pin v1, got %+v", status)
+	}
+	if rec := serve(h, http.MethodPost, "/api/signatures/pin", `{"version":"v0"}`, auth); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown version, got %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, "/api/signatures/pin", `{`, auth); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid body, got %d", rec.Code)
+	}
+	if status := decode(serve(h, http.MethodPost, "/api/signatures/pin", `{"version":""}`, auth)); status.Active != "v2" || status.Pinned != "" {
+		t.Errorf("Expected unpinning to restore the latest version, got %+v", status)
+	}
+	if status := decode(serve(h, http.MethodGet, "/api/signatures", "", nil)); len(status.Versions) != 2 {
+		t.Errorf("Expected both versions in the status, got %+v", status)
+	}
+}
+
+func TestSignaturesRefusedWithoutConfiguredToken(t *testing.T) {
+	h, _ := newTestHandler(t, "")
+	sigs := &staticSignatures{versions: []string{"v2", "v1"}}
+	h.SetSignatureSource(sigs)
+	auth := map[string]string{"Authorization": "Bearer "}
+
+	if rec := serve(h, http.MethodPost, "/api/signatures/rollback", "", auth); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for rollback without a configured token, got %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, "/api/signatures/pin", `{"version":"v1"}`, auth); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for pin without a configured token, got %d", rec.Code)
+	}
+	if sigs.pinned != "" {
+		t.Errorf("Expected no version to be pinned, got %s", sigs.pinned)
+	}
+}
+
+func TestErrorsUseEnvelope(t *testing.T) {
+	h, violations := newTestHandler(t, "secret")
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		code   string
+	}{
+		{"invalid limit", http.MethodGet, "/api/violations?limit=0", http.StatusBadRequest, models.ErrorCodeBadRequest},
+		{"limit too large", http.MethodGet, "/api/violations?limit=5000", http.StatusBadRequest, models.ErrorCodeBadRequest},
+		{"invalid offset", http.MethodGet, "/api/violations?offset=-1", http.StatusBadRequest, models.ErrorCodeBadRequest},
+		{"invalid since", http.MethodGet, "/api/violations?since=yesterday", http.StatusBadRequest, models.ErrorCodeBadRequest},
+		{"unknown violation", http.MethodGet, "/api/violations/pv-unknown", http.StatusNotFound, models.ErrorCodeNotFound},
+		{"unknown route", http.MethodGet, "/api/unknown", http.StatusNotFound, models.ErrorCodeNotFound},
+		{"missing token", http.MethodPost, "/api/violations/" + violations[0].ID() + "/ack", http.StatusUnauthorized, models.ErrorCodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, tt.method, tt.target, "", nil)
+			var resp models.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error: %v: %s", err, rec.Body.String())
+			}
+			if rec.Code != tt.status || resp.Error.Code != tt.code || resp.Error.Message == "" {
+				t.Errorf("Expected %d %s, got %d: %s", tt.status, tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestOpenAPISpecIsPublished(t *testing.T) {
+	h, _ := newTestHandler(t, "")
+	rec := serve(h, http.MethodGet, "/api/openapi.json", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	published, err := os.ReadFile("../../api/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read published spec: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), published) {
+		t.Fatal("api/openapi.json is out of date, run go generate ./internal/api")
+	}
+
+	var spec struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(published, &spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+	for path, method := range map[string]string{
+		"/api/violations":             "get",
+		"/api/violations/{id}":        "get",
+		"/api/violations/{id}/ack":    "post",
+		"/api/violations/{id}/assign": "post",
+		"/api/rules":                  "get",
+		"/health":                     "get",
+	} {
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("Expected %s %s in spec", method, path)
+		}
+	}
+	// 嵌入的 ViolationRecord 字段展开到 ViolationView
+	for _, field := range []string{"id", "namespace", "node", "triage"} {
+		if _, ok := spec.Components.Schemas["ViolationView"].Properties[field]; !ok {
+			t.Errorf("Expected ViolationView.%s in spec", field)
+		}
+	}
+}
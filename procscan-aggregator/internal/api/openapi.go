@@ -0,0 +1,269 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+//go:generate go run ../../cmd/openapi -o ../../api/openapi.json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// object OpenAPI 文档中的 JSON 对象
+type object = map[string]any
+
+// OpenAPISpec 生成接口的 OpenAPI 3 定义，响应结构由 models 中的类型反射得到，与实际编码保持一致
+func OpenAPISpec() map[string]any {
+	schemas := object{}
+	ref := func(v any) object {
+		return schemaOf(reflect.TypeOf(v), schemas)
+	}
+
+	errorResponse := object{"$ref": "#/components/responses/Error"}
+	jsonContent := func(schema object) object {
+		return object{"application/json": object{"schema": schema}}
+	}
+	idParam := object{
+		"name": "id", "in": "path", "required": true,
+		"description": "违规 ID，与 ProcessViolation 资源名一致",
+		"schema":      object{"type": "string"},
+	}
+	triageOperation := func(id, summary string) object {
+		return object{
+			"operationId": id,
+			"summary":     summary,
+			"security":    []object{{"bearer": []string{}}},
+			"parameters":  []object{idParam},
+			"requestBody": object{"content": jsonContent(ref(models.TriageRequest{}))},
+			"responses": object{
+				"200": object{"description": "更新后的违规记录", "content": jsonContent(ref(models.ViolationView{}))},
+				"400": errorResponse,
+				"401": errorResponse,
+				"404": errorResponse,
+				"500": errorResponse,
+				"503": errorResponse,
+			},
+		}
+	}
+	queryParam := func(name, description string, schema object) object {
+		return object{"name": name, "in": "query", "description": description, "schema": schema}
+	}
+
+	paths := object{
+		"/api/violations": object{"get": object{
+			"operationId": "listViolations",
+			"summary":     "获取聚合的违规记录，按 namespace/pod/process 排序",
+			"parameters": []object{
+				queryParam("include_acknowledged", "同时返回已确认的违规", object{"type": "boolean"}),
+				queryParam("assignee", "只返回指派给该用户的违规", object{"type": "string"}),
+				queryParam("namespace", "只返回该命名空间的违规", object{"type": "string"}),
+				queryParam("node", "只返回该节点上报的违规", object{"type": "string"}),
+				queryParam("process", "只返回该进程名的违规", object{"type": "string"}),
+				queryParam("since", "只返回此后检测到的违规，RFC3339 时间或相对当前的时长（如 1h）", object{"type": "string"}),
+				queryParam("limit", "单页记录数，未指定时返回全部", object{"type": "integer", "minimum": 1, "maximum": MaxLimit}),
+				queryParam("offset", "跳过的记录数", object{"type": "integer", "minimum": 0, "default": 0}),
+			},
+			"responses": object{
+				"200": object{"description": "违规列表", "content": jsonContent(ref(models.ViolationList{}))},
+				"400": errorResponse,
+			},
+		}},
+		"/api/violations/{id}": object{"get": object{
+			"operationId": "getViolation",
+			"summary":     "获取单条违规记录及其处理状态",
+			"parameters":  []object{idParam},
+			"responses": object{
+				"200": object{"description": "违规记录", "content": jsonContent(ref(models.ViolationView{}))},
+				"404": errorResponse,
+			},
+		}},
+		"/api/exceptions": object{"get": object{
+			"operationId": "listExceptions",
+			"summary":     "获取命中 procscan 例外的记录，按过期时间排序",
+			"responses": object{
+				"200": object{"description": "例外列表", "content": jsonContent(ref(models.ExceptionList{}))},
+			},
+		}},
+		"/api/rules": object{"get": object{
+			"operationId": "getRules",
+			"summary":     "获取下发给节点的检测规则，灰度版本按节点比例下发",
+			"parameters": []object{
+				queryParam("node", "节点名，未指定时返回稳定版本", object{"type": "string"}),
+				{
+					"name": "If-None-Match", "in": "header",
+					"description": "上次获取的 ETag，规则版本未变化时返回 304",
+					"schema":      object{"type": "string"},
+				},
+			},
+			"responses": object{
+				"200": object{"description": "检测规则，ETag 为规则版本", "content": jsonContent(ref(models.DistributedRules{}))},
+				"304": object{"description": "规则版本未变化"},
+				"500": errorResponse,
+				"503": errorResponse,
+			},
+		}},
+		"/api/signatures": object{"get": object{
+			"operationId": "getSignatures",
+			"summary":     "获取特征库订阅状态和保留的版本",
+			"responses": object{
+				"200": object{"description": "特征库状态", "content": jsonContent(ref(models.SignatureStatus{}))},
+				"503": errorResponse,
+			},
+		}},
+		"/api/signatures/rollback": object{"post": object{
+			"operationId": "rollbackSignatures",
+			"summary":     "回滚并固定到当前特征库版本的上一个版本",
+			"security":    []object{{"bearer": []string{}}},
+			"responses": object{
+				"200": object{"description": "回滚后的特征库状态", "content": jsonContent(ref(models.SignatureStatus{}))},
+				"401": errorResponse,
+				"404": errorResponse,
+				"500": errorResponse,
+				"503": errorResponse,
+			},
+		}},
+		"/api/signatures/pin": object{"post": object{
+			"operationId": "pinSignatures",
+			"summary":     "固定特征库版本，version 为空时恢复使用最新版本",
+			"security":    []object{{"bearer": []string{}}},
+			"requestBody": object{"content": jsonContent(ref(models.SignaturePinRequest{}))},
+			"responses": object{
+				"200": object{"description": "固定后的特征库状态", "content": jsonContent(ref(models.SignatureStatus{}))},
+				"400": errorResponse,
+				"401": errorResponse,
+				"404": errorResponse,
+				"500": errorResponse,
+				"503": errorResponse,
+			},
+		}},
+		"/api/violations/{id}/ack":    object{"post": triageOperation("acknowledgeViolation", "确认违规")},
+		"/api/violations/{id}/assign": object{"post": triageOperation("assignViolation", "将违规指派给处理人")},
+		"/api/openapi.json": object{"get": object{
+			"operationId": "getOpenAPISpec",
+			"summary":     "获取本接口定义",
+			"responses":   object{"200": object{"description": "OpenAPI 3 文档", "content": jsonContent(object{"type": "object"})}},
+		}},
+		"/health": object{"get": object{
+			"operationId": "health",
+			"summary":     "健康检查",
+			"responses": object{"200": object{"description": "服务正常", "content": jsonContent(object{
+				"type":       "object",
+				"properties": object{"status": object{"type": "string"}},
+			})}},
+		}},
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "ProcScan Aggregator API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas,
+			"responses": object{"Error": object{
+				"description": "错误响应，code 取值为 " + strings.Join([]string{
+					models.ErrorCodeBadRequest, models.ErrorCodeUnauthorized, models.ErrorCodeNotFound,
+					models.ErrorCodeUnavailable, models.ErrorCodeInternal,
+				}, "、"),
+				"content": jsonContent(ref(models.ErrorResponse{})),
+			}},
+			"securitySchemes": object{"bearer": object{
+				"type": "http", "scheme": "bearer",
+				"description": "确认、指派和特征库操作接口需要携带 triage.token，未配置时拒绝这些请求",
+			}},
+		},
+	}
+}
+
+// OpenAPIJSON 返回格式化后的 OpenAPI 定义
+func OpenAPIJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(OpenAPISpec(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf 返回类型对应的 schema，结构体注册到 schemas 中并返回引用
+func schemaOf(t reflect.Type, schemas object) object {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return object{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			// 先占位，避免递归类型无限展开
+			schemas[t.Name()] = object{}
+			properties, required := object{}, []string{}
+			structFields(t, schemas, properties, &required)
+			schema := object{"type": "object", "properties": properties}
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+			schemas[t.Name()] = schema
+		}
+		return object{"$ref": "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Slice:
+		return object{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return object{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return object{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return object{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return object{"type": "integer", "format": "int64"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return object{"type": "number"}
+	}
+	return object{}
+}
+
+// structFields 按 encoding/json 的规则收集字段，匿名嵌入的结构体字段展开到外层
+func structFields(t reflect.Type, schemas, properties object, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			structFields(embedded, schemas, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
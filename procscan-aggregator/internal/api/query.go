@@ -0,0 +1,109 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// MaxLimit 单页最多返回的记录数
+const MaxLimit = 1000
+
+// listQuery 违规列表接口的过滤和分页参数
+type listQuery struct {
+	includeAcknowledged bool
+	assignee            string
+	namespace           string
+	node                string
+	process             string
+	since               time.Time
+	limit               int // 0 表示返回全部
+	offset              int
+}
+
+// parseListQuery 解析查询参数。since 可以是 RFC3339 时间，也可以是相对 now 的时长（如 "1h"），
+// 未指定 limit 时返回全部记录
+func parseListQuery(values url.Values, now time.Time) (*listQuery, error) {
+	query := &listQuery{
+		includeAcknowledged: values.Get("include_acknowledged") == "true",
+		assignee:            values.Get("assignee"),
+		namespace:           values.Get("namespace"),
+		node:                values.Get("node"),
+		process:             values.Get("process"),
+	}
+
+	if value := values.Get("since"); value != "" {
+		if since, err := time.Parse(time.RFC3339, value); err == nil {
+			query.since = since
+		} else if ago, err := time.ParseDuration(value); err == nil && ago > 0 {
+			query.since = now.Add(-ago)
+		} else {
+			return nil, fmt.Errorf("invalid since %q: expected an RFC3339 time or a positive duration", value)
+		}
+	}
+
+	var err error
+	if query.limit, err = intParam(values, "limit", 1, MaxLimit); err != nil {
+		return nil, err
+	}
+	if query.offset, err = intParam(values, "offset", 0, -1); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// intParam 解析整数参数，未指定时返回 0，max 小于 0 时不限制上限
+func intParam(values url.Values, name string, min, max int) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		if max >= 0 {
+			return 0, fmt.Errorf("invalid %s %q: expected an integer between %d and %d", name, value, min, max)
+		}
+		return 0, fmt.Errorf("invalid %s %q: expected an integer of at least %d", name, value, min)
+	}
+	return n, nil
+}
+
+// matches 判断违规是否符合过滤条件，检测时间无法解析的记录不符合 since 条件
+func (q *listQuery) matches(view *models.ViolationView) bool {
+	if q.assignee != "" && (view.Triage == nil || view.Triage.Assignee != q.assignee) {
+		return false
+	}
+	if q.namespace != "" && view.Namespace != q.namespace {
+		return false
+	}
+	if q.node != "" && view.Node != q.node {
+		return false
+	}
+	if q.process != "" && view.Process != q.process {
+		return false
+	}
+	if !q.since.IsZero() {
+		detected, err := time.Parse(time.RFC3339, view.Timestamp)
+		if err != nil || detected.Before(q.since) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// ManagedByLabel 标记由聚合器管理的 ProcessViolation 资源
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "procscan-aggregator"
+
+	// PhaseActive 违规仍在被检测到
+	PhaseActive = "Active"
+	// PhaseCleared 违规已不再被检测到，等待 TTL 到期后删除
+	PhaseCleared = "Cleared"
+)
+
+// ProcessViolationGVR ProcessViolation 自定义资源
+var ProcessViolationGVR = schema.GroupVersionResource{
+	Group:    "core.clawcloud.run",
+	Version:  "v1",
+	Resource: "processviolations",
+}
+
+// ViolationWriter 将聚合后的违规记录同步为 ProcessViolation 资源
+type ViolationWriter struct {
+	client dynamic.Interface
+	ttl    time.Duration
+}
+
+// NewViolationWriter 创建新的 ProcessViolation 写入器
+func NewViolationWriter(client dynamic.Interface, ttl time.Duration) *ViolationWriter {
+	return &ViolationWriter{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// ProcessViolationName 根据 namespace/pod/process 生成稳定的资源名，即违规 ID
+func ProcessViolationName(record *models.ViolationRecord) string {
+	return record.ID()
+}
+
+// Sync 创建或更新当前违规对应的资源，将不再出现的违规标记为 Cleared，
+// 并删除清除时间超过 TTL 的资源
+func (w *ViolationWriter) Sync(ctx context.Context, violations []*models.ViolationRecord, now time.Time) error {
+	existing, err := w.client.Resource(ProcessViolationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: ManagedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list processviolations: %w", err)
+	}
+
+	current := make(map[string]*unstructured.Unstructured, len(existing.Items))
+	for i := range existing.Items {
+		item := &existing.Items[i]
+		current[item.GetNamespace()+"/"+item.GetName()] = item
+	}
+
+	var (
+		errs                      []error
+		created, updated, cleared int
+		deleted                   int
+	)
+	desired := make(map[string]struct{}, len(violations))
+	for _, record := range violations {
+		name := ProcessViolationName(record)
+		key := record.Namespace + "/" + name
+		desired[key] = struct{}{}
+
+		obj, ok := current[key]
+		if !ok {
+			if err := w.create(ctx, name, record, now); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			created++
+			continue
+		}
+		if err := w.update(ctx, obj, record, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		updated++
+	}
+
+	for key, obj := range current {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase != PhaseCleared {
+			if err := w.markCleared(ctx, obj, now); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			cleared++
+			continue
+		}
+		if w.expired(obj, now) {
+			err := w.client.Resource(ProcessViolationGVR).Namespace(obj.GetNamespace()).
+				Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete processviolation %s: %w", key, err))
+				continue
+			}
+			deleted++
+		}
+	}
+
+	logger.L.WithFields(logrus.Fields{
+		"created": created,
+		"updated": updated,
+		"cleared": cleared,
+		"deleted": deleted,
+		"errors":  len(errs),
+	}).Info("ProcessViolation resources synced")
+
+	return errors.Join(errs...)
+}
+
+func (w *ViolationWriter) create(ctx context.Context, name string, record *models.ViolationRecord, now time.Time) error {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": ProcessViolationGVR.GroupVersion().String(),
+		"kind":       "ProcessViolation",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": record.Namespace,
+			"labels": map[string]any{
+				ManagedByLabel: managedByValue,
+			},
+		},
+		"spec": violationSpec(record),
+	}}
+
+	created, err := w.client.Resource(ProcessViolationGVR).Namespace(record.Namespace).
+		Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create processviolation %s/%s: %w", record.Namespace, name, err)
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	created.Object["status"] = map[string]any{
+		"phase":     PhaseActive,
+		"firstSeen": timestamp,
+		"lastSeen":  timestamp,
+	}
+	if _, err := w.client.Resource(ProcessViolationGVR).Namespace(record.Namespace).
+		UpdateStatus(ctx, created, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update processviolation status %s/%s: %w", record.Namespace, name, err)
+	}
+	return nil
+}
+
+func (w *ViolationWriter) update(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	record *models.ViolationRecord,
+	now time.Time,
+) error {
+	client := w.client.Resource(ProcessViolationGVR).Namespace(obj.GetNamespace())
+	if err := unstructured.SetNestedMap(obj.Object, violationSpec(record), "spec"); err != nil {
+		return err
+	}
+	updated, err := client.Update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update processviolation %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	status, _, _ := unstructured.NestedMap(updated.Object, "status")
+	if status == nil {
+		status = map[string]any{"firstSeen": timestamp}
+	}
+	status["phase"] = PhaseActive
+	status["lastSeen"] = timestamp
+	delete(status, "clearedAt")
+	delete(status, "expiresAt")
+	updated.Object["status"] = status
+	if _, err := client.UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update processviolation status %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+func (w *ViolationWriter) markCleared(ctx context.Context, obj *unstructured.Unstructured, now time.Time) error {
+	status, _, _ := unstructured.NestedMap(obj.Object, "status")
+	if status == nil {
+		status = map[string]any{}
+	}
+	status["phase"] = PhaseCleared
+	status["clearedAt"] = now.UTC().Format(time.RFC3339)
+	status["expiresAt"] = now.Add(w.ttl).UTC().Format(time.RFC3339)
+	obj.Object["status"] = status
+	if _, err := w.client.Resource(ProcessViolationGVR).Namespace(obj.GetNamespace()).
+		UpdateStatus(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to mark processviolation %s/%s cleared: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// expired 判断已清除的资源是否超过 TTL
+func (w *ViolationWriter) expired(obj *unstructured.Unstructured, now time.Time) bool {
+	clearedAt, _, _ := unstructured.NestedString(obj.Object, "status", "clearedAt")
+	t, err := time.Parse(time.RFC3339, clearedAt)
+	if err != nil {
+		// 无法解析清除时间时按创建时间计算
+		t = obj.GetCreationTimestamp().Time
+	}
+	return now.Sub(t) >= w.ttl
+}
+
+func violationSpec(record *models.ViolationRecord) map[string]any {
+	return map[string]any{
+		"pod":       record.Pod,
+		"process":   record.Process,
+		"cmdline":   record.Cmdline,
+		"regex":     record.Regex,
+		"type":      record.Type,
+		"name":      record.Name,
+		"timestamp": record.Timestamp,
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newFakeWriter(ttl time.Duration) (*ViolationWriter, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ProcessViolationGVR: "ProcessViolationList"})
+	return NewViolationWriter(client, ttl), client
+}
+
+func getViolation(t *testing.T, client *dynamicfake.FakeDynamicClient, namespace, name string) *unstructured.Unstructured {
+	t.Helper()
+	obj, err := client.Resource(ProcessViolationGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get processviolation: %v", err)
+	}
+	return obj
+}
+
+func TestViolationWriterSync(t *testing.T) {
+	ctx := context.Background()
+	writer, client := newFakeWriter(24 * time.Hour)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := &models.ViolationRecord{
+		Pod:       "app-1",
+		Namespace: "ns-a",
+		Process:   "xmrig",
+		Cmdline:   "xmrig -o pool",
+		Type:      "app",
+		Name:      "app",
+	}
+	name := ProcessViolationName(record)
+
+	// 新违规创建资源
+	if err := writer.Sync(ctx, []*models.ViolationRecord{record}, now); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	obj := getViolation(t, client, "ns-a", name)
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != PhaseActive {
+		t.Errorf("Expected phase %s, got %s", PhaseActive, phase)
+	}
+	if process, _, _ := unstructured.NestedString(obj.Object, "spec", "process"); process != "xmrig" {
+		t.Errorf("Expected spec.process 'xmrig', got '%s'", process)
+	}
+
+	// 违规消失后标记为 Cleared
+	if err := writer.Sync(ctx, nil, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	obj = getViolation(t, client, "ns-a", name)
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != PhaseCleared {
+		t.Errorf("Expected phase %s, got %s", PhaseCleared, phase)
+	}
+
+	// TTL 未到期前保留
+	if err := writer.Sync(ctx, nil, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	getViolation(t, client, "ns-a", name)
+
+	// TTL 到期后删除
+	if err := writer.Sync(ctx, nil, now.Add(26*time.Hour)); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	list, err := client.Resource(ProcessViolationGVR).Namespace("ns-a").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list processviolations: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("Expected expired processviolation to be deleted, got %d items", len(list.Items))
+	}
+}
+
+func TestProcessViolationNameIsStable(t *testing.T) {
+	a := &models.ViolationRecord{Namespace: "ns", Pod: "pod", Process: "proc", Cmdline: "one"}
+	b := &models.ViolationRecord{Namespace: "ns", Pod: "pod", Process: "proc", Cmdline: "two"}
+	if ProcessViolationName(a) != ProcessViolationName(b) {
+		t.Errorf("Expected names to only depend on namespace/pod/process")
+	}
+	c := &models.ViolationRecord{Namespace: "ns", Pod: "pod-2", Process: "proc"}
+	if ProcessViolationName(a) == ProcessViolationName(c) {
+		t.Errorf("Expected different pods to produce different names")
+	}
+}
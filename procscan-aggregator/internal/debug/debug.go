@@ -0,0 +1,133 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debug 提供 pprof 和运行时统计接口，用于在生产环境排查内存和协程泄漏。
+// 默认不启用，监听非回环地址时必须配置 Bearer Token
+package debug
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+)
+
+// Runtime /debug/runtime 的响应
+type Runtime struct {
+	GoVersion    string `json:"go_version"`
+	Goroutines   int    `json:"goroutines"`
+	NumCPU       int    `json:"num_cpu"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	HeapAlloc    uint64 `json:"heap_alloc"` // 内存统计单位为字节，见 runtime.MemStats
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+	LastGC       string `json:"last_gc,omitempty"`
+}
+
+// Handler 返回调试接口，token 不为空时校验 Bearer Token
+func Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReadRuntime())
+	})
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ReadRuntime 读取当前的运行时统计
+func ReadRuntime() Runtime {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	info := Runtime{
+		GoVersion:    runtime.Version(),
+		Goroutines:   runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    stats.HeapAlloc,
+		HeapInuse:    stats.HeapInuse,
+		HeapObjects:  stats.HeapObjects,
+		StackInuse:   stats.StackInuse,
+		Sys:          stats.Sys,
+		NumGC:        stats.NumGC,
+		PauseTotalNs: stats.PauseTotalNs,
+	}
+	if stats.LastGC > 0 {
+		info.LastGC = time.Unix(0, int64(stats.LastGC)).UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// CheckExposure 没有 token 时只允许监听回环地址，profile 中包含进程内存的内容
+func CheckExposure(addr, token string) error {
+	if token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug addr '%s': %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return errors.New("debug addr beyond the loopback interface requires a token")
+}
+
+// Start 在后台启动调试接口。CPU profile 和 trace 按请求的时长采集，因此不设置写超时
+func Start(addr, token string) (*http.Server, error) {
+	if err := CheckExposure(addr, token); err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.L.WithField("addr", addr).Info("Debug server starting")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.L.WithError(err).Error("Debug server failed")
+		}
+	}()
+	return server, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func get(handler http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	handler := Handler("secret")
+	for _, token := range []string{"", "wrong"} {
+		if rec := get(handler, "/debug/pprof/", token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for token %q, got %d", token, rec.Code)
+		}
+	}
+	rec := get(handler, "/debug/pprof/", "secret")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Errorf("Expected the pprof index, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandlerServesRuntime(t *testing.T) {
+	handler := Handler("")
+	if rec := get(handler, "/debug/pprof/heap?debug=1", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected the heap profile, got %d", rec.Code)
+	}
+	rec := get(handler, "/debug/runtime", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var info Runtime
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Failed to decode runtime: %v", err)
+	}
+	if info.Goroutines == 0 || info.HeapAlloc == 0 || info.GoVersion == "" {
+		t.Errorf("Unexpected runtime statistics: %+v", info)
+	}
+}
+
+func TestCheckExposure(t *testing.T) {
+	tests := []struct {
+		addr    string
+		token   string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:6060"},
+		{addr: "[::1]:6060"},
+		{addr: "localhost:6060"},
+		{addr: ":6060", wantErr: true},
+		{addr: "0.0.0.0:6060", wantErr: true},
+		{addr: ":6060", token: "secret"},
+		{addr: "6060", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckExposure(tt.addr, tt.token); (err != nil) != tt.wantErr {
+			t.Errorf("CheckExposure(%q, %q) error = %v, wantErr %v", tt.addr, tt.token, err, tt.wantErr)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -31,7 +32,8 @@ import (
 
 // Client Kubernetes 客户端封装
 type Client struct {
-	clientset *kubernetes.Clientset
+	clientset     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
 }
 
 // NewClient 创建新的 Kubernetes 客户端
@@ -46,7 +48,17 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to create k8s clientset: %w", err)
 	}
 
-	return &Client{clientset: clientset}, nil
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s dynamic client: %w", err)
+	}
+
+	return &Client{clientset: clientset, dynamicClient: dynamicClient}, nil
+}
+
+// Dynamic 返回用于操作自定义资源的 dynamic 客户端
+func (c *Client) Dynamic() dynamic.Interface {
+	return c.dynamicClient
 }
 
 // getK8sConfig 获取 Kubernetes 配置
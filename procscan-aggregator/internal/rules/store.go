@@ -0,0 +1,198 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules 从规则文件加载检测规则并按节点下发给 procscan，支持按节点比例灰度发布新版本
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// File 规则文件内容。stable 下发给所有节点，配置了 canary 时按 percent 下发给部分节点，
+// 全量发布时将 canary 的内容移到 stable 并删除 canary
+type File struct {
+	Stable Version  `yaml:"stable"`
+	Canary *Version `yaml:"canary"`
+}
+
+// Version 一个规则版本
+type Version struct {
+	Version string                `yaml:"version"`
+	Percent int                   `yaml:"percent"` // 灰度节点比例（0-100），仅 canary 使用
+	Rules   models.DetectionRules `yaml:"rules"`
+}
+
+// SignatureSource 提供合并到规则中的特征库版本，没有可用版本时返回 nil
+type SignatureSource interface {
+	Active() *models.SignatureSet
+}
+
+// Store 规则文件的内容，每次读取时检查文件是否变化，变化后重新加载
+type Store struct {
+	path       string
+	signatures SignatureSource
+
+	mu      sync.Mutex
+	file    *File
+	modTime time.Time
+	size    int64
+}
+
+// NewStore 加载规则文件，文件不存在或内容无效时返回错误
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ForNode 返回下发给 node 的规则，node 为空时返回 stable 版本。
+// 规则文件变化但重新加载失败时继续使用上次加载成功的规则
+func (s *Store) ForNode(node string) (*models.DistributedRules, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		logger.L.WithError(err).Error("Failed to reload rules, keeping the previous rules")
+	}
+	if s.file == nil {
+		return nil, errors.New("no rules loaded")
+	}
+	rules := &models.DistributedRules{Version: s.file.Stable.Version, Rules: s.file.Stable.Rules}
+	if canary := s.file.Canary; canary != nil && node != "" && InCanary(node, canary.Version, canary.Percent) {
+		rules = &models.DistributedRules{Version: canary.Version, Canary: true, Rules: canary.Rules}
+	}
+	if s.signatures != nil {
+		if set := s.signatures.Active(); set != nil {
+			rules = MergeSignatures(rules, set)
+		}
+	}
+	return rules, nil
+}
+
+// SetSignatures 将特征库合并到下发的规则中
+func (s *Store) SetSignatures(source SignatureSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signatures = source
+}
+
+// MergeSignatures 返回合并了特征库的规则副本：进程名加入黑名单进程，关键字和矿池域名加入黑名单关键字，
+// 已有的规则不重复添加。版本为 "<规则版本>+sig.<特征库版本>"，特征库更新或回滚时 ETag 随之变化
+func MergeSignatures(rules *models.DistributedRules, set *models.SignatureSet) *models.DistributedRules {
+	merged := *rules
+	merged.Version = rules.Version + "+sig." + set.Version
+	blacklist := rules.Rules.Blacklist
+	blacklist.Processes = appendMissing(blacklist.Processes, set.Processes)
+	keywords := append([]string(nil), set.Keywords...)
+	for _, domain := range set.PoolDomains {
+		keywords = append(keywords, "(?i)"+regexp.QuoteMeta(domain))
+	}
+	blacklist.Keywords = appendMissing(blacklist.Keywords, keywords)
+	merged.Rules.Blacklist = blacklist
+	return &merged
+}
+
+// appendMissing 返回 base 的副本，追加其中没有的 additions
+func appendMissing(base, additions []string) []string {
+	result := append([]string(nil), base...)
+	seen := make(map[string]struct{}, len(base)+len(additions))
+	for _, value := range base {
+		seen[value] = struct{}{}
+	}
+	for _, value := range additions {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	return result
+}
+
+// InCanary 判断节点是否在版本的灰度范围内。节点按版本和节点名的哈希分桶，
+// 同一版本调大 percent 时已经灰度的节点保持不变，不同版本会选中不同的节点
+func InCanary(node, version string, percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(version + "/" + node))
+	return int(h.Sum32()%100) < percent
+}
+
+// reload 文件大小或修改时间变化时重新加载，调用方需持有锁或在构造时调用
+func (s *Store) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat rules file: %w", err)
+	}
+	if s.file != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse rules file %s: %w", s.path, err)
+	}
+	if err := validate(&file); err != nil {
+		return fmt.Errorf("invalid rules file %s: %w", s.path, err)
+	}
+
+	fields := logrus.Fields{"path": s.path, "stable": file.Stable.Version}
+	if file.Canary != nil {
+		fields["canary"] = file.Canary.Version
+		fields["canary_percent"] = file.Canary.Percent
+	}
+	logger.L.WithFields(fields).Info("Rules loaded")
+	s.file, s.modTime, s.size = &file, info.ModTime(), info.Size()
+	return nil
+}
+
+// validate 校验版本信息，规则本身由 procscan 在应用前校验
+func validate(file *File) error {
+	if file.Stable.Version == "" {
+		return errors.New("stable version is required")
+	}
+	if file.Canary == nil {
+		return nil
+	}
+	if file.Canary.Version == "" {
+		return errors.New("canary version is required")
+	}
+	if file.Canary.Version == file.Stable.Version {
+		return fmt.Errorf("canary version %q must differ from the stable version", file.Canary.Version)
+	}
+	if file.Canary.Percent < 0 || file.Canary.Percent > 100 {
+		return fmt.Errorf("invalid canary percent %d: must be between 0 and 100", file.Canary.Percent)
+	}
+	return nil
+}
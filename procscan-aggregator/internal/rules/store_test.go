@@ -0,0 +1,187 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+const rulesFile = `
+stable:
+  version: v1
+  rules:
+    blacklist:
+      processes: ["^xmrig$"]
+canary:
+  version: v2
+  percent: 30
+  rules:
+    blacklist:
+      processes: ["^xmrig$", "^minerd$"]
+`
+
+func writeRules(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+}
+
+func TestStoreRollsOutCanaryToPercentOfNodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, rulesFile)
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	canary := 0
+	for i := 0; i < 1000; i++ {
+		node := fmt.Sprintf("node-%d", i)
+		rules, err := store.ForNode(node)
+		if err != nil {
+			t.Fatalf("Failed to get rules: %v", err)
+		}
+		if rules.Canary {
+			canary++
+			if rules.Version != "v2" || len(rules.Rules.Blacklist.Processes) != 2 {
+				t.Errorf("Unexpected canary rules for %s: %+v", node, rules)
+			}
+		} else if rules.Version != "v1" {
+			t.Errorf("Unexpected stable version for %s: %s", node, rules.Version)
+		}
+		again, _ := store.ForNode(node)
+		if again.Version != rules.Version {
+			t.Errorf("Expected %s to get the same version on every request", node)
+		}
+	}
+	if canary < 250 || canary > 350 {
+		t.Errorf("Expected about 30%% of the nodes in the canary, got %d/1000", canary)
+	}
+
+	rules, _ := store.ForNode("")
+	if rules.Version != "v1" || rules.Canary {
+		t.Errorf("Expected requests without a node to get the stable rules, got %+v", rules)
+	}
+}
+
+func TestInCanaryKeepsNodesWhenPercentGrows(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		node := fmt.Sprintf("node-%d", i)
+		if InCanary(node, "v2", 10) && !InCanary(node, "v2", 50) {
+			t.Errorf("Expected %s to stay in the canary when the percent grows", node)
+		}
+		if !InCanary(node, "v2", 100) || InCanary(node, "v2", 0) {
+			t.Errorf("Unexpected canary membership for %s at 0%% or 100%%", node)
+		}
+	}
+}
+
+func TestStoreReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, "stable:\n  version: v1\n")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	writeRules(t, path, "stable:\n  version: v22\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to touch rules: %v", err)
+	}
+	if rules, _ := store.ForNode("node-1"); rules.Version != "v22" {
+		t.Errorf("Expected the changed file to be loaded, got %s", rules.Version)
+	}
+
+	// 无效的文件不替换已加载的规则
+	writeRules(t, path, "stable:\n  version: \"\"\n")
+	if rules, _ := store.ForNode("node-1"); rules.Version != "v22" {
+		t.Errorf("Expected the previous rules to be kept, got %s", rules.Version)
+	}
+}
+
+func TestNewStoreValidatesVersions(t *testing.T) {
+	cases := map[string]string{
+		"stable version is required": "stable:\n  rules: {}\n",
+		"canary version is required": "stable:\n  version: v1\ncanary:\n  percent: 10\n",
+		"must differ":                "stable:\n  version: v1\ncanary:\n  version: v1\n",
+		"must be between 0 and 100":  "stable:\n  version: v1\ncanary:\n  version: v2\n  percent: 120\n",
+		"failed to parse rules file": "stable: [\n",
+		"failed to stat rules file":  "",
+	}
+	for want, content := range cases {
+		path := filepath.Join(t.TempDir(), "rules.yaml")
+		if content != "" {
+			writeRules(t, path, content)
+		}
+		if _, err := NewStore(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+type staticSignatures struct {
+	set *models.SignatureSet
+}
+
+func (s *staticSignatures) Active() *models.SignatureSet { return s.set }
+
+func TestStoreMergesActiveSignatures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, rulesFile)
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	signatures := &staticSignatures{}
+	store.SetSignatures(signatures)
+	if rules, _ := store.ForNode(""); rules.Version != "v1" {
+		t.Errorf("Expected the rules version without signatures, got %s", rules.Version)
+	}
+
+	signatures.set = &models.SignatureSet{
+		Version:     "2025.06.01",
+		Processes:   []string{"^xmrig$", "^lolminer$"},
+		PoolDomains: []string{"pool.supportxmr.com"},
+		Keywords:    []string{"stratum\\+tcp://"},
+	}
+	rules, err := store.ForNode("")
+	if err != nil {
+		t.Fatalf("Failed to get rules: %v", err)
+	}
+	if rules.Version != "v1+sig.2025.06.01" {
+		t.Errorf("Expected the signature version in the rules version, got %s", rules.Version)
+	}
+	if got := fmt.Sprint(rules.Rules.Blacklist.Processes); got != "[^xmrig$ ^lolminer$]" {
+		t.Errorf("Expected signature processes to be merged without duplicates, got %s", got)
+	}
+	if got := fmt.Sprint(rules.Rules.Blacklist.Keywords); got != `[stratum\+tcp:// (?i)pool\.supportxmr\.com]` {
+		t.Errorf("Expected keywords and pool domains to be merged, got %s", got)
+	}
+
+	// 合并不修改规则文件的内容
+	signatures.set = nil
+	if rules, _ := store.ForNode(""); len(rules.Rules.Blacklist.Processes) != 1 || rules.Version != "v1" {
+		t.Errorf("Expected the loaded rules to be unchanged, got %s %v", rules.Version, rules.Rules.Blacklist.Processes)
+	}
+}
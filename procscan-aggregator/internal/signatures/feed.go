@@ -0,0 +1,378 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signatures 定时拉取签名的特征库（挖矿进程名、矿池域名和关键字），
+// 校验签名后保存历史版本，支持固定版本和回滚
+package signatures
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// maxFeedSize 特征库响应的大小上限
+const maxFeedSize = 4 << 20
+
+// ErrUnknownVersion 固定或回滚到的版本不在历史版本中
+var ErrUnknownVersion = errors.New("unknown signature version")
+
+// state 持久化的订阅状态
+type state struct {
+	Versions []*models.SignatureSet `json:"versions"` // 按发布时间倒序
+	Pinned   string                 `json:"pinned,omitempty"`
+}
+
+// Feed 特征库订阅，只接受签名有效且发布时间晚于已有版本的特征库，防止被重放旧版本
+type Feed struct {
+	url       string
+	publicKey ed25519.PublicKey
+	interval  time.Duration
+	path      string
+	history   int
+	client    *http.Client
+	now       func() time.Time
+
+	mu        sync.RWMutex
+	state     state
+	lastFetch *time.Time
+	lastError string
+}
+
+// NewFeed 根据配置创建订阅并加载已保存的版本，配置中的 pin 优先于保存的固定版本
+func NewFeed(cfg models.SignaturesConfig) (*Feed, error) {
+	if !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, fmt.Errorf("signature feed url must use https")
+	}
+	key, err := base64.StdEncoding.DecodeString(os.ExpandEnv(cfg.PublicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public_key must be a base64 encoded Ed25519 public key")
+	}
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval '%s': %w", cfg.Interval, err)
+	}
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout '%s': %w", cfg.Timeout, err)
+	}
+	f := &Feed{
+		url:       cfg.URL,
+		publicKey: ed25519.PublicKey(key),
+		interval:  interval,
+		path:      cfg.StatePath,
+		history:   cfg.History,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	if cfg.Pin != "" {
+		f.state.Pinned = cfg.Pin
+	}
+	return f, nil
+}
+
+// Start 立即拉取一次，之后按间隔拉取，ctx 取消后停止
+func (f *Feed) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for {
+			if err := f.Fetch(ctx); err != nil {
+				logger.L.WithError(err).Error("Failed to update signatures, keeping the previous version")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Fetch 拉取并校验特征库，新版本加入历史版本
+func (f *Feed) Fetch(ctx context.Context) error {
+	set, err := f.download(ctx)
+	now := f.now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastFetch = &now
+	if err != nil {
+		f.lastError = err.Error()
+		return err
+	}
+	f.lastError = ""
+	if latest := f.latest(); latest != nil {
+		if latest.Version == set.Version {
+			return nil
+		}
+		if !set.Published.After(latest.Published) {
+			err := fmt.Errorf("signature version %s is not newer than %s", set.Version, latest.Version)
+			f.lastError = err.Error()
+			return err
+		}
+	}
+
+	previous := f.state.Versions
+	f.state.Versions = f.trim(append([]*models.SignatureSet{set}, previous...))
+	if err := f.save(); err != nil {
+		f.state.Versions = previous
+		return err
+	}
+	fields := logrus.Fields{
+		"version":      set.Version,
+		"processes":    len(set.Processes),
+		"pool_domains": len(set.PoolDomains),
+		"keywords":     len(set.Keywords),
+	}
+	if f.state.Pinned != "" {
+		fields["pinned"] = f.state.Pinned
+	}
+	logger.L.WithFields(fields).Info("Signatures updated")
+	return nil
+}
+
+// download 下载特征库，校验签名和内容
+func (f *Feed) download(ctx context.Context) (*models.SignatureSet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signatures: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signature feed returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signatures: %w", err)
+	}
+	return Verify(body, f.publicKey)
+}
+
+// Verify 校验签名特征库并解析其内容，特征中的正则必须能够编译
+func Verify(body []byte, publicKey ed25519.PublicKey) (*models.SignatureSet, error) {
+	var feed models.SignedFeed
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("invalid signature feed: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(feed.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature feed payload: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(feed.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature feed signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return nil, errors.New("signature feed signature does not match")
+	}
+
+	var set models.SignatureSet
+	if err := json.Unmarshal(payload, &set); err != nil {
+		return nil, fmt.Errorf("invalid signature set: %w", err)
+	}
+	if set.Version == "" {
+		return nil, errors.New("signature set without a version")
+	}
+	if set.Published.IsZero() {
+		return nil, errors.New("signature set without a published time")
+	}
+	for _, patterns := range [][]string{set.Processes, set.Keywords} {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("invalid signature pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	for _, domain := range set.PoolDomains {
+		if domain == "" || strings.ContainsAny(domain, "/: ") {
+			return nil, fmt.Errorf("invalid pool domain %q", domain)
+		}
+	}
+	return &set, nil
+}
+
+// Active 返回合并到规则中的版本：固定的版本不在历史中时不使用特征库，没有固定时使用最新版本
+func (f *Feed) Active() *models.SignatureSet {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.active()
+}
+
+func (f *Feed) active() *models.SignatureSet {
+	if f.state.Pinned == "" {
+		return f.latest()
+	}
+	for _, set := range f.state.Versions {
+		if set.Version == f.state.Pinned {
+			return set
+		}
+	}
+	return nil
+}
+
+// trim 只保留最新的 history 个版本，固定的版本被淘汰时替换保留的最旧版本，保证回滚后仍可使用
+func (f *Feed) trim(versions []*models.SignatureSet) []*models.SignatureSet {
+	if len(versions) <= f.history {
+		return versions
+	}
+	pinned := f.active()
+	kept := versions[:f.history:f.history]
+	if pinned != nil && f.state.Pinned != "" {
+		for _, set := range kept {
+			if set == pinned {
+				return kept
+			}
+		}
+		kept[len(kept)-1] = pinned
+	}
+	return kept
+}
+
+func (f *Feed) latest() *models.SignatureSet {
+	if len(f.state.Versions) == 0 {
+		return nil
+	}
+	return f.state.Versions[0]
+}
+
+// Pin 固定使用 version，version 为空时恢复使用最新版本
+func (f *Feed) Pin(version string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if version != "" && !f.has(version) {
+		return fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+	}
+	return f.pin(version)
+}
+
+// Rollback 固定到当前版本的上一个版本并返回该版本，新拉取的版本在取消固定前不会生效
+func (f *Feed) Rollback() (*models.SignatureSet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	active := f.active()
+	for i, set := range f.state.Versions {
+		if set == active && i+1 < len(f.state.Versions) {
+			previous := f.state.Versions[i+1]
+			if err := f.pin(previous.Version); err != nil {
+				return nil, err
+			}
+			return previous, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no version before the active one", ErrUnknownVersion)
+}
+
+func (f *Feed) pin(version string) error {
+	previous := f.state.Pinned
+	f.state.Pinned = version
+	if err := f.save(); err != nil {
+		f.state.Pinned = previous
+		return err
+	}
+	logger.L.WithFields(logrus.Fields{
+		"pinned":   version,
+		"previous": previous,
+	}).Info("Signature version pinned")
+	return nil
+}
+
+func (f *Feed) has(version string) bool {
+	for _, set := range f.state.Versions {
+		if set.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 返回订阅状态
+func (f *Feed) Status() *models.SignatureStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	status := &models.SignatureStatus{
+		Pinned:    f.state.Pinned,
+		Versions:  append([]*models.SignatureSet(nil), f.state.Versions...),
+		LastFetch: f.lastFetch,
+		LastError: f.lastError,
+	}
+	if active := f.active(); active != nil {
+		status.Active = active.Version
+	}
+	return status
+}
+
+func (f *Feed) load() error {
+	if f.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read signature state: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f.state); err != nil {
+			return fmt.Errorf("failed to parse signature state %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// save 先写临时文件再重命名，调用方需持有锁
+func (f *Feed) save() error {
+	if f.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(f.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode signature state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create signature state directory: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write signature state: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to replace signature state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signatures
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// feedServer 返回当前设置的签名特征库
+type feedServer struct {
+	mu   sync.Mutex
+	body []byte
+}
+
+func (s *feedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = w.Write(s.body)
+}
+
+func (s *feedServer) set(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+func sign(t *testing.T, key ed25519.PrivateKey, set models.SignatureSet) []byte {
+	t.Helper()
+	payload, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to encode signature set: %v", err)
+	}
+	body, err := json.Marshal(models.SignedFeed{
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode feed: %v", err)
+	}
+	return body
+}
+
+func signatureSet(version string, published time.Time) models.SignatureSet {
+	return models.SignatureSet{
+		Version:     version,
+		Published:   published,
+		Processes:   []string{"^xmrig$"},
+		PoolDomains: []string{"pool.supportxmr.com"},
+		Keywords:    []string{"stratum\\+tcp://"},
+	}
+}
+
+func newTestFeed(t *testing.T, statePath string) (*Feed, *feedServer, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	server := &feedServer{}
+	ts := httptest.NewTLSServer(server)
+	t.Cleanup(ts.Close)
+	t.Setenv("SIGNATURE_PUBLIC_KEY", base64.StdEncoding.EncodeToString(public))
+
+	feed, err := NewFeed(models.SignaturesConfig{
+		URL:       ts.URL,
+		PublicKey: "${SIGNATURE_PUBLIC_KEY}",
+		Interval:  "1h",
+		Timeout:   "5s",
+		StatePath: statePath,
+		History:   2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create feed: %v", err)
+	}
+	feed.client = ts.Client()
+	return feed, server, private
+}
+
+func TestFetchKeepsHistoryAndRejectsOlderVersions(t *testing.T) {
+	feed, server, key := newTestFeed(t, "")
+	published := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, version := range []string{"v1", "v2", "v3"} {
+		server.set(sign(t, key, signatureSet(version, published.Add(time.Duration(i)*time.Hour))))
+		if err := feed.Fetch(context.Background()); err != nil {
+			t.Fatalf("Failed to fetch %s: %v", version, err)
+		}
+	}
+	status := feed.Status()
+	if status.Active != "v3" || len(status.Versions) != 2 || status.Versions[1].Version != "v2" {
+		t.Fatalf("Expected v3 and v2 to be kept, got %+v", status)
+	}
+
+	// 重放旧版本不会替换当前版本
+	server.set(sign(t, key, signatureSet("v0", published)))
+	if err := feed.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "not newer") {
+		t.Errorf("Expected an older version to be rejected, got %v", err)
+	}
+	if status := feed.Status(); status.Active != "v3" || status.LastError == "" {
+		t.Errorf("Expected v3 to stay active with the error reported, got %+v", status)
+	}
+}
+
+func TestFetchRejectsInvalidFeeds(t *testing.T) {
+	feed, server, key := newTestFeed(t, "")
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	published := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	invalidPattern := signatureSet("v1", published)
+	invalidPattern.Processes = []string{"("}
+	invalidDomain := signatureSet("v1", published)
+	invalidDomain.PoolDomains = []string{"stratum+tcp://pool.example.com:3333"}
+
+	cases := map[string][]byte{
+		"signature does not match":    sign(t, otherKey, signatureSet("v1", published)),
+		"invalid signature pattern":   sign(t, key, invalidPattern),
+		"invalid pool domain":         sign(t, key, invalidDomain),
+		"without a version":           sign(t, key, signatureSet("", published)),
+		"without a published time":    sign(t, key, signatureSet("v1", time.Time{})),
+		"invalid signature feed":      []byte("not json"),
+		"invalid signature feed payl": []byte(`{"payload":"%%%","signature":""}`),
+	}
+	for want, body := range cases {
+		server.set(body)
+		if err := feed.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+	if feed.Active() != nil {
+		t.Errorf("Expected no version to be accepted")
+	}
+}
+
+func TestRollbackPinsPreviousVersionAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	feed, server, key := newTestFeed(t, path)
+	published := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := feed.Rollback(); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected rollback without versions to fail, got %v", err)
+	}
+	for i, version := range []string{"v1", "v2"} {
+		server.set(sign(t, key, signatureSet(version, published.Add(time.Duration(i)*time.Hour))))
+		if err := feed.Fetch(context.Background()); err != nil {
+			t.Fatalf("Failed to fetch %s: %v", version, err)
+		}
+	}
+
+	previous, err := feed.Rollback()
+	if err != nil || previous.Version != "v1" {
+		t.Fatalf("Expected rollback to v1, got %v %v", previous, err)
+	}
+	// 回滚后新版本在取消固定前不生效
+	server.set(sign(t, key, signatureSet("v3", published.Add(2*time.Hour))))
+	if err := feed.Fetch(context.Background()); err != nil {
+		t.Fatalf("Failed to fetch v3: %v", err)
+	}
+	if active := feed.Active(); active.Version != "v1" {
+		t.Errorf("Expected the pinned v1 to stay active, got %s", active.Version)
+	}
+	if err := feed.Pin("v0"); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected pinning an unknown version to fail, got %v", err)
+	}
+
+	reloaded, err := NewFeed(models.SignaturesConfig{
+		URL:       "https://signatures.example.com/feed.json",
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Interval:  "1h",
+		Timeout:   "5s",
+		StatePath: path,
+		History:   2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to reload feed: %v", err)
+	}
+	// 固定的 v1 不因超出历史版本数被淘汰
+	status := reloaded.Status()
+	if status.Active != "v1" || len(status.Versions) != 2 || status.Versions[0].Version != "v3" || status.Versions[1].Version != "v1" {
+		t.Errorf("Expected the state to be persisted with the pinned version kept, got %+v", status)
+	}
+	if err := reloaded.Pin(""); err != nil {
+		t.Fatalf("Failed to unpin: %v", err)
+	}
+	if active := reloaded.Active(); active.Version != "v3" {
+		t.Errorf("Expected the latest version after unpinning, got %s", active.Version)
+	}
+}
+
+func TestNewFeedValidatesConfig(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize))
+	cases := map[string]models.SignaturesConfig{
+		"must use https": {URL: "http://signatures.example.com", PublicKey: key, Interval: "1h", Timeout: "5s"},
+		"public_key":     {URL: "https://signatures.example.com", PublicKey: "c2hvcnQ=", Interval: "1h", Timeout: "5s"},
+		"interval":       {URL: "https://signatures.example.com", PublicKey: key, Interval: "soon", Timeout: "5s"},
+	}
+	for want, cfg := range cases {
+		if _, err := NewFeed(cfg); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package triage 保存违规的确认和指派状态，使聚合器可以作为轻量的事件处理队列
+package triage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// 操作类型，记录在 TriageNote.Action 中
+const (
+	ActionAck    = "ack"
+	ActionAssign = "assign"
+)
+
+// Store 以 JSON 文件持久化违规 ID 到处理状态的映射，StatePath 为空时只保存在内存中
+type Store struct {
+	path   string
+	mu     sync.RWMutex
+	states map[string]*models.TriageState
+}
+
+// NewStore 创建状态存储，并加载已有的状态文件
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:   path,
+		states: make(map[string]*models.TriageState),
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read triage state: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.states); err != nil {
+			return nil, fmt.Errorf("failed to parse triage state %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Get 返回违规的处理状态副本，没有记录时返回 nil
+func (s *Store) Get(id string) *models.TriageState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.states[id]
+	if !ok {
+		return nil
+	}
+	return copyState(state)
+}
+
+// Acknowledge 将违规标记为已确认，重复确认会保留首次确认的人和时间
+func (s *Store) Acknowledge(id, by, note string, now time.Time) (*models.TriageState, error) {
+	return s.update(id, func(state *models.TriageState) {
+		if !state.Acknowledged {
+			state.Acknowledged = true
+			state.AcknowledgedBy = by
+			state.AcknowledgedAt = &now
+		}
+		state.Notes = append(state.Notes, models.TriageNote{Action: ActionAck, Author: by, Text: note, CreatedAt: now})
+	})
+}
+
+// Assign 将违规指派给 assignee，覆盖之前的指派
+func (s *Store) Assign(id, assignee, by, note string, now time.Time) (*models.TriageState, error) {
+	return s.update(id, func(state *models.TriageState) {
+		state.Assignee = assignee
+		state.AssignedBy = by
+		state.AssignedAt = &now
+		text := "assigned to " + assignee
+		if note != "" {
+			text += ": " + note
+		}
+		state.Notes = append(state.Notes, models.TriageNote{Action: ActionAssign, Author: by, Text: text, CreatedAt: now})
+	})
+}
+
+// Prune 删除已不在 active 中的违规状态，违规清除后再次出现时作为新事件处理
+func (s *Store) Prune(active map[string]struct{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := false
+	for id := range s.states {
+		if _, ok := active[id]; !ok {
+			delete(s.states, id)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return s.save()
+}
+
+func (s *Store) update(id string, fn func(state *models.TriageState)) (*models.TriageState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.states[id]
+	state := &models.TriageState{}
+	if existed {
+		state = copyState(previous)
+	}
+	fn(state)
+	s.states[id] = state
+	if err := s.save(); err != nil {
+		// 保存失败时回滚，避免内存与文件不一致
+		if existed {
+			s.states[id] = previous
+		} else {
+			delete(s.states, id)
+		}
+		return nil, err
+	}
+	return copyState(state), nil
+}
+
+// save 先写临时文件再重命名，避免进程中断时留下不完整的状态文件，调用方需持有锁
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode triage state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create triage state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write triage state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace triage state: %w", err)
+	}
+	return nil
+}
+
+func copyState(state *models.TriageState) *models.TriageState {
+	c := *state
+	c.Notes = append([]models.TriageNote(nil), state.Notes...)
+	return &c
+}
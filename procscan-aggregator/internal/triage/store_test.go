@@ -0,0 +1,139 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package triage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStorePersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "triage.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := store.Acknowledge("pv-1", "alice", "known batch job", first); err != nil {
+		t.Fatalf("Failed to acknowledge: %v", err)
+	}
+	if _, err := store.Acknowledge("pv-1", "bob", "", first.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to acknowledge again: %v", err)
+	}
+	if _, err := store.Assign("pv-1", "carol", "bob", "please confirm with owner", first.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Failed to assign: %v", err)
+	}
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+	state := reloaded.Get("pv-1")
+	if state == nil {
+		t.Fatal("Expected state to survive reload")
+	}
+	if !state.Acknowledged || state.AcknowledgedBy != "alice" || !state.AcknowledgedAt.Equal(first) {
+		t.Errorf("Expected first acknowledgement to be kept, got %+v", state)
+	}
+	if state.Assignee != "carol" || state.AssignedBy != "bob" {
+		t.Errorf("Unexpected assignment: %+v", state)
+	}
+	if len(state.Notes) != 3 {
+		t.Fatalf("Expected 3 notes, got %d", len(state.Notes))
+	}
+	if got := state.Notes[2].Text; got != "assigned to carol: please confirm with owner" {
+		t.Errorf("Unexpected assign note %q", got)
+	}
+	if reloaded.Get("pv-2") != nil {
+		t.Error("Expected no state for unknown violation")
+	}
+}
+
+func TestStoreGetReturnsCopy(t *testing.T) {
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if _, err := store.Acknowledge("pv-1", "alice", "note", time.Now()); err != nil {
+		t.Fatalf("Failed to acknowledge: %v", err)
+	}
+
+	state := store.Get("pv-1")
+	state.Acknowledged = false
+	state.Notes[0].Text = "changed"
+	if got := store.Get("pv-1"); !got.Acknowledged || got.Notes[0].Text != "note" {
+		t.Errorf("Expected stored state to be unaffected, got %+v", got)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	now := time.Now()
+	for _, id := range []string{"pv-1", "pv-2"} {
+		if _, err := store.Acknowledge(id, "alice", "", now); err != nil {
+			t.Fatalf("Failed to acknowledge %s: %v", id, err)
+		}
+	}
+
+	if err := store.Prune(map[string]struct{}{"pv-2": {}}); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+	if reloaded.Get("pv-1") != nil {
+		t.Error("Expected cleared violation to be pruned")
+	}
+	if reloaded.Get("pv-2") == nil {
+		t.Error("Expected active violation to be kept")
+	}
+}
+
+func TestStoreRollsBackOnSaveFailure(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "state")
+	store, err := NewStore(filepath.Join(blocker, "triage.json"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	// 状态目录的位置被普通文件占用，保存必然失败
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create blocker file: %v", err)
+	}
+
+	if _, err := store.Acknowledge("pv-1", "alice", "", time.Now()); err == nil {
+		t.Fatal("Expected acknowledgement to fail")
+	}
+	if store.Get("pv-1") != nil {
+		t.Error("Expected failed update to be rolled back")
+	}
+}
+
+func TestNewStoreRejectsCorruptState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	if _, err := NewStore(path); err == nil {
+		t.Error("Expected corrupt state to be rejected")
+	}
+}
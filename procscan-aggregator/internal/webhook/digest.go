@@ -0,0 +1,321 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	// 镜像中不一定有时区数据，内嵌一份保证 timezone 可用
+	_ "time/tzdata"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/config"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSummary 未配置 summary 时的摘要文本
+const defaultSummary = `ProcScan digest {{ .Digest }}: {{ .Total }} open violations in {{ len .Namespaces }} namespaces
+{{- range .Namespaces }}
+{{ .Namespace }} ({{ .Total }})
+{{- range .Rules }}
+  - {{ .Rule }}: {{ len .Violations }}
+{{- end }}
+{{- end }}`
+
+// ViolationSource 提供当前的聚合结果
+type ViolationSource interface {
+	GetViolations() *models.AggregatedViolations
+}
+
+// TriageSource 提供违规的确认状态
+type TriageSource interface {
+	Get(id string) *models.TriageState
+}
+
+// DigestPayload 渲染摘要模板时的数据，未配置模板时直接序列化为 JSON
+type DigestPayload struct {
+	Digest       string             `json:"digest"`
+	Timestamp    time.Time          `json:"timestamp"`
+	UpdateTime   time.Time          `json:"update_time"`  // 摘要所用聚合结果的时间
+	Total        int                `json:"total"`        // 摘要中的违规数
+	Acknowledged int                `json:"acknowledged"` // 匹配但因已确认而未列出的违规数
+	Namespaces   []*NamespaceDigest `json:"namespaces"`   // 按命名空间排序
+	Summary      string             `json:"summary"`      // 渲染后的摘要文本
+}
+
+// NamespaceDigest 一个命名空间的违规，按规则分组
+type NamespaceDigest struct {
+	Namespace string        `json:"namespace"`
+	Total     int           `json:"total"`
+	Rules     []*RuleDigest `json:"rules"` // 违规多的规则在前
+}
+
+// RuleDigest 命中同一规则的违规
+type RuleDigest struct {
+	Rule       string                    `json:"rule"`
+	Violations []*models.ViolationRecord `json:"violations"`
+}
+
+// Digester 按各自的计划发送定时摘要，每个摘要独立调度
+type Digester struct {
+	digests []*digest
+	source  ViolationSource
+	triage  TriageSource
+	client  *http.Client
+	now     func() time.Time
+}
+
+type digest struct {
+	*target
+	times               []int // 一天中的发送时间，单位分钟，升序
+	weekdays            map[time.Weekday]bool
+	location            *time.Location
+	includeAcknowledged bool
+	sendEmpty           bool
+	summary             *template.Template
+}
+
+// NewDigester 根据配置创建摘要调度器，triage 为 nil 时所有违规都视为未确认
+func NewDigester(configs []models.DigestConfig, source ViolationSource, triage TriageSource) (*Digester, error) {
+	d := &Digester{source: source, triage: triage, client: &http.Client{}, now: time.Now}
+	for _, cfg := range configs {
+		dg, err := newDigest(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid digest '%s': %w", cfg.Name, err)
+		}
+		d.digests = append(d.digests, dg)
+	}
+	return d, nil
+}
+
+func newDigest(cfg models.DigestConfig) (*digest, error) {
+	// 请求的发送和重试与 Webhook 相同
+	t, err := newTarget(models.WebhookConfig{
+		Name:          cfg.Name,
+		URL:           cfg.URL,
+		Method:        cfg.Method,
+		Headers:       cfg.Headers,
+		Namespaces:    cfg.Namespaces,
+		Rules:         cfg.Rules,
+		Template:      cfg.Template,
+		Timeout:       cfg.Timeout,
+		MaxRetries:    cfg.MaxRetries,
+		RetryInterval: cfg.RetryInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	dg := &digest{
+		target:              t,
+		weekdays:            make(map[time.Weekday]bool, len(cfg.Weekdays)),
+		location:            time.Local,
+		includeAcknowledged: cfg.IncludeAcknowledged,
+		sendEmpty:           cfg.SendEmpty,
+	}
+
+	if len(cfg.Schedule) == 0 {
+		return nil, fmt.Errorf("schedule is required")
+	}
+	for _, at := range cfg.Schedule {
+		parsed, err := time.Parse("15:04", at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: must be HH:MM", at)
+		}
+		dg.times = append(dg.times, parsed.Hour()*60+parsed.Minute())
+	}
+	sort.Ints(dg.times)
+	for _, day := range cfg.Weekdays {
+		weekday, ok := config.Weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", day)
+		}
+		dg.weekdays[weekday] = true
+	}
+	if cfg.Timezone != "" {
+		if dg.location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	summary := cfg.Summary
+	if summary == "" {
+		summary = defaultSummary
+	}
+	if dg.summary, err = template.New(cfg.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(summary); err != nil {
+		return nil, fmt.Errorf("invalid summary: %w", err)
+	}
+	return dg, nil
+}
+
+// Start 为每个摘要启动调度协程，ctx 取消后停止。错过的发送时间不会补发
+func (d *Digester) Start(ctx context.Context) {
+	for _, dg := range d.digests {
+		go func(dg *digest) {
+			for {
+				next := dg.next(d.now())
+				logger.L.WithFields(logrus.Fields{
+					"digest": dg.name,
+					"next":   next.Format(time.RFC3339),
+				}).Debug("Digest scheduled")
+
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				if err := d.Send(ctx, dg.name); err != nil {
+					logger.L.WithFields(logrus.Fields{
+						"digest": dg.name,
+						"error":  err.Error(),
+					}).Error("Failed to deliver digest")
+				}
+			}
+		}(dg)
+	}
+}
+
+// Send 立即发送指定摘要，没有违规且未开启 send_empty 时跳过
+func (d *Digester) Send(ctx context.Context, name string) error {
+	for _, dg := range d.digests {
+		if dg.name != name {
+			continue
+		}
+		payload := d.build(dg, d.now())
+		if payload.Total == 0 && !dg.sendEmpty {
+			logger.L.WithField("digest", dg.name).Debug("No open violations, skipping digest")
+			return nil
+		}
+		body, err := dg.render(payload)
+		if err != nil {
+			return err
+		}
+		attempts, err := dg.post(ctx, d.client, body)
+		if err != nil {
+			return err
+		}
+		logger.L.WithFields(logrus.Fields{
+			"digest":     dg.name,
+			"violations": payload.Total,
+			"namespaces": len(payload.Namespaces),
+			"attempts":   attempts,
+		}).Info("Digest delivered")
+		return nil
+	}
+	return fmt.Errorf("unknown digest '%s'", name)
+}
+
+// next 返回 now 之后的第一个发送时间
+func (dg *digest) next(now time.Time) time.Time {
+	local := now.In(dg.location)
+	year, month, day := local.Date()
+	// 一周内必然有允许的星期，多检查一天覆盖当天时间已过的情况
+	for offset := 0; offset <= 7; offset++ {
+		date := time.Date(year, month, day+offset, 0, 0, 0, 0, dg.location)
+		if len(dg.weekdays) > 0 && !dg.weekdays[date.Weekday()] {
+			continue
+		}
+		for _, minutes := range dg.times {
+			at := time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, dg.location)
+			if at.After(now) {
+				return at
+			}
+		}
+	}
+	return now.Add(24 * time.Hour)
+}
+
+// build 汇总匹配该摘要的违规，按命名空间和规则分组
+func (d *Digester) build(dg *digest, now time.Time) *DigestPayload {
+	aggregated := d.source.GetViolations()
+	payload := &DigestPayload{
+		Digest:     dg.name,
+		Timestamp:  now,
+		UpdateTime: aggregated.UpdateTime,
+		Namespaces: make([]*NamespaceDigest, 0),
+	}
+
+	namespaces := make(map[string]*NamespaceDigest)
+	rules := make(map[string]*RuleDigest)
+	for _, record := range aggregated.Violations {
+		if !matchAny(dg.namespaces, record.Namespace) || !matchAny(dg.rules, record.Regex) {
+			continue
+		}
+		if d.triage != nil && !dg.includeAcknowledged {
+			if state := d.triage.Get(record.ID()); state != nil && state.Acknowledged {
+				payload.Acknowledged++
+				continue
+			}
+		}
+
+		ns, ok := namespaces[record.Namespace]
+		if !ok {
+			ns = &NamespaceDigest{Namespace: record.Namespace}
+			namespaces[record.Namespace] = ns
+			payload.Namespaces = append(payload.Namespaces, ns)
+		}
+		key := record.Namespace + "\x00" + record.Regex
+		rule, ok := rules[key]
+		if !ok {
+			rule = &RuleDigest{Rule: record.Regex}
+			rules[key] = rule
+			ns.Rules = append(ns.Rules, rule)
+		}
+		rule.Violations = append(rule.Violations, record)
+		ns.Total++
+		payload.Total++
+	}
+
+	sort.Slice(payload.Namespaces, func(i, j int) bool {
+		return payload.Namespaces[i].Namespace < payload.Namespaces[j].Namespace
+	})
+	for _, ns := range payload.Namespaces {
+		sort.SliceStable(ns.Rules, func(i, j int) bool {
+			if len(ns.Rules[i].Violations) != len(ns.Rules[j].Violations) {
+				return len(ns.Rules[i].Violations) > len(ns.Rules[j].Violations)
+			}
+			return ns.Rules[i].Rule < ns.Rules[j].Rule
+		})
+		for _, rule := range ns.Rules {
+			sort.Slice(rule.Violations, func(i, j int) bool {
+				return rule.Violations[i].Key() < rule.Violations[j].Key()
+			})
+		}
+	}
+	return payload
+}
+
+// render 先渲染摘要文本，再将其作为 .Summary 渲染请求体
+func (dg *digest) render(payload *DigestPayload) ([]byte, error) {
+	var summary bytes.Buffer
+	if err := dg.summary.Execute(&summary, payload); err != nil {
+		return nil, fmt.Errorf("failed to render summary: %w", err)
+	}
+	payload.Summary = summary.String()
+
+	var buf bytes.Buffer
+	if err := dg.tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
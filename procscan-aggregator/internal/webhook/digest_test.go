@@ -0,0 +1,175 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+type staticSource struct {
+	violations []*models.ViolationRecord
+}
+
+func (s *staticSource) GetViolations() *models.AggregatedViolations {
+	return &models.AggregatedViolations{Violations: s.violations, UpdateTime: time.Now()}
+}
+
+type staticTriage map[string]*models.TriageState
+
+func (s staticTriage) Get(id string) *models.TriageState {
+	return s[id]
+}
+
+func digestConfig(name, url string) models.DigestConfig {
+	return models.DigestConfig{
+		Name:          name,
+		URL:           url,
+		Schedule:      []string{"09:00"},
+		MaxRetries:    3,
+		RetryInterval: "10ms",
+	}
+}
+
+func TestDigestNext(t *testing.T) {
+	cfg := digestConfig("daily", "http://example.com")
+	cfg.Schedule = []string{"18:00", "09:00"}
+	cfg.Weekdays = []string{"mon", "FRI"}
+	cfg.Timezone = "Asia/Shanghai"
+	dg, err := newDigest(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create digest: %v", err)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		// 周一早上发送前
+		{time.Date(2025, 6, 2, 8, 0, 0, 0, shanghai), time.Date(2025, 6, 2, 9, 0, 0, 0, shanghai)},
+		// 周一两次发送之间
+		{time.Date(2025, 6, 2, 9, 0, 0, 0, shanghai), time.Date(2025, 6, 2, 18, 0, 0, 0, shanghai)},
+		// 周一晚上之后跳到周五
+		{time.Date(2025, 6, 2, 19, 0, 0, 0, shanghai), time.Date(2025, 6, 6, 9, 0, 0, 0, shanghai)},
+		// UTC 周日 23:30 已是上海周一 07:30
+		{time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC), time.Date(2025, 6, 2, 9, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		if got := dg.next(tt.now); !got.Equal(tt.want) {
+			t.Errorf("next(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDigestGroupsOpenViolations(t *testing.T) {
+	chat := &recorder{}
+	server := httptest.NewServer(chat)
+	defer server.Close()
+
+	acked := &models.ViolationRecord{Namespace: "ns-prod-a", Pod: "p3", Process: "nc", Regex: "^nc$"}
+	source := &staticSource{violations: []*models.ViolationRecord{
+		{Namespace: "ns-prod-b", Pod: "p1", Process: "xmrig", Regex: "^xmrig$"},
+		{Namespace: "ns-prod-a", Pod: "p1", Process: "xmrig", Regex: "^xmrig$"},
+		{Namespace: "ns-prod-a", Pod: "p2", Process: "xmrig", Regex: "^xmrig$"},
+		{Namespace: "ns-prod-a", Pod: "p4", Process: "kinsing", Regex: "kinsing"},
+		{Namespace: "ns-dev", Pod: "p5", Process: "xmrig", Regex: "^xmrig$"},
+		acked,
+	}}
+	triage := staticTriage{acked.ID(): {Acknowledged: true}}
+
+	cfg := digestConfig("prod", server.URL)
+	cfg.Namespaces = []string{"^ns-prod-"}
+	d, err := NewDigester([]models.DigestConfig{cfg}, source, triage)
+	if err != nil {
+		t.Fatalf("Failed to create digester: %v", err)
+	}
+	if err := d.Send(context.Background(), "prod"); err != nil {
+		t.Fatalf("Failed to send digest: %v", err)
+	}
+
+	bodies := chat.received()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 digest, got %d", len(bodies))
+	}
+	var payload DigestPayload
+	if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil {
+		t.Fatalf("Digest is not JSON: %v", err)
+	}
+	if payload.Total != 4 || payload.Acknowledged != 1 {
+		t.Errorf("Expected 4 open and 1 acknowledged violations, got %d and %d", payload.Total, payload.Acknowledged)
+	}
+	if len(payload.Namespaces) != 2 || payload.Namespaces[0].Namespace != "ns-prod-a" {
+		t.Fatalf("Unexpected namespaces: %+v", payload.Namespaces)
+	}
+	rules := payload.Namespaces[0].Rules
+	if len(rules) != 2 || rules[0].Rule != "^xmrig$" || len(rules[0].Violations) != 2 {
+		t.Errorf("Expected the rule with most violations first, got %+v", rules)
+	}
+	if !strings.Contains(payload.Summary, "ns-prod-a (3)") || !strings.Contains(payload.Summary, "  - ^xmrig$: 2") {
+		t.Errorf("Unexpected summary:\n%s", payload.Summary)
+	}
+}
+
+func TestDigestTemplatesAndEmptyDigests(t *testing.T) {
+	chat := &recorder{}
+	server := httptest.NewServer(chat)
+	defer server.Close()
+
+	source := &staticSource{}
+	quiet := digestConfig("quiet", server.URL)
+	lark := digestConfig("lark", server.URL)
+	lark.SendEmpty = true
+	lark.Summary = `{{ .Total }} open violations`
+	lark.Template = `{"msg_type": "text", "content": {"text": {{ json .Summary }}}}`
+	d, err := NewDigester([]models.DigestConfig{quiet, lark}, source, nil)
+	if err != nil {
+		t.Fatalf("Failed to create digester: %v", err)
+	}
+
+	for _, name := range []string{"quiet", "lark"} {
+		if err := d.Send(context.Background(), name); err != nil {
+			t.Fatalf("Failed to send digest %s: %v", name, err)
+		}
+	}
+	bodies := chat.received()
+	if len(bodies) != 1 || bodies[0] != `{"msg_type": "text", "content": {"text": "0 open violations"}}` {
+		t.Errorf("Expected only the lark digest, got %v", bodies)
+	}
+	if err := d.Send(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for an unknown digest")
+	}
+}
+
+func TestNewDigesterRejectsInvalidConfig(t *testing.T) {
+	for _, mutate := range []func(*models.DigestConfig){
+		func(c *models.DigestConfig) { c.Schedule = []string{"9am"} },
+		func(c *models.DigestConfig) { c.Weekdays = []string{"someday"} },
+		func(c *models.DigestConfig) { c.Timezone = "Mars/Olympus" },
+		func(c *models.DigestConfig) { c.Summary = "{{ .Total" },
+	} {
+		cfg := digestConfig("bad", "http://example.com")
+		mutate(&cfg)
+		if _, err := NewDigester([]models.DigestConfig{cfg}, &staticSource{}, nil); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
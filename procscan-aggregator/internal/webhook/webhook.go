@@ -0,0 +1,362 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook 将违规变化按命名空间和规则路由到出站 Webhook
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/logger"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// 违规事件类型
+const (
+	EventAdded   = "added"
+	EventChanged = "changed"
+	EventCleared = "cleared"
+)
+
+// Event 一条违规记录的变化
+type Event struct {
+	Type      string
+	Violation *models.ViolationRecord
+	Timestamp time.Time
+}
+
+// Payload 渲染请求体模板时的数据，未配置模板时直接序列化为 JSON
+type Payload struct {
+	Webhook   string                  `json:"webhook"`
+	Event     string                  `json:"event"`
+	Violation *models.ViolationRecord `json:"violation"`
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+// templateFuncs 模板中可用的函数，json 用于安全地嵌入字符串和对象，env 读取环境变量中的密钥
+var templateFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+const defaultTemplate = `{{ json . }}`
+
+// Diff 比较前后两次聚合结果，返回新增、变化和清除的违规事件
+func Diff(previous, current []*models.ViolationRecord, now time.Time) []Event {
+	before := make(map[string]*models.ViolationRecord, len(previous))
+	for _, record := range previous {
+		before[record.Key()] = record
+	}
+
+	var events []Event
+	seen := make(map[string]struct{}, len(current))
+	for _, record := range current {
+		key := record.Key()
+		seen[key] = struct{}{}
+		old, ok := before[key]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventAdded, Violation: record, Timestamp: now})
+		case changed(old, record):
+			events = append(events, Event{Type: EventChanged, Violation: record, Timestamp: now})
+		}
+	}
+	for key, record := range before {
+		if _, ok := seen[key]; !ok {
+			events = append(events, Event{Type: EventCleared, Violation: record, Timestamp: now})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Violation.Key() < events[j].Violation.Key()
+	})
+	return events
+}
+
+func changed(old, current *models.ViolationRecord) bool {
+	return old.Cmdline != current.Cmdline ||
+		old.Regex != current.Regex ||
+		old.Status != current.Status ||
+		old.Type != current.Type ||
+		old.Name != current.Name
+}
+
+// Dispatcher 将违规事件分发到匹配的 Webhook，每个 Webhook 独立排队和重试
+type Dispatcher struct {
+	targets []*target
+	client  *http.Client
+}
+
+type target struct {
+	name          string
+	url           string
+	method        string
+	headers       map[string]string
+	namespaces    []*regexp.Regexp
+	rules         []*regexp.Regexp
+	events        map[string]bool
+	tmpl          *template.Template
+	timeout       time.Duration
+	maxRetries    int
+	retryInterval time.Duration
+	queue         chan Event
+}
+
+// NewDispatcher 根据配置创建分发器，URL 和请求头中的 ${ENV} 会被替换为环境变量
+func NewDispatcher(configs []models.WebhookConfig) (*Dispatcher, error) {
+	d := &Dispatcher{client: &http.Client{}}
+	for _, cfg := range configs {
+		t, err := newTarget(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook '%s': %w", cfg.Name, err)
+		}
+		d.targets = append(d.targets, t)
+	}
+	return d, nil
+}
+
+func newTarget(cfg models.WebhookConfig) (*target, error) {
+	t := &target{
+		name:       cfg.Name,
+		url:        os.ExpandEnv(cfg.URL),
+		method:     cfg.Method,
+		headers:    make(map[string]string, len(cfg.Headers)),
+		events:     make(map[string]bool, len(cfg.Events)),
+		maxRetries: cfg.MaxRetries,
+		queue:      make(chan Event, max(cfg.QueueSize, 1)),
+	}
+	if t.method == "" {
+		t.method = http.MethodPost
+	}
+	for key, value := range cfg.Headers {
+		t.headers[key] = os.ExpandEnv(value)
+	}
+	for _, event := range cfg.Events {
+		t.events[event] = true
+	}
+
+	var err error
+	if t.namespaces, err = compileAll(cfg.Namespaces); err != nil {
+		return nil, fmt.Errorf("invalid namespace pattern: %w", err)
+	}
+	if t.rules, err = compileAll(cfg.Rules); err != nil {
+		return nil, fmt.Errorf("invalid rule pattern: %w", err)
+	}
+
+	body := cfg.Template
+	if body == "" {
+		body = defaultTemplate
+	}
+	if t.tmpl, err = template.New(cfg.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	if t.timeout, err = parseDuration(cfg.Timeout, 10*time.Second); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if t.retryInterval, err = parseDuration(cfg.RetryInterval, 5*time.Second); err != nil {
+		return nil, fmt.Errorf("invalid retry_interval: %w", err)
+	}
+	return t, nil
+}
+
+// Start 为每个 Webhook 启动发送协程，ctx 取消后停止
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, t := range d.targets {
+		go func(t *target) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-t.queue:
+					if err := d.deliver(ctx, t, event); err != nil {
+						logger.L.WithFields(logrus.Fields{
+							"webhook":   t.name,
+							"event":     event.Type,
+							"namespace": event.Violation.Namespace,
+							"pod":       event.Violation.Pod,
+							"process":   event.Violation.Process,
+							"error":     err.Error(),
+						}).Error("Failed to deliver webhook")
+					}
+				}
+			}
+		}(t)
+	}
+}
+
+// Dispatch 将事件放入所有匹配 Webhook 的队列，队列已满时丢弃并记录日志
+func (d *Dispatcher) Dispatch(events []Event) {
+	for _, event := range events {
+		for _, t := range d.targets {
+			if !t.matches(event) {
+				continue
+			}
+			select {
+			case t.queue <- event:
+			default:
+				logger.L.WithFields(logrus.Fields{
+					"webhook":   t.name,
+					"event":     event.Type,
+					"namespace": event.Violation.Namespace,
+				}).Warn("Webhook queue is full, dropping event")
+			}
+		}
+	}
+}
+
+// matches 判断事件是否路由到该 Webhook，命名空间和规则都需匹配
+func (t *target) matches(event Event) bool {
+	if !t.events[event.Type] {
+		return false
+	}
+	return matchAny(t.namespaces, event.Violation.Namespace) && matchAny(t.rules, event.Violation.Regex)
+}
+
+// deliver 发送事件，网络错误、429 和 5xx 按指数退避重试
+func (d *Dispatcher) deliver(ctx context.Context, t *target, event Event) error {
+	body, err := t.render(event)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := t.post(ctx, d.client, body)
+	if err != nil {
+		return err
+	}
+	logger.L.WithFields(logrus.Fields{
+		"webhook":   t.name,
+		"event":     event.Type,
+		"namespace": event.Violation.Namespace,
+		"attempts":  attempts,
+	}).Debug("Webhook delivered")
+	return nil
+}
+
+// post 发送请求体并返回尝试次数，网络错误、429 和 5xx 按指数退避重试
+func (t *target) post(ctx context.Context, client *http.Client, body []byte) (int, error) {
+	backoff := t.retryInterval
+	for attempt := 0; ; attempt++ {
+		retryable, err := t.send(ctx, client, body)
+		if err == nil {
+			return attempt + 1, nil
+		}
+		if !retryable || attempt >= t.maxRetries {
+			return attempt + 1, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.L.WithFields(logrus.Fields{
+			"webhook": t.name,
+			"attempt": attempt + 1,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		}).Warn("Webhook delivery failed, retrying")
+		select {
+		case <-ctx.Done():
+			return attempt + 1, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (t *target) send(ctx context.Context, client *http.Client, body []byte) (retryable bool, err error) {
+	reqCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, t.method, t.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+}
+
+func (t *target) render(event Event) ([]byte, error) {
+	var buf bytes.Buffer
+	payload := Payload{
+		Webhook:   t.name,
+		Event:     event.Type,
+		Violation: event.Violation,
+		Timestamp: event.Timestamp,
+	}
+	if err := t.tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matchAny 没有配置模式时匹配全部
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
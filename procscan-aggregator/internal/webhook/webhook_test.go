@@ -0,0 +1,265 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// recorder 记录收到的请求体，前 failures 次请求返回 status
+type recorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	calls    atomic.Int32
+	failures int32
+	status   int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.calls.Add(1) <= r.failures {
+		w.WriteHeader(r.status)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header.Clone())
+	r.mu.Unlock()
+}
+
+func (r *recorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for webhook delivery")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func webhookConfig(name, url string) models.WebhookConfig {
+	return models.WebhookConfig{
+		Name:          name,
+		URL:           url,
+		Events:        []string{EventAdded, EventChanged, EventCleared},
+		MaxRetries:    3,
+		RetryInterval: "10ms",
+		QueueSize:     10,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	previous := []*models.ViolationRecord{
+		{Namespace: "ns-a", Pod: "p1", Process: "xmrig", Cmdline: "xmrig -o pool"},
+		{Namespace: "ns-a", Pod: "p2", Process: "miner"},
+		{Namespace: "ns-b", Pod: "p3", Process: "nc"},
+	}
+	current := []*models.ViolationRecord{
+		{Namespace: "ns-a", Pod: "p1", Process: "xmrig", Cmdline: "xmrig -o other-pool"},
+		{Namespace: "ns-a", Pod: "p2", Process: "miner"},
+		{Namespace: "ns-c", Pod: "p4", Process: "kinsing"},
+	}
+
+	events := Diff(previous, current, now)
+	got := map[string]string{}
+	for _, event := range events {
+		got[event.Violation.Key()] = event.Type
+	}
+	want := map[string]string{
+		"ns-a/p1/xmrig":   EventChanged,
+		"ns-b/p3/nc":      EventCleared,
+		"ns-c/p4/kinsing": EventAdded,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d events, got %v", len(want), got)
+	}
+	for key, eventType := range want {
+		if got[key] != eventType {
+			t.Errorf("Expected %s to be %s, got %q", key, eventType, got[key])
+		}
+	}
+}
+
+func TestDispatchRoutesByNamespaceAndRule(t *testing.T) {
+	pager := &recorder{}
+	chat := &recorder{}
+	pagerServer := httptest.NewServer(pager)
+	defer pagerServer.Close()
+	chatServer := httptest.NewServer(chat)
+	defer chatServer.Close()
+
+	prod := webhookConfig("pagerduty", pagerServer.URL)
+	prod.Namespaces = []string{"^ns-prod-"}
+	prod.Rules = []string{"xmrig", "^web-server-shell$"}
+	prod.Events = []string{EventAdded}
+	dev := webhookConfig("slack", chatServer.URL)
+	dev.Namespaces = []string{"^ns-dev-"}
+
+	dispatcher, err := NewDispatcher([]models.WebhookConfig{prod, dev})
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher.Start(ctx)
+
+	now := time.Now()
+	dispatcher.Dispatch([]Event{
+		{Type: EventAdded, Timestamp: now, Violation: &models.ViolationRecord{Namespace: "ns-prod-a", Pod: "p1", Process: "xmrig", Regex: "^xmrig$"}},
+		{Type: EventAdded, Timestamp: now, Violation: &models.ViolationRecord{Namespace: "ns-prod-a", Pod: "p2", Process: "nc", Regex: "^nc.*-l.*-p"}},
+		{Type: EventCleared, Timestamp: now, Violation: &models.ViolationRecord{Namespace: "ns-prod-b", Pod: "p3", Process: "sh", Regex: "^xmrig$"}},
+		{Type: EventAdded, Timestamp: now, Violation: &models.ViolationRecord{Namespace: "ns-dev-a", Pod: "p4", Process: "xmrig", Regex: "^xmrig$"}},
+		{Type: EventCleared, Timestamp: now, Violation: &models.ViolationRecord{Namespace: "ns-dev-a", Pod: "p5", Process: "miner"}},
+	})
+
+	waitFor(t, func() bool { return len(pager.received()) == 1 && len(chat.received()) == 2 })
+	time.Sleep(50 * time.Millisecond)
+	if len(pager.received()) != 1 {
+		t.Fatalf("Expected exactly one page, got %v", pager.received())
+	}
+
+	var payload Payload
+	if err := json.Unmarshal([]byte(pager.received()[0]), &payload); err != nil {
+		t.Fatalf("Default payload is not JSON: %v", err)
+	}
+	if payload.Webhook != "pagerduty" || payload.Event != EventAdded || payload.Violation.Pod != "p1" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+}
+
+func TestDispatchRendersTemplateAndHeaders(t *testing.T) {
+	t.Setenv("TEST_WEBHOOK_TOKEN", "secret-token")
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	cfg := webhookConfig("chat", server.URL)
+	cfg.Headers = map[string]string{"Authorization": "Bearer ${TEST_WEBHOOK_TOKEN}"}
+	cfg.Template = `{"key": {{ json (env "TEST_WEBHOOK_TOKEN") }}, "text": {{ json (printf "[%s] %s/%s %s" (upper .Event) .Violation.Namespace .Violation.Pod .Violation.Process) }}}`
+	dispatcher, err := NewDispatcher([]models.WebhookConfig{cfg})
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher.Start(ctx)
+
+	dispatcher.Dispatch([]Event{{
+		Type:      EventAdded,
+		Timestamp: time.Now(),
+		Violation: &models.ViolationRecord{Namespace: "ns-a", Pod: "app-1", Process: `mi"ner`},
+	}})
+
+	waitFor(t, func() bool { return len(rec.received()) == 1 })
+	want := `{"key": "secret-token", "text": "[ADDED] ns-a/app-1 mi\"ner"}`
+	if got := rec.received()[0]; got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if got := rec.headers[0].Get("Authorization"); got != "Bearer secret-token" {
+		t.Errorf("Expected expanded authorization header, got %q", got)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	violation := &models.ViolationRecord{Namespace: "ns-a", Pod: "p1", Process: "xmrig"}
+
+	t.Run("retries server errors", func(t *testing.T) {
+		rec := &recorder{failures: 2, status: http.StatusServiceUnavailable}
+		server := httptest.NewServer(rec)
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher([]models.WebhookConfig{webhookConfig("retry", server.URL)})
+		if err != nil {
+			t.Fatalf("Failed to create dispatcher: %v", err)
+		}
+		err = dispatcher.deliver(context.Background(), dispatcher.targets[0], Event{Type: EventAdded, Violation: violation})
+		if err != nil {
+			t.Fatalf("Expected delivery to succeed after retries: %v", err)
+		}
+		if calls := rec.calls.Load(); calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("gives up on client errors", func(t *testing.T) {
+		rec := &recorder{failures: 10, status: http.StatusBadRequest}
+		server := httptest.NewServer(rec)
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher([]models.WebhookConfig{webhookConfig("reject", server.URL)})
+		if err != nil {
+			t.Fatalf("Failed to create dispatcher: %v", err)
+		}
+		err = dispatcher.deliver(context.Background(), dispatcher.targets[0], Event{Type: EventAdded, Violation: violation})
+		if err == nil {
+			t.Fatal("Expected delivery to fail")
+		}
+		if calls := rec.calls.Load(); calls != 1 {
+			t.Errorf("Expected a single attempt, got %d", calls)
+		}
+	})
+
+	t.Run("stops after max retries", func(t *testing.T) {
+		rec := &recorder{failures: 10, status: http.StatusInternalServerError}
+		server := httptest.NewServer(rec)
+		defer server.Close()
+
+		cfg := webhookConfig("flaky", server.URL)
+		cfg.MaxRetries = 1
+		dispatcher, err := NewDispatcher([]models.WebhookConfig{cfg})
+		if err != nil {
+			t.Fatalf("Failed to create dispatcher: %v", err)
+		}
+		if err := dispatcher.deliver(context.Background(), dispatcher.targets[0], Event{Type: EventAdded, Violation: violation}); err == nil {
+			t.Fatal("Expected delivery to fail")
+		}
+		if calls := rec.calls.Load(); calls != 2 {
+			t.Errorf("Expected 2 attempts, got %d", calls)
+		}
+	})
+}
+
+func TestNewDispatcherRejectsInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]models.WebhookConfig{
+		"namespace pattern": {Name: "a", URL: "http://x", Namespaces: []string{"[invalid"}},
+		"rule pattern":      {Name: "b", URL: "http://x", Rules: []string{"(unclosed"}},
+		"template":          {Name: "c", URL: "http://x", Template: "{{ .Event "},
+	} {
+		if _, err := NewDispatcher([]models.WebhookConfig{cfg}); err == nil {
+			t.Errorf("Expected invalid %s to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client 提供聚合器 HTTP 接口的 Go 客户端，接口定义见 api/openapi.json
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+// Client 聚合器接口客户端
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient 创建客户端，baseURL 如 http://procscan-aggregator.kube-system:8090，
+// token 非空时确认和指派请求携带 Bearer Token，httpClient 为 nil 时使用 10 秒超时的默认客户端
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// ListOptions 违规列表的过滤和分页参数，零值表示不过滤
+type ListOptions struct {
+	IncludeAcknowledged bool
+	Assignee            string
+	Namespace           string
+	Node                string
+	Process             string
+	Since               time.Time
+	Limit               int // 0 表示返回全部
+	Offset              int
+}
+
+// Error 接口返回的错误
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("aggregator api error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound 判断错误是否为违规或路径不存在
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == models.ErrorCodeNotFound
+}
+
+// ListViolations 获取一页违规记录，Limit 为 0 时返回全部
+func (c *Client) ListViolations(ctx context.Context, opts ListOptions) (*models.ViolationList, error) {
+	query := url.Values{}
+	if opts.IncludeAcknowledged {
+		query.Set("include_acknowledged", "true")
+	}
+	for name, value := range map[string]string{
+		"assignee":  opts.Assignee,
+		"namespace": opts.Namespace,
+		"node":      opts.Node,
+		"process":   opts.Process,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	path := "/api/violations"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var list models.ViolationList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListAllViolations 按 pageSize 分页获取全部符合条件的违规记录，opts 中的 Limit 和 Offset 被忽略
+func (c *Client) ListAllViolations(ctx context.Context, opts ListOptions, pageSize int) ([]*models.ViolationView, error) {
+	opts.Limit, opts.Offset = pageSize, 0
+	var all []*models.ViolationView
+	for {
+		list, err := c.ListViolations(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list.Violations...)
+		if list.NextOffset == nil {
+			return all, nil
+		}
+		opts.Offset = *list.NextOffset
+	}
+}
+
+// GetViolation 获取单条违规记录，不存在时返回的错误满足 IsNotFound
+func (c *Client) GetViolation(ctx context.Context, id string) (*models.ViolationView, error) {
+	var view models.ViolationView
+	if err := c.do(ctx, http.MethodGet, "/api/violations/"+url.PathEscape(id), nil, &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListExceptions 获取命中 procscan 例外的记录
+func (c *Client) ListExceptions(ctx context.Context) (*models.ExceptionList, error) {
+	var list models.ExceptionList
+	if err := c.do(ctx, http.MethodGet, "/api/exceptions", nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetRules 获取下发给节点的检测规则，node 为空时返回稳定版本
+func (c *Client) GetRules(ctx context.Context, node string) (*models.DistributedRules, error) {
+	var rules models.DistributedRules
+	if err := c.do(ctx, http.MethodGet, "/api/rules?"+url.Values{"node": {node}}.Encode(), nil, &rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// GetSignatures 获取特征库订阅状态
+func (c *Client) GetSignatures(ctx context.Context) (*models.SignatureStatus, error) {
+	return c.signatures(ctx, http.MethodGet, "/api/signatures", nil)
+}
+
+// RollbackSignatures 回滚并固定到当前特征库版本的上一个版本
+func (c *Client) RollbackSignatures(ctx context.Context) (*models.SignatureStatus, error) {
+	return c.signatures(ctx, http.MethodPost, "/api/signatures/rollback", nil)
+}
+
+// PinSignatures 固定特征库版本，version 为空时恢复使用最新版本
+func (c *Client) PinSignatures(ctx context.Context, version string) (*models.SignatureStatus, error) {
+	return c.signatures(ctx, http.MethodPost, "/api/signatures/pin", models.SignaturePinRequest{Version: version})
+}
+
+// Acknowledge 确认违规
+func (c *Client) Acknowledge(ctx context.Context, id string, req models.TriageRequest) (*models.ViolationView, error) {
+	return c.triage(ctx, id, "ack", req)
+}
+
+// Assign 将违规指派给 req.Assignee
+func (c *Client) Assign(ctx context.Context, id string, req models.TriageRequest) (*models.ViolationView, error) {
+	return c.triage(ctx, id, "assign", req)
+}
+
+// Health 检查聚合器是否可用
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+func (c *Client) triage(ctx context.Context, id, action string, req models.TriageRequest) (*models.ViolationView, error) {
+	var view models.ViolationView
+	if err := c.do(ctx, http.MethodPost, "/api/violations/"+url.PathEscape(id)+"/"+action, req, &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (c *Client) signatures(ctx context.Context, method, path string, body any) (*models.SignatureStatus, error) {
+	var status models.SignatureStatus
+	if err := c.do(ctx, method, path, body, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// do 发送请求并解码响应，非 2xx 响应转换为 *Error
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var envelope models.ErrorResponse
+		if json.Unmarshal(data, &envelope) == nil && envelope.Error.Code != "" {
+			apiErr.Code, apiErr.Message = envelope.Error.Code, envelope.Error.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/api"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/internal/triage"
+	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
+)
+
+type staticSource struct {
+	violations []*models.ViolationRecord
+}
+
+func (s *staticSource) GetViolations() *models.AggregatedViolations {
+	return &models.AggregatedViolations{Violations: s.violations, UpdateTime: time.Now()}
+}
+
+func newTestServer(t *testing.T, token string) (*httptest.Server, []*models.ViolationRecord) {
+	t.Helper()
+	source := &staticSource{}
+	for i := range 5 {
+		source.violations = append(source.violations, &models.ViolationRecord{
+			Namespace: fmt.Sprintf("ns-%d", i%2),
+			Pod:       fmt.Sprintf("p%d", i),
+			Process:   "xmrig",
+			Timestamp: time.Now().Format(time.RFC3339),
+		})
+	}
+	store, err := triage.NewStore("")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	server := httptest.NewServer(api.NewHandler(source, store, token))
+	t.Cleanup(server.Close)
+	return server, source.violations
+}
+
+func TestListAllViolationsFollowsPages(t *testing.T) {
+	server, _ := newTestServer(t, "")
+	c := NewClient(server.URL+"/", "", nil)
+	ctx := context.Background()
+
+	page, err := c.ListViolations(ctx, ListOptions{Namespace: "ns-0", Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to list violations: %v", err)
+	}
+	if page.MatchedCount != 3 || len(page.Violations) != 2 || page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Errorf("Unexpected first page: %+v", page)
+	}
+
+	all, err := c.ListAllViolations(ctx, ListOptions{Since: time.Now().Add(-time.Hour)}, 2)
+	if err != nil {
+		t.Fatalf("Failed to list all violations: %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("Expected 5 violations, got %d", len(all))
+	}
+}
+
+func TestTriageAndErrors(t *testing.T) {
+	server, violations := newTestServer(t, "secret")
+	ctx := context.Background()
+	id := violations[0].ID()
+
+	_, err := NewClient(server.URL, "", nil).Acknowledge(ctx, id, models.TriageRequest{By: "alice"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != models.ErrorCodeUnauthorized {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	c := NewClient(server.URL, "secret", nil)
+	view, err := c.Assign(ctx, id, models.TriageRequest{By: "bob", Assignee: "carol"})
+	if err != nil {
+		t.Fatalf("Failed to assign violation: %v", err)
+	}
+	if view.ID != id || view.Triage == nil || view.Triage.Assignee != "carol" {
+		t.Errorf("Unexpected assign response: %+v", view)
+	}
+
+	view, err = c.GetViolation(ctx, id)
+	if err != nil || view.Triage.AssignedBy != "bob" {
+		t.Errorf("Expected assigned violation, got %+v, %v", view, err)
+	}
+	if _, err := c.GetViolation(ctx, "pv-unknown"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := c.Health(ctx); err != nil {
+		t.Errorf("Expected healthy aggregator, got %v", err)
+	}
+}
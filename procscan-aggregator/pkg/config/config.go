@@ -18,6 +18,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/procscan-aggregator/pkg/models"
@@ -52,6 +53,9 @@ func setDefaults(config *models.Config) {
 	if config.Aggregator.ScanInterval == "" {
 		config.Aggregator.ScanInterval = "60s"
 	}
+	if config.Aggregator.FullSyncInterval == "" {
+		config.Aggregator.FullSyncInterval = "1h"
+	}
 	if config.Aggregator.Port == 0 {
 		config.Aggregator.Port = 8090
 	}
@@ -67,6 +71,65 @@ func setDefaults(config *models.Config) {
 	if config.DaemonSet.APIPath == "" {
 		config.DaemonSet.APIPath = "/api/violations"
 	}
+	if config.DaemonSet.DeltaPath == "" {
+		config.DaemonSet.DeltaPath = "/api/violations/delta"
+	}
+	if config.CRD.TTL == "" {
+		config.CRD.TTL = "24h"
+	}
+	// Webhook 默认值
+	for i := range config.Webhooks {
+		webhook := &config.Webhooks[i]
+		if webhook.Method == "" {
+			webhook.Method = "POST"
+		}
+		if len(webhook.Events) == 0 {
+			webhook.Events = []string{"added", "changed", "cleared"}
+		}
+		if webhook.Timeout == "" {
+			webhook.Timeout = "10s"
+		}
+		if webhook.MaxRetries == 0 {
+			webhook.MaxRetries = 3
+		}
+		if webhook.RetryInterval == "" {
+			webhook.RetryInterval = "5s"
+		}
+		if webhook.QueueSize == 0 {
+			webhook.QueueSize = 1000
+		}
+	}
+	// 定时摘要默认值
+	for i := range config.Digests {
+		digest := &config.Digests[i]
+		if digest.Method == "" {
+			digest.Method = "POST"
+		}
+		if digest.Timeout == "" {
+			digest.Timeout = "10s"
+		}
+		if digest.MaxRetries == 0 {
+			digest.MaxRetries = 3
+		}
+		if digest.RetryInterval == "" {
+			digest.RetryInterval = "5s"
+		}
+	}
+	if config.Triage.StatePath == "" {
+		config.Triage.StatePath = "/data/triage.json"
+	}
+	if config.Signatures.Interval == "" {
+		config.Signatures.Interval = "1h"
+	}
+	if config.Signatures.Timeout == "" {
+		config.Signatures.Timeout = "30s"
+	}
+	if config.Signatures.StatePath == "" {
+		config.Signatures.StatePath = "/data/signatures.json"
+	}
+	if config.Signatures.History == 0 {
+		config.Signatures.History = 5
+	}
 	if config.Logger.Level == "" {
 		config.Logger.Level = "info"
 	}
@@ -82,6 +145,18 @@ func validateConfig(config *models.Config) error {
 		return fmt.Errorf("invalid scan_interval '%s': %w", config.Aggregator.ScanInterval, err)
 	}
 
+	if config.Aggregator.FullSyncInterval != "" {
+		if _, err := time.ParseDuration(config.Aggregator.FullSyncInterval); err != nil {
+			return fmt.Errorf("invalid full_sync_interval '%s': %w", config.Aggregator.FullSyncInterval, err)
+		}
+	}
+
+	if config.CRD.TTL != "" {
+		if _, err := time.ParseDuration(config.CRD.TTL); err != nil {
+			return fmt.Errorf("invalid crd ttl '%s': %w", config.CRD.TTL, err)
+		}
+	}
+
 	// 验证端口范围
 	if config.Aggregator.Port < 1 || config.Aggregator.Port > 65535 {
 		return fmt.Errorf("invalid aggregator port %d: must be between 1 and 65535", config.Aggregator.Port)
@@ -100,10 +175,140 @@ func validateConfig(config *models.Config) error {
 		return fmt.Errorf("daemonset service_name is required")
 	}
 
+	if err := validateWebhooks(config.Webhooks); err != nil {
+		return err
+	}
+	if err := validateDigests(config.Digests); err != nil {
+		return err
+	}
+	return validateSignatures(config.Signatures)
+}
+
+// validateSignatures 验证特征库订阅配置，公钥在创建订阅时解析
+func validateSignatures(signatures models.SignaturesConfig) error {
+	if signatures.URL == "" {
+		return nil
+	}
+	if !strings.HasPrefix(signatures.URL, "https://") {
+		return fmt.Errorf("invalid signatures url '%s': must use https", signatures.URL)
+	}
+	if signatures.PublicKey == "" {
+		return fmt.Errorf("signatures public_key is required")
+	}
+	interval, err := time.ParseDuration(signatures.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid signatures interval '%s': %w", signatures.Interval, err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid signatures interval '%s': must be positive", signatures.Interval)
+	}
+	if _, err := time.ParseDuration(signatures.Timeout); err != nil {
+		return fmt.Errorf("invalid signatures timeout '%s': %w", signatures.Timeout, err)
+	}
+	if signatures.History < 1 {
+		return fmt.Errorf("invalid signatures history %d: must be at least 1", signatures.History)
+	}
+	return nil
+}
+
+// validateWebhooks 验证出站 Webhook 配置，正则和模板在创建分发器时编译
+func validateWebhooks(webhooks []models.WebhookConfig) error {
+	names := make(map[string]struct{}, len(webhooks))
+	for i, webhook := range webhooks {
+		if webhook.Name == "" {
+			return fmt.Errorf("webhooks[%d] name is required", i)
+		}
+		if _, ok := names[webhook.Name]; ok {
+			return fmt.Errorf("duplicate webhook name '%s'", webhook.Name)
+		}
+		names[webhook.Name] = struct{}{}
+
+		if webhook.URL == "" {
+			return fmt.Errorf("webhook '%s' url is required", webhook.Name)
+		}
+		if _, err := time.ParseDuration(webhook.Timeout); err != nil {
+			return fmt.Errorf("invalid webhook '%s' timeout '%s': %w", webhook.Name, webhook.Timeout, err)
+		}
+		if _, err := time.ParseDuration(webhook.RetryInterval); err != nil {
+			return fmt.Errorf("invalid webhook '%s' retry_interval '%s': %w", webhook.Name, webhook.RetryInterval, err)
+		}
+		if webhook.MaxRetries < 0 {
+			return fmt.Errorf("invalid webhook '%s' max_retries %d: must not be negative", webhook.Name, webhook.MaxRetries)
+		}
+		for _, event := range webhook.Events {
+			switch event {
+			case "added", "changed", "cleared":
+			default:
+				return fmt.Errorf("invalid webhook '%s' event '%s': must be added, changed or cleared", webhook.Name, event)
+			}
+		}
+	}
 	return nil
 }
 
+// validateDigests 验证定时摘要配置，正则、模板和时区在创建摘要调度器时加载
+func validateDigests(digests []models.DigestConfig) error {
+	names := make(map[string]struct{}, len(digests))
+	for i, digest := range digests {
+		if digest.Name == "" {
+			return fmt.Errorf("digests[%d] name is required", i)
+		}
+		if _, ok := names[digest.Name]; ok {
+			return fmt.Errorf("duplicate digest name '%s'", digest.Name)
+		}
+		names[digest.Name] = struct{}{}
+
+		if digest.URL == "" {
+			return fmt.Errorf("digest '%s' url is required", digest.Name)
+		}
+		if len(digest.Schedule) == 0 {
+			return fmt.Errorf("digest '%s' schedule is required", digest.Name)
+		}
+		for _, at := range digest.Schedule {
+			if _, err := time.Parse("15:04", at); err != nil {
+				return fmt.Errorf("invalid digest '%s' schedule '%s': must be HH:MM", digest.Name, at)
+			}
+		}
+		for _, day := range digest.Weekdays {
+			if _, ok := Weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("invalid digest '%s' weekday '%s': must be one of mon, tue, wed, thu, fri, sat, sun", digest.Name, day)
+			}
+		}
+		if _, err := time.ParseDuration(digest.Timeout); err != nil {
+			return fmt.Errorf("invalid digest '%s' timeout '%s': %w", digest.Name, digest.Timeout, err)
+		}
+		if _, err := time.ParseDuration(digest.RetryInterval); err != nil {
+			return fmt.Errorf("invalid digest '%s' retry_interval '%s': %w", digest.Name, digest.RetryInterval, err)
+		}
+		if digest.MaxRetries < 0 {
+			return fmt.Errorf("invalid digest '%s' max_retries %d: must not be negative", digest.Name, digest.MaxRetries)
+		}
+	}
+	return nil
+}
+
+// Weekdays 定时摘要 weekdays 字段可用的取值
+var Weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
 // GetScanInterval 获取解析后的扫描间隔
 func GetScanInterval(config *models.Config) (time.Duration, error) {
 	return time.ParseDuration(config.Aggregator.ScanInterval)
 }
+
+// GetFullSyncInterval 获取解析后的全量对账间隔
+func GetFullSyncInterval(config *models.Config) (time.Duration, error) {
+	return time.ParseDuration(config.Aggregator.FullSyncInterval)
+}
+
+// GetCRDTTL 获取解析后的 ProcessViolation 保留时长
+func GetCRDTTL(config *models.Config) (time.Duration, error) {
+	return time.ParseDuration(config.CRD.TTL)
+}
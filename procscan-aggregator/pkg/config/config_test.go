@@ -127,6 +127,57 @@ daemonset:
 	if cfg.Logger.Level != "info" {
 		t.Errorf("Expected default log level 'info', got '%s'", cfg.Logger.Level)
 	}
+
+	if cfg.Aggregator.FullSyncInterval != "1h" {
+		t.Errorf("Expected default full_sync_interval '1h', got '%s'", cfg.Aggregator.FullSyncInterval)
+	}
+
+	if cfg.DaemonSet.DeltaPath != "/api/violations/delta" {
+		t.Errorf("Expected default delta_path '/api/violations/delta', got '%s'", cfg.DaemonSet.DeltaPath)
+	}
+
+	if cfg.Triage.StatePath != "/data/triage.json" {
+		t.Errorf("Expected default triage state_path '/data/triage.json', got '%s'", cfg.Triage.StatePath)
+	}
+}
+
+func TestLoadConfigWebhookDefaults(t *testing.T) {
+	configContent := `
+webhooks:
+  - name: "slack-dev"
+    url: "https://hooks.slack.com/services/${SLACK_PATH}"
+    namespaces: ["^ns-dev-"]
+`
+
+	tmpFile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(configContent); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	cfg, err := LoadConfig(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(cfg.Webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d", len(cfg.Webhooks))
+	}
+	webhook := cfg.Webhooks[0]
+	if webhook.Method != "POST" || webhook.Timeout != "10s" || webhook.RetryInterval != "5s" {
+		t.Errorf("Unexpected webhook defaults: %+v", webhook)
+	}
+	if webhook.MaxRetries != 3 || webhook.QueueSize != 1000 {
+		t.Errorf("Unexpected webhook retry defaults: %+v", webhook)
+	}
+	if len(webhook.Events) != 3 {
+		t.Errorf("Expected all events by default, got %v", webhook.Events)
+	}
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -165,6 +216,22 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid full sync interval",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval:     "60s",
+					FullSyncInterval: "soon",
+					Port:             8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid port",
 			config: &models.Config{
@@ -180,6 +247,112 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid webhook event",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval: "60s",
+					Port:         8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+				Webhooks: []models.WebhookConfig{{
+					Name:          "slack",
+					URL:           "https://hooks.slack.com/services/x",
+					Events:        []string{"resolved"},
+					Timeout:       "10s",
+					RetryInterval: "5s",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate webhook name",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval: "60s",
+					Port:         8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+				Webhooks: []models.WebhookConfig{
+					{Name: "slack", URL: "https://a", Timeout: "10s", RetryInterval: "5s"},
+					{Name: "slack", URL: "https://b", Timeout: "10s", RetryInterval: "5s"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid digest schedule",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval: "60s",
+					Port:         8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+				Digests: []models.DigestConfig{{
+					Name:          "daily",
+					URL:           "https://open.feishu.cn/open-apis/bot/v2/hook/x",
+					Schedule:      []string{"9am"},
+					Timeout:       "10s",
+					RetryInterval: "5s",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "signatures over http",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval: "60s",
+					Port:         8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+				Signatures: models.SignaturesConfig{
+					URL:       "http://signatures.example.com/feed.json",
+					PublicKey: "${SIGNATURE_PUBLIC_KEY}",
+					Interval:  "1h",
+					Timeout:   "30s",
+					History:   5,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "digest without schedule",
+			config: &models.Config{
+				Aggregator: models.AggregatorConfig{
+					ScanInterval: "60s",
+					Port:         8090,
+				},
+				DaemonSet: models.DaemonSetConfig{
+					Namespace:   "test",
+					ServiceName: "service",
+					APIPort:     9090,
+				},
+				Digests: []models.DigestConfig{{
+					Name:          "daily",
+					URL:           "https://open.feishu.cn/open-apis/bot/v2/hook/x",
+					Timeout:       "10s",
+					RetryInterval: "5s",
+				}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing namespace",
 			config: &models.Config{
@@ -1,18 +1,101 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // Config 聚合服务配置
 type Config struct {
 	Aggregator AggregatorConfig `yaml:"aggregator"`
 	DaemonSet  DaemonSetConfig  `yaml:"daemonset"`
 	Logger     LoggerConfig     `yaml:"logger"`
+	CRD        CRDConfig        `yaml:"crd"`
+	Webhooks   []WebhookConfig  `yaml:"webhooks"`
+	Digests    []DigestConfig   `yaml:"digests"`
+	Triage     TriageConfig     `yaml:"triage"`
+	Rules      RulesConfig      `yaml:"rules"`
+	Signatures SignaturesConfig `yaml:"signatures"`
+	Debug      DebugConfig      `yaml:"debug"`
+}
+
+// RulesConfig 检测规则下发配置
+type RulesConfig struct {
+	Path string `yaml:"path"` // 规则文件路径，文件变化后自动重新加载，为空时不下发规则
+}
+
+// SignaturesConfig 特征库订阅配置，特征库合并到下发给 procscan 的规则中
+type SignaturesConfig struct {
+	URL       string `yaml:"url"`        // 签名特征库地址，必须为 HTTPS，为空时不订阅
+	PublicKey string `yaml:"public_key"` // 校验签名的 Ed25519 公钥（base64），支持 ${ENV} 环境变量
+	Interval  string `yaml:"interval"`   // 拉取间隔（字符串格式，如 "1h"）
+	Timeout   string `yaml:"timeout"`    // 单次拉取超时
+	StatePath string `yaml:"state_path"` // 已验证版本的持久化文件，重启后无需重新拉取即可回滚
+	History   int    `yaml:"history"`    // 保留的历史版本数，用于回滚
+	Pin       string `yaml:"pin"`        // 固定使用的版本，为空时使用最新版本
+}
+
+// DebugConfig pprof 和运行时调试接口配置
+type DebugConfig struct {
+	Addr  string `yaml:"addr"`  // 调试接口监听地址（如 "127.0.0.1:6060"），为空时不启用
+	Token string `yaml:"token"` // 调试接口的 Bearer Token，支持 ${ENV} 环境变量，监听非回环地址时必填
+}
+
+// TriageConfig 违规确认和指派配置
+type TriageConfig struct {
+	StatePath string `yaml:"state_path"` // 确认和指派状态的持久化文件
+	Token     string `yaml:"token"`      // 写接口的 Bearer Token，支持 ${ENV} 环境变量，为空时拒绝写请求
+}
+
+// CRDConfig ProcessViolation 自定义资源输出配置
+type CRDConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否将违规记录写为 ProcessViolation 资源
+	TTL     string `yaml:"ttl"`     // 违规清除后资源保留时长（字符串格式，如 "24h"）
+}
+
+// WebhookConfig 出站 Webhook 配置，违规按命名空间和规则路由到不同的目标
+type WebhookConfig struct {
+	Name          string            `yaml:"name"`           // 名称，用于日志
+	URL           string            `yaml:"url"`            // 目标地址，支持 ${ENV} 环境变量
+	Method        string            `yaml:"method"`         // HTTP 方法（默认：POST）
+	Headers       map[string]string `yaml:"headers"`        // 请求头，支持 ${ENV} 环境变量
+	Namespaces    []string          `yaml:"namespaces"`     // 命名空间正则，为空时匹配全部
+	Rules         []string          `yaml:"rules"`          // 规则正则，匹配违规记录的 regex 字段，为空时匹配全部
+	Events        []string          `yaml:"events"`         // 触发事件：added/changed/cleared（默认：全部）
+	Template      string            `yaml:"template"`       // 请求体 Go 模板，为空时发送 JSON
+	Timeout       string            `yaml:"timeout"`        // 单次请求超时（默认：10s）
+	MaxRetries    int               `yaml:"max_retries"`    // 最大重试次数（默认：3）
+	RetryInterval string            `yaml:"retry_interval"` // 首次重试间隔，之后每次翻倍（默认：5s）
+	QueueSize     int               `yaml:"queue_size"`     // 待发送事件队列长度，队列满时丢弃（默认：1000）
+}
+
+// DigestConfig 定时摘要通知配置，按计划汇总未确认的违规，与实时 Webhook 相互独立
+type DigestConfig struct {
+	Name                string            `yaml:"name"`                 // 名称，用于日志
+	URL                 string            `yaml:"url"`                  // 目标地址，支持 ${ENV} 环境变量
+	Method              string            `yaml:"method"`               // HTTP 方法（默认：POST）
+	Headers             map[string]string `yaml:"headers"`              // 请求头，支持 ${ENV} 环境变量
+	Namespaces          []string          `yaml:"namespaces"`           // 命名空间正则，为空时匹配全部
+	Rules               []string          `yaml:"rules"`                // 规则正则，匹配违规记录的 regex 字段，为空时匹配全部
+	Schedule            []string          `yaml:"schedule"`             // 每天的发送时间，格式 HH:MM，如 "09:00"
+	Weekdays            []string          `yaml:"weekdays"`             // 发送的星期：mon/tue/wed/thu/fri/sat/sun，为空时每天发送
+	Timezone            string            `yaml:"timezone"`             // 发送时间的时区，如 Asia/Shanghai（默认：本地时区）
+	IncludeAcknowledged bool              `yaml:"include_acknowledged"` // 是否包含已确认的违规（默认：不包含）
+	SendEmpty           bool              `yaml:"send_empty"`           // 没有违规时是否也发送
+	Summary             string            `yaml:"summary"`              // 摘要文本 Go 模板，渲染结果作为 .Summary 传给 Template
+	Template            string            `yaml:"template"`             // 请求体 Go 模板，为空时发送 JSON
+	Timeout             string            `yaml:"timeout"`              // 单次请求超时（默认：10s）
+	MaxRetries          int               `yaml:"max_retries"`          // 最大重试次数（默认：3）
+	RetryInterval       string            `yaml:"retry_interval"`       // 首次重试间隔，之后每次翻倍（默认：5s）
 }
 
 // AggregatorConfig 聚合器配置
 type AggregatorConfig struct {
 	ScanInterval string `yaml:"scan_interval"` // 扫描间隔（字符串格式，如 "60s"）
 	Port         int    `yaml:"port"`          // HTTP 服务端口
+	// FullSyncInterval 全量对账间隔，期间只拉取增量（字符串格式，如 "1h"）
+	FullSyncInterval string `yaml:"full_sync_interval"`
 }
 
 // DaemonSetConfig DaemonSet 配置
@@ -21,6 +104,7 @@ type DaemonSetConfig struct {
 	ServiceName string `yaml:"service_name"` // Service 名称
 	APIPort     int    `yaml:"api_port"`     // DaemonSet Pod 的 API 端口
 	APIPath     string `yaml:"api_path"`     // API 路径
+	DeltaPath   string `yaml:"delta_path"`   // 增量 API 路径，旧版本 Pod 不支持时回退到 APIPath
 }
 
 // LoggerConfig 日志配置
@@ -29,22 +113,209 @@ type LoggerConfig struct {
 	Format string `yaml:"format"` // 日志格式：json/text
 }
 
-// ViolationRecord 不合规记录（与 procscan 中的定义保持一致）
+// ViolationRecord 不合规记录（与 procscan 中的定义保持一致，Node 由聚合器填写）
 type ViolationRecord struct {
-	Pod       string `json:"pod"`       // Pod 名称
-	Namespace string `json:"namespace"` // 命名空间
-	Process   string `json:"process"`   // 进程名称
-	Cmdline   string `json:"cmdline"`   // 完整命令行
-	Regex     string `json:"regex"`     // 匹配的正则表达式规则
-	Status    string `json:"status"`    // 状态
-	Type      string `json:"type"`      // 类型（app 或 devbox）
-	Name      string `json:"name"`      // 应用名称
-	Timestamp string `json:"timestamp"` // 检测时间
-}
-
-// AggregatedViolations 聚合后的违规记录
+	Pod       string `json:"pod"`                // Pod 名称
+	Namespace string `json:"namespace"`          // 命名空间
+	Process   string `json:"process"`            // 进程名称
+	Cmdline   string `json:"cmdline"`            // 完整命令行
+	Regex     string `json:"regex"`              // 匹配的正则表达式规则
+	Status    string `json:"status"`             // 状态
+	Type      string `json:"type"`               // 类型（app 或 devbox）
+	Name      string `json:"name"`               // 应用名称
+	Timestamp string `json:"timestamp"`          // 检测时间
+	Node      string `json:"node,omitempty"`     // 上报该记录的节点，旧版本 procscan 不提供时为空
+	Category  string `json:"category,omitempty"` // 违规类别，如 tampering、egress，进程违规为空
+	Severity  string `json:"severity,omitempty"` // 违规等级
+	// 违规进程所在的容器，主机上的违规为空
+	ContainerID string `json:"container_id,omitempty"`
+	SandboxID   string `json:"sandbox_id,omitempty"`
+	Image       string `json:"image,omitempty"`
+	ImageDigest string `json:"image_digest,omitempty"`
+	// Exception 放行该违规的例外，仅 Status 为 excepted 时存在
+	Exception *Exception `json:"exception,omitempty"`
+}
+
+// StatusExcepted 命中 procscan 中未过期例外的记录状态，这类记录不作为违规处理
+const StatusExcepted = "excepted"
+
+// Exception 在 procscan 中配置的临时例外（与 procscan 中的定义保持一致）
+type Exception struct {
+	Namespace     string    `yaml:"namespace"     json:"namespace"`
+	Process       string    `yaml:"process"       json:"process"` // 进程名正则
+	Expires       time.Time `yaml:"expires"       json:"expires"`
+	Justification string    `yaml:"justification" json:"justification"`
+}
+
+// DetectionRules procscan 的检测规则（与 procscan 中的定义保持一致）
+type DetectionRules struct {
+	Blacklist  RuleSet        `yaml:"blacklist"  json:"blacklist"`
+	Whitelist  RuleSet        `yaml:"whitelist"  json:"whitelist"`
+	Ancestry   []AncestryRule `yaml:"ancestry"   json:"ancestry"`
+	Escape     EscapeRules    `yaml:"escape"     json:"escape"`
+	Exceptions []Exception    `yaml:"exceptions" json:"exceptions"`
+}
+
+// RuleSet 一组正则规则
+type RuleSet struct {
+	Processes  []string `yaml:"processes"  json:"processes"`
+	Keywords   []string `yaml:"keywords"   json:"keywords"`
+	Commands   []string `yaml:"commands"   json:"commands"`
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+	PodNames   []string `yaml:"podNames"   json:"podNames"`
+}
+
+// AncestryRule 进程祖先规则
+type AncestryRule struct {
+	Name   string `yaml:"name"   json:"name"`
+	Parent string `yaml:"parent" json:"parent"`
+	Child  string `yaml:"child"  json:"child"`
+	Depth  int    `yaml:"depth"  json:"depth"`
+}
+
+// EscapeRules 容器逃逸检测开关
+type EscapeRules struct {
+	Nsenter        bool `yaml:"nsenter"        json:"nsenter"`
+	CgroupMismatch bool `yaml:"cgroupMismatch" json:"cgroupMismatch"`
+}
+
+// DistributedRules 下发给单个 procscan 节点的规则版本，Canary 表示该节点处于灰度中
+type DistributedRules struct {
+	Version string         `json:"version"`
+	Canary  bool           `json:"canary"`
+	Rules   DetectionRules `json:"rules"`
+}
+
+// SignatureSet 特征库的一个版本，Processes 和 Keywords 为正则，PoolDomains 为矿池域名
+type SignatureSet struct {
+	Version     string    `json:"version"`
+	Published   time.Time `json:"published"`
+	Processes   []string  `json:"processes"`
+	PoolDomains []string  `json:"pool_domains"`
+	Keywords    []string  `json:"keywords"`
+}
+
+// SignedFeed 特征库响应，Signature 为 Payload 原始字节的 Ed25519 签名，均为 base64 编码
+type SignedFeed struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// SignatureStatus 特征库订阅状态，Versions 按发布时间倒序
+type SignatureStatus struct {
+	Active    string          `json:"active,omitempty"` // 当前合并到规则中的版本
+	Pinned    string          `json:"pinned,omitempty"` // 固定的版本，回滚后为回滚到的版本
+	Versions  []*SignatureSet `json:"versions"`
+	LastFetch *time.Time      `json:"last_fetch,omitempty"`
+	LastError string          `json:"last_error,omitempty"` // 最近一次拉取失败的原因，成功后清空
+}
+
+// SignaturePinRequest 固定特征库版本的请求，Version 为空时取消固定
+type SignaturePinRequest struct {
+	Version string `json:"version"`
+}
+
+// AggregatedViolations 聚合后的违规记录，命中例外的记录单独放在 Exceptions 中
 type AggregatedViolations struct {
 	Violations []*ViolationRecord `json:"violations"`
+	Exceptions []*ViolationRecord `json:"exceptions"`
 	UpdateTime time.Time          `json:"update_time"`
 	TotalCount int                `json:"total_count"`
 }
+
+// ViolationDelta 单个 DaemonSet Pod 返回的违规记录变化（与 procscan 中的定义保持一致）
+type ViolationDelta struct {
+	Node       string             `json:"node"`
+	Epoch      string             `json:"epoch"` // procscan 重启后 Seq 从头计数，Epoch 随之变化
+	Seq        uint64             `json:"seq"`
+	Since      uint64             `json:"since"`
+	Full       bool               `json:"full"`
+	Added      []*ViolationRecord `json:"added,omitempty"`
+	Changed    []*ViolationRecord `json:"changed,omitempty"`
+	Cleared    []*ViolationRecord `json:"cleared,omitempty"`
+	Violations []*ViolationRecord `json:"violations,omitempty"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// Key 返回违规记录的唯一 key：namespace/pod/process
+func (r *ViolationRecord) Key() string {
+	return r.Namespace + "/" + r.Pod + "/" + r.Process
+}
+
+// ID 根据 Key 生成稳定的违规 ID，与对应的 ProcessViolation 资源名一致
+func (r *ViolationRecord) ID() string {
+	sum := sha256.Sum256([]byte(r.Key()))
+	return "pv-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// TriageState 违规的确认和指派状态
+type TriageState struct {
+	Acknowledged   bool         `json:"acknowledged"`
+	AcknowledgedBy string       `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time   `json:"acknowledged_at,omitempty"`
+	Assignee       string       `json:"assignee,omitempty"`
+	AssignedBy     string       `json:"assigned_by,omitempty"`
+	AssignedAt     *time.Time   `json:"assigned_at,omitempty"`
+	Notes          []TriageNote `json:"notes,omitempty"`
+}
+
+// TriageNote 一次确认或指派操作的记录
+type TriageNote struct {
+	Action    string    `json:"action"` // ack 或 assign
+	Author    string    `json:"author"`
+	Text      string    `json:"text,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ViolationView 接口返回的违规记录，附带 ID 和处理状态
+type ViolationView struct {
+	ID string `json:"id"`
+	*ViolationRecord
+	Triage *TriageState `json:"triage,omitempty"`
+}
+
+// ViolationList 违规列表接口的响应
+type ViolationList struct {
+	Violations        []*ViolationView `json:"violations"`
+	UpdateTime        time.Time        `json:"update_time"`
+	TotalCount        int              `json:"total_count"`           // 本次返回的记录数
+	AcknowledgedCount int              `json:"acknowledged_count"`    // 全部违规中已确认的数量
+	ExceptedCount     int              `json:"excepted_count"`        // 命中例外而未列出的记录数，见 /api/exceptions
+	MatchedCount      int              `json:"matched_count"`         // 符合过滤条件的记录数，不受分页影响
+	Offset            int              `json:"offset"`                // 本页第一条记录的位置
+	NextOffset        *int             `json:"next_offset,omitempty"` // 下一页的 offset，已是最后一页时为空
+}
+
+// ExceptionList 例外接口的响应，记录按过期时间排序
+type ExceptionList struct {
+	Exceptions []*ViolationView `json:"exceptions"`
+	UpdateTime time.Time        `json:"update_time"`
+	TotalCount int              `json:"total_count"`
+}
+
+// TriageRequest 确认和指派接口的请求体
+type TriageRequest struct {
+	By       string `json:"by,omitempty"`       // 操作人，为空时使用认证代理注入的用户名
+	Assignee string `json:"assignee,omitempty"` // 处理人，指派时必填
+	Note     string `json:"note,omitempty"`     // 备注
+}
+
+// ErrorResponse 所有接口出错时的响应
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError 错误码和错误信息，错误码取值见 ErrorCode 开头的常量
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 接口错误码
+const (
+	ErrorCodeBadRequest   = "bad_request"
+	ErrorCodeUnauthorized = "unauthorized"
+	ErrorCodeNotFound     = "not_found"
+	ErrorCodeUnavailable  = "unavailable"
+	ErrorCodeInternal     = "internal"
+)
@@ -0,0 +1,216 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API Suite")
+}
+
+type fakeProvider struct {
+	status   *models.AgentStatus
+	lastScan *models.ScanSummary
+	rules    models.DetectionRules
+	records  []*models.ViolationRecord
+	since    []uint64
+}
+
+func (p *fakeProvider) GetViolationRecords() []*models.ViolationRecord { return p.records }
+func (p *fakeProvider) GetStatus() *models.AgentStatus                 { return p.status }
+func (p *fakeProvider) GetLastScan() *models.ScanSummary               { return p.lastScan }
+func (p *fakeProvider) GetRules() models.DetectionRules                { return p.rules }
+
+func (p *fakeProvider) GetViolationDelta(since uint64) *models.ViolationDelta {
+	p.since = append(p.since, since)
+	return &models.ViolationDelta{Node: "node-1", Seq: 3, Since: since}
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		provider *fakeProvider
+		handler  *Handler
+	)
+
+	BeforeEach(func() {
+		provider = &fakeProvider{
+			status: &models.AgentStatus{Node: "node-1", ScanCount: 2, ScanInterval: "1m0s"},
+			rules:  models.DetectionRules{Blacklist: models.RuleSet{Processes: []string{"^xmrig$"}}},
+		}
+		handler = NewHandler(provider)
+	})
+
+	serve := func(h http.HandlerFunc, method, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		h(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	It("should return the status of the scanner", func() {
+		recorder := serve(handler.StatusHandler, http.MethodGet, "/status")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var status models.AgentStatus
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &status)).To(Succeed())
+		Expect(status.Node).To(Equal("node-1"))
+		Expect(status.ScanCount).To(Equal(int64(2)))
+		Expect(status.ScanInterval).To(Equal("1m0s"))
+	})
+
+	It("should return no content before the first scan finished", func() {
+		recorder := serve(handler.LastScanHandler, http.MethodGet, "/last-scan")
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(recorder.Body.Len()).To(BeZero())
+	})
+
+	It("should return the summary of the last scan", func() {
+		provider.lastScan = &models.ScanSummary{
+			ProcessesScanned:   42,
+			Violations:         1,
+			AffectedNamespaces: []string{"ns-a"},
+		}
+		recorder := serve(handler.LastScanHandler, http.MethodGet, "/last-scan")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var summary models.ScanSummary
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &summary)).To(Succeed())
+		Expect(summary.ProcessesScanned).To(Equal(42))
+		Expect(summary.Violations).To(Equal(1))
+		Expect(summary.AffectedNamespaces).To(Equal([]string{"ns-a"}))
+	})
+
+	It("should return the active detection rules", func() {
+		recorder := serve(handler.RulesHandler, http.MethodGet, "/rules")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var rules models.DetectionRules
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &rules)).To(Succeed())
+		Expect(rules.Blacklist.Processes).To(Equal([]string{"^xmrig$"}))
+	})
+
+	It("should pass the since parameter to the violation delta", func() {
+		recorder := serve(handler.GetViolationDeltaHandler, http.MethodGet, "/violations/delta?since=2")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(provider.since).To(Equal([]uint64{2}))
+
+		recorder = serve(handler.GetViolationDeltaHandler, http.MethodGet, "/violations/delta?since=abc")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(provider.since).To(HaveLen(1))
+	})
+
+	It("should reject methods other than GET", func() {
+		for _, h := range []http.HandlerFunc{
+			handler.StatusHandler,
+			handler.LastScanHandler,
+			handler.RulesHandler,
+			handler.GetViolationsHandler,
+			handler.GetViolationDeltaHandler,
+		} {
+			Expect(serve(h, http.MethodPost, "/").Code).To(Equal(http.StatusMethodNotAllowed))
+		}
+	})
+})
+
+var _ = Describe("Server", func() {
+	ctx := context.Background()
+
+	freePort := func() int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+
+	get := func(client *http.Client, url string) *http.Response {
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			resp, err = client.Get(url)
+			return err
+		}, 2*time.Second, 50*time.Millisecond).Should(Succeed())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+
+	It("should serve the API over the Unix socket", func() {
+		socketPath := filepath.Join(GinkgoT().TempDir(), "procscan.sock")
+		server := NewServer(&fakeProvider{}, models.APIConfig{SocketPath: socketPath})
+		Expect(server.Start(ctx)).To(Succeed())
+
+		Expect(server.socketOpen).To(BeTrue())
+		info, err := os.Stat(socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode() & os.ModeSocket).NotTo(BeZero())
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		}}
+		Expect(get(client, "http://procscan/health").StatusCode).To(Equal(http.StatusOK))
+
+		Expect(server.Stop(ctx)).To(Succeed())
+		_, err = os.Stat(socketPath)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should fall back to TCP when the socket cannot be created", func() {
+		// socket 目录的父路径是普通文件，MkdirAll 必然失败
+		file := filepath.Join(GinkgoT().TempDir(), "not-a-dir")
+		Expect(os.WriteFile(file, nil, 0o600)).To(Succeed())
+
+		server := NewServer(&fakeProvider{}, models.APIConfig{SocketPath: filepath.Join(file, "run", "procscan.sock")})
+		port := freePort()
+		server.fallbackPort = port
+		Expect(server.Start(ctx)).To(Succeed())
+		DeferCleanup(func() { Expect(server.Stop(ctx)).To(Succeed()) })
+
+		Expect(server.socketOpen).To(BeFalse())
+		resp := get(http.DefaultClient, fmt.Sprintf("http://127.0.0.1:%d/health", port))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(file).To(BeARegularFile())
+	})
+
+	It("should prefer the configured port over the fallback port", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "not-a-dir")
+		Expect(os.WriteFile(file, nil, 0o600)).To(Succeed())
+
+		port := freePort()
+		server := NewServer(&fakeProvider{}, models.APIConfig{Port: port, SocketPath: filepath.Join(file, "procscan.sock")})
+		server.fallbackPort = -1
+		Expect(server.Start(ctx)).To(Succeed())
+		DeferCleanup(func() { Expect(server.Stop(ctx)).To(Succeed()) })
+
+		resp := get(http.DefaultClient, fmt.Sprintf("http://127.0.0.1:%d/status", port))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+})
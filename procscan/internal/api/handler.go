@@ -18,6 +18,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
 	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
@@ -29,12 +30,21 @@ type ViolationRecordsProvider interface {
 	GetViolationRecords() []*models.ViolationRecord
 }
 
+// StatusProvider 定义只读状态接口所需的数据来源
+type StatusProvider interface {
+	ViolationRecordsProvider
+	GetStatus() *models.AgentStatus
+	GetLastScan() *models.ScanSummary
+	GetRules() models.DetectionRules
+	GetViolationDelta(since uint64) *models.ViolationDelta
+}
+
 // Handler API 处理器
 type Handler struct {
-	Provider ViolationRecordsProvider
+	Provider StatusProvider
 }
 
-func NewHandler(provider ViolationRecordsProvider) *Handler {
+func NewHandler(provider StatusProvider) *Handler {
 	return &Handler{
 		Provider: provider,
 	}
@@ -53,12 +63,58 @@ func (h *Handler) GetViolationsHandler(w http.ResponseWriter, r *http.Request) {
 		"remote": r.RemoteAddr,
 	}).Info("API: Returning violation records")
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(records); err != nil {
-		legacy.L.WithError(err).Error("Failed to encode violation records")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	writeJSON(w, records)
+}
+
+// GetViolationDeltaHandler 返回自 since 序号以来的违规记录变化
+// since 为空、为 0 或已超出历史范围时返回全量状态
+func (h *Handler) GetViolationDeltaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+	writeJSON(w, h.Provider.GetViolationDelta(since))
+}
+
+// StatusHandler 返回扫描器运行状态
+func (h *Handler) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, h.Provider.GetStatus())
+}
+
+// LastScanHandler 返回最近一轮扫描的摘要，尚未完成任何扫描时返回 204
+func (h *Handler) LastScanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	summary := h.Provider.GetLastScan()
+	if summary == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, summary)
+}
+
+// RulesHandler 返回当前生效的检测规则
+func (h *Handler) RulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, h.Provider.GetRules())
 }
 
 // HealthHandler 健康检查接口
@@ -67,3 +123,11 @@ func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		legacy.L.WithError(err).Error("Failed to encode API response")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -17,72 +17,161 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// DefaultSocketPath 是未配置 socket_path 时使用的 Unix socket 路径
+	DefaultSocketPath = "/var/run/procscan/procscan.sock"
+	// DefaultPort 是 Unix socket 不可用且未配置 port 时回退使用的 TCP 端口
+	DefaultPort = 9090
+)
+
 // Server API 服务器
 type Server struct {
-	handler    *Handler
-	httpServer *http.Server
-	port       int
+	handler      *Handler
+	httpServer   *http.Server
+	port         int
+	fallbackPort int
+	socketPath   string
+	// socketOpen 记录 socket 是否由本服务创建，Stop 时只清理自己创建的 socket
+	socketOpen bool
 }
 
-func NewServer(provider ViolationRecordsProvider, port int) *Server {
+func NewServer(provider StatusProvider, cfg models.APIConfig) *Server {
 	handler := NewHandler(provider)
 	mux := http.NewServeMux()
 
 	// 注册路由
 	mux.HandleFunc("/api/violations", handler.GetViolationsHandler)
+	mux.HandleFunc("/violations", handler.GetViolationsHandler)
+	mux.HandleFunc("/violations/delta", handler.GetViolationDeltaHandler)
+	mux.HandleFunc("/api/violations/delta", handler.GetViolationDeltaHandler)
+	mux.HandleFunc("/status", handler.StatusHandler)
+	mux.HandleFunc("/last-scan", handler.LastScanHandler)
+	mux.HandleFunc("/rules", handler.RulesHandler)
 	mux.HandleFunc("/health", handler.HealthHandler)
 
 	httpServer := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
+	socketPath := cfg.SocketPath
+	if socketPath == "" {
+		socketPath = DefaultSocketPath
+	}
+
 	return &Server{
-		handler:    handler,
-		httpServer: httpServer,
-		port:       port,
+		handler:      handler,
+		httpServer:   httpServer,
+		port:         cfg.Port,
+		fallbackPort: DefaultPort,
+		socketPath:   socketPath,
 	}
 }
 
-// Start 启动 API 服务器
+// Start 启动 API 服务器，监听 Unix socket 以及可选的 TCP 端口
+// Unix socket 不可用时（例如 /var/run/procscan 未挂载）记录警告并仅监听 TCP，
+// 未配置 port 时回退到 DefaultPort
 func (s *Server) Start(ctx context.Context) error {
 	legacy.L.WithFields(logrus.Fields{
-		"port": s.port,
+		"port":   s.port,
+		"socket": s.socketPath,
 		"endpoints": []string{
+			"/status",
+			"/last-scan",
+			"/rules",
+			"/violations",
+			"/violations/delta",
 			"/api/violations",
+			"/api/violations/delta",
 			"/health",
 		},
 	}).Info("Starting API server")
 
-	errChan := make(chan error, 1)
-	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
+	listeners := make([]net.Listener, 0, 2)
+	port := s.port
+	socketListener, err := listenUnix(s.socketPath)
+	if err != nil {
+		if port <= 0 {
+			port = s.fallbackPort
+		}
+		legacy.L.WithError(err).WithFields(logrus.Fields{
+			"socket": s.socketPath,
+			"port":   port,
+		}).Warn("API socket unavailable, serving the API over TCP only")
+	} else {
+		s.socketOpen = true
+		listeners = append(listeners, socketListener)
+	}
+
+	if port > 0 {
+		tcpListener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			return fmt.Errorf("failed to start API server: %w", err)
 		}
-	}()
-
-	// 等待服务器启动或错误
-	select {
-	case err := <-errChan:
-		return fmt.Errorf("failed to start API server: %w", err)
-	case <-time.After(100 * time.Millisecond):
-		legacy.L.WithField("port", s.port).Info("API server started successfully")
-		return nil
+		listeners = append(listeners, tcpListener)
 	}
+
+	for _, listener := range listeners {
+		go func(l net.Listener) {
+			if err := s.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				legacy.L.WithError(err).WithField("addr", l.Addr().String()).Error("API listener stopped")
+			}
+		}(listener)
+	}
+
+	legacy.L.WithFields(logrus.Fields{
+		"port":        port,
+		"socket":      s.socketPath,
+		"socket_open": s.socketOpen,
+	}).Info("API server started successfully")
+	return nil
 }
 
 // Stop 停止 API 服务器
 func (s *Server) Stop(ctx context.Context) error {
 	legacy.L.Info("Stopping API server")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if !s.socketOpen {
+		return err
+	}
+	if removeErr := os.Remove(s.socketPath); removeErr != nil && !os.IsNotExist(removeErr) {
+		legacy.L.WithError(removeErr).Warn("Failed to remove API socket")
+	}
+	return err
+}
+
+// listenUnix 监听 Unix socket，并清理上次运行遗留的 socket 文件
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o660); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
 }
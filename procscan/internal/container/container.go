@@ -19,83 +19,341 @@ package container
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
+const (
+	// DefaultTimeout bounds every CRI request when no timeout is configured
+	DefaultTimeout = 5 * time.Second
+	// DefaultCacheTTL is how long a resolved container is reused when no TTL is configured
+	DefaultCacheTTL = 10 * time.Minute
+
+	// maxCacheEntries bounds the caches, expired entries are dropped once it is reached
+	maxCacheEntries = 4096
+)
+
+// DefaultEndpoints are the CRI sockets of containerd and CRI-O, tried in order
+var DefaultEndpoints = []string{
+	"unix:///run/containerd/containerd.sock",
+	"unix:///var/run/containerd/containerd.sock",
+	"unix:///var/run/crio/crio.sock",
+	"unix:///run/crio/crio.sock",
+}
+
+// Kubernetes labels set by the kubelet on every container and sandbox
+const (
+	labelPodName      = "io.kubernetes.pod.name"
+	labelPodNamespace = "io.kubernetes.pod.namespace"
+	labelPodUID       = "io.kubernetes.pod.uid"
+	labelContainer    = "io.kubernetes.container.name"
+)
+
 // ContainerInfo 存储容器的完整信息
 type ContainerInfo struct {
-	PodName      string
-	PodNamespace string
-	Labels       map[string]string // Pod 的所有 label
+	ContainerID   string
+	ContainerName string
+	SandboxID     string // Pod sandbox 的 ID
+	PodName       string
+	PodNamespace  string
+	PodUID        string
+	Labels        map[string]string // Pod 的所有 label
+	Image         string            // 创建容器时指定的镜像
+	ImageID       string            // 运行时解析出的镜像 ID
+	ImageDigest   string            // 镜像仓库中的 digest，运行时未提供时与 ImageID 相同
+	Runtime       string            // 运行时名称，如 containerd 或 cri-o
 }
 
-// GetContainerInfo retrieves pod name and namespace for a given container ID via on-demand query
-func GetContainerInfo(containerID string) (string, string, error) {
-	info, err := GetContainerInfoDetailed(containerID)
-	if err != nil {
-		return "", "", err
+// Resolver resolves container IDs to their pods through the CRI API. The
+// connection is established on first use and kept, re-established after errors.
+type Resolver struct {
+	endpoints []string
+	timeout   time.Duration
+	cacheTTL  time.Duration
+	now       func() time.Time
+
+	mu             sync.Mutex
+	conn           *grpc.ClientConn
+	runtime        runtimeapi.RuntimeServiceClient
+	images         runtimeapi.ImageServiceClient
+	runtimeName    string
+	runtimeVersion string
+	containers     map[string]cached[*ContainerInfo]
+	sandboxes      map[string]cached[map[string]string]
+	digests        map[string]cached[string]
+}
+
+type cached[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// NewResolver creates a resolver for the configured endpoint or, when none is
+// configured, for the first default endpoint that answers
+func NewResolver(config models.ContainerRuntimeConfig) *Resolver {
+	r := &Resolver{
+		endpoints:  DefaultEndpoints,
+		timeout:    config.Timeout,
+		cacheTTL:   config.CacheTTL,
+		now:        time.Now,
+		containers: make(map[string]cached[*ContainerInfo]),
+		sandboxes:  make(map[string]cached[map[string]string]),
+		digests:    make(map[string]cached[string]),
 	}
-	return info.PodName, info.PodNamespace, nil
+	if config.Endpoint != "" {
+		r.endpoints = []string{normalizeEndpoint(config.Endpoint)}
+	}
+	if r.timeout <= 0 {
+		r.timeout = DefaultTimeout
+	}
+	if r.cacheTTL <= 0 {
+		r.cacheTTL = DefaultCacheTTL
+	}
+	return r
 }
 
-// GetContainerInfoDetailed 获取容器的详细信息，包括 Pod labels
-func GetContainerInfoDetailed(containerID string) (*ContainerInfo, error) {
-	conn, err := createGRPCConnection()
+// normalizeEndpoint accepts socket paths as well as unix:// endpoints
+func normalizeEndpoint(endpoint string) string {
+	if strings.HasPrefix(endpoint, "/") {
+		return "unix://" + endpoint
+	}
+	return endpoint
+}
+
+// Resolve returns the container, its pod sandbox and its image
+func (r *Resolver) Resolve(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if entry, ok := r.containers[containerID]; ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+	if err := r.connect(ctx); err != nil {
+		return nil, err
+	}
+	info, err := r.resolve(ctx, containerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create connection: %v", err)
+		if isUnavailable(err) {
+			r.disconnect()
+		}
+		return nil, err
 	}
-	defer conn.Close()
-	client := runtimeapi.NewRuntimeServiceClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	prune(r.containers, now)
+	r.containers[containerID] = cached[*ContainerInfo]{value: info, expires: now.Add(r.cacheTTL)}
+	return info, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
-	statusReq := &runtimeapi.ContainerStatusRequest{ContainerId: containerID, Verbose: true}
-	statusResp, err := client.ContainerStatus(ctx, statusReq)
+	// ListContainers is the only call returning the sandbox ID on every runtime
+	listResp, err := r.runtime.ListContainers(reqCtx, &runtimeapi.ListContainersRequest{
+		Filter: &runtimeapi.ContainerFilter{Id: containerID},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get container status: %v", err)
+		return nil, fmt.Errorf("failed to list container: %w", err)
 	}
-	if statusResp.Status == nil {
-		return nil, fmt.Errorf("container status is empty")
+	var found *runtimeapi.Container
+	for _, c := range listResp.GetContainers() {
+		// CRI-O matches the filter by prefix
+		if c.GetId() == containerID {
+			found = c
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("container %s not found in %s", containerID, r.runtimeName)
 	}
 
-	labels := statusResp.Status.GetLabels()
-	podNamespace := labels["io.kubernetes.pod.namespace"]
-	podName := labels["io.kubernetes.pod.name"]
+	containerLabels := found.GetLabels()
+	info := &ContainerInfo{
+		ContainerID:   containerID,
+		ContainerName: containerLabels[labelContainer],
+		SandboxID:     found.GetPodSandboxId(),
+		PodName:       containerLabels[labelPodName],
+		PodNamespace:  containerLabels[labelPodNamespace],
+		PodUID:        containerLabels[labelPodUID],
+		Image:         found.GetImage().GetImage(),
+		ImageID:       found.GetImageRef(),
+		Runtime:       r.runtimeName,
+	}
+	if info.ContainerName == "" {
+		info.ContainerName = found.GetMetadata().GetName()
+	}
+	if info.PodName == "" {
+		return nil, fmt.Errorf("cannot find pod name (%s) in container labels", labelPodName)
+	}
+	if info.PodNamespace == "" {
+		return nil, fmt.Errorf("cannot find pod namespace (%s) in container labels", labelPodNamespace)
+	}
 
-	if podName == "" {
-		return nil, fmt.Errorf("cannot find pod name (io.kubernetes.pod.name) in container labels")
+	// The pod labels are only set on the sandbox, the container labels identify the pod
+	info.Labels = make(map[string]string)
+	if sandboxLabels, err := r.sandboxLabels(ctx, info.SandboxID); err == nil {
+		for k, v := range sandboxLabels {
+			info.Labels[k] = v
+		}
+	} else {
+		legacy.L.WithFields(logrus.Fields{
+			"sandboxID": info.SandboxID,
+			"error":     err.Error(),
+		}).Debug("Failed to get pod sandbox labels")
 	}
-	if podNamespace == "" {
-		return nil, fmt.Errorf("cannot find pod namespace (io.kubernetes.pod.namespace) in container labels")
+	for k, v := range containerLabels {
+		info.Labels[k] = v
 	}
 
-	return &ContainerInfo{
-		PodName:      podName,
-		PodNamespace: podNamespace,
-		Labels:       labels,
-	}, nil
+	info.ImageDigest = r.imageDigest(ctx, info.ImageID)
+	return info, nil
 }
 
-// createGRPCConnection establishes a gRPC connection to the container runtime
-func createGRPCConnection() (*grpc.ClientConn, error) {
-	endpoints := []string{
-		"unix:///var/run/containerd/containerd.sock",
-		"unix:///run/containerd/containerd.sock",
-		"unix:///var/run/crio/crio.sock",
-		"unix:///var/run/dockershim.sock",
+// sandboxLabels returns the labels of a pod sandbox
+func (r *Resolver) sandboxLabels(ctx context.Context, sandboxID string) (map[string]string, error) {
+	now := r.now()
+	if entry, ok := r.sandboxes[sandboxID]; ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	resp, err := r.runtime.PodSandboxStatus(reqCtx, &runtimeapi.PodSandboxStatusRequest{PodSandboxId: sandboxID})
+	if err != nil {
+		return nil, err
+	}
+	labels := resp.GetStatus().GetLabels()
+	prune(r.sandboxes, now)
+	r.sandboxes[sandboxID] = cached[map[string]string]{value: labels, expires: now.Add(r.cacheTTL)}
+	return labels, nil
+}
+
+// imageDigest returns the repository digest of an image. CRI-O already reports
+// a digest reference, containerd reports the image ID whose repository
+// digests are looked up in the image service.
+func (r *Resolver) imageDigest(ctx context.Context, imageRef string) string {
+	if digest, ok := digestOf(imageRef); ok {
+		return digest
+	}
+	now := r.now()
+	if entry, ok := r.digests[imageRef]; ok && now.Before(entry.expires) {
+		return entry.value
+	}
+	digest := imageRef
+	reqCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	resp, err := r.images.ImageStatus(reqCtx, &runtimeapi.ImageStatusRequest{
+		Image: &runtimeapi.ImageSpec{Image: imageRef},
+	})
+	if err != nil {
+		legacy.L.WithFields(logrus.Fields{
+			"image": imageRef,
+			"error": err.Error(),
+		}).Debug("Failed to get image status")
+		return digest
+	}
+	for _, repoDigest := range resp.GetImage().GetRepoDigests() {
+		if d, ok := digestOf(repoDigest); ok {
+			digest = d
+			break
+		}
+	}
+	prune(r.digests, now)
+	r.digests[imageRef] = cached[string]{value: digest, expires: now.Add(r.cacheTTL)}
+	return digest
+}
+
+// digestOf returns the digest of a name@digest reference
+func digestOf(ref string) (string, bool) {
+	if i := strings.LastIndexByte(ref, '@'); i >= 0 && i < len(ref)-1 {
+		return ref[i+1:], true
+	}
+	return "", false
+}
+
+// connect establishes the connection to the first endpoint whose runtime answers
+func (r *Resolver) connect(ctx context.Context) error {
+	if r.conn != nil {
+		return nil
 	}
 	var lastErr error
-	for _, endpoint := range endpoints {
-		conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
-		if err == nil {
-			legacy.L.WithField("endpoint", endpoint).Info("Successfully connected to container runtime")
-			return conn, nil
+	for _, endpoint := range r.endpoints {
+		socket := strings.TrimPrefix(endpoint, "unix://")
+		if socket != endpoint {
+			if _, err := os.Stat(socket); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		runtime := runtimeapi.NewRuntimeServiceClient(conn)
+		reqCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		version, err := runtime.Version(reqCtx, &runtimeapi.VersionRequest{})
+		cancel()
+		if err != nil {
+			_ = conn.Close()
+			lastErr = err
+			continue
+		}
+		r.conn = conn
+		r.runtime = runtime
+		r.images = runtimeapi.NewImageServiceClient(conn)
+		r.runtimeName = version.GetRuntimeName()
+		r.runtimeVersion = version.GetRuntimeVersion()
+		legacy.L.WithFields(logrus.Fields{
+			"endpoint": endpoint,
+			"runtime":  r.runtimeName,
+			"version":  r.runtimeVersion,
+		}).Info("Successfully connected to container runtime")
+		return nil
+	}
+	return fmt.Errorf("failed to connect to any container runtime: %v", lastErr)
+}
+
+func (r *Resolver) disconnect() {
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+	r.conn = nil
+	r.runtime = nil
+	r.images = nil
+}
+
+// Close closes the connection to the runtime
+func (r *Resolver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disconnect()
+}
+
+// isUnavailable reports whether the runtime could not be reached, such as after a restart
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+func prune[T any](entries map[string]cached[T], now time.Time) {
+	if len(entries) < maxCacheEntries {
+		return
+	}
+	for key, entry := range entries {
+		if !now.Before(entry.expires) {
+			delete(entries, key)
 		}
-		lastErr = err
 	}
-	return nil, fmt.Errorf("failed to connect to any container runtime: %v", lastErr)
+	if len(entries) >= maxCacheEntries {
+		clear(entries)
+	}
 }
@@ -0,0 +1,163 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestContainer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Container Suite")
+}
+
+const (
+	containerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	sandboxID   = "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	imageID     = "sha256:4f53e8b1a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c"
+	digest      = "sha256:9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b"
+)
+
+// fakeRuntime serves the CRI calls of the resolver like containerd or CRI-O
+type fakeRuntime struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+	runtimeapi.UnimplementedImageServiceServer
+	name     string
+	imageRef string
+	lists    atomic.Int32
+}
+
+func (f *fakeRuntime) Version(context.Context, *runtimeapi.VersionRequest) (*runtimeapi.VersionResponse, error) {
+	return &runtimeapi.VersionResponse{RuntimeName: f.name, RuntimeVersion: "1.0.0"}, nil
+}
+
+func (f *fakeRuntime) ListContainers(_ context.Context, req *runtimeapi.ListContainersRequest) (*runtimeapi.ListContainersResponse, error) {
+	f.lists.Add(1)
+	containers := []*runtimeapi.Container{{
+		Id:           containerID,
+		PodSandboxId: sandboxID,
+		Metadata:     &runtimeapi.ContainerMetadata{Name: "web"},
+		Image:        &runtimeapi.ImageSpec{Image: "docker.io/library/nginx:1.27"},
+		ImageRef:     f.imageRef,
+		Labels: map[string]string{
+			labelPodName:      "web-0",
+			labelPodNamespace: "ns-test",
+			labelPodUID:       "0b7e0c36",
+			labelContainer:    "web",
+		},
+	}}
+	var matched []*runtimeapi.Container
+	for _, c := range containers {
+		if strings.HasPrefix(c.Id, req.GetFilter().GetId()) {
+			matched = append(matched, c)
+		}
+	}
+	return &runtimeapi.ListContainersResponse{Containers: matched}, nil
+}
+
+func (f *fakeRuntime) PodSandboxStatus(_ context.Context, req *runtimeapi.PodSandboxStatusRequest) (*runtimeapi.PodSandboxStatusResponse, error) {
+	return &runtimeapi.PodSandboxStatusResponse{Status: &runtimeapi.PodSandboxStatus{
+		Id:     req.GetPodSandboxId(),
+		Labels: map[string]string{"app": "web", labelPodName: "web-0"},
+	}}, nil
+}
+
+func (f *fakeRuntime) ImageStatus(_ context.Context, req *runtimeapi.ImageStatusRequest) (*runtimeapi.ImageStatusResponse, error) {
+	return &runtimeapi.ImageStatusResponse{Image: &runtimeapi.Image{
+		Id:          req.GetImage().GetImage(),
+		RepoDigests: []string{"docker.io/library/nginx@" + digest},
+	}}, nil
+}
+
+var _ = Describe("Resolver", func() {
+	var endpoint string
+
+	serve := func(runtime *fakeRuntime) {
+		// Socket paths are limited to about 100 bytes, the test temp dir may be longer
+		dir, err := os.MkdirTemp("", "cri")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+		socket := filepath.Join(dir, "cri.sock")
+		listener, err := net.Listen("unix", socket)
+		Expect(err).NotTo(HaveOccurred())
+		server := grpc.NewServer()
+		runtimeapi.RegisterRuntimeServiceServer(server, runtime)
+		runtimeapi.RegisterImageServiceServer(server, runtime)
+		go func() { _ = server.Serve(listener) }()
+		DeferCleanup(server.Stop)
+		endpoint = socket
+	}
+
+	It("should resolve containerd containers with their sandbox and image digest", func() {
+		runtime := &fakeRuntime{name: "containerd", imageRef: imageID}
+		serve(runtime)
+		resolver := NewResolver(models.ContainerRuntimeConfig{Endpoint: endpoint})
+		DeferCleanup(resolver.Close)
+
+		info, err := resolver.Resolve(context.Background(), containerID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PodName).To(Equal("web-0"))
+		Expect(info.PodNamespace).To(Equal("ns-test"))
+		Expect(info.PodUID).To(Equal("0b7e0c36"))
+		Expect(info.ContainerName).To(Equal("web"))
+		Expect(info.SandboxID).To(Equal(sandboxID))
+		Expect(info.Image).To(Equal("docker.io/library/nginx:1.27"))
+		Expect(info.ImageID).To(Equal(imageID))
+		Expect(info.ImageDigest).To(Equal(digest))
+		Expect(info.Runtime).To(Equal("containerd"))
+		Expect(info.Labels).To(HaveKeyWithValue("app", "web"))
+		Expect(info.Labels).To(HaveKeyWithValue(labelPodNamespace, "ns-test"))
+
+		_, err = resolver.Resolve(context.Background(), containerID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runtime.lists.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should take the digest of CRI-O image references and match IDs exactly", func() {
+		serve(&fakeRuntime{name: "cri-o", imageRef: "docker.io/library/nginx@" + digest})
+		resolver := NewResolver(models.ContainerRuntimeConfig{Endpoint: "unix://" + endpoint})
+		DeferCleanup(resolver.Close)
+
+		info, err := resolver.Resolve(context.Background(), containerID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.ImageDigest).To(Equal(digest))
+		Expect(info.Runtime).To(Equal("cri-o"))
+
+		_, err = resolver.Resolve(context.Background(), containerID[:12])
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should fail without a reachable runtime", func() {
+		resolver := NewResolver(models.ContainerRuntimeConfig{
+			Endpoint: filepath.Join(GinkgoT().TempDir(), "missing.sock"),
+			Timeout:  100 * time.Millisecond,
+		})
+		_, err := resolver.Resolve(context.Background(), containerID)
+		Expect(err).To(MatchError(ContainSubstring("failed to connect to any container runtime")))
+	})
+})
@@ -44,10 +44,35 @@ type NamespaceScanResult struct {
 
 // SendGlobalBatchAlert constructs and sends aggregated alert using Markdown format
 func SendGlobalBatchAlert(results []*NamespaceScanResult, webhookURL string, region string) error {
+	return sendBatchAlert("Suspicious Process Alert", results, nil, webhookURL, region)
+}
+
+// SendReconciliationAlert sends the full violation state of the node, used for periodic reconciliation
+func SendReconciliationAlert(results []*NamespaceScanResult, webhookURL string, region string) error {
+	return sendBatchAlert("Suspicious Process Full State Report", results, nil, webhookURL, region)
+}
+
+// SendDeltaAlert sends only the new or changed suspicious processes and the violations cleared since the last scan
+func SendDeltaAlert(
+	results []*NamespaceScanResult,
+	cleared []*models.ViolationRecord,
+	webhookURL string,
+	region string,
+) error {
+	return sendBatchAlert("Suspicious Process Changes", results, cleared, webhookURL, region)
+}
+
+func sendBatchAlert(
+	title string,
+	results []*NamespaceScanResult,
+	cleared []*models.ViolationRecord,
+	webhookURL string,
+	region string,
+) error {
 	if webhookURL == "" {
 		return fmt.Errorf("webhook URL cannot be empty")
 	}
-	if len(results) == 0 {
+	if len(results) == 0 && len(cleared) == 0 {
 		return nil // No issues found, skip alert
 	}
 
@@ -68,6 +93,9 @@ func SendGlobalBatchAlert(results []*NamespaceScanResult, webhookURL string, reg
 	// 1. Overview information - using prominent styling
 	summaryText := fmt.Sprintf("**Availability Zone:** `%s`\n**Node:** `%s`\n**Anomalies Found:** %d suspicious processes\n**Affected Namespaces:** %d",
 		region, nodeName, totalProcesses, len(results))
+	if len(cleared) > 0 {
+		summaryText += fmt.Sprintf("\n**Cleared:** %d processes no longer detected", len(cleared))
+	}
 	allElements = append(allElements, newMarkdownElement(summaryText))
 
 	// 2. Separator line
@@ -113,16 +141,35 @@ func SendGlobalBatchAlert(results []*NamespaceScanResult, webhookURL string, reg
 		}
 	}
 
-	// 4. Bottom tip
-	allElements = append(allElements, newHrElement())
-	allElements = append(allElements, newMarkdownElement("**Suggestion:** Please check and handle anomalous processes promptly"))
+	// 4. Cleared violations
+	if len(cleared) > 0 {
+		allElements = append(allElements, newHrElement())
+		allElements = append(allElements, newMarkdownElement("### Cleared"))
+		allElements = append(allElements, newMarkdownElement("| Namespace | Pod | Process |\n| --- | --- | --- |"))
+		for _, c := range cleared {
+			podName := c.Pod
+			if len(podName) > 30 {
+				podName = podName[:27] + "..."
+			}
+			allElements = append(allElements, newMarkdownElement(
+				fmt.Sprintf("| `%s` | `%s` | `%s` |", c.Namespace, podName, c.Process)))
+		}
+	}
+
+	// 5. Bottom tip
+	template := "green"
+	if len(results) > 0 {
+		template = "red"
+		allElements = append(allElements, newHrElement())
+		allElements = append(allElements, newMarkdownElement("**Suggestion:** Please check and handle anomalous processes promptly"))
+	}
 
 	cardContent := map[string]any{
 		"config": map[string]any{"wide_screen_mode": true},
 		"header": map[string]any{
-			"template": "red",
+			"template": template,
 			"title": map[string]any{
-				"content": "Suspicious Process Alert",
+				"content": title,
 				"tag":     "plain_text",
 			},
 		},
@@ -150,7 +197,7 @@ func SendGlobalBatchAlert(results []*NamespaceScanResult, webhookURL string, reg
 		return fmt.Errorf("Lark notification failed: HTTP status code %d", resp.StatusCode)
 	}
 
-	legacy.L.Info("Global Lark alert sent successfully")
+	legacy.L.WithField("title", title).Info("Global Lark alert sent successfully")
 	return nil
 }
 
@@ -192,6 +239,24 @@ func extractReason(message string) string {
 	if strings.Contains(message, "matched blacklist") {
 		return "Blacklisted Process"
 	}
+	if strings.Contains(message, "matched ancestry rule") {
+		return "Suspicious Ancestry"
+	}
+	if strings.Contains(message, "matched escape rule") {
+		return "Container Escape"
+	}
+	if strings.Contains(message, "under watched path") {
+		return "File Integrity Change"
+	}
+	if strings.Contains(message, "matched tampering rule 'kernel_module'") {
+		return "Kernel Module Tampering"
+	}
+	if strings.Contains(message, "matched tampering rule") {
+		return "Library Preload Tampering"
+	}
+	if strings.Contains(message, "matched egress rule") {
+		return "Suspicious Egress"
+	}
 	if strings.Contains(message, "suspicious") {
 		return "Suspicious Behavior"
 	}
@@ -0,0 +1,460 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package egress samples the outbound TCP connections and the sent bytes of
+// every pod network namespace and finds pods that keep connecting to many
+// distinct external addresses, a common sign of exfiltration and botnets.
+package egress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+)
+
+const (
+	// DefaultMinDestinations is the number of distinct external addresses a
+	// pod is flagged at when none is configured
+	DefaultMinDestinations = 50
+	// DefaultSustainedSamples is the number of consecutive scans a pod must
+	// exceed the thresholds in when none is configured
+	DefaultSustainedSamples = 3
+	// DefaultSeverity is reported when no severity is configured
+	DefaultSeverity = "high"
+
+	// topDestinations is the number of destinations listed in a finding
+	topDestinations = 5
+)
+
+// TCP states of /proc/net/tcp
+const (
+	stateEstablished = 0x01
+	stateSynSent     = 0x02
+	stateListen      = 0x0A
+)
+
+// Socket is a line of /proc/net/tcp or /proc/net/tcp6
+type Socket struct {
+	Local  netip.AddrPort
+	Remote netip.AddrPort
+	State  int
+	Inode  uint64
+}
+
+// Sample is the egress of a network namespace at one scan
+type Sample struct {
+	// Netns identifies the network namespace, e.g. net:[4026532301]
+	Netns string
+	// PIDs are the scanned processes of the namespace
+	PIDs []int
+	// Connections are the outbound connections to external addresses, by
+	// destination address
+	Connections map[netip.Addr]int
+	// Inodes are the socket inodes of the outbound external connections
+	Inodes map[uint64]bool
+	// TxBytes is the number of bytes sent on every interface but lo
+	TxBytes uint64
+	At      time.Time
+}
+
+// Destinations returns the number of distinct external addresses
+func (s *Sample) Destinations() int {
+	return len(s.Connections)
+}
+
+// Total returns the number of outbound external connections
+func (s *Sample) Total() int {
+	total := 0
+	for _, count := range s.Connections {
+		total += count
+	}
+	return total
+}
+
+// Finding is a network namespace whose egress exceeded the thresholds for the
+// sustained number of samples
+type Finding struct {
+	// PID is the process with the most outbound external connections
+	PID     int
+	Process string
+	Netns   string
+	// Destinations is the number of distinct external addresses and
+	// Connections the number of outbound connections to them
+	Destinations int
+	Connections  int
+	// TxRate is the sent bytes per second since the previous sample
+	TxRate float64
+	// Samples is the number of consecutive samples above the thresholds
+	Samples int
+	// Top lists the destinations with the most connections
+	Top []string
+}
+
+// history is what the detector remembers of a namespace between scans
+type history struct {
+	txBytes uint64
+	at      time.Time
+	streak  int
+}
+
+// Detector samples the network namespaces of the scanned processes on every
+// scan. It keeps the previous sample of every namespace, so one detector must
+// be used for consecutive scans.
+type Detector struct {
+	procPath         string
+	minDestinations  int
+	minTxRate        float64
+	sustainedSamples int
+	ignored          []netip.Prefix
+	now              func() time.Time
+
+	previous map[string]*history
+}
+
+// NewDetector creates a detector reading processes below procPath
+func NewDetector(procPath string, config models.EgressConfig) (*Detector, error) {
+	d := &Detector{
+		procPath:         procPath,
+		minDestinations:  config.MinDestinations,
+		minTxRate:        float64(config.MinTxBytesPerSecond),
+		sustainedSamples: config.SustainedSamples,
+		now:              time.Now,
+		previous:         make(map[string]*history),
+	}
+	if d.procPath == "" {
+		d.procPath = "/proc"
+	}
+	if d.minDestinations <= 0 {
+		d.minDestinations = DefaultMinDestinations
+	}
+	if d.sustainedSamples <= 0 {
+		d.sustainedSamples = DefaultSustainedSamples
+	}
+	for _, cidr := range config.IgnoredCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored cidr %q: %w", cidr, err)
+		}
+		d.ignored = append(d.ignored, prefix.Masked())
+	}
+	return d, nil
+}
+
+// Scan samples the network namespaces of pids other than the one of the host
+// and returns those above the thresholds for the sustained number of samples,
+// sorted by namespace. Namespaces that are gone are forgotten.
+func (d *Detector) Scan(pids []int) []Finding {
+	hostNet, _ := os.Readlink(filepath.Join(d.procPath, "1", "ns", "net"))
+	byNetns := make(map[string][]int)
+	var order []string
+	for _, pid := range pids {
+		netns, err := os.Readlink(filepath.Join(d.procPath, strconv.Itoa(pid), "ns", "net"))
+		if err != nil || netns == hostNet {
+			continue
+		}
+		if _, ok := byNetns[netns]; !ok {
+			order = append(order, netns)
+		}
+		byNetns[netns] = append(byNetns[netns], pid)
+	}
+	sort.Strings(order)
+
+	var findings []Finding
+	seen := make(map[string]bool, len(order))
+	for _, netns := range order {
+		sample := d.sample(netns, byNetns[netns])
+		if sample == nil {
+			continue
+		}
+		seen[netns] = true
+		if finding, ok := d.evaluate(sample); ok {
+			findings = append(findings, finding)
+		}
+	}
+	for netns := range d.previous {
+		if !seen[netns] {
+			delete(d.previous, netns)
+		}
+	}
+	return findings
+}
+
+// sample reads the sockets and interfaces of netns through the first of pids
+// that can still be read, nil when every process exited
+func (d *Detector) sample(netns string, pids []int) *Sample {
+	for _, pid := range pids {
+		netDir := filepath.Join(d.procPath, strconv.Itoa(pid), "net")
+		v4, err4 := os.ReadFile(filepath.Join(netDir, "tcp"))
+		v6, err6 := os.ReadFile(filepath.Join(netDir, "tcp6"))
+		if err4 != nil && err6 != nil {
+			continue
+		}
+		dev, err := os.ReadFile(filepath.Join(netDir, "dev"))
+		if err != nil {
+			continue
+		}
+		sample := &Sample{
+			Netns:       netns,
+			PIDs:        pids,
+			Connections: make(map[netip.Addr]int),
+			Inodes:      make(map[uint64]bool),
+			TxBytes:     ParseNetDev(dev),
+			At:          d.now(),
+		}
+		sockets := append(ParseTCP(v4), ParseTCP(v6)...)
+		for _, socket := range Outbound(sockets) {
+			remote := socket.Remote.Addr().Unmap()
+			if !d.external(remote) {
+				continue
+			}
+			sample.Connections[remote]++
+			sample.Inodes[socket.Inode] = true
+		}
+		return sample
+	}
+	return nil
+}
+
+// evaluate compares sample with the thresholds and the previous sample
+func (d *Detector) evaluate(sample *Sample) (Finding, bool) {
+	previous := d.previous[sample.Netns]
+	if previous == nil {
+		previous = &history{}
+		d.previous[sample.Netns] = previous
+	}
+	rate := 0.0
+	measured := false
+	if !previous.at.IsZero() && sample.At.After(previous.at) && sample.TxBytes >= previous.txBytes {
+		rate = float64(sample.TxBytes-previous.txBytes) / sample.At.Sub(previous.at).Seconds()
+		measured = true
+	}
+	previous.txBytes = sample.TxBytes
+	previous.at = sample.At
+
+	above := sample.Destinations() >= d.minDestinations
+	if d.minTxRate > 0 {
+		above = above && measured && rate >= d.minTxRate
+	}
+	if !above {
+		previous.streak = 0
+		return Finding{}, false
+	}
+	previous.streak++
+	if previous.streak < d.sustainedSamples {
+		return Finding{}, false
+	}
+
+	pid, process := d.owner(sample)
+	return Finding{
+		PID:          pid,
+		Process:      process,
+		Netns:        sample.Netns,
+		Destinations: sample.Destinations(),
+		Connections:  sample.Total(),
+		TxRate:       rate,
+		Samples:      previous.streak,
+		Top:          top(sample.Connections, topDestinations),
+	}, true
+}
+
+// owner returns the process holding the most outbound external sockets of
+// sample, the first process when none of the sockets can be attributed
+func (d *Detector) owner(sample *Sample) (int, string) {
+	best, bestCount := sample.PIDs[0], 0
+	for _, pid := range sample.PIDs {
+		fdDir := filepath.Join(d.procPath, strconv.Itoa(pid), "fd")
+		entries, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		count := 0
+		for _, entry := range entries {
+			target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := socketInode(target)
+			if ok && sample.Inodes[inode] {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = pid, count
+		}
+	}
+	return best, processName(filepath.Join(d.procPath, strconv.Itoa(best)))
+}
+
+// external reports whether addr is outside of the host, the cluster and the
+// ignored ranges
+func (d *Detector) external(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsUnspecified() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range d.ignored {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Outbound returns the established and connecting sockets whose local port is
+// not a listening port of the namespace, the connections the namespace opened
+func Outbound(sockets []Socket) []Socket {
+	listening := make(map[uint16]bool)
+	for _, socket := range sockets {
+		if socket.State == stateListen {
+			listening[socket.Local.Port()] = true
+		}
+	}
+	var outbound []Socket
+	for _, socket := range sockets {
+		if socket.State != stateEstablished && socket.State != stateSynSent {
+			continue
+		}
+		if listening[socket.Local.Port()] {
+			continue
+		}
+		outbound = append(outbound, socket)
+	}
+	return outbound
+}
+
+// ParseTCP parses the content of /proc/net/tcp or /proc/net/tcp6, skipping
+// lines that cannot be parsed
+func ParseTCP(data []byte) []Socket {
+	var sockets []Socket
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+		local, err := parseAddrPort(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := parseAddrPort(fields[2])
+		if err != nil {
+			continue
+		}
+		state, err := strconv.ParseInt(fields[3], 16, 32)
+		if err != nil {
+			continue
+		}
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+		sockets = append(sockets, Socket{Local: local, Remote: remote, State: int(state), Inode: inode})
+	}
+	return sockets
+}
+
+// parseAddrPort parses an address of /proc/net/tcp, the hexadecimal address in
+// host byte order of every 32 bit word followed by the hexadecimal port
+func parseAddrPort(value string) (netip.AddrPort, error) {
+	addrHex, portHex, ok := strings.Cut(value, ":")
+	if !ok {
+		return netip.AddrPort{}, fmt.Errorf("invalid address %q", value)
+	}
+	raw, err := hex.DecodeString(addrHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return netip.AddrPort{}, fmt.Errorf("invalid address %q", value)
+	}
+	// The words are written in host byte order, which is little endian on
+	// the architectures procscan runs on
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid port %q", value)
+	}
+	addr, _ := netip.AddrFromSlice(raw)
+	return netip.AddrPortFrom(addr, uint16(port)), nil
+}
+
+// ParseNetDev returns the bytes sent on every interface of /proc/net/dev but lo
+func ParseNetDev(data []byte) uint64 {
+	var total uint64
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		// 8 receive counters are followed by the transmitted bytes
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			continue
+		}
+		if sent, err := strconv.ParseUint(fields[8], 10, 64); err == nil {
+			total += sent
+		}
+	}
+	return total
+}
+
+// socketInode returns the inode of an fd link like socket:[12345]
+func socketInode(target string) (uint64, bool) {
+	value, ok := strings.CutPrefix(target, "socket:[")
+	if !ok {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(strings.TrimSuffix(value, "]"), 10, 64)
+	return inode, err == nil
+}
+
+// top returns the n destinations with the most connections as address:count
+func top(connections map[netip.Addr]int, n int) []string {
+	addrs := make([]netip.Addr, 0, len(connections))
+	for addr := range connections {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		if connections[addrs[i]] != connections[addrs[j]] {
+			return connections[addrs[i]] > connections[addrs[j]]
+		}
+		return addrs[i].Less(addrs[j])
+	})
+	if len(addrs) > n {
+		addrs = addrs[:n]
+	}
+	listed := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		listed = append(listed, fmt.Sprintf("%s:%d", addr, connections[addr]))
+	}
+	return listed
+}
+
+func processName(procDir string) string {
+	comm, err := os.ReadFile(filepath.Join(procDir, "comm"))
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(comm))
+}
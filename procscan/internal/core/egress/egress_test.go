@@ -0,0 +1,198 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEgress(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Egress Suite")
+}
+
+// hexAddr encodes an IPv4 address and port like /proc/net/tcp
+func hexAddr(addr string, port int) string {
+	ip := netip.MustParseAddr(addr).As4()
+	return fmt.Sprintf("%02X%02X%02X%02X:%04X", ip[3], ip[2], ip[1], ip[0], port)
+}
+
+// tcpLine is a line of /proc/net/tcp
+func tcpLine(local, remote string, state, inode int) string {
+	return fmt.Sprintf("   0: %s %s %02X 00000000:00000000 00:00000000 00000000  1000        0 %d 1 0000000000000000 20 4 30 10 -1",
+		local, remote, state, inode)
+}
+
+const tcpHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode"
+
+func netDev(eth0Sent int) string {
+	return fmt.Sprintf(`Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    5000      50    0    0    0     0          0         0     5000      50    0    0    0     0       0          0
+  eth0: 1000000    900    0    0    0     0          0         0 %8d    800    0    0    0     0       0          0
+`, eth0Sent)
+}
+
+var _ = Describe("Parsing", func() {
+	It("should parse IPv4 and IPv4-mapped IPv6 sockets", func() {
+		v4 := tcpHeader + "\n" + tcpLine(hexAddr("10.0.0.5", 43210), hexAddr("1.2.3.4", 443), stateEstablished, 101) + "\n"
+		sockets := ParseTCP([]byte(v4))
+		Expect(sockets).To(Equal([]Socket{{
+			Local:  netip.MustParseAddrPort("10.0.0.5:43210"),
+			Remote: netip.MustParseAddrPort("1.2.3.4:443"),
+			State:  stateEstablished,
+			Inode:  101,
+		}}))
+
+		v6 := tcpLine("00000000000000000000000001000000:1F90", "0000000000000000FFFF000004030201:01BB", stateSynSent, 102)
+		sockets = ParseTCP([]byte(v6))
+		Expect(sockets).To(HaveLen(1))
+		Expect(sockets[0].Local.Addr()).To(Equal(netip.MustParseAddr("::1")))
+		Expect(sockets[0].Remote.Addr().Unmap()).To(Equal(netip.MustParseAddr("1.2.3.4")))
+		Expect(sockets[0].Remote.Port()).To(BeEquivalentTo(443))
+	})
+
+	It("should only keep connections the namespace opened", func() {
+		sockets := []Socket{
+			{Local: netip.MustParseAddrPort("0.0.0.0:8080"), State: stateListen},
+			{Local: netip.MustParseAddrPort("10.0.0.5:8080"), Remote: netip.MustParseAddrPort("1.1.1.1:5000"), State: stateEstablished},
+			{Local: netip.MustParseAddrPort("10.0.0.5:40000"), Remote: netip.MustParseAddrPort("2.2.2.2:443"), State: stateEstablished},
+			{Local: netip.MustParseAddrPort("10.0.0.5:40001"), Remote: netip.MustParseAddrPort("3.3.3.3:443"), State: 0x06},
+		}
+		Expect(Outbound(sockets)).To(Equal(sockets[2:3]))
+	})
+
+	It("should sum the sent bytes of every interface but lo", func() {
+		Expect(ParseNetDev([]byte(netDev(4096)))).To(BeEquivalentTo(4096))
+	})
+})
+
+var _ = Describe("Detector", func() {
+	var procPath string
+	var now time.Time
+
+	writeProcess := func(pid int, netns, comm string, fds ...int) {
+		dir := filepath.Join(procPath, strconv.Itoa(pid))
+		Expect(os.MkdirAll(filepath.Join(dir, "ns"), 0o755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "fd"), 0o755)).To(Succeed())
+		Expect(os.Symlink(netns, filepath.Join(dir, "ns", "net"))).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o644)).To(Succeed())
+		for i, inode := range fds {
+			Expect(os.Symlink(fmt.Sprintf("socket:[%d]", inode), filepath.Join(dir, "fd", strconv.Itoa(i+3)))).To(Succeed())
+		}
+	}
+	writeNet := func(pid int, sent int, lines ...string) {
+		dir := filepath.Join(procPath, strconv.Itoa(pid), "net")
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+		tcp := tcpHeader + "\n" + strings.Join(lines, "\n") + "\n"
+		Expect(os.WriteFile(filepath.Join(dir, "tcp"), []byte(tcp), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "tcp6"), []byte(tcpHeader+"\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "dev"), []byte(netDev(sent)), 0o644)).To(Succeed())
+	}
+	connections := func() []string {
+		return []string{
+			tcpLine(hexAddr("0.0.0.0", 8080), hexAddr("0.0.0.0", 0), stateListen, 1),
+			tcpLine(hexAddr("10.0.0.5", 40001), hexAddr("45.1.1.1", 3333), stateEstablished, 11),
+			tcpLine(hexAddr("10.0.0.5", 40002), hexAddr("45.1.1.1", 3333), stateEstablished, 12),
+			tcpLine(hexAddr("10.0.0.5", 40003), hexAddr("45.2.2.2", 22), stateSynSent, 13),
+			tcpLine(hexAddr("10.0.0.5", 40004), hexAddr("100.64.0.9", 53), stateEstablished, 14),
+			tcpLine(hexAddr("10.0.0.5", 40005), hexAddr("10.96.0.1", 443), stateEstablished, 15),
+			tcpLine(hexAddr("10.0.0.5", 8080), hexAddr("45.3.3.3", 51000), stateEstablished, 16),
+		}
+	}
+
+	BeforeEach(func() {
+		procPath = GinkgoT().TempDir()
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		writeProcess(1, "net:[1]", "systemd")
+		writeNet(1, 0, connections()...)
+		writeProcess(100, "net:[2]", "pause")
+		writeProcess(101, "net:[2]", "xmrig", 11, 12, 13)
+		writeNet(100, 1000, connections()...)
+	})
+
+	newDetector := func(config models.EgressConfig) *Detector {
+		d, err := NewDetector(procPath, config)
+		Expect(err).NotTo(HaveOccurred())
+		d.now = func() time.Time { return now }
+		return d
+	}
+
+	It("should report sustained egress of pod namespaces with the owning process", func() {
+		d := newDetector(models.EgressConfig{
+			MinDestinations:  2,
+			SustainedSamples: 2,
+			IgnoredCIDRs:     []string{"100.64.0.0/10"},
+		})
+		Expect(d.Scan([]int{1, 100, 101})).To(BeEmpty())
+
+		now = now.Add(time.Minute)
+		findings := d.Scan([]int{1, 100, 101})
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0]).To(Equal(Finding{
+			PID:          101,
+			Process:      "xmrig",
+			Netns:        "net:[2]",
+			Destinations: 2,
+			Connections:  3,
+			Samples:      2,
+			Top:          []string{"45.1.1.1:2", "45.2.2.2:1"},
+		}))
+	})
+
+	It("should reset the streak when the namespace falls below the thresholds", func() {
+		d := newDetector(models.EgressConfig{MinDestinations: 3, SustainedSamples: 1})
+		// 100.64.0.9 is only counted without ignored CIDRs
+		Expect(d.Scan([]int{100, 101})).To(HaveLen(1))
+
+		writeNet(100, 2000, connections()[:3]...)
+		Expect(d.Scan([]int{100, 101})).To(BeEmpty())
+		Expect(d.previous["net:[2]"].streak).To(BeZero())
+
+		Expect(d.Scan([]int{1})).To(BeEmpty())
+		Expect(d.previous).To(BeEmpty())
+	})
+
+	It("should require the sent bytes rate when configured", func() {
+		d := newDetector(models.EgressConfig{MinDestinations: 2, SustainedSamples: 1, MinTxBytesPerSecond: 100})
+		Expect(d.Scan([]int{100, 101})).To(BeEmpty())
+
+		now = now.Add(10 * time.Second)
+		writeNet(100, 1500, connections()...)
+		Expect(d.Scan([]int{100, 101})).To(BeEmpty())
+
+		now = now.Add(10 * time.Second)
+		writeNet(100, 11500, connections()...)
+		findings := d.Scan([]int{100, 101})
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].TxRate).To(BeNumerically("==", 1000))
+	})
+
+	It("should reject invalid ignored CIDRs", func() {
+		_, err := NewDetector(procPath, models.EgressConfig{IgnoredCIDRs: []string{"10.0.0.1"}})
+		Expect(err).To(MatchError(ContainSubstring("invalid ignored cidr")))
+	})
+})
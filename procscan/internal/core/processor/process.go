@@ -18,6 +18,7 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -40,12 +41,29 @@ type compiledRules struct {
 	whitelistCommands   []*regexp.Regexp
 	whitelistNamespaces []*regexp.Regexp
 	whitelistPodNames   []*regexp.Regexp
+	ancestry            []compiledAncestryRule
+	escape              models.EscapeRules
+	exceptions          []compiledException
+}
+
+type compiledException struct {
+	process   *regexp.Regexp
+	exception models.Exception
+}
+
+type compiledAncestryRule struct {
+	name   string
+	parent *regexp.Regexp
+	child  *regexp.Regexp
+	depth  int
 }
 
 type Processor struct {
-	ProcPath string
-	rules    compiledRules
-	mu       sync.RWMutex
+	ProcPath      string
+	rules         compiledRules
+	runtime       *container.Resolver
+	runtimeConfig models.ContainerRuntimeConfig
+	mu            sync.RWMutex
 }
 
 // compileRules compiles a list of regex patterns into compiled regular expressions
@@ -62,6 +80,43 @@ func compileRules(patterns []string) []*regexp.Regexp {
 	return regexps
 }
 
+// compileAncestryRules compiles ancestry rules, skipping rules with invalid patterns
+func compileAncestryRules(rules []models.AncestryRule) []compiledAncestryRule {
+	compiled := make([]compiledAncestryRule, 0, len(rules))
+	for _, rule := range rules {
+		parent, err := regexp.Compile(rule.Parent)
+		if err != nil {
+			legacy.L.WithFields(logrus.Fields{"rule": rule.Name, "parent": rule.Parent}).WithError(err).Warn("Invalid ancestry parent pattern, skipping")
+			continue
+		}
+		child, err := regexp.Compile(rule.Child)
+		if err != nil {
+			legacy.L.WithFields(logrus.Fields{"rule": rule.Name, "child": rule.Child}).WithError(err).Warn("Invalid ancestry child pattern, skipping")
+			continue
+		}
+		depth := rule.Depth
+		if depth <= 0 {
+			depth = 1
+		}
+		compiled = append(compiled, compiledAncestryRule{name: rule.Name, parent: parent, child: child, depth: depth})
+	}
+	return compiled
+}
+
+// compileExceptions compiles the process patterns of exceptions, skipping invalid ones
+func compileExceptions(exceptions []models.Exception) []compiledException {
+	compiled := make([]compiledException, 0, len(exceptions))
+	for _, exception := range exceptions {
+		process, err := regexp.Compile(exception.Process)
+		if err != nil {
+			legacy.L.WithFields(logrus.Fields{"namespace": exception.Namespace, "process": exception.Process}).WithError(err).Warn("Invalid exception process pattern, skipping")
+			continue
+		}
+		compiled = append(compiled, compiledException{process: process, exception: exception})
+	}
+	return compiled
+}
+
 // NewProcessor creates a new processor instance with the given configuration
 func NewProcessor(config *models.Config) *Processor {
 	p := &Processor{ProcPath: config.Scanner.ProcPath}
@@ -73,6 +128,13 @@ func NewProcessor(config *models.Config) *Processor {
 func (p *Processor) UpdateConfig(config *models.Config) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if p.runtime == nil || p.runtimeConfig != config.ContainerRuntime {
+		if p.runtime != nil {
+			p.runtime.Close()
+		}
+		p.runtime = container.NewResolver(config.ContainerRuntime)
+		p.runtimeConfig = config.ContainerRuntime
+	}
 	rules := config.DetectionRules
 	p.rules = compiledRules{
 		blacklistProcesses:  compileRules(rules.Blacklist.Processes),
@@ -81,9 +143,19 @@ func (p *Processor) UpdateConfig(config *models.Config) {
 		whitelistCommands:   compileRules(rules.Whitelist.Commands),
 		whitelistNamespaces: compileRules(rules.Whitelist.Namespaces),
 		whitelistPodNames:   compileRules(rules.Whitelist.PodNames),
+		ancestry:            compileAncestryRules(rules.Ancestry),
+		escape:              rules.Escape,
+		exceptions:          compileExceptions(rules.Exceptions),
 	}
 }
 
+// NeedsProcessTree reports whether any configured rule inspects the process tree
+func (p *Processor) NeedsProcessTree() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.rules.ancestry) > 0 || p.rules.escape.Nsenter || p.rules.escape.CgroupMismatch
+}
+
 // GetAllProcesses returns a list of all process IDs from the proc filesystem
 func (p *Processor) GetAllProcesses() ([]int, error) {
 	procDirs, err := os.ReadDir(p.ProcPath)
@@ -119,6 +191,12 @@ func matchAny(text string, regexps []*regexp.Regexp) (bool, string) {
 // Returns process info if malicious, nil otherwise
 // This function queries container info on-demand instead of using cache
 func (p *Processor) AnalyzeProcess(pid int) (*models.ProcessInfo, error) {
+	return p.AnalyzeProcessInTree(pid, nil)
+}
+
+// AnalyzeProcessInTree is AnalyzeProcess with the process tree of the current
+// scan, which enables the ancestry and container escape rules
+func (p *Processor) AnalyzeProcessInTree(pid int, tree *ProcessTree) (*models.ProcessInfo, error) {
 	procDir := filepath.Join(p.ProcPath, strconv.Itoa(pid))
 	cmdlineFile := filepath.Join(procDir, "cmdline")
 	cmdlineData, err := os.ReadFile(cmdlineFile)
@@ -141,35 +219,48 @@ func (p *Processor) AnalyzeProcess(pid int) (*models.ProcessInfo, error) {
 	})
 	procLogger.Debug("Starting process analysis,Process Info:")
 
-	// Step 1: Check if process matches blacklist
+	// Step 1: Check if process matches blacklist, then the process tree rules
 	isBlacklisted, message := p.isBlacklisted(processName, cmdline)
+	var matched *treeMatch
+	if !isBlacklisted && tree != nil {
+		if matched = p.matchTreeRules(tree, pid, processName); matched != nil {
+			isBlacklisted, message = true, matched.message
+		}
+	}
 	if !isBlacklisted {
 		procLogger.Debug("Process not in blacklist, skipping")
 		return nil, nil
 	}
 	procLogger.WithField("reason", message).Info("Process matched blacklist rule")
 
-	// Step 2: Check process whitelist (before heavy operations)
-	if p.isProcessWhitelisted(processName, cmdline) {
+	// Step 2: Check process whitelist (before heavy operations). Tree rules name
+	// the processes they flag explicitly, e.g. shells that are whitelisted on
+	// their own, so only blacklist matches can be whitelisted.
+	if matched == nil && p.isProcessWhitelisted(processName, cmdline) {
 		procLogger.Info("Process is whitelisted, ignoring")
 		return nil, nil
 	}
 
-	// Step 3: Identify container main process
+	// Step 3: Identify container main process. Processes that left their
+	// container cgroup are attributed to the container of their ancestor.
+	tracePID := pid
+	if matched != nil && matched.containerPID != 0 {
+		tracePID = matched.containerPID
+	}
 	var mainProcessPID int
-	processStatus, err := ReadProcessStatus(p.ProcPath, pid)
+	processStatus, err := ReadProcessStatus(p.ProcPath, tracePID)
 	if err != nil {
 		procLogger.WithError(err).Debug("Failed to read process status")
 	} else {
 		if IsContainerMainProcess(processStatus) {
-			mainProcessPID = pid
+			mainProcessPID = tracePID
 			procLogger.WithField("main_process_pid", mainProcessPID).Info("Detected malicious process is container main process")
 		} else {
 			// Trace back to find container main process
-			mainPID, err := FindContainerMainProcess(p.ProcPath, pid)
+			mainPID, err := FindContainerMainProcess(p.ProcPath, tracePID)
 			if err != nil {
 				procLogger.WithError(err).Debug("Failed to find container main process, continuing with current PID")
-				mainProcessPID = pid
+				mainProcessPID = tracePID
 			} else {
 				mainProcessPID = mainPID
 				procLogger.WithFields(logrus.Fields{
@@ -187,8 +278,8 @@ func (p *Processor) AnalyzeProcess(pid int) (*models.ProcessInfo, error) {
 		return nil, nil
 	}
 
-	// Step 5: Query container info on-demand (no cache)
-	containerInfo, err := container.GetContainerInfoDetailed(containerID)
+	// Step 5: Resolve the container to its pod through the CRI
+	containerInfo, err := p.resolveContainer(containerID)
 	if err != nil {
 		procLogger.WithFields(logrus.Fields{
 			"containerID": containerID,
@@ -226,12 +317,27 @@ func (p *Processor) AnalyzeProcess(pid int) (*models.ProcessInfo, error) {
 	// 通过 label 判断是 app 还是 devbox
 	appType, appName := p.determineAppTypeAndName(containerInfo.Labels, podName)
 
+	var ancestry []string
+	if matched != nil {
+		ancestry = matched.ancestry
+	}
+	exception := p.findException(namespace, processName, time.Now())
+	if exception != nil {
+		procLogger.WithFields(logrus.Fields{
+			"namespace":     namespace,
+			"expires":       exception.Expires.Format(time.RFC3339),
+			"justification": exception.Justification,
+		}).Info("Process is allowed by an exception")
+	}
 	return &models.ProcessInfo{
 		PID:         pid,
 		ProcessName: processName,
 		Command:     cmdline,
 		Timestamp:   time.Now().Format(time.RFC3339),
 		ContainerID: containerID,
+		SandboxID:   containerInfo.SandboxID,
+		Image:       containerInfo.Image,
+		ImageDigest: containerInfo.ImageDigest,
 		Message:     message,
 		PodName:     podName,
 		Namespace:   namespace,
@@ -239,9 +345,92 @@ func (p *Processor) AnalyzeProcess(pid int) (*models.ProcessInfo, error) {
 		AppType:     appType,
 		AppName:     appName,
 		MatchedRule: p.extractMatchedRule(message),
+		Ancestry:    ancestry,
+		Exception:   exception,
 	}, nil
 }
 
+// findException returns the exception allowing processName in namespace at
+// now, or nil. Matching exceptions that expired are logged and ignored.
+func (p *Processor) findException(namespace, processName string, now time.Time) *models.Exception {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, compiled := range p.rules.exceptions {
+		if compiled.exception.Namespace != namespace || !compiled.process.MatchString(processName) {
+			continue
+		}
+		if !compiled.exception.Active(now) {
+			legacy.L.WithFields(logrus.Fields{
+				"namespace": namespace,
+				"process":   processName,
+				"expired":   compiled.exception.Expires.Format(time.RFC3339),
+			}).Warn("Exception has expired, process is reported as a violation")
+			continue
+		}
+		exception := compiled.exception
+		return &exception
+	}
+	return nil
+}
+
+// treeMatch describes a process matched by a process tree rule
+type treeMatch struct {
+	message  string
+	ancestry []string
+	// containerPID is the ancestor whose container the process belongs to,
+	// set when the process itself is outside any container cgroup
+	containerPID int
+}
+
+// matchTreeRules checks the ancestry and container escape rules for pid
+func (p *Processor) matchTreeRules(tree *ProcessTree, pid int, processName string) *treeMatch {
+	node := tree.Node(pid)
+	if node == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ancestors := tree.Ancestors(pid, 0)
+	names := make([]string, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		names = append(names, ancestor.Name)
+	}
+
+	for _, rule := range p.rules.ancestry {
+		if !rule.child.MatchString(processName) {
+			continue
+		}
+		for _, ancestor := range ancestors[:min(rule.depth, len(ancestors))] {
+			if rule.parent.MatchString(ancestor.Name) {
+				return &treeMatch{
+					message:  fmt.Sprintf("Process '%s' spawned by '%s' matched ancestry rule '%s'", processName, ancestor.Name, rule.name),
+					ancestry: names,
+				}
+			}
+		}
+	}
+
+	if p.rules.escape.Nsenter && processName == "nsenter" {
+		return &treeMatch{
+			message:  fmt.Sprintf("Process '%s' entering other namespaces matched escape rule 'nsenter'", processName),
+			ancestry: names,
+		}
+	}
+	if p.rules.escape.CgroupMismatch && node.ContainerID == "" {
+		for _, ancestor := range ancestors {
+			if ancestor.ContainerID != "" {
+				return &treeMatch{
+					message: fmt.Sprintf("Process '%s' outside the cgroup of its container ancestor '%s' matched escape rule 'cgroupMismatch'",
+						processName, ancestor.Name),
+					ancestry:     names,
+					containerPID: ancestor.PID,
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // isBlacklisted checks if a process name or command line matches blacklist rules
 func (p *Processor) isBlacklisted(processName, cmdline string) (bool, string) {
 	p.mu.RLock()
@@ -295,22 +484,65 @@ func (p *Processor) getContainerIDFromPID(pid int) string {
 	if err != nil {
 		return ""
 	}
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "containerd") || strings.Contains(line, "docker") || strings.Contains(line, "kubepods") {
-			parts := strings.Split(line, "/")
-			for _, part := range parts {
-				if strings.HasPrefix(part, "cri-containerd-") && strings.HasSuffix(part, ".scope") {
-					containerID := strings.TrimPrefix(part, "cri-containerd-")
-					containerID = strings.TrimSuffix(containerID, ".scope")
-					if len(containerID) == 64 && isHexString(containerID) {
-						return containerID
-					}
-				}
-			}
-		}
+	return containerIDFromCgroup(string(content))
+}
+
+// ContainerInfo returns the pod of the container pid runs in
+func (p *Processor) ContainerInfo(pid int) (*container.ContainerInfo, error) {
+	containerID := p.getContainerIDFromPID(pid)
+	if containerID == "" {
+		return nil, fmt.Errorf("process %d does not run in a container", pid)
+	}
+	return p.resolveContainer(containerID)
+}
+
+// TenantProcess returns the pod fields of a violation of pid and the exception
+// of processName in its namespace. It returns nil when pid does not run in a
+// container of an ns- namespace or when its pod is whitelisted.
+func (p *Processor) TenantProcess(pid int, processName string) *models.ProcessInfo {
+	containerID := p.getContainerIDFromPID(pid)
+	if containerID == "" {
+		return nil
+	}
+	info, err := p.resolveContainer(containerID)
+	if err != nil {
+		return nil
+	}
+	if !strings.HasPrefix(info.PodNamespace, "ns-") || p.isInfraWhitelisted(info.PodNamespace, info.PodName) {
+		return nil
+	}
+	appType, appName := p.determineAppTypeAndName(info.Labels, info.PodName)
+	return &models.ProcessInfo{
+		PID:         pid,
+		ProcessName: processName,
+		ContainerID: containerID,
+		SandboxID:   info.SandboxID,
+		Image:       info.Image,
+		ImageDigest: info.ImageDigest,
+		PodName:     info.PodName,
+		Namespace:   info.PodNamespace,
+		PodLabels:   info.Labels,
+		AppType:     appType,
+		AppName:     appName,
+		Exception:   p.findException(info.PodNamespace, processName, time.Now()),
+	}
+}
+
+// resolveContainer queries the container runtime for the pod of a container
+func (p *Processor) resolveContainer(containerID string) (*container.ContainerInfo, error) {
+	p.mu.RLock()
+	runtime := p.runtime
+	p.mu.RUnlock()
+	return runtime.Resolve(context.Background(), containerID)
+}
+
+// Close closes the connection to the container runtime
+func (p *Processor) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.runtime != nil {
+		p.runtime.Close()
 	}
-	return ""
 }
 
 // isHexString checks if a string contains only hexadecimal characters
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
 	. "github.com/onsi/ginkgo/v2"
@@ -206,6 +207,40 @@ var _ = Describe("Processor", func() {
 		})
 	})
 
+	Describe("findException", func() {
+		var processor *Processor
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			processor = NewProcessor(&models.Config{
+				DetectionRules: models.DetectionRules{
+					Exceptions: []models.Exception{
+						{Namespace: "ns-old", Process: "^xmrig$", Expires: now.Add(-time.Hour), Justification: "Expired"},
+						{Namespace: "ns-lab", Process: "[invalid", Expires: now.Add(time.Hour), Justification: "Invalid"},
+						{Namespace: "ns-lab", Process: "^xmrig$", Expires: now.Add(time.Hour), Justification: "Benchmark"},
+					},
+				},
+			})
+		})
+
+		It("should skip invalid exception patterns", func() {
+			Expect(processor.rules.exceptions).To(HaveLen(2))
+		})
+
+		It("should return the exception of the namespace and process", func() {
+			exception := processor.findException("ns-lab", "xmrig", now)
+			Expect(exception).NotTo(BeNil())
+			Expect(exception.Justification).To(Equal("Benchmark"))
+			Expect(processor.findException("ns-other", "xmrig", now)).To(BeNil())
+			Expect(processor.findException("ns-lab", "minerd", now)).To(BeNil())
+		})
+
+		It("should ignore expired exceptions", func() {
+			Expect(processor.findException("ns-old", "xmrig", now)).To(BeNil())
+			Expect(processor.findException("ns-lab", "xmrig", now.Add(time.Hour))).To(BeNil())
+		})
+	})
+
 	Describe("isProcessWhitelisted", func() {
 		var processor *Processor
 
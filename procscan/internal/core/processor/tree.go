@@ -0,0 +1,122 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessNode is a process in a ProcessTree
+type ProcessNode struct {
+	PID     int
+	PPID    int
+	Name    string
+	Cmdline string
+	// ContainerID is empty when the cgroup of the process is not a container cgroup
+	ContainerID string
+}
+
+// ProcessTree is a snapshot of the parent-child relationships in /proc
+type ProcessTree struct {
+	nodes map[int]*ProcessNode
+}
+
+// BuildProcessTree reads the status, cmdline and cgroup of pids below procPath.
+// Processes that exit while the tree is built are left out.
+func BuildProcessTree(procPath string, pids []int) *ProcessTree {
+	tree := &ProcessTree{nodes: make(map[int]*ProcessNode, len(pids))}
+	for _, pid := range pids {
+		status, err := ReadProcessStatus(procPath, pid)
+		if err != nil {
+			continue
+		}
+		procDir := filepath.Join(procPath, strconv.Itoa(pid))
+		node := &ProcessNode{PID: pid, PPID: status.PPID, Name: status.Name}
+		if data, err := os.ReadFile(filepath.Join(procDir, "cgroup")); err == nil {
+			node.ContainerID = containerIDFromCgroup(string(data))
+		}
+		if data, err := os.ReadFile(filepath.Join(procDir, "cmdline")); err == nil {
+			node.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+			if fields := strings.Fields(node.Cmdline); len(fields) > 0 {
+				// The status name is truncated to 15 characters
+				node.Name = filepath.Base(fields[0])
+			}
+		}
+		tree.nodes[pid] = node
+	}
+	return tree
+}
+
+// Node returns the process with pid, nil when it is not part of the tree
+func (t *ProcessTree) Node(pid int) *ProcessNode {
+	if t == nil {
+		return nil
+	}
+	return t.nodes[pid]
+}
+
+// Ancestors returns up to depth ancestors of pid, nearest first. A depth of
+// zero or less returns the whole chain up to the init process.
+func (t *ProcessTree) Ancestors(pid, depth int) []*ProcessNode {
+	var ancestors []*ProcessNode
+	visited := map[int]bool{pid: true}
+	node := t.Node(pid)
+	for node != nil && (depth <= 0 || len(ancestors) < depth) {
+		parent := t.Node(node.PPID)
+		if parent == nil || visited[parent.PID] {
+			break
+		}
+		visited[parent.PID] = true
+		ancestors = append(ancestors, parent)
+		node = parent
+	}
+	return ancestors
+}
+
+// containerCgroupPrefixes are the prefixes of container scopes of the systemd
+// cgroup driver, for containerd, CRI-O and docker
+var containerCgroupPrefixes = []string{"cri-containerd-", "crio-", "docker-"}
+
+// containerIDFromCgroup extracts the container ID from the content of a
+// /proc/{pid}/cgroup file. Both the systemd cgroup driver, where containers are
+// scopes such as cri-containerd-<id>.scope or crio-<id>.scope, and the cgroupfs
+// driver, where containers are plain <id> directories below the pod, are supported.
+func containerIDFromCgroup(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.Contains(line, "containerd") && !strings.Contains(line, "crio") &&
+			!strings.Contains(line, "docker") && !strings.Contains(line, "kubepods") {
+			continue
+		}
+		for _, part := range strings.Split(line, "/") {
+			// The conmon monitor of CRI-O runs next to the container, not inside it
+			if strings.HasPrefix(part, "crio-conmon-") {
+				continue
+			}
+			containerID := strings.TrimSuffix(part, ".scope")
+			if containerID != part {
+				for _, prefix := range containerCgroupPrefixes {
+					containerID = strings.TrimPrefix(containerID, prefix)
+				}
+			}
+			if len(containerID) == 64 && isHexString(containerID) {
+				return containerID
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessTree", func() {
+	const containerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	containerCgroup := "0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-" + containerID + ".scope\n"
+	hostCgroup := "0::/system.slice/containerd.service\n"
+
+	var procDir string
+
+	writeProcess := func(pid, ppid int, cmdline, cgroup string) {
+		dir := filepath.Join(procDir, strconv.Itoa(pid))
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+		name := filepath.Base(strings.Fields(cmdline)[0])
+		status := "Name:\t" + name + "\nPid:\t" + strconv.Itoa(pid) + "\nPPid:\t" + strconv.Itoa(ppid) + "\n"
+		Expect(os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.ReplaceAll(cmdline, " ", "\x00")), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o644)).To(Succeed())
+	}
+
+	newProcessor := func(rules models.DetectionRules) *Processor {
+		return NewProcessor(&models.Config{
+			Scanner:        models.ScannerConfig{ProcPath: procDir},
+			DetectionRules: rules,
+		})
+	}
+
+	BeforeEach(func() {
+		procDir = GinkgoT().TempDir()
+		writeProcess(1, 0, "/sbin/init", hostCgroup)
+		writeProcess(100, 1, "/usr/bin/containerd-shim-runc-v2 -namespace k8s.io", hostCgroup)
+		writeProcess(200, 100, "/usr/sbin/nginx -g daemon off;", containerCgroup)
+		writeProcess(201, 200, "/bin/sh -c id", containerCgroup)
+		writeProcess(202, 201, "/usr/bin/curl http://example.com", containerCgroup)
+		writeProcess(300, 200, "/usr/bin/nsenter -t 1 -m -u -i -n sh", containerCgroup)
+		writeProcess(400, 200, "/tmp/payload", hostCgroup)
+	})
+
+	It("should link processes to their ancestors", func() {
+		tree := BuildProcessTree(procDir, []int{1, 100, 200, 201, 202, 300, 400, 999})
+		Expect(tree.Node(999)).To(BeNil())
+		Expect(tree.Node(200).ContainerID).To(Equal(containerID))
+		Expect(tree.Node(400).ContainerID).To(BeEmpty())
+
+		var names []string
+		for _, ancestor := range tree.Ancestors(202, 0) {
+			names = append(names, ancestor.Name)
+		}
+		Expect(names).To(Equal([]string{"sh", "nginx", "containerd-shim-runc-v2", "init"}))
+		Expect(tree.Ancestors(202, 2)).To(HaveLen(2))
+	})
+
+	It("should match parent-child rules within the configured depth", func() {
+		p := newProcessor(models.DetectionRules{Ancestry: []models.AncestryRule{
+			{Name: "web-shell", Parent: "^nginx$", Child: "^(sh|bash)$"},
+			{Name: "web-download", Parent: "^nginx$", Child: "^curl$", Depth: 2},
+			{Name: "too-deep", Parent: "^containerd-shim", Child: "^curl$", Depth: 2},
+		}})
+		Expect(p.NeedsProcessTree()).To(BeTrue())
+		tree := BuildProcessTree(procDir, []int{1, 100, 200, 201, 202})
+
+		match := p.matchTreeRules(tree, 201, "sh")
+		Expect(match).NotTo(BeNil())
+		Expect(p.extractMatchedRule(match.message)).To(Equal("web-shell"))
+		Expect(match.ancestry).To(Equal([]string{"nginx", "containerd-shim-runc-v2", "init"}))
+
+		match = p.matchTreeRules(tree, 202, "curl")
+		Expect(match).NotTo(BeNil())
+		Expect(p.extractMatchedRule(match.message)).To(Equal("web-download"))
+		Expect(p.matchTreeRules(tree, 200, "nginx")).To(BeNil())
+	})
+
+	It("should flag nsenter and processes outside their container cgroup", func() {
+		tree := BuildProcessTree(procDir, []int{1, 100, 200, 300, 400})
+		Expect(newProcessor(models.DetectionRules{}).matchTreeRules(tree, 300, "nsenter")).To(BeNil())
+
+		p := newProcessor(models.DetectionRules{Escape: models.EscapeRules{Nsenter: true, CgroupMismatch: true}})
+		match := p.matchTreeRules(tree, 300, "nsenter")
+		Expect(match).NotTo(BeNil())
+		Expect(p.extractMatchedRule(match.message)).To(Equal("nsenter"))
+
+		match = p.matchTreeRules(tree, 400, "payload")
+		Expect(match).NotTo(BeNil())
+		Expect(match.containerPID).To(Equal(200))
+		Expect(p.extractMatchedRule(match.message)).To(Equal("cgroupMismatch"))
+
+		// The shim is outside any container like its ancestors
+		Expect(p.matchTreeRules(tree, 100, "containerd-shim-runc-v2")).To(BeNil())
+	})
+	It("should extract container IDs of containerd and CRI-O cgroups", func() {
+		Expect(containerIDFromCgroup(containerCgroup)).To(Equal(containerID))
+		Expect(containerIDFromCgroup("0::/kubepods.slice/kubepods-pod1.slice/crio-" + containerID + ".scope\n")).
+			To(Equal(containerID))
+		Expect(containerIDFromCgroup("0::/kubepods.slice/kubepods-pod1.slice/crio-conmon-" + containerID + ".scope\n")).
+			To(BeEmpty())
+		Expect(containerIDFromCgroup("11:memory:/kubepods/burstable/pod0b7e0c36/" + containerID + "\n")).
+			To(Equal(containerID))
+		Expect(containerIDFromCgroup(hostCgroup)).To(BeEmpty())
+	})
+})
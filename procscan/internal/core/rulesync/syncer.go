@@ -0,0 +1,127 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rulesync pulls the detection rules of this node from the aggregator
+// so rules can be changed centrally and rolled out to part of the nodes first.
+package rulesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultInterval is used when no sync interval is configured
+const DefaultInterval = time.Minute
+
+// Applier applies the rules received from the aggregator
+type Applier interface {
+	ApplyRules(rules *models.DistributedRules) error
+}
+
+// Syncer polls the aggregator for the rules of a node. The version of the last
+// applied rules is sent as If-None-Match, so unchanged rules are not downloaded again.
+type Syncer struct {
+	url      string
+	interval time.Duration
+	applier  Applier
+	client   *http.Client
+	etag     string
+}
+
+// NewSyncer creates a syncer fetching the rules of node from the configured aggregator
+func NewSyncer(config models.RuleSyncConfig, node string, applier Applier) *Syncer {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Syncer{
+		url:      strings.TrimRight(config.URL, "/") + "/api/rules?" + url.Values{"node": {node}}.Encode(),
+		interval: interval,
+		applier:  applier,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run syncs the rules immediately and then on every interval until ctx is done.
+// Failures are logged and the rules in effect are kept.
+func (s *Syncer) Run(ctx context.Context) {
+	legacy.L.WithFields(logrus.Fields{
+		"url":      s.url,
+		"interval": s.interval.String(),
+	}).Info("Rule sync started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			legacy.L.WithError(err).Warn("Failed to sync rules from the aggregator, keeping the current rules")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fetches the rules once and applies them when their version changed
+func (s *Syncer) Sync(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusOK:
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var rules models.DistributedRules
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&rules); err != nil {
+		return fmt.Errorf("failed to decode rules: %w", err)
+	}
+	if rules.Version == "" {
+		return errors.New("rules without a version received")
+	}
+	if err := s.applier.ApplyRules(&rules); err != nil {
+		return fmt.Errorf("failed to apply rules %s: %w", rules.Version, err)
+	}
+	s.etag = resp.Header.Get("ETag")
+	return nil
+}
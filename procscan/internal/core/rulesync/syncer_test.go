@@ -0,0 +1,117 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRuleSync(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rule Sync Suite")
+}
+
+type recordingApplier struct {
+	applied []*models.DistributedRules
+	err     error
+}
+
+func (a *recordingApplier) ApplyRules(rules *models.DistributedRules) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.applied = append(a.applied, rules)
+	return nil
+}
+
+var _ = Describe("Syncer", func() {
+	ctx := context.Background()
+	var (
+		rules    *models.DistributedRules
+		requests []*http.Request
+		applier  *recordingApplier
+		syncer   *Syncer
+	)
+
+	BeforeEach(func() {
+		rules = &models.DistributedRules{
+			Version: "v1",
+			Rules:   models.DetectionRules{Blacklist: models.RuleSet{Processes: []string{"^xmrig$"}}},
+		}
+		requests = nil
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			etag := strconv.Quote(rules.Version)
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(rules)
+		}))
+		DeferCleanup(server.Close)
+		applier = &recordingApplier{}
+		syncer = NewSyncer(models.RuleSyncConfig{URL: server.URL + "/"}, "node-1", applier)
+	})
+
+	It("should apply the rules of the node and skip unchanged versions", func() {
+		Expect(syncer.interval).To(Equal(DefaultInterval))
+		Expect(syncer.Sync(ctx)).To(Succeed())
+		Expect(requests[0].URL.Path).To(Equal("/api/rules"))
+		Expect(requests[0].URL.Query().Get("node")).To(Equal("node-1"))
+		Expect(applier.applied).To(HaveLen(1))
+		Expect(applier.applied[0].Rules.Blacklist.Processes).To(Equal([]string{"^xmrig$"}))
+
+		Expect(syncer.Sync(ctx)).To(Succeed())
+		Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		Expect(applier.applied).To(HaveLen(1))
+
+		rules = &models.DistributedRules{Version: "v2", Canary: true}
+		Expect(syncer.Sync(ctx)).To(Succeed())
+		Expect(applier.applied).To(HaveLen(2))
+		Expect(applier.applied[1].Canary).To(BeTrue())
+	})
+
+	It("should fetch rejected rules again", func() {
+		applier.err = errors.New("invalid regex")
+		Expect(syncer.Sync(ctx)).To(MatchError(ContainSubstring("failed to apply rules v1")))
+
+		applier.err = nil
+		Expect(syncer.Sync(ctx)).To(Succeed())
+		Expect(requests[1].Header.Get("If-None-Match")).To(BeEmpty())
+		Expect(applier.applied).To(HaveLen(1))
+	})
+
+	It("should report errors of the aggregator", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "rule distribution is not enabled", http.StatusServiceUnavailable)
+		}))
+		DeferCleanup(server.Close)
+		syncer = NewSyncer(models.RuleSyncConfig{URL: server.URL}, "node-1", applier)
+
+		Expect(syncer.Sync(ctx)).To(MatchError(ContainSubstring("unexpected status 503")))
+		Expect(applier.applied).To(BeEmpty())
+	})
+})
@@ -0,0 +1,135 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/alert"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/egress"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// egressRule is the matched rule of egress violations
+const egressRule = "external_destinations"
+
+// newEgressDetector creates the egress detector, nil when it is disabled or misconfigured
+func newEgressDetector(config *models.Config) *egress.Detector {
+	if !config.Egress.Enabled {
+		legacy.L.Info("Egress detection disabled")
+		return nil
+	}
+	detector, err := egress.NewDetector(config.Scanner.ProcPath, config.Egress)
+	if err != nil {
+		legacy.L.WithError(err).Error("Failed to create egress detector, egress detection will be unavailable")
+		return nil
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"min_destinations":        config.Egress.MinDestinations,
+		"min_tx_bytes_per_second": config.Egress.MinTxBytesPerSecond,
+		"sustained_samples":       config.Egress.SustainedSamples,
+	}).Info("Egress detector configured")
+	return detector
+}
+
+// egressResults converts the pods with sustained egress to many external
+// addresses into scan results in the namespaces of the pods. Pods allowed by
+// an exception are only recorded.
+func (s *Scanner) egressResults(
+	detector *egress.Detector,
+	pids []int,
+	config *models.Config,
+) []*alert.NamespaceScanResult {
+	if detector == nil {
+		return nil
+	}
+	findings := detector.Scan(pids)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	severity := config.Egress.Severity
+	if severity == "" {
+		severity = egress.DefaultSeverity
+	}
+	procPath := config.Scanner.ProcPath
+	if procPath == "" {
+		procPath = "/proc"
+	}
+	now := time.Now().Format(time.RFC3339)
+	byNamespace := make(map[string][]*models.ProcessInfo)
+	for _, finding := range findings {
+		info := s.processor.TenantProcess(finding.PID, finding.Process)
+		if info == nil {
+			continue
+		}
+		info.Command = cmdline(procPath, finding.PID)
+		info.Timestamp = now
+		info.Message = fmt.Sprintf(
+			"Process '%s' matched egress rule '%s': %d connections to %d external addresses for %d scans, %.0f bytes/s sent, top %s",
+			finding.Process, egressRule, finding.Connections, finding.Destinations, finding.Samples,
+			finding.TxRate, strings.Join(finding.Top, " "),
+		)
+		info.MatchedRule = egressRule
+		info.Category = models.CategoryEgress
+		info.Severity = severity
+		s.updateViolationRecord(info)
+		if info.Exception != nil {
+			continue
+		}
+		byNamespace[info.Namespace] = append(byNamespace[info.Namespace], info)
+	}
+	if len(byNamespace) == 0 {
+		return nil
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	// Crawlers and proxies also reach many addresses, so egress is reported
+	// without the label action
+	results := make([]*alert.NamespaceScanResult, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		results = append(results, &alert.NamespaceScanResult{
+			Namespace:    namespace,
+			ProcessInfos: byNamespace[namespace],
+		})
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"count":      len(findings),
+		"namespaces": namespaces,
+		"severity":   severity,
+	}).Warn("Suspicious egress found")
+	return results
+}
+
+// cmdline returns the command line of pid with spaces between the arguments
+func cmdline(procPath string, pid int) string {
+	data, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
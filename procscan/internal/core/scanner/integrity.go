@@ -0,0 +1,118 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/alert"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/integrity"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultIntegrityNamespace is used when neither the config nor POD_NAMESPACE names one
+const defaultIntegrityNamespace = "block-system"
+
+// newIntegrityMonitor creates the file integrity monitor, nil when it is disabled or misconfigured
+func newIntegrityMonitor(config models.IntegrityConfig) *integrity.Monitor {
+	if !config.Enabled {
+		legacy.L.Info("File integrity monitoring disabled")
+		return nil
+	}
+	monitor, err := integrity.NewMonitor(config)
+	if err != nil {
+		legacy.L.WithError(err).Error("Failed to create file integrity monitor, file integrity monitoring will be unavailable")
+		return nil
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"paths":     config.Paths,
+		"host_root": config.HostRoot,
+	}).Info("File integrity monitor configured")
+	return monitor
+}
+
+// startIntegrityMonitor starts the file integrity monitor if one is configured
+func (s *Scanner) startIntegrityMonitor() {
+	if s.integrity == nil {
+		return
+	}
+	if err := s.integrity.Start(); err != nil {
+		legacy.L.WithError(err).Error("Failed to start file integrity monitor")
+		s.integrity = nil
+	}
+}
+
+// stopIntegrityMonitor stops the file integrity monitor if one is running
+func (s *Scanner) stopIntegrityMonitor() {
+	if s.integrity == nil {
+		return
+	}
+	if err := s.integrity.Stop(); err != nil {
+		legacy.L.WithError(err).Warn("Failed to stop file integrity monitor")
+	}
+}
+
+// integrityResult converts the current host file changes into a scan result,
+// so that they flow through the same violation records, deltas and alerts as processes
+func (s *Scanner) integrityResult(monitor *integrity.Monitor, config *models.Config) *alert.NamespaceScanResult {
+	if monitor == nil {
+		return nil
+	}
+	changes := monitor.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	namespace := integrityNamespace(config.Integrity)
+	infos := make([]*models.ProcessInfo, 0, len(changes))
+	for _, change := range changes {
+		infos = append(infos, &models.ProcessInfo{
+			ProcessName: change.Path,
+			Command:     fmt.Sprintf("baseline=%s current=%s", change.BaselineHash, change.CurrentHash),
+			PodName:     s.nodeName,
+			Namespace:   namespace,
+			Timestamp:   change.DetectedAt.Format(time.RFC3339),
+			Message:     fmt.Sprintf("Host file '%s' %s under watched path '%s'", change.Path, change.Type, change.Root),
+			AppType:     "host",
+			AppName:     s.nodeName,
+			MatchedRule: change.Root,
+		})
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"namespace": namespace,
+		"count":     len(infos),
+	}).Info("Host file integrity changes found")
+
+	// Host file changes are not caused by the namespace, so no label action is taken
+	return &alert.NamespaceScanResult{
+		Namespace:    namespace,
+		ProcessInfos: infos,
+	}
+}
+
+// integrityNamespace returns the namespace host file changes are reported in
+func integrityNamespace(config models.IntegrityConfig) string {
+	if config.Namespace != "" {
+		return config.Namespace
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return defaultIntegrityNamespace
+}
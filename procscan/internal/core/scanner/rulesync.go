@@ -0,0 +1,69 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/rulesync"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/config"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// startRuleSync pulls the detection rules from the aggregator when rule sync is enabled
+func (s *Scanner) startRuleSync(ctx context.Context) {
+	if !s.config.RuleSync.Enabled {
+		return
+	}
+	go rulesync.NewSyncer(s.config.RuleSync, s.nodeName, s).Run(ctx)
+}
+
+// ApplyRules validates the rules received from the aggregator and replaces the
+// detection rules in effect. The rules are kept when the configuration file is
+// reloaded, invalid rules are rejected and the current rules stay in effect.
+func (s *Scanner) ApplyRules(rules *models.DistributedRules) error {
+	result := config.NewConfigValidator().ValidateRules(rules.Rules)
+	if !result.Valid {
+		return errors.New(strings.Join(result.Errors, "; "))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := ""
+	if s.remoteRules != nil {
+		previous = s.remoteRules.Version
+	}
+	s.remoteRules = rules
+
+	newConfig := *s.config
+	newConfig.DetectionRules = rules.Rules
+	s.config = &newConfig
+	s.processor.UpdateConfig(&newConfig)
+
+	fields := logrus.Fields{
+		"from":   previous,
+		"to":     rules.Version,
+		"canary": rules.Canary,
+	}
+	if len(result.Warnings) > 0 {
+		fields["warnings"] = result.Warnings
+	}
+	legacy.L.WithFields(fields).Info("Detection rules from the aggregator applied")
+	return nil
+}
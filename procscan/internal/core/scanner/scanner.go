@@ -20,15 +20,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"runtime"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/bearslyricattack/CompliK/procscan/internal/api"
 	"github.com/bearslyricattack/CompliK/procscan/internal/core/alert"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/egress"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/integrity"
 	k8sClient "github.com/bearslyricattack/CompliK/procscan/internal/core/k8s"
 	"github.com/bearslyricattack/CompliK/procscan/internal/core/processor"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/tampering"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/tracker"
 	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
 	"github.com/bearslyricattack/CompliK/procscan/pkg/metrics"
 	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
@@ -48,6 +52,18 @@ type Scanner struct {
 	ticker           *time.Ticker
 	violationRecords map[string]*models.ViolationRecord // 本地存储不合规记录，key 为 "namespace/pod/process"
 	violationMu      sync.RWMutex                       // 保护 violationRecords
+	tracker          *tracker.Tracker                   // 记录历史扫描结果，用于增量上报
+	integrity        *integrity.Monitor                 // 主机路径文件完整性监控，未启用时为 nil
+	tampering        *tampering.Detector                // 内核模块和预加载库检测，未启用时为 nil
+	egress           *egress.Detector                   // Pod 外连检测，保存上一轮的采样，未启用时为 nil
+	remoteRules      *models.DistributedRules           // 从聚合器同步的检测规则，覆盖配置文件中的规则，受 mu 保护
+
+	nodeName  string
+	startedAt time.Time
+	statusMu  sync.RWMutex // 保护以下扫描统计
+	lastScan  *models.ScanSummary
+	scanCount int64
+	scanErrs  int64
 }
 
 // k8sClientInterface defines the interface for Kubernetes client operations
@@ -118,12 +134,25 @@ func NewScanner(config *models.Config) *Scanner {
 		metrics:          metricsCollector,
 		metricsSrv:       metricsServer,
 		violationRecords: make(map[string]*models.ViolationRecord),
+		integrity:        newIntegrityMonitor(config.Integrity),
+		tampering:        newTamperingDetector(config),
+		egress:           newEgressDetector(config),
+		nodeName:         os.Getenv("NODE_NAME"),
+		startedAt:        time.Now(),
 	}
+	if scanner.nodeName == "" {
+		scanner.nodeName = "unknown"
+	}
+	metricsCollector.RecordAgentInfo(scanner.nodeName)
+	scanner.tracker = tracker.NewTracker(scanner.nodeName, config.Scanner.FullSyncInterval, 0)
 
 	// Initialize API server
 	if config.API.Enabled {
-		scanner.apiServer = api.NewServer(scanner, config.API.Port)
-		legacy.L.WithField("port", config.API.Port).Info("API server configured")
+		scanner.apiServer = api.NewServer(scanner, config.API)
+		legacy.L.WithFields(logrus.Fields{
+			"port":   config.API.Port,
+			"socket": config.API.SocketPath,
+		}).Info("API server configured")
 	} else {
 		legacy.L.Info("API server disabled")
 	}
@@ -137,6 +166,10 @@ func (s *Scanner) UpdateConfig(newConfig *models.Config) {
 	defer s.mu.Unlock()
 
 	legacy.L.Info("Applying new configuration...")
+	if s.remoteRules != nil {
+		newConfig.DetectionRules = s.remoteRules.Rules
+		legacy.L.WithField("version", s.remoteRules.Version).Info("Keeping detection rules from the aggregator")
+	}
 	oldConfig := s.config
 	s.config = newConfig
 
@@ -164,6 +197,33 @@ func (s *Scanner) UpdateConfig(newConfig *models.Config) {
 		}).Info("Configuration changed")
 	}
 
+	if oldConfig.Scanner.FullSyncInterval != newConfig.Scanner.FullSyncInterval {
+		s.tracker.SetFullSyncInterval(newConfig.Scanner.FullSyncInterval)
+		legacy.L.WithFields(logrus.Fields{
+			"key":  "scanner.full_sync_interval",
+			"from": oldConfig.Scanner.FullSyncInterval.String(),
+			"to":   newConfig.Scanner.FullSyncInterval.String(),
+		}).Info("Configuration changed")
+	}
+
+	if !reflect.DeepEqual(oldConfig.Integrity, newConfig.Integrity) {
+		// A new baseline is taken, so changes accepted by editing the config stop being reported
+		s.stopIntegrityMonitor()
+		s.integrity = newIntegrityMonitor(newConfig.Integrity)
+		s.startIntegrityMonitor()
+		legacy.L.WithField("key", "integrity").Info("Configuration changed")
+	}
+
+	if !reflect.DeepEqual(oldConfig.Tampering, newConfig.Tampering) {
+		s.tampering = newTamperingDetector(newConfig)
+		legacy.L.WithField("key", "tampering").Info("Configuration changed")
+	}
+
+	if !reflect.DeepEqual(oldConfig.Egress, newConfig.Egress) {
+		s.egress = newEgressDetector(newConfig)
+		legacy.L.WithField("key", "egress").Info("Configuration changed")
+	}
+
 	s.processor.UpdateConfig(newConfig)
 	legacy.L.Info("Detection rules refreshed")
 
@@ -172,6 +232,9 @@ func (s *Scanner) UpdateConfig(newConfig *models.Config) {
 
 // Start initializes and starts the scanner
 func (s *Scanner) Start(ctx context.Context) error {
+	if s.processor != nil {
+		s.processor.Close()
+	}
 	s.processor = processor.NewProcessor(s.config)
 
 	// Check service initialization status
@@ -199,21 +262,23 @@ func (s *Scanner) Start(ctx context.Context) error {
 		}()
 	}
 
+	s.mu.Lock()
+	s.startIntegrityMonitor()
+	s.mu.Unlock()
+
+	// Start pulling detection rules from the aggregator
+	s.startRuleSync(ctx)
+
 	// Start metrics collector
 	if s.metrics != nil {
 		go s.metrics.StartMetricsUpdater(ctx, 30*time.Second)
-		s.metrics.RecordScanStart()
 	}
 
 	initialInterval := s.config.Scanner.ScanInterval
 	s.ticker = time.NewTicker(initialInterval)
 
-	nodeName := os.Getenv("NODE_NAME")
-	if nodeName == "" {
-		nodeName = "unknown"
-	}
 	legacy.L.WithFields(logrus.Fields{
-		"node":     nodeName,
+		"node":     s.nodeName,
 		"interval": initialInterval.String(),
 	}).Info("ProcScan process scanner started")
 
@@ -238,10 +303,18 @@ func (s *Scanner) runScanLoop(ctx context.Context) error {
 			if s.apiServer != nil {
 				s.apiServer.Stop(ctx)
 			}
+			s.mu.Lock()
+			s.stopIntegrityMonitor()
+			s.processor.Close()
+			s.mu.Unlock()
 			return ctx.Err()
 		case <-s.ticker.C:
 			scanStart := time.Now()
+			if s.metrics != nil {
+				s.metrics.RecordScanStart()
+			}
 			if err := s.scanProcesses(); err != nil {
+				s.recordScanFailure(scanStart, err)
 				legacy.L.WithError(err).Error("Failed to scan processes")
 				if s.metrics != nil {
 					s.metrics.RecordScanError()
@@ -257,9 +330,13 @@ func (s *Scanner) runScanLoop(ctx context.Context) error {
 
 func (s *Scanner) scanProcesses() error {
 	legacy.L.Info("Starting new scan round...")
+	scanStarted := time.Now()
 
 	s.mu.RLock()
 	currentConfig := s.config
+	integrityMonitor := s.integrity
+	tamperingDetector := s.tampering
+	egressDetector := s.egress
 	s.mu.RUnlock()
 
 	pids, err := s.processor.GetAllProcesses()
@@ -273,6 +350,12 @@ func (s *Scanner) scanProcesses() error {
 		s.metrics.RecordProcessesAnalyzed(len(pids))
 	}
 
+	// The process tree is only read when a rule inspects ancestry
+	var tree *processor.ProcessTree
+	if s.processor.NeedsProcessTree() {
+		tree = processor.BuildProcessTree(currentConfig.Scanner.ProcPath, pids)
+	}
+
 	numWorkers := runtime.NumCPU()
 	pidChan := make(chan int, len(pids))
 	resultsChan := make(chan *models.ProcessInfo, len(pids))
@@ -283,7 +366,7 @@ func (s *Scanner) scanProcesses() error {
 		go func(workerID int) {
 			defer wg.Done()
 			for pid := range pidChan {
-				processInfo, _ := s.processor.AnalyzeProcess(pid)
+				processInfo, _ := s.processor.AnalyzeProcessInTree(pid, tree)
 				if processInfo != nil {
 					resultsChan <- processInfo
 				}
@@ -300,8 +383,14 @@ func (s *Scanner) scanProcesses() error {
 	close(resultsChan)
 	legacy.L.Info("All process analysis completed")
 
+	s.violationMu.Lock()
+	s.violationRecords = make(map[string]*models.ViolationRecord)
+	s.violationMu.Unlock()
+	legacy.L.Debug("已重置违规记录 map")
+
 	resultsByNamespace := make(map[string][]*models.ProcessInfo)
 	processCount := 0
+	exceptedCount := 0
 	for processInfo := range resultsChan {
 		processCount++
 		legacy.L.WithFields(logrus.Fields{
@@ -309,20 +398,22 @@ func (s *Scanner) scanProcesses() error {
 			"process_name": processInfo.ProcessName,
 			"pid":          processInfo.PID,
 		}).Debug("接收进程信息")
+		s.updateViolationRecord(processInfo)
+		// 命中例外的进程只记录，不执行处置也不告警
+		if processInfo.Exception != nil {
+			exceptedCount++
+			continue
+		}
 		resultsByNamespace[processInfo.Namespace] = append(
 			resultsByNamespace[processInfo.Namespace],
 			processInfo,
 		)
-		s.violationMu.Lock()
-		s.violationRecords = make(map[string]*models.ViolationRecord)
-		s.violationMu.Unlock()
-		legacy.L.Debug("已重置违规记录 map")
-		s.updateViolationRecord(processInfo)
 	}
 
 	// 最终统计
 	legacy.L.WithFields(logrus.Fields{
 		"total_processes": processCount,
+		"excepted":        exceptedCount,
 		"namespace_count": len(resultsByNamespace),
 	}).Info("进程信息处理完成")
 
@@ -343,11 +434,31 @@ func (s *Scanner) scanProcesses() error {
 			LabelResult:  labelResult,
 		})
 	}
+	if result := s.integrityResult(integrityMonitor, currentConfig); result != nil {
+		for _, processInfo := range result.ProcessInfos {
+			s.updateViolationRecord(processInfo)
+		}
+		finalResults = append(finalResults, result)
+	}
+	for _, result := range s.tamperingResults(tamperingDetector, pids, currentConfig) {
+		for _, processInfo := range result.ProcessInfos {
+			s.updateViolationRecord(processInfo)
+		}
+		finalResults = append(finalResults, result)
+	}
+	// Egress violations are recorded by egressResults, including excepted ones
+	finalResults = append(finalResults, s.egressResults(egressDetector, pids, currentConfig)...)
 
-	if err := alert.SendGlobalBatchAlert(finalResults, currentConfig.Notifications.Lark.Webhook, currentConfig.Notifications.Region); err != nil {
-		legacy.L.WithError(err).Error("Failed to send global batch Lark alert")
+	s.violationMu.RLock()
+	delta := s.tracker.Update(s.violationRecords, time.Now())
+	if s.metrics != nil {
+		s.metrics.RecordRuleHits(delta.Added)
+		s.metrics.RecordActiveViolations(s.violationRecords)
 	}
+	s.violationMu.RUnlock()
+	s.reportDelta(delta, finalResults, currentConfig)
 
+	s.recordScanSuccess(scanStarted, len(pids), processCount-exceptedCount, resultsByNamespace)
 	legacy.L.Info("Scan round completed")
 	return nil
 }
@@ -382,6 +493,9 @@ func (s *Scanner) handleGroupedActions(namespace string, config *models.Config)
 			}
 		} else {
 			labelResult = "Cannot execute (K8s client unavailable)"
+			if s.metrics != nil {
+				s.metrics.RecordAction("label", metrics.ActionSkipped)
+			}
 		}
 	} else {
 		labelResult = "Feature disabled"
@@ -393,8 +507,7 @@ func (s *Scanner) updateViolationRecord(processInfo *models.ProcessInfo) {
 	s.violationMu.Lock()
 	defer s.violationMu.Unlock()
 
-	// 生成唯一 key：namespace/pod/process
-	key := fmt.Sprintf("%s/%s/%s", processInfo.Namespace, processInfo.PodName, processInfo.ProcessName)
+	key := violationKey(processInfo.Namespace, processInfo.PodName, processInfo.ProcessName)
 
 	_, exists := s.violationRecords[key]
 
@@ -412,10 +525,21 @@ func (s *Scanner) updateViolationRecord(processInfo *models.ProcessInfo) {
 		Process:   processInfo.ProcessName,
 		Cmdline:   processInfo.Command,
 		Regex:     processInfo.MatchedRule,
-		Status:    "active",
+		Status:    models.StatusActive,
 		Type:      processInfo.AppType,
 		Name:      processInfo.AppName,
 		Timestamp: processInfo.Timestamp,
+		Category:  processInfo.Category,
+		Severity:  processInfo.Severity,
+
+		ContainerID: processInfo.ContainerID,
+		SandboxID:   processInfo.SandboxID,
+		Image:       processInfo.Image,
+		ImageDigest: processInfo.ImageDigest,
+	}
+	if processInfo.Exception != nil {
+		record.Status = models.StatusExcepted
+		record.Exception = processInfo.Exception
 	}
 
 	s.violationRecords[key] = record
@@ -429,11 +553,84 @@ func (s *Scanner) updateViolationRecord(processInfo *models.ProcessInfo) {
 func (s *Scanner) GetViolationRecords() []*models.ViolationRecord {
 	s.violationMu.RLock()
 	defer s.violationMu.RUnlock()
-	fmt.Printf("进入")
-	fmt.Printf(strconv.Itoa(len(s.violationRecords)))
 	records := make([]*models.ViolationRecord, 0, len(s.violationRecords))
 	for _, record := range s.violationRecords {
 		records = append(records, record)
 	}
 	return records
 }
+
+// violationKey 生成违规记录的唯一 key：namespace/pod/process
+func violationKey(namespace, pod, process string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, pod, process)
+}
+
+// reportDelta 仅通知新增、变化和已清除的违规记录，全量对账时发送完整状态
+func (s *Scanner) reportDelta(
+	delta *models.ViolationDelta,
+	results []*alert.NamespaceScanResult,
+	config *models.Config,
+) {
+	logFields := logrus.Fields{
+		"seq":     delta.Seq,
+		"full":    delta.Full,
+		"added":   len(delta.Added),
+		"changed": len(delta.Changed),
+		"cleared": len(delta.Cleared),
+	}
+	webhook := config.Notifications.Lark.Webhook
+	region := config.Notifications.Region
+
+	if delta.Full {
+		legacy.L.WithFields(logFields).Info("Reporting full violation state")
+		err := alert.SendReconciliationAlert(results, webhook, region)
+		if err != nil {
+			legacy.L.WithError(err).Error("Failed to send full state Lark alert")
+		}
+		s.recordNotification(webhook, len(results) > 0, err)
+		return
+	}
+	if delta.Empty() {
+		legacy.L.WithFields(logFields).Info("No violation changes since last scan, skipping alert")
+		return
+	}
+
+	updated := make(map[string]struct{}, len(delta.Added)+len(delta.Changed))
+	for _, records := range [][]*models.ViolationRecord{delta.Added, delta.Changed} {
+		for _, record := range records {
+			updated[violationKey(record.Namespace, record.Pod, record.Process)] = struct{}{}
+		}
+	}
+	changedResults := make([]*alert.NamespaceScanResult, 0, len(results))
+	for _, result := range results {
+		var infos []*models.ProcessInfo
+		for _, info := range result.ProcessInfos {
+			if _, ok := updated[violationKey(info.Namespace, info.PodName, info.ProcessName)]; ok {
+				infos = append(infos, info)
+			}
+		}
+		if len(infos) > 0 {
+			changedResults = append(changedResults, &alert.NamespaceScanResult{
+				Namespace:    result.Namespace,
+				ProcessInfos: infos,
+				LabelResult:  result.LabelResult,
+			})
+		}
+	}
+
+	legacy.L.WithFields(logFields).Info("Reporting violation changes")
+	err := alert.SendDeltaAlert(changedResults, delta.Cleared, webhook, region)
+	if err != nil {
+		legacy.L.WithError(err).Error("Failed to send violation change Lark alert")
+	}
+	s.recordNotification(webhook, len(changedResults) > 0 || len(delta.Cleared) > 0, err)
+}
+
+// recordNotification counts an alert that was sent or failed to send. Alerts
+// skipped because no webhook is configured or nothing is reported are not counted.
+func (s *Scanner) recordNotification(webhook string, sent bool, err error) {
+	if s.metrics == nil || webhook == "" || (!sent && err == nil) {
+		return
+	}
+	s.metrics.RecordNotification(err == nil)
+}
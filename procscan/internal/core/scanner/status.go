@@ -0,0 +1,121 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+)
+
+// GetStatus returns the current runtime status of this agent
+func (s *Scanner) GetStatus() *models.AgentStatus {
+	s.mu.RLock()
+	scanInterval := s.config.Scanner.ScanInterval
+	labelEnabled := s.config.Actions.Label.Enabled
+	remoteRules := s.remoteRules
+	s.mu.RUnlock()
+
+	s.violationMu.RLock()
+	violationCount := len(s.violationRecords)
+	s.violationMu.RUnlock()
+
+	status := &models.AgentStatus{
+		Node:           s.nodeName,
+		StartedAt:      s.startedAt,
+		UptimeSeconds:  time.Since(s.startedAt).Seconds(),
+		ScanInterval:   scanInterval.String(),
+		ViolationCount: violationCount,
+		K8sClientReady: s.k8sClient != nil,
+		LabelEnabled:   labelEnabled,
+	}
+	if remoteRules != nil {
+		status.RulesVersion = remoteRules.Version
+		status.RulesCanary = remoteRules.Canary
+	}
+
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	status.ScanCount = s.scanCount
+	status.ScanErrors = s.scanErrs
+	if s.lastScan != nil {
+		status.LastScanAt = s.lastScan.FinishedAt
+	}
+	return status
+}
+
+// GetLastScan returns a copy of the most recent scan summary, or nil before the first scan
+func (s *Scanner) GetLastScan() *models.ScanSummary {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	if s.lastScan == nil {
+		return nil
+	}
+	summary := *s.lastScan
+	summary.AffectedNamespaces = append([]string(nil), s.lastScan.AffectedNamespaces...)
+	return &summary
+}
+
+// GetViolationDelta returns the violation changes since the scan with sequence since
+func (s *Scanner) GetViolationDelta(since uint64) *models.ViolationDelta {
+	return s.tracker.Since(since, time.Now())
+}
+
+// GetRules returns the detection rules currently in effect
+func (s *Scanner) GetRules() models.DetectionRules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.DetectionRules
+}
+
+func (s *Scanner) recordScanSuccess(
+	startedAt time.Time,
+	processesScanned, violations int,
+	resultsByNamespace map[string][]*models.ProcessInfo,
+) {
+	namespaces := make([]string, 0, len(resultsByNamespace))
+	for namespace := range resultsByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	finishedAt := time.Now()
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.scanCount++
+	s.lastScan = &models.ScanSummary{
+		StartedAt:          startedAt,
+		FinishedAt:         finishedAt,
+		DurationSeconds:    finishedAt.Sub(startedAt).Seconds(),
+		ProcessesScanned:   processesScanned,
+		Violations:         violations,
+		AffectedNamespaces: namespaces,
+	}
+}
+
+func (s *Scanner) recordScanFailure(startedAt time.Time, err error) {
+	finishedAt := time.Now()
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.scanCount++
+	s.scanErrs++
+	s.lastScan = &models.ScanSummary{
+		StartedAt:       startedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+		Error:           err.Error(),
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/alert"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/tampering"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// newTamperingDetector creates the tampering detector, nil when it is disabled or misconfigured
+func newTamperingDetector(config *models.Config) *tampering.Detector {
+	if !config.Tampering.Enabled {
+		legacy.L.Info("Tampering detection disabled")
+		return nil
+	}
+	detector, err := tampering.NewDetector(config.Scanner.ProcPath, config.Tampering)
+	if err != nil {
+		legacy.L.WithError(err).Error("Failed to create tampering detector, tampering detection will be unavailable")
+		return nil
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"modules":   config.Tampering.Modules,
+		"preload":   config.Tampering.Preload,
+		"host_root": config.Tampering.HostRoot,
+	}).Info("Tampering detector configured")
+	return detector
+}
+
+// tamperingResults converts the kernel modules and preloaded libraries that are
+// not allowed into scan results. Findings of containers are reported in the
+// namespace of their pod, those of the host in the tampering namespace.
+func (s *Scanner) tamperingResults(
+	detector *tampering.Detector,
+	pids []int,
+	config *models.Config,
+) []*alert.NamespaceScanResult {
+	if detector == nil {
+		return nil
+	}
+	findings, err := detector.Scan(pids)
+	if err != nil {
+		legacy.L.WithError(err).Warn("Tampering detection incomplete")
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	severity := config.Tampering.Severity
+	if severity == "" {
+		severity = tampering.DefaultSeverity
+	}
+	hostNamespace := tamperingNamespace(config.Tampering)
+	now := time.Now().Format(time.RFC3339)
+	byNamespace := make(map[string][]*models.ProcessInfo)
+	for _, finding := range findings {
+		info := &models.ProcessInfo{
+			PID:         finding.PID,
+			ProcessName: finding.Name,
+			Command:     finding.Detail,
+			PodName:     s.nodeName,
+			Namespace:   hostNamespace,
+			Timestamp:   now,
+			Message:     fmt.Sprintf("%s '%s' matched tampering rule '%s'", finding.Detail, finding.Name, finding.Kind),
+			AppType:     "host",
+			AppName:     s.nodeName,
+			MatchedRule: string(finding.Kind),
+			Category:    models.CategoryTampering,
+			Severity:    severity,
+		}
+		if finding.PID != 0 {
+			if pod, err := s.processor.ContainerInfo(finding.PID); err == nil {
+				info.PodName = pod.PodName
+				info.Namespace = pod.PodNamespace
+				info.PodLabels = pod.Labels
+				info.AppType = "container"
+				info.AppName = pod.PodName
+			}
+		}
+		byNamespace[info.Namespace] = append(byNamespace[info.Namespace], info)
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	// Tampering is reported without the label action, the namespace of a pod
+	// may only be the victim of a compromised node
+	results := make([]*alert.NamespaceScanResult, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		results = append(results, &alert.NamespaceScanResult{
+			Namespace:    namespace,
+			ProcessInfos: byNamespace[namespace],
+		})
+	}
+	legacy.L.WithFields(logrus.Fields{
+		"count":      len(findings),
+		"namespaces": namespaces,
+		"severity":   severity,
+	}).Warn("Tampering found")
+	return results
+}
+
+// tamperingNamespace returns the namespace host tampering is reported in
+func tamperingNamespace(config models.TamperingConfig) string {
+	if config.Namespace != "" {
+		return config.Namespace
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return defaultIntegrityNamespace
+}
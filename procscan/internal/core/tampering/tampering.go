@@ -0,0 +1,299 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tampering finds kernel modules and preloaded libraries that are not
+// allowed, the usual hooks of rootkits that hide processes from the scanner.
+package tampering
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+)
+
+const (
+	// DefaultHostRoot is where the host filesystem is mounted in the scanner container
+	DefaultHostRoot = "/host"
+	// DefaultSeverity is reported when no severity is configured
+	DefaultSeverity = "critical"
+)
+
+// Kind is the hook a finding was found in
+type Kind string
+
+const (
+	KindModule     Kind = "kernel_module"
+	KindLDPreload  Kind = "ld_preload"
+	KindPreloadCfg Kind = "ld_so_preload"
+)
+
+// Finding is a kernel module or preloaded library that is not allowed
+type Finding struct {
+	Kind Kind
+	// Name is the module name or the library path
+	Name string
+	// Detail describes where the finding was read from
+	Detail string
+	// PID is the process the finding was read from, 0 for host files and modules
+	PID int
+}
+
+// Detector checks the loaded kernel modules and the preloaded libraries
+// against the allowlists of the configuration
+type Detector struct {
+	procPath       string
+	hostRoot       string
+	modules        bool
+	preload        bool
+	allowedModules []*regexp.Regexp
+	allowedPreload []*regexp.Regexp
+}
+
+// NewDetector creates a detector reading processes below procPath
+func NewDetector(procPath string, config models.TamperingConfig) (*Detector, error) {
+	d := &Detector{
+		procPath: procPath,
+		hostRoot: config.HostRoot,
+		modules:  config.Modules,
+		preload:  config.Preload,
+	}
+	if d.procPath == "" {
+		d.procPath = "/proc"
+	}
+	if d.hostRoot == "" {
+		d.hostRoot = DefaultHostRoot
+	}
+	var err error
+	if d.allowedModules, err = compile(config.AllowedModules); err != nil {
+		return nil, fmt.Errorf("invalid allowed module: %w", err)
+	}
+	if d.allowedPreload, err = compile(config.AllowedPreload); err != nil {
+		return nil, fmt.Errorf("invalid allowed preload: %w", err)
+	}
+	return d, nil
+}
+
+// Scan returns the findings of the host and of the containers of pids, sorted
+// by kind and name. Unreadable processes are skipped, they usually exited.
+func (d *Detector) Scan(pids []int) ([]Finding, error) {
+	var findings []Finding
+	var errs []error
+	if d.modules {
+		modules, err := d.Modules()
+		errs = append(errs, err)
+		findings = append(findings, modules...)
+	}
+	if d.preload {
+		host, err := d.HostPreload()
+		errs = append(errs, err)
+		findings = append(findings, host...)
+		findings = append(findings, d.ProcessPreload(pids)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return findings, errors.Join(errs...)
+}
+
+// Modules returns the loaded kernel modules that are not allowed
+func (d *Detector) Modules() ([]Finding, error) {
+	data, err := os.ReadFile(filepath.Join(d.procPath, "modules"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kernel modules: %w", err)
+	}
+	var findings []Finding
+	for _, module := range ParseModules(data) {
+		if matchAny(d.allowedModules, module.Name) {
+			continue
+		}
+		detail := "loaded kernel module"
+		if module.Taint != "" {
+			detail += " taint=" + module.Taint
+		}
+		findings = append(findings, Finding{Kind: KindModule, Name: module.Name, Detail: detail})
+	}
+	return findings, nil
+}
+
+// HostPreload returns the libraries of the host /etc/ld.so.preload that are not allowed
+func (d *Detector) HostPreload() ([]Finding, error) {
+	data, err := os.ReadFile(filepath.Join(d.hostRoot, "etc", "ld.so.preload"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read host ld.so.preload: %w", err)
+	}
+	return d.preloadFindings(KindPreloadCfg, ParsePreload(data), "/etc/ld.so.preload", 0), nil
+}
+
+// ProcessPreload returns the LD_PRELOAD libraries of pids and the ld.so.preload
+// entries of their containers that are not allowed. Every mount namespace
+// other than the one of the host is read once.
+func (d *Detector) ProcessPreload(pids []int) []Finding {
+	hostMnt, _ := os.Readlink(filepath.Join(d.procPath, "1", "ns", "mnt"))
+	seenMnt := make(map[string]bool)
+	seen := make(map[string]bool)
+	var findings []Finding
+	// A library preloaded by many processes of a mount namespace is reported once
+	add := func(mnt string, found []Finding) {
+		for _, finding := range found {
+			key := mnt + "\x00" + string(finding.Kind) + "\x00" + finding.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			findings = append(findings, finding)
+		}
+	}
+
+	for _, pid := range pids {
+		procDir := filepath.Join(d.procPath, strconv.Itoa(pid))
+		mnt, err := os.Readlink(filepath.Join(procDir, "ns", "mnt"))
+		if err != nil {
+			continue
+		}
+
+		if environ, err := os.ReadFile(filepath.Join(procDir, "environ")); err == nil {
+			if libraries := PreloadFromEnviron(environ); len(libraries) > 0 {
+				detail := fmt.Sprintf("LD_PRELOAD of %s (pid %d)", processName(procDir), pid)
+				add(mnt, d.preloadFindings(KindLDPreload, libraries, detail, pid))
+			}
+		}
+
+		// The host file is read by HostPreload
+		if mnt == hostMnt || seenMnt[mnt] {
+			continue
+		}
+		seenMnt[mnt] = true
+		data, err := os.ReadFile(filepath.Join(procDir, "root", "etc", "ld.so.preload"))
+		if err != nil {
+			continue
+		}
+		add(mnt, d.preloadFindings(KindPreloadCfg, ParsePreload(data), "/etc/ld.so.preload", pid))
+	}
+	return findings
+}
+
+func (d *Detector) preloadFindings(kind Kind, libraries []string, detail string, pid int) []Finding {
+	var findings []Finding
+	for _, library := range libraries {
+		if matchAny(d.allowedPreload, library) {
+			continue
+		}
+		findings = append(findings, Finding{Kind: kind, Name: library, Detail: detail, PID: pid})
+	}
+	return findings
+}
+
+// Module is a line of /proc/modules
+type Module struct {
+	Name  string
+	State string
+	// Taint holds the taint flags, such as O for out-of-tree or E for unsigned modules
+	Taint string
+}
+
+// ParseModules parses the content of /proc/modules
+func ParseModules(data []byte) []Module {
+	var modules []Module
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		module := Module{Name: fields[0]}
+		if len(fields) > 4 {
+			module.State = fields[4]
+		}
+		if last := fields[len(fields)-1]; len(fields) > 6 && strings.HasPrefix(last, "(") {
+			module.Taint = strings.Trim(last, "()")
+		}
+		modules = append(modules, module)
+	}
+	return modules
+}
+
+// ParsePreload parses an ld.so.preload file, whose entries are separated by
+// whitespace or colons and may be followed by comments
+func ParsePreload(data []byte) []string {
+	var libraries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		libraries = append(libraries, splitLibraries(line)...)
+	}
+	return libraries
+}
+
+// PreloadFromEnviron returns the LD_PRELOAD libraries of the NUL separated
+// environment of a process
+func PreloadFromEnviron(environ []byte) []string {
+	for _, entry := range bytes.Split(environ, []byte{0}) {
+		if value, ok := bytes.CutPrefix(entry, []byte("LD_PRELOAD=")); ok {
+			return splitLibraries(string(value))
+		}
+	}
+	return nil
+}
+
+func splitLibraries(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ':' || r == ' ' || r == '\t' || r == '\r'
+	})
+}
+
+func processName(procDir string) string {
+	comm, err := os.ReadFile(filepath.Join(procDir, "comm"))
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
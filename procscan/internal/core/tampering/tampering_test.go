@@ -0,0 +1,127 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tampering
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTampering(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tampering Suite")
+}
+
+const modules = `overlay 151552 12 - Live 0x0000000000000000
+nf_conntrack 172032 5 xt_conntrack,nf_nat, Live 0x0000000000000000
+diamorphine 16384 0 - Live 0x0000000000000000 (OE)
+`
+
+var _ = Describe("Parsing", func() {
+	It("should parse modules with their taint flags", func() {
+		parsed := ParseModules([]byte(modules))
+		Expect(parsed).To(HaveLen(3))
+		Expect(parsed[0]).To(Equal(Module{Name: "overlay", State: "Live"}))
+		Expect(parsed[2]).To(Equal(Module{Name: "diamorphine", State: "Live", Taint: "OE"}))
+	})
+
+	It("should split preload files and skip comments", func() {
+		data := "# added by vendor\n/usr/lib/libsnoopy.so /lib/libhide.so:/lib/libx.so # trailing\n\n"
+		Expect(ParsePreload([]byte(data))).To(Equal([]string{"/usr/lib/libsnoopy.so", "/lib/libhide.so", "/lib/libx.so"}))
+	})
+
+	It("should read LD_PRELOAD from the environment", func() {
+		environ := []byte("PATH=/bin\x00LD_PRELOAD=/tmp/.x/libprocesshider.so\x00HOME=/root\x00")
+		Expect(PreloadFromEnviron(environ)).To(Equal([]string{"/tmp/.x/libprocesshider.so"}))
+		Expect(PreloadFromEnviron([]byte("PATH=/bin\x00"))).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Detector", func() {
+	var procPath, hostRoot string
+
+	write := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+	process := func(pid, mnt, environ string) string {
+		dir := filepath.Join(procPath, pid)
+		Expect(os.MkdirAll(filepath.Join(dir, "ns"), 0o755)).To(Succeed())
+		Expect(os.Symlink("mnt:["+mnt+"]", filepath.Join(dir, "ns", "mnt"))).To(Succeed())
+		write(filepath.Join(dir, "environ"), environ)
+		write(filepath.Join(dir, "comm"), "proc-"+pid+"\n")
+		return dir
+	}
+
+	BeforeEach(func() {
+		procPath = GinkgoT().TempDir()
+		hostRoot = GinkgoT().TempDir()
+		write(filepath.Join(procPath, "modules"), modules)
+		write(filepath.Join(hostRoot, "etc", "ld.so.preload"), "/usr/lib/libsnoopy.so\n/lib/libhide.so\n")
+
+		process("1", "1", "PATH=/bin\x00")
+		process("100", "1", "LD_PRELOAD=/usr/lib/libsnoopy.so\x00")
+		container := process("200", "2", "LD_PRELOAD=/tmp/libprocesshider.so\x00")
+		write(filepath.Join(container, "root", "etc", "ld.so.preload"), "/lib/libcontainer.so\n")
+		process("201", "2", "LD_PRELOAD=/tmp/libprocesshider.so\x00")
+	})
+
+	It("should report what the allowlists do not match", func() {
+		detector, err := NewDetector(procPath, models.TamperingConfig{
+			HostRoot:       hostRoot,
+			Modules:        true,
+			AllowedModules: []string{"^overlay$", "^nf_"},
+			Preload:        true,
+			AllowedPreload: []string{`^/usr/lib/libsnoopy\.so$`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		findings, err := detector.Scan([]int{1, 100, 200, 201, 999})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(Equal([]Finding{
+			{Kind: KindModule, Name: "diamorphine", Detail: "loaded kernel module taint=OE"},
+			{Kind: KindLDPreload, Name: "/tmp/libprocesshider.so", Detail: "LD_PRELOAD of proc-200 (pid 200)", PID: 200},
+			{Kind: KindPreloadCfg, Name: "/lib/libcontainer.so", Detail: "/etc/ld.so.preload", PID: 200},
+			{Kind: KindPreloadCfg, Name: "/lib/libhide.so", Detail: "/etc/ld.so.preload"},
+		}))
+	})
+
+	It("should only run the enabled checks", func() {
+		detector, err := NewDetector(procPath, models.TamperingConfig{HostRoot: hostRoot, Modules: true})
+		Expect(err).NotTo(HaveOccurred())
+		findings, err := detector.Scan([]int{200})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(3))
+		for _, finding := range findings {
+			Expect(finding.Kind).To(Equal(KindModule))
+		}
+	})
+
+	It("should fail on unreadable modules and invalid allowlists", func() {
+		Expect(os.Remove(filepath.Join(procPath, "modules"))).To(Succeed())
+		detector, err := NewDetector(procPath, models.TamperingConfig{HostRoot: hostRoot, Modules: true})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = detector.Scan(nil)
+		Expect(err).To(MatchError(ContainSubstring("failed to read kernel modules")))
+
+		_, err = NewDetector(procPath, models.TamperingConfig{AllowedPreload: []string{"[invalid"}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,231 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracker keeps the violation state of recent scans so that only
+// changes have to be reported to the aggregator and the notifier.
+package tracker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+)
+
+const (
+	// DefaultFullSyncInterval is used when no full sync interval is configured
+	DefaultFullSyncInterval = time.Hour
+	// DefaultHistorySize is the number of scan snapshots kept for delta queries
+	DefaultHistorySize = 16
+	// StatusCleared marks a violation that disappeared since the previous scan
+	StatusCleared = "cleared"
+)
+
+type snapshot struct {
+	seq     uint64
+	records map[string]*models.ViolationRecord
+}
+
+// Tracker diffs consecutive scan results and remembers recent snapshots
+type Tracker struct {
+	node             string
+	epoch            string // random per tracker, the sequences restart with the agent
+	fullSyncInterval time.Duration
+	historySize      int
+
+	mu       sync.RWMutex
+	seq      uint64
+	history  []snapshot
+	lastFull time.Time
+}
+
+// NewTracker creates a tracker for node. Non-positive arguments fall back to defaults.
+func NewTracker(node string, fullSyncInterval time.Duration, historySize int) *Tracker {
+	if fullSyncInterval <= 0 {
+		fullSyncInterval = DefaultFullSyncInterval
+	}
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Tracker{
+		node:             node,
+		epoch:            newEpoch(),
+		fullSyncInterval: fullSyncInterval,
+		historySize:      historySize,
+	}
+}
+
+// SetFullSyncInterval changes the reconciliation interval, e.g. after a config reload
+func (t *Tracker) SetFullSyncInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFullSyncInterval
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fullSyncInterval = interval
+}
+
+// Update records the violations found by a scan and returns the changes since
+// the previous scan. The first scan and every full sync interval produce a
+// full-state delta.
+func (t *Tracker) Update(current map[string]*models.ViolationRecord, now time.Time) *models.ViolationDelta {
+	records := make(map[string]*models.ViolationRecord, len(current))
+	for key, record := range current {
+		records[key] = record
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var previous map[string]*models.ViolationRecord
+	since := t.seq
+	if len(t.history) > 0 {
+		previous = t.history[len(t.history)-1].records
+	}
+	t.seq++
+	t.history = append(t.history, snapshot{seq: t.seq, records: records})
+	if len(t.history) > t.historySize {
+		t.history = t.history[len(t.history)-t.historySize:]
+	}
+
+	delta := diff(previous, records)
+	delta.Node = t.node
+	delta.Epoch = t.epoch
+	delta.Seq = t.seq
+	delta.Since = since
+	delta.Timestamp = now
+	if t.lastFull.IsZero() || now.Sub(t.lastFull) >= t.fullSyncInterval {
+		t.lastFull = now
+		delta.Full = true
+		delta.Violations = sortedRecords(records)
+	}
+	return delta
+}
+
+// Since returns the changes between the snapshot with sequence since and the
+// latest one. A full-state delta is returned when since is zero or no longer
+// in the history.
+func (t *Tracker) Since(since uint64, now time.Time) *models.ViolationDelta {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var latest map[string]*models.ViolationRecord
+	if len(t.history) > 0 {
+		latest = t.history[len(t.history)-1].records
+	}
+	if since != 0 {
+		for _, snap := range t.history {
+			if snap.seq == since {
+				delta := diff(snap.records, latest)
+				delta.Node = t.node
+				delta.Epoch = t.epoch
+				delta.Seq = t.seq
+				delta.Since = since
+				delta.Timestamp = now
+				return delta
+			}
+		}
+	}
+	return &models.ViolationDelta{
+		Node:       t.node,
+		Epoch:      t.epoch,
+		Seq:        t.seq,
+		Since:      since,
+		Full:       true,
+		Violations: sortedRecords(latest),
+		Timestamp:  now,
+	}
+}
+
+// Epoch returns the identifier of the sequence numbers of t
+func (t *Tracker) Epoch() string {
+	return t.epoch
+}
+
+// Seq returns the sequence number of the latest scan
+func (t *Tracker) Seq() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.seq
+}
+
+func newEpoch() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
+
+func diff(previous, current map[string]*models.ViolationRecord) *models.ViolationDelta {
+	delta := &models.ViolationDelta{}
+	for _, key := range sortedKeys(current) {
+		record := current[key]
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			delta.Added = append(delta.Added, record)
+		case changed(old, record):
+			delta.Changed = append(delta.Changed, record)
+		}
+	}
+	for _, key := range sortedKeys(previous) {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		cleared := *previous[key]
+		cleared.Status = StatusCleared
+		delta.Cleared = append(delta.Cleared, &cleared)
+	}
+	return delta
+}
+
+// changed ignores the timestamp, which is refreshed on every scan
+func changed(old, current *models.ViolationRecord) bool {
+	return old.Cmdline != current.Cmdline ||
+		old.Regex != current.Regex ||
+		old.Status != current.Status ||
+		old.Type != current.Type ||
+		old.Name != current.Name ||
+		old.Severity != current.Severity ||
+		exceptionChanged(old.Exception, current.Exception)
+}
+
+func exceptionChanged(old, current *models.Exception) bool {
+	if old == nil || current == nil {
+		return old != current
+	}
+	return !old.Expires.Equal(current.Expires) || old.Justification != current.Justification
+}
+
+func sortedKeys(records map[string]*models.ViolationRecord) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedRecords(records map[string]*models.ViolationRecord) []*models.ViolationRecord {
+	result := make([]*models.ViolationRecord, 0, len(records))
+	for _, key := range sortedKeys(records) {
+		result = append(result, records[key])
+	}
+	return result
+}
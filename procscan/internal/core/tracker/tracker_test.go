@@ -0,0 +1,165 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracker Suite")
+}
+
+func record(pod, process, cmdline, timestamp string) *models.ViolationRecord {
+	return &models.ViolationRecord{
+		Namespace: "ns-a",
+		Pod:       pod,
+		Process:   process,
+		Cmdline:   cmdline,
+		Status:    "active",
+		Timestamp: timestamp,
+	}
+}
+
+var _ = Describe("Tracker", func() {
+	var (
+		t   *Tracker
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		t = NewTracker("node-1", time.Hour, 4)
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("should report full state on the first scan", func() {
+		delta := t.Update(map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig -o pool", "t1"),
+		}, now)
+		Expect(delta.Full).To(BeTrue())
+		Expect(delta.Seq).To(Equal(uint64(1)))
+		Expect(delta.Violations).To(HaveLen(1))
+		Expect(delta.Added).To(HaveLen(1))
+	})
+
+	It("should report only added, changed and cleared violations", func() {
+		t.Update(map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig -o pool", "t1"),
+			"ns-a/pod-2/nc":    record("pod-2", "nc", "nc -l -p 4444", "t1"),
+			"ns-a/pod-3/miner": record("pod-3", "miner", "miner", "t1"),
+		}, now)
+
+		delta := t.Update(map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig -o pool", "t2"),
+			"ns-a/pod-2/nc":    record("pod-2", "nc", "nc -l -p 5555", "t2"),
+			"ns-a/pod-4/asd":   record("pod-4", "asd", "asd", "t2"),
+		}, now.Add(time.Minute))
+
+		Expect(delta.Full).To(BeFalse())
+		Expect(delta.Since).To(Equal(uint64(1)))
+		Expect(delta.Added).To(HaveLen(1))
+		Expect(delta.Added[0].Pod).To(Equal("pod-4"))
+		Expect(delta.Changed).To(HaveLen(1))
+		Expect(delta.Changed[0].Pod).To(Equal("pod-2"))
+		Expect(delta.Cleared).To(HaveLen(1))
+		Expect(delta.Cleared[0].Pod).To(Equal("pod-3"))
+		Expect(delta.Cleared[0].Status).To(Equal(StatusCleared))
+	})
+
+	It("should report an empty delta when nothing changed", func() {
+		current := map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t1"),
+		}
+		t.Update(current, now)
+		Expect(t.Update(current, now.Add(time.Minute)).Empty()).To(BeTrue())
+	})
+
+	It("should report violations allowed or changed by an exception as changed", func() {
+		t.Update(map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t1"),
+		}, now)
+
+		excepted := record("pod-1", "xmrig", "xmrig", "t2")
+		excepted.Status = models.StatusExcepted
+		excepted.Exception = &models.Exception{Expires: now.Add(time.Hour), Justification: "Benchmark"}
+		delta := t.Update(map[string]*models.ViolationRecord{"ns-a/pod-1/xmrig": excepted}, now.Add(time.Minute))
+		Expect(delta.Changed).To(HaveLen(1))
+
+		extended := *excepted
+		extended.Exception = &models.Exception{Expires: now.Add(24 * time.Hour), Justification: "Benchmark"}
+		delta = t.Update(map[string]*models.ViolationRecord{"ns-a/pod-1/xmrig": &extended}, now.Add(2*time.Minute))
+		Expect(delta.Changed).To(HaveLen(1))
+	})
+
+	It("should reconcile with full state once the interval elapses", func() {
+		current := map[string]*models.ViolationRecord{
+			"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t1"),
+		}
+		t.Update(current, now)
+		Expect(t.Update(current, now.Add(30*time.Minute)).Full).To(BeFalse())
+		delta := t.Update(current, now.Add(time.Hour))
+		Expect(delta.Full).To(BeTrue())
+		Expect(delta.Violations).To(HaveLen(1))
+	})
+
+	Describe("Since", func() {
+		It("should diff against an earlier snapshot", func() {
+			t.Update(map[string]*models.ViolationRecord{}, now)
+			t.Update(map[string]*models.ViolationRecord{
+				"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t2"),
+			}, now)
+			t.Update(map[string]*models.ViolationRecord{
+				"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t3"),
+				"ns-a/pod-2/nc":    record("pod-2", "nc", "nc", "t3"),
+			}, now)
+
+			delta := t.Since(1, now)
+			Expect(delta.Full).To(BeFalse())
+			Expect(delta.Seq).To(Equal(uint64(3)))
+			Expect(delta.Added).To(HaveLen(2))
+		})
+
+		It("should fall back to full state for unknown sequences", func() {
+			for i := 0; i < 6; i++ {
+				t.Update(map[string]*models.ViolationRecord{
+					"ns-a/pod-1/xmrig": record("pod-1", "xmrig", "xmrig", "t"),
+				}, now)
+			}
+			Expect(t.Since(0, now).Full).To(BeTrue())
+			delta := t.Since(1, now)
+			Expect(delta.Full).To(BeTrue())
+			Expect(delta.Violations).To(HaveLen(1))
+			Expect(t.Since(t.Seq(), now).Empty()).To(BeTrue())
+		})
+
+		It("should tell restarted trackers apart by their epoch", func() {
+			t.Update(map[string]*models.ViolationRecord{}, now)
+			Expect(t.Epoch()).NotTo(BeEmpty())
+			Expect(t.Since(1, now).Epoch).To(Equal(t.Epoch()))
+
+			restarted := NewTracker("node-1", time.Hour, 4)
+			delta := restarted.Update(map[string]*models.ViolationRecord{}, now)
+			Expect(delta.Seq).To(Equal(uint64(1)))
+			Expect(delta.Epoch).To(Equal(restarted.Epoch()))
+			Expect(restarted.Epoch()).NotTo(Equal(t.Epoch()))
+		})
+	})
+})
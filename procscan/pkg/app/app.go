@@ -0,0 +1,76 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package app runs the ProcScan scanner. It backs both the procscan binary and
+// the `complik scan` subcommand.
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bearslyricattack/CompliK/procscan/internal/config"
+	"github.com/bearslyricattack/CompliK/procscan/internal/core/scanner"
+	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+)
+
+// Options controls how the scanner is started
+type Options struct {
+	ConfigPath string
+	// LogLevel overrides scanner.log_level from the configuration when set
+	LogLevel string
+	// LogFormat is "json" (default) or "text"
+	LogFormat string
+}
+
+// Run loads the configuration, watches it for hot reloads and scans until ctx
+// is cancelled
+func Run(ctx context.Context, opts Options) error {
+	legacy.SetFormat(opts.LogFormat)
+	legacy.L.Info("ProcScan is starting...")
+
+	// Load initial configuration
+	loader := config.NewLoader(opts.ConfigPath)
+	cfg, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load initial configuration: %w", err)
+	}
+
+	// Set log level from the flags or the initial configuration
+	if opts.LogLevel != "" {
+		legacy.SetLevel(opts.LogLevel)
+	} else if cfg.Scanner.LogLevel != "" {
+		legacy.SetLevel(cfg.Scanner.LogLevel)
+	}
+	legacy.L.Info("Initial configuration loaded successfully")
+
+	// Create scanner
+	s := scanner.NewScanner(cfg)
+
+	// Setup configuration watcher
+	configWatcher, err := config.NewWatcher(loader, s.UpdateConfig)
+	if err != nil {
+		legacy.L.WithError(err).Warn("Failed to create configuration watcher, hot-reload will be unavailable")
+	} else if err := configWatcher.Start(ctx); err != nil {
+		legacy.L.WithError(err).Warn("Failed to start configuration watcher, hot-reload will be unavailable")
+	} else {
+		defer configWatcher.Stop()
+	}
+
+	// Start scanner
+	if err := s.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start scanner: %w", err)
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -84,6 +85,12 @@ func (v *ConfigValidator) registerDefaultRules() {
 		AllowEmpty:      true,
 	})
 
+	// Rule sync configuration rules
+	v.AddRule("rule_sync.url", &URLRule{
+		RequiredSchemes: []string{"https", "http"},
+		AllowEmpty:      true,
+	})
+
 	// Detection rules configuration rules
 	v.AddRule("detectionRules.blacklist.processes", &SliceRule{
 		ElementRule: &RegexRule{},
@@ -132,9 +139,24 @@ func (v *ConfigValidator) Validate(config *models.Config) *ValidationResult {
 	// Validate notifications configuration
 	v.validateNotifications(config.Notifications, result)
 
+	// Validate container runtime
+	v.validateContainerRuntime(config.ContainerRuntime, result)
+
+	// Validate rule sync
+	v.validateRuleSync(config.RuleSync, result)
+
 	// Validate detection rules
 	v.validateDetectionRules(config.DetectionRules, result)
 
+	// Validate file integrity monitoring
+	v.validateIntegrity(config.Integrity, result)
+
+	// Validate tampering detection
+	v.validateTampering(config.Tampering, result)
+
+	// Validate egress detection
+	v.validateEgress(config.Egress, result)
+
 	// Cross-field validation
 	v.validateCrossFields(config, result)
 
@@ -194,6 +216,38 @@ func (v *ConfigValidator) validateNotifications(notifications models.Notificatio
 	}
 }
 
+// ValidateRules validates detection rules received from the aggregator
+func (v *ConfigValidator) ValidateRules(rules models.DetectionRules) *ValidationResult {
+	result := &ValidationResult{
+		Valid:    true,
+		Errors:   make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+	v.validateDetectionRules(rules, result)
+	v.validateRuleConflicts(rules, result)
+	if len(result.Errors) > 0 {
+		result.Valid = false
+	}
+	return result
+}
+
+// validateRuleSync validates the rule sync configuration
+func (v *ConfigValidator) validateRuleSync(ruleSync models.RuleSyncConfig, result *ValidationResult) {
+	if err := v.validateField("rule_sync.url", ruleSync.URL); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	if !ruleSync.Enabled {
+		return
+	}
+	if ruleSync.URL == "" {
+		result.Errors = append(result.Errors, "rule_sync.url: Field cannot be empty")
+	}
+	if ruleSync.Interval < 0 {
+		result.Errors = append(result.Errors, "rule_sync.interval: Interval cannot be negative")
+	}
+	result.Warnings = append(result.Warnings, "Rule sync is enabled, detectionRules of the configuration file are replaced by the rules of the aggregator")
+}
+
 // validateDetectionRules validates detection rules
 func (v *ConfigValidator) validateDetectionRules(rules models.DetectionRules, result *ValidationResult) {
 	// Validate blacklist rules
@@ -202,12 +256,220 @@ func (v *ConfigValidator) validateDetectionRules(rules models.DetectionRules, re
 	// Validate whitelist rules
 	v.validateRuleSet("detectionRules.whitelist", rules.Whitelist, result)
 
+	// Validate ancestry rules
+	v.validateAncestryRules(rules.Ancestry, result)
+
+	// Validate exceptions
+	v.validateExceptions(rules.Exceptions, time.Now(), result)
+
 	// Check rule logic
-	if len(rules.Blacklist.Processes) == 0 && len(rules.Blacklist.Keywords) == 0 {
+	if len(rules.Blacklist.Processes) == 0 && len(rules.Blacklist.Keywords) == 0 &&
+		len(rules.Ancestry) == 0 && !rules.Escape.Nsenter && !rules.Escape.CgroupMismatch {
 		result.Warnings = append(result.Warnings, "Blacklist rules are empty, may not detect suspicious processes")
 	}
 }
 
+// validateAncestryRules validates the parent-child process rules
+func (v *ConfigValidator) validateAncestryRules(rules []models.AncestryRule, result *ValidationResult) {
+	regexRule := &RegexRule{}
+	for i, rule := range rules {
+		prefix := fmt.Sprintf("detectionRules.ancestry[%d]", i)
+		if rule.Name == "" {
+			result.Errors = append(result.Errors, prefix+".name: Field cannot be empty")
+		}
+		if rule.Parent == "" || rule.Child == "" {
+			result.Errors = append(result.Errors, prefix+": parent and child patterns are required")
+			continue
+		}
+		if err := regexRule.Validate(rule.Parent); err != nil {
+			err.Field = prefix + ".parent"
+			result.Errors = append(result.Errors, err.Error())
+		}
+		if err := regexRule.Validate(rule.Child); err != nil {
+			err.Field = prefix + ".child"
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+}
+
+// validateExceptions validates the temporary exceptions, expired exceptions are only warned about
+func (v *ConfigValidator) validateExceptions(exceptions []models.Exception, now time.Time, result *ValidationResult) {
+	regexRule := &RegexRule{}
+	for i, exception := range exceptions {
+		prefix := fmt.Sprintf("detectionRules.exceptions[%d]", i)
+		if exception.Namespace == "" {
+			result.Errors = append(result.Errors, prefix+".namespace: Field cannot be empty")
+		}
+		if exception.Process == "" {
+			result.Errors = append(result.Errors, prefix+".process: Field cannot be empty")
+		} else if err := regexRule.Validate(exception.Process); err != nil {
+			err.Field = prefix + ".process"
+			result.Errors = append(result.Errors, err.Error())
+		}
+		if strings.TrimSpace(exception.Justification) == "" {
+			result.Errors = append(result.Errors, prefix+".justification: Field cannot be empty")
+		}
+		if exception.Expires.IsZero() {
+			result.Errors = append(result.Errors, prefix+".expires: Field cannot be empty")
+		} else if !exception.Active(now) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s expired at %s and is ignored",
+				prefix, exception.Expires.Format(time.RFC3339)))
+		}
+	}
+}
+
+// validateContainerRuntime validates the CRI endpoint used to resolve containers
+func (v *ConfigValidator) validateContainerRuntime(runtime models.ContainerRuntimeConfig, result *ValidationResult) {
+	if runtime.Endpoint != "" && !strings.HasPrefix(runtime.Endpoint, "unix://") && !strings.HasPrefix(runtime.Endpoint, "/") {
+		err := &ValidationError{
+			Field:   "container_runtime.endpoint",
+			Value:   runtime.Endpoint,
+			Message: "Must be a unix:// endpoint or an absolute socket path",
+			Code:    "INVALID_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	}
+	if runtime.Timeout < 0 {
+		result.Errors = append(result.Errors, "container_runtime.timeout: Timeout cannot be negative")
+	}
+	if runtime.CacheTTL < 0 {
+		result.Errors = append(result.Errors, "container_runtime.cache_ttl: TTL cannot be negative")
+	}
+}
+
+// validateIntegrity validates the file integrity monitoring configuration
+func (v *ConfigValidator) validateIntegrity(integrity models.IntegrityConfig, result *ValidationResult) {
+	if !integrity.Enabled {
+		return
+	}
+	if len(integrity.Paths) == 0 {
+		result.Warnings = append(result.Warnings, "File integrity monitoring is enabled but integrity.paths is empty")
+	}
+	pathRule := &PathRule{}
+	for i, path := range integrity.Paths {
+		if path == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("integrity.paths[%d]: Field cannot be empty", i))
+			continue
+		}
+		if err := pathRule.Validate(path); err != nil {
+			err.Field = fmt.Sprintf("integrity.paths[%d]", i)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	if err := pathRule.Validate(integrity.HostRoot); err != nil {
+		err.Field = "integrity.host_root"
+		result.Errors = append(result.Errors, err.Error())
+	}
+	regexRule := &RegexRule{}
+	for i, pattern := range integrity.Exclude {
+		if err := regexRule.Validate(pattern); err != nil {
+			err.Field = fmt.Sprintf("integrity.exclude[%d]", i)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+}
+
+// validateTampering validates the tampering detection configuration
+func (v *ConfigValidator) validateTampering(tampering models.TamperingConfig, result *ValidationResult) {
+	if !tampering.Enabled {
+		return
+	}
+	if !tampering.Modules && !tampering.Preload {
+		result.Warnings = append(result.Warnings, "Tampering detection is enabled but neither tampering.modules nor tampering.preload is set")
+	}
+	if tampering.Modules && len(tampering.AllowedModules) == 0 {
+		result.Warnings = append(result.Warnings, "tampering.allowed_modules is empty, every loaded kernel module is reported")
+	}
+	if tampering.HostRoot != "" {
+		if err := (&PathRule{}).Validate(tampering.HostRoot); err != nil {
+			err.Field = "tampering.host_root"
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	regexRule := &RegexRule{}
+	for i, pattern := range tampering.AllowedModules {
+		if err := regexRule.Validate(pattern); err != nil {
+			err.Field = fmt.Sprintf("tampering.allowed_modules[%d]", i)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	for i, pattern := range tampering.AllowedPreload {
+		if err := regexRule.Validate(pattern); err != nil {
+			err.Field = fmt.Sprintf("tampering.allowed_preload[%d]", i)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	switch tampering.Severity {
+	case "", "low", "medium", "high", "critical":
+	default:
+		err := &ValidationError{
+			Field:   "tampering.severity",
+			Value:   tampering.Severity,
+			Message: "Must be one of low, medium, high, critical",
+			Code:    "INVALID_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
+
+// validateEgress validates the egress detection configuration
+func (v *ConfigValidator) validateEgress(egress models.EgressConfig, result *ValidationResult) {
+	if !egress.Enabled {
+		return
+	}
+	if egress.MinDestinations < 0 {
+		err := &ValidationError{
+			Field:   "egress.min_destinations",
+			Value:   egress.MinDestinations,
+			Message: "Must not be negative",
+			Code:    "MIN_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	} else if egress.MinDestinations > 0 && egress.MinDestinations < 10 {
+		result.Warnings = append(result.Warnings, "egress.min_destinations below 10 flags ordinary clients of a few APIs")
+	}
+	if egress.MinTxBytesPerSecond < 0 {
+		err := &ValidationError{
+			Field:   "egress.min_tx_bytes_per_second",
+			Value:   egress.MinTxBytesPerSecond,
+			Message: "Must not be negative",
+			Code:    "MIN_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	}
+	if egress.SustainedSamples < 0 {
+		err := &ValidationError{
+			Field:   "egress.sustained_samples",
+			Value:   egress.SustainedSamples,
+			Message: "Must not be negative",
+			Code:    "MIN_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	}
+	for i, cidr := range egress.IgnoredCIDRs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			err := &ValidationError{
+				Field:   fmt.Sprintf("egress.ignored_cidrs[%d]", i),
+				Value:   cidr,
+				Message: "Must be a CIDR such as 100.64.0.0/10",
+				Code:    "INVALID_CIDR",
+			}
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	switch egress.Severity {
+	case "", "low", "medium", "high", "critical":
+	default:
+		err := &ValidationError{
+			Field:   "egress.severity",
+			Value:   egress.Severity,
+			Message: "Must be one of low, medium, high, critical",
+			Code:    "INVALID_VALUE",
+		}
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
+
 // validateRuleSet validates a rule set
 func (v *ConfigValidator) validateRuleSet(prefix string, ruleSet models.RuleSet, result *ValidationResult) {
 	if err := v.validateField(prefix+".processes", ruleSet.Processes); err != nil {
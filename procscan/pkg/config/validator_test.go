@@ -138,6 +138,170 @@ var _ = Describe("ConfigValidator", func() {
 			Expect(result.Errors).NotTo(BeEmpty())
 		})
 
+		It("should detect invalid ancestry rules", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				DetectionRules: models.DetectionRules{
+					Blacklist: models.RuleSet{Processes: []string{"^xmrig$"}},
+					Ancestry: []models.AncestryRule{
+						{Name: "web-shell", Parent: "^(nginx|httpd)$", Child: "^(sh|bash)$"},
+						{Name: "broken", Parent: "[invalid", Child: "^sh$"},
+						{Parent: "^java$"},
+					},
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(3))
+			Expect(result.Errors[0]).To(ContainSubstring("detectionRules.ancestry[1].parent"))
+			Expect(result.Errors[1]).To(ContainSubstring("detectionRules.ancestry[2].name"))
+			Expect(result.Errors[2]).To(ContainSubstring("parent and child patterns are required"))
+		})
+
+		It("should detect invalid exceptions and warn about expired ones", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				DetectionRules: models.DetectionRules{
+					Blacklist: models.RuleSet{Processes: []string{"^xmrig$"}},
+					Exceptions: []models.Exception{
+						{Namespace: "ns-lab", Process: "^xmrig$", Expires: time.Now().Add(time.Hour), Justification: "Benchmark"},
+						{Namespace: "ns-lab", Process: "[invalid", Expires: time.Now().Add(time.Hour), Justification: "Benchmark"},
+						{Namespace: "ns-lab", Process: "^xmrig$", Justification: " "},
+						{Namespace: "ns-old", Process: "^xmrig$", Expires: time.Now().Add(-time.Hour), Justification: "Expired"},
+					},
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(3))
+			Expect(result.Errors[0]).To(ContainSubstring("detectionRules.exceptions[1].process"))
+			Expect(result.Errors[1]).To(ContainSubstring("detectionRules.exceptions[2].justification"))
+			Expect(result.Errors[2]).To(ContainSubstring("detectionRules.exceptions[2].expires"))
+			Expect(result.Warnings).To(ContainElement(ContainSubstring("detectionRules.exceptions[3] expired")))
+		})
+
+		It("should detect invalid integrity configuration", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				Integrity: models.IntegrityConfig{
+					Enabled:  true,
+					HostRoot: "host",
+					Paths:    []string{"/etc/cron.d", "usr/local/bin"},
+					Exclude:  []string{"[invalid"},
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(3))
+			Expect(result.Errors[0]).To(ContainSubstring("integrity.paths[1]"))
+			Expect(result.Errors[1]).To(ContainSubstring("integrity.host_root"))
+			Expect(result.Errors[2]).To(ContainSubstring("integrity.exclude[0]"))
+		})
+
+		It("should detect invalid container runtime configuration", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				ContainerRuntime: models.ContainerRuntimeConfig{
+					Endpoint: "tcp://127.0.0.1:10010",
+					Timeout:  -time.Second,
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(2))
+			Expect(result.Errors[0]).To(ContainSubstring("container_runtime.endpoint"))
+			Expect(result.Errors[1]).To(ContainSubstring("container_runtime.timeout"))
+
+			config.ContainerRuntime = models.ContainerRuntimeConfig{Endpoint: "/var/run/crio/crio.sock"}
+			Expect(validator.Validate(config).Valid).To(BeTrue())
+		})
+
+		It("should detect invalid tampering configuration", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				Tampering: models.TamperingConfig{
+					Enabled:        true,
+					HostRoot:       "host",
+					Modules:        true,
+					AllowedPreload: []string{"[invalid"},
+					Severity:       "urgent",
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(3))
+			Expect(result.Errors[0]).To(ContainSubstring("tampering.host_root"))
+			Expect(result.Errors[1]).To(ContainSubstring("tampering.allowed_preload[0]"))
+			Expect(result.Errors[2]).To(ContainSubstring("tampering.severity"))
+			Expect(result.Warnings).To(ContainElement(ContainSubstring("tampering.allowed_modules is empty")))
+		})
+
+		It("should detect invalid egress configuration", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				Egress: models.EgressConfig{
+					Enabled:          true,
+					MinDestinations:  5,
+					SustainedSamples: -1,
+					IgnoredCIDRs:     []string{"100.64.0.0/10", "100.64.0.0"},
+					Severity:         "urgent",
+				},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(HaveLen(3))
+			Expect(result.Errors[0]).To(ContainSubstring("egress.sustained_samples"))
+			Expect(result.Errors[1]).To(ContainSubstring("egress.ignored_cidrs[1]"))
+			Expect(result.Errors[2]).To(ContainSubstring("egress.severity"))
+			Expect(result.Warnings).To(ContainElement(ContainSubstring("egress.min_destinations below 10")))
+		})
+
+		It("should require the aggregator URL when rule sync is enabled", func() {
+			config := &models.Config{
+				Scanner: models.ScannerConfig{
+					ScanInterval: 60 * time.Second,
+					LogLevel:     "info",
+				},
+				RuleSync: models.RuleSyncConfig{Enabled: true},
+			}
+
+			result := validator.Validate(config)
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(ConsistOf(ContainSubstring("rule_sync.url")))
+
+			config.RuleSync.URL = "ftp://procscan-aggregator:8090"
+			Expect(validator.Validate(config).Errors).To(ConsistOf(ContainSubstring("rule_sync.url")))
+
+			config.RuleSync.URL = "http://procscan-aggregator.kube-system:8090"
+			result = validator.Validate(config)
+			Expect(result.Valid).To(BeTrue())
+			Expect(result.Warnings).To(ContainElement(ContainSubstring("Rule sync is enabled")))
+		})
+
 		It("should warn when webhook is empty", func() {
 			config := &models.Config{
 				Scanner: models.ScannerConfig{
@@ -231,6 +395,23 @@ var _ = Describe("ConfigValidator", func() {
 		})
 	})
 
+	Describe("ValidateRules", func() {
+		It("should validate rules received from the aggregator", func() {
+			result := validator.ValidateRules(models.DetectionRules{
+				Blacklist: models.RuleSet{Processes: []string{"^xmrig$"}},
+				Whitelist: models.RuleSet{Processes: []string{"^xmrig$"}},
+			})
+			Expect(result.Valid).To(BeTrue())
+			Expect(result.Warnings).To(ContainElement(ContainSubstring("appears in both blacklist and whitelist")))
+
+			result = validator.ValidateRules(models.DetectionRules{
+				Blacklist: models.RuleSet{Processes: []string{"[invalid"}},
+			})
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Errors).To(ConsistOf(ContainSubstring("detectionRules.blacklist.processes")))
+		})
+	})
+
 	Describe("ValidateFile", func() {
 		It("should accept .yaml extension", func() {
 			result := validator.ValidateFile("/path/to/config.yaml")
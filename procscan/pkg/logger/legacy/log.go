@@ -43,3 +43,15 @@ func SetLevel(levelStr string) {
 	L.SetLevel(level)
 	L.WithField("new_level", level.String()).Info("Log level updated")
 }
+
+// SetFormat switches the global logger between "json" (default) and "text" output.
+func SetFormat(format string) {
+	switch format {
+	case "", "json":
+		L.SetFormatter(&logrus.JSONFormatter{})
+	case "text":
+		L.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		L.Warnf("Invalid log format '%s', will continue using current format", format)
+	}
+}
@@ -23,8 +23,19 @@ import (
 	"time"
 
 	legacy "github.com/bearslyricattack/CompliK/procscan/pkg/logger/legacy"
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
 )
 
+// Action results used as the result label of ActionsTotal
+const (
+	ActionSuccess = "success"
+	ActionFailure = "failure"
+	ActionSkipped = "skipped"
+)
+
+// unknownRule labels the violations without a matched rule
+const unknownRule = "unknown"
+
 // Collector is responsible for collecting and updating various metrics
 type Collector struct {
 	startTime time.Time
@@ -37,6 +48,12 @@ func NewCollector() *Collector {
 	}
 }
 
+// RecordAgentInfo records the node the agent runs on
+func (c *Collector) RecordAgentInfo(node string) {
+	AgentInfo.Reset()
+	AgentInfo.WithLabelValues(node).Set(1)
+}
+
 // RecordScanStart records the start of a scan
 func (c *Collector) RecordScanStart() {
 	ScanTotal.Inc()
@@ -67,14 +84,52 @@ func (c *Collector) RecordSuspiciousProcesses(count int, namespace string) {
 	SuspiciousProcessesByNamespace.WithLabelValues(namespace).Set(float64(count))
 }
 
+// RecordRuleHits records the new violations of a scan by the rule that found them
+func (c *Collector) RecordRuleHits(added []*models.ViolationRecord) {
+	for _, record := range added {
+		RuleHitsTotal.WithLabelValues(ruleLabel(record)).Inc()
+	}
+}
+
+// RecordActiveViolations replaces the active violation counts with those of
+// the last scan. Violations allowed by an exception are not counted.
+func (c *Collector) RecordActiveViolations(records map[string]*models.ViolationRecord) {
+	counts := make(map[string]int)
+	for _, record := range records {
+		if record.Status == models.StatusExcepted {
+			continue
+		}
+		counts[ruleLabel(record)]++
+	}
+	RuleActiveViolations.Reset()
+	for rule, count := range counts {
+		RuleActiveViolations.WithLabelValues(rule).Set(float64(count))
+	}
+}
+
+func ruleLabel(record *models.ViolationRecord) string {
+	if record.Regex == "" {
+		return unknownRule
+	}
+	return record.Regex
+}
+
 // RecordLabelAction records a label operation
 func (c *Collector) RecordLabelAction(success bool) {
 	LabelActionsTotal.Inc()
 	if success {
 		LabelActionsSuccessTotal.Inc()
+		c.RecordAction("label", ActionSuccess)
+	} else {
+		c.RecordAction("label", ActionFailure)
 	}
 }
 
+// RecordAction records a response action with its result
+func (c *Collector) RecordAction(action, result string) {
+	ActionsTotal.WithLabelValues(action, result).Inc()
+}
+
 // RecordNotification records a notification send attempt
 func (c *Collector) RecordNotification(success bool) {
 	if success {
@@ -0,0 +1,64 @@
+// Copyright 2025 CompliK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/bearslyricattack/CompliK/procscan/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metrics Suite")
+}
+
+var _ = Describe("Collector", func() {
+	c := NewCollector()
+
+	It("should count rule hits and active violations by rule", func() {
+		xmrig := &models.ViolationRecord{Pod: "pod-1", Regex: "^xmrig$"}
+		c.RecordRuleHits([]*models.ViolationRecord{xmrig, {Pod: "pod-2", Regex: "^xmrig$"}, {Pod: "pod-3"}})
+		Expect(testutil.ToFloat64(RuleHitsTotal.WithLabelValues("^xmrig$"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(RuleHitsTotal.WithLabelValues("unknown"))).To(Equal(1.0))
+
+		c.RecordActiveViolations(map[string]*models.ViolationRecord{"ns-a/pod-1/xmrig": xmrig})
+		c.RecordActiveViolations(map[string]*models.ViolationRecord{"ns-a/pod-1/xmrig": xmrig})
+		Expect(testutil.ToFloat64(RuleActiveViolations.WithLabelValues("^xmrig$"))).To(Equal(1.0))
+
+		c.RecordActiveViolations(nil)
+		Expect(testutil.CollectAndCount(RuleActiveViolations)).To(BeZero())
+	})
+
+	It("should count actions by result", func() {
+		c.RecordLabelAction(true)
+		c.RecordLabelAction(false)
+		c.RecordAction("label", ActionSkipped)
+		Expect(testutil.ToFloat64(ActionsTotal.WithLabelValues("label", ActionSuccess))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(ActionsTotal.WithLabelValues("label", ActionFailure))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(ActionsTotal.WithLabelValues("label", ActionSkipped))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(LabelActionsTotal)).To(Equal(2.0))
+	})
+
+	It("should label the agent with its node", func() {
+		c.RecordAgentInfo("node-1")
+		c.RecordAgentInfo("node-2")
+		Expect(testutil.CollectAndCount(AgentInfo)).To(Equal(1))
+		Expect(testutil.ToFloat64(AgentInfo.WithLabelValues("node-2"))).To(Equal(1.0))
+	})
+})
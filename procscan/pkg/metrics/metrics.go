@@ -26,6 +26,11 @@ var (
 		Help: "Indicates whether the scanner is currently running (1 for running, 0 for stopped)",
 	})
 
+	AgentInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "procscan_agent_info",
+		Help: "Always 1, labeled with the node the agent runs on",
+	}, []string{"node"})
+
 	ScannerUptimeSeconds = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "procscan_scanner_uptime_seconds",
 		Help: "Total uptime of the scanner in seconds",
@@ -73,7 +78,23 @@ var (
 		Help: "Number of suspicious processes detected by namespace",
 	}, []string{"namespace"})
 
+	// Rule metrics
+	RuleHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "procscan_rule_hits_total",
+		Help: "Number of new violations found by each rule",
+	}, []string{"rule"})
+
+	RuleActiveViolations = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "procscan_rule_active_violations",
+		Help: "Number of violations of each rule found by the last scan",
+	}, []string{"rule"})
+
 	// Response action metrics
+	ActionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "procscan_actions_total",
+		Help: "Number of response actions taken by action and result",
+	}, []string{"action", "result"})
+
 	LabelActionsTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "procscan_label_actions_total",
 		Help: "Total number of label actions attempted",
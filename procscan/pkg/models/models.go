@@ -27,6 +27,19 @@ type ScannerConfig struct {
 	ProcPath     string        `yaml:"proc_path"`
 	ScanInterval time.Duration `yaml:"scan_interval"`
 	LogLevel     string        `yaml:"log_level"`
+	// FullSyncInterval is how often the full violation state is reported instead of a delta
+	FullSyncInterval time.Duration `yaml:"full_sync_interval"`
+}
+
+// ContainerRuntimeConfig contains configuration for resolving containers to pods
+// through the CRI API of containerd or CRI-O
+type ContainerRuntimeConfig struct {
+	// Endpoint is the CRI socket, the default sockets of containerd and CRI-O are tried when empty
+	Endpoint string `yaml:"endpoint"`
+	// Timeout bounds every CRI request
+	Timeout time.Duration `yaml:"timeout"`
+	// CacheTTL is how long a resolved container is reused
+	CacheTTL time.Duration `yaml:"cache_ttl"`
 }
 
 // LabelActionConfig contains configuration for label actions
@@ -62,35 +75,151 @@ type MetricsConfig struct {
 	RetryInterval time.Duration `yaml:"retry_interval"`
 }
 
-// APIConfig contains configuration for the HTTP API server
+// APIConfig contains configuration for the HTTP API server.
+// The API is served on SocketPath; Port additionally exposes it over TCP when non-zero.
+// When the socket cannot be created the API is only served over TCP, on Port or 9090 when unset.
 type APIConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Port       int    `yaml:"port"`
+	SocketPath string `yaml:"socket_path"`
+}
+
+// IntegrityConfig contains configuration for file integrity monitoring of host paths.
+// Paths are host paths; they are read below HostRoot, where the host filesystem is mounted.
+type IntegrityConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	HostRoot string   `yaml:"host_root"`
+	Paths    []string `yaml:"paths"`
+	// Exclude holds regular expressions on host paths that are never reported
+	Exclude []string `yaml:"exclude"`
+	// MaxFileSize is the largest file that is hashed, larger files are compared by size and mtime
+	MaxFileSize int64 `yaml:"max_file_size"`
+	// Namespace is the namespace host file changes are reported in
+	Namespace string `yaml:"namespace"`
+}
+
+// TamperingConfig contains configuration for the detection of kernel modules and
+// preloaded libraries that tamper with the host or with containers
+type TamperingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// HostRoot is where the host filesystem is mounted, /etc/ld.so.preload is read below it
+	HostRoot string `yaml:"host_root"`
+	// Modules reports loaded kernel modules that do not match AllowedModules
+	Modules        bool     `yaml:"modules"`
+	AllowedModules []string `yaml:"allowed_modules"`
+	// Preload reports LD_PRELOAD and ld.so.preload entries that do not match AllowedPreload,
+	// on the host and inside containers
+	Preload        bool     `yaml:"preload"`
+	AllowedPreload []string `yaml:"allowed_preload"`
+	// Severity is reported with every tampering violation
+	Severity string `yaml:"severity"`
+	// Namespace is the namespace host tampering is reported in
+	Namespace string `yaml:"namespace"`
+}
+
+// EgressConfig contains configuration for the detection of pods that keep
+// connecting to many distinct external addresses
+type EgressConfig struct {
 	Enabled bool `yaml:"enabled"`
-	Port    int  `yaml:"port"`
+	// MinDestinations is the number of distinct external addresses with open
+	// outbound connections a pod is flagged at
+	MinDestinations int `yaml:"min_destinations"`
+	// MinTxBytesPerSecond additionally requires the pod to send that many bytes
+	// per second since the previous scan, 0 disables the condition
+	MinTxBytesPerSecond int64 `yaml:"min_tx_bytes_per_second"`
+	// SustainedSamples is the number of consecutive scans a pod must exceed the
+	// thresholds in before it is reported
+	SustainedSamples int `yaml:"sustained_samples"`
+	// IgnoredCIDRs are destinations that are not counted, in addition to
+	// private, loopback and link-local addresses
+	IgnoredCIDRs []string `yaml:"ignored_cidrs"`
+	// Severity is reported with every egress violation
+	Severity string `yaml:"severity"`
+}
+
+// RuleSyncConfig contains configuration for pulling detection rules from the aggregator.
+// Rules received from the aggregator replace DetectionRules of the configuration file.
+type RuleSyncConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL is the base URL of the aggregator, rules are fetched from {URL}/api/rules
+	URL      string        `yaml:"url"`
+	Interval time.Duration `yaml:"interval"`
 }
 
 // RuleSet defines a set of matching rules, all rules will be parsed as regular expressions
 type RuleSet struct {
-	Processes  []string `yaml:"processes"`
-	Keywords   []string `yaml:"keywords"`
-	Commands   []string `yaml:"commands"`
-	Namespaces []string `yaml:"namespaces"`
-	PodNames   []string `yaml:"podNames"`
+	Processes  []string `yaml:"processes"  json:"processes"`
+	Keywords   []string `yaml:"keywords"   json:"keywords"`
+	Commands   []string `yaml:"commands"   json:"commands"`
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+	PodNames   []string `yaml:"podNames"   json:"podNames"`
+}
+
+// AncestryRule flags a process whose name matches Child when one of its
+// nearest Depth ancestors matches Parent. Both are regular expressions on the
+// process name; Depth defaults to 1, the direct parent.
+type AncestryRule struct {
+	Name   string `yaml:"name"   json:"name"`
+	Parent string `yaml:"parent" json:"parent"`
+	Child  string `yaml:"child"  json:"child"`
+	Depth  int    `yaml:"depth"  json:"depth"`
+}
+
+// EscapeRules enables the container escape heuristics
+type EscapeRules struct {
+	// Nsenter flags nsenter running inside a container
+	Nsenter bool `yaml:"nsenter" json:"nsenter"`
+	// CgroupMismatch flags processes outside any container cgroup whose
+	// ancestors run inside a container
+	CgroupMismatch bool `yaml:"cgroupMismatch" json:"cgroupMismatch"`
+}
+
+// Exception temporarily allows the processes whose name matches the regular
+// expression Process in Namespace. Allowed processes are still reported with
+// the excepted status, but no action is taken and no alert is sent for them.
+// The exception is ignored once Expires has passed.
+type Exception struct {
+	Namespace     string    `yaml:"namespace"     json:"namespace"`
+	Process       string    `yaml:"process"       json:"process"`
+	Expires       time.Time `yaml:"expires"       json:"expires"`
+	Justification string    `yaml:"justification" json:"justification"`
+}
+
+// Active reports whether the exception is honored at now
+func (e *Exception) Active(now time.Time) bool {
+	return now.Before(e.Expires)
 }
 
 // DetectionRules contains both blacklist and whitelist rule sets
 type DetectionRules struct {
-	Blacklist RuleSet `yaml:"blacklist"`
-	Whitelist RuleSet `yaml:"whitelist"`
+	Blacklist  RuleSet        `yaml:"blacklist"  json:"blacklist"`
+	Whitelist  RuleSet        `yaml:"whitelist"  json:"whitelist"`
+	Ancestry   []AncestryRule `yaml:"ancestry"   json:"ancestry"`
+	Escape     EscapeRules    `yaml:"escape"     json:"escape"`
+	Exceptions []Exception    `yaml:"exceptions" json:"exceptions"`
 }
 
 // Config is the final, unified top-level configuration structure
 type Config struct {
-	Scanner        ScannerConfig       `yaml:"scanner"`
-	Actions        ActionsConfig       `yaml:"actions"`
-	Notifications  NotificationsConfig `yaml:"notifications"`
-	Metrics        MetricsConfig       `yaml:"metrics"`
-	API            APIConfig           `yaml:"api"`
-	DetectionRules DetectionRules      `yaml:"detectionRules"`
+	Scanner          ScannerConfig          `yaml:"scanner"`
+	ContainerRuntime ContainerRuntimeConfig `yaml:"container_runtime"`
+	Actions          ActionsConfig          `yaml:"actions"`
+	Notifications    NotificationsConfig    `yaml:"notifications"`
+	Metrics          MetricsConfig          `yaml:"metrics"`
+	API              APIConfig              `yaml:"api"`
+	Integrity        IntegrityConfig        `yaml:"integrity"`
+	Tampering        TamperingConfig        `yaml:"tampering"`
+	Egress           EgressConfig           `yaml:"egress"`
+	RuleSync         RuleSyncConfig         `yaml:"rule_sync"`
+	DetectionRules   DetectionRules         `yaml:"detectionRules"`
+}
+
+// DistributedRules is a version of the detection rules served by the aggregator,
+// Canary is set when the version is only rolled out to part of the nodes
+type DistributedRules struct {
+	Version string         `json:"version"`
+	Canary  bool           `json:"canary"`
+	Rules   DetectionRules `json:"rules"`
 }
 
 // --- Business data models ---
@@ -103,14 +232,33 @@ type ProcessInfo struct {
 	PodName     string
 	Namespace   string
 	ContainerID string
+	SandboxID   string // 容器所属 Pod sandbox 的 ID
+	Image       string // 容器镜像
+	ImageDigest string // 镜像的 digest，运行时未提供时为镜像 ID
 	Timestamp   string
 	Message     string
 	PodLabels   map[string]string // Pod 的 labels
 	AppType     string            // "app" 或 "devbox"
 	AppName     string            // 应用名称
 	MatchedRule string            // 匹配的正则规则
+	Ancestry    []string          // 祖先进程名，由近及远，仅进程树规则命中时填充
+	Exception   *Exception        // 放行该进程的例外，未命中例外时为 nil
+	Category    string            // 违规类别，进程和文件完整性违规为空
+	Severity    string            // 违规等级，仅设置了 Category 的违规填写
 }
 
+// 违规记录状态
+const (
+	StatusActive   = "active"
+	StatusExcepted = "excepted" // 命中了未过期的例外，不执行处置也不告警
+)
+
+// 违规类别
+const (
+	CategoryTampering = "tampering" // 可疑的内核模块或预加载库
+	CategoryEgress    = "egress"    // 持续连接大量外部地址的 Pod
+)
+
 // ViolationRecord 表示不合规应用的完整记录信息
 // 用于API返回和聚合服务处理
 type ViolationRecord struct {
@@ -123,4 +271,61 @@ type ViolationRecord struct {
 	Type      string `json:"type"`      // 类型（app 或 devbox）
 	Name      string `json:"name"`      // 应用名称（app label 或 devbox name）
 	Timestamp string `json:"timestamp"` // 检测时间
+	// Exception 放行该违规的例外，仅 Status 为 excepted 时存在
+	Exception *Exception `json:"exception,omitempty"`
+	Category  string     `json:"category,omitempty"` // 违规类别，进程和文件完整性违规为空
+	Severity  string     `json:"severity,omitempty"` // 违规等级
+	// 违规进程所在的容器，主机上的违规为空
+	ContainerID string `json:"container_id,omitempty"`
+	SandboxID   string `json:"sandbox_id,omitempty"`
+	Image       string `json:"image,omitempty"`
+	ImageDigest string `json:"image_digest,omitempty"`
+}
+
+// ScanSummary 描述一轮扫描的结果摘要
+type ScanSummary struct {
+	StartedAt          time.Time `json:"started_at"`
+	FinishedAt         time.Time `json:"finished_at"`
+	DurationSeconds    float64   `json:"duration_seconds"`
+	ProcessesScanned   int       `json:"processes_scanned"`
+	Violations         int       `json:"violations"`
+	AffectedNamespaces []string  `json:"affected_namespaces"`
+	Error              string    `json:"error,omitempty"`
+}
+
+// AgentStatus 描述单个节点上扫描器的运行状态
+type AgentStatus struct {
+	Node           string    `json:"node"`
+	StartedAt      time.Time `json:"started_at"`
+	UptimeSeconds  float64   `json:"uptime_seconds"`
+	ScanInterval   string    `json:"scan_interval"`
+	ScanCount      int64     `json:"scan_count"`
+	ScanErrors     int64     `json:"scan_errors"`
+	LastScanAt     time.Time `json:"last_scan_at,omitzero"`
+	ViolationCount int       `json:"violation_count"`
+	K8sClientReady bool      `json:"k8s_client_ready"`
+	LabelEnabled   bool      `json:"label_action_enabled"`
+	// RulesVersion is the version of the rules received from the aggregator, empty when the local rules are used
+	RulesVersion string `json:"rules_version,omitempty"`
+	RulesCanary  bool   `json:"rules_canary,omitempty"`
+}
+
+// ViolationDelta 描述相邻两次扫描之间违规记录的变化
+// Full 为 true 时 Violations 包含完整状态，用于周期性全量对账
+type ViolationDelta struct {
+	Node       string             `json:"node"`
+	Epoch      string             `json:"epoch"` // procscan 重启后 Seq 从头计数，Epoch 随之变化
+	Seq        uint64             `json:"seq"`
+	Since      uint64             `json:"since"`
+	Full       bool               `json:"full"`
+	Added      []*ViolationRecord `json:"added,omitempty"`
+	Changed    []*ViolationRecord `json:"changed,omitempty"`
+	Cleared    []*ViolationRecord `json:"cleared,omitempty"`
+	Violations []*ViolationRecord `json:"violations,omitempty"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// Empty 判断增量是否没有任何变化
+func (d *ViolationDelta) Empty() bool {
+	return !d.Full && len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Cleared) == 0
 }